@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/horizoncd/horizon/pkg/agent"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+)
+
+const (
+	AgentCommand = "agent"
+
+	_agentTokenEnv = "HORIZON_AGENT_TOKEN"
+)
+
+// RunAgent runs the agent of a region inside its cluster. The agent polls kubernetes requests
+// of the region from horizon and performs them locally, so horizon can manage clusters it
+// cannot reach directly. It returns the exit code.
+func RunAgent(args []string) int {
+	var (
+		server     string
+		region     string
+		kubeconfig string
+	)
+	fs := flag.NewFlagSet(AgentCommand, flag.ExitOnError)
+	fs.StringVar(&server, "server", "", "address of horizon, such as https://horizon.example.com")
+	fs.StringVar(&region, "region", "", "name of the region served by the agent")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "kubeconfig file path, in cluster config is used when empty")
+	_ = fs.Parse(args)
+
+	token := os.Getenv(_agentTokenEnv)
+	if server == "" || region == "" || token == "" {
+		fmt.Fprintf(os.Stderr, "-server, -region and %s are required\n", _agentTokenEnv)
+		return 1
+	}
+	restConfig, _, err := kube.BuildClient(kubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build kube client: %v\n", err)
+		return 1
+	}
+	a, err := agent.New(server, region, token, restConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create agent: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-sig
+		cancel()
+	}()
+	if err := a.Run(ctx); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "agent stopped: %v\n", err)
+		return 1
+	}
+	return 0
+}
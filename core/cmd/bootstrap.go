@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/config"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/bootstrap"
+	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
+	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+const BootstrapCommand = "bootstrap"
+
+// RunBootstrap reconciles the platform resources declared in yaml files into the database,
+// so that new installations and platform config managed by git don't need to be set up by apis.
+// It returns the exit code.
+func RunBootstrap(args []string) int {
+	var (
+		configFile string
+		files      string
+		accountID  uint
+		dryRun     bool
+	)
+	fs := flag.NewFlagSet(BootstrapCommand, flag.ExitOnError)
+	fs.StringVar(&configFile, "config", "", "configuration file path")
+	fs.StringVar(&files, "f", "", "spec files or directories separated by comma")
+	fs.UintVar(&accountID, "account", 0, "id of the user who applies the spec and owns the resources created")
+	fs.BoolVar(&dryRun, "dry-run", false, "if true, only print the changes without applying them")
+	_ = fs.Parse(args)
+
+	if err := runBootstrap(context.Background(), configFile, files, accountID, dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "bootstrap failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runBootstrap(ctx context.Context, configFile, files string, accountID uint, dryRun bool) error {
+	if files == "" {
+		return fmt.Errorf("spec files are required by -f")
+	}
+	if accountID == 0 {
+		return fmt.Errorf("the user applying the spec is required by -account")
+	}
+	spec, err := bootstrap.LoadSpec(strings.Split(files, ",")...)
+	if err != nil {
+		return err
+	}
+
+	coreConfig, err := config.LoadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	db, err := orm.NewMySQLDB(&orm.MySQL{
+		Host:     coreConfig.DBConfig.Host,
+		Port:     coreConfig.DBConfig.Port,
+		Username: coreConfig.DBConfig.Username,
+		Password: coreConfig.DBConfig.Password,
+		Database: coreConfig.DBConfig.Database,
+	})
+	if err != nil {
+		return err
+	}
+	callbacks.RegisterCustomCallbacks(db)
+
+	manager := managerparam.InitManager(db)
+	oauthManager := oauthmanager.NewManager(oauthdao.NewDAO(db), tokenstore.NewStore(db),
+		generator.NewAuthorizeGenerator(),
+		coreConfig.Oauth.AuthorizeCodeExpireIn,
+		coreConfig.Oauth.AccessTokenExpireIn,
+		coreConfig.Oauth.RefreshTokenExpireIn)
+	oauthManager.SetMaxTokenExpireTime(coreConfig.Oauth.MaxAccessTokenExpireIn,
+		coreConfig.Oauth.MaxRefreshTokenExpireIn)
+	oauthManager.SetDeviceCodeExpireTime(coreConfig.Oauth.Device.CodeExpireIn)
+
+	user, err := manager.UserMgr.GetUserByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	// the spec is applied entirely or not at all
+	tx, err := orm.BeginRequestTx(db)
+	if err != nil {
+		return err
+	}
+	ctx = orm.WithRequestTx(ctx, tx)
+	if err := bootstrap.New(manager, oauthManager, os.Stdout, dryRun).Apply(ctx, spec); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
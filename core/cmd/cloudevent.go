@@ -15,7 +15,7 @@
 package cmd
 
 import (
-	"fmt"
+	"context"
 	"log"
 
 	"github.com/gin-gonic/gin"
@@ -26,7 +26,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 )
 
-func runCloudEventServer(tektonFty factory.Factory, config server.Config,
+func runCloudEventServer(ctx context.Context, tektonFty factory.Factory, config server.Config,
 	parameter *param.Param, middlewares ...gin.HandlerFunc) {
 	r := gin.Default()
 	r.Use(middlewares...)
@@ -35,5 +35,7 @@ func runCloudEventServer(tektonFty factory.Factory, config server.Config,
 
 	cloudevent.RegisterRoutes(r, cloudevent.NewAPI(cloudEventCtl))
 
-	log.Fatal(r.Run(fmt.Sprintf(":%d", config.Port)))
+	if err := runServer(ctx, "cloud event server", r, config); err != nil {
+		log.Fatal(err)
+	}
 }
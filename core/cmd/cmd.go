@@ -19,40 +19,53 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"flag"
-	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"regexp"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/horizoncd/horizon/core/config"
 	accessctl "github.com/horizoncd/horizon/core/controller/access"
 	accesstokenctl "github.com/horizoncd/horizon/core/controller/accesstoken"
+	agentctl "github.com/horizoncd/horizon/core/controller/agent"
 	applicationctl "github.com/horizoncd/horizon/core/controller/application"
 	applicationregionctl "github.com/horizoncd/horizon/core/controller/applicationregion"
+	asynctaskctl "github.com/horizoncd/horizon/core/controller/asynctask"
+	auditlogctl "github.com/horizoncd/horizon/core/controller/auditlog"
 	"github.com/horizoncd/horizon/core/controller/build"
 	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
 	codectl "github.com/horizoncd/horizon/core/controller/code"
+	compliancectl "github.com/horizoncd/horizon/core/controller/compliance"
+	deploylockctl "github.com/horizoncd/horizon/core/controller/deploylock"
 	environmentctl "github.com/horizoncd/horizon/core/controller/environment"
 	environmentregionctl "github.com/horizoncd/horizon/core/controller/environmentregion"
 	envtemplatectl "github.com/horizoncd/horizon/core/controller/envtemplate"
 	eventctl "github.com/horizoncd/horizon/core/controller/event"
+	favoritectl "github.com/horizoncd/horizon/core/controller/favorite"
+	gitopscachectl "github.com/horizoncd/horizon/core/controller/gitopscache"
 	groupctl "github.com/horizoncd/horizon/core/controller/group"
 	idpctl "github.com/horizoncd/horizon/core/controller/idp"
 	memberctl "github.com/horizoncd/horizon/core/controller/member"
+	metadatactl "github.com/horizoncd/horizon/core/controller/metadata"
+	migrationctl "github.com/horizoncd/horizon/core/controller/migration"
+	namingctl "github.com/horizoncd/horizon/core/controller/naming"
+	notificationctl "github.com/horizoncd/horizon/core/controller/notification"
 	oauthservicectl "github.com/horizoncd/horizon/core/controller/oauth"
 	oauthappctl "github.com/horizoncd/horizon/core/controller/oauthapp"
 	oauthcheckctl "github.com/horizoncd/horizon/core/controller/oauthcheck"
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	pipelinestepctl "github.com/horizoncd/horizon/core/controller/pipelinestep"
+	quotactl "github.com/horizoncd/horizon/core/controller/quota"
 	regionctl "github.com/horizoncd/horizon/core/controller/region"
 	registryctl "github.com/horizoncd/horizon/core/controller/registry"
+	robotctl "github.com/horizoncd/horizon/core/controller/robot"
 	roltctl "github.com/horizoncd/horizon/core/controller/role"
 	scopectl "github.com/horizoncd/horizon/core/controller/scope"
+	searchctl "github.com/horizoncd/horizon/core/controller/search"
 	tagctl "github.com/horizoncd/horizon/core/controller/tag"
 	templatectl "github.com/horizoncd/horizon/core/controller/template"
 	templateschematagctl "github.com/horizoncd/horizon/core/controller/templateschematag"
@@ -63,6 +76,7 @@ import (
 	"github.com/horizoncd/horizon/core/http/api/v1/accesstoken"
 	"github.com/horizoncd/horizon/core/http/api/v1/application"
 	"github.com/horizoncd/horizon/core/http/api/v1/applicationregion"
+	"github.com/horizoncd/horizon/core/http/api/v1/auditlog"
 	"github.com/horizoncd/horizon/core/http/api/v1/cluster"
 	codeapi "github.com/horizoncd/horizon/core/http/api/v1/code"
 	"github.com/horizoncd/horizon/core/http/api/v1/environment"
@@ -79,23 +93,37 @@ import (
 	"github.com/horizoncd/horizon/core/http/api/v1/registry"
 	roleapi "github.com/horizoncd/horizon/core/http/api/v1/role"
 	"github.com/horizoncd/horizon/core/http/api/v1/scope"
+	"github.com/horizoncd/horizon/core/http/api/v1/search"
 	"github.com/horizoncd/horizon/core/http/api/v1/tag"
 	"github.com/horizoncd/horizon/core/http/api/v1/template"
 	accessv2 "github.com/horizoncd/horizon/core/http/api/v2/access"
 	accesstokenv2 "github.com/horizoncd/horizon/core/http/api/v2/accesstoken"
+	agentv2 "github.com/horizoncd/horizon/core/http/api/v2/agent"
 	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
+	asynctaskv2 "github.com/horizoncd/horizon/core/http/api/v2/asynctask"
 	clusterv2 "github.com/horizoncd/horizon/core/http/api/v2/cluster"
 	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	compliancev2 "github.com/horizoncd/horizon/core/http/api/v2/compliance"
+	deploylockv2 "github.com/horizoncd/horizon/core/http/api/v2/deploylock"
 	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
 	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
 	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
+	favoritev2 "github.com/horizoncd/horizon/core/http/api/v2/favorite"
+	gitopscachev2 "github.com/horizoncd/horizon/core/http/api/v2/gitopscache"
 	groupv2 "github.com/horizoncd/horizon/core/http/api/v2/group"
 	idpv2 "github.com/horizoncd/horizon/core/http/api/v2/idp"
 	memberv2 "github.com/horizoncd/horizon/core/http/api/v2/member"
+	metadatav2 "github.com/horizoncd/horizon/core/http/api/v2/metadata"
+	migrationv2 "github.com/horizoncd/horizon/core/http/api/v2/migration"
+	namingv2 "github.com/horizoncd/horizon/core/http/api/v2/naming"
+	notificationv2 "github.com/horizoncd/horizon/core/http/api/v2/notification"
 	oauthappv2 "github.com/horizoncd/horizon/core/http/api/v2/oauthapp"
 	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
+	pipelinestepv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinestep"
+	quotav2 "github.com/horizoncd/horizon/core/http/api/v2/quota"
 	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
 	registryv2 "github.com/horizoncd/horizon/core/http/api/v2/registry"
+	robotv2 "github.com/horizoncd/horizon/core/http/api/v2/robot"
 	rolev2 "github.com/horizoncd/horizon/core/http/api/v2/role"
 	scopev2 "github.com/horizoncd/horizon/core/http/api/v2/scope"
 	tagv2 "github.com/horizoncd/horizon/core/http/api/v2/tag"
@@ -107,23 +135,41 @@ import (
 	"github.com/horizoncd/horizon/core/middleware/auth"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	"github.com/horizoncd/horizon/lib/s3"
 	"github.com/horizoncd/horizon/pkg/cd"
 	clustermetrcis "github.com/horizoncd/horizon/pkg/cluster/metrics"
+	clustersnapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/eventhandler/csgenerator"
+	"github.com/horizoncd/horizon/pkg/gitopscache"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	"github.com/horizoncd/horizon/pkg/jobs"
+	jobartifactclean "github.com/horizoncd/horizon/pkg/jobs/artifactclean"
 	"github.com/horizoncd/horizon/pkg/jobs/autofree"
 	"github.com/horizoncd/horizon/pkg/jobs/clean"
+	jobclustersnapshot "github.com/horizoncd/horizon/pkg/jobs/clustersnapshot"
 	"github.com/horizoncd/horizon/pkg/jobs/eventhandler"
 	"github.com/horizoncd/horizon/pkg/jobs/grafanasync"
 	"github.com/horizoncd/horizon/pkg/jobs/k8sevent"
+	jobrecyclebin "github.com/horizoncd/horizon/pkg/jobs/recyclebin"
+	jobscheduleddeploy "github.com/horizoncd/horizon/pkg/jobs/scheduleddeploy"
+	jobtokenclean "github.com/horizoncd/horizon/pkg/jobs/tokenclean"
 	jobwebhook "github.com/horizoncd/horizon/pkg/jobs/webhook"
+	"github.com/horizoncd/horizon/pkg/manifestpolicy"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/migration"
+	"github.com/horizoncd/horizon/pkg/naming"
+	notificationservice "github.com/horizoncd/horizon/pkg/notification/service"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/server/route"
 	"github.com/horizoncd/horizon/pkg/token/generator"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
+	"github.com/horizoncd/horizon/pkg/trace"
 	"github.com/horizoncd/horizon/pkg/util/kube"
 	"github.com/horizoncd/horizon/pkg/workload"
 
@@ -135,39 +181,59 @@ import (
 	buildAPI "github.com/horizoncd/horizon/core/http/api/v2/build"
 	envtemplatev2 "github.com/horizoncd/horizon/core/http/api/v2/envtemplate"
 	templatev2 "github.com/horizoncd/horizon/core/http/api/v2/template"
+	"github.com/horizoncd/horizon/core/http/debug"
 	"github.com/horizoncd/horizon/core/http/health"
 	"github.com/horizoncd/horizon/core/http/metrics"
+	"github.com/horizoncd/horizon/core/http/openapi"
+	auditmiddle "github.com/horizoncd/horizon/core/middleware/audit"
+	corsmiddle "github.com/horizoncd/horizon/core/middleware/cors"
 	ginlogmiddle "github.com/horizoncd/horizon/core/middleware/ginlog"
+	idempotencymiddle "github.com/horizoncd/horizon/core/middleware/idempotency"
 	logmiddle "github.com/horizoncd/horizon/core/middleware/log"
 	metricsmiddle "github.com/horizoncd/horizon/core/middleware/metrics"
+	ormmiddle "github.com/horizoncd/horizon/core/middleware/orm"
 	prehandlemiddle "github.com/horizoncd/horizon/core/middleware/prehandle"
+	problemmiddle "github.com/horizoncd/horizon/core/middleware/problem"
+	ratelimitmiddle "github.com/horizoncd/horizon/core/middleware/ratelimit"
+	recentvisitmiddle "github.com/horizoncd/horizon/core/middleware/recentvisit"
 	regionmiddle "github.com/horizoncd/horizon/core/middleware/region"
 	tagmiddle "github.com/horizoncd/horizon/core/middleware/tag"
 	tokenmiddle "github.com/horizoncd/horizon/core/middleware/token"
+	tracemiddle "github.com/horizoncd/horizon/core/middleware/trace"
 	usermiddle "github.com/horizoncd/horizon/core/middleware/user"
 	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/agent"
 	"github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	gitlabconfig "github.com/horizoncd/horizon/pkg/config/gitlab"
 	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
-	"github.com/horizoncd/horizon/pkg/config/pprof"
 	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	"github.com/horizoncd/horizon/pkg/config/server"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	"github.com/horizoncd/horizon/pkg/oauth/oidc"
 	scopeservice "github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/pipelinestep"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
 	"github.com/horizoncd/horizon/pkg/rbac"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
+	templaterender "github.com/horizoncd/horizon/pkg/templaterelease/render"
 	templateschemarepo "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/templatesource"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
 
@@ -189,6 +255,11 @@ type Flags struct {
 	Dev                 bool
 	Environment         string
 	LogLevel            string
+	// ConfigReloadInterval is the interval to check the config file for changes, 0 disables reloading
+	ConfigReloadInterval time.Duration
+	// Migrate applies the pending schema migrations in MigrationsDir before serving
+	Migrate       bool
+	MigrationsDir string
 }
 
 type RegisterRouter interface {
@@ -223,6 +294,13 @@ func ParseFlags() *Flags {
 	flag.StringVar(
 		&flags.LogLevel, "loglevel", "info", "the loglevel(panic/fatal/error/warn/info/debug/trace))")
 
+	flag.DurationVar(&flags.ConfigReloadInterval, "config-reload-interval", 10*time.Second,
+		"the interval to check the configuration file for changes, 0 disables reloading")
+
+	flag.BoolVar(&flags.Migrate, "migrate", false, "if true, apply the pending schema migrations before serving")
+
+	flag.StringVar(&flags.MigrationsDir, "migrations", _defaultMigrationsDir, "schema migrations directory")
+
 	flag.Parse()
 	return &flags
 }
@@ -241,17 +319,6 @@ func InitLog(flags *Flags) {
 	logrus.SetLevel(level)
 }
 
-func runPProfServer(config *pprof.Config) {
-	if config.Enabled {
-		go func() {
-			if err := http.ListenAndServe(fmt.Sprintf(":%d", config.Port), nil); err != nil {
-				log.Printf("[pprof] failed to start, error: %s", err.Error())
-			}
-		}()
-		log.Printf("[pprof] Listening and serving HTTP on :%d", config.Port)
-	}
-}
-
 func LoadConfig(flags *Flags) (*config.Config, error) {
 	coreConfig, err := config.LoadConfig(flags.ConfigFile)
 	if err != nil {
@@ -264,6 +331,25 @@ func LoadConfig(flags *Flags) (*config.Config, error) {
 	return coreConfig, nil
 }
 
+// replicaMySQLs returns the replicas of dbConfig, which share the database and default to the primary's account
+func replicaMySQLs(coreConfig *config.Config) []orm.MySQL {
+	replicas := make([]orm.MySQL, 0, len(coreConfig.DBConfig.Replicas))
+	for _, replica := range coreConfig.DBConfig.Replicas {
+		username, password := replica.Username, replica.Password
+		if username == "" {
+			username, password = coreConfig.DBConfig.Username, coreConfig.DBConfig.Password
+		}
+		replicas = append(replicas, orm.MySQL{
+			Host:     replica.Host,
+			Port:     replica.Port,
+			Username: username,
+			Password: password,
+			Database: coreConfig.DBConfig.Database,
+		})
+	}
+	return replicas
+}
+
 func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	// init roles
 	file, err := os.OpenFile(flags.RoleConfigFile, os.O_RDONLY, 0644)
@@ -289,12 +375,35 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		Password:          coreConfig.DBConfig.Password,
 		Database:          coreConfig.DBConfig.Database,
 		PrometheusEnabled: coreConfig.DBConfig.PrometheusEnabled,
+		Replicas:          replicaMySQLs(coreConfig),
 	})
 	if err != nil {
 		panic(err)
 	}
 	callbacks.RegisterCustomCallbacks(mysqlDB)
 
+	// the migrations are applied before anything reads the database
+	migrationRunner, err := migration.NewRunner(mysqlDB, flags.MigrationsDir)
+	if err != nil {
+		if flags.Migrate {
+			panic(err)
+		}
+		log.Printf("schema migration status is unavailable: %v\n", err)
+	}
+	if flags.Migrate {
+		versions, err := migrationRunner.Up(ctx)
+		if err != nil {
+			panic(err)
+		}
+		log.Printf("applied %d schema migrations: %v\n", len(versions), versions)
+	}
+
+	// init tracing, the spans left are exported when the server stops
+	tracer, err := trace.Init(coreConfig.TraceConfig)
+	if err != nil {
+		panic(err)
+	}
+
 	redisClient := redis.NewClient(&redis.Options{
 		Network:  coreConfig.RedisConfig.Protocol,
 		Addr:     coreConfig.RedisConfig.Address,
@@ -318,10 +427,23 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	// init manager parameter
 	manager := managerparam.InitManager(mysqlDB)
 
-	gitlabGitops, err := gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
+	gitopsToken := gitlablib.NewToken(coreConfig.GitopsRepoConfig.Token)
+	gitlabGitops, err := gitlablib.NewWithToken(gitopsToken, coreConfig.GitopsRepoConfig.URL,
+		coreConfig.GitopsRepoConfig.Resilience)
 	if err != nil {
 		panic(err)
 	}
+	// files read from gitops repos are cached if enabled
+	var gitopsCache gitopscache.Store
+	switch cacheConfig := coreConfig.GitopsRepoConfig.Cache; cacheConfig.Backend {
+	case gitlabconfig.CacheBackendMemory:
+		gitopsCache = gitopscache.NewMemoryStore(cacheConfig.TTL, cacheConfig.Size)
+	case gitlabconfig.CacheBackendRedis:
+		gitopsCache = gitopscache.NewRedisStore(redisClient, "horizon:gitopscache:", cacheConfig.TTL)
+	}
+	if gitopsCache != nil {
+		gitlabGitops = gitopscache.NewGitlab(gitlabGitops, gitopsCache)
+	}
 	// check existence of gitops root group
 	rootGroupPath := coreConfig.GitopsRepoConfig.RootGroupPath
 	rootGroup, err := gitlabGitops.GetGroup(ctx, rootGroupPath)
@@ -334,10 +456,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		}
 	}
 
-	applicationGitRepo, err := gitrepo.NewApplicationGitlabRepo(ctx, gitlabGitops, gitrepo.ApplicationGitRepoConfig{
+	applicationGitRepo, err := gitrepo.NewApplicationGitRepo(ctx, gitlabGitops, gitrepo.ApplicationGitRepoConfig{
 		RootGroup:         rootGroup,
 		DefaultBranch:     coreConfig.GitopsRepoConfig.DefaultBranch,
 		DefaultVisibility: coreConfig.GitopsRepoConfig.DefaultVisibility,
+		Provider:          coreConfig.GitopsRepoConfig.ApplicationRepo,
 	})
 
 	if err != nil {
@@ -356,6 +479,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	}
 
 	templateSchemaGetter := templateschemarepo.NewSchemaGetter(ctx, templateRepo, manager)
+	templateValidationSvc := templatevalidation.NewService(templateSchemaGetter)
+	templateRenderer := templaterender.NewRenderer(templateRepo)
+	pipelineStepSvc := pipelinestepservice.NewService(pipelinestep.NewGetter(templateRepo, manager), manager)
 
 	outputGetter, err := output.NewOutPutGetter(ctx, templateRepo, manager)
 	if err != nil {
@@ -366,6 +492,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	if err != nil {
 		panic(err)
 	}
+	templateSources := templatesource.NewSources(gitGetter, coreConfig.TemplateSources)
 	tektonFty, err := factory.NewFactory(coreConfig.TektonMapper)
 	if err != nil {
 		panic(err)
@@ -378,8 +505,15 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		coreConfig.Oauth.AuthorizeCodeExpireIn,
 		coreConfig.Oauth.AccessTokenExpireIn,
 		coreConfig.Oauth.RefreshTokenExpireIn)
+	oauthManager.SetMaxTokenExpireTime(coreConfig.Oauth.MaxAccessTokenExpireIn,
+		coreConfig.Oauth.MaxRefreshTokenExpireIn)
+	oauthManager.SetDeviceCodeExpireTime(coreConfig.Oauth.Device.CodeExpireIn)
+	oidcProvider, err := oidc.NewProvider(coreConfig.Oauth.OIDC)
+	if err != nil {
+		panic(err)
+	}
 
-	roleService, err := role.NewFileRoleFrom2(context.TODO(), roleConfig)
+	roleService, err := role.NewCustomRoleService(context.TODO(), roleConfig, manager.CustomRoleMgr)
 	if err != nil {
 		panic(err)
 	}
@@ -437,6 +571,32 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	clusterSvc := clusterservice.NewService(applicationSvc, manager)
 	userSvc := userservice.NewService(manager)
 	tokenSvc := tokenservice.NewService(manager, coreConfig.TokenConfig)
+	namingSvc, err := naming.NewService(manager, coreConfig.NamingConfig)
+	if err != nil {
+		panic(err)
+	}
+	deployWindowSvc := deploywindow.NewService(manager, coreConfig.DeployWindowConfig, rbacAuthorizer)
+	manifestPolicySvc, err := manifestpolicy.NewService(coreConfig.ManifestPolicyConfig)
+	if err != nil {
+		panic(err)
+	}
+	metadataSvc, err := metadataservice.NewService(manager, coreConfig.MetadataConfig)
+	if err != nil {
+		panic(err)
+	}
+	snapshotSvc := clustersnapshotservice.NewService(manager)
+	// notifications are sent on the events recorded
+	notificationSvc, err := notificationservice.NewService(manager, coreConfig.NotificationConfig)
+	if err != nil {
+		panic(err)
+	}
+	if err := manager.EventMgr.Subscribe("notification", notificationSvc); err != nil {
+		panic(err)
+	}
+	asyncTaskSvc := asynctaskservice.NewService(manager)
+	// every server keeps the heartbeats of its own tasks, so it's not one of the jobs run by the leader
+	go asyncTaskSvc.Run(ctx)
+	quotaSvc := quotaservice.NewService(manager)
 
 	// init kube client
 	_, client, err := kube.BuildClient(coreConfig.KubeConfig)
@@ -445,12 +605,35 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	}
 
 	grafanaService := grafana.NewService(coreConfig.GrafanaConfig, manager, client)
+	// kube clients of regions are rate limited by config, init it before building any of them.
+	// requests to regions served by agents are forwarded by the agent hub
+	agentHub := agent.NewHub(coreConfig.AgentConfig)
+	kubeclient.Fty = kubeclient.NewFactory(coreConfig.KubeClientConfig, agentHub)
+	// artifacts and reports uploaded by pipelineruns are kept in s3 compatible storage if configured
+	var artifactStorage s3.Interface
+	if artifactConfig := coreConfig.ArtifactConfig; artifactConfig.Enabled() {
+		artifactStorage, err = s3.NewDriver(s3.Params{
+			AccessKey:        artifactConfig.Storage.AccessKey,
+			SecretKey:        artifactConfig.Storage.SecretKey,
+			Region:           artifactConfig.Storage.Region,
+			Endpoint:         artifactConfig.Storage.Endpoint,
+			Bucket:           artifactConfig.Storage.Bucket,
+			DisableSSL:       artifactConfig.Storage.DisableSSL,
+			SkipVerify:       artifactConfig.Storage.SkipVerify,
+			S3ForcePathStyle: artifactConfig.Storage.S3ForcePathStyle,
+			ContentType:      "application/octet-stream",
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
 	regionInformers := regioninformers.NewRegionInformers(manager.RegionMgr, 0)
 	regionInformers.Register(workload.Resources...)
 	go regionInformers.WatchRegion(ctx, 60*time.Second)
 	parameter := &param.Param{
 		Manager:              manager,
 		OauthManager:         oauthManager,
+		OIDCProvider:         oidcProvider,
 		AutoFreeSvc:          autoFreeSvc,
 		MemberService:        mservice,
 		ApplicationSvc:       applicationSvc,
@@ -463,23 +646,41 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		ScopeService:         scopeService,
 		ApplicationGitRepo:   applicationGitRepo,
 		TemplateSchemaGetter: templateSchemaGetter,
-		CD: cd.NewCD(regionInformers, clusterGitRepo, coreConfig.ArgoCDMapper,
-			coreConfig.GitopsRepoConfig.DefaultBranch),
-		K8sUtil:        cd.NewK8sUtil(regionInformers, manager.EventMgr),
-		OutputGetter:   outputGetter,
-		TektonFty:      tektonFty,
-		ClusterGitRepo: clusterGitRepo,
-		PRService:      prservice.NewService(manager),
-		GitGetter:      gitGetter,
-		GrafanaService: grafanaService,
-		BuildSchema:    buildSchema,
+		CD: cd.NewCD(manager.RegionMgr, regionInformers, clusterGitRepo, coreConfig.ArgoCDMapper,
+			coreConfig.FluxCD, coreConfig.GitopsRepoConfig.DefaultBranch, coreConfig.ArgoCDSyncConcurrency),
+		K8sUtil:           cd.NewK8sUtil(regionInformers, manager.EventMgr),
+		OutputGetter:      outputGetter,
+		TektonFty:         tektonFty,
+		ClusterGitRepo:    clusterGitRepo,
+		PRService:         prservice.NewService(manager),
+		GitGetter:         gitGetter,
+		GrafanaService:    grafanaService,
+		BuildSchema:       buildSchema,
+		NamingSvc:         namingSvc,
+		DeployWindowSvc:   deployWindowSvc,
+		SnapshotSvc:       snapshotSvc,
+		AsyncTaskSvc:      asyncTaskSvc,
+		ManifestPolicySvc: manifestPolicySvc,
+		MetadataSvc:       metadataSvc,
+		AgentHub:          agentHub,
+
+		TemplateValidationSvc: templateValidationSvc,
+		TemplateRenderer:      templateRenderer,
+		TemplateSources:       templateSources,
+		GitopsCache:           gitopsCache,
+		ArtifactStorage:       artifactStorage,
+		PipelineStepSvc:       pipelineStepSvc,
+		QuotaSvc:              quotaSvc,
 	}
 
 	var (
 		authnSkippers = []middleware.Skipper{
 			middleware.MethodAndPathSkipper("*",
-				regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
-					"(^/apis/core/v[12]/roles)|(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/access_token)")),
+				regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/ready)|(^/metrics)|(^/apis/login)|"+
+					"(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/access_token)|"+
+					"(^/login/oauth/revoke)|(^/login/oauth/device)|(^/login/oauth/userinfo)|(^/login/oauth/jwks)|(^/.well-known/)|"+
+					"(^/apis/swagger.json$)|(^/apis/docs$)")),
+			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/roles$")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/login/callback")),
 			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/logout")),
@@ -489,6 +690,27 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		authzSkippers = []middleware.Skipper{
 			middleware.MethodAndPathSkipper("*",
 				regexp.MustCompile("^/apis/core/v[12]/templates$")),
+			// tasks are not members of any resource, the controller checks their creators
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v2/tasks/[0-9]+$")),
+			// the export is not a member resource, the controller checks admins and auditors
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v2/compliance/export$")),
+			// the token audits are not member resources, the controller checks admins and auditors
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v2/oauthtokenaudits$")),
+			// the schema version is not a member resource, the controller checks admins and auditors
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v2/migrations$")),
+			// the audit logs are not member resources, the controller checks admins and auditors
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v1/auditlogs$")),
+			// the search is not a member resource, the controller filters out the resources invisible to the user
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v1/search$")),
+			// the custom roles are not member resources, the controller checks admins
+			middleware.MethodAndPathSkipper("*",
+				regexp.MustCompile("^/apis/core/v2/roles(/[^/]+)?$")),
 		}
 	)
 	authzSkippers = append(authzSkippers, authnSkippers...)
@@ -498,11 +720,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		memberCtl            = memberctl.NewController(parameter)
 		applicationCtl       = applicationctl.NewController(parameter)
 		envTemplateCtl       = envtemplatectl.NewController(parameter)
-		clusterCtl           = clusterctl.NewController(coreConfig, parameter)
+		clusterCtl           = clusterctl.NewController(coreConfig, parameter, rbacAuthorizer)
 		prCtl                = prctl.NewController(coreConfig, parameter)
 		templateCtl          = templatectl.NewController(parameter, templateRepo)
 		roleCtl              = roltctl.NewController(parameter)
-		terminalCtl          = terminalctl.NewController(parameter)
+		terminalCtl          = terminalctl.NewController(parameter, rbacAuthorizer)
 		codeGitCtl           = codectl.NewController(gitGetter)
 		tagCtl               = tagctl.NewController(parameter)
 		templateSchemaTagCtl = templateschematagctl.NewController(parameter)
@@ -510,21 +732,42 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		applicationRegionCtl = applicationregionctl.NewController(parameter)
 		groupCtl             = groupctl.NewController(parameter)
 		oauthCheckerCtl      = oauthcheckctl.NewOauthChecker(parameter)
-		oauthAppCtl          = oauthappctl.NewController(parameter)
+		oauthAppCtl          = oauthappctl.NewController(parameter, rbacAuthorizer)
 		oauthServerCtl       = oauthservicectl.NewController(parameter)
 		regionCtl            = regionctl.NewController(parameter)
 		userCtl              = userctl.NewController(parameter)
 		environmentCtl       = environmentctl.NewController(parameter)
 		environmentregionCtl = environmentregionctl.NewController(parameter)
 		registryCtl          = registryctl.NewController(parameter)
-		idpCtrl              = idpctl.NewController(parameter)
+		idpCtrl              = idpctl.NewController(coreConfig, parameter)
 		buildSchemaCtrl      = build.NewController(buildSchema)
 		accessTokenCtl       = accesstokenctl.NewController(parameter)
 		scopeCtl             = scopectl.NewController(parameter)
 		webhookCtl           = webhookctl.NewController(parameter)
 		eventCtl             = eventctl.NewController(parameter)
+		namingCtl            = namingctl.NewController(parameter)
+		metadataCtl          = metadatactl.NewController(parameter)
+		agentCtl             = agentctl.NewController(parameter)
+		deployLockCtl        = deploylockctl.NewController(parameter)
+		asyncTaskCtl         = asynctaskctl.NewController(parameter)
+		complianceCtl        = compliancectl.NewController(parameter)
+		migrationCtl         = migrationctl.NewController(migrationRunner)
+		auditLogCtl          = auditlogctl.NewController(parameter)
+		notificationCtl      = notificationctl.NewController(parameter)
+		robotCtl             = robotctl.NewController(parameter)
+		quotaCtl             = quotactl.NewController(parameter)
+		searchCtl            = searchctl.NewController(parameter)
+		favoriteCtl          = favoritectl.NewController(parameter)
+		gitopsCacheCtl       = gitopscachectl.NewController(coreConfig, parameter)
+		pipelineStepCtl      = pipelinestepctl.NewController(parameter)
 	)
 
+	// the limit changes on reload
+	oauthLimiter := ratelimitmiddle.NewLimiter(coreConfig.Oauth.RateLimit.Rate, coreConfig.Oauth.RateLimit.Burst)
+	clientLimiter := ratelimitmiddle.NewLimiter(coreConfig.Oauth.RateLimit.ClientRate,
+		coreConfig.Oauth.RateLimit.ClientBurst)
+	rateLimits := rateLimitClasses(coreConfig.RateLimitConfig, redisClient)
+
 	var (
 		// init v1 API
 		groupAPI             = group.NewAPI(groupCtl)
@@ -547,33 +790,49 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		accessAPI            = accessapi.NewAPI(accessCtl)
 		applicationRegionAPI = applicationregion.NewAPI(applicationRegionCtl)
 		oauthAppAPI          = oauthapp.NewAPI(oauthAppCtl)
-		oauthServerAPI       = oauthserver.NewAPI(oauthServerCtl, oauthAppCtl,
-			coreConfig.Oauth.OauthHTMLLocation, scopeService)
+		oauthServerAPI       = oauthserver.NewAPI(oauthServerCtl,
+			coreConfig.Oauth.OauthHTMLLocation, coreConfig.Oauth.Device,
+			ratelimitmiddle.Middleware(oauthLimiter, clientLimiter))
 		idpAPI         = idp.NewAPI(idpCtrl, store)
 		accessTokenAPI = accesstoken.NewAPI(accessTokenCtl, roleService, scopeService)
 		scopeAPI       = scope.NewAPI(scopeCtl)
 		webhookAPI     = webhook.NewAPI(webhookCtl)
 		eventAPI       = event.NewAPI(eventCtl)
+		auditLogAPI    = auditlog.NewAPI(auditLogCtl)
+		searchAPI      = search.NewAPI(searchCtl)
 
 		// init v2 API
 		accessAPIV2            = accessv2.NewAPI(accessCtl)
 		accessTokenAPIV2       = accesstokenv2.NewAPI(accessTokenCtl, roleService, scopeService)
+		agentAPIV2             = agentv2.NewAPI(agentCtl)
 		applicationAPIV2       = appv2.NewAPI(applicationCtl)
 		applicationRegionAPIV2 = applicationregionv2.NewAPI(applicationRegionCtl)
+		asyncTaskAPIV2         = asynctaskv2.NewAPI(asyncTaskCtl)
 		buildSchemaAPI         = buildAPI.NewAPI(buildSchemaCtrl)
 		clusterAPIV2           = clusterv2.NewAPI(clusterCtl)
+		complianceAPIV2        = compliancev2.NewAPI(complianceCtl)
 		codeGitAPIV2           = codev2.NewAPI(codeGitCtl)
+		deployLockAPIV2        = deploylockv2.NewAPI(deployLockCtl)
 		environmentAPIV2       = environmentv2.NewAPI(environmentCtl)
 		environmentRegionAPIV2 = environmentregionv2.NewAPI(environmentregionCtl)
 		envtemplateAPIV2       = envtemplatev2.NewAPI(envTemplateCtl)
 		eventAPIV2             = eventv2.NewAPI(eventCtl)
+		favoriteAPIV2          = favoritev2.NewAPI(favoriteCtl)
+		gitopsCacheAPIV2       = gitopscachev2.NewAPI(gitopsCacheCtl)
 		groupAPIV2             = groupv2.NewAPI(groupCtl)
 		idpAPIV2               = idpv2.NewAPI(idpCtrl, store)
 		memberAPIV2            = memberv2.NewAPI(memberCtl, roleService)
+		metadataAPIV2          = metadatav2.NewAPI(metadataCtl)
+		migrationAPIV2         = migrationv2.NewAPI(migrationCtl)
+		namingAPIV2            = namingv2.NewAPI(namingCtl)
+		notificationAPIV2      = notificationv2.NewAPI(notificationCtl)
 		oauthAppAPIV2          = oauthappv2.NewAPI(oauthAppCtl)
 		pipelinerunAPIV2       = pipelinerunv2.NewAPI(prCtl)
+		pipelineStepAPIV2      = pipelinestepv2.NewAPI(pipelineStepCtl)
+		quotaAPIV2             = quotav2.NewAPI(quotaCtl)
 		regionAPIV2            = regionv2.NewAPI(regionCtl, tagCtl)
 		registryAPIV2          = registryv2.NewAPI(registryCtl)
+		robotAPIV2             = robotv2.NewAPI(robotCtl)
 		roleAPIV2              = rolev2.NewAPI(roleCtl)
 		scopeAPIV2             = scopev2.NewAPI(scopeCtl)
 		tagAPIV2               = tagv2.NewAPI(tagCtl)
@@ -587,53 +846,163 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	// start jobs
 	cleaner := clean.New(coreConfig.Clean, manager)
 	autoFreeJob := func(ctx context.Context) {
-		autofree.Run(ctx, &coreConfig.AutoFreeConfig, manager.UserMgr, clusterCtl, prCtl)
+		autofree.Run(ctx, &coreConfig.AutoFreeConfig, manager.UserMgr, eventSvc, clusterCtl, prCtl)
 	}
 	eventHandlerJob, eventHandlerSvc := eventhandler.New(ctx, coreConfig.EventHandlerConfig, manager)
 	webhookJob, _ := jobwebhook.New(ctx, eventHandlerSvc, coreConfig.WebhookConfig, manager)
+	if err := eventHandlerSvc.RegisterEventHandler("clusterSummary",
+		csgenerator.NewClusterSummaryGenerator(manager)); err != nil {
+		panic(err)
+	}
+	// the groups are warned as their usages of quotas reach the soft thresholds
+	if err := eventHandlerSvc.RegisterEventHandler("quota", quotaSvc); err != nil {
+		panic(err)
+	}
 	grafanaSyncJob := func(ctx context.Context) {
-		grafanasync.Run(ctx, coreConfig, manager, client)
+		grafanasync.Run(ctx, grafanaService)
+	}
+	clusterSnapshotJob := func(ctx context.Context) {
+		jobclustersnapshot.Run(ctx, &coreConfig.ClusterSnapshotConfig, manager, clusterGitRepo, snapshotSvc)
+	}
+	tokenCleanJob := func(ctx context.Context) {
+		jobtokenclean.Run(ctx, &coreConfig.TokenCleanConfig, manager.TokenMgr)
+	}
+	recycleBinJob := func(ctx context.Context) {
+		jobrecyclebin.Run(ctx, &coreConfig.RecycleBinConfig, manager, applicationGitRepo, clusterGitRepo)
+	}
+	scheduledDeployJob := func(ctx context.Context) {
+		jobscheduleddeploy.Run(ctx, &coreConfig.ScheduledDeployConfig, manager, rbacAuthorizer, clusterCtl)
+	}
+	artifactCleanJob := func(ctx context.Context) {
+		if artifactStorage != nil {
+			jobartifactclean.Run(ctx, &coreConfig.ArtifactConfig.Retention, manager.ArtifactMgr, artifactStorage)
+		}
 	}
 	k8seventJob := k8sevent.New(coreConfig.KubernetesEvent, regionInformers, manager, mysqlDB)
-	go jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob,
-		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob)
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob,
+			k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob, clusterSnapshotJob, tokenCleanJob,
+			recycleBinJob, scheduledDeployJob, artifactCleanJob)
+	}()
+
+	// apply the changes of config file without a restart
+	if flags.ConfigReloadInterval > 0 {
+		go watchConfig(ctx, flags, reloadables{
+			gitopsToken:    gitopsToken,
+			oauthLimiter:   oauthLimiter,
+			clientLimiter:  clientLimiter,
+			rateLimits:     rateLimits,
+			grafanaService: grafanaService,
+		})
+	}
 
 	// init server
 	r := gin.New()
+	// the client ip is taken from X-Forwarded-For only if the request comes from a trusted proxy,
+	// otherwise anyone can spoof the ip to escape the rate limits
+	if err := r.SetTrustedProxies(coreConfig.ServerConfig.TrustedProxies); err != nil {
+		panic(err)
+	}
 	// use middleware
 	middlewares := []gin.HandlerFunc{
-		ginlogmiddle.Middleware(gin.DefaultWriter, "/health", "/metrics"),
+		ginlogmiddle.Middleware(gin.DefaultWriter, "/health", "/ready", "/metrics"),
 		gin.Recovery(),
 		requestid.Middleware(), // requestID middleware, attach a requestID to context
-		logmiddle.Middleware(), // log middleware, attach a logger to context
+		// problem middleware, respond the errors of api v2 as problem details to the clients accepting them
+		problemmiddle.Middleware(problemmiddle.V2Paths),
+		// cors middleware, answer the preflight requests of the console hosted on other domains
+		corsmiddle.Middleware(coreConfig.CORSConfig,
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/ready")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics"))),
+		// trace middleware, attach the span of the request to context
+		tracemiddle.Middleware(coreConfig.TraceConfig.ServiceName,
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/ready")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics"))),
+		tracemiddle.TraceIDMiddleware(), // respond the trace id of the request
+		logmiddle.Middleware(),          // log middleware, attach a logger to context
 
 		metricsmiddle.Middleware( // metrics middleware
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/ready")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics"))),
 		regionmiddle.Middleware(parameter, applicationRegionCtl),
 		tokenmiddle.MiddleWare(oauthCheckerCtl, authnSkippers...),
 		//  user middleware, check user and attach current user to context.
 		usermiddle.Middleware(parameter, store, coreConfig,
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/ready")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v1/terminal")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v2/buildschema")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/access_token")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/revoke")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/device/code")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/userinfo")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/jwks")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/.well-known/")),
+			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/swagger.json$")),
+			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/docs$")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/internal/v2/.*")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
 			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login"))),
+		// rate limit middleware, limit the requests of each user and each source ip.
+		// agents poll by long requests, which are limited by their poll timeout
+		ratelimitmiddle.APIMiddleware(rateLimits,
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/ready")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/internal/v2/agents/"))),
 		prehandlemiddle.Middleware(r, manager),
+		// audit middleware, record mutating requests, including the ones denied by auth middleware.
+		// agents poll by posting, and oauth tokens are audited by the oauth server
+		auditmiddle.Middleware(manager.AuditLogMgr,
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/internal/")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/"))),
 		auth.Middleware(rbacAuthorizer, authzSkippers...),
+		// recent visit middleware, record the details of applications and clusters viewed by the users
+		recentvisitmiddle.Middleware(manager.RecentVisitMgr),
+		// idempotency middleware, replay the responses of the creations retried with the same Idempotency-Key
+		idempotencymiddle.Middleware(idempotencymiddle.NewRedisStore(redisClient, "horizon:idempotency:"),
+			regexp.MustCompile("^/apis/core/v[12]/((groups/[^/]+/applications)|"+
+				"(applications/[^/]+/clusters)|(clusters/[^/]+/builddeploy))$")),
 		tagmiddle.Middleware(), // tag middleware, parse and attach tagSelector to context
 	}
+	if len(coreConfig.DBConfig.Replicas) > 0 {
+		// orm middleware, route the queries of read-only requests to replicas
+		middlewares = append(middlewares, ormmiddle.ReplicaMiddleware())
+	}
+	if coreConfig.DBConfig.RequestTransaction {
+		// orm middleware, run the mutating requests of the routes opting in by Transactional in a transaction
+		route.UseTransactionMiddleware(ormmiddle.Middleware(mysqlDB))
+	}
 	r.Use(middlewares...)
 
 	gin.ForceConsoleColor()
 
 	// register routes
 	health.RegisterRoutes(r)
+	health.RegisterReadinessRoutes(r, coreConfig.ServerConfig.ReadinessTimeout,
+		readinessComponents(coreConfig, mysqlDB, gitlabGitops, client)...)
 	clustermetrcis.NewMetrics(manager)
 	metrics.RegisterRoutes(r)
+	openapi.RegisterRoutes(r)
+	if coreConfig.ServerConfig.Debug.Enabled {
+		debug.RegisterRoutes(r)
+	}
+	if coreConfig.ServerConfig.Debug.Port != 0 {
+		go func() {
+			if err := runServer(ctx, "debug server", debug.Handler(), server.Config{
+				Port:            coreConfig.ServerConfig.Debug.Port,
+				ShutdownTimeout: coreConfig.ServerConfig.ShutdownTimeout,
+			}); err != nil {
+				log.Printf("debug server failed: %v", err)
+			}
+		}()
+	}
 
 	// v1
 	registerV1Group := []RegisterRouter{
@@ -663,6 +1032,8 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		scopeAPI,
 		webhookAPI,
 		eventAPI,
+		auditLogAPI,
+		searchAPI,
 	}
 
 	// v2
@@ -670,21 +1041,34 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		groupAPIV2,
 		accessAPIV2,
 		accessTokenAPIV2,
+		agentAPIV2,
 		applicationAPIV2,
 		applicationRegionAPIV2,
+		asyncTaskAPIV2,
 		buildSchemaAPI,
 		clusterAPIV2,
+		complianceAPIV2,
 		codeGitAPIV2,
+		deployLockAPIV2,
 		environmentAPIV2,
 		environmentRegionAPIV2,
 		envtemplateAPIV2,
 		eventAPIV2,
+		favoriteAPIV2,
+		gitopsCacheAPIV2,
 		idpAPIV2,
 		memberAPIV2,
+		metadataAPIV2,
+		migrationAPIV2,
+		namingAPIV2,
+		notificationAPIV2,
 		oauthAppAPIV2,
 		pipelinerunAPIV2,
+		pipelineStepAPIV2,
+		quotaAPIV2,
 		regionAPIV2,
 		registryAPIV2,
+		robotAPIV2,
 		roleAPIV2,
 		scopeAPIV2,
 		tagAPIV2,
@@ -696,27 +1080,59 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	}
 
 	// start cloud event server
-	go runCloudEventServer(
-		tektonFty,
-		coreConfig.CloudEventServerConfig,
-		parameter,
-		ginlogmiddle.Middleware(gin.DefaultWriter, "/health", "/metrics"),
-		requestid.Middleware(),
-	)
+	cloudEventServerDone := make(chan struct{})
+	go func() {
+		defer close(cloudEventServerDone)
+		runCloudEventServer(ctx,
+			tektonFty,
+			coreConfig.CloudEventServerConfig,
+			parameter,
+			ginlogmiddle.Middleware(gin.DefaultWriter, "/health", "/ready", "/metrics"),
+			requestid.Middleware(),
+			// events update pipelineruns and read them back
+			ormmiddle.ReplicaMiddleware(),
+		)
+	}()
 	// merge routes
 	registerAll := append(registerV1Group, registerV2Group...)
 	for _, register := range registerAll {
 		register.RegisterRoute(r)
 	}
 
-	// start api server
+	// start api server, it returns once drained on SIGTERM or SIGINT
 	log.Printf("Server started")
-	log.Print(r.Run(fmt.Sprintf(":%d", coreConfig.ServerConfig.Port)))
+	if err := runServer(ctx, "api server", r, coreConfig.ServerConfig); err != nil {
+		log.Print(err)
+	}
+
+	// wait for the jobs, the cloud event server and the async tasks to stop before closing the connections they use
+	if !waitUntil(jobsDone, coreConfig.ServerConfig.ShutdownTimeout) {
+		log.Printf("jobs did not stop in %v", coreConfig.ServerConfig.ShutdownTimeout)
+	}
+	if !waitUntil(cloudEventServerDone, coreConfig.CloudEventServerConfig.ShutdownTimeout) {
+		log.Printf("cloud event server did not stop in %v", coreConfig.CloudEventServerConfig.ShutdownTimeout)
+	}
+	asyncTaskSvc.Drain(coreConfig.ServerConfig.ShutdownTimeout)
+	if sqlDB, err := mysqlDB.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("failed to close mysql connections: %v", err)
+		}
+	}
+	if err := redisClient.Close(); err != nil {
+		log.Printf("failed to close redis connections: %v", err)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), coreConfig.ServerConfig.ShutdownTimeout)
+	defer cancel()
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to export the spans left: %v", err)
+	}
+	log.Printf("Server stopped")
 }
 
-// Run runs the agent.
+// Run runs the core server until SIGTERM or SIGINT is caught.
 func Run(flags *Flags) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
 
 	setTasksBeforeExit(cancelFunc)
 
@@ -725,34 +1141,29 @@ func Run(flags *Flags) {
 		panic(err)
 	}
 
-	// enable pprof
-	runPProfServer(&configs.PProf)
-
-	// init log
+	// init log, the level of config file takes precedence
+	if configs.LogLevel != "" {
+		flags.LogLevel = configs.LogLevel
+	}
 	InitLog(flags)
 
 	// init api
 	Init(ctx, flags, configs)
 }
 
-// setTasksBeforeExit set stop funcs which will be executed after sigterm and sigint catched
+// setTasksBeforeExit set stop funcs which will be executed after sigterm and sigint catched,
+// a second signal exits at once without waiting for them
 func setTasksBeforeExit(stopFuncs ...func()) {
-	sig := make(chan os.Signal, 1)
+	sig := make(chan os.Signal, 2)
 	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
 	go func() {
 		s := <-sig
 		log.Printf("got %s signal, stop tasks...\n", s)
-		if len(stopFuncs) == 0 {
-			return
-		}
-		wg := sync.WaitGroup{}
-		wg.Add(len(stopFuncs))
-		for _, stopFunc := range stopFuncs {
-			go func(stop func()) {
-				stop()
-			}(stopFunc)
+		for _, stop := range stopFuncs {
+			stop()
 		}
-		wg.Wait()
-		log.Printf("all tasks stopped, exit now.")
+		s = <-sig
+		log.Printf("got %s signal again, exit now.", s)
+		os.Exit(1)
 	}()
 }
@@ -0,0 +1,320 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/config"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	"github.com/horizoncd/horizon/lib/orm"
+	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+)
+
+const (
+	DoctorCommand = "doctor"
+
+	_doctorTimeout = 10 * time.Second
+)
+
+type doctor struct {
+	out      io.Writer
+	client   *http.Client
+	checks   int
+	failures int
+}
+
+// check runs f and prints its result, hint is printed to help fixing the failure
+func (d *doctor) check(name, hint string, f func() (string, error)) bool {
+	d.checks++
+	detail, err := f()
+	if err != nil {
+		d.failures++
+		fmt.Fprintf(d.out, "[FAIL] %s: %v\n", name, err)
+		if hint != "" {
+			fmt.Fprintf(d.out, "       hint: %s\n", hint)
+		}
+		return false
+	}
+	fmt.Fprintf(d.out, "[ OK ] %s: %s\n", name, detail)
+	return true
+}
+
+// RunDoctor validates configuration and the systems horizon depends on,
+// prints diagnostics for each of them and returns the exit code
+func RunDoctor(args []string) int {
+	var flags Flags
+	fs := flag.NewFlagSet(DoctorCommand, flag.ExitOnError)
+	fs.StringVar(&flags.ConfigFile, "config", "", "configuration file path")
+	fs.StringVar(&flags.RoleConfigFile, "roles", "", "roles file path")
+	fs.StringVar(&flags.ScopeRoleFile, "scopes", "", "scopes file path")
+	_ = fs.Parse(args)
+
+	d := &doctor{
+		out:    os.Stdout,
+		client: &http.Client{Timeout: _doctorTimeout},
+	}
+	d.diagnose(context.Background(), &flags)
+
+	if d.failures > 0 {
+		fmt.Fprintf(d.out, "\n%d of %d checks failed\n", d.failures, d.checks)
+		return 1
+	}
+	fmt.Fprintf(d.out, "\nall %d checks passed\n", d.checks)
+	return 0
+}
+
+func (d *doctor) diagnose(ctx context.Context, flags *Flags) {
+	var coreConfig *config.Config
+	if !d.check("config", "pass the configuration file by -config and check its yaml syntax",
+		func() (string, error) {
+			var err error
+			coreConfig, err = config.LoadConfig(flags.ConfigFile)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("loaded %s", flags.ConfigFile), nil
+		}) {
+		return
+	}
+
+	if flags.RoleConfigFile != "" {
+		d.check("roles", "check the yaml syntax of the roles file", func() (string, error) {
+			var roleConfig roleconfig.Config
+			return d.parseYAMLFile(flags.RoleConfigFile, &roleConfig)
+		})
+	}
+	if flags.ScopeRoleFile != "" {
+		d.check("scopes", "check the yaml syntax of the scopes file", func() (string, error) {
+			var scopes oauthconfig.Scopes
+			return d.parseYAMLFile(flags.ScopeRoleFile, &scopes)
+		})
+	}
+
+	var db *gorm.DB
+	d.check("database", "check host, port, username, password and database in dbConfig, "+
+		"and make sure the schema in db/ has been imported",
+		func() (string, error) {
+			var err error
+			db, err = orm.NewMySQLDB(&orm.MySQL{
+				Host:     coreConfig.DBConfig.Host,
+				Port:     coreConfig.DBConfig.Port,
+				Username: coreConfig.DBConfig.Username,
+				Password: coreConfig.DBConfig.Password,
+				Database: coreConfig.DBConfig.Database,
+			})
+			if err != nil {
+				return "", err
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				return "", err
+			}
+			pingCtx, cancel := context.WithTimeout(ctx, _doctorTimeout)
+			defer cancel()
+			if err := sqlDB.PingContext(pingCtx); err != nil {
+				db = nil
+				return "", err
+			}
+			return fmt.Sprintf("connected to %s:%d/%s", coreConfig.DBConfig.Host,
+				coreConfig.DBConfig.Port, coreConfig.DBConfig.Database), nil
+		})
+
+	d.check("gitops repo", "check url and token in gitopsRepoConfig, "+
+		"the token needs the api scope and access to rootGroupPath",
+		func() (string, error) {
+			gitlabGitops, err := gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
+			if err != nil {
+				return "", err
+			}
+			group, err := gitlabGitops.GetGroup(ctx, coreConfig.GitopsRepoConfig.RootGroupPath)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("found root group %s", group.FullPath), nil
+		})
+
+	for _, repo := range coreConfig.CodeGitRepos {
+		repo := repo
+		d.check(fmt.Sprintf("code repo %s", repo.URL), "check url and token of the repo in gitRepos",
+			func() (string, error) {
+				if repo.Kind != "gitlab" {
+					return fmt.Sprintf("skipped, kind %s is not checked", repo.Kind), nil
+				}
+				return d.get(ctx, strings.TrimSuffix(repo.URL, "/")+"/api/v4/user",
+					map[string]string{"PRIVATE-TOKEN": repo.Token})
+			})
+	}
+
+	for env, argoCD := range coreConfig.ArgoCDMapper {
+		argoCD := argoCD
+		d.check(fmt.Sprintf("argocd for %s", env), "check url and token of the environment in argoCDMapper",
+			func() (string, error) {
+				return d.get(ctx, strings.TrimSuffix(argoCD.URL, "/")+"/api/version",
+					map[string]string{"Authorization": "Bearer " + argoCD.Token})
+			})
+	}
+
+	if coreConfig.KubeConfig != "" {
+		d.check("kubeconfig", "check the kubeconfig file and whether the cluster is reachable",
+			func() (string, error) {
+				_, client, err := kube.BuildClient(coreConfig.KubeConfig)
+				if err != nil {
+					return "", err
+				}
+				version, err := client.Discovery().ServerVersion()
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("kubernetes %s", version.GitVersion), nil
+			})
+	}
+
+	if db == nil {
+		return
+	}
+	manager := managerparam.InitManager(db)
+	d.diagnoseRegions(ctx, manager, coreConfig)
+	d.diagnoseIdps(ctx, manager)
+}
+
+func (d *doctor) diagnoseRegions(ctx context.Context, manager *managerparam.Manager, coreConfig *config.Config) {
+	regions, err := manager.RegionMgr.ListAll(ctx)
+	if err != nil {
+		d.check("regions", "", func() (string, error) { return "", err })
+		return
+	}
+	for _, region := range regions {
+		if region.Disabled {
+			continue
+		}
+		region := region
+		if _, ok := coreConfig.AgentConfig.Regions[region.Name]; ok {
+			d.check(fmt.Sprintf("region %s", region.Name), "", func() (string, error) {
+				return "skipped, the region is served by agent", nil
+			})
+			continue
+		}
+		d.check(fmt.Sprintf("region %s", region.Name),
+			"update the kubeconfig of the region, and make sure its server is reachable from horizon",
+			func() (string, error) {
+				if region.Certificate == "" {
+					return "", fmt.Errorf("kubeconfig of region is empty")
+				}
+				_, client, err := kube.BuildClientFromContent(region.Certificate)
+				if err != nil {
+					return "", err
+				}
+				version, err := client.Basic.Discovery().ServerVersion()
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("kubernetes %s", version.GitVersion), nil
+			})
+	}
+}
+
+func (d *doctor) diagnoseIdps(ctx context.Context, manager *managerparam.Manager) {
+	idps, err := manager.IdpMgr.List(ctx)
+	if err != nil {
+		d.check("identity providers", "", func() (string, error) { return "", err })
+		return
+	}
+	for _, idp := range idps {
+		if idp.Issuer == "" {
+			continue
+		}
+		idp := idp
+		d.check(fmt.Sprintf("oidc issuer of %s", idp.Name),
+			"the issuer must serve /.well-known/openid-configuration and match the configured issuer exactly",
+			func() (string, error) {
+				discovery := strings.TrimSuffix(idp.Issuer, "/") + "/.well-known/openid-configuration"
+				body, err := d.fetch(ctx, discovery, nil)
+				if err != nil {
+					return "", err
+				}
+				var metadata struct {
+					Issuer string `json:"issuer"`
+				}
+				if err := json.Unmarshal(body, &metadata); err != nil {
+					return "", fmt.Errorf("invalid discovery document: %v", err)
+				}
+				if metadata.Issuer != idp.Issuer {
+					return "", fmt.Errorf("issuer in discovery document is %s, expected %s",
+						metadata.Issuer, idp.Issuer)
+				}
+				return fmt.Sprintf("discovered %s", discovery), nil
+			})
+	}
+}
+
+func (d *doctor) parseYAMLFile(file string, out interface{}) (string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	if err := yaml.Unmarshal(content, out); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("loaded %s", file), nil
+}
+
+func (d *doctor) get(ctx context.Context, url string, headers map[string]string) (string, error) {
+	if _, err := d.fetch(ctx, url, headers); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("reached %s", url), nil
+}
+
+func (d *doctor) fetch(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("token is rejected with status %d", resp.StatusCode)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return body, nil
+}
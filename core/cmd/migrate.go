@@ -0,0 +1,111 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/horizoncd/horizon/core/config"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/migration"
+)
+
+const (
+	MigrateCommand = "migrate"
+
+	_defaultMigrationsDir = "db/migrations"
+)
+
+// RunMigrate applies the pending schema migrations, prints the schema version with -status,
+// or marks the migrations up to a version as applied with -baseline. It returns the exit code.
+func RunMigrate(args []string) int {
+	var (
+		configFile string
+		dir        string
+		baseline   string
+		status     bool
+	)
+	fs := flag.NewFlagSet(MigrateCommand, flag.ExitOnError)
+	fs.StringVar(&configFile, "config", "", "configuration file path")
+	fs.StringVar(&dir, "migrations", _defaultMigrationsDir, "schema migrations directory")
+	fs.StringVar(&baseline, "baseline", "",
+		"mark the migrations up to the version as applied without running them, "+
+			"for the databases imported from db/ by hand")
+	fs.BoolVar(&status, "status", false, "if true, only print the schema version and the pending migrations")
+	_ = fs.Parse(args)
+
+	if err := runMigrate(context.Background(), configFile, dir, baseline, status); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runMigrate(ctx context.Context, configFile, dir, baseline string, status bool) error {
+	coreConfig, err := config.LoadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	db, err := orm.NewMySQLDB(&orm.MySQL{
+		Host:     coreConfig.DBConfig.Host,
+		Port:     coreConfig.DBConfig.Port,
+		Username: coreConfig.DBConfig.Username,
+		Password: coreConfig.DBConfig.Password,
+		Database: coreConfig.DBConfig.Database,
+	})
+	if err != nil {
+		return err
+	}
+	runner, err := migration.NewRunner(db, dir)
+	if err != nil {
+		return err
+	}
+
+	if status {
+		s, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		current := s.Current
+		if current == "" {
+			current = "none"
+		}
+		fmt.Printf("current version: %s, %d applied\n", current, s.Applied)
+		for _, version := range s.Pending {
+			fmt.Printf("pending: %s\n", version)
+		}
+		return nil
+	}
+
+	var versions []string
+	if baseline != "" {
+		versions, err = runner.Baseline(ctx, baseline)
+	} else {
+		versions, err = runner.Up(ctx)
+	}
+	for _, version := range versions {
+		fmt.Printf("applied: %s\n", version)
+	}
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Println("the schema is up to date")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	ratelimitmiddle "github.com/horizoncd/horizon/core/middleware/ratelimit"
+	"github.com/horizoncd/horizon/pkg/config/ratelimit"
+)
+
+const _rateLimitRedisPrefix = "horizon:ratelimit:"
+
+// rateLimitClasses builds the classes limiting api requests, the routes configured come first
+// and the default class matching all requests comes last
+func rateLimitClasses(config ratelimit.Config, redisClient *redis.Client) []*ratelimitmiddle.Class {
+	newAllower := func(name, kind string, limit ratelimit.Limit) ratelimitmiddle.Allower {
+		if config.Backend == ratelimit.BackendRedis {
+			return ratelimitmiddle.NewRedisLimiter(redisClient,
+				fmt.Sprintf("%s%s:%s:", _rateLimitRedisPrefix, name, kind), limit.Rate, limit.Burst)
+		}
+		return ratelimitmiddle.NewLimiter(limit.Rate, limit.Burst)
+	}
+
+	classes := make([]*ratelimitmiddle.Class, 0, len(config.Routes)+1)
+	for _, route := range config.Routes {
+		methods := make(map[string]bool, len(route.Methods))
+		for _, method := range route.Methods {
+			methods[strings.ToUpper(method)] = true
+		}
+		classes = append(classes, &ratelimitmiddle.Class{
+			Name:    route.Name,
+			Methods: methods,
+			// validated on loading
+			Path: regexp.MustCompile(route.Path),
+			User: newAllower(route.Name, "user", route.User),
+			IP:   newAllower(route.Name, "ip", route.IP),
+		})
+	}
+	return append(classes, &ratelimitmiddle.Class{
+		Name: ratelimit.DefaultRoute,
+		User: newAllower(ratelimit.DefaultRoute, "user", config.User),
+		IP:   newAllower(ratelimit.DefaultRoute, "ip", config.IP),
+	})
+}
+
+// setRateLimits applies the limits reloaded to the classes, the routes added or removed
+// take effect on the next start
+func setRateLimits(classes []*ratelimitmiddle.Class, config ratelimit.Config) {
+	routes := make(map[string]ratelimit.Route, len(config.Routes)+1)
+	for _, route := range config.Routes {
+		routes[route.Name] = route
+	}
+	routes[ratelimit.DefaultRoute] = ratelimit.Route{User: config.User, IP: config.IP}
+	for _, class := range classes {
+		route, ok := routes[class.Name]
+		if !ok {
+			continue
+		}
+		class.User.SetLimit(route.User.Rate, route.User.Burst)
+		class.IP.SetLimit(route.IP.Rate, route.IP.Burst)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/horizoncd/horizon/core/config"
+	"github.com/horizoncd/horizon/core/http/health"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+)
+
+// readinessComponents are the dependencies checked by /ready
+func readinessComponents(coreConfig *config.Config, db *gorm.DB, gitlabGitops gitlablib.Interface,
+	kubeClient kubernetes.Interface) []health.Component {
+	components := []health.Component{
+		{
+			Name: "mysql",
+			Check: func(ctx context.Context) error {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			},
+		},
+		{
+			Name: "gitlab",
+			Check: func(ctx context.Context) error {
+				_, err := gitlabGitops.GetGroup(ctx, coreConfig.GitopsRepoConfig.RootGroupPath)
+				return err
+			},
+		},
+		{
+			Name: "kubernetes",
+			Check: func(ctx context.Context) error {
+				return kubeClient.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+			},
+		},
+	}
+
+	// environments share argoCD mostly, each argoCD is checked once
+	argoCDTokens := make(map[string]string)
+	for _, argoCD := range coreConfig.ArgoCDMapper {
+		argoCDTokens[strings.TrimSuffix(argoCD.URL, "/")] = argoCD.Token
+	}
+	argoCDURLs := make([]string, 0, len(argoCDTokens))
+	for url := range argoCDTokens {
+		argoCDURLs = append(argoCDURLs, url)
+	}
+	sort.Strings(argoCDURLs)
+	// the same as the argoCD client
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // nolint
+		},
+	}
+	for _, url := range argoCDURLs {
+		url, token := url, argoCDTokens[url]
+		components = append(components, health.Component{
+			Name: fmt.Sprintf("argocd %s", url),
+			Check: func(ctx context.Context) error {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/version", nil)
+				if err != nil {
+					return err
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+				resp, err := client.Do(req)
+				if err != nil {
+					return err
+				}
+				defer resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					return fmt.Errorf("argocd responded %s", resp.Status)
+				}
+				return nil
+			},
+		})
+	}
+	return components
+}
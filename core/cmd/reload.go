@@ -0,0 +1,75 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/horizoncd/horizon/core/config"
+	ratelimitmiddle "github.com/horizoncd/horizon/core/middleware/ratelimit"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	"github.com/horizoncd/horizon/pkg/grafana"
+)
+
+// reloadables are the components which apply the changes of config file without a restart,
+// the other fields of config file take effect on the next start
+type reloadables struct {
+	gitopsToken    *gitlablib.Token
+	oauthLimiter   *ratelimitmiddle.Limiter
+	clientLimiter  *ratelimitmiddle.Limiter
+	rateLimits     []*ratelimitmiddle.Class
+	grafanaService grafana.Service
+}
+
+// watchConfig pushes the config file reloaded to the reloadables until ctx is done
+func watchConfig(ctx context.Context, flags *Flags, r reloadables) {
+	watcher, err := config.NewWatcher(flags.ConfigFile, flags.ConfigReloadInterval)
+	if err != nil {
+		log.Printf("failed to watch config file, changes take effect on the next start: %v", err)
+		return
+	}
+
+	watcher.Subscribe(func(ctx context.Context, c *config.Config) {
+		// the level of flag is restored if the one of config file is removed
+		levelName := c.LogLevel
+		if levelName == "" {
+			levelName = flags.LogLevel
+		}
+		level, err := logrus.ParseLevel(levelName)
+		if err != nil || level == logrus.GetLevel() {
+			return
+		}
+		logrus.SetLevel(level)
+		log.Printf("log level is changed to %s", level)
+	})
+	watcher.Subscribe(func(ctx context.Context, c *config.Config) {
+		r.oauthLimiter.SetLimit(c.Oauth.RateLimit.Rate, c.Oauth.RateLimit.Burst)
+		r.clientLimiter.SetLimit(c.Oauth.RateLimit.ClientRate, c.Oauth.RateLimit.ClientBurst)
+		setRateLimits(r.rateLimits, c.RateLimitConfig)
+	})
+	watcher.Subscribe(func(ctx context.Context, c *config.Config) {
+		r.grafanaService.UpdateConfig(c.GrafanaConfig)
+	})
+	watcher.Subscribe(func(ctx context.Context, c *config.Config) {
+		if c.GitopsRepoConfig.Token != r.gitopsToken.Get() {
+			r.gitopsToken.Set(c.GitopsRepoConfig.Token)
+			log.Printf("token of gitops repo is rotated")
+		}
+	})
+	watcher.Run(ctx)
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/server"
+	"github.com/horizoncd/horizon/pkg/server/certreload"
+)
+
+// runServer serves the handler until ctx is done, then stops accepting new connections and
+// waits for in-flight requests to finish within the shutdown timeout.
+// An error is returned only if the server fails to serve.
+func runServer(ctx context.Context, name string, handler http.Handler, config server.Config) error {
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", config.Port),
+		Handler: handler,
+	}
+	errCh := make(chan error, 1)
+	if config.TLS.Enabled() {
+		reloader, err := certreload.New(config.TLS)
+		if err != nil {
+			return err
+		}
+		go reloader.Run(ctx)
+		srv.TLSConfig = reloader.TLSConfig()
+		go func() {
+			// the certificate is served by the tls config
+			errCh <- srv.ListenAndServeTLS("", "")
+		}()
+	} else {
+		go func() {
+			errCh <- srv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down %s, draining connections for at most %v", name, config.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to drain connections of %s, close them forcibly: %v", name, err)
+		_ = srv.Close()
+		return nil
+	}
+	log.Printf("%s stopped", name)
+	return nil
+}
+
+// waitUntil waits for done to be closed, and gives up after the timeout
+func waitUntil(done <-chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -22,6 +22,7 @@ const (
 	ApplicationQueryByGroup          = "groupID"
 	ApplicationQueryByGroupRecursive = "groupRecursive"
 	ApplicationQueryID               = "id"
+	ApplicationQueryTagSelector      = "tagSelector"
 
 	ApplicationQueryWithDeleted = "withDeleted"
 )
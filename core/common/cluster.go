@@ -26,12 +26,18 @@ const (
 	ClusterQueryTagSelector   = "tagSelector"
 	ClusterQueryScope         = "scope"
 	ClusterQueryMergePatch    = "mergePatch"
+	ClusterQueryAsync         = "async"
 	ClusterQueryTargetBranch  = "targetBranch"
 	ClusterQueryTargetCommit  = "targetCommit"
 	ClusterQueryTargetTag     = "targetTag"
 	ClusterQueryContainerName = "containerName"
 	ClusterQueryPodName       = "podName"
 	ClusterQueryTailLines     = "tailLines"
+	ClusterQueryFollow        = "follow"
+	ClusterQuerySinceTime     = "sinceTime"
+	ClusterQueryPrevious      = "previous"
+	ClusterQueryTimestamps    = "timestamps"
+	ClusterQueryDownload      = "download"
 	ClusterQueryExtraOwner    = "extraOwner"
 	ClusterQueryHard          = "hard"
 
@@ -46,6 +52,13 @@ const (
 const (
 	ClusterClusterLabelKey = "cloudnative.music.netease.com/cluster"
 	ClusterRestartTimeKey  = "cloudnative.music.netease.com/user-restart-time"
+
+	// annotations of build provenance, they are written into the pipeline output on deploy,
+	// and templates render them on the workloads and pods of clusters
+	ClusterIDKey            = "cloudnative.music.netease.com/cluster-id"
+	ClusterPipelinerunIDKey = "cloudnative.music.netease.com/pipelinerun-id"
+	ClusterSourceCommitKey  = "cloudnative.music.netease.com/source-commit"
+	ClusterBuilderKey       = "cloudnative.music.netease.com/builder"
 )
 
 // status of cluster
@@ -31,11 +31,15 @@ const (
 	EndID     = "endID"
 	EventType = "eventType"
 	WebhookID = "webhookID"
+	Status    = "status"
 	CreatedAt = "createdAt"
 	Enabled   = "enabled"
 	Orphaned  = "orphaned"
 	OrderBy   = "orderBy"
 	ReqID     = "reqID"
+	// StartTime and EndTime should be type time.Time
+	StartTime = "startTime"
+	EndTime   = "endTime"
 
 	DefaultPageNumber = 1
 	DefaultPageSize   = 20
@@ -75,6 +79,12 @@ const (
 
 	// NotFound 404 NotFound error code
 	NotFound = "NotFound"
+
+	// TooManyRequests 429 rate limited error code
+	TooManyRequests = "TooManyRequests"
+
+	// AuthorizationPending 400 the device code is not yet approved, the client keeps polling
+	AuthorizationPending = "AuthorizationPending"
 )
 
 const (
@@ -109,7 +119,15 @@ const (
 	ResourceWebhook    = "webhooks"
 	ResourceWebhookLog = "webhooklogs"
 
+	// ResourceNotificationChannel use the member info of the group or application it belongs to
+	ResourceNotificationChannel = "notificationchannels"
+
+	// ResourceRobot use the member info of the group or application it belongs to
+	ResourceRobot = "robots"
+
 	ResourceMember = "members"
+
+	ResourceAccessToken = "accesstokens"
 )
 
 const (
@@ -15,35 +15,60 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/horizoncd/horizon/pkg/config/agent"
 	"github.com/horizoncd/horizon/pkg/config/argocd"
+	"github.com/horizoncd/horizon/pkg/config/artifact"
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	"github.com/horizoncd/horizon/pkg/config/autofree"
+	"github.com/horizoncd/horizon/pkg/config/changerequest"
 	"github.com/horizoncd/horizon/pkg/config/clean"
+	"github.com/horizoncd/horizon/pkg/config/clustersnapshot"
+	"github.com/horizoncd/horizon/pkg/config/cors"
 	"github.com/horizoncd/horizon/pkg/config/db"
+	"github.com/horizoncd/horizon/pkg/config/deployapproval"
+	"github.com/horizoncd/horizon/pkg/config/deploywindow"
 	"github.com/horizoncd/horizon/pkg/config/eventhandler"
+	"github.com/horizoncd/horizon/pkg/config/fluxcd"
 	"github.com/horizoncd/horizon/pkg/config/git"
 	"github.com/horizoncd/horizon/pkg/config/gitlab"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	"github.com/horizoncd/horizon/pkg/config/idp"
 	"github.com/horizoncd/horizon/pkg/config/job"
 	"github.com/horizoncd/horizon/pkg/config/k8sevent"
+	"github.com/horizoncd/horizon/pkg/config/kubeclient"
+	"github.com/horizoncd/horizon/pkg/config/manifestpolicy"
+	"github.com/horizoncd/horizon/pkg/config/metadata"
+	"github.com/horizoncd/horizon/pkg/config/naming"
+	"github.com/horizoncd/horizon/pkg/config/networkpolicy"
+	"github.com/horizoncd/horizon/pkg/config/notification"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
+	"github.com/horizoncd/horizon/pkg/config/ratelimit"
+	"github.com/horizoncd/horizon/pkg/config/recyclebin"
 	"github.com/horizoncd/horizon/pkg/config/redis"
+	"github.com/horizoncd/horizon/pkg/config/sandbox"
+	"github.com/horizoncd/horizon/pkg/config/sbom"
+	"github.com/horizoncd/horizon/pkg/config/scheduleddeploy"
 	"github.com/horizoncd/horizon/pkg/config/server"
 	"github.com/horizoncd/horizon/pkg/config/session"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/templaterepo"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	"github.com/horizoncd/horizon/pkg/config/tokenclean"
+	"github.com/horizoncd/horizon/pkg/config/trace"
 	"github.com/horizoncd/horizon/pkg/config/webhook"
-
-	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
+	// LogLevel overrides the loglevel flag if set, it is applied on reload as well
+	LogLevel               string                  `yaml:"logLevel"`
 	ServerConfig           server.Config           `yaml:"serverConfig"`
 	CloudEventServerConfig server.Config           `yaml:"cloudEventServerConfig"`
 	JobConfig              job.Config              `yaml:"jobConfig"`
@@ -52,23 +77,50 @@ type Config struct {
 	SessionConfig          session.Config          `yaml:"sessionConfig"`
 	GitopsRepoConfig       gitlab.GitopsRepoConfig `yaml:"gitopsRepoConfig"`
 	ArgoCDMapper           argocd.Mapper           `yaml:"argoCDMapper"`
+	ArgoCDSyncConcurrency  argocd.SyncConcurrency  `yaml:"argoCDSyncConcurrency"`
+	FluxCD                 fluxcd.Config           `yaml:"fluxCD"`
 	RedisConfig            redis.Redis             `yaml:"redisConfig"`
 	TektonMapper           tekton.Mapper           `yaml:"tektonMapper"`
 	TemplateRepo           templaterepo.Repo       `yaml:"templateRepo"`
+	TemplateSources        []*templaterepo.Repo    `yaml:"templateSources"`
 	AccessSecretKeys       authenticate.KeysConfig `yaml:"accessSecretKeys"`
 	GrafanaConfig          grafana.Config          `yaml:"grafanaConfig"`
 	Oauth                  oauth.Server            `yaml:"oauth"`
 	AutoFreeConfig         autofree.Config         `yaml:"autoFree"`
 	KubeConfig             string                  `yaml:"kubeconfig"`
 	WebhookConfig          webhook.Config          `yaml:"webhook"`
+	NotificationConfig     notification.Config     `yaml:"notification"`
 	EventHandlerConfig     eventhandler.Config     `yaml:"eventHandler"`
 	CodeGitRepos           []*git.Repo             `yaml:"gitRepos"`
 	TokenConfig            token.Config            `yaml:"tokenConfig"`
+	TokenCleanConfig       tokenclean.Config       `yaml:"tokenClean"`
+	RecycleBinConfig       recyclebin.Config       `yaml:"recycleBin"`
+	ArtifactConfig         artifact.Config         `yaml:"artifact"`
+	SBOMConfig             sbom.Config             `yaml:"sbom"`
 	TemplateUpgradeMapper  template.UpgradeMapper  `yaml:"templateUpgradeMapper"`
 	KubernetesEvent        k8sevent.Config         `yaml:"kubernetesEvent"`
 	Clean                  clean.Config            `yaml:"clean"`
+	NamingConfig           naming.Config           `yaml:"naming"`
+	DeployWindowConfig     deploywindow.Config     `yaml:"deployWindow"`
+	ScheduledDeployConfig  scheduleddeploy.Config  `yaml:"scheduledDeploy"`
+	KubeClientConfig       kubeclient.Config       `yaml:"kubeClient"`
+	ClusterSnapshotConfig  clustersnapshot.Config  `yaml:"clusterSnapshot"`
+	IDPConfig              idp.Config              `yaml:"idp"`
+	NetworkPolicyConfig    networkpolicy.Config    `yaml:"networkPolicy"`
+	ManifestPolicyConfig   manifestpolicy.Config   `yaml:"manifestPolicy"`
+	ChangeRequestConfig    changerequest.Config    `yaml:"changeRequest"`
+	DeployApprovalConfig   deployapproval.Config   `yaml:"deployApproval"`
+	SandboxConfig          sandbox.Config          `yaml:"sandbox"`
+	MetadataConfig         metadata.Config         `yaml:"metadata"`
+	AgentConfig            agent.Config            `yaml:"agent"`
+	TraceConfig            trace.Config            `yaml:"trace"`
+	RateLimitConfig        ratelimit.Config        `yaml:"rateLimit"`
+	CORSConfig             cors.Config             `yaml:"cors"`
 }
 
+// LoadConfig loads the config file. Values can refer to environment variables by ${NAME} or
+// ${NAME:-default}, and to secret files by the !file tag. Defaults are applied and required
+// fields are validated, all the invalid fields are reported with their lines in a ValidationError.
 func LoadConfig(configFilePath string) (*Config, error) {
 	var config Config
 	data, err := ioutil.ReadFile(configFilePath)
@@ -76,9 +128,20 @@ func LoadConfig(configFilePath string) (*Config, error) {
 		return nil, err
 	}
 
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	root, err := parseConfigFile(configFilePath, data)
+	if err != nil {
 		return nil, err
 	}
+	if root.Kind != 0 {
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("%s: %v", configFilePath, err)
+		}
+	}
+
+	config.setDefaults()
+	if errs := config.validate(root); len(errs) > 0 {
+		return nil, &ValidationError{File: configFilePath, Errors: errs}
+	}
 
 	newArgoCDMapper := argocd.Mapper{}
 	for key, v := range config.ArgoCDMapper {
@@ -98,27 +161,215 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	}
 	config.TektonMapper = newTektonMapper
 
-	if config.EventHandlerConfig.BatchEventsCount <= 0 {
-		config.EventHandlerConfig.BatchEventsCount = 5
+	return &config, nil
+}
+
+func (c *Config) setDefaults() {
+	if c.ServerConfig.Port == 0 {
+		c.ServerConfig.Port = 8080
+	}
+	if c.CloudEventServerConfig.Port == 0 {
+		c.CloudEventServerConfig.Port = 8181
+	}
+	if c.ServerConfig.ShutdownTimeout <= 0 {
+		c.ServerConfig.ShutdownTimeout = 30 * time.Second
+	}
+	if c.ServerConfig.ReadinessTimeout <= 0 {
+		c.ServerConfig.ReadinessTimeout = 5 * time.Second
+	}
+	if c.CloudEventServerConfig.ShutdownTimeout <= 0 {
+		c.CloudEventServerConfig.ShutdownTimeout = c.ServerConfig.ShutdownTimeout
+	}
+	// pprofConfig is kept for compatibility, it serves the debug endpoints on the port
+	if c.PProf.Enabled && c.ServerConfig.Debug.Port == 0 {
+		c.ServerConfig.Debug.Port = c.PProf.Port
+	}
+	if c.ServerConfig.TLS.ReloadInterval <= 0 {
+		c.ServerConfig.TLS.ReloadInterval = time.Minute
+	}
+	if c.CloudEventServerConfig.TLS.ReloadInterval <= 0 {
+		c.CloudEventServerConfig.TLS.ReloadInterval = time.Minute
+	}
+	if c.DBConfig.Port == 0 {
+		c.DBConfig.Port = 3306
+	}
+	if c.EventHandlerConfig.BatchEventsCount <= 0 {
+		c.EventHandlerConfig.BatchEventsCount = 5
+	}
+	if c.EventHandlerConfig.CursorSaveInterval <= 0 {
+		c.EventHandlerConfig.CursorSaveInterval = 10
+	}
+	if c.EventHandlerConfig.IdleWaitInterval <= 0 {
+		c.EventHandlerConfig.IdleWaitInterval = 3
+	}
+	if c.WebhookConfig.ClientTimeout <= 0 {
+		c.WebhookConfig.ClientTimeout = 30
+	}
+	if c.WebhookConfig.IdleWaitInterval <= 0 {
+		c.WebhookConfig.IdleWaitInterval = 2
+	}
+	if c.WebhookConfig.WorkerReconcileInterval <= 0 {
+		c.WebhookConfig.WorkerReconcileInterval = 5
+	}
+	if c.WebhookConfig.ResponseBodyTruncateSize <= 0 {
+		c.WebhookConfig.ResponseBodyTruncateSize = 16384
+	}
+	if c.WebhookConfig.MaxRetries <= 0 {
+		c.WebhookConfig.MaxRetries = 5
+	}
+	if c.WebhookConfig.RetryBackoff <= 0 {
+		c.WebhookConfig.RetryBackoff = 10
+	}
+	if c.WebhookConfig.MaxRetryBackoff <= 0 {
+		c.WebhookConfig.MaxRetryBackoff = 3600
+	}
+	if c.NotificationConfig.ClientTimeout <= 0 {
+		c.NotificationConfig.ClientTimeout = 10
+	}
+	if c.DeployWindowConfig.ConflictPolicy == "" {
+		c.DeployWindowConfig.ConflictPolicy = deploywindow.ConflictPolicyWarn
+	}
+	if c.ScheduledDeployConfig.JobInterval <= 0 {
+		c.ScheduledDeployConfig.JobInterval = time.Minute
+	}
+	if c.ScheduledDeployConfig.BatchSize <= 0 {
+		c.ScheduledDeployConfig.BatchSize = 20
+	}
+	if c.ClusterSnapshotConfig.JobInterval <= 0 {
+		c.ClusterSnapshotConfig.JobInterval = 24 * time.Hour
+	}
+	if c.ClusterSnapshotConfig.BatchSize <= 0 {
+		c.ClusterSnapshotConfig.BatchSize = 100
+	}
+	if c.ClusterSnapshotConfig.Retention <= 0 {
+		c.ClusterSnapshotConfig.Retention = 30 * 24 * time.Hour
+	}
+	if c.TokenCleanConfig.JobInterval <= 0 {
+		c.TokenCleanConfig.JobInterval = time.Hour
 	}
-	if config.EventHandlerConfig.CursorSaveInterval <= 0 {
-		config.EventHandlerConfig.CursorSaveInterval = 10
+	if c.TokenCleanConfig.BatchSize <= 0 {
+		c.TokenCleanConfig.BatchSize = 500
 	}
-	if config.EventHandlerConfig.IdleWaitInterval <= 0 {
-		config.EventHandlerConfig.IdleWaitInterval = 3
+	if c.RecycleBinConfig.JobInterval <= 0 {
+		c.RecycleBinConfig.JobInterval = time.Hour
 	}
-	if config.WebhookConfig.ClientTimeout <= 0 {
-		config.WebhookConfig.ClientTimeout = 30
+	if c.RecycleBinConfig.BatchSize <= 0 {
+		c.RecycleBinConfig.BatchSize = 100
 	}
-	if config.WebhookConfig.IdleWaitInterval <= 0 {
-		config.WebhookConfig.IdleWaitInterval = 2
+	if c.RecycleBinConfig.Retention <= 0 {
+		c.RecycleBinConfig.Retention = 30 * 24 * time.Hour
 	}
-	if config.WebhookConfig.WorkerReconcileInterval <= 0 {
-		config.WebhookConfig.WorkerReconcileInterval = 5
+	if c.ArtifactConfig.MaxSize <= 0 {
+		c.ArtifactConfig.MaxSize = 100 << 20
 	}
-	if config.WebhookConfig.ResponseBodyTruncateSize <= 0 {
-		config.WebhookConfig.ResponseBodyTruncateSize = 16384
+	if c.ArtifactConfig.DownloadURLExpireIn <= 0 {
+		c.ArtifactConfig.DownloadURLExpireIn = 10 * time.Minute
 	}
+	if c.ArtifactConfig.Retention.Artifacts <= 0 {
+		c.ArtifactConfig.Retention.Artifacts = 30 * 24 * time.Hour
+	}
+	if c.ArtifactConfig.Retention.Reports <= 0 {
+		c.ArtifactConfig.Retention.Reports = 90 * 24 * time.Hour
+	}
+	if c.ArtifactConfig.Retention.JobInterval <= 0 {
+		c.ArtifactConfig.Retention.JobInterval = time.Hour
+	}
+	if c.ArtifactConfig.Retention.BatchSize <= 0 {
+		c.ArtifactConfig.Retention.BatchSize = 100
+	}
+	if c.SBOMConfig.Image == "" {
+		c.SBOMConfig.Image = "anchore/syft:v0.98.0-debug"
+	}
+	if c.SBOMConfig.Timeout <= 0 {
+		c.SBOMConfig.Timeout = 10 * time.Minute
+	}
+	if c.Oauth.Device.CodeExpireIn <= 0 {
+		c.Oauth.Device.CodeExpireIn = 10 * time.Minute
+	}
+	if c.Oauth.Device.Interval <= 0 {
+		c.Oauth.Device.Interval = 5 * time.Second
+	}
+	if c.Oauth.OIDC.IDTokenExpireIn <= 0 {
+		c.Oauth.OIDC.IDTokenExpireIn = time.Hour
+	}
+	if c.Oauth.RateLimit.Rate > 0 && c.Oauth.RateLimit.Burst <= 0 {
+		c.Oauth.RateLimit.Burst = int(c.Oauth.RateLimit.Rate)
+		if c.Oauth.RateLimit.Burst < 1 {
+			c.Oauth.RateLimit.Burst = 1
+		}
+	}
+	if c.Oauth.RateLimit.ClientRate > 0 && c.Oauth.RateLimit.ClientBurst <= 0 {
+		c.Oauth.RateLimit.ClientBurst = int(c.Oauth.RateLimit.ClientRate)
+		if c.Oauth.RateLimit.ClientBurst < 1 {
+			c.Oauth.RateLimit.ClientBurst = 1
+		}
+	}
+	if c.SandboxConfig.TTL <= 0 {
+		c.SandboxConfig.TTL = 24 * time.Hour
+	}
+	if c.SandboxConfig.Replicas <= 0 {
+		c.SandboxConfig.Replicas = 1
+	}
+	if c.SandboxConfig.MaxPerUser <= 0 {
+		c.SandboxConfig.MaxPerUser = 1
+	}
+	if c.AgentConfig.PollTimeout <= 0 {
+		c.AgentConfig.PollTimeout = 30 * time.Second
+	}
+	if c.AgentConfig.OfflineTimeout <= 0 {
+		c.AgentConfig.OfflineTimeout = 90 * time.Second
+	}
+	if c.AgentConfig.RequestTimeout <= 0 {
+		c.AgentConfig.RequestTimeout = 30 * time.Second
+	}
+	if c.GitopsRepoConfig.Cache.TTL <= 0 {
+		c.GitopsRepoConfig.Cache.TTL = 10 * time.Minute
+	}
+	if c.GitopsRepoConfig.Cache.Size <= 0 {
+		c.GitopsRepoConfig.Cache.Size = 1000
+	}
+	if c.TraceConfig.ServiceName == "" {
+		c.TraceConfig.ServiceName = "horizon"
+	}
+	if c.TraceConfig.SampleRatio <= 0 {
+		c.TraceConfig.SampleRatio = 1
+	}
+	if c.TraceConfig.BatchSize <= 0 {
+		c.TraceConfig.BatchSize = 512
+	}
+	if c.TraceConfig.FlushInterval <= 0 {
+		c.TraceConfig.FlushInterval = 5 * time.Second
+	}
+	if c.RateLimitConfig.Backend == "" {
+		c.RateLimitConfig.Backend = ratelimit.BackendMemory
+	}
+	defaultBurst(&c.RateLimitConfig.User)
+	defaultBurst(&c.RateLimitConfig.IP)
+	for i := range c.RateLimitConfig.Routes {
+		defaultBurst(&c.RateLimitConfig.Routes[i].User)
+		defaultBurst(&c.RateLimitConfig.Routes[i].IP)
+	}
+	if len(c.CORSConfig.AllowedMethods) == 0 {
+		c.CORSConfig.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	if len(c.CORSConfig.AllowedHeaders) == 0 {
+		c.CORSConfig.AllowedHeaders = []string{"Content-Type", "Authorization", "X-Request-ID", "Idempotency-Key"}
+	}
+	if len(c.CORSConfig.ExposedHeaders) == 0 {
+		c.CORSConfig.ExposedHeaders = []string{"X-Request-ID", "X-Trace-ID", "Retry-After", "Idempotent-Replayed"}
+	}
+	if c.CORSConfig.MaxAge <= 0 {
+		c.CORSConfig.MaxAge = 10 * time.Minute
+	}
+}
 
-	return &config, nil
+// defaultBurst makes the burst of the limit the same as the rate if not set
+func defaultBurst(limit *ratelimit.Limit) {
+	if limit.Rate > 0 && limit.Burst <= 0 {
+		limit.Burst = int(limit.Rate)
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+	}
 }
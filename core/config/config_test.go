@@ -0,0 +1,134 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validConfig = `
+serverConfig:
+  port: ${HORIZON_TEST_PORT}
+dbConfig:
+  host: ${HORIZON_TEST_DB_HOST:-127.0.0.1}
+  username: horizon
+  password: !file db-password
+  database: horizon
+gitopsRepoConfig:
+  url: https://gitlab.com
+argoCDMapper:
+  test,reg:
+    url: https://argocd.com
+tektonMapper:
+  test,reg:
+    server: https://tekton.com
+    namespace: tekton-resources
+argoCDSyncConcurrency:
+  limit: 3
+  regions:
+    small: 1
+`
+
+const invalidConfig = `
+serverConfig:
+  port: 70000
+dbConfig:
+  host: 127.0.0.1
+  database: horizon
+gitopsRepoConfig:
+  url: https://gitlab.com
+argoCDMapper:
+  test:
+    token: abc
+tektonMapper:
+  test:
+    server: https://tekton.com
+    namespace: tekton-resources
+manifestPolicy:
+  policies:
+    - name: no-latest-tag
+      rule: disallowLatestTag
+      environments:
+        online: deny
+    - name: no-privileged
+      action: deny
+argoCDSyncConcurrency:
+  limit: 2
+  regions:
+    small: -1
+sandbox:
+  applicationID: 3
+  environment: dev
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "db-password", "secret\n")
+	path := writeFile(t, dir, "config.yaml", validConfig)
+
+	// unset variable without default
+	_, err = LoadConfig(path)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 3: environment variable HORIZON_TEST_PORT is not set")
+
+	os.Setenv("HORIZON_TEST_PORT", "9090")
+	defer os.Unsetenv("HORIZON_TEST_PORT")
+	config, err := LoadConfig(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 9090, config.ServerConfig.Port)
+	assert.Equal(t, 8181, config.CloudEventServerConfig.Port)
+	assert.Equal(t, "127.0.0.1", config.DBConfig.Host)
+	assert.Equal(t, 3306, config.DBConfig.Port)
+	assert.Equal(t, "secret", config.DBConfig.Password)
+	assert.Equal(t, uint(5), config.EventHandlerConfig.BatchEventsCount)
+	assert.Equal(t, "https://argocd.com", config.ArgoCDMapper["reg"].URL)
+	assert.Equal(t, "tekton-resources", config.TektonMapper["test"].Namespace)
+	assert.Equal(t, 1, config.ArgoCDSyncConcurrency.LimitOf("small"))
+	assert.Equal(t, 3, config.ArgoCDSyncConcurrency.LimitOf("reg"))
+	assert.False(t, config.SandboxConfig.Enabled())
+	assert.Equal(t, 24*time.Hour, config.SandboxConfig.TTL)
+
+	path = writeFile(t, dir, "invalid.yaml", invalidConfig)
+	_, err = LoadConfig(path)
+	assert.NotNil(t, err)
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, []*FieldError{
+		{Line: 3, Field: "serverConfig.port", Message: "must be between 1 and 65535"},
+		{Line: 4, Field: "dbConfig.username", Message: "is required"},
+		{Line: 10, Field: "argoCDMapper.test.url", Message: "is required"},
+		{Line: 27, Field: "argoCDSyncConcurrency.regions.small", Message: "must not be negative"},
+		{Line: 21, Field: "manifestPolicy.policies.0.environments.online", Message: "must be one of block, warn and off"},
+		{Line: 22, Field: "manifestPolicy.policies.1.rule", Message: "is required"},
+		{Line: 23, Field: "manifestPolicy.policies.1.action", Message: "must be one of block, warn and off"},
+		{Line: 28, Field: "sandbox.region", Message: "is required"},
+		{Line: 30, Field: "sandbox.environment", Message: "must be one of autoFree.supportedEnvs"},
+	}, validationErr.Errors)
+}
@@ -0,0 +1,150 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretFileTag marks a scalar whose value is the path of a file holding the real value,
+// e.g. `password: !file /etc/horizon/secrets/db-password`
+const SecretFileTag = "!file"
+
+// envVarPattern matches ${NAME} and ${NAME:-default}
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// parseConfigFile parses the config file into a yaml node with environment variables
+// interpolated and secret files resolved, so that the node can be decoded and used to locate fields
+func parseConfigFile(configFilePath string, data []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %v", configFilePath, err)
+	}
+	if err := resolveNode(&root, filepath.Dir(configFilePath)); err != nil {
+		return nil, fmt.Errorf("%s: %v", configFilePath, err)
+	}
+	return &root, nil
+}
+
+// ParseYAMLFile parses a yaml file other than the config file, in which environment variables
+// and secret files can be referred to in the same way as the config file
+func ParseYAMLFile(path string) (*yaml.Node, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigFile(path, data)
+}
+
+func resolveNode(node *yaml.Node, baseDir string) error {
+	if node.Kind != yaml.ScalarNode {
+		for _, child := range node.Content {
+			if err := resolveNode(child, baseDir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	value, err := expandEnv(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %v", node.Line, err)
+	}
+	if value != node.Value {
+		node.Value = value
+		// let plain scalars resolve their type again, so that `port: ${PORT}` is still an int
+		if node.Style == 0 && node.Tag != SecretFileTag {
+			node.Tag = ""
+		}
+	}
+
+	if node.Tag == SecretFileTag {
+		path := node.Value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("line %d: failed to read secret file: %v", node.Line, err)
+		}
+		node.Value = strings.TrimRight(string(content), "\r\n")
+		node.Tag = "!!str"
+	}
+	return nil
+}
+
+func expandEnv(s string) (string, error) {
+	var err error
+	expanded := envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := envVarPattern.FindStringSubmatch(match)
+		if value, ok := os.LookupEnv(groups[1]); ok {
+			return value
+		}
+		if groups[2] != "" {
+			return groups[3]
+		}
+		if err == nil {
+			err = fmt.Errorf("environment variable %s is not set", groups[1])
+		}
+		return match
+	})
+	return expanded, err
+}
+
+// lookupKey finds the key node of the field by its yaml path, for elements of a sequence the element
+// itself is returned. nil is returned if the field does not exist
+func lookupKey(root *yaml.Node, path ...string) *yaml.Node {
+	var key *yaml.Node
+	node := root
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, k := range path {
+		if node == nil {
+			return nil
+		}
+		switch node.Kind {
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			key, node = node.Content[i], node.Content[i]
+		case yaml.MappingNode:
+			content := node.Content
+			key, node = nil, nil
+			for i := 0; i+1 < len(content); i += 2 {
+				if content[i].Value == k {
+					key, node = content[i], content[i+1]
+					break
+				}
+			}
+			if key == nil {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return key
+}
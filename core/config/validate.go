@@ -0,0 +1,349 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/config/argocd"
+	"github.com/horizoncd/horizon/pkg/config/gitlab"
+	"github.com/horizoncd/horizon/pkg/config/manifestpolicy"
+	"github.com/horizoncd/horizon/pkg/config/ratelimit"
+	"github.com/horizoncd/horizon/pkg/config/tekton"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError describes an invalid field of the config file
+type FieldError struct {
+	// Line of the field in config file, 0 means unknown
+	Line    int
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError collects all invalid fields of the config file
+type ValidationError struct {
+	File   string
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("invalid config file %s:\n  %s", e.File, strings.Join(msgs, "\n  "))
+}
+
+type validator struct {
+	root   *yaml.Node
+	errors []*FieldError
+}
+
+// addError records an error of the field, the line of the field or its nearest existing parent is attached
+func (v *validator) addError(message string, path ...string) {
+	line := 0
+	for i := len(path); i > 0; i-- {
+		if key := lookupKey(v.root, path[:i]...); key != nil {
+			line = key.Line
+			break
+		}
+	}
+	v.errors = append(v.errors, &FieldError{
+		Line:    line,
+		Field:   strings.Join(path, "."),
+		Message: message,
+	})
+}
+
+func (v *validator) required(value string, path ...string) {
+	if value == "" {
+		v.addError("is required", path...)
+	}
+}
+
+func (v *validator) port(value int, path ...string) {
+	if value <= 0 || value > 65535 {
+		v.addError("must be between 1 and 65535", path...)
+	}
+}
+
+func (v *validator) rateLimit(limit ratelimit.Limit, path ...string) {
+	if limit.Rate < 0 {
+		v.addError("must not be negative", append(path, "rate")...)
+	}
+	if limit.Burst < 0 {
+		v.addError("must not be negative", append(path, "burst")...)
+	}
+}
+
+func (v *validator) policyAction(value string, path ...string) {
+	switch value {
+	case "", manifestpolicy.ActionBlock, manifestpolicy.ActionWarn, manifestpolicy.ActionOff:
+	default:
+		v.addError(fmt.Sprintf("must be one of %s, %s and %s",
+			manifestpolicy.ActionBlock, manifestpolicy.ActionWarn, manifestpolicy.ActionOff), path...)
+	}
+}
+
+// validate checks the required fields, it must be called before mappers are expanded
+// so that errors can be located by the original keys
+func (c *Config) validate(root *yaml.Node) []*FieldError {
+	v := &validator{root: root}
+
+	if c.LogLevel != "" {
+		if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+			v.addError(err.Error(), "logLevel")
+		}
+	}
+
+	v.port(c.ServerConfig.Port, "serverConfig", "port")
+	v.port(c.CloudEventServerConfig.Port, "cloudEventServerConfig", "port")
+	if c.ServerConfig.Debug.Port != 0 {
+		v.port(c.ServerConfig.Debug.Port, "serverConfig", "debug", "port")
+		if c.ServerConfig.Debug.Port == c.ServerConfig.Port ||
+			c.ServerConfig.Debug.Port == c.CloudEventServerConfig.Port {
+			v.addError("must differ from the ports of the servers", "serverConfig", "debug", "port")
+		}
+	}
+	if c.ServerConfig.TLS.Enabled() {
+		v.required(c.ServerConfig.TLS.KeyFile, "serverConfig", "tls", "keyFile")
+	}
+	if c.CloudEventServerConfig.TLS.Enabled() {
+		v.required(c.CloudEventServerConfig.TLS.KeyFile, "cloudEventServerConfig", "tls", "keyFile")
+	}
+
+	v.required(c.DBConfig.Host, "dbConfig", "host")
+	v.port(c.DBConfig.Port, "dbConfig", "port")
+	v.required(c.DBConfig.Username, "dbConfig", "username")
+	v.required(c.DBConfig.Database, "dbConfig", "database")
+	for i, replica := range c.DBConfig.Replicas {
+		v.required(replica.Host, "dbConfig", "replicas", strconv.Itoa(i), "host")
+		v.port(replica.Port, "dbConfig", "replicas", strconv.Itoa(i), "port")
+	}
+
+	v.required(c.GitopsRepoConfig.URL, "gitopsRepoConfig", "url")
+
+	if len(c.ArgoCDMapper) == 0 {
+		v.addError("at least one environment is required", "argoCDMapper")
+	}
+	for _, key := range sortedArgoCDKeys(c.ArgoCDMapper) {
+		argoCD := c.ArgoCDMapper[key]
+		if argoCD == nil {
+			v.addError("is required", "argoCDMapper", key)
+			continue
+		}
+		v.required(argoCD.URL, "argoCDMapper", key, "url")
+	}
+
+	if c.ArgoCDSyncConcurrency.Limit < 0 {
+		v.addError("must not be negative", "argoCDSyncConcurrency", "limit")
+	}
+	for _, region := range sortedLimitKeys(c.ArgoCDSyncConcurrency.Regions) {
+		if c.ArgoCDSyncConcurrency.Regions[region] < 0 {
+			v.addError("must not be negative", "argoCDSyncConcurrency", "regions", region)
+		}
+	}
+
+	if len(c.TektonMapper) == 0 {
+		v.addError("at least one environment is required", "tektonMapper")
+	}
+	for _, key := range sortedTektonKeys(c.TektonMapper) {
+		t := c.TektonMapper[key]
+		if t == nil {
+			v.addError("is required", "tektonMapper", key)
+			continue
+		}
+		v.required(t.Server, "tektonMapper", key, "server")
+		v.required(t.Namespace, "tektonMapper", key, "namespace")
+	}
+
+	for i, repo := range c.CodeGitRepos {
+		if repo == nil {
+			continue
+		}
+		v.required(repo.URL, "gitRepos", fmt.Sprint(i), "url")
+	}
+
+	for i, policy := range c.ManifestPolicyConfig.Policies {
+		if policy == nil {
+			continue
+		}
+		v.required(policy.Name, "manifestPolicy", "policies", fmt.Sprint(i), "name")
+		v.required(policy.Rule, "manifestPolicy", "policies", fmt.Sprint(i), "rule")
+		v.policyAction(policy.Action, "manifestPolicy", "policies", fmt.Sprint(i), "action")
+		for _, env := range sortedKeys(policy.Environments) {
+			v.policyAction(policy.Environments[env], "manifestPolicy", "policies", fmt.Sprint(i), "environments", env)
+		}
+	}
+
+	for i, window := range c.DeployWindowConfig.FreezeWindows {
+		if window == nil {
+			continue
+		}
+		v.required(window.Name, "deployWindow", "freezeWindows", fmt.Sprint(i), "name")
+		v.required(window.Start, "deployWindow", "freezeWindows", fmt.Sprint(i), "start")
+		v.required(window.End, "deployWindow", "freezeWindows", fmt.Sprint(i), "end")
+		if window.Start == "" || window.End == "" {
+			continue
+		}
+		if err := window.Validate(); err != nil {
+			v.addError(err.Error(), "deployWindow", "freezeWindows", fmt.Sprint(i))
+		}
+	}
+
+	if c.SBOMConfig.Enabled {
+		v.required(c.SBOMConfig.ServerURL, "sbom", "serverURL")
+	}
+
+	if c.SandboxConfig.Enabled() {
+		v.required(c.SandboxConfig.Environment, "sandbox", "environment")
+		v.required(c.SandboxConfig.Region, "sandbox", "region")
+		if c.SandboxConfig.Environment != "" && !contains(c.AutoFreeConfig.SupportedEnvs, c.SandboxConfig.Environment) {
+			v.addError("must be one of autoFree.supportedEnvs", "sandbox", "environment")
+		}
+	}
+
+	if c.NotificationConfig.SMTP.Host != "" {
+		v.port(c.NotificationConfig.SMTP.Port, "notification", "smtp", "port")
+		v.required(c.NotificationConfig.SMTP.From, "notification", "smtp", "from")
+	}
+
+	if c.Oauth.OIDC.Enabled() {
+		v.required(c.Oauth.OIDC.SigningKeyFile, "oauth", "oidc", "signingKeyFile")
+	}
+
+	if c.TraceConfig.SampleRatio > 1 {
+		v.addError("must not be greater than 1", "trace", "sampleRatio")
+	}
+
+	for i, origin := range c.CORSConfig.AllowedOrigins {
+		index := strconv.Itoa(i)
+		if origin == "*" {
+			if c.CORSConfig.AllowCredentials {
+				v.addError("the * origin can not be used with allowCredentials, list the origins instead",
+					"cors", "allowedOrigins", index)
+			}
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			v.addError("must be * or start with http:// or https://", "cors", "allowedOrigins", index)
+		}
+	}
+
+	switch c.GitopsRepoConfig.Cache.Backend {
+	case "", gitlab.CacheBackendMemory, gitlab.CacheBackendRedis:
+	default:
+		v.addError(fmt.Sprintf("must be %s or %s", gitlab.CacheBackendMemory, gitlab.CacheBackendRedis),
+			"gitopsRepoConfig", "cache", "backend")
+	}
+
+	switch c.RateLimitConfig.Backend {
+	case "", ratelimit.BackendMemory, ratelimit.BackendRedis:
+	default:
+		v.addError(fmt.Sprintf("must be %s or %s", ratelimit.BackendMemory, ratelimit.BackendRedis),
+			"rateLimit", "backend")
+	}
+	v.rateLimit(c.RateLimitConfig.User, "rateLimit", "user")
+	v.rateLimit(c.RateLimitConfig.IP, "rateLimit", "ip")
+	routeNames := make(map[string]bool)
+	for i, route := range c.RateLimitConfig.Routes {
+		index := strconv.Itoa(i)
+		v.required(route.Name, "rateLimit", "routes", index, "name")
+		if route.Name == ratelimit.DefaultRoute {
+			v.addError(fmt.Sprintf("%s is reserved for the global limits", route.Name),
+				"rateLimit", "routes", index, "name")
+		}
+		if routeNames[route.Name] {
+			v.addError(fmt.Sprintf("route %s is duplicated", route.Name), "rateLimit", "routes", index, "name")
+		}
+		routeNames[route.Name] = true
+		v.required(route.Path, "rateLimit", "routes", index, "path")
+		if _, err := regexp.Compile(route.Path); err != nil {
+			v.addError(err.Error(), "rateLimit", "routes", index, "path")
+		}
+		v.rateLimit(route.User, "rateLimit", "routes", index, "user")
+		v.rateLimit(route.IP, "rateLimit", "routes", index, "ip")
+	}
+
+	regions := make([]string, 0, len(c.AgentConfig.Regions))
+	for region := range c.AgentConfig.Regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		v.required(c.AgentConfig.Regions[region].Token, "agent", "regions", region, "token")
+	}
+
+	return v.errors
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedArgoCDKeys(m argocd.Mapper) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedTektonKeys(m tekton.Mapper) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedLimitKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
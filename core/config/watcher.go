@@ -0,0 +1,107 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Subscriber applies the fields it is interested in of the config reloaded
+type Subscriber func(ctx context.Context, config *Config)
+
+// Watcher re-reads the config file on change and pushes the new config to the subscribers.
+// The content of the file is compared instead of its modification time, so that the ConfigMaps mounted,
+// which are swapped by symlinks, are picked up as well.
+type Watcher struct {
+	file     string
+	interval time.Duration
+
+	lock        sync.Mutex
+	digest      [sha256.Size]byte
+	subscribers []Subscriber
+}
+
+// NewWatcher watches the file loaded already, the current content is not pushed to the subscribers
+func NewWatcher(file string, interval time.Duration) (*Watcher, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		file:     file,
+		interval: interval,
+		digest:   sha256.Sum256(content),
+	}, nil
+}
+
+// Subscribe registers s to be called with each config reloaded, it must be called before Run
+func (w *Watcher) Subscribe(s Subscriber) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.subscribers = append(w.subscribers, s)
+}
+
+// reload returns the new config if the file changed, or nil if not.
+// An invalid config is not pushed, and it's reported again only if the file changes again.
+func (w *Watcher) reload() (*Config, error) {
+	content, err := ioutil.ReadFile(w.file)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(content)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if digest == w.digest {
+		return nil, nil
+	}
+	w.digest = digest
+	return LoadConfig(w.file)
+}
+
+// Run checks the file for changes until ctx is done
+func (w *Watcher) Run(ctx context.Context) {
+	log.Infof(ctx, "watching config file %s for changes every %v", w.file, w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		config, err := w.reload()
+		if err != nil {
+			log.Errorf(ctx, "failed to reload config file, keep the current config: %v", err)
+			continue
+		}
+		if config == nil {
+			continue
+		}
+		log.Infof(ctx, "config file %s is reloaded", w.file)
+		w.lock.Lock()
+		subscribers := w.subscribers
+		w.lock.Unlock()
+		for _, s := range subscribers {
+			s(ctx, config)
+		}
+	}
+}
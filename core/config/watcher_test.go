@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.Setenv("HORIZON_TEST_PORT", "9090"))
+	defer os.Unsetenv("HORIZON_TEST_PORT")
+
+	writeFile(t, dir, "db-password", "secret\n")
+	path := writeFile(t, dir, "config.yaml", validConfig)
+	w, err := NewWatcher(path, 10*time.Millisecond)
+	assert.Nil(t, err)
+
+	// unchanged
+	config, err := w.reload()
+	assert.Nil(t, err)
+	assert.Nil(t, config)
+
+	// invalid config is not pushed, and it's not reported again until the file changes
+	writeFile(t, dir, "config.yaml", validConfig+"logLevel: verbose\n")
+	_, err = w.reload()
+	assert.NotNil(t, err)
+	config, err = w.reload()
+	assert.Nil(t, err)
+	assert.Nil(t, config)
+
+	reloaded := make(chan *Config, 1)
+	w.Subscribe(func(ctx context.Context, config *Config) {
+		reloaded <- config
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	writeFile(t, dir, "config.yaml", validConfig+"logLevel: debug\n")
+	select {
+	case config = <-reloaded:
+		assert.Equal(t, "debug", config.LogLevel)
+		assert.Equal(t, 9090, config.ServerConfig.Port)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config is not reloaded")
+	}
+}
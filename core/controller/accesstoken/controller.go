@@ -31,10 +31,13 @@ import (
 	accesstokenmanager "github.com/horizoncd/horizon/pkg/accesstoken/manager"
 	"github.com/horizoncd/horizon/pkg/accesstoken/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	membermanager "github.com/horizoncd/horizon/pkg/member"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	robotmanager "github.com/horizoncd/horizon/pkg/robot/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 )
@@ -59,6 +62,8 @@ type controller struct {
 	tokenSvc       tokenservice.Service
 	memberSvc      memberservice.Service
 	memberMgr      membermanager.Manager
+	eventSvc       eventservice.Service
+	robotMgr       robotmanager.Manager
 }
 
 func NewController(param *param.Param) Controller {
@@ -69,6 +74,8 @@ func NewController(param *param.Param) Controller {
 		tokenSvc:       param.TokenSvc,
 		memberSvc:      param.MemberService,
 		memberMgr:      param.MemberMgr,
+		eventSvc:       param.EventSvc,
+		robotMgr:       param.RobotMgr,
 	}
 }
 
@@ -264,7 +271,11 @@ func (c *controller) RevokePersonalAccessToken(ctx context.Context, id uint) err
 	}
 
 	// 2. delete token
-	return c.tokenMgr.RevokeTokenByID(ctx, id)
+	if err := c.tokenMgr.RevokeTokenByID(ctx, id); err != nil {
+		return err
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceAccessToken, id, eventmodels.TokenRevoked, nil)
+	return nil
 }
 
 func (c *controller) RevokeResourceAccessToken(ctx context.Context, id uint) error {
@@ -280,6 +291,12 @@ func (c *controller) RevokeResourceAccessToken(ctx context.Context, id uint) err
 	if user.UserType != usermodels.UserTypeRobot {
 		return perror.Wrap(herror.ErrParamInvalid, "this is not a resource token")
 	}
+	// revoking a resource token deletes its user, which the robot accounts keep
+	if robot, err := c.robotMgr.GetByUserID(ctx, user.ID); err == nil {
+		return perror.Wrapf(herror.ErrParamInvalid, "this token belongs to robot %s", robot.Name)
+	} else if _, ok := perror.Cause(err).(*herror.HorizonErrNotFound); !ok {
+		return err
+	}
 
 	checkPermission := func() error {
 		// list members of the robot user
@@ -316,6 +333,7 @@ func (c *controller) RevokeResourceAccessToken(ctx context.Context, id uint) err
 	if err := c.tokenMgr.RevokeTokenByID(ctx, id); err != nil {
 		return err
 	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceAccessToken, id, eventmodels.TokenRevoked, nil)
 
 	// 3. delete related resources
 	return cleanRelatedResources()
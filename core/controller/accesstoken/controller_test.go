@@ -36,6 +36,8 @@ import (
 	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
@@ -43,13 +45,15 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	robotmodels "github.com/horizoncd/horizon/pkg/robot/models"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
 )
 
 var (
-	ctx context.Context
-	c   Controller
+	ctx       context.Context
+	c         Controller
+	parameter *param.Param
 )
 
 // valid params
@@ -83,6 +87,8 @@ func TestMain(m *testing.M) {
 		&tokenmodels.Token{},
 		&groupmodels.Group{},
 		&applicationmodels.Application{},
+		&eventmodels.Event{},
+		&robotmodels.Robot{},
 	); err != nil {
 		panic(err)
 	}
@@ -101,10 +107,11 @@ func TestMain(m *testing.M) {
 	oauthMgr := oauthmanager.NewManager(oauthAppDAO, tokenStore, generator.NewAuthorizeGenerator(),
 		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn)
 
-	parameter := &param.Param{
+	parameter = &param.Param{
 		Manager:       manager,
 		TokenSvc:      tokenservice.NewService(manager, token.Config{}),
 		MemberService: memberservice.NewService(roleSvc, oauthMgr, manager),
+		EventSvc:      eventservice.New(manager),
 	}
 
 	ctx = context.TODO()
@@ -233,6 +240,12 @@ func Test(t *testing.T) {
 
 			err = c.RevokePersonalAccessToken(ctx, createTokenResp.ID)
 			assert.Equal(t, nil, err)
+
+			events, _, err := parameter.EventMgr.ListEventsByResource(ctx, common.ResourceAccessToken,
+				createTokenResp.ID, 0, 10)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 1, len(events))
+			assert.Equal(t, eventmodels.TokenRevoked, events[0].EventType)
 		}
 	}
 
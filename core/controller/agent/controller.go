@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/agent"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+)
+
+type Controller interface {
+	// Poll waits for kubernetes requests to the region, empty is returned when none is queued in poll timeout
+	Poll(ctx context.Context, region, token string) ([]*agent.Request, error)
+	// Respond delivers a part of the response to a request of the region
+	Respond(ctx context.Context, region, token string, resp *agent.Response) error
+}
+
+type controller struct {
+	agentHub agent.Hub
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		agentHub: param.AgentHub,
+	}
+}
+
+func (c *controller) Poll(ctx context.Context, region, token string) ([]*agent.Request, error) {
+	if err := c.authenticate(region, token); err != nil {
+		return nil, err
+	}
+	return c.agentHub.Poll(ctx, region)
+}
+
+func (c *controller) Respond(ctx context.Context, region, token string, resp *agent.Response) error {
+	if err := c.authenticate(region, token); err != nil {
+		return err
+	}
+	return c.agentHub.Respond(ctx, region, resp)
+}
+
+func (c *controller) authenticate(region, token string) error {
+	if !c.agentHub.Serves(region) {
+		return perror.Wrapf(herrors.ErrForbidden, "region %s is not served by agent", region)
+	}
+	if !c.agentHub.Authenticate(region, token) {
+		return perror.Wrapf(herrors.ErrTokenInvalid, "token of agent of region %s is invalid", region)
+	}
+	return nil
+}
@@ -29,8 +29,13 @@ import (
 	"github.com/horizoncd/horizon/pkg/application/models"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	csmanager "github.com/horizoncd/horizon/pkg/clustersummary/manager"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
@@ -38,14 +43,19 @@ import (
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
 	"github.com/horizoncd/horizon/pkg/member"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/naming"
 	"github.com/horizoncd/horizon/pkg/param"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
 	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/pipeline/models"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
-	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templaterender "github.com/horizoncd/horizon/pkg/templaterelease/render"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
 	usersvc "github.com/horizoncd/horizon/pkg/user/service"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/jsonschema"
@@ -81,24 +91,56 @@ type Controller interface {
 	// GetApplicationPipelineStats return pipeline stats about an application
 	GetApplicationPipelineStats(ctx context.Context, applicationID uint, cluster string, pageNumber, pageSize int) (
 		[]*pipelinemodels.PipelineStats, int64, error)
+	// GetDeployWindow returns deploys in progress and upcoming deploys of an application
+	GetDeployWindow(ctx context.Context, applicationID uint) (*deploywindow.Window, error)
+	// ImportApplications creates applications in the group one by one in background,
+	// the applications left are created even if some of them fail
+	ImportApplications(ctx context.Context, groupID uint,
+		request *ImportApplicationsRequest) (*ImportApplicationsAsyncResponse, error)
+	// ListDeleted lists applications soft deleted under the group which are not purged yet
+	ListDeleted(ctx context.Context, groupID uint) ([]*DeletedApplication, error)
+	// Restore restores an application soft deleted under the group
+	Restore(ctx context.Context, groupID, id uint) error
+	// CloneApplication creates an application with the config, env configs, regions and tags
+	// of the application, the config is read from its gitops repo and rendered again
+	CloneApplication(ctx context.Context, id uint,
+		request *CloneApplicationRequest) (*CreateApplicationResponseV2, error)
+	// DryRunApplicationV2 renders the kubernetes manifests of the application in the environment with the template
+	// and template config in the request, which default to the current ones, and compares them with the current ones
+	DryRunApplicationV2(ctx context.Context, id uint, environment string,
+		request *CreateOrUpdateApplicationRequestV2) (*templaterender.Preview, error)
+	// ListConfigCommits lists the commits changing the config of the application in the environment,
+	// the default config if the environment is empty, the latest first
+	ListConfigCommits(ctx context.Context, id uint, environment string,
+		pageNumber, pageSize int) ([]*pkgcommon.ConfigCommit, error)
+	// RollbackConfig restores the config of the application to the one of the commit
+	RollbackConfig(ctx context.Context, id uint, r *RollbackConfigRequest) error
 }
 
 type controller struct {
 	applicationGitRepo   gitrepo.ApplicationGitRepo
-	templateSchemaGetter templateschema.Getter
+	templateValidator    templatevalidation.Service
+	templateRenderer     templaterender.Renderer
 	applicationMgr       applicationmanager.Manager
 	applicationSvc       applicationservice.Service
 	groupMgr             groupmanager.Manager
 	groupSvc             groupsvc.Service
 	templateReleaseMgr   trmanager.Manager
 	clusterMgr           clustermanager.Manager
+	clusterSummaryMgr    csmanager.Manager
 	userSvc              usersvc.Service
 	memberManager        member.Manager
 	eventSvc             eventservice.Service
 	tagMgr               tagmanager.Manager
 	applicationRegionMgr applicationregionmanager.Manager
+	envMgr               envmanager.Manager
 	pipelinemanager      pipelinemanager.Manager
 	buildSchema          *build.Schema
+	namingSvc            naming.Service
+	deployWindowSvc      deploywindow.Service
+	metadataSvc          metadataservice.Service
+	asyncTaskSvc         asynctaskservice.Service
+	quotaSvc             quotaservice.Service
 }
 
 var _ Controller = (*controller)(nil)
@@ -106,20 +148,28 @@ var _ Controller = (*controller)(nil)
 func NewController(param *param.Param) Controller {
 	return &controller{
 		applicationGitRepo:   param.ApplicationGitRepo,
-		templateSchemaGetter: param.TemplateSchemaGetter,
+		templateValidator:    param.TemplateValidationSvc,
+		templateRenderer:     param.TemplateRenderer,
 		applicationMgr:       param.ApplicationMgr,
 		applicationSvc:       param.ApplicationSvc,
 		groupMgr:             param.GroupMgr,
 		groupSvc:             param.GroupSvc,
 		templateReleaseMgr:   param.TemplateReleaseMgr,
 		clusterMgr:           param.ClusterMgr,
+		clusterSummaryMgr:    param.ClusterSummaryMgr,
 		userSvc:              param.UserSvc,
 		memberManager:        param.MemberMgr,
 		eventSvc:             param.EventSvc,
 		tagMgr:               param.TagMgr,
 		applicationRegionMgr: param.ApplicationRegionMgr,
+		envMgr:               param.EnvMgr,
 		pipelinemanager:      param.PipelineMgr,
 		buildSchema:          param.BuildSchema,
+		namingSvc:            param.NamingSvc,
+		deployWindowSvc:      param.DeployWindowSvc,
+		metadataSvc:          param.MetadataSvc,
+		asyncTaskSvc:         param.AsyncTaskSvc,
+		quotaSvc:             param.QuotaSvc,
 	}
 }
 
@@ -191,6 +241,12 @@ func (c *controller) GetApplicationV2(ctx context.Context, id uint) (_ *GetAppli
 		return nil, err
 	}
 
+	// 5. get metadata
+	metadata, err := c.metadataSvc.Get(ctx, common.ResourceApplication, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	resp := &GetApplicationResponseV2{
 		ID:          id,
 		Name:        app.Name,
@@ -202,9 +258,11 @@ func (c *controller) GetApplicationV2(ctx context.Context, id uint) (_ *GetAppli
 			}
 			return codemodels.NewGit(app.GitURL, app.GitSubfolder, app.GitRefType, app.GitRef)
 		}(),
-		Image:       app.Image,
-		BuildConfig: applicationRepo.BuildConf,
-		Tags:        tagmodels.Tags(tags).IntoTagsBasic(),
+		Image:        app.Image,
+		Metadata:     metadata,
+		BuildConfig:  applicationRepo.BuildConf,
+		ConfigCommit: applicationRepo.Commit,
+		Tags:         tagmodels.Tags(tags).IntoTagsBasic(),
 		TemplateInfo: func() *codemodels.TemplateInfo {
 			if app.Template == "" {
 				return nil
@@ -233,6 +291,9 @@ func (c *controller) CreateApplication(ctx context.Context, groupID uint,
 	if err := validateApplicationName(request.Name); err != nil {
 		return nil, err
 	}
+	if err := c.namingSvc.Validate(ctx, naming.KindApplication, request.Name); err != nil {
+		return nil, err
+	}
 	if err := c.validateCreate(request.Base); err != nil {
 		return nil, err
 	}
@@ -241,13 +302,16 @@ func (c *controller) CreateApplication(ctx context.Context, groupID uint,
 	}
 
 	if err := c.validateTemplateInput(ctx, request.Template.Name,
-		request.Template.Release, request.TemplateInput); err != nil {
+		request.Template.Release, request.TemplateInput, nil); err != nil {
 		return nil, err
 	}
 	group, err := c.groupSvc.GetChildByID(ctx, groupID)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.quotaSvc.Check(ctx, groupID, quotamodels.ResourceApplications); err != nil {
+		return nil, err
+	}
 
 	// 2. check groups or applications with the same name exists
 	groups, err := c.groupMgr.GetByNameOrPathUnderParent(ctx, request.Name, request.Name, groupID)
@@ -268,6 +332,9 @@ func (c *controller) CreateApplication(ctx context.Context, groupID uint,
 		return nil, perror.Wrap(herrors.ErrNameConflict, "an application with the same name already exists, "+
 			"please do not create it again")
 	}
+	if err := c.checkDeletedName(ctx, request.Name); err != nil {
+		return nil, err
+	}
 
 	// 3. create application in git repo
 	createRepoReq := gitrepo.CreateOrUpdateRequest{
@@ -315,12 +382,12 @@ func (c *controller) CreateApplication(ctx context.Context, groupID uint,
 }
 
 func (c *controller) validateBuildAndTemplateConfigV2(ctx context.Context,
-	request *CreateOrUpdateApplicationRequestV2) error {
+	request *CreateOrUpdateApplicationRequestV2, previous *TemplateInput) error {
 	if request.TemplateConfig != nil && request.TemplateInfo != nil {
 		if err := c.validateTemplateInput(ctx, request.TemplateInfo.Name, request.TemplateInfo.Release, &TemplateInput{
 			Application: request.TemplateConfig,
 			Pipeline:    nil,
-		}); err != nil {
+		}, previous); err != nil {
 			return err
 		}
 	}
@@ -342,6 +409,9 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 	if err := validateApplicationName(request.Name); err != nil {
 		return nil, err
 	}
+	if err := c.namingSvc.Validate(ctx, naming.KindApplication, request.Name); err != nil {
+		return nil, err
+	}
 	if request.Priority != nil {
 		if err := validatePriority(*request.Priority); err != nil {
 			return nil, err
@@ -358,7 +428,10 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 		}
 	}
 
-	if err := c.validateBuildAndTemplateConfigV2(ctx, request); err != nil {
+	if err := c.validateBuildAndTemplateConfigV2(ctx, request, nil); err != nil {
+		return nil, err
+	}
+	if err := c.quotaSvc.Check(ctx, groupID, quotamodels.ResourceApplications); err != nil {
 		return nil, err
 	}
 	// check groups or applications with the same name exists
@@ -380,6 +453,9 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 		return nil, perror.Wrap(herrors.ErrNameConflict, "an application with the same name already exists, "+
 			"please do not create it again")
 	}
+	if err := c.checkDeletedName(ctx, request.Name); err != nil {
+		return nil, err
+	}
 
 	// create v2
 	createRepoReq := gitrepo.CreateOrUpdateRequest{
@@ -460,7 +536,12 @@ func (c *controller) UpdateApplication(ctx context.Context, id uint,
 			template = appExistsInDB.Template
 			templateRelease = appExistsInDB.TemplateRelease
 		}
-		if err := c.validateTemplateInput(ctx, template, templateRelease, request.TemplateInput); err != nil {
+		previous, err := c.getTemplateInput(ctx, appExistsInDB.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.validateTemplateInput(ctx, template, templateRelease,
+			request.TemplateInput, previous); err != nil {
 			return nil, err
 		}
 
@@ -538,15 +619,24 @@ func (c *controller) UpdateApplicationV2(ctx context.Context, id uint,
 		}
 	}
 
-	if err := c.validateBuildAndTemplateConfigV2(ctx, request); err != nil {
+	if request.TemplateConfig != nil && request.TemplateInfo != nil {
+		previous, err := c.getTemplateInput(ctx, appExistsInDB.Name)
+		if err != nil {
+			return err
+		}
+		if err := c.validateBuildAndTemplateConfigV2(ctx, request, previous); err != nil {
+			return err
+		}
+	} else if err := c.validateBuildAndTemplateConfigV2(ctx, request, nil); err != nil {
 		return err
 	}
 	if (request.TemplateConfig != nil && request.TemplateInfo != nil) || request.BuildConfig != nil {
 		updateRepoReq := gitrepo.CreateOrUpdateRequest{
-			Version:      common.MetaVersion2,
-			Environment:  common.ApplicationRepoDefaultEnv,
-			BuildConf:    request.BuildConfig,
-			TemplateConf: request.TemplateConfig,
+			Version:        common.MetaVersion2,
+			Environment:    common.ApplicationRepoDefaultEnv,
+			BuildConf:      request.BuildConfig,
+			TemplateConf:   request.TemplateConfig,
+			ExpectedCommit: request.ConfigCommit,
 		}
 		if err = c.applicationGitRepo.CreateOrUpdateApplication(ctx, appExistsInDB.Name, updateRepoReq); err != nil {
 			return err
@@ -594,7 +684,7 @@ func (c *controller) DeleteApplication(ctx context.Context, id uint, hard bool)
 			"because there are clusters under this application.")
 	}
 
-	// 2. delete application in git repo
+	// 2. delete members, region config and git repo of the application if hard
 	if hard {
 		// delete member
 		if err := c.memberManager.HardDeleteMemberByResourceTypeID(ctx,
@@ -611,14 +701,15 @@ func (c *controller) DeleteApplication(ctx context.Context, id uint, hard bool)
 				return err
 			}
 		}
-	} else {
-		if err := c.applicationGitRepo.HardDeleteApplication(ctx, app.Name); err != nil {
-			return err
-		}
 	}
 
-	// 3. delete application in db
-	if err := c.applicationMgr.DeleteByID(ctx, id); err != nil {
+	// 3. delete application in db, a soft deleted application keeps its git repo and members
+	// until it is restored or purged
+	deleteByID := c.applicationMgr.DeleteByID
+	if hard {
+		deleteByID = c.applicationMgr.HardDeleteByID
+	}
+	if err := deleteByID(ctx, id); err != nil {
 		return err
 	}
 
@@ -666,30 +757,37 @@ func validateGit(b Base) error {
 	return nil
 }
 
-// validateTemplateInput validate templateInput is valid for template schema
+// validateTemplateInput validate templateInput is valid for template schema,
+// previous is the template input replaced, which is nil when creating
 func (c *controller) validateTemplateInput(ctx context.Context,
-	template, release string, templateInput *TemplateInput) error {
+	template, release string, templateInput, previous *TemplateInput) error {
 	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, template, release)
 	if err != nil {
 		return err
 	}
-	schema, err := c.templateSchemaGetter.GetTemplateSchema(ctx, tr.TemplateName, tr.Name, nil)
-	if err != nil {
-		return err
+	input := &templatevalidation.Input{
+		Template:    tr.TemplateName,
+		Release:     tr.Name,
+		Application: templateInput.Application,
+		Pipeline:    templateInput.Pipeline,
 	}
-	if schema.Application.JSONSchema != nil && templateInput.Application != nil {
-		if err := jsonschema.Validate(schema.Application.JSONSchema,
-			templateInput.Application, false); err != nil {
-			return err
-		}
+	if previous != nil {
+		input.PreviousApplication = previous.Application
+		input.PreviousPipeline = previous.Pipeline
 	}
-	if schema.Pipeline.JSONSchema != nil && templateInput.Pipeline != nil {
-		if err := jsonschema.Validate(schema.Pipeline.JSONSchema, templateInput.Pipeline,
-			true); err != nil {
-			return err
-		}
+	return c.templateValidator.Validate(ctx, input)
+}
+
+// getTemplateInput gets the template input of the application in its gitops repo
+func (c *controller) getTemplateInput(ctx context.Context, application string) (*TemplateInput, error) {
+	applicationRepo, err := c.applicationGitRepo.GetApplication(ctx, application, common.ApplicationRepoDefaultEnv)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &TemplateInput{
+		Application: applicationRepo.TemplateConf,
+		Pipeline:    applicationRepo.BuildConf,
+	}, nil
 }
 
 // validatePriority validate priority
@@ -791,7 +889,17 @@ func (c *controller) List(ctx context.Context, query *q.Query) (
 		return nil, count, err
 	}
 
-	// 3. convert models.Application to ListApplicationResponse
+	// 3. get cluster summaries of applications in one query
+	applicationIDs := make([]uint, 0, len(applications))
+	for _, application := range applications {
+		applicationIDs = append(applicationIDs, application.ID)
+	}
+	summaryMap, err := c.clusterSummaryMgr.ListByApplicationIDs(ctx, applicationIDs)
+	if err != nil {
+		return nil, count, err
+	}
+
+	// 4. convert models.Application to ListApplicationResponse
 	for _, application := range applications {
 		group, exist := groupMap[application.GroupID]
 		if !exist {
@@ -802,13 +910,14 @@ func (c *controller) List(ctx context.Context, query *q.Query) (
 		listApplicationResp = append(
 			listApplicationResp,
 			&ListApplicationResponse{
-				FullName:  fullName,
-				FullPath:  fullPath,
-				Name:      application.Name,
-				GroupID:   application.GroupID,
-				ID:        application.ID,
-				CreatedAt: application.CreatedAt,
-				UpdatedAt: application.UpdatedAt,
+				FullName:       fullName,
+				FullPath:       fullPath,
+				Name:           application.Name,
+				GroupID:        application.GroupID,
+				ID:             application.ID,
+				CreatedAt:      application.CreatedAt,
+				UpdatedAt:      application.UpdatedAt,
+				ClusterSummary: ofClusterSummaries(summaryMap[application.ID]),
 			},
 		)
 	}
@@ -858,3 +967,13 @@ func (c *controller) GetApplicationPipelineStats(ctx context.Context, applicatio
 
 	return c.pipelinemanager.ListPipelineStats(ctx, app.Name, cluster, pageNumber, pageSize)
 }
+
+func (c *controller) GetDeployWindow(ctx context.Context, applicationID uint) (*deploywindow.Window, error) {
+	const op = "application controller: get deploy window"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	return c.deployWindowSvc.GetWindow(ctx, applicationID)
+}
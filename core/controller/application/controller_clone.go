@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/application/gitrepo"
+	appregionmodels "github.com/horizoncd/horizon/pkg/applicationregion/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) CloneApplication(ctx context.Context, id uint,
+	request *CloneApplicationRequest) (*CreateApplicationResponseV2, error) {
+	const op = "application controller: clone application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get the origin application with its config in the gitops repo
+	origin, err := c.GetApplicationV2(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	envConfigs, err := c.listEnvConfigs(ctx, origin)
+	if err != nil {
+		return nil, err
+	}
+	regions, err := c.applicationRegionMgr.ListByApplicationID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. create the application, the config is validated against the template again
+	groupID := request.GroupID
+	if groupID == 0 {
+		groupID = origin.GroupID
+	}
+	description := origin.Description
+	if request.Description != nil {
+		description = *request.Description
+	}
+	createRequest := &CreateOrUpdateApplicationRequestV2{
+		Name:           request.Name,
+		Description:    description,
+		Priority:       &origin.Priority,
+		Tags:           origin.Tags,
+		Git:            origin.Git,
+		BuildConfig:    origin.BuildConfig,
+		TemplateInfo:   origin.TemplateInfo,
+		TemplateConfig: origin.TemplateConfig,
+	}
+	if origin.Image != "" {
+		createRequest.Image = &origin.Image
+	}
+	resp, err := c.CreateApplicationV2(ctx, groupID, createRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. copy the env configs and the regions
+	for env, envConfig := range envConfigs {
+		if err := c.applicationGitRepo.CreateOrUpdateApplication(ctx, request.Name, gitrepo.CreateOrUpdateRequest{
+			Version:      common.MetaVersion2,
+			Environment:  env,
+			BuildConf:    envConfig.BuildConf,
+			TemplateConf: envConfig.TemplateConf,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if len(regions) > 0 {
+		cloned := make([]*appregionmodels.ApplicationRegion, 0, len(regions))
+		for _, region := range regions {
+			cloned = append(cloned, &appregionmodels.ApplicationRegion{
+				ApplicationID:   resp.ID,
+				EnvironmentName: region.EnvironmentName,
+				RegionName:      region.RegionName,
+			})
+		}
+		if err := c.applicationRegionMgr.UpsertByApplicationID(ctx, resp.ID, cloned); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// listEnvConfigs lists the configs customized for environments, the default config is returned
+// by the gitops repo for environments without their own config, which is told by the commit
+func (c *controller) listEnvConfigs(ctx context.Context,
+	application *GetApplicationResponseV2) (map[string]*gitrepo.GetResponse, error) {
+	envs, err := c.envMgr.ListAllEnvironment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	envConfigs := make(map[string]*gitrepo.GetResponse)
+	for _, env := range envs {
+		if env.Name == common.ApplicationRepoDefaultEnv {
+			continue
+		}
+		envConfig, err := c.applicationGitRepo.GetApplication(ctx, application.Name, env.Name)
+		if err != nil {
+			return nil, err
+		}
+		if envConfig.Commit == "" || envConfig.Commit == application.ConfigCommit {
+			continue
+		}
+		envConfigs[env.Name] = envConfig
+	}
+	return envConfigs, nil
+}
@@ -0,0 +1,159 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	appgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
+	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/application/gitrepo"
+	"github.com/horizoncd/horizon/pkg/application/models"
+	appregionmodels "github.com/horizoncd/horizon/pkg/applicationregion/models"
+	metadataconfig "github.com/horizoncd/horizon/pkg/config/metadata"
+	namingconfig "github.com/horizoncd/horizon/pkg/config/naming"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	groupservice "github.com/horizoncd/horizon/pkg/group/service"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/naming"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
+)
+
+func TestCloneApplication(t *testing.T) {
+	originName, cloneName := "clone-origin", "clone-copy"
+	mockCtl := gomock.NewController(t)
+	applicationGitRepo := appgitrepomock.NewMockApplicationGitRepo2(mockCtl)
+	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(ctx, "cloneapp", "v1.0.0", nil).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{
+				JSONSchema: applicationSchema,
+			},
+			Pipeline: &trschema.Schema{
+				JSONSchema: pipelineSchema,
+			},
+		}, nil).Times(1)
+
+	// only clone-dev has its own config, the default config is returned for the other environments
+	envConfig := map[string]interface{}{"app": map[string]interface{}{"envs": "dev"}}
+	applicationGitRepo.EXPECT().GetApplication(ctx, originName, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, env string) (*gitrepo.GetResponse, error) {
+			if env == "clone-dev" {
+				return &gitrepo.GetResponse{TemplateConf: envConfig, Commit: "dev"}, nil
+			}
+			return &gitrepo.GetResponse{
+				BuildConf:    pipelineJSONBlob,
+				TemplateConf: applicationJSONBlob,
+				Commit:       "default",
+			}, nil
+		}).AnyTimes()
+	createdEnvs := make(map[string]gitrepo.CreateOrUpdateRequest)
+	applicationGitRepo.EXPECT().CreateOrUpdateApplication(ctx, cloneName, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, request gitrepo.CreateOrUpdateRequest) error {
+			createdEnvs[request.Environment] = request
+			return nil
+		}).Times(2)
+
+	_, err := manager.TemplateReleaseMgr.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: "cloneapp",
+		ChartVersion: "v1.0.0",
+		Name:         "v1.0.0",
+		ChartName:    "cloneapp",
+	})
+	assert.Nil(t, err)
+	for _, env := range []string{"clone-dev", "clone-online"} {
+		_, err = manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: env, DisplayName: env})
+		assert.Nil(t, err)
+	}
+	namingSvc, err := naming.NewService(manager, namingconfig.Config{})
+	assert.Nil(t, err)
+	metadataSvc, err := metadataservice.NewService(manager, metadataconfig.Config{})
+	assert.Nil(t, err)
+	c := &controller{
+		applicationGitRepo:   applicationGitRepo,
+		templateValidator:    templatevalidation.NewService(templateSchemaGetter),
+		applicationMgr:       manager.ApplicationMgr,
+		tagMgr:               manager.TagMgr,
+		groupMgr:             manager.GroupMgr,
+		groupSvc:             groupservice.NewService(manager),
+		templateReleaseMgr:   manager.TemplateReleaseMgr,
+		eventSvc:             eventservice.New(manager),
+		memberManager:        manager.MemberMgr,
+		applicationRegionMgr: manager.ApplicationRegionMgr,
+		envMgr:               manager.EnvMgr,
+		namingSvc:            namingSvc,
+		metadataSvc:          metadataSvc,
+		quotaSvc:             quotaservice.NewService(manager),
+	}
+
+	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
+		Name: "clone",
+		Path: "clone",
+	})
+	assert.Nil(t, err)
+	origin, err := manager.ApplicationMgr.Create(ctx, &models.Application{
+		GroupID:         group.ID,
+		Name:            originName,
+		Description:     "origin",
+		Priority:        "P1",
+		Template:        "cloneapp",
+		TemplateRelease: "v1.0.0",
+	}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, manager.TagMgr.UpsertByResourceTypeID(ctx, common.ResourceApplication, origin.ID,
+		tagmodels.TagsBasic{{Key: "key1", Value: "value1"}}))
+	assert.Nil(t, manager.ApplicationRegionMgr.UpsertByApplicationID(ctx, origin.ID,
+		[]*appregionmodels.ApplicationRegion{{
+			ApplicationID:   origin.ID,
+			EnvironmentName: "clone-dev",
+			RegionName:      "hz",
+		}}))
+
+	resp, err := c.CloneApplication(ctx, origin.ID, &CloneApplicationRequest{Name: cloneName})
+	assert.Nil(t, err)
+	assert.Equal(t, group.ID, resp.GroupID)
+	assert.Equal(t, "P1", resp.Priority)
+
+	// the default config and the config of clone-dev are copied
+	assert.Equal(t, 2, len(createdEnvs))
+	assert.Equal(t, applicationJSONBlob, createdEnvs[common.ApplicationRepoDefaultEnv].TemplateConf)
+	assert.Equal(t, pipelineJSONBlob, createdEnvs[common.ApplicationRepoDefaultEnv].BuildConf)
+	assert.Equal(t, envConfig, createdEnvs["clone-dev"].TemplateConf)
+
+	cloned, err := manager.ApplicationMgr.GetByID(ctx, resp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, origin.Description, cloned.Description)
+	assert.Equal(t, origin.Template, cloned.Template)
+	assert.Equal(t, origin.TemplateRelease, cloned.TemplateRelease)
+	tags, err := manager.TagMgr.ListByResourceTypeID(ctx, common.ResourceApplication, resp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, tagmodels.TagsBasic{{Key: "key1", Value: "value1"}}, tagmodels.Tags(tags).IntoTagsBasic())
+	regions, err := manager.ApplicationRegionMgr.ListByApplicationID(ctx, resp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(regions))
+	assert.Equal(t, "clone-dev", regions[0].EnvironmentName)
+	assert.Equal(t, "hz", regions[0].RegionName)
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/application/gitrepo"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListConfigCommits(ctx context.Context, id uint, environment string,
+	pageNumber, pageSize int) ([]*pkgcommon.ConfigCommit, error) {
+	const op = "application controller: list config commits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	application, err := c.applicationMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return c.applicationGitRepo.ListConfigCommits(ctx, application.Name, environment, pageNumber, pageSize)
+}
+
+func (c *controller) RollbackConfig(ctx context.Context, id uint, r *RollbackConfigRequest) error {
+	const op = "application controller: rollback config"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if r.Commit == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "commit to rollback to is empty")
+	}
+	application, err := c.applicationMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	environment := r.Environment
+	if environment == "" {
+		environment = common.ApplicationRepoDefaultEnv
+	}
+
+	// 1. get the config of the commit and the current one of the environment
+	config, err := c.applicationGitRepo.GetApplicationByCommit(ctx, application.Name, environment, r.Commit)
+	if err != nil {
+		return err
+	}
+	if config.Commit == "" {
+		return perror.Wrapf(herrors.ErrParamInvalid, "no config is found in commit %s", r.Commit)
+	}
+	previous, err := c.applicationGitRepo.GetApplication(ctx, application.Name, environment)
+	if err != nil {
+		return err
+	}
+
+	// 2. validate the config with the current template of the application
+	if err := c.validateBuildAndTemplateConfigV2(ctx, &CreateOrUpdateApplicationRequestV2{
+		BuildConfig: config.BuildConf,
+		TemplateInfo: &codemodels.TemplateInfo{
+			Name:    application.Template,
+			Release: application.TemplateRelease,
+		},
+		TemplateConfig: config.TemplateConf,
+	}, &TemplateInput{
+		Application: previous.TemplateConf,
+		Pipeline:    previous.BuildConf,
+	}); err != nil {
+		return err
+	}
+
+	// 3. write the config back
+	if err := c.applicationGitRepo.CreateOrUpdateApplication(ctx, application.Name, gitrepo.CreateOrUpdateRequest{
+		Version:        common.MetaVersion2,
+		Environment:    environment,
+		BuildConf:      config.BuildConf,
+		TemplateConf:   config.TemplateConf,
+		ExpectedCommit: r.ConfigCommit,
+	}); err != nil {
+		return err
+	}
+
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceApplication, application.ID,
+		eventmodels.ApplicationUpdated, nil)
+	return nil
+}
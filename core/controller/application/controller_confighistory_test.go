@@ -0,0 +1,127 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
+	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/application/gitrepo"
+	"github.com/horizoncd/horizon/pkg/application/models"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
+)
+
+func TestRollbackConfig(t *testing.T) {
+	name := "rollback-config"
+	mockCtl := gomock.NewController(t)
+	applicationGitRepo := appgitrepomock.NewMockApplicationGitRepo2(mockCtl)
+	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(ctx, "rollbackapp", "v1.0.0", nil).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{
+				JSONSchema: applicationSchema,
+			},
+			Pipeline: &trschema.Schema{
+				JSONSchema: pipelineSchema,
+			},
+		}, nil).AnyTimes()
+
+	commits := []*pkgcommon.ConfigCommit{{ID: "current"}, {ID: "previous"}, {ID: "initial"}}
+	applicationGitRepo.EXPECT().ListConfigCommits(ctx, name, "", 1, 10).Return(commits, nil).Times(1)
+	applicationGitRepo.EXPECT().GetApplicationByCommit(ctx, name, common.ApplicationRepoDefaultEnv,
+		gomock.Any()).DoAndReturn(func(_ context.Context, _, _, commit string) (*gitrepo.GetResponse, error) {
+		// the initial commit has no config
+		if commit == "initial" {
+			return &gitrepo.GetResponse{}, nil
+		}
+		return &gitrepo.GetResponse{
+			BuildConf:    pipelineJSONBlob,
+			TemplateConf: applicationJSONBlob,
+			Commit:       commit,
+		}, nil
+	}).Times(2)
+	applicationGitRepo.EXPECT().GetApplication(ctx, name, common.ApplicationRepoDefaultEnv).
+		Return(&gitrepo.GetResponse{
+			BuildConf:    pipelineJSONBlob,
+			TemplateConf: map[string]interface{}{},
+			Commit:       "current",
+		}, nil).Times(1)
+	var written gitrepo.CreateOrUpdateRequest
+	applicationGitRepo.EXPECT().CreateOrUpdateApplication(ctx, name, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, request gitrepo.CreateOrUpdateRequest) error {
+			written = request
+			return nil
+		}).Times(1)
+
+	_, err := manager.TemplateReleaseMgr.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: "rollbackapp",
+		ChartVersion: "v1.0.0",
+		Name:         "v1.0.0",
+		ChartName:    "rollbackapp",
+	})
+	assert.Nil(t, err)
+	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
+		Name: "rollback",
+		Path: "rollback",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationMgr.Create(ctx, &models.Application{
+		GroupID:         group.ID,
+		Name:            name,
+		Priority:        "P1",
+		Template:        "rollbackapp",
+		TemplateRelease: "v1.0.0",
+	}, nil)
+	assert.Nil(t, err)
+	c := &controller{
+		applicationGitRepo: applicationGitRepo,
+		templateValidator:  templatevalidation.NewService(templateSchemaGetter),
+		applicationMgr:     manager.ApplicationMgr,
+		templateReleaseMgr: manager.TemplateReleaseMgr,
+		eventSvc:           eventservice.New(manager),
+	}
+
+	got, err := c.ListConfigCommits(ctx, application.ID, "", 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, commits, got)
+
+	err = c.RollbackConfig(ctx, application.ID, &RollbackConfigRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = c.RollbackConfig(ctx, application.ID, &RollbackConfigRequest{Commit: "initial"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	err = c.RollbackConfig(ctx, application.ID, &RollbackConfigRequest{
+		Commit:       "previous",
+		ConfigCommit: "current",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, common.ApplicationRepoDefaultEnv, written.Environment)
+	assert.Equal(t, applicationJSONBlob, written.TemplateConf)
+	assert.Equal(t, pipelineJSONBlob, written.BuildConf)
+	assert.Equal(t, "current", written.ExpectedCommit)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	templaterender "github.com/horizoncd/horizon/pkg/templaterelease/render"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) DryRunApplicationV2(ctx context.Context, id uint, environment string,
+	request *CreateOrUpdateApplicationRequestV2) (*templaterender.Preview, error) {
+	const op = "application controller: dry run application v2"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get the current template and template config
+	app, err := c.applicationMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if environment == "" {
+		environment = common.ApplicationRepoDefaultEnv
+	}
+	applicationRepo, err := c.applicationGitRepo.GetApplication(ctx, app.Name, environment)
+	if err != nil {
+		return nil, err
+	}
+	var current *templaterender.Input
+	if app.Template != "" {
+		tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, app.Template, app.TemplateRelease)
+		if err != nil {
+			return nil, err
+		}
+		current = renderInput(app.Name, tr, applicationRepo.TemplateConf)
+	}
+
+	// 2. validate the template config in the request as updating does
+	templateInfo, templateConfig := request.TemplateInfo, request.TemplateConfig
+	if templateInfo == nil {
+		if app.Template == "" {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "template info is required")
+		}
+		templateInfo = &codemodels.TemplateInfo{Name: app.Template, Release: app.TemplateRelease}
+	}
+	if templateConfig == nil {
+		templateConfig = applicationRepo.TemplateConf
+	} else if err := c.validateTemplateInput(ctx, templateInfo.Name, templateInfo.Release,
+		&TemplateInput{Application: templateConfig}, &TemplateInput{
+			Application: applicationRepo.TemplateConf,
+			Pipeline:    applicationRepo.BuildConf,
+		}); err != nil {
+		return nil, err
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateInfo.Name, templateInfo.Release)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. render the manifests and compare them with the current ones
+	return c.templateRenderer.Preview(ctx, current, renderInput(app.Name, tr, templateConfig))
+}
+
+// renderInput renders the template config as the application value file of clusters,
+// the release is named after the application as there is no cluster yet
+func renderInput(application string, tr *trmodels.TemplateRelease,
+	templateConfig map[string]interface{}) *templaterender.Input {
+	return &templaterender.Input{
+		TemplateRelease: tr,
+		ReleaseName:     application,
+		ValueFiles:      []string{common.GitopsFileApplication},
+		Values: map[string]interface{}{
+			common.GitopsFileApplication: map[string]interface{}{
+				tr.ChartName: templateConfig,
+			},
+		},
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	asynctaskmodels "github.com/horizoncd/horizon/pkg/asynctask/models"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// _maxImportApplications bounds the applications of an import, which are created one by one in a task
+const _maxImportApplications = 100
+
+func (c *controller) ImportApplications(ctx context.Context, groupID uint,
+	request *ImportApplicationsRequest) (*ImportApplicationsAsyncResponse, error) {
+	const op = "application controller: import applications"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if len(request.Applications) == 0 || len(request.Applications) > _maxImportApplications {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"count of applications must be between 1 and %d", _maxImportApplications)
+	}
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	seen := make(map[string]bool, len(request.Applications))
+	for _, app := range request.Applications {
+		if app == nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "application is empty")
+		}
+		if seen[app.Name] {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "application %s is duplicated", app.Name)
+		}
+		seen[app.Name] = true
+		// invalid names fail the whole import at once instead of failing in background one by one
+		if err := validateApplicationName(app.Name); err != nil {
+			return nil, err
+		}
+		result.Applications = append(result.Applications, &ApplicationImportResult{
+			Name:   app.Name,
+			Status: ImportStatusPending,
+		})
+	}
+
+	task, err := c.asyncTaskSvc.Submit(ctx, asynctaskmodels.TypeApplicationImport,
+		func(ctx context.Context, reporter asynctaskservice.Reporter) (interface{}, error) {
+			err := c.importApplications(ctx, reporter, groupID, request, result)
+			return result, err
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &ImportApplicationsAsyncResponse{TaskID: task.ID}, nil
+}
+
+// importApplications creates all the applications even if some of them fail, and fails if any of them fails
+func (c *controller) importApplications(ctx context.Context, reporter asynctaskservice.Reporter,
+	groupID uint, request *ImportApplicationsRequest, result *ImportResult) error {
+	total, failed := len(result.Applications), 0
+	for i, app := range result.Applications {
+		reporter.Progress(i, total, fmt.Sprintf("creating application %s", app.Name))
+		resp, err := c.CreateApplicationV2(ctx, groupID, request.Applications[i])
+		if err != nil {
+			app.Status = ImportStatusFailed
+			app.Message = err.Error()
+			failed++
+		} else {
+			app.Status = ImportStatusCreated
+			app.ApplicationID = resp.ID
+		}
+		reporter.Result(result)
+	}
+	reporter.Progress(total, total, "imported")
+	if failed > 0 {
+		return perror.Errorf("%d of %d application(s) failed to create", failed, total)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListDeleted(ctx context.Context, groupID uint) ([]*DeletedApplication, error) {
+	const op = "application controller: list deleted applications"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	applications, err := c.applicationMgr.ListDeleted(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*DeletedApplication, 0, len(applications))
+	for _, application := range applications {
+		resp = append(resp, ofDeletedApplication(application))
+	}
+	return resp, nil
+}
+
+func (c *controller) Restore(ctx context.Context, groupID, id uint) error {
+	const op = "application controller: restore application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get the deleted application under the group
+	app, err := c.applicationMgr.GetByIDIncludeSoftDelete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if app.DeletedTs == 0 || app.HardDeleted || app.GroupID != groupID {
+		return herrors.NewErrNotFound(herrors.ApplicationInDB, "deleted application not found")
+	}
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return err
+	}
+	if err := c.quotaSvc.Check(ctx, groupID, quotamodels.ResourceApplications); err != nil {
+		return err
+	}
+
+	// 2. check groups or applications with the same name exists
+	groups, err := c.groupMgr.GetByNameOrPathUnderParent(ctx, app.Name, app.Name, groupID)
+	if err != nil {
+		return err
+	}
+	if len(groups) > 0 {
+		return perror.Wrap(herrors.ErrNameConflict, "an group with the same name already exists")
+	}
+	if _, err := c.applicationMgr.GetByName(ctx, app.Name); err == nil {
+		return perror.Wrap(herrors.ErrNameConflict, "an application with the same name already exists")
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return err
+	}
+
+	// 3. restore application in db, its git repo and members are kept while it is deleted
+	if err := c.applicationMgr.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	// 4. record event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceApplication, id,
+		eventmodels.ApplicationRestored, nil)
+	return nil
+}
+
+// checkDeletedName returns ErrNameConflict if an application deleted but not purged yet has the name,
+// which is kept for the application to be restored
+func (c *controller) checkDeletedName(ctx context.Context, name string) error {
+	applications, err := c.applicationMgr.GetDeletedByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(applications) > 0 {
+		return perror.Wrap(herrors.ErrNameConflict, "an application with the same name was deleted "+
+			"and can be restored, the name can not be used until it is purged")
+	}
+	return nil
+}
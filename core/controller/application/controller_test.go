@@ -20,28 +20,42 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	appgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
 	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
 	"github.com/horizoncd/horizon/pkg/application/gitrepo"
 	"github.com/horizoncd/horizon/pkg/application/models"
+	appregionmodels "github.com/horizoncd/horizon/pkg/applicationregion/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	csmodels "github.com/horizoncd/horizon/pkg/clustersummary/models"
+	metadataconfig "github.com/horizoncd/horizon/pkg/config/metadata"
+	namingconfig "github.com/horizoncd/horizon/pkg/config/naming"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	metadatamodels "github.com/horizoncd/horizon/pkg/metadata/models"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/naming"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 
@@ -298,6 +312,21 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&usermodel.User{}); err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&csmodels.ClusterSummary{}); err != nil {
+		panic(err)
+	}
+	if err := db.AutoMigrate(&metadatamodels.Metadata{}); err != nil {
+		panic(err)
+	}
+	if err := db.AutoMigrate(&quotamodels.GroupQuota{}); err != nil {
+		panic(err)
+	}
+	if err := db.AutoMigrate(&appregionmodels.ApplicationRegion{}); err != nil {
+		panic(err)
+	}
+	if err := db.AutoMigrate(&envmodels.Environment{}); err != nil {
+		panic(err)
+	}
 	ctx = context.TODO()
 	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
 		Name: "Tony",
@@ -357,9 +386,14 @@ func Test(t *testing.T) {
 	_, err := manager.TemplateReleaseMgr.Create(ctx, tr)
 	assert.Nil(t, err)
 
+	namingSvc, err := naming.NewService(manager, namingconfig.Config{})
+	assert.Nil(t, err)
+	metadataSvc, err := metadataservice.NewService(manager, metadataconfig.Config{})
+	assert.Nil(t, err)
+
 	c = &controller{
 		applicationGitRepo:   applicationGitRepo,
-		templateSchemaGetter: templateSchemaGetter,
+		templateValidator:    templatevalidation.NewService(templateSchemaGetter),
 		tagMgr:               manager.TagMgr,
 		applicationMgr:       manager.ApplicationMgr,
 		groupMgr:             manager.GroupMgr,
@@ -369,6 +403,10 @@ func Test(t *testing.T) {
 		userSvc:              userservice.NewService(manager),
 		eventSvc:             eventservice.New(manager),
 		memberManager:        manager.MemberMgr,
+		applicationRegionMgr: manager.ApplicationRegionMgr,
+		namingSvc:            namingSvc,
+		metadataSvc:          metadataSvc,
+		quotaSvc:             quotaservice.NewService(manager),
 	}
 
 	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
@@ -448,6 +486,35 @@ func Test(t *testing.T) {
 	err = c.DeleteApplication(ctx, resp.ID, false)
 	assert.Nil(t, err)
 
+	// the name of an application soft deleted is kept for it to be restored
+	_, err = c.CreateApplication(ctx, group.ID, createRequest)
+	assert.True(t, perror.Cause(err) == herrors.ErrNameConflict)
+
+	deleted, err := c.ListDeleted(ctx, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(deleted))
+	assert.Equal(t, resp.ID, deleted[0].ID)
+	assert.Equal(t, appName, deleted[0].Name)
+
+	err = c.Restore(ctx, group.ID+1, resp.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	err = c.Restore(ctx, group.ID, resp.ID)
+	assert.Nil(t, err)
+	resp, err = c.GetApplication(ctx, resp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, updatedDescription, resp.Description)
+	deleted, err = c.ListDeleted(ctx, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(deleted))
+
+	// the name of an application hard deleted can be used at once
+	err = c.DeleteApplication(ctx, resp.ID, true)
+	assert.Nil(t, err)
+	deleted, err = c.ListDeleted(ctx, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(deleted))
+
 	resp, err = c.CreateApplication(ctx, group.ID, createRequest)
 	if err != nil {
 		t.Logf("%v", err)
@@ -513,18 +580,25 @@ func TestV2(t *testing.T) {
 	}
 	_, err := manager.TemplateReleaseMgr.Create(ctx, tr)
 	assert.Nil(t, err)
+	namingSvc, err := naming.NewService(manager, namingconfig.Config{})
+	assert.Nil(t, err)
+	metadataSvc, err := metadataservice.NewService(manager, metadataconfig.Config{})
+	assert.Nil(t, err)
 	c := &controller{
-		applicationGitRepo:   applicationGitRepo,
-		templateSchemaGetter: templateSchemaGetter,
-		applicationMgr:       manager.ApplicationMgr,
-		tagMgr:               manager.TagMgr,
-		groupMgr:             manager.GroupMgr,
-		groupSvc:             groupservice.NewService(manager),
-		templateReleaseMgr:   manager.TemplateReleaseMgr,
-		clusterMgr:           manager.ClusterMgr,
-		userSvc:              userservice.NewService(manager),
-		eventSvc:             eventservice.New(manager),
-		memberManager:        manager.MemberMgr,
+		applicationGitRepo: applicationGitRepo,
+		templateValidator:  templatevalidation.NewService(templateSchemaGetter),
+		applicationMgr:     manager.ApplicationMgr,
+		tagMgr:             manager.TagMgr,
+		groupMgr:           manager.GroupMgr,
+		groupSvc:           groupservice.NewService(manager),
+		templateReleaseMgr: manager.TemplateReleaseMgr,
+		clusterMgr:         manager.ClusterMgr,
+		userSvc:            userservice.NewService(manager),
+		eventSvc:           eventservice.New(manager),
+		memberManager:      manager.MemberMgr,
+		namingSvc:          namingSvc,
+		metadataSvc:        metadataSvc,
+		quotaSvc:           quotaservice.NewService(manager),
 	}
 
 	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
@@ -674,10 +748,24 @@ func TestListUserApplication(t *testing.T) {
 	}
 
 	c = &controller{
-		applicationMgr: manager.ApplicationMgr,
-		groupMgr:       manager.GroupMgr,
-		groupSvc:       groupservice.NewService(manager),
-		memberManager:  manager.MemberMgr,
+		applicationMgr:    manager.ApplicationMgr,
+		clusterSummaryMgr: manager.ClusterSummaryMgr,
+		groupMgr:          manager.GroupMgr,
+		groupSvc:          groupservice.NewService(manager),
+		memberManager:     manager.MemberMgr,
+	}
+
+	deployedAt := time.Now()
+	for i, env := range []string{"test", "online"} {
+		err := manager.ClusterSummaryMgr.Upsert(ctx, &csmodels.ClusterSummary{
+			ClusterID:         uint(i + 1),
+			ApplicationID:     applications[1].ID,
+			EnvironmentName:   env,
+			PipelinerunID:     uint(i + 1),
+			PipelinerunStatus: "ok",
+			DeployedAt:        &deployedAt,
+		})
+		assert.Nil(t, err)
 	}
 
 	// nolint
@@ -716,6 +804,10 @@ func TestListUserApplication(t *testing.T) {
 	assert.Equal(t, 2, count)
 	assert.Equal(t, "appFuzzily1", resps[0].Name)
 	assert.Equal(t, "appFuzzily0", resps[1].Name)
+	assert.Equal(t, 2, resps[0].ClusterSummary.ClusterCount)
+	assert.Equal(t, []string{"test", "online"}, resps[0].ClusterSummary.Environments)
+	assert.Equal(t, "ok", resps[0].ClusterSummary.LatestDeployStatus)
+	assert.Nil(t, resps[1].ClusterSummary)
 	for _, resp := range resps {
 		t.Logf("%v", resp)
 	}
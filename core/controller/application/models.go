@@ -19,6 +19,7 @@ import (
 
 	"github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	csmodels "github.com/horizoncd/horizon/pkg/clustersummary/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 )
@@ -61,13 +62,69 @@ type GetApplicationResponse struct {
 }
 
 type ListApplicationResponse struct {
-	FullPath  string    `json:"fullPath"`
-	FullName  string    `json:"fullName"`
-	Name      string    `json:"name"`
-	ID        uint      `json:"id"`
-	GroupID   uint      `json:"groupID"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	FullPath       string          `json:"fullPath"`
+	FullName       string          `json:"fullName"`
+	Name           string          `json:"name"`
+	ID             uint            `json:"id"`
+	GroupID        uint            `json:"groupID"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+	ClusterSummary *ClusterSummary `json:"clusterSummary,omitempty"`
+}
+
+// DeletedApplication is an application soft deleted which can be restored until it is purged
+type DeletedApplication struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Priority    string    `json:"priority"`
+	GroupID     uint      `json:"groupID"`
+	DeletedBy   uint      `json:"deletedBy"`
+	DeletedAt   time.Time `json:"deletedAt"`
+}
+
+func ofDeletedApplication(application *models.Application) *DeletedApplication {
+	return &DeletedApplication{
+		ID:          application.ID,
+		Name:        application.Name,
+		Description: application.Description,
+		Priority:    string(application.Priority),
+		GroupID:     application.GroupID,
+		DeletedBy:   application.UpdatedBy,
+		DeletedAt:   time.Unix(int64(application.DeletedTs), 0),
+	}
+}
+
+// ClusterSummary aggregates cluster summaries of an application
+type ClusterSummary struct {
+	ClusterCount int      `json:"clusterCount"`
+	Environments []string `json:"environments"`
+	// LatestDeployStatus is the status of the latest deploy pipelinerun among clusters of the application
+	LatestDeployStatus string     `json:"latestDeployStatus,omitempty"`
+	LatestDeployedAt   *time.Time `json:"latestDeployedAt,omitempty"`
+}
+
+func ofClusterSummaries(summaries []*csmodels.ClusterSummary) *ClusterSummary {
+	if len(summaries) == 0 {
+		return nil
+	}
+	resp := &ClusterSummary{
+		ClusterCount: len(summaries),
+		Environments: []string{},
+	}
+	envs := map[string]struct{}{}
+	for _, summary := range summaries {
+		if _, ok := envs[summary.EnvironmentName]; !ok {
+			envs[summary.EnvironmentName] = struct{}{}
+			resp.Environments = append(resp.Environments, summary.EnvironmentName)
+		}
+		if summary.DeployedAt != nil &&
+			(resp.LatestDeployedAt == nil || summary.DeployedAt.After(*resp.LatestDeployedAt)) {
+			resp.LatestDeployedAt = summary.DeployedAt
+			resp.LatestDeployStatus = summary.PipelinerunStatus
+		}
+	}
+	return resp
 }
 
 // Template struct about template
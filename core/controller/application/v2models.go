@@ -30,11 +30,14 @@ type GetApplicationResponseV2 struct {
 	Tags        tagmodels.TagsBasic `json:"tags,omitempty"`
 	Git         *codemodels.Git     `json:"git"`
 	Image       string              `json:"image"`
+	// Metadata are the values of the metadata fields defined by the installation, such as tier
+	Metadata map[string]string `json:"metadata"`
 
 	BuildConfig    map[string]interface{}   `json:"buildConfig"`
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
 	TemplateConfig map[string]interface{}   `json:"templateConfig"`
 	Manifest       map[string]interface{}   `json:"manifest"`
+	ConfigCommit   string                   `json:"configCommit"`
 
 	FullPath string `json:"fullPath"`
 	GroupID  uint   `json:"groupID"`
@@ -45,15 +48,18 @@ type GetApplicationResponseV2 struct {
 
 // CreateOrUpdateApplicationRequestV2 holds the parameters required to create an application
 type CreateOrUpdateApplicationRequestV2 struct {
-	Name           string                   `json:"name"`
+	Name           string                   `json:"name" binding:"omitempty,max=40,resourcename"`
 	Description    string                   `json:"description"`
 	Priority       *string                  `json:"priority"`
 	Tags           tagmodels.TagsBasic      `json:"tags,omitempty"`
 	Git            *codemodels.Git          `json:"git"`
 	Image          *string                  `json:"image"`
-	BuildConfig    map[string]interface{}   `json:"buildConfig"`
+	BuildConfig    map[string]interface{}   `json:"buildConfig" binding:"omitempty,templatevalues"`
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
-	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig" binding:"omitempty,templatevalues"`
+	// ConfigCommit is the config commit which the update is based on, the update fails
+	// with a conflict if config has been changed since it
+	ConfigCommit string `json:"configCommit"`
 
 	// TODO(remove it): only for internal usage
 	ExtraMembers map[string]string `json:"extraMembers"`
@@ -157,3 +163,48 @@ type CreateApplicationResponseV2 struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+const (
+	ImportStatusPending = "pending"
+	ImportStatusCreated = "created"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportApplicationsRequest creates the applications in a group, such as the ones migrated from other platforms
+type ImportApplicationsRequest struct {
+	Applications []*CreateOrUpdateApplicationRequestV2 `json:"applications"`
+}
+
+type ImportApplicationsAsyncResponse struct {
+	TaskID uint `json:"taskID"`
+}
+
+// ImportResult is the result of the import task, it's updated as the applications are created
+type ImportResult struct {
+	Applications []*ApplicationImportResult `json:"applications"`
+}
+
+type ApplicationImportResult struct {
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	ApplicationID uint   `json:"applicationID,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// CloneApplicationRequest creates an application with the config, regions and tags of an existing one
+type CloneApplicationRequest struct {
+	Name string `json:"name" binding:"required,max=40,resourcename"`
+	// Description is copied from the origin application if it's nil
+	Description *string `json:"description"`
+	// GroupID is the group to create the application in, it's the group of the origin application if it's 0
+	GroupID uint `json:"groupID"`
+}
+
+type RollbackConfigRequest struct {
+	// Environment is the environment whose config is rolled back, the default config is if it's empty
+	Environment string `json:"environment"`
+	// Commit is the commit of the config to rollback to
+	Commit string `json:"commit"`
+	// ConfigCommit is the config commit which the rollback is based on, like the one of updates
+	ConfigCommit string `json:"configCommit"`
+}
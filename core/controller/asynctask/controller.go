@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asynctask
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	asynctaskmanager "github.com/horizoncd/horizon/pkg/asynctask/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Get returns the progress, result and error of the task, only its creator, admins and auditors can read it
+	Get(ctx context.Context, id uint) (*AsyncTask, error)
+}
+
+type controller struct {
+	taskMgr asynctaskmanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		taskMgr: param.AsyncTaskMgr,
+	}
+}
+
+func (c *controller) Get(ctx context.Context, id uint) (_ *AsyncTask, err error) {
+	const op = "async task controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := c.taskMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() && !currentUser.IsAuditor() && task.CreatedBy != currentUser.GetID() {
+		return nil, perror.Wrap(herrors.ErrNoPrivilege, "could not get task\n"+
+			"should be admin, auditor or task creator")
+	}
+	return ofAsyncTask(task), nil
+}
@@ -0,0 +1,56 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asynctask
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/asynctask/models"
+)
+
+type AsyncTask struct {
+	ID      uint   `json:"id"`
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+	Message string `json:"message"`
+	// Result is the final result of a finished task, or the partial result of a running one
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	CreatedBy  uint            `json:"createdBy"`
+}
+
+func ofAsyncTask(task *models.AsyncTask) *AsyncTask {
+	t := &AsyncTask{
+		ID:         task.ID,
+		Type:       task.Type,
+		Status:     task.Status,
+		Done:       task.Done,
+		Total:      task.Total,
+		Message:    task.Message,
+		Error:      task.ErrorMessage,
+		CreatedAt:  task.CreatedAt,
+		FinishedAt: task.FinishedAt,
+		CreatedBy:  task.CreatedBy,
+	}
+	if task.Result != "" {
+		t.Result = json.RawMessage(task.Result)
+	}
+	return t
+}
@@ -0,0 +1,59 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auditlog/manager"
+	"github.com/horizoncd/horizon/pkg/auditlog/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// List lists the audit logs of mutating requests, latest first,
+	// only admins and auditors can list them
+	List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int, error)
+}
+
+type controller struct {
+	auditLogMgr manager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		auditLogMgr: param.AuditLogMgr,
+	}
+}
+
+func (c *controller) List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int, error) {
+	const op = "auditlog controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !currentUser.IsAdmin() && !currentUser.IsAuditor() {
+		return nil, 0, perror.Wrap(herrors.ErrNoPrivilege, "could not list audit logs\n"+
+			"should be admin or auditor")
+	}
+	return c.auditLogMgr.List(ctx, query)
+}
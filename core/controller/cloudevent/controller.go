@@ -26,6 +26,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
@@ -52,6 +54,7 @@ type controller struct {
 	templateReleaseMgr trmanager.Manager
 	applicationMgr     applicationmanager.Manager
 	userMgr            usermanager.Manager
+	eventSvc           eventservice.Service
 }
 
 func NewController(tektonFty factory.Factory, parameter *param.Param) Controller {
@@ -64,6 +67,7 @@ func NewController(tektonFty factory.Factory, parameter *param.Param) Controller
 		templateReleaseMgr: parameter.TemplateReleaseMgr,
 		applicationMgr:     parameter.ApplicationMgr,
 		userMgr:            parameter.UserMgr,
+		eventSvc:           parameter.EventSvc,
 	}
 }
 
@@ -110,6 +114,8 @@ func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (e
 	}); err != nil {
 		return err
 	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pipelinerunID,
+		eventmodels.PipelinerunFinished, nil)
 
 	// format Pipeline results
 	pipelineResult := tekton.FormatPipelineResults(wpr.PipelineRun)
@@ -16,6 +16,8 @@ package cluster
 
 import (
 	"context"
+	"io"
+	"net/url"
 
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -26,16 +28,32 @@ import (
 	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
 	"github.com/horizoncd/horizon/pkg/cd"
+	changerequestmanager "github.com/horizoncd/horizon/pkg/changerequest/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/envvar"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	registryfty "github.com/horizoncd/horizon/pkg/cluster/registry/factory"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	envchangemanager "github.com/horizoncd/horizon/pkg/clusterenv/manager"
+	snapshotmanager "github.com/horizoncd/horizon/pkg/clustersnapshot/manager"
+	snapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	csmanager "github.com/horizoncd/horizon/pkg/clustersummary/manager"
 	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	changerequestconfig "github.com/horizoncd/horizon/pkg/config/changerequest"
+	deployapprovalconfig "github.com/horizoncd/horizon/pkg/config/deployapproval"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	networkpolicyconfig "github.com/horizoncd/horizon/pkg/config/networkpolicy"
+	sandboxconfig "github.com/horizoncd/horizon/pkg/config/sandbox"
+	sbomconfig "github.com/horizoncd/horizon/pkg/config/sbom"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	deployapprovalmanager "github.com/horizoncd/horizon/pkg/deployapproval/manager"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	environmentregionmapper "github.com/horizoncd/horizon/pkg/environmentregion/manager"
@@ -43,17 +61,26 @@ import (
 	grafanaservice "github.com/horizoncd/horizon/pkg/grafana"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
+	"github.com/horizoncd/horizon/pkg/manifestpolicy"
 	"github.com/horizoncd/horizon/pkg/member"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/naming"
 	"github.com/horizoncd/horizon/pkg/param"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
+	"github.com/horizoncd/horizon/pkg/rbac"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	scheduleddeploymanager "github.com/horizoncd/horizon/pkg/scheduleddeploy/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
+	templaterender "github.com/horizoncd/horizon/pkg/templaterelease/render"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -89,6 +116,8 @@ type Controller interface {
 
 	ExecuteAction(ctx context.Context, clusterID uint, action string,
 		gvk schema.GroupVersionResource) error
+	// ExecuteRolloutAction pauses, resumes, promotes, aborts or retries the step by step deploy of a cluster
+	ExecuteRolloutAction(ctx context.Context, clusterID uint, action string) error
 
 	// Deprecated: GetClusterStatus
 	GetClusterStatus(ctx context.Context, clusterID uint) (_ *GetClusterStatusResponse, err error)
@@ -101,18 +130,35 @@ type Controller interface {
 	GetDiff(ctx context.Context, clusterID uint, refType, ref string) (*GetDiffResponse, error)
 	GetContainerLog(ctx context.Context, clusterID uint, podName, containerName string, tailLines int64) (
 		<-chan string, error)
+	// StreamContainerLog opens the log stream of a container of the cluster's pod, which must be closed by the caller
+	StreamContainerLog(ctx context.Context, clusterID uint, podName, containerName string,
+		options *ContainerLogOptions) (io.ReadCloser, error)
 
 	DeleteClusterPods(ctx context.Context, clusterID uint, podName []string) (BatchResponse, error)
 	GetClusterPod(ctx context.Context, clusterID uint, podName string) (
 		*GetClusterPodResponse, error)
+	// GetPodProvenance traces the pod back to the pipelinerun and the commit which it's built from
+	GetPodProvenance(ctx context.Context, clusterID uint, podName string) (*PodProvenance, error)
 
 	GetPodEvents(ctx context.Context, clusterID uint, podName string) (interface{}, error)
 	GetContainers(ctx context.Context, clusterID uint, podName string) (interface{}, error)
 	GetGrafanaDashBoard(c context.Context, clusterID uint) (*GetGrafanaDashboardsResponse, error)
+	// KubeProxy forwards read-only kubernetes requests in the cluster's namespace
+	KubeProxy(ctx context.Context, clusterID uint, path string, query url.Values) ([]byte, error)
+	// ListResources lists the kubernetes resources managed for the cluster, filtered by kind if it's not empty
+	ListResources(ctx context.Context, clusterID uint, kind string) ([]*cd.ClusterResource, error)
+	// GetResource gets the yaml view of a kubernetes resource managed for the cluster
+	GetResource(ctx context.Context, clusterID uint, kind, name string) (*cd.ClusterResourceDetail, error)
 
 	CreateClusterV2(ctx context.Context, params *CreateClusterParamsV2) (*CreateClusterResponseV2, error)
+	// CreateClusterV2Async creates the cluster in background, the progress can be polled by the returned task id
+	CreateClusterV2Async(ctx context.Context, params *CreateClusterParamsV2) (*CreateClusterAsyncResponse, error)
 	GetClusterV2(ctx context.Context, clusterID uint) (*GetClusterResponseV2, error)
 	UpdateClusterV2(ctx context.Context, clusterID uint, r *UpdateClusterRequestV2, mergePatch bool) error
+	// DryRunClusterV2 renders the values and kubernetes manifests the cluster would be updated to by the request
+	// without committing them, and compares them with the current ones
+	DryRunClusterV2(ctx context.Context, clusterID uint, r *UpdateClusterRequestV2,
+		mergePatch bool) (*templaterender.Preview, error)
 	// InternalDeployV2 deploy only used by internal system
 	InternalDeployV2(ctx context.Context, clusterID uint,
 		r *InternalDeployRequestV2) (_ *InternalDeployResponseV2, err error)
@@ -120,15 +166,84 @@ type Controller interface {
 	GetClusterStatusV2(ctx context.Context, clusterID uint) (_ *StatusResponseV2, err error)
 	GetClusterPipelinerunStatus(ctx context.Context, clusterID uint) (*PipelinerunStatusResponse, error)
 	GetResourceTree(ctx context.Context, clusterID uint) (*GetResourceTreeResponse, error)
+	// GetDrift compares the workloads declared in the gitops branch of the cluster with the live ones
+	GetDrift(ctx context.Context, clusterID uint) (*cd.ClusterDrift, error)
 	GetStep(ctx context.Context, clusterID uint) (resp *GetStepResponse, err error)
+	// GetAutoscaling gets the autoscaling config of the cluster and the live status of its autoscaler
+	GetAutoscaling(ctx context.Context, clusterID uint) (*GetAutoscalingResponse, error)
+	// UpdateAutoscaling updates the autoscaling config of the cluster, it takes effect after the next deploy.
+	// An empty config removes the autoscaler.
+	UpdateAutoscaling(ctx context.Context, clusterID uint, config *autoscaling.Config) error
 	// Deprecated: for internal usage, v1 to v2
 	Upgrade(ctx context.Context, clusterID uint) error
+	// GetTemplateUpgradePlan computes the value migration to another release of the cluster's template
+	GetTemplateUpgradePlan(ctx context.Context, clusterID uint, templateRelease string) (*TemplateUpgradePlan, error)
+	// UpgradeTemplate migrates cluster's config to another template release and creates a pipelinerun to deploy it
+	UpgradeTemplate(ctx context.Context, clusterID uint, r *TemplateUpgradeRequest) (*prmodels.PipelineBasic, error)
 	ToggleLikeStatus(ctx context.Context, clusterID uint, like *WhetherLike) (err error)
 	CreatePipelineRun(ctx context.Context, clusterID uint, r *CreatePipelineRunRequest) (*prmodels.PipelineBasic, error)
+	// ListSnapshots lists config snapshots of the cluster, the latest first
+	ListSnapshots(ctx context.Context, clusterID uint, query *q.Query) (int, []*Snapshot, error)
+	// RestoreSnapshot restores the config and metadata of the cluster to the snapshot without deploying
+	RestoreSnapshot(ctx context.Context, clusterID, snapshotID uint) error
+	// ListConfigCommits lists the commits changing the config of the cluster in its gitops repo, the latest first
+	ListConfigCommits(ctx context.Context, clusterID uint, pageNumber, pageSize int) ([]*pkgcommon.ConfigCommit, error)
+	// RollbackConfig restores the config of the cluster to the one of the commit without deploying
+	RollbackConfig(ctx context.Context, clusterID uint, r *RollbackConfigRequest) error
+	// ListEnvs lists the environment variables in the cluster's config, values of secrets are masked
+	ListEnvs(ctx context.Context, clusterID uint) ([]*envvar.EnvVar, error)
+	// AddEnv adds an environment variable to the cluster's config, it takes effect after the next deploy
+	AddEnv(ctx context.Context, clusterID uint, env *envvar.EnvVar) error
+	UpdateEnv(ctx context.Context, clusterID uint, name string, r *UpdateEnvRequest) error
+	DeleteEnv(ctx context.Context, clusterID uint, name string) error
+	// ListEnvHistory lists the changes of the cluster's environment variables, the latest first.
+	// Only changes of the variable are listed if name is not empty.
+	ListEnvHistory(ctx context.Context, clusterID uint, name string, query *q.Query) (int, []*EnvChange, error)
+	// ReleaseClusters deploys clusters of the group in background stage by stage in their dependency order,
+	// the next stage starts after all clusters of the previous stage are healthy and the release halts on failures
+	ReleaseClusters(ctx context.Context, groupID uint, r *ReleaseRequest) (*ReleaseAsyncResponse, error)
+	// BatchDeploy deploys clusters of the application one by one in background,
+	// the clusters left are deployed even if some of them fail
+	BatchDeploy(ctx context.Context, applicationID uint, r *BatchDeployRequest) (*BatchDeployAsyncResponse, error)
+	// CreateChangeRequest writes the proposed config changes to a new branch and opens a merge request for review
+	CreateChangeRequest(ctx context.Context, clusterID uint,
+		r *CreateChangeRequestRequest, mergePatch bool) (*ChangeRequest, error)
+	// ListChangeRequests lists change requests of the cluster in the status, latest first
+	ListChangeRequests(ctx context.Context, clusterID uint, status string) ([]*ChangeRequest, error)
+	// GetChangeRequest gets the change request with the config diff proposed if it's pending
+	GetChangeRequest(ctx context.Context, clusterID, changeRequestID uint) (*ChangeRequest, error)
+	// ReviewChangeRequest approves or rejects a pending change request, config changes are merged when approved.
+	// The creator of the change request can not review it.
+	ReviewChangeRequest(ctx context.Context, clusterID, changeRequestID uint,
+		r *ReviewChangeRequestRequest) (*ChangeRequest, error)
+	// CreateScheduledDeploy schedules a builddeploy or restart of the cluster, which is triggered
+	// as the current user at the time scheduled
+	CreateScheduledDeploy(ctx context.Context, clusterID uint,
+		r *CreateScheduledDeployRequest) (*ScheduledDeploy, error)
+	// ListScheduledDeploys lists scheduled deploys of the cluster in the status, latest scheduled first
+	ListScheduledDeploys(ctx context.Context, clusterID uint, status string) ([]*ScheduledDeploy, error)
+	// CancelScheduledDeploy cancels a scheduled deploy which is not triggered yet
+	CancelScheduledDeploy(ctx context.Context, clusterID, scheduledDeployID uint) error
+
+	// CreateSandbox creates a short-lived cluster with limited resources for the current user to try out,
+	// the cluster is freed by auto-free once it expires
+	CreateSandbox(ctx context.Context, r *CreateSandboxRequest) (*Sandbox, error)
+	// ListSandboxes lists the sandbox clusters of the current user
+	ListSandboxes(ctx context.Context) ([]*Sandbox, error)
+
+	// ListDeleted lists clusters soft deleted under the application which are not purged yet
+	ListDeleted(ctx context.Context, applicationID uint) ([]*DeletedCluster, error)
+	// Restore restores a cluster soft deleted under the application, the cluster restored is freed
+	// and can be deployed again
+	Restore(ctx context.Context, applicationID, clusterID uint) error
+	// CloneCluster creates a cluster with the config and tags of the cluster, the config is read
+	// from its gitops repo and rendered again in the environment and region of the request
+	CloneCluster(ctx context.Context, clusterID uint, request *CloneClusterRequest) (*CreateClusterResponseV2, error)
 }
 
 type controller struct {
 	clusterMgr            clustermanager.Manager
+	clusterSummaryMgr     csmanager.Manager
 	clusterGitRepo        gitrepo.ClusterGitRepo
 	applicationGitRepo    appgitrepo.ApplicationGitRepo
 	commitGetter          code.GitGetter
@@ -140,6 +255,8 @@ type controller struct {
 	templateMgr           templatemanager.Manager
 	templateReleaseMgr    trmanager.Manager
 	templateSchemaGetter  templateschema.Getter
+	templateValidator     templatevalidation.Service
+	templateRenderer      templaterender.Renderer
 	outputGetter          output.Getter
 	envMgr                envmanager.Manager
 	envRegionMgr          environmentregionmapper.Manager
@@ -148,6 +265,7 @@ type controller struct {
 	prMgr                 *prmanager.PRManager
 	prSvc                 *prservice.Service
 	pipelineMgr           pipelinemanager.Manager
+	pipelineStepSvc       pipelinestepservice.Service
 	tektonFty             factory.Factory
 	registryFty           registryfty.RegistryGetter
 	userManager           usermanager.Manager
@@ -164,13 +282,32 @@ type controller struct {
 	tokenConfig           token.Config
 	templateUpgradeMapper template.UpgradeMapper
 	collectionManager     collectionmanager.Manager
+	namingSvc             naming.Service
+	deployWindowSvc       deploywindow.Service
+	manifestPolicySvc     manifestpolicy.Service
+	snapshotMgr           snapshotmanager.Manager
+	snapshotSvc           snapshotservice.Service
+	envChangeMgr          envchangemanager.Manager
+	asyncTaskSvc          asynctaskservice.Service
+	networkPolicyConfig   networkpolicyconfig.Config
+	changeRequestConfig   changerequestconfig.Config
+	changeRequestMgr      changerequestmanager.Manager
+	deployApprovalConfig  deployapprovalconfig.Config
+	deployApprovalMgr     deployapprovalmanager.Manager
+	scheduledDeployMgr    scheduleddeploymanager.Manager
+	sandboxConfig         sandboxconfig.Config
+	sbomConfig            sbomconfig.Config
+	metadataSvc           metadataservice.Service
+	quotaSvc              quotaservice.Service
+	authorizer            rbac.Authorizer
 }
 
 var _ Controller = (*controller)(nil)
 
-func NewController(config *config.Config, param *param.Param) Controller {
+func NewController(config *config.Config, param *param.Param, authorizer rbac.Authorizer) Controller {
 	return &controller{
 		clusterMgr:            param.ClusterMgr,
+		clusterSummaryMgr:     param.ClusterSummaryMgr,
 		clusterGitRepo:        param.ClusterGitRepo,
 		applicationGitRepo:    param.ApplicationGitRepo,
 		commitGetter:          param.GitGetter,
@@ -181,6 +318,8 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		templateMgr:           param.TemplateMgr,
 		templateReleaseMgr:    param.TemplateReleaseMgr,
 		templateSchemaGetter:  param.TemplateSchemaGetter,
+		templateValidator:     param.TemplateValidationSvc,
+		templateRenderer:      param.TemplateRenderer,
 		autoFreeSvc:           param.AutoFreeSvc,
 		outputGetter:          param.OutputGetter,
 		envMgr:                param.EnvMgr,
@@ -190,6 +329,7 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		prMgr:                 param.PRMgr,
 		prSvc:                 param.PRService,
 		pipelineMgr:           param.PipelineMgr,
+		pipelineStepSvc:       param.PipelineStepSvc,
 		tektonFty:             param.TektonFty,
 		registryFty:           registryfty.Fty,
 		userManager:           param.UserMgr,
@@ -206,5 +346,23 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		tokenConfig:           config.TokenConfig,
 		templateUpgradeMapper: config.TemplateUpgradeMapper,
 		collectionManager:     param.CollectionMgr,
+		namingSvc:             param.NamingSvc,
+		deployWindowSvc:       param.DeployWindowSvc,
+		manifestPolicySvc:     param.ManifestPolicySvc,
+		snapshotMgr:           param.ClusterSnapshotMgr,
+		snapshotSvc:           param.SnapshotSvc,
+		envChangeMgr:          param.ClusterEnvChangeMgr,
+		asyncTaskSvc:          param.AsyncTaskSvc,
+		networkPolicyConfig:   config.NetworkPolicyConfig,
+		changeRequestConfig:   config.ChangeRequestConfig,
+		changeRequestMgr:      param.ChangeRequestMgr,
+		deployApprovalConfig:  config.DeployApprovalConfig,
+		deployApprovalMgr:     param.DeployApprovalMgr,
+		scheduledDeployMgr:    param.ScheduledDeployMgr,
+		sandboxConfig:         config.SandboxConfig,
+		sbomConfig:            config.SBOMConfig,
+		metadataSvc:           param.MetadataSvc,
+		quotaSvc:              param.QuotaSvc,
+		authorizer:            authorizer,
 	}
 }
@@ -0,0 +1,98 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) GetAutoscaling(ctx context.Context, clusterID uint) (*GetAutoscalingResponse, error) {
+	const op = "cluster controller: get autoscaling"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	return &GetAutoscalingResponse{
+		Config: files.Autoscaling,
+		Status: c.autoscalingStatus(ctx, cluster, application, regionEntity),
+	}, nil
+}
+
+func (c *controller) UpdateAutoscaling(ctx context.Context, clusterID uint, config *autoscaling.Config) error {
+	const op = "cluster controller: update autoscaling"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return err
+	}
+	// the template config is written back as it is, so that the replicas are checked against the autoscaling
+	return c.UpdateClusterV2(ctx, clusterID, &UpdateClusterRequestV2{
+		TemplateConfig: files.ApplicationJSONBlob,
+		Autoscaling:    config,
+		ConfigCommit:   files.Commit,
+	}, false)
+}
+
+// autoscalingStatus returns the live status of the cluster's autoscaler, nil if it's not autoscaled.
+// It's best effort, since the status of the cluster is still meaningful without it.
+func (c *controller) autoscalingStatus(ctx context.Context, cluster *clustermodels.Cluster,
+	application *appmodels.Application, regionEntity *regionmodels.RegionEntity) *cd.AutoscalingStatus {
+	namespace, err := c.namespaceOf(ctx, cluster, application)
+	if err != nil {
+		log.Warningf(ctx, "failed to get namespace of cluster %s: %v", cluster.Name, err)
+		return nil
+	}
+	status, err := c.k8sutil.GetAutoscalingStatus(ctx, &cd.GetAutoscalingStatusParams{
+		RegionEntity: regionEntity,
+		Cluster:      cluster.Name,
+		Namespace:    namespace,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to get autoscaling status of cluster %s: %v", cluster.Name, err)
+		return nil
+	}
+	return status
+}
@@ -38,10 +38,12 @@ import (
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/naming"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
-	"github.com/horizoncd/horizon/pkg/util/jsonschema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/mergemap"
 	"github.com/horizoncd/horizon/pkg/util/permission"
@@ -122,6 +124,9 @@ func (c *controller) List(ctx context.Context, query *q.Query) ([]*ListClusterWi
 	if err != nil {
 		return nil, 0, err
 	}
+	if err = c.addLatestDeployForClusters(ctx, responses); err != nil {
+		return nil, 0, err
+	}
 
 	if _, ok := query.Keywords[common.ClusterQueryWithFavorite]; ok {
 		err = c.addIsFavoriteForClusters(ctx, currentUserID, responses)
@@ -172,6 +177,9 @@ func (c *controller) ListByApplication(ctx context.Context,
 			ListClusterResponse: cluster,
 		})
 	}
+	if err = c.addLatestDeployForClusters(ctx, responses); err != nil {
+		return 0, nil, err
+	}
 
 	if _, ok := query.Keywords[common.ClusterQueryWithFavorite]; ok {
 		err = c.addIsFavoriteForClusters(ctx, currentUserID, responses)
@@ -211,10 +219,9 @@ func (c *controller) getFullResponses(ctx context.Context,
 			return nil, err
 		}
 		responses = append(responses, &ListClusterWithFullResponse{
-			response,
-			nil,
-			fullName,
-			fullPath,
+			ListClusterResponse: response,
+			FullName:            fullName,
+			FullPath:            fullPath,
 		})
 	}
 	return responses, nil
@@ -438,6 +445,9 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 	}
 
 	// 2. validate
+	if err := c.quotaSvc.Check(ctx, application.GroupID, quotamodels.ResourceClusters); err != nil {
+		return nil, err
+	}
 	exists, err := c.clusterMgr.CheckClusterExists(ctx, r.Name)
 	if err != nil {
 		return nil, err
@@ -446,15 +456,27 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 		return nil, perror.Wrap(herrors.ErrNameConflict,
 			"a cluster with the same name already exists, please do not create it again")
 	}
+	if err := c.checkDeletedName(ctx, r.Name); err != nil {
+		return nil, err
+	}
 	if err := c.validateCreate(r); err != nil {
 		return nil, err
 	}
+	if err := c.namingSvc.Validate(ctx, naming.KindCluster, r.Name); err != nil {
+		return nil, err
+	}
+	if r.Namespace != "" {
+		if err := c.namingSvc.Validate(ctx, naming.KindNamespace, r.Namespace); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := c.customizeTemplateInfo(ctx, r, application, environment, mergePatch); err != nil {
+	inherited, err := c.customizeTemplateInfo(ctx, r, application, environment, mergePatch)
+	if err != nil {
 		return nil, err
 	}
 	if err := c.validateTemplateInput(ctx, r.Template.Name,
-		r.Template.Release, r.TemplateInput, nil); err != nil {
+		r.Template.Release, r.TemplateInput, inherited, nil); err != nil {
 		return nil, err
 	}
 
@@ -486,6 +508,12 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 	if err != nil {
 		return nil, err
 	}
+	if err := validateTemplateRelease(tr, r.Name); err != nil {
+		return nil, err
+	}
+	if err := c.checkDeprecatedRelease(ctx, tr, application); err != nil {
+		return nil, err
+	}
 
 	// 6. create cluster, after created, params.Cluster is the newest cluster
 	cluster, tags := r.toClusterModel(application, er, expireSeconds)
@@ -514,6 +542,8 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 			Environment:         environment,
 			RegionEntity:        regionEntity,
 			Namespace:           r.Namespace,
+			// the baseline is mandatory even if clusters of v1 cannot declare network policies
+			NetworkPolicyBaseline: c.networkPolicyConfig.BaselineOf(region),
 		},
 		Tags:  tags,
 		Image: r.Image,
@@ -525,7 +555,7 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 				err = perror.WithMessage(err, deleteErr.Error())
 			}
 		}
-		if deleteErr := c.clusterMgr.DeleteByID(ctx, cluster.ID); deleteErr != nil {
+		if deleteErr := c.clusterMgr.HardDeleteByID(ctx, cluster.ID); deleteErr != nil {
 			err = perror.WithMessage(err, deleteErr.Error())
 		}
 		return nil, err
@@ -570,6 +600,11 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	if r.Base != nil && r.TemplateInput != nil && c.changeRequestConfig.Required(cluster.EnvironmentName) {
+		return nil, perror.Wrapf(herrors.ErrChangeRequestRequired,
+			"clusters in environment %s can only change config by change requests", cluster.EnvironmentName)
+	}
+
 	// 2. get application that this cluster belongs to
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
@@ -632,20 +667,29 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if templateRelease != cluster.TemplateRelease {
+		if err := validateTemplateRelease(tr, cluster.Name); err != nil {
+			return nil, err
+		}
+	}
 
 	clusterModel, tags := r.toClusterModel(cluster, templateRelease, er)
 
 	// 4. if templateInput is not empty, validate templateInput and update templateInput in git repo
 	if r.TemplateInput != nil {
+		files, err := c.clusterGitRepo.GetCluster(ctx, application.Name,
+			cluster.Name, cluster.Template)
+		if err != nil {
+			return nil, err
+		}
+		// the files are changed by merging
+		previous := &TemplateInput{
+			Application: mergemap.Copy(files.ApplicationJSONBlob),
+			Pipeline:    mergemap.Copy(files.PipelineJSONBlob),
+		}
 		// merge cluster config and request config
 		// merge patch allows users to pass only some fields
 		if mergePatch {
-			files, err := c.clusterGitRepo.GetCluster(ctx, application.Name,
-				cluster.Name, cluster.Template)
-			if err != nil {
-				return nil, err
-			}
-
 			r.TemplateInput.Application, err = mergemap.Merge(files.ApplicationJSONBlob,
 				r.TemplateInput.Application)
 			if err != nil {
@@ -665,9 +709,8 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 			return nil, err
 		}
 		if err := c.validateTemplateInput(ctx,
-			cluster.Template, templateRelease, r.TemplateInput, renderValues); err != nil {
-			return nil, perror.Wrapf(herrors.ErrParamInvalid,
-				"request body validate err: %v", err)
+			cluster.Template, templateRelease, r.TemplateInput, previous, renderValues); err != nil {
+			return nil, perror.WithMessage(err, "request body validate err")
 		}
 		// update cluster in git repo
 		if err := c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
@@ -681,6 +724,8 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 				Environment:         er.EnvironmentName,
 				RegionEntity:        regionEntity,
 				Namespace:           namespace,
+				// the baseline is mandatory even if clusters of v1 cannot declare network policies
+				NetworkPolicyBaseline: c.networkPolicyConfig.BaselineOf(er.RegionName),
 			},
 		}); err != nil {
 			return nil, err
@@ -844,6 +889,7 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 		// 1. delete cluster in cd system
 		if err = c.cd.DeleteCluster(newctx, &cd.DeleteClusterParams{
 			Environment: cluster.EnvironmentName,
+			Region:      cluster.RegionName,
 			Cluster:     cluster.Name,
 		}); err != nil {
 			log.Errorf(newctx, "failed to delete cluster: %v in cd system, err: %v", cluster.Name, err)
@@ -897,8 +943,13 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 			}
 		}
 
-		// 4. delete cluster in db
-		if err = c.clusterMgr.DeleteByID(newctx, clusterID); err != nil {
+		// 4. delete cluster in db, a soft deleted cluster keeps its git repo in the recycling group
+		// until it is restored or purged
+		deleteByID := c.clusterMgr.DeleteByID
+		if hard {
+			deleteByID = c.clusterMgr.HardDeleteByID
+		}
+		if err = deleteByID(newctx, clusterID); err != nil {
 			log.Errorf(newctx, "failed to delete cluster: %v in db, err: %v", cluster.Name, err)
 		}
 
@@ -960,6 +1011,7 @@ func (c *controller) FreeCluster(ctx context.Context, clusterID uint) (err error
 		// 2. delete cluster in cd system
 		if err = c.cd.DeleteCluster(newctx, &cd.DeleteClusterParams{
 			Environment: cluster.EnvironmentName,
+			Region:      cluster.RegionName,
 			Cluster:     cluster.Name,
 		}); err != nil {
 			log.Errorf(newctx, "failed to delete cluster: %v in cd system, err: %v", cluster.Name, err)
@@ -1000,8 +1052,10 @@ func (c *controller) toExpireSeconds(ctx context.Context, expireTime string, env
 	return expireSeconds, nil
 }
 
+// customizeTemplateInfo fills the template and the template input of the request with the application's,
+// and returns the template input of the application in the environment
 func (c *controller) customizeTemplateInfo(ctx context.Context, r *CreateClusterRequest,
-	application *models.Application, environment string, mergePatch bool) error {
+	application *models.Application, environment string, mergePatch bool) (*TemplateInput, error) {
 	// 1. if template is empty, set it with application's template
 	if r.Template == nil {
 		r.Template = &Template{
@@ -1021,10 +1075,15 @@ func (c *controller) customizeTemplateInfo(ctx context.Context, r *CreateCluster
 
 	appGitRepo, err := c.applicationGitRepo.GetApplication(ctx, application.Name, environment)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	pipelineJSONBlob := appGitRepo.BuildConf
 	applicationJSONBlob := appGitRepo.TemplateConf
+	// the template input of the application is changed by merging
+	inherited := &TemplateInput{
+		Application: mergemap.Copy(applicationJSONBlob),
+		Pipeline:    mergemap.Copy(pipelineJSONBlob),
+	}
 	if r.TemplateInput == nil {
 		r.TemplateInput = &TemplateInput{}
 		r.TemplateInput.Application = applicationJSONBlob
@@ -1034,18 +1093,18 @@ func (c *controller) customizeTemplateInfo(ctx context.Context, r *CreateCluster
 		applicationJSONBlob, err := mergemap.Merge(applicationJSONBlob,
 			r.TemplateInput.Application)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		pipelineJSONBlob, err := mergemap.Merge(pipelineJSONBlob,
 			r.TemplateInput.Pipeline)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		r.TemplateInput = &TemplateInput{}
 		r.TemplateInput.Application = applicationJSONBlob
 		r.TemplateInput.Pipeline = pipelineJSONBlob
 	}
-	return nil
+	return inherited, nil
 }
 
 func (c *controller) getRenderValueFromTag(ctx context.Context, clusterID uint) (map[string]string, error) {
@@ -1077,22 +1136,27 @@ func (c *controller) validateCreate(r *CreateClusterRequest) error {
 	return nil
 }
 
-// validateTemplateInput validate templateInput is valid for template schema
-func (c *controller) validateTemplateInput(ctx context.Context,
-	template, release string, templateInput *TemplateInput, templateSchemaRenderVal map[string]string) error {
+// validateTemplateInput validate templateInput is valid for template schema,
+// previous is the template input replaced, which is the one inherited from the application when creating
+func (c *controller) validateTemplateInput(ctx context.Context, template, release string,
+	templateInput, previous *TemplateInput, templateSchemaRenderVal map[string]string) error {
 	if templateSchemaRenderVal == nil {
 		templateSchemaRenderVal = make(map[string]string)
 	}
 	// TODO (remove it, currently some template need it)
 	templateSchemaRenderVal["resourceType"] = "cluster"
-	schema, err := c.templateSchemaGetter.GetTemplateSchema(ctx, template, release, templateSchemaRenderVal)
-	if err != nil {
-		return err
-	}
-	if err := jsonschema.Validate(schema.Application.JSONSchema, templateInput.Application, false); err != nil {
-		return err
-	}
-	return jsonschema.Validate(schema.Pipeline.JSONSchema, templateInput.Pipeline, true)
+	input := &templatevalidation.Input{
+		Template:     template,
+		Release:      release,
+		RenderValues: templateSchemaRenderVal,
+		Application:  templateInput.Application,
+		Pipeline:     templateInput.Pipeline,
+	}
+	if previous != nil {
+		input.PreviousApplication = previous.Application
+		input.PreviousPipeline = previous.Pipeline
+	}
+	return c.templateValidator.Validate(ctx, input)
 }
 
 // validateClusterName validate cluster name
@@ -1132,6 +1196,24 @@ var (
 	favoriteFalse = false
 )
 
+// addLatestDeployForClusters fills latest deploy by cluster summaries in one query,
+// instead of looking up pipelineruns of each cluster
+func (c *controller) addLatestDeployForClusters(ctx context.Context,
+	clusters []*ListClusterWithFullResponse) error {
+	ids := make([]uint, 0, len(clusters))
+	for i := range clusters {
+		ids = append(ids, clusters[i].ID)
+	}
+	summaries, err := c.clusterSummaryMgr.ListByClusterIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range clusters {
+		clusters[i].LatestDeploy = ofClusterSummary(summaries[clusters[i].ID])
+	}
+	return nil
+}
+
 func (c *controller) addIsFavoriteForClusters(ctx context.Context,
 	userID uint, clusters []*ListClusterWithFullResponse) error {
 	ids := make([]uint, 0, len(clusters))
@@ -97,13 +97,14 @@ func testListClusterByNameFuzzily(t *testing.T) {
 	}
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		memberManager:  manager.MemberMgr,
-		eventSvc:       eventservice.New(manager),
-		commitGetter:   commitGetter,
+		clusterMgr:        manager.ClusterMgr,
+		clusterSummaryMgr: manager.ClusterSummaryMgr,
+		applicationMgr:    manager.ApplicationMgr,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupMgr,
+		memberManager:     manager.MemberMgr,
+		eventSvc:          eventservice.New(manager),
+		commitGetter:      commitGetter,
 	}
 
 	resps, count, err := c.List(ctx, &q.Query{Keywords: q.KeyWords{common.ClusterQueryName: "fuzzilyCluster"}})
@@ -214,13 +215,14 @@ func testListUserClustersByNameFuzzily(t *testing.T) {
 	assert.Nil(t, err)
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		memberManager:  manager.MemberMgr,
-		eventSvc:       eventservice.New(manager),
-		commitGetter:   commitGetter,
+		clusterMgr:        manager.ClusterMgr,
+		clusterSummaryMgr: manager.ClusterSummaryMgr,
+		applicationMgr:    manager.ApplicationMgr,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupMgr,
+		memberManager:     manager.MemberMgr,
+		eventSvc:          eventservice.New(manager),
+		commitGetter:      commitGetter,
 	}
 
 	resps, count, err := c.List(ctx,
@@ -300,14 +302,15 @@ func testControllerFreeOrDeleteClusterFailed(t *testing.T) {
 	cd.EXPECT().DeleteCluster(gomock.Any(), gomock.Any()).Return(errors.New("test")).AnyTimes()
 
 	c = &controller{
-		cd:             cd,
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		envMgr:         manager.EnvMgr,
-		regionMgr:      manager.RegionMgr,
-		eventSvc:       eventservice.New(manager),
+		cd:                cd,
+		clusterMgr:        manager.ClusterMgr,
+		clusterSummaryMgr: manager.ClusterSummaryMgr,
+		applicationMgr:    manager.ApplicationMgr,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupMgr,
+		envMgr:            manager.EnvMgr,
+		regionMgr:         manager.RegionMgr,
+		eventSvc:          eventservice.New(manager),
 	}
 
 	id, err := registrydao.NewDAO(db).Create(ctx, &registrymodels.Registry{
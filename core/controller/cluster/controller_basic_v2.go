@@ -17,26 +17,38 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/build"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	asynctaskmodels "github.com/horizoncd/horizon/pkg/asynctask/models"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
+	"github.com/horizoncd/horizon/pkg/auth"
 	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
+	"github.com/horizoncd/horizon/pkg/cluster/availability"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/networkpolicy"
+	"github.com/horizoncd/horizon/pkg/cluster/rollout"
 	collectionmodels "github.com/horizoncd/horizon/pkg/collection/models"
+	networkpolicyconfig "github.com/horizoncd/horizon/pkg/config/networkpolicy"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/naming"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/models"
-	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
 	"github.com/horizoncd/horizon/pkg/util/jsonschema"
 	"github.com/horizoncd/horizon/pkg/util/mergemap"
 	"github.com/horizoncd/horizon/pkg/util/validate"
 
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 
@@ -92,7 +104,10 @@ func (c *controller) GetClusterStatusV2(ctx context.Context, clusterID uint) (*S
 		if resp.Status == "" {
 			resp.Status = cdStatus.Status
 		}
+		resp.Step = c.stepInProgress(ctx, cluster, regionEntity, cdStatus.Status)
+		resp.Autoscaling = c.autoscalingStatus(ctx, cluster, application, regionEntity)
 	}
+	resp.OperationQueue = c.cd.ListQueuedOperations(ctx, cluster.Name)
 
 	return resp, nil
 }
@@ -102,6 +117,7 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	const op = "cluster controller: create cluster v2"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	asynctaskservice.Report(ctx, 0, 4, "validating request")
 	// 1. check exist
 	exists, err := c.clusterMgr.CheckClusterExists(ctx, params.Name)
 	if err != nil {
@@ -111,11 +127,22 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 		return nil, perror.Wrap(herrors.ErrNameConflict,
 			"a cluster with the same name already exists, please do not create it again")
 	}
+	if err := c.checkDeletedName(ctx, params.Name); err != nil {
+		return nil, err
+	}
 
 	// 2. validate create req
 	if err := validateClusterName(params.Name); err != nil {
 		return nil, err
 	}
+	if err := c.namingSvc.Validate(ctx, naming.KindCluster, params.Name); err != nil {
+		return nil, err
+	}
+	if params.Namespace != "" {
+		if err := c.namingSvc.Validate(ctx, naming.KindNamespace, params.Namespace); err != nil {
+			return nil, err
+		}
+	}
 	if params.Git != nil && params.Git.URL != "" {
 		if err := validate.CheckGitURL(params.Git.URL); err != nil {
 			return nil, err
@@ -126,22 +153,51 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 			return nil, err
 		}
 	}
+	clusterRollout := params.Rollout
+	if clusterRollout == nil {
+		clusterRollout = rollout.Default()
+	}
+	if err := validateRollout(clusterRollout); err != nil {
+		return nil, err
+	}
+	networkPolicyBaseline := c.networkPolicyConfig.BaselineOf(params.Region)
+	if params.NetworkPolicy != nil {
+		if err := validateNetworkPolicy(params.NetworkPolicy, networkPolicyBaseline); err != nil {
+			return nil, err
+		}
+	}
 
 	// 3. get application
 	application, err := c.applicationMgr.GetByID(ctx, params.ApplicationID)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.quotaSvc.Check(ctx, application.GroupID, quotamodels.ResourceClusters); err != nil {
+		return nil, err
+	}
 
 	// 4. customize buildTemplateInfo and do validate
 	buildTemplateInfo, err := c.customizeCreateReqBuildTemplateInfo(ctx, params, application)
 	if err != nil {
 		return nil, err
 	}
+	if c.isSandbox(application.ID) {
+		constrainSandboxConfig(buildTemplateInfo.TemplateConfig, c.sandboxConfig.Replicas, c.sandboxConfig.Resource)
+	}
 	if err := buildTemplateInfo.Validate(ctx,
-		c.templateSchemaGetter, nil, c.buildSchema); err != nil {
+		c.templateValidator, nil, c.buildSchema); err != nil {
 		return nil, err
 	}
+	if params.Availability != nil {
+		if err := validateAvailability(params.Availability, buildTemplateInfo.TemplateConfig); err != nil {
+			return nil, err
+		}
+	}
+	if params.Autoscaling != nil {
+		if err := c.validateAutoscaling(application, params.Autoscaling, params.Availability); err != nil {
+			return nil, err
+		}
+	}
 
 	// 5. get environment and region
 	envEntity, err := c.envRegionMgr.GetByEnvironmentAndRegion(ctx,
@@ -174,18 +230,26 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if err := validateTemplateRelease(tr, params.Name); err != nil {
+		return nil, err
+	}
+	if err := c.checkDeprecatedRelease(ctx, tr, application); err != nil {
+		return nil, err
+	}
 
 	// 8. customize db infos
 	cluster, tags := params.toClusterModel(application,
 		envEntity, buildTemplateInfo, template, expireSeconds)
 
 	// 9. update db and tags
+	asynctaskservice.Report(ctx, 1, 4, "saving cluster")
 	clusterResp, err := c.clusterMgr.Create(ctx, cluster, tags, params.ExtraMembers)
 	if err != nil {
 		return nil, err
 	}
 
 	// 10. create git repo
+	asynctaskservice.Report(ctx, 2, 4, "creating git repo")
 	err = c.clusterGitRepo.CreateCluster(ctx, &gitrepo.CreateClusterParams{
 		BaseParams: &gitrepo.BaseParams{
 			ClusterID:           clusterResp.ID,
@@ -196,7 +260,14 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 			Application:         application,
 			Environment:         params.Environment,
 			RegionEntity:        regionEntity,
+			Namespace:           params.Namespace,
+			Rollout:             clusterRollout,
 			Version:             common.MetaVersion2,
+
+			NetworkPolicy:         params.NetworkPolicy,
+			NetworkPolicyBaseline: networkPolicyBaseline,
+			Availability:          params.Availability,
+			Autoscaling:           params.Autoscaling,
 		},
 		Tags: tags,
 	})
@@ -207,7 +278,7 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 				err = perror.WithMessage(err, deleteErr.Error())
 			}
 		}
-		if deleteErr := c.clusterMgr.DeleteByID(ctx, cluster.ID); deleteErr != nil {
+		if deleteErr := c.clusterMgr.HardDeleteByID(ctx, cluster.ID); deleteErr != nil {
 			err = perror.WithMessage(err, deleteErr.Error())
 		}
 		return nil, err
@@ -219,6 +290,7 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	}
 
 	// 11. get full path
+	asynctaskservice.Report(ctx, 3, 4, "recording events")
 	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
 	if err != nil {
 		return nil, err
@@ -247,10 +319,30 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, ret.ID,
 		eventmodels.ClusterCreated, nil)
 	c.eventSvc.RecordMemberCreatedEvent(ctx, common.ResourceCluster, ret.ID)
+	asynctaskservice.Report(ctx, 4, 4, "cluster created")
 	// 13. customize response
 	return ret, nil
 }
 
+func (c *controller) CreateClusterV2Async(ctx context.Context,
+	params *CreateClusterParamsV2) (*CreateClusterAsyncResponse, error) {
+	const op = "cluster controller: create cluster v2 async"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	task, err := c.asyncTaskSvc.Submit(ctx, asynctaskmodels.TypeClusterCreate,
+		func(ctx context.Context, _ asynctaskservice.Reporter) (interface{}, error) {
+			resp, err := c.CreateClusterV2(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			return resp, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &CreateClusterAsyncResponse{TaskID: task.ID}, nil
+}
+
 func (c *controller) GetClusterV2(ctx context.Context, clusterID uint) (*GetClusterResponseV2, error) {
 	const op = "cluster controller: get cluster v2"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -291,13 +383,19 @@ func (c *controller) GetClusterV2(ctx context.Context, clusterID uint) (*GetClus
 		return nil, err
 	}
 
-	// 6. get GitRepo
+	// 6. get metadata
+	metadata, err := c.metadataSvc.Get(ctx, common.ResourceCluster, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 7. get GitRepo
 	clusterGitRepoFile, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
 	if err != nil {
 		return nil, err
 	}
 
-	// 7. get createdBy and updatedBy users
+	// 8. get createdBy and updatedBy users
 	userMap, err := c.userManager.GetUserMapByIDs(ctx, []uint{cluster.CreatedBy, cluster.UpdatedBy})
 	if err != nil {
 		return nil, err
@@ -324,6 +422,7 @@ func (c *controller) GetClusterV2(ctx context.Context, clusterID uint) (*GetClus
 		ApplicationName: application.Name,
 		ApplicationID:   application.ID,
 		Tags:            tagmodels.Tags(tags).IntoTagsBasic(),
+		Metadata:        metadata,
 		Git: func() *codemodels.Git {
 			if cluster.GitURL == "" {
 				return nil
@@ -344,6 +443,11 @@ func (c *controller) GetClusterV2(ctx context.Context, clusterID uint) (*GetClus
 		}(),
 		TemplateConfig: clusterGitRepoFile.ApplicationJSONBlob,
 		Manifest:       clusterGitRepoFile.Manifest,
+		Rollout:        clusterGitRepoFile.Rollout,
+		NetworkPolicy:  clusterGitRepoFile.NetworkPolicy,
+		Availability:   clusterGitRepoFile.Availability,
+		Autoscaling:    clusterGitRepoFile.Autoscaling,
+		ConfigCommit:   clusterGitRepoFile.Commit,
 		Status:         cluster.Status,
 		CreatedAt:      cluster.CreatedAt,
 		UpdatedAt:      cluster.UpdatedAt,
@@ -368,26 +472,100 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	const op = "cluster controller: update cluster v2"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	return c.updateClusterV2(ctx, clusterID, r, mergePatch, "")
+}
+
+// clusterUpdateV2 is what the cluster is updated to by an UpdateClusterRequestV2
+type clusterUpdateV2 struct {
+	cluster         *clustermodels.Cluster
+	application     *appmodels.Application
+	params          *gitrepo.UpdateClusterParams
+	expireSeconds   uint
+	environmentName string
+	regionName      string
+	templateInfo    *codemodels.TemplateInfo
+}
+
+// updateClusterV2 updates the cluster, config changes are written to the branch if it's not empty,
+// otherwise to the gitops branch. Only config is changed when written to the branch,
+// it's applied after the branch is merged.
+func (c *controller) updateClusterV2(ctx context.Context, clusterID uint,
+	r *UpdateClusterRequestV2, mergePatch bool, branch string) error {
+	update, err := c.prepareUpdateClusterV2(ctx, clusterID, r, mergePatch, branch)
+	if err != nil {
+		return err
+	}
+	cluster, application := update.cluster, update.application
+	if branch == "" && r.changesConfig() && c.changeRequestConfig.Required(cluster.EnvironmentName) {
+		return perror.Wrapf(herrors.ErrChangeRequestRequired,
+			"clusters in environment %s can only change config by change requests", cluster.EnvironmentName)
+	}
+
+	// 6. update in git repo
+	if err = c.clusterGitRepo.UpdateCluster(ctx, update.params); err != nil {
+		return err
+	}
+	if branch != "" {
+		return nil
+	}
+
+	// 7. record event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
+		eventmodels.ClusterUpdated, nil)
+
+	// 8. update cluster in db
+	clusterModel, tags := r.toClusterModel(cluster, update.expireSeconds, update.environmentName,
+		update.regionName, update.templateInfo.Name, update.templateInfo.Release)
+	_, err = c.clusterMgr.UpdateByID(ctx, clusterID, clusterModel)
+	if err != nil {
+		return err
+	}
+
+	// 9. update cluster tags
+	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
+	if err != nil {
+		return err
+	}
+	if r.Tags != nil && !tagmodels.Tags(tags).Eq(tagsInDB) {
+		if err := c.clusterGitRepo.UpdateTags(ctx, application.Name, cluster.Name, cluster.Template, tags); err != nil {
+			return err
+		}
+		if err := c.tagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, clusterID, r.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareUpdateClusterV2 validates the request and merges it with the cluster,
+// the config to write to the branch is returned without writing it
+func (c *controller) prepareUpdateClusterV2(ctx context.Context, clusterID uint,
+	r *UpdateClusterRequestV2, mergePatch bool, branch string) (*clusterUpdateV2, error) {
 	// validate request
 	if r.Git != nil && r.Git.URL != "" {
 		if err := validate.CheckGitURL(r.Git.URL); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if r.Image != nil && *r.Image != "" {
 		if err := validate.CheckImageURL(*r.Image); err != nil {
-			return err
+			return nil, err
+		}
+	}
+	if r.Rollout != nil {
+		if err := validateRollout(r.Rollout); err != nil {
+			return nil, err
 		}
 	}
 
 	// 1. get cluster and application from db
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 2. check if we should update region and env
@@ -405,11 +583,18 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		}
 		regionEntity, err = c.regionMgr.GetRegionEntity(ctx, regionName)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		_, err = c.envRegionMgr.GetByEnvironmentAndRegion(ctx, environmentName, regionName)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	networkPolicyBaseline := c.networkPolicyConfig.BaselineOf(regionName)
+	if r.NetworkPolicy != nil {
+		if err := validateNetworkPolicy(r.NetworkPolicy, networkPolicyBaseline); err != nil {
+			return nil, err
 		}
 	}
 
@@ -418,7 +603,7 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	if r.ExpireTime != "" {
 		expireSeconds, err = c.toExpireSeconds(ctx, r.ExpireTime, environmentName)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -438,20 +623,34 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		if err != nil {
 			return nil, nil, err
 		}
+		if templateInfo.Name != cluster.Template || templateInfo.Release != cluster.TemplateRelease {
+			if err := validateTemplateRelease(tr, cluster.Name); err != nil {
+				return nil, nil, err
+			}
+		}
 		return templateInfo, tr, nil
 	}()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	expectedCommit := r.ConfigCommit
+	var (
+		files                  *gitrepo.ClusterFiles
+		previousTemplateConfig map[string]interface{}
+	)
 	buildConfig, templateConfig, err := func() (map[string]interface{}, map[string]interface{}, error) {
 		if r.BuildConfig == nil && r.TemplateConfig == nil {
 			return nil, nil, nil
 		}
-		files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+		files, err = c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
 		if err != nil {
 			return nil, nil, err
 		}
+		if mergePatch && expectedCommit == "" {
+			// the patch is merged into the files read now, which should not be changed before writing back
+			expectedCommit = files.Commit
+		}
 		if files.Manifest == nil {
 			return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "git repo  %s not support v2 interface",
 				cluster.Name)
@@ -459,6 +658,8 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 
 		buildConfig := r.BuildConfig
 		templateConfig := r.TemplateConfig
+		// the files are changed by merging
+		previousTemplateConfig = mergemap.Copy(files.ApplicationJSONBlob)
 		if r.BuildConfig != nil && mergePatch {
 			buildConfig, err = mergemap.Merge(files.PipelineJSONBlob, r.BuildConfig)
 			if err != nil {
@@ -474,7 +675,11 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		return buildConfig, templateConfig, nil
 	}()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if templateConfig != nil && c.isSandbox(application.ID) {
+		constrainSandboxConfig(templateConfig, c.sandboxConfig.Replicas, c.sandboxConfig.Resource)
 	}
 
 	// 5. validate update Request
@@ -484,56 +689,168 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 			return err
 		}
 		info := BuildTemplateInfo{
-			BuildConfig:    buildConfig,
-			TemplateInfo:   templateInfo,
-			TemplateConfig: templateConfig,
+			BuildConfig:            buildConfig,
+			TemplateInfo:           templateInfo,
+			TemplateConfig:         templateConfig,
+			PreviousTemplateConfig: previousTemplateConfig,
 		}
-		return info.Validate(ctx, c.templateSchemaGetter, renderValues, c.buildSchema)
+		return info.Validate(ctx, c.templateValidator, renderValues, c.buildSchema)
 	}()
 	if err != nil {
+		return nil, err
+	}
+
+	// replicas may be changed as well as the availability and autoscaling configs, check them together
+	if r.Availability != nil || r.Autoscaling != nil || templateConfig != nil {
+		if files == nil {
+			files, err = c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+			if err != nil {
+				return nil, err
+			}
+		}
+		availabilityConfig, replicasConfig := r.Availability, templateConfig
+		if availabilityConfig == nil {
+			availabilityConfig = files.Availability
+		}
+		if replicasConfig == nil {
+			replicasConfig = files.ApplicationJSONBlob
+		}
+		if availabilityConfig != nil {
+			if err := validateAvailability(availabilityConfig, replicasConfig); err != nil {
+				return nil, err
+			}
+		}
+		autoscalingConfig := r.Autoscaling
+		if autoscalingConfig == nil {
+			autoscalingConfig = files.Autoscaling
+		}
+		if autoscalingConfig != nil {
+			if err := c.validateAutoscaling(application, autoscalingConfig, availabilityConfig); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &clusterUpdateV2{
+		cluster:     cluster,
+		application: application,
+		params: &gitrepo.UpdateClusterParams{
+			BaseParams: &gitrepo.BaseParams{
+				ClusterID:           cluster.ID,
+				Cluster:             cluster.Name,
+				PipelineJSONBlob:    buildConfig,
+				ApplicationJSONBlob: templateConfig,
+				TemplateRelease:     templateRelease,
+				Application:         application,
+				Environment:         environmentName,
+				RegionEntity:        regionEntity,
+				Rollout:             r.Rollout,
+				Version:             common.MetaVersion2,
+
+				NetworkPolicy:         r.NetworkPolicy,
+				NetworkPolicyBaseline: networkPolicyBaseline,
+				Availability:          r.Availability,
+				Autoscaling:           r.Autoscaling,
+			},
+			ExpectedCommit: expectedCommit,
+			Branch:         branch,
+		},
+		expireSeconds:   expireSeconds,
+		environmentName: environmentName,
+		regionName:      regionName,
+		templateInfo:    templateInfo,
+	}, nil
+}
+
+// validateRollout fills the defaults of rollout config and validates it
+func validateRollout(r *rollout.Config) error {
+	r.SetDefaults()
+	return r.Validate()
+}
+
+// validateNetworkPolicy fills the defaults of network policy config,
+// validates it and makes sure it respects the baseline of the region
+func validateNetworkPolicy(p *networkpolicy.Config, baseline *networkpolicyconfig.Baseline) error {
+	p.SetDefaults()
+	if err := p.Validate(); err != nil {
 		return err
 	}
+	return p.Check(baseline)
+}
 
-	// 6. update in git repo
-	if err = c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
-		BaseParams: &gitrepo.BaseParams{
-			ClusterID:           cluster.ID,
-			Cluster:             cluster.Name,
-			PipelineJSONBlob:    buildConfig,
-			ApplicationJSONBlob: templateConfig,
-			TemplateRelease:     templateRelease,
-			Application:         application,
-			Environment:         environmentName,
-			RegionEntity:        regionEntity,
-			Version:             common.MetaVersion2,
-		}}); err != nil {
+// validateAvailability fills the defaults of availability config,
+// validates it and checks it against the replicas in template config if there are
+func validateAvailability(a *availability.Config, templateConfig map[string]interface{}) error {
+	a.SetDefaults()
+	if err := a.Validate(); err != nil {
 		return err
 	}
+	if replicas, ok := availability.ReplicasOf(templateConfig); ok {
+		return a.CheckReplicas(replicas)
+	}
+	return nil
+}
 
-	// 7. record event
-	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
-		eventmodels.ClusterUpdated, nil)
+// validateAutoscaling fills the defaults of autoscaling config, validates it and makes sure
+// the availability config still allows evictions when the cluster is scaled down to the min replicas.
+// Sandbox clusters are not autoscaled, since their replicas are fixed.
+func (c *controller) validateAutoscaling(application *appmodels.Application, a *autoscaling.Config,
+	availabilityConfig *availability.Config) error {
+	a.SetDefaults()
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if !a.Enabled() {
+		return nil
+	}
+	if c.isSandbox(application.ID) {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"clusters of sandbox application %s can not be autoscaled", application.Name)
+	}
+	if availabilityConfig != nil {
+		return availabilityConfig.CheckReplicas(int(a.MinReplicas))
+	}
+	return nil
+}
 
-	// 8. update cluster in db
-	clusterModel, tags := r.toClusterModel(cluster, expireSeconds, environmentName,
-		regionName, templateInfo.Name, templateInfo.Release)
-	_, err = c.clusterMgr.UpdateByID(ctx, clusterID, clusterModel)
+// validateTemplateRelease makes sure a canary release is only used by the clusters it's available to
+func validateTemplateRelease(tr *models.TemplateRelease, cluster string) error {
+	if !tr.AvailableTo(cluster) {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"release %s of template %s is canary and not available to cluster %s",
+			tr.Name, tr.TemplateName, cluster)
+	}
+	return nil
+}
+
+const _subresourceDeprecatedReleases = "deprecatedreleases"
+
+// checkDeprecatedRelease blocks creating clusters on a deprecated release,
+// unless the current user is allowed to use deprecated releases in the application
+func (c *controller) checkDeprecatedRelease(ctx context.Context, tr *models.TemplateRelease,
+	application *appmodels.Application) error {
+	if !tr.IsDeprecated() {
+		return nil
+	}
+	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
 		return err
 	}
-
-	// 9. update cluster tags
-	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
+	decision, _, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            "create",
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceApplication,
+		SubResource:     _subresourceDeprecatedReleases,
+		Name:            strconv.FormatUint(uint64(application.ID), 10),
+		ResourceRequest: true,
+	})
 	if err != nil {
 		return err
 	}
-	if r.Tags != nil && !tagmodels.Tags(tags).Eq(tagsInDB) {
-		if err := c.clusterGitRepo.UpdateTags(ctx, application.Name, cluster.Name, cluster.Template, tags); err != nil {
-			return err
-		}
-		if err := c.tagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, clusterID, r.Tags); err != nil {
-			return err
-		}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"release %s of template %s is deprecated: %s", tr.Name, tr.TemplateName, tr.DeprecationMessage)
 	}
 	return nil
 }
@@ -542,28 +859,30 @@ type BuildTemplateInfo struct {
 	BuildConfig    map[string]interface{}
 	TemplateInfo   *codemodels.TemplateInfo
 	TemplateConfig map[string]interface{}
+	// PreviousTemplateConfig is the template config replaced by TemplateConfig,
+	// which is the config inherited from the application when creating
+	PreviousTemplateConfig map[string]interface{}
 }
 
-func (info *BuildTemplateInfo) Validate(ctx context.Context,
-	trGetter templateschema.Getter, templateSchemaRenderVal map[string]string, buildSchema *build.Schema) error {
+func (info *BuildTemplateInfo) Validate(ctx context.Context, validator templatevalidation.Service,
+	templateSchemaRenderVal map[string]string, buildSchema *build.Schema) error {
 	if templateSchemaRenderVal == nil {
 		templateSchemaRenderVal = make(map[string]string)
 	}
 	// TODO (remove it, currently some template need it)
 	templateSchemaRenderVal["resourceType"] = "cluster"
-	schema, err := trGetter.GetTemplateSchema(ctx, info.TemplateInfo.Name,
-		info.TemplateInfo.Release, templateSchemaRenderVal)
-	if err != nil {
-		return err
-	}
-	err = jsonschema.Validate(schema.Application.JSONSchema, info.TemplateConfig, false)
-	if err != nil {
+	if err := validator.Validate(ctx, &templatevalidation.Input{
+		Template:            info.TemplateInfo.Name,
+		Release:             info.TemplateInfo.Release,
+		RenderValues:        templateSchemaRenderVal,
+		Application:         info.TemplateConfig,
+		PreviousApplication: info.PreviousTemplateConfig,
+	}); err != nil {
 		return err
 	}
 
 	if buildSchema != nil && info.BuildConfig != nil && len(info.BuildConfig) > 0 {
-		err = jsonschema.Validate(buildSchema.JSONSchema, info.BuildConfig, false)
-		if err != nil {
+		if err := jsonschema.Validate(buildSchema.JSONSchema, info.BuildConfig, false); err != nil {
 			return err
 		}
 	}
@@ -598,6 +917,8 @@ func (c *controller) customizeCreateReqBuildTemplateInfo(ctx context.Context, pa
 			buildTemplateInfo.BuildConfig = appGitRepoFile.BuildConf
 		}
 		buildTemplateInfo.TemplateConfig = appGitRepoFile.TemplateConf
+		// the template config of the application is changed by merging
+		buildTemplateInfo.PreviousTemplateConfig = mergemap.Copy(appGitRepoFile.TemplateConf)
 	}
 
 	if params.TemplateConfig != nil {
@@ -657,12 +978,19 @@ func (c *controller) CreatePipelineRun(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	// deploys to protected environments keep pending until approved
+	approvalRequired := c.deployApprovalConfig.Of(cluster.EnvironmentName) != nil
+
 	// 找一下是否需要check，如果不需要则直接设为ready
 	checks, err := c.prSvc.GetCheckByResource(ctx, clusterID, common.ResourceCluster)
 	if err != nil {
 		return nil, err
 	}
-	if len(checks) == 0 {
+	if len(checks) == 0 && !approvalRequired {
 		pipelineRun.Status = string(prmodels.StatusReady)
 	}
 
@@ -672,6 +1000,11 @@ func (c *controller) CreatePipelineRun(ctx context.Context, clusterID uint,
 
 	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pipelineRun.ID,
 		eventmodels.PipelinerunCreated, nil)
+	if approvalRequired {
+		if err := c.requestDeployApproval(ctx, pipelineRun, cluster.EnvironmentName); err != nil {
+			return nil, err
+		}
+	}
 
 	firstCanRollbackPipelinerun, err := c.prMgr.PipelineRun.GetFirstCanRollbackPipelinerun(ctx, pipelineRun.ClusterID)
 	if err != nil {
@@ -9,13 +9,21 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	mock_code "github.com/horizoncd/horizon/mock/pkg/cluster/code"
 	mock_gitrepo "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/auth"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
+	"github.com/horizoncd/horizon/pkg/cluster/availability"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
+	deployapprovalconfig "github.com/horizoncd/horizon/pkg/config/deployapproval"
+	sandboxconfig "github.com/horizoncd/horizon/pkg/config/sandbox"
+	deployapprovalmodels "github.com/horizoncd/horizon/pkg/deployapproval/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
@@ -25,6 +33,8 @@ import (
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
 )
 
@@ -33,7 +43,7 @@ func TestCreatePipelineRun(t *testing.T) {
 	if err := db.AutoMigrate(&appmodels.Application{}, &models.Cluster{},
 		&regionmodels.Region{}, &membermodels.Member{}, &registrymodels.Registry{},
 		&prmodels.Pipelinerun{}, &groupmodels.Group{}, &prmodels.Check{},
-		&usermodel.User{}, &eventmodels.Event{}); err != nil {
+		&usermodel.User{}, &eventmodels.Event{}, &deployapprovalmodels.DeployApproval{}); err != nil {
 		panic(err)
 	}
 	param := managerparam.InitManager(db)
@@ -64,6 +74,10 @@ func TestCreatePipelineRun(t *testing.T) {
 		clusterGitRepo: mockClusterGitRepo,
 		commitGetter:   mockGitGetter,
 		eventSvc:       eventservice.New(param),
+		deployApprovalConfig: deployapprovalconfig.Config{
+			Environments: []deployapprovalconfig.Environment{{Name: "online"}},
+		},
+		deployApprovalMgr: param.DeployApprovalMgr,
 	}
 
 	_, err := param.UserMgr.Create(ctx, &usermodel.User{
@@ -139,6 +153,24 @@ func TestCreatePipelineRun(t *testing.T) {
 	_, err = controller.CreatePipelineRun(ctx, clusterGit.ID, requestRollback)
 	assert.NotNil(t, err)
 
+	// deploys to protected environments wait for approval
+	clusterOnline, err := param.ClusterMgr.Create(ctx, &models.Cluster{
+		Name:            "clusterOnline",
+		ApplicationID:   app.ID,
+		GitURL:          "hello",
+		RegionName:      region.Name,
+		EnvironmentName: "online",
+	}, nil, nil)
+	assert.NoError(t, err)
+	pipelineOnline, err := controller.CreatePipelineRun(ctx, clusterOnline.ID, requestBuildDeploy)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", pipelineOnline.Status)
+	approval, err := param.DeployApprovalMgr.GetByPipelinerunID(ctx, pipelineOnline.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, deployapprovalmodels.StatusPending, approval.Status)
+	assert.Nil(t, controller.checkDirectDeploy(clusterGit))
+	assert.Equal(t, herrors.ErrDeployApprovalRequired, perror.Cause(controller.checkDirectDeploy(clusterOnline)))
+
 	_, err = param.PRMgr.Check.Create(ctx, &prmodels.Check{
 		Resource: common.Resource{
 			ResourceID: group.ID,
@@ -151,3 +183,55 @@ func TestCreatePipelineRun(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "pending", pipelineBuildDeployPending.Status)
 }
+
+// applicationAuthorizer allows to use deprecated releases in the applications listed only
+type applicationAuthorizer map[string]bool
+
+func (a applicationAuthorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetResource() == common.ResourceApplication &&
+		attr.GetSubResource() == _subresourceDeprecatedReleases &&
+		attr.GetVerb() == "create" && a[attr.GetName()] {
+		return auth.DecisionAllow, "", nil
+	}
+	return auth.DecisionDeny, "not a pe", nil
+}
+
+func TestCheckDeprecatedRelease(t *testing.T) {
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+	c := &controller{authorizer: applicationAuthorizer{"1": true}}
+
+	stable := &trmodels.TemplateRelease{Name: "v1.0.1", TemplateName: "javaapp", Channel: trmodels.ChannelStable}
+	deprecated := &trmodels.TemplateRelease{Name: "v1.0.0", TemplateName: "javaapp",
+		Channel: trmodels.ChannelDeprecated, DeprecationMessage: "use v1.0.1 instead"}
+
+	assert.Nil(t, c.checkDeprecatedRelease(ctx, stable, &appmodels.Application{Model: global.Model{ID: 2}}))
+	assert.Nil(t, c.checkDeprecatedRelease(ctx, deprecated, &appmodels.Application{Model: global.Model{ID: 1}}))
+	err := c.checkDeprecatedRelease(ctx, deprecated, &appmodels.Application{Model: global.Model{ID: 2}})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	assert.Contains(t, err.Error(), "use v1.0.1 instead")
+}
+
+func TestValidateAutoscaling(t *testing.T) {
+	c := &controller{sandboxConfig: sandboxconfig.Config{ApplicationID: 3}}
+	application := &appmodels.Application{Model: global.Model{ID: 2}, Name: "app"}
+	cpu := int32(80)
+
+	config := &autoscaling.Config{MaxReplicas: 3, CPUUtilization: &cpu}
+	assert.Nil(t, c.validateAutoscaling(application, config, nil))
+	assert.Equal(t, int32(1), config.MinReplicas)
+
+	// an empty config removes the autoscaler
+	assert.Nil(t, c.validateAutoscaling(application, &autoscaling.Config{}, nil))
+
+	// no pod could be evicted when the cluster is scaled down to 1 replica
+	err := c.validateAutoscaling(application, &autoscaling.Config{MaxReplicas: 3, CPUUtilization: &cpu},
+		&availability.Config{PodDisruptionBudget: &availability.PodDisruptionBudget{MinAvailable: "1"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	assert.Nil(t, c.validateAutoscaling(application, &autoscaling.Config{MinReplicas: 2, MaxReplicas: 3,
+		CPUUtilization: &cpu}, &availability.Config{PodDisruptionBudget: &availability.PodDisruptionBudget{
+		MinAvailable: "1"}}))
+
+	sandbox := &appmodels.Application{Model: global.Model{ID: 3}, Name: "sandbox"}
+	err = c.validateAutoscaling(sandbox, &autoscaling.Config{MaxReplicas: 3, CPUUtilization: &cpu}, nil)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	asynctaskmodels "github.com/horizoncd/horizon/pkg/asynctask/models"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// _maxBatchDeployClusters bounds the clusters of a batch deploy, which run one by one in a task
+const _maxBatchDeployClusters = 100
+
+func (c *controller) BatchDeploy(ctx context.Context, applicationID uint,
+	r *BatchDeployRequest) (*BatchDeployAsyncResponse, error) {
+	const op = "cluster controller: batch deploy"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if len(r.ClusterIDs) == 0 || len(r.ClusterIDs) > _maxBatchDeployClusters {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"count of clusterIDs must be between 1 and %d", _maxBatchDeployClusters)
+	}
+
+	result := &BatchDeployResult{}
+	seen := make(map[uint]bool, len(r.ClusterIDs))
+	for _, clusterID := range r.ClusterIDs {
+		if seen[clusterID] {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "cluster %d is duplicated", clusterID)
+		}
+		seen[clusterID] = true
+
+		cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+		if err != nil {
+			return nil, err
+		}
+		// permissions of the batch deploy are checked against the application
+		if cluster.ApplicationID != applicationID {
+			return nil, perror.Wrapf(herrors.ErrForbidden,
+				"cluster %s does not belong to application %d", cluster.Name, applicationID)
+		}
+		result.Clusters = append(result.Clusters, &ClusterDeployResult{
+			ClusterID:   cluster.ID,
+			ClusterName: cluster.Name,
+			Status:      BatchDeployStatusPending,
+		})
+	}
+
+	task, err := c.asyncTaskSvc.Submit(ctx, asynctaskmodels.TypeClusterBatchDeploy,
+		func(ctx context.Context, reporter asynctaskservice.Reporter) (interface{}, error) {
+			err := c.batchDeploy(ctx, reporter, r, result)
+			return result, err
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &BatchDeployAsyncResponse{TaskID: task.ID}, nil
+}
+
+// batchDeploy deploys all the clusters even if some of them fail, and fails if any of them fails
+func (c *controller) batchDeploy(ctx context.Context, reporter asynctaskservice.Reporter,
+	r *BatchDeployRequest, result *BatchDeployResult) error {
+	total, failed := len(result.Clusters), 0
+	for i, cluster := range result.Clusters {
+		reporter.Progress(i, total, fmt.Sprintf("deploying cluster %s", cluster.ClusterName))
+		resp, err := c.Deploy(ctx, cluster.ClusterID, &DeployRequest{
+			Title:       r.Title,
+			Description: r.Description,
+		})
+		if err != nil {
+			cluster.Status = BatchDeployStatusFailed
+			cluster.Message = err.Error()
+			failed++
+		} else {
+			cluster.Status = BatchDeployStatusDeployed
+			cluster.PipelinerunID = resp.PipelinerunID
+		}
+		reporter.Result(result)
+	}
+	reporter.Progress(total, total, "deployed")
+	if failed > 0 {
+		return perror.Errorf("%d of %d cluster(s) failed to deploy", failed, total)
+	}
+	return nil
+}
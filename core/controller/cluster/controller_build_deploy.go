@@ -28,6 +28,7 @@ import (
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/pipelinestep"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	tokensvc "github.com/horizoncd/horizon/pkg/token/service"
@@ -96,6 +97,13 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	if err := c.checkDirectDeploy(cluster); err != nil {
+		return nil, err
+	}
+	if _, err := c.deployWindowSvc.Check(ctx, clusterID, 0); err != nil {
+		return nil, err
+	}
+
 	// 2. add pipelinerun in db
 	pr := &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
@@ -115,6 +123,7 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	c.snapshotSvc.TakeBeforeDeploy(ctx, cluster, configCommit.Gitops, prCreated.ID)
 
 	// 3. generate a JWT token for tekton callback
 	token, err := c.tokenSvc.CreateJWTToken(strconv.Itoa(int(currentUser.GetID())),
@@ -139,6 +148,12 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	customSteps, err := c.pipelineStepSvc.ListPipelineRunSteps(ctx, application.ID,
+		cluster.Template, cluster.TemplateRelease, prmodels.ActionBuildDeploy)
+	if err != nil {
+		return nil, err
+	}
+
 	prGit := tekton.PipelineRunGit{
 		URL:       cluster.GitURL,
 		Subfolder: cluster.GitSubfolder,
@@ -151,12 +166,13 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		prGit.Branch = prCreated.GitRef
 	}
 
-	ciEventID, err := tektonClient.CreatePipelineRun(ctx, &tekton.PipelineRun{
+	ciEventID, err := tektonClient.CreatePipelineRun(ctx, pipelinestep.WithSBOMStep(c.sbomConfig, &tekton.PipelineRun{
 		Action:           prmodels.ActionBuildDeploy,
 		Application:      application.Name,
 		ApplicationID:    application.ID,
 		Cluster:          cluster.Name,
 		ClusterID:        cluster.ID,
+		CustomSteps:      customSteps,
 		Environment:      cluster.EnvironmentName,
 		Git:              prGit,
 		ImageURL:         imageURL,
@@ -167,7 +183,7 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		RegionID:         regionEntity.ID,
 		Template:         cluster.Template,
 		Token:            token,
-	})
+	}))
 	if err != nil {
 		return nil, err
 	}
@@ -252,7 +268,17 @@ func (c *controller) GetDiff(ctx context.Context, clusterID uint, refType, ref s
 	if err != nil {
 		return nil, err
 	}
-	return c.ofClusterDiff(cluster.GitURL, refType, ref, commit, diff)
+	resp, err := c.ofClusterDiff(cluster.GitURL, refType, ref, commit, diff)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. get env changes which are not deployed yet
+	resp.EnvChanges, err = c.envChangesSinceLastDeploy(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (c *controller) ofClusterDiff(gitURL, refType, ref string, commit *git.Commit, diff string) (
@@ -0,0 +1,222 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	changerequestmodels "github.com/horizoncd/horizon/pkg/changerequest/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) CreateChangeRequest(ctx context.Context, clusterID uint,
+	r *CreateChangeRequestRequest, mergePatch bool) (_ *ChangeRequest, err error) {
+	const op = "cluster controller: create change request"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.Title == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "title of change request cannot be empty")
+	}
+	updateRequest := r.toUpdateClusterRequest()
+	if !updateRequest.changesConfig() {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "change request does not change any config")
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. write config changes to a new branch
+	branch := fmt.Sprintf("%s%d", changerequestmodels.BranchPrefix, time.Now().UnixNano())
+	if err := c.clusterGitRepo.CreateBranch(ctx, application.Name, cluster.Name, branch); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if closeErr := c.clusterGitRepo.CloseBranch(ctx, application.Name,
+			cluster.Name, branch); closeErr != nil {
+			log.Warningf(ctx, "failed to clean up branch %s of cluster %s: %v", branch, cluster.Name, closeErr)
+		}
+	}()
+	if err = c.updateClusterV2(ctx, clusterID, updateRequest, mergePatch, branch); err != nil {
+		return nil, err
+	}
+
+	// 2. open a merge request for review
+	mergeRequestURL, err := c.clusterGitRepo.CreateMergeRequest(ctx, application.Name, cluster.Name,
+		branch, gitrepo.GitOpsBranch, r.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. record the change request
+	changeRequest, err := c.changeRequestMgr.Create(ctx, &changerequestmodels.ChangeRequest{
+		ClusterID:       clusterID,
+		Title:           r.Title,
+		Description:     r.Description,
+		Branch:          branch,
+		MergeRequestURL: mergeRequestURL,
+		Status:          changerequestmodels.StatusPending,
+		CreatedBy:       currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofChangeRequest(changeRequest), nil
+}
+
+func (c *controller) ListChangeRequests(ctx context.Context, clusterID uint,
+	status string) ([]*ChangeRequest, error) {
+	const op = "cluster controller: list change requests"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	changeRequests, err := c.changeRequestMgr.ListByCluster(ctx, clusterID, status)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*ChangeRequest, 0, len(changeRequests))
+	for _, changeRequest := range changeRequests {
+		resp = append(resp, ofChangeRequest(changeRequest))
+	}
+	return resp, nil
+}
+
+func (c *controller) GetChangeRequest(ctx context.Context, clusterID,
+	changeRequestID uint) (*ChangeRequest, error) {
+	const op = "cluster controller: get change request"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	changeRequest, err := c.getChangeRequest(ctx, clusterID, changeRequestID)
+	if err != nil {
+		return nil, err
+	}
+	resp := ofChangeRequest(changeRequest)
+	if changeRequest.Status != changerequestmodels.StatusPending {
+		return resp, nil
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := gitrepo.GitOpsBranch, changeRequest.Branch
+	resp.Diff, err = c.clusterGitRepo.CompareConfig(ctx, application.Name, cluster.Name, &from, &to)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *controller) ReviewChangeRequest(ctx context.Context, clusterID, changeRequestID uint,
+	r *ReviewChangeRequestRequest) (*ChangeRequest, error) {
+	const op = "cluster controller: review change request"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changeRequest, err := c.getChangeRequest(ctx, clusterID, changeRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if changeRequest.Status != changerequestmodels.StatusPending {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"change request %d has been %s", changeRequestID, changeRequest.Status)
+	}
+	if changeRequest.CreatedBy == currentUser.GetID() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "change request cannot be reviewed by its creator")
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. merge config changes if approved
+	changeRequest.Status = changerequestmodels.StatusRejected
+	if r.Approved {
+		commit, err := c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
+			changeRequest.Branch, gitrepo.GitOpsBranch, nil)
+		if err != nil {
+			return nil, err
+		}
+		changeRequest.Status = changerequestmodels.StatusMerged
+		changeRequest.MergedCommit = commit
+	}
+
+	// 2. clean up the branch, the review result is saved even if it fails
+	if err := c.clusterGitRepo.CloseBranch(ctx, application.Name, cluster.Name,
+		changeRequest.Branch); err != nil {
+		log.Warningf(ctx, "failed to clean up branch %s of cluster %s: %v",
+			changeRequest.Branch, cluster.Name, err)
+	}
+
+	// 3. save the review result
+	now := time.Now()
+	changeRequest.ReviewedBy = currentUser.GetID()
+	changeRequest.ReviewComment = r.Comment
+	changeRequest.ReviewedAt = &now
+	if err := c.changeRequestMgr.UpdateReview(ctx, changeRequest); err != nil {
+		return nil, err
+	}
+
+	if changeRequest.Status == changerequestmodels.StatusMerged {
+		c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
+			eventmodels.ClusterUpdated, nil)
+	}
+	return ofChangeRequest(changeRequest), nil
+}
+
+// getChangeRequest gets the change request and checks that it belongs to the cluster
+func (c *controller) getChangeRequest(ctx context.Context, clusterID,
+	changeRequestID uint) (*changerequestmodels.ChangeRequest, error) {
+	changeRequest, err := c.changeRequestMgr.GetByID(ctx, changeRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if changeRequest.ClusterID != clusterID {
+		return nil, herrors.NewErrNotFound(herrors.ChangeRequestInDB,
+			fmt.Sprintf("change request %d not found in cluster %d", changeRequestID, clusterID))
+	}
+	return changeRequest, nil
+}
@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) CloneCluster(ctx context.Context, clusterID uint,
+	request *CloneClusterRequest) (*CreateClusterResponseV2, error) {
+	const op = "cluster controller: clone cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get the origin cluster with its config in the gitops repo
+	origin, err := c.GetClusterV2(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. create the cluster with the config of the origin, which is validated and rendered again,
+	// the template info is always set to keep the config from being inherited from the application
+	params := &CreateClusterParamsV2{
+		CreateClusterRequestV2: &CreateClusterRequestV2{
+			Name:           request.Name,
+			Description:    origin.Description,
+			ExpireTime:     origin.ExpireTime,
+			Git:            origin.Git,
+			Tags:           origin.Tags,
+			BuildConfig:    origin.BuildConfig,
+			TemplateInfo:   origin.TemplateInfo,
+			TemplateConfig: origin.TemplateConfig,
+			Rollout:        origin.Rollout,
+			NetworkPolicy:  origin.NetworkPolicy,
+			Availability:   origin.Availability,
+			Autoscaling:    origin.Autoscaling,
+		},
+		ApplicationID: origin.ApplicationID,
+		Environment:   origin.Scope.Environment,
+		Region:        origin.Scope.Region,
+	}
+	if request.Description != nil {
+		params.Description = *request.Description
+	}
+	if origin.Image != "" {
+		params.Image = &origin.Image
+	}
+	if request.ApplicationID != 0 {
+		params.ApplicationID = request.ApplicationID
+	}
+	if request.Environment != "" {
+		params.Environment = request.Environment
+	}
+	if request.Region != "" {
+		params.Region = request.Region
+	}
+	return c.CreateClusterV2(ctx, params)
+}
@@ -0,0 +1,157 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/rollout"
+	metadataconfig "github.com/horizoncd/horizon/pkg/config/metadata"
+	namingconfig "github.com/horizoncd/horizon/pkg/config/naming"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	groupservice "github.com/horizoncd/horizon/pkg/group/service"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/naming"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
+)
+
+// testCloneCluster clones a cluster in environment test2 into dev2,
+// which are created with the region and the template in testV2
+func testCloneCluster(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	applicationGitRepo := applicationgitrepomock.NewMockApplicationGitRepo2(mockCtl)
+	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
+
+	namingSvc, err := naming.NewService(manager, namingconfig.Config{})
+	assert.Nil(t, err)
+	metadataSvc, err := metadataservice.NewService(manager, metadataconfig.Config{})
+	assert.Nil(t, err)
+	c := &controller{
+		clusterMgr:           manager.ClusterMgr,
+		clusterGitRepo:       clusterGitRepo,
+		applicationMgr:       manager.ApplicationMgr,
+		applicationGitRepo:   applicationGitRepo,
+		templateMgr:          manager.TemplateMgr,
+		templateReleaseMgr:   manager.TemplateReleaseMgr,
+		templateSchemaGetter: templateSchemaGetter,
+		templateValidator:    templatevalidation.NewService(templateSchemaGetter),
+		envMgr:               manager.EnvMgr,
+		envRegionMgr:         manager.EnvRegionMgr,
+		regionMgr:            manager.RegionMgr,
+		groupSvc:             groupservice.NewService(manager),
+		prMgr:                manager.PRMgr,
+		userManager:          manager.UserMgr,
+		tagMgr:               manager.TagMgr,
+		eventSvc:             eventservice.New(manager),
+		memberManager:        manager.MemberMgr,
+		namingSvc:            namingSvc,
+		metadataSvc:          metadataSvc,
+		quotaSvc:             quotaservice.NewService(manager),
+	}
+
+	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
+		Name: "TestCloneCluster",
+		Path: "TestCloneCluster",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationMgr.Create(ctx, &appmodels.Application{
+		GroupID:         group.ID,
+		Name:            "TestCloneCluster",
+		Priority:        "P3",
+		Template:        "rollout",
+		TemplateRelease: "v1.0.0",
+	}, nil)
+	assert.Nil(t, err)
+	origin, err := manager.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "TestCloneCluster",
+		Description:     "origin",
+		EnvironmentName: "test2",
+		RegionName:      "hz",
+		Template:        "rollout",
+		TemplateRelease: "v1.0.0",
+	}, nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, manager.TagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, origin.ID,
+		tagmodels.TagsBasic{{Key: "key1", Value: "value1"}}))
+
+	clusterGitRepo.EXPECT().GetCluster(ctx, application.Name, origin.Name, "rollout").Return(&gitrepo.ClusterFiles{
+		PipelineJSONBlob:    pipelineJSONBlob,
+		ApplicationJSONBlob: applicationJSONBlob,
+		Rollout:             rollout.Default(),
+	}, nil).Times(2)
+
+	// the name of the origin cluster can not be used
+	_, err = c.CloneCluster(ctx, origin.ID, &CloneClusterRequest{Name: origin.Name})
+	assert.True(t, perror.Cause(err) == herrors.ErrNameConflict)
+
+	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), application.Name, "dev2").
+		Return(&appgitrepo.GetResponse{}, nil).Times(1)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), "rollout", "v1.0.0", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{
+				JSONSchema: applicationSchema,
+			},
+			Pipeline: &trschema.Schema{
+				JSONSchema: pipelineSchema,
+			},
+		}, nil).Times(1)
+	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *gitrepo.CreateClusterParams) error {
+			// the config of the origin is rendered into the new environment
+			assert.Equal(t, "TestCloneCluster-dev", params.Cluster)
+			assert.Equal(t, "dev2", params.Environment)
+			assertMapEqual(t, applicationJSONBlob, params.ApplicationJSONBlob)
+			assertMapEqual(t, pipelineJSONBlob, params.PipelineJSONBlob)
+			assert.Equal(t, rollout.Default(), params.Rollout)
+			return nil
+		},
+	).Times(1)
+
+	resp, err := c.CloneCluster(ctx, origin.ID, &CloneClusterRequest{
+		Name:        "TestCloneCluster-dev",
+		Environment: "dev2",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, application.ID, resp.ApplicationID)
+	assert.Equal(t, "dev2", resp.Scope.Environment)
+	assert.Equal(t, "hz", resp.Scope.Region)
+
+	cloned, err := manager.ClusterMgr.GetByID(ctx, resp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, origin.Description, cloned.Description)
+	tags, err := manager.TagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, resp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, tagmodels.TagsBasic{{Key: "key1", Value: "value1"}}, tagmodels.Tags(tags).IntoTagsBasic())
+}
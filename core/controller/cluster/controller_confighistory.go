@@ -0,0 +1,76 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListConfigCommits(ctx context.Context, clusterID uint,
+	pageNumber, pageSize int) ([]*pkgcommon.ConfigCommit, error) {
+	const op = "cluster controller: list config commits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	return c.clusterGitRepo.ListConfigCommits(ctx, application.Name, cluster.Name, pageNumber, pageSize)
+}
+
+func (c *controller) RollbackConfig(ctx context.Context, clusterID uint, r *RollbackConfigRequest) error {
+	const op = "cluster controller: rollback config"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if r.Commit == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "commit to rollback to is empty")
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+
+	// the config of the commit is parsed with the current template,
+	// it fails if the template of the cluster has been changed since then
+	files, err := c.clusterGitRepo.GetClusterByCommit(ctx, application.Name, cluster.Name,
+		cluster.Template, r.Commit)
+	if err != nil {
+		return err
+	}
+	if files.ApplicationJSONBlob == nil && files.PipelineJSONBlob == nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "no config is found in commit %s", r.Commit)
+	}
+
+	// the config is written back like an update, so it's validated and rendered as well
+	return c.updateClusterV2(ctx, clusterID, &UpdateClusterRequestV2{
+		Description:    cluster.Description,
+		BuildConfig:    files.PipelineJSONBlob,
+		TemplateConfig: files.ApplicationJSONBlob,
+		ConfigCommit:   r.ConfigCommit,
+	}, false, "")
+}
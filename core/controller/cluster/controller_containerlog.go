@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// ContainerLogOptions selects the logs of a container
+type ContainerLogOptions struct {
+	// Follow keeps streaming the new logs until the container stops
+	Follow bool
+	// TailLines is the number of lines from the end of the logs, all the logs are returned if it's nil
+	TailLines *int64
+	// SinceTime only returns the logs after the time
+	SinceTime *time.Time
+	// Previous returns the logs of the previous terminated container
+	Previous   bool
+	Timestamps bool
+}
+
+func (c *controller) StreamContainerLog(ctx context.Context, clusterID uint, podName, containerName string,
+	options *ContainerLogOptions) (_ io.ReadCloser, err error) {
+	const op = "cluster controller: stream container log"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, regionEntity, namespace, err := c.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	logOptions := &corev1.PodLogOptions{
+		Container:  containerName,
+		Follow:     options.Follow,
+		TailLines:  options.TailLines,
+		Previous:   options.Previous,
+		Timestamps: options.Timestamps,
+	}
+	if options.SinceTime != nil {
+		sinceTime := metav1.NewTime(*options.SinceTime)
+		logOptions.SinceTime = &sinceTime
+	}
+	return c.k8sutil.StreamContainerLog(ctx, &cd.StreamContainerLogParams{
+		RegionEntity: regionEntity,
+		Cluster:      cluster.Name,
+		Namespace:    namespace,
+		Pod:          podName,
+		LogOptions:   logOptions,
+	})
+}
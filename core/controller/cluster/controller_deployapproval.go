@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	deployapprovalmodels "github.com/horizoncd/horizon/pkg/deployapproval/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+// checkDirectDeploy refuses deploys which skip pipelineruns to protected environments,
+// they cannot wait for approval
+func (c *controller) checkDirectDeploy(cluster *clustermodels.Cluster) error {
+	if c.deployApprovalConfig.Of(cluster.EnvironmentName) == nil {
+		return nil
+	}
+	return perror.Wrapf(herrors.ErrDeployApprovalRequired,
+		"deploys to environment %s must be approved, please create a pipelinerun instead",
+		cluster.EnvironmentName)
+}
+
+// requestDeployApproval records a pending approval of the pipelinerun deploying to a protected environment
+func (c *controller) requestDeployApproval(ctx context.Context, pipelinerun *prmodels.Pipelinerun,
+	environment string) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.deployApprovalMgr.Create(ctx, &deployapprovalmodels.DeployApproval{
+		PipelinerunID: pipelinerun.ID,
+		ClusterID:     pipelinerun.ClusterID,
+		Environment:   environment,
+		Status:        deployapprovalmodels.StatusPending,
+		CreatedBy:     currentUser.GetID(),
+	})
+	if err != nil {
+		return err
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pipelinerun.ID,
+		eventmodels.PipelinerunAwaiting, nil)
+	return nil
+}
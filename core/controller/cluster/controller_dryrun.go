@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	templaterender "github.com/horizoncd/horizon/pkg/templaterelease/render"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) DryRunClusterV2(ctx context.Context, clusterID uint,
+	r *UpdateClusterRequestV2, mergePatch bool) (*templaterender.Preview, error) {
+	const op = "cluster controller: dry run cluster v2"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. validate and merge the request as updating does
+	update, err := c.prepareUpdateClusterV2(ctx, clusterID, r, mergePatch, "")
+	if err != nil {
+		return nil, err
+	}
+	cluster, application := update.cluster, update.application
+
+	// 2. render the value files without writing them
+	current, rendered, err := c.clusterGitRepo.RenderCluster(ctx, update.params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. render the manifests and compare them with the current ones
+	currentRelease, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+		cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	valueFiles := c.clusterGitRepo.GetRepoInfo(ctx, application.Name, cluster.Name).ValueFiles
+	return c.templateRenderer.Preview(ctx, &templaterender.Input{
+		TemplateRelease: currentRelease,
+		ReleaseName:     cluster.Name,
+		Namespace:       namespaceOf(current, currentRelease.ChartName),
+		ValueFiles:      valueFiles,
+		Values:          current,
+	}, &templaterender.Input{
+		TemplateRelease: update.params.TemplateRelease,
+		ReleaseName:     cluster.Name,
+		Namespace:       namespaceOf(rendered, update.params.TemplateRelease.ChartName),
+		ValueFiles:      valueFiles,
+		Values:          rendered,
+	})
+}
+
+// namespaceOf returns the namespace in the env value file of the cluster
+func namespaceOf(values map[string]interface{}, chartName string) string {
+	envValues, _ := values[common.GitopsFileEnv].(map[string]interface{})
+	chartValues, _ := envValues[chartName].(map[string]interface{})
+	env, _ := chartValues[common.GitopsEnvValueNamespace].(map[string]interface{})
+	namespace, _ := env["namespace"].(string)
+	return namespace
+}
@@ -0,0 +1,213 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/cluster/envvar"
+	envchangemodels "github.com/horizoncd/horizon/pkg/clusterenv/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListEnvs(ctx context.Context, clusterID uint) ([]*envvar.EnvVar, error) {
+	const op = "cluster controller: list envs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := envvar.List(files.ApplicationJSONBlob)
+	if err != nil {
+		return nil, err
+	}
+	for i, env := range envs {
+		envs[i] = env.Masked()
+	}
+	return envs, nil
+}
+
+func (c *controller) AddEnv(ctx context.Context, clusterID uint, env *envvar.EnvVar) error {
+	const op = "cluster controller: add env"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	env.SetDefaults()
+	if err := env.Validate(); err != nil {
+		return err
+	}
+	return c.updateEnvs(ctx, clusterID, func(envs []*envvar.EnvVar) ([]*envvar.EnvVar,
+		*envchangemodels.ClusterEnvChange, error) {
+		if envvar.IndexOf(envs, env.Name) >= 0 {
+			return nil, nil, perror.Wrapf(herrors.ErrNameConflict, "env %s already exists", env.Name)
+		}
+		return append(envs, env), newEnvChange(nil, env), nil
+	})
+}
+
+func (c *controller) UpdateEnv(ctx context.Context, clusterID uint, name string, r *UpdateEnvRequest) error {
+	const op = "cluster controller: update env"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.updateEnvs(ctx, clusterID, func(envs []*envvar.EnvVar) ([]*envvar.EnvVar,
+		*envchangemodels.ClusterEnvChange, error) {
+		i := envvar.IndexOf(envs, name)
+		if i < 0 {
+			return nil, nil, envNotFound(clusterID, name)
+		}
+		before, after := envs[i], *envs[i]
+		// the masked value read from the list is sent back when other fields of a secret are updated
+		if r.Value != nil && !(before.Secret && *r.Value == envvar.MaskedValue) {
+			after.Value = *r.Value
+		}
+		if r.Type != nil {
+			after.Type = *r.Type
+		}
+		if r.Secret != nil {
+			after.Secret = *r.Secret
+		}
+		after.SetDefaults()
+		if err := after.Validate(); err != nil {
+			return nil, nil, err
+		}
+		envs[i] = &after
+		return envs, newEnvChange(before, &after), nil
+	})
+}
+
+func (c *controller) DeleteEnv(ctx context.Context, clusterID uint, name string) error {
+	const op = "cluster controller: delete env"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.updateEnvs(ctx, clusterID, func(envs []*envvar.EnvVar) ([]*envvar.EnvVar,
+		*envchangemodels.ClusterEnvChange, error) {
+		i := envvar.IndexOf(envs, name)
+		if i < 0 {
+			return nil, nil, envNotFound(clusterID, name)
+		}
+		change := newEnvChange(envs[i], nil)
+		return append(envs[:i], envs[i+1:]...), change, nil
+	})
+}
+
+func (c *controller) ListEnvHistory(ctx context.Context, clusterID uint, name string,
+	query *q.Query) (int, []*EnvChange, error) {
+	const op = "cluster controller: list env history"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return 0, nil, err
+	}
+	total, changes, err := c.envChangeMgr.ListByClusterID(ctx, clusterID, name, query)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp := make([]*EnvChange, 0, len(changes))
+	for _, change := range changes {
+		resp = append(resp, ofEnvChange(change))
+	}
+	return total, resp, nil
+}
+
+// updateEnvs applies the mutation to the envs in the cluster's template config and writes the config back,
+// the update fails if the config is changed by others in the meantime. The change is recorded in the history.
+func (c *controller) updateEnvs(ctx context.Context, clusterID uint,
+	mutate func([]*envvar.EnvVar) ([]*envvar.EnvVar, *envchangemodels.ClusterEnvChange, error)) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return err
+	}
+	envs, err := envvar.List(files.ApplicationJSONBlob)
+	if err != nil {
+		return err
+	}
+	envs, change, err := mutate(envs)
+	if err != nil {
+		return err
+	}
+
+	templateConfig := files.ApplicationJSONBlob
+	if templateConfig == nil {
+		templateConfig = make(map[string]interface{})
+	}
+	envvar.Set(templateConfig, envs)
+	if err := c.UpdateClusterV2(ctx, clusterID, &UpdateClusterRequestV2{
+		TemplateConfig: templateConfig,
+		ConfigCommit:   files.Commit,
+	}, false); err != nil {
+		return err
+	}
+
+	change.ClusterID = clusterID
+	change.ConfigCommit = files.Commit
+	change.CreatedBy = currentUser.GetID()
+	_, err = c.envChangeMgr.Create(ctx, change)
+	return err
+}
+
+// envChangesSinceLastDeploy lists the env changes which are not deployed by the last successful pipelinerun
+func (c *controller) envChangesSinceLastDeploy(ctx context.Context, clusterID uint) ([]*EnvChange, error) {
+	var since time.Time
+	pr, err := c.prMgr.PipelineRun.GetLatestSuccessByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if pr != nil {
+		since = pr.CreatedAt
+	}
+	changes, err := c.envChangeMgr.ListSince(ctx, clusterID, since)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	resp := make([]*EnvChange, 0, len(changes))
+	for _, change := range changes {
+		resp = append(resp, ofEnvChange(change))
+	}
+	return resp, nil
+}
+
+func envNotFound(clusterID uint, name string) error {
+	return herrors.NewErrNotFound(herrors.ClusterEnvInConfig,
+		fmt.Sprintf("env %s of cluster %d not found", name, clusterID))
+}
@@ -66,6 +66,7 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 			URL:      &pr.GitURL,
 			CommitID: &pr.GitCommit,
 		},
+		Annotations: c.buildAnnotations(ctx, cluster, pr),
 	}
 	switch pr.GitRefType {
 	case codemodels.GitRefTypeTag:
@@ -98,6 +99,9 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	}
 
 	// 5. merge branch from gitops to master  and update status
+	if err := c.checkManifestPolicies(ctx, cluster, commit); err != nil {
+		return nil, err
+	}
 	masterRevision, err := c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
 		gitrepo.GitOpsBranch, c.clusterGitRepo.DefaultBranch(), &pr.ID)
 	if err != nil {
@@ -140,6 +144,7 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	// 8. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
@@ -24,6 +24,7 @@ import (
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
@@ -97,8 +98,13 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 	if (pr.Action == prmodels.ActionBuildDeploy && pr.GitURL != "") ||
 		(pr.Action == prmodels.ActionDeploy && pr.GitURL == "") {
 		log.Infof(ctx, "pipeline %v output content: %+v", r.PipelinerunID, r.Output)
+		output := make(map[string]interface{}, len(r.Output)+1)
+		for k, v := range r.Output {
+			output[k] = v
+		}
+		output[_pipelineOutputAnnotations] = c.buildAnnotations(ctx, cluster, pr)
 		commit, err := c.clusterGitRepo.UpdatePipelineOutput(ctx, application.Name, cluster.Name,
-			tr.ChartName, r.Output)
+			tr.ChartName, output)
 		if err != nil {
 			return nil, perror.WithMessage(err, op)
 		}
@@ -123,6 +129,9 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 	}
 	masterRevision := configCommit.Master
 	if diff != "" {
+		if err := c.checkManifestPolicies(ctx, cluster, configCommit.Gitops); err != nil {
+			return nil, err
+		}
 		masterRevision, err = c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
 			gitrepo.GitOpsBranch, c.clusterGitRepo.DefaultBranch(), &pr.ID)
 		if err != nil {
@@ -166,6 +175,7 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 	// 8. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
@@ -227,3 +237,33 @@ func (c *controller) InternalGetClusterStatus(ctx context.Context,
 	})
 	return c.GetClusterStatus(ctx, clusterID)
 }
+
+// checkManifestPolicies renders manifests of the cluster at the revision of gitops branch
+// and checks them against manifest policies of the cluster's environment
+func (c *controller) checkManifestPolicies(ctx context.Context, cluster *clustermodels.Cluster,
+	revision string) error {
+	if !c.manifestPolicySvc.Enabled(cluster.EnvironmentName) {
+		return nil
+	}
+	manifests, err := c.cd.GetManifests(ctx, &cd.GetManifestsParams{
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+		Cluster:     cluster.Name,
+		Revision:    revision,
+	})
+	if err != nil {
+		// application is created in cd system after the first merge, nothing to check before that
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			log.Warningf(ctx, "skip checking manifest policies of cluster %s: %v", cluster.Name, err)
+			return nil
+		}
+		// manifests are not rendered by some cd systems, such as flux
+		if perror.Cause(err) == herrors.ErrNotSupport {
+			log.Warningf(ctx, "skip checking manifest policies of cluster %s: %v", cluster.Name, err)
+			return nil
+		}
+		return err
+	}
+	_, err = c.manifestPolicySvc.Check(ctx, cluster.EnvironmentName, manifests)
+	return err
+}
@@ -0,0 +1,121 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"net/url"
+
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cd"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) KubeProxy(ctx context.Context, clusterID uint, path string, query url.Values) (_ []byte,
+	err error) {
+	const op = "cluster controller: kube proxy"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, regionEntity, namespace, err := c.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.k8sutil.KubeProxy(ctx, &cd.KubeProxyParams{
+		RegionEntity: regionEntity,
+		Cluster:      cluster.Name,
+		Namespace:    namespace,
+		Path:         path,
+		Query:        query,
+	})
+}
+
+func (c *controller) ListResources(ctx context.Context, clusterID uint, kind string) (_ []*cd.ClusterResource,
+	err error) {
+	const op = "cluster controller: list resources"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, regionEntity, namespace, err := c.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.k8sutil.ListClusterResources(ctx, &cd.ListClusterResourcesParams{
+		RegionEntity: regionEntity,
+		Cluster:      cluster.Name,
+		Namespace:    namespace,
+		Kind:         kind,
+	})
+}
+
+func (c *controller) GetResource(ctx context.Context, clusterID uint, kind, name string) (_ *cd.ClusterResourceDetail,
+	err error) {
+	const op = "cluster controller: get resource"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, regionEntity, namespace, err := c.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.k8sutil.GetClusterResource(ctx, &cd.GetClusterResourceParams{
+		RegionEntity: regionEntity,
+		Cluster:      cluster.Name,
+		Namespace:    namespace,
+		Kind:         kind,
+		Name:         name,
+	})
+}
+
+// getClusterNamespace gets the region and the namespace where the cluster's resources are deployed
+func (c *controller) getClusterNamespace(ctx context.Context, clusterID uint) (*clustermodels.Cluster,
+	*regionmodels.RegionEntity, string, error) {
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	namespace, err := c.namespaceOf(ctx, cluster, application)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return cluster, regionEntity, namespace, nil
+}
+
+// namespaceOf reads the namespace of the cluster from the env value in its git repo
+func (c *controller) namespaceOf(ctx context.Context, cluster *clustermodels.Cluster,
+	application *appmodels.Application) (string, error) {
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return "", err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return "", err
+	}
+	return envValue.Namespace, nil
+}
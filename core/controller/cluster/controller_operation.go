@@ -57,6 +57,10 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 		return nil, err
 	}
 
+	if _, err := c.deployWindowSvc.Check(ctx, clusterID, 0); err != nil {
+		return nil, err
+	}
+
 	// 1. get config commit now
 	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
 	if err != nil {
@@ -90,6 +94,7 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 	// 3. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    commit,
 	}); err != nil {
@@ -157,6 +162,13 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		imageURL, err = getDeployImage(cluster.Image, r.ImageTag)
 	}
 
+	if err := c.checkDirectDeploy(cluster); err != nil {
+		return nil, err
+	}
+	if _, err := c.deployWindowSvc.Check(ctx, clusterID, 0); err != nil {
+		return nil, err
+	}
+
 	// 2. create pipeline record
 	prCreated, err := c.prMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
@@ -175,6 +187,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	c.snapshotSvc.TakeBeforeDeploy(ctx, cluster, configCommit.Gitops, prCreated.ID)
 
 	// 3. generate a JWT token for tekton callback
 	token, err := c.tokenSvc.CreateJWTToken(strconv.Itoa(int(currentUser.GetID())),
@@ -199,6 +212,11 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if clusterFiles.PipelineJSONBlob != nil {
 		pipelineJSONBlob = clusterFiles.PipelineJSONBlob
 	}
+	customSteps, err := c.pipelineStepSvc.ListPipelineRunSteps(ctx, application.ID,
+		cluster.Template, cluster.TemplateRelease, prmodels.ActionDeploy)
+	if err != nil {
+		return nil, err
+	}
 	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
 	if err != nil {
 		return nil, err
@@ -210,6 +228,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		ApplicationID:    application.ID,
 		Cluster:          cluster.Name,
 		ClusterID:        cluster.ID,
+		CustomSteps:      customSteps,
 		Environment:      cluster.EnvironmentName,
 		Git:              prGit,
 		ImageURL:         imageURL,
@@ -310,6 +329,13 @@ func (c *controller) Rollback(ctx context.Context,
 		return nil, err
 	}
 
+	if err := c.checkDirectDeploy(cluster); err != nil {
+		return nil, err
+	}
+	if _, err := c.deployWindowSvc.Check(ctx, clusterID, 0); err != nil {
+		return nil, err
+	}
+
 	// 2. get config commit now
 	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
 	if err != nil {
@@ -334,6 +360,7 @@ func (c *controller) Rollback(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	c.snapshotSvc.TakeBeforeDeploy(ctx, cluster, lastConfigCommit.Gitops, prCreated.ID)
 
 	// Deprecated: for internal usage
 	err = c.checkAndSyncGitOpsBranch(ctx, application.Name, cluster.Name, pipelinerun.ConfigCommit)
@@ -406,6 +433,7 @@ func (c *controller) Rollback(ctx context.Context,
 	// 9. deploy cluster in cd and update status
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/common"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// _pipelineOutputAnnotations is the key of build provenance annotations in the pipeline output
+const _pipelineOutputAnnotations = "annotations"
+
+// buildAnnotations returns the build provenance of the pipelinerun, so that a running pod
+// can be traced back to the pipelinerun and the commit which it's built from
+func (c *controller) buildAnnotations(ctx context.Context, cluster *clustermodels.Cluster,
+	pr *prmodels.Pipelinerun) map[string]string {
+	annotations := map[string]string{
+		common.ClusterIDKey:            strconv.FormatUint(uint64(cluster.ID), 10),
+		common.ClusterPipelinerunIDKey: strconv.FormatUint(uint64(pr.ID), 10),
+	}
+	if pr.GitCommit != "" {
+		annotations[common.ClusterSourceCommitKey] = pr.GitCommit
+	}
+	// provenance should not fail the deploy, the builder is left out if it's not found
+	builder, err := c.userManager.GetUserByID(ctx, pr.CreatedBy)
+	if err != nil {
+		log.Warningf(ctx, "failed to get builder %d of pipelinerun %d: %v", pr.CreatedBy, pr.ID, err)
+	} else {
+		annotations[common.ClusterBuilderKey] = builder.Name
+	}
+	return annotations
+}
+
+func (c *controller) GetPodProvenance(ctx context.Context, clusterID uint,
+	podName string) (*PodProvenance, error) {
+	const op = "cluster controller: get pod provenance"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pod, err := c.GetClusterPod(ctx, clusterID, podName)
+	if err != nil {
+		return nil, err
+	}
+	annotations := pod.GetAnnotations()
+	provenance := &PodProvenance{
+		Pod:          podName,
+		ClusterID:    clusterID,
+		SourceCommit: annotations[common.ClusterSourceCommitKey],
+		Builder:      annotations[common.ClusterBuilderKey],
+	}
+	prID, err := strconv.ParseUint(annotations[common.ClusterPipelinerunIDKey], 10, 0)
+	if err != nil {
+		log.Warningf(ctx, "pod %s of cluster %d has no valid pipelinerun annotation", podName, clusterID)
+		return provenance, nil
+	}
+	provenance.PipelinerunID = uint(prID)
+
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, uint(prID))
+	if err != nil {
+		return nil, err
+	}
+	// the annotation may be copied from another cluster, only pipelineruns of the cluster are trusted
+	if pr == nil || pr.ClusterID != clusterID {
+		return provenance, nil
+	}
+	firstCanRollbackPipelinerun, err := c.prMgr.PipelineRun.GetFirstCanRollbackPipelinerun(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	provenance.Pipelinerun, err = c.prSvc.OfPipelineBasic(ctx, pr, firstCanRollbackPipelinerun)
+	if err != nil {
+		return nil, err
+	}
+	return provenance, nil
+}
@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListDeleted(ctx context.Context, applicationID uint) ([]*DeletedCluster, error) {
+	const op = "cluster controller: list deleted clusters"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	clusters, err := c.clusterMgr.ListDeleted(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*DeletedCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		resp = append(resp, ofDeletedCluster(cluster))
+	}
+	return resp, nil
+}
+
+func (c *controller) Restore(ctx context.Context, applicationID, clusterID uint) error {
+	const op = "cluster controller: restore cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get the deleted cluster under the application
+	cluster, err := c.clusterMgr.GetByIDIncludeSoftDelete(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if cluster.DeletedTs == 0 || cluster.HardDeleted || cluster.ApplicationID != applicationID {
+		return herrors.NewErrNotFound(herrors.ClusterInDB, "deleted cluster not found")
+	}
+	application, err := c.applicationMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return err
+	}
+	if err := c.quotaSvc.Check(ctx, application.GroupID, quotamodels.ResourceClusters); err != nil {
+		return err
+	}
+	exists, err := c.clusterMgr.CheckClusterExists(ctx, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return perror.Wrap(herrors.ErrNameConflict, "a cluster with the same name already exists")
+	}
+
+	// 2. move the git repo back from the recycling group
+	if err := c.clusterGitRepo.RestoreCluster(ctx, application.Name, cluster.Name, cluster.ID); err != nil {
+		return err
+	}
+
+	// 3. restore cluster in db, the resources of the cluster were deleted with it,
+	// so it is restored as a freed cluster
+	if err := c.clusterMgr.Restore(ctx, clusterID, common.ClusterStatusFreed); err != nil {
+		return err
+	}
+
+	// 4. record event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, clusterID,
+		eventmodels.ClusterRestored, nil)
+	return nil
+}
+
+// checkDeletedName returns ErrNameConflict if a cluster deleted but not purged yet has the name,
+// which is kept for the cluster to be restored
+func (c *controller) checkDeletedName(ctx context.Context, name string) error {
+	clusters, err := c.clusterMgr.GetDeletedByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(clusters) > 0 {
+		return perror.Wrap(herrors.ErrNameConflict, "a cluster with the same name was deleted "+
+			"and can be restored, the name can not be used until it is purged")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
+)
+
+func testRestoreCluster(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+
+	c := &controller{
+		clusterMgr:     manager.ClusterMgr,
+		applicationMgr: manager.ApplicationMgr,
+		clusterGitRepo: clusterGitRepo,
+		eventSvc:       eventservice.New(manager),
+		quotaSvc:       quotaservice.NewService(manager),
+	}
+
+	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
+		Name: "TestRestoreCluster",
+		Path: "TestRestoreCluster",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationMgr.Create(ctx, &appmodels.Application{
+		GroupID:         group.ID,
+		Name:            "TestRestoreCluster",
+		Priority:        "P3",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	}, nil)
+	assert.Nil(t, err)
+
+	newCluster := func(name string) *clustermodels.Cluster {
+		cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+			ApplicationID:   application.ID,
+			Name:            name,
+			EnvironmentName: "test",
+			RegionName:      "hz",
+		}, nil, nil)
+		assert.Nil(t, err)
+		return cluster
+	}
+	deleted := newCluster("TestRestoreCluster-deleted")
+	assert.Nil(t, manager.ClusterMgr.DeleteByID(ctx, deleted.ID))
+	hardDeleted := newCluster("TestRestoreCluster-hard-deleted")
+	assert.Nil(t, manager.ClusterMgr.HardDeleteByID(ctx, hardDeleted.ID))
+
+	clusters, err := c.ListDeleted(ctx, application.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(clusters))
+	assert.Equal(t, deleted.ID, clusters[0].ID)
+	assert.Equal(t, deleted.Name, clusters[0].Name)
+
+	// names of clusters which can be restored are kept
+	assert.True(t, perror.Cause(c.checkDeletedName(ctx, deleted.Name)) == herrors.ErrNameConflict)
+	assert.Nil(t, c.checkDeletedName(ctx, hardDeleted.Name))
+
+	// clusters hard deleted or under another application can not be restored
+	err = c.Restore(ctx, application.ID, hardDeleted.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	err = c.Restore(ctx, application.ID+1, deleted.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// a cluster with the same name is created after the cluster is deleted
+	live := newCluster(deleted.Name)
+	err = c.Restore(ctx, application.ID, deleted.ID)
+	assert.True(t, perror.Cause(err) == herrors.ErrNameConflict)
+	assert.Nil(t, manager.ClusterMgr.HardDeleteByID(ctx, live.ID))
+
+	clusterGitRepo.EXPECT().RestoreCluster(ctx, application.Name, deleted.Name, deleted.ID).Return(nil).Times(1)
+	err = c.Restore(ctx, application.ID, deleted.ID)
+	assert.Nil(t, err)
+
+	restored, err := manager.ClusterMgr.GetByID(ctx, deleted.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ClusterStatusFreed, restored.Status)
+	clusters, err = c.ListDeleted(ctx, application.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(clusters))
+}
@@ -0,0 +1,196 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	asynctaskmodels "github.com/horizoncd/horizon/pkg/asynctask/models"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
+	"github.com/horizoncd/horizon/pkg/cluster/releaseplan"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_defaultReleaseHealthTimeout = 10 * time.Minute
+	_maxReleaseHealthTimeout     = time.Hour
+)
+
+// releaseHealthCheckInterval is how often the health of a released stage is checked
+var releaseHealthCheckInterval = 10 * time.Second
+
+func (c *controller) ReleaseClusters(ctx context.Context, groupID uint,
+	r *ReleaseRequest) (*ReleaseAsyncResponse, error) {
+	const op = "cluster controller: release clusters"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	timeout := _defaultReleaseHealthTimeout
+	if r.HealthTimeoutSeconds != 0 {
+		timeout = time.Duration(r.HealthTimeoutSeconds) * time.Second
+		if timeout < 0 || timeout > _maxReleaseHealthTimeout {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"healthTimeoutSeconds must be between 1 and %d", int(_maxReleaseHealthTimeout.Seconds()))
+		}
+	}
+	stages, err := releaseplan.Stages(r.Clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReleaseResult{Stages: stages}
+	for i, stage := range stages {
+		for _, clusterID := range stage {
+			cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+			if err != nil {
+				return nil, err
+			}
+			if err := c.checkClusterInGroup(ctx, cluster.ApplicationID, groupID); err != nil {
+				return nil, perror.WithMessagef(err, "cluster %s", cluster.Name)
+			}
+			result.Clusters = append(result.Clusters, &ClusterReleaseResult{
+				ClusterID:   cluster.ID,
+				ClusterName: cluster.Name,
+				Stage:       i,
+				Status:      ReleaseStatusPending,
+			})
+		}
+	}
+
+	task, err := c.asyncTaskSvc.Submit(ctx, asynctaskmodels.TypeClusterRelease,
+		func(ctx context.Context, reporter asynctaskservice.Reporter) (interface{}, error) {
+			err := c.release(ctx, reporter, r, result, timeout)
+			return result, err
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &ReleaseAsyncResponse{TaskID: task.ID, Stages: stages}, nil
+}
+
+// checkClusterInGroup makes sure the application of the cluster is in the group or its subgroups,
+// as permissions of the release are checked against the group
+func (c *controller) checkClusterInGroup(ctx context.Context, applicationID, groupID uint) error {
+	application, err := c.applicationMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return err
+	}
+	group, err := c.groupManager.GetByID(ctx, application.GroupID)
+	if err != nil {
+		return err
+	}
+	for _, id := range groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs) {
+		if id == groupID {
+			return nil
+		}
+	}
+	return perror.Wrapf(herrors.ErrForbidden, "application %s does not belong to group %d", application.Name, groupID)
+}
+
+// release deploys the clusters stage by stage, a stage starts only after all the clusters of
+// the previous stage are healthy, and the release halts once a cluster fails to deploy or to get healthy
+func (c *controller) release(ctx context.Context, reporter asynctaskservice.Reporter,
+	r *ReleaseRequest, result *ReleaseResult, timeout time.Duration) error {
+	total := len(result.Stages)
+	for i := range result.Stages {
+		reporter.Progress(i, total, fmt.Sprintf("releasing stage %d of %d", i+1, total))
+
+		var stage []*ClusterReleaseResult
+		for _, cluster := range result.Clusters {
+			if cluster.Stage == i {
+				stage = append(stage, cluster)
+			}
+		}
+		if err := c.releaseStage(ctx, reporter, r, result, stage, timeout); err != nil {
+			for _, cluster := range result.Clusters {
+				if cluster.Status == ReleaseStatusPending {
+					cluster.Status = ReleaseStatusSkipped
+				}
+			}
+			reporter.Progress(i, total, fmt.Sprintf("release halted at stage %d of %d", i+1, total))
+			return perror.WithMessagef(err, "release halted at stage %d", i+1)
+		}
+	}
+	reporter.Progress(total, total, "released")
+	return nil
+}
+
+func (c *controller) releaseStage(ctx context.Context, reporter asynctaskservice.Reporter,
+	r *ReleaseRequest, result *ReleaseResult, stage []*ClusterReleaseResult, timeout time.Duration) error {
+	for _, cluster := range stage {
+		cluster.Status = ReleaseStatusDeploying
+		resp, err := c.Deploy(ctx, cluster.ClusterID, &DeployRequest{
+			Title:       r.Title,
+			Description: r.Description,
+		})
+		if err != nil {
+			cluster.Status = ReleaseStatusFailed
+			cluster.Message = err.Error()
+			return perror.WithMessagef(err, "failed to deploy cluster %s", cluster.ClusterName)
+		}
+		cluster.PipelinerunID = resp.PipelinerunID
+	}
+	reporter.Result(result)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		unhealthy := 0
+		for _, cluster := range stage {
+			if cluster.Status != ReleaseStatusDeploying {
+				continue
+			}
+			status, err := c.GetClusterStatusV2(ctx, cluster.ClusterID)
+			if err != nil {
+				log.Warningf(ctx, "failed to get status of cluster %s: %v", cluster.ClusterName, err)
+				cluster.Message = err.Error()
+				unhealthy++
+				continue
+			}
+			if status.Status == string(health.HealthStatusHealthy) {
+				cluster.Status = ReleaseStatusHealthy
+				cluster.Message = ""
+				continue
+			}
+			cluster.Message = fmt.Sprintf("cluster is %s", status.Status)
+			unhealthy++
+		}
+		reporter.Result(result)
+		if unhealthy == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			for _, cluster := range stage {
+				if cluster.Status == ReleaseStatusDeploying {
+					cluster.Status = ReleaseStatusFailed
+					cluster.Message = fmt.Sprintf("not healthy in %v, %s", timeout, cluster.Message)
+				}
+			}
+			return perror.Errorf("%d cluster(s) are not healthy in %v", unhealthy, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(releaseHealthCheckInterval):
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cd"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	rolloutworkload "github.com/horizoncd/horizon/pkg/workload/rollout"
+)
+
+const (
+	RolloutActionPause       = "pause"
+	RolloutActionResume      = "resume"
+	RolloutActionPromote     = "promote"
+	RolloutActionPromoteFull = "promote-full"
+	RolloutActionAbort       = "abort"
+	RolloutActionRetry       = "retry"
+)
+
+var rolloutActions = map[string]bool{
+	RolloutActionPause:       true,
+	RolloutActionResume:      true,
+	RolloutActionPromote:     true,
+	RolloutActionPromoteFull: true,
+	RolloutActionAbort:       true,
+	RolloutActionRetry:       true,
+}
+
+func (c *controller) ExecuteRolloutAction(ctx context.Context, clusterID uint, action string) error {
+	const op = "cluster controller: execute rollout action"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if !rolloutActions[action] {
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported rollout action: %s", action)
+	}
+	return c.ExecuteAction(ctx, clusterID, action, rolloutworkload.GVRRollout)
+}
+
+// stepStatuses are the statuses of clusters whose deploy may be in progress,
+// paused rollouts are suspended and aborted ones are degraded
+var stepStatuses = map[string]bool{
+	string(health.HealthStatusProgressing): true,
+	string(health.HealthStatusSuspended):   true,
+	string(health.HealthStatusDegraded):    true,
+}
+
+// stepInProgress returns the step of the deploy in progress, nil if the cluster is settled.
+// It's best effort, since the status is still meaningful without the step.
+func (c *controller) stepInProgress(ctx context.Context, cluster *clustermodels.Cluster,
+	regionEntity *regionmodels.RegionEntity, status string) *GetStepResponse {
+	if !stepStatuses[status] {
+		return nil
+	}
+	step, err := c.cd.GetStep(ctx, &cd.GetStepParams{
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		RegionEntity: regionEntity,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to get step of cluster %s: %v", cluster.Name, err)
+		return nil
+	}
+	return ofStep(step)
+}
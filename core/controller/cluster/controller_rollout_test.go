@@ -0,0 +1,32 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestExecuteRolloutAction(t *testing.T) {
+	c := &controller{}
+	err := c.ExecuteRolloutAction(ctx, 1, "auto-promote")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	assert.Nil(t, c.stepInProgress(ctx, nil, nil, "Healthy"))
+}
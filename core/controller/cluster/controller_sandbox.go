@@ -0,0 +1,148 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) CreateSandbox(ctx context.Context, r *CreateSandboxRequest) (*Sandbox, error) {
+	const op = "cluster controller: create sandbox"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if !c.sandboxConfig.Enabled() {
+		return nil, perror.Wrap(herrors.ErrDisabled, "sandbox is not enabled")
+	}
+	if r.TemplateInfo == nil || r.TemplateInfo.Name == "" || r.TemplateInfo.Release == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "template and its release are required")
+	}
+	if !c.sandboxConfig.TemplateAllowed(r.TemplateInfo.Name) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"sandbox can not be created from template %s", r.TemplateInfo.Name)
+	}
+
+	sandboxes, err := c.listSandboxes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(sandboxes) >= c.sandboxConfig.MaxPerUser {
+		return nil, perror.Wrapf(herrors.ErrForbidden,
+			"at most %d sandboxes can be held by a user, please delete the unused ones first",
+			c.sandboxConfig.MaxPerUser)
+	}
+
+	name := r.Name
+	if name == "" {
+		name = fmt.Sprintf("sandbox-%s", rand.String(8))
+	}
+	resp, err := c.CreateClusterV2(ctx, &CreateClusterParamsV2{
+		CreateClusterRequestV2: &CreateClusterRequestV2{
+			Name:           name,
+			Description:    r.Description,
+			ExpireTime:     c.sandboxConfig.TTL.String(),
+			Git:            r.Git,
+			Image:          r.Image,
+			TemplateInfo:   r.TemplateInfo,
+			TemplateConfig: r.TemplateConfig,
+		},
+		ApplicationID: c.sandboxConfig.ApplicationID,
+		Environment:   c.sandboxConfig.Environment,
+		Region:        c.sandboxConfig.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, resp.ID)
+	if err != nil {
+		return nil, err
+	}
+	return ofSandbox(cluster, resp.FullPath), nil
+}
+
+func (c *controller) ListSandboxes(ctx context.Context) ([]*Sandbox, error) {
+	const op = "cluster controller: list sandboxes"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if !c.sandboxConfig.Enabled() {
+		return []*Sandbox{}, nil
+	}
+	return c.listSandboxes(ctx)
+}
+
+// listSandboxes lists the sandbox clusters which the current user is a member of
+func (c *controller) listSandboxes(ctx context.Context) ([]*Sandbox, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, c.sandboxConfig.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, clusters, err := c.clusterMgr.List(ctx, &q.Query{
+		WithoutPagination: true,
+		Keywords: q.KeyWords{
+			common.ParamApplicationID: application.ID,
+			common.ClusterQueryByUser: currentUser.GetID(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sandboxes := make([]*Sandbox, 0, len(clusters))
+	for _, cluster := range clusters {
+		fullPath := fmt.Sprintf("%v/%v/%v", group.FullPath, application.Name, cluster.Name)
+		sandboxes = append(sandboxes, ofSandbox(cluster.Cluster, fullPath))
+	}
+	return sandboxes, nil
+}
+
+// isSandbox tells whether the application is the one which sandbox clusters are created in
+func (c *controller) isSandbox(applicationID uint) bool {
+	return c.sandboxConfig.Enabled() && applicationID == c.sandboxConfig.ApplicationID
+}
+
+// constrainSandboxConfig overrides spec.replicas and spec.resource under the template's root key
+// of the template config, such as app.spec.replicas, so that sandboxes only take limited resources
+func constrainSandboxConfig(templateConfig map[string]interface{}, replicas int, resource string) {
+	for _, value := range templateConfig {
+		root, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		spec, ok := root["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		spec["replicas"] = replicas
+		if resource != "" {
+			spec["resource"] = resource
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sandboxconfig "github.com/horizoncd/horizon/pkg/config/sandbox"
+)
+
+func TestConstrainSandboxConfig(t *testing.T) {
+	templateConfig := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": float64(5),
+				"resource": "large",
+			},
+			"params": map[string]interface{}{
+				"mainClassName": "com.example.Main",
+			},
+		},
+		"version": "v1",
+	}
+	constrainSandboxConfig(templateConfig, 1, "tiny")
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 1,
+				"resource": "tiny",
+			},
+			"params": map[string]interface{}{
+				"mainClassName": "com.example.Main",
+			},
+		},
+		"version": "v1",
+	}, templateConfig)
+
+	// resource is kept if it's not configured
+	constrainSandboxConfig(templateConfig, 2, "")
+	spec := templateConfig["app"].(map[string]interface{})["spec"]
+	assert.Equal(t, map[string]interface{}{"replicas": 2, "resource": "tiny"}, spec)
+
+	constrainSandboxConfig(nil, 1, "tiny")
+}
+
+func TestIsSandbox(t *testing.T) {
+	c := &controller{}
+	assert.False(t, c.isSandbox(0))
+
+	c.sandboxConfig = sandboxconfig.Config{ApplicationID: 3, Templates: []string{"javaapp"}}
+	assert.True(t, c.isSandbox(3))
+	assert.False(t, c.isSandbox(4))
+	assert.True(t, c.sandboxConfig.TemplateAllowed("javaapp"))
+	assert.False(t, c.sandboxConfig.TemplateAllowed("tomcat"))
+}
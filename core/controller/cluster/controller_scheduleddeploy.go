@@ -0,0 +1,126 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	scheduleddeploymodels "github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) CreateScheduledDeploy(ctx context.Context, clusterID uint,
+	r *CreateScheduledDeployRequest) (*ScheduledDeploy, error) {
+	const op = "cluster controller: create scheduled deploy"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.Action != prmodels.ActionBuildDeploy && r.Action != prmodels.ActionRestart {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "action must be %s or %s",
+			prmodels.ActionBuildDeploy, prmodels.ActionRestart)
+	}
+	if !r.ScheduledAt.After(time.Now()) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "scheduledAt must be in the future")
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	deploy := &scheduleddeploymodels.ScheduledDeploy{
+		ClusterID:   clusterID,
+		Action:      r.Action,
+		Status:      scheduleddeploymodels.StatusScheduled,
+		ScheduledAt: r.ScheduledAt,
+		CreatedBy:   currentUser.GetID(),
+	}
+	if r.Action == prmodels.ActionBuildDeploy {
+		if cluster.GitURL == "" {
+			return nil, herrors.ErrBuildDeployNotSupported
+		}
+		if err := c.checkDirectDeploy(cluster); err != nil {
+			return nil, err
+		}
+		deploy.Title = r.Title
+		deploy.Description = r.Description
+		if r.Git != nil {
+			switch {
+			case r.Git.Commit != "":
+				deploy.GitRefType, deploy.GitRef = codemodels.GitRefTypeCommit, r.Git.Commit
+			case r.Git.Tag != "":
+				deploy.GitRefType, deploy.GitRef = codemodels.GitRefTypeTag, r.Git.Tag
+			case r.Git.Branch != "":
+				deploy.GitRefType, deploy.GitRef = codemodels.GitRefTypeBranch, r.Git.Branch
+			}
+		}
+	}
+	// refuse deploys which are bound to be frozen, the freeze is checked again when triggered
+	if err := c.deployWindowSvc.CheckFreeze(ctx, cluster, r.ScheduledAt); err != nil {
+		return nil, err
+	}
+
+	deploy, err = c.scheduledDeployMgr.Create(ctx, deploy)
+	if err != nil {
+		return nil, err
+	}
+	return ofScheduledDeploy(deploy), nil
+}
+
+func (c *controller) ListScheduledDeploys(ctx context.Context, clusterID uint,
+	status string) ([]*ScheduledDeploy, error) {
+	const op = "cluster controller: list scheduled deploys"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	deploys, err := c.scheduledDeployMgr.ListByCluster(ctx, clusterID, status)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*ScheduledDeploy, 0, len(deploys))
+	for _, deploy := range deploys {
+		resp = append(resp, ofScheduledDeploy(deploy))
+	}
+	return resp, nil
+}
+
+func (c *controller) CancelScheduledDeploy(ctx context.Context, clusterID, scheduledDeployID uint) error {
+	const op = "cluster controller: cancel scheduled deploy"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	deploy, err := c.scheduledDeployMgr.GetByID(ctx, scheduledDeployID)
+	if err != nil {
+		return err
+	}
+	if deploy.ClusterID != clusterID {
+		return herrors.NewErrNotFound(herrors.ScheduledDeployInDB,
+			fmt.Sprintf("scheduled deploy %d not found in cluster %d", scheduledDeployID, clusterID))
+	}
+	if deploy.Status != scheduleddeploymodels.StatusScheduled {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"scheduled deploy %d is %s, only deploys waiting for their time can be cancelled",
+			scheduledDeployID, deploy.Status)
+	}
+	return c.scheduledDeployMgr.UpdateStatus(ctx, scheduledDeployID, scheduleddeploymodels.StatusScheduled,
+		scheduleddeploymodels.StatusCancelled, 0, "")
+}
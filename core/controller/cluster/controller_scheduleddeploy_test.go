@@ -0,0 +1,115 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	deploywindowconfig "github.com/horizoncd/horizon/pkg/config/deploywindow"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	scheduleddeploymodels "github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+)
+
+func testScheduledDeploy(t *testing.T) {
+	c := &controller{
+		clusterMgr:         manager.ClusterMgr,
+		scheduledDeployMgr: manager.ScheduledDeployMgr,
+		deployWindowSvc: deploywindow.NewService(manager, deploywindowconfig.Config{
+			FreezeWindows: []*deploywindowconfig.FreezeWindow{{
+				Name:         "weekend",
+				Environments: []string{"online"},
+				Start:        "Sat 00:00",
+				End:          "Sun 00:00",
+			}},
+		}, nil),
+	}
+
+	newCluster := func(name, environment, gitURL string) *clustermodels.Cluster {
+		cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+			ApplicationID:   1,
+			Name:            name,
+			EnvironmentName: environment,
+			RegionName:      "hz",
+			GitURL:          gitURL,
+		}, nil, nil)
+		assert.Nil(t, err)
+		return cluster
+	}
+	test := newCluster("TestScheduledDeploy-test", "test", "ssh://git@cloudnative.com/demo.git")
+	online := newCluster("TestScheduledDeploy-online", "online", "")
+
+	// the next monday, which is not frozen
+	now := time.Now()
+	monday := time.Date(now.Year(), now.Month(), now.Day()+8-int(now.Weekday()), 10, 0, 0, 0, time.Local)
+	deploy, err := c.CreateScheduledDeploy(ctx, test.ID, &CreateScheduledDeployRequest{
+		Action:      prmodels.ActionBuildDeploy,
+		ScheduledAt: monday,
+		Title:       "nightly",
+		Git:         &BuildDeployRequestGit{Tag: "v1.0.0"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, scheduleddeploymodels.StatusScheduled, deploy.Status)
+	assert.Equal(t, "tag", deploy.GitRefType)
+	assert.Equal(t, "v1.0.0", deploy.GitRef)
+
+	for _, r := range []*CreateScheduledDeployRequest{
+		{Action: prmodels.ActionDeploy, ScheduledAt: monday},
+		{Action: prmodels.ActionRestart, ScheduledAt: now.Add(-time.Minute)},
+	} {
+		_, err = c.CreateScheduledDeploy(ctx, test.ID, r)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+	// clusters without git repo can not be built
+	_, err = c.CreateScheduledDeploy(ctx, online.ID, &CreateScheduledDeployRequest{
+		Action:      prmodels.ActionBuildDeploy,
+		ScheduledAt: monday,
+	})
+	assert.Equal(t, herrors.ErrBuildDeployNotSupported, perror.Cause(err))
+	// deploys scheduled in freeze windows are refused
+	_, err = c.CreateScheduledDeploy(ctx, online.ID, &CreateScheduledDeployRequest{
+		Action:      prmodels.ActionRestart,
+		ScheduledAt: monday.AddDate(0, 0, 5),
+	})
+	assert.Equal(t, herrors.ErrDeployFrozen, perror.Cause(err))
+	restart, err := c.CreateScheduledDeploy(ctx, online.ID, &CreateScheduledDeployRequest{
+		Action:      prmodels.ActionRestart,
+		ScheduledAt: monday,
+	})
+	assert.Nil(t, err)
+
+	deploys, err := c.ListScheduledDeploys(ctx, test.ID, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(deploys))
+	assert.Equal(t, deploy.ID, deploys[0].ID)
+
+	// deploys can only be cancelled in their clusters and only once
+	err = c.CancelScheduledDeploy(ctx, test.ID, restart.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	assert.Nil(t, c.CancelScheduledDeploy(ctx, online.ID, restart.ID))
+	err = c.CancelScheduledDeploy(ctx, online.ID, restart.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	deploys, err = c.ListScheduledDeploys(ctx, online.ID, scheduleddeploymodels.StatusCancelled)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(deploys))
+}
@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	snapshotmodels "github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListSnapshots(ctx context.Context, clusterID uint,
+	query *q.Query) (int, []*Snapshot, error) {
+	const op = "cluster controller: list snapshots"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return 0, nil, err
+	}
+	total, snapshots, err := c.snapshotMgr.ListByClusterID(ctx, clusterID, query)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp := make([]*Snapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		resp = append(resp, ofSnapshot(snapshot))
+	}
+	return total, resp, nil
+}
+
+func (c *controller) RestoreSnapshot(ctx context.Context, clusterID, snapshotID uint) error {
+	const op = "cluster controller: restore snapshot"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get snapshot, cluster and application
+	snapshot, err := c.snapshotMgr.GetByID(ctx, snapshotID)
+	if err != nil {
+		return err
+	}
+	if snapshot.ClusterID != clusterID {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"snapshot %d does not belong to cluster %d", snapshotID, clusterID)
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+
+	// 2. take a snapshot of the current state, so that the restore can be undone
+	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := c.snapshotSvc.Take(ctx, cluster, configCommit.Gitops,
+		snapshotmodels.TriggerRestore, 0); err != nil {
+		return err
+	}
+
+	// 3. revert gitops repo to the snapshot if config changed since then
+	diff, err := c.clusterGitRepo.CompareConfig(ctx, application.Name, cluster.Name,
+		&snapshot.ConfigCommit, &configCommit.Gitops)
+	if err != nil {
+		return err
+	}
+	if diff != "" {
+		if _, err := c.clusterGitRepo.Rollback(ctx, application.Name, cluster.Name,
+			snapshot.ConfigCommit); err != nil {
+			return err
+		}
+	}
+
+	// 4. restore metadata in db
+	cluster.Template = snapshot.Template
+	cluster.TemplateRelease = snapshot.TemplateRelease
+	cluster.GitURL = snapshot.GitURL
+	cluster.GitSubfolder = snapshot.GitSubfolder
+	cluster.GitRefType = snapshot.GitRefType
+	cluster.GitRef = snapshot.GitRef
+	cluster.Image = snapshot.Image
+	if _, err := c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster); err != nil {
+		return err
+	}
+	if err := c.tagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, cluster.ID,
+		snapshotTags(snapshot)); err != nil {
+		return err
+	}
+
+	// 5. record event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
+		eventmodels.ClusterUpdated, nil)
+	return nil
+}
@@ -507,6 +507,32 @@ func (c *controller) GetResourceTree(ctx context.Context, clusterID uint) (resp
 	return
 }
 
+func (c *controller) GetDrift(ctx context.Context, clusterID uint) (_ *cd.ClusterDrift, err error) {
+	const op = "cluster controller: get drift"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the gitops branch is what is going to be deployed
+	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	return c.cd.GetClusterDrift(ctx, &cd.GetClusterDriftParams{
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+		Cluster:     cluster.Name,
+		Revision:    configCommit.Gitops,
+	})
+}
+
 func (c *controller) GetStep(ctx context.Context, clusterID uint) (resp *GetStepResponse, err error) {
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
@@ -527,25 +553,31 @@ func (c *controller) GetStep(ctx context.Context, clusterID uint) (resp *GetStep
 		return
 	}
 
-	if steps != nil {
-		resp = &GetStepResponse{
-			Total:        steps.Total,
-			Index:        steps.Index,
-			Replicas:     steps.Replicas,
-			ManualPaused: steps.ManualPaused,
-			AutoPromote:  steps.AutoPromote,
-			Extra:        steps.Extra,
-		}
-	} else {
-		resp = &GetStepResponse{
+	return ofStep(steps), nil
+}
+
+func ofStep(steps *cd.Step) *GetStepResponse {
+	if steps == nil {
+		return &GetStepResponse{
 			Total:        0,
 			Index:        0,
 			Replicas:     []int{},
 			ManualPaused: false,
-			AutoPromote:  steps.AutoPromote,
+			AutoPromote:  false,
 		}
 	}
-	return
+	return &GetStepResponse{
+		Total:        steps.Total,
+		Index:        steps.Index,
+		Replicas:     steps.Replicas,
+		ManualPaused: steps.ManualPaused,
+		AutoPromote:  steps.AutoPromote,
+		Strategy:     steps.Strategy,
+		Phase:        steps.Phase,
+		Aborted:      steps.Aborted,
+		Message:      steps.Message,
+		Extra:        steps.Extra,
+	}
 }
 
 func willExpireIn(ttl uint, tms ...time.Time) *uint {
@@ -17,6 +17,7 @@ package cluster
 import (
 	"testing"
 
+	"github.com/argoproj/gitops-engine/pkg/health"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
@@ -25,15 +26,18 @@ import (
 	"github.com/horizoncd/horizon/lib/orm"
 	applicationmanangermock "github.com/horizoncd/horizon/mock/pkg/application/manager"
 	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
 	clustermanagermock "github.com/horizoncd/horizon/mock/pkg/cluster/manager"
 	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 )
 
 func testGetClusterStatusV2(t *testing.T) {
@@ -41,8 +45,10 @@ func testGetClusterStatusV2(t *testing.T) {
 	clusterManagerMock := clustermanagermock.NewMockManager(mockCtl)
 	appManagerMock := applicationmanangermock.NewMockManager(mockCtl)
 	mockCD := cdmock.NewMockCD(mockCtl)
+	k8sutil := cdmock.NewMockK8sUtil(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
 	db, _ := orm.NewSqliteDB("")
-	_ = db.AutoMigrate(&regionmodels.Region{}, &registrymodels.Registry{})
+	_ = db.AutoMigrate(&regionmodels.Region{}, &registrymodels.Registry{}, &trmodels.TemplateRelease{})
 	manager := managerparam.InitManager(db)
 
 	regionName := "test"
@@ -53,9 +59,20 @@ func testGetClusterStatusV2(t *testing.T) {
 		applicationMgr: appManagerMock,
 		regionMgr:      manager.RegionMgr,
 		cd:             mockCD,
+
+		templateReleaseMgr: manager.TemplateReleaseMgr,
+		clusterGitRepo:     clusterGitRepo,
+		k8sutil:            k8sutil,
 	}
 
-	_, err := manager.RegistryMgr.Create(ctx, &registrymodels.Registry{
+	_, err := manager.TemplateReleaseMgr.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: "javaapp",
+		Name:         "v1.0.0",
+		ChartName:    "javaapp",
+	})
+	assert.Nil(t, err)
+
+	_, err = manager.RegistryMgr.Create(ctx, &registrymodels.Registry{
 		Model: global.Model{ID: 1},
 	})
 	assert.Nil(t, err)
@@ -69,13 +86,19 @@ func testGetClusterStatusV2(t *testing.T) {
 	assert.Nil(t, err)
 
 	clusterManagerMock.EXPECT().GetByID(gomock.Any(), gomock.Any()).Times(1).
-		Return(&clustermodels.Cluster{Status: common.ClusterStatusEmpty, RegionName: regionName}, nil)
+		Return(&clustermodels.Cluster{Status: common.ClusterStatusEmpty, RegionName: regionName,
+			Template: "javaapp", TemplateRelease: "v1.0.0"}, nil)
+	clusterManagerMock.EXPECT().GetByID(gomock.Any(), gomock.Any()).Times(1).
+		Return(&clustermodels.Cluster{Status: common.ClusterStatusCreating, RegionName: regionName,
+			Template: "javaapp", TemplateRelease: "v1.0.0"}, nil)
 	clusterManagerMock.EXPECT().GetByID(gomock.Any(), gomock.Any()).Times(1).
-		Return(&clustermodels.Cluster{Status: common.ClusterStatusCreating, RegionName: regionName}, nil)
+		Return(&clustermodels.Cluster{Status: common.ClusterStatusEmpty, RegionName: regionName,
+			Template: "javaapp", TemplateRelease: "v1.0.0"}, nil)
 	clusterManagerMock.EXPECT().GetByID(gomock.Any(), gomock.Any()).Times(1).
-		Return(&clustermodels.Cluster{Status: common.ClusterStatusEmpty, RegionName: regionName}, nil)
+		Return(&clustermodels.Cluster{Status: common.ClusterStatusEmpty, RegionName: regionName,
+			Template: "javaapp", TemplateRelease: "v1.0.0"}, nil)
 
-	appManagerMock.EXPECT().GetByID(gomock.Any(), gomock.Any()).Times(3).
+	appManagerMock.EXPECT().GetByID(gomock.Any(), gomock.Any()).Times(4).
 		Return(&applicationmodel.Application{}, nil)
 
 	mockCD.EXPECT().GetClusterState(gomock.Any(), gomock.Any()).Times(1).
@@ -84,10 +107,23 @@ func testGetClusterStatusV2(t *testing.T) {
 		Return(&cd.ClusterStateV2{Status: status}, nil)
 	mockCD.EXPECT().GetClusterState(gomock.Any(), gomock.Any()).Times(1).
 		Return(nil, perror.Wrap(herrors.NewErrNotFound(herrors.ApplicationInArgo, ""), ""))
+	mockCD.EXPECT().GetClusterState(gomock.Any(), gomock.Any()).Times(1).
+		Return(&cd.ClusterStateV2{Status: string(health.HealthStatusSuspended)}, nil)
+	// only the paused rollout asks for the step
+	mockCD.EXPECT().GetStep(gomock.Any(), gomock.Any()).Times(1).
+		Return(&cd.Step{Index: 1, Total: 3, Replicas: []int{1, 1, 1}, Strategy: "canary", Phase: "Paused"}, nil)
+	mockCD.EXPECT().ListQueuedOperations(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	// the autoscaler is looked up when the cluster is deployed, and it's autoscaled at last
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), gomock.Any(), gomock.Any(), "javaapp").
+		Return(&gitrepo.EnvValue{Namespace: "test-1"}, nil).Times(3)
+	k8sutil.EXPECT().GetAutoscalingStatus(gomock.Any(), gomock.Any()).Times(2).Return(nil, nil)
+	k8sutil.EXPECT().GetAutoscalingStatus(gomock.Any(), gomock.Any()).Times(1).
+		Return(&cd.AutoscalingStatus{MinReplicas: 1, MaxReplicas: 3, CurrentReplicas: 2, DesiredReplicas: 2}, nil)
 
 	resp, err := c.GetClusterStatusV2(ctx, 1)
 	assert.Nil(t, err)
 	assert.Equal(t, status, resp.Status)
+	assert.Nil(t, resp.Autoscaling)
 
 	resp, err = c.GetClusterStatusV2(ctx, 1)
 	assert.Nil(t, err)
@@ -96,4 +132,47 @@ func testGetClusterStatusV2(t *testing.T) {
 	resp, err = c.GetClusterStatusV2(ctx, 1)
 	assert.Nil(t, err)
 	assert.Equal(t, _notFound, resp.Status)
+	assert.Nil(t, resp.Step)
+
+	resp, err = c.GetClusterStatusV2(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, string(health.HealthStatusSuspended), resp.Status)
+	assert.Equal(t, 1, resp.Step.Index)
+	assert.Equal(t, "canary", resp.Step.Strategy)
+	assert.Equal(t, "Paused", resp.Step.Phase)
+	assert.Equal(t, int32(3), resp.Autoscaling.MaxReplicas)
+}
+
+func TestGetDrift(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterManagerMock := clustermanagermock.NewMockManager(mockCtl)
+	appManagerMock := applicationmanangermock.NewMockManager(mockCtl)
+	clusterGitRepoMock := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	mockCD := cdmock.NewMockCD(mockCtl)
+
+	c := controller{
+		clusterMgr:     clusterManagerMock,
+		applicationMgr: appManagerMock,
+		clusterGitRepo: clusterGitRepoMock,
+		cd:             mockCD,
+	}
+
+	clusterManagerMock.EXPECT().GetByID(gomock.Any(), uint(1)).Return(&clustermodels.Cluster{
+		Name: "cluster", ApplicationID: 2, EnvironmentName: "dev", RegionName: "hz",
+	}, nil)
+	appManagerMock.EXPECT().GetByID(gomock.Any(), uint(2)).
+		Return(&applicationmodel.Application{Name: "app"}, nil)
+	clusterGitRepoMock.EXPECT().GetConfigCommit(gomock.Any(), "app", "cluster").
+		Return(&gitrepo.ClusterCommit{Master: "master", Gitops: "gitops"}, nil)
+	drift := &cd.ClusterDrift{Revision: "gitops", LiveRevision: "master", Drifted: true}
+	mockCD.EXPECT().GetClusterDrift(gomock.Any(), &cd.GetClusterDriftParams{
+		Environment: "dev",
+		Region:      "hz",
+		Cluster:     "cluster",
+		Revision:    "gitops",
+	}).Return(drift, nil)
+
+	resp, err := c.GetDrift(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, drift, resp)
 }
@@ -0,0 +1,161 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/util/jsonschema"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) GetTemplateUpgradePlan(ctx context.Context, clusterID uint,
+	templateRelease string) (*TemplateUpgradePlan, error) {
+	const op = "cluster controller: get template upgrade plan"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return c.getTemplateUpgradePlan(ctx, cluster, templateRelease)
+}
+
+func (c *controller) UpgradeTemplate(ctx context.Context, clusterID uint,
+	r *TemplateUpgradeRequest) (*prmodels.PipelineBasic, error) {
+	const op = "cluster controller: upgrade template"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := c.getTemplateUpgradePlan(ctx, cluster, r.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	templateConfig := plan.TemplateConfig
+	if len(r.TemplateConfig) > 0 {
+		templateConfig = r.TemplateConfig
+	} else if plan.ValidationError != "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"migrated config is invalid, please provide templateConfig: %s", plan.ValidationError)
+	}
+
+	// 1. write the migrated config and the new release to git repo and db,
+	// config is validated against the target release here
+	if err := c.UpdateClusterV2(ctx, clusterID, &UpdateClusterRequestV2{
+		Description:    cluster.Description,
+		TemplateInfo:   plan.To,
+		TemplateConfig: templateConfig,
+	}, false); err != nil {
+		return nil, err
+	}
+
+	// 2. create a pipelinerun to deploy the upgraded cluster
+	title := r.Title
+	if title == "" {
+		title = fmt.Sprintf("upgrade template from %s to %s", plan.From.Release, plan.To.Release)
+	}
+	return c.CreatePipelineRun(ctx, clusterID, &CreatePipelineRunRequest{
+		Title:       title,
+		Description: r.Description,
+		Action:      prmodels.ActionDeploy,
+	})
+}
+
+func (c *controller) getTemplateUpgradePlan(ctx context.Context, cluster *cmodels.Cluster,
+	templateRelease string) (*TemplateUpgradePlan, error) {
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. use the recommended release if target release is not specified
+	if templateRelease == "" {
+		releases, err := c.templateReleaseMgr.ListByTemplateName(ctx, cluster.Template)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if release.Recommended != nil && *release.Recommended {
+				templateRelease = release.Name
+				break
+			}
+		}
+		if templateRelease == "" {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"template %s has no recommended release", cluster.Template)
+		}
+	}
+	if templateRelease == cluster.TemplateRelease {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cluster is already on release %s", templateRelease)
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, templateRelease)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTemplateRelease(tr, cluster.Name); err != nil {
+		return nil, err
+	}
+
+	// 2. compare schemas of the current and the target release
+	renderValues, err := c.getRenderValueFromTag(ctx, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	renderValues[templateschema.ResourceTypeKey] = "cluster"
+	fromSchema, err := c.templateSchemaGetter.GetTemplateSchema(ctx, cluster.Template,
+		cluster.TemplateRelease, renderValues)
+	if err != nil {
+		return nil, err
+	}
+	toSchema, err := c.templateSchemaGetter.GetTemplateSchema(ctx, cluster.Template,
+		templateRelease, renderValues)
+	if err != nil {
+		return nil, err
+	}
+	migration := templateschema.DiffSchema(fromSchema.Application.JSONSchema, toSchema.Application.JSONSchema)
+
+	// 3. propose migrated config
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	plan := &TemplateUpgradePlan{
+		From: &codemodels.TemplateInfo{
+			Name:    cluster.Template,
+			Release: cluster.TemplateRelease,
+		},
+		To: &codemodels.TemplateInfo{
+			Name:    cluster.Template,
+			Release: templateRelease,
+		},
+		Migration:      migration,
+		TemplateConfig: migration.Apply(files.ApplicationJSONBlob),
+	}
+	if err := jsonschema.Validate(toSchema.Application.JSONSchema, plan.TemplateConfig, false); err != nil {
+		plan.ValidationError = err.Error()
+	}
+	return plan, nil
+}
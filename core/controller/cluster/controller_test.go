@@ -48,17 +48,30 @@ import (
 	registryftymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry/factory"
 	tektonmock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton"
 	tektonftymock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/factory"
+	pipelinestepmock "github.com/horizoncd/horizon/mock/pkg/pipelinestep"
 	outputmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/output"
 	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
 	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/envvar"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/rollout"
+	envchangemodels "github.com/horizoncd/horizon/pkg/clusterenv/models"
+	snapshotmodels "github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	snapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	csmodels "github.com/horizoncd/horizon/pkg/clustersummary/models"
+	deploywindowconfig "github.com/horizoncd/horizon/pkg/config/deploywindow"
 	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
+	manifestpolicyconfig "github.com/horizoncd/horizon/pkg/config/manifestpolicy"
+	metadataconfig "github.com/horizoncd/horizon/pkg/config/metadata"
+	namingconfig "github.com/horizoncd/horizon/pkg/config/naming"
 	templateconfig "github.com/horizoncd/horizon/pkg/config/template"
 	tokenconfig "github.com/horizoncd/horizon/pkg/config/token"
+	deploylockmodels "github.com/horizoncd/horizon/pkg/deploylock/models"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
 	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
@@ -68,19 +81,28 @@ import (
 	"github.com/horizoncd/horizon/pkg/git/gitlab"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
+	"github.com/horizoncd/horizon/pkg/manifestpolicy"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	metadatamodels "github.com/horizoncd/horizon/pkg/metadata/models"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/naming"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrydao "github.com/horizoncd/horizon/pkg/registry/dao"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	scheduleddeploymodels "github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	tmodel "github.com/horizoncd/horizon/pkg/tag/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	gitlabschema "github.com/horizoncd/horizon/pkg/templaterelease/schema/gitlab"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
 	schematagmodel "github.com/horizoncd/horizon/pkg/templateschematag/models"
 	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
@@ -457,7 +479,10 @@ func TestMain(m *testing.M) {
 		&registrymodels.Registry{}, eventmodels.Event{}, &templatemodels.Template{},
 		&regionmodels.Region{}, &envregionmodels.EnvironmentRegion{}, &eventmodels.Event{},
 		&prmodels.Pipelinerun{}, &schematagmodel.ClusterTemplateSchemaTag{}, &tmodel.Tag{},
-		&envmodels.Environment{}, &tokenmodels.Token{}); err != nil {
+		&envmodels.Environment{}, &tokenmodels.Token{}, &csmodels.ClusterSummary{},
+		&deploylockmodels.DeployLock{}, &snapshotmodels.ClusterSnapshot{},
+		&envchangemodels.ClusterEnvChange{}, &metadatamodels.Metadata{}, &quotamodels.GroupQuota{},
+		&scheduleddeploymodels.ScheduledDeploy{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -509,6 +534,9 @@ func TestAll(t *testing.T) {
 	t.Run("TestListClusterWithExpiry", testListClusterWithExpiry)
 	t.Run("TestControllerFreeOrDeleteClusterFailed", testControllerFreeOrDeleteClusterFailed)
 	t.Run("TestGetClusterStatusV2", testGetClusterStatusV2)
+	t.Run("TestRestoreCluster", testRestoreCluster)
+	t.Run("TestCloneCluster", testCloneCluster)
+	t.Run("TestScheduledDeploy", testScheduledDeploy)
 }
 
 // nolint
@@ -519,7 +547,7 @@ func test(t *testing.T) {
 		AutoFreeSvc: service.New([]string{"test", "dev"}),
 		Manager:     managerparam.InitManager(nil),
 	}
-	NewController(&conf, &param)
+	NewController(&conf, &param, nil)
 
 	templateName := "javaapp"
 	mockCtl := gomock.NewController(t)
@@ -533,6 +561,8 @@ func test(t *testing.T) {
 	tagManager := manager.TagMgr
 
 	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
+	pipelineStepGetter := pipelinestepmock.NewMockGetter(mockCtl)
+	pipelineStepGetter.EXPECT().GetSteps(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 	expectparams := make(map[string]string)
 	expectparams[gitlabschema.ClusterIDKey] = "1"
 
@@ -631,8 +661,14 @@ func test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, env)
 
+	namingSvc, err := naming.NewService(manager, namingconfig.Config{})
+	assert.Nil(t, err)
+	manifestPolicySvc, err := manifestpolicy.NewService(manifestpolicyconfig.Config{})
+	assert.Nil(t, err)
+
 	c = &controller{
 		clusterMgr:           manager.ClusterMgr,
+		clusterSummaryMgr:    manager.ClusterSummaryMgr,
 		clusterGitRepo:       clusterGitRepo,
 		commitGetter:         commitGetter,
 		cd:                   cd,
@@ -641,6 +677,7 @@ func test(t *testing.T) {
 		templateMgr:          templateMgr,
 		templateReleaseMgr:   trMgr,
 		templateSchemaGetter: templateSchemaGetter,
+		templateValidator:    templatevalidation.NewService(templateSchemaGetter),
 		envMgr:               envMgr,
 		envRegionMgr:         envRegionMgr,
 		regionMgr:            regionMgr,
@@ -660,6 +697,14 @@ func test(t *testing.T) {
 			JwtSigningKey:         "horizon",
 			CallbackTokenExpireIn: time.Hour * 2,
 		}),
+		namingSvc:         namingSvc,
+		deployWindowSvc:   deploywindow.NewService(manager, deploywindowconfig.Config{}, nil),
+		manifestPolicySvc: manifestPolicySvc,
+		snapshotMgr:       manager.ClusterSnapshotMgr,
+		snapshotSvc:       snapshotservice.NewService(manager),
+		envChangeMgr:      manager.ClusterEnvChangeMgr,
+		quotaSvc:          quotaservice.NewService(manager),
+		pipelineStepSvc:   pipelinestepservice.NewService(pipelineStepGetter, manager),
 	}
 
 	commitGetter.EXPECT().GetHTTPLink(gomock.Any()).Return("https://cloudnative.com:22222/demo/springboot-demo", nil).AnyTimes()
@@ -912,7 +957,14 @@ func test(t *testing.T) {
 		}, nil).AnyTimes()
 	clusterGitRepo.EXPECT().CompareConfig(gomock.Any(), application.Name, resp.Name, gomock.Any(), gomock.Any()).
 		Return("config-diff", nil).AnyTimes()
-	clusterGitRepo.EXPECT().UpdatePipelineOutput(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("image-commit", nil).AnyTimes()
+	clusterGitRepo.EXPECT().UpdatePipelineOutput(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, _ string, output interface{}) (string, error) {
+			// build provenance is written into the pipeline output
+			annotations := output.(map[string]interface{})["annotations"].(map[string]string)
+			assert.Equal(t, strconv.Itoa(int(resp.ID)), annotations[common.ClusterIDKey])
+			assert.Equal(t, strconv.Itoa(int(buildDeployResp.PipelinerunID)), annotations[common.ClusterPipelinerunIDKey])
+			return "image-commit", nil
+		}).AnyTimes()
 	clusterGitRepo.EXPECT().MergeBranch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return("newest-commit", nil).AnyTimes()
 	clusterGitRepo.EXPECT().GetRepoInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
@@ -1112,6 +1164,20 @@ func test(t *testing.T) {
 	assert.Equal(t, 1, len(tags))
 	assert.Equal(t, "test_key", tags[0].Key)
 	assert.Equal(t, "test_value", tags[0].Value)
+
+	// snapshots are taken before deploys and rollbacks, and can be restored
+	total, snapshots, err := c.ListSnapshots(ctx, resp.ID, nil)
+	assert.Nil(t, err)
+	assert.True(t, total > 0)
+	assert.Equal(t, rollbackResp.PipelinerunID, snapshots[0].PipelinerunID)
+	assert.Equal(t, snapshotmodels.TriggerDeploy, snapshots[0].Trigger)
+	err = c.RestoreSnapshot(ctx, resp.ID+1, snapshots[0].ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = c.RestoreSnapshot(ctx, resp.ID, snapshots[len(snapshots)-1].ID)
+	assert.Nil(t, err)
+	_, snapshots, err = c.ListSnapshots(ctx, resp.ID, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, snapshotmodels.TriggerRestore, snapshots[0].Trigger)
 	c.tagMgr = tagManager
 
 	k8sutil.EXPECT().DeletePods(ctx, gomock.Any()).Return(
@@ -1285,7 +1351,7 @@ func testV2(t *testing.T) {
 		AutoFreeSvc: service.New([]string{"dev", "test2"}),
 		Manager:     managerparam.InitManager(nil),
 	}
-	NewController(&conf, &param)
+	NewController(&conf, &param, nil)
 	templateName := "rollout"
 	templateVersion := "v1.0.0"
 	mockCtl := gomock.NewController(t)
@@ -1402,13 +1468,22 @@ func testV2(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, tr)
 
+	namingSvc, err := naming.NewService(manager, namingconfig.Config{})
+	assert.Nil(t, err)
+	metadataSvc, err := metadataservice.NewService(manager, metadataconfig.Config{
+		Cluster: []metadataconfig.Field{{Key: "runbookURL", Type: metadataconfig.FieldTypeURL}},
+	})
+	assert.Nil(t, err)
+
 	c = &controller{
 		clusterMgr:           manager.ClusterMgr,
+		clusterSummaryMgr:    manager.ClusterSummaryMgr,
 		clusterGitRepo:       clusterGitRepo,
 		applicationMgr:       appMgr,
 		templateMgr:          templateMgr,
 		templateReleaseMgr:   trMgr,
 		templateSchemaGetter: templateSchemaGetter,
+		templateValidator:    templatevalidation.NewService(templateSchemaGetter),
 		envMgr:               envMgr,
 		envRegionMgr:         envRegionMgr,
 		regionMgr:            regionMgr,
@@ -1424,6 +1499,9 @@ func testV2(t *testing.T) {
 		cd:                   mockCd,
 		eventSvc:             eventservice.New(manager),
 		memberManager:        manager.MemberMgr,
+		namingSvc:            namingSvc,
+		metadataSvc:          metadataSvc,
+		quotaSvc:             quotaservice.NewService(manager),
 	}
 	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), applicationName, gomock.Any()).
 		Return(&appgitrepo.GetResponse{
@@ -1431,7 +1509,13 @@ func testV2(t *testing.T) {
 			BuildConf:    pipelineJSONBlob,
 			TemplateConf: applicationJSONBlob,
 		}, nil).Times(1)
-	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *gitrepo.CreateClusterParams) error {
+			// rollout config defaults when it's not specified
+			assert.Equal(t, rollout.Default(), params.Rollout)
+			return nil
+		},
+	).Times(1)
 
 	createClusterName := "app-cluster2"
 	createReq := &CreateClusterRequestV2{
@@ -1455,6 +1539,25 @@ func testV2(t *testing.T) {
 		TemplateConfig: applicationJSONBlob,
 		ExtraMembers:   nil,
 	}
+
+	// the namespace must obey the naming policy of namespaces as well as in v1
+	strictNamingSvc, err := naming.NewService(manager, namingconfig.Config{
+		Namespace: namingconfig.Policy{Prefix: "team-"},
+	})
+	assert.Nil(t, err)
+	strictController := *c
+	strictController.namingSvc = strictNamingSvc
+	_, err = strictController.CreateClusterV2(ctx, &CreateClusterParamsV2{
+		CreateClusterRequestV2: &CreateClusterRequestV2{
+			Name:      createClusterName,
+			Namespace: "test2-ns",
+		},
+		ApplicationID: application.ID,
+		Environment:   "test2",
+		Region:        "hz",
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
 	resp, err := c.CreateClusterV2(ctx, &CreateClusterParamsV2{
 		CreateClusterRequestV2: createReq,
 		ApplicationID:          application.ID,
@@ -1475,6 +1578,10 @@ func testV2(t *testing.T) {
 		Manifest:            nil,
 	}, nil).Times(1)
 
+	err = metadataSvc.Update(ctx, common.ResourceCluster, resp.ID,
+		map[string]string{"runbookURL": "https://wiki.example.com/runbook"})
+	assert.Nil(t, err)
+
 	getClusterResp, err := c.GetClusterV2(ctx, resp.ID)
 	assert.Nil(t, err)
 	assert.Equal(t, getClusterResp.ID, resp.ID)
@@ -1493,6 +1600,7 @@ func testV2(t *testing.T) {
 	assert.Equal(t, getClusterResp.Status, "")
 	assert.Equal(t, 1, len(getClusterResp.Tags))
 	assert.Equal(t, getClusterResp.Tags[0].Value, "value1")
+	assert.Equal(t, map[string]string{"runbookURL": "https://wiki.example.com/runbook"}, getClusterResp.Metadata)
 	t.Logf("%+v", getClusterResp)
 
 	// update v2
@@ -1563,7 +1671,7 @@ func testUpgrade(t *testing.T) {
 		AutoFreeSvc: service.New([]string{"dev", "test"}),
 		Manager:     managerparam.InitManager(nil),
 	}
-	NewController(&conf, &parameter)
+	NewController(&conf, &parameter, nil)
 	templateName := "javaapp"
 	templateRelease := "v1.0.1"
 	mockCtl := gomock.NewController(t)
@@ -1628,13 +1736,18 @@ func testUpgrade(t *testing.T) {
 		},
 	}
 
+	namingSvc, err := naming.NewService(manager, namingconfig.Config{})
+	assert.Nil(t, err)
+
 	c = &controller{
 		clusterMgr:            manager.ClusterMgr,
+		clusterSummaryMgr:     manager.ClusterSummaryMgr,
 		clusterGitRepo:        clusterGitRepo,
 		applicationMgr:        appMgr,
 		templateMgr:           manager.TemplateMgr,
 		templateReleaseMgr:    trMgr,
 		templateSchemaGetter:  templateSchemaGetter,
+		templateValidator:     templatevalidation.NewService(templateSchemaGetter),
 		envMgr:                envMgr,
 		envRegionMgr:          envRegionMgr,
 		regionMgr:             regionMgr,
@@ -1651,6 +1764,8 @@ func testUpgrade(t *testing.T) {
 		eventSvc:              eventservice.New(manager),
 		templateUpgradeMapper: templateUpgradeMapper,
 		memberManager:         manager.MemberMgr,
+		namingSvc:             namingSvc,
+		quotaSvc:              quotaservice.NewService(manager),
 	}
 
 	applicationGitRepo.EXPECT().GetApplication(ctx, gomock.Any(), gomock.Any()).
@@ -2026,3 +2141,26 @@ func testIsClusterActuallyHealthy(t *testing.T) {
 	// three t1 pods is not expected
 	assert.Equal(t, false, isClusterActuallyHealthy(ctx, cs, imageV1, tActual, 3))
 }
+
+func TestNewEnvChange(t *testing.T) {
+	plain := &envvar.EnvVar{Name: "JAVA_OPTS", Value: "-Xmx1g", Type: envvar.TypeString}
+	secret := &envvar.EnvVar{Name: "JAVA_OPTS", Value: "-Xmx2g", Type: envvar.TypeString, Secret: true}
+
+	change := newEnvChange(nil, plain)
+	assert.Equal(t, envchangemodels.ActionAdded, change.Action)
+	assert.Equal(t, "JAVA_OPTS", change.Name)
+	assert.Equal(t, "", change.OldValue)
+	assert.Equal(t, "-Xmx1g", change.NewValue)
+
+	// the value is masked once the variable is marked as secret
+	change = newEnvChange(plain, secret)
+	assert.Equal(t, envchangemodels.ActionUpdated, change.Action)
+	assert.True(t, change.Secret)
+	assert.Equal(t, "-Xmx1g", change.OldValue)
+	assert.Equal(t, envvar.MaskedValue, change.NewValue)
+
+	change = newEnvChange(secret, nil)
+	assert.Equal(t, envchangemodels.ActionDeleted, change.Action)
+	assert.Equal(t, envvar.MaskedValue, change.OldValue)
+	assert.Equal(t, "", change.NewValue)
+}
@@ -20,6 +20,7 @@ import (
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
+	csmodels "github.com/horizoncd/horizon/pkg/clustersummary/models"
 	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
@@ -312,9 +313,30 @@ type GetClusterByNameResponse struct {
 
 type ListClusterWithFullResponse struct {
 	*ListClusterResponse
-	IsFavorite *bool  `json:"isFavorite,omitempty"`
-	FullName   string `json:"fullName,omitempty"`
-	FullPath   string `json:"fullPath,omitempty"`
+	IsFavorite   *bool         `json:"isFavorite,omitempty"`
+	FullName     string        `json:"fullName,omitempty"`
+	FullPath     string        `json:"fullPath,omitempty"`
+	LatestDeploy *LatestDeploy `json:"latestDeploy,omitempty"`
+}
+
+// LatestDeploy is the latest deploy pipelinerun of a cluster, which is taken from cluster summary
+type LatestDeploy struct {
+	PipelinerunID uint       `json:"pipelinerunID"`
+	Action        string     `json:"action"`
+	Status        string     `json:"status"`
+	DeployedAt    *time.Time `json:"deployedAt,omitempty"`
+}
+
+func ofClusterSummary(summary *csmodels.ClusterSummary) *LatestDeploy {
+	if summary == nil || summary.PipelinerunID == 0 {
+		return nil
+	}
+	return &LatestDeploy{
+		PipelinerunID: summary.PipelinerunID,
+		Action:        summary.PipelinerunAction,
+		Status:        summary.PipelinerunStatus,
+		DeployedAt:    summary.DeployedAt,
+	}
 }
 
 type ListClusterWithExpiryResponse struct {
@@ -19,25 +19,38 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
+	"github.com/horizoncd/horizon/pkg/cluster/availability"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/networkpolicy"
+	"github.com/horizoncd/horizon/pkg/cluster/rollout"
 	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
 )
 
 type CreateClusterRequestV2 struct {
-	Name        string              `json:"name"`
+	Name        string              `json:"name" binding:"required,max=53,resourcename"`
 	Description string              `json:"description"`
+	Namespace   string              `json:"namespace"`
 	Priority    string              `json:"priority"`
 	ExpireTime  string              `json:"expireTime"`
 	Git         *codemodels.Git     `json:"git"`
 	Image       *string             `json:"image"`
 	Tags        tagmodels.TagsBasic `json:"tags"`
 
-	BuildConfig    map[string]interface{}   `json:"buildConfig"`
+	BuildConfig    map[string]interface{}   `json:"buildConfig" binding:"omitempty,templatevalues"`
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
-	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig" binding:"omitempty,templatevalues"`
+	// Rollout defaults to rollout.Default() if not specified
+	Rollout *rollout.Config `json:"rollout"`
+	// NetworkPolicy leaves the traffic open if not specified, unless the region mandates default deny
+	NetworkPolicy *networkpolicy.Config `json:"networkPolicy"`
+	// Availability declares the pod disruption budget and topology spread constraints
+	Availability *availability.Config `json:"availability"`
+	// Autoscaling declares the horizontal pod autoscaler, the replicas are fixed if not specified
+	Autoscaling *autoscaling.Config `json:"autoscaling"`
 
 	// TODO(tom): just for internal usage
 	ExtraMembers map[string]string `json:"extraMembers"`
@@ -129,6 +142,10 @@ func (r *CreateClusterParamsV2) toClusterModel(application *appmodels.Applicatio
 	return cluster, tags
 }
 
+type CreateClusterAsyncResponse struct {
+	TaskID uint `json:"taskID"`
+}
+
 type CreateClusterResponseV2 struct {
 	ID            uint         `json:"id"`
 	Name          string       `json:"name"`
@@ -156,9 +173,27 @@ type UpdateClusterRequestV2 struct {
 	Image *string         `json:"image"`
 
 	// git config info
-	BuildConfig    map[string]interface{}   `json:"buildConfig"`
+	BuildConfig    map[string]interface{}   `json:"buildConfig" binding:"omitempty,templatevalues"`
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
-	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig" binding:"omitempty,templatevalues"`
+	// Rollout is kept unchanged if not specified
+	Rollout *rollout.Config `json:"rollout"`
+	// NetworkPolicy is kept unchanged if not specified, an empty one removes the rules
+	NetworkPolicy *networkpolicy.Config `json:"networkPolicy"`
+	// Availability is kept unchanged if not specified, an empty one removes the requirements
+	Availability *availability.Config `json:"availability"`
+	// Autoscaling is kept unchanged if not specified, an empty one removes the autoscaler
+	Autoscaling *autoscaling.Config `json:"autoscaling"`
+	// ConfigCommit is the config commit which the update is based on, the update fails
+	// with a conflict if config has been changed since it
+	ConfigCommit string `json:"configCommit"`
+}
+
+// changesConfig tells whether the request changes config in git repo
+func (r *UpdateClusterRequestV2) changesConfig() bool {
+	return r.BuildConfig != nil || r.TemplateInfo != nil || r.TemplateConfig != nil ||
+		r.Rollout != nil || r.NetworkPolicy != nil || r.Availability != nil ||
+		r.Autoscaling != nil
 }
 
 func (r *UpdateClusterRequestV2) toClusterModel(cluster *models.Cluster, expireSeconds uint, environmentName,
@@ -214,6 +249,8 @@ type GetClusterResponseV2 struct {
 	ApplicationName string              `json:"applicationName"`
 	ApplicationID   uint                `json:"applicationID"`
 	Tags            tagmodels.TagsBasic `json:"tags"`
+	// Metadata are the values of the metadata fields defined by the installation, such as the runbook url
+	Metadata map[string]string `json:"metadata"`
 
 	// source info
 	Git   *codemodels.Git `json:"git"`
@@ -224,6 +261,11 @@ type GetClusterResponseV2 struct {
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
 	TemplateConfig map[string]interface{}   `json:"templateConfig"`
 	Manifest       map[string]interface{}   `json:"manifest"`
+	Rollout        *rollout.Config          `json:"rollout,omitempty"`
+	NetworkPolicy  *networkpolicy.Config    `json:"networkPolicy,omitempty"`
+	Availability   *availability.Config     `json:"availability,omitempty"`
+	Autoscaling    *autoscaling.Config      `json:"autoscaling,omitempty"`
+	ConfigCommit   string                   `json:"configCommit"`
 
 	// status and update info
 	Status       string    `json:"status"`
@@ -0,0 +1,46 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+const (
+	BatchDeployStatusPending  = "pending"
+	BatchDeployStatusDeployed = "deployed"
+	BatchDeployStatusFailed   = "failed"
+)
+
+// BatchDeployRequest deploys clusters of an application one by one,
+// unlike a release it neither orders the clusters nor waits for them to get healthy
+type BatchDeployRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ClusterIDs  []uint `json:"clusterIDs"`
+}
+
+type BatchDeployAsyncResponse struct {
+	TaskID uint `json:"taskID"`
+}
+
+// BatchDeployResult is the result of the batch deploy task, it's updated as the clusters are deployed
+type BatchDeployResult struct {
+	Clusters []*ClusterDeployResult `json:"clusters"`
+}
+
+type ClusterDeployResult struct {
+	ClusterID     uint   `json:"clusterID"`
+	ClusterName   string `json:"clusterName"`
+	Status        string `json:"status"`
+	PipelinerunID uint   `json:"pipelinerunID,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
@@ -33,6 +33,8 @@ type BuildDeployResponse struct {
 type GetDiffResponse struct {
 	CodeInfo   *CodeInfo `json:"codeInfo"`
 	ConfigDiff string    `json:"configDiff"`
+	// EnvChanges are changes of environment variables since the last successful deploy
+	EnvChanges []*EnvChange `json:"envChanges,omitempty"`
 }
 
 type CodeInfo struct {
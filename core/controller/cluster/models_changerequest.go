@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	changerequestmodels "github.com/horizoncd/horizon/pkg/changerequest/models"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
+	"github.com/horizoncd/horizon/pkg/cluster/availability"
+	"github.com/horizoncd/horizon/pkg/cluster/networkpolicy"
+	"github.com/horizoncd/horizon/pkg/cluster/rollout"
+)
+
+// CreateChangeRequestRequest proposes config changes of a cluster, configs not specified are kept unchanged
+type CreateChangeRequestRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	BuildConfig    map[string]interface{} `json:"buildConfig"`
+	TemplateConfig map[string]interface{} `json:"templateConfig"`
+	Rollout        *rollout.Config        `json:"rollout"`
+	NetworkPolicy  *networkpolicy.Config  `json:"networkPolicy"`
+	Availability   *availability.Config   `json:"availability"`
+	Autoscaling    *autoscaling.Config    `json:"autoscaling"`
+	// ConfigCommit is the config commit which the changes are based on
+	ConfigCommit string `json:"configCommit"`
+}
+
+func (r *CreateChangeRequestRequest) toUpdateClusterRequest() *UpdateClusterRequestV2 {
+	return &UpdateClusterRequestV2{
+		BuildConfig:    r.BuildConfig,
+		TemplateConfig: r.TemplateConfig,
+		Rollout:        r.Rollout,
+		NetworkPolicy:  r.NetworkPolicy,
+		Availability:   r.Availability,
+		Autoscaling:    r.Autoscaling,
+		ConfigCommit:   r.ConfigCommit,
+	}
+}
+
+// ReviewChangeRequestRequest approves or rejects a change request
+type ReviewChangeRequestRequest struct {
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment"`
+}
+
+type ChangeRequest struct {
+	ID              uint       `json:"id"`
+	ClusterID       uint       `json:"clusterID"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Branch          string     `json:"branch"`
+	MergeRequestURL string     `json:"mergeRequestURL"`
+	Status          string     `json:"status"`
+	MergedCommit    string     `json:"mergedCommit,omitempty"`
+	ReviewedBy      uint       `json:"reviewedBy,omitempty"`
+	ReviewComment   string     `json:"reviewComment,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	CreatedBy       uint       `json:"createdBy"`
+	// Diff is the diff of config proposed, only returned when getting a pending change request
+	Diff string `json:"diff,omitempty"`
+}
+
+func ofChangeRequest(changeRequest *changerequestmodels.ChangeRequest) *ChangeRequest {
+	return &ChangeRequest{
+		ID:              changeRequest.ID,
+		ClusterID:       changeRequest.ClusterID,
+		Title:           changeRequest.Title,
+		Description:     changeRequest.Description,
+		Branch:          changeRequest.Branch,
+		MergeRequestURL: changeRequest.MergeRequestURL,
+		Status:          changeRequest.Status,
+		MergedCommit:    changeRequest.MergedCommit,
+		ReviewedBy:      changeRequest.ReviewedBy,
+		ReviewComment:   changeRequest.ReviewComment,
+		ReviewedAt:      changeRequest.ReviewedAt,
+		CreatedAt:       changeRequest.CreatedAt,
+		CreatedBy:       changeRequest.CreatedBy,
+	}
+}
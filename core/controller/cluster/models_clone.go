@@ -0,0 +1,28 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+// CloneClusterRequest creates a cluster with the config and tags of an existing one
+type CloneClusterRequest struct {
+	Name string `json:"name" binding:"required,max=53,resourcename"`
+	// Description is copied from the origin cluster if it's nil
+	Description *string `json:"description"`
+	// ApplicationID is the application to create the cluster in, it's the application of the origin if it's 0
+	ApplicationID uint `json:"applicationID"`
+	// Environment and Region default to the ones of the origin cluster, so that a staging copy
+	// can be created by setting the environment only
+	Environment string `json:"environment"`
+	Region      string `json:"region"`
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/envvar"
+	envchangemodels "github.com/horizoncd/horizon/pkg/clusterenv/models"
+)
+
+// UpdateEnvRequest updates an environment variable of a cluster, fields not specified are kept unchanged
+type UpdateEnvRequest struct {
+	Value  *string `json:"value"`
+	Type   *string `json:"type"`
+	Secret *bool   `json:"secret"`
+}
+
+// EnvChange is a change of an environment variable, values of secrets are masked
+type EnvChange struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Action       string    `json:"action"`
+	Type         string    `json:"type"`
+	Secret       bool      `json:"secret"`
+	OldValue     string    `json:"oldValue,omitempty"`
+	NewValue     string    `json:"newValue,omitempty"`
+	ConfigCommit string    `json:"configCommit"`
+	CreatedAt    time.Time `json:"createdAt"`
+	CreatedBy    uint      `json:"createdBy"`
+}
+
+func ofEnvChange(change *envchangemodels.ClusterEnvChange) *EnvChange {
+	return &EnvChange{
+		ID:           change.ID,
+		Name:         change.Name,
+		Action:       change.Action,
+		Type:         change.Type,
+		Secret:       change.Secret,
+		OldValue:     change.OldValue,
+		NewValue:     change.NewValue,
+		ConfigCommit: change.ConfigCommit,
+		CreatedAt:    change.CreatedAt,
+		CreatedBy:    change.CreatedBy,
+	}
+}
+
+// newEnvChange records the change from before to after, either of them is nil for additions and deletions.
+// Values are masked before saved if the variable is secret at that side.
+func newEnvChange(before, after *envvar.EnvVar) *envchangemodels.ClusterEnvChange {
+	change := &envchangemodels.ClusterEnvChange{}
+	switch {
+	case before == nil:
+		change.Action = envchangemodels.ActionAdded
+	case after == nil:
+		change.Action = envchangemodels.ActionDeleted
+	default:
+		change.Action = envchangemodels.ActionUpdated
+	}
+	if before != nil {
+		masked := before.Masked()
+		change.Name, change.Type, change.Secret, change.OldValue = masked.Name, masked.Type, masked.Secret, masked.Value
+	}
+	if after != nil {
+		masked := after.Masked()
+		change.Name, change.Type, change.Secret, change.NewValue = masked.Name, masked.Type, masked.Secret, masked.Value
+	}
+	return change
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+)
+
+// DeletedCluster is a cluster soft deleted which can be restored until it is purged
+type DeletedCluster struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	ApplicationID uint      `json:"applicationID"`
+	Environment   string    `json:"environment"`
+	Region        string    `json:"region"`
+	Template      string    `json:"template"`
+	DeletedBy     uint      `json:"deletedBy"`
+	DeletedAt     time.Time `json:"deletedAt"`
+}
+
+func ofDeletedCluster(cluster *models.Cluster) *DeletedCluster {
+	return &DeletedCluster{
+		ID:            cluster.ID,
+		Name:          cluster.Name,
+		Description:   cluster.Description,
+		ApplicationID: cluster.ApplicationID,
+		Environment:   cluster.EnvironmentName,
+		Region:        cluster.RegionName,
+		Template:      cluster.Template,
+		DeletedBy:     cluster.UpdatedBy,
+		DeletedAt:     time.Unix(int64(cluster.DeletedTs), 0),
+	}
+}
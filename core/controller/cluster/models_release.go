@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/horizoncd/horizon/pkg/cluster/releaseplan"
+)
+
+const (
+	ReleaseStatusPending   = "pending"
+	ReleaseStatusDeploying = "deploying"
+	ReleaseStatusHealthy   = "healthy"
+	ReleaseStatusFailed    = "failed"
+	ReleaseStatusSkipped   = "skipped"
+)
+
+// ReleaseRequest releases clusters of a group stage by stage in their dependency order
+type ReleaseRequest struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Clusters    []*releaseplan.Item `json:"clusters"`
+	// HealthTimeoutSeconds is how long to wait for the clusters of a stage to become healthy
+	// before the release is halted, defaults to 600
+	HealthTimeoutSeconds int `json:"healthTimeoutSeconds"`
+}
+
+type ReleaseAsyncResponse struct {
+	TaskID uint `json:"taskID"`
+	// Stages are cluster ids grouped by the order they are released in
+	Stages [][]uint `json:"stages"`
+}
+
+// ReleaseResult is the result of the release task, it's updated as the release goes on
+type ReleaseResult struct {
+	Stages   [][]uint                `json:"stages"`
+	Clusters []*ClusterReleaseResult `json:"clusters"`
+}
+
+type ClusterReleaseResult struct {
+	ClusterID     uint   `json:"clusterID"`
+	ClusterName   string `json:"clusterName"`
+	Stage         int    `json:"stage"`
+	Status        string `json:"status"`
+	PipelinerunID uint   `json:"pipelinerunID,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+)
+
+// CreateSandboxRequest creates a sandbox cluster from a template,
+// its environment, region, expiry and resources are decided by the sandbox config
+type CreateSandboxRequest struct {
+	// Name is generated if it's empty
+	Name           string                   `json:"name"`
+	Description    string                   `json:"description"`
+	Git            *codemodels.Git          `json:"git"`
+	Image          *string                  `json:"image"`
+	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+}
+
+type Sandbox struct {
+	ID              uint   `json:"id"`
+	Name            string `json:"name"`
+	FullPath        string `json:"fullPath"`
+	Environment     string `json:"environment"`
+	Region          string `json:"region"`
+	Template        string `json:"template"`
+	TemplateRelease string `json:"templateRelease"`
+	Status          string `json:"status"`
+	// ExpireSeconds is how long the sandbox is kept after its last update, it's freed by auto-free then
+	ExpireSeconds uint      `json:"expireSeconds"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func ofSandbox(cluster *models.Cluster, fullPath string) *Sandbox {
+	return &Sandbox{
+		ID:              cluster.ID,
+		Name:            cluster.Name,
+		FullPath:        fullPath,
+		Environment:     cluster.EnvironmentName,
+		Region:          cluster.RegionName,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		Status:          cluster.Status,
+		ExpireSeconds:   cluster.ExpireSeconds,
+		CreatedAt:       cluster.CreatedAt,
+		UpdatedAt:       cluster.UpdatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	scheduleddeploymodels "github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+)
+
+// CreateScheduledDeployRequest schedules a builddeploy or restart of a cluster at a future time.
+// Title, Description and Git are used by builddeploy only, the current git ref of the cluster
+// is built if Git is not specified.
+type CreateScheduledDeployRequest struct {
+	Action      string                 `json:"action"`
+	ScheduledAt time.Time              `json:"scheduledAt"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Git         *BuildDeployRequestGit `json:"git"`
+}
+
+type ScheduledDeploy struct {
+	ID            uint      `json:"id"`
+	ClusterID     uint      `json:"clusterID"`
+	Action        string    `json:"action"`
+	Title         string    `json:"title,omitempty"`
+	Description   string    `json:"description,omitempty"`
+	GitRefType    string    `json:"gitRefType,omitempty"`
+	GitRef        string    `json:"gitRef,omitempty"`
+	ScheduledAt   time.Time `json:"scheduledAt"`
+	Status        string    `json:"status"`
+	PipelinerunID uint      `json:"pipelinerunID,omitempty"`
+	// Message is the reason why the deploy failed to be triggered
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy uint      `json:"createdBy"`
+}
+
+func ofScheduledDeploy(deploy *scheduleddeploymodels.ScheduledDeploy) *ScheduledDeploy {
+	return &ScheduledDeploy{
+		ID:            deploy.ID,
+		ClusterID:     deploy.ClusterID,
+		Action:        deploy.Action,
+		Title:         deploy.Title,
+		Description:   deploy.Description,
+		GitRefType:    deploy.GitRefType,
+		GitRef:        deploy.GitRef,
+		ScheduledAt:   deploy.ScheduledAt,
+		Status:        deploy.Status,
+		PipelinerunID: deploy.PipelinerunID,
+		Message:       deploy.Message,
+		CreatedAt:     deploy.CreatedAt,
+		CreatedBy:     deploy.CreatedBy,
+	}
+}
+
+// BuildDeployRequestOf returns the builddeploy request of a scheduled deploy
+func BuildDeployRequestOf(deploy *scheduleddeploymodels.ScheduledDeploy) *BuildDeployRequest {
+	r := &BuildDeployRequest{
+		Title:       deploy.Title,
+		Description: deploy.Description,
+	}
+	switch deploy.GitRefType {
+	case codemodels.GitRefTypeBranch:
+		r.Git = &BuildDeployRequestGit{Branch: deploy.GitRef}
+	case codemodels.GitRefTypeTag:
+		r.Git = &BuildDeployRequestGit{Tag: deploy.GitRef}
+	case codemodels.GitRefTypeCommit:
+		r.Git = &BuildDeployRequestGit{Commit: deploy.GitRef}
+	}
+	return r
+}
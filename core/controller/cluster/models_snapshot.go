@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"time"
+
+	snapshotmodels "github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+)
+
+// Snapshot is a snapshot of the config and metadata of a cluster
+type Snapshot struct {
+	ID              uint                  `json:"id"`
+	Trigger         string                `json:"trigger"`
+	PipelinerunID   uint                  `json:"pipelinerunID,omitempty"`
+	ConfigCommit    string                `json:"configCommit"`
+	Template        string                `json:"template"`
+	TemplateRelease string                `json:"templateRelease"`
+	GitURL          string                `json:"gitURL,omitempty"`
+	GitSubfolder    string                `json:"gitSubfolder,omitempty"`
+	GitRefType      string                `json:"gitRefType,omitempty"`
+	GitRef          string                `json:"gitRef,omitempty"`
+	Image           string                `json:"image,omitempty"`
+	Tags            []*tagmodels.TagBasic `json:"tags"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	CreatedBy       uint                  `json:"createdBy"`
+}
+
+func ofSnapshot(snapshot *snapshotmodels.ClusterSnapshot) *Snapshot {
+	return &Snapshot{
+		ID:              snapshot.ID,
+		Trigger:         snapshot.TriggerType,
+		PipelinerunID:   snapshot.PipelinerunID,
+		ConfigCommit:    snapshot.ConfigCommit,
+		Template:        snapshot.Template,
+		TemplateRelease: snapshot.TemplateRelease,
+		GitURL:          snapshot.GitURL,
+		GitSubfolder:    snapshot.GitSubfolder,
+		GitRefType:      snapshot.GitRefType,
+		GitRef:          snapshot.GitRef,
+		Image:           snapshot.Image,
+		Tags:            snapshotTags(snapshot),
+		CreatedAt:       snapshot.CreatedAt,
+		CreatedBy:       snapshot.CreatedBy,
+	}
+}
+
+// snapshotTags parses tags of the snapshot, invalid tags are regarded as empty
+func snapshotTags(snapshot *snapshotmodels.ClusterSnapshot) []*tagmodels.TagBasic {
+	tags := make([]*tagmodels.TagBasic, 0)
+	if snapshot.Tags != "" {
+		_ = json.Unmarshal([]byte(snapshot.Tags), &tags)
+	}
+	return tags
+}
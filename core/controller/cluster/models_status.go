@@ -16,7 +16,10 @@ package cluster
 
 import (
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
 	"github.com/horizoncd/horizon/pkg/grafana"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -32,6 +35,18 @@ type Revision struct {
 
 type StatusResponseV2 struct {
 	Status string `json:"status"`
+	// OperationQueue lists argocd operations running or waiting for the cluster
+	OperationQueue []*cd.QueuedOperation `json:"operationQueue,omitempty"`
+	// Step is the step of the deploy in progress, it's set when the cluster is not healthy
+	Step *GetStepResponse `json:"step,omitempty"`
+	// Autoscaling is the status of the autoscaler, it's set when the cluster is autoscaled
+	Autoscaling *cd.AutoscalingStatus `json:"autoscaling,omitempty"`
+}
+
+type GetAutoscalingResponse struct {
+	// Config is nil if the cluster is not autoscaled
+	Config *autoscaling.Config   `json:"config"`
+	Status *cd.AutoscalingStatus `json:"status"`
 }
 
 type PipelinerunStatusResponse struct {
@@ -69,6 +84,17 @@ type GetGrafanaDashboardsResponse struct {
 type GetClusterPodResponse struct {
 	corev1.Pod
 }
+
+// PodProvenance tells which pipelinerun and commit a pod is built from, by the build annotations of the pod
+type PodProvenance struct {
+	Pod           string `json:"pod"`
+	ClusterID     uint   `json:"clusterID"`
+	SourceCommit  string `json:"sourceCommit,omitempty"`
+	Builder       string `json:"builder,omitempty"`
+	PipelinerunID uint   `json:"pipelinerunID,omitempty"`
+	// Pipelinerun is nil if the pod is not deployed by a pipelinerun of the cluster
+	Pipelinerun *prmodels.PipelineBasic `json:"pipelinerun,omitempty"`
+}
 type ResourceNode struct {
 	v1alpha1.ResourceNode
 	PodDetail interface{} `json:"podDetail,omitempty"`
@@ -84,5 +110,9 @@ type GetStepResponse struct {
 	Replicas     []int   `json:"replicas"`
 	ManualPaused bool    `json:"manualPaused"`
 	AutoPromote  bool    `json:"autoPromote"`
+	Strategy     string  `json:"strategy,omitempty"`
+	Phase        string  `json:"phase,omitempty"`
+	Aborted      bool    `json:"aborted"`
+	Message      string  `json:"message,omitempty"`
 	Extra        *string `json:"extra"`
 }
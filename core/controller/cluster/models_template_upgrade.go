@@ -0,0 +1,38 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+)
+
+type TemplateUpgradePlan struct {
+	From *codemodels.TemplateInfo `json:"from"`
+	To   *codemodels.TemplateInfo `json:"to"`
+	*templateschema.Migration
+	// TemplateConfig is the proposed config migrated to the target release
+	TemplateConfig map[string]interface{} `json:"templateConfig"`
+	// ValidationError is not empty when the proposed config needs manual changes
+	ValidationError string `json:"validationError,omitempty"`
+}
+
+type TemplateUpgradeRequest struct {
+	TemplateRelease string `json:"templateRelease"`
+	// TemplateConfig overrides the proposed config if not empty
+	TemplateConfig map[string]interface{} `json:"templateConfig"`
+	Title          string                 `json:"title"`
+	Description    string                 `json:"description"`
+}
@@ -0,0 +1,150 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// MaxExportRange limits the time range of an export to keep it in a reasonable size
+const MaxExportRange = 366 * 24 * time.Hour
+
+type Controller interface {
+	// Export bundles audit logs and deploy history in [start, end) along with current members of all resources,
+	// only admins and auditors can export
+	Export(ctx context.Context, start, end time.Time) (*Export, error)
+}
+
+type controller struct {
+	eventMgr  eventmanager.Manager
+	memberMgr membermanager.Manager
+	prMgr     *prmanager.PRManager
+	userMgr   usermanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		eventMgr:  param.EventMgr,
+		memberMgr: param.MemberMgr,
+		prMgr:     param.PRMgr,
+		userMgr:   param.UserMgr,
+	}
+}
+
+func (c *controller) Export(ctx context.Context, start, end time.Time) (*Export, error) {
+	const op = "compliance controller: export"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() && !currentUser.IsAuditor() {
+		return nil, perror.Wrap(herrors.ErrNoPrivilege, "could not export\n"+
+			"should be admin or auditor")
+	}
+	if !start.Before(end) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "start time should be before end time")
+	}
+	if end.Sub(start) > MaxExportRange {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "time range should not exceed %v", MaxExportRange)
+	}
+
+	export := &Export{
+		StartTime:   start,
+		EndTime:     end,
+		GeneratedAt: time.Now(),
+		GeneratedBy: currentUser.String(),
+		AuditLogs:   make([]*AuditLog, 0),
+		Members:     make([]*Member, 0),
+		Deploys:     make([]*Deploy, 0),
+	}
+	userIDs := make(map[uint]struct{})
+
+	events, err := c.eventMgr.ListEventsByTimeRange(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		export.AuditLogs = append(export.AuditLogs, ofAuditLog(event))
+		userIDs[event.CreatedBy] = struct{}{}
+	}
+
+	members, err := c.memberMgr.ListAll(ctx)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.MemberInfoInDB, err.Error())
+	}
+	for i := range members {
+		export.Members = append(export.Members, ofMember(&members[i]))
+		userIDs[members[i].GrantedBy] = struct{}{}
+		if members[i].MemberType == membermodels.MemberUser {
+			userIDs[members[i].MemberNameID] = struct{}{}
+		}
+	}
+
+	pipelineruns, err := c.prMgr.PipelineRun.ListByTimeRange(ctx, start, end,
+		prmodels.ActionBuildDeploy, prmodels.ActionDeploy, prmodels.ActionRollback)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range pipelineruns {
+		export.Deploys = append(export.Deploys, ofDeploy(pr))
+		userIDs[pr.CreatedBy] = struct{}{}
+	}
+
+	export.Users, err = c.listUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+func (c *controller) listUsers(ctx context.Context, userIDs map[uint]struct{}) ([]*usermodels.UserBasic, error) {
+	ids := make([]uint, 0, len(userIDs))
+	for id := range userIDs {
+		if id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	users := make([]*usermodels.UserBasic, 0, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+	usersInDB, err := c.userMgr.GetUserByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range usersInDB {
+		users = append(users, usermodels.ToUser(user))
+	}
+	return users, nil
+}
@@ -0,0 +1,130 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+var (
+	ctx     context.Context
+	manager *managerparam.Manager
+)
+
+// nolint
+func TestMain(m *testing.M) {
+	db, _ := orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	if err := db.AutoMigrate(&eventmodels.Event{}, &membermodels.Member{},
+		&prmodels.Pipelinerun{}, &usermodels.User{}); err != nil {
+		panic(err)
+	}
+	ctx = context.WithValue(context.TODO(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:    "Tony",
+		ID:      uint(1),
+		Auditor: true,
+	})
+
+	os.Exit(m.Run())
+}
+
+func TestExport(t *testing.T) {
+	user, err := manager.UserMgr.Create(ctx, &usermodels.User{
+		Name:  "Tony",
+		Email: "tony@horizon.com",
+	})
+	assert.Nil(t, err)
+
+	_, err = manager.EventMgr.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   1,
+			EventType:    eventmodels.ClusterDeployed,
+		},
+		ReqID: "xxx",
+	})
+	assert.Nil(t, err)
+	_, err = manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   1,
+		Role:         "owner",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: user.ID,
+		GrantedBy:    user.ID,
+	})
+	assert.Nil(t, err)
+	for _, action := range []string{prmodels.ActionDeploy, prmodels.ActionRestart} {
+		_, err = manager.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+			ClusterID: 1,
+			Action:    action,
+			Status:    string(prmodels.StatusOK),
+		})
+		assert.Nil(t, err)
+	}
+
+	c := &controller{
+		eventMgr:  manager.EventMgr,
+		memberMgr: manager.MemberMgr,
+		prMgr:     manager.PRMgr,
+		userMgr:   manager.UserMgr,
+	}
+
+	now := time.Now()
+	export, err := c.Export(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(export.AuditLogs))
+	assert.Equal(t, eventmodels.ClusterDeployed, export.AuditLogs[0].EventType)
+	assert.Equal(t, 1, len(export.Members))
+	assert.Equal(t, MemberTypeUser, export.Members[0].MemberType)
+	// restarts are not deploys
+	assert.Equal(t, 1, len(export.Deploys))
+	assert.Equal(t, prmodels.ActionDeploy, export.Deploys[0].Action)
+	assert.Equal(t, 1, len(export.Users))
+	assert.Equal(t, "Tony", export.Users[0].Name)
+
+	export, err = c.Export(ctx, now.Add(time.Hour), now.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(export.AuditLogs))
+	assert.Equal(t, 1, len(export.Members))
+	assert.Equal(t, 0, len(export.Deploys))
+
+	_, err = c.Export(ctx, now, now.Add(-time.Hour))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Export(ctx, now.Add(-2*MaxExportRange), now)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	guestCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Jerry",
+		ID:   uint(2),
+	})
+	_, err = c.Export(guestCtx, now.Add(-time.Hour), now)
+	assert.Equal(t, herrors.ErrNoPrivilege, perror.Cause(err))
+}
@@ -0,0 +1,136 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"time"
+
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// Export bundles what external audits ask for in a time range
+type Export struct {
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	GeneratedBy string    `json:"generatedBy"`
+	// AuditLogs are the events happened in the time range
+	AuditLogs []*AuditLog `json:"auditLogs"`
+	// Members are the members of all resources when the export is generated
+	Members []*Member `json:"members"`
+	// Deploys are the pipelineruns which build, deploy or rollback clusters in the time range
+	Deploys []*Deploy `json:"deploys"`
+	// Users are the users referred by the audit logs, members and deploys
+	Users []*usermodels.UserBasic `json:"users"`
+}
+
+type AuditLog struct {
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	EventType    string    `json:"eventType"`
+	Extra        string    `json:"extra,omitempty"`
+	ReqID        string    `json:"reqID"`
+	CreatedAt    time.Time `json:"createdAt"`
+	CreatedBy    uint      `json:"createdBy"`
+}
+
+func ofAuditLog(event *eventmodels.Event) *AuditLog {
+	auditLog := &AuditLog{
+		ID:           event.ID,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		EventType:    event.EventType,
+		ReqID:        event.ReqID,
+		CreatedAt:    event.CreatedAt,
+		CreatedBy:    event.CreatedBy,
+	}
+	if event.Extra != nil {
+		auditLog.Extra = *event.Extra
+	}
+	return auditLog
+}
+
+const (
+	MemberTypeUser  = "user"
+	MemberTypeGroup = "group"
+)
+
+type Member struct {
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	MemberType   string    `json:"memberType"`
+	MemberNameID uint      `json:"memberNameID"`
+	Role         string    `json:"role"`
+	GrantedBy    uint      `json:"grantedBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func ofMember(member *membermodels.Member) *Member {
+	memberType := MemberTypeUser
+	if member.MemberType == membermodels.MemberGroup {
+		memberType = MemberTypeGroup
+	}
+	return &Member{
+		ResourceType: string(member.ResourceType),
+		ResourceID:   member.ResourceID,
+		MemberType:   memberType,
+		MemberNameID: member.MemberNameID,
+		Role:         member.Role,
+		GrantedBy:    member.GrantedBy,
+		CreatedAt:    member.CreatedAt,
+	}
+}
+
+type Deploy struct {
+	PipelinerunID uint       `json:"pipelinerunID"`
+	ClusterID     uint       `json:"clusterID"`
+	Action        string     `json:"action"`
+	Status        string     `json:"status"`
+	Title         string     `json:"title"`
+	GitURL        string     `json:"gitURL,omitempty"`
+	GitRef        string     `json:"gitRef,omitempty"`
+	GitCommit     string     `json:"gitCommit,omitempty"`
+	ImageURL      string     `json:"imageURL,omitempty"`
+	ConfigCommit  string     `json:"configCommit,omitempty"`
+	RollbackFrom  *uint      `json:"rollbackFrom,omitempty"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	CreatedBy     uint       `json:"createdBy"`
+}
+
+func ofDeploy(pr *prmodels.Pipelinerun) *Deploy {
+	return &Deploy{
+		PipelinerunID: pr.ID,
+		ClusterID:     pr.ClusterID,
+		Action:        pr.Action,
+		Status:        pr.Status,
+		Title:         pr.Title,
+		GitURL:        pr.GitURL,
+		GitRef:        pr.GitRef,
+		GitCommit:     pr.GitCommit,
+		ImageURL:      pr.ImageURL,
+		ConfigCommit:  pr.ConfigCommit,
+		RollbackFrom:  pr.RollbackFrom,
+		StartedAt:     pr.StartedAt,
+		FinishedAt:    pr.FinishedAt,
+		CreatedAt:     pr.CreatedAt,
+		CreatedBy:     pr.CreatedBy,
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploylock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	deploylockmanager "github.com/horizoncd/horizon/pkg/deploylock/manager"
+	"github.com/horizoncd/horizon/pkg/deploylock/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Get returns the lock of the cluster or application, nil is returned if it is not locked
+	Get(ctx context.Context, resourceType string, resourceID uint) (*DeployLock, error)
+	// Lock forbids deploys of the cluster or application, an existing lock is replaced
+	Lock(ctx context.Context, resourceType string, resourceID uint, r *LockRequest) (*DeployLock, error)
+	Unlock(ctx context.Context, resourceType string, resourceID uint) error
+}
+
+type controller struct {
+	deployLockMgr  deploylockmanager.Manager
+	clusterMgr     clustermanager.Manager
+	applicationMgr appmanager.Manager
+	userMgr        usermanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		deployLockMgr:  param.DeployLockMgr,
+		clusterMgr:     param.ClusterMgr,
+		applicationMgr: param.ApplicationMgr,
+		userMgr:        param.UserMgr,
+	}
+}
+
+func (c *controller) Get(ctx context.Context, resourceType string, resourceID uint) (_ *DeployLock, err error) {
+	const op = "deploy lock controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	lock, err := c.deployLockMgr.GetByResource(ctx, resourceType, resourceID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	owner, err := c.userMgr.GetUserByID(ctx, lock.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return ofDeployLock(lock, owner), nil
+}
+
+func (c *controller) Lock(ctx context.Context, resourceType string, resourceID uint,
+	r *LockRequest) (_ *DeployLock, err error) {
+	const op = "deploy lock controller: lock"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if r.Reason == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "reason is required to lock deploys")
+	}
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &models.DeployLock{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Reason:       r.Reason,
+		CreatedAt:    time.Now(),
+		CreatedBy:    currentUser.GetID(),
+	}
+	if r.ExpireSeconds > 0 {
+		expireAt := lock.CreatedAt.Add(time.Duration(r.ExpireSeconds) * time.Second)
+		lock.ExpireAt = &expireAt
+	}
+	if err := c.deployLockMgr.Lock(ctx, lock); err != nil {
+		return nil, err
+	}
+	owner, err := c.userMgr.GetUserByID(ctx, lock.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return ofDeployLock(lock, owner), nil
+}
+
+func (c *controller) Unlock(ctx context.Context, resourceType string, resourceID uint) (err error) {
+	const op = "deploy lock controller: unlock"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return err
+	}
+	return c.deployLockMgr.Unlock(ctx, resourceType, resourceID)
+}
+
+// checkResource makes sure the cluster or application to lock exists
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	switch resourceType {
+	case common.ResourceCluster:
+		_, err := c.clusterMgr.GetByID(ctx, resourceID)
+		return err
+	case common.ResourceApplication:
+		_, err := c.applicationMgr.GetByID(ctx, resourceID)
+		return err
+	default:
+		return perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("deploy lock is not supported for %s", resourceType))
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploylock
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	deploylockmodels "github.com/horizoncd/horizon/pkg/deploylock/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+var (
+	ctx     context.Context
+	manager *managerparam.Manager
+)
+
+// nolint
+func TestMain(m *testing.M) {
+	db, _ := orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&membermodels.Member{}, &usermodels.User{}, &deploylockmodels.DeployLock{}); err != nil {
+		panic(err)
+	}
+	ctx = context.WithValue(context.TODO(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   uint(1),
+	})
+
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	user, err := manager.UserMgr.Create(ctx, &usermodels.User{
+		Name:  "Tony",
+		Email: "tony@horizon.com",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	application, err := manager.ApplicationMgr.Create(ctx, &appmodels.Application{
+		GroupID:  uint(1),
+		Name:     "app",
+		Priority: "P3",
+	}, nil)
+	assert.Nil(t, err)
+
+	c := &controller{
+		deployLockMgr:  manager.DeployLockMgr,
+		clusterMgr:     manager.ClusterMgr,
+		applicationMgr: manager.ApplicationMgr,
+		userMgr:        manager.UserMgr,
+	}
+
+	lock, err := c.Get(ctx, common.ResourceApplication, application.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, lock)
+
+	_, err = c.Lock(ctx, common.ResourceApplication, application.ID, &LockRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	_, err = c.Lock(ctx, common.ResourceCluster, 100, &LockRequest{Reason: "incident"})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	lock, err = c.Lock(ctx, common.ResourceApplication, application.ID, &LockRequest{
+		Reason:        "incident",
+		ExpireSeconds: 3600,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "incident", lock.Reason)
+	assert.NotNil(t, lock.ExpireAt)
+	assert.Equal(t, user.ID, lock.Owner.ID)
+
+	lock, err = c.Get(ctx, common.ResourceApplication, application.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "incident", lock.Reason)
+	assert.Equal(t, "tony@horizon.com", lock.Owner.Email)
+
+	err = c.Unlock(ctx, common.ResourceApplication, application.ID)
+	assert.Nil(t, err)
+	lock, err = c.Get(ctx, common.ResourceApplication, application.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, lock)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploylock
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/deploylock/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+type LockRequest struct {
+	Reason string `json:"reason"`
+	// ExpireSeconds releases the lock automatically after the seconds, 0 means never
+	ExpireSeconds uint `json:"expireSeconds"`
+}
+
+type DeployLock struct {
+	ResourceType string                `json:"resourceType"`
+	ResourceID   uint                  `json:"resourceID"`
+	Reason       string                `json:"reason"`
+	ExpireAt     *time.Time            `json:"expireAt,omitempty"`
+	LockedAt     time.Time             `json:"lockedAt"`
+	Owner        *usermodels.UserBasic `json:"owner"`
+}
+
+func ofDeployLock(lock *models.DeployLock, owner *usermodels.User) *DeployLock {
+	return &DeployLock{
+		ResourceType: lock.ResourceType,
+		ResourceID:   lock.ResourceID,
+		Reason:       lock.Reason,
+		ExpireAt:     lock.ExpireAt,
+		LockedAt:     lock.CreatedAt,
+		Owner:        usermodels.ToUser(owner),
+	}
+}
@@ -17,6 +17,9 @@ package event
 import (
 	"context"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -24,6 +27,17 @@ import (
 
 type Controller interface {
 	ListSupportEvents(ctx context.Context) map[string]string
+	// ListResourceEvents lists the events of the resource, the newest first,
+	// cursor is the NextCursor of the previous page, 0 for the first page
+	ListResourceEvents(ctx context.Context, resourceType string, resourceID uint,
+		cursor uint, pageSize int) (*ResourceEvents, error)
+}
+
+// _resourcesWithEvents are the resources whose events can be listed
+var _resourcesWithEvents = map[string]bool{
+	common.ResourceApplication: true,
+	common.ResourceCluster:     true,
+	common.ResourcePipelinerun: true,
 }
 
 type controller struct {
@@ -42,3 +56,32 @@ func (c *controller) ListSupportEvents(ctx context.Context) map[string]string {
 
 	return c.eventMgr.ListSupportEvents()
 }
+
+func (c *controller) ListResourceEvents(ctx context.Context, resourceType string, resourceID uint,
+	cursor uint, pageSize int) (*ResourceEvents, error) {
+	const op = "event controller: list resource events"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if !_resourcesWithEvents[resourceType] {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "events of %s are not supported", resourceType)
+	}
+	if pageSize <= 0 {
+		pageSize = common.DefaultPageSize
+	}
+	if pageSize > common.MaxPageSize {
+		pageSize = common.MaxPageSize
+	}
+
+	events, next, err := c.eventMgr.ListEventsByResource(ctx, resourceType, resourceID, cursor, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ResourceEvents{
+		Events:     make([]*Event, 0, len(events)),
+		NextCursor: next,
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, ofEventModel(e))
+	}
+	return resp, nil
+}
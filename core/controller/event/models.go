@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/event/models"
+)
+
+type Event struct {
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	EventType    string    `json:"eventType"`
+	Extra        *string   `json:"extra,omitempty"`
+	ReqID        string    `json:"reqID"`
+	CreatedAt    time.Time `json:"createdAt"`
+	CreatedBy    uint      `json:"createdBy"`
+}
+
+// ResourceEvents is a page of the events of a resource, NextCursor is 0 if there are no more events
+type ResourceEvents struct {
+	Events     []*Event `json:"events"`
+	NextCursor uint     `json:"nextCursor,omitempty"`
+}
+
+func ofEventModel(e *models.Event) *Event {
+	return &Event{
+		ID:           e.ID,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		EventType:    e.EventType,
+		Extra:        e.Extra,
+		ReqID:        e.ReqID,
+		CreatedAt:    e.CreatedAt,
+		CreatedBy:    e.CreatedBy,
+	}
+}
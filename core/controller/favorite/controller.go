@@ -0,0 +1,271 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package favorite
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
+	collectionmodels "github.com/horizoncd/horizon/pkg/collection/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	recentvisitmanager "github.com/horizoncd/horizon/pkg/recentvisit/manager"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// AddFavorite stars the application or cluster for current user
+	AddFavorite(ctx context.Context, resourceType string, resourceID uint) error
+	// DeleteFavorite unstars the application or cluster for current user
+	DeleteFavorite(ctx context.Context, resourceType string, resourceID uint) error
+	// ListFavorites lists the favorites of current user, latest starred first.
+	// resourceType is applications or clusters, both if empty
+	ListFavorites(ctx context.Context, resourceType string) ([]*Resource, error)
+	// ListRecentVisits lists at most limit resources recently visited by current user, latest first.
+	// resourceType is applications or clusters, both if empty
+	ListRecentVisits(ctx context.Context, resourceType string, limit int) ([]*Resource, error)
+}
+
+type controller struct {
+	collectionMgr  collectionmanager.Manager
+	recentVisitMgr recentvisitmanager.Manager
+	applicationMgr appmanager.Manager
+	clusterMgr     clustermanager.Manager
+	groupSvc       groupsvc.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		collectionMgr:  param.CollectionMgr,
+		recentVisitMgr: param.RecentVisitMgr,
+		applicationMgr: param.ApplicationMgr,
+		clusterMgr:     param.ClusterMgr,
+		groupSvc:       param.GroupSvc,
+	}
+}
+
+func (c *controller) AddFavorite(ctx context.Context, resourceType string, resourceID uint) error {
+	const op = "favorite controller: add favorite"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateResourceType(resourceType, false); err != nil {
+		return err
+	}
+	// make sure the resource exists
+	if resourceType == common.ResourceApplication {
+		_, err = c.applicationMgr.GetByID(ctx, resourceID)
+	} else {
+		_, err = c.clusterMgr.GetByID(ctx, resourceID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = c.collectionMgr.Create(ctx, &collectionmodels.Collection{
+		ResourceID:   resourceID,
+		ResourceType: resourceType,
+		UserID:       currentUser.GetID(),
+	})
+	return err
+}
+
+func (c *controller) DeleteFavorite(ctx context.Context, resourceType string, resourceID uint) error {
+	const op = "favorite controller: delete favorite"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateResourceType(resourceType, false); err != nil {
+		return err
+	}
+
+	_, err = c.collectionMgr.DeleteByResource(ctx, currentUser.GetID(), resourceID, resourceType)
+	return err
+}
+
+func (c *controller) ListFavorites(ctx context.Context, resourceType string) ([]*Resource, error) {
+	const op = "favorite controller: list favorites"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateResourceType(resourceType, true); err != nil {
+		return nil, err
+	}
+
+	collections, err := c.collectionMgr.ListByUser(ctx, currentUser.GetID(), resourceType)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]reference, 0, len(collections))
+	for _, collection := range collections {
+		if collection.ResourceType != common.ResourceApplication &&
+			collection.ResourceType != common.ResourceCluster {
+			continue
+		}
+		refs = append(refs, reference{resourceType: collection.ResourceType, resourceID: collection.ResourceID})
+	}
+	return c.resolve(ctx, refs)
+}
+
+func (c *controller) ListRecentVisits(ctx context.Context, resourceType string, limit int) ([]*Resource, error) {
+	const op = "favorite controller: list recent visits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateResourceType(resourceType, true); err != nil {
+		return nil, err
+	}
+
+	visits, err := c.recentVisitMgr.List(ctx, currentUser.GetID(), resourceType, limit)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]reference, 0, len(visits))
+	for _, visit := range visits {
+		refs = append(refs, reference{
+			resourceType: visit.ResourceType,
+			resourceID:   visit.ResourceID,
+			visitedAt:    visit.VisitedAt,
+		})
+	}
+	return c.resolve(ctx, refs)
+}
+
+type reference struct {
+	resourceType string
+	resourceID   uint
+	visitedAt    time.Time
+}
+
+// resolve fills names and full paths of the referred resources in order,
+// the resources deleted since they were starred or visited are left out
+func (c *controller) resolve(ctx context.Context, refs []reference) ([]*Resource, error) {
+	clusters := make(map[uint]*clustermodels.Cluster)
+	var appIDs []uint
+	for _, ref := range refs {
+		switch ref.resourceType {
+		case common.ResourceApplication:
+			appIDs = append(appIDs, ref.resourceID)
+		case common.ResourceCluster:
+			if _, ok := clusters[ref.resourceID]; ok {
+				continue
+			}
+			cluster, err := c.clusterMgr.GetByID(ctx, ref.resourceID)
+			if err != nil {
+				if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+					continue
+				}
+				return nil, err
+			}
+			clusters[cluster.ID] = cluster
+			appIDs = append(appIDs, cluster.ApplicationID)
+		}
+	}
+
+	applications, err := c.applicationMgr.GetByIDs(ctx, appIDs)
+	if err != nil {
+		return nil, err
+	}
+	apps := make(map[uint]*appmodels.Application, len(applications))
+	groupIDs := make([]uint, 0, len(applications))
+	for _, application := range applications {
+		apps[application.ID] = application
+		groupIDs = append(groupIDs, application.GroupID)
+	}
+	groups, err := c.groupSvc.GetChildrenByIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	fullOfApp := func(application *appmodels.Application) (string, string) {
+		group, ok := groups[application.GroupID]
+		if !ok {
+			return application.Name, "/" + application.Name
+		}
+		return group.FullName + "/" + application.Name, group.FullPath + "/" + application.Name
+	}
+
+	resources := make([]*Resource, 0, len(refs))
+	for _, ref := range refs {
+		resource := &Resource{
+			ResourceType: ref.resourceType,
+			ID:           ref.resourceID,
+		}
+		switch ref.resourceType {
+		case common.ResourceApplication:
+			application, ok := apps[ref.resourceID]
+			if !ok {
+				continue
+			}
+			resource.Name = application.Name
+			resource.FullName, resource.FullPath = fullOfApp(application)
+		case common.ResourceCluster:
+			cluster, ok := clusters[ref.resourceID]
+			if !ok {
+				continue
+			}
+			application, ok := apps[cluster.ApplicationID]
+			if !ok {
+				continue
+			}
+			fullName, fullPath := fullOfApp(application)
+			resource.Name = cluster.Name
+			resource.FullName = fullName + "/" + cluster.Name
+			resource.FullPath = fullPath + "/" + cluster.Name
+		}
+		if !ref.visitedAt.IsZero() {
+			visitedAt := ref.visitedAt
+			resource.VisitedAt = &visitedAt
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// validateResourceType checks that the resource type is applications or clusters,
+// an empty one is allowed for listing both
+func validateResourceType(resourceType string, allowEmpty bool) error {
+	switch resourceType {
+	case common.ResourceApplication, common.ResourceCluster:
+		return nil
+	case "":
+		if allowEmpty {
+			return nil
+		}
+	}
+	return perror.Wrapf(herrors.ErrParamInvalid, "unsupported resource type: %s", resourceType)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package favorite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	collectionmodels "github.com/horizoncd/horizon/pkg/collection/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	recentvisitmodels "github.com/horizoncd/horizon/pkg/recentvisit/models"
+)
+
+func TestFavoritesAndRecentVisits(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&collectionmodels.Collection{}, &recentvisitmodels.RecentVisit{}))
+	manager := managerparam.InitManager(db)
+	ctl := NewController(&param.Param{Manager: manager, GroupSvc: groupsvc.NewService(manager)})
+
+	group := &groupmodels.Group{Name: "Shop", Path: "shop"}
+	assert.Nil(t, db.Create(group).Error)
+	assert.Nil(t, db.Model(group).Update("traversal_ids", "1").Error)
+	app := &appmodels.Application{Name: "cart", GroupID: group.ID}
+	assert.Nil(t, db.Create(app).Error)
+	cluster := &clustermodels.Cluster{Name: "cart-online", ApplicationID: app.ID}
+	assert.Nil(t, db.Create(cluster).Error)
+
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{ID: 1})
+
+	assert.Nil(t, ctl.AddFavorite(ctx, common.ResourceApplication, app.ID))
+	assert.Nil(t, ctl.AddFavorite(ctx, common.ResourceCluster, cluster.ID))
+	// starring twice is fine
+	assert.Nil(t, ctl.AddFavorite(ctx, common.ResourceCluster, cluster.ID))
+	err = ctl.AddFavorite(ctx, common.ResourceApplication, 100)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	err = ctl.AddFavorite(ctx, common.ResourceGroup, group.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	favorites, err := ctl.ListFavorites(ctx, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(favorites))
+	assert.Equal(t, common.ResourceCluster, favorites[0].ResourceType)
+	assert.Equal(t, "Shop/cart/cart-online", favorites[0].FullName)
+	assert.Equal(t, "/shop/cart/cart-online", favorites[0].FullPath)
+	assert.Equal(t, "/shop/cart", favorites[1].FullPath)
+	assert.Nil(t, favorites[1].VisitedAt)
+
+	assert.Nil(t, ctl.DeleteFavorite(ctx, common.ResourceCluster, cluster.ID))
+	favorites, err = ctl.ListFavorites(ctx, common.ResourceCluster)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(favorites))
+
+	assert.Nil(t, manager.RecentVisitMgr.Record(ctx, 1, common.ResourceCluster, cluster.ID))
+	assert.Nil(t, manager.RecentVisitMgr.Record(ctx, 1, common.ResourceApplication, app.ID))
+	// the deleted resources are left out
+	assert.Nil(t, manager.RecentVisitMgr.Record(ctx, 1, common.ResourceCluster, 100))
+	visits, err := ctl.ListRecentVisits(ctx, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(visits))
+	assert.Equal(t, common.ResourceApplication, visits[0].ResourceType)
+	assert.NotNil(t, visits[0].VisitedAt)
+	assert.Equal(t, "/shop/cart/cart-online", visits[1].FullPath)
+
+	visits, err = ctl.ListRecentVisits(ctx, common.ResourceCluster, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(visits))
+	assert.Equal(t, cluster.ID, visits[0].ID)
+}
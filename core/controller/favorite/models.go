@@ -0,0 +1,28 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package favorite
+
+import "time"
+
+// Resource is an application or a cluster favorited or recently visited by current user
+type Resource struct {
+	ResourceType string `json:"resourceType"`
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	FullName     string `json:"fullName"`
+	FullPath     string `json:"fullPath"`
+	// VisitedAt is the last time current user visited the resource, only set for the recent visits
+	VisitedAt *time.Time `json:"visitedAt,omitempty"`
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitopscache
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/horizoncd/horizon/core/config"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/gitopscache"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Invalidate drops the files cached of the projects in the event sent by gitlab webhooks
+	Invalidate(ctx context.Context, token string, event *Event) error
+}
+
+type controller struct {
+	webhookToken string
+	store        gitopscache.Store
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(config *config.Config, param *param.Param) Controller {
+	return &controller{
+		webhookToken: config.GitopsRepoConfig.Cache.WebhookToken,
+		store:        param.GitopsCache,
+	}
+}
+
+func (c *controller) Invalidate(ctx context.Context, token string, event *Event) error {
+	const op = "gitops cache controller: invalidate"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if c.webhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(c.webhookToken)) != 1 {
+		return perror.Wrap(herrors.ErrTokenInvalid, "token of gitops webhook is invalid")
+	}
+	// events are accepted and ignored if the cache is disabled, so that gitlab does not disable the webhook
+	if c.store == nil {
+		return nil
+	}
+	for _, repo := range event.projects() {
+		log.Debugf(ctx, "files cached of %s are invalidated by gitlab webhook", repo)
+		c.store.Invalidate(ctx, repo)
+	}
+	return nil
+}
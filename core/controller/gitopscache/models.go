@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitopscache
+
+// Event is the part of the events of gitlab webhooks identifying the projects changed,
+// it's project.path_with_namespace in the events of project webhooks such as push and merge request,
+// or path_with_namespace and old_path_with_namespace in the events of system hooks
+type Event struct {
+	// ObjectKind is the kind of the events of project webhooks
+	ObjectKind string `json:"object_kind"`
+	// EventName is the name of the events of system hooks
+	EventName            string        `json:"event_name"`
+	Project              *EventProject `json:"project"`
+	PathWithNamespace    string        `json:"path_with_namespace"`
+	OldPathWithNamespace string        `json:"old_path_with_namespace"`
+}
+
+type EventProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+func (e *Event) projects() []string {
+	projects := make([]string, 0)
+	for _, project := range []string{e.PathWithNamespace, e.OldPathWithNamespace} {
+		if project != "" {
+			projects = append(projects, project)
+		}
+	}
+	if e.Project != nil && e.Project.PathWithNamespace != "" {
+		projects = append(projects, e.Project.PathWithNamespace)
+	}
+	return projects
+}
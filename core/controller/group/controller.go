@@ -16,6 +16,7 @@ package group
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"sort"
@@ -30,12 +31,18 @@ import (
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	"github.com/horizoncd/horizon/pkg/util/errors"
@@ -74,6 +81,9 @@ type Controller interface {
 	ListAuthedGroup(ctx context.Context) ([]*Group, error)
 	// UpdateRegionSelector update regionSelector
 	UpdateRegionSelector(ctx context.Context, id uint, regionSelector RegionSelectors) error
+	// GetTree get the tree of groups visible to current user in one response. Private groups are visible to
+	// admins and members of the groups or their ancestors, ancestors of visible groups are kept in the tree.
+	GetTree(ctx context.Context) ([]*GroupTreeNode, error)
 }
 
 type controller struct {
@@ -81,8 +91,11 @@ type controller struct {
 	applicationManager appmanager.Manager
 	clusterManager     clustermanager.Manager
 	memberSvc          memberservice.Service
+	memberManager      membermanager.Manager
 	templateMgr        tmanager.Manager
 	templateReleaseMgr trmanager.Manager
+	eventSvc           eventservice.Service
+	tagMgr             tagmanager.Manager
 }
 
 // NewController initializes a new group controller
@@ -92,8 +105,11 @@ func NewController(param *param.Param) Controller {
 		applicationManager: param.ApplicationMgr,
 		clusterManager:     param.ClusterMgr,
 		memberSvc:          param.MemberService,
+		memberManager:      param.MemberMgr,
 		templateMgr:        param.TemplateMgr,
 		templateReleaseMgr: param.TemplateReleaseMgr,
+		eventSvc:           param.EventSvc,
+		tagMgr:             param.TagMgr,
 	}
 }
 
@@ -162,7 +178,7 @@ func (c *controller) GetChildren(ctx context.Context, id uint, pageNumber, pageS
 
 // SearchGroups search subGroups of a group
 func (c *controller) SearchGroups(ctx context.Context, params *SearchParams) ([]*service.Child, int64, error) {
-	if params.Filter == "" {
+	if params.Filter == "" && len(params.TagSelectors) == 0 {
 		return c.GetSubGroups(ctx, params.GroupID, params.PageNumber, params.PageSize)
 	}
 
@@ -177,7 +193,13 @@ func (c *controller) SearchGroups(ctx context.Context, params *SearchParams) ([]
 	if err != nil {
 		return nil, 0, err
 	}
-	if matchedGroups == nil {
+	if len(params.TagSelectors) > 0 {
+		matchedGroups, err = c.filterGroupsByTagSelectors(ctx, matchedGroups, params.TagSelectors)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	if len(matchedGroups) == 0 {
 		return []*service.Child{}, 0, nil
 	}
 
@@ -197,6 +219,27 @@ func (c *controller) SearchGroups(ctx context.Context, params *SearchParams) ([]
 	return childrenWithLevelStruct, int64(len(childrenWithLevelStruct)), nil
 }
 
+// filterGroupsByTagSelectors keeps the groups whose tags match all the tagSelectors
+func (c *controller) filterGroupsByTagSelectors(ctx context.Context, groups []*models.Group,
+	tagSelectors []tagmodels.TagSelector) ([]*models.Group, error) {
+	ids, err := c.tagMgr.ListResourceIDsByTagSelectors(ctx, common.ResourceGroup, tagSelectors)
+	if err != nil {
+		return nil, err
+	}
+	matchedIDs := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		matchedIDs[id] = struct{}{}
+	}
+
+	filtered := make([]*models.Group, 0, len(groups))
+	for _, group := range groups {
+		if _, ok := matchedIDs[group.ID]; ok {
+			filtered = append(filtered, group)
+		}
+	}
+	return filtered, nil
+}
+
 // SearchChildren search children of a group, including subgroups and applications
 func (c *controller) SearchChildren(ctx context.Context, params *SearchParams) ([]*service.Child, int64, error) {
 	if params.Filter == "" {
@@ -322,11 +365,29 @@ func (c *controller) UpdateBasic(ctx context.Context, id uint, updateGroup *Upda
 
 // Transfer put a group under another parent group
 func (c *controller) Transfer(ctx context.Context, id, newParentID uint) error {
-	err := c.groupManager.Transfer(ctx, id, newParentID)
+	group, err := c.groupManager.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if group.ParentID == newParentID {
+		return nil
+	}
+
+	err = c.groupManager.Transfer(ctx, id, newParentID)
 	if err != nil {
 		return err
 	}
 
+	extra, err := json.Marshal(eventmodels.GroupTransfer{
+		FromParentID: group.ParentID,
+		ToParentID:   newParentID,
+	})
+	if err != nil {
+		return err
+	}
+	extraStr := string(extra)
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceGroup, id,
+		eventmodels.GroupTransferred, &extraStr)
 	return nil
 }
 
@@ -697,3 +758,62 @@ func (c *controller) UpdateRegionSelector(ctx context.Context, id uint, regionSe
 
 	return c.groupManager.UpdateRegionSelector(ctx, id, string(regionSelectorBytes))
 }
+
+// GetTree get the tree of groups visible to current user
+func (c *controller) GetTree(ctx context.Context) ([]*GroupTreeNode, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.groupManager.GetTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// groups which current user is a member of, nil means all the groups are visible
+	var memberOf map[uint]struct{}
+	if !currentUser.IsAdmin() {
+		members, err := c.memberManager.ListMembersByUserID(ctx, currentUser.GetID())
+		if err != nil {
+			return nil, err
+		}
+		memberOf = make(map[uint]struct{})
+		for _, m := range members {
+			if m.ResourceType == membermodels.TypeGroup {
+				memberOf[m.ResourceID] = struct{}{}
+			}
+		}
+	}
+
+	var build func(parent *GroupTreeNode, granted bool) []*GroupTreeNode
+	build = func(parent *GroupTreeNode, granted bool) []*GroupTreeNode {
+		nodes := make([]*GroupTreeNode, 0)
+		for _, group := range tree.Children[parent.ID] {
+			groupGranted := granted
+			if !groupGranted {
+				_, groupGranted = memberOf[group.ID]
+			}
+			node := &GroupTreeNode{
+				ID:              group.ID,
+				Name:            group.Name,
+				Path:            group.Path,
+				VisibilityLevel: group.VisibilityLevel,
+				Description:     group.Description,
+				ParentID:        group.ParentID,
+				FullName:        group.Name,
+				FullPath:        fmt.Sprintf("/%s", group.Path),
+			}
+			if parent.ID != 0 {
+				node.FullName = fmt.Sprintf("%s/%s", parent.FullName, group.Name)
+				node.FullPath = fmt.Sprintf("%s/%s", parent.FullPath, group.Path)
+			}
+			node.Children = build(node, groupGranted)
+			node.ChildrenCount = len(node.Children)
+			if groupGranted || group.VisibilityLevel != VisibilityPrivate || node.ChildrenCount > 0 {
+				nodes = append(nodes, node)
+			}
+		}
+		return nodes
+	}
+	return build(&GroupTreeNode{}, memberOf == nil), nil
+}
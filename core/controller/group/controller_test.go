@@ -27,14 +27,19 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	groupmanagermock "github.com/horizoncd/horizon/mock/pkg/group/manager"
+	membermanagermock "github.com/horizoncd/horizon/mock/pkg/member/manager"
 	membermock "github.com/horizoncd/horizon/mock/pkg/member/service"
 	templatemock "github.com/horizoncd/horizon/mock/pkg/template/manager"
 	releasemanagermock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
 	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
@@ -52,7 +57,7 @@ var (
 	db, _    = orm.NewSqliteDB("")
 	ctx      = context.TODO()
 	manager  = managerparam.InitManager(db)
-	groupCtl = NewController(&param.Param{Manager: manager})
+	groupCtl = NewController(&param.Param{Manager: manager, EventSvc: eventservice.New(manager)})
 )
 
 func GroupValueEqual(g1, g2 *models.Group) bool {
@@ -92,6 +97,11 @@ func init() {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
 	}
+	err = db.AutoMigrate(&eventmodels.Event{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
 
 	callbacks.RegisterCustomCallbacks(db)
 }
@@ -1222,6 +1232,23 @@ func TestControllerTransfer(t *testing.T) {
 	assert.Equal(t, "/c/a/b", group2.FullPath)
 	assert.Equal(t, strconv.Itoa(int(id3))+","+strconv.Itoa(int(id))+","+strconv.Itoa(int(id2)), group2.TraversalIDs)
 
+	events, _, err := manager.EventMgr.ListEventsByResource(ctx, common.ResourceGroup, id, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, eventmodels.GroupTransferred, events[0].EventType)
+
+	// a group can not be transferred under its sub groups
+	err = groupCtl.Transfer(ctx, id, id2)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// transfer to the root
+	err = groupCtl.Transfer(ctx, id, 0)
+	assert.Nil(t, err)
+	group2, err = groupCtl.GetByID(ctx, id2)
+	assert.Nil(t, err)
+	assert.Equal(t, "/a/b", group2.FullPath)
+	assert.Equal(t, strconv.Itoa(int(id))+","+strconv.Itoa(int(id2)), group2.TraversalIDs)
+
 	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
 }
 
@@ -1458,3 +1485,66 @@ func TestGenerateChildrenWithLevelStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestControllerGetTree(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	groupManager := groupmanagermock.NewMockManager(mockCtl)
+	memberManager := membermanagermock.NewMockManager(mockCtl)
+	ctl := &controller{
+		groupManager:  groupManager,
+		memberManager: memberManager,
+	}
+
+	newGroup := func(id, parentID uint, name, visibility string) *models.Group {
+		return &models.Group{
+			Model:           global.Model{ID: id},
+			Name:            name,
+			Path:            name,
+			VisibilityLevel: visibility,
+			ParentID:        parentID,
+		}
+	}
+	tree := models.NewGroupTree([]*models.Group{
+		newGroup(1, 0, "a", VisibilityPrivate),
+		newGroup(2, 1, "b", VisibilityPrivate),
+		newGroup(3, 2, "c", VisibilityPrivate),
+		newGroup(4, 0, "d", "public"),
+		newGroup(5, 0, "e", VisibilityPrivate),
+		newGroup(6, 5, "f", "public"),
+		newGroup(7, 0, "g", VisibilityPrivate),
+	})
+	groupManager.EXPECT().GetTree(gomock.Any()).Return(tree, nil).Times(2)
+
+	// names of the nodes in preorder
+	var names func(nodes []*GroupTreeNode) []string
+	names = func(nodes []*GroupTreeNode) []string {
+		ret := make([]string, 0)
+		for _, node := range nodes {
+			ret = append(ret, node.FullPath)
+			ret = append(ret, names(node.Children)...)
+		}
+		return ret
+	}
+
+	// members see the subgroups of their groups, public groups and the ancestors
+	memberManager.EXPECT().ListMembersByUserID(gomock.Any(), uint(110)).Return([]membermodels.Member{
+		{ResourceType: membermodels.TypeGroup, ResourceID: 2},
+		{ResourceType: membermodels.TypeApplication, ResourceID: 7},
+	}, nil)
+	nodes, err := ctl.GetTree(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/a", "/a/b", "/a/b/c", "/d", "/e", "/e/f"}, names(nodes))
+	assert.Equal(t, "a/b", nodes[0].Children[0].FullName)
+	assert.Equal(t, 1, nodes[0].ChildrenCount)
+
+	// admins see all the groups
+	adminCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    1,
+		Admin: true,
+	})
+	nodes, err = ctl.GetTree(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/a", "/a/b", "/a/b/c", "/d", "/e", "/e/f", "/g"}, names(nodes))
+}
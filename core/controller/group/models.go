@@ -16,12 +16,14 @@ package group
 
 import (
 	"time"
+
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 )
 
 // NewGroup model for creating a group
 type NewGroup struct {
-	Name            string `json:"name" binding:"required"`
-	Path            string `json:"path" binding:"required"`
+	Name            string `json:"name" binding:"required,max=128"`
+	Path            string `json:"path" binding:"required,max=32,grouppath"`
 	VisibilityLevel string `json:"visibilityLevel"`
 	Description     string `json:"description"`
 	ParentID        uint   `json:"parentID"`
@@ -29,18 +31,19 @@ type NewGroup struct {
 
 // UpdateGroup model for updating a group
 type UpdateGroup struct {
-	Name            string `json:"name" binding:"required"`
-	Path            string `json:"path" binding:"required"`
+	Name            string `json:"name" binding:"required,max=128"`
+	Path            string `json:"path" binding:"required,max=32,grouppath"`
 	VisibilityLevel string `json:"visibilityLevel"`
 	Description     string `json:"description"`
 }
 
 // SearchParams contains parameters for searching operation
 type SearchParams struct {
-	Filter     string
-	GroupID    uint
-	PageNumber int
-	PageSize   int
+	Filter       string
+	GroupID      uint
+	PageNumber   int
+	PageSize     int
+	TagSelectors []tagmodels.TagSelector
 }
 
 // Group basic info of group, including group & application
@@ -69,3 +72,21 @@ type StructuredGroup struct {
 	*Group
 	RegionSelectors RegionSelectors `json:"regionSelectors"`
 }
+
+// VisibilityPrivate private groups are only visible to their members and members of their ancestors
+const VisibilityPrivate = "private"
+
+// GroupTreeNode a group and its subgroups visible to the current user
+type GroupTreeNode struct {
+	ID              uint   `json:"id"`
+	Name            string `json:"name"`
+	Path            string `json:"path"`
+	VisibilityLevel string `json:"visibilityLevel"`
+	Description     string `json:"description"`
+	ParentID        uint   `json:"parentID"`
+	FullName        string `json:"fullName"`
+	FullPath        string `json:"fullPath"`
+	// ChildrenCount is the count of visible subgroups
+	ChildrenCount int              `json:"childrenCount"`
+	Children      []*GroupTreeNode `json:"children,omitempty"`
+}
@@ -25,8 +25,10 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	idpconst "github.com/horizoncd/horizon/core/common/idp"
+	"github.com/horizoncd/horizon/core/config"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
+	idpconfig "github.com/horizoncd/horizon/pkg/config/idp"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/idp/manager"
 	"github.com/horizoncd/horizon/pkg/idp/utils"
@@ -56,13 +58,15 @@ type controller struct {
 	idpManager  manager.Manager
 	userManager usermanager.Manager
 	linkManager linkmanager.Manager
+	idpConfig   idpconfig.Config
 }
 
-func NewController(param *param.Param) Controller {
+func NewController(config *config.Config, param *param.Param) Controller {
 	return &controller{
 		idpManager:  param.IdpMgr,
 		userManager: param.UserMgr,
 		linkManager: param.UserLinksMgr,
+		idpConfig:   config.IDPConfig,
 	}
 }
 
@@ -135,8 +139,9 @@ func (c *controller) LoginOrLink(ctx context.Context,
 		return nil, err
 	}
 
+	mapping := c.idpConfig.ClaimsMappings[idp.Name]
 	var claims *utils.Claims
-	claims, err = utils.HandleOIDC(ctx, idp, code, redirectURL)
+	claims, err = utils.HandleOIDC(ctx, idp, mapping, code, redirectURL)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +164,10 @@ func (c *controller) LoginOrLink(ctx context.Context,
 				return nil, err
 			}
 			// for register
+			if !mapping.CanProvision(claims.Groups) {
+				return nil, perror.Wrapf(herrors.ErrForbidden,
+					"user %s is not allowed to be registered by %s", claims.Email, idp.Name)
+			}
 			name := strings.SplitN(claims.Email, "@", 2)[0]
 			if claims.Name == "" {
 				claims.Name = name
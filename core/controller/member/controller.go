@@ -19,10 +19,18 @@ import (
 	"strconv"
 
 	"github.com/horizoncd/horizon/core/common"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param"
+	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 )
 
 type Controller interface {
@@ -36,21 +44,40 @@ type Controller interface {
 	ListMember(ctx context.Context, resourceType string, resourceID uint) ([]Member, error)
 	// GetMemberOfResource get the member of the group by user info in ctx
 	GetMemberOfResource(ctx context.Context, resourceType string, resourceID uint) (*Member, error)
+	// ExplainPermission explains the effective role of the user on the group, application or cluster,
+	// along with the members of the user on the resource and its parents and the scopes of the user's tokens
+	ExplainPermission(ctx context.Context, resourceType string, resourceID uint, userID uint) (*Permission, error)
 }
 
 // NewController initializes a new group controller
 func NewController(param *param.Param) Controller {
 	return &controller{
-		memberService: param.MemberService,
-		convertHelper: New(param),
-		eventSvc:      param.EventSvc,
+		memberService:  param.MemberService,
+		convertHelper:  New(param),
+		eventSvc:       param.EventSvc,
+		memberMgr:      param.MemberMgr,
+		groupMgr:       param.GroupMgr,
+		applicationMgr: param.ApplicationMgr,
+		clusterMgr:     param.ClusterMgr,
+		userMgr:        param.UserMgr,
+		tokenMgr:       param.TokenMgr,
+		roleService:    param.RoleService,
+		scopeService:   param.ScopeService,
 	}
 }
 
 type controller struct {
-	memberService memberservice.Service
-	convertHelper ConvertMemberHelp
-	eventSvc      eventservice.Service
+	memberService  memberservice.Service
+	convertHelper  ConvertMemberHelp
+	eventSvc       eventservice.Service
+	memberMgr      membermanager.Manager
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+	userMgr        usermanager.Manager
+	tokenMgr       tokenmanager.Manager
+	roleService    roleservice.Service
+	scopeService   scope.Service
 }
 
 func (c *controller) CreateMember(ctx context.Context, postMember *PostMember) (*Member, error) {
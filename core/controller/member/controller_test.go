@@ -17,6 +17,7 @@ package member
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
@@ -32,16 +33,21 @@ import (
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
+	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	scopeservice "github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
 )
 
@@ -329,3 +335,109 @@ func TestTemplateMember(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, createMemberParam.Role, member.Role)
 }
+
+func TestExplainPermission(t *testing.T) {
+	createContext(t)
+	err := db.AutoMigrate(&tokenmodels.Token{})
+	assert.Nil(t, err)
+	CreateUsers(t)
+
+	rules := []types.PolicyRule{{
+		Verbs:     []string{"get"},
+		APIGroups: []string{"core"},
+		Resources: []string{"groups"},
+		Scopes:    []string{"*"},
+	}}
+	roleSvc, err := roleservice.NewFileRoleFrom2(ctx, roleconfig.Config{
+		RolePriorityRankDesc: []string{roleservice.Owner, "maintainer", "guest"},
+		DefaultRole:          "guest",
+		Roles: []types.Role{
+			{Name: roleservice.Owner, PolicyRules: rules},
+			{Name: "maintainer", PolicyRules: rules},
+			{Name: "guest", PolicyRules: rules},
+		},
+	})
+	assert.Nil(t, err)
+	scopeSvc, err := scopeservice.NewFileScopeService(oauthconfig.Scopes{
+		Roles: []types.Role{{Name: "groups:read-only", PolicyRules: rules}},
+	})
+	assert.Nil(t, err)
+	ctl := NewController(&param.Param{
+		MemberService:  memberservice.NewService(roleSvc, nil, manager),
+		Manager:        manager,
+		GroupSvc:       groupSvc,
+		ApplicationSvc: applicationSvc,
+		ClusterSvc:     clusterSvc,
+		EventSvc:       eventSvc,
+		RoleService:    roleSvc,
+		ScopeService:   scopeSvc,
+	})
+
+	parentID, err := groupCtl.CreateGroup(ctx, &group.NewGroup{
+		Name:            "parent",
+		Path:            "parent",
+		VisibilityLevel: "private",
+	})
+	assert.Nil(t, err)
+	childID, err := groupCtl.CreateGroup(ctx, &group.NewGroup{
+		Name:            "child",
+		Path:            "child",
+		VisibilityLevel: "private",
+		ParentID:        parentID,
+	})
+	assert.Nil(t, err)
+	_, err = ctl.CreateMember(ctx, &PostMember{
+		ResourceType: common.ResourceGroup,
+		ResourceID:   parentID,
+		MemberNameID: user2ID,
+		MemberType:   membermodels.MemberUser,
+		Role:         "maintainer",
+	})
+	assert.Nil(t, err)
+	_, err = manager.TokenMgr.CreateToken(ctx, &tokenmodels.Token{
+		Name:      "ci",
+		Code:      "ha_ci",
+		Scope:     "groups:read-only",
+		CreatedAt: time.Now(),
+		ExpiresIn: time.Hour,
+		UserID:    user2ID,
+	})
+	assert.Nil(t, err)
+
+	// the role granted on the parent group is inherited by the child group
+	permission, err := ctl.ExplainPermission(ctx, common.ResourceGroup, childID, user2ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "maintainer", permission.Role)
+	assert.Equal(t, PermissionSourceInherited, permission.Source)
+	assert.Equal(t, rules, permission.Rules)
+	assert.Equal(t, 2, len(permission.Chain))
+	assert.Equal(t, childID, permission.Chain[0].ResourceID)
+	assert.False(t, permission.Chain[0].Effective)
+	assert.Equal(t, parentID, permission.Chain[1].ResourceID)
+	assert.True(t, permission.Chain[1].Effective)
+	assert.Equal(t, 1, len(permission.Tokens))
+	assert.Equal(t, []string{"groups:read-only"}, permission.Tokens[0].Scopes)
+	assert.Equal(t, rules, permission.Tokens[0].Rules)
+	assert.NotNil(t, permission.Tokens[0].ExpiresAt)
+
+	// the role granted on the resource itself takes precedence
+	permission, err = ctl.ExplainPermission(ctx, common.ResourceGroup, childID, user1ID)
+	assert.Nil(t, err)
+	assert.Equal(t, roleservice.Owner, permission.Role)
+	assert.Equal(t, PermissionSourceDirect, permission.Source)
+
+	// users who are not members fall back to the default role
+	permission, err = ctl.ExplainPermission(ctx, common.ResourceGroup, childID, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, "guest", permission.Role)
+	assert.Equal(t, PermissionSourceDefault, permission.Source)
+	assert.Equal(t, 0, len(permission.Tokens))
+
+	_, err = ctl.ExplainPermission(ctx, common.ResourceTemplate, childID, user2ID)
+	assert.NotNil(t, err)
+
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&membermodels.Member{})
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&usermodel.User{})
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&tokenmodels.Token{})
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -49,6 +50,8 @@ type PostMember struct {
 
 	// MemberNameID group id / userid
 	MemberNameID uint `json:"memberNameID"`
+	// Robot tells whether the member is the user of a robot
+	Robot bool `json:"robot"`
 
 	// Role owner/maintainer/develop/...
 	Role string `json:"role"`
@@ -146,6 +149,7 @@ func (c *converter) ConvertMember(ctx context.Context, member *models.Member) (_
 		Role:         member.Role,
 		GrantedBy:    member.GrantedBy,
 		GrantTime:    member.UpdatedAt,
+		Robot:        user.UserType == usermodels.UserTypeRobot,
 	}, nil
 }
 func (c *converter) ConvertMembers(ctx context.Context, members []models.Member) ([]Member, error) {
@@ -162,8 +166,12 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 		return nil, err
 	}
 	userIDToName := make(map[uint]string)
+	robotUserIDs := make(map[uint]bool)
 	for _, userItem := range users {
 		userIDToName[userItem.ID] = userItem.Name
+		if userItem.UserType == usermodels.UserTypeRobot {
+			robotUserIDs[userItem.ID] = true
+		}
 	}
 	var retMembers []Member
 	for _, member := range members {
@@ -216,6 +224,7 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 			MemberType:   member.MemberType,
 			MemberName:   userIDToName[member.MemberNameID],
 			MemberNameID: member.MemberNameID,
+			Robot:        robotUserIDs[member.MemberNameID],
 			ResourceType: member.ResourceType,
 			ResourceID:   member.ResourceID,
 			ResourceName: resourceName,
@@ -228,3 +237,53 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 	}
 	return retMembers, nil
 }
+
+// sources of the effective role of a user
+const (
+	// PermissionSourceDirect means the user is a member of the resource itself
+	PermissionSourceDirect = "direct"
+	// PermissionSourceInherited means the user is a member of a parent of the resource
+	PermissionSourceInherited = "inherited"
+	// PermissionSourceDefault means the user is not a member, the default role is taken
+	PermissionSourceDefault = "default"
+)
+
+// Permission explains the permissions of a user on a resource and where they come from
+type Permission struct {
+	UserID   uint   `json:"userID"`
+	UserName string `json:"userName"`
+	// Admin users are allowed everything regardless of their roles
+	Admin bool `json:"admin"`
+	// Auditor users read the resources listed in the auditor role regardless of their roles
+	Auditor bool `json:"auditor"`
+	// Role is the effective role of the user on the resource, empty if there's no default role
+	Role string `json:"role"`
+	// Source is where the role comes from, direct, inherited or default
+	Source string `json:"source"`
+	// Rules are the policy rules of the role
+	Rules []types.PolicyRule `json:"rules"`
+	// Chain lists the resource and its parents from the nearest to the root, the role granted
+	// on the nearest one is effective
+	Chain []*PermissionLink `json:"chain"`
+	// Tokens are the access tokens of the user, requests with them are further limited to their scopes
+	Tokens []*TokenScope `json:"tokens"`
+}
+
+type PermissionLink struct {
+	ResourceType string `json:"resourceType"`
+	ResourceID   uint   `json:"resourceID"`
+	ResourceName string `json:"resourceName"`
+	// Role is granted to the user on the resource directly, empty if the user is not a member of it
+	Role      string `json:"role,omitempty"`
+	GrantedBy uint   `json:"grantedBy,omitempty"`
+	Effective bool   `json:"effective"`
+}
+
+type TokenScope struct {
+	ID     uint     `json:"id"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// Rules are the policy rules of the scopes
+	Rules     []types.PolicyRule `json:"rules"`
+	ExpiresAt *time.Time         `json:"expiresAt,omitempty"`
+}
@@ -0,0 +1,169 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ExplainPermission(ctx context.Context, resourceType string,
+	resourceID uint, userID uint) (*Permission, error) {
+	const op = "member controller: explain permission"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	user, err := c.userMgr.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := c.chainOf(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	permission := &Permission{
+		UserID:   user.ID,
+		UserName: user.Name,
+		Admin:    user.Admin,
+		Auditor:  user.Auditor,
+		Rules:    make([]types.PolicyRule, 0),
+		Chain:    chain,
+		Tokens:   make([]*TokenScope, 0),
+	}
+	// the role granted on the nearest resource is effective, the same as how members are listed
+	for i, link := range chain {
+		members, err := c.memberMgr.ListDirectMember(ctx, membermodels.ResourceType(link.ResourceType),
+			link.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if member.MemberType != membermodels.MemberUser || member.MemberNameID != userID {
+				continue
+			}
+			link.Role = member.Role
+			link.GrantedBy = member.GrantedBy
+			if permission.Role == "" {
+				link.Effective = true
+				permission.Role = member.Role
+				permission.Source = PermissionSourceInherited
+				if i == 0 {
+					permission.Source = PermissionSourceDirect
+				}
+			}
+			break
+		}
+	}
+	if permission.Role == "" {
+		if defaultRole := c.roleService.GetDefaultRole(ctx); defaultRole != nil {
+			permission.Role = defaultRole.Name
+			permission.Source = PermissionSourceDefault
+		}
+	}
+	if permission.Role != "" {
+		role, err := c.roleService.GetRole(ctx, permission.Role)
+		if err != nil {
+			return nil, perror.Wrapf(err, "failed to get role %s", permission.Role)
+		}
+		permission.Rules = append(permission.Rules, role.PolicyRules...)
+	}
+
+	tokens, err := c.tokenMgr.ListAccessTokensByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		tokenScope := &TokenScope{
+			ID:     token.ID,
+			Name:   token.Name,
+			Scopes: strings.Fields(token.Scope),
+			Rules:  make([]types.PolicyRule, 0),
+		}
+		for _, scopeRole := range c.scopeService.GetRulesByScope(tokenScope.Scopes) {
+			tokenScope.Rules = append(tokenScope.Rules, scopeRole.PolicyRules...)
+		}
+		if token.ExpiresIn > 0 {
+			expiresAt := token.CreatedAt.Add(token.ExpiresIn)
+			tokenScope.ExpiresAt = &expiresAt
+		}
+		permission.Tokens = append(permission.Tokens, tokenScope)
+	}
+	return permission, nil
+}
+
+// chainOf returns the resource and its parents from the nearest to the root group
+func (c *controller) chainOf(ctx context.Context, resourceType string, resourceID uint) ([]*PermissionLink, error) {
+	var chain []*PermissionLink
+	switch resourceType {
+	case common.ResourceCluster:
+		cluster, err := c.clusterMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, &PermissionLink{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   cluster.ID,
+			ResourceName: cluster.Name,
+		})
+		resourceID = cluster.ApplicationID
+		fallthrough
+	case common.ResourceApplication:
+		application, err := c.applicationMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, &PermissionLink{
+			ResourceType: common.ResourceApplication,
+			ResourceID:   application.ID,
+			ResourceName: application.Name,
+		})
+		resourceID = application.GroupID
+		fallthrough
+	case common.ResourceGroup:
+		group, err := c.groupMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+		groups, err := c.groupMgr.GetByIDs(ctx, groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[uint]string, len(groups))
+		for _, group := range groups {
+			names[group.ID] = group.Name
+		}
+		for i := len(groupIDs) - 1; i >= 0; i-- {
+			chain = append(chain, &PermissionLink{
+				ResourceType: common.ResourceGroup,
+				ResourceID:   groupIDs[i],
+				ResourceName: names[groupIDs[i]],
+			})
+		}
+	default:
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("permissions of %s are not supported", resourceType))
+	}
+	return chain, nil
+}
@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	metadataconfig "github.com/horizoncd/horizon/pkg/config/metadata"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// GetFields returns the metadata fields defined for applications and clusters
+	GetFields(ctx context.Context) metadataconfig.Config
+	// Get returns the metadata of an application or a cluster
+	Get(ctx context.Context, resourceType string, resourceID uint) (map[string]string, error)
+	// Update replaces the metadata of an application or a cluster
+	Update(ctx context.Context, resourceType string, resourceID uint, r *UpdateRequest) error
+}
+
+type UpdateRequest struct {
+	Metadata map[string]string `json:"metadata"`
+}
+
+type controller struct {
+	metadataSvc    metadataservice.Service
+	applicationMgr appmanager.Manager
+	clusterMgr     clustermanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		metadataSvc:    param.MetadataSvc,
+		applicationMgr: param.ApplicationMgr,
+		clusterMgr:     param.ClusterMgr,
+	}
+}
+
+func (c *controller) GetFields(ctx context.Context) metadataconfig.Config {
+	return c.metadataSvc.Fields()
+}
+
+func (c *controller) Get(ctx context.Context, resourceType string, resourceID uint) (map[string]string, error) {
+	const op = "metadata controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	return c.metadataSvc.Get(ctx, resourceType, resourceID)
+}
+
+func (c *controller) Update(ctx context.Context, resourceType string, resourceID uint, r *UpdateRequest) error {
+	const op = "metadata controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return err
+	}
+	return c.metadataSvc.Update(ctx, resourceType, resourceID, r.Metadata)
+}
+
+// checkResource returns a not found error if the application or cluster does not exist
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	var err error
+	switch resourceType {
+	case common.ResourceApplication:
+		_, err = c.applicationMgr.GetByID(ctx, resourceID)
+	case common.ResourceCluster:
+		_, err = c.clusterMgr.GetByID(ctx, resourceID)
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/migration"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Status reports the schema version of the database and the migrations pending,
+	// only admins and auditors can get it
+	Status(ctx context.Context) (*migration.Status, error)
+}
+
+type controller struct {
+	// runner is nil if the migrations could not be loaded
+	runner *migration.Runner
+}
+
+func NewController(runner *migration.Runner) Controller {
+	return &controller{runner: runner}
+}
+
+func (c *controller) Status(ctx context.Context) (*migration.Status, error) {
+	const op = "migration controller: status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() && !currentUser.IsAuditor() {
+		return nil, perror.Wrap(herrors.ErrNoPrivilege, "could not get migration status\n"+
+			"should be admin or auditor")
+	}
+	if c.runner == nil {
+		return nil, perror.New("migrations are not loaded, check the -migrations flag of the server")
+	}
+	return c.runner.Status(ctx)
+}
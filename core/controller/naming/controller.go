@@ -0,0 +1,59 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package naming
+
+import (
+	"context"
+
+	namingconfig "github.com/horizoncd/horizon/pkg/config/naming"
+	"github.com/horizoncd/horizon/pkg/naming"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	GetPolicies(ctx context.Context) namingconfig.Config
+	Preview(ctx context.Context, kind, name string) (*PreviewResponse, error)
+}
+
+type controller struct {
+	namingSvc naming.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{namingSvc: param.NamingSvc}
+}
+
+func (c *controller) GetPolicies(ctx context.Context) namingconfig.Config {
+	return c.namingSvc.Policies()
+}
+
+func (c *controller) Preview(ctx context.Context, kind, name string) (*PreviewResponse, error) {
+	const op = "naming controller: preview"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	violations, err := c.namingSvc.Preview(ctx, naming.Kind(kind), name)
+	if err != nil {
+		return nil, err
+	}
+	return &PreviewResponse{
+		Kind:       kind,
+		Name:       name,
+		Valid:      len(violations) == 0,
+		Violations: violations,
+	}, nil
+}
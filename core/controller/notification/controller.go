@@ -0,0 +1,219 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	notificationmanager "github.com/horizoncd/horizon/pkg/notification/manager"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	CreateChannel(ctx context.Context, resourceType string, resourceID uint,
+		request *CreateChannelRequest) (*Channel, error)
+	ListChannels(ctx context.Context, resourceType string, resourceID uint) ([]*Channel, error)
+	UpdateChannel(ctx context.Context, id uint, request *UpdateChannelRequest) (*Channel, error)
+	DeleteChannel(ctx context.Context, id uint) error
+	// ListSubscriptions lists the subscriptions of the current user
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+	// Subscribe subscribes the notifications of a resource for the current user,
+	// the triggers are replaced if the resource has been subscribed
+	Subscribe(ctx context.Context, request *SubscribeRequest) (*Subscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+}
+
+type controller struct {
+	notificationMgr notificationmanager.Manager
+	groupMgr        groupmanager.Manager
+	applicationMgr  applicationmanager.Manager
+	clusterMgr      clustermanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		notificationMgr: param.NotificationMgr,
+		groupMgr:        param.GroupMgr,
+		applicationMgr:  param.ApplicationMgr,
+		clusterMgr:      param.ClusterMgr,
+	}
+}
+
+func (c *controller) CreateChannel(ctx context.Context, resourceType string, resourceID uint,
+	request *CreateChannelRequest) (*Channel, error) {
+	const op = "notification controller: create channel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := request.validate(resourceType); err != nil {
+		return nil, err
+	}
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := request.toModel(resourceType, resourceID)
+	channel.CreatedBy = user.GetID()
+	channel.UpdatedBy = user.GetID()
+	channel, err = c.notificationMgr.CreateChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	return ofChannelModel(channel), nil
+}
+
+func (c *controller) ListChannels(ctx context.Context, resourceType string,
+	resourceID uint) ([]*Channel, error) {
+	const op = "notification controller: list channels"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	channels, err := c.notificationMgr.ListChannelsOfResources(ctx, map[string][]uint{
+		resourceType: {resourceID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Channel, 0, len(channels))
+	for _, channel := range channels {
+		result = append(result, ofChannelModel(channel))
+	}
+	return result, nil
+}
+
+func (c *controller) UpdateChannel(ctx context.Context, id uint,
+	request *UpdateChannelRequest) (*Channel, error) {
+	const op = "notification controller: update channel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	channel, err := c.notificationMgr.GetChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := request.validate(channel.Type); err != nil {
+		return nil, err
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channel = request.toModel(channel)
+	channel.UpdatedBy = user.GetID()
+	if _, err := c.notificationMgr.UpdateChannel(ctx, id, channel); err != nil {
+		return nil, err
+	}
+	return ofChannelModel(channel), nil
+}
+
+func (c *controller) DeleteChannel(ctx context.Context, id uint) error {
+	const op = "notification controller: delete channel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.notificationMgr.GetChannel(ctx, id); err != nil {
+		return err
+	}
+	return c.notificationMgr.DeleteChannel(ctx, id)
+}
+
+func (c *controller) ListSubscriptions(ctx context.Context) ([]*Subscription, error) {
+	const op = "notification controller: list subscriptions"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := c.notificationMgr.ListSubscriptionsByUser(ctx, user.GetID())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Subscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, ofSubscriptionModel(subscription))
+	}
+	return result, nil
+}
+
+func (c *controller) Subscribe(ctx context.Context, request *SubscribeRequest) (*Subscription, error) {
+	const op = "notification controller: subscribe"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkResource(ctx, request.ResourceType, request.ResourceID); err != nil {
+		return nil, err
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := c.notificationMgr.UpsertSubscription(ctx, &models.NotificationSubscription{
+		UserID:       user.GetID(),
+		ResourceType: request.ResourceType,
+		ResourceID:   request.ResourceID,
+		Triggers:     models.JoinTriggers(request.Triggers),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofSubscriptionModel(subscription), nil
+}
+
+func (c *controller) DeleteSubscription(ctx context.Context, id uint) error {
+	const op = "notification controller: delete subscription"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	subscription, err := c.notificationMgr.GetSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	// subscriptions of others are not exposed
+	if subscription.UserID != user.GetID() {
+		return herrors.NewErrNotFound(herrors.NotificationSubscriptionInDB, "subscription not found")
+	}
+	return c.notificationMgr.DeleteSubscription(ctx, id)
+}
+
+// checkResource checks whether the resource exists
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	var err error
+	switch resourceType {
+	case common.ResourceGroup:
+		_, err = c.groupMgr.GetByID(ctx, resourceID)
+	case common.ResourceApplication:
+		_, err = c.applicationMgr.GetByID(ctx, resourceID)
+	case common.ResourceCluster:
+		_, err = c.clusterMgr.GetByID(ctx, resourceID)
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	utilcommon "github.com/horizoncd/horizon/pkg/util/common"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "tom", ID: 1})
+	c     Controller
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&models.NotificationChannel{}, &models.NotificationSubscription{}); err != nil {
+		panic(err)
+	}
+	if err := db.Create(&groupmodels.Group{Model: global.Model{ID: 1}, Name: "horizon", Path: "horizon",
+		TraversalIDs: "1"}).Error; err != nil {
+		panic(err)
+	}
+	if err := db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "demo",
+		GroupID: 1}).Error; err != nil {
+		panic(err)
+	}
+	c = NewController(&param.Param{Manager: managerparam.InitManager(db)})
+	os.Exit(m.Run())
+}
+
+func isParamInvalid(err error) bool {
+	return perror.Cause(err) == herrors.ErrParamInvalid
+}
+
+func TestChannel(t *testing.T) {
+	request := &CreateChannelRequest{
+		Name:     "dingtalk",
+		Type:     models.ChannelDingTalk,
+		URL:      "https://oapi.dingtalk.com/robot/send?access_token=token",
+		Secret:   "secret",
+		Triggers: []string{models.DeployFailed},
+		Enabled:  true,
+	}
+	channel, err := c.CreateChannel(ctx, common.ResourceApplication, 1, request)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{models.DeployFailed}, channel.Triggers)
+
+	// invalid requests
+	_, err = c.CreateChannel(ctx, common.ResourceCluster, 1, request)
+	assert.True(t, isParamInvalid(err))
+	invalid := *request
+	invalid.Type = "popo"
+	_, err = c.CreateChannel(ctx, common.ResourceApplication, 1, &invalid)
+	assert.True(t, isParamInvalid(err))
+	invalid = *request
+	invalid.Triggers = []string{"clusters_created"}
+	_, err = c.CreateChannel(ctx, common.ResourceApplication, 1, &invalid)
+	assert.True(t, isParamInvalid(err))
+	invalid = *request
+	invalid.Type, invalid.URL = models.ChannelEmail, "tom, jerry@noreply.com"
+	_, err = c.CreateChannel(ctx, common.ResourceApplication, 1, &invalid)
+	assert.True(t, isParamInvalid(err))
+	// the application does not exist
+	_, err = c.CreateChannel(ctx, common.ResourceApplication, 2, request)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	_, err = c.CreateChannel(ctx, common.ResourceGroup, 1, &CreateChannelRequest{
+		Name:     "email",
+		Type:     models.ChannelEmail,
+		URL:      "tom@noreply.com,jerry@noreply.com",
+		Triggers: models.Kinds,
+	})
+	assert.Nil(t, err)
+
+	channels, err := c.ListChannels(ctx, common.ResourceApplication, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(channels))
+	assert.Equal(t, "dingtalk", channels[0].Name)
+
+	_, err = c.UpdateChannel(ctx, channel.ID, &UpdateChannelRequest{URL: utilcommon.StringPtr("oapi.dingtalk.com")})
+	assert.True(t, isParamInvalid(err))
+	channel, err = c.UpdateChannel(ctx, channel.ID, &UpdateChannelRequest{
+		Enabled:  utilcommon.BoolPtr(false),
+		Triggers: []string{models.DeployStarted, models.DeploySucceeded},
+	})
+	assert.Nil(t, err)
+	assert.False(t, channel.Enabled)
+	assert.Equal(t, []string{models.DeployStarted, models.DeploySucceeded}, channel.Triggers)
+
+	assert.Nil(t, c.DeleteChannel(ctx, channel.ID))
+	channels, err = c.ListChannels(ctx, common.ResourceApplication, 1)
+	assert.Nil(t, err)
+	assert.Empty(t, channels)
+	assert.NotNil(t, c.DeleteChannel(ctx, channel.ID))
+}
+
+func TestSubscription(t *testing.T) {
+	subscription, err := c.Subscribe(ctx, &SubscribeRequest{
+		ResourceType: common.ResourceApplication,
+		ResourceID:   1,
+		Triggers:     []string{models.DeployFailed},
+	})
+	assert.Nil(t, err)
+	_, err = c.Subscribe(ctx, &SubscribeRequest{
+		ResourceType: common.ResourceApplication,
+		ResourceID:   1,
+		Triggers:     []string{models.DeployFailed, models.AutoFreeWarning},
+	})
+	assert.Nil(t, err)
+	_, err = c.Subscribe(ctx, &SubscribeRequest{
+		ResourceType: common.ResourceCluster,
+		ResourceID:   1,
+		Triggers:     []string{models.DeployFailed},
+	})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	subscriptions, err := c.ListSubscriptions(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(subscriptions))
+	assert.Equal(t, []string{models.DeployFailed, models.AutoFreeWarning}, subscriptions[0].Triggers)
+
+	// others can not delete the subscription
+	jerry := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "jerry", ID: 2})
+	err = c.DeleteSubscription(jerry, subscription.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	subscriptions, err = c.ListSubscriptions(jerry)
+	assert.Nil(t, err)
+	assert.Empty(t, subscriptions)
+
+	assert.Nil(t, c.DeleteSubscription(ctx, subscription.ID))
+	subscriptions, err = c.ListSubscriptions(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, subscriptions)
+}
@@ -0,0 +1,222 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+	"github.com/horizoncd/horizon/pkg/notification/sender"
+	commonvalidate "github.com/horizoncd/horizon/pkg/util/validate"
+)
+
+type CreateChannelRequest struct {
+	Name string `json:"name"`
+	// Type is email, slack or dingtalk
+	Type string `json:"type"`
+	// URL is the incoming webhook of slack or the robot of dingtalk, or the comma separated addresses of email
+	URL string `json:"url"`
+	// Secret signs the requests to the dingtalk robot, it's optional
+	Secret   string   `json:"secret"`
+	Triggers []string `json:"triggers"`
+	Enabled  bool     `json:"enabled"`
+}
+
+type UpdateChannelRequest struct {
+	Name     *string  `json:"name"`
+	URL      *string  `json:"url"`
+	Secret   *string  `json:"secret"`
+	Triggers []string `json:"triggers"`
+	Enabled  *bool    `json:"enabled"`
+}
+
+// Channel is a notification channel, its secret is not responded
+type Channel struct {
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	URL          string    `json:"url"`
+	Triggers     []string  `json:"triggers"`
+	Enabled      bool      `json:"enabled"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type SubscribeRequest struct {
+	ResourceType string   `json:"resourceType"`
+	ResourceID   uint     `json:"resourceID"`
+	Triggers     []string `json:"triggers"`
+}
+
+type Subscription struct {
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	Triggers     []string  `json:"triggers"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func validateTriggers(triggers []string) error {
+	if len(triggers) == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "triggers should not be empty")
+	}
+	for _, trigger := range triggers {
+		valid := false
+		for _, kind := range models.Kinds {
+			if trigger == kind {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid trigger: %s, it should be one of %s",
+				trigger, strings.Join(models.Kinds, ", "))
+		}
+	}
+	return nil
+}
+
+func validateChannelURL(channelType, url string) error {
+	if channelType == models.ChannelEmail {
+		addresses := sender.SplitAddresses(url)
+		if len(addresses) == 0 {
+			return perror.Wrap(herrors.ErrParamInvalid, "email addresses should not be empty")
+		}
+		for _, address := range addresses {
+			if !strings.Contains(address, "@") {
+				return perror.Wrapf(herrors.ErrParamInvalid, "invalid email address: %s", address)
+			}
+		}
+		return nil
+	}
+	return commonvalidate.CheckURL(url)
+}
+
+func (r *CreateChannelRequest) validate(resourceType string) error {
+	switch resourceType {
+	case common.ResourceGroup, common.ResourceApplication:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"notification channels are not supported by resource type %s", resourceType)
+	}
+	if r.Name == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "name should not be empty")
+	}
+	valid := false
+	for _, channelType := range models.ChannelTypes {
+		if r.Type == channelType {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid type: %s, it should be one of %s",
+			r.Type, strings.Join(models.ChannelTypes, ", "))
+	}
+	if err := validateChannelURL(r.Type, r.URL); err != nil {
+		return err
+	}
+	return validateTriggers(r.Triggers)
+}
+
+func (r *CreateChannelRequest) toModel(resourceType string, resourceID uint) *models.NotificationChannel {
+	return &models.NotificationChannel{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Name:         r.Name,
+		Type:         r.Type,
+		URL:          r.URL,
+		Secret:       r.Secret,
+		Triggers:     models.JoinTriggers(r.Triggers),
+		Enabled:      r.Enabled,
+	}
+}
+
+func (r *UpdateChannelRequest) validate(channelType string) error {
+	if r.Name != nil && *r.Name == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "name should not be empty")
+	}
+	if r.URL != nil {
+		if err := validateChannelURL(channelType, *r.URL); err != nil {
+			return err
+		}
+	}
+	if r.Triggers != nil {
+		return validateTriggers(r.Triggers)
+	}
+	return nil
+}
+
+func (r *UpdateChannelRequest) toModel(channel *models.NotificationChannel) *models.NotificationChannel {
+	if r.Name != nil {
+		channel.Name = *r.Name
+	}
+	if r.URL != nil {
+		channel.URL = *r.URL
+	}
+	if r.Secret != nil {
+		channel.Secret = *r.Secret
+	}
+	if r.Triggers != nil {
+		channel.Triggers = models.JoinTriggers(r.Triggers)
+	}
+	if r.Enabled != nil {
+		channel.Enabled = *r.Enabled
+	}
+	return channel
+}
+
+func (r *SubscribeRequest) validate() error {
+	switch r.ResourceType {
+	case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"notifications of resource type %s can not be subscribed", r.ResourceType)
+	}
+	return validateTriggers(r.Triggers)
+}
+
+func ofChannelModel(channel *models.NotificationChannel) *Channel {
+	return &Channel{
+		ID:           channel.ID,
+		ResourceType: channel.ResourceType,
+		ResourceID:   channel.ResourceID,
+		Name:         channel.Name,
+		Type:         channel.Type,
+		URL:          channel.URL,
+		Triggers:     models.SplitTriggers(channel.Triggers),
+		Enabled:      channel.Enabled,
+		CreatedAt:    channel.CreatedAt,
+		UpdatedAt:    channel.UpdatedAt,
+	}
+}
+
+func ofSubscriptionModel(subscription *models.NotificationSubscription) *Subscription {
+	return &Subscription{
+		ID:           subscription.ID,
+		ResourceType: subscription.ResourceType,
+		ResourceID:   subscription.ResourceID,
+		Triggers:     models.SplitTriggers(subscription.Triggers),
+		CreatedAt:    subscription.CreatedAt,
+		UpdatedAt:    subscription.UpdatedAt,
+	}
+}
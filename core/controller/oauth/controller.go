@@ -15,19 +15,31 @@
 package oauth
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/oauth/manager"
 	oauthmodel "github.com/horizoncd/horizon/pkg/oauth/models"
+	"github.com/horizoncd/horizon/pkg/oauth/oidc"
+	"github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"golang.org/x/net/context"
 )
 
+// _appIdentityEmailSuffix makes the emails of the identities of oauth apps, which are never delivered
+const _appIdentityEmailSuffix = "@oauthapp.noreply.com"
+
 type AuthorizeReq struct {
 	ClientID     string
 	Scope        string
@@ -36,6 +48,12 @@ type AuthorizeReq struct {
 	UserIdentity uint
 
 	Request *http.Request
+
+	// CodeChallenge and CodeChallengeMethod are the PKCE challenge of public clients, ref: rfc7636
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// Nonce is the openid connect nonce, which is carried by the id token
+	Nonce string
 }
 
 type AuthorizeCodeResponse struct {
@@ -44,6 +62,34 @@ type AuthorizeCodeResponse struct {
 	State       string
 }
 
+type ScopeBasic struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+// AuthorizationResponse describes a pending authorization for the user to consent to
+type AuthorizationResponse struct {
+	ClientID   string       `json:"clientID"`
+	ClientName string       `json:"clientName"`
+	HomeURL    string       `json:"homeURL"`
+	Desc       string       `json:"desc"`
+	Scopes     []ScopeBasic `json:"scopes"`
+	// Granted is true if the user has consented to all the scopes requested before,
+	// the consent step is skipped then
+	Granted bool `json:"granted"`
+}
+
+type ConsentReq struct {
+	AuthorizeReq
+	Approved bool
+}
+
+type ConsentResponse struct {
+	// RedirectURL is the location the user is redirected back to the client,
+	// with the authorization code if approved or the error if denied
+	RedirectURL string `json:"redirectURL"`
+}
+
 type BaseTokenReq struct {
 	ClientID     string
 	ClientSecret string
@@ -55,6 +101,8 @@ type BaseTokenReq struct {
 type AccessTokenReq struct {
 	BaseTokenReq
 	Code string
+	// CodeVerifier is required if the code is requested with a PKCE challenge
+	CodeVerifier string
 }
 
 type RefreshTokenReq struct {
@@ -62,37 +110,126 @@ type RefreshTokenReq struct {
 	RefreshToken string
 }
 
+type ClientCredentialsTokenReq struct {
+	ClientID     string
+	ClientSecret string
+	Scope        string
+
+	Request *http.Request
+}
+
+type RevokeTokenReq struct {
+	ClientID string
+	Token    string
+
+	Request *http.Request
+}
+
+type DeviceCodeReq struct {
+	ClientID string
+	Scope    string
+
+	Request *http.Request
+}
+
+// DeviceCodeResponse is the device authorization response without the verification uri and the polling interval,
+// which are up to the deployment, ref: rfc8628 section 3.2
+type DeviceCodeResponse struct {
+	DeviceCode string `json:"device_code"`
+	UserCode   string `json:"user_code"`
+	// ExpiresIn is the lifetime of the codes in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+type DeviceConsentReq struct {
+	UserCode     string
+	UserIdentity uint
+	Approved     bool
+}
+
+type DeviceAccessTokenReq struct {
+	ClientID string
+	// ClientSecret is optional, as devices are public clients mostly
+	ClientSecret string
+	DeviceCode   string
+
+	Request *http.Request
+}
+
 type AccessTokenResponse struct {
 	AccessToken  string        `json:"access_token"`
-	RefreshToken string        `json:"refresh_token"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
 	ExpiresIn    time.Duration `json:"expires_in"`
 	Scope        string        `json:"scope"`
 	TokenType    string        `json:"token_type"`
+	// IDToken is issued if the openid scope is granted, ref: OpenID Connect Core 1.0 section 3.1.3.3
+	IDToken string `json:"id_token,omitempty"`
 }
 
 type Controller interface {
 	// GenAuthorizeCode oauth  Authorization GenOauthTokensRequest ref:rfc6750
 	GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*AuthorizeCodeResponse, error)
+	// GetAuthorization returns the app and the scopes of a pending authorization for the user to consent to
+	GetAuthorization(ctx context.Context, req *AuthorizeReq) (*AuthorizationResponse, error)
+	// Consent records the decision of the user on a pending authorization, the scopes approved are
+	// remembered so that later authorizations within them skip the consent step, while a denial
+	// forgets the consents given to the client before
+	Consent(ctx context.Context, req *ConsentReq) (*ConsentResponse, error)
 	// GenAccessToken Access Token GenOauthTokensRequest,ref:rfc6750
 	GenAccessToken(ctx context.Context, req *AccessTokenReq) (*AccessTokenResponse, error)
 	RefreshToken(ctx context.Context, req *RefreshTokenReq) (*AccessTokenResponse, error)
+	// GenClientCredentialsToken Client Credentials Grant of direct oauth apps, ref:rfc6749 section 4.4
+	GenClientCredentialsToken(ctx context.Context, req *ClientCredentialsTokenReq) (*AccessTokenResponse, error)
+	// RevokeToken revokes a single access token or refresh token of the client, ref:rfc7009
+	RevokeToken(ctx context.Context, req *RevokeTokenReq) error
+
+	// GenDeviceCode issues a device code for clients without browsers to poll for tokens,
+	// and a user code for the user to approve it on another device, ref: rfc8628
+	GenDeviceCode(ctx context.Context, req *DeviceCodeReq) (*DeviceCodeResponse, error)
+	// GetDeviceAuthorization returns the app and the scopes of the pending device code for the user to consent to
+	GetDeviceAuthorization(ctx context.Context, userCode string) (*AuthorizationResponse, error)
+	// DeviceConsent records the decision of the user on the pending device code
+	DeviceConsent(ctx context.Context, req *DeviceConsentReq) error
+	// GenDeviceAccessToken exchanges the device code for tokens once approved by the user
+	GenDeviceAccessToken(ctx context.Context, req *DeviceAccessTokenReq) (*AccessTokenResponse, error)
+
+	// UserInfo returns the claims about the user of the access token granted the openid scope
+	UserInfo(ctx context.Context, accessToken string) (*oidc.UserInfo, error)
+	// OpenIDConfiguration returns the discovery document of horizon as an openid provider
+	OpenIDConfiguration(ctx context.Context, endpoints oidc.Endpoints) (*oidc.Configuration, error)
+	// JWKS returns the keys to verify id tokens
+	JWKS(ctx context.Context) (*oidc.JSONWebKeySet, error)
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{oauthManager: param.OauthManager}
+	return &controller{
+		oauthManager: param.OauthManager,
+		scopeService: param.ScopeService,
+		tokenManager: param.TokenMgr,
+		userManager:  param.UserMgr,
+		oidcProvider: param.OIDCProvider,
+	}
 }
 
 var _ Controller = &controller{}
 
 type controller struct {
 	oauthManager manager.Manager
+	scopeService scope.Service
+	tokenManager tokenmanager.Manager
+	userManager  usermanager.Manager
+	// oidcProvider is nil if openid connect is disabled
+	oidcProvider *oidc.Provider
 }
 
 func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*AuthorizeCodeResponse, error) {
 	const op = "oauth controller: GenAuthorizeCode"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	// 1. TODO: check if the scope is ok (now horizon app do not need provide scope)
+	// 1. check the scopes are defined, the granted scopes are carried by the tokens exchanged by the code
+	if err := c.scopeService.ValidateScopes(strings.Split(req.Scope, " ")); err != nil {
+		return nil, err
+	}
 	// 2. gen authorization Code
 	authToken, err := c.oauthManager.GenAuthorizeCode(ctx, &manager.AuthorizeGenerateRequest{
 		ClientID:     req.ClientID,
@@ -101,6 +238,10 @@ func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*
 		Scope:        req.Scope,
 		UserIdentify: req.UserIdentity,
 		Request:      req.Request,
+
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
 	})
 	if err != nil {
 		return nil, err
@@ -113,6 +254,88 @@ func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*
 	return resp, nil
 }
 
+func (c *controller) GetAuthorization(ctx context.Context,
+	req *AuthorizeReq) (*AuthorizationResponse, error) {
+	const op = "oauth controller: GetAuthorization"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	scopes := strings.Split(req.Scope, " ")
+	if err := c.scopeService.ValidateScopes(scopes); err != nil {
+		return nil, err
+	}
+	app, err := c.getAuthorizingApp(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	granted, err := c.oauthManager.IsGranted(ctx, req.UserIdentity, req.ClientID, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeBasics := make([]ScopeBasic, 0)
+	for _, rule := range c.scopeService.GetRulesByScope(scopes) {
+		scopeBasics = append(scopeBasics, ScopeBasic{
+			Name: rule.Name,
+			Desc: rule.Desc,
+		})
+	}
+	return &AuthorizationResponse{
+		ClientID:   app.ClientID,
+		ClientName: app.Name,
+		HomeURL:    app.HomeURL,
+		Desc:       app.Desc,
+		Scopes:     scopeBasics,
+		Granted:    granted,
+	}, nil
+}
+
+func (c *controller) Consent(ctx context.Context, req *ConsentReq) (*ConsentResponse, error) {
+	const op = "oauth controller: Consent"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// the user is only redirected back to the registered url, even if denied
+	if _, err := c.getAuthorizingApp(ctx, &req.AuthorizeReq); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("state", req.State)
+	if !req.Approved {
+		if err := c.oauthManager.RevokeGrant(ctx, req.UserIdentity, req.ClientID); err != nil {
+			return nil, err
+		}
+		q.Set("error", "the user has denied your application access")
+		return &ConsentResponse{RedirectURL: redirectLocation(req.RedirectURL, q)}, nil
+	}
+
+	resp, err := c.GenAuthorizeCode(ctx, &req.AuthorizeReq)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.oauthManager.Grant(ctx, req.UserIdentity, req.ClientID, req.Scope); err != nil {
+		return nil, err
+	}
+	q.Set("code", resp.Code)
+	return &ConsentResponse{RedirectURL: redirectLocation(resp.RedirectURL, q)}, nil
+}
+
+// getAuthorizingApp gets the app to authorize and checks the redirect url is the registered one
+func (c *controller) getAuthorizingApp(ctx context.Context, req *AuthorizeReq) (*oauthmodel.OauthApp, error) {
+	app, err := c.oauthManager.GetOAuthApp(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if req.RedirectURL != app.RedirectURL {
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid, "redirect URL not match")
+	}
+	return app, nil
+}
+
+func redirectLocation(redirectURL string, q url.Values) string {
+	location := url.URL{Path: redirectURL, RawQuery: q.Encode()}
+	return location.RequestURI()
+}
+
 func (c *controller) getAccessTokenGenerator(ctx context.Context,
 	clientID string) (generator.CodeGenerator, error) {
 	app, err := c.oauthManager.GetOAuthApp(ctx, clientID)
@@ -143,6 +366,7 @@ func (c *controller) GenAccessToken(ctx context.Context, req *AccessTokenReq) (*
 		ClientID:              req.ClientID,
 		ClientSecret:          req.ClientSecret,
 		Code:                  req.Code,
+		CodeVerifier:          req.CodeVerifier,
 		RedirectURL:           req.RedirectURL,
 		Request:               req.Request,
 		AccessTokenGenerator:  accessTokenGenerator,
@@ -151,13 +375,7 @@ func (c *controller) GenAccessToken(ctx context.Context, req *AccessTokenReq) (*
 	if err != nil {
 		return nil, err
 	}
-	return &AccessTokenResponse{
-		AccessToken:  tokens.AccessToken.Code,
-		RefreshToken: tokens.RefreshToken.Code,
-		ExpiresIn:    tokens.AccessToken.ExpiresIn,
-		Scope:        tokens.AccessToken.Scope,
-		TokenType:    "bearer",
-	}, nil
+	return c.ofOauthTokens(ctx, tokens)
 }
 
 func (c *controller) RefreshToken(ctx context.Context,
@@ -180,11 +398,227 @@ func (c *controller) RefreshToken(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return &AccessTokenResponse{
+	return c.ofOauthTokens(ctx, tokens)
+}
+
+// ofOauthTokens returns the tokens issued to the user, along with an id token if the openid scope is granted
+func (c *controller) ofOauthTokens(ctx context.Context,
+	tokens *manager.OauthTokensResponse) (*AccessTokenResponse, error) {
+	resp := &AccessTokenResponse{
 		AccessToken:  tokens.AccessToken.Code,
 		RefreshToken: tokens.RefreshToken.Code,
 		ExpiresIn:    tokens.AccessToken.ExpiresIn,
 		Scope:        tokens.AccessToken.Scope,
 		TokenType:    "bearer",
+	}
+	if c.oidcProvider == nil || !oidc.HasOpenIDScope(tokens.AccessToken.Scope) {
+		return resp, nil
+	}
+	user, err := c.userManager.GetUserByID(ctx, tokens.AccessToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	resp.IDToken, err = c.oidcProvider.IssueIDToken(tokens.AccessToken.ClientID, user,
+		tokens.AccessToken.Scope, tokens.Nonce)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrOAuthInternal, "failed to sign id token: %v", err)
+	}
+	return resp, nil
+}
+
+func (c *controller) GenClientCredentialsToken(ctx context.Context,
+	req *ClientCredentialsTokenReq) (*AccessTokenResponse, error) {
+	const op = "oauth controller: GenClientCredentialsToken"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.scopeService.ValidateScopes(strings.Split(req.Scope, " ")); err != nil {
+		return nil, err
+	}
+	token, err := c.oauthManager.GenClientCredentialsToken(ctx, req.ClientID, req.ClientSecret, req.Scope,
+		c.appIdentity, req.Request)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessTokenResponse{
+		AccessToken: token.Code,
+		ExpiresIn:   token.ExpiresIn,
+		Scope:       token.Scope,
+		TokenType:   "bearer",
+	}, nil
+}
+
+// appIdentity returns the robot user a direct oauth app acts as by its own credentials, which is created
+// on the first request. It's granted no role but the default one, unless the owners add it as a member,
+// so that the app never gets the privileges of a human, such as the creator of the app.
+func (c *controller) appIdentity(ctx context.Context, app *oauthmodel.OauthApp) (uint, error) {
+	email := app.ClientID + _appIdentityEmailSuffix
+	find := func() (uint, bool, error) {
+		users, err := c.userManager.ListByEmail(ctx, []string{email})
+		if err != nil {
+			return 0, false, err
+		}
+		for _, user := range users {
+			if user.UserType == usermodels.UserTypeRobot {
+				return user.ID, true, nil
+			}
+		}
+		return 0, false, nil
+	}
+	if id, ok, err := find(); err != nil || ok {
+		return id, err
+	}
+	user, err := c.userManager.Create(ctx, &usermodels.User{
+		Name:     app.ClientID,
+		FullName: fmt.Sprintf("%s_oauthapp", app.Name),
+		Email:    email,
+		UserType: usermodels.UserTypeRobot,
+	})
+	if err != nil {
+		// it may be created by a concurrent request
+		if id, ok, findErr := find(); findErr == nil && ok {
+			return id, nil
+		}
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+func (c *controller) RevokeToken(ctx context.Context, req *RevokeTokenReq) error {
+	const op = "oauth controller: RevokeToken"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.oauthManager.RevokeAccessToken(ctx, req.ClientID, req.Token, req.Request)
+}
+
+func (c *controller) GenDeviceCode(ctx context.Context, req *DeviceCodeReq) (*DeviceCodeResponse, error) {
+	const op = "oauth controller: GenDeviceCode"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.scopeService.ValidateScopes(strings.Split(req.Scope, " ")); err != nil {
+		return nil, err
+	}
+	deviceToken, err := c.oauthManager.GenDeviceCode(ctx, req.ClientID, req.Scope, req.Request)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceCodeResponse{
+		DeviceCode: deviceToken.Code,
+		UserCode:   manager.FormatUserCode(deviceToken.UserCode),
+		ExpiresIn:  int64(deviceToken.ExpiresIn / time.Second),
+	}, nil
+}
+
+func (c *controller) GetDeviceAuthorization(ctx context.Context, userCode string) (*AuthorizationResponse, error) {
+	const op = "oauth controller: GetDeviceAuthorization"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	deviceToken, err := c.oauthManager.GetDeviceCode(ctx, userCode)
+	if err != nil {
+		return nil, err
+	}
+	app, err := c.oauthManager.GetOAuthApp(ctx, deviceToken.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeBasics := make([]ScopeBasic, 0)
+	for _, rule := range c.scopeService.GetRulesByScope(strings.Split(deviceToken.Scope, " ")) {
+		scopeBasics = append(scopeBasics, ScopeBasic{
+			Name: rule.Name,
+			Desc: rule.Desc,
+		})
+	}
+	// the user is always asked, since the user code is typed rather than following a link of the client
+	return &AuthorizationResponse{
+		ClientID:   app.ClientID,
+		ClientName: app.Name,
+		HomeURL:    app.HomeURL,
+		Desc:       app.Desc,
+		Scopes:     scopeBasics,
 	}, nil
 }
+
+func (c *controller) DeviceConsent(ctx context.Context, req *DeviceConsentReq) error {
+	const op = "oauth controller: DeviceConsent"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if !req.Approved {
+		return c.oauthManager.DenyDeviceCode(ctx, req.UserCode)
+	}
+	deviceToken, err := c.oauthManager.ApproveDeviceCode(ctx, req.UserCode, req.UserIdentity)
+	if err != nil {
+		return err
+	}
+	return c.oauthManager.Grant(ctx, req.UserIdentity, deviceToken.ClientID, deviceToken.Scope)
+}
+
+func (c *controller) GenDeviceAccessToken(ctx context.Context,
+	req *DeviceAccessTokenReq) (*AccessTokenResponse, error) {
+	const op = "oauth controller: GenDeviceAccessToken"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	accessTokenGenerator, err := c.getAccessTokenGenerator(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := c.oauthManager.GenDeviceTokens(ctx, &manager.OauthTokensRequest{
+		ClientID:              req.ClientID,
+		ClientSecret:          req.ClientSecret,
+		Code:                  req.DeviceCode,
+		Request:               req.Request,
+		AccessTokenGenerator:  accessTokenGenerator,
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.ofOauthTokens(ctx, tokens)
+}
+
+func (c *controller) UserInfo(ctx context.Context, accessToken string) (*oidc.UserInfo, error) {
+	const op = "oauth controller: UserInfo"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if c.oidcProvider == nil {
+		return nil, perror.Wrap(herrors.ErrNotSupport, "openid connect is not enabled")
+	}
+	// only the access tokens issued to oauth apps are accepted, ref: OpenID Connect Core 1.0 section 5.3
+	if !strings.HasPrefix(accessToken, generator.HorizonAppUserToServerAccessTokenPrefix) &&
+		!strings.HasPrefix(accessToken, generator.OauthAPPAccessTokenPrefix) {
+		return nil, perror.Wrap(herrors.ErrOAuthReqNotValid, "not an access token issued to oauth apps")
+	}
+	token, err := c.tokenManager.LoadTokenByCode(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if isExpired(token) {
+		return nil, perror.Wrap(herrors.ErrOAuthAccessTokenExpired, "")
+	}
+	if !oidc.HasOpenIDScope(token.Scope) {
+		return nil, perror.Wrap(herrors.ErrForbidden, "the openid scope is not granted")
+	}
+	user, err := c.userManager.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return c.oidcProvider.UserInfo(user, token.Scope), nil
+}
+
+func isExpired(token *tokenmodels.Token) bool {
+	return token.ExpiresIn > 0 && token.CreatedAt.Add(token.ExpiresIn).Before(time.Now())
+}
+
+func (c *controller) OpenIDConfiguration(ctx context.Context,
+	endpoints oidc.Endpoints) (*oidc.Configuration, error) {
+	if c.oidcProvider == nil {
+		return nil, perror.Wrap(herrors.ErrNotSupport, "openid connect is not enabled")
+	}
+	return c.oidcProvider.Configuration(endpoints, c.scopeService.GetAllScopeNames()), nil
+}
+
+func (c *controller) JWKS(ctx context.Context) (*oidc.JSONWebKeySet, error) {
+	if c.oidcProvider == nil {
+		return nil, perror.Wrap(herrors.ErrNotSupport, "openid connect is not enabled")
+	}
+	return c.oidcProvider.JWKS(), nil
+}
@@ -15,22 +15,37 @@
 package oauthapp
 
 import (
+	"strconv"
 	"time"
 
 	"golang.org/x/net/context"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auth"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/oauth/manager"
 	"github.com/horizoncd/horizon/pkg/oauth/models"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+const _subresourceOauthApps = "oauthapps"
+
 type CreateOauthAPPRequest struct {
 	Name        string `json:"name"`
 	Desc        string `json:"desc"`
 	HomeURL     string `json:"homeURL"`
 	RedirectURL string `json:"redirectURL"`
+	// ClientType is confidential or public, confidential by default
+	ClientType models.ClientType `json:"clientType"`
+	// AccessTokenExpireIn and RefreshTokenExpireIn are the token lifetimes in seconds, zero means the defaults
+	AccessTokenExpireIn  uint `json:"accessTokenExpireIn"`
+	RefreshTokenExpireIn uint `json:"refreshTokenExpireIn"`
 }
 
 type APPBasicInfo struct {
@@ -42,6 +57,38 @@ type APPBasicInfo struct {
 	RedirectURL string    `json:"redirectURL"`
 	UpdatedBy   uint      `json:"updatedBy"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	// ClientType is confidential or public, it's kept when omitted on update
+	ClientType models.ClientType `json:"clientType,omitempty"`
+	// AccessTokenExpireIn and RefreshTokenExpireIn are the token lifetimes in seconds, zero means the defaults,
+	// they are kept when omitted on update
+	AccessTokenExpireIn  *uint `json:"accessTokenExpireIn,omitempty"`
+	RefreshTokenExpireIn *uint `json:"refreshTokenExpireIn,omitempty"`
+}
+
+func ofOauthApp(app *models.OauthApp) APPBasicInfo {
+	accessTokenExpireIn := uint(app.AccessTokenExpireIn / time.Second)
+	refreshTokenExpireIn := uint(app.RefreshTokenExpireIn / time.Second)
+	return APPBasicInfo{
+		AppID:                app.ID,
+		AppName:              app.Name,
+		Desc:                 app.Desc,
+		HomeURL:              app.HomeURL,
+		ClientID:             app.ClientID,
+		RedirectURL:          app.RedirectURL,
+		UpdatedBy:            app.UpdatedBy,
+		UpdatedAt:            app.UpdatedAt,
+		ClientType:           app.ClientType,
+		AccessTokenExpireIn:  &accessTokenExpireIn,
+		RefreshTokenExpireIn: &refreshTokenExpireIn,
+	}
+}
+
+func toExpireTime(seconds *uint) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	expireTime := time.Duration(*seconds) * time.Second
+	return &expireTime
 }
 
 type Controller interface {
@@ -50,24 +97,34 @@ type Controller interface {
 	List(ctx context.Context, groupID uint) ([]APPBasicInfo, error)
 	Update(ctx context.Context, info APPBasicInfo) (*APPBasicInfo, error)
 	Delete(ctx context.Context, clientID string) error
+	// Transfer transfers the app to another group
+	Transfer(ctx context.Context, clientID string, groupID uint) (*APPBasicInfo, error)
 
 	CreateSecret(ctx context.Context, clientID string) (*SecretBasic, error)
 	DeleteSecret(ctx context.Context, ClientID string, clientSecretID uint) error
 	ListSecret(ctx context.Context, ClientID string) ([]SecretBasic, error)
+
+	// ListTokenAudits lists the issuances, refreshes and revocations of oauth tokens across all apps,
+	// only admins and auditors can list
+	ListTokenAudits(ctx context.Context, query *q.Query) ([]*models.OauthTokenAudit, int, error)
 }
 
 var _ Controller = &controller{}
 
-func NewController(param *param.Param) Controller {
+func NewController(param *param.Param, authorizer rbac.Authorizer) Controller {
 	return &controller{
 		oauthManager: param.OauthManager,
 		userManager:  param.UserMgr,
+		groupManager: param.GroupMgr,
+		authorizer:   authorizer,
 	}
 }
 
 type controller struct {
 	oauthManager manager.Manager
 	userManager  usermanager.Manager
+	groupManager groupmanager.Manager
+	authorizer   rbac.Authorizer
 }
 
 type SecretBasic struct {
@@ -145,21 +202,16 @@ func (c *controller) Create(ctx context.Context, groupID uint, request CreateOau
 		OwnerType:   models.GroupOwnerType,
 		OwnerID:     groupID,
 		APPType:     models.DirectOAuthAPP,
+		ClientType:  request.ClientType,
+
+		AccessTokenExpireIn:  time.Duration(request.AccessTokenExpireIn) * time.Second,
+		RefreshTokenExpireIn: time.Duration(request.RefreshTokenExpireIn) * time.Second,
 	})
 	if err != nil {
 		return nil, err
 	}
-	resp := &APPBasicInfo{
-		AppID:       oauthApp.ID,
-		AppName:     oauthApp.Name,
-		Desc:        oauthApp.Desc,
-		HomeURL:     oauthApp.Desc,
-		ClientID:    oauthApp.ClientID,
-		RedirectURL: oauthApp.RedirectURL,
-		UpdatedBy:   oauthApp.UpdatedBy,
-		UpdatedAt:   oauthApp.UpdatedAt,
-	}
-	return resp, err
+	resp := ofOauthApp(oauthApp)
+	return &resp, err
 }
 
 func (c *controller) Get(ctx context.Context, clientID string) (*APPBasicInfo, error) {
@@ -170,17 +222,8 @@ func (c *controller) Get(ctx context.Context, clientID string) (*APPBasicInfo, e
 	if err != nil {
 		return nil, err
 	}
-	resp := &APPBasicInfo{
-		AppID:       oauthApp.ID,
-		AppName:     oauthApp.Name,
-		Desc:        oauthApp.Desc,
-		HomeURL:     oauthApp.HomeURL,
-		ClientID:    oauthApp.ClientID,
-		RedirectURL: oauthApp.RedirectURL,
-		UpdatedBy:   oauthApp.UpdatedBy,
-		UpdatedAt:   oauthApp.UpdatedAt,
-	}
-	return resp, err
+	resp := ofOauthApp(oauthApp)
+	return &resp, err
 }
 
 func (c *controller) List(ctx context.Context, groupID uint) ([]APPBasicInfo, error) {
@@ -192,17 +235,8 @@ func (c *controller) List(ctx context.Context, groupID uint) ([]APPBasicInfo, er
 		return nil, err
 	}
 	var appInfos = make([]APPBasicInfo, 0)
-	for _, app := range apps {
-		appInfos = append(appInfos, APPBasicInfo{
-			AppID:       app.ID,
-			AppName:     app.Name,
-			Desc:        app.Desc,
-			HomeURL:     app.HomeURL,
-			ClientID:    app.ClientID,
-			RedirectURL: app.RedirectURL,
-			UpdatedBy:   app.UpdatedBy,
-			UpdatedAt:   app.UpdatedAt,
-		})
+	for i := range apps {
+		appInfos = append(appInfos, ofOauthApp(&apps[i]))
 	}
 	return appInfos, nil
 }
@@ -216,22 +250,79 @@ func (c *controller) Update(ctx context.Context, info APPBasicInfo) (*APPBasicIn
 		HomeURL:     info.HomeURL,
 		RedirectURI: info.RedirectURL,
 		Desc:        info.Desc,
+		ClientType:  info.ClientType,
+
+		AccessTokenExpireIn:  toExpireTime(info.AccessTokenExpireIn),
+		RefreshTokenExpireIn: toExpireTime(info.RefreshTokenExpireIn),
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &APPBasicInfo{
-		AppID:       app.ID,
-		AppName:     app.Name,
-		Desc:        app.Desc,
-		HomeURL:     app.HomeURL,
-		ClientID:    app.ClientID,
-		RedirectURL: app.RedirectURL,
-		UpdatedBy:   app.UpdatedBy,
-		UpdatedAt:   app.UpdatedAt,
-	}, nil
+	resp := ofOauthApp(app)
+	return &resp, nil
 }
 
 func (c *controller) Delete(ctx context.Context, clientID string) error {
 	return c.oauthManager.DeleteOAuthApp(ctx, clientID)
 }
+
+func (c *controller) Transfer(ctx context.Context, clientID string, groupID uint) (*APPBasicInfo, error) {
+	const op = "oauth app controller  Transfer"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	group, err := c.groupManager.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.authorizeCreate(ctx, group.ID); err != nil {
+		return nil, err
+	}
+	app, err := c.oauthManager.TransferOauthApp(ctx, clientID, models.GroupOwnerType, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := ofOauthApp(app)
+	return &resp, nil
+}
+
+// authorizeCreate checks the current user is allowed to create oauth apps in the group.
+// The auth middleware only checks the app transferred, the group it's transferred to is checked here.
+func (c *controller) authorizeCreate(ctx context.Context, groupID uint) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            "create",
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceGroup,
+		SubResource:     _subresourceOauthApps,
+		Name:            strconv.FormatUint(uint64(groupID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"oauth apps are not allowed to be transferred to group %d: %s", groupID, reason)
+	}
+	return nil
+}
+
+func (c *controller) ListTokenAudits(ctx context.Context,
+	query *q.Query) ([]*models.OauthTokenAudit, int, error) {
+	const op = "oauth app controller  ListTokenAudits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !currentUser.IsAdmin() && !currentUser.IsAuditor() {
+		return nil, 0, perror.Wrap(herrors.ErrNoPrivilege, "could not list token audits\n"+
+			"should be admin or auditor")
+	}
+	return c.oauthManager.ListTokenAudits(ctx, query)
+}
@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauthapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
+	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	"github.com/horizoncd/horizon/pkg/oauth/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+// groupAuthorizer allows to create oauth apps in the groups listed only
+type groupAuthorizer map[string]bool
+
+func (a groupAuthorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetResource() == common.ResourceGroup && attr.GetSubResource() == _subresourceOauthApps &&
+		attr.GetVerb() == "create" && a[attr.GetName()] {
+		return auth.DecisionAllow, "", nil
+	}
+	return auth.DecisionDeny, "not a maintainer", nil
+}
+
+func TestTransfer(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	callbacks.RegisterCustomCallbacks(db)
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &membermodels.Member{}, &applicationmodels.Application{},
+		&models.OauthApp{}, &models.OauthClientSecret{}, &tokenmodels.Token{}))
+	manager := managerparam.InitManager(db)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+
+	var groupIDs []uint
+	for _, name := range []string{"source", "allowed", "denied"} {
+		group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{Name: name, Path: name})
+		assert.Nil(t, err)
+		groupIDs = append(groupIDs, group.ID)
+	}
+	oauthMgr := oauthmanager.NewManager(oauthdao.NewDAO(db), tokenstore.NewStore(db),
+		generator.NewAuthorizeGenerator(), time.Minute, time.Hour, time.Hour)
+	ctl := NewController(&param.Param{Manager: manager, OauthManager: oauthMgr},
+		groupAuthorizer{"2": true})
+
+	app, err := oauthMgr.CreateOauthApp(ctx, &oauthmanager.CreateOAuthAppReq{
+		Name:        "transfer-test",
+		RedirectURI: "https://machine.com/oauth/redirect",
+		HomeURL:     "https://machine.com",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     groupIDs[0],
+		APPType:     models.DirectOAuthAPP,
+	})
+	assert.Nil(t, err)
+
+	// the group transferred to is checked besides the app
+	_, err = ctl.Transfer(ctx, app.ClientID, groupIDs[2])
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	loaded, err := oauthMgr.GetOAuthApp(ctx, app.ClientID)
+	assert.Nil(t, err)
+	assert.Equal(t, groupIDs[0], loaded.OwnerID)
+
+	_, err = ctl.Transfer(ctx, app.ClientID, groupIDs[1])
+	assert.Nil(t, err)
+	loaded, err = oauthMgr.GetOAuthApp(ctx, app.ClientID)
+	assert.Nil(t, err)
+	assert.Equal(t, groupIDs[1], loaded.OwnerID)
+}
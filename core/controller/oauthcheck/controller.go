@@ -31,6 +31,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 )
 
 type Controller interface {
@@ -95,6 +96,8 @@ func (c *controller) LoadAccessTokenUser(ctx context.Context, accessToken string
 		ID:       usr.ID,
 		Email:    usr.Email,
 		Admin:    usr.Admin,
+		Auditor:  usr.Auditor,
+		Robot:    usr.UserType == usermodels.UserTypeRobot,
 	}, nil
 }
 
@@ -19,13 +19,16 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/config"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/lib/s3"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	artifactmanager "github.com/horizoncd/horizon/pkg/artifact/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
@@ -33,15 +36,25 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	snapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	artifactconfig "github.com/horizoncd/horizon/pkg/config/artifact"
+	deployapprovalconfig "github.com/horizoncd/horizon/pkg/config/deployapproval"
+	sbomconfig "github.com/horizoncd/horizon/pkg/config/sbom"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	deployapprovalmanager "github.com/horizoncd/horizon/pkg/deployapproval/manager"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	membermanager "github.com/horizoncd/horizon/pkg/member"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/pipelinestep"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
@@ -50,12 +63,17 @@ import (
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/valuediff"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
 type Controller interface {
 	GetPipelinerunLog(ctx context.Context, pipelinerunID uint) (*collector.Log, error)
 	GetClusterLatestLog(ctx context.Context, clusterID uint) (*collector.Log, error)
+	// StreamPipelinerunLog sends the lines of the logs after the cursor in batches,
+	// and keeps sending the new lines until the pipelinerun finishes if follow is true
+	StreamPipelinerunLog(ctx context.Context, pipelinerunID uint, follow bool, cursor LogCursor,
+		send func(lines []*LogLine, cursor LogCursor) error) error
 	GetDiff(ctx context.Context, pipelinerunID uint) (*GetDiffResponse, error)
 	GetPipelinerun(ctx context.Context, pipelinerunID uint) (*prmodels.PipelineBasic, error)
 	ListPipelineruns(ctx context.Context, clusterID uint, canRollback bool,
@@ -70,14 +88,40 @@ type Controller interface {
 	ListMessagesByPipelinerun(ctx context.Context, pipelinerunID uint, query *q.Query) (int, []*prmodels.PRMessage, error)
 	// Execute runs a pipelineRun only if its state is ready.
 	Execute(ctx context.Context, pipelinerunID uint, force bool) error
-	// Cancel withdraws a pipelineRun only if its state is pending.
+	// Cancel withdraws a pipelineRun if its state is pending or ready, and stops it in CI if it is running.
 	Cancel(ctx context.Context, pipelinerunID uint) error
+	// Retry reruns a failed or cancelled pipelineRun in a new pipelineRun.
+	Retry(ctx context.Context, pipelinerunID uint) (*prmodels.PipelineBasic, error)
+	// RerunFromStep reruns a failed or cancelled pipelineRun from a step in a new pipelineRun,
+	// the steps before it are skipped by the pipeline reusing what the pipelineRun rerun has done.
+	RerunFromStep(ctx context.Context, pipelinerunID uint, r *RerunFromStepRequest) (*prmodels.PipelineBasic, error)
+
+	GetDeployApproval(ctx context.Context, pipelinerunID uint) (*DeployApproval, error)
+	// ListDeployApprovals lists approvals of the environments which the current user can approve
+	ListDeployApprovals(ctx context.Context, status string) ([]*DeployApproval, error)
+	// ReviewDeployApproval approves or rejects the deploy of a pipelinerun to a protected environment,
+	// the pipelinerun is cancelled if rejected
+	ReviewDeployApproval(ctx context.Context, pipelinerunID uint,
+		r *ReviewDeployApprovalRequest) (*DeployApproval, error)
 
 	ListCheckRuns(ctx context.Context, pipelinerunID uint) ([]*prmodels.CheckRun, error)
 	CreateCheckRun(ctx context.Context, pipelineRunID uint,
 		request *CreateOrUpdateCheckRunRequest) (*prmodels.CheckRun, error)
 	ListPRMessages(ctx context.Context, pipelineRunID uint, q *q.Query) (int, []*PrMessage, error)
 	CreatePRMessage(ctx context.Context, pipelineRunID uint, request *CreatePrMessageRequest) (*prmodels.PRMessage, error)
+
+	// InternalCreateSBOM stores the sbom uploaded by the build pipeline of pipelinerun
+	InternalCreateSBOM(ctx context.Context, pipelinerunID uint, request *CreateSBOMRequest) (*prmodels.SBOM, error)
+	GetSBOM(ctx context.Context, pipelinerunID uint) (*prmodels.SBOM, error)
+	// ListClustersBySBOMComponent lists clusters running images which contain the component
+	ListClustersBySBOMComponent(ctx context.Context, name, version string) ([]*prmodels.ClusterComponent, error)
+
+	// InternalUploadArtifact stores the artifact or report uploaded by the steps of pipelinerun
+	InternalUploadArtifact(ctx context.Context, pipelinerunID uint, r *UploadArtifactRequest) (*Artifact, error)
+	// ListArtifacts lists artifacts of the pipelinerun, all types are listed if artifactType is empty
+	ListArtifacts(ctx context.Context, pipelinerunID uint, artifactType string) ([]*Artifact, error)
+	// GetArtifactDownloadURL returns a presigned url to download the artifact from the artifact storage
+	GetArtifactDownloadURL(ctx context.Context, pipelinerunID, artifactID uint) (string, error)
 }
 
 type controller struct {
@@ -91,11 +135,25 @@ type controller struct {
 	tokenSvc           tokensvc.Service
 	tokenConfig        token.Config
 	memberMgr          membermanager.Manager
+	memberSvc          memberservice.Service
 	templateReleaseMgr trmanager.Manager
 	commitGetter       code.GitGetter
 	clusterGitRepo     gitrepo.ClusterGitRepo
 	userMgr            usermanager.Manager
 	eventSvc           eventservice.Service
+	deployWindowSvc    deploywindow.Service
+	snapshotSvc        snapshotservice.Service
+	pipelineMgr        pipelinemanager.Manager
+	pipelineStepSvc    pipelinestepservice.Service
+
+	deployApprovalConfig deployapprovalconfig.Config
+	deployApprovalMgr    deployapprovalmanager.Manager
+
+	artifactConfig  artifactconfig.Config
+	artifactMgr     artifactmanager.Manager
+	artifactStorage s3.Interface
+
+	sbomConfig sbomconfig.Config
 }
 
 var _ Controller = (*controller)(nil)
@@ -112,11 +170,25 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		commitGetter:       param.GitGetter,
 		appMgr:             param.ApplicationMgr,
 		memberMgr:          param.MemberMgr,
+		memberSvc:          param.MemberService,
 		regionMgr:          param.RegionMgr,
 		clusterGitRepo:     param.ClusterGitRepo,
 		userMgr:            param.UserMgr,
 		templateReleaseMgr: param.TemplateReleaseMgr,
 		eventSvc:           param.EventSvc,
+		deployWindowSvc:    param.DeployWindowSvc,
+		snapshotSvc:        param.SnapshotSvc,
+		pipelineMgr:        param.PipelineMgr,
+		pipelineStepSvc:    param.PipelineStepSvc,
+
+		deployApprovalConfig: config.DeployApprovalConfig,
+		deployApprovalMgr:    param.DeployApprovalMgr,
+
+		artifactConfig:  config.ArtifactConfig,
+		artifactMgr:     param.ArtifactMgr,
+		artifactStorage: param.ArtifactStorage,
+
+		sbomConfig: config.SBOMConfig,
 	}
 }
 
@@ -229,10 +301,16 @@ func (c *controller) GetDiff(ctx context.Context, pipelinerunID uint) (_ *GetDif
 		if err != nil {
 			return nil, err
 		}
+		values, secretValues, err := c.getConfigValuesDiff(ctx, application.Name, cluster.Name,
+			pipelinerun.LastConfigCommit, pipelinerun.ConfigCommit)
+		if err != nil {
+			return nil, err
+		}
 		configDiff = &ConfigDiff{
-			From: pipelinerun.LastConfigCommit,
-			To:   pipelinerun.ConfigCommit,
-			Diff: diff,
+			From:   pipelinerun.LastConfigCommit,
+			To:     pipelinerun.ConfigCommit,
+			Diff:   valuediff.MaskDiff(diff, secretValues),
+			Values: values,
 		}
 	}
 
@@ -242,6 +320,40 @@ func (c *controller) GetDiff(ctx context.Context, pipelinerunID uint) (_ *GetDif
 	}, nil
 }
 
+// getConfigValuesDiff compares config values of the deployed revision with the revision to deploy,
+// it returns the secret values of both revisions as well, which are masked in the raw diff
+func (c *controller) getConfigValuesDiff(ctx context.Context, application, cluster,
+	from, to string) (map[string][]*valuediff.Change, map[string]bool, error) {
+	if from == to {
+		return nil, nil, nil
+	}
+	fromValues, err := c.clusterGitRepo.GetConfigValues(ctx, application, cluster, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	toValues, err := c.clusterGitRepo.GetConfigValues(ctx, application, cluster, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	secretValues := valuediff.SecretValues(fromValues, toValues)
+
+	if fromValues == nil {
+		fromValues = make(map[string]interface{})
+	}
+	for file := range toValues {
+		if _, ok := fromValues[file]; !ok {
+			fromValues[file] = nil
+		}
+	}
+	diffs := make(map[string][]*valuediff.Change)
+	for file, fromValue := range fromValues {
+		if changes := valuediff.Compare(fromValue, toValues[file]); len(changes) > 0 {
+			diffs[file] = changes
+		}
+	}
+	return diffs, secretValues, nil
+}
+
 func (c *controller) GetPipelinerun(ctx context.Context, pipelineID uint) (_ *prmodels.PipelineBasic, err error) {
 	const op = "pipelinerun controller: get pipelinerun basic"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -384,10 +496,46 @@ func (c *controller) Execute(ctx context.Context, pipelinerunID uint, force bool
 			return perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun is not ready to execute")
 		}
 	}
+	// forcing skips the checks, but never the approval
+	if err := c.checkDeployApproval(ctx, pr); err != nil {
+		return err
+	}
+
+	conflicts, err := c.deployWindowSvc.Check(ctx, pr.ClusterID, pr.ID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		c.recordDeployConflicts(ctx, pr, conflicts)
+	}
 
 	return c.execute(ctx, pr)
 }
 
+// recordDeployConflicts leaves a message on the pipelinerun about deploys in progress it collides with
+func (c *controller) recordDeployConflicts(ctx context.Context, pr *prmodels.Pipelinerun,
+	conflicts []*deploywindow.Conflict) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return
+	}
+	descriptions := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		descriptions = append(descriptions, fmt.Sprintf("%s of cluster %s (pipelinerun %d)",
+			conflict.Action, conflict.ClusterName, conflict.PipelinerunID))
+	}
+	_, err = c.prMgr.Message.Create(ctx, &prmodels.PRMessage{
+		PipelineRunID: pr.ID,
+		Content: fmt.Sprintf("executed while other deploys were in progress: %s",
+			strings.Join(descriptions, ", ")),
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to record deploy conflicts of pipelinerun %d: %v", pr.ID, err)
+	}
+}
+
 func (c *controller) execute(ctx context.Context, pr *prmodels.Pipelinerun) error {
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -399,6 +547,7 @@ func (c *controller) execute(ctx context.Context, pr *prmodels.Pipelinerun) erro
 	if err != nil {
 		return err
 	}
+	c.snapshotSvc.TakeBeforeDeploy(ctx, cluster, pr.ConfigCommit, pr.ID)
 
 	// 2. get application
 	application, err := c.appMgr.GetByID(ctx, cluster.ApplicationID)
@@ -449,13 +598,32 @@ func (c *controller) execute(ctx context.Context, pr *prmodels.Pipelinerun) erro
 	if clusterFiles.PipelineJSONBlob != nil {
 		pipelineJSONBlob = clusterFiles.PipelineJSONBlob
 	}
+	customSteps, err := c.pipelineStepSvc.ListPipelineRunSteps(ctx, application.ID,
+		cluster.Template, cluster.TemplateRelease, pr.Action)
+	if err != nil {
+		return err
+	}
+	var rerunFrom *tekton.PipelineRunRerunFrom
+	if pr.RetryOf != nil && pr.RerunFromStep != "" {
+		retried, err := c.prMgr.PipelineRun.GetByID(ctx, *pr.RetryOf)
+		if err != nil {
+			return err
+		}
+		rerunFrom = &tekton.PipelineRunRerunFrom{
+			PipelinerunID: retried.ID,
+			CIEventID:     retried.CIEventID,
+			Task:          pr.RerunFromTask,
+			Step:          pr.RerunFromStep,
+		}
+	}
 
-	ciEventID, err := tektonClient.CreatePipelineRun(ctx, &tekton.PipelineRun{
+	ciEventID, err := tektonClient.CreatePipelineRun(ctx, pipelinestep.WithSBOMStep(c.sbomConfig, &tekton.PipelineRun{
 		Action:           pr.Action,
 		Application:      application.Name,
 		ApplicationID:    application.ID,
 		Cluster:          cluster.Name,
 		ClusterID:        cluster.ID,
+		CustomSteps:      customSteps,
 		Environment:      cluster.EnvironmentName,
 		Git:              prGit,
 		ImageURL:         pr.ImageURL,
@@ -464,9 +632,10 @@ func (c *controller) execute(ctx context.Context, pr *prmodels.Pipelinerun) erro
 		PipelineJSONBlob: pipelineJSONBlob,
 		Region:           cluster.RegionName,
 		RegionID:         regionEntity.ID,
+		RerunFrom:        rerunFrom,
 		Template:         cluster.Template,
 		Token:            token,
-	})
+	}))
 	if err != nil {
 		return err
 	}
@@ -497,8 +666,23 @@ func (c *controller) Cancel(ctx context.Context, pipelinerunID uint) error {
 		return err
 	}
 
-	if pr.Status != string(prmodels.StatusPending) && pr.Status != string(prmodels.StatusReady) {
-		return perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun is not pending or ready to cancel")
+	switch prmodels.PipelineStatus(pr.Status) {
+	case prmodels.StatusPending, prmodels.StatusReady:
+	case prmodels.StatusCreated, prmodels.StatusRunning:
+		// the result reported back by CI after the pipelinerun is stopped is cancelled as well
+		cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+		if err != nil {
+			return err
+		}
+		tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+		if err != nil {
+			return err
+		}
+		if err := tektonClient.StopPipelineRun(ctx, pr.CIEventID); err != nil {
+			return err
+		}
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun is already %s", pr.Status)
 	}
 	err = c.prMgr.PipelineRun.UpdateStatusByID(ctx, pipelinerunID, prmodels.StatusCancelled)
 	if err != nil {
@@ -689,5 +873,9 @@ func (c *controller) calculatePrSuccessStatus(ctx context.Context,
 			return prmodels.StatusPending, nil
 		}
 	}
+	approved, err := c.deployApproved(ctx, cluster.EnvironmentName, pipelinerun.ID)
+	if err != nil || !approved {
+		return prmodels.StatusPending, err
+	}
 	return prmodels.StatusReady, nil
 }
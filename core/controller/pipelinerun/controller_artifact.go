@@ -0,0 +1,257 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	artifactmodels "github.com/horizoncd/horizon/pkg/artifact/models"
+	"github.com/horizoncd/horizon/pkg/artifact/report"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _artifactNameMaxLength = 128
+
+func (c *controller) InternalUploadArtifact(ctx context.Context, pipelinerunID uint,
+	r *UploadArtifactRequest) (*Artifact, error) {
+	const op = "pipelinerun controller: internal upload artifact"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if c.artifactStorage == nil {
+		return nil, perror.Wrap(herrors.ErrArtifactStorageDisabled, "failed to upload artifact")
+	}
+
+	// 1. auth jwt token, the token must be issued for this pipelinerun
+	ctx, user, err := c.authPipelinerunToken(ctx, pipelinerunID, "upload artifact")
+	if err != nil {
+		return nil, err
+	}
+	pr, err := c.getPipelinerun(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. check the artifact and parse the summary of reports
+	if r.Type == "" {
+		r.Type = artifactmodels.TypeArtifact
+	}
+	if err := checkArtifactName(r.Name); err != nil {
+		return nil, err
+	}
+	artifact := &artifactmodels.Artifact{
+		PipelinerunID: pr.ID,
+		ClusterID:     pr.ClusterID,
+		Name:          r.Name,
+		Type:          r.Type,
+		CreatedBy:     user.ID,
+		UpdatedBy:     user.ID,
+	}
+	content, err := c.readArtifact(artifact, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. put the content to the artifact storage, an artifact of the same name is overwritten
+	existing, err := c.artifactMgr.GetByName(ctx, pipelinerunID, r.Name)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+	}
+	if existing != nil {
+		artifact.ID = existing.ID
+	} else {
+		created, err := c.artifactMgr.Create(ctx, &artifactmodels.Artifact{
+			PipelinerunID: artifact.PipelinerunID,
+			ClusterID:     artifact.ClusterID,
+			Name:          artifact.Name,
+			Type:          artifact.Type,
+			CreatedBy:     artifact.CreatedBy,
+			UpdatedBy:     artifact.UpdatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		artifact.ID = created.ID
+	}
+	artifact.ObjectKey = artifactmodels.ObjectPrefix(pipelinerunID, artifact.ID) + artifact.Name
+	if err := c.artifactStorage.PutObject(ctx, artifact.ObjectKey, content,
+		map[string]string{"sha256": artifact.SHA256}); err != nil {
+		if existing == nil {
+			if err := c.artifactMgr.DeleteByID(ctx, artifact.ID); err != nil {
+				log.Warningf(ctx, "failed to delete artifact %v: %v", artifact.ID, err)
+			}
+		}
+		return nil, perror.Wrapf(herrors.ErrS3PutObjFailed, "failed to upload artifact %s: %v", r.Name, err)
+	}
+	if err := c.artifactMgr.UpdateContent(ctx, artifact); err != nil {
+		return nil, err
+	}
+	log.Infof(ctx, "artifact %s of pipelinerun %v is uploaded, size: %v", artifact.Name,
+		pipelinerunID, artifact.Size)
+	return c.getArtifact(ctx, artifact.ID)
+}
+
+// readArtifact fills the size, digest and summary of the artifact,
+// it returns the content to upload which is read from the beginning
+func (c *controller) readArtifact(artifact *artifactmodels.Artifact,
+	r *UploadArtifactRequest) (io.ReadSeeker, error) {
+	if r.Content == nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "artifact content cannot be empty")
+	}
+	maxSize := c.artifactConfig.MaxSize
+	hash := sha256.New()
+	var content io.ReadSeeker
+	switch r.Type {
+	case artifactmodels.TypeArtifact:
+		size, err := io.Copy(hash, io.LimitReader(r.Content, maxSize+1))
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to read artifact: %v", err)
+		}
+		artifact.Size = size
+		if _, err := r.Content.Seek(0, io.SeekStart); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to read artifact: %v", err)
+		}
+		content = r.Content
+	case artifactmodels.TypeJUnit, artifactmodels.TypeCoverage:
+		// reports are parsed in memory
+		data, err := ioutil.ReadAll(io.LimitReader(r.Content, maxSize+1))
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to read artifact: %v", err)
+		}
+		artifact.Size = int64(len(data))
+		hash.Write(data)
+		content = bytes.NewReader(data)
+		if artifact.Size > maxSize {
+			break
+		}
+		var summary interface{}
+		if r.Type == artifactmodels.TypeJUnit {
+			summary, err = report.ParseJUnit(data)
+		} else {
+			var coverage *report.CoverageSummary
+			coverage, err = report.ParseCoverage(r.Format, data)
+			if err == nil {
+				artifact.Format = coverage.Format
+				summary = coverage
+			}
+		}
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		summaryBytes, err := json.Marshal(summary)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		artifact.Summary = string(summaryBytes)
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"unsupported artifact type %q, only %s, %s and %s are supported", r.Type,
+			artifactmodels.TypeArtifact, artifactmodels.TypeJUnit, artifactmodels.TypeCoverage)
+	}
+	if artifact.Size == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "artifact content cannot be empty")
+	}
+	if artifact.Size > maxSize {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "artifact is larger than %v bytes", maxSize)
+	}
+	artifact.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return content, nil
+}
+
+func checkArtifactName(name string) error {
+	if name == "" || name == "." || name == ".." || len(name) > _artifactNameMaxLength ||
+		strings.ContainsAny(name, "/\\") {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid artifact name %q, it should be a file name of at most %v characters",
+			name, _artifactNameMaxLength)
+	}
+	return nil
+}
+
+func (c *controller) ListArtifacts(ctx context.Context, pipelinerunID uint,
+	artifactType string) ([]*Artifact, error) {
+	const op = "pipelinerun controller: list artifacts"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.getPipelinerun(ctx, pipelinerunID); err != nil {
+		return nil, err
+	}
+	artifacts, err := c.artifactMgr.ListByPipelinerunID(ctx, pipelinerunID, artifactType)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		result = append(result, ofArtifact(artifact))
+	}
+	return result, nil
+}
+
+func (c *controller) GetArtifactDownloadURL(ctx context.Context, pipelinerunID,
+	artifactID uint) (string, error) {
+	const op = "pipelinerun controller: get artifact download url"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if c.artifactStorage == nil {
+		return "", perror.Wrap(herrors.ErrArtifactStorageDisabled, "failed to download artifact")
+	}
+	artifact, err := c.artifactMgr.GetByID(ctx, artifactID)
+	if err != nil {
+		return "", err
+	}
+	// the artifact may be in the middle of its first upload
+	if artifact.PipelinerunID != pipelinerunID || artifact.ObjectKey == "" {
+		return "", herrors.NewErrNotFound(herrors.ArtifactInDB,
+			fmt.Sprintf("no artifact found for id %v in pipelinerun %v", artifactID, pipelinerunID))
+	}
+	url, err := c.artifactStorage.GetSignedObjectURL(artifact.ObjectKey, c.artifactConfig.DownloadURLExpireIn)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrS3SignFailed, "failed to sign url of artifact %v: %v", artifactID, err)
+	}
+	return url, nil
+}
+
+func (c *controller) getArtifact(ctx context.Context, artifactID uint) (*Artifact, error) {
+	artifact, err := c.artifactMgr.GetByID(ctx, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	return ofArtifact(artifact), nil
+}
+
+func (c *controller) getPipelinerun(ctx context.Context, pipelinerunID uint) (*prmodels.Pipelinerun, error) {
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, herrors.NewErrNotFound(herrors.PipelinerunInDB,
+			fmt.Sprintf("cannot find the pipelinerun with id: %v", pipelinerunID))
+	}
+	return pr, nil
+}
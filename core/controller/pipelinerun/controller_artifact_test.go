@@ -0,0 +1,197 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/s3"
+	artifactmodels "github.com/horizoncd/horizon/pkg/artifact/models"
+	"github.com/horizoncd/horizon/pkg/artifact/report"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	artifactconfig "github.com/horizoncd/horizon/pkg/config/artifact"
+	"github.com/horizoncd/horizon/pkg/config/token"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	usermodel "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestArtifact(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&prmodels.Pipelinerun{}, &usermodel.User{},
+		&artifactmodels.Artifact{}); err != nil {
+		panic(err)
+	}
+	param := managerparam.InitManager(db)
+	// nolint
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   uint(1),
+	})
+
+	backend := s3mem.New()
+	_ = backend.CreateBucket("artifacts")
+	ts := httptest.NewServer(gofakes3.New(backend).Server())
+	defer ts.Close()
+	storage, err := s3.NewDriver(s3.Params{
+		AccessKey:        "accessKey",
+		SecretKey:        "secretKey",
+		Region:           "us-east-1",
+		Endpoint:         ts.URL,
+		Bucket:           "artifacts",
+		ContentType:      "application/octet-stream",
+		S3ForcePathStyle: true,
+	})
+	assert.Nil(t, err)
+
+	tokenConfig := token.Config{
+		JwtSigningKey:         "hello",
+		CallbackTokenExpireIn: 24 * time.Hour,
+	}
+	ctrl := controller{
+		prMgr:       param.PRMgr,
+		userMgr:     param.UserMgr,
+		tokenSvc:    tokenservice.NewService(param, tokenConfig),
+		tokenConfig: tokenConfig,
+		artifactConfig: artifactconfig.Config{
+			MaxSize:             1024,
+			DownloadURLExpireIn: time.Minute,
+		},
+		artifactMgr:     param.ArtifactMgr,
+		artifactStorage: storage,
+	}
+
+	user, err := param.UserMgr.Create(ctx, &usermodel.User{Name: "Tony"})
+	assert.Nil(t, err)
+	pr, err := param.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: 1,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusRunning),
+	})
+	assert.Nil(t, err)
+	newCtx := func(pipelinerunID uint) context.Context {
+		jwtToken, err := ctrl.tokenSvc.CreateJWTToken(strconv.Itoa(int(user.ID)), time.Hour,
+			tokenservice.WithPipelinerunID(pipelinerunID))
+		assert.Nil(t, err)
+		return common.WithContextJWTTokenString(ctx, jwtToken)
+	}
+
+	// the token must be issued for the pipelinerun
+	_, err = ctrl.InternalUploadArtifact(newCtx(pr.ID+1), pr.ID, &UploadArtifactRequest{
+		Name:    "app.jar",
+		Content: strings.NewReader("jar"),
+	})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = ctrl.InternalUploadArtifact(ctx, pr.ID, &UploadArtifactRequest{
+		Name:    "app.jar",
+		Content: strings.NewReader("jar"),
+	})
+	assert.Equal(t, herrors.ErrTokenInvalid, perror.Cause(err))
+
+	for _, r := range []*UploadArtifactRequest{
+		{Name: "../app.jar", Content: strings.NewReader("jar")},
+		{Name: "app.jar", Type: "unknown", Content: strings.NewReader("jar")},
+		{Name: "app.jar", Content: strings.NewReader("")},
+		{Name: "app.jar", Content: strings.NewReader(strings.Repeat("a", 1025))},
+		{Name: "junit.xml", Type: artifactmodels.TypeJUnit, Content: strings.NewReader("not xml")},
+	} {
+		_, err = ctrl.InternalUploadArtifact(newCtx(pr.ID), pr.ID, r)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), r.Name)
+	}
+
+	jar, err := ctrl.InternalUploadArtifact(newCtx(pr.ID), pr.ID, &UploadArtifactRequest{
+		Name:    "app.jar",
+		Content: strings.NewReader("jar"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, artifactmodels.TypeArtifact, jar.Type)
+	assert.Equal(t, int64(3), jar.Size)
+	assert.Equal(t, "0163f1eea7894350060624d315234d40c508ab251ba121714e234503045faadd", jar.SHA256)
+	assert.Equal(t, user.ID, jar.CreatedBy)
+
+	junit, err := ctrl.InternalUploadArtifact(newCtx(pr.ID), pr.ID, &UploadArtifactRequest{
+		Name: "junit.xml",
+		Type: artifactmodels.TypeJUnit,
+		Content: strings.NewReader(`<testsuite name="pkg"><testcase name="TestA"/>` +
+			`<testcase name="TestB"><failure message="failed"/></testcase></testsuite>`),
+	})
+	assert.Nil(t, err)
+	var summary report.TestSummary
+	assert.Nil(t, json.Unmarshal(junit.Summary, &summary))
+	assert.Equal(t, 2, summary.Tests)
+	assert.Equal(t, 1, summary.Failures)
+
+	// the artifact of the same name is overwritten
+	coverage := "mode: set\na.go:1.1,2.2 3 1\na.go:3.1,4.2 1 0\n"
+	for i := 0; i < 2; i++ {
+		_, err = ctrl.InternalUploadArtifact(newCtx(pr.ID), pr.ID, &UploadArtifactRequest{
+			Name:    "coverage.out",
+			Type:    artifactmodels.TypeCoverage,
+			Content: strings.NewReader(coverage),
+		})
+		assert.Nil(t, err)
+		coverage += "b.go:1.1,2.2 4 1\n"
+	}
+
+	artifacts, err := ctrl.ListArtifacts(ctx, pr.ID, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(artifacts))
+	artifacts, err = ctrl.ListArtifacts(ctx, pr.ID, artifactmodels.TypeCoverage)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(artifacts))
+	assert.Equal(t, report.CoverageGo, artifacts[0].Format)
+	var coverageSummary report.CoverageSummary
+	assert.Nil(t, json.Unmarshal(artifacts[0].Summary, &coverageSummary))
+	assert.Equal(t, int64(7), coverageSummary.LinesCovered)
+	assert.Equal(t, int64(8), coverageSummary.LinesValid)
+	_, err = ctrl.ListArtifacts(ctx, pr.ID+1, "")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// the artifact is downloaded from the storage by the presigned url
+	url, err := ctrl.GetArtifactDownloadURL(ctx, pr.ID, artifacts[0].ID)
+	assert.Nil(t, err)
+	resp, err := http.Get(url)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, coverage[:len(coverage)-len("b.go:1.1,2.2 4 1\n")], string(content))
+
+	_, err = ctrl.GetArtifactDownloadURL(ctx, pr.ID+1, artifacts[0].ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	ctrl.artifactStorage = nil
+	_, err = ctrl.GetArtifactDownloadURL(ctx, pr.ID, artifacts[0].ID)
+	assert.Equal(t, herrors.ErrArtifactStorageDisabled, perror.Cause(err))
+}
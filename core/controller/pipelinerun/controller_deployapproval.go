@@ -0,0 +1,214 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	deployapprovalmodels "github.com/horizoncd/horizon/pkg/deployapproval/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) GetDeployApproval(ctx context.Context, pipelinerunID uint) (*DeployApproval, error) {
+	const op = "pipelinerun controller: get deploy approval"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	approval, err := c.deployApprovalMgr.GetByPipelinerunID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	return ofDeployApproval(approval), nil
+}
+
+func (c *controller) ListDeployApprovals(ctx context.Context, status string) ([]*DeployApproval, error) {
+	const op = "pipelinerun controller: list deploy approvals"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	environments := c.deployApprovalConfig.ApprovableEnvironments(currentUser.GetEmail(), currentUser.IsAdmin())
+	approvals, err := c.deployApprovalMgr.ListByEnvironments(ctx, environments, status)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*DeployApproval, 0, len(approvals))
+	for _, approval := range approvals {
+		resp = append(resp, ofDeployApproval(approval))
+	}
+	return resp, nil
+}
+
+func (c *controller) ReviewDeployApproval(ctx context.Context, pipelinerunID uint,
+	r *ReviewDeployApprovalRequest) (*DeployApproval, error) {
+	const op = "pipelinerun controller: review deploy approval"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	approval, err := c.deployApprovalMgr.GetByPipelinerunID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != deployapprovalmodels.StatusPending {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"deploy of pipelinerun %d has been %s", pipelinerunID, approval.Status)
+	}
+	env := c.deployApprovalConfig.Of(approval.Environment)
+	if !currentUser.IsAdmin() && (env == nil || !env.IsApprover(currentUser.GetEmail())) {
+		return nil, perror.Wrapf(herrors.ErrForbidden,
+			"%s is not an approver of environment %s", currentUser.GetEmail(), approval.Environment)
+	}
+	if approval.CreatedBy == currentUser.GetID() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "deploy cannot be approved by the creator of pipelinerun")
+	}
+
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status != string(prmodels.StatusPending) && pr.Status != string(prmodels.StatusReady) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"pipelinerun %d is %s, its deploy cannot be reviewed", pipelinerunID, pr.Status)
+	}
+
+	// 1. save the review result
+	now := time.Now()
+	approval.Status = deployapprovalmodels.StatusRejected
+	if r.Approved {
+		approval.Status = deployapprovalmodels.StatusApproved
+	}
+	approval.ReviewedBy = currentUser.GetID()
+	approval.ReviewComment = r.Comment
+	approval.ReviewedAt = &now
+	if err := c.deployApprovalMgr.UpdateReview(ctx, approval); err != nil {
+		return nil, err
+	}
+
+	// 2. leave the review result on the pipelinerun
+	content := fmt.Sprintf("%s the deploy", approval.Status)
+	if r.Comment != "" {
+		content = fmt.Sprintf("%s: %s", content, r.Comment)
+	}
+	if _, err := c.prMgr.Message.Create(ctx, &prmodels.PRMessage{
+		PipelineRunID: pipelinerunID,
+		Content:       content,
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}); err != nil {
+		log.Warningf(ctx, "failed to record deploy approval of pipelinerun %d: %v", pipelinerunID, err)
+	}
+
+	// 3. a rejected pipelinerun is cancelled, an approved one gets ready if all checks passed
+	if !r.Approved {
+		if err := c.prMgr.PipelineRun.UpdateStatusByID(ctx, pipelinerunID, prmodels.StatusCancelled); err != nil {
+			return nil, err
+		}
+		c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pipelinerunID,
+			eventmodels.PipelinerunRejected, nil)
+		return ofDeployApproval(approval), nil
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pipelinerunID,
+		eventmodels.PipelinerunApproved, nil)
+	if pr.Status == string(prmodels.StatusPending) {
+		status, err := c.calculatePrSuccessStatus(ctx, pr)
+		if err != nil {
+			return nil, err
+		}
+		if status == prmodels.StatusReady {
+			if err := c.prMgr.PipelineRun.UpdateStatusByID(ctx, pipelinerunID, status); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ofDeployApproval(approval), nil
+}
+
+// checkDeployApproval returns ErrDeployApprovalRequired if the pipelinerun deploys to
+// a protected environment and has not been approved
+func (c *controller) checkDeployApproval(ctx context.Context, pr *prmodels.Pipelinerun) error {
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return err
+	}
+	if c.deployApprovalConfig.Of(cluster.EnvironmentName) == nil {
+		return nil
+	}
+	approval, err := c.deployApprovalMgr.GetByPipelinerunID(ctx, pr.ID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		// the pipelinerun was created before the environment was protected
+		approval, err = c.requestDeployApproval(ctx, pr, cluster.EnvironmentName)
+		if err != nil {
+			return err
+		}
+	}
+	if approval.Status != deployapprovalmodels.StatusApproved {
+		return perror.Wrapf(herrors.ErrDeployApprovalRequired,
+			"deploy of pipelinerun %d is %s, it must be approved by approvers of environment %s",
+			pr.ID, approval.Status, cluster.EnvironmentName)
+	}
+	return nil
+}
+
+// requestDeployApproval records a pending approval of the pipelinerun deploying to a protected environment
+func (c *controller) requestDeployApproval(ctx context.Context, pr *prmodels.Pipelinerun,
+	environment string) (*deployapprovalmodels.DeployApproval, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	approval, err := c.deployApprovalMgr.Create(ctx, &deployapprovalmodels.DeployApproval{
+		PipelinerunID: pr.ID,
+		ClusterID:     pr.ClusterID,
+		Environment:   environment,
+		Status:        deployapprovalmodels.StatusPending,
+		CreatedBy:     currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pr.ID,
+		eventmodels.PipelinerunAwaiting, nil)
+	return approval, nil
+}
+
+// deployApproved tells whether the pipelinerun can deploy to the environment as far as approval is concerned
+func (c *controller) deployApproved(ctx context.Context, environment string, pipelinerunID uint) (bool, error) {
+	if c.deployApprovalConfig.Of(environment) == nil {
+		return true, nil
+	}
+	approval, err := c.deployApprovalMgr.GetByPipelinerunID(ctx, pipelinerunID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return approval.Status == deployapprovalmodels.StatusApproved, nil
+}
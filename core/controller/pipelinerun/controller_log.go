@@ -0,0 +1,225 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// _eofLog is sent by the log reader at the end of each step
+const _eofLog = "EOFLOG"
+
+// _followLogInterval is the interval to read the logs of a running pipelinerun again
+var _followLogInterval = 2 * time.Second
+
+// _logLinePattern matches the lines of the logs collected, such as "[task : step] log"
+var _logLinePattern = regexp.MustCompile(`^\[(.+?) : (.+?)\] (.*)$`)
+
+// LogLine is a line of the logs of a pipelinerun, the lines without task and step are errors
+type LogLine struct {
+	Task string `json:"task,omitempty"`
+	Step string `json:"step,omitempty"`
+	Log  string `json:"log"`
+}
+
+func (l *LogLine) key() string {
+	if l.Task == "" && l.Step == "" {
+		return ""
+	}
+	return l.Task + "/" + l.Step
+}
+
+// LogCursor counts the lines sent of each step, so that a stream of logs is resumed where it stopped.
+// Steps are counted separately, since the logs of a running step grow while the steps after it are read.
+type LogCursor map[string]int
+
+// String encodes the cursor as the token to resume the stream
+func (c LogCursor) String() string {
+	values := url.Values{}
+	for key, n := range c {
+		values.Set(key, strconv.Itoa(n))
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(values.Encode()))
+}
+
+// ParseLogCursor decodes the token to resume a stream, an empty token starts from the beginning
+func ParseLogCursor(token string) (LogCursor, error) {
+	cursor := LogCursor{}
+	if token == "" {
+		return cursor, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+	values, err := url.ParseQuery(string(decoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+	for key := range values {
+		n, err := strconv.Atoi(values.Get(key))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid cursor: invalid count of %s", key)
+		}
+		cursor[key] = n
+	}
+	return cursor, nil
+}
+
+// logFinished tells whether no more logs will be produced by the pipelinerun
+func logFinished(pr *prmodels.Pipelinerun) bool {
+	if pr.PrObject != "" {
+		return true
+	}
+	switch prmodels.PipelineStatus(pr.Status) {
+	case prmodels.StatusCreated, prmodels.StatusRunning, prmodels.StatusPending, prmodels.StatusReady:
+		return false
+	default:
+		return true
+	}
+}
+
+// parseLogBytes parses the logs collected, the blank lines between steps are skipped
+func parseLogBytes(logBytes []byte) []*LogLine {
+	var lines []*LogLine
+	for _, line := range strings.Split(string(logBytes), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if matches := _logLinePattern.FindStringSubmatch(line); matches != nil {
+			lines = append(lines, &LogLine{Task: matches[1], Step: matches[2], Log: matches[3]})
+			continue
+		}
+		lines = append(lines, &LogLine{Log: line})
+	}
+	return lines
+}
+
+// readLogLines reads all the lines of the logs, errors are returned separately,
+// since the steps not started yet fail to be read while the pipelinerun is running
+func readLogLines(l *collector.Log) ([]*LogLine, []*LogLine) {
+	if l.LogBytes != nil {
+		return parseLogBytes(l.LogBytes), nil
+	}
+
+	var lines, errLines []*LogLine
+	logC, errC := l.LogChannel, l.ErrChannel
+	for logC != nil || errC != nil {
+		select {
+		case l, ok := <-logC:
+			if !ok {
+				logC = nil
+				continue
+			}
+			if l.Log == _eofLog {
+				continue
+			}
+			lines = append(lines, &LogLine{Task: l.Task, Step: l.Step, Log: l.Log})
+		case e, ok := <-errC:
+			if !ok {
+				errC = nil
+				continue
+			}
+			errLines = append(errLines, &LogLine{Log: e.Error()})
+		}
+	}
+	return lines, errLines
+}
+
+// unsent filters the lines which are not counted by the cursor, and counts them
+func unsent(lines []*LogLine, cursor LogCursor) []*LogLine {
+	var batch []*LogLine
+	read := LogCursor{}
+	for _, line := range lines {
+		key := line.key()
+		read[key]++
+		if read[key] > cursor[key] {
+			batch = append(batch, line)
+			cursor[key] = read[key]
+		}
+	}
+	return batch
+}
+
+func (c *controller) StreamPipelinerunLog(ctx context.Context, pipelinerunID uint, follow bool,
+	cursor LogCursor, send func(lines []*LogLine, cursor LogCursor) error) (err error) {
+	const op = "pipelinerun controller: stream pipelinerun log"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return err
+	}
+	// only builddeploy and deploy have logs
+	if pr.Action != prmodels.ActionBuildDeploy && pr.Action != prmodels.ActionDeploy {
+		return errors.E(op, fmt.Errorf("%v action has no log", pr.Action))
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	if cursor == nil {
+		cursor = LogCursor{}
+	}
+
+	for {
+		// the status is checked before reading, so that the logs read after the pipelinerun finished are complete
+		finished := logFinished(pr)
+		l, err := c.getPipelinerunLog(ctx, pr, cluster.EnvironmentName)
+		if err != nil {
+			if finished || !follow {
+				return err
+			}
+			log.Warningf(ctx, "failed to read the log of pipelinerun %d: %v", pr.ID, err)
+		} else {
+			lines, errLines := readLogLines(l)
+			if finished || !follow {
+				lines = append(lines, errLines...)
+			}
+			if batch := unsent(lines, cursor); len(batch) > 0 {
+				if err := send(batch, cursor); err != nil {
+					return err
+				}
+			}
+		}
+		if finished || !follow {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(_followLogInterval):
+		}
+		pr, err = c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+		if err != nil {
+			return perror.WithMessagef(err, "failed to get pipelinerun %d", pipelinerunID)
+		}
+	}
+}
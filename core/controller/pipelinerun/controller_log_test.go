@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	tektoncollectormock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/collector"
+	tektonftymock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/factory"
+	clustermodel "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestLogCursor(t *testing.T) {
+	cursor := LogCursor{"build/compile": 3, "build/image": 1, "": 2}
+	parsed, err := ParseLogCursor(cursor.String())
+	assert.Nil(t, err)
+	assert.Equal(t, cursor, parsed)
+
+	parsed, err = ParseLogCursor("")
+	assert.Nil(t, err)
+	assert.Empty(t, parsed)
+
+	_, err = ParseLogCursor("not a cursor!")
+	assert.NotNil(t, err)
+	_, err = ParseLogCursor("YT0tMQ")
+	assert.NotNil(t, err)
+}
+
+func TestParseLogBytes(t *testing.T) {
+	lines := parseLogBytes([]byte("[build : compile] go build\n[build : compile] \n\n" +
+		"[build : image] [1/2] FROM golang\n\nfailed to get log of task deploy\n"))
+	assert.Equal(t, []*LogLine{
+		{Task: "build", Step: "compile", Log: "go build"},
+		{Task: "build", Step: "compile", Log: ""},
+		{Task: "build", Step: "image", Log: "[1/2] FROM golang"},
+		{Log: "failed to get log of task deploy"},
+	}, lines)
+}
+
+func TestStreamPipelinerunLog(t *testing.T) {
+	interval := _followLogInterval
+	_followLogInterval = 10 * time.Millisecond
+	defer func() { _followLogInterval = interval }()
+
+	mockCtl := gomock.NewController(t)
+	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodel.Cluster{
+		Name:            "cluster-stream-log",
+		EnvironmentName: "test",
+		RegionName:      "hz",
+	}, nil, nil)
+	assert.Nil(t, err)
+	pipelinerunMgr := manager.PRMgr.PipelineRun
+	pipelinerun, err := pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusRunning),
+		CreatedBy: 1,
+	})
+	assert.Nil(t, err)
+
+	c := &controller{
+		prMgr:      manager.PRMgr,
+		clusterMgr: manager.ClusterMgr,
+		tektonFty:  tektonFty,
+	}
+
+	// reads the running pipelinerun, and then the logs collected once it finished
+	liveLog := func() *collector.Log {
+		logCh := make(chan log.Log)
+		errCh := make(chan error)
+		go func() {
+			defer close(logCh)
+			defer close(errCh)
+			for _, l := range []string{"0", "1", "EOFLOG"} {
+				logCh <- log.Log{Task: "build", Step: "compile", Log: l}
+			}
+			errCh <- errors.New("task test has not started yet")
+		}()
+		return &collector.Log{LogChannel: logCh, ErrChannel: errCh}
+	}
+	gomock.InOrder(
+		tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, _ interface{}) (*collector.Log, error) {
+				return liveLog(), nil
+			}),
+		tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
+			Return(&collector.Log{
+				LogBytes: []byte("[build : compile] 0\n[build : compile] 1\n\n[test : run] 2\n\n"),
+			}, nil),
+	)
+
+	var (
+		batches [][]*LogLine
+		token   string
+	)
+	// resumes after the first line
+	err = c.StreamPipelinerunLog(ctx, pipelinerun.ID, true, LogCursor{"build/compile": 1},
+		func(lines []*LogLine, cursor LogCursor) error {
+			batches = append(batches, lines)
+			token = cursor.String()
+			if len(batches) == 1 {
+				return pipelinerunMgr.UpdateColumns(ctx, pipelinerun.ID, map[string]interface{}{
+					"status":    string(prmodels.StatusOK),
+					"pr_object": "prObject",
+				})
+			}
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]*LogLine{
+		{{Task: "build", Step: "compile", Log: "1"}},
+		{{Task: "test", Step: "run", Log: "2"}},
+	}, batches)
+	cursor, err := ParseLogCursor(token)
+	assert.Nil(t, err)
+	assert.Equal(t, LogCursor{"build/compile": 2, "test/run": 1}, cursor)
+
+	// the stream without follow ends once the logs are sent
+	tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
+		Return(&collector.Log{LogBytes: []byte("[test : run] 2\n[test : run] 3\n")}, nil)
+	batches = nil
+	err = c.StreamPipelinerunLog(ctx, pipelinerun.ID, false, cursor,
+		func(lines []*LogLine, cursor LogCursor) error {
+			batches = append(batches, lines)
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]*LogLine{{{Task: "test", Step: "run", Log: "3"}}}, batches)
+}
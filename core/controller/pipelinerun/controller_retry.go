@@ -0,0 +1,202 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/pipeline/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) Retry(ctx context.Context, pipelinerunID uint) (*prmodels.PipelineBasic, error) {
+	const op = "pipelinerun controller: retry pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pr, err := c.getRetryablePipelinerun(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	return c.retry(ctx, pr, nil)
+}
+
+func (c *controller) RerunFromStep(ctx context.Context, pipelinerunID uint,
+	r *RerunFromStepRequest) (*prmodels.PipelineBasic, error) {
+	const op = "pipelinerun controller: rerun pipelinerun from step"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if (r.Task == "") != (r.Step == "") {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "task and step must be specified together")
+	}
+	pr, err := c.getRetryablePipelinerun(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+
+	// steps are recorded when the pipelinerun finishes
+	steps, err := c.pipelineMgr.ListSteps(ctx, pr.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"steps of pipelinerun %d are not recorded, please retry it instead", pr.ID)
+	}
+	var from *pipelinemodels.Step
+	for _, step := range steps {
+		if (r.Step == "" && step.Result == string(prmodels.StatusFailed)) ||
+			(step.Task == r.Task && step.Step == r.Step) {
+			from = step
+			break
+		}
+	}
+	if from == nil {
+		if r.Step == "" {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun %d has no failed step", pr.ID)
+		}
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"step %s of task %s is not found in pipelinerun %d", r.Step, r.Task, pr.ID)
+	}
+	return c.retry(ctx, pr, from)
+}
+
+// getRetryablePipelinerun gets the pipelinerun which has run in CI but not succeeded
+func (c *controller) getRetryablePipelinerun(ctx context.Context, pipelinerunID uint) (*prmodels.Pipelinerun, error) {
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Action != prmodels.ActionBuildDeploy && pr.Action != prmodels.ActionDeploy {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"pipelinerun %d is a %s, only builddeploy and deploy can be retried", pr.ID, pr.Action)
+	}
+	if pr.Status != string(prmodels.StatusFailed) && pr.Status != string(prmodels.StatusCancelled) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"pipelinerun %d is %s, only failed or cancelled pipelineruns can be retried", pr.ID, pr.Status)
+	}
+	if pr.CIEventID == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"pipelinerun %d was cancelled before running, please create a new one instead", pr.ID)
+	}
+	return pr, nil
+}
+
+// retry creates a pipelinerun building and deploying what the pipelinerun retried did,
+// it runs at once unless it has to be checked or approved like a pipelinerun newly created.
+func (c *controller) retry(ctx context.Context, pr *prmodels.Pipelinerun,
+	from *pipelinemodels.Step) (*prmodels.PipelineBasic, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.appMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	approvalRequired := c.deployApprovalConfig.Of(cluster.EnvironmentName) != nil
+	checks, err := c.prSvc.GetCheckByResource(ctx, cluster.ID, common.ResourceCluster)
+	if err != nil {
+		return nil, err
+	}
+	status := prmodels.StatusPending
+	if len(checks) == 0 && !approvalRequired {
+		status = prmodels.StatusReady
+	}
+
+	// fail before creating the pipelinerun if it cannot run now
+	var conflicts []*deploywindow.Conflict
+	if status == prmodels.StatusReady {
+		conflicts, err = c.deployWindowSvc.Check(ctx, cluster.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	retryOf := pr.ID
+	newPr := &prmodels.Pipelinerun{
+		ClusterID:        pr.ClusterID,
+		Action:           pr.Action,
+		Status:           string(status),
+		Title:            pr.Title,
+		Description:      pr.Description,
+		GitURL:           pr.GitURL,
+		GitRef:           pr.GitRef,
+		GitRefType:       pr.GitRefType,
+		GitCommit:        pr.GitCommit,
+		ImageURL:         pr.ImageURL,
+		LastConfigCommit: configCommit.Master,
+		ConfigCommit:     configCommit.Gitops,
+		RetryOf:          &retryOf,
+		CreatedBy:        currentUser.GetID(),
+	}
+	extra := eventmodels.PipelinerunRetry{RetryOf: pr.ID}
+	if from != nil {
+		newPr.RerunFromTask, newPr.RerunFromStep = from.Task, from.Step
+		extra.Task, extra.Step = from.Task, from.Step
+	}
+	if newPr, err = c.prMgr.PipelineRun.Create(ctx, newPr); err != nil {
+		return nil, err
+	}
+
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, newPr.ID,
+		eventmodels.PipelinerunCreated, nil)
+	if extraBytes, err := json.Marshal(extra); err != nil {
+		log.Warningf(ctx, "failed to marshal retry of pipelinerun %d, err: %v", newPr.ID, err)
+	} else {
+		extraStr := string(extraBytes)
+		c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, newPr.ID,
+			eventmodels.PipelinerunRetried, &extraStr)
+	}
+
+	if approvalRequired {
+		if _, err := c.requestDeployApproval(ctx, newPr, cluster.EnvironmentName); err != nil {
+			return nil, err
+		}
+	}
+	if status == prmodels.StatusReady {
+		if len(conflicts) > 0 {
+			c.recordDeployConflicts(ctx, newPr, conflicts)
+		}
+		if err := c.execute(ctx, newPr); err != nil {
+			return nil, err
+		}
+		if newPr, err = c.prMgr.PipelineRun.GetByID(ctx, newPr.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	firstCanRollbackPipelinerun, err := c.prMgr.PipelineRun.GetFirstCanRollbackPipelinerun(ctx, newPr.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	return c.prSvc.OfPipelineBasic(ctx, newPr, firstCanRollbackPipelinerun)
+}
@@ -0,0 +1,239 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	tektonmock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton"
+	tektonftymock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/factory"
+	pipelinestepmock "github.com/horizoncd/horizon/mock/pkg/pipelinestep"
+	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodel "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	snapshotmodels "github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	snapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	deploywindowconfig "github.com/horizoncd/horizon/pkg/config/deploywindow"
+	"github.com/horizoncd/horizon/pkg/config/token"
+	deploylockmodels "github.com/horizoncd/horizon/pkg/deploylock/models"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/pipelinestep"
+	pipelinestepmodels "github.com/horizoncd/horizon/pkg/pipelinestep/models"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/pipeline/models"
+	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	usermodel "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestRetryPipelinerun(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&applicationmodel.Application{}, &clustermodel.Cluster{},
+		&regionmodels.Region{}, &membermodels.Member{}, &registrymodels.Registry{},
+		&prmodels.Pipelinerun{}, &groupmodels.Group{}, &prmodels.Check{},
+		&usermodel.User{}, &trmodels.TemplateRelease{}, &prmodels.PRMessage{},
+		&deploylockmodels.DeployLock{}, &tagmodels.Tag{}, &snapshotmodels.ClusterSnapshot{},
+		&eventmodels.Event{}, &pipelinemodels.Step{}, &pipelinestepmodels.Setting{}); err != nil {
+		panic(err)
+	}
+	param := managerparam.InitManager(db)
+	// nolint
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   uint(1),
+	})
+	mockCtl := gomock.NewController(t)
+
+	var created []*tekton.PipelineRun
+	mockTektonInterface := tektonmock.NewMockInterface(mockCtl)
+	mockTektonInterface.EXPECT().CreatePipelineRun(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, pr *tekton.PipelineRun) (string, error) {
+			created = append(created, pr)
+			return fmt.Sprintf("event-%d", len(created)), nil
+		}).AnyTimes()
+	mockFactory := tektonftymock.NewMockFactory(mockCtl)
+	mockFactory.EXPECT().GetTekton(gomock.Any()).Return(mockTektonInterface, nil).AnyTimes()
+
+	mockClusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	mockClusterGitRepo.EXPECT().GetCluster(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&clustergitrepo.ClusterFiles{
+			PipelineJSONBlob:    map[string]interface{}{},
+			ApplicationJSONBlob: map[string]interface{}{},
+		}, nil).AnyTimes()
+	mockClusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&clustergitrepo.ClusterCommit{Master: "master", Gitops: "gitops"}, nil).AnyTimes()
+
+	mockPipelineStepGetter := pipelinestepmock.NewMockGetter(mockCtl)
+	mockPipelineStepGetter.EXPECT().GetSteps(gomock.Any(), "javaapp", "v1.0.0").Return([]*pipelinestep.Step{
+		{Name: "scan", Stage: pipelinestep.StageAfterBuild, Image: "scanner", Script: "scan", Enabled: true},
+		{Name: "e2e", Stage: pipelinestep.StageAfterDeploy, Image: "tester", Script: "test"},
+	}, nil).AnyTimes()
+
+	tokenConfig := token.Config{
+		JwtSigningKey:         "hello",
+		CallbackTokenExpireIn: 24 * time.Hour,
+	}
+	ctrl := controller{
+		prMgr:              param.PRMgr,
+		prSvc:              prservice.NewService(param),
+		appMgr:             param.ApplicationMgr,
+		clusterMgr:         param.ClusterMgr,
+		regionMgr:          param.RegionMgr,
+		tektonFty:          mockFactory,
+		tokenSvc:           tokenservice.NewService(param, tokenConfig),
+		tokenConfig:        tokenConfig,
+		clusterGitRepo:     mockClusterGitRepo,
+		templateReleaseMgr: param.TemplateReleaseMgr,
+		eventSvc:           eventservice.New(param),
+		deployWindowSvc:    deploywindow.NewService(param, deploywindowconfig.Config{}, nil),
+		snapshotSvc:        snapshotservice.NewService(param),
+		pipelineMgr:        param.PipelineMgr,
+		pipelineStepSvc:    pipelinestepservice.NewService(mockPipelineStepGetter, param),
+	}
+
+	_, err := param.UserMgr.Create(ctx, &usermodel.User{Name: "Tony"})
+	assert.NoError(t, err)
+	group, err := param.GroupMgr.Create(ctx, &groupmodels.Group{Name: "test"})
+	assert.NoError(t, err)
+	app, err := param.ApplicationMgr.Create(ctx, &applicationmodel.Application{
+		Name:    "test",
+		GroupID: group.ID,
+	}, nil)
+	assert.NoError(t, err)
+	registryID, err := param.RegistryMgr.Create(ctx, &registrymodels.Registry{Name: "test"})
+	assert.NoError(t, err)
+	region, err := param.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:       "test",
+		RegistryID: registryID,
+	})
+	assert.NoError(t, err)
+	cluster, err := param.ClusterMgr.Create(ctx, &clustermodel.Cluster{
+		Name:            "cluster",
+		ApplicationID:   app.ID,
+		GitURL:          "hello",
+		RegionName:      region.Name,
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	}, nil, nil)
+	assert.NoError(t, err)
+	_, err = param.TemplateReleaseMgr.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: "javaapp",
+		Name:         "v1.0.0",
+	})
+	assert.NoError(t, err)
+
+	failed, err := param.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusFailed),
+		GitCommit: "commit",
+		ImageURL:  "image",
+		CIEventID: "failed",
+	})
+	assert.NoError(t, err)
+	ready, err := param.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusReady),
+	})
+	assert.NoError(t, err)
+
+	_, err = ctrl.Retry(ctx, ready.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// retry reruns all the steps with the same commit and image
+	retried, err := ctrl.Retry(ctx, failed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(prmodels.StatusRunning), retried.Status)
+	assert.Equal(t, failed.ID, *retried.RetryOf)
+	assert.Equal(t, "gitops", retried.ConfigCommit)
+	assert.Equal(t, 1, len(created))
+	assert.Equal(t, retried.ID, created[0].PipelinerunID)
+	assert.Equal(t, "commit", created[0].Git.Commit)
+	assert.Equal(t, "image", created[0].ImageURL)
+	assert.Nil(t, created[0].RerunFrom)
+	// the steps enabled by the template are materialized into the pipeline
+	assert.Equal(t, 1, len(created[0].CustomSteps))
+	assert.Equal(t, "scan", created[0].CustomSteps[0].Name)
+
+	// steps are not recorded until the pipelinerun finishes
+	_, err = ctrl.RerunFromStep(ctx, failed.ID, &RerunFromStepRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	for _, step := range []*pipelinemodels.Step{
+		{PipelinerunID: failed.ID, Task: "build", Step: "git", Result: string(prmodels.StatusOK)},
+		{PipelinerunID: failed.ID, Task: "build", Step: "compile", Result: string(prmodels.StatusOK)},
+		{PipelinerunID: failed.ID, Task: "build", Step: "image", Result: string(prmodels.StatusFailed)},
+	} {
+		assert.NoError(t, db.Create(step).Error)
+	}
+
+	_, err = ctrl.RerunFromStep(ctx, failed.ID, &RerunFromStepRequest{Task: "build"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctrl.RerunFromStep(ctx, failed.ID, &RerunFromStepRequest{Task: "build", Step: "deploy"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// rerun from the first failed step by default
+	rerun, err := ctrl.RerunFromStep(ctx, failed.ID, &RerunFromStepRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "build", rerun.RerunFromTask)
+	assert.Equal(t, "image", rerun.RerunFromStep)
+	assert.Equal(t, 2, len(created))
+	assert.Equal(t, &tekton.PipelineRunRerunFrom{
+		PipelinerunID: failed.ID,
+		CIEventID:     "failed",
+		Task:          "build",
+		Step:          "image",
+	}, created[1].RerunFrom)
+
+	rerun, err = ctrl.RerunFromStep(ctx, failed.ID, &RerunFromStepRequest{Task: "build", Step: "compile"})
+	assert.NoError(t, err)
+	assert.Equal(t, "compile", rerun.RerunFromStep)
+	assert.Equal(t, "compile", created[2].RerunFrom.Step)
+
+	// retries and reruns are recorded as events of the new pipelineruns
+	events, err := param.EventMgr.ListEventsByRange(ctx, 0, 100)
+	assert.NoError(t, err)
+	retriedEvents := 0
+	for _, event := range events {
+		if event.EventType == eventmodels.PipelinerunRetried {
+			retriedEvents++
+		}
+	}
+	assert.Equal(t, 3, retriedEvents)
+}
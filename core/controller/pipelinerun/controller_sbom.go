@@ -0,0 +1,174 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/sbom"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) InternalCreateSBOM(ctx context.Context, pipelinerunID uint,
+	request *CreateSBOMRequest) (*prmodels.SBOM, error) {
+	const op = "pipelinerun controller: internal create sbom"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. auth jwt token, the token must be issued for this pipelinerun
+	ctx, user, err := c.authPipelinerunToken(ctx, pipelinerunID, "upload sbom")
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. parse components
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, herrors.NewErrNotFound(herrors.PipelinerunInDB,
+			fmt.Sprintf("cannot find the pipelinerun with id: %v", pipelinerunID))
+	}
+	if len(request.Content) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "sbom content cannot be empty")
+	}
+	format := request.Format
+	if format == "" {
+		format = sbom.DetectFormat(request.Content)
+	}
+	components, err := sbom.Parse(format, request.Content)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	imageURL := request.ImageURL
+	if imageURL == "" {
+		imageURL = pr.ImageURL
+	}
+
+	// 3. store sbom as an artifact of pipelinerun
+	sbomComponents := make([]*prmodels.SBOMComponent, 0, len(components))
+	for _, component := range components {
+		sbomComponents = append(sbomComponents, &prmodels.SBOMComponent{
+			Name:    component.Name,
+			Version: component.Version,
+			Type:    component.Type,
+			PURL:    component.PURL,
+		})
+	}
+	created, err := c.prMgr.SBOM.Create(ctx, &prmodels.SBOM{
+		PipelineRunID: pipelinerunID,
+		ImageURL:      imageURL,
+		Format:        format,
+		Content:       string(request.Content),
+		CreatedBy:     user.ID,
+		UpdatedBy:     user.ID,
+	}, sbomComponents)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof(ctx, "sbom of pipelinerun %v is created with %v components", pipelinerunID, len(sbomComponents))
+	return created, nil
+}
+
+// authPipelinerunToken authenticates the jwt token issued for the pipelinerun,
+// and returns the context of the user who the token is issued to
+func (c *controller) authPipelinerunToken(ctx context.Context, pipelinerunID uint,
+	action string) (context.Context, *usermodels.User, error) {
+	jwtTokenString, err := common.JWTTokenStringFromContext(ctx)
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrTokenInvalid, "%v", err.Error())
+	}
+	claims, err := c.tokenSvc.ParseJWTToken(jwtTokenString)
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrTokenInvalid, "%v", err.Error())
+	}
+	if claims.PipelinerunID == nil || *claims.PipelinerunID != pipelinerunID {
+		return nil, nil, perror.Wrapf(herrors.ErrForbidden,
+			"no permission to %s for pipelinerun %v", action, pipelinerunID)
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrTokenInvalid, "%v", err.Error())
+	}
+	user, err := c.userMgr.GetUserByID(ctx, uint(userID))
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+	return ctx, user, nil
+}
+
+func (c *controller) GetSBOM(ctx context.Context, pipelinerunID uint) (*prmodels.SBOM, error) {
+	const op = "pipelinerun controller: get sbom"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.prMgr.SBOM.GetLatestByPipelineRunID(ctx, pipelinerunID)
+}
+
+func (c *controller) ListClustersBySBOMComponent(ctx context.Context,
+	name, version string) ([]*prmodels.ClusterComponent, error) {
+	const op = "pipelinerun controller: list clusters by sbom component"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if name == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "component name cannot be empty")
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	components, err := c.prMgr.SBOM.ListClustersByComponent(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser.IsAdmin() {
+		return components, nil
+	}
+
+	// only the clusters which the current user is a member of are listed
+	authedComponents := make([]*prmodels.ClusterComponent, 0, len(components))
+	authed := make(map[uint]bool)
+	for _, component := range components {
+		isMember, ok := authed[component.ClusterID]
+		if !ok {
+			strID := strconv.FormatUint(uint64(component.ClusterID), 10)
+			member, err := c.memberSvc.GetMemberOfResource(ctx, common.ResourceCluster, strID)
+			if err != nil {
+				return nil, err
+			}
+			isMember = member != nil
+			authed[component.ClusterID] = isMember
+		}
+		if isMember {
+			authedComponents = append(authedComponents, component)
+		}
+	}
+	return authedComponents, nil
+}
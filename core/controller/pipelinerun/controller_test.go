@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmockmanager "github.com/horizoncd/horizon/mock/pkg/application/manager"
@@ -36,6 +37,7 @@ import (
 	tektoncollectormock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/collector"
 	tektonftymock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/factory"
 	pipelinemockmanager "github.com/horizoncd/horizon/mock/pkg/pipelinerun/manager"
+	pipelinestepmock "github.com/horizoncd/horizon/mock/pkg/pipelinestep"
 	usermock "github.com/horizoncd/horizon/mock/pkg/user/manager"
 	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
@@ -44,20 +46,33 @@ import (
 	clustermodel "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	snapshotmodels "github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	snapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	deployapprovalconfig "github.com/horizoncd/horizon/pkg/config/deployapproval"
+	deploywindowconfig "github.com/horizoncd/horizon/pkg/config/deploywindow"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	deployapprovalmodels "github.com/horizoncd/horizon/pkg/deployapproval/models"
+	deploylockmodels "github.com/horizoncd/horizon/pkg/deploylock/models"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
 	envmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/git"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	"github.com/horizoncd/horizon/pkg/pr/models"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	"github.com/horizoncd/horizon/pkg/util/valuediff"
 
 	pipelinemodel "github.com/horizoncd/horizon/pkg/pr/models"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
@@ -213,9 +228,17 @@ func TestGetDiff(t *testing.T) {
 			Message: commitMsg,
 		}, nil)
 
-	diff := "this is mydiff"
+	diff := "--- a/application.yaml\n+++ b/application.yaml\n@@ -1,2 +1,2 @@\n-password: old\n+password: new\n replicas: 1"
 	mockClusterGitRepo.EXPECT().CompareConfig(ctx, applicationName, clusterName,
 		&lastConfigCommit, &configCommit).Return(diff, nil).Times(1)
+	mockClusterGitRepo.EXPECT().GetConfigValues(ctx, applicationName, clusterName, lastConfigCommit).
+		Return(map[string]interface{}{
+			"application.yaml": map[string]interface{}{"password": "old", "replicas": float64(1)},
+		}, nil).Times(1)
+	mockClusterGitRepo.EXPECT().GetConfigValues(ctx, applicationName, clusterName, configCommit).
+		Return(map[string]interface{}{
+			"application.yaml": map[string]interface{}{"password": "new", "replicas": float64(1)},
+		}, nil).Times(1)
 
 	resp, err := ctl.GetDiff(ctx, pipelineID)
 	assert.Nil(t, err)
@@ -233,7 +256,17 @@ func TestGetDiff(t *testing.T) {
 		ConfigDiff: &ConfigDiff{
 			From: lastConfigCommit,
 			To:   configCommit,
-			Diff: diff,
+			Diff: "--- a/application.yaml\n+++ b/application.yaml\n@@ -1,2 +1,2 @@\n" +
+				"-password: ******\n+password: ******\n replicas: 1",
+			Values: map[string][]*valuediff.Change{
+				"application.yaml": {{
+					Path:   "password",
+					Type:   valuediff.ChangeModified,
+					From:   valuediff.MaskedValue,
+					To:     valuediff.MaskedValue,
+					Masked: true,
+				}},
+			},
 		},
 	}
 	assert.Equal(t, *expectResp, *resp)
@@ -399,7 +432,9 @@ func TestExecutePipelineRun(t *testing.T) {
 	if err := db.AutoMigrate(&applicationmodel.Application{}, &clustermodel.Cluster{},
 		&regionmodels.Region{}, &membermodels.Member{}, &registrymodels.Registry{},
 		&prmodels.Pipelinerun{}, &groupmodels.Group{}, &prmodels.Check{},
-		&usermodel.User{}, &trmodels.TemplateRelease{}); err != nil {
+		&usermodel.User{}, &trmodels.TemplateRelease{}, &prmodels.PRMessage{},
+		&deploylockmodels.DeployLock{}, &tagmodels.Tag{}, &snapshotmodels.ClusterSnapshot{},
+		&eventmodels.Event{}); err != nil {
 		panic(err)
 	}
 	param := managerparam.InitManager(db)
@@ -421,6 +456,8 @@ func TestExecutePipelineRun(t *testing.T) {
 	}
 
 	mockClusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	mockPipelineStepGetter := pipelinestepmock.NewMockGetter(mockCtl)
+	mockPipelineStepGetter.EXPECT().GetSteps(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 
 	ctrl := controller{
 		prMgr:              param.PRMgr,
@@ -433,6 +470,10 @@ func TestExecutePipelineRun(t *testing.T) {
 		tokenConfig:        tokenConfig,
 		clusterGitRepo:     mockClusterGitRepo,
 		templateReleaseMgr: param.TemplateReleaseMgr,
+		deployWindowSvc:    deploywindow.NewService(param, deploywindowconfig.Config{}, nil),
+		snapshotSvc:        snapshotservice.NewService(param),
+		eventSvc:           eventservice.New(param),
+		pipelineStepSvc:    pipelinestepservice.NewService(mockPipelineStepGetter, param),
 	}
 
 	_, err := param.UserMgr.Create(ctx, &usermodel.User{
@@ -509,6 +550,26 @@ func TestExecutePipelineRun(t *testing.T) {
 	err = ctrl.Execute(ctx, PRPending.ID, true)
 	assert.NoError(t, err)
 
+	// PRReady is still running, the conflict is recorded as a message of PRPending
+	total, messages, err := param.PRMgr.Message.List(ctx, PRPending.ID, &q.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Contains(t, messages[0].Content, strconv.Itoa(int(PRReady.ID)))
+
+	PRQueued, err := param.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Status:    string(pipelinemodel.StatusReady),
+	})
+	assert.NoError(t, err)
+	ctrl.deployWindowSvc = deploywindow.NewService(param, deploywindowconfig.Config{
+		ConflictPolicy: deploywindowconfig.ConflictPolicyQueue,
+	}, nil)
+	err = ctrl.Execute(ctx, PRQueued.ID, false)
+	assert.Equal(t, herrors.ErrDeployConflict, perror.Cause(err))
+	PRQueued, err = param.PRMgr.PipelineRun.GetByID(ctx, PRQueued.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(pipelinemodel.StatusReady), PRQueued.Status)
+
 	PRCancel, err := param.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
 		ClusterID: cluster.ID,
 		Status:    string(pipelinemodel.StatusPending),
@@ -518,8 +579,16 @@ func TestExecutePipelineRun(t *testing.T) {
 	err = ctrl.Cancel(ctx, PRCancel.ID)
 	assert.NoError(t, err)
 
+	// a running pipelinerun is stopped in CI
+	mockTektonInterface.EXPECT().StopPipelineRun(ctx, "hello").Return(nil).Times(1)
 	err = ctrl.Cancel(ctx, PRReady.ID)
-	assert.NotNil(t, err)
+	assert.NoError(t, err)
+	PRReady, err = param.PRMgr.PipelineRun.GetByID(ctx, PRReady.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(pipelinemodel.StatusCancelled), PRReady.Status)
+
+	err = ctrl.Cancel(ctx, PRReady.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
 }
 
 func TestCheckRun(t *testing.T) {
@@ -608,6 +677,115 @@ func TestCheckRun(t *testing.T) {
 	assert.Equal(t, len(checkRuns), 1)
 }
 
+func TestDeployApproval(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&groupmodels.Group{}, &membermodels.Member{}, &applicationmodel.Application{},
+		&clustermodel.Cluster{}, &prmodels.CheckRun{}, &prmodels.Pipelinerun{}, &prmodels.PRMessage{},
+		&usermodel.User{}, &prmodels.Check{}, &deployapprovalmodels.DeployApproval{},
+		&eventmodels.Event{}); err != nil {
+		panic(err)
+	}
+	param := managerparam.InitManager(db)
+	// nolint
+	creatorCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "Tony",
+		ID:    uint(1),
+		Email: "tony@horizoncd.io",
+	})
+	// nolint
+	approverCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "Jerry",
+		ID:    uint(2),
+		Email: "jerry@horizoncd.io",
+	})
+
+	ctrl := controller{
+		clusterMgr: param.ClusterMgr,
+		prMgr:      param.PRMgr,
+		prSvc:      prservice.NewService(param),
+		eventSvc:   eventservice.New(param),
+		deployApprovalConfig: deployapprovalconfig.Config{
+			Environments: []deployapprovalconfig.Environment{
+				{Name: "online", Approvers: []string{"jerry@horizoncd.io"}},
+			},
+		},
+		deployApprovalMgr: param.DeployApprovalMgr,
+	}
+
+	group, err := param.GroupMgr.Create(creatorCtx, &groupmodels.Group{
+		Name: "test",
+	})
+	assert.NoError(t, err)
+	app, err := param.ApplicationMgr.Create(creatorCtx, &applicationmodel.Application{
+		Name:    "test",
+		GroupID: group.ID,
+	}, nil)
+	assert.NoError(t, err)
+	cluster, err := param.ClusterMgr.Create(creatorCtx, &clustermodel.Cluster{
+		Name:            "cluster",
+		ApplicationID:   app.ID,
+		EnvironmentName: "online",
+	}, nil, nil)
+	assert.NoError(t, err)
+
+	createPipelinerun := func() *prmodels.Pipelinerun {
+		pr, err := param.PRMgr.PipelineRun.Create(creatorCtx, &prmodels.Pipelinerun{
+			Status:    string(prmodels.StatusPending),
+			ClusterID: cluster.ID,
+			CreatedBy: 1,
+		})
+		assert.NoError(t, err)
+		return pr
+	}
+
+	// forcing a pipelinerun to execute requests an approval
+	pr := createPipelinerun()
+	err = ctrl.Execute(creatorCtx, pr.ID, true)
+	assert.Equal(t, herrors.ErrDeployApprovalRequired, perror.Cause(err))
+	approval, err := ctrl.GetDeployApproval(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, deployapprovalmodels.StatusPending, approval.Status)
+
+	approvals, err := ctrl.ListDeployApprovals(creatorCtx, deployapprovalmodels.StatusPending)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(approvals))
+	approvals, err = ctrl.ListDeployApprovals(approverCtx, deployapprovalmodels.StatusPending)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(approvals))
+
+	// only approvers can approve
+	_, err = ctrl.ReviewDeployApproval(creatorCtx, pr.ID, &ReviewDeployApprovalRequest{Approved: true})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	approval, err = ctrl.ReviewDeployApproval(approverCtx, pr.ID, &ReviewDeployApprovalRequest{
+		Approved: true,
+		Comment:  "lgtm",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, deployapprovalmodels.StatusApproved, approval.Status)
+	assert.Equal(t, uint(2), approval.ReviewedBy)
+	prInDB, err := param.PRMgr.PipelineRun.GetByID(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(prmodels.StatusReady), prInDB.Status)
+	total, messages, err := param.PRMgr.Message.List(creatorCtx, pr.ID, &q.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "approved the deploy: lgtm", messages[0].Content)
+
+	_, err = ctrl.ReviewDeployApproval(approverCtx, pr.ID, &ReviewDeployApprovalRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// a rejected pipelinerun is cancelled
+	pr = createPipelinerun()
+	err = ctrl.checkDeployApproval(creatorCtx, pr)
+	assert.Equal(t, herrors.ErrDeployApprovalRequired, perror.Cause(err))
+	_, err = ctrl.ReviewDeployApproval(approverCtx, pr.ID, &ReviewDeployApprovalRequest{})
+	assert.NoError(t, err)
+	prInDB, err = param.PRMgr.PipelineRun.GetByID(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(prmodels.StatusCancelled), prInDB.Status)
+}
+
 func TestMessage(t *testing.T) {
 	db, _ := orm.NewSqliteDB("")
 	if err := db.AutoMigrate(&prmodels.PRMessage{}, &usermodel.User{}); err != nil {
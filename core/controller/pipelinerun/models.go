@@ -14,7 +14,12 @@
 
 package pipelinerun
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/util/valuediff"
+)
 
 type GetDiffResponse struct {
 	CodeInfo   *CodeInfo   `json:"codeInfo"`
@@ -38,6 +43,8 @@ type ConfigDiff struct {
 	From string `json:"from"`
 	To   string `json:"to"`
 	Diff string `json:"diff"`
+	// Values are changes of config values keyed by file, values of sensitive keys are masked
+	Values map[string][]*valuediff.Change `json:"values,omitempty"`
 }
 
 type BuildDeployRequestGit struct {
@@ -71,3 +78,17 @@ type CreateOrUpdateCheckRunRequest struct {
 	ExternalID string `json:"externalId"`
 	DetailURL  string `json:"detailUrl"`
 }
+
+// RerunFromStepRequest specifies the step to rerun a pipelinerun from, the first failed step if empty
+type RerunFromStepRequest struct {
+	Task string `json:"task"`
+	Step string `json:"step"`
+}
+
+type CreateSBOMRequest struct {
+	// Format is one of syft-json, cyclonedx-json and spdx-json, detected from content if empty
+	Format string `json:"format"`
+	// ImageURL is the image which the sbom is generated for, defaults to the image of pipelinerun
+	ImageURL string          `json:"imageURL"`
+	Content  json.RawMessage `json:"content"`
+}
@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	artifactmodels "github.com/horizoncd/horizon/pkg/artifact/models"
+)
+
+// UploadArtifactRequest uploads a file of pipelinerun, the artifact of the same name is overwritten
+type UploadArtifactRequest struct {
+	Name string
+	// Type is one of artifact, junit and coverage, defaults to artifact
+	Type string
+	// Format is the format of a coverage report, it's detected from the content if empty
+	Format  string
+	Content io.ReadSeeker
+}
+
+type Artifact struct {
+	ID            uint   `json:"id"`
+	PipelinerunID uint   `json:"pipelinerunID"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Format        string `json:"format,omitempty"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	// Summary is the summary of a junit or coverage report
+	Summary   json.RawMessage `json:"summary,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	CreatedBy uint            `json:"createdBy"`
+}
+
+func ofArtifact(artifact *artifactmodels.Artifact) *Artifact {
+	a := &Artifact{
+		ID:            artifact.ID,
+		PipelinerunID: artifact.PipelinerunID,
+		Name:          artifact.Name,
+		Type:          artifact.Type,
+		Format:        artifact.Format,
+		Size:          artifact.Size,
+		SHA256:        artifact.SHA256,
+		CreatedAt:     artifact.CreatedAt,
+		UpdatedAt:     artifact.UpdatedAt,
+		CreatedBy:     artifact.CreatedBy,
+	}
+	if artifact.Summary != "" {
+		a.Summary = json.RawMessage(artifact.Summary)
+	}
+	return a
+}
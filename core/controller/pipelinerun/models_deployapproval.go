@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"time"
+
+	deployapprovalmodels "github.com/horizoncd/horizon/pkg/deployapproval/models"
+)
+
+// ReviewDeployApprovalRequest approves or rejects the deploy of a pipelinerun
+type ReviewDeployApprovalRequest struct {
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment"`
+}
+
+type DeployApproval struct {
+	ID            uint       `json:"id"`
+	PipelinerunID uint       `json:"pipelinerunID"`
+	ClusterID     uint       `json:"clusterID"`
+	Environment   string     `json:"environment"`
+	Status        string     `json:"status"`
+	ReviewedBy    uint       `json:"reviewedBy,omitempty"`
+	ReviewComment string     `json:"reviewComment,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	CreatedBy     uint       `json:"createdBy"`
+}
+
+func ofDeployApproval(approval *deployapprovalmodels.DeployApproval) *DeployApproval {
+	return &DeployApproval{
+		ID:            approval.ID,
+		PipelinerunID: approval.PipelinerunID,
+		ClusterID:     approval.ClusterID,
+		Environment:   approval.Environment,
+		Status:        approval.Status,
+		ReviewedBy:    approval.ReviewedBy,
+		ReviewComment: approval.ReviewComment,
+		ReviewedAt:    approval.ReviewedAt,
+		CreatedAt:     approval.CreatedAt,
+		CreatedBy:     approval.CreatedBy,
+	}
+}
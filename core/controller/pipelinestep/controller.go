@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinestep
+
+import (
+	"context"
+
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// List lists the custom steps declared by the template of the application
+	// and whether they are enabled for the application
+	List(ctx context.Context, applicationID uint) ([]*pipelinestepservice.ApplicationStep, error)
+	// Update enables or disables the custom steps for the application
+	Update(ctx context.Context, applicationID uint, request *UpdateRequest) error
+}
+
+type controller struct {
+	applicationMgr  applicationmanager.Manager
+	pipelineStepSvc pipelinestepservice.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		applicationMgr:  param.ApplicationMgr,
+		pipelineStepSvc: param.PipelineStepSvc,
+	}
+}
+
+func (c *controller) List(ctx context.Context,
+	applicationID uint) ([]*pipelinestepservice.ApplicationStep, error) {
+	const op = "pipeline step controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	application, err := c.applicationMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return c.pipelineStepSvc.ListSteps(ctx, applicationID, application.Template, application.TemplateRelease)
+}
+
+func (c *controller) Update(ctx context.Context, applicationID uint, request *UpdateRequest) error {
+	const op = "pipeline step controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	application, err := c.applicationMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return err
+	}
+	settings := make(map[string]bool, len(request.Steps))
+	for _, step := range request.Steps {
+		settings[step.Name] = step.Enabled
+	}
+	return c.pipelineStepSvc.UpdateSettings(ctx, applicationID,
+		application.Template, application.TemplateRelease, settings)
+}
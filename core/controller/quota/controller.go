@@ -0,0 +1,136 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// List reports the usages and forecasts of the quotas effective on the group,
+	// which are set on the group and its parents
+	List(ctx context.Context, groupID uint) ([]*Quota, error)
+	// Set limits a resource under the group and its subgroups, the existing quota is replaced
+	Set(ctx context.Context, groupID uint, r *SetQuotaRequest) (*Quota, error)
+	Delete(ctx context.Context, groupID uint, resource string) error
+}
+
+type controller struct {
+	quotaMgr quotamanager.Manager
+	quotaSvc quotaservice.Service
+	groupMgr groupmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		quotaMgr: param.QuotaMgr,
+		quotaSvc: param.QuotaSvc,
+		groupMgr: param.GroupMgr,
+	}
+}
+
+func (c *controller) List(ctx context.Context, groupID uint) (_ []*Quota, err error) {
+	const op = "quota controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	usages, err := c.quotaSvc.ListUsages(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	quotas := make([]*Quota, 0, len(usages))
+	for _, usage := range usages {
+		quotas = append(quotas, ofUsage(groupID, usage))
+	}
+	return quotas, nil
+}
+
+func (c *controller) Set(ctx context.Context, groupID uint, r *SetQuotaRequest) (_ *Quota, err error) {
+	const op = "quota controller: set"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := validateResource(r.Resource); err != nil {
+		return nil, err
+	}
+	if r.HardLimit == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "hardLimit must be positive, delete the quota to remove it")
+	}
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// the soft thresholds are warned again against the new limit
+	if err := c.quotaMgr.Set(ctx, &models.GroupQuota{
+		GroupID:   groupID,
+		Resource:  r.Resource,
+		HardLimit: r.HardLimit,
+		CreatedAt: now,
+		CreatedBy: currentUser.GetID(),
+		UpdatedAt: now,
+		UpdatedBy: currentUser.GetID(),
+	}); err != nil {
+		return nil, err
+	}
+
+	quotas, err := c.List(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, quota := range quotas {
+		if quota.GroupID == groupID && quota.Resource == r.Resource {
+			return quota, nil
+		}
+	}
+	return nil, herrors.NewErrNotFound(herrors.GroupQuotaInDB,
+		fmt.Sprintf("quota of %s is not found for group %d", r.Resource, groupID))
+}
+
+func (c *controller) Delete(ctx context.Context, groupID uint, resource string) (err error) {
+	const op = "quota controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := validateResource(resource); err != nil {
+		return err
+	}
+	return c.quotaMgr.Delete(ctx, groupID, resource)
+}
+
+func validateResource(resource string) error {
+	for _, r := range models.Resources {
+		if r == resource {
+			return nil
+		}
+	}
+	return perror.Wrapf(herrors.ErrParamInvalid, "quota of %s is not supported, it must be one of %v",
+		resource, models.Resources)
+}
@@ -0,0 +1,57 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"time"
+
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
+)
+
+type SetQuotaRequest struct {
+	// Resource is applications or clusters
+	Resource string `json:"resource"`
+	// HardLimit is the max number of the resource under the group and its subgroups
+	HardLimit uint `json:"hardLimit"`
+}
+
+// Quota is a quota effective on the group with its usage and forecast
+type Quota struct {
+	// GroupID is the group which the quota is set on, it's a parent if the quota is inherited
+	GroupID   uint   `json:"groupID"`
+	Inherited bool   `json:"inherited"`
+	Resource  string `json:"resource"`
+	HardLimit uint   `json:"hardLimit"`
+	Used      int64  `json:"used"`
+	// Threshold is the highest soft threshold in percentage reached, 0 if none is reached
+	Threshold int `json:"threshold"`
+	// GrowthPerDay is the net number of the resource created per day in the last 30 days
+	GrowthPerDay float64 `json:"growthPerDay"`
+	// ExhaustAt is when the quota is forecasted to be exhausted at the growth, it's omitted if never
+	ExhaustAt *time.Time `json:"exhaustAt,omitempty"`
+}
+
+func ofUsage(groupID uint, usage *quotaservice.Usage) *Quota {
+	return &Quota{
+		GroupID:      usage.GroupID,
+		Inherited:    usage.GroupID != groupID,
+		Resource:     usage.Resource,
+		HardLimit:    usage.HardLimit,
+		Used:         usage.Used,
+		Threshold:    usage.Threshold,
+		GrowthPerDay: usage.GrowthPerDay,
+		ExhaustAt:    usage.ExhaustAt,
+	}
+}
@@ -17,6 +17,8 @@ package region
 import (
 	"context"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	"github.com/horizoncd/horizon/pkg/region/models"
@@ -53,13 +55,18 @@ func (c controller) DeleteByID(ctx context.Context, id uint) error {
 }
 
 func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegionRequest) error {
-	err := c.regionMgr.UpdateByID(ctx, id, &models.Region{
+	cdType, err := checkCDType(request.CDType)
+	if err != nil {
+		return err
+	}
+	err = c.regionMgr.UpdateByID(ctx, id, &models.Region{
 		DisplayName:   request.DisplayName,
 		Server:        request.Server,
 		Certificate:   request.Certificate,
 		IngressDomain: request.IngressDomain,
 		PrometheusURL: request.PrometheusURL,
 		RegistryID:    request.RegistryID,
+		CDType:        cdType,
 		Disabled:      request.Disabled,
 	})
 	if err != nil {
@@ -70,6 +77,10 @@ func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegi
 }
 
 func (c controller) Create(ctx context.Context, request *CreateRegionRequest) (uint, error) {
+	cdType, err := checkCDType(request.CDType)
+	if err != nil {
+		return 0, err
+	}
 	create, err := c.regionMgr.Create(ctx, &models.Region{
 		Name:          request.Name,
 		DisplayName:   request.DisplayName,
@@ -78,6 +89,7 @@ func (c controller) Create(ctx context.Context, request *CreateRegionRequest) (u
 		IngressDomain: request.IngressDomain,
 		PrometheusURL: request.PrometheusURL,
 		RegistryID:    request.RegistryID,
+		CDType:        cdType,
 	})
 	if err != nil {
 		return 0, err
@@ -93,3 +105,16 @@ func (c controller) ListRegions(ctx context.Context) ([]*Region, error) {
 	}
 	return ofRegionEntities(entities), nil
 }
+
+// checkCDType checks the cd type of region, and returns argocd if it's empty
+func checkCDType(cdType string) (string, error) {
+	switch cdType {
+	case "":
+		return models.CDTypeArgoCD, nil
+	case models.CDTypeArgoCD, models.CDTypeFlux:
+		return cdType, nil
+	default:
+		return "", perror.Wrapf(herrors.ErrParamInvalid,
+			"cd type %s is not supported, supported types: %s, %s", cdType, models.CDTypeArgoCD, models.CDTypeFlux)
+	}
+}
@@ -30,6 +30,7 @@ type Region struct {
 	Certificate   string            `json:"certificate"`
 	IngressDomain string            `json:"ingressDomain"`
 	PrometheusURL string            `json:"prometheusURL"`
+	CDType        string            `json:"cdType"`
 	Disabled      bool              `json:"disabled"`
 	RegistryID    uint              `json:"registryID"`
 	Registry      registry.Registry `json:"registry"`
@@ -46,6 +47,8 @@ type CreateRegionRequest struct {
 	IngressDomain string `json:"ingressDomain"`
 	PrometheusURL string `json:"prometheusURL"`
 	RegistryID    uint   `json:"registryID"`
+	// CDType is the cd system syncing clusters of the region, argocd or flux, argocd if empty
+	CDType string `json:"cdType"`
 }
 
 type UpdateRegionRequest struct {
@@ -56,6 +59,7 @@ type UpdateRegionRequest struct {
 	IngressDomain string `json:"ingressDomain"`
 	PrometheusURL string `json:"prometheusURL"`
 	RegistryID    uint   `json:"registryID"`
+	CDType        string `json:"cdType"`
 	Disabled      bool   `json:"disabled"`
 }
 
@@ -74,6 +78,7 @@ func ofRegionEntity(entity *models.RegionEntity) *Region {
 		Server:        entity.Server,
 		IngressDomain: entity.IngressDomain,
 		PrometheusURL: entity.PrometheusURL,
+		CDType:        entity.CDType,
 		Certificate:   entity.Certificate,
 		Disabled:      entity.Disabled,
 		RegistryID:    entity.RegistryID,
@@ -0,0 +1,383 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/oauth/scope"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	robotmanager "github.com/horizoncd/horizon/pkg/robot/manager"
+	"github.com/horizoncd/horizon/pkg/robot/models"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// CreateRobot creates a robot under the group or application, with a role not higher than the current user's
+	CreateRobot(ctx context.Context, resourceType string, resourceID uint,
+		request *CreateRobotRequest) (*Robot, error)
+	ListRobots(ctx context.Context, resourceType string, resourceID uint) ([]*Robot, error)
+	GetRobot(ctx context.Context, id uint) (*Robot, error)
+	UpdateRobot(ctx context.Context, id uint, request *UpdateRobotRequest) (*Robot, error)
+	// DeleteRobot deletes the robot, and revokes its tokens and its role
+	DeleteRobot(ctx context.Context, id uint) error
+	// CreateToken issues a token to the robot, the code of the token is only returned here
+	CreateToken(ctx context.Context, id uint, request *CreateTokenRequest) (*CreateTokenResponse, error)
+	ListTokens(ctx context.Context, id uint) ([]*Token, error)
+	RevokeToken(ctx context.Context, id, tokenID uint) error
+}
+
+type controller struct {
+	robotMgr       robotmanager.Manager
+	userMgr        usermanager.Manager
+	memberMgr      membermanager.Manager
+	memberSvc      memberservice.Service
+	tokenMgr       tokenmanager.Manager
+	tokenSvc       tokenservice.Service
+	roleSvc        role.Service
+	scopeSvc       scope.Service
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	eventSvc       eventservice.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		robotMgr:       param.RobotMgr,
+		userMgr:        param.UserMgr,
+		memberMgr:      param.MemberMgr,
+		memberSvc:      param.MemberService,
+		tokenMgr:       param.TokenMgr,
+		tokenSvc:       param.TokenSvc,
+		roleSvc:        param.RoleService,
+		scopeSvc:       param.ScopeService,
+		groupMgr:       param.GroupMgr,
+		applicationMgr: param.ApplicationMgr,
+		eventSvc:       param.EventSvc,
+	}
+}
+
+func (c *controller) CreateRobot(ctx context.Context, resourceType string, resourceID uint,
+	request *CreateRobotRequest) (*Robot, error) {
+	const op = "robot controller: create robot"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := request.validate(resourceType); err != nil {
+		return nil, err
+	}
+	if _, err := c.roleSvc.GetRole(ctx, request.Role); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid role %s: %v", request.Role, err)
+	}
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	robots, err := c.robotMgr.ListByResource(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, robot := range robots {
+		if robot.Name == request.Name {
+			return nil, perror.Wrapf(herrors.ErrNameConflict, "robot %s already exists", request.Name)
+		}
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the current user can not grant a role higher than its own,
+	// it's checked before creating the user to leave no user behind
+	if err := c.memberSvc.RequirePermissionEqualOrHigher(ctx, request.Role, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+
+	// the robot acts as its user, which is granted the role on the resource
+	user, err := c.userMgr.Create(ctx, newRobotUser(resourceType, resourceID, request.Name))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.memberSvc.CreateMember(ctx, memberservice.PostMember{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		MemberInfo:   user.ID,
+		MemberType:   membermodels.MemberUser,
+		Role:         request.Role,
+	}); err != nil {
+		return nil, err
+	}
+	robot, err := c.robotMgr.Create(ctx, &models.Robot{
+		Name:         request.Name,
+		Description:  request.Description,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		UserID:       user.ID,
+		CreatedBy:    currentUser.GetID(),
+		UpdatedBy:    currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceRobot, robot.ID, eventmodels.RobotCreated, nil)
+	return c.ofRobot(ctx, robot)
+}
+
+func (c *controller) ListRobots(ctx context.Context, resourceType string, resourceID uint) ([]*Robot, error) {
+	const op = "robot controller: list robots"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	robots, err := c.robotMgr.ListByResource(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Robot, 0, len(robots))
+	for _, robot := range robots {
+		r, err := c.ofRobot(ctx, robot)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (c *controller) GetRobot(ctx context.Context, id uint) (*Robot, error) {
+	const op = "robot controller: get robot"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	robot, err := c.robotMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return c.ofRobot(ctx, robot)
+}
+
+func (c *controller) UpdateRobot(ctx context.Context, id uint, request *UpdateRobotRequest) (*Robot, error) {
+	const op = "robot controller: update robot"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	robot, err := c.robotMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Role != nil {
+		if _, err := c.roleSvc.GetRole(ctx, *request.Role); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid role %s: %v", *request.Role, err)
+		}
+		member, err := c.memberOf(ctx, robot)
+		if err != nil {
+			return nil, err
+		}
+		// the current user can neither downgrade a robot higher than it nor grant a role higher than its own
+		for _, r := range []string{member.Role, *request.Role} {
+			if err := c.memberSvc.RequirePermissionEqualOrHigher(ctx, r,
+				robot.ResourceType, robot.ResourceID); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := c.memberMgr.UpdateByID(ctx, member.ID, *request.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.Description != nil {
+		robot.Description = *request.Description
+	}
+	if request.Disabled != nil {
+		robot.Disabled = *request.Disabled
+	}
+	robot.UpdatedBy = currentUser.GetID()
+	robot, err = c.robotMgr.UpdateByID(ctx, id, robot)
+	if err != nil {
+		return nil, err
+	}
+	return c.ofRobot(ctx, robot)
+}
+
+func (c *controller) DeleteRobot(ctx context.Context, id uint) error {
+	const op = "robot controller: delete robot"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	robot, err := c.robotMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	member, err := c.memberOf(ctx, robot)
+	if err != nil {
+		return err
+	}
+	if err := c.memberSvc.RequirePermissionEqualOrHigher(ctx, member.Role,
+		robot.ResourceType, robot.ResourceID); err != nil {
+		return err
+	}
+
+	if err := c.tokenMgr.RevokeTokensByUserID(ctx, robot.UserID); err != nil {
+		return err
+	}
+	if err := c.memberMgr.DeleteMemberByMemberNameID(ctx, robot.UserID); err != nil {
+		return err
+	}
+	if err := c.userMgr.DeleteUser(ctx, robot.UserID); err != nil {
+		return err
+	}
+	if err := c.robotMgr.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceRobot, id, eventmodels.RobotDeleted, nil)
+	return nil
+}
+
+func (c *controller) CreateToken(ctx context.Context, id uint,
+	request *CreateTokenRequest) (*CreateTokenResponse, error) {
+	const op = "robot controller: create token"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.scopeSvc.ValidateScopes(request.Scopes); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	robot, err := c.robotMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.tokenSvc.CreateAccessToken(ctx, request.Name, request.ExpiresAt,
+		robot.UserID, request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	creator, err := c.userMgr.GetUserByID(ctx, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return &CreateTokenResponse{
+		Token: *ofTokenModel(token, creator),
+		Code:  token.Code,
+	}, nil
+}
+
+func (c *controller) ListTokens(ctx context.Context, id uint) ([]*Token, error) {
+	const op = "robot controller: list tokens"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	robot, err := c.robotMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := c.tokenMgr.ListAccessTokensByUserID(ctx, robot.UserID)
+	if err != nil {
+		return nil, err
+	}
+	creatorIDs := make([]uint, 0, len(tokens))
+	for _, token := range tokens {
+		creatorIDs = append(creatorIDs, token.CreatedBy)
+	}
+	creators, err := c.userMgr.GetUserMapByIDs(ctx, creatorIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Token, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, ofTokenModel(token, creators[token.CreatedBy]))
+	}
+	return result, nil
+}
+
+func (c *controller) RevokeToken(ctx context.Context, id, tokenID uint) error {
+	const op = "robot controller: revoke token"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	robot, err := c.robotMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	token, err := c.tokenMgr.LoadTokenByID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	// tokens of others are not exposed
+	if token.UserID != robot.UserID {
+		return herrors.NewErrNotFound(herrors.TokenInDB, "token not found")
+	}
+	if err := c.tokenMgr.RevokeTokenByID(ctx, tokenID); err != nil {
+		return err
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceAccessToken, tokenID, eventmodels.TokenRevoked, nil)
+	return nil
+}
+
+// memberOf gets the member granting the robot its role
+func (c *controller) memberOf(ctx context.Context, robot *models.Robot) (*membermodels.Member, error) {
+	member, err := c.memberMgr.Get(ctx, membermodels.ResourceType(robot.ResourceType), robot.ResourceID,
+		membermodels.MemberUser, robot.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, herrors.NewErrNotFound(herrors.MemberInfoInDB, "member of the robot not found")
+	}
+	return member, nil
+}
+
+func (c *controller) ofRobot(ctx context.Context, robot *models.Robot) (*Robot, error) {
+	member, err := c.memberOf(ctx, robot)
+	if err != nil {
+		return nil, err
+	}
+	creator, err := c.userMgr.GetUserByID(ctx, robot.CreatedBy)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+	}
+	return ofRobotModel(robot, member.Role, creator), nil
+}
+
+// checkResource checks whether the resource exists
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	var err error
+	switch resourceType {
+	case common.ResourceGroup:
+		_, err = c.groupMgr.GetByID(ctx, resourceID)
+	case common.ResourceApplication:
+		_, err = c.applicationMgr.GetByID(ctx, resourceID)
+	}
+	return err
+}
@@ -0,0 +1,225 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/oauth"
+	"github.com/horizoncd/horizon/pkg/config/token"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
+	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	"github.com/horizoncd/horizon/pkg/oauth/scope"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	robotmodels "github.com/horizoncd/horizon/pkg/robot/models"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+const roleConfig = `RolePriorityRankDesc:
+  - owner
+  - maintainer
+  - guest
+DefaultRole: guest
+Roles:
+  - name: owner
+    rules: []
+  - name: maintainer
+    rules: []
+  - name: guest
+    rules: []
+`
+
+var (
+	ctx            context.Context
+	maintainerCtx  context.Context
+	manager        *managerparam.Manager
+	groupID        uint
+	ctl            Controller
+	readWriteScope = "applications:read-write"
+)
+
+func TestMain(m *testing.M) {
+	db, err := orm.NewSqliteDB("")
+	if err != nil {
+		panic(err)
+	}
+	callbacks.RegisterCustomCallbacks(db)
+	if err := db.AutoMigrate(&usermodels.User{}, &membermodels.Member{}, &tokenmodels.Token{},
+		&groupmodels.Group{}, &applicationmodels.Application{}, &eventmodels.Event{},
+		&robotmodels.Robot{}); err != nil {
+		panic(err)
+	}
+	manager = managerparam.InitManager(db)
+
+	roleSvc, err := role.NewFileRole(context.Background(), strings.NewReader(roleConfig))
+	if err != nil {
+		panic(err)
+	}
+	scopeSvc, err := scope.NewFileScopeService(oauth.Scopes{Roles: []types.Role{{Name: readWriteScope}}})
+	if err != nil {
+		panic(err)
+	}
+	oauthMgr := oauthmanager.NewManager(oauthdao.NewDAO(db), tokenstore.NewStore(db),
+		generator.NewAuthorizeGenerator(), time.Minute, time.Hour, time.Hour)
+	ctl = NewController(&param.Param{
+		Manager:       manager,
+		TokenSvc:      tokenservice.NewService(manager, token.Config{}),
+		MemberService: memberservice.NewService(roleSvc, oauthMgr, manager),
+		EventSvc:      eventservice.New(manager),
+		RoleService:   roleSvc,
+		ScopeService:  scopeSvc,
+	})
+
+	userCtx := func(name string) context.Context {
+		user, err := manager.UserMgr.Create(context.Background(),
+			&usermodels.User{Name: name, Email: name + "@noreply.com"})
+		if err != nil {
+			panic(err)
+		}
+		return common.WithContext(context.Background(), &userauth.DefaultInfo{Name: user.Name, ID: user.ID})
+	}
+	// the creator of the group is its owner
+	ctx, maintainerCtx = userCtx("owner"), userCtx("maintainer")
+	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{Name: "group", Path: "group"})
+	if err != nil {
+		panic(err)
+	}
+	groupID = group.ID
+	maintainer, err := common.UserFromContext(maintainerCtx)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   groupID,
+		Role:         "maintainer",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: maintainer.GetID(),
+	}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestRobot(t *testing.T) {
+	_, err := ctl.CreateRobot(ctx, common.ResourceCluster, 1, &CreateRobotRequest{Name: "ci", Role: "guest"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.CreateRobot(ctx, common.ResourceGroup, groupID, &CreateRobotRequest{Name: "CI", Role: "guest"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.CreateRobot(ctx, common.ResourceGroup, groupID, &CreateRobotRequest{Name: "ci", Role: "joker"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	// a maintainer can not create an owner robot
+	_, err = ctl.CreateRobot(maintainerCtx, common.ResourceGroup, groupID,
+		&CreateRobotRequest{Name: "ci", Role: "owner"})
+	assert.Equal(t, herrors.ErrNoPrivilege, perror.Cause(err))
+
+	robot, err := ctl.CreateRobot(ctx, common.ResourceGroup, groupID, &CreateRobotRequest{
+		Name:        "ci",
+		Description: "builds images",
+		Role:        "maintainer",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "ci", robot.Name)
+	assert.Equal(t, "maintainer", robot.Role)
+	assert.Equal(t, "owner", robot.CreatedBy.Name)
+	_, err = ctl.CreateRobot(ctx, common.ResourceGroup, groupID, &CreateRobotRequest{Name: "ci", Role: "guest"})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	robots, err := ctl.ListRobots(ctx, common.ResourceGroup, groupID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(robots))
+
+	// the robot acts as a robot user which is a member of the group
+	robotModel, err := manager.RobotMgr.GetByID(ctx, robot.ID)
+	assert.Nil(t, err)
+	user, err := manager.UserMgr.GetUserByID(ctx, robotModel.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(usermodels.UserTypeRobot), user.UserType)
+
+	// a maintainer can not upgrade the robot to owner
+	owner := "owner"
+	_, err = ctl.UpdateRobot(maintainerCtx, robot.ID, &UpdateRobotRequest{Role: &owner})
+	assert.Equal(t, herrors.ErrNoPrivilege, perror.Cause(err))
+	disabled, guest := true, "guest"
+	robot, err = ctl.UpdateRobot(ctx, robot.ID, &UpdateRobotRequest{Role: &guest, Disabled: &disabled})
+	assert.Nil(t, err)
+	assert.Equal(t, "guest", robot.Role)
+	assert.True(t, robot.Disabled)
+	assert.Equal(t, "builds images", robot.Description)
+
+	_, err = ctl.CreateToken(ctx, robot.ID, &CreateTokenRequest{Name: "ci", ExpiresAt: tokenservice.NeverExpire,
+		Scopes: []string{"clusters:read-write"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	resp, err := ctl.CreateToken(ctx, robot.ID, &CreateTokenRequest{Name: "ci", ExpiresAt: tokenservice.NeverExpire,
+		Scopes: []string{readWriteScope}})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(resp.Code, generator.AccessTokenPrefix))
+	assert.Equal(t, tokenservice.NeverExpire, resp.ExpiresAt)
+	issued, err := manager.TokenMgr.LoadTokenByCode(ctx, resp.Code)
+	assert.Nil(t, err)
+	assert.Equal(t, robotModel.UserID, issued.UserID)
+
+	tokens, err := ctl.ListTokens(ctx, robot.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tokens))
+	assert.Equal(t, []string{readWriteScope}, tokens[0].Scopes)
+	assert.Equal(t, "owner", tokens[0].CreatedBy.Name)
+
+	// tokens of others can not be revoked by the robot
+	_, ok := perror.Cause(ctl.RevokeToken(ctx, robot.ID+1, resp.ID)).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	assert.Nil(t, ctl.RevokeToken(ctx, robot.ID, resp.ID))
+	tokens, err = ctl.ListTokens(ctx, robot.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tokens))
+
+	_, err = ctl.CreateToken(ctx, robot.ID, &CreateTokenRequest{Name: "ci", ExpiresAt: tokenservice.NeverExpire})
+	assert.Nil(t, err)
+	assert.Nil(t, ctl.DeleteRobot(ctx, robot.ID))
+	_, ok = perror.Cause(ctl.DeleteRobot(ctx, robot.ID)).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	tokens, err = manager.TokenMgr.ListAccessTokensByUserID(ctx, robotModel.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tokens))
+	members, err := manager.MemberMgr.ListMembersByUserID(ctx, robotModel.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(members))
+}
@@ -0,0 +1,159 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/robot/models"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+const (
+	_robotEmailSuffix = "@noreply.com"
+	_maxNameLength    = 40
+)
+
+var _namePattern = regexp.MustCompile(`^(([a-z][-a-z0-9]*)?[a-z0-9])?$`)
+
+type CreateRobotRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Role is the role of the robot on the group or application
+	Role string `json:"role"`
+}
+
+func (r *CreateRobotRequest) validate(resourceType string) error {
+	if resourceType != common.ResourceGroup && resourceType != common.ResourceApplication {
+		return perror.Wrapf(herrors.ErrParamInvalid, "robots can not be created under %s", resourceType)
+	}
+	return validateName(r.Name)
+}
+
+type UpdateRobotRequest struct {
+	Description *string `json:"description"`
+	Role        *string `json:"role"`
+	// Disabled robots can not be authenticated by their tokens
+	Disabled *bool `json:"disabled"`
+}
+
+type Robot struct {
+	ID           uint                  `json:"id"`
+	Name         string                `json:"name"`
+	Description  string                `json:"description"`
+	ResourceType string                `json:"resourceType"`
+	ResourceID   uint                  `json:"resourceID"`
+	Role         string                `json:"role"`
+	Disabled     bool                  `json:"disabled"`
+	CreatedAt    time.Time             `json:"createdAt"`
+	CreatedBy    *usermodels.UserBasic `json:"createdBy"`
+	UpdatedAt    time.Time             `json:"updatedAt"`
+}
+
+type CreateTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is a date in the format of 2006-01-02, or never
+	ExpiresAt string `json:"expiresAt"`
+}
+
+func (r *CreateTokenRequest) validate() error {
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if r.ExpiresAt == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "expiresAt is required")
+	}
+	return nil
+}
+
+type Token struct {
+	ID        uint                  `json:"id"`
+	Name      string                `json:"name"`
+	Scopes    []string              `json:"scopes"`
+	ExpiresAt string                `json:"expiresAt"`
+	CreatedAt time.Time             `json:"createdAt"`
+	CreatedBy *usermodels.UserBasic `json:"createdBy"`
+}
+
+type CreateTokenResponse struct {
+	Token
+	// Code is only returned once on creation
+	Code string `json:"token"`
+}
+
+func validateName(name string) error {
+	if len(name) == 0 || len(name) > _maxNameLength {
+		return perror.Wrapf(herrors.ErrParamInvalid, "length of name should > 0 and <= %d", _maxNameLength)
+	}
+	if !_namePattern.MatchString(name) {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid name, regex used for validation is %v", _namePattern.String())
+	}
+	return nil
+}
+
+// newRobotUser returns the user the robot acts as
+func newRobotUser(resourceType string, resourceID uint, name string) *usermodels.User {
+	fullName := fmt.Sprintf("%s_%d_robot_%s", resourceType, resourceID, name)
+	return &usermodels.User{
+		Name:     name,
+		FullName: fullName,
+		Email:    fullName + _robotEmailSuffix,
+		UserType: usermodels.UserTypeRobot,
+	}
+}
+
+func ofRobotModel(robot *models.Robot, role string, creator *usermodels.User) *Robot {
+	return &Robot{
+		ID:           robot.ID,
+		Name:         robot.Name,
+		Description:  robot.Description,
+		ResourceType: robot.ResourceType,
+		ResourceID:   robot.ResourceID,
+		Role:         role,
+		Disabled:     robot.Disabled,
+		CreatedAt:    robot.CreatedAt,
+		CreatedBy:    usermodels.ToUser(creator),
+		UpdatedAt:    robot.UpdatedAt,
+	}
+}
+
+func ofTokenModel(token *tokenmodels.Token, creator *usermodels.User) *Token {
+	expiresAt := tokenservice.NeverExpire
+	if token.ExpiresIn > 0 {
+		expiresAt = token.CreatedAt.Add(token.ExpiresIn).Format(tokenservice.ExpiresAtFormat)
+	}
+	scopes := []string{}
+	if token.Scope != "" {
+		scopes = strings.Split(token.Scope, " ")
+	}
+	return &Token{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: token.CreatedAt,
+		CreatedBy: usermodels.ToUser(creator),
+	}
+}
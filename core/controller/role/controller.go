@@ -16,24 +16,57 @@ package role
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"regexp"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	customrolemodels "github.com/horizoncd/horizon/pkg/customrole/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+// _roleNameRegex is the format of the names of custom roles
+var _roleNameRegex = regexp.MustCompile("^[a-z][a-z0-9-]{0,62}$")
+
+// _verbs are the verbs of the requests, see pkg/auth/prehandle.go
+var _verbs = map[string]bool{
+	types.VerbAll: true,
+	"get":         true,
+	"list":        true,
+	"create":      true,
+	"update":      true,
+	"patch":       true,
+	"delete":      true,
+}
+
 type Controller interface {
 	ListRole(ctx context.Context) ([]types.Role, error)
+	// CreateRole creates a custom role, only admins can manage custom roles
+	CreateRole(ctx context.Context, request *CreateRoleRequest) (*types.Role, error)
+	// UpdateRole updates a custom role, members granted the role are affected immediately
+	UpdateRole(ctx context.Context, name string, request *UpdateRoleRequest) (*types.Role, error)
+	// DeleteRole deletes a custom role, which is not granted to any member
+	DeleteRole(ctx context.Context, name string) error
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{roleService: param.RoleService}
+	return &controller{
+		roleService:   param.RoleService,
+		customRoleMgr: param.CustomRoleMgr,
+	}
 }
 
 type controller struct {
-	roleService role.Service
+	roleService   role.Service
+	customRoleMgr customrolemanager.Manager
 }
 
 func (c controller) ListRole(ctx context.Context) ([]types.Role, error) {
@@ -44,3 +77,127 @@ func (c controller) ListRole(ctx context.Context) ([]types.Role, error) {
 	}
 	return roles, nil
 }
+
+func (c controller) CreateRole(ctx context.Context, request *CreateRoleRequest) (*types.Role, error) {
+	const op = "role controller: create role"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !_roleNameRegex.MatchString(request.Name) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid role name %s, it should match %s", request.Name, _roleNameRegex)
+	}
+	if err := validateRules(request.Rules); err != nil {
+		return nil, err
+	}
+	// the name of a built-in role is taken as well
+	if _, err := c.roleService.GetRole(ctx, request.Name); err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "role %s already exists", request.Name)
+	} else if err != role.ErrorRoleNotFound {
+		return nil, err
+	}
+
+	rules, err := json.Marshal(request.Rules)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	customRole := &customrolemodels.CustomRole{
+		Name:      request.Name,
+		Desc:      request.Desc,
+		Rules:     string(rules),
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	}
+	if err := c.customRoleMgr.Create(ctx, customRole); err != nil {
+		return nil, err
+	}
+	return role.OfCustomRole(customRole)
+}
+
+func (c controller) UpdateRole(ctx context.Context, name string, request *UpdateRoleRequest) (*types.Role, error) {
+	const op = "role controller: update role"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRules(request.Rules); err != nil {
+		return nil, err
+	}
+	rules, err := json.Marshal(request.Rules)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	if err := c.customRoleMgr.Update(ctx, &customrolemodels.CustomRole{
+		Name:      name,
+		Desc:      request.Desc,
+		Rules:     string(rules),
+		UpdatedBy: currentUser.GetID(),
+	}); err != nil {
+		return nil, err
+	}
+	customRole, err := c.customRoleMgr.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return role.OfCustomRole(customRole)
+}
+
+func (c controller) DeleteRole(ctx context.Context, name string) error {
+	const op = "role controller: delete role"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	count, err := c.customRoleMgr.CountMembers(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return perror.Wrapf(herrors.ErrRoleInUse,
+			"role %s is granted to %d members, please change their roles first", name, count)
+	}
+	return c.customRoleMgr.Delete(ctx, name)
+}
+
+func requireAdmin(ctx context.Context) (userauth.User, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "only admins can manage custom roles")
+	}
+	return currentUser, nil
+}
+
+// validateRules checks that every rule allows some verbs on some resources or urls
+func validateRules(rules []types.PolicyRule) error {
+	if len(rules) == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "rules should not be empty")
+	}
+	for i, rule := range rules {
+		if len(rule.Verbs) == 0 {
+			return perror.Wrapf(herrors.ErrParamInvalid, "verbs of rule[%d] should not be empty", i)
+		}
+		for _, verb := range rule.Verbs {
+			if !_verbs[verb] {
+				return perror.Wrapf(herrors.ErrParamInvalid, "verb %s of rule[%d] is not supported", verb, i)
+			}
+		}
+		if len(rule.Resources) == 0 && len(rule.NonResourceURLs) == 0 {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"resources and nonResourceURLs of rule[%d] should not be both empty", i)
+		}
+		if len(rule.Resources) > 0 && (len(rule.APIGroups) == 0 || len(rule.Scopes) == 0) {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"apiGroups and scopes of rule[%d] should not be empty with resources", i)
+		}
+	}
+	return nil
+}
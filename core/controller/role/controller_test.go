@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	customrolemodels "github.com/horizoncd/horizon/pkg/customrole/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+const roleConfig = `RolePriorityRankDesc:
+  - owner
+  - guest
+DefaultRole: guest
+Roles:
+  - name: owner
+    rules: []
+  - name: guest
+    rules: []
+`
+
+func TestCustomRoles(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&customrolemodels.CustomRole{}, &membermodels.Member{}))
+	manager := managerparam.InitManager(db)
+	var config roleconfig.Config
+	assert.Nil(t, yaml.Unmarshal([]byte(roleConfig), &config))
+	roleSvc, err := role.NewCustomRoleService(context.Background(), config, manager.CustomRoleMgr)
+	assert.Nil(t, err)
+	ctl := NewController(&param.Param{Manager: manager, RoleService: roleSvc})
+
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "admin", ID: 1, Admin: true})
+	userCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "user", ID: 2})
+	rules := []types.PolicyRule{{
+		Verbs:     []string{"create"},
+		APIGroups: []string{"core"},
+		Resources: []string{"clusters/deploy", "clusters/builddeploy"},
+		Scopes:    []string{"*"},
+	}}
+
+	_, err = ctl.CreateRole(userCtx, &CreateRoleRequest{Name: "deployer", Rules: rules})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "Deployer!", Rules: rules})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer", Rules: []types.PolicyRule{{
+		Verbs:     []string{"deploy"},
+		APIGroups: []string{"core"},
+		Resources: []string{"clusters"},
+		Scopes:    []string{"*"},
+	}}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "owner", Rules: rules})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	created, err := ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer", Desc: "deploy only", Rules: rules})
+	assert.Nil(t, err)
+	assert.Equal(t, "deployer", created.Name)
+	assert.Equal(t, rules, created.PolicyRules)
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer", Rules: rules})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	roles, err := ctl.ListRole(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(roles))
+	assert.Equal(t, "deployer", roles[2].Name)
+
+	rules[0].Verbs = []string{"create", "get"}
+	updated, err := ctl.UpdateRole(adminCtx, "deployer", &UpdateRoleRequest{Desc: "deploy and read", Rules: rules})
+	assert.Nil(t, err)
+	assert.Equal(t, "deploy and read", updated.Desc)
+	assert.Equal(t, []string{"create", "get"}, updated.PolicyRules[0].Verbs)
+	// built-in roles are not updatable
+	_, err = ctl.UpdateRole(adminCtx, "owner", &UpdateRoleRequest{Rules: rules})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// roles granted to members are not deletable
+	assert.Nil(t, db.Create(&membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   1,
+		Role:         "deployer",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: 2,
+	}).Error)
+	err = ctl.DeleteRole(adminCtx, "deployer")
+	assert.Equal(t, herrors.ErrRoleInUse, perror.Cause(err))
+	assert.Nil(t, db.Exec("delete from tb_member").Error)
+	err = ctl.DeleteRole(userCtx, "deployer")
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	assert.Nil(t, ctl.DeleteRole(adminCtx, "deployer"))
+	_, err = roleSvc.GetRole(adminCtx, "deployer")
+	assert.Equal(t, role.ErrorRoleNotFound, err)
+}
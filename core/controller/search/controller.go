@@ -0,0 +1,209 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
+	"github.com/horizoncd/horizon/pkg/param"
+	searchmanager "github.com/horizoncd/horizon/pkg/search/manager"
+	"github.com/horizoncd/horizon/pkg/search/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _visibilityPrivate = "private"
+
+var _resourceTypes = []string{common.ResourceGroup, common.ResourceApplication, common.ResourceCluster}
+
+type Controller interface {
+	// Search searches groups, applications and clusters by names, paths, descriptions, tags and owners,
+	// the most relevant first. Resources in private groups are listed only if current user is a member of them,
+	// unless current user is an admin or auditor
+	Search(ctx context.Context, params *SearchParams) ([]*Result, int, error)
+}
+
+type controller struct {
+	searchMgr searchmanager.Manager
+	groupMgr  groupmanager.Manager
+	memberMgr membermanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		searchMgr: param.SearchMgr,
+		groupMgr:  param.GroupMgr,
+		memberMgr: param.MemberMgr,
+	}
+}
+
+func (c *controller) Search(ctx context.Context, params *SearchParams) ([]*Result, int, error) {
+	const op = "search controller: search"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if strings.TrimSpace(params.Keyword) == "" {
+		return nil, 0, perror.Wrap(herrors.ErrParamInvalid, "keyword should not be empty")
+	}
+	resourceTypes := params.ResourceTypes
+	if len(resourceTypes) == 0 {
+		resourceTypes = _resourceTypes
+	}
+	for _, resourceType := range resourceTypes {
+		if !contains(_resourceTypes, resourceType) {
+			return nil, 0, perror.Wrapf(herrors.ErrParamInvalid,
+				"resource type %s is not supported, should be one of %v", resourceType, _resourceTypes)
+		}
+	}
+
+	hits, err := c.searchMgr.Search(ctx, params.Keyword, resourceTypes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// groups of the hits and their parents, for full paths and visibilities
+	groupIDs := make([]uint, 0)
+	for _, hit := range hits {
+		groupIDs = append(groupIDs, groupmanager.FormatIDsFromTraversalIDs(hit.TraversalIDs)...)
+	}
+	groups := make(map[uint]*groupmodels.Group)
+	if len(groupIDs) > 0 {
+		groupList, err := c.groupMgr.GetByIDs(ctx, groupIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, group := range groupList {
+			groups[group.ID] = group
+		}
+	}
+
+	// resources which current user is a member of, nil means all the resources are visible
+	var memberOf map[string]struct{}
+	if !currentUser.IsAdmin() && !currentUser.IsAuditor() {
+		members, err := c.memberMgr.ListMembersByUserID(ctx, currentUser.GetID())
+		if err != nil {
+			return nil, 0, err
+		}
+		memberOf = make(map[string]struct{}, len(members))
+		for _, member := range members {
+			memberOf[key(string(member.ResourceType), member.ResourceID)] = struct{}{}
+		}
+	}
+
+	results := make([]*Result, 0, len(hits))
+	for _, hit := range hits {
+		chain := groupmanager.FormatIDsFromTraversalIDs(hit.TraversalIDs)
+		if memberOf != nil && !visible(hit, chain, groups, memberOf) {
+			continue
+		}
+		results = append(results, ofHit(hit, chain, groups))
+	}
+
+	total := len(results)
+	start := (params.PageNumber - 1) * params.PageSize
+	if start < 0 || start >= total {
+		return []*Result{}, total, nil
+	}
+	end := start + params.PageSize
+	if end > total {
+		end = total
+	}
+	return results[start:end], total, nil
+}
+
+// visible tells whether a resource is visible to current user, who is a member of it, of its application
+// or of a group it belongs to, or whom none of the groups it belongs to is private to
+func visible(hit *models.Hit, chain []uint, groups map[uint]*groupmodels.Group,
+	memberOf map[string]struct{}) bool {
+	if _, ok := memberOf[key(hit.ResourceType, hit.ResourceID)]; ok {
+		return true
+	}
+	if hit.ResourceType == common.ResourceCluster {
+		if _, ok := memberOf[key(common.ResourceApplication, hit.ApplicationID)]; ok {
+			return true
+		}
+	}
+	for _, id := range chain {
+		if _, ok := memberOf[key(common.ResourceGroup, id)]; ok {
+			return true
+		}
+	}
+	for _, id := range chain {
+		if group, ok := groups[id]; ok && group.VisibilityLevel == _visibilityPrivate {
+			return false
+		}
+	}
+	return true
+}
+
+func ofHit(hit *models.Hit, chain []uint, groups map[uint]*groupmodels.Group) *Result {
+	paths := make([]string, 0, len(chain)+2)
+	names := make([]string, 0, len(chain)+2)
+	for _, id := range chain {
+		if group, ok := groups[id]; ok {
+			paths = append(paths, group.Path)
+			names = append(names, group.Name)
+		}
+	}
+	switch hit.ResourceType {
+	case common.ResourceApplication:
+		paths = append(paths, hit.Name)
+		names = append(names, hit.Name)
+	case common.ResourceCluster:
+		paths = append(paths, hit.ApplicationName, hit.Name)
+		names = append(names, hit.ApplicationName, hit.Name)
+	}
+
+	result := &Result{
+		ResourceType:  hit.ResourceType,
+		ID:            hit.ResourceID,
+		Name:          hit.Name,
+		Description:   hit.Description,
+		FullName:      strings.Join(names, "/"),
+		FullPath:      "/" + strings.Join(paths, "/"),
+		MatchedFields: hit.MatchedFields,
+		Owner:         hit.Owner,
+		Score:         hit.Score,
+	}
+	if hit.TagKey != "" {
+		result.Tag = fmt.Sprintf("%s=%s", hit.TagKey, hit.TagValue)
+	}
+	return result
+}
+
+func key(resourceType string, resourceID uint) string {
+	return fmt.Sprintf("%s/%d", resourceType, resourceID)
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
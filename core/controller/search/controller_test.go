@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestSearch(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&tagmodels.Tag{}, &membermodels.Member{}, &usermodels.User{}))
+	ctl := NewController(&param.Param{Manager: managerparam.InitManager(db)})
+
+	parent := &groupmodels.Group{Name: "Parent", Path: "parent", VisibilityLevel: "private"}
+	assert.Nil(t, db.Create(parent).Error)
+	assert.Nil(t, db.Model(parent).Update("traversal_ids", "1").Error)
+	app := &appmodels.Application{Name: "shop", GroupID: parent.ID}
+	assert.Nil(t, db.Create(app).Error)
+	cluster := &clustermodels.Cluster{Name: "shop-online", ApplicationID: app.ID}
+	assert.Nil(t, db.Create(cluster).Error)
+	assert.Nil(t, db.Create(&membermodels.Member{ResourceType: membermodels.TypeApplication,
+		ResourceID: app.ID, MemberType: membermodels.MemberUser, MemberNameID: 2, Role: "owner"}).Error)
+
+	adminCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		ID:    1,
+		Admin: true,
+	})
+	results, total, err := ctl.Search(adminCtx, &SearchParams{Keyword: "p", PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, "/parent", results[0].FullPath)
+	assert.Equal(t, "Parent/shop/shop-online", results[2].FullName)
+	assert.Equal(t, "/parent/shop/shop-online", results[2].FullPath)
+
+	results, total, err = ctl.Search(adminCtx, &SearchParams{Keyword: "p", PageNumber: 2, PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 1, len(results))
+
+	// the private group is invisible to the users who are not members of it
+	memberCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		ID: 2,
+	})
+	results, total, err = ctl.Search(memberCtx, &SearchParams{Keyword: "p", PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, common.ResourceApplication, results[0].ResourceType)
+	assert.Equal(t, common.ResourceCluster, results[1].ResourceType)
+
+	guestCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		ID: 3,
+	})
+	results, total, err = ctl.Search(guestCtx, &SearchParams{Keyword: "p", PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, 0, len(results))
+
+	_, _, err = ctl.Search(adminCtx, &SearchParams{Keyword: " ", PageNumber: 1, PageSize: 10})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, _, err = ctl.Search(adminCtx, &SearchParams{Keyword: "p", ResourceTypes: []string{"templates"},
+		PageNumber: 1, PageSize: 10})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
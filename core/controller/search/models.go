@@ -0,0 +1,39 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+type SearchParams struct {
+	Keyword string
+	// ResourceTypes are the types of resources to search, all the types if empty
+	ResourceTypes []string
+	PageNumber    int
+	PageSize      int
+}
+
+type Result struct {
+	ResourceType string `json:"resourceType"`
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	FullName     string `json:"fullName"`
+	FullPath     string `json:"fullPath"`
+	// MatchedFields are the fields matching the keyword, including name, path, description, tag and owner
+	MatchedFields []string `json:"matchedFields"`
+	// Tag is the tag matching the keyword in form of key=value
+	Tag string `json:"tag,omitempty"`
+	// Owner is the name of the owner matching the keyword
+	Owner string `json:"owner,omitempty"`
+	Score int    `json:"score"`
+}
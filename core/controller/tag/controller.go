@@ -18,10 +18,12 @@ import (
 	"context"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	"github.com/horizoncd/horizon/pkg/tag/models"
@@ -58,6 +60,10 @@ func (c *controller) List(ctx context.Context, resourceType string, resourceID u
 	const op = "cluster tag controller: list"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	if err := validateResourceType(resourceType); err != nil {
+		return nil, err
+	}
+
 	tags, err := c.tagMgr.ListByResourceTypeID(ctx, resourceType, resourceID)
 	if err != nil {
 		return nil, err
@@ -70,6 +76,10 @@ func (c *controller) Update(ctx context.Context, resourceType string, resourceID
 	const op = "cluster tag controller: update"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	if err := validateResourceType(resourceType); err != nil {
+		return err
+	}
+
 	tags := r.toTags(resourceType, resourceID)
 	if err := tagmanager.ValidateUpsert(tags); err != nil {
 		return err
@@ -135,3 +145,12 @@ func (c *controller) GetMetatagKeys(ctx context.Context) ([]string, error) {
 func (c *controller) GetMetatagsByKey(ctx context.Context, key string) ([]*models.Metatag, error) {
 	return c.tagMgr.GetMetatagsByKey(ctx, key)
 }
+
+// validateResourceType checks that tags can be attached to the given resource type
+func validateResourceType(resourceType string) error {
+	switch resourceType {
+	case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
+		return nil
+	}
+	return perror.Wrapf(herrors.ErrParamInvalid, "tags are not supported for resource type %s", resourceType)
+}
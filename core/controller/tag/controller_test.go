@@ -24,12 +24,14 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
 	"github.com/horizoncd/horizon/lib/orm"
 	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
@@ -249,4 +251,20 @@ func Test(t *testing.T) {
 	resp, err = c.ListSubResourceTags(ctx, common.ResourceApplication, cluster.ApplicationID)
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(resp.Tags))
+
+	err = c.Update(ctx, common.ResourceGroup, 1, &UpdateRequest{
+		Tags: []*tagmodels.TagBasic{
+			{
+				Key:   "costCenter",
+				Value: "infra",
+			},
+		},
+	})
+	assert.Nil(t, err)
+	resp, err = c.List(ctx, common.ResourceGroup, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Tags))
+
+	_, err = c.List(ctx, common.ResourceTemplate, 1)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
 }
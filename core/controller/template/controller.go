@@ -31,7 +31,6 @@ import (
 	"github.com/horizoncd/horizon/lib/q"
 	hctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
-	"github.com/horizoncd/horizon/pkg/git"
 	gmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupModels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
@@ -39,6 +38,7 @@ import (
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	"github.com/horizoncd/horizon/pkg/template/models"
@@ -46,6 +46,7 @@ import (
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/templatesource"
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
@@ -80,12 +81,18 @@ type Controller interface {
 	UpdateTemplate(ctx context.Context, templateID uint, request UpdateTemplateRequest) error
 	// UpdateRelease deletes a template release by ID
 	UpdateRelease(ctx context.Context, releaseID uint, request UpdateReleaseRequest) error
-	// SyncReleaseToRepo downloads template from gitlab, packages the template and uploads it to chart repo
+	// SyncReleaseToRepo downloads template from its source, packages the template and uploads it to chart repo
 	SyncReleaseToRepo(ctx context.Context, releaseID uint) error
+	// ListSourceVersions lists versions of the template in its source, which can be created as releases
+	ListSourceVersions(ctx context.Context, templateID uint) ([]string, error)
+	// PromoteRelease promotes a canary release to stable, which makes it available to all clusters
+	PromoteRelease(ctx context.Context, releaseID uint) error
+	// GetCanaryStats gets the deploy statistics of clusters using the release since it became canary
+	GetCanaryStats(ctx context.Context, releaseID uint) (*CanaryStats, error)
 }
 
 type controller struct {
-	gitgetter            git.Helper
+	templateSources      templatesource.Sources
 	templateRepo         templaterepo.TemplateRepo
 	groupMgr             gmanager.Manager
 	templateMgr          tmanager.Manager
@@ -100,7 +107,7 @@ var _ Controller = (*controller)(nil)
 // NewController initializes a new controller
 func NewController(param *param.Param, repo templaterepo.TemplateRepo) Controller {
 	return &controller{
-		gitgetter:            param.GitGetter,
+		templateSources:      param.TemplateSources,
 		templateMgr:          param.TemplateMgr,
 		templateReleaseMgr:   param.TemplateReleaseMgr,
 		templateSchemaGetter: param.TemplateSchemaGetter,
@@ -396,6 +403,23 @@ func (c *controller) GetTemplateSchema(ctx context.Context, releaseID uint,
 		return nil, err
 	}
 
+	// the release never synced to the template repo is read from the source of the template
+	if release.ChartVersion == "" {
+		template, err := c.templateMgr.GetByID(ctx, release.Template)
+		if err != nil {
+			return nil, err
+		}
+		source, err := c.templateSources.Get(template.SourceType)
+		if err != nil {
+			return nil, err
+		}
+		schemas, err := templatesource.GetSchema(ctx, source, template.Repository, release.Name, param)
+		if err != nil {
+			return nil, err
+		}
+		return toSchemas(schemas), nil
+	}
+
 	schemas, err := c.templateSchemaGetter.GetTemplateSchema(ctx, release.TemplateName, release.Name, param)
 	if err != nil {
 		return nil, err
@@ -524,7 +548,7 @@ func (c *controller) ListTemplateReleaseByTemplateID(ctx context.Context, templa
 		_ = c.templateReleaseMgr.UpdateByID(ctx, release.ID, release)
 	}
 
-	if user.IsAdmin() {
+	if user.IsAdmin() || user.IsAuditor() {
 		return toReleases(releases), nil
 	}
 
@@ -575,6 +599,12 @@ func (c *controller) CreateTemplate(ctx context.Context,
 	if template.Type == "" {
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "template type is empty")
 	}
+	if template.SourceType == "" {
+		template.SourceType = templatesource.KindGit
+	}
+	if _, err := c.templateSources.Get(template.SourceType); err != nil {
+		return nil, err
+	}
 
 	template, err = c.templateMgr.Create(ctx, template)
 	if err != nil {
@@ -613,16 +643,16 @@ func (c *controller) CreateRelease(ctx context.Context,
 	}
 
 	if syncToRepo, ok := ctx.Value(hctx.ReleaseSyncToRepo).(bool); !ok || (ok && syncToRepo) {
-		tag, err := c.getTag(ctx, template.Repository, template.ChartName, release.Name)
+		chart, err := c.getChart(ctx, template, release.Name)
 		if err != nil {
 			return nil, err
 		}
-		chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-		err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+		chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, chart.Revision)
+		err = c.syncReleaseToRepo(chart.ArchiveData, template.ChartName, chartVersion)
 		if err != nil {
 			return nil, err
 		}
-		release.CommitID = tag.ShortID
+		release.CommitID = chart.Revision
 		release.SyncStatus = trmodels.StatusSucceed
 		release.ChartVersion = chartVersion
 	} else {
@@ -778,11 +808,22 @@ func (c *controller) UpdateTemplate(ctx context.Context, templateID uint, reques
 			"can not modify template repository while releases existing:\n"+
 				"releases numbers: %d", len(releases))
 	}
+	if len(releases) != 0 && request.SourceType != "" &&
+		request.SourceType != template.SourceType {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"can not modify template source type while releases existing:\n"+
+				"releases numbers: %d", len(releases))
+	}
 
 	tplUpdate, err := request.toTemplateModel(ctx)
 	if err != nil {
 		return err
 	}
+	if tplUpdate.SourceType != "" {
+		if _, err := c.templateSources.Get(tplUpdate.SourceType); err != nil {
+			return err
+		}
+	}
 
 	return c.templateMgr.UpdateByID(ctx, templateID, tplUpdate)
 }
@@ -797,7 +838,111 @@ func (c *controller) UpdateRelease(ctx context.Context, releaseID uint, request
 		return err
 	}
 
-	return c.templateReleaseMgr.UpdateByID(ctx, releaseID, trUpdate)
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return err
+	}
+	recommended := release.Recommended != nil && *release.Recommended
+	if request.Recommended != nil {
+		recommended = *request.Recommended
+	}
+	if request.Canary != nil {
+		if err := request.Canary.validate(recommended); err != nil {
+			return err
+		}
+	} else if recommended && release.IsCanary() {
+		return perror.Wrap(herrors.ErrParamInvalid, "canary release cannot be recommended")
+	}
+	if request.Channel != "" {
+		if err := validateChannel(request.Channel, request.DeprecationMessage, recommended); err != nil {
+			return err
+		}
+	} else if request.DeprecationMessage != "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "deprecation message must be updated with channel")
+	} else if recommended && release.IsDeprecated() {
+		return perror.Wrap(herrors.ErrParamInvalid, "deprecated release cannot be recommended")
+	}
+
+	if err := c.templateReleaseMgr.UpdateByID(ctx, releaseID, trUpdate); err != nil {
+		return err
+	}
+	if request.Channel != "" {
+		release.Channel = request.Channel
+		release.DeprecationMessage = request.DeprecationMessage
+		if err := c.templateReleaseMgr.UpdateChannelByID(ctx, releaseID, release); err != nil {
+			return err
+		}
+	}
+	if request.Canary == nil {
+		return nil
+	}
+	request.Canary.applyTo(release, time.Now())
+	return c.templateReleaseMgr.UpdateCanaryByID(ctx, releaseID, release)
+}
+
+func (c *controller) PromoteRelease(ctx context.Context, releaseID uint) error {
+	const op = "template controller: promoteRelease"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return err
+	}
+	if !release.IsCanary() {
+		return perror.Wrapf(herrors.ErrParamInvalid, "release %s is not canary", release.Name)
+	}
+
+	stable := false
+	return c.templateReleaseMgr.UpdateCanaryByID(ctx, releaseID, &trmodels.TemplateRelease{
+		Canary: &stable,
+	})
+}
+
+func (c *controller) GetCanaryStats(ctx context.Context, releaseID uint) (*CanaryStats, error) {
+	const op = "template controller: getCanaryStats"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.checkHasOnlyOwnerPermissionForRelease(ctx, release) {
+		return nil, perror.Wrapf(herrors.ErrForbidden,
+			"you have no permission to access this resource:\n"+
+				"release id = %d", releaseID)
+	}
+
+	ctx = context.WithValue(ctx, hctx.TemplateOnlyRefCount, true)
+	_, clusters, err := c.templateReleaseMgr.GetRefOfCluster(ctx, releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// deploys of a stable release are counted since it's created
+	since := release.CreatedAt
+	if release.CanaryAt != nil {
+		since = *release.CanaryAt
+	}
+	counts, err := c.templateReleaseMgr.CountDeploysByStatus(ctx, releaseID,
+		[]string{prmodels.ActionBuildDeploy, prmodels.ActionDeploy}, since)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CanaryStats{Clusters: clusters, Since: &since}
+	for _, count := range counts {
+		stats.Deploys += count.Count
+		switch prmodels.PipelineStatus(count.Status) {
+		case prmodels.StatusOK:
+			stats.Succeeded += count.Count
+		case prmodels.StatusFailed:
+			stats.Failed += count.Count
+		}
+	}
+	if finished := stats.Succeeded + stats.Failed; finished > 0 {
+		stats.FailureRate = float64(stats.Failed) / float64(finished)
+	}
+	return stats, nil
 }
 
 func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) error {
@@ -814,20 +959,41 @@ func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) erro
 		return err
 	}
 
-	tag, err := c.getTag(ctx, template.Repository, template.ChartName, release.Name)
+	chart, err := c.getChart(ctx, template, release.Name)
 	if err != nil {
 		return err
 	}
-	chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-	err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+	chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, chart.Revision)
+	err = c.syncReleaseToRepo(chart.ArchiveData, template.ChartName, chartVersion)
 	if err != nil {
-		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, err.Error())
+		_ = c.handleReleaseSyncStatus(ctx, release, chart.Revision, err.Error())
 	} else {
-		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, "")
+		_ = c.handleReleaseSyncStatus(ctx, release, chart.Revision, "")
 	}
 	return err
 }
 
+func (c *controller) ListSourceVersions(ctx context.Context, templateID uint) ([]string, error) {
+	const op = "template controller: listSourceVersions"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	template, err := c.templateMgr.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.checkHasOnlyOwnerPermissionForTemplate(ctx, template) {
+		return nil, perror.Wrapf(herrors.ErrForbidden,
+			"you have no permission to access this resource:\n"+
+				"template id = %d", templateID)
+	}
+
+	source, err := c.templateSources.Get(template.SourceType)
+	if err != nil {
+		return nil, err
+	}
+	return source.ListVersions(ctx, template.Repository)
+}
+
 func (c *controller) handleReleaseSyncStatus(ctx context.Context,
 	release *trmodels.TemplateRelease, commitID string, failedReason string) error {
 	if failedReason == "" {
@@ -843,14 +1009,14 @@ func (c *controller) handleReleaseSyncStatus(ctx context.Context,
 	return c.templateReleaseMgr.UpdateByID(ctx, release.ID, release)
 }
 
-func (c *controller) getTag(ctx context.Context, repository,
-	name, tag string) (*git.Tag, error) {
-	tagDetail, err := c.gitgetter.GetTagArchive(ctx, repository, tag)
+// getChart fetches the chart of the version from the source of the template
+func (c *controller) getChart(ctx context.Context, template *models.Template,
+	version string) (*templatesource.Chart, error) {
+	source, err := c.templateSources.Get(template.SourceType)
 	if err != nil {
 		return nil, err
 	}
-
-	return tagDetail, nil
+	return source.GetChart(ctx, template.Repository, version)
 }
 
 func (c *controller) checkStatusForReleases(ctx context.Context,
@@ -876,12 +1042,12 @@ func (c *controller) checkStatusForRelease(ctx context.Context,
 		return release, nil
 	}
 
-	tag, err := c.getTag(ctx, template.Repository, release.ChartName, release.Name)
+	chart, err := c.getChart(ctx, template, release.Name)
 	if err != nil {
 		release.SyncStatus = trmodels.StatusUnknown
 		return release, err
 	}
-	if tag.ShortID != release.CommitID {
+	if chart.Revision != release.CommitID {
 		release.SyncStatus = trmodels.StatusOutOfSync
 	}
 	return release, nil
@@ -904,7 +1070,7 @@ func (c *controller) checkHasOnlyOwnerPermissionForTemplate(ctx context.Context,
 	if err != nil {
 		return false
 	}
-	if user.IsAdmin() {
+	if user.IsAdmin() || user.IsAuditor() {
 		return true
 	}
 
@@ -932,7 +1098,7 @@ func (c *controller) checkHasOnlyOwnerPermissionForRelease(ctx context.Context,
 	if err != nil {
 		return false
 	}
-	if user.IsAdmin() {
+	if user.IsAdmin() || user.IsAuditor() {
 		return true
 	}
 
@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"reflect"
 	"regexp"
+	"sort"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -52,6 +53,7 @@ import (
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	"github.com/horizoncd/horizon/pkg/template/models"
@@ -59,6 +61,7 @@ import (
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	reposchema "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
+	"github.com/horizoncd/horizon/pkg/templatesource"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 )
 
@@ -199,7 +202,7 @@ func TestList(t *testing.T) {
 		templateMgr:        templateMgr,
 		templateReleaseMgr: templateReleaseMgr,
 		groupMgr:           groupMgr,
-		gitgetter:          gitlabLib,
+		templateSources:    templatesource.NewSources(gitlabLib, nil),
 		memberSvc:          memberService,
 		memberMgr:          memberMgr,
 	}
@@ -481,6 +484,41 @@ func TestGetTemplate(t *testing.T) {
 	assert.NotNil(t, schemas)
 }
 
+func TestTemplateSource(t *testing.T) {
+	createContext()
+
+	mockCtl := gomock.NewController(t)
+	gitHelper := gitmock.NewMockHelper(mockCtl)
+	gitHelper.EXPECT().ListTag(gomock.Any(), templateRepo, gomock.Any()).
+		Return([]string{"v0.0.2", templateTag}, nil).Times(1)
+
+	ctl := &controller{
+		templateMgr:        mgr.TemplateMgr,
+		templateReleaseMgr: mgr.TemplateReleaseMgr,
+		groupMgr:           mgr.GroupMgr,
+		memberMgr:          mgr.MemberMgr,
+		templateSources:    templatesource.NewSources(gitHelper, nil),
+	}
+
+	request := CreateTemplateRequest{
+		Name:       templateName,
+		Repository: templateRepo,
+		Type:       "v1",
+		SourceType: "svn",
+	}
+	_, err := ctl.CreateTemplate(ctx, 0, request)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	request.SourceType = ""
+	template, err := ctl.CreateTemplate(ctx, 0, request)
+	assert.Nil(t, err)
+	assert.Equal(t, templatesource.KindGit, template.SourceType)
+
+	versions, err := ctl.ListSourceVersions(ctx, template.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"v0.0.2", templateTag}, versions)
+}
+
 func TestUpdateTemplate(t *testing.T) {
 	createContext()
 	ctl, _ := createController(t)
@@ -557,6 +595,106 @@ func TestUpdateTemplate(t *testing.T) {
 	assert.Equal(t, onlyOwnerTrue, release.OnlyOwner)
 }
 
+func TestCanaryRelease(t *testing.T) {
+	createContext()
+	ctl, _ := createController(t)
+
+	ctx = context.WithValue(ctx, hctx.ReleaseSyncToRepo, false)
+	createChart(t, ctl, 0)
+
+	recommended := false
+	err := ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{
+		Recommended: &recommended,
+		Canary:      &Canary{Percentage: 20, Clusters: []string{"cluster-1"}},
+	})
+	assert.Nil(t, err)
+
+	release, err := ctl.GetRelease(ctx, 1)
+	assert.Nil(t, err)
+	assert.NotNil(t, release.Canary)
+	assert.Equal(t, uint(20), release.Canary.Percentage)
+	assert.Equal(t, []string{"cluster-1"}, release.Canary.Clusters)
+	assert.NotNil(t, release.Canary.StartedAt)
+
+	// canary release cannot be recommended
+	recommended = true
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{Recommended: &recommended})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{Canary: &Canary{Percentage: 101}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	stats, err := ctl.GetCanaryStats(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), stats.Deploys)
+	assert.Equal(t, float64(0), stats.FailureRate)
+
+	err = ctl.PromoteRelease(ctx, 1)
+	assert.Nil(t, err)
+	release, err = ctl.GetRelease(ctx, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, release.Canary)
+
+	err = ctl.PromoteRelease(ctx, 1)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func TestReleaseChannel(t *testing.T) {
+	createContext()
+	ctl, _ := createController(t)
+
+	ctx = context.WithValue(ctx, hctx.ReleaseSyncToRepo, false)
+	createChart(t, ctl, 0)
+
+	release, err := ctl.GetRelease(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, trmodels.ChannelStable, release.Channel)
+
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{Channel: "alpha"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{Channel: trmodels.ChannelBeta, DeprecationMessage: "old"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{DeprecationMessage: "old"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	recommended := false
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{
+		Recommended:        &recommended,
+		Channel:            trmodels.ChannelDeprecated,
+		DeprecationMessage: "use v1.0.1 instead",
+	})
+	assert.Nil(t, err)
+	release, err = ctl.GetRelease(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, trmodels.ChannelDeprecated, release.Channel)
+	assert.Equal(t, "use v1.0.1 instead", release.DeprecationMessage)
+
+	// deprecated release cannot be recommended
+	recommended = true
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{Recommended: &recommended})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// the deprecation message is cleared when the release is not deprecated any more
+	err = ctl.UpdateRelease(ctx, 1, UpdateReleaseRequest{Channel: trmodels.ChannelBeta})
+	assert.Nil(t, err)
+	release, err = ctl.GetRelease(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, trmodels.ChannelBeta, release.Channel)
+	assert.Empty(t, release.DeprecationMessage)
+
+	releases := Releases{
+		{Name: "v1.0.2", Channel: trmodels.ChannelDeprecated},
+		{Name: "v1.0.0", Channel: trmodels.ChannelStable},
+		{Name: "v1.0.1", Channel: trmodels.ChannelStable, Recommended: true},
+		{Name: "v1.0.3", Channel: trmodels.ChannelBeta},
+	}
+	sort.Sort(releases)
+	var names []string
+	for _, r := range releases {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"v1.0.1", "v1.0.3", "v1.0.0", "v1.0.2"}, names)
+}
+
 func TestListTemplate(t *testing.T) {
 	createContext()
 	ctl, _ := createController(t)
@@ -592,7 +730,8 @@ func createContext() {
 	db, _ = orm.NewSqliteDB("")
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{},
 		&amodels.Application{}, &cmodels.Cluster{}, &membermodels.Member{},
-		&tmodels.Template{}, &membermodels.Member{}, &groupmodels.Group{}, &usermodels.User{}); err != nil {
+		&tmodels.Template{}, &membermodels.Member{}, &groupmodels.Group{}, &usermodels.User{},
+		&prmodels.Pipelinerun{}); err != nil {
 		panic(err)
 	}
 	mgr = managerparam.InitManager(db)
@@ -627,7 +766,7 @@ func createController(t *testing.T) (Controller, *mock_repo.MockTemplateRepo) {
 	assert.Nil(t, err)
 
 	ctl := &controller{
-		gitgetter:            githubGetter,
+		templateSources:      templatesource.NewSources(githubGetter, nil),
 		templateRepo:         repo,
 		groupMgr:             mgr.GroupMgr,
 		templateMgr:          mgr.TemplateMgr,
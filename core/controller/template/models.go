@@ -36,6 +36,8 @@ type CreateTemplateRequest struct {
 	Repository           string `json:"repository"`
 	Type                 string `json:"type"`
 	OnlyOwner            bool   `json:"onlyOwner"`
+	// SourceType is the kind of the source the chart is fetched from, git, helm or oci, it's git if empty
+	SourceType string `json:"sourceType"`
 }
 
 func (c *CreateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.Template, error) {
@@ -52,6 +54,7 @@ func (c *CreateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.T
 		Name:        c.Name,
 		Description: c.Description,
 		Repository:  c.Repository,
+		SourceType:  c.SourceType,
 		OnlyOwner:   &c.OnlyOwner,
 		Type:        c.Type,
 	}
@@ -63,6 +66,11 @@ type CreateReleaseRequest struct {
 	Recommended bool   `json:"recommended"`
 	Description string `json:"description"`
 	OnlyOwner   bool   `json:"onlyOwner"`
+	// Canary publishes the release as canary if it's not nil
+	Canary *Canary `json:"canary,omitempty"`
+	// Channel is one of stable, beta and deprecated, it's stable if empty
+	Channel            string `json:"channel,omitempty"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
 }
 
 func (c *CreateReleaseRequest) toReleaseModel(ctx context.Context,
@@ -75,6 +83,17 @@ func (c *CreateReleaseRequest) toReleaseModel(ctx context.Context,
 		Recommended:  &c.Recommended,
 		OnlyOwner:    &c.OnlyOwner,
 	}
+	if err := validateChannel(c.Channel, c.DeprecationMessage, c.Recommended); err != nil {
+		return nil, err
+	}
+	t.Channel = channelOrDefault(c.Channel)
+	t.DeprecationMessage = c.DeprecationMessage
+	if c.Canary != nil {
+		if err := c.Canary.validate(c.Recommended); err != nil {
+			return nil, err
+		}
+		c.Canary.applyTo(t, time.Now())
+	}
 
 	return t, nil
 }
@@ -83,6 +102,7 @@ type UpdateTemplateRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Repository  string `json:"repository"`
+	SourceType  string `json:"sourceType"`
 	OnlyOwner   bool   `json:"onlyOwner"`
 	Type        string `json:"type"`
 	WithoutCI   bool   `json:"withoutCI"`
@@ -103,6 +123,7 @@ func (c *UpdateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.T
 		Name:        c.Name,
 		Description: c.Description,
 		Repository:  c.Repository,
+		SourceType:  c.SourceType,
 		OnlyOwner:   &c.OnlyOwner,
 		Type:        c.Type,
 		WithoutCI:   c.WithoutCI,
@@ -115,6 +136,12 @@ type UpdateReleaseRequest struct {
 	Recommended *bool  `json:"recommended,omitempty"`
 	Description string `json:"description"`
 	OnlyOwner   bool   `json:"onlyOwner"`
+	// Canary updates the canary config of the release if it's not nil,
+	// use promote api to make a canary release stable
+	Canary *Canary `json:"canary,omitempty"`
+	// Channel updates the channel and the deprecation message of the release if it's not empty
+	Channel            string `json:"channel,omitempty"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
 }
 
 func (c *UpdateReleaseRequest) toReleaseModel(ctx context.Context) (*trmodels.TemplateRelease, error) {
@@ -127,12 +154,96 @@ func (c *UpdateReleaseRequest) toReleaseModel(ctx context.Context) (*trmodels.Te
 	return tr, nil
 }
 
+// Canary is the config of a canary release, which is only available to
+// the opted-in clusters and a percentage of clusters
+type Canary struct {
+	// Percentage of clusters the release is available to, from 0 to 100
+	Percentage uint `json:"percentage"`
+	// Clusters are names of the opted-in clusters
+	Clusters []string `json:"clusters"`
+	// StartedAt is the time when the release became canary, it's ignored in requests
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+}
+
+func (c *Canary) validate(recommended bool) error {
+	if c.Percentage > 100 {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"canary percentage should be between 0 and 100, got %d", c.Percentage)
+	}
+	if recommended {
+		return perror.Wrap(herrors.ErrParamInvalid, "canary release cannot be recommended")
+	}
+	return nil
+}
+
+// applyTo makes the release canary, startedAt is kept if the release is canary already
+func (c *Canary) applyTo(tr *trmodels.TemplateRelease, now time.Time) {
+	if !tr.IsCanary() || tr.CanaryAt == nil {
+		tr.CanaryAt = &now
+	}
+	canary := true
+	tr.Canary = &canary
+	tr.CanaryPercentage = c.Percentage
+	tr.CanaryClusters = trmodels.JoinCanaryClusters(c.Clusters)
+}
+
+func channelOrDefault(channel string) string {
+	if channel == "" {
+		return trmodels.ChannelStable
+	}
+	return channel
+}
+
+// validateChannel checks the channel is known, only deprecated releases have deprecation messages,
+// and deprecated releases cannot be recommended
+func validateChannel(channel, deprecationMessage string, recommended bool) error {
+	switch channelOrDefault(channel) {
+	case trmodels.ChannelStable, trmodels.ChannelBeta:
+		if deprecationMessage != "" {
+			return perror.Wrap(herrors.ErrParamInvalid, "deprecation message is only for deprecated release")
+		}
+	case trmodels.ChannelDeprecated:
+		if recommended {
+			return perror.Wrap(herrors.ErrParamInvalid, "deprecated release cannot be recommended")
+		}
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "channel %s is not one of %s, %s and %s",
+			channel, trmodels.ChannelStable, trmodels.ChannelBeta, trmodels.ChannelDeprecated)
+	}
+	return nil
+}
+
+func toCanary(m *trmodels.TemplateRelease) *Canary {
+	if !m.IsCanary() {
+		return nil
+	}
+	return &Canary{
+		Percentage: m.CanaryPercentage,
+		Clusters:   trmodels.ParseCanaryClusters(m.CanaryClusters),
+		StartedAt:  m.CanaryAt,
+	}
+}
+
+// CanaryStats is the statistics of deploys from clusters using a canary release
+type CanaryStats struct {
+	// Clusters is the number of clusters using the release
+	Clusters uint `json:"clusters"`
+	// Deploys is the number of deploys since the release became canary
+	Deploys   uint `json:"deploys"`
+	Succeeded uint `json:"succeeded"`
+	Failed    uint `json:"failed"`
+	// FailureRate is failed / (succeeded + failed), it's 0 if no deploy finished
+	FailureRate float64    `json:"failureRate"`
+	Since       *time.Time `json:"since,omitempty"`
+}
+
 type Template struct {
 	ID          uint      `json:"id"`
 	Name        string    `json:"name"`
 	ChartName   string    `json:"chartName"`
 	Description string    `json:"description"`
 	Repository  string    `json:"repository"`
+	SourceType  string    `json:"sourceType"`
 	Releases    Releases  `json:"releases,omitempty"`
 	FullPath    string    `json:"fullPath,omitempty"`
 	GroupID     uint      `json:"group"`
@@ -155,6 +266,7 @@ func toTemplate(m *tmodels.Template) *Template {
 		ChartName:   m.ChartName,
 		Description: m.Description,
 		Repository:  m.Repository,
+		SourceType:  m.SourceType,
 		GroupID:     m.GroupID,
 		WithoutCI:   m.WithoutCI,
 		Type:        m.Type,
@@ -198,10 +310,14 @@ type Release struct {
 	SyncStatus     string    `json:"syncStatus"`
 	LastSyncAt     time.Time `json:"lastSyncAt"`
 	FailedReason   string    `json:"failedReason"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	CreatedBy      uint      `json:"createdBy"`
-	UpdatedBy      uint      `json:"updatedBy"`
+	Canary         *Canary   `json:"canary,omitempty"`
+	Channel        string    `json:"channel"`
+	// DeprecationMessage tells why the release is deprecated and which release to use instead
+	DeprecationMessage string    `json:"deprecationMessage,omitempty"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+	CreatedBy          uint      `json:"createdBy"`
+	UpdatedBy          uint      `json:"updatedBy"`
 }
 
 type Releases []*Release
@@ -211,12 +327,13 @@ func (r Releases) Len() int {
 }
 
 func (r Releases) Less(i, j int) bool {
-	// recommended first
-	if r[i].Recommended {
-		return true
+	// recommended first and deprecated last
+	if r[i].Recommended != r[j].Recommended {
+		return r[i].Recommended
 	}
-	if r[j].Recommended {
-		return false
+	if deprecatedI, deprecatedJ := r[i].Channel == trmodels.ChannelDeprecated,
+		r[j].Channel == trmodels.ChannelDeprecated; deprecatedI != deprecatedJ {
+		return deprecatedJ
 	}
 	return r[i].Name > r[j].Name
 }
@@ -230,20 +347,23 @@ func toRelease(m *trmodels.TemplateRelease) *Release {
 		return nil
 	}
 	tr := &Release{
-		ID:             m.ID,
-		Name:           m.Name,
-		ChartVersion:   m.ChartVersion,
-		Description:    m.Description,
-		TemplateID:     m.Template,
-		TemplateName:   m.TemplateName,
-		SyncStatusCode: uint8(m.SyncStatus),
-		LastSyncAt:     m.LastSyncAt,
-		CommitID:       m.CommitID,
-		FailedReason:   m.FailedReason,
-		CreatedAt:      m.Model.CreatedAt,
-		UpdatedAt:      m.Model.UpdatedAt,
-		CreatedBy:      m.CreatedBy,
-		UpdatedBy:      m.UpdatedBy,
+		ID:                 m.ID,
+		Name:               m.Name,
+		ChartVersion:       m.ChartVersion,
+		Description:        m.Description,
+		TemplateID:         m.Template,
+		TemplateName:       m.TemplateName,
+		SyncStatusCode:     uint8(m.SyncStatus),
+		LastSyncAt:         m.LastSyncAt,
+		CommitID:           m.CommitID,
+		FailedReason:       m.FailedReason,
+		Canary:             toCanary(m),
+		Channel:            m.ChannelOrDefault(),
+		DeprecationMessage: m.DeprecationMessage,
+		CreatedAt:          m.Model.CreatedAt,
+		UpdatedAt:          m.Model.UpdatedAt,
+		CreatedBy:          m.CreatedBy,
+		UpdatedBy:          m.UpdatedBy,
 	}
 	switch trmodels.SyncStatus(tr.SyncStatusCode) {
 	case trmodels.StatusSucceed:
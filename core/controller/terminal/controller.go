@@ -27,6 +27,8 @@ import (
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	auditlogmanager "github.com/horizoncd/horizon/pkg/auditlog/manager"
+	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
@@ -34,6 +36,7 @@ import (
 	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	"github.com/horizoncd/horizon/pkg/util/errors"
@@ -48,6 +51,10 @@ type Controller interface {
 	// CreateShell returns sessionID and sockJSHandler according to clusterID,podName,containerName
 	CreateShell(ctx context.Context, clusterID uint, podName, containerName string) (sessionID string,
 		sockJSHandler http.Handler, err error)
+	// Exec attaches an interactive shell to the container over the stream connected,
+	// once the current user is allowed to run commands in the cluster's pods.
+	// It returns when the shell exits or the stream is closed, and the session is recorded in the audit log.
+	Exec(ctx context.Context, clusterID uint, request *ExecRequest, connect func() (PtyHandler, error)) error
 }
 
 type controller struct {
@@ -59,11 +66,14 @@ type controller struct {
 	envRegionMgr       envregionmanager.Manager
 	regionMgr          regionmanager.Manager
 	clusterGitRepo     gitrepo.ClusterGitRepo
+	k8sUtil            cd.K8sUtil
+	authorizer         rbac.Authorizer
+	auditLogMgr        auditlogmanager.Manager
 }
 
 var _ Controller = (*controller)(nil)
 
-func NewController(param *param.Param) Controller {
+func NewController(param *param.Param, authorizer rbac.Authorizer) Controller {
 	return &controller{
 		kubeClientFty:      kubeclient.Fty,
 		clusterMgr:         param.ClusterMgr,
@@ -73,6 +83,9 @@ func NewController(param *param.Param) Controller {
 		envRegionMgr:       param.EnvRegionMgr,
 		regionMgr:          param.RegionMgr,
 		clusterGitRepo:     param.ClusterGitRepo,
+		k8sUtil:            param.K8sUtil,
+		authorizer:         authorizer,
+		auditLogMgr:        param.AuditLogMgr,
 	}
 }
 
@@ -114,7 +127,7 @@ func (c *controller) GetSockJSHandler(ctx context.Context, sessionID string) (ht
 		return nil, err
 	}
 
-	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
+	kubeConfig, kubeClient, err := c.kubeClientFty.GetByRegion(regionEntity.Region)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +183,7 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 		return "", nil, err
 	}
 
-	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
+	kubeConfig, kubeClient, err := c.kubeClientFty.GetByRegion(regionEntity.Region)
 	if err != nil {
 		return "", nil, err
 	}
@@ -0,0 +1,136 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	auditmodels "github.com/horizoncd/horizon/pkg/auditlog/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/cd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _subresourceExec = "exec"
+
+// authorizeExec checks the current user is allowed to run commands in the cluster's pods.
+// The websocket handshake is a get request, which is also allowed for the read-only roles by the auth middleware.
+func (c *controller) authorizeExec(ctx context.Context, clusterID uint) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            "create",
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceCluster,
+		SubResource:     _subresourceExec,
+		Name:            strconv.FormatUint(uint64(clusterID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden, "exec in cluster %d is not allowed: %s", clusterID, reason)
+	}
+	return nil
+}
+
+func (c *controller) Exec(ctx context.Context, clusterID uint, request *ExecRequest,
+	connect func() (PtyHandler, error)) (err error) {
+	const op = "terminal controller: exec"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.authorizeExec(ctx, clusterID); err != nil {
+		return err
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return err
+	}
+
+	stream, err := connect()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	defer func() {
+		c.recordExecSession(ctx, clusterID, request, time.Since(start))
+	}()
+
+	return c.k8sUtil.Shell(ctx, &cd.ShellParams{
+		RegionEntity: regionEntity,
+		Cluster:      cluster.Name,
+		Namespace:    envValue.Namespace,
+		Pod:          request.PodName,
+		Container:    request.ContainerName,
+		Stdin:        stream,
+		Stdout:       stream,
+		SizeQueue:    stream,
+	})
+}
+
+// recordExecSession records the session into the audit log as the audit middleware does for the mutating requests
+func (c *controller) recordExecSession(ctx context.Context, clusterID uint, request *ExecRequest,
+	duration time.Duration) {
+	auditLog := &auditmodels.AuditLog{
+		Method:       http.MethodGet,
+		Path:         request.RequestURI,
+		ResourceType: common.ResourceCluster,
+		ResourceName: strconv.FormatUint(uint64(clusterID), 10),
+		SubResource:  _subresourceExec,
+		StatusCode:   http.StatusSwitchingProtocols,
+		LatencyMs:    duration.Milliseconds(),
+		SourceIP:     request.SourceIP,
+	}
+	if currentUser, err := common.UserFromContext(ctx); err == nil {
+		auditLog.UserID = currentUser.GetID()
+		auditLog.UserName = currentUser.GetName()
+	}
+	if rid, err := requestid.FromContext(ctx); err == nil {
+		auditLog.RequestID = rid
+	}
+	if err := c.auditLogMgr.Create(ctx, auditLog); err != nil {
+		log.Warningf(ctx, "record exec session error, cluster = %d, pod = %s, err = %v",
+			clusterID, request.PodName, err)
+	}
+}
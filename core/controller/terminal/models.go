@@ -17,3 +17,13 @@ package terminal
 type SessionIDResp struct {
 	ID string `json:"id"`
 }
+
+// ExecRequest opens a shell to a container of the cluster's pod
+type ExecRequest struct {
+	PodName string
+	// ContainerName defaults to the first container of the pod
+	ContainerName string
+	// SourceIP and RequestURI are recorded in the audit log of the session
+	SourceIP   string
+	RequestURI string
+}
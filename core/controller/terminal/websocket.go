@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// _closeTimeout bounds the time to send the close message to a client which may be gone
+const _closeTimeout = time.Second
+
+// WebSocketSession implements PtyHandler over a websocket connection,
+// the messages are the same as the ones of Session
+type WebSocketSession struct {
+	conn     *websocket.Conn
+	sizeChan chan remotecommand.TerminalSize
+	doneChan chan struct{}
+	// pending is the stdin received but not read yet
+	pending   []byte
+	writeLock sync.Mutex
+	closeOnce sync.Once
+}
+
+var _ PtyHandler = (*WebSocketSession)(nil)
+
+func NewWebSocketSession(conn *websocket.Conn) *WebSocketSession {
+	return &WebSocketSession{
+		conn:     conn,
+		sizeChan: make(chan remotecommand.TerminalSize),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// Next handles pty->process resize events
+func (s *WebSocketSession) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-s.sizeChan:
+		return &size
+	case <-s.doneChan:
+		return nil
+	}
+}
+
+// Read handles pty->process messages (stdin, resize)
+func (s *WebSocketSession) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			// Send terminated signal to process to avoid resource leak
+			return copy(p, EndOfTransmission), err
+		}
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return copy(p, EndOfTransmission), err
+		}
+		switch msg.Op {
+		case "stdin":
+			s.pending = []byte(msg.Data)
+		case "resize":
+			select {
+			case s.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}:
+			case <-s.doneChan:
+			}
+		default:
+			return copy(p, EndOfTransmission), fmt.Errorf("unknown message type '%s'", msg.Op)
+		}
+	}
+	// a paste may be larger than the buffer
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Write handles process->pty stdout
+func (s *WebSocketSession) Write(p []byte) (int, error) {
+	if err := s.send(Message{Op: "stdout", Data: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Toast can be used to send the user any OOB messages
+func (s *WebSocketSession) Toast(p string) error {
+	return s.send(Message{Op: "toast", Data: p})
+}
+
+func (s *WebSocketSession) send(msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// Close closes the connection with the reason shown to the user, the session ends normally if err is nil
+func (s *WebSocketSession) Close(err error) {
+	s.closeOnce.Do(func() {
+		close(s.doneChan)
+		code, reason := websocket.CloseNormalClosure, "Process exited"
+		if err != nil {
+			code, reason = websocket.CloseInternalServerErr, err.Error()
+		}
+		// the reason of a control frame is limited to 123 bytes
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		// control messages are allowed to be written concurrently with the others
+		_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason),
+			time.Now().Add(_closeTimeout))
+		_ = s.conn.Close()
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+func TestWebSocketSession(t *testing.T) {
+	sessions := make(chan *WebSocketSession, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		assert.Nil(t, err)
+		sessions <- NewWebSocketSession(conn)
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.Nil(t, err)
+	defer client.Close()
+	session := <-sessions
+
+	send := func(msg Message) {
+		data, err := json.Marshal(msg)
+		assert.Nil(t, err)
+		assert.Nil(t, client.WriteMessage(websocket.TextMessage, data))
+	}
+	send(Message{Op: "resize", Rows: 24, Cols: 80})
+	send(Message{Op: "stdin", Data: "echo hello\n"})
+
+	// resizing is passed to the size queue
+	sizes := make(chan *remotecommand.TerminalSize, 1)
+	go func() {
+		sizes <- session.Next()
+	}()
+	// the stdin larger than the buffer is read in pieces
+	buf := make([]byte, 4)
+	var stdin []byte
+	for len(stdin) < len("echo hello\n") {
+		n, err := session.Read(buf)
+		assert.Nil(t, err)
+		stdin = append(stdin, buf[:n]...)
+	}
+	assert.Equal(t, "echo hello\n", string(stdin))
+	assert.Equal(t, &remotecommand.TerminalSize{Width: 80, Height: 24}, <-sizes)
+
+	n, err := session.Write([]byte("hello\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, n)
+	var msg Message
+	assert.Nil(t, client.ReadJSON(&msg))
+	assert.Equal(t, Message{Op: "stdout", Data: "hello\n"}, msg)
+
+	session.Close(nil)
+	assert.Nil(t, session.Next())
+	_, _, err = client.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+
+	// the process is terminated once the client is gone
+	n, err = session.Read(buf)
+	assert.NotNil(t, err)
+	assert.Equal(t, EndOfTransmission, string(buf[:n]))
+}
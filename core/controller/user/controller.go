@@ -88,7 +88,7 @@ func (c *controller) UpdateByID(ctx context.Context,
 		return nil, err
 	}
 
-	if u.IsAdmin == nil && u.IsBanned == nil {
+	if u.IsAdmin == nil && u.IsAuditor == nil && u.IsBanned == nil {
 		return ofUser(userInDB), nil
 	}
 
@@ -98,6 +98,9 @@ func (c *controller) UpdateByID(ctx context.Context,
 	if u.IsAdmin != nil {
 		userInDB.Admin = *u.IsAdmin
 	}
+	if u.IsAuditor != nil {
+		userInDB.Auditor = *u.IsAuditor
+	}
 
 	updatedUserInDB, err := c.userMgr.UpdateByID(ctx, id, userInDB)
 	if err != nil {
@@ -112,9 +115,9 @@ func (c *controller) ListUserLinks(ctx context.Context, uid uint) ([]*Link, erro
 	if err != nil {
 		return nil, err
 	}
-	if !user.IsAdmin() && uid != user.GetID() {
+	if !user.IsAdmin() && !user.IsAuditor() && uid != user.GetID() {
 		return nil, perror.Wrap(herrors.ErrNoPrivilege, "could not list link\n"+
-			"should be admin or link owner")
+			"should be admin, auditor or link owner")
 	}
 	links, err := c.linksMgr.ListByUserID(ctx, uid)
 	if err != nil {
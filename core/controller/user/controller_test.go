@@ -163,6 +163,13 @@ func Test(t *testing.T) {
 	assert.Equal(t, false, user.IsAdmin)
 	assert.Equal(t, true, user.IsBanned)
 
+	user, err = ctrl.UpdateByID(ctx, 2, &UpdateUserRequest{
+		IsAuditor: &resT,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, true, user.IsAuditor)
+	assert.Equal(t, true, user.IsBanned)
+
 	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
 		Admin: false,
 	})
@@ -27,6 +27,7 @@ type User struct {
 	FullName  string    `json:"fullName,omitempty"`
 	Email     string    `json:"email,omitempty"`
 	IsAdmin   bool      `json:"isAdmin"`
+	IsAuditor bool      `json:"isAuditor"`
 	IsBanned  bool      `json:"isBanned"`
 	Phone     string    `json:"phone,omitempty"`
 	UpdatedAt time.Time `json:"updatedAt,omitempty"`
@@ -40,6 +41,7 @@ func ofUser(u *models.User) *User {
 		FullName:  u.FullName,
 		Email:     u.Email,
 		IsAdmin:   u.Admin,
+		IsAuditor: u.Auditor,
 		IsBanned:  u.Banned,
 		Phone:     u.Phone,
 		UpdatedAt: u.UpdatedAt,
@@ -56,8 +58,9 @@ func ofUsers(users []*models.User) []*User {
 }
 
 type UpdateUserRequest struct {
-	IsAdmin  *bool `json:"isAdmin"`
-	IsBanned *bool `json:"isBanned"`
+	IsAdmin   *bool `json:"isAdmin"`
+	IsAuditor *bool `json:"isAuditor"`
+	IsBanned  *bool `json:"isBanned"`
 }
 
 type Link struct {
@@ -72,6 +72,8 @@ type LogSummary struct {
 	EventType    string                `json:"eventType"`
 	Extra        *string               `json:"extra"`
 	ErrorMessage string                `json:"errorMessage"`
+	Attempts     uint                  `json:"attempts"`
+	NextRetryAt  *time.Time            `json:"nextRetryAt,omitempty"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	CreatedBy    *usermodels.UserBasic `json:"createdBy,omitempty"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
@@ -225,6 +227,8 @@ func ofWebhookLogSummaryModel(wm *wmodels.WebhookLogWithEventInfo) *LogSummary {
 		EventType:    wm.EventType,
 		Status:       wm.Status,
 		ErrorMessage: wm.ErrorMessage,
+		Attempts:     wm.Attempts,
+		NextRetryAt:  wm.NextRetryAt,
 		CreatedAt:    wm.CreatedAt,
 		UpdatedAt:    wm.UpdatedAt,
 	}
@@ -240,6 +244,8 @@ func ofWebhookLogModel(wm *wmodels.WebhookLog) *Log {
 			URL:          wm.URL,
 			Status:       wm.Status,
 			ErrorMessage: wm.ErrorMessage,
+			Attempts:     wm.Attempts,
+			NextRetryAt:  wm.NextRetryAt,
 			CreatedAt:    wm.CreatedAt,
 			UpdatedAt:    wm.UpdatedAt,
 		},
@@ -29,18 +29,34 @@ var (
 	GitlabClient              = sourceType{name: "GitlabClient"}
 	GitlabResource            = sourceType{name: "GitlabResource"}
 	GithubResource            = sourceType{name: "GithubResource"}
+	GiteaResource             = sourceType{name: "GiteaResource"}
 	ClusterInDB               = sourceType{name: "ClusterInDB"}
 	CollectionInDB            = sourceType{name: "CollectionInDB"}
+	RecentVisitInDB           = sourceType{name: "RecentVisitInDB"}
 	ClusterStateInArgo        = sourceType{name: "ClusterStateInArgo"}
 	TagInDB                   = sourceType{name: "TagInDB"}
 	ApplicationInArgo         = sourceType{name: "ApplicationInArgo"}
 	ApplicationResourceInArgo = sourceType{name: "ApplicationResourceInArgo"}
+	ReleaseInFlux             = sourceType{name: "ReleaseInFlux"}
 	ApplicationInDB           = sourceType{name: "ApplicationInDB"}
 	ApplicationRegionInDB     = sourceType{name: "ApplicationRegionInDB"}
+	ClusterSummaryInDB        = sourceType{name: "ClusterSummaryInDB"}
+	AsyncTaskInDB             = sourceType{name: "AsyncTaskInDB"}
+	ClusterSnapshotInDB       = sourceType{name: "ClusterSnapshotInDB"}
+	ClusterEnvChangeInDB      = sourceType{name: "ClusterEnvChangeInDB"}
+	ChangeRequestInDB         = sourceType{name: "ChangeRequestInDB"}
+	DeployApprovalInDB        = sourceType{name: "DeployApprovalInDB"}
+	ClusterEnvInConfig        = sourceType{name: "ClusterEnvInConfig"}
+	DeployLockInDB            = sourceType{name: "DeployLockInDB"}
+	ScheduledDeployInDB       = sourceType{name: "ScheduledDeployInDB"}
+	CustomRoleInDB            = sourceType{name: "CustomRoleInDB"}
+	AuditLogInDB              = sourceType{name: "AuditLogInDB"}
+	SearchInDB                = sourceType{name: "SearchInDB"}
 	EnvironmentRegionInDB     = sourceType{name: "EnvironmentRegionInDB"}
 	EnvironmentInDB           = sourceType{name: "EnvironmentInDB"}
 	RegionInDB                = sourceType{name: "RegionInDB"}
 	GroupInDB                 = sourceType{name: "GroupInDB"}
+	GroupQuotaInDB            = sourceType{name: "GroupQuotaInDB"}
 	K8SClient                 = sourceType{name: "K8SClient"}
 	RegistryInDB              = sourceType{name: "RegistryInDB"}
 	Pipelinerun               = sourceType{name: "Pipelinerun"}
@@ -55,6 +71,7 @@ var (
 	TemplateInDB              = sourceType{name: "TemplateInDB"}
 	TemplateReleaseInDB       = sourceType{name: "TemplateReleaseInDB"}
 	TemplateReleaseInRepo     = sourceType{name: "TempalteReleaseInRepo"}
+	ChartInTemplateSource     = sourceType{name: "ChartInTemplateSource"}
 	MemberInfoInDB            = sourceType{name: "MemberInfoInDB"}
 	ApplicationManifestInArgo = sourceType{name: "ApplicationManifestInArgo"}
 	PodsInK8S                 = sourceType{name: "PodsInK8S"}
@@ -71,9 +88,17 @@ var (
 	WebhookInDB               = sourceType{name: "WebhookInDB"}
 	WebhookLogInDB            = sourceType{name: "WebhookLogInDB"}
 	MetatagInDB               = sourceType{name: "MetatagInDB"}
+	MetadataInDB              = sourceType{name: "MetadataInDB"}
 	CheckInDB                 = sourceType{name: "CheckInDB"}
 	CheckRunInDB              = sourceType{name: "CheckRunInDB"}
 	PRMessageInDB             = sourceType{name: "PRMessageInDB"}
+	PRSBOMInDB                = sourceType{name: "PRSBOMInDB"}
+	ArtifactInDB              = sourceType{name: "ArtifactInDB"}
+	PipelineStepSettingInDB   = sourceType{name: "PipelineStepSettingInDB"}
+
+	NotificationChannelInDB      = sourceType{name: "NotificationChannelInDB"}
+	NotificationSubscriptionInDB = sourceType{name: "NotificationSubscriptionInDB"}
+	RobotInDB                    = sourceType{name: "RobotInDB"}
 
 	// S3
 	PipelinerunLog = sourceType{name: "PipelinerunLog"}
@@ -85,10 +110,12 @@ var (
 	TektonClient    = sourceType{name: "TektonClient"}
 	TektonCollector = sourceType{name: "TektonCollector"}
 
-	HelmRepo  = sourceType{name: "HelmRepo"}
-	OAuthInDB = sourceType{name: "OauthAppClient"}
-	TokenInDB = sourceType{name: "TokenInDB"}
-	ChartFile = sourceType{name: "ChartFile"}
+	HelmRepo            = sourceType{name: "HelmRepo"}
+	OAuthInDB           = sourceType{name: "OauthAppClient"}
+	OAuthGrantInDB      = sourceType{name: "OAuthGrantInDB"}
+	OAuthTokenAuditInDB = sourceType{name: "OAuthTokenAuditInDB"}
+	TokenInDB           = sourceType{name: "TokenInDB"}
+	ChartFile           = sourceType{name: "ChartFile"}
 
 	// identity provider
 	Oauth2Token           = sourceType{name: "Oauth2Token"}
@@ -222,8 +249,26 @@ var (
 	ErrClusterNoChange         = errors.New("no change to cluster")
 	ErrShouldBuildDeployFirst  = errors.New("clusters with build config should build and deploy first")
 	ErrBuildDeployNotSupported = errors.New("builddeploy is not supported for this cluster")
+	ErrChangeRequestRequired   = errors.New("config changes of the cluster require a change request")
 
 	// pipelinerun
+	ErrDeployConflict = errors.New("deploy conflicts with deploys in progress")
+	ErrDeployLocked   = errors.New("deploy is locked")
+	// ErrDeployApprovalRequired means deploys to the environment must be approved before executed
+	ErrDeployApprovalRequired = errors.New("deploy requires approval")
+	// ErrDeployFrozen means deploys to the environment are refused during a freeze window
+	ErrDeployFrozen = errors.New("deploy is frozen")
+	// ErrArtifactStorageDisabled means the storage of artifacts is not configured
+	ErrArtifactStorageDisabled = errors.New("artifact storage is not configured")
+
+	// manifest policy
+	ErrManifestPolicyViolated = errors.New("manifests violate policies")
+
+	// quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// role
+	ErrRoleInUse = errors.New("role is granted to members")
 
 	// context
 	ErrFailedToGetORM       = errors.New("cannot get the ORM from context")
@@ -243,6 +288,8 @@ var (
 	ErrGitlabMRNotReady            = errors.New("gitlab mr is not ready and cannot be merged")
 	ErrGitlabResourceNotFound      = errors.New("gitlab resource not found")
 	ErrGitLabDefaultBranchNotMatch = errors.New("gitlab default branch do not match")
+	ErrGitlabCommitConflict        = errors.New("gitlab repo has been changed since the expected commit")
+	ErrGitlabUnavailable           = errors.New("gitlab is unavailable, please try again later")
 
 	// git
 	ErrBranchAndCommitEmpty      = errors.New("branch and commit cannot be empty at the same time")
@@ -278,6 +325,10 @@ var (
 	ErrAuthorizationHeaderNotFound = errors.New("AuthorizationHeader not found")
 	ErrOAuthTokenFormatError       = errors.New("Oauth token format error")
 	ErrOAuthNotGroupOwnerType      = errors.New("not group oauth app")
+	// ErrOAuthScopeNotValid the requested scope is not defined in the scope catalog
+	ErrOAuthScopeNotValid = errors.New("scope not valid")
+	// ErrOAuthAuthorizationPending the user has not yet approved the device authorization, ref: rfc8628 section 3.5
+	ErrOAuthAuthorizationPending = errors.New("authorization pending")
 
 	// ErrRegistryUsedByRegions used when deleting a registry that is still used by regions
 	ErrRegistryUsedByRegions = errors.New("cannot delete a registry when used by regions")
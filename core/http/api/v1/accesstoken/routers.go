@@ -29,14 +29,16 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 	coreGroup := engine.Group("/apis/core/v1")
 	var coreRouters = route.Routes{
 		{
-			Method:      http.MethodPost,
-			Pattern:     "/personalaccesstokens",
-			HandlerFunc: api.CreatePersonalAccessToken,
+			Method:        http.MethodPost,
+			Pattern:       "/personalaccesstokens",
+			HandlerFunc:   api.CreatePersonalAccessToken,
+			Transactional: true,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/:%s/:%s/accesstokens", common.ParamResourceType, common.ParamResourceID),
-			HandlerFunc: api.CreateResourceAccessToken,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%s/:%s/accesstokens", common.ParamResourceType, common.ParamResourceID),
+			HandlerFunc:   api.CreateResourceAccessToken,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -49,14 +51,16 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListResourceAccessTokens,
 		},
 		{
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/personalaccesstokens/:%s", common.ParamAccessTokenID),
-			HandlerFunc: api.RevokePersonalAccessToken,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/personalaccesstokens/:%s", common.ParamAccessTokenID),
+			HandlerFunc:   api.RevokePersonalAccessToken,
+			Transactional: true,
 		},
 		{
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/accesstokens/:%s", common.ParamAccessTokenID),
-			HandlerFunc: api.RevokeResourceAccessToken,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/accesstokens/:%s", common.ParamAccessTokenID),
+			HandlerFunc:   api.RevokeResourceAccessToken,
+			Transactional: true,
 		},
 	}
 
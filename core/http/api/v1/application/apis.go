@@ -114,7 +114,11 @@ func (a *API) Create(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
 			return
 		} else if perror.Cause(err) == herrors.ErrParamInvalid {
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
 			return
 		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
@@ -146,7 +150,8 @@ func (a *API) Update(c *gin.Context) {
 				return
 			}
 		} else if perror.Cause(err) == herrors.ErrParamInvalid {
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
 			return
 		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
@@ -34,9 +34,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.List,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/applications/:%v/defaultregions", common.ParamApplicationID),
-			HandlerFunc: api.Update,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/applications/:%v/defaultregions", common.ParamApplicationID),
+			HandlerFunc:   api.Update,
+			Transactional: true,
 		},
 	}
 
@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/auditlog"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type API struct {
+	auditLogCtl auditlog.Controller
+}
+
+func NewAPI(auditLogCtl auditlog.Controller) *API {
+	return &API{
+		auditLogCtl: auditLogCtl,
+	}
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "auditlog: list"
+	keywords := q.KeyWords{}
+	for _, key := range []string{common.AuditLogQueryResourceType, common.AuditLogQueryResourceName} {
+		if v := c.Query(key); v != "" {
+			keywords[key] = v
+		}
+	}
+	if method := c.Query(common.AuditLogQueryMethod); method != "" {
+		switch method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			keywords[common.AuditLogQueryMethod] = method
+		default:
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s",
+				common.AuditLogQueryMethod, method)))
+			return
+		}
+	}
+	for _, key := range []string{common.AuditLogQueryUserID, common.AuditLogQueryRobotID,
+		common.AuditLogQueryStatusCode} {
+		if v := c.Query(key); v != "" {
+			n, err := strconv.ParseUint(v, 10, 0)
+			if err != nil {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s",
+					key, v)))
+				return
+			}
+			keywords[key] = uint(n)
+		}
+	}
+	for _, key := range []string{common.StartTime, common.EndTime} {
+		if v := c.Query(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				response.AbortWithRPCError(c, rpcerror.ParamError.
+					WithErrMsg(fmt.Sprintf("invalid %s, should be in RFC3339: %v", key, err)))
+				return
+			}
+			keywords[key] = t
+		}
+	}
+
+	query := q.New(keywords).WithPagination(c)
+	auditLogs, total, err := a.auditLogCtl.List(c, query)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrNoPrivilege {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: auditLogs,
+		Total: int64(total),
+	})
+}
@@ -235,12 +235,16 @@ func (a *API) Create(c *gin.Context) {
 		}
 		if perror.Cause(err) == herrors.ErrParamInvalid {
 			log.WithFiled(c, "op", op).Errorf("err = %+v, request = %+v", err, request)
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
 			return
 		} else if perror.Cause(err) == herrors.ErrNameConflict {
 			log.WithFiled(c, "op", op).Errorf("err = %+v, request = %+v", err, request)
 			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
 			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded || perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
 		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
@@ -284,7 +288,13 @@ func (a *API) Update(c *gin.Context) {
 
 		if perror.Cause(err) == herrors.ErrParamInvalid {
 			log.WithFiled(c, "op", op).Errorf("err = %+v, request = %+v", err, request)
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		}
+
+		if perror.Cause(err) == herrors.ErrChangeRequestRequired {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
 			return
 		}
 
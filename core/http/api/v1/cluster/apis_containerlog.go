@@ -0,0 +1,173 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_podNameParam       = "podName"
+	_containerNameParam = "containerName"
+)
+
+// flushWriter flushes every write, so that the logs followed are sent once they're produced
+type flushWriter struct {
+	w gin.ResponseWriter
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.w.Flush()
+	return n, err
+}
+
+// containerLogOptions parses the options from the query, the last lines are returned by default except downloading
+func containerLogOptions(c *gin.Context) (_ *cluster.ContainerLogOptions, download bool, err error) {
+	parseBool := func(key string) (bool, error) {
+		value := c.Query(key)
+		if value == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s: %s", key, value)
+		}
+		return b, nil
+	}
+
+	options := &cluster.ContainerLogOptions{}
+	if options.Follow, err = parseBool(common.ClusterQueryFollow); err != nil {
+		return nil, false, err
+	}
+	if options.Previous, err = parseBool(common.ClusterQueryPrevious); err != nil {
+		return nil, false, err
+	}
+	if options.Timestamps, err = parseBool(common.ClusterQueryTimestamps); err != nil {
+		return nil, false, err
+	}
+	if download, err = parseBool(common.ClusterQueryDownload); err != nil {
+		return nil, false, err
+	}
+	if download && options.Follow {
+		return nil, false, fmt.Errorf("%s can not be used with %s", common.ClusterQueryFollow,
+			common.ClusterQueryDownload)
+	}
+
+	if tailLinesStr := c.Query(common.ClusterQueryTailLines); tailLinesStr != "" {
+		tailLines, err := strconv.ParseUint(tailLinesStr, 10, 0)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid %s: %s", common.ClusterQueryTailLines, tailLinesStr)
+		}
+		options.TailLines = new(int64)
+		*options.TailLines = int64(tailLines)
+	} else if !download {
+		options.TailLines = new(int64)
+		*options.TailLines = defaultTailLines
+	}
+	if sinceTimeStr := c.Query(common.ClusterQuerySinceTime); sinceTimeStr != "" {
+		sinceTime, err := time.Parse(time.RFC3339, sinceTimeStr)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid %s: %s, it should be in RFC3339", common.ClusterQuerySinceTime,
+				sinceTimeStr)
+		}
+		options.SinceTime = &sinceTime
+	}
+	return options, download, nil
+}
+
+// StreamContainerLog streams the logs of a container of the cluster's pod, or downloads them as a gzip file
+func (a *API) StreamContainerLog(c *gin.Context) {
+	op := "cluster: stream container log"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	podName, containerName := c.Param(_podNameParam), c.Param(_containerNameParam)
+	options, download, err := containerLogOptions(c)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	// gin.Context is never done, the stream is closed once the client disconnects
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+	disconnected := c.Request.Context().Done()
+	go func() {
+		select {
+		case <-disconnected:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	stream, err := a.clusterCtl.StreamContainerLog(ctx, uint(clusterID), podName, containerName, options)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	defer stream.Close()
+
+	if download {
+		c.Header("Content-Type", "application/gzip")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+			fmt.Sprintf("%s-%s.log.gz", podName, containerName)))
+		c.Status(http.StatusOK)
+		gz := gzip.NewWriter(c.Writer)
+		if _, err = io.Copy(gz, stream); err == nil {
+			err = gz.Close()
+		}
+	} else {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		var w io.Writer = c.Writer
+		if options.Follow {
+			w = flushWriter{w: c.Writer}
+		}
+		_, err = io.Copy(w, stream)
+	}
+	// the response has been started, a failure is only logged
+	if err != nil && ctx.Err() == nil {
+		log.WithFiled(c, "op", op).Warningf("failed to stream logs of %s/%s: %v", podName, containerName, err)
+	}
+}
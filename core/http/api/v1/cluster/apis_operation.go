@@ -198,6 +198,11 @@ func (a *API) InternalDeploy(c *gin.Context) {
 				return
 			}
 		}
+		if perror.Cause(err) == herrors.ErrManifestPolicyViolated {
+			log.WithFiled(c, "op", op).Errorf("%+v", err)
+			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+			return
+		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -83,6 +83,11 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/containerlog", common.ParamClusterID),
 			HandlerFunc: api.GetContainerLog,
+		}, {
+			Method: http.MethodGet,
+			Pattern: fmt.Sprintf("/clusters/:%v/pods/:%v/containers/:%v/logs", common.ParamClusterID,
+				_podNameParam, _containerNameParam),
+			HandlerFunc: api.StreamContainerLog,
 		}, {
 			Method: http.MethodPost,
 			// Deprecated
@@ -30,16 +30,19 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			HandlerFunc: api.List,
 		}, {
-			Method:      http.MethodPost,
-			HandlerFunc: api.Create,
+			Method:        http.MethodPost,
+			HandlerFunc:   api.Create,
+			Transactional: true,
 		}, {
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/:%v/setdefault", _environmentRegionIDParam),
-			HandlerFunc: api.SetDefault,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%v/setdefault", _environmentRegionIDParam),
+			HandlerFunc:   api.SetDefault,
+			Transactional: true,
 		}, {
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/:%v", _environmentRegionIDParam),
-			HandlerFunc: api.DeleteByID,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/:%v", _environmentRegionIDParam),
+			HandlerFunc:   api.DeleteByID,
+			Transactional: true,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
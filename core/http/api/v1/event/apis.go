@@ -15,12 +15,21 @@
 package event
 
 import (
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/event"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
 
 	"github.com/gin-gonic/gin"
 )
 
+const _cursorQuery = "cursor"
+
 type API struct {
 	eventCtl event.Controller
 }
@@ -36,3 +45,44 @@ func NewAPI(controller event.Controller) *API {
 func (a *API) ListSupportEvents(c *gin.Context) {
 	response.SuccessWithData(c, a.eventCtl.ListSupportEvents(c))
 }
+
+// ListResourceEvents lists the events of a resource page by page, the newest first
+func (a *API) ListResourceEvents(c *gin.Context) {
+	const op = "event: list resource events"
+	resourceType := c.Param(common.ParamResourceType)
+	resourceIDStr := c.Param(common.ParamResourceID)
+	resourceID, err := strconv.ParseUint(resourceIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid resource id: %s", resourceIDStr))
+		return
+	}
+	var cursor uint64
+	if cursorStr := c.Query(_cursorQuery); cursorStr != "" {
+		if cursor, err = strconv.ParseUint(cursorStr, 10, 0); err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid cursor: %s", cursorStr))
+			return
+		}
+	}
+	var pageSize int
+	if pageSizeStr := c.Query(common.PageSize); pageSizeStr != "" {
+		if pageSize, err = strconv.Atoi(pageSizeStr); err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid pageSize: %s", pageSizeStr))
+			return
+		}
+	}
+
+	resp, err := a.eventCtl.ListResourceEvents(c, resourceType, uint(resourceID), uint(cursor), pageSize)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
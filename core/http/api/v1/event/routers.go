@@ -15,8 +15,10 @@
 package event
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/server/route"
 
 	"github.com/gin-gonic/gin"
@@ -31,6 +33,11 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			HandlerFunc: a.ListSupportEvents,
 		},
+		{
+			Pattern:     fmt.Sprintf("/:%s/:%s/domainevents", common.ParamResourceType, common.ParamResourceID),
+			Method:      http.MethodGet,
+			HandlerFunc: a.ListResourceEvents,
+		},
 	}
 
 	route.RegisterRoutes(coreAPI, coreRoutes)
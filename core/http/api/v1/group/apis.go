@@ -25,6 +25,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/request"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/server/validation"
 
 	"github.com/gin-gonic/gin"
 )
@@ -49,9 +50,7 @@ func NewAPI(controller group.Controller) *API {
 // CreateGroup create a group
 func (a *API) CreateGroup(c *gin.Context) {
 	var newGroup *group.NewGroup
-	err := c.ShouldBindJSON(&newGroup)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &newGroup) {
 		return
 	}
 
@@ -79,9 +78,7 @@ func (a *API) CreateSubGroup(c *gin.Context) {
 	}
 
 	var newGroup *group.NewGroup
-	err = c.ShouldBindJSON(&newGroup)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &newGroup) {
 		return
 	}
 
@@ -192,9 +189,7 @@ func (a *API) UpdateGroup(c *gin.Context) {
 	}
 
 	var updatedGroup *group.UpdateGroup
-	err = c.ShouldBindJSON(&updatedGroup)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &updatedGroup) {
 		return
 	}
 
@@ -333,9 +328,7 @@ func (a *API) UpdateRegionSelector(c *gin.Context) {
 	}
 
 	var regionSelectors group.RegionSelectors
-	err = c.ShouldBindJSON(&regionSelectors)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &regionSelectors) {
 		return
 	}
 
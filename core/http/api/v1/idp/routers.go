@@ -40,8 +40,9 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListAuthEndpoints,
 		},
 		{
-			Method:      http.MethodPost,
-			HandlerFunc: api.CreateIDP,
+			Method:        http.MethodPost,
+			HandlerFunc:   api.CreateIDP,
+			Transactional: true,
 		},
 		{
 			Pattern:     fmt.Sprintf("/:%s", _idp),
@@ -19,15 +19,15 @@ import (
 	"html/template"
 	"net/http"
 	"net/url"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/oauth"
-	"github.com/horizoncd/horizon/core/controller/oauthapp"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
 	perror "github.com/horizoncd/horizon/pkg/errors"
-	"github.com/horizoncd/horizon/pkg/oauth/scope"
+	"github.com/horizoncd/horizon/pkg/oauth/oidc"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
@@ -39,31 +39,49 @@ const (
 	KeyRedirectURI = "redirect_uri"
 	KeyAuthorize   = "authorize"
 
+	// PKCE params, ref: rfc7636
+	KeyCodeChallenge       = "code_challenge"
+	KeyCodeChallengeMethod = "code_challenge_method"
+	KeyCodeVerifier        = "code_verifier"
+
+	// openid connect params, ref: OpenID Connect Core 1.0 section 3.1.2.1
+	KeyNonce = "nonce"
+
 	KeyCode         = "code"
 	KeyRefreshToken = "refresh_token"
 	KeyClientSecret = "client_secret"
 
-	KeyGrantType          = "grant_type"
-	GrantTypeAuthCode     = "authorization_code"
-	GrantTypeRefreshToken = "refresh_token"
+	KeyGrantType               = "grant_type"
+	GrantTypeAuthCode          = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// device authorization params, ref: rfc8628
+	KeyDeviceCode = "device_code"
+	KeyUserCode   = "user_code"
+
+	// revocation params, ref: rfc7009
+	KeyToken = "token"
 
 	Authorized = "1"
 )
 
 type API struct {
-	oauthAppController oauthapp.Controller
-	oAuthServer        oauth.Controller
-	oauthHTMLLocation  string
-	scopeService       scope.Service
+	oAuthServer       oauth.Controller
+	oauthHTMLLocation string
+	device            oauthconfig.Device
+	// limiters are applied to the authorize and token endpoints, which can be brute-forced
+	limiters []gin.HandlerFunc
 }
 
-func NewAPI(oauthServerController oauth.Controller,
-	oauthAppController oauthapp.Controller, oauthHTMLLocation string, scopeService scope.Service) *API {
+func NewAPI(oauthServerController oauth.Controller, oauthHTMLLocation string, device oauthconfig.Device,
+	limiters ...gin.HandlerFunc) *API {
 	return &API{
-		oAuthServer:        oauthServerController,
-		oauthAppController: oauthAppController,
-		oauthHTMLLocation:  oauthHTMLLocation,
-		scopeService:       scopeService,
+		oAuthServer:       oauthServerController,
+		oauthHTMLLocation: oauthHTMLLocation,
+		device:            device,
+		limiters:          limiters,
 	}
 }
 
@@ -80,32 +98,77 @@ type AuthorizationPageParams struct {
 	Scope       string
 	ClientName  string
 	ScopeBasic  []ScopeBasic
+
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
 }
 
-func (a *API) HandleAuthorizationGetReq(c *gin.Context) {
-	var err error
-	checkReq := func() bool {
-		keys := []string{
-			KeyClientID,
-			KeyState,
-			KeyRedirectURI,
-		}
-		for _, key := range keys {
-			if _, ok := c.GetQuery(key); !ok {
-				err = fmt.Errorf("%s not exist", key)
-				log.Warning(c, err.Error())
-				return false
-			}
+// ConsentRequest is the decision of the user on a pending authorization
+type ConsentRequest struct {
+	ClientID            string `json:"clientID"`
+	Scope               string `json:"scope"`
+	RedirectURI         string `json:"redirectURI"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"codeChallenge"`
+	CodeChallengeMethod string `json:"codeChallengeMethod"`
+	Nonce               string `json:"nonce"`
+	Approved            bool   `json:"approved"`
+}
+
+// DeviceCodeResponse is the device authorization response, ref: rfc8628 section 3.2
+type DeviceCodeResponse struct {
+	*oauth.DeviceCodeResponse
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	// Interval is the seconds the client waits between polling the token endpoint
+	Interval int64 `json:"interval"`
+}
+
+// DeviceConsentRequest is the decision of the user on the device code of the user code
+type DeviceConsentRequest struct {
+	UserCode string `json:"userCode"`
+	Approved bool   `json:"approved"`
+}
+
+// checkAuthorizationQuery checks the query of a pending authorization,
+// and aborts the request if any key is missing
+func checkAuthorizationQuery(c *gin.Context) bool {
+	for _, key := range []string{KeyClientID, KeyState, KeyRedirectURI} {
+		if _, ok := c.GetQuery(key); !ok {
+			err := fmt.Errorf("%s not exist", key)
+			log.Warning(c, err.Error())
+			response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
+			return false
 		}
-		return true
 	}
-	if !checkReq() {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
-		return
+	return true
+}
+
+func authorizeReqFromQuery(c *gin.Context, userID uint) oauth.AuthorizeReq {
+	return oauth.AuthorizeReq{
+		ClientID:     c.Query(KeyClientID),
+		Scope:        c.Query(KeyScope),
+		RedirectURL:  c.Query(KeyRedirectURI),
+		State:        c.Query(KeyState),
+		UserIdentity: userID,
+		Request:      c.Request,
+
+		CodeChallenge:       c.Query(KeyCodeChallenge),
+		CodeChallengeMethod: c.Query(KeyCodeChallengeMethod),
+		Nonce:               c.Query(KeyNonce),
 	}
+}
 
-	appBasicInfo, err := a.oauthAppController.Get(c, c.Query(KeyClientID))
-	if err != nil {
+func abortWithAuthorizationError(c *gin.Context, err error) {
+	switch perror.Cause(err) {
+	case herrors.ErrOAuthReqNotValid:
+		log.Warning(c, err.Error())
+		response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+	case herrors.ErrOAuthScopeNotValid:
+		log.Warning(c, err.Error())
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+	default:
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.OAuthInDB {
 				response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
@@ -114,35 +177,60 @@ func (a *API) HandleAuthorizationGetReq(c *gin.Context) {
 		}
 		log.Error(c, err.Error())
 		response.AbortWithInternalError(c, err.Error())
-		return
-	}
-	scopeRules := a.scopeService.GetRulesByScope(strings.Split(c.Query(KeyScope), " "))
-	scopeInfo := func() []ScopeBasic {
-		scopeBasics := make([]ScopeBasic, 0)
-		for _, scope := range scopeRules {
-			scopeBasics = append(scopeBasics, ScopeBasic{
-				Name: scope.Name,
-				Desc: scope.Desc,
-			})
-		}
-		return scopeBasics
 	}
+}
 
+// HandleAuthorizationGetReq renders the consent page of a pending authorization,
+// the user is redirected back with the code at once if the scopes were approved before
+func (a *API) HandleAuthorizationGetReq(c *gin.Context) {
+	if !checkAuthorizationQuery(c) {
+		return
+	}
 	currentUser, err := common.UserFromContext(c)
 	if err != nil {
 		response.AbortWithForbiddenError(c, common.Forbidden, err.Error())
 		return
 	}
 
+	authorizeReq := authorizeReqFromQuery(c, currentUser.GetID())
+	authorization, err := a.oAuthServer.GetAuthorization(c, &authorizeReq)
+	if err != nil {
+		abortWithAuthorizationError(c, err)
+		return
+	}
+	if authorization.Granted {
+		resp, err := a.oAuthServer.Consent(c, &oauth.ConsentReq{
+			AuthorizeReq: authorizeReq,
+			Approved:     true,
+		})
+		if err != nil {
+			abortWithAuthorizationError(c, err)
+			return
+		}
+		c.Redirect(http.StatusFound, resp.RedirectURL)
+		return
+	}
+
+	scopeBasics := make([]ScopeBasic, 0)
+	for _, scope := range authorization.Scopes {
+		scopeBasics = append(scopeBasics, ScopeBasic{
+			Name: scope.Name,
+			Desc: scope.Desc,
+		})
+	}
 	params := AuthorizationPageParams{
 		UserName:    currentUser.GetName(),
-		ClientName:  appBasicInfo.AppName,
+		ClientName:  authorization.ClientName,
 		ClientID:    c.Query(KeyClientID),
-		HomeURL:     appBasicInfo.HomeURL,
+		HomeURL:     authorization.HomeURL,
 		State:       c.Query(KeyState),
 		Scope:       c.Query(KeyScope),
 		RedirectURL: c.Query(KeyRedirectURI),
-		ScopeBasic:  scopeInfo(),
+		ScopeBasic:  scopeBasics,
+
+		CodeChallenge:       c.Query(KeyCodeChallenge),
+		CodeChallengeMethod: c.Query(KeyCodeChallengeMethod),
+		Nonce:               c.Query(KeyNonce),
 	}
 	authTemplate, err := template.ParseFiles(a.oauthHTMLLocation)
 	if err != nil {
@@ -190,47 +278,85 @@ func (a *API) handlerPostAuthorizationReq(c *gin.Context) {
 		return
 	}
 	value, ok := c.GetPostForm(KeyAuthorize)
-	if !ok || value != Authorized {
-		const DenyKey = "error"
-		const DenyDesc = "the user has denied your application access"
-		q := url.Values{}
-		q.Set(DenyKey, DenyDesc)
-		q.Set(KeyState, c.PostForm(KeyState))
-		location := url.URL{Path: c.PostForm(KeyRedirectURI), RawQuery: q.Encode()}
-		c.Redirect(http.StatusFound, location.RequestURI())
-	} else {
-		resp, err := a.oAuthServer.GenAuthorizeCode(c, &oauth.AuthorizeReq{
+	resp, err := a.oAuthServer.Consent(c, &oauth.ConsentReq{
+		AuthorizeReq: oauth.AuthorizeReq{
 			ClientID:     c.PostForm(KeyClientID),
 			Scope:        c.PostForm(KeyScope),
 			RedirectURL:  c.PostForm(KeyRedirectURI),
 			State:        c.PostForm(KeyState),
 			UserIdentity: user.GetID(),
 			Request:      c.Request,
-		})
-		if err != nil {
-			causeErr := perror.Cause(err)
-			switch causeErr {
-			case herrors.ErrOAuthReqNotValid:
-				log.Warning(c, err.Error())
-				response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
-			default:
-				if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
-					if e.Source == herrors.OAuthInDB {
-						response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
-						return
-					}
-				}
-				log.Error(c, err.Error())
-				response.AbortWithInternalError(c, err.Error())
-			}
-		} else {
-			q := url.Values{}
-			q.Set(KeyCode, resp.Code)
-			q.Set(KeyState, resp.State)
-			location := url.URL{Path: resp.RedirectURL, RawQuery: q.Encode()}
-			c.Redirect(http.StatusFound, location.RequestURI())
-		}
+
+			CodeChallenge:       c.PostForm(KeyCodeChallenge),
+			CodeChallengeMethod: c.PostForm(KeyCodeChallengeMethod),
+			Nonce:               c.PostForm(KeyNonce),
+		},
+		Approved: ok && value == Authorized,
+	})
+	if err != nil {
+		abortWithAuthorizationError(c, err)
+		return
 	}
+	c.Redirect(http.StatusFound, resp.RedirectURL)
+}
+
+// GetConsent returns the app and the scopes of a pending authorization for consent pages rendered by the frontend
+func (a *API) GetConsent(c *gin.Context) {
+	if !checkAuthorizationQuery(c) {
+		return
+	}
+	user, err := common.UserFromContext(c)
+	if err != nil {
+		response.AbortWithForbiddenError(c, common.Forbidden, err.Error())
+		return
+	}
+	authorizeReq := authorizeReqFromQuery(c, user.GetID())
+	authorization, err := a.oAuthServer.GetAuthorization(c, &authorizeReq)
+	if err != nil {
+		abortWithAuthorizationError(c, err)
+		return
+	}
+	response.SuccessWithData(c, authorization)
+}
+
+// Consent records the decision of the user on a pending authorization,
+// the frontend redirects the user to the location returned
+func (a *API) Consent(c *gin.Context) {
+	var req ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
+		return
+	}
+	if req.ClientID == "" || req.RedirectURI == "" || req.State == "" {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			"clientID, redirectURI and state are required")
+		return
+	}
+	user, err := common.UserFromContext(c)
+	if err != nil {
+		response.AbortWithForbiddenError(c, common.Forbidden, err.Error())
+		return
+	}
+	resp, err := a.oAuthServer.Consent(c, &oauth.ConsentReq{
+		AuthorizeReq: oauth.AuthorizeReq{
+			ClientID:     req.ClientID,
+			Scope:        req.Scope,
+			RedirectURL:  req.RedirectURI,
+			State:        req.State,
+			UserIdentity: user.GetID(),
+			Request:      c.Request,
+
+			CodeChallenge:       req.CodeChallenge,
+			CodeChallengeMethod: req.CodeChallengeMethod,
+			Nonce:               req.Nonce,
+		},
+		Approved: req.Approved,
+	})
+	if err != nil {
+		abortWithAuthorizationError(c, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
 }
 
 func (a *API) HandleAccessTokenReq(c *gin.Context) {
@@ -243,13 +369,17 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 
 	keys := []string{
 		KeyClientID,
-		KeyClientSecret,
-		KeyRedirectURI,
 	}
 	if grantType == GrantTypeAuthCode {
-		keys = append(keys, KeyCode)
+		// the client secret is required by the type of the client, public clients use PKCE instead
+		keys = append(keys, KeyRedirectURI, KeyCode)
 	} else if grantType == GrantTypeRefreshToken {
-		keys = append(keys, KeyRefreshToken)
+		// tokens issued by device codes are not bound to any redirect url
+		keys = append(keys, KeyClientSecret, KeyRefreshToken)
+	} else if grantType == GrantTypeClientCredentials {
+		keys = append(keys, KeyClientSecret)
+	} else if grantType == GrantTypeDeviceCode && a.device.Enabled() {
+		keys = append(keys, KeyDeviceCode)
 	} else {
 		response.AbortWithRequestError(c, common.InvalidRequestParam, "grant_type not supported")
 		return
@@ -283,6 +413,21 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 		tokenResponse, err = a.oAuthServer.GenAccessToken(c, &oauth.AccessTokenReq{
 			BaseTokenReq: baseTokenReq,
 			Code:         c.PostForm(KeyCode),
+			CodeVerifier: c.PostForm(KeyCodeVerifier),
+		})
+	} else if grantType == GrantTypeClientCredentials {
+		tokenResponse, err = a.oAuthServer.GenClientCredentialsToken(c, &oauth.ClientCredentialsTokenReq{
+			ClientID:     c.PostForm(KeyClientID),
+			ClientSecret: c.PostForm(KeyClientSecret),
+			Scope:        c.PostForm(KeyScope),
+			Request:      c.Request,
+		})
+	} else if grantType == GrantTypeDeviceCode {
+		tokenResponse, err = a.oAuthServer.GenDeviceAccessToken(c, &oauth.DeviceAccessTokenReq{
+			ClientID:     c.PostForm(KeyClientID),
+			ClientSecret: c.PostForm(KeyClientSecret),
+			DeviceCode:   c.PostForm(KeyDeviceCode),
+			Request:      c.Request,
 		})
 	} else {
 		tokenResponse, err = a.oAuthServer.RefreshToken(c, &oauth.RefreshTokenReq{
@@ -300,6 +445,12 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 		case herrors.ErrOAuthCodeExpired, herrors.ErrOAuthRefreshTokenExpired:
 			response.AbortWithUnauthorized(c, common.CodeExpired, err.Error())
 			return
+		case herrors.ErrOAuthScopeNotValid:
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		case herrors.ErrOAuthAuthorizationPending:
+			response.AbortWithRequestError(c, common.AuthorizationPending, err.Error())
+			return
 		default:
 			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 				if e.Source == herrors.OAuthInDB || e.Source == herrors.TokenInDB {
@@ -314,3 +465,205 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, tokenResponse)
 }
+
+// HandleRevokeReq revokes a single token, such as a leaked one, and leaves other tokens of the app untouched.
+// Tokens which do not exist are treated as revoked already, ref: rfc7009
+func (a *API) HandleRevokeReq(c *gin.Context) {
+	for _, key := range []string{KeyClientID, KeyToken} {
+		if _, ok := c.GetPostForm(key); !ok {
+			err := fmt.Errorf("%s not exist", key)
+			log.Warning(c, err.Error())
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
+	}
+
+	err := a.oAuthServer.RevokeToken(c, &oauth.RevokeTokenReq{
+		ClientID: c.PostForm(KeyClientID),
+		Token:    c.PostForm(KeyToken),
+		Request:  c.Request,
+	})
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrOAuthReqNotValid {
+			log.Warning(c, err.Error())
+			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+			return
+		}
+		log.Error(c, err.Error())
+		response.AbortWithInternalError(c, err.Error())
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// UserInfo returns the claims about the user of the bearer access token, ref: OpenID Connect Core 1.0 section 5.3
+func (a *API) UserInfo(c *gin.Context) {
+	token, err := common.GetToken(c)
+	if err != nil {
+		c.Header("WWW-Authenticate", "Bearer")
+		response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+		return
+	}
+	userInfo, err := a.oAuthServer.UserInfo(c, token)
+	if err != nil {
+		log.Warning(c, err.Error())
+		switch perror.Cause(err) {
+		case herrors.ErrNotSupport:
+			response.AbortWithNotExistError(c, err.Error())
+		case herrors.ErrOAuthReqNotValid:
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+		case herrors.ErrOAuthAccessTokenExpired:
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			response.AbortWithUnauthorized(c, common.CodeExpired, err.Error())
+		case herrors.ErrForbidden:
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+			response.AbortWithForbiddenError(c, common.Forbidden, err.Error())
+		default:
+			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				if e.Source == herrors.TokenInDB || e.Source == herrors.UserInDB {
+					c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+					response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+					return
+				}
+			}
+			log.Error(c, err.Error())
+			response.AbortWithInternalError(c, err.Error())
+		}
+		return
+	}
+	c.JSON(http.StatusOK, userInfo)
+}
+
+// OpenIDConfiguration serves the discovery document, ref: OpenID Connect Discovery 1.0 section 4
+func (a *API) OpenIDConfiguration(c *gin.Context) {
+	configuration, err := a.oAuthServer.OpenIDConfiguration(c, oidc.Endpoints{
+		Authorization: BasicPath + AuthorizePath,
+		Token:         BasicPath + AccessTokenPath,
+		UserInfo:      BasicPath + UserInfoPath,
+		JWKS:          BasicPath + JWKSPath,
+		Revocation:    BasicPath + RevokePath,
+	})
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrNotSupport {
+			response.AbortWithNotExistError(c, err.Error())
+			return
+		}
+		response.AbortWithInternalError(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, configuration)
+}
+
+// JWKS serves the keys to verify id tokens, ref: rfc7517
+func (a *API) JWKS(c *gin.Context) {
+	keySet, err := a.oAuthServer.JWKS(c)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrNotSupport {
+			response.AbortWithNotExistError(c, err.Error())
+			return
+		}
+		response.AbortWithInternalError(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, keySet)
+}
+
+// HandleDeviceCodeReq issues a device code and a user code to clients without browsers,
+// the client displays the user code and the verification uri, then polls the token endpoint by the device code
+func (a *API) HandleDeviceCodeReq(c *gin.Context) {
+	if !a.device.Enabled() {
+		response.AbortWithNotExistError(c, "device authorization grant is not enabled")
+		return
+	}
+	if _, ok := c.GetPostForm(KeyClientID); !ok {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, "client_id not exist")
+		return
+	}
+	resp, err := a.oAuthServer.GenDeviceCode(c, &oauth.DeviceCodeReq{
+		ClientID: c.PostForm(KeyClientID),
+		Scope:    c.PostForm(KeyScope),
+		Request:  c.Request,
+	})
+	if err != nil {
+		abortWithAuthorizationError(c, err)
+		return
+	}
+
+	verificationURIComplete, err := url.Parse(a.device.VerificationURI)
+	if err != nil {
+		response.AbortWithInternalError(c, err.Error())
+		return
+	}
+	q := verificationURIComplete.Query()
+	q.Set(KeyUserCode, resp.UserCode)
+	verificationURIComplete.RawQuery = q.Encode()
+	c.JSON(http.StatusOK, &DeviceCodeResponse{
+		DeviceCodeResponse:      resp,
+		VerificationURI:         a.device.VerificationURI,
+		VerificationURIComplete: verificationURIComplete.String(),
+		Interval:                int64(a.device.Interval / time.Second),
+	})
+}
+
+func abortWithDeviceConsentError(c *gin.Context, err error) {
+	if perror.Cause(err) == herrors.ErrOAuthCodeExpired {
+		log.Warning(c, err.Error())
+		response.AbortWithRequestError(c, common.CodeExpired, err.Error())
+		return
+	}
+	if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.TokenInDB {
+		response.AbortWithNotExistError(c, err.Error())
+		return
+	}
+	if perror.Cause(err) == herrors.ErrOAuthReqNotValid {
+		log.Warning(c, err.Error())
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	abortWithAuthorizationError(c, err)
+}
+
+// GetDeviceConsent returns the app and the scopes of the device code of the user code
+// for the verification page rendered by the frontend
+func (a *API) GetDeviceConsent(c *gin.Context) {
+	userCode, ok := c.GetQuery(KeyUserCode)
+	if !ok {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, "user_code not exist")
+		return
+	}
+	authorization, err := a.oAuthServer.GetDeviceAuthorization(c, userCode)
+	if err != nil {
+		abortWithDeviceConsentError(c, err)
+		return
+	}
+	response.SuccessWithData(c, authorization)
+}
+
+// DeviceConsent records the decision of the user on the device code of the user code
+func (a *API) DeviceConsent(c *gin.Context) {
+	var req DeviceConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
+		return
+	}
+	if req.UserCode == "" {
+		response.AbortWithRequestError(c, common.InvalidRequestBody, "userCode is required")
+		return
+	}
+	user, err := common.UserFromContext(c)
+	if err != nil {
+		response.AbortWithForbiddenError(c, common.Forbidden, err.Error())
+		return
+	}
+	err = a.oAuthServer.DeviceConsent(c, &oauth.DeviceConsentReq{
+		UserCode:     req.UserCode,
+		UserIdentity: user.GetID(),
+		Approved:     req.Approved,
+	})
+	if err != nil {
+		abortWithDeviceConsentError(c, err)
+		return
+	}
+	response.Success(c)
+}
@@ -24,13 +24,23 @@ import (
 const (
 	BasicPath       = "/login/oauth"
 	AuthorizePath   = "/authorize"
+	ConsentPath     = "/authorize/consent"
 	AccessTokenPath = "/access_token"
+	RevokePath      = "/revoke"
+	DeviceCodePath  = "/device/code"
+	DevicePath      = "/device"
+	UserInfoPath    = "/userinfo"
+	JWKSPath        = "/jwks"
+
+	// OpenIDConfigurationPath is the discovery document of openid connect, which lies at the root of the issuer
+	OpenIDConfigurationPath = "/.well-known/openid-configuration"
 )
 
 func (a *API) RegisterRoute(engine *gin.Engine) {
 	apiGroup := engine.Group(BasicPath)
+	limitedGroup := apiGroup.Group("", a.limiters...)
 
-	var routes = route.Routes{
+	var limitedRoutes = route.Routes{
 		{
 			Pattern:     AuthorizePath,
 			Method:      http.MethodGet,
@@ -43,7 +53,50 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     AccessTokenPath,
 			Method:      http.MethodPost,
 			HandlerFunc: a.HandleAccessTokenReq,
+		}, {
+			Pattern:     DeviceCodePath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.HandleDeviceCodeReq,
+		},
+	}
+	route.RegisterRoutes(limitedGroup, limitedRoutes)
+
+	var routes = route.Routes{
+		{
+			Pattern:     ConsentPath,
+			Method:      http.MethodGet,
+			HandlerFunc: a.GetConsent,
+		}, {
+			Pattern:     ConsentPath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.Consent,
+		}, {
+			Pattern:     RevokePath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.HandleRevokeReq,
+		}, {
+			Pattern:     DevicePath,
+			Method:      http.MethodGet,
+			HandlerFunc: a.GetDeviceConsent,
+		}, {
+			Pattern:     DevicePath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.DeviceConsent,
+		}, {
+			Pattern:     UserInfoPath,
+			Method:      http.MethodGet,
+			HandlerFunc: a.UserInfo,
+		}, {
+			Pattern:     UserInfoPath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.UserInfo,
+		}, {
+			Pattern:     JWKSPath,
+			Method:      http.MethodGet,
+			HandlerFunc: a.JWKS,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
+
+	engine.GET(OpenIDConfigurationPath, a.OpenIDConfiguration)
 }
@@ -15,6 +15,7 @@
 package pipelinerun
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 )
 
@@ -37,6 +39,14 @@ const (
 	_clusterIDParam     = "clusterID"
 	_clusterParam       = "cluster"
 	_canRollbackParam   = "canRollback"
+	_followQuery        = "follow"
+	_cursorQuery        = "cursor"
+
+	_lastEventIDHeader = "Last-Event-ID"
+
+	_logEvent   = "log"
+	_errorEvent = "error"
+	_endEvent   = "end"
 )
 
 type API struct {
@@ -82,6 +92,76 @@ func (a *API) LatestLogForCluster(c *gin.Context) {
 	a.writeLog(c, l)
 }
 
+// Logs streams the logs of pipelinerun as server-sent events, each line of the logs is a log event.
+// The id of the last event in a batch is the cursor to resume the stream from,
+// which is sent back by Last-Event-ID header once reconnected, or by cursor query.
+func (a *API) Logs(c *gin.Context) {
+	prIDStr := c.Param(_pipelinerunIDParam)
+	prID, err := strconv.ParseUint(prIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	follow := false
+	if followStr := c.Query(_followQuery); followStr != "" {
+		follow, err = strconv.ParseBool(followStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
+	}
+	token := c.GetHeader(_lastEventIDHeader)
+	if token == "" {
+		token = c.Query(_cursorQuery)
+	}
+	cursor, err := prctl.ParseLogCursor(token)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	// gin.Context is never done, the stream is stopped once the client disconnects
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+	disconnected := c.Request.Context().Done()
+	go func() {
+		select {
+		case <-disconnected:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err = a.prCtl.StreamPipelinerunLog(ctx, uint(prID), follow, cursor,
+		func(lines []*prctl.LogLine, cursor prctl.LogCursor) error {
+			for i, line := range lines {
+				event := sse.Event{Event: _logEvent, Data: line}
+				if i == len(lines)-1 {
+					event.Id = cursor.String()
+				}
+				c.Render(-1, event)
+			}
+			c.Writer.Flush()
+			return ctx.Err()
+		})
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		if !c.Writer.Written() {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+			response.AbortWithError(c, err)
+			return
+		}
+		c.Render(-1, sse.Event{Event: _errorEvent, Data: errors.Message(err)})
+		return
+	}
+	c.Render(-1, sse.Event{Event: _endEvent, Data: ""})
+}
+
 func (a *API) writeLog(c *gin.Context, l *collector.Log) {
 	c.Header("Content-Type", "text/plain")
 	if l.LogBytes != nil {
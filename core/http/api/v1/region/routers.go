@@ -31,8 +31,9 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			HandlerFunc: api.ListRegions,
 		}, {
-			Method:      http.MethodPost,
-			HandlerFunc: api.Create,
+			Method:        http.MethodPost,
+			HandlerFunc:   api.Create,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/:%v", _regionIDParam),
@@ -42,13 +43,15 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/:%v/tags", _regionIDParam),
 			HandlerFunc: api.ListRegionTags,
 		}, {
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/:%v", _regionIDParam),
-			HandlerFunc: api.UpdateByID,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/:%v", _regionIDParam),
+			HandlerFunc:   api.UpdateByID,
+			Transactional: true,
 		}, {
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/:%v", _regionIDParam),
-			HandlerFunc: api.DeleteByID,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/:%v", _regionIDParam),
+			HandlerFunc:   api.DeleteByID,
+			Transactional: true,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
@@ -31,20 +31,23 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			HandlerFunc: api.ListAll,
 		}, {
-			Method:      http.MethodPost,
-			HandlerFunc: api.Create,
+			Method:        http.MethodPost,
+			HandlerFunc:   api.Create,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/:%v", _registryIDParam),
 			HandlerFunc: api.GetByID,
 		}, {
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/:%v", _registryIDParam),
-			HandlerFunc: api.UpdateByID,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/:%v", _registryIDParam),
+			HandlerFunc:   api.UpdateByID,
+			Transactional: true,
 		}, {
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/:%v", _registryIDParam),
-			HandlerFunc: api.DeleteByID,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/:%v", _registryIDParam),
+			HandlerFunc:   api.DeleteByID,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     "/kinds",
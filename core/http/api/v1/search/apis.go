@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"strings"
+
+	"github.com/horizoncd/horizon/core/controller/search"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/request"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	_queryKeyword      = "q"
+	_queryResourceType = "resourceType"
+)
+
+type API struct {
+	searchCtl search.Controller
+}
+
+func NewAPI(searchCtl search.Controller) *API {
+	return &API{
+		searchCtl: searchCtl,
+	}
+}
+
+// Search searches groups, applications and clusters, resourceType is a comma separated list of the types to search
+func (a *API) Search(c *gin.Context) {
+	const op = "search: search"
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	params := &search.SearchParams{
+		Keyword:    c.Query(_queryKeyword),
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+	}
+	if resourceType := c.Query(_queryResourceType); resourceType != "" {
+		params.ResourceTypes = strings.Split(resourceType, ",")
+	}
+
+	results, total, err := a.searchCtl.Search(c, params)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: results,
+		Total: int64(total),
+	})
+}
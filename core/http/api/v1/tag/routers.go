@@ -31,9 +31,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/:%v/:%v/tags", _resourceTypeParam, _resourceIDParam),
 			HandlerFunc: api.List,
 		}, {
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/:%v/:%v/tags", _resourceTypeParam, _resourceIDParam),
-			HandlerFunc: api.Update,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%v/:%v/tags", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc:   api.Update,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/:%v/:%v/subresourcetags", _resourceTypeParam, _resourceIDParam),
@@ -30,9 +30,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/clusters/:%v/templateschematags", _clusterIDParam),
 			HandlerFunc: api.List,
 		}, {
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/clusters/:%v/templateschematags", _clusterIDParam),
-			HandlerFunc: api.Update,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/clusters/:%v/templateschematags", _clusterIDParam),
+			HandlerFunc:   api.Update,
+			Transactional: true,
 		},
 	}
 	route.RegisterRoutes(group, routes)
@@ -42,9 +42,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.GetSelf,
 		},
 		{
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/:%s", _userIDParam),
-			HandlerFunc: api.Update,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/:%s", _userIDParam),
+			HandlerFunc:   api.Update,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -62,9 +63,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 	linkGroup := engine.Group("/apis/core/v1/links")
 	var linkRoutes = route.Routes{
 		{
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/:%v", _linkIDParam),
-			HandlerFunc: api.DeleteLink,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/:%v", _linkIDParam),
+			HandlerFunc:   api.DeleteLink,
+			Transactional: true,
 		},
 	}
 	route.RegisterRoutes(linkGroup, linkRoutes)
@@ -204,6 +204,9 @@ func (a *API) ListWebhookLogs(c *gin.Context) {
 	if filter := c.Query(common.Filter); filter != "" {
 		keywords[common.Filter] = c.Query(common.Filter)
 	}
+	if status := c.Query(common.Status); status != "" {
+		keywords[common.Status] = status
+	}
 
 	query := q.New(keywords).WithPagination(c)
 	items, total, err := a.webhookCtl.ListWebhookLogs(c, uint(webhookID), query)
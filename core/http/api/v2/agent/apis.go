@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	agentctl "github.com/horizoncd/horizon/core/controller/agent"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/agent"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _paramRegion = "region"
+
+type API struct {
+	agentCtl agentctl.Controller
+}
+
+func NewAPI(agentCtl agentctl.Controller) *API {
+	return &API{agentCtl: agentCtl}
+}
+
+// Poll is held until requests are queued or poll timeout, the request context is
+// used so that polls are canceled once agents disconnect
+func (a *API) Poll(c *gin.Context) {
+	const op = "agent: poll"
+	requests, err := a.agentCtl.Poll(c.Request.Context(), c.Param(_paramRegion), c.GetHeader(agent.TokenHeader))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, requests)
+}
+
+func (a *API) Respond(c *gin.Context) {
+	const op = "agent: respond"
+	var resp agent.Response
+	if err := c.ShouldBindJSON(&resp); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid request body, err: %s", err.Error())))
+		return
+	}
+	if err := a.agentCtl.Respond(c.Request.Context(), c.Param(_paramRegion),
+		c.GetHeader(agent.TokenHeader), &resp); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	switch perror.Cause(err) {
+	case herrors.ErrTokenInvalid:
+		response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+	case agent.ErrRequestNotFound:
+		// the request is timeout or closed, the agent stops responding it
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+	default:
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
@@ -29,7 +29,9 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/request"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/server/validation"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	tagutil "github.com/horizoncd/horizon/pkg/util/tag"
 )
 
 const (
@@ -61,9 +63,7 @@ func (a *API) Create(c *gin.Context) {
 		return
 	}
 	var request *application.CreateOrUpdateApplicationRequestV2
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
-			err.Error())))
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 
@@ -87,9 +87,59 @@ func (a *API) Create(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
 			return
 		} else if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Import(c *gin.Context) {
+	const op = "application: import"
+	groupIDStr := c.Param(common.ParamGroupID)
+	groupID, err := strconv.ParseUint(groupIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid groupID: %s, err: %s",
+			groupIDStr, err.Error())))
+		return
+	}
+	var request *application.ImportApplicationsRequest
+	if !validation.BindJSON(c, &request) {
+		return
+	}
+	if request == nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("request body is empty"))
+		return
+	}
+	for _, app := range request.Applications {
+		if app == nil {
+			continue
+		}
+		for _, roleOfMember := range app.ExtraMembers {
+			if !role.CheckRoleIfValid(roleOfMember) {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("extra member is invalid"))
+				return
+			}
+		}
+	}
+
+	resp, err := a.applicationCtl.ImportApplications(c, uint(groupID), request)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
 			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
 			return
 		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -100,9 +150,7 @@ func (a *API) Create(c *gin.Context) {
 func (a *API) Update(c *gin.Context) {
 	const op = "application: update v2"
 	var request *application.CreateOrUpdateApplicationRequestV2
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
-			err.Error())))
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 	appIDStr := c.Param(common.ParamApplicationID)
@@ -119,7 +167,11 @@ func (a *API) Update(c *gin.Context) {
 				return
 			}
 		} else if perror.Cause(err) == herrors.ErrParamInvalid {
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		} else if perror.Cause(err) == herrors.ErrGitlabCommitConflict {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
 			return
 		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
@@ -189,6 +241,140 @@ func (a *API) Transfer(c *gin.Context) {
 	response.Success(c)
 }
 
+func (a *API) Clone(c *gin.Context) {
+	const op = "application: clone"
+	appIDStr := c.Param(common.ParamApplicationID)
+	appID, err := strconv.ParseUint(appIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid appID: %s, err: %s",
+			appIDStr, err.Error())))
+		return
+	}
+	var request *application.CloneApplicationRequest
+	if !validation.BindJSON(c, &request) {
+		return
+	}
+
+	resp, err := a.applicationCtl.CloneApplication(c, uint(appID), request)
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.GroupInDB || e.Source == herrors.ApplicationInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		} else if perror.Cause(err) == herrors.ErrNameConflict {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) DryRun(c *gin.Context) {
+	const op = "application: dry run"
+	appIDStr := c.Param(common.ParamApplicationID)
+	appID, err := strconv.ParseUint(appIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid appID: %s, err: %s",
+			appIDStr, err.Error())))
+		return
+	}
+	var request *application.CreateOrUpdateApplicationRequestV2
+	if !validation.BindJSON(c, &request) {
+		return
+	}
+
+	resp, err := a.applicationCtl.DryRunApplicationV2(c, uint(appID), c.Query(_envQuery), request)
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.ApplicationInDB || e.Source == herrors.TemplateReleaseInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		} else if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListConfigCommits(c *gin.Context) {
+	const op = "application: list config commits"
+	appIDStr := c.Param(common.ParamApplicationID)
+	appID, err := strconv.ParseUint(appIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid appID: %s, err: %s",
+			appIDStr, err.Error())))
+		return
+	}
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	commits, err := a.applicationCtl.ListConfigCommits(c, uint(appID), c.Query(_envQuery), pageNumber, pageSize)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, commits)
+}
+
+func (a *API) RollbackConfig(c *gin.Context) {
+	const op = "application: rollback config"
+	appIDStr := c.Param(common.ParamApplicationID)
+	appID, err := strconv.ParseUint(appIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid appID: %s, err: %s",
+			appIDStr, err.Error())))
+		return
+	}
+	var request *application.RollbackConfigRequest
+	if !validation.BindJSON(c, &request) {
+		return
+	}
+
+	if err := a.applicationCtl.RollbackConfig(c, uint(appID), request); err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.ApplicationInDB || e.Source == herrors.TemplateReleaseInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		} else if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		} else if perror.Cause(err) == herrors.ErrGitlabCommitConflict {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.Success(c)
+}
+
 func (a *API) Delete(c *gin.Context) {
 	const op = "application: delete"
 	appIDStr := c.Param(common.ParamApplicationID)
@@ -291,6 +477,19 @@ func (a *API) List(c *gin.Context) {
 		keywords[common.ApplicationQueryByGroupRecursive] = groupRecursive
 	}
 
+	tagSelectorStr := c.Query(common.ApplicationQueryTagSelector)
+	if tagSelectorStr != "" {
+		tagSelectors, err := tagutil.ParseTagSelector(tagSelectorStr)
+		if err != nil {
+			response.AbortWithRPCError(c,
+				rpcerror.ParamError.WithErrMsgf(
+					"failed to parse tagSelector\n"+
+						"selector = %s\nerr = %v", tagSelectorStr, err))
+			return
+		}
+		keywords[common.ApplicationQueryTagSelector] = tagSelectors
+	}
+
 	query := q.New(keywords).WithPagination(c)
 
 	applications, total, err := a.applicationCtl.List(c, query)
@@ -370,3 +569,86 @@ func (a *API) GetApplicationPipelineStats(c *gin.Context) {
 		Items: pipelineStats,
 	})
 }
+
+func (a *API) GetDeployWindow(c *gin.Context) {
+	const op = "application: get deploy window"
+	appIDStr := c.Param(common.ParamApplicationID)
+	appID, err := strconv.ParseUint(appIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid appID: %s, err: %s",
+			appIDStr, err.Error())))
+		return
+	}
+
+	window, err := a.applicationCtl.GetDeployWindow(c, uint(appID))
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ApplicationInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, window)
+}
+
+func (a *API) ListDeleted(c *gin.Context) {
+	const op = "application: list deleted"
+	groupIDStr := c.Param(common.ParamGroupID)
+	groupID, err := strconv.ParseUint(groupIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid groupID: %s, err: %s",
+			groupIDStr, err.Error())))
+		return
+	}
+
+	applications, err := a.applicationCtl.ListDeleted(c, uint(groupID))
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.GroupInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, applications)
+}
+
+func (a *API) Restore(c *gin.Context) {
+	const op = "application: restore"
+	groupIDStr := c.Param(common.ParamGroupID)
+	groupID, err := strconv.ParseUint(groupIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid groupID: %s, err: %s",
+			groupIDStr, err.Error())))
+		return
+	}
+	appIDStr := c.Param(common.ParamApplicationID)
+	appID, err := strconv.ParseUint(appIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid appID: %s, err: %s",
+			appIDStr, err.Error())))
+		return
+	}
+
+	if err := a.applicationCtl.Restore(c, uint(groupID), uint(appID)); err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.GroupInDB || e.Source == herrors.ApplicationInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		} else if perror.Cause(err) == herrors.ErrNameConflict {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.Success(c)
+}
@@ -33,6 +33,22 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/groups/:%v/applications", common.ParamGroupID),
 			HandlerFunc: api.Create,
 		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/groups/:%v/applications/import", common.ParamGroupID),
+			HandlerFunc: api.Import,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/groups/:%v/deletedapplications", common.ParamGroupID),
+			HandlerFunc: api.ListDeleted,
+		},
+		{
+			Method: http.MethodPost,
+			Pattern: fmt.Sprintf("/groups/:%v/deletedapplications/:%v/restore",
+				common.ParamGroupID, common.ParamApplicationID),
+			HandlerFunc: api.Restore,
+		},
 		{
 			Method:      http.MethodGet,
 			Pattern:     "/applications",
@@ -63,11 +79,36 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/applications/:%v/transfer", common.ParamApplicationID),
 			HandlerFunc: api.Transfer,
 		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/clone", common.ParamApplicationID),
+			HandlerFunc: api.Clone,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/dryrun", common.ParamApplicationID),
+			HandlerFunc: api.DryRun,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/configcommits", common.ParamApplicationID),
+			HandlerFunc: api.ListConfigCommits,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/configrollback", common.ParamApplicationID),
+			HandlerFunc: api.RollbackConfig,
+		},
 		{
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/applications/:%v/pipelinestats", common.ParamApplicationID),
 			HandlerFunc: api.GetApplicationPipelineStats,
 		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/deploywindow", common.ParamApplicationID),
+			HandlerFunc: api.GetDeployWindow,
+		},
 	}
 	route.RegisterRoutes(apiV2Group, apiV2Routes)
 
@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asynctask
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/controller/asynctask"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _taskIDParam = "taskID"
+
+type API struct {
+	taskCtl asynctask.Controller
+}
+
+func NewAPI(taskCtl asynctask.Controller) *API {
+	return &API{
+		taskCtl: taskCtl,
+	}
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "async task: get"
+	taskIDStr := c.Param(_taskIDParam)
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid task id: %s", taskIDStr)))
+		return
+	}
+
+	resp, err := a.taskCtl.Get(c, uint(taskID))
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrNoPrivilege {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
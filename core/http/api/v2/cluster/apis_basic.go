@@ -29,6 +29,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/server/validation"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	tagutil "github.com/horizoncd/horizon/pkg/util/tag"
 )
@@ -212,12 +213,21 @@ func (a *API) Create(c *gin.Context) {
 		}
 	}
 
+	async := false
+	asyncStr := c.Request.URL.Query().Get(common.ClusterQueryAsync)
+	if asyncStr != "" {
+		async, err = strconv.ParseBool(asyncStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam,
+				fmt.Sprintf("async is invalid, err: %v", err))
+			return
+		}
+	}
+
 	extraOwners := c.QueryArray(common.ClusterQueryExtraOwner)
 
 	var request *cluster.CreateClusterRequestV2
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody,
-			fmt.Sprintf("request body is invalid, err: %v", err))
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 
@@ -235,13 +245,25 @@ func (a *API) Create(c *gin.Context) {
 		request.ExtraMembers[extraOwner] = role.Owner
 	}
 
-	resp, err := a.clusterCtl.CreateClusterV2(c, &cluster.CreateClusterParamsV2{
+	params := &cluster.CreateClusterParamsV2{
 		CreateClusterRequestV2: request,
 		ApplicationID:          uint(applicationID),
 		Environment:            environment,
 		Region:                 region,
 		MergePatch:             mergePatch,
-	})
+	}
+	if async {
+		resp, err := a.clusterCtl.CreateClusterV2Async(c, params)
+		if err != nil {
+			log.WithFiled(c, "op", op).Errorf("%+v", err)
+			response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+			return
+		}
+		response.SuccessWithData(c, resp)
+		return
+	}
+
+	resp, err := a.clusterCtl.CreateClusterV2(c, params)
 	if err != nil {
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ApplicationInDB {
 			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
@@ -250,12 +272,16 @@ func (a *API) Create(c *gin.Context) {
 		}
 		if perror.Cause(err) == herrors.ErrParamInvalid {
 			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
 			return
 		} else if perror.Cause(err) == herrors.ErrNameConflict {
 			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
 			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
 			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded || perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
 		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
@@ -285,9 +311,7 @@ func (a *API) Update(c *gin.Context) {
 	}
 
 	var request *cluster.UpdateClusterRequestV2
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody,
-			fmt.Sprintf("request body is invalid, err: %v", err))
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 	err = a.clusterCtl.UpdateClusterV2(c, uint(clusterID), request, mergePatch)
@@ -299,7 +323,18 @@ func (a *API) Update(c *gin.Context) {
 
 		if perror.Cause(err) == herrors.ErrParamInvalid {
 			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		}
+
+		if perror.Cause(err) == herrors.ErrGitlabCommitConflict {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		}
+
+		if perror.Cause(err) == herrors.ErrChangeRequestRequired {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
 			return
 		}
 
@@ -310,6 +345,52 @@ func (a *API) Update(c *gin.Context) {
 	response.Success(c)
 }
 
+func (a *API) DryRun(c *gin.Context) {
+	op := "cluster: dry run v2"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	mergePatch := false
+	mergepatchStr := c.Request.URL.Query().Get(common.ClusterQueryMergePatch)
+	if mergepatchStr != "" {
+		mergePatch, err = strconv.ParseBool(mergepatchStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam,
+				fmt.Sprintf("mergepatch is invalid, err: %v", err))
+			return
+		}
+	}
+
+	var request *cluster.UpdateClusterRequestV2
+	if !validation.BindJSON(c, &request) {
+		return
+	}
+	resp, err := a.clusterCtl.DryRunClusterV2(c, uint(clusterID), request, mergePatch)
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok &&
+			(e.Source == herrors.ClusterInDB || e.Source == herrors.TemplateReleaseInDB) {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
+			response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()),
+				response.ErrorDetails(err))
+			return
+		}
+
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
 // Get V2 get api can also be used to get original cluster
 func (a *API) Get(c *gin.Context) {
 	op := "cluster: get v2"
@@ -362,6 +443,101 @@ func (a *API) Delete(c *gin.Context) {
 	response.Success(c)
 }
 
+func (a *API) ListDeleted(c *gin.Context) {
+	op := "cluster: list deleted"
+	applicationIDStr := c.Param(common.ParamApplicationID)
+	applicationID, err := strconv.ParseUint(applicationIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	clusters, err := a.clusterCtl.ListDeleted(c, uint(applicationID))
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ApplicationInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, clusters)
+}
+
+func (a *API) Restore(c *gin.Context) {
+	op := "cluster: restore"
+	applicationIDStr := c.Param(common.ParamApplicationID)
+	applicationID, err := strconv.ParseUint(applicationIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	if err := a.clusterCtl.Restore(c, uint(applicationID), uint(clusterID)); err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.ApplicationInDB || e.Source == herrors.ClusterInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		} else if perror.Cause(err) == herrors.ErrNameConflict {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) Clone(c *gin.Context) {
+	op := "cluster: clone"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request *cluster.CloneClusterRequest
+	if !validation.BindJSON(c, &request) {
+		return
+	}
+
+	resp, err := a.clusterCtl.CloneCluster(c, uint(clusterID), request)
+	if err != nil {
+		switch perror.Cause(err) {
+		case herrors.ErrParamInvalid:
+			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrNameConflict:
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrDisabled, herrors.ErrQuotaExceeded, herrors.ErrForbidden:
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
 func (a *API) Free(c *gin.Context) {
 	op := "cluster: free"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -465,6 +641,34 @@ func (a *API) GetClusterPod(c *gin.Context) {
 	response.SuccessWithData(c, resp)
 }
 
+func (a *API) GetPodProvenance(c *gin.Context) {
+	op := "cluster: get pod provenance"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	podName := c.Query(common.ClusterQueryPodName)
+	if podName == "" {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("podName should not be empty"))
+		return
+	}
+
+	resp, err := a.clusterCtl.GetPodProvenance(c, uint(clusterID), podName)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
 func (a *API) GetByName(c *gin.Context) {
 	op := "cluster: get by name"
 	clusterName := c.Param(common.ParamClusterName)
@@ -560,3 +764,47 @@ func (a *API) CreatePipelineRun(c *gin.Context) {
 	}
 	response.SuccessWithData(c, pipelineRun)
 }
+
+func (a *API) CreateSandbox(c *gin.Context) {
+	op := "cluster: create sandbox"
+	var request *cluster.CreateSandboxRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	resp, err := a.clusterCtl.CreateSandbox(c, request)
+	if err != nil {
+		switch perror.Cause(err) {
+		case herrors.ErrParamInvalid:
+			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrNameConflict:
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrDisabled, herrors.ErrForbidden:
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListSandboxes(c *gin.Context) {
+	op := "cluster: list sandboxes"
+	sandboxes, err := a.clusterCtl.ListSandboxes(c)
+	if err != nil {
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, sandboxes)
+}
@@ -35,6 +35,23 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/applications/:%v/clusters", common.ParamApplicationID),
 			HandlerFunc: api.ListByApplication,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/deletedclusters", common.ParamApplicationID),
+			HandlerFunc: api.ListDeleted,
+		}, {
+			Method: http.MethodPost,
+			Pattern: fmt.Sprintf("/applications/:%v/deletedclusters/:%v/restore",
+				common.ParamApplicationID, common.ParamClusterID),
+			HandlerFunc: api.Restore,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/groups/:%v/releases", common.ParamGroupID),
+			HandlerFunc: api.Release,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/batchdeploy", common.ParamApplicationID),
+			HandlerFunc: api.BatchDeploy,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     "/clusters",
@@ -67,6 +84,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/resourcetree", common.ParamClusterID),
 			HandlerFunc: api.GetResourceTree,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/drift", common.ParamClusterID),
+			HandlerFunc: api.GetDrift,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/status", common.ParamClusterID),
@@ -91,10 +112,19 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/action", common.ParamClusterID),
 			HandlerFunc: api.ExecuteAction,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/rollout/:%v", common.ParamClusterID, _rolloutActionParam),
+			HandlerFunc: api.ExecuteRolloutAction,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/containerlog", common.ParamClusterID),
 			HandlerFunc: api.GetContainerLog,
+		}, {
+			Method: http.MethodGet,
+			Pattern: fmt.Sprintf("/clusters/:%v/pods/:%v/containers/:%v/logs", common.ParamClusterID,
+				_podNameParam, _containerNameParam),
+			HandlerFunc: api.StreamContainerLog,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/exec", common.ParamClusterID),
@@ -107,6 +137,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/pod", common.ParamClusterID),
 			HandlerFunc: api.GetClusterPod,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/provenance", common.ParamClusterID),
+			HandlerFunc: api.GetPodProvenance,
 		}, {
 			Method:      http.MethodDelete,
 			Pattern:     fmt.Sprintf("/clusters/:%v/pods", common.ParamClusterID),
@@ -115,10 +149,31 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/free", common.ParamClusterID),
 			HandlerFunc: api.Free,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/clone", common.ParamClusterID),
+			HandlerFunc: api.Clone,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/dryrun", common.ParamClusterID),
+			HandlerFunc: api.DryRun,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/events", common.ParamClusterID),
 			HandlerFunc: api.PodEvents,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/kubeproxy/*%v", common.ParamClusterID, _kubeProxyPath),
+			HandlerFunc: api.KubeProxy,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/resources", common.ParamClusterID),
+			HandlerFunc: api.ListResources,
+		}, {
+			Method: http.MethodGet,
+			Pattern: fmt.Sprintf("/clusters/:%v/resources/:%v/:%v", common.ParamClusterID,
+				_resourceKindParam, _resourceNameParam),
+			HandlerFunc: api.GetResource,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/outputs", common.ParamClusterID),
@@ -128,17 +183,111 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/clusters/:%v/containers", common.ParamClusterID),
 			HandlerFunc: api.GetContainers,
 		}, {
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/clusters/:%v/favorite", common.ParamClusterID),
-			HandlerFunc: api.AddFavorite,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/clusters/:%v/favorite", common.ParamClusterID),
+			HandlerFunc:   api.AddFavorite,
+			Transactional: true,
 		}, {
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/clusters/:%v/favorite", common.ParamClusterID),
-			HandlerFunc: api.DeleteFavorite,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/clusters/:%v/favorite", common.ParamClusterID),
+			HandlerFunc:   api.DeleteFavorite,
+			Transactional: true,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/pipelineruns", common.ParamClusterID),
 			HandlerFunc: api.CreatePipelineRun,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/templateupgrade", common.ParamClusterID),
+			HandlerFunc: api.GetTemplateUpgradePlan,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/templateupgrade", common.ParamClusterID),
+			HandlerFunc: api.UpgradeTemplate,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/snapshots", common.ParamClusterID),
+			HandlerFunc: api.ListSnapshots,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/snapshots/:%v/restore", common.ParamClusterID, _snapshotIDParam),
+			HandlerFunc: api.RestoreSnapshot,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/configcommits", common.ParamClusterID),
+			HandlerFunc: api.ListConfigCommits,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/configrollback", common.ParamClusterID),
+			HandlerFunc: api.RollbackConfig,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/envs", common.ParamClusterID),
+			HandlerFunc: api.ListEnvs,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/envs", common.ParamClusterID),
+			HandlerFunc: api.AddEnv,
+		}, {
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/clusters/:%v/envs/:%v", common.ParamClusterID, _envNameParam),
+			HandlerFunc: api.UpdateEnv,
+		}, {
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/clusters/:%v/envs/:%v", common.ParamClusterID, _envNameParam),
+			HandlerFunc: api.DeleteEnv,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/envs/:%v/history", common.ParamClusterID, _envNameParam),
+			HandlerFunc: api.ListEnvHistory,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/autoscaling", common.ParamClusterID),
+			HandlerFunc: api.GetAutoscaling,
+		}, {
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/clusters/:%v/autoscaling", common.ParamClusterID),
+			HandlerFunc: api.UpdateAutoscaling,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/changerequests", common.ParamClusterID),
+			HandlerFunc: api.CreateChangeRequest,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/changerequests", common.ParamClusterID),
+			HandlerFunc: api.ListChangeRequests,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/changerequests/:%v", common.ParamClusterID, _changeRequestIDParam),
+			HandlerFunc: api.GetChangeRequest,
+		}, {
+			Method: http.MethodPost,
+			Pattern: fmt.Sprintf("/clusters/:%v/changerequests/:%v/review",
+				common.ParamClusterID, _changeRequestIDParam),
+			HandlerFunc: api.ReviewChangeRequest,
+		}, {
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/clusters/:%v/scheduleddeploys", common.ParamClusterID),
+			HandlerFunc:   api.CreateScheduledDeploy,
+			Transactional: true,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/scheduleddeploys", common.ParamClusterID),
+			HandlerFunc: api.ListScheduledDeploys,
+		}, {
+			Method: http.MethodDelete,
+			Pattern: fmt.Sprintf("/clusters/:%v/scheduleddeploys/:%v",
+				common.ParamClusterID, _scheduledDeployIDParam),
+			HandlerFunc:   api.CancelScheduledDeploy,
+			Transactional: true,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     "/sandboxes",
+			HandlerFunc: api.CreateSandbox,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     "/sandboxes",
+			HandlerFunc: api.ListSandboxes,
 		},
 	}
 
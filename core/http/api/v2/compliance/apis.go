@@ -0,0 +1,64 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/controller/compliance"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	_startTimeQuery = "startTime"
+	_endTimeQuery   = "endTime"
+)
+
+type API struct {
+	complianceCtl compliance.Controller
+}
+
+func NewAPI(complianceCtl compliance.Controller) *API {
+	return &API{
+		complianceCtl: complianceCtl,
+	}
+}
+
+func (a *API) Export(c *gin.Context) {
+	const op = "compliance: export"
+	start, err := time.Parse(time.RFC3339, c.Query(_startTimeQuery))
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid %s, should be in RFC3339: %v", _startTimeQuery, err)))
+		return
+	}
+	end, err := time.Parse(time.RFC3339, c.Query(_endTimeQuery))
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid %s, should be in RFC3339: %v", _endTimeQuery, err)))
+		return
+	}
+
+	resp, err := a.complianceCtl.Export(c, start, end)
+	if err != nil {
+		response.AbortWithHorizonError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
@@ -0,0 +1,140 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploylock
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/deploylock"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type API struct {
+	deployLockCtl deploylock.Controller
+}
+
+func NewAPI(deployLockCtl deploylock.Controller) *API {
+	return &API{
+		deployLockCtl: deployLockCtl,
+	}
+}
+
+func (a *API) GetClusterLock(c *gin.Context) {
+	a.get(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) GetApplicationLock(c *gin.Context) {
+	a.get(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) LockCluster(c *gin.Context) {
+	a.lock(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) LockApplication(c *gin.Context) {
+	a.lock(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) UnlockCluster(c *gin.Context) {
+	a.unlock(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) UnlockApplication(c *gin.Context) {
+	a.unlock(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) get(c *gin.Context, resourceType, keyResourceID string) {
+	const op = "deploy lock: get"
+	resourceID, ok := parseResourceID(c, keyResourceID)
+	if !ok {
+		return
+	}
+
+	resp, err := a.deployLockCtl.Get(c, resourceType, resourceID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) lock(c *gin.Context, resourceType, keyResourceID string) {
+	const op = "deploy lock: lock"
+	resourceID, ok := parseResourceID(c, keyResourceID)
+	if !ok {
+		return
+	}
+
+	var request *deploylock.LockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid request body, err: %s", err.Error())))
+		return
+	}
+	resp, err := a.deployLockCtl.Lock(c, resourceType, resourceID, request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) unlock(c *gin.Context, resourceType, keyResourceID string) {
+	const op = "deploy lock: unlock"
+	resourceID, ok := parseResourceID(c, keyResourceID)
+	if !ok {
+		return
+	}
+
+	if err := a.deployLockCtl.Unlock(c, resourceType, resourceID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseResourceID(c *gin.Context, keyResourceID string) (uint, bool) {
+	resourceIDStr := c.Param(keyResourceID)
+	resourceID, err := strconv.ParseUint(resourceIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid resource id: %s", resourceIDStr)))
+		return 0, false
+	}
+	return uint(resourceID), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		if e.Source == herrors.ClusterInDB || e.Source == herrors.ApplicationInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
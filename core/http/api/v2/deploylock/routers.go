@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploylock
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (api *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/deploylock", common.ParamClusterID),
+			HandlerFunc: api.GetClusterLock,
+		}, {
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/clusters/:%v/deploylock", common.ParamClusterID),
+			HandlerFunc:   api.LockCluster,
+			Transactional: true,
+		}, {
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/clusters/:%v/deploylock", common.ParamClusterID),
+			HandlerFunc:   api.UnlockCluster,
+			Transactional: true,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/deploylock", common.ParamApplicationID),
+			HandlerFunc: api.GetApplicationLock,
+		}, {
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/applications/:%v/deploylock", common.ParamApplicationID),
+			HandlerFunc:   api.LockApplication,
+			Transactional: true,
+		}, {
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/applications/:%v/deploylock", common.ParamApplicationID),
+			HandlerFunc:   api.UnlockApplication,
+			Transactional: true,
+		},
+	}
+	route.RegisterRoutes(group, routes)
+}
@@ -30,20 +30,23 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			HandlerFunc: api.ListEnvironments,
 		}, {
-			Method:      http.MethodPost,
-			HandlerFunc: api.Create,
+			Method:        http.MethodPost,
+			HandlerFunc:   api.Create,
+			Transactional: true,
 		}, {
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/:%v", _environmentParam),
-			HandlerFunc: api.Update,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/:%v", _environmentParam),
+			HandlerFunc:   api.Update,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/:%v", _environmentParam),
 			HandlerFunc: api.GetByID,
 		}, {
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/:%v", _environmentParam),
-			HandlerFunc: api.Delete,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/:%v", _environmentParam),
+			HandlerFunc:   api.Delete,
+			Transactional: true,
 		},
 	}
 
@@ -0,0 +1,125 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package favorite
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/favorite"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_resourceTypeQuery = "resourceType"
+	_limitQuery        = "limit"
+)
+
+type API struct {
+	favoriteCtl favorite.Controller
+}
+
+func NewAPI(favoriteCtl favorite.Controller) *API {
+	return &API{
+		favoriteCtl: favoriteCtl,
+	}
+}
+
+func (a *API) AddApplicationFavorite(c *gin.Context) {
+	const op = "favorite: add application favorite"
+	applicationID, ok := parseID(c, common.ParamApplicationID)
+	if !ok {
+		return
+	}
+	if err := a.favoriteCtl.AddFavorite(c, common.ResourceApplication, applicationID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) DeleteApplicationFavorite(c *gin.Context) {
+	const op = "favorite: delete application favorite"
+	applicationID, ok := parseID(c, common.ParamApplicationID)
+	if !ok {
+		return
+	}
+	if err := a.favoriteCtl.DeleteFavorite(c, common.ResourceApplication, applicationID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) ListFavorites(c *gin.Context) {
+	const op = "favorite: list favorites"
+	resources, err := a.favoriteCtl.ListFavorites(c, c.Query(_resourceTypeQuery))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resources)
+}
+
+func (a *API) ListRecentVisits(c *gin.Context) {
+	const op = "favorite: list recent visits"
+	limit := 0
+	if limitStr := c.Query(_limitQuery); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsg(fmt.Sprintf("invalid limit: %s", limitStr)))
+			return
+		}
+	}
+	resources, err := a.favoriteCtl.ListRecentVisits(c, c.Query(_resourceTypeQuery), limit)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resources)
+}
+
+func parseID(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid resource id: %s", idStr)))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
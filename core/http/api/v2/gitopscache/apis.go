@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitopscache
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	gitopscachectl "github.com/horizoncd/horizon/core/controller/gitopscache"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// _tokenHeader is the header of the secret token sent by gitlab webhooks
+const _tokenHeader = "X-Gitlab-Token"
+
+type API struct {
+	gitopsCacheCtl gitopscachectl.Controller
+}
+
+func NewAPI(gitopsCacheCtl gitopscachectl.Controller) *API {
+	return &API{gitopsCacheCtl: gitopsCacheCtl}
+}
+
+// Webhook receives the events of gitops repos from gitlab, and invalidates the files cached of them
+func (a *API) Webhook(c *gin.Context) {
+	const op = "gitops cache: webhook"
+	var event gitopscachectl.Event
+	if err := c.ShouldBindJSON(&event); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid request body, err: %s", err.Error())))
+		return
+	}
+	if err := a.gitopsCacheCtl.Invalidate(c, c.GetHeader(_tokenHeader), &event); err != nil {
+		if perror.Cause(err) == herrors.ErrTokenInvalid {
+			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.Success(c)
+}
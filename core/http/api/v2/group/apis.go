@@ -25,14 +25,18 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/request"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/server/validation"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	tagutil "github.com/horizoncd/horizon/pkg/util/tag"
 
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	_paramGroupID  = "groupID"
-	_paramFullPath = "fullPath"
-	_paramType     = "type"
+	_paramGroupID     = "groupID"
+	_paramFullPath    = "fullPath"
+	_paramType        = "type"
+	_paramTagSelector = "tagSelector"
 )
 
 type API struct {
@@ -49,9 +53,7 @@ func NewAPI(controller group.Controller) *API {
 // CreateGroup create a group
 func (a *API) CreateGroup(c *gin.Context) {
 	var newGroup *group.NewGroup
-	err := c.ShouldBindJSON(&newGroup)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &newGroup) {
 		return
 	}
 
@@ -79,9 +81,7 @@ func (a *API) CreateSubGroup(c *gin.Context) {
 	}
 
 	var newGroup *group.NewGroup
-	err = c.ShouldBindJSON(&newGroup)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &newGroup) {
 		return
 	}
 
@@ -140,6 +140,16 @@ func (a *API) ListAuthedGroup(c *gin.Context) {
 	response.SuccessWithData(c, groups)
 }
 
+// GetTree get the tree of groups visible to current user
+func (a *API) GetTree(c *gin.Context) {
+	tree, err := a.groupCtl.GetTree(c)
+	if err != nil {
+		response.AbortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, tree)
+}
+
 // GetGroupByFullPath get a group child by fullPath
 func (a *API) GetGroupByFullPath(c *gin.Context) {
 	path := c.Query(_paramFullPath)
@@ -167,7 +177,7 @@ func (a *API) TransferGroup(c *gin.Context) {
 		response.AbortWithRequestError(c, common.InvalidRequestParam, fmt.Sprintf("%v", err))
 		return
 	}
-	pIDInt, err := strconv.Atoi(parentID)
+	pIDInt, err := strconv.ParseUint(parentID, 10, 0)
 	if err != nil {
 		response.AbortWithRequestError(c, common.InvalidRequestParam, fmt.Sprintf("%v", err))
 		return
@@ -175,7 +185,18 @@ func (a *API) TransferGroup(c *gin.Context) {
 
 	err = a.groupCtl.Transfer(c, uint(intID), uint(pIDInt))
 	if err != nil {
-		response.AbortWithError(c, err)
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		switch perror.Cause(err) {
+		case herrors.ErrParamInvalid:
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		case herrors.ErrNameConflict, herrors.ErrPathConflict:
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		default:
+			response.AbortWithError(c, err)
+		}
 		return
 	}
 
@@ -192,9 +213,7 @@ func (a *API) UpdateGroup(c *gin.Context) {
 	}
 
 	var updatedGroup *group.UpdateGroup
-	err = c.ShouldBindJSON(&updatedGroup)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &updatedGroup) {
 		return
 	}
 
@@ -308,11 +327,24 @@ func (a *API) SearchGroups(c *gin.Context) {
 
 	filter := c.Query(common.Filter)
 
+	var tagSelectors []tagmodels.TagSelector
+	if tagSelectorStr := c.Query(_paramTagSelector); tagSelectorStr != "" {
+		tagSelectors, err = tagutil.ParseTagSelector(tagSelectorStr)
+		if err != nil {
+			response.AbortWithRPCError(c,
+				rpcerror.ParamError.WithErrMsgf(
+					"failed to parse tagSelector\n"+
+						"selector = %s\nerr = %v", tagSelectorStr, err))
+			return
+		}
+	}
+
 	searchGroups, count, err := a.groupCtl.SearchGroups(c, &group.SearchParams{
-		GroupID:    uint(intID),
-		PageSize:   pageSize,
-		PageNumber: pageNumber,
-		Filter:     filter,
+		GroupID:      uint(intID),
+		PageSize:     pageSize,
+		PageNumber:   pageNumber,
+		Filter:       filter,
+		TagSelectors: tagSelectors,
 	})
 	if err != nil {
 		response.AbortWithError(c, err)
@@ -333,9 +365,7 @@ func (a *API) UpdateRegionSelector(c *gin.Context) {
 	}
 
 	var regionSelectors group.RegionSelectors
-	err = c.ShouldBindJSON(&regionSelectors)
-	if err != nil {
-		response.AbortWithRequestError(c, common.InvalidRequestBody, fmt.Sprintf("%v", err))
+	if !validation.BindJSON(c, &regionSelectors) {
 		return
 	}
 
@@ -28,18 +28,21 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 	coreAPI := engine.Group("/apis/core/v2/groups")
 	var coreRoutes = route.Routes{
 		{
-			Method:      http.MethodPost,
-			HandlerFunc: a.CreateGroup,
+			Method:        http.MethodPost,
+			HandlerFunc:   a.CreateGroup,
+			Transactional: true,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/:%s/groups", _paramGroupID),
-			HandlerFunc: a.CreateSubGroup,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%s/groups", _paramGroupID),
+			HandlerFunc:   a.CreateSubGroup,
+			Transactional: true,
 		},
 		{
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/:%s", _paramGroupID),
-			HandlerFunc: a.DeleteGroup,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/:%s", _paramGroupID),
+			HandlerFunc:   a.DeleteGroup,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -47,9 +50,10 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: a.GetGroup,
 		},
 		{
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/:%s", _paramGroupID),
-			HandlerFunc: a.UpdateGroup,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/:%s", _paramGroupID),
+			HandlerFunc:   a.UpdateGroup,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -57,14 +61,16 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: a.GetSubGroups,
 		},
 		{
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/:%s/transfer", _paramGroupID),
-			HandlerFunc: a.TransferGroup,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/:%s/transfer", _paramGroupID),
+			HandlerFunc:   a.TransferGroup,
+			Transactional: true,
 		},
 		{
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/:%s/regionselectors", _paramGroupID),
-			HandlerFunc: a.UpdateRegionSelector,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/:%s/regionselectors", _paramGroupID),
+			HandlerFunc:   a.UpdateRegionSelector,
+			Transactional: true,
 		},
 	}
 
@@ -79,6 +85,11 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     "/authedgroups",
 			HandlerFunc: a.ListAuthedGroup,
 		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/tree",
+			HandlerFunc: a.GetTree,
+		},
 		{
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/:%s/children", _paramGroupID),
@@ -27,6 +27,8 @@ import (
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
 
 	"github.com/gin-gonic/gin"
 )
@@ -40,6 +42,7 @@ const (
 	_querySelf                 = "self"
 	_queryEmail                = "email"
 	_queryDirectMemberOnly     = "directMemberOnly"
+	_queryUserID               = "userID"
 )
 
 type API struct {
@@ -339,6 +342,60 @@ func (a *API) listMember(c *gin.Context, resourceID uint64, resourceType memberm
 	response.SuccessWithData(c, membersResp)
 }
 
+func (a *API) ExplainGroupPermission(c *gin.Context) {
+	a.explainPermission(c, common.ResourceGroup, _paramGroupID)
+}
+
+func (a *API) ExplainApplicationPermission(c *gin.Context) {
+	a.explainPermission(c, common.ResourceApplication, _paramApplicationID)
+}
+
+func (a *API) ExplainClusterPermission(c *gin.Context) {
+	a.explainPermission(c, common.ResourceCluster, _paramApplicationClusterID)
+}
+
+// explainPermission explains the permissions of the user in query on the resource, the current user by default
+func (a *API) explainPermission(c *gin.Context, resourceType, param string) {
+	const op = "member: explain permission"
+	resourceID, err := strconv.ParseUint(c.Param(param), 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid %s: %s", param, c.Param(param)))
+		return
+	}
+	var userID uint
+	if userIDStr := c.Query(_queryUserID); userIDStr != "" {
+		id, err := strconv.ParseUint(userIDStr, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid userID: %s", userIDStr))
+			return
+		}
+		userID = uint(id)
+	} else {
+		currentUser, err := common.UserFromContext(c)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		userID = currentUser.GetID()
+	}
+
+	permission, err := a.memberCtrl.ExplainPermission(c, resourceType, uint(resourceID), userID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, permission)
+}
+
 func (a *API) validRole(ctx context.Context, role string) error {
 	_, err := a.roleService.GetRole(ctx, role)
 	if err != nil {
@@ -33,9 +33,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListGroupMember,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/groups/:%v/members", _paramGroupID),
-			HandlerFunc: api.CreateGroupMember,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/groups/:%v/members", _paramGroupID),
+			HandlerFunc:   api.CreateGroupMember,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -43,9 +44,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListApplicationMember,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/applications/:%v/members", _paramApplicationID),
-			HandlerFunc: api.CreateApplicationMember,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/applications/:%v/members", _paramApplicationID),
+			HandlerFunc:   api.CreateApplicationMember,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -53,9 +55,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListApplicationClusterMember,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/clusters/:%v/members", _paramApplicationClusterID),
-			HandlerFunc: api.CreateApplicationClusterMember,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/clusters/:%v/members", _paramApplicationClusterID),
+			HandlerFunc:   api.CreateApplicationClusterMember,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -63,19 +66,37 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListTemplateMember,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/templates/:%v/members", _paramTemplateID),
-			HandlerFunc: api.CreateTemplateMember,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/templates/:%v/members", _paramTemplateID),
+			HandlerFunc:   api.CreateTemplateMember,
+			Transactional: true,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/groups/:%v/permissions", _paramGroupID),
+			HandlerFunc: api.ExplainGroupPermission,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/permissions", _paramApplicationID),
+			HandlerFunc: api.ExplainApplicationPermission,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/permissions", _paramApplicationClusterID),
+			HandlerFunc: api.ExplainClusterPermission,
 		},
 		{
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/members/:%v", _paramMemberID),
-			HandlerFunc: api.UpdateMember,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/members/:%v", _paramMemberID),
+			HandlerFunc:   api.UpdateMember,
+			Transactional: true,
 		},
 		{
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/members/:%v", _paramMemberID),
-			HandlerFunc: api.DeleteMember,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/members/:%v", _paramMemberID),
+			HandlerFunc:   api.DeleteMember,
+			Transactional: true,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
@@ -0,0 +1,114 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/metadata"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	metadataCtl metadata.Controller
+}
+
+func NewAPI(controller metadata.Controller) *API {
+	return &API{metadataCtl: controller}
+}
+
+func (a *API) GetFields(c *gin.Context) {
+	response.SuccessWithData(c, a.metadataCtl.GetFields(c))
+}
+
+func (a *API) GetApplicationMetadata(c *gin.Context) {
+	a.get(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) GetClusterMetadata(c *gin.Context) {
+	a.get(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) UpdateApplicationMetadata(c *gin.Context) {
+	a.update(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) UpdateClusterMetadata(c *gin.Context) {
+	a.update(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) get(c *gin.Context, resourceType, keyResourceID string) {
+	const op = "metadata: get"
+	resourceIDStr := c.Param(keyResourceID)
+	resourceID, err := strconv.ParseUint(resourceIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid resource id: %s", resourceIDStr)))
+		return
+	}
+
+	values, err := a.metadataCtl.Get(c, resourceType, uint(resourceID))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, values)
+}
+
+func (a *API) update(c *gin.Context, resourceType, keyResourceID string) {
+	const op = "metadata: update"
+	resourceIDStr := c.Param(keyResourceID)
+	resourceID, err := strconv.ParseUint(resourceIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid resource id: %s", resourceIDStr)))
+		return
+	}
+
+	var request metadata.UpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid request body, err: %s", err.Error())))
+		return
+	}
+	if err := a.metadataCtl.Update(c, resourceType, uint(resourceID), &request); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		if e.Source == herrors.ClusterInDB || e.Source == herrors.ApplicationInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
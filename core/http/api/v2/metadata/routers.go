@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+func (api *API) RegisterRoute(engine *gin.Engine) {
+	frontGroup := engine.Group("/apis/front/v2")
+	var frontRoutes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/metadatafields",
+			HandlerFunc: api.GetFields,
+		},
+	}
+	route.RegisterRoutes(frontGroup, frontRoutes)
+
+	coreGroup := engine.Group("/apis/core/v2")
+	var routes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/metadata", common.ParamApplicationID),
+			HandlerFunc: api.GetApplicationMetadata,
+		}, {
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/applications/:%v/metadata", common.ParamApplicationID),
+			HandlerFunc:   api.UpdateApplicationMetadata,
+			Transactional: true,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/metadata", common.ParamClusterID),
+			HandlerFunc: api.GetClusterMetadata,
+		}, {
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/clusters/:%v/metadata", common.ParamClusterID),
+			HandlerFunc:   api.UpdateClusterMetadata,
+			Transactional: true,
+		},
+	}
+	route.RegisterRoutes(coreGroup, routes)
+}
@@ -0,0 +1,42 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"github.com/horizoncd/horizon/core/controller/migration"
+	"github.com/horizoncd/horizon/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type API struct {
+	migrationCtl migration.Controller
+}
+
+func NewAPI(migrationCtl migration.Controller) *API {
+	return &API{
+		migrationCtl: migrationCtl,
+	}
+}
+
+func (a *API) Status(c *gin.Context) {
+	const op = "migration: status"
+	status, err := a.migrationCtl.Status(c)
+	if err != nil {
+		response.AbortWithHorizonError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, status)
+}
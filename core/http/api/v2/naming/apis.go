@@ -0,0 +1,66 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package naming
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/naming"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_kind = "kind"
+	_name = "name"
+)
+
+type API struct {
+	namingCtl naming.Controller
+}
+
+func NewAPI(controller naming.Controller) *API {
+	return &API{namingCtl: controller}
+}
+
+func (a *API) GetPolicies(c *gin.Context) {
+	response.SuccessWithData(c, a.namingCtl.GetPolicies(c))
+}
+
+func (a *API) Preview(c *gin.Context) {
+	const op = "naming: preview"
+	kind := c.Query(_kind)
+	name := c.Query(_name)
+	if kind == "" || name == "" {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, "kind and name cannot be empty")
+		return
+	}
+
+	resp, err := a.namingCtl.Preview(c, kind, name)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
@@ -0,0 +1,175 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	notificationctl "github.com/horizoncd/horizon/core/controller/notification"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	notificationCtl notificationctl.Controller
+}
+
+func NewAPI(ctl notificationctl.Controller) *API {
+	return &API{
+		notificationCtl: ctl,
+	}
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
+
+func parseID(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid %s: %s", param, idStr))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (a *API) CreateChannel(c *gin.Context) {
+	const op = "notification: create channel"
+	resourceID, ok := parseID(c, _resourceIDParam)
+	if !ok {
+		return
+	}
+
+	var request notificationctl.CreateChannelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.notificationCtl.CreateChannel(c, c.Param(_resourceTypeParam), resourceID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListChannels(c *gin.Context) {
+	const op = "notification: list channels"
+	resourceID, ok := parseID(c, _resourceIDParam)
+	if !ok {
+		return
+	}
+
+	resp, err := a.notificationCtl.ListChannels(c, c.Param(_resourceTypeParam), resourceID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) UpdateChannel(c *gin.Context) {
+	const op = "notification: update channel"
+	id, ok := parseID(c, _channelIDParam)
+	if !ok {
+		return
+	}
+
+	var request notificationctl.UpdateChannelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.notificationCtl.UpdateChannel(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) DeleteChannel(c *gin.Context) {
+	const op = "notification: delete channel"
+	id, ok := parseID(c, _channelIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.notificationCtl.DeleteChannel(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) ListSubscriptions(c *gin.Context) {
+	const op = "notification: list subscriptions"
+	resp, err := a.notificationCtl.ListSubscriptions(c)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Subscribe(c *gin.Context) {
+	const op = "notification: subscribe"
+	var request notificationctl.SubscribeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.notificationCtl.Subscribe(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) DeleteSubscription(c *gin.Context) {
+	const op = "notification: delete subscription"
+	id, ok := parseID(c, _subscriptionIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.notificationCtl.DeleteSubscription(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
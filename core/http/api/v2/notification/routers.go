@@ -0,0 +1,78 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	_resourceTypeParam   = "resourceType"
+	_resourceIDParam     = "resourceID"
+	_channelIDParam      = "channelID"
+	_subscriptionIDParam = "subscriptionID"
+)
+
+func (api *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routes = route.Routes{
+		{
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%v/:%v/notificationchannels", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc:   api.CreateChannel,
+			Transactional: true,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/:%v/:%v/notificationchannels", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc: api.ListChannels,
+		},
+		{
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/notificationchannels/:%v", _channelIDParam),
+			HandlerFunc:   api.UpdateChannel,
+			Transactional: true,
+		},
+		{
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/notificationchannels/:%v", _channelIDParam),
+			HandlerFunc:   api.DeleteChannel,
+			Transactional: true,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/notificationsubscriptions",
+			HandlerFunc: api.ListSubscriptions,
+		},
+		{
+			Method:        http.MethodPut,
+			Pattern:       "/notificationsubscriptions",
+			HandlerFunc:   api.Subscribe,
+			Transactional: true,
+		},
+		{
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/notificationsubscriptions/:%v", _subscriptionIDParam),
+			HandlerFunc:   api.DeleteSubscription,
+			Transactional: true,
+		},
+	}
+	route.RegisterRoutes(group, routes)
+}
@@ -17,11 +17,13 @@ package oauthapp
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/oauthapp"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
@@ -55,6 +57,10 @@ func (a *API) CreateOauthApp(c *gin.Context) {
 	}
 	resp, err := a.oauthAppController.Create(c, uint(groupID), *req)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
 		log.Errorf(c, "%s err, error = %s", op, err.Error())
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -118,6 +124,39 @@ func (a *API) UpdateOauthApp(c *gin.Context) {
 	}
 	oauthApp, err := a.oauthAppController.Update(c, *req)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.Errorf(c, "%s err, error = %s", op, err.Error())
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, oauthApp)
+}
+
+func (a *API) TransferOauthApp(c *gin.Context) {
+	const op = "TransferOauthApp"
+	oauthAppClientIDStr := c.Param(_oauthAppClientIDParam)
+	groupIDStr := c.Query(_groupIDParam)
+	groupID, err := strconv.ParseUint(groupIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid groupID: %s, err: %s",
+			groupIDStr, err.Error())))
+		return
+	}
+	oauthApp, err := a.oauthAppController.Transfer(c, oauthAppClientIDStr, uint(groupID))
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.GroupInDB || e.Source == herrors.OAuthInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		}
 		log.Errorf(c, "%s err, error = %s", op, err.Error())
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -185,3 +224,50 @@ func (a *API) DeleteSecret(c *gin.Context) {
 	}
 	response.Success(c)
 }
+
+func (a *API) ListTokenAudits(c *gin.Context) {
+	const op = "ListTokenAudits"
+	keywords := q.KeyWords{}
+	for _, key := range []string{common.OauthTokenAuditQueryClientID, common.OauthTokenAuditQueryAction,
+		common.OauthTokenAuditQuerySourceIP} {
+		if v := c.Query(key); v != "" {
+			keywords[key] = v
+		}
+	}
+	if userIDStr := c.Query(common.OauthTokenAuditQueryUserID); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s",
+				common.OauthTokenAuditQueryUserID, userIDStr)))
+			return
+		}
+		keywords[common.OauthTokenAuditQueryUserID] = uint(userID)
+	}
+	for _, key := range []string{common.StartTime, common.EndTime} {
+		if v := c.Query(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				response.AbortWithRPCError(c, rpcerror.ParamError.
+					WithErrMsg(fmt.Sprintf("invalid %s, should be in RFC3339: %v", key, err)))
+				return
+			}
+			keywords[key] = t
+		}
+	}
+
+	query := q.New(keywords).WithPagination(c)
+	audits, total, err := a.oauthAppController.ListTokenAudits(c, query)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrNoPrivilege {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.Errorf(c, "%s err, error = %s", op, err.Error())
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: audits,
+		Total: int64(total),
+	})
+}
@@ -32,9 +32,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 	apiGroup := engine.Group("/apis/core/v2")
 	r := route.Routes{
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/groups/:%v/oauthapps", _groupIDParam),
-			HandlerFunc: api.CreateOauthApp,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/groups/:%v/oauthapps", _groupIDParam),
+			HandlerFunc:   api.CreateOauthApp,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/groups/:%v/oauthapps", _groupIDParam),
@@ -44,13 +45,20 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/oauthapps/:%v", _oauthAppClientIDParam),
 			HandlerFunc: api.GetOauthApp,
 		}, {
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/oauthapps/:%v", _oauthAppClientIDParam),
-			HandlerFunc: api.UpdateOauthApp,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/oauthapps/:%v", _oauthAppClientIDParam),
+			HandlerFunc:   api.UpdateOauthApp,
+			Transactional: true,
 		}, {
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/oauthapps/:%v", _oauthAppClientIDParam),
-			HandlerFunc: api.DeleteOauthApp,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/oauthapps/:%v", _oauthAppClientIDParam),
+			HandlerFunc:   api.DeleteOauthApp,
+			Transactional: true,
+		}, {
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/oauthapps/:%v/transfer", _oauthAppClientIDParam),
+			HandlerFunc:   api.TransferOauthApp,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/oauthapps/:%v/clientsecret", _oauthAppClientIDParam),
@@ -59,11 +67,17 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method: http.MethodDelete,
 			Pattern: fmt.Sprintf("/oauthapps/:%v/clientsecret/:%v",
 				_oauthAppClientIDParam, _oauthClientSecretID),
-			HandlerFunc: api.DeleteSecret,
+			HandlerFunc:   api.DeleteSecret,
+			Transactional: true,
 		}, {
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/oauthapps/:%v/clientsecret", _oauthAppClientIDParam),
-			HandlerFunc: api.CreateSecret,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/oauthapps/:%v/clientsecret", _oauthAppClientIDParam),
+			HandlerFunc:   api.CreateSecret,
+			Transactional: true,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     "/oauthtokenaudits",
+			HandlerFunc: api.ListTokenAudits,
 		},
 	}
 	route.RegisterRoutes(apiGroup, r)
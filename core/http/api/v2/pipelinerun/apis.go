@@ -15,7 +15,10 @@
 package pipelinerun
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -29,6 +32,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 )
 
@@ -37,7 +41,27 @@ const (
 	_checkrunIDParam    = "checkrunID"
 	_clusterIDParam     = "clusterID"
 	_canRollbackParam   = "canRollback"
+	_followQuery        = "follow"
+	_cursorQuery        = "cursor"
 	_pipelineStatus     = "status"
+	_artifactIDParam    = "artifactID"
+	_artifactTypeQuery  = "type"
+
+	_componentNameParam    = "name"
+	_componentVersionParam = "version"
+
+	_artifactFileForm   = "file"
+	_artifactNameForm   = "name"
+	_artifactTypeForm   = "type"
+	_artifactFormatForm = "format"
+
+	_jwtTokenHeader = "X-Horizon-JWT-Token"
+
+	_lastEventIDHeader = "Last-Event-ID"
+
+	_logEvent   = "log"
+	_errorEvent = "error"
+	_endEvent   = "end"
 )
 
 type API struct {
@@ -64,6 +88,73 @@ func (a *API) Log(c *gin.Context) {
 	})
 }
 
+// Logs streams the logs of pipelinerun as server-sent events, each line of the logs is a log event.
+// The id of the last event in a batch is the cursor to resume the stream from,
+// which is sent back by Last-Event-ID header once reconnected, or by cursor query.
+func (a *API) Logs(c *gin.Context) {
+	a.withPipelinerunID(c, func(prID uint) {
+		follow := false
+		if followStr := c.Query(_followQuery); followStr != "" {
+			var err error
+			follow, err = strconv.ParseBool(followStr)
+			if err != nil {
+				response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+				return
+			}
+		}
+		token := c.GetHeader(_lastEventIDHeader)
+		if token == "" {
+			token = c.Query(_cursorQuery)
+		}
+		cursor, err := prctl.ParseLogCursor(token)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
+
+		// gin.Context is never done, the stream is stopped once the client disconnects
+		ctx, cancel := context.WithCancel(c)
+		defer cancel()
+		disconnected := c.Request.Context().Done()
+		go func() {
+			select {
+			case <-disconnected:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		err = a.prCtl.StreamPipelinerunLog(ctx, prID, follow, cursor,
+			func(lines []*prctl.LogLine, cursor prctl.LogCursor) error {
+				for i, line := range lines {
+					event := sse.Event{Event: _logEvent, Data: line}
+					if i == len(lines)-1 {
+						event.Id = cursor.String()
+					}
+					c.Render(-1, event)
+				}
+				c.Writer.Flush()
+				return ctx.Err()
+			})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if !c.Writer.Written() {
+				if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+					response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+					return
+				}
+				response.AbortWithError(c, err)
+				return
+			}
+			c.Render(-1, sse.Event{Event: _errorEvent, Data: errors.Message(err)})
+			return
+		}
+		c.Render(-1, sse.Event{Event: _endEvent, Data: ""})
+	})
+}
+
 func (a *API) writeLog(c *gin.Context, l *collector.Log) {
 	c.Header("Content-Type", "text/plain")
 	if l.LogBytes != nil {
@@ -189,6 +280,22 @@ func (a *API) execute(c *gin.Context, force bool) {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(e.Error()))
 				return
 			}
+			if perror.Cause(err) == herrors.ErrDeployConflict {
+				response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+				return
+			}
+			if perror.Cause(err) == herrors.ErrDeployLocked {
+				response.AbortWithRPCError(c, rpcerror.LockedError.WithErrMsg(err.Error()))
+				return
+			}
+			if perror.Cause(err) == herrors.ErrDeployFrozen {
+				response.AbortWithRPCError(c, rpcerror.LockedError.WithErrMsg(err.Error()))
+				return
+			}
+			if perror.Cause(err) == herrors.ErrDeployApprovalRequired {
+				response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+				return
+			}
 			response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 			return
 		}
@@ -196,10 +303,68 @@ func (a *API) execute(c *gin.Context, force bool) {
 	})
 }
 
+func (a *API) GetDeployApproval(c *gin.Context) {
+	a.withPipelinerunID(c, func(prID uint) {
+		approval, err := a.prCtl.GetDeployApproval(c, prID)
+		if err != nil {
+			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(e.Error()))
+				return
+			}
+			response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+			return
+		}
+		response.SuccessWithData(c, approval)
+	})
+}
+
+func (a *API) ReviewDeployApproval(c *gin.Context) {
+	var req *prctl.ReviewDeployApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req == nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	a.withPipelinerunID(c, func(prID uint) {
+		approval, err := a.prCtl.ReviewDeployApproval(c, prID, req)
+		if err != nil {
+			if perror.Cause(err) == herrors.ErrParamInvalid {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+				return
+			}
+			if perror.Cause(err) == herrors.ErrForbidden {
+				response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+				return
+			}
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+			response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+			return
+		}
+		response.SuccessWithData(c, approval)
+	})
+}
+
+// ListDeployApprovals lists approvals which the current user can review
+func (a *API) ListDeployApprovals(c *gin.Context) {
+	approvals, err := a.prCtl.ListDeployApprovals(c, c.Query(_pipelineStatus))
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, approvals)
+}
+
 func (a *API) Cancel(c *gin.Context) {
 	a.withPipelinerunID(c, func(prID uint) {
 		err := a.prCtl.Cancel(c, prID)
 		if err != nil {
+			if perror.Cause(err) == herrors.ErrParamInvalid {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+				return
+			}
 			response.AbortWithError(c, err)
 			return
 		}
@@ -207,6 +372,52 @@ func (a *API) Cancel(c *gin.Context) {
 	})
 }
 
+func (a *API) Retry(c *gin.Context) {
+	a.withPipelinerunID(c, func(prID uint) {
+		pipelinerun, err := a.prCtl.Retry(c, prID)
+		if err != nil {
+			abortWithRetryError(c, err)
+			return
+		}
+		response.SuccessWithData(c, pipelinerun)
+	})
+}
+
+func (a *API) RerunFromStep(c *gin.Context) {
+	// reruns from the first failed step without a body
+	var req prctl.RerunFromStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	a.withPipelinerunID(c, func(prID uint) {
+		pipelinerun, err := a.prCtl.RerunFromStep(c, prID, &req)
+		if err != nil {
+			abortWithRetryError(c, err)
+			return
+		}
+		response.SuccessWithData(c, pipelinerun)
+	})
+}
+
+func abortWithRetryError(c *gin.Context, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+	case herrors.ErrDeployConflict:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+	case herrors.ErrDeployLocked, herrors.ErrDeployFrozen:
+		response.AbortWithRPCError(c, rpcerror.LockedError.WithErrMsg(err.Error()))
+	default:
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
+
 func (a *API) ListCheckRuns(c *gin.Context) {
 	a.withPipelinerunID(c, func(pipelinerunID uint) {
 		checkRuns, err := a.prCtl.ListCheckRuns(c, pipelinerunID)
@@ -320,3 +531,168 @@ func (a *API) withCheckrunID(c *gin.Context, f func(pipelineRunID uint)) {
 	}
 	f(uint(id))
 }
+
+func (a *API) InternalCreateSBOM(c *gin.Context) {
+	var req prctl.CreateSBOMRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	tokenString := c.Request.Header.Get(_jwtTokenHeader)
+	if tokenString == "" {
+		response.AbortWithUnauthorized(c, common.Unauthorized, "jwt token is empty!")
+		return
+	}
+
+	a.withPipelinerunID(c, func(prID uint) {
+		var ctx context.Context = c
+		ctx = common.WithContextJWTTokenString(ctx, tokenString)
+		sbom, err := a.prCtl.InternalCreateSBOM(ctx, prID, &req)
+		if err != nil {
+			if perror.Cause(err) == herrors.ErrTokenInvalid {
+				response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+				return
+			}
+			if perror.Cause(err) == herrors.ErrForbidden {
+				response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+				return
+			}
+			if perror.Cause(err) == herrors.ErrParamInvalid {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+				return
+			}
+			response.AbortWithError(c, err)
+			return
+		}
+		response.SuccessWithData(c, sbom)
+	})
+}
+
+func (a *API) GetSBOM(c *gin.Context) {
+	a.withPipelinerunID(c, func(prID uint) {
+		sbom, err := a.prCtl.GetSBOM(c, prID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+			response.AbortWithError(c, err)
+			return
+		}
+		c.Header("Content-Type", "application/json")
+		_, _ = c.Writer.Write([]byte(sbom.Content))
+	})
+}
+
+func (a *API) ListClustersBySBOMComponent(c *gin.Context) {
+	components, err := a.prCtl.ListClustersBySBOMComponent(c,
+		c.Query(_componentNameParam), c.Query(_componentVersionParam))
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		response.AbortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, components)
+}
+
+func (a *API) InternalUploadArtifact(c *gin.Context) {
+	tokenString := c.Request.Header.Get(_jwtTokenHeader)
+	if tokenString == "" {
+		response.AbortWithUnauthorized(c, common.Unauthorized, "jwt token is empty!")
+		return
+	}
+	fileHeader, err := c.FormFile(_artifactFileForm)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	defer file.Close()
+	name := c.PostForm(_artifactNameForm)
+	if name == "" {
+		name = fileHeader.Filename
+	}
+
+	a.withPipelinerunID(c, func(prID uint) {
+		var ctx context.Context = c
+		ctx = common.WithContextJWTTokenString(ctx, tokenString)
+		artifact, err := a.prCtl.InternalUploadArtifact(ctx, prID, &prctl.UploadArtifactRequest{
+			Name:    name,
+			Type:    c.PostForm(_artifactTypeForm),
+			Format:  c.PostForm(_artifactFormatForm),
+			Content: file,
+		})
+		if err != nil {
+			if perror.Cause(err) == herrors.ErrTokenInvalid {
+				response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+				return
+			}
+			if perror.Cause(err) == herrors.ErrForbidden {
+				response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+				return
+			}
+			if perror.Cause(err) == herrors.ErrParamInvalid ||
+				perror.Cause(err) == herrors.ErrArtifactStorageDisabled {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+				return
+			}
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+			response.AbortWithError(c, err)
+			return
+		}
+		response.SuccessWithData(c, artifact)
+	})
+}
+
+func (a *API) ListArtifacts(c *gin.Context) {
+	a.withPipelinerunID(c, func(prID uint) {
+		artifacts, err := a.prCtl.ListArtifacts(c, prID, c.Query(_artifactTypeQuery))
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+			response.AbortWithError(c, err)
+			return
+		}
+		response.SuccessWithData(c, artifacts)
+	})
+}
+
+// DownloadArtifact redirects to the presigned url of the artifact in the artifact storage
+func (a *API) DownloadArtifact(c *gin.Context) {
+	artifactID, err := strconv.ParseUint(c.Param(_artifactIDParam), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	a.withPipelinerunID(c, func(prID uint) {
+		url, err := a.prCtl.GetArtifactDownloadURL(c, prID, uint(artifactID))
+		if err != nil {
+			if perror.Cause(err) == herrors.ErrArtifactStorageDisabled {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+				return
+			}
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+			response.AbortWithError(c, err)
+			return
+		}
+		c.Redirect(http.StatusFound, url)
+	})
+}
@@ -31,6 +31,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/pipelineruns/:%v/log", _pipelinerunIDParam),
 			HandlerFunc: api.Log,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/logs", _pipelinerunIDParam),
+			HandlerFunc: api.Logs,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/pipelineruns/:%v/stop", _pipelinerunIDParam),
@@ -66,8 +70,34 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 		},
 		{
 			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/pipelineruns/:%v/checkruns", _pipelinerunIDParam),
-			HandlerFunc: api.CreateCheckRun,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/retry", _pipelinerunIDParam),
+			HandlerFunc: api.Retry,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/rerun", _pipelinerunIDParam),
+			HandlerFunc: api.RerunFromStep,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/approval", _pipelinerunIDParam),
+			HandlerFunc: api.GetDeployApproval,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/approval", _pipelinerunIDParam),
+			HandlerFunc: api.ReviewDeployApproval,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/deployapprovals",
+			HandlerFunc: api.ListDeployApprovals,
+		},
+		{
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/pipelineruns/:%v/checkruns", _pipelinerunIDParam),
+			HandlerFunc:   api.CreateCheckRun,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -80,21 +110,59 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListCheckRuns,
 		},
 		{
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/checkruns/:%v", _checkrunIDParam),
-			HandlerFunc: api.UpdateCheckRun,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/checkruns/:%v", _checkrunIDParam),
+			HandlerFunc:   api.UpdateCheckRun,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/pipelineruns/:%v/messages", _pipelinerunIDParam),
 			HandlerFunc: api.ListPrMessages,
 		},
+		{
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/pipelineruns/:%v/messages", _pipelinerunIDParam),
+			HandlerFunc:   api.CreatePrMessage,
+			Transactional: true,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/sbom", _pipelinerunIDParam),
+			HandlerFunc: api.GetSBOM,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/sbomcomponents/clusters",
+			HandlerFunc: api.ListClustersBySBOMComponent,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/artifacts", _pipelinerunIDParam),
+			HandlerFunc: api.ListArtifacts,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/artifacts/:%v/download", _pipelinerunIDParam, _artifactIDParam),
+			HandlerFunc: api.DownloadArtifact,
+		},
+	}
+
+	internalGroup := engine.Group("/apis/internal/v2")
+	var internalRoutes = route.Routes{
+		{
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/pipelineruns/:%v/sbom", _pipelinerunIDParam),
+			HandlerFunc:   api.InternalCreateSBOM,
+			Transactional: true,
+		},
 		{
 			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/pipelineruns/:%v/messages", _pipelinerunIDParam),
-			HandlerFunc: api.CreatePrMessage,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/artifacts", _pipelinerunIDParam),
+			HandlerFunc: api.InternalUploadArtifact,
 		},
 	}
 
 	route.RegisterRoutes(apiGroup, routes)
+	route.RegisterRoutes(internalGroup, internalRoutes)
 }
@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinestep
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/pipelinestep"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type API struct {
+	pipelineStepCtl pipelinestep.Controller
+}
+
+func NewAPI(pipelineStepCtl pipelinestep.Controller) *API {
+	return &API{
+		pipelineStepCtl: pipelineStepCtl,
+	}
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "pipeline step: list"
+	applicationID, ok := parseApplicationID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.pipelineStepCtl.List(c, applicationID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "pipeline step: update"
+	applicationID, ok := parseApplicationID(c)
+	if !ok {
+		return
+	}
+
+	var request *pipelinestep.UpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request == nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid request body, err: %v", err)))
+		return
+	}
+	if err := a.pipelineStepCtl.Update(c, applicationID, request); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseApplicationID(c *gin.Context) (uint, bool) {
+	applicationIDStr := c.Param(common.ParamApplicationID)
+	applicationID, err := strconv.ParseUint(applicationIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid application id: %s", applicationIDStr)))
+		return 0, false
+	}
+	return uint(applicationID), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		if e.Source == herrors.ApplicationInDB || e.Source == herrors.TemplateReleaseInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
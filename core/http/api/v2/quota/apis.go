@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/quota"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _queryResource = "resource"
+
+type API struct {
+	quotaCtl quota.Controller
+}
+
+func NewAPI(quotaCtl quota.Controller) *API {
+	return &API{
+		quotaCtl: quotaCtl,
+	}
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "quota: list"
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.quotaCtl.List(c, groupID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Set(c *gin.Context) {
+	const op = "quota: set"
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var request *quota.SetQuotaRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request == nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid request body, err: %v", err)))
+		return
+	}
+	resp, err := a.quotaCtl.Set(c, groupID, request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "quota: delete"
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	if err := a.quotaCtl.Delete(c, groupID, c.Query(_queryResource)); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseGroupID(c *gin.Context) (uint, bool) {
+	groupIDStr := c.Param(common.ParamGroupID)
+	groupID, err := strconv.ParseUint(groupIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsg(fmt.Sprintf("invalid group id: %s", groupIDStr)))
+		return 0, false
+	}
+	return uint(groupID), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		if e.Source == herrors.GroupInDB || e.Source == herrors.GroupQuotaInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
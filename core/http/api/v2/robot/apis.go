@@ -0,0 +1,213 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	robotctl "github.com/horizoncd/horizon/core/controller/robot"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	robotCtl robotctl.Controller
+}
+
+func NewAPI(ctl robotctl.Controller) *API {
+	return &API{
+		robotCtl: ctl,
+	}
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	switch cause := perror.Cause(err); cause {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrNameConflict:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrNoPrivilege, herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	default:
+		if _, ok := cause.(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
+
+func parseID(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid %s: %s", param, idStr))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (a *API) CreateRobot(c *gin.Context) {
+	const op = "robot: create robot"
+	resourceID, ok := parseID(c, _resourceIDParam)
+	if !ok {
+		return
+	}
+
+	var request robotctl.CreateRobotRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.robotCtl.CreateRobot(c, c.Param(_resourceTypeParam), resourceID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListRobots(c *gin.Context) {
+	const op = "robot: list robots"
+	resourceID, ok := parseID(c, _resourceIDParam)
+	if !ok {
+		return
+	}
+
+	resp, err := a.robotCtl.ListRobots(c, c.Param(_resourceTypeParam), resourceID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) GetRobot(c *gin.Context) {
+	const op = "robot: get robot"
+	id, ok := parseID(c, _robotIDParam)
+	if !ok {
+		return
+	}
+
+	resp, err := a.robotCtl.GetRobot(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) UpdateRobot(c *gin.Context) {
+	const op = "robot: update robot"
+	id, ok := parseID(c, _robotIDParam)
+	if !ok {
+		return
+	}
+
+	var request robotctl.UpdateRobotRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.robotCtl.UpdateRobot(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) DeleteRobot(c *gin.Context) {
+	const op = "robot: delete robot"
+	id, ok := parseID(c, _robotIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.robotCtl.DeleteRobot(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) CreateToken(c *gin.Context) {
+	const op = "robot: create token"
+	id, ok := parseID(c, _robotIDParam)
+	if !ok {
+		return
+	}
+
+	var request robotctl.CreateTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.robotCtl.CreateToken(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListTokens(c *gin.Context) {
+	const op = "robot: list tokens"
+	id, ok := parseID(c, _robotIDParam)
+	if !ok {
+		return
+	}
+
+	resp, err := a.robotCtl.ListTokens(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) RevokeToken(c *gin.Context) {
+	const op = "robot: revoke token"
+	id, ok := parseID(c, _robotIDParam)
+	if !ok {
+		return
+	}
+	tokenID, ok := parseID(c, _tokenIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.robotCtl.RevokeToken(c, id, tokenID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
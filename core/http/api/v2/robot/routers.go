@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	_resourceTypeParam = "resourceType"
+	_resourceIDParam   = "resourceID"
+	_robotIDParam      = "robotID"
+	_tokenIDParam      = "tokenID"
+)
+
+func (api *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routes = route.Routes{
+		{
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%v/:%v/robots", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc:   api.CreateRobot,
+			Transactional: true,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/:%v/:%v/robots", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc: api.ListRobots,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/robots/:%v", _robotIDParam),
+			HandlerFunc: api.GetRobot,
+		},
+		{
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/robots/:%v", _robotIDParam),
+			HandlerFunc:   api.UpdateRobot,
+			Transactional: true,
+		},
+		{
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/robots/:%v", _robotIDParam),
+			HandlerFunc:   api.DeleteRobot,
+			Transactional: true,
+		},
+		{
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/robots/:%v/tokens", _robotIDParam),
+			HandlerFunc:   api.CreateToken,
+			Transactional: true,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/robots/:%v/tokens", _robotIDParam),
+			HandlerFunc: api.ListTokens,
+		},
+		{
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/robots/:%v/tokens/:%v", _robotIDParam, _tokenIDParam),
+			HandlerFunc:   api.RevokeToken,
+			Transactional: true,
+		},
+	}
+	route.RegisterRoutes(group, routes)
+}
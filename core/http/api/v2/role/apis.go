@@ -17,9 +17,15 @@ package role
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/controller/role"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
+const _roleNameParam = "roleName"
+
 type API struct {
 	roleCtrl role.Controller
 }
@@ -36,3 +42,65 @@ func (a *API) ListRole(c *gin.Context) {
 	}
 	response.SuccessWithData(c, roles)
 }
+
+func (a *API) CreateRole(c *gin.Context) {
+	const op = "role: create role"
+	var request role.CreateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.roleCtrl.CreateRole(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) UpdateRole(c *gin.Context) {
+	const op = "role: update role"
+	var request role.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.roleCtrl.UpdateRole(c, c.Param(_roleNameParam), &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) DeleteRole(c *gin.Context) {
+	const op = "role: delete role"
+	if err := a.roleCtrl.DeleteRole(c, c.Param(_roleNameParam)); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	switch cause := perror.Cause(err); cause {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrNameConflict, herrors.ErrRoleInUse:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	default:
+		if _, ok := cause.(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -15,6 +15,7 @@
 package role
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -30,6 +31,21 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     "/roles",
 			HandlerFunc: api.ListRole,
+		}, {
+			Method:        http.MethodPost,
+			Pattern:       "/roles",
+			HandlerFunc:   api.CreateRole,
+			Transactional: true,
+		}, {
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/roles/:%v", _roleNameParam),
+			HandlerFunc:   api.UpdateRole,
+			Transactional: true,
+		}, {
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/roles/:%v", _roleNameParam),
+			HandlerFunc:   api.DeleteRole,
+			Transactional: true,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
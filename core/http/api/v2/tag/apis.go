@@ -53,6 +53,10 @@ func (a *API) ListApplicationTags(c *gin.Context) {
 	a.List(c, common.ResourceApplication, common.ParamApplicationID)
 }
 
+func (a *API) ListGroupTags(c *gin.Context) {
+	a.List(c, common.ResourceGroup, common.ParamGroupID)
+}
+
 func (a *API) List(c *gin.Context, resourceType, keyResourceID string) {
 	const op = "tag: list"
 	resourceIDStr := c.Param(keyResourceID)
@@ -100,7 +104,8 @@ func (a *API) Update(c *gin.Context) {
 			return
 		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
-			if e.Source == herrors.ClusterInDB || e.Source == herrors.ApplicationInDB {
+			if e.Source == herrors.ClusterInDB || e.Source == herrors.ApplicationInDB ||
+				e.Source == herrors.GroupInDB {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 				return
 			}
@@ -36,17 +36,23 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/applications/:%v/tags", common.ParamApplicationID),
 			HandlerFunc: api.ListApplicationTags,
 		}, {
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/:%v/:%v/tags", _resourceTypeParam, _resourceIDParam),
-			HandlerFunc: api.Update,
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/groups/:%v/tags", common.ParamGroupID),
+			HandlerFunc: api.ListGroupTags,
+		}, {
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%v/:%v/tags", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc:   api.Update,
+			Transactional: true,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/:%v/:%v/subresourcetags", _resourceTypeParam, _resourceIDParam),
 			HandlerFunc: api.ListSubResourceTags,
 		}, {
-			Method:      http.MethodPost,
-			Pattern:     "/metatags",
-			HandlerFunc: api.CreateMetatags,
+			Method:        http.MethodPost,
+			Pattern:       "/metatags",
+			HandlerFunc:   api.CreateMetatags,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -308,8 +308,8 @@ func (a *API) CreateTemplate(c *gin.Context) {
 		defer func() { _ = a.templateCtl.DeleteTemplate(c, template.ID) }()
 
 		if perror.Cause(err) == herrors.ErrParamInvalid {
-			log.WithFiled(c, "op", op).Infof("could not parse gitlab url: %s", err)
-			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("failed parsing gitlab URL: %s", err)))
+			log.WithFiled(c, "op", op).Infof("request is invalid: %s", err)
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("request is invalid: %s", err)))
 			return
 		}
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
@@ -390,6 +390,10 @@ func (a *API) UpdateTemplate(c *gin.Context) {
 	}
 
 	if err = a.templateCtl.UpdateTemplate(c, uint(templateID), updateRequest); err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			log.WithFiled(c, "op", op).Infof("template with ID %d not found", templateID)
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(fmt.Sprintf("not found: %s", err)))
@@ -514,6 +518,43 @@ func (a *API) GetReleases(c *gin.Context) {
 	response.SuccessWithData(c, releases)
 }
 
+func (a *API) ListSourceVersions(c *gin.Context) {
+	op := "template: list source versions"
+
+	t := c.Param(_templateParam)
+	var (
+		templateID uint64
+		err        error
+	)
+
+	if templateID, err = strconv.ParseUint(t, 10, 64); err != nil {
+		log.WithFiled(c, "op", op).Info("templateID not found or invalid")
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("templateID not found or invalid"))
+		return
+	}
+
+	var versions []string
+	if versions, err = a.templateCtl.ListSourceVersions(c, uint(templateID)); err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			log.WithFiled(c, "op", op).Infof("template with ID %d not found", templateID)
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(fmt.Sprintf("not found: %s", err)))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(fmt.Sprintf("%s", err)))
+		return
+	}
+	response.SuccessWithData(c, versions)
+}
+
 func (a *API) DeleteRelease(c *gin.Context) {
 	op := "template: delete release"
 
@@ -598,6 +639,10 @@ func (a *API) UpdateRelease(c *gin.Context) {
 	}
 
 	if err = a.templateCtl.UpdateRelease(c, uint(releaseID), updateRequest); err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			log.WithFiled(c, "op", op).Infof("release with ID %d not found", releaseID)
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(fmt.Sprintf("not found: %s", err)))
@@ -637,3 +682,68 @@ func (a *API) SyncReleaseToRepo(c *gin.Context) {
 	}
 	response.Success(c)
 }
+
+func (a *API) PromoteRelease(c *gin.Context) {
+	op := "template: promote release"
+
+	r := c.Param(_releaseParam)
+	var (
+		releaseID uint64
+		err       error
+	)
+
+	if releaseID, err = strconv.ParseUint(r, 10, 64); err != nil {
+		log.WithFiled(c, "op", op).Info("releaseID not found or invalid")
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("releaseID not found or invalid"))
+		return
+	}
+
+	if err = a.templateCtl.PromoteRelease(c, uint(releaseID)); err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			log.WithFiled(c, "op", op).Infof("release with ID %d not found", releaseID)
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(fmt.Sprintf("not found: %s", err)))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(fmt.Sprintf("%s", err)))
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) GetCanaryStats(c *gin.Context) {
+	op := "template: get canary stats"
+
+	r := c.Param(_releaseParam)
+	var (
+		releaseID uint64
+		err       error
+	)
+
+	if releaseID, err = strconv.ParseUint(r, 10, 64); err != nil {
+		log.WithFiled(c, "op", op).Info("releaseID not found or invalid")
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("releaseID not found or invalid"))
+		return
+	}
+
+	var stats *templatectl.CanaryStats
+	if stats, err = a.templateCtl.GetCanaryStats(c, uint(releaseID)); err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			log.WithFiled(c, "op", op).Infof("release with ID %d not found", releaseID)
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(fmt.Sprintf("not found: %s", err)))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(fmt.Sprintf("%s", err)))
+		return
+	}
+	response.SuccessWithData(c, stats)
+}
@@ -64,6 +64,11 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.GetReleases,
 			Pattern:     fmt.Sprintf("/:%s/releases", _templateParam),
 		},
+		{
+			Method:      http.MethodGet,
+			HandlerFunc: api.ListSourceVersions,
+			Pattern:     fmt.Sprintf("/:%s/sourceversions", _templateParam),
+		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
 
@@ -91,6 +96,16 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.SyncReleaseToRepo,
 			Pattern:     "/sync",
 		},
+		{
+			Method:      http.MethodPost,
+			HandlerFunc: api.PromoteRelease,
+			Pattern:     "/promote",
+		},
+		{
+			Method:      http.MethodGet,
+			HandlerFunc: api.GetCanaryStats,
+			Pattern:     "/canarystats",
+		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
 }
@@ -16,11 +16,15 @@ package terminal
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/horizoncd/horizon/core/controller/terminal"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/cors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
@@ -78,3 +82,83 @@ func (a *API) CreateShell(c *gin.Context) {
 	c.Request.URL.Path = fmt.Sprintf("/apis/core/v2/0/%s/websocket", sessionID)
 	sockJS.ServeHTTP(c.Writer, c.Request)
 }
+
+// checkOrigin allows the same origin, and the origins allowed by the cors middleware with credentials,
+// since the browsers send cookies in the websocket handshakes of any origin
+func checkOrigin(c *gin.Context) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get(cors.HeaderOrigin)
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+			return true
+		}
+		return c.Writer.Header().Get(cors.HeaderAccessControlAllowOrigin) == origin
+	}
+}
+
+// Exec opens an interactive shell to a container of the cluster's pod over websocket,
+// the messages are the same as the ones of CreateShell
+func (a *API) Exec(c *gin.Context) {
+	const op = "terminal: exec"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", clusterIDStr, err.Error())))
+		return
+	}
+	podName := c.Query(_podNameQuery)
+	if podName == "" {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("podName is required"))
+		return
+	}
+
+	var (
+		upgraded bool
+		session  *terminal.WebSocketSession
+	)
+	err = a.terminalCtl.Exec(c, uint(clusterID), &terminal.ExecRequest{
+		PodName:       podName,
+		ContainerName: c.Query(_containerNameQuery),
+		SourceIP:      c.ClientIP(),
+		RequestURI:    c.Request.URL.RequestURI(),
+	}, func() (terminal.PtyHandler, error) {
+		upgraded = true
+		upgrader := websocket.Upgrader{CheckOrigin: checkOrigin(c)}
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return nil, err
+		}
+		session = terminal.NewWebSocketSession(conn)
+		return session, nil
+	})
+	if session != nil {
+		if err != nil {
+			log.WithFiled(c, "op", op).Warningf("exec session closed: %v", err)
+		}
+		session.Close(err)
+		return
+	}
+	// the upgrader responds the failure itself
+	if upgraded {
+		log.WithFiled(c, "op", op).Warningf("failed to upgrade to websocket: %v", err)
+		return
+	}
+	if err != nil {
+		cause := perror.Cause(err)
+		if e, ok := cause.(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.ClusterInDB || e.Source == herrors.PodsInK8S {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		}
+		if cause == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
@@ -31,6 +31,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/shell", _clusterIDParam),
 			HandlerFunc: api.CreateShell,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/exec/websocket", _clusterIDParam),
+			HandlerFunc: api.Exec,
 		},
 	}
 	route.RegisterRoutes(coreGroup, coreRoutes)
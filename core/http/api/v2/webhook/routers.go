@@ -34,9 +34,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 	group := engine.Group("/apis/core/v2")
 	var routers = route.Routes{
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/:%v/:%v/webhooks", _resourceTypeParam, _resourceIDParam),
-			HandlerFunc: api.CreateWebhook,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/:%v/:%v/webhooks", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc:   api.CreateWebhook,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -44,9 +45,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.ListWebhooks,
 		},
 		{
-			Method:      http.MethodPut,
-			Pattern:     fmt.Sprintf("/webhooks/:%v", _webhookIDParam),
-			HandlerFunc: api.UpdateWebhook,
+			Method:        http.MethodPut,
+			Pattern:       fmt.Sprintf("/webhooks/:%v", _webhookIDParam),
+			HandlerFunc:   api.UpdateWebhook,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -54,9 +56,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.GetWebhook,
 		},
 		{
-			Method:      http.MethodDelete,
-			Pattern:     fmt.Sprintf("/webhooks/:%v", _webhookIDParam),
-			HandlerFunc: api.DeleteWebhook,
+			Method:        http.MethodDelete,
+			Pattern:       fmt.Sprintf("/webhooks/:%v", _webhookIDParam),
+			HandlerFunc:   api.DeleteWebhook,
+			Transactional: true,
 		},
 		{
 			Method:      http.MethodGet,
@@ -69,9 +72,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			HandlerFunc: api.GetWebhookLog,
 		},
 		{
-			Method:      http.MethodPost,
-			Pattern:     fmt.Sprintf("/webhooklogs/:%v/resend", _webhookLogIDParam),
-			HandlerFunc: api.ResendWebhook,
+			Method:        http.MethodPost,
+			Pattern:       fmt.Sprintf("/webhooklogs/:%v/resend", _webhookLogIDParam),
+			HandlerFunc:   api.ResendWebhook,
+			Transactional: true,
 		},
 	}
 	route.RegisterRoutes(group, routers)
@@ -0,0 +1,134 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	PathPrefix = "/debug"
+	// _recentPauses is the number of the latest gc pauses reported
+	_recentPauses = 16
+)
+
+// Handler serves pprof, goroutine dumps and gc stats under /debug
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathPrefix+"/pprof/", pprof.Index)
+	mux.HandleFunc(PathPrefix+"/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc(PathPrefix+"/pprof/profile", pprof.Profile)
+	mux.HandleFunc(PathPrefix+"/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc(PathPrefix+"/pprof/trace", pprof.Trace)
+	mux.HandleFunc(PathPrefix+"/goroutines", goroutines)
+	mux.HandleFunc(PathPrefix+"/gcstats", gcStats)
+	return mux
+}
+
+// RegisterRoutes serves the handler on engine, only admins are allowed
+func RegisterRoutes(engine *gin.Engine) {
+	api := engine.Group(PathPrefix, adminOnly)
+
+	handler := gin.WrapH(Handler())
+	var routes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/*path",
+			HandlerFunc: handler,
+		},
+		{
+			// pprof looks up symbols by POST as well
+			Method:      http.MethodPost,
+			Pattern:     "/*path",
+			HandlerFunc: handler,
+		},
+	}
+	route.RegisterRoutes(api, routes)
+}
+
+func adminOnly(c *gin.Context) {
+	user, err := common.UserFromContext(c)
+	if err != nil {
+		response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+		return
+	}
+	if !user.IsAdmin() {
+		response.AbortWithForbiddenError(c, common.Forbidden, "only admins are allowed to debug the server")
+		return
+	}
+	c.Next()
+}
+
+// goroutines dumps the stacks of all goroutines in the format of panics
+func goroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// GCStats are the runtime stats of memory and gc, durations are in nanoseconds
+type GCStats struct {
+	NumGoroutine int           `json:"numGoroutine"`
+	NumGC        int64         `json:"numGC"`
+	LastGC       time.Time     `json:"lastGC"`
+	PauseTotal   time.Duration `json:"pauseTotal"`
+	// RecentPauses are the latest pauses, the most recent first
+	RecentPauses []time.Duration `json:"recentPauses"`
+	HeapAlloc    uint64          `json:"heapAlloc"`
+	HeapInuse    uint64          `json:"heapInuse"`
+	HeapObjects  uint64          `json:"heapObjects"`
+	NextGC       uint64          `json:"nextGC"`
+	Sys          uint64          `json:"sys"`
+}
+
+func readGCStats() *GCStats {
+	stats := &debug.GCStats{}
+	debug.ReadGCStats(stats)
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	pauses := stats.Pause
+	if len(pauses) > _recentPauses {
+		pauses = pauses[:_recentPauses]
+	}
+	return &GCStats{
+		NumGoroutine: runtime.NumGoroutine(),
+		NumGC:        stats.NumGC,
+		LastGC:       stats.LastGC,
+		PauseTotal:   stats.PauseTotal,
+		RecentPauses: pauses,
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapObjects:  mem.HeapObjects,
+		NextGC:       mem.NextGC,
+		Sys:          mem.Sys,
+	}
+}
+
+func gcStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(readGCStats())
+}
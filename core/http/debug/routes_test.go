@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+)
+
+func newEngine(user userauth.User) *gin.Engine {
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		if user != nil {
+			common.SetUser(c, user)
+		}
+	})
+	RegisterRoutes(engine)
+	return engine
+}
+
+func TestRoutes(t *testing.T) {
+	get := func(engine *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get(newEngine(nil), "/debug/gcstats")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = get(newEngine(&userauth.DefaultInfo{ID: 2}), "/debug/gcstats")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	admin := newEngine(&userauth.DefaultInfo{ID: 1, Admin: true})
+	w = get(admin, "/debug/gcstats")
+	assert.Equal(t, http.StatusOK, w.Code)
+	stats := &GCStats{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), stats))
+	assert.True(t, stats.NumGoroutine > 0)
+	assert.True(t, stats.Sys > 0)
+
+	w = get(admin, "/debug/goroutines")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), "goroutine "))
+
+	w = get(admin, "/debug/pprof/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), "heap"))
+}
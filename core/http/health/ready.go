@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Component is a dependency the server can not serve without
+type Component struct {
+	Name string
+	// Check returns an error if the component is unavailable, it should return once ctx is done
+	Check func(ctx context.Context) error
+}
+
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency"`
+}
+
+type Readiness struct {
+	Ready      bool               `json:"ready"`
+	Components []*ComponentStatus `json:"components"`
+}
+
+// check checks the components concurrently, each of them fails if it's not done within the timeout
+func check(ctx context.Context, components []Component, timeout time.Duration) *Readiness {
+	statuses := make([]*ComponentStatus, len(components))
+	var wg sync.WaitGroup
+	for i, component := range components {
+		wg.Add(1)
+		go func(i int, component Component) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- component.Check(checkCtx)
+			}()
+			var err error
+			select {
+			case err = <-errCh:
+			case <-checkCtx.Done():
+				// the check may not respect ctx, it's left behind
+				err = checkCtx.Err()
+			}
+
+			status := &ComponentStatus{
+				Name:    component.Name,
+				Status:  StatusOK,
+				Latency: time.Since(start).Round(time.Millisecond).String(),
+			}
+			if err != nil {
+				status.Status = StatusFailed
+				status.Error = err.Error()
+			}
+			statuses[i] = status
+		}(i, component)
+	}
+	wg.Wait()
+
+	readiness := &Readiness{Ready: true, Components: statuses}
+	for _, status := range statuses {
+		if status.Status != StatusOK {
+			readiness.Ready = false
+		}
+	}
+	return readiness
+}
+
+// RegisterReadinessRoutes serves /ready, which responds 503 if any of the components is unavailable,
+// so that no traffic is routed to the server until they recover
+func RegisterReadinessRoutes(engine *gin.Engine, timeout time.Duration, components ...Component) {
+	api := engine.Group("/ready")
+
+	var routes = route.Routes{
+		{
+			Method: http.MethodGet,
+			HandlerFunc: func(c *gin.Context) {
+				readiness := check(c.Request.Context(), components, timeout)
+				if !readiness.Ready {
+					c.JSON(http.StatusServiceUnavailable, response.NewResponseWithData(readiness))
+					return
+				}
+				response.SuccessWithData(c, readiness)
+			},
+		},
+	}
+	route.RegisterRoutes(api, routes)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	ok := Component{Name: "mysql", Check: func(ctx context.Context) error { return nil }}
+	failed := Component{Name: "gitlab", Check: func(ctx context.Context) error { return errors.New("unreachable") }}
+	// hangs regardless of ctx
+	hanging := Component{Name: "argocd", Check: func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}}
+
+	get := func(components ...Component) (int, *Readiness) {
+		r := gin.New()
+		RegisterReadinessRoutes(r, 50*time.Millisecond, components...)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		resp := struct {
+			Data *Readiness `json:"data"`
+		}{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp.Data
+	}
+
+	code, readiness := get(ok)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, readiness.Ready)
+	assert.Equal(t, StatusOK, readiness.Components[0].Status)
+
+	start := time.Now()
+	code, readiness = get(ok, failed, hanging)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, "mysql", readiness.Components[0].Name)
+	assert.Equal(t, StatusOK, readiness.Components[0].Status)
+	assert.Equal(t, StatusFailed, readiness.Components[1].Status)
+	assert.Equal(t, "unreachable", readiness.Components[1].Error)
+	assert.Equal(t, StatusFailed, readiness.Components[2].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), readiness.Components[2].Error)
+}
@@ -0,0 +1,277 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openapi builds the OpenAPI 3 document of the routes registered in the engine,
+// so that clients can be generated from the server running.
+// Only methods and paths are known from the routes, parameters in query and schemas of bodies are not documented,
+// see the documents in openapi/ for them.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/middleware/problem"
+	"github.com/horizoncd/horizon/pkg/server/response"
+)
+
+const (
+	Version         = "3.0.3"
+	_schemaResponse = "Response"
+	_schemaProblem  = "Problem"
+	_securityBearer = "bearer"
+)
+
+var (
+	// _apiPath matches the paths of apis, such as /apis/core/v2/clusters/:clusterID
+	_apiPath = regexp.MustCompile("^/apis/([a-z]+)/(v[0-9]+)/")
+	// _closure matches the names of anonymous functions, such as func1
+	_closure = regexp.MustCompile(`^func[0-9]+$`)
+)
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps the lower case methods to the operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Content map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+func ref(schema string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + schema}
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// Build builds the document of the routes of apis, the other routes such as /health are left out
+func Build(routes gin.RoutesInfo) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   "Horizon",
+			Version: "v2",
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				_schemaResponse: {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"data":      {Description: "data of the api, whose schema differs"},
+						"requestID": {Type: "string"},
+					},
+				},
+				_schemaProblem: {
+					Type:        "object",
+					Description: "problem details of RFC 7807, errorCode and errorMessage are the same as the ones of api v1",
+					Properties: map[string]*Schema{
+						"type":         {Type: "string"},
+						"title":        {Type: "string"},
+						"status":       {Type: "integer"},
+						"detail":       {Type: "string"},
+						"instance":     {Type: "string"},
+						"errorCode":    {Type: "string"},
+						"errorMessage": {Type: "string"},
+						"requestID":    {Type: "string"},
+					},
+				},
+			},
+			SecuritySchemes: map[string]SecurityScheme{
+				_securityBearer: {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []map[string][]string{{_securityBearer: {}}},
+	}
+
+	// routes are sorted to keep the suffixes of duplicated operation ids stable
+	sorted := make(gin.RoutesInfo, len(routes))
+	copy(sorted, routes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Method < sorted[j].Method
+	})
+
+	operationIDs := make(map[string]int)
+	for _, route := range sorted {
+		if !strings.HasPrefix(route.Path, "/apis/") || route.Path == SpecPath || route.Path == DocsPath {
+			continue
+		}
+		path, parameters := convertPath(route.Path)
+		operation := &Operation{
+			Parameters: parameters,
+			Responses: map[string]*Response{
+				"200": {Description: "OK", Content: jsonContent(ref(_schemaResponse))},
+			},
+		}
+		tag, name := handlerName(route.Handler)
+		if tag != "" {
+			operation.Tags = []string{tag}
+		}
+
+		id := operationID(route.Path, tag, name, route.Method)
+		operationIDs[id]++
+		if n := operationIDs[id]; n > 1 {
+			id = fmt.Sprintf("%s%d", id, n)
+		}
+		operation.OperationID = id
+
+		switch route.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			operation.RequestBody = &RequestBody{Content: jsonContent(&Schema{Type: "object"})}
+		}
+		errorContent := jsonContent(ref(_schemaResponse))
+		if problem.V2Paths.MatchString(route.Path) {
+			errorContent = map[string]MediaType{response.ContentTypeProblemJSON: {Schema: ref(_schemaProblem)}}
+		}
+		operation.Responses["default"] = &Response{Description: "error", Content: errorContent}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+	return doc
+}
+
+// convertPath converts the path of gin to the one of OpenAPI, such as /clusters/:clusterID to /clusters/{clusterID}
+func convertPath(path string) (string, []Parameter) {
+	var parameters []Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		parameters = append(parameters, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// handlerName returns the package and the name of the handler,
+// such as cluster and Get for github.com/horizoncd/horizon/core/http/api/v2/cluster.(*API).Get-fm
+func handlerName(handler string) (string, string) {
+	handler = strings.TrimSuffix(handler, "-fm")
+	handler = handler[strings.LastIndex(handler, "/")+1:]
+	parts := strings.Split(handler, ".")
+	if len(parts) < 2 {
+		return "", ""
+	}
+	name := parts[len(parts)-1]
+	if _closure.MatchString(name) {
+		name = ""
+	}
+	return parts[0], name
+}
+
+// operationID makes up the id of the operation, such as coreV2ClusterGet,
+// the method and the path are used if the handler is anonymous
+func operationID(path, tag, name, method string) string {
+	prefix := ""
+	if matches := _apiPath.FindStringSubmatch(path); matches != nil {
+		prefix = matches[1] + upperFirst(matches[2])
+	}
+	if name == "" {
+		words := []string{strings.ToLower(method)}
+		for _, segment := range strings.Split(strings.TrimPrefix(path, "/apis/"), "/") {
+			segment = strings.TrimLeft(segment, ":*")
+			for _, word := range strings.FieldsFunc(segment, func(r rune) bool {
+				return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+			}) {
+				words = append(words, upperFirst(word))
+			}
+		}
+		return strings.Join(words, "")
+	}
+	if prefix == "" {
+		return tag + upperFirst(name)
+	}
+	return prefix + upperFirst(tag) + upperFirst(name)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,75 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/server/response"
+)
+
+type API struct{}
+
+func (a *API) Get(c *gin.Context) {}
+
+func TestBuild(t *testing.T) {
+	a := &API{}
+	r := gin.New()
+	r.GET("/health", func(c *gin.Context) {})
+	r.GET("/apis/core/v1/clusters/:clusterID", a.Get)
+	r.GET("/apis/core/v2/clusters/:clusterID", a.Get)
+	r.GET("/apis/front/v2/clusters/:clusterID", a.Get)
+	r.POST("/apis/core/v2/clusters/:clusterID/builddeploy", func(c *gin.Context) {})
+	RegisterRoutes(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, SpecPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	doc := &Document{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), doc))
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Len(t, doc.Paths, 4)
+	assert.NotContains(t, doc.Paths, "/health")
+	assert.NotContains(t, doc.Paths, SpecPath)
+
+	get := doc.Paths["/apis/core/v2/clusters/{clusterID}"]["get"]
+	assert.Equal(t, "coreV2OpenapiGet", get.OperationID)
+	assert.Equal(t, []string{"openapi"}, get.Tags)
+	assert.Equal(t, []Parameter{{Name: "clusterID", In: "path", Required: true, Schema: &Schema{Type: "string"}}},
+		get.Parameters)
+	assert.Nil(t, get.RequestBody)
+	assert.Contains(t, get.Responses["default"].Content, response.ContentTypeProblemJSON)
+	assert.Equal(t, "coreV1OpenapiGet", doc.Paths["/apis/core/v1/clusters/{clusterID}"]["get"].OperationID)
+	assert.Contains(t, doc.Paths["/apis/core/v1/clusters/{clusterID}"]["get"].Responses["default"].Content,
+		"application/json")
+	assert.Equal(t, "frontV2OpenapiGet", doc.Paths["/apis/front/v2/clusters/{clusterID}"]["get"].OperationID)
+
+	// anonymous handlers are named by the method and the path
+	post := doc.Paths["/apis/core/v2/clusters/{clusterID}/builddeploy"]["post"]
+	assert.Equal(t, "postCoreV2ClustersClusterIDBuilddeploy", post.OperationID)
+	assert.NotNil(t, post.RequestBody)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DocsPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), SpecPath)
+}
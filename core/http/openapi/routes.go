@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	SpecPath = "/apis/swagger.json"
+	DocsPath = "/apis/docs"
+
+	_swaggerUIVersion = "4.15.5"
+)
+
+// _docsHTML is the page of swagger ui rendering the document
+var _docsHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8"/>
+  <title>Horizon API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + _swaggerUIVersion + `/swagger-ui.css"/>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@` + _swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+<script>
+  window.ui = SwaggerUIBundle({url: "` + SpecPath + `", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
+
+// RegisterRoutes serves the document and the page of it,
+// the document is built once requested, it should be registered before the engine serves
+func RegisterRoutes(engine *gin.Engine) {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	api := engine.Group("/apis")
+
+	var routes = route.Routes{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/swagger.json",
+			HandlerFunc: func(c *gin.Context) {
+				once.Do(func() {
+					doc = Build(engine.Routes())
+				})
+				c.JSON(http.StatusOK, doc)
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/docs",
+			HandlerFunc: func(c *gin.Context) {
+				c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(_docsHTML))
+			},
+		},
+	}
+	route.RegisterRoutes(api, routes)
+}
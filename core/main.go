@@ -15,7 +15,7 @@
 package main
 
 import (
-	_ "net/http/pprof"
+	"os"
 
 	"github.com/horizoncd/horizon/core/cmd"
 
@@ -39,5 +39,17 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == cmd.DoctorCommand {
+		os.Exit(cmd.RunDoctor(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == cmd.BootstrapCommand {
+		os.Exit(cmd.RunBootstrap(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == cmd.AgentCommand {
+		os.Exit(cmd.RunAgent(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == cmd.MigrateCommand {
+		os.Exit(cmd.RunMigrate(os.Args[2:]))
+	}
 	cmd.Run(cmd.ParseFlags())
 }
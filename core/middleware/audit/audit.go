@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/auditlog/manager"
+	"github.com/horizoncd/horizon/pkg/auditlog/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Middleware records every mutating request into the audit log after it's handled,
+// including the ones denied or failed. It must be used after the user and prehandle middlewares,
+// which attach the user and the resource of the request.
+func Middleware(mgr manager.Manager, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		digest := ""
+		if c.Request.Body != nil {
+			body, err := ioutil.ReadAll(c.Request.Body)
+			if err != nil {
+				response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
+				return
+			}
+			sum := sha256.Sum256(body)
+			digest = hex.EncodeToString(sum[:])
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+
+		auditLog := &models.AuditLog{
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			RequestDigest: digest,
+			StatusCode:    c.Writer.Status(),
+			LatencyMs:     time.Since(start).Milliseconds(),
+			SourceIP:      c.ClientIP(),
+		}
+		if currentUser, err := common.UserFromContext(c); err == nil {
+			auditLog.UserID = currentUser.GetID()
+			auditLog.UserName = currentUser.GetName()
+			auditLog.RobotID = currentUser.GetRobotID()
+		}
+		if record, ok := c.Get(common.ContextAuthRecord); ok {
+			if authRecord, ok := record.(auth.AttributesRecord); ok {
+				auditLog.ResourceType = authRecord.Resource
+				auditLog.ResourceName = authRecord.Name
+				auditLog.SubResource = authRecord.SubResource
+			}
+		}
+		if rid, err := requestid.FromContext(c); err == nil {
+			auditLog.RequestID = rid
+		}
+		// the request has been served, a failure is only logged
+		if err := mgr.Create(c, auditLog); err != nil {
+			log.Warningf(c, "record audit log error, method = %s, path = %s, err = %v",
+				auditLog.Method, auditLog.Path, err)
+		}
+	}, skippers...)
+}
@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auditlog/manager"
+	"github.com/horizoncd/horizon/pkg/auditlog/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/server/response"
+)
+
+func TestMiddleware(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	// a temporary sqlite database is private to its connection
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	assert.Nil(t, db.AutoMigrate(&models.AuditLog{}))
+	mgr := manager.New(db)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		// attached by the user and prehandle middlewares
+		common.SetUser(c, &userauth.DefaultInfo{ID: 1, Name: "ci", RobotID: 2})
+		c.Set(common.ContextAuthRecord, auth.AttributesRecord{
+			Resource:    "clusters",
+			Name:        "1",
+			SubResource: "builddeploy",
+		})
+		c.Next()
+	}, Middleware(mgr))
+	r.POST("/apis/core/v2/clusters/1/builddeploy", func(c *gin.Context) {
+		// the body is still readable by the handler
+		body, err := ioutil.ReadAll(c.Request.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"title":"deploy"}`, string(body))
+		response.AbortWithForbiddenError(c, common.Forbidden, "denied")
+	})
+	r.GET("/apis/core/v2/clusters/1", func(c *gin.Context) {
+		response.Success(c)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apis/core/v2/clusters/1/builddeploy",
+		strings.NewReader(`{"title":"deploy"}`)))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/apis/core/v2/clusters/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// reads are not recorded
+	auditLogs, total, err := mgr.List(context.Background(), q.New(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	sum := sha256.Sum256([]byte(`{"title":"deploy"}`))
+	auditLog := auditLogs[0]
+	assert.Equal(t, uint(1), auditLog.UserID)
+	assert.Equal(t, "ci", auditLog.UserName)
+	assert.Equal(t, uint(2), auditLog.RobotID)
+	assert.Equal(t, http.MethodPost, auditLog.Method)
+	assert.Equal(t, "/apis/core/v2/clusters/1/builddeploy", auditLog.Path)
+	assert.Equal(t, "clusters", auditLog.ResourceType)
+	assert.Equal(t, "1", auditLog.ResourceName)
+	assert.Equal(t, "builddeploy", auditLog.SubResource)
+	assert.Equal(t, hex.EncodeToString(sum[:]), auditLog.RequestDigest)
+	assert.Equal(t, http.StatusForbidden, auditLog.StatusCode)
+}
@@ -0,0 +1,152 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/middleware"
+	corsconfig "github.com/horizoncd/horizon/pkg/config/cors"
+)
+
+const (
+	HeaderOrigin                        = "Origin"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+type policy struct {
+	anyOrigin bool
+	origins   map[string]bool
+	// prefixes and suffixes of the subdomain wildcards, such as https:// and .example.com for https://*.example.com
+	wildcards [][2]string
+
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+func newPolicy(config corsconfig.Config) *policy {
+	p := &policy{
+		origins:     make(map[string]bool),
+		methods:     strings.Join(config.AllowedMethods, ", "),
+		headers:     strings.Join(config.AllowedHeaders, ", "),
+		exposed:     strings.Join(config.ExposedHeaders, ", "),
+		credentials: config.AllowCredentials,
+	}
+	if config.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			i := strings.Index(origin, "*")
+			p.wildcards = append(p.wildcards, [2]string{origin[:i], origin[i+1:]})
+		default:
+			p.origins[origin] = true
+		}
+	}
+	return p
+}
+
+func (p *policy) allowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, wildcard := range p.wildcards {
+		if strings.HasPrefix(origin, wildcard[0]) && strings.HasSuffix(origin, wildcard[1]) &&
+			len(origin) > len(wildcard[0])+len(wildcard[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware answers the preflight requests, and allows the origins configured to read the responses.
+// Requests of other origins are served without the CORS headers, so that the browsers block the responses.
+// It must be used before the middlewares authenticating requests, since preflight requests carry no credentials.
+// Nothing is done if no origin is allowed.
+func Middleware(config corsconfig.Config, skippers ...middleware.Skipper) gin.HandlerFunc {
+	if !config.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	p := newPolicy(config)
+	return middleware.New(func(c *gin.Context) {
+		origin := c.GetHeader(HeaderOrigin)
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader(HeaderAccessControlRequestMethod) != ""
+
+		// the responses differ by the origins, which must not be shared by caches
+		c.Writer.Header().Add("Vary", HeaderOrigin)
+		if !p.allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if p.anyOrigin && !p.credentials {
+			c.Header(HeaderAccessControlAllowOrigin, "*")
+		} else {
+			c.Header(HeaderAccessControlAllowOrigin, origin)
+		}
+		if p.credentials {
+			c.Header(HeaderAccessControlAllowCredentials, "true")
+		}
+		if !preflight {
+			if p.exposed != "" {
+				c.Header(HeaderAccessControlExposeHeaders, p.exposed)
+			}
+			c.Next()
+			return
+		}
+
+		if p.methods != "" {
+			c.Header(HeaderAccessControlAllowMethods, p.methods)
+		}
+		if p.headers != "" {
+			c.Header(HeaderAccessControlAllowHeaders, p.headers)
+		}
+		if p.maxAge != "" {
+			c.Header(HeaderAccessControlMaxAge, p.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}, skippers...)
+}
@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	corsconfig "github.com/horizoncd/horizon/pkg/config/cors"
+)
+
+func TestMiddleware(t *testing.T) {
+	newRouter := func(config corsconfig.Config) *gin.Engine {
+		r := gin.New()
+		r.Use(Middleware(config))
+		r.GET("/apis/core/v2/clusters", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+	request := func(r *gin.Engine, method, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/apis/core/v2/clusters", nil)
+		if origin != "" {
+			req.Header.Set(HeaderOrigin, origin)
+		}
+		if preflight {
+			req.Header.Set(HeaderAccessControlRequestMethod, http.MethodGet)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	r := newRouter(corsconfig.Config{
+		AllowedOrigins:   []string{"https://console.example.com", "https://*.horizon.io"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Content-Type"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	// preflight
+	w := request(r, http.MethodOptions, "https://console.example.com", true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://console.example.com", w.Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET, POST", w.Header().Get(HeaderAccessControlAllowMethods))
+	assert.Equal(t, "Content-Type", w.Header().Get(HeaderAccessControlAllowHeaders))
+	assert.Equal(t, "true", w.Header().Get(HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "600", w.Header().Get(HeaderAccessControlMaxAge))
+
+	// actual request of a subdomain
+	w = request(r, http.MethodGet, "https://dev.horizon.io", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://dev.horizon.io", w.Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "X-Request-ID", w.Header().Get(HeaderAccessControlExposeHeaders))
+	assert.Equal(t, HeaderOrigin, w.Header().Get("Vary"))
+
+	// origins not allowed
+	w = request(r, http.MethodOptions, "https://evil.com", true)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(r, http.MethodGet, "https://horizon.io.evil.com", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderAccessControlAllowOrigin))
+
+	// same origin requests
+	w = request(r, http.MethodGet, "", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderAccessControlAllowOrigin))
+
+	// any origin
+	r = newRouter(corsconfig.Config{AllowedOrigins: []string{"*"}})
+	w = request(r, http.MethodGet, "https://other.com", false)
+	assert.Equal(t, "*", w.Header().Get(HeaderAccessControlAllowOrigin))
+
+	// disabled
+	r = newRouter(corsconfig.Config{})
+	w = request(r, http.MethodOptions, "https://console.example.com", true)
+	assert.Empty(t, w.Header().Get(HeaderAccessControlAllowOrigin))
+}
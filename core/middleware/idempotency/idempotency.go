@@ -0,0 +1,162 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed tells the response is replayed from the one of the first request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// TTL is how long the responses are kept for retries
+	TTL = 24 * time.Hour
+	// _processingTTL releases the keys of the requests whose servers died while serving them
+	_processingTTL = 5 * time.Minute
+	_maxKeyLength  = 255
+)
+
+// Record is the request of an idempotency key, and its response once completed
+type Record struct {
+	// Fingerprint identifies the request, a key can not be reused by other requests
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store keeps the records of the idempotency keys
+type Store interface {
+	// Reserve saves the record if the key is absent and returns nil, or returns the record of the key
+	Reserve(key string, record *Record, ttl time.Duration) (*Record, error)
+	Save(key string, record *Record, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// recorder keeps a copy of the response written
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Middleware makes the POST requests matching paths idempotent by the Idempotency-Key header.
+// The response of the first request is kept for TTL, and replayed to the requests retried with the same key,
+// while a request retried before the first one completes is rejected with 409.
+// Responses of server errors are not kept, so that the requests can be retried.
+// Keys are scoped by users, it must be used after the user middleware and the auth middleware,
+// so that the requests denied are not kept.
+func Middleware(store Store, paths *regexp.Regexp, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(HeaderIdempotencyKey)
+		if idempotencyKey == "" || c.Request.Method != http.MethodPost || !paths.MatchString(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > _maxKeyLength {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf(
+				"%s must not exceed %d characters", HeaderIdempotencyKey, _maxKeyLength))
+			return
+		}
+		currentUser, err := common.UserFromContext(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+		key := fmt.Sprintf("%d:%s", currentUser.GetID(), idempotencyKey)
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = ioutil.ReadAll(c.Request.Body)
+			if err != nil {
+				response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
+				return
+			}
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.RequestURI()+"\n"), body...))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		record, err := store.Reserve(key, &Record{Fingerprint: fingerprint}, _processingTTL)
+		if err != nil {
+			// the same as the requests without keys
+			log.Warningf(c, "failed to reserve idempotency key %s, the request is served: %v", key, err)
+			c.Next()
+			return
+		}
+		if record != nil {
+			switch {
+			case record.Fingerprint != fingerprint:
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf(
+					"%s %s has been used by another request", HeaderIdempotencyKey, idempotencyKey))
+			case !record.Completed:
+				response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsgf(
+					"the request of %s %s is being processed, retry later", HeaderIdempotencyKey, idempotencyKey))
+			default:
+				c.Header(HeaderIdempotentReplayed, "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		rec := &recorder{ResponseWriter: c.Writer}
+		c.Writer = rec
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			if err := store.Delete(key); err != nil {
+				log.Warningf(c, "failed to release idempotency key %s: %v", key, err)
+			}
+			return
+		}
+		if err := store.Save(key, &Record{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  c.Writer.Status(),
+			ContentType: c.Writer.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		}, TTL); err != nil {
+			log.Warningf(c, "failed to save the response of idempotency key %s: %v", key, err)
+		}
+	}, skippers...)
+}
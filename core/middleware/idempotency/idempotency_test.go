@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+)
+
+func TestMiddleware(t *testing.T) {
+	store := NewMemoryStore()
+	created := 0
+	failed := true
+	block := make(chan struct{})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		// attached by the user middleware
+		common.SetUser(c, &userauth.DefaultInfo{ID: 1, Name: "tony"})
+		c.Next()
+	}, Middleware(store, regexp.MustCompile("^/apis/core/v2/applications/[^/]+/clusters$")))
+	r.POST("/apis/core/v2/applications/:applicationID/clusters", func(c *gin.Context) {
+		if c.Query("block") != "" {
+			<-block
+		}
+		if c.Query("fail") != "" && failed {
+			failed = false
+			response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg("gitlab is unavailable"))
+			return
+		}
+		created++
+		response.SuccessWithData(c, created)
+	})
+
+	post := func(path, key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// retries with the same key are replayed
+	w := post("/apis/core/v2/applications/1/clusters", "k1", `{"name":"app-dev"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"data":1}`, w.Body.String())
+	assert.Equal(t, "", w.Header().Get(HeaderIdempotentReplayed))
+	w = post("/apis/core/v2/applications/1/clusters", "k1", `{"name":"app-dev"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"data":1}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, 1, created)
+
+	// the key can not be reused by another request
+	w = post("/apis/core/v2/applications/1/clusters", "k1", `{"name":"app-test"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 1, created)
+
+	// requests without keys are not idempotent
+	post("/apis/core/v2/applications/1/clusters", "", `{"name":"app-dev"}`)
+	post("/apis/core/v2/applications/1/clusters", "", `{"name":"app-dev"}`)
+	assert.Equal(t, 3, created)
+
+	// server errors are not kept
+	w = post("/apis/core/v2/applications/1/clusters?fail=true", "k2", `{"name":"app-dev"}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	w = post("/apis/core/v2/applications/1/clusters?fail=true", "k2", `{"name":"app-dev"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 4, created)
+
+	// retries before the first request completes are rejected
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- post("/apis/core/v2/applications/1/clusters?block=true", "k3", `{"name":"app-dev"}`)
+	}()
+	reserved := func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		_, ok := store.records["1:k3"]
+		return ok
+	}
+	for !reserved() {
+		time.Sleep(time.Millisecond)
+	}
+	w = post("/apis/core/v2/applications/1/clusters?block=true", "k3", `{"name":"app-dev"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	close(block)
+	assert.Equal(t, http.StatusOK, (<-done).Code)
+	assert.Equal(t, 5, created)
+}
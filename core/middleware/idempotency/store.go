@@ -0,0 +1,134 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const _redisTimeout = time.Second
+
+// RedisStore keeps the records in redis, so that the servers share them
+type RedisStore struct {
+	client *redis.Client
+	// prefix of the keys in redis
+	prefix string
+}
+
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Reserve(key string, record *Record, ttl time.Duration) (*Record, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), _redisTimeout)
+	defer cancel()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	// the record may expire between SETNX and GET, it's reserved again then
+	for i := 0; i < 2; i++ {
+		ok, err := s.client.SetNX(ctx, s.prefix+key, data, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+		existing, err := s.client.Get(ctx, s.prefix+key).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		r := &Record{}
+		if err := json.Unmarshal(existing, r); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	return nil, redis.Nil
+}
+
+func (s *RedisStore) Save(key string, record *Record, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), _redisTimeout)
+	defer cancel()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, data, ttl).Err()
+}
+
+func (s *RedisStore) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), _redisTimeout)
+	defer cancel()
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+type memoryRecord struct {
+	record   *Record
+	expireAt time.Time
+}
+
+// MemoryStore keeps the records in memory, which are not shared by the servers
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*memoryRecord)}
+}
+
+func (s *MemoryStore) Reserve(key string, record *Record, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	// expired records are dropped once reserving, which keeps the map bounded by the keys alive
+	for k, r := range s.records {
+		if now.After(r.expireAt) {
+			delete(s.records, k)
+		}
+	}
+	if existing, ok := s.records[key]; ok {
+		return existing.record, nil
+	}
+	s.records[key] = &memoryRecord{record: record, expireAt: now.Add(ttl)}
+	return nil, nil
+}
+
+func (s *MemoryStore) Save(key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = &memoryRecord{record: record, expireAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
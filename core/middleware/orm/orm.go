@@ -0,0 +1,149 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	middleware "github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// bufferedWriter holds the response until the transaction is finished,
+// so that the client never sees a success of what fails to be committed
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// Flush is deferred until the response is released
+func (w *bufferedWriter) Flush() {}
+
+// release writes the response held to the client
+func (w *bufferedWriter) release() {
+	w.ResponseWriter.WriteHeader(w.status)
+	// the client may have gone, there is nothing more to do then
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// Middleware wraps a mutating request in a transaction, which is committed when the handler
+// succeeds and rolled back when the handler aborts with an error or panics.
+// The response is held until the transaction is committed, and 500 is responded if it fails to.
+// It's used by the routes opting in by route.Route.Transactional, see route.UseTransactionMiddleware.
+//
+// The transaction is attached to the request context. Goroutines spawned from the request must not use the
+// request context for db operations, since they may outlive the transaction or race on its connection;
+// they should use a detached context instead, and orm.AfterCommit to start after the commit.
+func Middleware(db *gorm.DB, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		tx, err := orm.BeginRequestTx(db)
+		if err != nil {
+			response.AbortWithInternalError(c, fmt.Sprintf("failed to begin transaction, err: %v", err))
+			return
+		}
+		c.Set(orm.RequestTxContextKey(), tx)
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+
+		defer func() {
+			if r := recover(); r != nil {
+				// the recovery responds with the writer of its own
+				c.Writer = writer.ResponseWriter
+				if err := tx.Rollback(); err != nil {
+					log.Errorf(c, "failed to rollback transaction, err: %v", err)
+				}
+				panic(r)
+			}
+		}()
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if c.IsAborted() || len(c.Errors) > 0 || writer.Status() >= http.StatusBadRequest {
+			if err := tx.Rollback(); err != nil {
+				log.Errorf(c, "failed to rollback transaction, err: %v", err)
+			}
+			writer.release()
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			log.Errorf(c, "failed to commit transaction, err: %v", err)
+			response.AbortWithInternalError(c, fmt.Sprintf("failed to commit transaction, err: %v", err))
+			return
+		}
+		writer.release()
+	}, skippers...)
+}
+
+// HeaderReadPrimary pins the queries of a read-only request to the primary,
+// clients set it to read what they have just written, since replicas lag behind
+const HeaderReadPrimary = "X-Horizon-Read-Primary"
+
+// ReplicaMiddleware lets read-only requests query the replicas, while mutating requests and requests
+// with HeaderReadPrimary are pinned to the primary so that the reads after their writes are consistent.
+func ReplicaMiddleware(skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if c.GetHeader(HeaderReadPrimary) == "" {
+				c.Next()
+				return
+			}
+		}
+		c.Set(orm.PrimaryContextKey(), true)
+		c.Next()
+	}, skippers...)
+}
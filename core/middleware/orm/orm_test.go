@@ -0,0 +1,146 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+type record struct {
+	ID   uint
+	Name string
+}
+
+func TestMiddleware(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	// a temporary sqlite database is private to its connection
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	callbacks.RegisterCustomCallbacks(db)
+	assert.Nil(t, db.AutoMigrate(&record{}))
+
+	r := gin.New()
+	r.Use(gin.Recovery(), Middleware(db))
+	var committed []string
+	r.POST("/ok", func(c *gin.Context) {
+		assert.Nil(t, db.WithContext(c).Create(&record{Name: "ok"}).Error)
+		orm.AfterCommit(c, func() { committed = append(committed, "ok") })
+		response.Success(c)
+	})
+	r.POST("/failed", func(c *gin.Context) {
+		assert.Nil(t, db.WithContext(c).Create(&record{Name: "failed"}).Error)
+		orm.AfterCommit(c, func() { committed = append(committed, "failed") })
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg("failed"))
+	})
+	r.POST("/commitfailed", func(c *gin.Context) {
+		assert.Nil(t, db.WithContext(c).Create(&record{Name: "commitfailed"}).Error)
+		// the transaction is finished behind the middleware, so that it fails to commit,
+		// and the success is never responded for what is not saved
+		tx, ok := orm.TxFromContext(c)
+		assert.True(t, ok)
+		assert.Nil(t, tx.Rollback().Error)
+		response.Success(c)
+	})
+	r.POST("/panic", func(c *gin.Context) {
+		assert.Nil(t, db.WithContext(c).Create(&record{Name: "panic"}).Error)
+		panic("panic")
+	})
+
+	for path, code := range map[string]int{
+		"/ok":           http.StatusOK,
+		"/failed":       http.StatusInternalServerError,
+		"/commitfailed": http.StatusInternalServerError,
+		"/panic":        http.StatusInternalServerError,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code)
+	}
+
+	var records []*record
+	assert.Nil(t, db.Find(&records).Error)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "ok", records[0].Name)
+	// callbacks of the requests rolled back never run
+	assert.Equal(t, []string{"ok"}, committed)
+}
+
+func TestReplicaMiddleware(t *testing.T) {
+	newDB := func(name string) *gorm.DB {
+		db, err := orm.NewSqliteDB("")
+		assert.Nil(t, err)
+		sqlDB, err := db.DB()
+		assert.Nil(t, err)
+		sqlDB.SetMaxOpenConns(1)
+		assert.Nil(t, db.AutoMigrate(&record{}))
+		assert.Nil(t, db.Create(&record{Name: name}).Error)
+		return db
+	}
+	db, replica := newDB("primary"), newDB("replica")
+	replicaDB, err := replica.DB()
+	assert.Nil(t, err)
+	orm.UseReplicas(db, replicaDB)
+
+	r := gin.New()
+	r.Use(ReplicaMiddleware())
+	handler := func(c *gin.Context) {
+		var rec record
+		assert.Nil(t, db.WithContext(c).First(&rec).Error)
+		response.SuccessWithData(c, rec.Name)
+	}
+	r.GET("/records", handler)
+	r.POST("/records", handler)
+
+	for _, tc := range []struct {
+		method  string
+		primary bool
+		expect  string
+	}{
+		{method: http.MethodGet, expect: "replica"},
+		{method: http.MethodGet, primary: true, expect: "primary"},
+		{method: http.MethodPost, expect: "primary"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tc.method, "/records", nil)
+		if tc.primary {
+			req.Header.Set(HeaderReadPrimary, "true")
+		}
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), tc.expect)
+	}
+
+	// transactions stay on the primary
+	assert.Nil(t, db.Transaction(func(tx *gorm.DB) error {
+		var rec record
+		assert.Nil(t, tx.First(&rec).Error)
+		assert.Equal(t, "primary", rec.Name)
+		return nil
+	}))
+}
@@ -0,0 +1,54 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package problem
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/pkg/server/response"
+)
+
+// V2Paths are the paths of the core api v2, whose errors can be problem details
+var V2Paths = regexp.MustCompile("^/apis/core/v2/")
+
+// Middleware enables problem details for the requests matching paths which accept application/problem+json,
+// the others keep the errors of Response, so that the existing clients are not broken.
+// It should be used before the middlewares aborting requests, so that their errors are problem details as well
+func Middleware(paths *regexp.Regexp, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		if paths.MatchString(c.Request.URL.Path) && acceptsProblemDetails(c.Request) {
+			response.EnableProblemDetails(c)
+		}
+		c.Next()
+	}, skippers...)
+}
+
+// acceptsProblemDetails checks whether application/problem+json is one of the media types of Accept
+func acceptsProblemDetails(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+			if strings.EqualFold(mediaType, response.ContentTypeProblemJSON) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+)
+
+func TestMiddleware(t *testing.T) {
+	r := gin.New()
+	r.Use(requestid.Middleware(), Middleware(V2Paths))
+	for _, path := range []string{"/apis/core/v1/apps", "/apis/core/v2/apps"} {
+		r.GET(path, func(c *gin.Context) {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg("app not found"))
+		})
+	}
+	r.GET("/apis/core/v2/users", func(c *gin.Context) {
+		response.AbortWithHorizonError(c, "test",
+			perror.Wrap(herrors.ErrNoPrivilege, "only admins are allowed"))
+	})
+	r.GET("/apis/core/v2/users/self", func(c *gin.Context) {
+		response.SuccessWithData(c, "self")
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(requestid.HeaderXRequestID, "rid")
+		req.Header.Set("Accept", "application/json, application/problem+json;q=0.9")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/apis/core/v1/apps")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	resp := response.Response{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, response.Response{ErrorCode: "NotFound", ErrorMessage: "app not found", RequestID: "rid"}, resp)
+
+	w = serve("/apis/core/v2/apps")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, response.ContentTypeProblemJSON, w.Header().Get("Content-Type"))
+	problem := response.Problem{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, response.Problem{
+		Type:         response.ProblemTypePrefix + "NotFound",
+		Title:        "Not Found",
+		Status:       http.StatusNotFound,
+		Detail:       "app not found",
+		Instance:     "/apis/core/v2/apps",
+		ErrorCode:    "NotFound",
+		ErrorMessage: "app not found",
+		RequestID:    "rid",
+	}, problem)
+
+	w = serve("/apis/core/v2/users")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	problem = response.Problem{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "AccessDeny", problem.ErrorCode)
+
+	w = serve("/apis/core/v2/users/self")
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp = response.Response{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, response.Response{Data: "self", RequestID: "rid"}, resp)
+
+	// the clients not accepting problem details keep the errors of Response
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/apis/core/v2/apps", nil)
+	req.Header.Set(requestid.HeaderXRequestID, "rid")
+	req.Header.Set("Accept", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	resp = response.Response{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, response.Response{ErrorCode: "NotFound", ErrorMessage: "app not found", RequestID: "rid"}, resp)
+}
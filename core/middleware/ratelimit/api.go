@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Class is the routes sharing the limits of each user and each source ip, such as deploys or searches
+type Class struct {
+	Name string
+	// Methods matched, all methods are matched if empty
+	Methods map[string]bool
+	// Path matched, all paths are matched if nil
+	Path *regexp.Regexp
+	User Allower
+	IP   Allower
+}
+
+func (c *Class) match(method, path string) bool {
+	if len(c.Methods) > 0 && !c.Methods[method] {
+		return false
+	}
+	return c.Path == nil || c.Path.MatchString(path)
+}
+
+// APIMiddleware limits the requests by the first class matching the route, requests of authenticated users
+// take tokens of both their users and source ips, the others take tokens of their source ips only.
+// Requests over the limits are rejected with 429. It must be used after the user middleware.
+func APIMiddleware(classes []*Class, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		var class *Class
+		for _, candidate := range classes {
+			if candidate.match(c.Request.Method, c.Request.URL.Path) {
+				class = candidate
+				break
+			}
+		}
+		if class == nil {
+			c.Next()
+			return
+		}
+
+		if currentUser, err := common.UserFromContext(c); err == nil {
+			if ok, wait := class.User.Allow(strconv.FormatUint(uint64(currentUser.GetID()), 10)); !ok {
+				log.Warningf(c, "request is rate limited by %s, user = %s", class.Name, currentUser.GetName())
+				abortTooManyRequests(c, wait)
+				return
+			}
+		}
+		if ok, wait := class.IP.Allow(c.ClientIP()); !ok {
+			log.Warningf(c, "request is rate limited by %s, ip = %s", class.Name, c.ClientIP())
+			abortTooManyRequests(c, wait)
+			return
+		}
+		c.Next()
+	}, skippers...)
+}
@@ -0,0 +1,146 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// sweepInterval is how often the buckets refilled are dropped, so that the buckets do not grow unbounded
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Allower decides whether the request of a key is allowed, and how long to wait if it's not
+type Allower interface {
+	Allow(key string) (bool, time.Duration)
+	// SetLimit changes the rate and the burst of all keys, zero rate disables the limit
+	SetLimit(rate float64, burst int)
+}
+
+// Limiter is a token bucket for each key in memory, which refills at the rate up to the burst.
+// No limit is applied if the rate is not set.
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetLimit changes the rate and the burst, the buckets are kept and refill at the new rate
+func (l *Limiter) SetLimit(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	l.burst = float64(burst)
+}
+
+// Allow takes a token from the bucket of key, and returns how long to wait for the next one if it is empty
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = l.refill(b, now)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	return math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+}
+
+// sweep drops the buckets refilled, which are the same as new ones
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Middleware limits the requests of each pair of oauth client and source ip by limiter, and the requests
+// of each client from all source ips by clientLimiter, requests over either limit are rejected with 429
+func Middleware(limiter, clientLimiter *Limiter, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		clientID := c.Query("client_id")
+		if clientID == "" {
+			clientID = c.PostForm("client_id")
+		}
+		key := fmt.Sprintf("%s/%s", clientID, c.ClientIP())
+		if ok, wait := limiter.Allow(key); !ok {
+			log.Warningf(c, "oauth request is rate limited, client = %s, ip = %s", clientID, c.ClientIP())
+			abortTooManyRequests(c, wait)
+			return
+		}
+		if ok, wait := clientLimiter.Allow(clientID); !ok {
+			log.Warningf(c, "oauth request is rate limited, client = %s", clientID)
+			abortTooManyRequests(c, wait)
+			return
+		}
+		c.Next()
+	}, skippers...)
+}
+
+// abortTooManyRequests rejects the request with 429, and tells the client when to retry by Retry-After
+func abortTooManyRequests(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	response.Abort(c, http.StatusTooManyRequests, common.TooManyRequests,
+		"too many requests, please try again later")
+}
@@ -0,0 +1,172 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	// the burst is allowed at once
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok)
+	}
+	ok, wait := l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// other keys are not affected
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+
+	// the bucket refills at the rate
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.False(t, ok)
+
+	// the buckets refilled are dropped
+	now = now.Add(2 * sweepInterval)
+	ok, _ = l.Allow("c")
+	assert.True(t, ok)
+	assert.Len(t, l.buckets, 1)
+
+	// the new limit applies to the buckets at once, the tokens left are capped by the new burst
+	l.SetLimit(1, 1)
+	ok, _ = l.Allow("c")
+	assert.True(t, ok)
+	ok, wait = l.Allow("c")
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+	l.SetLimit(0, 0)
+	ok, _ = l.Allow("c")
+	assert.True(t, ok)
+}
+
+func TestMiddleware(t *testing.T) {
+	r := gin.New()
+	assert.Nil(t, r.SetTrustedProxies(nil))
+	r.POST("/token", Middleware(NewLimiter(0.001, 1), NewLimiter(0.001, 2)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	post := func(clientID, ip, forwardedFor string) *httptest.ResponseRecorder {
+		form := url.Values{"client_id": {clientID}}
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = ip + ":12345"
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, post("app1", "10.0.0.1", "").Code)
+	w := post("app1", "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, post("app2", "10.0.0.1", "").Code)
+
+	// the client is limited across source ips
+	assert.Equal(t, http.StatusOK, post("app1", "10.0.0.2", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, post("app1", "10.0.0.3", "").Code)
+
+	// X-Forwarded-For of an untrusted peer does not change the source ip
+	assert.Equal(t, http.StatusTooManyRequests, post("app2", "10.0.0.1", "10.0.0.9").Code)
+
+	// no limit without rate
+	r = gin.New()
+	r.POST("/token", Middleware(NewLimiter(0, 0), NewLimiter(0, 0)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, post("app1", "10.0.0.1", "").Code)
+	}
+}
+
+func TestAPIMiddleware(t *testing.T) {
+	classes := []*Class{
+		{
+			Name:    "deploy",
+			Methods: map[string]bool{http.MethodPost: true},
+			Path:    regexp.MustCompile("^/clusters/[0-9]+/deploy$"),
+			User:    NewLimiter(0.001, 1),
+			IP:      NewLimiter(0, 0),
+		},
+		{
+			Name: "default",
+			User: NewLimiter(0, 0),
+			IP:   NewLimiter(0.001, 2),
+		},
+	}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-User"); id != "" {
+			userID, _ := strconv.Atoi(id)
+			common.SetUser(c, &userauth.DefaultInfo{ID: uint(userID)})
+		}
+		c.Next()
+	}, APIMiddleware(classes))
+	r.Any("/*path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	request := func(method, path, user, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// deploys are limited by users
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/clusters/1/deploy", "1", "10.0.0.1").Code)
+	w := request(http.MethodPost, "/clusters/2/deploy", "1", "10.0.0.2")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/clusters/1/deploy", "2", "10.0.0.1").Code)
+
+	// the other requests are limited by source ips, regardless of the users
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/clusters/1/deploy", "1", "10.0.0.3").Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/clusters/1", "", "10.0.0.3").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request(http.MethodGet, "/clusters/1", "2", "10.0.0.3").Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/clusters/1", "", "10.0.0.4").Code)
+
+	// the limits reloaded apply at once
+	classes[1].IP.SetLimit(0, 0)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/clusters/1", "", "10.0.0.3").Code)
+}
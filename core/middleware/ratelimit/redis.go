@@ -0,0 +1,110 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// _redisTimeout bounds the time a request waits on redis, the request is allowed if redis is slow or down
+const _redisTimeout = 100 * time.Millisecond
+
+// _tokenBucketScript refills the bucket of KEYS[1] by the time passed, and takes a token from it.
+// It returns 1 if a token is taken, or 0 and the milliseconds to wait for the next token.
+// The bucket expires once it's refilled, which is the same as a new one.
+var _tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1])
+local last = tonumber(bucket[2])
+if tokens == nil or last == nil then
+  tokens = burst
+else
+  tokens = math.min(burst, tokens + math.max(0, now - last) * rate)
+end
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tokens, 'last', now)
+redis.call('EXPIRE', KEYS[1], math.ceil(burst / rate) + 1)
+return {allowed, wait}
+`)
+
+// RedisLimiter is a token bucket for each key in redis, so that the servers share the limit
+type RedisLimiter struct {
+	client *redis.Client
+	// prefix of the keys in redis, which separates the buckets of different limiters
+	prefix string
+
+	mu    sync.RWMutex
+	rate  float64
+	burst int
+}
+
+func NewRedisLimiter(client *redis.Client, prefix string, rate float64, burst int) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: prefix,
+		rate:   rate,
+		burst:  burst,
+	}
+}
+
+func (l *RedisLimiter) SetLimit(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	l.burst = burst
+}
+
+func (l *RedisLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.RLock()
+	rate, burst := l.rate, l.burst
+	l.mu.RUnlock()
+	if rate <= 0 {
+		return true, 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _redisTimeout)
+	defer cancel()
+	now := float64(time.Now().UnixNano()) / float64(time.Second)
+	val, err := _tokenBucketScript.Run(ctx, l.client, []string{l.prefix + key}, rate, burst,
+		fmt.Sprintf("%.3f", now)).Result()
+	result, ok := val.([]interface{})
+	if err != nil || !ok || len(result) != 2 {
+		log.Warningf(ctx, "failed to take token of %s from redis, the request is allowed: %v", key, err)
+		return true, 0
+	}
+	allowed, _ := result[0].(int64)
+	wait, _ := result[1].(int64)
+	if allowed == 1 {
+		return true, 0
+	}
+	return false, time.Duration(wait) * time.Millisecond
+}
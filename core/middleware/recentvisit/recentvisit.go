@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recentvisit
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/pkg/recentvisit/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// _detailPattern matches the detail routes of applications and clusters, e.g. /apis/core/v2/clusters/1
+var _detailPattern = regexp.MustCompile("^/apis/core/v[12]/(applications|clusters)/([0-9]+)$")
+
+// Middleware records the applications and clusters the users visited, after their details are served.
+// It must be used after the user middleware, which attaches the user to the request.
+func Middleware(mgr manager.Manager, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		matches := _detailPattern.FindStringSubmatch(c.Request.URL.Path)
+		if matches == nil {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() != http.StatusOK {
+			return
+		}
+		currentUser, err := common.UserFromContext(c)
+		if err != nil || currentUser.GetRobotID() != 0 {
+			return
+		}
+		resourceID, err := strconv.ParseUint(matches[2], 10, 0)
+		if err != nil {
+			return
+		}
+		// the details have been served, a failure is only logged
+		if err := mgr.Record(c, currentUser.GetID(), matches[1], uint(resourceID)); err != nil {
+			log.Warningf(c, "record recent visit error, path = %s, err = %v", c.Request.URL.Path, err)
+		}
+	}, skippers...)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recentvisit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/recentvisit/manager"
+	"github.com/horizoncd/horizon/pkg/recentvisit/models"
+	"github.com/horizoncd/horizon/pkg/server/response"
+)
+
+func TestMiddleware(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	// a temporary sqlite database is private to its connection
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	assert.Nil(t, db.AutoMigrate(&models.RecentVisit{}))
+	mgr := manager.New(db)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		// attached by the user middleware
+		common.SetUser(c, &userauth.DefaultInfo{ID: 1, Name: "tony"})
+		c.Next()
+	}, Middleware(mgr))
+	r.GET("/apis/core/v2/clusters/:clusterID", func(c *gin.Context) {
+		if c.Param("clusterID") == "2" {
+			response.AbortWithNotExistError(c, "cluster not found")
+			return
+		}
+		response.Success(c)
+	})
+	r.GET("/apis/core/v2/clusters/:clusterID/status", func(c *gin.Context) {
+		response.Success(c)
+	})
+	r.GET("/apis/core/v1/applications/:applicationID", func(c *gin.Context) {
+		response.Success(c)
+	})
+
+	for _, path := range []string{
+		"/apis/core/v2/clusters/1",
+		// failed and non detail requests are not recorded
+		"/apis/core/v2/clusters/2",
+		"/apis/core/v2/clusters/3/status",
+		"/apis/core/v1/applications/4",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	visits, err := mgr.List(context.Background(), 1, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(visits))
+	assert.Equal(t, common.ResourceApplication, visits[0].ResourceType)
+	assert.Equal(t, uint(4), visits[0].ResourceID)
+	assert.Equal(t, common.ResourceCluster, visits[1].ResourceType)
+	assert.Equal(t, uint(1), visits[1].ResourceID)
+}
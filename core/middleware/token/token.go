@@ -86,6 +86,8 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 				response.AbortWithUnauthorized(c, common.Unauthorized, e.Error())
 				return
 			}
+			response.AbortWithInternalError(c, err.Error())
+			return
 		}
 		if !result {
 			log.WithFiled(c, CheckResult, result).Warningf("reason = %s", reason)
@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	middleware "github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+)
+
+// HeaderXTraceID tells the trace id of the request, so that the trace can be found by the response
+const HeaderXTraceID = "X-Trace-ID"
+
+// Middleware starts a server span for each request by otelgin, the spans started by controllers, managers
+// and clients with the request context are its children
+func Middleware(serviceName string, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(otelgin.Middleware(serviceName), skippers...)
+}
+
+// TraceIDMiddleware responds the trace id of the request in the X-Trace-ID header, and records the request id
+// in the span. It must be used after Middleware, which has started the span before calling it.
+func TraceIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		span := oteltrace.SpanFromContext(c.Request.Context())
+		if !span.SpanContext().IsValid() {
+			c.Next()
+			return
+		}
+		c.Header(HeaderXTraceID, span.SpanContext().TraceID().String())
+		if rid, err := requestid.FromContext(c); err == nil {
+			span.SetAttributes(attribute.String("http.request_id", rid))
+		}
+		c.Next()
+	}
+}
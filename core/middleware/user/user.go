@@ -39,6 +39,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
+	robotmanager "github.com/horizoncd/horizon/pkg/robot/manager"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -77,6 +78,7 @@ func Middleware(param *param.Param, store sessions.Store,
 				ID:       user.ID,
 				Email:    user.Email,
 				Admin:    user.Admin,
+				Auditor:  user.Auditor,
 			})
 			c.Next()
 			return
@@ -84,6 +86,10 @@ func Middleware(param *param.Param, store sessions.Store,
 
 		// 2. token auth request ( get user by token)
 		if _, err := common.GetToken(c); err == nil {
+			if err := robotAuthn(c, param.RobotMgr); err != nil {
+				response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+				return
+			}
 			c.Next()
 			return
 		}
@@ -128,6 +134,41 @@ func Middleware(param *param.Param, store sessions.Store,
 	}, skippers...)
 }
 
+// robotAuthn checks the robot whose user is attached by the token, and attaches the robot for attribution.
+// The user of a robot only authenticates with the tokens of the robot.
+// Robots are only looked up for the users marked as robot users, the others are returned directly.
+func robotAuthn(c *gin.Context, robotMgr robotmanager.Manager) error {
+	user, err := common.UserFromContext(c)
+	if err != nil {
+		// the token is not checked on the paths skipped by the token middleware
+		return nil
+	}
+	if !user.IsRobot() {
+		return nil
+	}
+	robot, err := robotMgr.GetByUserID(c, user.GetID())
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	if robot.Disabled {
+		return perror.Wrapf(herrors.ErrForbidden, "robot %s is disabled", robot.Name)
+	}
+	common.SetUser(c, &userauth.DefaultInfo{
+		Name:     user.GetName(),
+		FullName: user.GetFullName(),
+		ID:       user.GetID(),
+		Email:    user.GetEmail(),
+		Admin:    user.IsAdmin(),
+		Auditor:  user.IsAuditor(),
+		Robot:    true,
+		RobotID:  robot.ID,
+	})
+	return nil
+}
+
 func akskAuthn(c *gin.Context, keys authenticate.KeysConfig, userMgr usermanager.Manager) (*models.User, error) {
 	r := c.Request
 	log.Infof(c, "request url path: %v", r.URL)
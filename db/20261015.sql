@@ -0,0 +1,1139 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- check table
+CREATE TABLE `tb_check`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource type',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'resource id',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_resource_deleted` (`resource_type`, `resource_id`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- check run table
+CREATE TABLE `tb_checkrun`
+(
+  `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'the name of check run',
+  `status`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'the status of check run',
+  `pipeline_run_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline run id',
+  `check_id`        bigint(20) unsigned NOT NULL COMMENT 'check id',
+  `message`         varchar(256)        NOT NULL DEFAULT '',
+  `detail_url`      varchar(256)        NOT NULL DEFAULT '' COMMENT 'the detail url of check run',
+  `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+  `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+  `updated_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_pipeline_run_id_check_id_deleted` (`pipeline_run_id`, `check_id`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- pr_msg table
+CREATE TABLE `tb_pr_msg`
+(
+  `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `pipeline_run_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline run id',
+  `content`         text                NOT NULL COMMENT 'content of message',
+  `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+  `updated_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+  `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+  PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- group table
+CREATE TABLE `tb_group`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`             varchar(128)        NOT NULL DEFAULT '',
+    `path`             varchar(32)         NOT NULL DEFAULT '',
+    `description`      varchar(256)                 DEFAULT NULL,
+    `visibility_level` varchar(16)         NOT NULL COMMENT 'public or private',
+    `parent_id`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'ID of the parent group',
+    `traversal_ids`    varchar(32)         NOT NULL DEFAULT '' COMMENT 'ID path from the root, like 1,2,3',
+    `region_selector`  varchar(512)        NOT NULL DEFAULT '' COMMENT 'used for filtering kubernetes',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_parentId_name_deletedTs` (`parent_id`, `name`, `deleted_ts`),
+    UNIQUE KEY `uk_parentId_path_deletedTs` (`parent_id`, `path`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- user table
+CREATE TABLE `tb_user`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`       varchar(64)         NOT NULL DEFAULT '',
+    `full_name`  varchar(128)                 DEFAULT '',
+    `email`      varchar(64)         NOT NULL DEFAULT '',
+    `phone`      varchar(32)                  DEFAULT NULL,
+    `oidc_id`    varchar(64)         NOT NULL COMMENT 'oidc id, which is a unique index in oidc system.',
+    `oidc_type`  varchar(64)         NOT NULL COMMENT 'oidc type, such as google, github, gitlab etc.',
+    `admin`      tinyint(1)          NOT NULL COMMENT 'is system admin，0-false，1-true',
+    `auditor`    tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'is system auditor with read-only access to all resources',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts` bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by` bigint(20) unsigned NOT NULL DEFAULT 0,
+    `user_type`  tinyint(1) unsigned NOT NULL DEFAULT 0 COMMENT 'the option type is: 0 (common user), 1(robot user)',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`),
+    UNIQUE KEY `idx_email` (`email`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- template table
+CREATE TABLE `tb_template`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'the name of template',
+    `description` varchar(256)                 DEFAULT NULL COMMENT 'the template description',
+    `repository`  varchar(256)        NOT NULL DEFAULT '',
+    `source_type` varchar(16)         NOT NULL DEFAULT 'git' COMMENT 'kind of the source the chart is fetched from, git, helm or oci',
+    `group_id`    bigint(20) unsigned NOT NULL DEFAULT '0',
+    `chart_name`  varchar(256)                 DEFAULT '',
+    `only_owner`  tinyint(1)          NOT NULL DEFAULT '0',
+    `without_ci`  tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'without_ci configuration, 0 means with ci',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`  bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- template release table
+CREATE TABLE `tb_template_release`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `template_name` varchar(64)         NOT NULL COMMENT 'the name of template',
+    `name`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'the name of template release',
+    `description`   varchar(256)        NOT NULL COMMENT 'description about this template release',
+    `recommended`   tinyint(1)          NOT NULL COMMENT 'is the most recommended template, 0-false, 1-true',
+    `template`      bigint(20) unsigned NOT NULL DEFAULT '0',
+    `chart_name`    varchar(256)        NOT NULL DEFAULT '',
+    `only_owner`    tinyint(1)          NOT NULL DEFAULT '0',
+    `chart_version` varchar(256)        NOT NULL DEFAULT '' COMMENT 'chart version on template repository',
+    `sync_status`   varchar(64)         NOT NULL DEFAULT 'status_unknown' COMMENT 'shows sync status',
+    `failed_reason` varchar(2048)       NOT NULL DEFAULT '' COMMENT 'failed reason at last time',
+    `commit_id`     varchar(256)        NOT NULL DEFAULT '' COMMENT 'commit id at last sync',
+    `last_sync_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    `canary`            tinyint(1)      NOT NULL DEFAULT '0' COMMENT 'is canary release, 0-false, 1-true',
+    `canary_percentage` int(11)         NOT NULL DEFAULT '0' COMMENT 'percentage of clusters the canary release is available to',
+    `canary_clusters`   varchar(2048)   NOT NULL DEFAULT '' COMMENT 'opted-in clusters of the canary release, joined by comma',
+    `canary_at`         datetime                 DEFAULT NULL COMMENT 'time when the release became canary',
+    `channel`           varchar(16)     NOT NULL DEFAULT 'stable' COMMENT 'channel of the release, stable, beta or deprecated',
+    `deprecation_message` varchar(512)  NOT NULL DEFAULT '' COMMENT 'why the release is deprecated and what to use instead',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_template_name_name` (`template_name`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- member table
+CREATE TABLE `tb_member`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groupapplicationcluster',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'resource id',
+    `role`          varchar(64)         NOT NULL COMMENT 'binding role name',
+    `member_type`   tinyint(1)          NOT NULL DEFAULT '0' COMMENT '0-USER, 1-group',
+    `membername_id` bigint(20) unsigned NOT NULL COMMENT 'UserID or GroupID',
+    `granted_by`    bigint(20) unsigned NOT NULL COMMENT 'who grant the role',
+    `created_by`    bigint(20) unsigned NOT NULL COMMENT 'who create the role',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)          NOT NULL DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_resource_member_deleted` (`resource_type`, `resource_id`, `member_type`, `membername_id`,
+                                             `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- application table
+CREATE TABLE `tb_application`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `group_id`         bigint(20) unsigned NOT NULL COMMENT 'group id',
+    `name`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'the name of application',
+    `description`      varchar(256)                 DEFAULT NULL COMMENT 'the description of application',
+    `priority`         varchar(16)         NOT NULL DEFAULT 'P3' COMMENT 'the priority of application',
+    `git_url`          varchar(128)                 DEFAULT NULL COMMENT 'git repo url',
+    `git_subfolder`    varchar(128)                 DEFAULT NULL COMMENT 'git repo subfolder',
+    `git_branch`       varchar(128)                 DEFAULT NULL COMMENT 'git default branch',
+    `git_ref`          varchar(128)                 DEFAULT NULL,
+    `git_ref_type`     varchar(64)                  DEFAULT NULL,
+    `template`         varchar(64)         NOT NULL COMMENT 'template name',
+    `template_release` varchar(64)         NOT NULL COMMENT 'template release',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    `hard_deleted`     tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'deleted permanently, can not be restored',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_name_deletedTs` (`name`, `deleted_ts`),
+    KEY `idx_deleted_ts` (`deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- registry table
+CREATE TABLE `tb_registry`
+(
+    `id`                       bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`                     varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the harbor registry',
+    `server`                   varchar(256)        NOT NULL DEFAULT '' COMMENT 'harbor server address',
+    `token`                    varchar(512)        NOT NULL DEFAULT '' COMMENT 'harbor server token',
+    `path`                     varchar(256)        NOT NULL DEFAULT '' COMMENT 'path of image',
+    `insecure_skip_tls_verify` tinyint(1)          NOT NULL DEFAULT false COMMENT 'skip tls verify',
+    `kind`                     varchar(256)        NOT NULL DEFAULT 'harbor' COMMENT 'which kind registry it is',
+    `created_at`               datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`               datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`               bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`               bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`               bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 12
+  DEFAULT CHARSET = utf8mb4;
+
+-- environment table
+CREATE TABLE `tb_environment`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`           varchar(128)        NOT NULL DEFAULT '' COMMENT 'env name',
+    `display_name`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'display name',
+    `default_region` varchar(128)                 DEFAULT NULL COMMENT 'default region of the environment',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    `auto_free`      tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'auto free configuration, 0 means disabled',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_name_deletedTs` (`name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- region table
+CREATE TABLE `tb_region`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`           varchar(128)        NOT NULL DEFAULT '' COMMENT 'region name',
+    `display_name`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'region display name',
+    `server`         varchar(256)                 DEFAULT NULL COMMENT 'k8s server url',
+    `certificate`    text COMMENT 'k8s kube config',
+    `ingress_domain` text COMMENT 'k8s ingress domain',
+    `prometheus_url` varchar(128) COMMENT 'prometheus url',
+    `registry_id`    bigint(20) unsigned NOT NULL COMMENT 'registry id',
+    `cd_type`        varchar(16)         NOT NULL DEFAULT 'argocd' COMMENT 'cd system syncing clusters of the region, argocd or flux',
+    `disabled`       tinyint(1)          NOT NULL DEFAULT '0' COMMENT '0 means not disabled, 1 means disabled',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- environment_region table
+CREATE TABLE `tb_environment_region`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `environment_name` varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment name',
+    `region_name`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'region name',
+    `is_default`       tinyint(1)          NOT NULL DEFAULT '0' COMMENT '0 means not default region, 1 means default region',
+    `disabled`         tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'is disabled，0-false，1-true',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_env_region_deletedTs` (`environment_name`, `region_name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- cluster table
+CREATE TABLE `tb_cluster`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id`   bigint(20) unsigned NOT NULL COMMENT 'application id',
+    `name`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'the name of cluster',
+    `environment_name` varchar(128)        NOT NULL DEFAULT '',
+    `region_name`      varchar(128)        NOT NULL DEFAULT '',
+    `description`      varchar(256)                 DEFAULT NULL COMMENT 'the description of cluster',
+    `git_url`          varchar(128)                 DEFAULT NULL COMMENT 'git repo url',
+    `git_subfolder`    varchar(128)                 DEFAULT NULL COMMENT 'git repo subfolder',
+    `git_branch`       varchar(128)                 DEFAULT NULL COMMENT 'git branch',
+    `git_ref`          varchar(128)                 DEFAULT NULL,
+    `git_ref_type`     varchar(64)                  DEFAULT NULL,
+    `template`         varchar(64)         NOT NULL COMMENT 'template name',
+    `template_release` varchar(64)         NOT NULL COMMENT 'template release',
+    `status`           varchar(64)                  DEFAULT NULL,
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    `expire_seconds`   bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'expiration seconds, 0 means permanent',
+    `hard_deleted`     tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'deleted permanently, can not be restored',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_name_deletedTs` (`name`, `deleted_ts`),
+    KEY `idx_application_id` (`application_id`),
+    KEY `idx_deleted_ts` (`deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- tag table
+CREATE TABLE `tb_tag`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'resource id',
+    `resource_type` varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource type',
+    `tag_key`       varchar(64)         NOT NULL DEFAULT '' COMMENT 'key of tag',
+    `tag_value`     varchar(1280)       NOT NULL DEFAULT '' COMMENT 'value of tag',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_rType_cId_tKey` (`resource_type`, `resource_id`, `tag_key`),
+    KEY `idx_cluster_id` (`resource_id`),
+    KEY `idx_key` (`tag_key`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- cluster template schema tag table
+CREATE TABLE `tb_cluster_template_schema_tag`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id` bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `tag_key`    varchar(64)         NOT NULL DEFAULT '' COMMENT 'key of tag',
+    `tag_value`  varchar(1280)       NOT NULL DEFAULT '' COMMENT 'value of tag',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id_key` (`cluster_id`, `tag_key`),
+    KEY `idx_key` (`tag_key`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- pipelinerun table
+CREATE TABLE `tb_pipelinerun`
+(
+    `id`                 bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`         bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `action`             varchar(64)         NOT NULL COMMENT 'action',
+    `status`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'the pipelinerun status',
+    `title`              varchar(256)        NOT NULL DEFAULT '' COMMENT 'the title of pipelinerun',
+    `description`        varchar(2048)                DEFAULT NULL COMMENT 'the description of pipelinerun',
+    `git_url`            varchar(128)                 DEFAULT NULL COMMENT 'git repo url',
+    `git_branch`         varchar(128)                 DEFAULT NULL COMMENT 'the branch to build of this pipelinerun',
+    `git_ref`            varchar(128)                 DEFAULT NULL,
+    `git_ref_type`       varchar(64)                  DEFAULT NULL,
+    `git_commit`         varchar(128)                 DEFAULT NULL COMMENT 'the commit to build of this pipelinerun',
+    `image_url`          varchar(256)                 DEFAULT NULL COMMENT 'image url',
+    `last_config_commit` varchar(128)                 DEFAULT NULL COMMENT 'the last commit of cluster config',
+    `config_commit`      varchar(128)                 DEFAULT NULL COMMENT 'the new commit of cluster config',
+    `s3_bucket`          varchar(128)        NOT NULL DEFAULT '' COMMENT 's3 bucket to storage this pipelinerun log',
+    `log_object`         varchar(258)        NOT NULL DEFAULT '' COMMENT 's3 object for log',
+    `pr_object`          varchar(258)        NOT NULL DEFAULT '' COMMENT 's3 object for pipelinerun',
+    `ci_event_id`        varchar(36)         NOT NULL DEFAULT '' COMMENT 'event id returned from ci component',
+    `started_at`         datetime                     DEFAULT NULL COMMENT 'start time of this pipelinerun',
+    `finished_at`        datetime                     DEFAULT NULL COMMENT 'finish time of this pipelinerun',
+    `rollback_from`      bigint(20) unsigned          DEFAULT NULL COMMENT 'the pipelinerun id that this pipelinerun rollback from',
+    `retry_of`           bigint(20) unsigned          DEFAULT NULL COMMENT 'the pipelinerun id that this pipelinerun retries',
+    `rerun_from_task`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'the task of the step which this pipelinerun reruns from',
+    `rerun_from_step`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'the step which this pipelinerun reruns from',
+    `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`         bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_action` (`cluster_id`, `action`),
+    KEY `idx_cluster_config_commit` (`cluster_id`, `config_commit`),
+    KEY `idx_ci_event_id` (`ci_event_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- application region table
+CREATE TABLE `tb_application_region`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id`   bigint(20) unsigned NOT NULL COMMENT 'application id',
+    `environment_name` varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment name',
+    `region_name`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'default deploy region of the environment',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_application_environment` (`application_id`, `environment_name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- tekton pipeline
+CREATE TABLE `tb_pipeline`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun id',
+    `application`    varchar(64)         NOT NULL COMMENT 'application name',
+    `cluster`        varchar(64)         NOT NULL COMMENT 'cluster name',
+    `region`         varchar(16)         NOT NULL COMMENT 'region name',
+    `pipeline`       varchar(16)         NOT NULL DEFAULT '' COMMENT 'pipeline name',
+    `result`         varchar(16)         NOT NULL DEFAULT '' COMMENT 'result of the step, ok、failed or others',
+    `duration`       int(16)             NOT NULL COMMENT 'duration',
+    `started_at`     datetime            NOT NULL COMMENT 'start time of this pipelinerun',
+    `finished_at`    datetime            NOT NULL COMMENT 'finish time of this pipelinerun',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_region_application_created_at` (`region`, `application`, `created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- tekton pipeline task
+CREATE TABLE `tb_task`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun id',
+    `application`    varchar(64)         NOT NULL COMMENT 'application name',
+    `cluster`        varchar(64)         NOT NULL COMMENT 'cluster name',
+    `region`         varchar(16)         NOT NULL COMMENT 'region name',
+    `pipeline`       varchar(16)         NOT NULL DEFAULT '' COMMENT 'pipeline name',
+    `task`           varchar(16)         NOT NULL DEFAULT '' COMMENT 'task name',
+    `result`         varchar(16)         NOT NULL DEFAULT '' COMMENT 'result of the step, ok or failed',
+    `duration`       int(16)             NOT NULL COMMENT 'duration',
+    `started_at`     datetime            NOT NULL COMMENT 'start time of this pipelinerun',
+    `finished_at`    datetime            NOT NULL COMMENT 'finish time of this pipelinerun',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_region_application_created_at` (`region`, `application`, `created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- tekton task step
+CREATE TABLE `tb_step`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun id',
+    `application`    varchar(64)         NOT NULL COMMENT 'application name',
+    `cluster`        varchar(64)         NOT NULL COMMENT 'cluster name',
+    `region`         varchar(16)         NOT NULL COMMENT 'region name',
+    `pipeline`       varchar(16)         NOT NULL DEFAULT '' COMMENT 'pipeline name',
+    `task`           varchar(16)         NOT NULL DEFAULT '' COMMENT 'task name',
+    `step`           varchar(16)         NOT NULL DEFAULT '' COMMENT 'step name',
+    `result`         varchar(16)         NOT NULL DEFAULT '' COMMENT 'result of the step, ok or failed',
+    `duration`       int(16)             NOT NULL COMMENT 'duration',
+    `started_at`     datetime            NOT NULL COMMENT 'start time of this pipelinerun',
+    `finished_at`    datetime            NOT NULL COMMENT 'finish time of this pipelinerun',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_region_application_created_at` (`region`, `application`, `created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- oauth app table
+CREATE TABLE `tb_oauth_app`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`         varchar(128)                 DEFAULT NULL COMMENT 'short name of app client',
+    `client_id`    varchar(128)                 DEFAULT NULL COMMENT 'oauth app client',
+    `redirect_url` varchar(256)                 DEFAULT NULL COMMENT 'the authorization callback url',
+    `home_url`     varchar(256)                 DEFAULT NULL COMMENT 'the oauth app home url',
+    `description`  varchar(256)                 DEFAULT NULL COMMENT 'the desc of app',
+    `app_type`     tinyint(1)          NOT NULL DEFAULT '1' COMMENT '1 for HorizonOAuthAPP, 2 for DirectOAuthAPP',
+    `client_type`  varchar(16)         NOT NULL DEFAULT 'confidential' COMMENT 'confidential or public, public clients use PKCE instead of client secrets',
+    `owner_type`   tinyint(1)          NOT NULL DEFAULT '1' COMMENT '1 for group, 2 for user',
+    `owner_id`     bigint(20)                   DEFAULT NULL COMMENT 'group owner id',
+    `access_token_expire_in`  bigint(20) NOT NULL DEFAULT 0 COMMENT 'access token lifetime in nanoseconds, 0 for default',
+    `refresh_token_expire_in` bigint(20) NOT NULL DEFAULT 0 COMMENT 'refresh token lifetime in nanoseconds, 0 for default',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created_at',
+    `created_by`   bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by`   bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_client_id` (`client_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- oauth client secret table
+CREATE TABLE `tb_oauth_client_secret`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `client_id`     varchar(256)                 DEFAULT NULL COMMENT 'oauth app client',
+    `client_secret` varchar(256)                 DEFAULT NULL COMMENT 'hashed oauth app secret',
+    `client_secret_suffix` varchar(8)            NOT NULL DEFAULT '' COMMENT 'last characters of the plaintext secret',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_client_id_secret` (`client_id`, `client_secret`),
+    KEY `idx_client_id_secret_suffix` (`client_id`, `client_secret_suffix`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- oauth grant table
+CREATE TABLE `tb_oauth_grant`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`    bigint(20) unsigned NOT NULL COMMENT 'user who consented',
+    `client_id`  varchar(128)        NOT NULL COMMENT 'oauth app client',
+    `scope`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'space separated scopes granted',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_id_client_id` (`user_id`, `client_id`),
+    KEY `idx_client_id` (`client_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- token table
+CREATE TABLE `tb_token`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`         varchar(64)         NOT NULL DEFAULT '',
+    `client_id`    varchar(256)                 DEFAULT NULL COMMENT 'oauth app client',
+    `redirect_uri` varchar(256)                 DEFAULT NULL,
+    `state`        varchar(256)                 DEFAULT NULL COMMENT ' authorize_code state info',
+    `code`         varchar(256)        NOT NULL DEFAULT '' COMMENT 'private-token-code/authorize_code/access_token/refresh-token',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expires_in`   bigint(20)                   DEFAULT NULL,
+    `scope`        varchar(256)                 DEFAULT NULL,
+    `user_id`      bigint(20) unsigned NOT NULL DEFAULT '0',
+    `created_by`   bigint(20) unsigned NOT NULL DEFAULT '0',
+    `code_challenge`        varchar(256) NOT NULL DEFAULT '' COMMENT 'PKCE code challenge of authorize_code',
+    `code_challenge_method` varchar(16)  NOT NULL DEFAULT '' COMMENT 'PKCE code challenge method, plain or S256',
+    `nonce`                 varchar(256) NOT NULL DEFAULT '' COMMENT 'openid connect nonce of authorize_code',
+    `user_code`             varchar(16)  NOT NULL DEFAULT '' COMMENT 'user code of device_code',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_code` (`code`),
+    KEY `idx_client_id` (`client_id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_user_code` (`user_code`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- identity provider table
+create table `tb_identity_provider`
+(
+    `id`                         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `display_name`               varchar(128)        NOT NULL DEFAULT '' COMMENT 'name displayed on web',
+    `name`                       varchar(128)        NOT NULL DEFAULT '' COMMENT 'name to generate index in db, unique',
+    `avatar`                     varchar(256)        NOT NULL DEFAULT '' COMMENT 'link to avatar',
+    `authorization_endpoint`     varchar(256)        NOT NULL DEFAULT '' COMMENT 'authorization endpoint of idp',
+    `token_endpoint`             varchar(256)        NOT NULL DEFAULT '' COMMENT 'token endpoint of idp',
+    `userinfo_endpoint`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'userinfo endpoint of idp',
+    `revocation_endpoint`        varchar(256)        NOT NULL DEFAULT '' COMMENT 'revocation endpoint of idp',
+    `issuer`                     varchar(256)        NOT NULL DEFAULT '' COMMENT 'issuer of idp, generating discovery endpoint',
+    `scopes`                     varchar(256)        NOT NULL DEFAULT '' COMMENT 'scopes when asking for authorization',
+    `signing_algs`               varchar(256)        NOT NULL DEFAULT '' COMMENT 'algs for verifying signing',
+    `token_endpoint_auth_method` varchar(256)        NOT NULL DEFAULT 'client_secret_sent_as_post' COMMENT 'how to carry client secret',
+    `jwks`                       varchar(256)        NOT NULL DEFAULT '' COMMENT 'jwks endpoint, describe how to identify a token',
+    `client_id`                  varchar(256)        NOT NULL DEFAULT '' COMMENT 'client id issued by idp',
+    `client_secret`              varchar(256)        NOT NULL DEFAULT '' COMMENT 'client secret issued by idp',
+    `created_at`                 datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'time of first creating',
+    `updated_at`                 datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'time of last updating',
+    `deleted_ts`                 bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`                 bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+    `updated_by`                 bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- idp and user relationship table
+create table `tb_idp_user`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `sub`        varchar(256)        NOT NULL DEFAULT '' COMMENT 'user id in idp',
+    `idp_id`     bigint(20)          NOT NULL DEFAULT 0 COMMENT 'refer to tb_identify_provider',
+    `user_id`    bigint(20)          NOT NULL DEFAULT 0 COMMENT 'refer to tb_user',
+    `name`       varchar(256)        NOT NULL DEFAULT '' COMMENT 'user name from idp',
+    `email`      varchar(256)        NOT NULL DEFAULT '' COMMENT 'user email from idp',
+    `deletable`  bool                NOT NULL DEFAULT false COMMENT 'whether this link can be deleted',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'time of first creating',
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'time of last updating',
+    `deleted_ts` bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+    `updated_by` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uni_idx_idp_sub` (`idp_id`, `sub`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_event`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `req_id`        varchar(256)        NOT NULL DEFAULT '',
+    `resource_type` varchar(256)        NOT NULL DEFAULT '',
+    `resource_id`   varchar(256)        NOT NULL DEFAULT '',
+    `event_type`    varchar(256)        NOT NULL DEFAULT '',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0',
+    `extra`         varchar(255)        NOT NULL DEFAULT '' COMMENT 'extra infos to describe the event',
+    PRIMARY KEY (`id`),
+    KEY `idx_req_id` (`req_id`),
+    KEY `idx_resource_action` (`resource_id`, `resource_type`, `event_type`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_event_cursor`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `position`   bigint(20)          NOT NULL DEFAULT '0',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_value` (`position`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_webhook`
+(
+    `id`                 bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `enabled`            tinyint(1)          NOT NULL DEFAULT '1',
+    `url`                text                NOT NULL,
+    `ssl_verify_enabled` tinyint(1)          NOT NULL DEFAULT '0',
+    `description`        varchar(256)        NOT NULL DEFAULT '',
+    `secret`             text                NOT NULL,
+    `triggers`           text                NOT NULL,
+    `resource_type`      varchar(256)        NOT NULL DEFAULT '',
+    `resource_id`        bigint(20)          NOT NULL DEFAULT '0',
+    `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`         bigint(20) unsigned NOT NULL DEFAULT '0',
+    `updated_by`         bigint(20) unsigned NOT NULL DEFAULT '0',
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_webhook_log`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `webhook_id`       bigint(20) unsigned NOT NULL,
+    `event_id`         bigint(20) unsigned NOT NULL,
+    `url`              text                NOT NULL,
+    `request_headers`  text                NOT NULL,
+    `request_data`     text                NOT NULL,
+    `response_headers` text                NOT NULL,
+    `response_body`    text                NOT NULL,
+    `status`           varchar(256)        NOT NULL COMMENT 'status, currently support: waiting, retrying, failed, success, deadletter',
+    `error_message`    text                NOT NULL,
+    `attempts`         int(10) unsigned    NOT NULL DEFAULT 0 COMMENT 'times the delivery has been sent',
+    `next_retry_at`    datetime                     DEFAULT NULL COMMENT 'when to retry the delivery of status retrying',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0',
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_webhook_id_status` (`webhook_id`, `status`),
+    KEY `idx_event_id` (`event_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- metatag table
+CREATE TABLE `tb_metatag`
+(
+    `tag_key`     varchar(64)  NOT NULL DEFAULT '' comment 'key of the metatag',
+    `tag_value`   varchar(128) NOT NULL DEFAULT '' comment 'value of the metatag',
+    `description` varchar(64)  NOT NULL DEFAULT '' comment 'description',
+    `created_at`  datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `idx_key_value` (`tag_key`, `tag_value`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- pr_sbom table
+CREATE TABLE `tb_pr_sbom`
+(
+  `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `pipeline_run_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline run id',
+  `image_url`       varchar(512)        NOT NULL DEFAULT '' COMMENT 'image described by the sbom',
+  `format`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'format of the sbom document',
+  `content`         longtext            NOT NULL COMMENT 'raw sbom document',
+  `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+  `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+  `updated_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  KEY `idx_pipeline_run_id` (`pipeline_run_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- pr_sbom_component table
+CREATE TABLE `tb_pr_sbom_component`
+(
+  `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `sbom_id`         bigint(20) unsigned NOT NULL COMMENT 'sbom id',
+  `pipeline_run_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline run id',
+  `image_url`       varchar(512)        NOT NULL DEFAULT '' COMMENT 'image the component belongs to',
+  `name`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of the component',
+  `version`         varchar(128)        NOT NULL DEFAULT '' COMMENT 'version of the component',
+  `type`            varchar(64)         NOT NULL DEFAULT '' COMMENT 'type of the component',
+  `purl`            varchar(1024)       NOT NULL DEFAULT '' COMMENT 'package url of the component',
+  PRIMARY KEY (`id`),
+  KEY `idx_sbom_id` (`sbom_id`),
+  KEY `idx_name_version` (`name`, `version`),
+  KEY `idx_image_url` (`image_url`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- cluster_summary table
+CREATE TABLE `tb_cluster_summary`
+(
+  `id`                 bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`         bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+  `application_id`     bigint(20) unsigned NOT NULL COMMENT 'application id',
+  `environment_name`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment name',
+  `region_name`        varchar(128)        NOT NULL DEFAULT '' COMMENT 'region name',
+  `template`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'template name',
+  `template_release`   varchar(64)         NOT NULL DEFAULT '' COMMENT 'template release',
+  `pipelinerun_id`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the latest deploy pipelinerun',
+  `pipelinerun_action` varchar(64)         NOT NULL DEFAULT '' COMMENT 'action of the latest deploy pipelinerun',
+  `pipelinerun_status` varchar(64)         NOT NULL DEFAULT '' COMMENT 'status of the latest deploy pipelinerun',
+  `deployed_at`        datetime                     DEFAULT NULL COMMENT 'time of the latest deploy',
+  `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_cluster_id` (`cluster_id`),
+  KEY `idx_application_id` (`application_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- deploy_lock table
+CREATE TABLE `tb_deploy_lock`
+(
+  `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `resource_type` varchar(64)         NOT NULL COMMENT 'locked resource type, clusters or applications',
+  `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'locked resource id',
+  `reason`        varchar(1024)       NOT NULL DEFAULT '' COMMENT 'reason of the lock',
+  `expire_at`     datetime                     DEFAULT NULL COMMENT 'lock is released after the time, never if null',
+  `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'owner of the lock',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- cluster_snapshot table
+CREATE TABLE `tb_cluster_snapshot`
+(
+  `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`       bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+  `trigger_type`     varchar(32)         NOT NULL DEFAULT '' COMMENT 'deploy, schedule or restore',
+  `pipelinerun_id`   bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'pipelinerun which triggers the snapshot',
+  `config_commit`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'head commit of the gitops branch',
+  `template`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'template name',
+  `template_release` varchar(64)         NOT NULL DEFAULT '' COMMENT 'template release',
+  `git_url`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'git repo url',
+  `git_subfolder`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'git repo subfolder',
+  `git_ref_type`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'git ref type',
+  `git_ref`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'git ref',
+  `image`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'image',
+  `tags`             text COMMENT 'tags of the cluster in json',
+  `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `created_by`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator, 0 for scheduled snapshots',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id` (`cluster_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- async_task table
+CREATE TABLE `tb_async_task`
+(
+  `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `type`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'task type, such as cluster_create',
+  `status`        varchar(32)         NOT NULL DEFAULT '' COMMENT 'running, succeeded or failed',
+  `done`          int(11)             NOT NULL DEFAULT 0 COMMENT 'count of finished steps',
+  `total`         int(11)             NOT NULL DEFAULT 0 COMMENT 'count of all steps',
+  `message`       varchar(256)        NOT NULL DEFAULT '' COMMENT 'what the task is doing',
+  `result`        text COMMENT 'result of the task in json, partial result while running',
+  `error_message` text COMMENT 'error of the failed task',
+  `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `finished_at`   datetime                     DEFAULT NULL,
+  `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  PRIMARY KEY (`id`),
+  KEY `idx_created_by` (`created_by`),
+  KEY `idx_status_updated_at` (`status`, `updated_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- cluster_env_change table
+CREATE TABLE `tb_cluster_env_change`
+(
+  `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`    bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+  `name`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of the environment variable',
+  `action`        varchar(32)         NOT NULL DEFAULT '' COMMENT 'added, updated or deleted',
+  `type`          varchar(32)         NOT NULL DEFAULT '' COMMENT 'type of the value',
+  `secret`        tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'whether the value is secret',
+  `old_value`     text COMMENT 'value before the change, masked for secrets',
+  `new_value`     text COMMENT 'value after the change, masked for secrets',
+  `config_commit` varchar(128)        NOT NULL DEFAULT '' COMMENT 'gitops commit which the change is made on',
+  `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id_name` (`cluster_id`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- change_request table
+CREATE TABLE `tb_change_request`
+(
+  `id`                bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`        bigint(20) unsigned NOT NULL COMMENT 'cluster the changes are proposed to',
+  `title`             varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the change request',
+  `description`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'description of the change request',
+  `branch`            varchar(128)        NOT NULL COMMENT 'git branch holding the changes',
+  `merge_request_url` varchar(512)        NOT NULL DEFAULT '' COMMENT 'url of the merge request of the branch',
+  `status`            varchar(32)         NOT NULL COMMENT 'pending, merged or rejected',
+  `merged_commit`     varchar(128)        NOT NULL DEFAULT '' COMMENT 'commit of gitops branch after merged',
+  `reviewed_by`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'reviewer of the change request',
+  `review_comment`    varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comment of the reviewer',
+  `reviewed_at`       datetime                     DEFAULT NULL,
+  `created_at`        datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`        datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`        bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator of the change request',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id_status` (`cluster_id`, `status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- resource metadata table, values of the metadata fields configured for applications and clusters
+CREATE TABLE `tb_resource_metadata`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource type',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'resource id',
+    `meta_key`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'key of metadata field',
+    `meta_value`    varchar(1024)       NOT NULL DEFAULT '' COMMENT 'value of metadata field',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_key` (`resource_type`, `resource_id`, `meta_key`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- oauth token audit table, records issuances, refreshes and revocations of oauth tokens
+CREATE TABLE `tb_oauth_token_audit`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `action`     varchar(32)         NOT NULL COMMENT 'issue, refresh or revoke',
+    `client_id`  varchar(128)        NOT NULL COMMENT 'oauth app client',
+    `user_id`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'subject the token acts as',
+    `scope`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'space separated scopes of the token',
+    `token_id`   bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the token',
+    `source_ip`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'ip the request comes from',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_client_id` (`client_id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- audit log table, records mutating api requests
+CREATE TABLE `tb_audit_log`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`        bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'user who sent the request, 0 if not authenticated',
+    `user_name`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the user',
+    `method`         varchar(16)         NOT NULL COMMENT 'http method of the request',
+    `path`           varchar(1024)       NOT NULL COMMENT 'path of the request',
+    `resource_type`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource parsed from the path, such as clusters',
+    `resource_name`  varchar(128)        NOT NULL DEFAULT '' COMMENT 'id or name of the resource',
+    `sub_resource`   varchar(64)         NOT NULL DEFAULT '' COMMENT 'sub resource, such as builddeploy',
+    `request_digest` varchar(64)         NOT NULL DEFAULT '' COMMENT 'sha256 of the request body',
+    `status_code`    int(11)             NOT NULL DEFAULT 0 COMMENT 'http status code of the response',
+    `latency_ms`     bigint(20)          NOT NULL DEFAULT 0 COMMENT 'milliseconds the request took',
+    `source_ip`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'ip the request comes from',
+    `request_id`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'X-Request-ID of the request',
+    `robot_id`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the robot if the operator is a robot',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_robot_id` (`robot_id`),
+    KEY `idx_resource` (`resource_type`, `resource_name`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- notification channels of groups and applications, and the subscriptions of users
+CREATE TABLE `tb_notification_channel`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groups or applications',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `name`          varchar(128)        NOT NULL COMMENT 'name of the channel',
+    `type`          varchar(64)         NOT NULL COMMENT 'type, currently support: email, slack, dingtalk',
+    `url`           varchar(1024)       NOT NULL COMMENT 'webhook url of slack or dingtalk, or comma separated email addresses',
+    `secret`        varchar(256)        NOT NULL DEFAULT '' COMMENT 'secret to sign the requests to dingtalk',
+    `triggers`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated kinds of notifications to send',
+    `enabled`       tinyint(1)          NOT NULL DEFAULT 1 COMMENT 'whether the channel is enabled',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_notification_subscription`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`       bigint(20) unsigned NOT NULL COMMENT 'user who subscribes',
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groups, applications or clusters',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `triggers`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated kinds of notifications to receive',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_resource` (`user_id`, `resource_type`, `resource_id`),
+    KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- robot table, robot accounts of groups and applications
+CREATE TABLE `tb_robot`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`          varchar(64)         NOT NULL COMMENT 'name of the robot, unique in the resource',
+    `description`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'description of the robot',
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groups or applications',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `user_id`       bigint(20) unsigned NOT NULL COMMENT 'id of the robot user the robot acts as',
+    `disabled`      tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'whether the tokens of the robot are rejected',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_name` (`resource_type`, `resource_id`, `name`),
+    UNIQUE KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- group_quota table, quotas of the resources under groups
+CREATE TABLE `tb_group_quota`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `group_id`         bigint(20) unsigned NOT NULL COMMENT 'id of the group, the quota covers its subgroups',
+    `resource`         varchar(64)         NOT NULL COMMENT 'applications or clusters',
+    `hard_limit`       int(10) unsigned    NOT NULL COMMENT 'max number of the resource, creations fail once reached',
+    `warned_threshold` int(11)             NOT NULL DEFAULT 0 COMMENT 'highest soft threshold in percentage warned',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_group_resource` (`group_id`, `resource`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- custom_role table, roles defined by admins besides the built-in ones of the roles file
+CREATE TABLE `tb_custom_role`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`       varchar(64)         NOT NULL COMMENT 'name of the role, which is granted to members',
+    `desc`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'description of the role',
+    `rules`      text                NOT NULL COMMENT 'policy rules of the role in json',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by` bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by` bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- recent_visit table, the applications and clusters recently visited by each user
+CREATE TABLE `tb_recent_visit`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`       bigint(20) unsigned NOT NULL COMMENT 'id of the user',
+    `resource_type` varchar(64)         NOT NULL COMMENT 'applications or clusters',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `visited_at`    datetime(3)         NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT 'last time the user visited',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_resource` (`user_id`, `resource_type`, `resource_id`),
+    KEY `idx_user_visited_at` (`user_id`, `visited_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- deploy_approval table, approvals of pipelineruns deploying to protected environments
+CREATE TABLE `tb_deploy_approval`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun waiting for approval',
+  `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'cluster the pipelinerun deploys',
+  `environment`    varchar(128)        NOT NULL COMMENT 'protected environment of the cluster',
+  `status`         varchar(32)         NOT NULL COMMENT 'pending, approved or rejected',
+  `reviewed_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'approver who reviewed the deploy',
+  `review_comment` varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comment of the approver',
+  `reviewed_at`    datetime                     DEFAULT NULL,
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator of the pipelinerun',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_pipelinerun_id` (`pipelinerun_id`),
+  KEY `idx_environment_status` (`environment`, `status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- scheduled_deploy table, builddeploys and restarts of clusters triggered at a future time
+CREATE TABLE `tb_scheduled_deploy`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'cluster to deploy',
+  `action`         varchar(32)         NOT NULL COMMENT 'builddeploy or restart',
+  `title`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the pipelinerun',
+  `description`    varchar(2048)       NOT NULL DEFAULT '' COMMENT 'description of the pipelinerun',
+  `git_ref_type`   varchar(64)         NOT NULL DEFAULT '' COMMENT 'branch, tag or commit to build',
+  `git_ref`        varchar(128)        NOT NULL DEFAULT '' COMMENT 'git ref to build',
+  `status`         varchar(32)         NOT NULL COMMENT 'scheduled, running, triggered, failed or cancelled',
+  `scheduled_at`   datetime            NOT NULL COMMENT 'time to trigger the deploy',
+  `pipelinerun_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'pipelinerun created when triggered',
+  `message`        varchar(2048)       NOT NULL DEFAULT '' COMMENT 'reason of the failure',
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator, the deploy is triggered as the creator',
+  `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id` (`cluster_id`),
+  KEY `idx_status_scheduled_at` (`status`, `scheduled_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_artifact`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun which uploads the artifact',
+  `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'cluster of the pipelinerun',
+  `name`           varchar(128)        NOT NULL COMMENT 'file name of the artifact, unique in a pipelinerun',
+  `type`           varchar(32)         NOT NULL COMMENT 'artifact, junit or coverage',
+  `format`         varchar(32)         NOT NULL DEFAULT '' COMMENT 'cobertura, lcov or gocover for a coverage report',
+  `size`           bigint(20)          NOT NULL DEFAULT 0 COMMENT 'size in bytes',
+  `sha256`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'sha256 digest of the content',
+  `object_key`     varchar(512)        NOT NULL DEFAULT '' COMMENT 'key of the object in the artifact storage',
+  `summary`        text COMMENT 'summary of a junit or coverage report in json',
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_pipelinerun_name` (`pipelinerun_id`, `name`),
+  KEY `idx_cluster_pipelinerun` (`cluster_id`, `pipelinerun_id`),
+  KEY `idx_type_updated_at` (`type`, `updated_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_pipeline_step_setting`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `application_id` bigint(20) unsigned NOT NULL COMMENT 'application id',
+  `step_name`      varchar(63)         NOT NULL COMMENT 'name of the step declared by the template',
+  `enabled`        tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'whether the step is enabled for the application',
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_application_step` (`application_id`, `step_name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,20 @@
+-- async_task table
+CREATE TABLE `tb_async_task`
+(
+  `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `type`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'task type, such as cluster_create',
+  `status`        varchar(32)         NOT NULL DEFAULT '' COMMENT 'running, succeeded or failed',
+  `done`          int(11)             NOT NULL DEFAULT 0 COMMENT 'count of finished steps',
+  `total`         int(11)             NOT NULL DEFAULT 0 COMMENT 'count of all steps',
+  `message`       varchar(256)        NOT NULL DEFAULT '' COMMENT 'what the task is doing',
+  `result`        text COMMENT 'result of the task in json, partial result while running',
+  `error_message` text COMMENT 'error of the failed task',
+  `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `finished_at`   datetime                     DEFAULT NULL,
+  `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  PRIMARY KEY (`id`),
+  KEY `idx_created_by` (`created_by`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,22 @@
+-- change_request table
+CREATE TABLE `tb_change_request`
+(
+  `id`                bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`        bigint(20) unsigned NOT NULL COMMENT 'cluster the changes are proposed to',
+  `title`             varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the change request',
+  `description`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'description of the change request',
+  `branch`            varchar(128)        NOT NULL COMMENT 'git branch holding the changes',
+  `merge_request_url` varchar(512)        NOT NULL DEFAULT '' COMMENT 'url of the merge request of the branch',
+  `status`            varchar(32)         NOT NULL COMMENT 'pending, merged or rejected',
+  `merged_commit`     varchar(128)        NOT NULL DEFAULT '' COMMENT 'commit of gitops branch after merged',
+  `reviewed_by`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'reviewer of the change request',
+  `review_comment`    varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comment of the reviewer',
+  `reviewed_at`       datetime                     DEFAULT NULL,
+  `created_at`        datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`        datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`        bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator of the change request',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id_status` (`cluster_id`, `status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,19 @@
+-- cluster_env_change table
+CREATE TABLE `tb_cluster_env_change`
+(
+  `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`    bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+  `name`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of the environment variable',
+  `action`        varchar(32)         NOT NULL DEFAULT '' COMMENT 'added, updated or deleted',
+  `type`          varchar(32)         NOT NULL DEFAULT '' COMMENT 'type of the value',
+  `secret`        tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'whether the value is secret',
+  `old_value`     text COMMENT 'value before the change, masked for secrets',
+  `new_value`     text COMMENT 'value after the change, masked for secrets',
+  `config_commit` varchar(128)        NOT NULL DEFAULT '' COMMENT 'gitops commit which the change is made on',
+  `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id_name` (`cluster_id`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
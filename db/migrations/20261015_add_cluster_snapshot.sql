@@ -0,0 +1,24 @@
+-- cluster_snapshot table
+CREATE TABLE `tb_cluster_snapshot`
+(
+  `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`       bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+  `trigger_type`     varchar(32)         NOT NULL DEFAULT '' COMMENT 'deploy, schedule or restore',
+  `pipelinerun_id`   bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'pipelinerun which triggers the snapshot',
+  `config_commit`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'head commit of the gitops branch',
+  `template`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'template name',
+  `template_release` varchar(64)         NOT NULL DEFAULT '' COMMENT 'template release',
+  `git_url`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'git repo url',
+  `git_subfolder`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'git repo subfolder',
+  `git_ref_type`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'git ref type',
+  `git_ref`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'git ref',
+  `image`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'image',
+  `tags`             text COMMENT 'tags of the cluster in json',
+  `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `created_by`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator, 0 for scheduled snapshots',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id` (`cluster_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
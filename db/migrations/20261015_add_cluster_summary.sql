@@ -0,0 +1,43 @@
+-- cluster_summary table
+CREATE TABLE `tb_cluster_summary`
+(
+  `id`                 bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`         bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+  `application_id`     bigint(20) unsigned NOT NULL COMMENT 'application id',
+  `environment_name`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment name',
+  `region_name`        varchar(128)        NOT NULL DEFAULT '' COMMENT 'region name',
+  `template`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'template name',
+  `template_release`   varchar(64)         NOT NULL DEFAULT '' COMMENT 'template release',
+  `pipelinerun_id`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the latest deploy pipelinerun',
+  `pipelinerun_action` varchar(64)         NOT NULL DEFAULT '' COMMENT 'action of the latest deploy pipelinerun',
+  `pipelinerun_status` varchar(64)         NOT NULL DEFAULT '' COMMENT 'status of the latest deploy pipelinerun',
+  `deployed_at`        datetime                     DEFAULT NULL COMMENT 'time of the latest deploy',
+  `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_cluster_id` (`cluster_id`),
+  KEY `idx_application_id` (`application_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- backfill summaries of existing clusters, they are maintained by events afterwards
+INSERT INTO `tb_cluster_summary` (`cluster_id`, `application_id`, `environment_name`, `region_name`,
+                                  `template`, `template_release`, `pipelinerun_id`, `pipelinerun_action`,
+                                  `pipelinerun_status`, `deployed_at`)
+SELECT c.`id`,
+       c.`application_id`,
+       c.`environment_name`,
+       c.`region_name`,
+       c.`template`,
+       c.`template_release`,
+       IFNULL(pr.`id`, 0),
+       IFNULL(pr.`action`, ''),
+       IFNULL(pr.`status`, ''),
+       IFNULL(pr.`finished_at`, pr.`created_at`)
+FROM `tb_cluster` c
+         LEFT JOIN `tb_pipelinerun` pr ON pr.`id` = (SELECT MAX(p.`id`)
+                                                    FROM `tb_pipelinerun` p
+                                                    WHERE p.`cluster_id` = c.`id`
+                                                      AND p.`action` IN ('builddeploy', 'deploy', 'rollback'))
+WHERE c.`deleted_ts` = 0;
@@ -0,0 +1,16 @@
+-- deploy_lock table
+CREATE TABLE `tb_deploy_lock`
+(
+  `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `resource_type` varchar(64)         NOT NULL COMMENT 'locked resource type, clusters or applications',
+  `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'locked resource id',
+  `reason`        varchar(1024)       NOT NULL DEFAULT '' COMMENT 'reason of the lock',
+  `expire_at`     datetime                     DEFAULT NULL COMMENT 'lock is released after the time, never if null',
+  `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'owner of the lock',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,17 @@
+-- group_quota table, quotas of the resources under groups
+CREATE TABLE `tb_group_quota`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `group_id`         bigint(20) unsigned NOT NULL COMMENT 'id of the group, the quota covers its subgroups',
+    `resource`         varchar(64)         NOT NULL COMMENT 'applications or clusters',
+    `hard_limit`       int(10) unsigned    NOT NULL COMMENT 'max number of the resource, creations fail once reached',
+    `warned_threshold` int(11)             NOT NULL DEFAULT 0 COMMENT 'highest soft threshold in percentage warned',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_group_resource` (`group_id`, `resource`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,4 @@
+-- token lifetimes overridden by oauth app
+ALTER TABLE tb_oauth_app
+    ADD COLUMN `access_token_expire_in`  bigint(20) NOT NULL DEFAULT 0 COMMENT 'access token lifetime in nanoseconds, 0 for default',
+    ADD COLUMN `refresh_token_expire_in` bigint(20) NOT NULL DEFAULT 0 COMMENT 'refresh token lifetime in nanoseconds, 0 for default';
@@ -0,0 +1,15 @@
+-- oauth grant table, remembers the scopes users consented to grant to oauth apps
+CREATE TABLE `tb_oauth_grant`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`    bigint(20) unsigned NOT NULL COMMENT 'user who consented',
+    `client_id`  varchar(128)        NOT NULL COMMENT 'oauth app client',
+    `scope`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'space separated scopes granted',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_id_client_id` (`user_id`, `client_id`),
+    KEY `idx_client_id` (`client_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
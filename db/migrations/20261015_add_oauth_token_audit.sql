@@ -0,0 +1,18 @@
+-- oauth token audit table, records issuances, refreshes and revocations of oauth tokens
+CREATE TABLE `tb_oauth_token_audit`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `action`     varchar(32)         NOT NULL COMMENT 'issue, refresh or revoke',
+    `client_id`  varchar(128)        NOT NULL COMMENT 'oauth app client',
+    `user_id`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'subject the token acts as',
+    `scope`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'space separated scopes of the token',
+    `token_id`   bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the token',
+    `source_ip`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'ip the request comes from',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_client_id` (`client_id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,37 @@
+-- pr_sbom table
+CREATE TABLE `tb_pr_sbom`
+(
+  `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `pipeline_run_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline run id',
+  `image_url`       varchar(512)        NOT NULL DEFAULT '' COMMENT 'image described by the sbom',
+  `format`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'format of the sbom document',
+  `content`         longtext            NOT NULL COMMENT 'raw sbom document',
+  `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+  `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+  `updated_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  KEY `idx_pipeline_run_id` (`pipeline_run_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- pr_sbom_component table
+CREATE TABLE `tb_pr_sbom_component`
+(
+  `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `sbom_id`         bigint(20) unsigned NOT NULL COMMENT 'sbom id',
+  `pipeline_run_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline run id',
+  `image_url`       varchar(512)        NOT NULL DEFAULT '' COMMENT 'image the component belongs to',
+  `name`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of the component',
+  `version`         varchar(128)        NOT NULL DEFAULT '' COMMENT 'version of the component',
+  `type`            varchar(64)         NOT NULL DEFAULT '' COMMENT 'type of the component',
+  `purl`            varchar(1024)       NOT NULL DEFAULT '' COMMENT 'package url of the component',
+  PRIMARY KEY (`id`),
+  KEY `idx_sbom_id` (`sbom_id`),
+  KEY `idx_name_version` (`name`, `version`),
+  KEY `idx_image_url` (`image_url`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
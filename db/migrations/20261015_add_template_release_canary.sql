@@ -0,0 +1,6 @@
+-- canary of template release
+ALTER TABLE tb_template_release
+    ADD COLUMN `canary`            tinyint(1)    NOT NULL DEFAULT '0' COMMENT 'is canary release, 0-false, 1-true',
+    ADD COLUMN `canary_percentage` int(11)       NOT NULL DEFAULT '0' COMMENT 'percentage of clusters the canary release is available to',
+    ADD COLUMN `canary_clusters`   varchar(2048) NOT NULL DEFAULT '' COMMENT 'opted-in clusters of the canary release, joined by comma',
+    ADD COLUMN `canary_at`         datetime               DEFAULT NULL COMMENT 'time when the release became canary';
@@ -0,0 +1,4 @@
+-- PKCE challenge of authorization code
+ALTER TABLE tb_token
+    ADD COLUMN `code_challenge`        varchar(256) NOT NULL DEFAULT '' COMMENT 'PKCE code challenge of authorize_code',
+    ADD COLUMN `code_challenge_method` varchar(16)  NOT NULL DEFAULT '' COMMENT 'PKCE code challenge method, plain or S256';
@@ -0,0 +1,3 @@
+-- openid connect nonce of authorization code
+ALTER TABLE tb_token
+    ADD COLUMN `nonce` varchar(256) NOT NULL DEFAULT '' COMMENT 'openid connect nonce of authorize_code';
@@ -0,0 +1,3 @@
+-- system auditor, who has read-only access to all resources
+ALTER TABLE tb_user
+    ADD COLUMN `auditor` tinyint(1) NOT NULL DEFAULT 0 COMMENT 'is system auditor with read-only access to all resources' AFTER `admin`;
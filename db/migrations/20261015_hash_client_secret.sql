@@ -0,0 +1,14 @@
+-- client secrets are stored as "sha256$<salt>$<hex of sha256(salt + secret)>",
+-- the last characters of the plaintext are kept to show the secret musked and to look it up
+ALTER TABLE tb_oauth_client_secret
+    ADD COLUMN `client_secret_suffix` varchar(8) NOT NULL DEFAULT '' COMMENT 'last characters of the plaintext secret' AFTER `client_secret`,
+    ADD KEY `idx_client_id_secret_suffix` (`client_id`, `client_secret_suffix`);
+
+-- hash the plaintext secrets in a single statement, so that it's safe to rerun as the hashed ones are skipped.
+-- The suffix is assigned first, from the plaintext, as assignments of an update are applied from left to right.
+-- The salt is derived from the row to be referred to twice, it only needs to be unique rather than secret.
+UPDATE tb_oauth_client_secret
+SET client_secret_suffix = RIGHT(client_secret, 8),
+    client_secret        = CONCAT('sha256$', MD5(CONCAT(id, '$', client_id, '$', created_at)), '$',
+                                  SHA2(CONCAT(MD5(CONCAT(id, '$', client_id, '$', created_at)), client_secret), 256))
+WHERE client_secret NOT LIKE 'sha256$%';
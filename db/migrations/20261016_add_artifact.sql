@@ -0,0 +1,24 @@
+-- artifact table, artifacts and test reports uploaded by the steps of pipelineruns
+CREATE TABLE `tb_artifact`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun which uploads the artifact',
+  `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'cluster of the pipelinerun',
+  `name`           varchar(128)        NOT NULL COMMENT 'file name of the artifact, unique in a pipelinerun',
+  `type`           varchar(32)         NOT NULL COMMENT 'artifact, junit or coverage',
+  `format`         varchar(32)         NOT NULL DEFAULT '' COMMENT 'cobertura, lcov or gocover for a coverage report',
+  `size`           bigint(20)          NOT NULL DEFAULT 0 COMMENT 'size in bytes',
+  `sha256`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'sha256 digest of the content',
+  `object_key`     varchar(512)        NOT NULL DEFAULT '' COMMENT 'key of the object in the artifact storage',
+  `summary`        text COMMENT 'summary of a junit or coverage report in json',
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_pipelinerun_name` (`pipelinerun_id`, `name`),
+  KEY `idx_cluster_pipelinerun` (`cluster_id`, `pipelinerun_id`),
+  KEY `idx_type_updated_at` (`type`, `updated_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
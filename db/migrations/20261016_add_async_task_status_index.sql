@@ -0,0 +1,3 @@
+-- running tasks without heartbeats are failed every minute by every server
+ALTER TABLE tb_async_task
+    ADD KEY `idx_status_updated_at` (`status`, `updated_at`);
@@ -0,0 +1,24 @@
+-- audit log table, records mutating api requests
+CREATE TABLE `tb_audit_log`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`        bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'user who sent the request, 0 if not authenticated',
+    `user_name`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the user',
+    `method`         varchar(16)         NOT NULL COMMENT 'http method of the request',
+    `path`           varchar(1024)       NOT NULL COMMENT 'path of the request',
+    `resource_type`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource parsed from the path, such as clusters',
+    `resource_name`  varchar(128)        NOT NULL DEFAULT '' COMMENT 'id or name of the resource',
+    `sub_resource`   varchar(64)         NOT NULL DEFAULT '' COMMENT 'sub resource, such as builddeploy',
+    `request_digest` varchar(64)         NOT NULL DEFAULT '' COMMENT 'sha256 of the request body',
+    `status_code`    int(11)             NOT NULL DEFAULT 0 COMMENT 'http status code of the response',
+    `latency_ms`     bigint(20)          NOT NULL DEFAULT 0 COMMENT 'milliseconds the request took',
+    `source_ip`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'ip the request comes from',
+    `request_id`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'X-Request-ID of the request',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_resource` (`resource_type`, `resource_name`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
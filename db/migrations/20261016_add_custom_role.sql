@@ -0,0 +1,16 @@
+-- custom_role table, roles defined by admins besides the built-in ones of the roles file
+CREATE TABLE `tb_custom_role`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`       varchar(64)         NOT NULL COMMENT 'name of the role, which is granted to members',
+    `desc`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'description of the role',
+    `rules`      text                NOT NULL COMMENT 'policy rules of the role in json',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by` bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by` bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
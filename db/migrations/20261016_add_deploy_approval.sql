@@ -0,0 +1,20 @@
+-- deploy_approval table, approvals of pipelineruns deploying to protected environments
+CREATE TABLE `tb_deploy_approval`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun waiting for approval',
+  `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'cluster the pipelinerun deploys',
+  `environment`    varchar(128)        NOT NULL COMMENT 'protected environment of the cluster',
+  `status`         varchar(32)         NOT NULL COMMENT 'pending, approved or rejected',
+  `reviewed_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'approver who reviewed the deploy',
+  `review_comment` varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comment of the approver',
+  `reviewed_at`    datetime                     DEFAULT NULL,
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator of the pipelinerun',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_pipelinerun_id` (`pipelinerun_id`),
+  KEY `idx_environment_status` (`environment`, `status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
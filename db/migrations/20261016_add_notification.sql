@@ -0,0 +1,37 @@
+-- notification channels of groups and applications, and the subscriptions of users
+CREATE TABLE `tb_notification_channel`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groups or applications',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `name`          varchar(128)        NOT NULL COMMENT 'name of the channel',
+    `type`          varchar(64)         NOT NULL COMMENT 'type, currently support: email, slack, dingtalk',
+    `url`           varchar(1024)       NOT NULL COMMENT 'webhook url of slack or dingtalk, or comma separated email addresses',
+    `secret`        varchar(256)        NOT NULL DEFAULT '' COMMENT 'secret to sign the requests to dingtalk',
+    `triggers`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated kinds of notifications to send',
+    `enabled`       tinyint(1)          NOT NULL DEFAULT 1 COMMENT 'whether the channel is enabled',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_notification_subscription`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`       bigint(20) unsigned NOT NULL COMMENT 'user who subscribes',
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groups, applications or clusters',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `triggers`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated kinds of notifications to receive',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_resource` (`user_id`, `resource_type`, `resource_id`),
+    KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,3 @@
+-- client type of oauth app, the existing apps are confidential clients which authenticate by client secrets
+ALTER TABLE tb_oauth_app
+    ADD COLUMN `client_type` varchar(16) NOT NULL DEFAULT 'confidential' COMMENT 'confidential or public, public clients use PKCE instead of client secrets' AFTER `app_type`;
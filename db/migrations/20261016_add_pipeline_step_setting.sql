@@ -0,0 +1,16 @@
+-- pipeline step setting table, the custom pipeline steps enabled or disabled by applications
+CREATE TABLE `tb_pipeline_step_setting`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `application_id` bigint(20) unsigned NOT NULL COMMENT 'application id',
+  `step_name`      varchar(63)         NOT NULL COMMENT 'name of the step declared by the template',
+  `enabled`        tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'whether the step is enabled for the application',
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+  `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_application_step` (`application_id`, `step_name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
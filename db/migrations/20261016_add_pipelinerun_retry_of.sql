@@ -0,0 +1,5 @@
+-- the pipelinerun which a pipelinerun retries, and the step it reruns from
+ALTER TABLE tb_pipelinerun
+    ADD COLUMN `retry_of` bigint(20) unsigned DEFAULT NULL COMMENT 'the pipelinerun id that this pipelinerun retries' AFTER `rollback_from`,
+    ADD COLUMN `rerun_from_task` varchar(128) NOT NULL DEFAULT '' COMMENT 'the task of the step which this pipelinerun reruns from' AFTER `retry_of`,
+    ADD COLUMN `rerun_from_step` varchar(128) NOT NULL DEFAULT '' COMMENT 'the step which this pipelinerun reruns from' AFTER `rerun_from_task`;
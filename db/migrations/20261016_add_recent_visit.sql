@@ -0,0 +1,14 @@
+-- recent_visit table, the applications and clusters recently visited by each user
+CREATE TABLE `tb_recent_visit`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`       bigint(20) unsigned NOT NULL COMMENT 'id of the user',
+    `resource_type` varchar(64)         NOT NULL COMMENT 'applications or clusters',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `visited_at`    datetime(3)         NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT 'last time the user visited',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_resource` (`user_id`, `resource_type`, `resource_id`),
+    KEY `idx_user_visited_at` (`user_id`, `visited_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
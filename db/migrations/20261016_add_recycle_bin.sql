@@ -0,0 +1,12 @@
+-- soft deleted applications and clusters can be restored until they are purged,
+-- the ones deleted permanently are marked as hard deleted
+ALTER TABLE tb_application
+    ADD COLUMN `hard_deleted` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'deleted permanently, can not be restored',
+    ADD KEY `idx_deleted_ts` (`deleted_ts`);
+ALTER TABLE tb_cluster
+    ADD COLUMN `hard_deleted` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'deleted permanently, can not be restored';
+-- the git repos of applications deleted before were removed with them
+UPDATE tb_application SET hard_deleted = 1 WHERE deleted_ts != 0;
+-- the clusters deleted before can not be told whether their git repos were deleted or moved,
+-- they are not restorable, and their git repos are purged after the retention
+UPDATE tb_cluster SET hard_deleted = 1 WHERE deleted_ts != 0;
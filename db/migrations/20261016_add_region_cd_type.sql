@@ -0,0 +1,3 @@
+-- cd system syncing clusters of the region, clusters of regions existing are synced by argocd
+ALTER TABLE tb_region
+    ADD COLUMN `cd_type` varchar(16) NOT NULL DEFAULT 'argocd' COMMENT 'cd system syncing clusters of the region, argocd or flux' AFTER `registry_id`;
@@ -0,0 +1,24 @@
+-- robot accounts of groups and applications, and the robot attribution of audit logs
+CREATE TABLE `tb_robot`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`          varchar(64)         NOT NULL COMMENT 'name of the robot, unique in the resource',
+    `description`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'description of the robot',
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groups or applications',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `user_id`       bigint(20) unsigned NOT NULL COMMENT 'id of the robot user the robot acts as',
+    `disabled`      tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'whether the tokens of the robot are rejected',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_name` (`resource_type`, `resource_id`, `name`),
+    UNIQUE KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE `tb_audit_log`
+    ADD COLUMN `robot_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the robot if the operator is a robot',
+    ADD KEY `idx_robot_id` (`robot_id`);
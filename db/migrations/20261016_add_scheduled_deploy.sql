@@ -0,0 +1,24 @@
+-- scheduled_deploy table, builddeploys and restarts of clusters triggered at a future time
+CREATE TABLE `tb_scheduled_deploy`
+(
+  `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'cluster to deploy',
+  `action`         varchar(32)         NOT NULL COMMENT 'builddeploy or restart',
+  `title`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the pipelinerun',
+  `description`    varchar(2048)       NOT NULL DEFAULT '' COMMENT 'description of the pipelinerun',
+  `git_ref_type`   varchar(64)         NOT NULL DEFAULT '' COMMENT 'branch, tag or commit to build',
+  `git_ref`        varchar(128)        NOT NULL DEFAULT '' COMMENT 'git ref to build',
+  `status`         varchar(32)         NOT NULL COMMENT 'scheduled, running, triggered, failed or cancelled',
+  `scheduled_at`   datetime            NOT NULL COMMENT 'time to trigger the deploy',
+  `pipelinerun_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'pipelinerun created when triggered',
+  `message`        varchar(2048)       NOT NULL DEFAULT '' COMMENT 'reason of the failure',
+  `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator, the deploy is triggered as the creator',
+  `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_id` (`cluster_id`),
+  KEY `idx_status_scheduled_at` (`status`, `scheduled_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
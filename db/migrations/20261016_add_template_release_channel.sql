@@ -0,0 +1,4 @@
+-- channel of template release, new clusters cannot use deprecated releases unless permitted
+ALTER TABLE tb_template_release
+    ADD COLUMN `channel`             varchar(16)  NOT NULL DEFAULT 'stable' COMMENT 'channel of the release, stable, beta or deprecated',
+    ADD COLUMN `deprecation_message` varchar(512) NOT NULL DEFAULT '' COMMENT 'why the release is deprecated and what to use instead';
@@ -0,0 +1,3 @@
+-- kind of the source charts of template are fetched from, templates existing are fetched from git
+ALTER TABLE tb_template
+    ADD COLUMN `source_type` varchar(16) NOT NULL DEFAULT 'git' COMMENT 'kind of the source the chart is fetched from, git, helm or oci' AFTER `repository`;
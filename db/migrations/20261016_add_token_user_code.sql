@@ -0,0 +1,4 @@
+-- user code of device authorization, ref: rfc8628
+ALTER TABLE tb_token
+    ADD COLUMN `user_code` varchar(16) NOT NULL DEFAULT '' COMMENT 'user code of device_code',
+    ADD KEY `idx_user_code` (`user_code`);
@@ -0,0 +1,5 @@
+-- retries of webhook deliveries failed temporarily
+ALTER TABLE tb_webhook_log
+    ADD COLUMN `attempts`      int(10) unsigned NOT NULL DEFAULT 0 COMMENT 'times the delivery has been sent',
+    ADD COLUMN `next_retry_at` datetime                  DEFAULT NULL COMMENT 'when to retry the delivery of status retrying',
+    MODIFY COLUMN `status` varchar(256) NOT NULL COMMENT 'status, currently support: waiting, retrying, failed, success, deadletter';
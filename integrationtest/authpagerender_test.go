@@ -30,7 +30,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/oauth"
-	"github.com/horizoncd/horizon/core/controller/oauthapp"
 	oauthcheckctl "github.com/horizoncd/horizon/core/controller/oauthcheck"
 	clusterAPI "github.com/horizoncd/horizon/core/http/api/v1/cluster"
 	"github.com/horizoncd/horizon/core/http/api/v1/oauthserver"
@@ -113,7 +112,8 @@ func createOauthScopeConfig() oauthconfig.Scopes {
 func TestServer(t *testing.T) {
 	db, _ := orm.NewSqliteDB("")
 	manager = managerparam.InitManager(db)
-	if err := db.AutoMigrate(&tokenmodels.Token{}, &models.OauthApp{}, &models.OauthClientSecret{}); err != nil {
+	if err := db.AutoMigrate(&tokenmodels.Token{}, &models.OauthApp{}, &models.OauthClientSecret{},
+		&models.OauthGrant{}, &models.OauthTokenAudit{}); err != nil {
 		panic(err)
 	}
 	db = db.WithContext(context.WithValue(context.Background(), common.UserContextKey(), aUser))
@@ -150,14 +150,13 @@ func TestServer(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, authGetApp.ClientID, authApp.ClientID)
 
-	oauthServerController := oauth.NewController(&param.Param{Manager: manager, OauthManager: oauthManager})
-
-	oauthAppController := oauthapp.NewController(&param.Param{Manager: manager})
-
 	authScopeService, err := scope.NewFileScopeService(createOauthScopeConfig())
 	assert.Nil(t, err)
 
-	api := oauthserver.NewAPI(oauthServerController, oauthAppController, "authFileLoc", authScopeService)
+	oauthServerController := oauth.NewController(&param.Param{Manager: manager, OauthManager: oauthManager,
+		ScopeService: authScopeService})
+
+	api := oauthserver.NewAPI(oauthServerController, "authFileLoc", oauthconfig.Device{})
 
 	userMiddleWare := func(c *gin.Context) {
 		common.SetUser(c, aUser)
@@ -203,7 +202,19 @@ func TestServer(t *testing.T) {
 	httpClient := http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
 		return ErrMyError
 	}}
-	resp, err := httpClient.PostForm("http://localhost"+ListenPort+authorizeURI, data)
+
+	// scopes not defined are rejected
+	undefinedScopeData := url.Values{}
+	for k, v := range data {
+		undefinedScopeData[k] = v
+	}
+	undefinedScopeData.Set(oauthserver.KeyScope, "undefined-scope")
+	resp, err := httpClient.PostForm("http://localhost"+ListenPort+authorizeURI, undefinedScopeData)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = httpClient.PostForm("http://localhost"+ListenPort+authorizeURI, data)
 	assert.NotNil(t, err)
 	defer resp.Body.Close()
 	urlErr, ok := err.(*url.Error)
@@ -17,13 +17,21 @@ package gitlab
 import (
 	"context"
 	"crypto/tls"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
+	resilienceconfig "github.com/horizoncd/horizon/pkg/config/resilience"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/trace"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/resilience"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 
 	"github.com/xanzy/go-gitlab"
@@ -72,6 +80,13 @@ type Interface interface {
 	// See https://docs.gitlab.com/ee/api/commits.html#get-a-single-commit for more information.
 	GetCommit(ctx context.Context, pid interface{}, commit string) (_ *gitlab.Commit, err error)
 
+	// ListCommits lists commits of the ref with their stats, the newest first,
+	// only the ones changing the file of filepath are listed if it's not empty.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/commits.html#list-repository-commits for more information.
+	ListCommits(ctx context.Context, pid interface{}, ref, filepath string,
+		page, perPage int) ([]*gitlab.Commit, error)
+
 	// GetBranch get branch of the specified project.
 	// The pid can be the project's ID or relative path such as fist/second.
 	// See https://docs.gitlab.com/ee/api/branches.html#get-single-repository-branch for more information.
@@ -137,6 +152,10 @@ type Interface interface {
 	// See https://docs.gitlab.com/ee/api/repository_files.html#get-file-from-repository for more information.
 	GetFile(ctx context.Context, pid interface{}, ref, filepath string) ([]byte, error)
 
+	// GetFileAndCommit gets a file content like GetFile, and the commit which the ref points to,
+	// so that the other files can be read at the same commit without looking up the ref first.
+	GetFileAndCommit(ctx context.Context, pid interface{}, ref, filepath string) ([]byte, string, error)
+
 	// TransferProject transfer a project with the specified pid to the new group with the gid.
 	// The pid can be the project's ID or relative path such as fist/second.
 	// The gid can be the group's ID or relative path such as first/third.
@@ -179,6 +198,9 @@ type CommitAction struct {
 	FilePath     string
 	Content      string
 	PreviousPath string
+	// LastCommitID is the last known commit of the file, the action fails with ErrGitlabCommitConflict
+	// if the file has been changed after it. Only considered in update, move and delete actions.
+	LastCommitID string
 }
 
 func (a FileAction) toFileActionValuePtr() *gitlab.FileActionValue {
@@ -191,13 +213,61 @@ type helper struct {
 	httpURL string
 }
 
+// Token is the access token of gitlab, it can be rotated while the clients are in use
+type Token struct {
+	value atomic.Value
+}
+
+func NewToken(token string) *Token {
+	t := &Token{}
+	t.Set(token)
+	return t
+}
+
+func (t *Token) Set(token string) {
+	t.value.Store(token)
+}
+
+func (t *Token) Get() string {
+	return t.value.Load().(string)
+}
+
+// tokenTransport sends the latest token with each request
+type tokenTransport struct {
+	token *Token
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("PRIVATE-TOKEN", t.token.Get())
+	return t.base.RoundTrip(req)
+}
+
 // New an instance of Gitlab
 func New(token, httpURL string) (Interface, error) {
-	client, err := gitlab.NewClient(token,
+	return NewWithToken(NewToken(token), httpURL, resilienceconfig.Config{})
+}
+
+// NewWithToken an instance of Gitlab, which authenticates by the latest value of token,
+// and retries requests or fails them fast as the resilience config
+func NewWithToken(token *Token, httpURL string, resilienceConfig resilienceconfig.Config) (Interface, error) {
+	u, err := url.Parse(httpURL)
+	if err != nil {
+		return nil, herrors.NewErrCreateFailed(herrors.GitlabResource, err.Error())
+	}
+	// requests are retried by the resilience transport instead of the client,
+	// so that the circuit breaker sees each attempt
+	client, err := gitlab.NewClient(token.Get(),
 		gitlab.WithBaseURL(httpURL),
+		gitlab.WithoutRetries(),
 		gitlab.WithHTTPClient(&http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			Transport: &tokenTransport{
+				token: token,
+				base: resilience.Transport("gitlab", u.Host, resilienceConfig,
+					trace.Transport("gitlab", &http.Transport{
+						TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+					})),
 			},
 		}))
 	if err != nil {
@@ -366,6 +436,30 @@ func (h *helper) GetCommit(ctx context.Context, pid interface{}, commit string)
 	return c, nil
 }
 
+func (h *helper) ListCommits(ctx context.Context, pid interface{}, ref, filepath string,
+	page, perPage int) (_ []*gitlab.Commit, err error) {
+	const op = "gitlab: list commits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	opts := &gitlab.ListCommitsOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		},
+		RefName:   &ref,
+		WithStats: gitlab.Bool(true),
+	}
+	if filepath != "" {
+		opts.Path = &filepath
+	}
+	commits, rsp, err := h.client.Commits.ListCommits(pid, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, parseError(rsp, err)
+	}
+
+	return commits, nil
+}
+
 func (h *helper) GetTag(ctx context.Context, pid interface{}, tag string) (_ *gitlab.Tag, err error) {
 	const op = "gitlab: get tag"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -498,12 +592,16 @@ func (h *helper) WriteFiles(ctx context.Context, pid interface{}, branch, commit
 		Actions: func() []*gitlab.CommitActionOptions {
 			acts := make([]*gitlab.CommitActionOptions, 0)
 			for i := range actions {
-				acts = append(acts, &gitlab.CommitActionOptions{
+				act := &gitlab.CommitActionOptions{
 					Action:       actions[i].Action.toFileActionValuePtr(),
 					FilePath:     &actions[i].FilePath,
 					Content:      &actions[i].Content,
 					PreviousPath: &actions[i].PreviousPath,
-				})
+				}
+				if actions[i].LastCommitID != "" {
+					act.LastCommitID = &actions[i].LastCommitID
+				}
+				acts = append(acts, act)
 			}
 			return acts
 		}(),
@@ -532,6 +630,25 @@ func (h *helper) GetFile(ctx context.Context, pid interface{}, ref, filepath str
 	return content, nil
 }
 
+func (h *helper) GetFileAndCommit(ctx context.Context, pid interface{},
+	ref, filepath string) (_ []byte, _ string, err error) {
+	const op = "gitlab: get file and commit"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	file, rsp, err := h.client.RepositoryFiles.GetFile(pid, filepath, &gitlab.GetFileOptions{
+		Ref: &ref,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, "", parseError(rsp, err)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return nil, "", perror.Wrap(herrors.ErrGitlabInternal, err.Error())
+	}
+	return content, file.CommitID, nil
+}
+
 func (h *helper) TransferProject(ctx context.Context, pid interface{}, gid interface{}) (err error) {
 	const op = "gitlab: transfer project"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -615,11 +732,21 @@ func parseError(resp *gitlab.Response, err error) error {
 		return nil
 	}
 
+	// no response is received if the request failed to be sent
+	if resp == nil {
+		if errors.Is(err, resilience.ErrCircuitOpen) {
+			return perror.Wrap(herrors.ErrGitlabUnavailable, err.Error())
+		}
+		return perror.Wrap(herrors.ErrGitlabInternal, err.Error())
+	}
 	if resp.StatusCode == http.StatusNotFound {
 		return herrors.NewErrNotFound(herrors.GitlabResource, err.Error())
 	} else if resp.StatusCode == http.StatusNotAcceptable {
 		// https://docs.gitlab.com/ee/api/merge_requests.html#single-merge-request-response-notes
 		return perror.Wrap(herrors.ErrGitlabMRNotReady, err.Error())
+	} else if resp.StatusCode == http.StatusBadRequest && strings.Contains(err.Error(), "has changed since") {
+		// https://docs.gitlab.com/ee/api/commits.html#create-a-commit-with-multiple-files-and-actions
+		return perror.Wrap(herrors.ErrGitlabCommitConflict, err.Error())
 	}
 
 	return perror.Wrap(herrors.ErrGitlabInternal, err.Error())
@@ -39,9 +39,11 @@ type MySQL struct {
 	Password          string `json:"password,omitempty"`
 	Database          string `json:"database"`
 	PrometheusEnabled bool   `json:"prometheusEnabled"`
+	// Replicas serve the queries out of transactions, see UseReplicas
+	Replicas []MySQL `json:"replicas,omitempty"`
 }
 
-func NewMySQLDB(db *MySQL) (*gorm.DB, error) {
+func openMySQL(db *MySQL) (*sql.DB, error) {
 	conn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local", db.Username,
 		db.Password, db.Host, db.Port, db.Database)
 
@@ -54,6 +56,14 @@ func NewMySQLDB(db *MySQL) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 	sqlDB.SetConnMaxIdleTime(time.Hour)
+	return sqlDB, nil
+}
+
+func NewMySQLDB(db *MySQL) (*gorm.DB, error) {
+	sqlDB, err := openMySQL(db)
+	if err != nil {
+		return nil, err
+	}
 
 	orm, err := gorm.Open(mysql.New(mysql.Config{
 		Conn: sqlDB,
@@ -63,6 +73,21 @@ func NewMySQLDB(db *MySQL) (*gorm.DB, error) {
 			SingularTable: true,
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(db.Replicas) > 0 {
+		replicas := make([]gorm.ConnPool, 0, len(db.Replicas))
+		for i := range db.Replicas {
+			replica, err := openMySQL(&db.Replicas[i])
+			if err != nil {
+				return nil, err
+			}
+			replicas = append(replicas, replica)
+		}
+		UseReplicas(orm, replicas...)
+	}
 
 	if db.PrometheusEnabled {
 		if err := orm.Use(prometheus.New(prometheus.Config{
@@ -0,0 +1,89 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const contextPrimaryKey = "contextPrimary"
+
+func PrimaryContextKey() string {
+	return contextPrimaryKey
+}
+
+// WithPrimary pins the queries issued with the returned context to the primary,
+// it's used by the reads which must see the writes just made, since replicas lag behind
+func WithPrimary(parent context.Context) context.Context {
+	return context.WithValue(parent, contextPrimaryKey, true) // nolint
+}
+
+func primaryFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	primary, _ := ctx.Value(contextPrimaryKey).(bool)
+	return primary
+}
+
+type replicaResolver struct {
+	replicas []gorm.ConnPool
+	next     uint64
+}
+
+func (r *replicaResolver) resolve(db *gorm.DB) {
+	// statements in transactions stay in them
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+	if _, ok := TxFromContext(db.Statement.Context); ok {
+		return
+	}
+	if primaryFromContext(db.Statement.Context) {
+		return
+	}
+	// SELECT ... FOR UPDATE locks rows of the primary
+	if _, ok := db.Statement.Clauses["FOR"]; ok {
+		return
+	}
+	n := atomic.AddUint64(&r.next, 1)
+	db.Statement.ConnPool = r.replicas[n%uint64(len(r.replicas))]
+}
+
+// resolveRaw routes the raw statements which only read to replicas
+func (r *replicaResolver) resolveRaw(db *gorm.DB) {
+	sql := strings.TrimSpace(db.Statement.SQL.String())
+	if len(sql) < len("SELECT") || !strings.EqualFold(sql[:len("SELECT")], "SELECT") {
+		return
+	}
+	r.resolve(db)
+}
+
+// UseReplicas routes the queries out of transactions to the replicas in turn, unless the context is
+// pinned to the primary by WithPrimary or a request transaction. Creates, updates and deletes always
+// go to the primary.
+func UseReplicas(db *gorm.DB, replicas ...gorm.ConnPool) {
+	if len(replicas) == 0 {
+		return
+	}
+	r := &replicaResolver{replicas: replicas}
+	_ = db.Callback().Query().Before("gorm:query").Register("use_replica", r.resolve)
+	_ = db.Callback().Row().Before("gorm:row").Register("use_replica", r.resolve)
+	_ = db.Callback().Raw().Before("gorm:raw").Register("use_replica", r.resolveRaw)
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const contextRequestTxKey = "contextRequestTx"
+
+// RequestTx is a transaction shared by all db operations of a request
+type RequestTx struct {
+	tx       *gorm.DB
+	finished int32
+
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+func RequestTxContextKey() string {
+	return contextRequestTxKey
+}
+
+// BeginRequestTx begins a transaction which can be attached to the context of a request
+func BeginRequestTx(db *gorm.DB) (*RequestTx, error) {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &RequestTx{tx: tx}, nil
+}
+
+func (t *RequestTx) Commit() error {
+	if !atomic.CompareAndSwapInt32(&t.finished, 0, 1) {
+		return nil
+	}
+	err := t.tx.Commit().Error
+	callbacks := t.takeAfterCommit()
+	if err != nil {
+		return err
+	}
+	for _, f := range callbacks {
+		f()
+	}
+	return nil
+}
+
+func (t *RequestTx) Rollback() error {
+	if !atomic.CompareAndSwapInt32(&t.finished, 0, 1) {
+		return nil
+	}
+	t.takeAfterCommit()
+	return t.tx.Rollback().Error
+}
+
+// AfterCommit registers f to run once the transaction is committed, f is dropped if it's rolled back.
+// It returns false if the transaction has finished already.
+func (t *RequestTx) AfterCommit(f func()) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if atomic.LoadInt32(&t.finished) == 1 {
+		return false
+	}
+	t.afterCommit = append(t.afterCommit, f)
+	return true
+}
+
+func (t *RequestTx) takeAfterCommit() []func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	callbacks := t.afterCommit
+	t.afterCommit = nil
+	return callbacks
+}
+
+// TxFromContext returns the unfinished request transaction attached to ctx,
+// operations issued after the request finished (e.g. by goroutines) run without it
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	t, ok := ctx.Value(contextRequestTxKey).(*RequestTx)
+	if !ok || atomic.LoadInt32(&t.finished) == 1 {
+		return nil, false
+	}
+	return t.tx, true
+}
+
+func WithRequestTx(parent context.Context, t *RequestTx) context.Context {
+	return context.WithValue(parent, contextRequestTxKey, t) // nolint
+}
+
+// AfterCommit runs f once the request transaction attached to ctx is committed,
+// or right away if there is no unfinished one, so that what f announces has been saved
+func AfterCommit(ctx context.Context, f func()) {
+	if ctx != nil {
+		if t, ok := ctx.Value(contextRequestTxKey).(*RequestTx); ok && t.AfterCommit(f) {
+			return
+		}
+	}
+	f()
+}
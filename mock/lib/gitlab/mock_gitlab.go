@@ -257,6 +257,22 @@ func (mr *MockInterfaceMockRecorder) GetFile(ctx, pid, ref, filepath interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockInterface)(nil).GetFile), ctx, pid, ref, filepath)
 }
 
+// GetFileAndCommit mocks base method.
+func (m *MockInterface) GetFileAndCommit(ctx context.Context, pid interface{}, ref, filepath string) ([]byte, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileAndCommit", ctx, pid, ref, filepath)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetFileAndCommit indicates an expected call of GetFileAndCommit.
+func (mr *MockInterfaceMockRecorder) GetFileAndCommit(ctx, pid, ref, filepath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileAndCommit", reflect.TypeOf((*MockInterface)(nil).GetFileAndCommit), ctx, pid, ref, filepath)
+}
+
 // GetGroup mocks base method.
 func (m *MockInterface) GetGroup(ctx context.Context, gid interface{}) (*gitlab0.Group, error) {
 	m.ctrl.T.Helper()
@@ -346,6 +362,21 @@ func (mr *MockInterfaceMockRecorder) ListBranch(ctx, pid, listBranchOptions inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranch", reflect.TypeOf((*MockInterface)(nil).ListBranch), ctx, pid, listBranchOptions)
 }
 
+// ListCommits mocks base method.
+func (m *MockInterface) ListCommits(ctx context.Context, pid interface{}, ref, filepath string, page, perPage int) ([]*gitlab0.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCommits", ctx, pid, ref, filepath, page, perPage)
+	ret0, _ := ret[0].([]*gitlab0.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCommits indicates an expected call of ListCommits.
+func (mr *MockInterfaceMockRecorder) ListCommits(ctx, pid, ref, filepath, page, perPage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommits", reflect.TypeOf((*MockInterface)(nil).ListCommits), ctx, pid, ref, filepath, page, perPage)
+}
+
 // ListGroupProjects mocks base method.
 func (m *MockInterface) ListGroupProjects(ctx context.Context, gid interface{}, page, perPage int) ([]*gitlab0.Project, error) {
 	m.ctrl.T.Helper()
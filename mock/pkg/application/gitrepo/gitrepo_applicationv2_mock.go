@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	gitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
+	common "github.com/horizoncd/horizon/pkg/common"
 )
 
 // MockApplicationGitRepo2 is a mock of ApplicationGitRepo interface.
@@ -64,6 +65,21 @@ func (mr *MockApplicationGitRepo2MockRecorder) GetApplication(ctx, application,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplication", reflect.TypeOf((*MockApplicationGitRepo2)(nil).GetApplication), ctx, application, environment)
 }
 
+// GetApplicationByCommit mocks base method.
+func (m *MockApplicationGitRepo2) GetApplicationByCommit(ctx context.Context, application, environment, commit string) (*gitrepo.GetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationByCommit", ctx, application, environment, commit)
+	ret0, _ := ret[0].(*gitrepo.GetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationByCommit indicates an expected call of GetApplicationByCommit.
+func (mr *MockApplicationGitRepo2MockRecorder) GetApplicationByCommit(ctx, application, environment, commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationByCommit", reflect.TypeOf((*MockApplicationGitRepo2)(nil).GetApplicationByCommit), ctx, application, environment, commit)
+}
+
 // HardDeleteApplication mocks base method.
 func (m *MockApplicationGitRepo2) HardDeleteApplication(ctx context.Context, application string) error {
 	m.ctrl.T.Helper()
@@ -77,3 +93,18 @@ func (mr *MockApplicationGitRepo2MockRecorder) HardDeleteApplication(ctx, applic
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteApplication", reflect.TypeOf((*MockApplicationGitRepo2)(nil).HardDeleteApplication), ctx, application)
 }
+
+// ListConfigCommits mocks base method.
+func (m *MockApplicationGitRepo2) ListConfigCommits(ctx context.Context, application, environment string, page, perPage int) ([]*common.ConfigCommit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConfigCommits", ctx, application, environment, page, perPage)
+	ret0, _ := ret[0].([]*common.ConfigCommit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConfigCommits indicates an expected call of ListConfigCommits.
+func (mr *MockApplicationGitRepo2MockRecorder) ListConfigCommits(ctx, application, environment, page, perPage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConfigCommits", reflect.TypeOf((*MockApplicationGitRepo2)(nil).ListConfigCommits), ctx, application, environment, page, perPage)
+}
@@ -77,6 +77,21 @@ func (mr *MockCDMockRecorder) DeployCluster(ctx, params interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployCluster", reflect.TypeOf((*MockCD)(nil).DeployCluster), ctx, params)
 }
 
+// GetClusterDrift mocks base method.
+func (m *MockCD) GetClusterDrift(ctx context.Context, params *cd.GetClusterDriftParams) (*cd.ClusterDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterDrift", ctx, params)
+	ret0, _ := ret[0].(*cd.ClusterDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterDrift indicates an expected call of GetClusterDrift.
+func (mr *MockCDMockRecorder) GetClusterDrift(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterDrift", reflect.TypeOf((*MockCD)(nil).GetClusterDrift), ctx, params)
+}
+
 // GetClusterState mocks base method.
 func (m *MockCD) GetClusterState(ctx context.Context, params *cd.GetClusterStateV2Params) (*cd.ClusterStateV2, error) {
 	m.ctrl.T.Helper()
@@ -92,6 +107,21 @@ func (mr *MockCDMockRecorder) GetClusterState(ctx, params interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterState", reflect.TypeOf((*MockCD)(nil).GetClusterState), ctx, params)
 }
 
+// GetManifests mocks base method.
+func (m *MockCD) GetManifests(ctx context.Context, params *cd.GetManifestsParams) ([]map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManifests", ctx, params)
+	ret0, _ := ret[0].([]map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManifests indicates an expected call of GetManifests.
+func (mr *MockCDMockRecorder) GetManifests(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManifests", reflect.TypeOf((*MockCD)(nil).GetManifests), ctx, params)
+}
+
 // GetPodEvents mocks base method.
 func (m *MockCD) GetPodEvents(ctx context.Context, params *cd.GetPodEventsParams) ([]cd.Event, error) {
 	m.ctrl.T.Helper()
@@ -136,3 +166,17 @@ func (mr *MockCDMockRecorder) GetStep(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStep", reflect.TypeOf((*MockCD)(nil).GetStep), ctx, params)
 }
+
+// ListQueuedOperations mocks base method.
+func (m *MockCD) ListQueuedOperations(ctx context.Context, cluster string) []*cd.QueuedOperation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQueuedOperations", ctx, cluster)
+	ret0, _ := ret[0].([]*cd.QueuedOperation)
+	return ret0
+}
+
+// ListQueuedOperations indicates an expected call of ListQueuedOperations.
+func (mr *MockCDMockRecorder) ListQueuedOperations(ctx, cluster interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQueuedOperations", reflect.TypeOf((*MockCD)(nil).ListQueuedOperations), ctx, cluster)
+}
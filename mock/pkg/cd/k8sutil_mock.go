@@ -6,6 +6,7 @@ package mock_cd
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -80,6 +81,36 @@ func (mr *MockK8sUtilMockRecorder) ExecuteAction(ctx, params interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteAction", reflect.TypeOf((*MockK8sUtil)(nil).ExecuteAction), ctx, params)
 }
 
+// GetAutoscalingStatus mocks base method.
+func (m *MockK8sUtil) GetAutoscalingStatus(ctx context.Context, params *cd.GetAutoscalingStatusParams) (*cd.AutoscalingStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutoscalingStatus", ctx, params)
+	ret0, _ := ret[0].(*cd.AutoscalingStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutoscalingStatus indicates an expected call of GetAutoscalingStatus.
+func (mr *MockK8sUtilMockRecorder) GetAutoscalingStatus(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutoscalingStatus", reflect.TypeOf((*MockK8sUtil)(nil).GetAutoscalingStatus), ctx, params)
+}
+
+// GetClusterResource mocks base method.
+func (m *MockK8sUtil) GetClusterResource(ctx context.Context, params *cd.GetClusterResourceParams) (*cd.ClusterResourceDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterResource", ctx, params)
+	ret0, _ := ret[0].(*cd.ClusterResourceDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterResource indicates an expected call of GetClusterResource.
+func (mr *MockK8sUtilMockRecorder) GetClusterResource(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterResource", reflect.TypeOf((*MockK8sUtil)(nil).GetClusterResource), ctx, params)
+}
+
 // GetContainerLog mocks base method.
 func (m *MockK8sUtil) GetContainerLog(ctx context.Context, params *cd.GetContainerLogParams) (<-chan string, error) {
 	m.ctrl.T.Helper()
@@ -124,3 +155,62 @@ func (mr *MockK8sUtilMockRecorder) GetPodContainers(ctx, params interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodContainers", reflect.TypeOf((*MockK8sUtil)(nil).GetPodContainers), ctx, params)
 }
+
+// KubeProxy mocks base method.
+func (m *MockK8sUtil) KubeProxy(ctx context.Context, params *cd.KubeProxyParams) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KubeProxy", ctx, params)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KubeProxy indicates an expected call of KubeProxy.
+func (mr *MockK8sUtilMockRecorder) KubeProxy(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KubeProxy", reflect.TypeOf((*MockK8sUtil)(nil).KubeProxy), ctx, params)
+}
+
+// ListClusterResources mocks base method.
+func (m *MockK8sUtil) ListClusterResources(ctx context.Context, params *cd.ListClusterResourcesParams) ([]*cd.ClusterResource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterResources", ctx, params)
+	ret0, _ := ret[0].([]*cd.ClusterResource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterResources indicates an expected call of ListClusterResources.
+func (mr *MockK8sUtilMockRecorder) ListClusterResources(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterResources", reflect.TypeOf((*MockK8sUtil)(nil).ListClusterResources), ctx, params)
+}
+
+// Shell mocks base method.
+func (m *MockK8sUtil) Shell(ctx context.Context, params *cd.ShellParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shell", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shell indicates an expected call of Shell.
+func (mr *MockK8sUtilMockRecorder) Shell(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shell", reflect.TypeOf((*MockK8sUtil)(nil).Shell), ctx, params)
+}
+
+// StreamContainerLog mocks base method.
+func (m *MockK8sUtil) StreamContainerLog(ctx context.Context, params *cd.StreamContainerLogParams) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamContainerLog", ctx, params)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamContainerLog indicates an expected call of StreamContainerLog.
+func (mr *MockK8sUtilMockRecorder) StreamContainerLog(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamContainerLog", reflect.TypeOf((*MockK8sUtil)(nil).StreamContainerLog), ctx, params)
+}
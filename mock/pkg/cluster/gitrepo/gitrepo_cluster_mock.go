@@ -37,6 +37,20 @@ func (m *MockClusterGitRepo) EXPECT() *MockClusterGitRepoMockRecorder {
 	return m.recorder
 }
 
+// CloseBranch mocks base method.
+func (m *MockClusterGitRepo) CloseBranch(ctx context.Context, application, cluster, branch string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseBranch", ctx, application, cluster, branch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseBranch indicates an expected call of CloseBranch.
+func (mr *MockClusterGitRepoMockRecorder) CloseBranch(ctx, application, cluster, branch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseBranch", reflect.TypeOf((*MockClusterGitRepo)(nil).CloseBranch), ctx, application, cluster, branch)
+}
+
 // CompareConfig mocks base method.
 func (m *MockClusterGitRepo) CompareConfig(ctx context.Context, application, cluster string, from, to *string) (string, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +66,20 @@ func (mr *MockClusterGitRepoMockRecorder) CompareConfig(ctx, application, cluste
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareConfig", reflect.TypeOf((*MockClusterGitRepo)(nil).CompareConfig), ctx, application, cluster, from, to)
 }
 
+// CreateBranch mocks base method.
+func (m *MockClusterGitRepo) CreateBranch(ctx context.Context, application, cluster, branch string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBranch", ctx, application, cluster, branch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBranch indicates an expected call of CreateBranch.
+func (mr *MockClusterGitRepoMockRecorder) CreateBranch(ctx, application, cluster, branch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBranch", reflect.TypeOf((*MockClusterGitRepo)(nil).CreateBranch), ctx, application, cluster, branch)
+}
+
 // CreateCluster mocks base method.
 func (m *MockClusterGitRepo) CreateCluster(ctx context.Context, params *gitrepo.CreateClusterParams) error {
 	m.ctrl.T.Helper()
@@ -66,6 +94,21 @@ func (mr *MockClusterGitRepoMockRecorder) CreateCluster(ctx, params interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).CreateCluster), ctx, params)
 }
 
+// CreateMergeRequest mocks base method.
+func (m *MockClusterGitRepo) CreateMergeRequest(ctx context.Context, application, cluster, sourceBranch, targetBranch, title string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMergeRequest", ctx, application, cluster, sourceBranch, targetBranch, title)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMergeRequest indicates an expected call of CreateMergeRequest.
+func (mr *MockClusterGitRepoMockRecorder) CreateMergeRequest(ctx, application, cluster, sourceBranch, targetBranch, title interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequest", reflect.TypeOf((*MockClusterGitRepo)(nil).CreateMergeRequest), ctx, application, cluster, sourceBranch, targetBranch, title)
+}
+
 // DefaultBranch mocks base method.
 func (m *MockClusterGitRepo) DefaultBranch() string {
 	m.ctrl.T.Helper()
@@ -109,6 +152,21 @@ func (mr *MockClusterGitRepoMockRecorder) GetCluster(ctx, application, cluster,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).GetCluster), ctx, application, cluster, templateName)
 }
 
+// GetClusterByCommit mocks base method.
+func (m *MockClusterGitRepo) GetClusterByCommit(ctx context.Context, application, cluster, templateName, commit string) (*gitrepo.ClusterFiles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterByCommit", ctx, application, cluster, templateName, commit)
+	ret0, _ := ret[0].(*gitrepo.ClusterFiles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterByCommit indicates an expected call of GetClusterByCommit.
+func (mr *MockClusterGitRepoMockRecorder) GetClusterByCommit(ctx, application, cluster, templateName, commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterByCommit", reflect.TypeOf((*MockClusterGitRepo)(nil).GetClusterByCommit), ctx, application, cluster, templateName, commit)
+}
+
 // GetClusterTemplate mocks base method.
 func (m *MockClusterGitRepo) GetClusterTemplate(ctx context.Context, application, cluster string) (*gitrepo.ClusterTemplate, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +212,21 @@ func (mr *MockClusterGitRepoMockRecorder) GetConfigCommit(ctx, application, clus
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigCommit", reflect.TypeOf((*MockClusterGitRepo)(nil).GetConfigCommit), ctx, application, cluster)
 }
 
+// GetConfigValues mocks base method.
+func (m *MockClusterGitRepo) GetConfigValues(ctx context.Context, application, cluster, commit string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigValues", ctx, application, cluster, commit)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigValues indicates an expected call of GetConfigValues.
+func (mr *MockClusterGitRepoMockRecorder) GetConfigValues(ctx, application, cluster, commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigValues", reflect.TypeOf((*MockClusterGitRepo)(nil).GetConfigValues), ctx, application, cluster, commit)
+}
+
 // GetEnvValue mocks base method.
 func (m *MockClusterGitRepo) GetEnvValue(ctx context.Context, application, cluster, templateName string) (*gitrepo.EnvValue, error) {
 	m.ctrl.T.Helper()
@@ -242,6 +315,21 @@ func (mr *MockClusterGitRepoMockRecorder) HardDeleteCluster(ctx, application, cl
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).HardDeleteCluster), ctx, application, cluster)
 }
 
+// ListConfigCommits mocks base method.
+func (m *MockClusterGitRepo) ListConfigCommits(ctx context.Context, application, cluster string, page, perPage int) ([]*common.ConfigCommit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConfigCommits", ctx, application, cluster, page, perPage)
+	ret0, _ := ret[0].([]*common.ConfigCommit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConfigCommits indicates an expected call of ListConfigCommits.
+func (mr *MockClusterGitRepoMockRecorder) ListConfigCommits(ctx, application, cluster, page, perPage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConfigCommits", reflect.TypeOf((*MockClusterGitRepo)(nil).ListConfigCommits), ctx, application, cluster, page, perPage)
+}
+
 // MergeBranch mocks base method.
 func (m *MockClusterGitRepo) MergeBranch(ctx context.Context, application, cluster, sourceBranch, targetBranch string, pipelineRunID *uint) (string, error) {
 	m.ctrl.T.Helper()
@@ -257,6 +345,50 @@ func (mr *MockClusterGitRepoMockRecorder) MergeBranch(ctx, application, cluster,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeBranch", reflect.TypeOf((*MockClusterGitRepo)(nil).MergeBranch), ctx, application, cluster, sourceBranch, targetBranch, pipelineRunID)
 }
 
+// PurgeCluster mocks base method.
+func (m *MockClusterGitRepo) PurgeCluster(ctx context.Context, application, cluster string, clusterID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeCluster", ctx, application, cluster, clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeCluster indicates an expected call of PurgeCluster.
+func (mr *MockClusterGitRepoMockRecorder) PurgeCluster(ctx, application, cluster, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).PurgeCluster), ctx, application, cluster, clusterID)
+}
+
+// RenderCluster mocks base method.
+func (m *MockClusterGitRepo) RenderCluster(ctx context.Context, params *gitrepo.UpdateClusterParams) (map[string]interface{}, map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderCluster", ctx, params)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(map[string]interface{})
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RenderCluster indicates an expected call of RenderCluster.
+func (mr *MockClusterGitRepoMockRecorder) RenderCluster(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).RenderCluster), ctx, params)
+}
+
+// RestoreCluster mocks base method.
+func (m *MockClusterGitRepo) RestoreCluster(ctx context.Context, application, cluster string, clusterID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCluster", ctx, application, cluster, clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreCluster indicates an expected call of RestoreCluster.
+func (mr *MockClusterGitRepoMockRecorder) RestoreCluster(ctx, application, cluster, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).RestoreCluster), ctx, application, cluster, clusterID)
+}
+
 // Rollback mocks base method.
 func (m *MockClusterGitRepo) Rollback(ctx context.Context, application, cluster, commit string) (string, error) {
 	m.ctrl.T.Helper()
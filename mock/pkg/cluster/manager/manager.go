@@ -7,6 +7,7 @@ package mock_manager
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	q "github.com/horizoncd/horizon/lib/q"
@@ -141,6 +142,35 @@ func (mr *MockManagerMockRecorder) GetByNameFuzzilyIncludeSoftDelete(ctx, name i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByNameFuzzilyIncludeSoftDelete", reflect.TypeOf((*MockManager)(nil).GetByNameFuzzilyIncludeSoftDelete), ctx, name)
 }
 
+// GetDeletedByName mocks base method.
+func (m *MockManager) GetDeletedByName(ctx context.Context, name string) ([]*models.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedByName", ctx, name)
+	ret0, _ := ret[0].([]*models.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedByName indicates an expected call of GetDeletedByName.
+func (mr *MockManagerMockRecorder) GetDeletedByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedByName", reflect.TypeOf((*MockManager)(nil).GetDeletedByName), ctx, name)
+}
+
+// HardDeleteByID mocks base method.
+func (m *MockManager) HardDeleteByID(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HardDeleteByID", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HardDeleteByID indicates an expected call of HardDeleteByID.
+func (mr *MockManagerMockRecorder) HardDeleteByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteByID", reflect.TypeOf((*MockManager)(nil).HardDeleteByID), ctx, id)
+}
+
 // List mocks base method.
 func (m *MockManager) List(ctx context.Context, query *q.Query, appIDs ...uint) (int, []*models.ClusterWithRegion, error) {
 	m.ctrl.T.Helper()
@@ -193,6 +223,64 @@ func (mr *MockManagerMockRecorder) ListClusterWithExpiry(ctx, query interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterWithExpiry", reflect.TypeOf((*MockManager)(nil).ListClusterWithExpiry), ctx, query)
 }
 
+// ListDeleted mocks base method.
+func (m *MockManager) ListDeleted(ctx context.Context, applicationID uint) ([]*models.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, applicationID)
+	ret0, _ := ret[0].([]*models.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockManagerMockRecorder) ListDeleted(ctx, applicationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockManager)(nil).ListDeleted), ctx, applicationID)
+}
+
+// ListDeletedBefore mocks base method.
+func (m *MockManager) ListDeletedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]*models.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedBefore", ctx, before, afterID, limit)
+	ret0, _ := ret[0].([]*models.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedBefore indicates an expected call of ListDeletedBefore.
+func (mr *MockManagerMockRecorder) ListDeletedBefore(ctx, before, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedBefore", reflect.TypeOf((*MockManager)(nil).ListDeletedBefore), ctx, before, afterID, limit)
+}
+
+// Purge mocks base method.
+func (m *MockManager) Purge(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockManagerMockRecorder) Purge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockManager)(nil).Purge), ctx, id)
+}
+
+// Restore mocks base method.
+func (m *MockManager) Restore(ctx context.Context, id uint, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockManagerMockRecorder) Restore(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockManager)(nil).Restore), ctx, id, status)
+}
+
 // UpdateByID mocks base method.
 func (m *MockManager) UpdateByID(ctx context.Context, id uint, cluster *models.Cluster) (*models.Cluster, error) {
 	m.ctrl.T.Helper()
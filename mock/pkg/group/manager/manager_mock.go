@@ -294,6 +294,21 @@ func (mr *MockManagerMockRecorder) GetSubGroupsUnderParentIDs(ctx, parentIDs int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubGroupsUnderParentIDs", reflect.TypeOf((*MockManager)(nil).GetSubGroupsUnderParentIDs), ctx, parentIDs)
 }
 
+// GetTree mocks base method.
+func (m *MockManager) GetTree(ctx context.Context) (*models0.GroupTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTree", ctx)
+	ret0, _ := ret[0].(*models0.GroupTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTree indicates an expected call of GetTree.
+func (mr *MockManagerMockRecorder) GetTree(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockManager)(nil).GetTree), ctx)
+}
+
 // GroupExist mocks base method.
 func (m *MockManager) GroupExist(ctx context.Context, groupID uint) bool {
 	m.ctrl.T.Helper()
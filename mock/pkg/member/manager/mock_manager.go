@@ -137,6 +137,21 @@ func (mr *MockManagerMockRecorder) HardDeleteMemberByResourceTypeID(ctx, resourc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteMemberByResourceTypeID", reflect.TypeOf((*MockManager)(nil).HardDeleteMemberByResourceTypeID), ctx, resourceType, resourceID)
 }
 
+// ListAll mocks base method.
+func (m *MockManager) ListAll(ctx context.Context) ([]models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAll", ctx)
+	ret0, _ := ret[0].([]models.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAll indicates an expected call of ListAll.
+func (mr *MockManagerMockRecorder) ListAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockManager)(nil).ListAll), ctx)
+}
+
 // ListDirectMember mocks base method.
 func (m *MockManager) ListDirectMember(ctx context.Context, resourceType models.ResourceType, resourceID uint) ([]models.Member, error) {
 	m.ctrl.T.Helper()
@@ -7,6 +7,7 @@ package mock_manager
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	q "github.com/horizoncd/horizon/lib/q"
@@ -190,6 +191,46 @@ func (mr *MockPipelineRunManagerMockRecorder) GetLatestSuccessByClusterID(ctx, c
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSuccessByClusterID", reflect.TypeOf((*MockPipelineRunManager)(nil).GetLatestSuccessByClusterID), ctx, clusterID)
 }
 
+// ListByClusterIDsAndStatuses mocks base method.
+func (m *MockPipelineRunManager) ListByClusterIDsAndStatuses(ctx context.Context, clusterIDs []uint, statuses ...models.PipelineStatus) ([]*models.Pipelinerun, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, clusterIDs}
+	for _, a := range statuses {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListByClusterIDsAndStatuses", varargs...)
+	ret0, _ := ret[0].([]*models.Pipelinerun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByClusterIDsAndStatuses indicates an expected call of ListByClusterIDsAndStatuses.
+func (mr *MockPipelineRunManagerMockRecorder) ListByClusterIDsAndStatuses(ctx, clusterIDs interface{}, statuses ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, clusterIDs}, statuses...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByClusterIDsAndStatuses", reflect.TypeOf((*MockPipelineRunManager)(nil).ListByClusterIDsAndStatuses), varargs...)
+}
+
+// ListByTimeRange mocks base method.
+func (m *MockPipelineRunManager) ListByTimeRange(ctx context.Context, start, end time.Time, actions ...string) ([]*models.Pipelinerun, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, start, end}
+	for _, a := range actions {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListByTimeRange", varargs...)
+	ret0, _ := ret[0].([]*models.Pipelinerun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByTimeRange indicates an expected call of ListByTimeRange.
+func (mr *MockPipelineRunManagerMockRecorder) ListByTimeRange(ctx, start, end interface{}, actions ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, start, end}, actions...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByTimeRange", reflect.TypeOf((*MockPipelineRunManager)(nil).ListByTimeRange), varargs...)
+}
+
 // UpdateCIEventIDByID mocks base method.
 func (m *MockPipelineRunManager) UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error {
 	m.ctrl.T.Helper()
@@ -109,6 +109,21 @@ func (mr *MockManagerMockRecorder) ListByResourceTypeIDs(ctx, resourceType, reso
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByResourceTypeIDs", reflect.TypeOf((*MockManager)(nil).ListByResourceTypeIDs), ctx, resourceType, resourceIDs, deduplicate)
 }
 
+// ListResourceIDsByTagSelectors mocks base method.
+func (m *MockManager) ListResourceIDsByTagSelectors(ctx context.Context, resourceType string, tagSelectors []models.TagSelector) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListResourceIDsByTagSelectors", ctx, resourceType, tagSelectors)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListResourceIDsByTagSelectors indicates an expected call of ListResourceIDsByTagSelectors.
+func (mr *MockManagerMockRecorder) ListResourceIDsByTagSelectors(ctx, resourceType, tagSelectors interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResourceIDsByTagSelectors", reflect.TypeOf((*MockManager)(nil).ListResourceIDsByTagSelectors), ctx, resourceType, tagSelectors)
+}
+
 // UpsertByResourceTypeID mocks base method.
 func (m *MockManager) UpsertByResourceTypeID(ctx context.Context, resourceType string, resourceID uint, tags []*models.TagBasic) error {
 	m.ctrl.T.Helper()
@@ -7,6 +7,7 @@ package mock_manager
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/horizoncd/horizon/pkg/application/models"
@@ -37,6 +38,21 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// CountDeploysByStatus mocks base method.
+func (m *MockManager) CountDeploysByStatus(ctx context.Context, id uint, actions []string, since time.Time) ([]*models1.DeployStatusCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeploysByStatus", ctx, id, actions, since)
+	ret0, _ := ret[0].([]*models1.DeployStatusCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeploysByStatus indicates an expected call of CountDeploysByStatus.
+func (mr *MockManagerMockRecorder) CountDeploysByStatus(ctx, id, actions, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeploysByStatus", reflect.TypeOf((*MockManager)(nil).CountDeploysByStatus), ctx, id, actions, since)
+}
+
 // Create mocks base method.
 func (m *MockManager) Create(ctx context.Context, templateRelease *models1.TemplateRelease) (*models1.TemplateRelease, error) {
 	m.ctrl.T.Helper()
@@ -171,3 +187,31 @@ func (mr *MockManagerMockRecorder) UpdateByID(ctx, releaseID, release interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockManager)(nil).UpdateByID), ctx, releaseID, release)
 }
+
+// UpdateCanaryByID mocks base method.
+func (m *MockManager) UpdateCanaryByID(ctx context.Context, releaseID uint, release *models1.TemplateRelease) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCanaryByID", ctx, releaseID, release)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCanaryByID indicates an expected call of UpdateCanaryByID.
+func (mr *MockManagerMockRecorder) UpdateCanaryByID(ctx, releaseID, release interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCanaryByID", reflect.TypeOf((*MockManager)(nil).UpdateCanaryByID), ctx, releaseID, release)
+}
+
+// UpdateChannelByID mocks base method.
+func (m *MockManager) UpdateChannelByID(ctx context.Context, releaseID uint, release *models1.TemplateRelease) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChannelByID", ctx, releaseID, release)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChannelByID indicates an expected call of UpdateChannelByID.
+func (mr *MockManagerMockRecorder) UpdateChannelByID(ctx, releaseID, release interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChannelByID", reflect.TypeOf((*MockManager)(nil).UpdateChannelByID), ctx, releaseID, release)
+}
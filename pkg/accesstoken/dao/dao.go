@@ -66,6 +66,8 @@ func (d *dao) ListAccessTokensByResource(ctx context.Context, resourceType strin
 		Joins("join tb_user as u on t.user_id = u.id").
 		Joins("join tb_member as m on u.id = m.membername_id").
 		Where("u.user_type = ?", usermodels.UserTypeRobot).
+		// the tokens of robot accounts are listed by their robots
+		Where("u.id not in (?)", d.db.Table("tb_robot").Select("user_id")).
 		Where("m.resource_type = ?", resourceType).
 		Where("m.resource_id = ?", resourceID).
 		Select("t.*, m.role as role").Offset(offset).Limit(limit).Scan(&tokens).Offset(0).Limit(-1).Count(&total)
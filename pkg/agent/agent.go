@@ -0,0 +1,248 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	// _pollRequestTimeout must be longer than the poll timeout of horizon
+	_pollRequestTimeout = 2 * time.Minute
+	_minBackoff         = time.Second
+	_maxBackoff         = time.Minute
+	// _chunkSize is the max body size of a response part of streaming requests
+	_chunkSize = 32 * 1024
+)
+
+// Agent runs inside the cluster of a region. It polls kubernetes requests of the region from horizon,
+// performs them with the local rest config and posts the responses back, so only outbound
+// connections from the cluster to horizon are needed.
+type Agent struct {
+	server string
+	region string
+	token  string
+	client *http.Client
+
+	kubeHost      string
+	kubeTransport http.RoundTripper
+}
+
+func New(server, region, token string, restConfig *rest.Config) (*Agent, error) {
+	transport, err := rest.TransportFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	host := restConfig.Host
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	return &Agent{
+		server:        strings.TrimSuffix(server, "/"),
+		region:        region,
+		token:         token,
+		client:        &http.Client{},
+		kubeHost:      hostURL.Scheme + "://" + hostURL.Host + strings.TrimSuffix(hostURL.Path, "/"),
+		kubeTransport: transport,
+	}, nil
+}
+
+// Run polls and performs requests until ctx is done
+func (a *Agent) Run(ctx context.Context) error {
+	log.Infof(ctx, "agent of region %s started, polling requests from %s", a.region, a.server)
+	backoff := _minBackoff
+	for {
+		requests, err := a.poll(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			log.Errorf(ctx, "failed to poll requests of region %s, retry in %v: %v", a.region, backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if backoff *= 2; backoff > _maxBackoff {
+				backoff = _maxBackoff
+			}
+			continue
+		}
+		backoff = _minBackoff
+		for _, request := range requests {
+			go a.serve(ctx, request)
+		}
+	}
+}
+
+func (a *Agent) poll(ctx context.Context) ([]*Request, error) {
+	ctx, cancel := context.WithTimeout(ctx, _pollRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.server+PollPath(a.region), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(TokenHeader, a.token)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d: %s", resp.StatusCode, string(body))
+	}
+	var result struct {
+		Data []*Request `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// serve performs the request and posts its response, bodies of watches and
+// followed logs are streamed in parts as they are read
+func (a *Agent) serve(ctx context.Context, request *Request) {
+	resp, err := a.perform(ctx, request)
+	if err != nil {
+		a.respondError(ctx, request, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if !streaming(request) {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			a.respondError(ctx, request, err)
+			return
+		}
+		_ = a.respond(ctx, &Response{
+			ID:         request.ID,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       body,
+			Done:       true,
+		})
+		return
+	}
+
+	if err := a.respond(ctx, &Response{
+		ID:         request.ID,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}); err != nil {
+		return
+	}
+	buf := make([]byte, _chunkSize)
+	for {
+		n, err := resp.Body.Read(buf)
+		part := &Response{ID: request.ID}
+		if n > 0 {
+			part.Body = buf[:n]
+		}
+		if err == io.EOF {
+			part.Done = true
+		} else if err != nil {
+			part.Error = err.Error()
+		}
+		if part.Body == nil && !part.Done && part.Error == "" {
+			continue
+		}
+		// the request is closed by horizon when the part is not accepted
+		if err := a.respond(ctx, part); err != nil || part.Done || part.Error != "" {
+			return
+		}
+	}
+}
+
+func (a *Agent) perform(ctx context.Context, request *Request) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, request.Method, a.kubeHost+request.URI, bytes.NewReader(request.Body))
+	if err != nil {
+		return nil, err
+	}
+	if request.Header != nil {
+		req.Header = request.Header
+	}
+	return a.kubeTransport.RoundTrip(req)
+}
+
+func (a *Agent) respondError(ctx context.Context, request *Request, err error) {
+	log.Errorf(ctx, "failed to perform %s %s: %v", request.Method, request.URI, err)
+	_ = a.respond(ctx, &Response{
+		ID:    request.ID,
+		Error: err.Error(),
+	})
+}
+
+func (a *Agent) respond(ctx context.Context, part *Response) error {
+	body, err := json.Marshal(part)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.server+RespondPath(a.region), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(TokenHeader, a.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		log.Errorf(ctx, "failed to respond request %s: %v", part.ID, err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return perror.Errorf("failed to respond request %s, status code %d: %s", part.ID, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// streaming reports whether the response of the request is streamed, such as watches and followed logs
+func streaming(request *Request) bool {
+	u, err := url.Parse(request.URI)
+	if err != nil {
+		return false
+	}
+	query := u.Query()
+	for _, key := range []string{"watch", "follow"} {
+		if value := query.Get(key); value == "true" || value == "1" {
+			return true
+		}
+	}
+	return false
+}
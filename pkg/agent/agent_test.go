@@ -0,0 +1,135 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+
+	agentconfig "github.com/horizoncd/horizon/pkg/config/agent"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestAgent(t *testing.T) {
+	// kube serves the requests performed by the agent
+	kube := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") == "true" {
+			for i := 0; i < 2; i++ {
+				fmt.Fprintf(w, "event-%d\n", i)
+				w.(http.Flusher).Flush()
+			}
+			return
+		}
+		w.Header().Set("X-Method", r.Method)
+		fmt.Fprintf(w, "%s?%s", r.URL.Path, r.URL.RawQuery)
+	}))
+	defer kube.Close()
+
+	hub := NewHub(agentconfig.Config{
+		Regions:        map[string]agentconfig.Region{"hz": {Token: "token"}},
+		PollTimeout:    100 * time.Millisecond,
+		OfflineTimeout: time.Second,
+		RequestTimeout: 2 * time.Second,
+	})
+	assert.True(t, hub.Serves("hz"))
+	assert.False(t, hub.Serves("sh"))
+	assert.True(t, hub.Authenticate("hz", "token"))
+	assert.False(t, hub.Authenticate("hz", "invalid"))
+	assert.False(t, hub.Authenticate("sh", "token"))
+
+	// horizon serves the polls and responses of the agent
+	horizon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hub.Authenticate("hz", r.Header.Get(TokenHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case PollPath("hz"):
+			requests, err := hub.Poll(r.Context(), "hz")
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": requests})
+		case RespondPath("hz"):
+			var resp Response
+			_ = json.NewDecoder(r.Body).Decode(&resp)
+			if err := hub.Respond(r.Context(), "hz", &resp); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer horizon.Close()
+
+	req, err := http.NewRequest(http.MethodGet, Host+"/api/v1/namespaces", nil)
+	assert.Nil(t, err)
+	_, err = hub.RoundTripper("hz").RoundTrip(req)
+	assert.Equal(t, ErrAgentOffline, perror.Cause(err))
+
+	a, err := New(horizon.URL, "hz", "token", &rest.Config{Host: kube.URL})
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = a.Run(ctx) }()
+
+	client := &http.Client{Transport: hub.RoundTripper("hz")}
+
+	var resp *http.Response
+	assert.Eventually(t, func() bool {
+		resp, err = client.Get(Host + "/api/v1/namespaces?limit=1")
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.MethodGet, resp.Header.Get("X-Method"))
+	assert.Equal(t, "/api/v1/namespaces?limit=1", string(body))
+
+	// watches are streamed
+	resp, err = client.Get(Host + "/api/v1/pods?watch=true")
+	assert.Nil(t, err)
+	scanner := bufio.NewScanner(resp.Body)
+	events := make([]string, 0)
+	for scanner.Scan() {
+		events = append(events, scanner.Text())
+	}
+	assert.Nil(t, scanner.Err())
+	assert.Nil(t, resp.Body.Close())
+	assert.Equal(t, []string{"event-0", "event-1"}, events)
+
+	// upgrade requests are not supported
+	req, err = http.NewRequest(http.MethodPost, Host+"/api/v1/namespaces/default/pods/web/exec", nil)
+	assert.Nil(t, err)
+	req.Header.Set("Upgrade", "SPDY/3.1")
+	_, err = hub.RoundTripper("hz").RoundTrip(req)
+	assert.Equal(t, ErrRequestUnsupported, perror.Cause(err))
+
+	// responses of unknown requests are rejected, so that agents stop streaming them
+	err = hub.Respond(ctx, "hz", &Response{ID: "unknown", Done: true})
+	assert.Equal(t, ErrRequestNotFound, perror.Cause(err))
+}
@@ -0,0 +1,325 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	agentconfig "github.com/horizoncd/horizon/pkg/config/agent"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+var (
+	ErrRegionNotServed    = perror.New("region is not served by agent")
+	ErrAgentOffline       = perror.New("agent is offline")
+	ErrRequestUnsupported = perror.New("request is not supported by agent")
+	ErrRequestTimeout     = perror.New("request to agent timeout")
+	ErrRequestNotFound    = perror.New("request to agent not found")
+)
+
+const (
+	// _queueSize is the number of requests queued for each region before senders block
+	_queueSize = 256
+	// _pollBatchSize is the max number of requests returned by a poll
+	_pollBatchSize = 32
+)
+
+// Hub forwards kubernetes requests of regions served by agents to their agents
+type Hub interface {
+	// Serves reports whether the region is served by an agent
+	Serves(region string) bool
+	// Authenticate checks the token presented by the agent of the region
+	Authenticate(region, token string) bool
+	// RoundTripper returns a round tripper forwarding requests to the agent of the region,
+	// upgrade requests such as exec and port forward are not supported
+	RoundTripper(region string) http.RoundTripper
+	// Poll waits for requests to the region, it returns empty when none is queued in poll timeout
+	Poll(ctx context.Context, region string) ([]*Request, error)
+	// Respond delivers a part of the response to the waiting request,
+	// ErrRequestNotFound is returned when the request is timeout or closed by its sender
+	Respond(ctx context.Context, region string, resp *Response) error
+}
+
+type hub struct {
+	config agentconfig.Config
+
+	// mu protects lastPoll, polling and pending of regions
+	mu      sync.Mutex
+	regions map[string]*region
+}
+
+type region struct {
+	requests chan *Request
+	lastPoll time.Time
+	polling  int
+	pending  map[string]*pending
+}
+
+// pending is a request waiting for its response
+type pending struct {
+	first   chan *Response
+	started bool
+	reader  *io.PipeReader
+	writer  *io.PipeWriter
+}
+
+var _ Hub = (*hub)(nil)
+
+func NewHub(config agentconfig.Config) Hub {
+	regions := make(map[string]*region, len(config.Regions))
+	for name := range config.Regions {
+		regions[name] = &region{
+			requests: make(chan *Request, _queueSize),
+			pending:  make(map[string]*pending),
+		}
+	}
+	return &hub{
+		config:  config,
+		regions: regions,
+	}
+}
+
+func (h *hub) Serves(name string) bool {
+	_, ok := h.regions[name]
+	return ok
+}
+
+func (h *hub) Authenticate(name, token string) bool {
+	config, ok := h.config.Regions[name]
+	if !ok || config.Token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(config.Token), []byte(token)) == 1
+}
+
+func (h *hub) RoundTripper(name string) http.RoundTripper {
+	return &roundTripper{hub: h, region: name}
+}
+
+func (h *hub) Poll(ctx context.Context, name string) ([]*Request, error) {
+	r, ok := h.regions[name]
+	if !ok {
+		return nil, perror.Wrapf(ErrRegionNotServed, "region %s", name)
+	}
+
+	h.mu.Lock()
+	r.polling++
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		r.polling--
+		r.lastPoll = time.Now()
+		h.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(h.config.PollTimeout)
+	defer timer.Stop()
+	requests := make([]*Request, 0)
+	for len(requests) == 0 {
+		select {
+		case request := <-r.requests:
+			if h.isPending(r, request.ID) {
+				requests = append(requests, request)
+			}
+		case <-timer.C:
+			return requests, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	for len(requests) < _pollBatchSize {
+		select {
+		case request := <-r.requests:
+			if h.isPending(r, request.ID) {
+				requests = append(requests, request)
+			}
+		default:
+			return requests, nil
+		}
+	}
+	return requests, nil
+}
+
+func (h *hub) Respond(ctx context.Context, name string, resp *Response) error {
+	r, ok := h.regions[name]
+	if !ok {
+		return perror.Wrapf(ErrRegionNotServed, "region %s", name)
+	}
+
+	h.mu.Lock()
+	p, ok := r.pending[resp.ID]
+	first := ok && !p.started
+	if ok {
+		p.started = true
+		if resp.Done || resp.Error != "" {
+			delete(r.pending, resp.ID)
+		}
+	}
+	h.mu.Unlock()
+	if !ok {
+		return perror.Wrapf(ErrRequestNotFound, "request %s", resp.ID)
+	}
+
+	if first {
+		// first is buffered and only sent once
+		p.first <- resp
+		if resp.Error != "" {
+			return nil
+		}
+	} else if resp.Error != "" {
+		p.writer.CloseWithError(errors.New(resp.Error))
+		return nil
+	}
+	if len(resp.Body) > 0 {
+		if err := writeBody(ctx, p, resp.Body); err != nil {
+			h.remove(r, resp.ID)
+			return perror.Wrapf(ErrRequestNotFound, "request %s is closed: %v", resp.ID, err)
+		}
+	}
+	if resp.Done {
+		_ = p.writer.Close()
+	}
+	return nil
+}
+
+// writeBody blocks until the body is read by the sender of the request, or ctx is done
+func writeBody(ctx context.Context, p *pending, body []byte) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = p.reader.CloseWithError(ctx.Err())
+		case <-done:
+		}
+	}()
+	_, err := p.writer.Write(body)
+	return err
+}
+
+func (h *hub) isPending(r *region, id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := r.pending[id]
+	return ok
+}
+
+func (h *hub) remove(r *region, id string) {
+	h.mu.Lock()
+	p, ok := r.pending[id]
+	delete(r.pending, id)
+	h.mu.Unlock()
+	if ok {
+		_ = p.reader.Close()
+	}
+}
+
+func (h *hub) roundTrip(name string, req *http.Request) (*http.Response, error) {
+	r, ok := h.regions[name]
+	if !ok {
+		return nil, perror.Wrapf(ErrRegionNotServed, "region %s", name)
+	}
+	if upgrade := req.Header.Get("Upgrade"); upgrade != "" {
+		return nil, perror.Wrapf(ErrRequestUnsupported, "upgrading to %s", upgrade)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		defer req.Body.Close()
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+	request := &Request{
+		ID:     uuid.New().String(),
+		Method: req.Method,
+		URI:    req.URL.RequestURI(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	}
+	reader, writer := io.Pipe()
+	p := &pending{
+		first:  make(chan *Response, 1),
+		reader: reader,
+		writer: writer,
+	}
+
+	h.mu.Lock()
+	online := r.polling > 0 || time.Since(r.lastPoll) < h.config.OfflineTimeout
+	if online {
+		r.pending[request.ID] = p
+	}
+	h.mu.Unlock()
+	if !online {
+		return nil, perror.Wrapf(ErrAgentOffline, "agent of region %s", name)
+	}
+
+	ctx := req.Context()
+	timer := time.NewTimer(h.config.RequestTimeout)
+	defer timer.Stop()
+	select {
+	case r.requests <- request:
+	case <-ctx.Done():
+		h.remove(r, request.ID)
+		return nil, ctx.Err()
+	case <-timer.C:
+		h.remove(r, request.ID)
+		return nil, perror.Wrapf(ErrRequestTimeout, "requests to agent of region %s are full", name)
+	}
+
+	select {
+	case resp := <-p.first:
+		if resp.Error != "" {
+			return nil, fmt.Errorf("agent of region %s: %s", name, resp.Error)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			StatusCode:    resp.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        resp.Header,
+			Body:          reader,
+			ContentLength: -1,
+			Request:       req,
+		}, nil
+	case <-ctx.Done():
+		h.remove(r, request.ID)
+		return nil, ctx.Err()
+	case <-timer.C:
+		h.remove(r, request.ID)
+		return nil, perror.Wrapf(ErrRequestTimeout, "agent of region %s did not respond", name)
+	}
+}
+
+type roundTripper struct {
+	hub    *hub
+	region string
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.hub.roundTrip(t.region, req)
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	// TokenHeader carries the token of the agent
+	TokenHeader = "X-Horizon-Agent-Token"
+
+	// Host is the placeholder host of rest configs of regions served by agents,
+	// only paths and queries are forwarded to agents
+	Host = "http://horizon-agent"
+)
+
+// PollPath is the path agents poll requests of the region from
+func PollPath(region string) string {
+	return fmt.Sprintf("/apis/internal/v2/agents/%s/requests", region)
+}
+
+// RespondPath is the path agents post responses of the region to
+func RespondPath(region string) string {
+	return fmt.Sprintf("/apis/internal/v2/agents/%s/responses", region)
+}
+
+// Request is a kubernetes api request forwarded to the agent
+type Request struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	// URI is the path and query of the request
+	URI    string      `json:"uri"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Response is a part of the response to a request. The first part carries the status code and header,
+// the body is streamed in parts until the one marked done, so watches are forwarded as they happen.
+type Response struct {
+	ID         string      `json:"id"`
+	StatusCode int         `json:"statusCode,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+	Done       bool        `json:"done,omitempty"`
+	// Error is set when the agent failed to perform the request or to read its response
+	Error string `json:"error,omitempty"`
+}
@@ -30,6 +30,8 @@ import (
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	tagdao "github.com/horizoncd/horizon/pkg/tag/dao"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 )
 
@@ -52,8 +54,22 @@ type DAO interface {
 		extraMembers map[*usermodels.User]string) (*models.Application, error)
 	UpdateByID(ctx context.Context, id uint, application *models.Application) (*models.Application, error)
 	DeleteByID(ctx context.Context, id uint) error
+	// HardDeleteByID deletes an application and marks it as not restorable
+	HardDeleteByID(ctx context.Context, id uint) error
 	TransferByID(ctx context.Context, id uint, groupID uint) error
 	List(ctx context.Context, groupIDs []uint, query *q.Query) (int, []*models.Application, error)
+	// GetDeletedByName get soft deleted applications with the given name
+	GetDeletedByName(ctx context.Context, name string) ([]*models.Application, error)
+	// ListDeletedByGroupID list soft deleted applications under the given group, latest deleted first
+	ListDeletedByGroupID(ctx context.Context, groupID uint) ([]*models.Application, error)
+	// ListDeletedBefore list at most limit applications soft deleted before the given time,
+	// whose id is greater than afterID
+	ListDeletedBefore(ctx context.Context, before time.Time, afterID uint,
+		limit int) ([]*models.Application, error)
+	// RestoreByID restore a soft deleted application
+	RestoreByID(ctx context.Context, id uint) error
+	// PurgeByID remove a soft deleted application from db permanently
+	PurgeByID(ctx context.Context, id uint) error
 }
 
 // NewDAO returns an instance of the default DAO
@@ -253,12 +269,20 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, application *models.Appli
 }
 
 func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	return d.deleteByID(ctx, common.ApplicationDeleteByID, id)
+}
+
+func (d *dao) HardDeleteByID(ctx context.Context, id uint) error {
+	return d.deleteByID(ctx, common.ApplicationHardDeleteByID, id)
+}
+
+func (d *dao) deleteByID(ctx context.Context, sql string, id uint) error {
 	currentUser, err := corecommon.UserFromContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	result := d.db.WithContext(ctx).Exec(common.ApplicationDeleteByID, time.Now().Unix(), currentUser.GetID(), id)
+	result := d.db.WithContext(ctx).Exec(sql, time.Now().Unix(), currentUser.GetID(), id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return herrors.NewErrNotFound(herrors.ApplicationInDB, result.Error.Error())
@@ -271,6 +295,62 @@ func (d *dao) DeleteByID(ctx context.Context, id uint) error {
 	return nil
 }
 
+func (d *dao) GetDeletedByName(ctx context.Context, name string) ([]*models.Application, error) {
+	var applications []*models.Application
+	result := d.db.WithContext(ctx).Raw(common.ApplicationQueryDeletedByName, name).Scan(&applications)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ApplicationInDB, result.Error.Error())
+	}
+	return applications, nil
+}
+
+func (d *dao) ListDeletedByGroupID(ctx context.Context, groupID uint) ([]*models.Application, error) {
+	var applications []*models.Application
+	result := d.db.WithContext(ctx).Raw(common.ApplicationQueryDeletedByGroupID, groupID).Scan(&applications)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ApplicationInDB, result.Error.Error())
+	}
+	return applications, nil
+}
+
+func (d *dao) ListDeletedBefore(ctx context.Context, before time.Time, afterID uint,
+	limit int) ([]*models.Application, error) {
+	var applications []*models.Application
+	result := d.db.WithContext(ctx).Raw(common.ApplicationQueryDeletedBefore, before.Unix(), afterID, limit).
+		Scan(&applications)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ApplicationInDB, result.Error.Error())
+	}
+	return applications, nil
+}
+
+func (d *dao) RestoreByID(ctx context.Context, id uint) error {
+	currentUser, err := corecommon.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := d.db.WithContext(ctx).Exec(common.ApplicationRestoreByID, currentUser.GetID(), id)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ApplicationInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ApplicationInDB, "deleted application not found")
+	}
+	return nil
+}
+
+func (d *dao) PurgeByID(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Exec(common.ApplicationPurgeByID, id)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.ApplicationInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ApplicationInDB, "deleted application not found")
+	}
+	return nil
+}
+
 func (d *dao) TransferByID(ctx context.Context, id uint, groupID uint) error {
 	currentUser, err := corecommon.UserFromContext(ctx)
 	if err != nil {
@@ -325,6 +405,11 @@ func (d *dao) List(ctx context.Context, groupIDs []uint,
 				} else {
 					statement = statement.Where("a.id = ?", v)
 				}
+			case corecommon.ApplicationQueryTagSelector:
+				if tagSelectors, ok := v.([]tagmodels.TagSelector); ok && len(tagSelectors) > 0 {
+					statement = statement.Where("a.id in (?)", tagdao.GenSQLForTagSelector(
+						corecommon.ResourceApplication, tagSelectors, d.db.WithContext(ctx)))
+				}
 			case corecommon.ApplicationQueryWithDeleted:
 				withDeleted = true
 			}
@@ -21,9 +21,13 @@ import (
 	"os"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	gitlablibmock "github.com/horizoncd/horizon/mock/lib/gitlab"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/xanzy/go-gitlab"
 )
@@ -274,3 +278,41 @@ func TestV2(t *testing.T) {
 	err = r.HardDeleteApplication(ctx, app)
 	assert.Nil(t, err)
 }
+
+func TestCreateOrUpdateApplicationConflict(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+	})
+	gitlabmockLib := gitlablibmock.NewMockInterface(mockCtrl)
+	gitlabmockLib.EXPECT().GetCreatedGroup(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&gitlab.Group{FullPath: "root/applications"}, nil).AnyTimes()
+	gitlabmockLib.EXPECT().GetProject(gomock.Any(), "root/applications/app/default").
+		Return(&gitlab.Project{DefaultBranch: "master"}, nil).AnyTimes()
+	gitlabmockLib.EXPECT().GetBranch(gomock.Any(), "root/applications/app/default", "master").
+		Return(&gitlab.Branch{Commit: &gitlab.Commit{ID: "b2c3d4e5f6"}}, nil).Times(2)
+	gitlabmockLib.EXPECT().WriteFiles(gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitlab.Commit{}, nil).Times(1)
+
+	r, err := NewApplicationGitlabRepo(ctx, gitlabmockLib, ApplicationGitRepoConfig{
+		RootGroup:         &gitlab.Group{FullPath: "root"},
+		DefaultBranch:     "master",
+		DefaultVisibility: "private",
+	})
+	assert.Nil(t, err)
+
+	req := CreateOrUpdateRequest{
+		Environment:    common.ApplicationRepoDefaultEnv,
+		TemplateConf:   map[string]interface{}{"app": map[string]interface{}{}},
+		ExpectedCommit: "a1b2c3d4",
+	}
+	err = r.CreateOrUpdateApplication(ctx, "app", req)
+	assert.Equal(t, herrors.ErrGitlabCommitConflict, perror.Cause(err))
+
+	// short commit id of the head is accepted
+	req.ExpectedCommit = "b2c3d4"
+	err = r.CreateOrUpdateApplication(ctx, "app", req)
+	assert.Nil(t, err)
+}
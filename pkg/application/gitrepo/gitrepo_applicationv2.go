@@ -17,12 +17,15 @@ package gitrepo
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	pkgcommon "github.com/horizoncd/horizon/pkg/common"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	gitlabconf "github.com/horizoncd/horizon/pkg/config/gitlab"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/util/angular"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -47,12 +50,17 @@ type CreateOrUpdateRequest struct {
 	Environment  string
 	BuildConf    map[string]interface{}
 	TemplateConf map[string]interface{}
+	// ExpectedCommit is the commit which the update is based on, the update fails with
+	// ErrGitlabCommitConflict if the env repo has advanced since it. Nothing is checked if it's empty.
+	ExpectedCommit string
 }
 
 type GetResponse struct {
 	Manifest     map[string]interface{}
 	BuildConf    map[string]interface{}
 	TemplateConf map[string]interface{}
+	// Commit is the commit which the files are read from, empty if the files do not exist
+	Commit string
 }
 
 type ApplicationGitRepo interface {
@@ -60,6 +68,13 @@ type ApplicationGitRepo interface {
 	GetApplication(ctx context.Context, application, environment string) (*GetResponse, error)
 	// HardDeleteApplication hard delete an application by the specified application name
 	HardDeleteApplication(ctx context.Context, application string) error
+	// ListConfigCommits lists the commits of the repo of the environment, the default one if it's empty,
+	// the newest first. Nothing is listed if the repo does not exist.
+	ListConfigCommits(ctx context.Context, application, environment string,
+		page, perPage int) ([]*pkgcommon.ConfigCommit, error)
+	// GetApplicationByCommit gets the files of the repo of the environment at the commit,
+	// the default one is read if the environment is empty
+	GetApplicationByCommit(ctx context.Context, application, environment, commit string) (*GetResponse, error)
 }
 
 type appGitopsRepo struct {
@@ -74,10 +89,37 @@ type ApplicationGitRepoConfig struct {
 	RootGroup         *gitlab.Group
 	DefaultBranch     string
 	DefaultVisibility string
+	// Provider is where the repos are hosted, they are in the gitlab of RootGroup if its kind is empty
+	Provider gitlabconf.ApplicationRepoConfig
 }
 
 var _ ApplicationGitRepo = &appGitopsRepo{}
 
+// NewApplicationGitRepo creates the gitops repo of applications hosted by the provider in config,
+// gitlabLib is only used if the provider is gitlab
+func NewApplicationGitRepo(ctx context.Context, gitlabLib gitlablib.Interface,
+	config ApplicationGitRepoConfig) (ApplicationGitRepo, error) {
+	switch config.Provider.Kind {
+	case "", gitlabconf.KindGitlab:
+		return NewApplicationGitlabRepo(ctx, gitlabLib, config)
+	case gitlabconf.KindGithub:
+		provider, err := newGithubProvider(ctx, config.Provider)
+		if err != nil {
+			return nil, err
+		}
+		return newProviderAppGitopsRepo(provider, config), nil
+	case gitlabconf.KindGitea:
+		provider, err := newGiteaProvider(config.Provider)
+		if err != nil {
+			return nil, err
+		}
+		return newProviderAppGitopsRepo(provider, config), nil
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "application repo provider %s is not one of %s, %s and %s",
+			config.Provider.Kind, gitlabconf.KindGitlab, gitlabconf.KindGithub, gitlabconf.KindGitea)
+	}
+}
+
 func NewApplicationGitlabRepo(ctx context.Context, gitlabLib gitlablib.Interface,
 	config ApplicationGitRepoConfig) (ApplicationGitRepo, error) {
 	applicationsGroup, err := gitlabLib.GetCreatedGroup(ctx, config.RootGroup.ID,
@@ -146,6 +188,18 @@ func (g appGitopsRepo) CreateOrUpdateApplication(ctx context.Context,
 		return perror.Wrap(herrors.ErrGitLabDefaultBranchNotMatch,
 			fmt.Sprintf("expect %s, not got %s", g.defaultBranch, project.DefaultBranch))
 	}
+	if envProjectExists && req.ExpectedCommit != "" {
+		branch, err := g.gitlabLib.GetBranch(ctx, pid, g.defaultBranch)
+		if err != nil {
+			return err
+		}
+		// short commit id is accepted
+		if !strings.HasPrefix(branch.Commit.ID, req.ExpectedCommit) {
+			return perror.Wrapf(herrors.ErrGitlabCommitConflict,
+				"config has been changed from %s to %s, please reload it and try again",
+				req.ExpectedCommit, branch.Commit.ID)
+		}
+	}
 
 	// 2. if env template repo exists, the gitlab action is update, else the action is create
 	var action = gitlablib.FileCreate
@@ -154,67 +208,21 @@ func (g appGitopsRepo) CreateOrUpdateApplication(ctx context.Context,
 	}
 
 	// 3. write files
-	var templateConfYaml, buildConfYaml, manifestYaml []byte
-	if req.TemplateConf != nil {
-		templateConfYaml, err = yaml.Marshal(req.TemplateConf)
-		if err != nil {
-			log.Warningf(ctx, "templateConf marshal error, %v", req.TemplateConf)
-			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
-		}
-	}
-	if req.BuildConf != nil {
-		buildConfYaml, err = yaml.Marshal(req.BuildConf)
-		if err != nil {
-			log.Warningf(ctx, "buildConf marshal error, %v", req.BuildConf)
-			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
-		}
+	files, err := applicationFiles(ctx, req)
+	if err != nil {
+		return err
 	}
-	if req.Version != "" {
-		manifest := pkgcommon.Manifest{Version: req.Version}
-		manifestYaml, err = yaml.Marshal(manifest)
-		if err != nil {
-			log.Warningf(ctx, "Manifest marshal error, %+v", manifest)
-			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
-		}
+	actions := make([]gitlablib.CommitAction, 0, len(files))
+	for _, file := range files {
+		actions = append(actions, gitlablib.CommitAction{
+			Action:   action,
+			FilePath: file.Path,
+			Content:  string(file.Content),
+		})
 	}
 
-	actions := func() []gitlablib.CommitAction {
-		actions := make([]gitlablib.CommitAction, 0)
-		if req.BuildConf != nil {
-			actions = append(actions, gitlablib.CommitAction{
-				Action:   action,
-				FilePath: _filePathPipeline,
-				Content:  string(buildConfYaml),
-			})
-		}
-		if req.TemplateConf != nil {
-			actions = append(actions, gitlablib.CommitAction{
-				Action:   action,
-				FilePath: _filePathApplication,
-				Content:  string(templateConfYaml),
-			})
-		}
-		if req.Version != "" {
-			actions = append(actions, gitlablib.CommitAction{
-				Action:   action,
-				FilePath: _filePathManifest,
-				Content:  string(manifestYaml),
-			})
-		}
-		return actions
-	}()
-
-	commitMsg := angular.CommitMessage("application", angular.Subject{
-		Operator:    currentUser.GetName(),
-		Action:      fmt.Sprintf("%s application %s configure", string(action), environmentRepoName),
-		Application: angular.StringPtr(application),
-	}, struct {
-		Application map[string]interface{} `json:"application"`
-		Pipeline    map[string]interface{} `json:"pipeline"`
-	}{
-		Application: req.TemplateConf,
-		Pipeline:    req.BuildConf,
-	})
+	commitMsg := applicationCommitMessage(currentUser.GetName(), string(action),
+		environmentRepoName, application, req)
 	if _, err := g.gitlabLib.WriteFiles(ctx, pid, g.defaultBranch, commitMsg, nil, actions); err != nil {
 		return err
 	}
@@ -242,9 +250,14 @@ func (g appGitopsRepo) GetApplication(ctx context.Context, application, environm
 		}
 	}
 
-	manifestBytes, err1 := g.gitlabLib.GetFile(ctx, pid, g.defaultBranch, _filePathManifest)
-	buildConfBytes, err2 := g.gitlabLib.GetFile(ctx, pid, g.defaultBranch, _filePathPipeline)
-	templateConfBytes, err3 := g.gitlabLib.GetFile(ctx, pid, g.defaultBranch, _filePathApplication)
+	// the other files are read from the commit of application file to make sure they match it
+	ref := g.defaultBranch
+	templateConfBytes, commit, err3 := g.gitlabLib.GetFileAndCommit(ctx, pid, g.defaultBranch, _filePathApplication)
+	if err3 == nil {
+		ref = commit
+	}
+	manifestBytes, err1 := g.gitlabLib.GetFile(ctx, pid, ref, _filePathManifest)
+	buildConfBytes, err2 := g.gitlabLib.GetFile(ctx, pid, ref, _filePathPipeline)
 	for _, err := range []error{err1, err2, err3} {
 		if err != nil {
 			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
@@ -254,10 +267,135 @@ func (g appGitopsRepo) GetApplication(ctx context.Context, application, environm
 	}
 
 	// 2. process data
-	res := GetResponse{}
+	return toGetResponse(manifestBytes, buildConfBytes, templateConfBytes, commit)
+}
+
+func (g appGitopsRepo) HardDeleteApplication(ctx context.Context, application string) error {
+	const op = "gitlab repo: hard delete application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	gid := fmt.Sprintf("%v/%v", g.applicationsGroup.FullPath, application)
+	return g.gitlabLib.DeleteGroup(ctx, gid)
+}
+
+func (g appGitopsRepo) ListConfigCommits(ctx context.Context, application, environment string,
+	page, perPage int) ([]*pkgcommon.ConfigCommit, error) {
+	const op = "gitlab repo: list config commits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	commits, err := g.gitlabLib.ListCommits(ctx, g.projectPath(application, environment),
+		g.defaultBranch, "", page, perPage)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return []*pkgcommon.ConfigCommit{}, nil
+		}
+		return nil, err
+	}
+	configCommits := make([]*pkgcommon.ConfigCommit, 0, len(commits))
+	for _, commit := range commits {
+		var createdAt time.Time
+		if commit.CreatedAt != nil {
+			createdAt = *commit.CreatedAt
+		}
+		var stats *pkgcommon.CommitStats
+		if commit.Stats != nil {
+			stats = &pkgcommon.CommitStats{
+				Additions: commit.Stats.Additions,
+				Deletions: commit.Stats.Deletions,
+			}
+		}
+		configCommits = append(configCommits,
+			pkgcommon.NewConfigCommit(commit.ID, commit.Message, commit.AuthorName, createdAt, stats))
+	}
+	return configCommits, nil
+}
+
+func (g appGitopsRepo) GetApplicationByCommit(ctx context.Context,
+	application, environment, commit string) (*GetResponse, error) {
+	const op = "gitlab repo: get application by commit"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pid := g.projectPath(application, environment)
+	templateConfBytes, err1 := g.gitlabLib.GetFile(ctx, pid, commit, _filePathApplication)
+	manifestBytes, err2 := g.gitlabLib.GetFile(ctx, pid, commit, _filePathManifest)
+	buildConfBytes, err3 := g.gitlabLib.GetFile(ctx, pid, commit, _filePathPipeline)
+	for _, err := range []error{err1, err2, err3} {
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+		}
+	}
+	if err1 != nil {
+		commit = ""
+	}
+	return toGetResponse(manifestBytes, buildConfBytes, templateConfBytes, commit)
+}
+
+func (g appGitopsRepo) projectPath(application, environment string) string {
+	if environment == "" {
+		environment = common.ApplicationRepoDefaultEnv
+	}
+	return fmt.Sprintf("%v/%v/%v", g.applicationsGroup.FullPath, application, environment)
+}
+
+type gitFile struct {
+	Path    string
+	Content []byte
+}
+
+// applicationFiles marshals the files of the request to write, the ones not set in the request are left out
+func applicationFiles(ctx context.Context, req CreateOrUpdateRequest) ([]*gitFile, error) {
+	files := make([]*gitFile, 0)
+	if req.BuildConf != nil {
+		buildConfYaml, err := yaml.Marshal(req.BuildConf)
+		if err != nil {
+			log.Warningf(ctx, "buildConf marshal error, %v", req.BuildConf)
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		files = append(files, &gitFile{Path: _filePathPipeline, Content: buildConfYaml})
+	}
+	if req.TemplateConf != nil {
+		templateConfYaml, err := yaml.Marshal(req.TemplateConf)
+		if err != nil {
+			log.Warningf(ctx, "templateConf marshal error, %v", req.TemplateConf)
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		files = append(files, &gitFile{Path: _filePathApplication, Content: templateConfYaml})
+	}
+	if req.Version != "" {
+		manifest := pkgcommon.Manifest{Version: req.Version}
+		manifestYaml, err := yaml.Marshal(manifest)
+		if err != nil {
+			log.Warningf(ctx, "Manifest marshal error, %+v", manifest)
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		files = append(files, &gitFile{Path: _filePathManifest, Content: manifestYaml})
+	}
+	return files, nil
+}
+
+func applicationCommitMessage(operator, action, environment, application string,
+	req CreateOrUpdateRequest) string {
+	return angular.CommitMessage("application", angular.Subject{
+		Operator:    operator,
+		Action:      fmt.Sprintf("%s application %s configure", action, environment),
+		Application: angular.StringPtr(application),
+	}, struct {
+		Application map[string]interface{} `json:"application"`
+		Pipeline    map[string]interface{} `json:"pipeline"`
+	}{
+		Application: req.TemplateConf,
+		Pipeline:    req.BuildConf,
+	})
+}
+
+// toGetResponse parses the files read from the commit, the files not existing are nil
+func toGetResponse(manifestBytes, buildConfBytes, templateConfBytes []byte, commit string) (*GetResponse, error) {
+	res := GetResponse{Commit: commit}
 	TransformData := func(bytes []byte) (map[string]interface{}, error) {
 		var entity map[string]interface{}
-		err = yaml.Unmarshal(bytes, &entity)
+		err := yaml.Unmarshal(bytes, &entity)
 		if err != nil {
 			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
 		}
@@ -289,11 +427,3 @@ func (g appGitopsRepo) GetApplication(ctx context.Context, application, environm
 	}
 	return &res, nil
 }
-
-func (g appGitopsRepo) HardDeleteApplication(ctx context.Context, application string) error {
-	const op = "gitlab repo: hard delete application"
-	defer wlog.Start(ctx, op).StopPrint()
-
-	gid := fmt.Sprintf("%v/%v", g.applicationsGroup.FullPath, application)
-	return g.gitlabLib.DeleteGroup(ctx, gid)
-}
@@ -0,0 +1,223 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitrepo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_visibilityPublic = "public"
+	// _repoNameSeparator separates the application and the environment in repo names,
+	// neither of their names contains dots
+	_repoNameSeparator = "."
+)
+
+// gitProvider is the operations of a git provider that gitops repos of applications need,
+// the repos are all under the owner in config, and named by their names only
+type gitProvider interface {
+	// GetDefaultBranch gets the default branch of the repo, it's not found if the repo does not exist
+	GetDefaultBranch(ctx context.Context, repo string) (string, error)
+	// CreateRepo creates the repo initialized with the default branch, and returns the default branch
+	CreateRepo(ctx context.Context, repo, defaultBranch string, private bool) (string, error)
+	// GetBranchCommit gets the id of the commit the branch points to
+	GetBranchCommit(ctx context.Context, repo, branch string) (string, error)
+	// GetFile gets the content of the file at the ref, it's not found if the file does not exist
+	GetFile(ctx context.Context, repo, ref, path string) ([]byte, error)
+	// WriteFiles creates or updates the files on the branch in one commit
+	WriteFiles(ctx context.Context, repo, branch, message string, files []*gitFile) error
+	// ListCommits lists the commits of the branch, the newest first
+	ListCommits(ctx context.Context, repo, branch string, page, perPage int) ([]*pkgcommon.ConfigCommit, error)
+	// ListRepos lists names of the repos with the prefix
+	ListRepos(ctx context.Context, prefix string) ([]string, error)
+	DeleteRepo(ctx context.Context, repo string) error
+}
+
+// providerAppGitopsRepo is the gitops repo of applications hosted by git providers other than gitlab,
+// which have no nested groups, so the repo of each environment is named like <application>.<environment>
+type providerAppGitopsRepo struct {
+	provider          gitProvider
+	defaultBranch     string
+	defaultVisibility string
+}
+
+var _ ApplicationGitRepo = &providerAppGitopsRepo{}
+
+func newProviderAppGitopsRepo(provider gitProvider, config ApplicationGitRepoConfig) ApplicationGitRepo {
+	return &providerAppGitopsRepo{
+		provider:          provider,
+		defaultBranch:     config.DefaultBranch,
+		defaultVisibility: config.DefaultVisibility,
+	}
+}
+
+func repoName(application, environment string) string {
+	if environment == "" {
+		environment = common.ApplicationRepoDefaultEnv
+	}
+	return application + _repoNameSeparator + environment
+}
+
+func (g *providerAppGitopsRepo) CreateOrUpdateApplication(ctx context.Context,
+	application string, req CreateOrUpdateRequest) error {
+	const op = "provider repo: create or update application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	environmentRepoName := common.ApplicationRepoDefaultEnv
+	if req.Environment != "" {
+		environmentRepoName = req.Environment
+	}
+	repo := repoName(application, environmentRepoName)
+
+	// 1. create the repo if it does not exist
+	envRepoExists := true
+	defaultBranch, err := g.provider.GetDefaultBranch(ctx, repo)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		envRepoExists = false
+		defaultBranch, err = g.provider.CreateRepo(ctx, repo, g.defaultBranch,
+			g.defaultVisibility != _visibilityPublic)
+		if err != nil {
+			return err
+		}
+	}
+	if defaultBranch != g.defaultBranch {
+		return perror.Wrap(herrors.ErrGitLabDefaultBranchNotMatch,
+			fmt.Sprintf("expect %s, not got %s", g.defaultBranch, defaultBranch))
+	}
+	if envRepoExists && req.ExpectedCommit != "" {
+		commit, err := g.provider.GetBranchCommit(ctx, repo, g.defaultBranch)
+		if err != nil {
+			return err
+		}
+		// short commit id is accepted
+		if !strings.HasPrefix(commit, req.ExpectedCommit) {
+			return perror.Wrapf(herrors.ErrGitlabCommitConflict,
+				"config has been changed from %s to %s, please reload it and try again",
+				req.ExpectedCommit, commit)
+		}
+	}
+
+	// 2. write files
+	files, err := applicationFiles(ctx, req)
+	if err != nil {
+		return err
+	}
+	action := "create"
+	if envRepoExists {
+		action = "update"
+	}
+	commitMsg := applicationCommitMessage(currentUser.GetName(), action,
+		environmentRepoName, application, req)
+	return g.provider.WriteFiles(ctx, repo, g.defaultBranch, commitMsg, files)
+}
+
+func (g *providerAppGitopsRepo) GetApplication(ctx context.Context,
+	application, environment string) (*GetResponse, error) {
+	const op = "provider repo: get application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// if env repo not exist, use the default one
+	repo := repoName(application, environment)
+	commit, err := g.provider.GetBranchCommit(ctx, repo, g.defaultBranch)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		repo = repoName(application, common.ApplicationRepoDefaultEnv)
+		commit, err = g.provider.GetBranchCommit(ctx, repo, g.defaultBranch)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+			return &GetResponse{}, nil
+		}
+	}
+
+	return g.getFiles(ctx, repo, commit)
+}
+
+func (g *providerAppGitopsRepo) ListConfigCommits(ctx context.Context, application, environment string,
+	page, perPage int) ([]*pkgcommon.ConfigCommit, error) {
+	const op = "provider repo: list config commits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	commits, err := g.provider.ListCommits(ctx, repoName(application, environment), g.defaultBranch, page, perPage)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return []*pkgcommon.ConfigCommit{}, nil
+		}
+		return nil, err
+	}
+	return commits, nil
+}
+
+func (g *providerAppGitopsRepo) GetApplicationByCommit(ctx context.Context,
+	application, environment, commit string) (*GetResponse, error) {
+	const op = "provider repo: get application by commit"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return g.getFiles(ctx, repoName(application, environment), commit)
+}
+
+// getFiles reads all files from the same commit to make sure they match each other
+func (g *providerAppGitopsRepo) getFiles(ctx context.Context, repo, commit string) (*GetResponse, error) {
+	templateConfBytes, err1 := g.provider.GetFile(ctx, repo, commit, _filePathApplication)
+	manifestBytes, err2 := g.provider.GetFile(ctx, repo, commit, _filePathManifest)
+	buildConfBytes, err3 := g.provider.GetFile(ctx, repo, commit, _filePathPipeline)
+	for _, err := range []error{err1, err2, err3} {
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+		}
+	}
+	// the commit is empty if the application file does not exist, like the gitlab one
+	if err1 != nil {
+		commit = ""
+	}
+	return toGetResponse(manifestBytes, buildConfBytes, templateConfBytes, commit)
+}
+
+func (g *providerAppGitopsRepo) HardDeleteApplication(ctx context.Context, application string) error {
+	const op = "provider repo: hard delete application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	repos, err := g.provider.ListRepos(ctx, application+_repoNameSeparator)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if err := g.provider.DeleteRepo(ctx, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	gitlabconf "github.com/horizoncd/horizon/pkg/config/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const _giteaPageSize = 50
+
+type giteaRepo struct {
+	Name          string `json:"name"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type giteaBranch struct {
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+type giteaContent struct {
+	SHA string `json:"sha"`
+}
+
+type giteaCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	Stats *struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	} `json:"stats"`
+}
+
+type giteaFileOptions struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	SHA       string `json:"sha,omitempty"`
+}
+
+type giteaChangeFilesOptions struct {
+	Branch  string              `json:"branch"`
+	Message string              `json:"message"`
+	Files   []*giteaFileOptions `json:"files"`
+}
+
+// giteaProvider calls the api of gitea, files are changed in one commit by the api added in gitea 1.20
+type giteaProvider struct {
+	baseURL string
+	token   string
+	owner   string
+	client  *http.Client
+}
+
+var _ gitProvider = (*giteaProvider)(nil)
+
+func newGiteaProvider(config gitlabconf.ApplicationRepoConfig) (gitProvider, error) {
+	if _, err := url.Parse(config.URL); err != nil || config.URL == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "url of gitea is incorrect: %s", config.URL)
+	}
+	return &giteaProvider{
+		baseURL: strings.TrimSuffix(config.URL, "/") + "/api/v1",
+		token:   config.Token,
+		owner:   config.Owner,
+		client:  &http.Client{},
+	}, nil
+}
+
+func (p *giteaProvider) GetDefaultBranch(ctx context.Context, repo string) (string, error) {
+	var r giteaRepo
+	if err := p.do(ctx, http.MethodGet, p.repoPath(repo), nil, &r); err != nil {
+		return "", err
+	}
+	return r.DefaultBranch, nil
+}
+
+func (p *giteaProvider) CreateRepo(ctx context.Context, repo, defaultBranch string, private bool) (string, error) {
+	var r giteaRepo
+	if err := p.do(ctx, http.MethodPost, fmt.Sprintf("/orgs/%s/repos", url.PathEscape(p.owner)), map[string]interface{}{
+		"name":           repo,
+		"private":        private,
+		"auto_init":      true,
+		"default_branch": defaultBranch,
+	}, &r); err != nil {
+		return "", err
+	}
+	return r.DefaultBranch, nil
+}
+
+func (p *giteaProvider) GetBranchCommit(ctx context.Context, repo, branch string) (string, error) {
+	var b giteaBranch
+	if err := p.do(ctx, http.MethodGet,
+		fmt.Sprintf("%s/branches/%s", p.repoPath(repo), url.PathEscape(branch)), nil, &b); err != nil {
+		return "", err
+	}
+	return b.Commit.ID, nil
+}
+
+func (p *giteaProvider) GetFile(ctx context.Context, repo, ref, path string) ([]byte, error) {
+	var content bytes.Buffer
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/raw/%s?ref=%s",
+		p.repoPath(repo), path, url.QueryEscape(ref)), nil, &content); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+func (p *giteaProvider) WriteFiles(ctx context.Context, repo, branch, message string, files []*gitFile) error {
+	options := &giteaChangeFilesOptions{
+		Branch:  branch,
+		Message: message,
+		Files:   make([]*giteaFileOptions, 0, len(files)),
+	}
+	for _, file := range files {
+		// files existing are updated with their sha
+		fileOptions := &giteaFileOptions{
+			Operation: "create",
+			Path:      file.Path,
+			Content:   base64.StdEncoding.EncodeToString(file.Content),
+		}
+		var content giteaContent
+		err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/contents/%s?ref=%s",
+			p.repoPath(repo), file.Path, url.QueryEscape(branch)), nil, &content)
+		if err == nil {
+			fileOptions.Operation = "update"
+			fileOptions.SHA = content.SHA
+		} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		options.Files = append(options.Files, fileOptions)
+	}
+	return p.do(ctx, http.MethodPost, fmt.Sprintf("%s/contents", p.repoPath(repo)), options, nil)
+}
+
+func (p *giteaProvider) ListCommits(ctx context.Context, repo, branch string,
+	page, perPage int) ([]*pkgcommon.ConfigCommit, error) {
+	var commits []*giteaCommit
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/commits?sha=%s&page=%d&limit=%d&stat=true",
+		p.repoPath(repo), url.QueryEscape(branch), page, perPage), nil, &commits); err != nil {
+		return nil, err
+	}
+	configCommits := make([]*pkgcommon.ConfigCommit, 0, len(commits))
+	for _, commit := range commits {
+		var stats *pkgcommon.CommitStats
+		if commit.Stats != nil {
+			stats = &pkgcommon.CommitStats{
+				Additions: commit.Stats.Additions,
+				Deletions: commit.Stats.Deletions,
+			}
+		}
+		configCommits = append(configCommits, pkgcommon.NewConfigCommit(commit.SHA,
+			commit.Commit.Message, commit.Commit.Author.Name, commit.Commit.Author.Date, stats))
+	}
+	return configCommits, nil
+}
+
+func (p *giteaProvider) ListRepos(ctx context.Context, prefix string) ([]string, error) {
+	repos := make([]string, 0)
+	for page := 1; ; page++ {
+		var rs []*giteaRepo
+		if err := p.do(ctx, http.MethodGet, fmt.Sprintf("/orgs/%s/repos?page=%d&limit=%d",
+			url.PathEscape(p.owner), page, _giteaPageSize), nil, &rs); err != nil {
+			return nil, err
+		}
+		for _, r := range rs {
+			if strings.HasPrefix(r.Name, prefix) {
+				repos = append(repos, r.Name)
+			}
+		}
+		if len(rs) < _giteaPageSize {
+			return repos, nil
+		}
+	}
+}
+
+func (p *giteaProvider) DeleteRepo(ctx context.Context, repo string) error {
+	return p.do(ctx, http.MethodDelete, p.repoPath(repo), nil, nil)
+}
+
+func (p *giteaProvider) repoPath(repo string) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(p.owner), url.PathEscape(repo))
+}
+
+// do calls the api with body encoded as json, the response is decoded into result as json,
+// or copied into it if it's a buffer
+func (p *giteaProvider) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "failed to marshal request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "token "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to call gitea: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrReadFailed, "failed to read response: %v", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		reason := fmt.Sprintf("%s %s: %s", method, path, string(data))
+		return perror.Wrap(herrors.NewErrNotFound(herrors.GiteaResource, reason), reason)
+	case strings.HasSuffix(path, "/contents") &&
+		(resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusUnprocessableEntity):
+		// the sha of files changed is out of date, as the branch has been changed since
+		return perror.Wrapf(herrors.ErrGitlabCommitConflict, "%s %s: %s", method, path, string(data))
+	case resp.StatusCode >= http.StatusBadRequest:
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "%s %s, %s: %s", method, path, resp.Status, string(data))
+	}
+
+	switch r := result.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, _ = r.Write(data)
+		return nil
+	default:
+		if err := json.Unmarshal(data, result); err != nil {
+			return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to unmarshal response: %v", err)
+		}
+		return nil
+	}
+}
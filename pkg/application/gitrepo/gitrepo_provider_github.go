@@ -0,0 +1,202 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitrepo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v41/github"
+	"golang.org/x/oauth2"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	gitlabconf "github.com/horizoncd/horizon/pkg/config/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const _githubPageSize = 100
+
+type githubProvider struct {
+	client *github.Client
+	owner  string
+}
+
+var _ gitProvider = (*githubProvider)(nil)
+
+func newGithubProvider(ctx context.Context, config gitlabconf.ApplicationRepoConfig) (gitProvider, error) {
+	client := github.NewClient(oauth2.NewClient(ctx,
+		oauth2.StaticTokenSource(&oauth2.Token{AccessToken: config.Token})))
+	if config.URL != "" {
+		baseURL, err := url.Parse(strings.TrimSuffix(config.URL, "/") + "/")
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "url is incorrect: %v", err)
+		}
+		client.BaseURL = baseURL
+	}
+	return &githubProvider{client: client, owner: config.Owner}, nil
+}
+
+func (p *githubProvider) GetDefaultBranch(ctx context.Context, repo string) (string, error) {
+	repository, resp, err := p.client.Repositories.Get(ctx, p.owner, repo)
+	if err != nil {
+		return "", parseGithubError(resp, err)
+	}
+	return repository.GetDefaultBranch(), nil
+}
+
+func (p *githubProvider) CreateRepo(ctx context.Context, repo, _ string, private bool) (string, error) {
+	// the default branch of repos created is decided by the settings of the owner
+	repository, resp, err := p.client.Repositories.Create(ctx, p.owner, &github.Repository{
+		Name:     github.String(repo),
+		Private:  github.Bool(private),
+		AutoInit: github.Bool(true),
+	})
+	if err != nil {
+		return "", parseGithubError(resp, err)
+	}
+	return repository.GetDefaultBranch(), nil
+}
+
+func (p *githubProvider) GetBranchCommit(ctx context.Context, repo, branch string) (string, error) {
+	b, resp, err := p.client.Repositories.GetBranch(ctx, p.owner, repo, branch, true)
+	if err != nil {
+		return "", parseGithubError(resp, err)
+	}
+	return b.GetCommit().GetSHA(), nil
+}
+
+func (p *githubProvider) GetFile(ctx context.Context, repo, ref, path string) ([]byte, error) {
+	file, _, resp, err := p.client.Repositories.GetContents(ctx, p.owner, repo, path,
+		&github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		return nil, parseGithubError(resp, err)
+	}
+	if file == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "%s is a directory", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to decode content: %v", err)
+	}
+	return []byte(content), nil
+}
+
+// WriteFiles commits the files by the git data api, the branch is updated only if it's not changed during the write
+func (p *githubProvider) WriteFiles(ctx context.Context, repo, branch, message string, files []*gitFile) error {
+	ref, resp, err := p.client.Git.GetRef(ctx, p.owner, repo, "heads/"+branch)
+	if err != nil {
+		return parseGithubError(resp, err)
+	}
+	parent := ref.GetObject().GetSHA()
+	parentCommit, resp, err := p.client.Git.GetCommit(ctx, p.owner, repo, parent)
+	if err != nil {
+		return parseGithubError(resp, err)
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(file.Path),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(string(file.Content)),
+		})
+	}
+	tree, resp, err := p.client.Git.CreateTree(ctx, p.owner, repo, parentCommit.GetTree().GetSHA(), entries)
+	if err != nil {
+		return parseGithubError(resp, err)
+	}
+	commit, resp, err := p.client.Git.CreateCommit(ctx, p.owner, repo, &github.Commit{
+		Message: github.String(message),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: github.String(parent)}},
+	})
+	if err != nil {
+		return parseGithubError(resp, err)
+	}
+	_, resp, err = p.client.Git.UpdateRef(ctx, p.owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}, false)
+	if err != nil {
+		return parseGithubError(resp, err)
+	}
+	return nil
+}
+
+// ListCommits lists the commits without stats, as they are only returned by the api getting single commits
+func (p *githubProvider) ListCommits(ctx context.Context, repo, branch string,
+	page, perPage int) ([]*pkgcommon.ConfigCommit, error) {
+	commits, resp, err := p.client.Repositories.ListCommits(ctx, p.owner, repo, &github.CommitsListOptions{
+		SHA:         branch,
+		ListOptions: github.ListOptions{Page: page, PerPage: perPage},
+	})
+	if err != nil {
+		return nil, parseGithubError(resp, err)
+	}
+	configCommits := make([]*pkgcommon.ConfigCommit, 0, len(commits))
+	for _, commit := range commits {
+		author := commit.GetCommit().GetAuthor()
+		configCommits = append(configCommits, pkgcommon.NewConfigCommit(commit.GetSHA(),
+			commit.GetCommit().GetMessage(), author.GetName(), author.GetDate(), nil))
+	}
+	return configCommits, nil
+}
+
+func (p *githubProvider) ListRepos(ctx context.Context, prefix string) ([]string, error) {
+	repos := make([]string, 0)
+	opts := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: _githubPageSize}}
+	for {
+		repositories, resp, err := p.client.Repositories.ListByOrg(ctx, p.owner, opts)
+		if err != nil {
+			return nil, parseGithubError(resp, err)
+		}
+		for _, repository := range repositories {
+			if strings.HasPrefix(repository.GetName(), prefix) {
+				repos = append(repos, repository.GetName())
+			}
+		}
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (p *githubProvider) DeleteRepo(ctx context.Context, repo string) error {
+	resp, err := p.client.Repositories.Delete(ctx, p.owner, repo)
+	if err != nil {
+		return parseGithubError(resp, err)
+	}
+	return nil
+}
+
+func parseGithubError(resp *github.Response, err error) error {
+	if resp == nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to call github: %v", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return perror.Wrap(herrors.NewErrNotFound(herrors.GithubResource, err.Error()), err.Error())
+	case resp.StatusCode == http.StatusUnprocessableEntity && strings.Contains(err.Error(), "fast forward"):
+		// the branch has been changed since the parent commit
+		return perror.Wrap(herrors.ErrGitlabCommitConflict, err.Error())
+	default:
+		return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+	}
+}
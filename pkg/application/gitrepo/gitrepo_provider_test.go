@@ -0,0 +1,253 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitrepo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	gitlabconf "github.com/horizoncd/horizon/pkg/config/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// fakeGitea keeps repos in memory, each commit is numbered by its order in the repo
+type fakeGitea struct {
+	sync.Mutex
+	repos map[string]*fakeGiteaRepo
+}
+
+type fakeGiteaRepo struct {
+	defaultBranch string
+	// commits are the messages and files of the commits, the oldest first
+	commits []*fakeGiteaCommit
+}
+
+type fakeGiteaCommit struct {
+	message string
+	files   map[string]string
+}
+
+func (r *fakeGiteaRepo) head() *fakeGiteaCommit {
+	return r.commits[len(r.commits)-1]
+}
+
+func fakeCommitID(i int) string {
+	return fmt.Sprintf("%08x", i+1) + strings.Repeat("0", 32)
+}
+
+func (f *fakeGitea) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/api/v1")
+	if req.Header.Get("Authorization") != "token gitea-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if path == "/orgs/horizon/repos" {
+		switch req.Method {
+		case http.MethodPost:
+			var options struct {
+				Name          string `json:"name"`
+				DefaultBranch string `json:"default_branch"`
+			}
+			_ = json.NewDecoder(req.Body).Decode(&options)
+			f.repos[options.Name] = &fakeGiteaRepo{
+				defaultBranch: options.DefaultBranch,
+				commits: []*fakeGiteaCommit{{
+					message: "Initial commit",
+					files:   map[string]string{"README.md": ""},
+				}},
+			}
+			_ = json.NewEncoder(w).Encode(giteaRepo{Name: options.Name, DefaultBranch: options.DefaultBranch})
+		default:
+			repos := make([]*giteaRepo, 0)
+			if req.URL.Query().Get("page") == "1" {
+				for name := range f.repos {
+					repos = append(repos, &giteaRepo{Name: name})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(repos)
+		}
+		return
+	}
+
+	segments := strings.SplitN(strings.TrimPrefix(path, "/repos/horizon/"), "/", 3)
+	r, ok := f.repos[segments[0]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case len(segments) == 1 && req.Method == http.MethodDelete:
+		delete(f.repos, segments[0])
+	case len(segments) == 1:
+		_ = json.NewEncoder(w).Encode(giteaRepo{Name: segments[0], DefaultBranch: r.defaultBranch})
+	case segments[1] == "branches":
+		var b giteaBranch
+		b.Commit.ID = fakeCommitID(len(r.commits) - 1)
+		_ = json.NewEncoder(w).Encode(b)
+	case segments[1] == "commits":
+		commits := make([]*giteaCommit, 0)
+		for i := len(r.commits) - 1; i >= 0; i-- {
+			commit := &giteaCommit{SHA: fakeCommitID(i)}
+			commit.Commit.Message = r.commits[i].message
+			commit.Commit.Author.Name = "horizon"
+			commits = append(commits, commit)
+		}
+		_ = json.NewEncoder(w).Encode(commits)
+	case segments[1] == "raw":
+		for i, commit := range r.commits {
+			if content, ok := commit.files[segments[2]]; ok && req.URL.Query().Get("ref") == fakeCommitID(i) {
+				_, _ = w.Write([]byte(content))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case segments[1] == "contents" && len(segments) == 3:
+		content, ok := r.head().files[segments[2]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(giteaContent{SHA: fmt.Sprintf("%x", len(content))})
+	case segments[1] == "contents":
+		var options giteaChangeFilesOptions
+		_ = json.NewDecoder(req.Body).Decode(&options)
+		for _, file := range options.Files {
+			content, ok := r.head().files[file.Path]
+			if (file.Operation == "update") != ok ||
+				(ok && file.SHA != fmt.Sprintf("%x", len(content))) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+		}
+		commit := &fakeGiteaCommit{message: options.Message, files: map[string]string{}}
+		for path, content := range r.head().files {
+			commit.files[path] = content
+		}
+		for _, file := range options.Files {
+			data, _ := base64.StdEncoding.DecodeString(file.Content)
+			commit.files[file.Path] = string(data)
+		}
+		r.commits = append(r.commits, commit)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGiteaApplicationGitRepo(t *testing.T) {
+	server := httptest.NewServer(&fakeGitea{repos: map[string]*fakeGiteaRepo{}})
+	defer server.Close()
+
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:     "Tony",
+		FullName: "Tony",
+	})
+	repo, err := NewApplicationGitRepo(ctx, nil, ApplicationGitRepoConfig{
+		DefaultBranch:     "main",
+		DefaultVisibility: "private",
+		Provider: gitlabconf.ApplicationRepoConfig{
+			Kind:  gitlabconf.KindGitea,
+			URL:   server.URL,
+			Token: "gitea-token",
+			Owner: "horizon",
+		},
+	})
+	assert.Nil(t, err)
+
+	application := "gitea-app"
+	resp, err := repo.GetApplication(ctx, application, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.Commit)
+	assert.Nil(t, resp.TemplateConf)
+
+	// create the default env repo
+	req := CreateOrUpdateRequest{
+		Version:      common.MetaVersion2,
+		BuildConf:    map[string]interface{}{"buildxml": "<xml/>"},
+		TemplateConf: map[string]interface{}{"app": map[string]interface{}{"resource": "x-small"}},
+	}
+	assert.Nil(t, repo.CreateOrUpdateApplication(ctx, application, req))
+
+	// the env repo falls back to the default one
+	resp, err = repo.GetApplication(ctx, application, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, req.BuildConf, resp.BuildConf)
+	assert.Equal(t, req.TemplateConf, resp.TemplateConf)
+	assert.Equal(t, common.MetaVersion2, resp.Manifest["version"])
+	assert.NotEqual(t, "", resp.Commit)
+
+	// update based on the commit read
+	req.TemplateConf = map[string]interface{}{"app": map[string]interface{}{"resource": "small"}}
+	req.ExpectedCommit = resp.Commit[:8]
+	assert.Nil(t, repo.CreateOrUpdateApplication(ctx, application, req))
+
+	// update based on the stale commit
+	err = repo.CreateOrUpdateApplication(ctx, application, req)
+	assert.Equal(t, herrors.ErrGitlabCommitConflict, perror.Cause(err))
+
+	// create the env repo
+	req.Environment = "test"
+	req.ExpectedCommit = ""
+	assert.Nil(t, repo.CreateOrUpdateApplication(ctx, application, req))
+	resp, err = repo.GetApplication(ctx, application, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, req.TemplateConf, resp.TemplateConf)
+
+	// the history of the default env repo
+	commits, err := repo.ListConfigCommits(ctx, application, "", 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(commits))
+	assert.Equal(t, "Tony", commits[0].Operator)
+	assert.Equal(t, "change(application): Tony update application default configure gitea-app",
+		commits[0].Title)
+	assert.Equal(t, "horizon", commits[2].Operator)
+	resp, err = repo.GetApplicationByCommit(ctx, application, "", commits[1].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, commits[1].ID, resp.Commit)
+	assert.Equal(t, map[string]interface{}{"app": map[string]interface{}{"resource": "x-small"}}, resp.TemplateConf)
+	resp, err = repo.GetApplicationByCommit(ctx, application, "", commits[2].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.Commit)
+	commits, err = repo.ListConfigCommits(ctx, application, "online", 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(commits))
+
+	assert.Nil(t, repo.HardDeleteApplication(ctx, application))
+	resp, err = repo.GetApplication(ctx, application, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.Commit)
+}
+
+func TestNewApplicationGitRepoUnknownProvider(t *testing.T) {
+	_, err := NewApplicationGitRepo(context.Background(), nil, ApplicationGitRepoConfig{
+		Provider: gitlabconf.ApplicationRepoConfig{Kind: "bitbucket"},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
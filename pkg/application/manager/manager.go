@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/lib/q"
 	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
@@ -42,8 +43,22 @@ type Manager interface {
 		extraMembers map[string]string) (*models.Application, error)
 	UpdateByID(ctx context.Context, id uint, application *models.Application) (*models.Application, error)
 	DeleteByID(ctx context.Context, id uint) error
+	// HardDeleteByID deletes an application which can not be restored
+	HardDeleteByID(ctx context.Context, id uint) error
 	Transfer(ctx context.Context, id uint, groupID uint) error
 	List(ctx context.Context, groupIDs []uint, query *q.Query) (int, []*models.Application, error)
+	// GetDeletedByName get soft deleted applications with the given name
+	GetDeletedByName(ctx context.Context, name string) ([]*models.Application, error)
+	// ListDeleted list soft deleted applications under the given group
+	ListDeleted(ctx context.Context, groupID uint) ([]*models.Application, error)
+	// ListDeletedBefore list at most limit applications soft deleted before the given time,
+	// whose id is greater than afterID
+	ListDeletedBefore(ctx context.Context, before time.Time, afterID uint,
+		limit int) ([]*models.Application, error)
+	// Restore restore a soft deleted application
+	Restore(ctx context.Context, id uint) error
+	// Purge remove a soft deleted application from db permanently
+	Purge(ctx context.Context, id uint) error
 }
 
 func New(db *gorm.DB) Manager {
@@ -133,6 +148,10 @@ func (m *manager) DeleteByID(ctx context.Context, id uint) error {
 	return m.applicationDAO.DeleteByID(ctx, id)
 }
 
+func (m *manager) HardDeleteByID(ctx context.Context, id uint) error {
+	return m.applicationDAO.HardDeleteByID(ctx, id)
+}
+
 func (m *manager) Transfer(ctx context.Context, id uint, groupID uint) error {
 	return m.applicationDAO.TransferByID(ctx, id, groupID)
 }
@@ -140,3 +159,24 @@ func (m *manager) Transfer(ctx context.Context, id uint, groupID uint) error {
 func (m *manager) List(ctx context.Context, groupIDs []uint, query *q.Query) (int, []*models.Application, error) {
 	return m.applicationDAO.List(ctx, groupIDs, query)
 }
+
+func (m *manager) GetDeletedByName(ctx context.Context, name string) ([]*models.Application, error) {
+	return m.applicationDAO.GetDeletedByName(ctx, name)
+}
+
+func (m *manager) ListDeleted(ctx context.Context, groupID uint) ([]*models.Application, error) {
+	return m.applicationDAO.ListDeletedByGroupID(ctx, groupID)
+}
+
+func (m *manager) ListDeletedBefore(ctx context.Context, before time.Time,
+	afterID uint, limit int) ([]*models.Application, error) {
+	return m.applicationDAO.ListDeletedBefore(ctx, before, afterID, limit)
+}
+
+func (m *manager) Restore(ctx context.Context, id uint) error {
+	return m.applicationDAO.RestoreByID(ctx, id)
+}
+
+func (m *manager) Purge(ctx context.Context, id uint) error {
+	return m.applicationDAO.PurgeByID(ctx, id)
+}
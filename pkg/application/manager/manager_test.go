@@ -34,10 +34,13 @@ import (
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	userdao "github.com/horizoncd/horizon/pkg/user/dao"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+	"github.com/horizoncd/horizon/pkg/util/sets"
 )
 
 var (
@@ -73,7 +76,7 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&membermodels.Member{}, &usermodels.User{}); err != nil {
 		panic(err)
 	}
-	if err := db.AutoMigrate(&groupmodels.Group{}); err != nil {
+	if err := db.AutoMigrate(&groupmodels.Group{}, &tagmodels.Tag{}); err != nil {
 		panic(err)
 	}
 
@@ -305,6 +308,19 @@ func TestList(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, total)
 
+	tagMgr := tagmanager.New(db)
+	err = tagMgr.UpsertByResourceTypeID(ctx, common.ResourceApplication, application1.ID,
+		[]*tagmodels.TagBasic{{Key: "tier", Value: "core"}})
+	assert.Nil(t, err)
+	total, apps, err = mgr.List(ctx, []uint{1, 2}, q.New(q.KeyWords{
+		common.ApplicationQueryTagSelector: []tagmodels.TagSelector{
+			{Key: "tier", Operator: tagmodels.Equals, Values: sets.NewString("core")},
+		},
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, application1.ID, apps[0].ID)
+
 	err = mgr.DeleteByID(ctx, application0.ID)
 	assert.Nil(t, err)
 
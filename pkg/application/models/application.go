@@ -42,4 +42,6 @@ type Application struct {
 	TemplateRelease string
 	CreatedBy       uint
 	UpdatedBy       uint
+	// HardDeleted is true if the application is deleted permanently and can not be restored
+	HardDeleted bool
 }
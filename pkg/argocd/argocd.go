@@ -31,6 +31,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/trace"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -44,6 +45,8 @@ var (
 	ErrResourceNotFound = stderrors.New("resource not found")
 	ErrResponseNotOK    = stderrors.New("response for argoCD is not 200 OK")
 	ErrUnexpected       = stderrors.New("unexpected error")
+	// ErrOperationInProgress argoCD refuses to sync while another operation is in progress
+	ErrOperationInProgress = stderrors.New("another operation is already in progress")
 )
 
 type (
@@ -77,6 +80,9 @@ type (
 		// GetApplicationTree get resource-tree of an application in argoCD
 		GetApplicationTree(ctx context.Context, application string) (*v1alpha1.ApplicationTree, error)
 
+		// GetApplicationManifests get manifests of an application rendered at the revision in argoCD
+		GetApplicationManifests(ctx context.Context, application, revision string) ([]string, error)
+
 		// GetApplicationResource get a resource under an application in argoCD
 		GetApplicationResource(ctx context.Context, application string,
 			param ResourceParams, resource interface{}) error
@@ -181,11 +187,11 @@ const (
 var (
 	_client = &retryablehttp.Client{
 		HTTPClient: &http.Client{
-			Transport: &http.Transport{
+			Transport: trace.Transport("argocd", &http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: true,
 				},
-			},
+			}),
 			Timeout: _timeout,
 		},
 		RetryMax:     _retry,
@@ -274,7 +280,11 @@ func (h *helper) DeployApplication(ctx context.Context, application string, revi
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+		message := common.Response(ctx, resp)
+		if strings.Contains(message, ErrOperationInProgress.Error()) {
+			return perror.Wrap(ErrOperationInProgress, message)
+		}
+		return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, message)
 	}
 
 	return nil
@@ -427,6 +437,41 @@ func (h *helper) GetApplicationTree(ctx context.Context, application string) (
 	return tree, nil
 }
 
+func (h *helper) GetApplicationManifests(ctx context.Context, application, revision string) (
+	manifests []string, err error) {
+	const op = "argo: get application manifests"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	url := fmt.Sprintf("%v/api/v1/applications/%v/manifests?revision=%v", h.URL, application, revision)
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, herrors.NewErrNotFound(herrors.ApplicationInArgo,
+			fmt.Sprintf("application %s not found", application))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+
+	var manifestResp struct {
+		Manifests []string `json:"manifests"`
+	}
+	if err = json.Unmarshal(data, &manifestResp); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	return manifestResp.Manifests, nil
+}
+
 func (h *helper) GetApplicationResource(ctx context.Context, application string,
 	gvk ResourceParams, resource interface{}) (err error) {
 	const op = "argo: get application resource"
@@ -260,6 +260,16 @@ func SharedTestApplication(ctx context.Context, argoClient *helper, t *testing.T
 		t.Log("application tree:", string(data))
 	}
 
+	if _, err := argoClient.GetApplicationManifests(ctx, "notfound", "master"); err == nil {
+		t.Fatal("expected not found")
+	}
+
+	if manifests, err := argoClient.GetApplicationManifests(ctx, _cluster2, "master"); err != nil {
+		t.Fatal(err)
+	} else {
+		assert.Equal(t, 1, len(manifests))
+	}
+
 	var deployment *apps.Deployment
 	err := argoClient.GetApplicationResource(ctx, _cluster2, ResourceParams{
 		Group:        "apps",
@@ -78,6 +78,8 @@ func NewArgoServer() *ArgoServer {
 		HandlerFunc(c.GetApplication)
 	r.Path("/api/v1/applications/{application}/resource-tree").Methods(http.MethodGet).
 		HandlerFunc(c.GetApplicationTree)
+	r.Path("/api/v1/applications/{application}/manifests").Methods(http.MethodGet).
+		HandlerFunc(c.GetApplicationManifests)
 	r.Path("/api/v1/applications/{application}/resource").Methods(http.MethodGet).
 		Queries("namespace", "{namespace}", "resourceName", "{resourceName}",
 			"group", "{group}", "version", "{version}", "kind", "{kind}").
@@ -236,6 +238,17 @@ func (argoServer *ArgoServer) DeleteApplication(w http.ResponseWriter, r *http.R
 	w.WriteHeader(http.StatusOK)
 }
 
+func (argoServer *ArgoServer) GetApplicationManifests(w http.ResponseWriter, r *http.Request) {
+	if strings.Contains(r.URL.Path, "notfound") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	d := []byte(`{"manifests": [` +
+		`"{\"apiVersion\":\"apps/v1\",\"kind\":\"Deployment\",` +
+		`\"metadata\":{\"name\":\"unit-test-repo-test-2\"}}"]}`)
+	_, _ = w.Write(d)
+}
+
 func (argoServer *ArgoServer) GetApplicationTree(w http.ResponseWriter, r *http.Request) {
 	if strings.Contains(r.URL.Path, "notfound") {
 		w.WriteHeader(http.StatusNotFound)
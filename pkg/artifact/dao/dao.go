@@ -0,0 +1,147 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/artifact/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, artifact *models.Artifact) (*models.Artifact, error)
+	UpdateContent(ctx context.Context, artifact *models.Artifact) error
+	GetByID(ctx context.Context, id uint) (*models.Artifact, error)
+	GetByName(ctx context.Context, pipelinerunID uint, name string) (*models.Artifact, error)
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint, artifactType string) ([]*models.Artifact, error)
+	ListUploadedBefore(ctx context.Context, types []string, before time.Time,
+		afterID uint, limit int) ([]*models.Artifact, error)
+	ListLatestPipelinerunIDs(ctx context.Context, clusterID uint, limit int) ([]uint, error)
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, artifact *models.Artifact) (*models.Artifact, error) {
+	result := d.db.WithContext(ctx).Create(artifact)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	return artifact, nil
+}
+
+func (d *dao) UpdateContent(ctx context.Context, artifact *models.Artifact) error {
+	result := d.db.WithContext(ctx).Model(&models.Artifact{ID: artifact.ID}).
+		Updates(map[string]interface{}{
+			"type":       artifact.Type,
+			"format":     artifact.Format,
+			"size":       artifact.Size,
+			"sha256":     artifact.SHA256,
+			"summary":    artifact.Summary,
+			"object_key": artifact.ObjectKey,
+			"updated_by": artifact.UpdatedBy,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ArtifactInDB,
+			fmt.Sprintf("no artifact found for id %d", artifact.ID))
+	}
+	return nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Artifact, error) {
+	var artifact models.Artifact
+	result := d.db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&artifact)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ArtifactInDB,
+			fmt.Sprintf("no artifact found for id %d", id))
+	}
+	return &artifact, nil
+}
+
+func (d *dao) GetByName(ctx context.Context, pipelinerunID uint, name string) (*models.Artifact, error) {
+	var artifact models.Artifact
+	result := d.db.WithContext(ctx).Where("pipelinerun_id = ? and name = ?", pipelinerunID, name).
+		Limit(1).Find(&artifact)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ArtifactInDB,
+			fmt.Sprintf("no artifact %s found for pipelinerun %d", name, pipelinerunID))
+	}
+	return &artifact, nil
+}
+
+func (d *dao) ListByPipelinerunID(ctx context.Context, pipelinerunID uint,
+	artifactType string) ([]*models.Artifact, error) {
+	artifacts := make([]*models.Artifact, 0)
+	query := d.db.WithContext(ctx).Where("pipelinerun_id = ?", pipelinerunID)
+	if artifactType != "" {
+		query = query.Where("type = ?", artifactType)
+	}
+	result := query.Order("id").Find(&artifacts)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	return artifacts, nil
+}
+
+func (d *dao) ListUploadedBefore(ctx context.Context, types []string, before time.Time,
+	afterID uint, limit int) ([]*models.Artifact, error) {
+	artifacts := make([]*models.Artifact, 0)
+	result := d.db.WithContext(ctx).
+		Where("type in ? and updated_at < ? and id > ?", types, before, afterID).
+		Order("id").Limit(limit).Find(&artifacts)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	return artifacts, nil
+}
+
+func (d *dao) ListLatestPipelinerunIDs(ctx context.Context, clusterID uint, limit int) ([]uint, error) {
+	ids := make([]uint, 0)
+	result := d.db.WithContext(ctx).Model(&models.Artifact{}).
+		Where("cluster_id = ?", clusterID).Distinct("pipelinerun_id").
+		Order("pipelinerun_id desc").Limit(limit).Pluck("pipelinerun_id", &ids)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	return ids, nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Delete(&models.Artifact{}, id)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.ArtifactInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/artifact/dao"
+	"github.com/horizoncd/horizon/pkg/artifact/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, artifact *models.Artifact) (*models.Artifact, error)
+	// UpdateContent updates the content related fields of an artifact after it's uploaded
+	UpdateContent(ctx context.Context, artifact *models.Artifact) error
+	GetByID(ctx context.Context, id uint) (*models.Artifact, error)
+	GetByName(ctx context.Context, pipelinerunID uint, name string) (*models.Artifact, error)
+	// ListByPipelinerunID lists artifacts of the pipelinerun, all types are listed if artifactType is empty
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint, artifactType string) ([]*models.Artifact, error)
+	// ListUploadedBefore lists artifacts of the types last uploaded before the time, ordered by id,
+	// afterID is the id cursor of the last batch
+	ListUploadedBefore(ctx context.Context, types []string, before time.Time,
+		afterID uint, limit int) ([]*models.Artifact, error)
+	// ListLatestPipelinerunIDs lists ids of the latest pipelineruns with artifacts in the cluster
+	ListLatestPipelinerunIDs(ctx context.Context, clusterID uint, limit int) ([]uint, error)
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, artifact *models.Artifact) (*models.Artifact, error) {
+	return m.dao.Create(ctx, artifact)
+}
+
+func (m *manager) UpdateContent(ctx context.Context, artifact *models.Artifact) error {
+	return m.dao.UpdateContent(ctx, artifact)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.Artifact, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) GetByName(ctx context.Context, pipelinerunID uint, name string) (*models.Artifact, error) {
+	return m.dao.GetByName(ctx, pipelinerunID, name)
+}
+
+func (m *manager) ListByPipelinerunID(ctx context.Context, pipelinerunID uint,
+	artifactType string) ([]*models.Artifact, error) {
+	return m.dao.ListByPipelinerunID(ctx, pipelinerunID, artifactType)
+}
+
+func (m *manager) ListUploadedBefore(ctx context.Context, types []string, before time.Time,
+	afterID uint, limit int) ([]*models.Artifact, error) {
+	return m.dao.ListUploadedBefore(ctx, types, before, afterID, limit)
+}
+
+func (m *manager) ListLatestPipelinerunIDs(ctx context.Context, clusterID uint, limit int) ([]uint, error) {
+	return m.dao.ListLatestPipelinerunIDs(ctx, clusterID, limit)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	return m.dao.DeleteByID(ctx, id)
+}
@@ -0,0 +1,114 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/artifact/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Artifact{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	for i, a := range []struct {
+		pipelinerunID uint
+		name          string
+		artifactType  string
+	}{
+		{1, "app.jar", models.TypeArtifact},
+		{1, "junit.xml", models.TypeJUnit},
+		{2, "junit.xml", models.TypeJUnit},
+		{3, "coverage.out", models.TypeCoverage},
+	} {
+		_, err := mgr.Create(ctx, &models.Artifact{
+			PipelinerunID: a.pipelinerunID,
+			ClusterID:     1,
+			Name:          a.name,
+			Type:          a.artifactType,
+			Size:          int64(i + 1),
+			CreatedBy:     1,
+		})
+		assert.Nil(t, err)
+	}
+
+	// name is unique in a pipelinerun
+	_, err = mgr.Create(ctx, &models.Artifact{PipelinerunID: 1, ClusterID: 1, Name: "app.jar"})
+	assert.NotNil(t, err)
+
+	artifacts, err := mgr.ListByPipelinerunID(ctx, 1, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(artifacts))
+	artifacts, err = mgr.ListByPipelinerunID(ctx, 1, models.TypeJUnit)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(artifacts))
+	assert.Equal(t, "junit.xml", artifacts[0].Name)
+
+	artifact, err := mgr.GetByName(ctx, 2, "junit.xml")
+	assert.Nil(t, err)
+	artifact.Size = 100
+	artifact.SHA256 = "abc"
+	artifact.Summary = `{"tests":1}`
+	assert.Nil(t, mgr.UpdateContent(ctx, artifact))
+	artifact, err = mgr.GetByID(ctx, artifact.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), artifact.Size)
+	assert.Equal(t, "abc", artifact.SHA256)
+
+	ids, err := mgr.ListLatestPipelinerunIDs(ctx, 1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{3, 2}, ids)
+
+	before := time.Now().Add(time.Hour)
+	artifacts, err = mgr.ListUploadedBefore(ctx, []string{models.TypeJUnit, models.TypeCoverage}, before, 0, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(artifacts))
+	artifacts, err = mgr.ListUploadedBefore(ctx, []string{models.TypeJUnit, models.TypeCoverage},
+		before, artifacts[1].ID, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(artifacts))
+	artifacts, err = mgr.ListUploadedBefore(ctx, []string{models.TypeArtifact}, time.Now().Add(-time.Hour), 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(artifacts))
+
+	assert.Nil(t, mgr.DeleteByID(ctx, artifact.ID))
+	_, err = mgr.GetByID(ctx, artifact.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
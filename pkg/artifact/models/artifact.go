@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// TypeArtifact is a file built by the pipeline, such as a package or a binary
+	TypeArtifact = "artifact"
+	// TypeJUnit is a test report in JUnit XML
+	TypeJUnit = "junit"
+	// TypeCoverage is a coverage report in the format of cobertura, lcov or go cover profile
+	TypeCoverage = "coverage"
+)
+
+// Artifact is a file uploaded by a step of pipelinerun, its content is kept in the artifact storage
+type Artifact struct {
+	ID            uint
+	PipelinerunID uint   `gorm:"uniqueIndex:idx_pipelinerun_name"`
+	ClusterID     uint   `gorm:"index:idx_cluster_pipelinerun"`
+	Name          string `gorm:"uniqueIndex:idx_pipelinerun_name"`
+	Type          string
+	// Format is the format of a coverage report
+	Format string
+	Size   int64
+	SHA256 string `gorm:"column:sha256"`
+	// ObjectKey is the key of the object in the artifact storage
+	ObjectKey string
+	// Summary is the summary of a test or coverage report in json, empty for other artifacts
+	Summary   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+// ObjectPrefix is the prefix of the objects of an artifact in the artifact storage
+func ObjectPrefix(pipelinerunID, artifactID uint) string {
+	return fmt.Sprintf("artifacts/%d/%d/", pipelinerunID, artifactID)
+}
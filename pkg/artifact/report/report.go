@@ -0,0 +1,288 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CoverageCobertura is the cobertura xml coverage report
+	CoverageCobertura = "cobertura"
+	// CoverageLcov is the lcov tracefile
+	CoverageLcov = "lcov"
+	// CoverageGo is the go cover profile
+	CoverageGo = "gocover"
+
+	// maxFailedCases limits the failed cases kept in the summary of a junit report
+	maxFailedCases = 20
+)
+
+type TestSummary struct {
+	Tests       int          `json:"tests"`
+	Failures    int          `json:"failures"`
+	Errors      int          `json:"errors"`
+	Skipped     int          `json:"skipped"`
+	Time        float64      `json:"time"`
+	FailedCases []FailedCase `json:"failedCases,omitempty"`
+}
+
+type FailedCase struct {
+	Suite   string `json:"suite"`
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+}
+
+type CoverageSummary struct {
+	Format       string  `json:"format"`
+	LinesCovered int64   `json:"linesCovered"`
+	LinesValid   int64   `json:"linesValid"`
+	Percent      float64 `json:"percent"`
+}
+
+type junitSuites struct {
+	XMLName xml.Name
+	Suites  []junitSuite `xml:"testsuite"`
+	// attributes and cases of the root when the root element is a testsuite
+	junitSuite
+}
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+	Skipped   *junitFailure `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// ParseJUnit parses a junit xml report whose root element is testsuites or testsuite,
+// the summary is counted from test cases since the attributes of suites are not always reliable
+func ParseJUnit(content []byte) (*TestSummary, error) {
+	var root junitSuites
+	if err := xml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse junit report: %v", err)
+	}
+	var suites []junitSuite
+	switch root.XMLName.Local {
+	case "testsuites":
+		suites = root.Suites
+	case "testsuite":
+		suites = []junitSuite{root.junitSuite}
+		suites[0].Suites = root.Suites
+	default:
+		return nil, fmt.Errorf("failed to parse junit report: unexpected root element %s", root.XMLName.Local)
+	}
+
+	summary := &TestSummary{}
+	var walk func(suites []junitSuite)
+	walk = func(suites []junitSuite) {
+		for _, suite := range suites {
+			for _, c := range suite.Cases {
+				summary.Tests++
+				if t, err := strconv.ParseFloat(c.Time, 64); err == nil {
+					summary.Time += t
+				}
+				var failure *junitFailure
+				switch {
+				case c.Failure != nil:
+					summary.Failures++
+					failure = c.Failure
+				case c.Error != nil:
+					summary.Errors++
+					failure = c.Error
+				case c.Skipped != nil:
+					summary.Skipped++
+				}
+				if failure != nil && len(summary.FailedCases) < maxFailedCases {
+					suiteName := suite.Name
+					if suiteName == "" {
+						suiteName = c.ClassName
+					}
+					summary.FailedCases = append(summary.FailedCases, FailedCase{
+						Suite:   suiteName,
+						Name:    c.Name,
+						Message: failure.Message,
+					})
+				}
+			}
+			walk(suite.Suites)
+		}
+	}
+	walk(suites)
+	summary.Time = round(summary.Time)
+	return summary, nil
+}
+
+// DetectCoverageFormat detects the format of a coverage report, it returns empty if unknown
+func DetectCoverageFormat(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return CoverageGo
+	case bytes.HasPrefix(trimmed, []byte("<")) && bytes.Contains(trimmed, []byte("<coverage")):
+		return CoverageCobertura
+	case bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")):
+		return CoverageLcov
+	}
+	return ""
+}
+
+// ParseCoverage parses a coverage report in the format, the format is detected if it's empty
+func ParseCoverage(format string, content []byte) (*CoverageSummary, error) {
+	if format == "" {
+		format = DetectCoverageFormat(content)
+	}
+	var (
+		covered, valid int64
+		err            error
+	)
+	switch format {
+	case CoverageCobertura:
+		covered, valid, err = parseCobertura(content)
+	case CoverageLcov:
+		covered, valid, err = parseLcov(content)
+	case CoverageGo:
+		covered, valid, err = parseGoCover(content)
+	default:
+		return nil, fmt.Errorf("unsupported coverage format %q, only %s, %s and %s are supported",
+			format, CoverageCobertura, CoverageLcov, CoverageGo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s coverage report: %v", format, err)
+	}
+	summary := &CoverageSummary{
+		Format:       format,
+		LinesCovered: covered,
+		LinesValid:   valid,
+	}
+	if valid > 0 {
+		summary.Percent = round(float64(covered) * 100 / float64(valid))
+	}
+	return summary, nil
+}
+
+func parseCobertura(content []byte) (int64, int64, error) {
+	var coverage struct {
+		XMLName      xml.Name `xml:"coverage"`
+		LinesCovered int64    `xml:"lines-covered,attr"`
+		LinesValid   int64    `xml:"lines-valid,attr"`
+	}
+	if err := xml.Unmarshal(content, &coverage); err != nil {
+		return 0, 0, err
+	}
+	return coverage.LinesCovered, coverage.LinesValid, nil
+}
+
+// parseLcov sums LH (lines hit) and LF (lines found) of all source files
+func parseLcov(content []byte) (int64, int64, error) {
+	var covered, valid int64
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var target *int64
+		switch {
+		case strings.HasPrefix(line, "LH:"):
+			target = &covered
+		case strings.HasPrefix(line, "LF:"):
+			target = &valid
+		default:
+			continue
+		}
+		n, err := strconv.ParseInt(line[3:], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid line %q", line)
+		}
+		*target += n
+	}
+	return covered, valid, scanner.Err()
+}
+
+// parseGoCover counts statements of a go cover profile, blocks shown in several profiles are counted once
+func parseGoCover(content []byte) (int64, int64, error) {
+	type block struct {
+		statements int64
+		covered    bool
+	}
+	blocks := make(map[string]*block)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if first {
+			first = false
+			if !strings.HasPrefix(line, "mode:") {
+				return 0, 0, fmt.Errorf("missing mode line")
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, 0, fmt.Errorf("invalid line %q", line)
+		}
+		statements, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid line %q", line)
+		}
+		count, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid line %q", line)
+		}
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	var covered, valid int64
+	for _, b := range blocks {
+		valid += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	return covered, valid, nil
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJUnit(t *testing.T) {
+	suites := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="com.example.AppTest" tests="3">
+    <testcase name="testA" classname="com.example.AppTest" time="0.5"/>
+    <testcase name="testB" classname="com.example.AppTest" time="1.25">
+      <failure message="expected 1 but was 2">stack</failure>
+    </testcase>
+    <testcase name="testC" classname="com.example.AppTest"><skipped/></testcase>
+  </testsuite>
+  <testsuite name="com.example.DaoTest">
+    <testcase name="testD" classname="com.example.DaoTest" time="0.25">
+      <error message="connection refused"/>
+    </testcase>
+  </testsuite>
+</testsuites>`
+	summary, err := ParseJUnit([]byte(suites))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, summary.Tests)
+	assert.Equal(t, 1, summary.Failures)
+	assert.Equal(t, 1, summary.Errors)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, 2.0, summary.Time)
+	assert.Equal(t, []FailedCase{
+		{Suite: "com.example.AppTest", Name: "testB", Message: "expected 1 but was 2"},
+		{Suite: "com.example.DaoTest", Name: "testD", Message: "connection refused"},
+	}, summary.FailedCases)
+
+	suite := `<testsuite name="pkg"><testcase name="TestA"/><testcase name="TestB"><failure/></testcase></testsuite>`
+	summary, err = ParseJUnit([]byte(suite))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.Tests)
+	assert.Equal(t, 1, summary.Failures)
+	assert.Equal(t, "pkg", summary.FailedCases[0].Suite)
+
+	_, err = ParseJUnit([]byte(`<coverage/>`))
+	assert.NotNil(t, err)
+	_, err = ParseJUnit([]byte(`not xml`))
+	assert.NotNil(t, err)
+}
+
+func TestParseCoverage(t *testing.T) {
+	cobertura := `<?xml version="1.0" ?>
+<coverage line-rate="0.75" lines-covered="75" lines-valid="100" version="1.9"></coverage>`
+	lcov := "TN:\nSF:a.js\nLF:10\nLH:5\nend_of_record\nSF:b.js\nLF:10\nLH:10\nend_of_record\n"
+	gocover := `mode: set
+a.go:1.1,2.2 3 1
+a.go:3.1,4.2 1 0
+b.go:1.1,2.2 4 0
+b.go:1.1,2.2 4 1
+`
+	for _, c := range []struct {
+		format  string
+		content string
+		want    CoverageSummary
+	}{
+		{"", cobertura, CoverageSummary{Format: CoverageCobertura, LinesCovered: 75, LinesValid: 100, Percent: 75}},
+		{CoverageLcov, lcov, CoverageSummary{Format: CoverageLcov, LinesCovered: 15, LinesValid: 20, Percent: 75}},
+		{"", lcov, CoverageSummary{Format: CoverageLcov, LinesCovered: 15, LinesValid: 20, Percent: 75}},
+		{"", gocover, CoverageSummary{Format: CoverageGo, LinesCovered: 7, LinesValid: 8, Percent: 87.5}},
+	} {
+		summary, err := ParseCoverage(c.format, []byte(c.content))
+		assert.Nil(t, err)
+		assert.Equal(t, c.want, *summary)
+	}
+
+	_, err := ParseCoverage("", []byte("unknown"))
+	assert.NotNil(t, err)
+	_, err = ParseCoverage(CoverageLcov, []byte("LF:x"))
+	assert.NotNil(t, err)
+	_, err = ParseCoverage(CoverageGo, []byte("a.go:1.1,2.2 3 1"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,111 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/asynctask/models"
+	"github.com/horizoncd/horizon/pkg/common"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, task *models.AsyncTask) (*models.AsyncTask, error)
+	GetByID(ctx context.Context, id uint) (*models.AsyncTask, error)
+	UpdateProgress(ctx context.Context, id uint, done, total int, message string) error
+	UpdateResult(ctx context.Context, id uint, taskResult string) error
+	Finish(ctx context.Context, id uint, status, taskResult, errMsg string) error
+	Heartbeat(ctx context.Context, ids []uint) error
+	FailStale(ctx context.Context, before time.Time, errMsg string) (int64, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, task *models.AsyncTask) (*models.AsyncTask, error) {
+	result := d.db.WithContext(ctx).Create(task)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.AsyncTaskInDB, result.Error.Error())
+	}
+	return task, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.AsyncTask, error) {
+	var task models.AsyncTask
+	result := d.db.WithContext(ctx).Raw(common.AsyncTaskGetByID, id).Scan(&task)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.AsyncTaskInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.AsyncTaskInDB,
+			fmt.Sprintf("async task with id %d not found", id))
+	}
+	return &task, nil
+}
+
+func (d *dao) UpdateProgress(ctx context.Context, id uint, done, total int, message string) error {
+	result := d.db.WithContext(ctx).Exec(common.AsyncTaskUpdateProgress, done, total, message, time.Now(), id)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.AsyncTaskInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) UpdateResult(ctx context.Context, id uint, taskResult string) error {
+	result := d.db.WithContext(ctx).Exec(common.AsyncTaskUpdateResult, taskResult, time.Now(), id)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.AsyncTaskInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) Finish(ctx context.Context, id uint, status, taskResult, errMsg string) error {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Exec(common.AsyncTaskFinish, status, taskResult, errMsg, now, now, id)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.AsyncTaskInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) Heartbeat(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := d.db.WithContext(ctx).Exec(common.AsyncTaskHeartbeat, time.Now(), ids, models.StatusRunning)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.AsyncTaskInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) FailStale(ctx context.Context, before time.Time, errMsg string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Exec(common.AsyncTaskFailStale, models.StatusFailed, errMsg, now, now,
+		models.StatusRunning, before)
+	if result.Error != nil {
+		return 0, herrors.NewErrUpdateFailed(herrors.AsyncTaskInDB, result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
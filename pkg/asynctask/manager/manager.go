@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/asynctask/dao"
+	"github.com/horizoncd/horizon/pkg/asynctask/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, task *models.AsyncTask) (*models.AsyncTask, error)
+	GetByID(ctx context.Context, id uint) (*models.AsyncTask, error)
+	// UpdateProgress records the count of finished steps, all steps and what is being done
+	UpdateProgress(ctx context.Context, id uint, done, total int, message string) error
+	// UpdateResult records the partial result of a running task
+	UpdateResult(ctx context.Context, id uint, result string) error
+	// Finish marks the task as succeeded or failed with its result and error,
+	// the partial result is kept when result is empty
+	Finish(ctx context.Context, id uint, status, result, errMsg string) error
+	// Heartbeat touches the running tasks, so that they are not taken as stale
+	Heartbeat(ctx context.Context, ids []uint) error
+	// FailStale marks the running tasks without heartbeats since before as failed,
+	// which are left by the servers stopped abnormally. It returns the count of the tasks failed
+	FailStale(ctx context.Context, before time.Time, errMsg string) (int64, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, task *models.AsyncTask) (*models.AsyncTask, error) {
+	return m.dao.Create(ctx, task)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.AsyncTask, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) UpdateProgress(ctx context.Context, id uint, done, total int, message string) error {
+	return m.dao.UpdateProgress(ctx, id, done, total, message)
+}
+
+func (m *manager) UpdateResult(ctx context.Context, id uint, result string) error {
+	return m.dao.UpdateResult(ctx, id, result)
+}
+
+func (m *manager) Finish(ctx context.Context, id uint, status, result, errMsg string) error {
+	return m.dao.Finish(ctx, id, status, result, errMsg)
+}
+
+func (m *manager) Heartbeat(ctx context.Context, ids []uint) error {
+	return m.dao.Heartbeat(ctx, ids)
+}
+
+func (m *manager) FailStale(ctx context.Context, before time.Time, errMsg string) (int64, error) {
+	return m.dao.FailStale(ctx, before, errMsg)
+}
@@ -0,0 +1,109 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/asynctask/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.AsyncTask{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByID(ctx, 100)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	task, err := mgr.Create(ctx, &models.AsyncTask{
+		Type:      models.TypeClusterCreate,
+		Status:    models.StatusRunning,
+		CreatedBy: 1,
+	})
+	assert.Nil(t, err)
+
+	err = mgr.UpdateProgress(ctx, task.ID, 1, 3, "creating git repo")
+	assert.Nil(t, err)
+	err = mgr.UpdateResult(ctx, task.ID, `{"id":1}`)
+	assert.Nil(t, err)
+
+	task, err = mgr.GetByID(ctx, task.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRunning, task.Status)
+	assert.Equal(t, 1, task.Done)
+	assert.Equal(t, 3, task.Total)
+	assert.Equal(t, "creating git repo", task.Message)
+	assert.Equal(t, `{"id":1}`, task.Result)
+	assert.Nil(t, task.FinishedAt)
+
+	err = mgr.Finish(ctx, task.ID, models.StatusFailed, `{"id":1}`, "git repo exists")
+	assert.Nil(t, err)
+
+	task, err = mgr.GetByID(ctx, task.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusFailed, task.Status)
+	assert.Equal(t, "git repo exists", task.ErrorMessage)
+	assert.NotNil(t, task.FinishedAt)
+}
+
+func TestFailStale(t *testing.T) {
+	stale, err := mgr.Create(ctx, &models.AsyncTask{
+		Type:      models.TypeClusterCreate,
+		Status:    models.StatusRunning,
+		UpdatedAt: time.Now().Add(-time.Hour),
+	})
+	assert.Nil(t, err)
+	alive, err := mgr.Create(ctx, &models.AsyncTask{
+		Type:      models.TypeClusterCreate,
+		Status:    models.StatusRunning,
+		UpdatedAt: time.Now().Add(-time.Hour),
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, mgr.Heartbeat(ctx, nil))
+	assert.Nil(t, mgr.Heartbeat(ctx, []uint{alive.ID}))
+	count, err := mgr.FailStale(ctx, time.Now().Add(-time.Minute), "server stopped")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	stale, err = mgr.GetByID(ctx, stale.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusFailed, stale.Status)
+	assert.Equal(t, "server stopped", stale.ErrorMessage)
+	assert.NotNil(t, stale.FinishedAt)
+	alive, err = mgr.GetByID(ctx, alive.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRunning, alive.Status)
+}
@@ -0,0 +1,49 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+const (
+	TypeClusterCreate      = "cluster_create"
+	TypeClusterRelease     = "cluster_release"
+	TypeClusterBatchDeploy = "cluster_batch_deploy"
+	TypeApplicationImport  = "application_import"
+)
+
+// AsyncTask is a long-running operation executed in background,
+// its progress, result and error are kept so that callers can poll them
+type AsyncTask struct {
+	ID     uint
+	Type   string
+	Status string
+	// Done and Total are counts of finished steps and all steps
+	Done    int
+	Total   int
+	Message string
+	// Result is the json of the result, partial result is kept while the task is running
+	Result       string
+	ErrorMessage string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	FinishedAt   *time.Time
+	CreatedBy    uint
+}
@@ -0,0 +1,32 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import "context"
+
+type reporterKey struct{}
+
+// WithReporter returns a context carrying the reporter of the running task
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// Report reports the progress of the task running with ctx,
+// it does nothing when ctx does not belong to a task, so that shared code paths can call it freely
+func Report(ctx context.Context, done, total int, message string) {
+	if r, ok := ctx.Value(reporterKey{}).(Reporter); ok {
+		r.Progress(done, total, message)
+	}
+}
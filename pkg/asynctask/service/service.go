@@ -0,0 +1,252 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	asynctaskmanager "github.com/horizoncd/horizon/pkg/asynctask/manager"
+	"github.com/horizoncd/horizon/pkg/asynctask/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Reporter reports the progress and the partial result of a running task
+type Reporter interface {
+	Progress(done, total int, message string)
+	Result(result interface{})
+}
+
+// Func is the body of a task, the returned value is recorded as the result of the task
+type Func func(ctx context.Context, reporter Reporter) (interface{}, error)
+
+const (
+	// heartbeatInterval is how often the tasks running in this server are touched
+	heartbeatInterval = time.Minute
+	// staleTimeout is how long a running task without heartbeats is taken as left by a stopped server
+	staleTimeout = 3 * heartbeatInterval
+
+	_errStale       = "task is interrupted, the server running it stopped"
+	_errInterrupted = "task is interrupted by the shutdown of the server"
+)
+
+var errDraining = errors.New("server is shutting down, no more tasks are accepted")
+
+type Service interface {
+	// Submit records a running task and executes fn in background,
+	// the task is returned immediately so that callers can poll it by id
+	Submit(ctx context.Context, taskType string, fn Func) (*models.AsyncTask, error)
+	// Run fails the tasks left running by the servers stopped abnormally, and keeps the heartbeats
+	// of the tasks running in this server until ctx is done
+	Run(ctx context.Context)
+	// Drain stops accepting tasks and waits for the running ones until timeout, the ones left are marked
+	// as failed. It should be called before the database is closed
+	Drain(timeout time.Duration)
+}
+
+type service struct {
+	taskMgr asynctaskmanager.Manager
+
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	running  map[uint]struct{}
+	draining bool
+}
+
+func NewService(manager *managerparam.Manager) Service {
+	return &service{
+		taskMgr: manager.AsyncTaskMgr,
+		running: make(map[uint]struct{}),
+	}
+}
+
+func (s *service) Submit(ctx context.Context, taskType string, fn Func) (*models.AsyncTask, error) {
+	s.mu.Lock()
+	draining := s.draining
+	s.mu.Unlock()
+	if draining {
+		return nil, errDraining
+	}
+
+	task := &models.AsyncTask{
+		Type:   taskType,
+		Status: models.StatusRunning,
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err == nil {
+		task.CreatedBy = currentUser.GetID()
+	}
+	task, err = s.taskMgr.Create(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	// should use a new context, the request context is canceled once the response is written
+	rid, err := requestid.FromContext(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to get request id from context")
+	}
+	newctx := log.WithContext(context.Background(), rid)
+	if currentUser != nil {
+		newctx = common.WithContext(newctx, currentUser)
+	}
+	r := &reporter{ctx: newctx, taskID: task.ID, taskMgr: s.taskMgr}
+	newctx = WithReporter(newctx, r)
+
+	// checked again with the registration, so that Drain never waits without the tasks submitted meanwhile
+	s.mu.Lock()
+	if s.draining {
+		s.mu.Unlock()
+		s.finish(newctx, task.ID, nil, errDraining)
+		return nil, errDraining
+	}
+	s.running[task.ID] = struct{}{}
+	s.wg.Add(1)
+	s.mu.Unlock()
+	go func() {
+		defer s.wg.Done()
+		var (
+			result interface{}
+			err    error
+		)
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("task panicked: %v", p)
+			}
+			s.finish(newctx, task.ID, result, err)
+			s.mu.Lock()
+			delete(s.running, task.ID)
+			s.mu.Unlock()
+		}()
+		result, err = fn(newctx, r)
+	}()
+
+	return task, nil
+}
+
+func (s *service) finish(ctx context.Context, id uint, result interface{}, taskErr error) {
+	status, errMsg := models.StatusSucceeded, ""
+	if taskErr != nil {
+		status, errMsg = models.StatusFailed, taskErr.Error()
+	}
+	resultJSON := ""
+	if result != nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			log.Errorf(ctx, "failed to marshal result of async task %d: %v", id, err)
+		} else {
+			resultJSON = string(b)
+		}
+	}
+	if err := s.taskMgr.Finish(ctx, id, status, resultJSON, errMsg); err != nil {
+		log.Errorf(ctx, "failed to finish async task %d: %v", id, err)
+	}
+}
+
+func (s *service) Run(ctx context.Context) {
+	s.failStale(ctx)
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.taskMgr.Heartbeat(ctx, s.runningIDs()); err != nil {
+				log.Warningf(ctx, "failed to heartbeat async tasks: %v", err)
+			}
+			s.failStale(ctx)
+		}
+	}
+}
+
+// failStale fails the running tasks whose heartbeats stopped, every server does it
+// as the servers running the tasks may never start again
+func (s *service) failStale(ctx context.Context) {
+	count, err := s.taskMgr.FailStale(ctx, time.Now().Add(-staleTimeout), _errStale)
+	if err != nil {
+		log.Warningf(ctx, "failed to fail stale async tasks: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Infof(ctx, "%d stale async tasks are marked as failed", count)
+	}
+}
+
+func (s *service) Drain(timeout time.Duration) {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return
+	case <-time.After(timeout):
+	}
+
+	ctx := context.Background()
+	for _, id := range s.runningIDs() {
+		if err := s.taskMgr.Finish(ctx, id, models.StatusFailed, "", _errInterrupted); err != nil {
+			log.Errorf(ctx, "failed to fail async task %d interrupted: %v", id, err)
+		}
+	}
+}
+
+func (s *service) runningIDs() []uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]uint, 0, len(s.running))
+	for id := range s.running {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+type reporter struct {
+	ctx     context.Context
+	taskID  uint
+	taskMgr asynctaskmanager.Manager
+}
+
+// Progress records the progress of the task, errors are logged and ignored
+// so that tasks are never broken by reporting
+func (r *reporter) Progress(done, total int, message string) {
+	if err := r.taskMgr.UpdateProgress(r.ctx, r.taskID, done, total, message); err != nil {
+		log.Warningf(r.ctx, "failed to update progress of async task %d: %v", r.taskID, err)
+	}
+}
+
+func (r *reporter) Result(result interface{}) {
+	b, err := json.Marshal(result)
+	if err != nil {
+		log.Warningf(r.ctx, "failed to marshal result of async task %d: %v", r.taskID, err)
+		return
+	}
+	if err := r.taskMgr.UpdateResult(r.ctx, r.taskID, string(b)); err != nil {
+		log.Warningf(r.ctx, "failed to update result of async task %d: %v", r.taskID, err)
+	}
+}
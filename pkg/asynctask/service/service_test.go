@@ -0,0 +1,141 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/asynctask/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	// tasks run in other goroutines, the in-memory database must be shared by one connection
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	assert.Nil(t, db.AutoMigrate(&models.AsyncTask{}))
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	manager := managerparam.InitManager(db)
+	svc := NewService(manager)
+
+	// reporting outside a task does nothing
+	Report(ctx, 1, 2, "nothing")
+
+	wait := func(id uint) *models.AsyncTask {
+		var task *models.AsyncTask
+		assert.Eventually(t, func() bool {
+			task, err = manager.AsyncTaskMgr.GetByID(ctx, id)
+			return err == nil && task.Status != models.StatusRunning
+		}, 5*time.Second, 10*time.Millisecond)
+		return task
+	}
+
+	task, err := svc.Submit(ctx, models.TypeClusterCreate,
+		func(ctx context.Context, reporter Reporter) (interface{}, error) {
+			Report(ctx, 1, 2, "step 1")
+			reporter.Progress(2, 2, "step 2")
+			return map[string]int{"id": 3}, nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRunning, task.Status)
+	assert.Equal(t, uint(1), task.CreatedBy)
+
+	task = wait(task.ID)
+	assert.Equal(t, models.StatusSucceeded, task.Status)
+	assert.Equal(t, 2, task.Done)
+	assert.Equal(t, "step 2", task.Message)
+	assert.Equal(t, `{"id":3}`, task.Result)
+	assert.NotNil(t, task.FinishedAt)
+
+	task, err = svc.Submit(ctx, models.TypeClusterCreate,
+		func(ctx context.Context, reporter Reporter) (interface{}, error) {
+			reporter.Result([]string{"a"})
+			return nil, errors.New("failed to create git repo")
+		})
+	assert.Nil(t, err)
+	task = wait(task.ID)
+	assert.Equal(t, models.StatusFailed, task.Status)
+	assert.Equal(t, "failed to create git repo", task.ErrorMessage)
+	assert.Equal(t, `["a"]`, task.Result)
+
+	task, err = svc.Submit(ctx, models.TypeClusterCreate,
+		func(ctx context.Context, reporter Reporter) (interface{}, error) {
+			panic("boom")
+		})
+	assert.Nil(t, err)
+	task = wait(task.ID)
+	assert.Equal(t, models.StatusFailed, task.Status)
+	assert.Equal(t, "task panicked: boom", task.ErrorMessage)
+}
+
+func TestDrainAndFailStale(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	assert.Nil(t, db.AutoMigrate(&models.AsyncTask{}))
+	ctx := context.Background()
+	manager := managerparam.InitManager(db)
+	svc := NewService(manager)
+
+	// the tasks left by the servers stopped are failed once the service runs
+	stale, err := manager.AsyncTaskMgr.Create(ctx, &models.AsyncTask{
+		Type:      models.TypeClusterCreate,
+		Status:    models.StatusRunning,
+		UpdatedAt: time.Now().Add(-time.Hour),
+	})
+	assert.Nil(t, err)
+	runCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	svc.Run(runCtx)
+	stale, err = manager.AsyncTaskMgr.GetByID(ctx, stale.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusFailed, stale.Status)
+	assert.Equal(t, _errStale, stale.ErrorMessage)
+
+	// the tasks not finished in time are failed on drain, and no more tasks are accepted
+	release := make(chan struct{})
+	defer close(release)
+	task, err := svc.Submit(ctx, models.TypeClusterBatchDeploy,
+		func(ctx context.Context, reporter Reporter) (interface{}, error) {
+			<-release
+			return nil, nil
+		})
+	assert.Nil(t, err)
+	svc.Drain(50 * time.Millisecond)
+	task, err = manager.AsyncTaskMgr.GetByID(ctx, task.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusFailed, task.Status)
+	assert.Equal(t, _errInterrupted, task.ErrorMessage)
+
+	_, err = svc.Submit(ctx, models.TypeClusterBatchDeploy,
+		func(ctx context.Context, reporter Reporter) (interface{}, error) {
+			return nil, nil
+		})
+	assert.Equal(t, errDraining, err)
+}
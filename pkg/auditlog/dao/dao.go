@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	corecommon "github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auditlog/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, auditLog *models.AuditLog) error
+	// List lists the audit logs matching the keywords of query, latest first
+	List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, auditLog *models.AuditLog) error {
+	if result := d.db.WithContext(ctx).Create(auditLog); result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int, error) {
+	var (
+		auditLogs []*models.AuditLog
+		total     int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.AuditLog{})
+	if query != nil {
+		for k, v := range query.Keywords {
+			switch k {
+			case corecommon.AuditLogQueryUserID:
+				statement = statement.Where("user_id = ?", v)
+			case corecommon.AuditLogQueryRobotID:
+				statement = statement.Where("robot_id = ?", v)
+			case corecommon.AuditLogQueryMethod:
+				statement = statement.Where("method = ?", v)
+			case corecommon.AuditLogQueryResourceType:
+				statement = statement.Where("resource_type = ?", v)
+			case corecommon.AuditLogQueryResourceName:
+				statement = statement.Where("resource_name = ?", v)
+			case corecommon.AuditLogQueryStatusCode:
+				statement = statement.Where("status_code = ?", v)
+			case corecommon.StartTime:
+				statement = statement.Where("created_at >= ?", v)
+			case corecommon.EndTime:
+				statement = statement.Where("created_at < ?", v)
+			}
+		}
+	}
+	if result := statement.Count(&total); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Offset(query.Offset()).Limit(query.Limit())
+	}
+	if result := statement.Order("id desc").Find(&auditLogs); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return auditLogs, int(total), nil
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auditlog/dao"
+	"github.com/horizoncd/horizon/pkg/auditlog/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, auditLog *models.AuditLog) error
+	// List lists the audit logs matching the keywords of query, latest first
+	List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, auditLog *models.AuditLog) error {
+	return m.dao.Create(ctx, auditLog)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int, error) {
+	return m.dao.List(ctx, query)
+}
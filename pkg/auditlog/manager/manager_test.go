@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auditlog/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	for _, auditLog := range []*models.AuditLog{
+		{UserID: 1, Method: http.MethodPost, ResourceType: "clusters", ResourceName: "1", StatusCode: http.StatusOK},
+		{UserID: 2, Method: http.MethodDelete, ResourceType: "clusters", ResourceName: "1",
+			StatusCode: http.StatusForbidden},
+		{UserID: 1, Method: http.MethodPut, ResourceType: "applications", ResourceName: "2",
+			StatusCode: http.StatusOK},
+	} {
+		assert.Nil(t, mgr.Create(ctx, auditLog))
+	}
+
+	auditLogs, total, err := mgr.List(ctx, q.New(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	// latest first
+	assert.Equal(t, "applications", auditLogs[0].ResourceType)
+
+	auditLogs, total, err = mgr.List(ctx, q.New(q.KeyWords{
+		common.AuditLogQueryResourceType: "clusters",
+		common.AuditLogQueryResourceName: "1",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, http.MethodDelete, auditLogs[0].Method)
+
+	_, total, err = mgr.List(ctx, q.New(q.KeyWords{
+		common.AuditLogQueryUserID:     uint(1),
+		common.AuditLogQueryStatusCode: uint(http.StatusOK),
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+
+	_, total, err = mgr.List(ctx, q.New(q.KeyWords{
+		common.StartTime: time.Now().Add(time.Hour),
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+
+	auditLogs, total, err = mgr.List(ctx, &q.Query{PageNumber: 1, PageSize: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 1, len(auditLogs))
+}
@@ -0,0 +1,41 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// AuditLog records a mutating api request, so that who changed what and when can be found out
+type AuditLog struct {
+	ID uint `json:"id"`
+	// UserID is 0 if the request is not authenticated
+	UserID   uint   `json:"userID"`
+	UserName string `json:"userName"`
+	// RobotID is the robot the request is sent by with its token, 0 for the requests of humans
+	RobotID uint   `json:"robotID"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	// ResourceType and ResourceName are parsed from the path, e.g. clusters and 1 for /apis/core/v2/clusters/1/builddeploy
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourceName"`
+	SubResource  string `json:"subResource"`
+	// RequestDigest is the sha256 of the request body, the body is not kept since it may contain secrets
+	RequestDigest string `json:"requestDigest"`
+	StatusCode    int    `json:"statusCode"`
+	// LatencyMs is the milliseconds the request took
+	LatencyMs int64     `json:"latencyMs"`
+	SourceIP  string    `json:"sourceIP"`
+	RequestID string    `json:"requestID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
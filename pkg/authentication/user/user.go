@@ -27,6 +27,11 @@ type User interface {
 	GetEmail() string
 	String() string
 	IsAdmin() bool
+	IsAuditor() bool
+	// IsRobot returns whether the user is a robot user, such as the user of a robot or a resource access token
+	IsRobot() bool
+	// GetRobotID returns the id of the robot if the user is authenticated as a robot, or 0
+	GetRobotID() uint
 
 	GetStrID() string
 }
@@ -37,6 +42,9 @@ type DefaultInfo struct {
 	ID       uint
 	Email    string
 	Admin    bool
+	Auditor  bool
+	Robot    bool
+	RobotID  uint
 }
 
 func (d *DefaultInfo) GetName() string {
@@ -63,6 +71,18 @@ func (d *DefaultInfo) IsAdmin() bool {
 	return d.Admin
 }
 
+func (d *DefaultInfo) IsAuditor() bool {
+	return d.Auditor
+}
+
+func (d *DefaultInfo) IsRobot() bool {
+	return d.Robot
+}
+
+func (d *DefaultInfo) GetRobotID() uint {
+	return d.RobotID
+}
+
 func (d *DefaultInfo) GetStrID() string {
 	return strconv.FormatUint(uint64(d.GetID()), 10)
 }
@@ -0,0 +1,501 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	oauthmodels "github.com/horizoncd/horizon/pkg/oauth/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+)
+
+type Result string
+
+const (
+	Created   Result = "created"
+	Updated   Result = "updated"
+	Unchanged Result = "unchanged"
+)
+
+// Bootstrapper reconciles the resources declared by the spec into the database idempotently,
+// resources which are not declared are left untouched
+type Bootstrapper struct {
+	mgr      *managerparam.Manager
+	oauthMgr oauthmanager.Manager
+	out      io.Writer
+	dryRun   bool
+
+	// plannedGroups are the groups to be created in dry run, which can not be found in db
+	plannedGroups map[string]bool
+}
+
+func New(mgr *managerparam.Manager, oauthMgr oauthmanager.Manager, out io.Writer, dryRun bool) *Bootstrapper {
+	return &Bootstrapper{
+		mgr:           mgr,
+		oauthMgr:      oauthMgr,
+		out:           out,
+		dryRun:        dryRun,
+		plannedGroups: make(map[string]bool),
+	}
+}
+
+// Apply applies the spec as the user in ctx, who owns the groups and templates created.
+// In dry run, changes are only reported without being made.
+func (b *Bootstrapper) Apply(ctx context.Context, spec *Spec) error {
+	if _, err := common.UserFromContext(ctx); err != nil {
+		return err
+	}
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if err := b.applyRegions(ctx, spec.Regions); err != nil {
+		return err
+	}
+	if err := b.applyEnvironments(ctx, spec.Environments); err != nil {
+		return err
+	}
+	if err := b.applyGroups(ctx, spec.Groups); err != nil {
+		return err
+	}
+	if err := b.applyTemplates(ctx, spec.Templates); err != nil {
+		return err
+	}
+	return b.applyOauthApps(ctx, spec.OauthApps)
+}
+
+func (b *Bootstrapper) report(kind, name string, result Result) {
+	if b.dryRun && result != Unchanged {
+		fmt.Fprintf(b.out, "%s %s %s (dry run)\n", kind, name, result)
+		return
+	}
+	fmt.Fprintf(b.out, "%s %s %s\n", kind, name, result)
+}
+
+func (b *Bootstrapper) applyRegions(ctx context.Context, regions []Region) error {
+	if len(regions) == 0 {
+		return nil
+	}
+	registries, err := b.mgr.RegistryMgr.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	registryIDs := make(map[string]uint, len(registries))
+	for _, registry := range registries {
+		registryIDs[registry.Name] = registry.ID
+	}
+	regionsInDB, err := b.mgr.RegionMgr.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*regionmodels.Region, len(regionsInDB))
+	for _, region := range regionsInDB {
+		existing[region.Name] = region
+	}
+
+	for _, r := range regions {
+		registryID, ok := registryIDs[r.Registry]
+		if !ok {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"registry %s of region %s does not exist", r.Registry, r.Name)
+		}
+		region := &regionmodels.Region{
+			Name:          r.Name,
+			DisplayName:   r.DisplayName,
+			Server:        r.Server,
+			Certificate:   r.Certificate,
+			IngressDomain: r.IngressDomain,
+			PrometheusURL: r.PrometheusURL,
+			RegistryID:    registryID,
+			CDType:        r.CDType,
+			Disabled:      r.Disabled,
+		}
+		if region.CDType == "" {
+			region.CDType = regionmodels.CDTypeArgoCD
+		}
+		regionInDB, ok := existing[r.Name]
+		switch {
+		case !ok:
+			if !b.dryRun {
+				if _, err := b.mgr.RegionMgr.Create(ctx, region); err != nil {
+					return err
+				}
+			}
+			b.report("region", r.Name, Created)
+		case regionInDB.DisplayName == region.DisplayName && regionInDB.Server == region.Server &&
+			regionInDB.Certificate == region.Certificate && regionInDB.IngressDomain == region.IngressDomain &&
+			regionInDB.PrometheusURL == region.PrometheusURL && regionInDB.RegistryID == region.RegistryID &&
+			regionInDB.CDType == region.CDType && regionInDB.Disabled == region.Disabled:
+			b.report("region", r.Name, Unchanged)
+		default:
+			if !b.dryRun {
+				if err := b.mgr.RegionMgr.UpdateByID(ctx, regionInDB.ID, region); err != nil {
+					return err
+				}
+			}
+			b.report("region", r.Name, Updated)
+		}
+	}
+	return nil
+}
+
+func (b *Bootstrapper) applyEnvironments(ctx context.Context, environments []Environment) error {
+	if len(environments) == 0 {
+		return nil
+	}
+	environmentsInDB, err := b.mgr.EnvMgr.ListAllEnvironment(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*envmodels.Environment, len(environmentsInDB))
+	for _, environment := range environmentsInDB {
+		existing[environment.Name] = environment
+	}
+
+	for _, e := range environments {
+		environmentInDB, ok := existing[e.Name]
+		switch {
+		case !ok:
+			if !b.dryRun {
+				if _, err := b.mgr.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{
+					Name:        e.Name,
+					DisplayName: e.DisplayName,
+				}); err != nil {
+					return err
+				}
+			}
+			b.report("environment", e.Name, Created)
+		case environmentInDB.DisplayName == e.DisplayName:
+			b.report("environment", e.Name, Unchanged)
+		default:
+			if !b.dryRun {
+				if err := b.mgr.EnvMgr.UpdateByID(ctx, environmentInDB.ID, &envmodels.Environment{
+					DisplayName: e.DisplayName,
+				}); err != nil {
+					return err
+				}
+			}
+			b.report("environment", e.Name, Updated)
+		}
+
+		if err := b.applyEnvironmentRegions(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bootstrapper) applyEnvironmentRegions(ctx context.Context, e Environment) error {
+	if len(e.Regions) == 0 {
+		return nil
+	}
+	bindingsInDB, err := b.mgr.EnvRegionMgr.ListByEnvironment(ctx, e.Name)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*envregionmodels.EnvironmentRegion, len(bindingsInDB))
+	for _, binding := range bindingsInDB {
+		existing[binding.RegionName] = binding
+	}
+
+	for _, region := range e.Regions {
+		name := fmt.Sprintf("%s/%s", e.Name, region)
+		isDefault := region == e.DefaultRegion
+		binding, ok := existing[region]
+		switch {
+		case !ok:
+			if !b.dryRun {
+				binding, err = b.mgr.EnvRegionMgr.CreateEnvironmentRegion(ctx, &envregionmodels.EnvironmentRegion{
+					EnvironmentName: e.Name,
+					RegionName:      region,
+				})
+				if err != nil {
+					return err
+				}
+				if isDefault {
+					if err := b.mgr.EnvRegionMgr.SetEnvironmentRegionToDefaultByID(ctx, binding.ID); err != nil {
+						return err
+					}
+				}
+			}
+			b.report("environment region", name, Created)
+		case isDefault && !binding.IsDefault:
+			if !b.dryRun {
+				if err := b.mgr.EnvRegionMgr.SetEnvironmentRegionToDefaultByID(ctx, binding.ID); err != nil {
+					return err
+				}
+			}
+			b.report("environment region", name, Updated)
+		default:
+			b.report("environment region", name, Unchanged)
+		}
+	}
+	return nil
+}
+
+func (b *Bootstrapper) applyGroups(ctx context.Context, groups []Group) error {
+	for _, g := range groups {
+		groupInDB, err := b.getGroup(ctx, g.FullPath)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return err
+			}
+			if err := b.createGroup(ctx, g); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if groupInDB.Name == g.Name && groupInDB.Description == g.Description &&
+			groupInDB.VisibilityLevel == g.VisibilityLevel {
+			b.report("group", g.FullPath, Unchanged)
+			continue
+		}
+		if !b.dryRun {
+			groupInDB.Name = g.Name
+			groupInDB.Description = g.Description
+			groupInDB.VisibilityLevel = g.VisibilityLevel
+			if err := b.mgr.GroupMgr.UpdateBasic(ctx, groupInDB); err != nil {
+				return err
+			}
+		}
+		b.report("group", g.FullPath, Updated)
+	}
+	return nil
+}
+
+func (b *Bootstrapper) createGroup(ctx context.Context, g Group) error {
+	parentPath, groupPath := path.Split(g.FullPath)
+	parentPath = strings.TrimSuffix(parentPath, "/")
+	parentID, _, err := b.resolveGroup(ctx, parentPath)
+	if err != nil {
+		return err
+	}
+	if b.dryRun {
+		b.plannedGroups[g.FullPath] = true
+	} else {
+		if _, err := b.mgr.GroupMgr.Create(ctx, &groupmodels.Group{
+			Name:            g.Name,
+			Path:            groupPath,
+			Description:     g.Description,
+			VisibilityLevel: g.VisibilityLevel,
+			ParentID:        parentID,
+		}); err != nil {
+			return err
+		}
+	}
+	b.report("group", g.FullPath, Created)
+	return nil
+}
+
+// resolveGroup returns the id of the group by its full path, the root group is resolved to 0.
+// planned is true if the group is to be created in dry run, whose id is unknown.
+func (b *Bootstrapper) resolveGroup(ctx context.Context, fullPath string) (id uint, planned bool, err error) {
+	if fullPath == "" {
+		return 0, false, nil
+	}
+	if b.plannedGroups[fullPath] {
+		return 0, true, nil
+	}
+	group, err := b.getGroup(ctx, fullPath)
+	if err != nil {
+		return 0, false, err
+	}
+	return group.ID, false, nil
+}
+
+func (b *Bootstrapper) getGroup(ctx context.Context, fullPath string) (*groupmodels.Group, error) {
+	var group *groupmodels.Group
+	for _, groupPath := range strings.Split(fullPath, "/") {
+		parentID := uint(0)
+		if group != nil {
+			parentID = group.ID
+		}
+		groups, err := b.mgr.GroupMgr.GetByNameOrPathUnderParent(ctx, groupPath, groupPath, parentID)
+		if err != nil {
+			return nil, err
+		}
+		group = nil
+		for _, g := range groups {
+			if g.Path == groupPath {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			reason := fmt.Sprintf("group %s does not exist", fullPath)
+			return nil, perror.Wrap(herrors.NewErrNotFound(herrors.GroupInDB, reason), reason)
+		}
+	}
+	return group, nil
+}
+
+func (b *Bootstrapper) applyTemplates(ctx context.Context, templates []Template) error {
+	if len(templates) == 0 {
+		return nil
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	templatesInDB, err := b.mgr.TemplateMgr.ListTemplate(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*templatemodels.Template, len(templatesInDB))
+	for _, template := range templatesInDB {
+		existing[template.Name] = template
+	}
+
+	for _, t := range templates {
+		groupID, _, err := b.resolveGroup(ctx, t.Group)
+		if err != nil {
+			return err
+		}
+		onlyOwner := t.OnlyOwner
+		templateInDB, ok := existing[t.Name]
+		if !ok {
+			if !b.dryRun {
+				template, err := b.mgr.TemplateMgr.Create(ctx, &templatemodels.Template{
+					Name:        t.Name,
+					ChartName:   t.Name,
+					Description: t.Description,
+					Repository:  t.Repository,
+					GroupID:     groupID,
+					OnlyOwner:   &onlyOwner,
+					WithoutCI:   true,
+					Type:        t.Type,
+				})
+				if err != nil {
+					return err
+				}
+				if _, err := b.mgr.MemberMgr.Create(ctx, &membermodels.Member{
+					ResourceType: membermodels.TypeTemplate,
+					ResourceID:   template.ID,
+					Role:         role.Owner,
+					MemberType:   membermodels.MemberUser,
+					MemberNameID: user.GetID(),
+					GrantedBy:    user.GetID(),
+				}); err != nil {
+					return err
+				}
+			}
+			b.report("template", t.Name, Created)
+			continue
+		}
+
+		if templateInDB.GroupID != groupID {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"template %s exists in another group, which can not be changed by bootstrap", t.Name)
+		}
+		if templateInDB.Description == t.Description && templateInDB.Repository == t.Repository &&
+			templateInDB.Type == t.Type && templateInDB.OnlyOwner != nil && *templateInDB.OnlyOwner == onlyOwner {
+			b.report("template", t.Name, Unchanged)
+			continue
+		}
+		if !b.dryRun {
+			if err := b.mgr.TemplateMgr.UpdateByID(ctx, templateInDB.ID, &templatemodels.Template{
+				Description: t.Description,
+				Repository:  t.Repository,
+				OnlyOwner:   &onlyOwner,
+				Type:        t.Type,
+				UpdatedBy:   user.GetID(),
+			}); err != nil {
+				return err
+			}
+		}
+		b.report("template", t.Name, Updated)
+	}
+	return nil
+}
+
+func (b *Bootstrapper) applyOauthApps(ctx context.Context, apps []OauthApp) error {
+	for _, a := range apps {
+		name := fmt.Sprintf("%s/%s", a.Group, a.Name)
+		groupID, planned, err := b.resolveGroup(ctx, a.Group)
+		if err != nil {
+			return err
+		}
+		var appInDB *oauthmodels.OauthApp
+		if !planned {
+			appsInDB, err := b.oauthMgr.ListOauthApp(ctx, oauthmodels.GroupOwnerType, groupID)
+			if err != nil {
+				return err
+			}
+			for i := range appsInDB {
+				if appsInDB[i].Name == a.Name {
+					appInDB = &appsInDB[i]
+					break
+				}
+			}
+		}
+
+		if appInDB == nil {
+			appType := oauthmodels.HorizonOAuthAPP
+			if a.Type == OauthAppTypeDirect {
+				appType = oauthmodels.DirectOAuthAPP
+			}
+			if !b.dryRun {
+				app, err := b.oauthMgr.CreateOauthApp(ctx, &oauthmanager.CreateOAuthAppReq{
+					Name:        a.Name,
+					RedirectURI: a.RedirectURL,
+					HomeURL:     a.HomeURL,
+					Desc:        a.Description,
+					OwnerType:   oauthmodels.GroupOwnerType,
+					OwnerID:     groupID,
+					APPType:     appType,
+				})
+				if err != nil {
+					return err
+				}
+				// secrets are not managed by bootstrap, they are created by the owners with the client id
+				name = fmt.Sprintf("%s(client id: %s)", name, app.ClientID)
+			}
+			b.report("oauth app", name, Created)
+			continue
+		}
+
+		if appInDB.RedirectURL == a.RedirectURL && appInDB.HomeURL == a.HomeURL && appInDB.Desc == a.Description {
+			b.report("oauth app", name, Unchanged)
+			continue
+		}
+		if !b.dryRun {
+			if _, err := b.oauthMgr.UpdateOauthApp(ctx, appInDB.ClientID, oauthmanager.UpdateOauthAppReq{
+				Name:        a.Name,
+				HomeURL:     a.HomeURL,
+				RedirectURI: a.RedirectURL,
+				Desc:        a.Description,
+			}); err != nil {
+				return err
+			}
+		}
+		b.report("oauth app", name, Updated)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
+	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	oauthmodels "github.com/horizoncd/horizon/pkg/oauth/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+const _spec = `
+regions:
+- name: hz
+  displayName: HangZhou
+  server: https://hz.example.com
+  certificate: ${BOOTSTRAP_TEST_CERT}
+  ingressDomain: hz.example.com
+  registry: harbor
+environments:
+- name: test
+  displayName: Test
+  regions: [hz]
+  defaultRegion: hz
+groups:
+- fullPath: platform/infra
+  description: infra team
+- fullPath: platform
+templates:
+- name: javaapp
+  repository: https://charts.example.com
+  group: platform
+oauthApps:
+- name: portal
+  group: platform/infra
+  redirectURL: https://portal.example.com/callback
+  homeURL: https://portal.example.com
+`
+
+func TestLoadSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "platform.yaml"), []byte(_spec), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "more.yml"),
+		[]byte("environments:\n- name: online\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a spec"), 0644))
+
+	_, err = LoadSpec(dir)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.Setenv("BOOTSTRAP_TEST_CERT", "kubeconfig"))
+	defer os.Unsetenv("BOOTSTRAP_TEST_CERT")
+	spec, err := LoadSpec(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "kubeconfig", spec.Regions[0].Certificate)
+	assert.Equal(t, 2, len(spec.Environments))
+	assert.Equal(t, 2, len(spec.Groups))
+
+	assert.Nil(t, spec.Validate())
+	assert.Equal(t, "platform", spec.Groups[0].FullPath)
+	assert.Equal(t, "infra", spec.Groups[1].Name)
+	assert.Equal(t, _defaultVisibilityLevel, spec.Groups[1].VisibilityLevel)
+	assert.Equal(t, templatemodels.TemplateTypeWorkload, spec.Templates[0].Type)
+	assert.Equal(t, OauthAppTypeHorizon, spec.OauthApps[0].Type)
+}
+
+func TestValidate(t *testing.T) {
+	spec := &Spec{
+		Regions:      []Region{{Name: "hz", CDType: "argo"}},
+		Environments: []Environment{{Name: "test", Regions: []string{"hz"}, DefaultRegion: "js"}, {Name: "test"}},
+		OauthApps:    []OauthApp{{Name: "portal", Type: "unknown"}},
+	}
+	err := spec.Validate()
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	for _, msg := range []string{
+		"regions[0]: registry is required",
+		"regions[0]: cdType argo is not supported",
+		"environments[0]: default region js is not one of its regions",
+		"environment test is declared more than once",
+		"oauthApps[0]: group is required",
+		"oauthApps[0]: redirectURL is required",
+		"oauthApps[0]: type unknown is not one of horizon and direct",
+	} {
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestApply(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&registrymodels.Registry{}, &regionmodels.Region{}, &envmodels.Environment{},
+		&envregionmodels.EnvironmentRegion{}, &groupmodels.Group{}, &membermodels.Member{},
+		&applicationmodels.Application{}, &templatemodels.Template{}, &oauthmodels.OauthApp{},
+		&oauthmodels.OauthClientSecret{}, &tokenmodels.Token{}))
+	callbacks.RegisterCustomCallbacks(db)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "admin",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+	oauthMgr := oauthmanager.NewManager(oauthdao.NewDAO(db), tokenstore.NewStore(db),
+		generator.NewAuthorizeGenerator(), time.Minute, time.Hour, time.Hour)
+	_, err := mgr.RegistryMgr.Create(ctx, &registrymodels.Registry{Name: "harbor"})
+	assert.Nil(t, err)
+
+	newSpec := func() *Spec {
+		return &Spec{
+			Regions: []Region{{Name: "hz", DisplayName: "HangZhou", Registry: "harbor"}},
+			Environments: []Environment{
+				{Name: "test", DisplayName: "Test", Regions: []string{"hz"}, DefaultRegion: "hz"},
+			},
+			Groups:    []Group{{FullPath: "platform/infra"}, {FullPath: "platform"}},
+			Templates: []Template{{Name: "javaapp", Repository: "https://charts.example.com", Group: "platform"}},
+			OauthApps: []OauthApp{{Name: "portal", Group: "platform/infra", RedirectURL: "https://portal.com"}},
+		}
+	}
+	apply := func(spec *Spec, dryRun bool) string {
+		out := &bytes.Buffer{}
+		assert.Nil(t, New(mgr, oauthMgr, out, dryRun).Apply(ctx, spec))
+		return out.String()
+	}
+
+	// nothing is changed in dry run
+	out := apply(newSpec(), true)
+	assert.Equal(t, 7, strings.Count(out, "created (dry run)"))
+	regions, err := mgr.RegionMgr.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(regions))
+
+	out = apply(newSpec(), false)
+	assert.Equal(t, 7, strings.Count(out, string(Created)))
+	region, err := mgr.RegionMgr.GetRegionByName(ctx, "hz")
+	assert.Nil(t, err)
+	assert.Equal(t, "HangZhou", region.DisplayName)
+	envRegion, err := mgr.EnvRegionMgr.GetDefaultRegionByEnvironment(ctx, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "hz", envRegion.RegionName)
+	b := New(mgr, oauthMgr, ioutil.Discard, false)
+	platform, err := b.getGroup(ctx, "platform")
+	assert.Nil(t, err)
+	infra, err := b.getGroup(ctx, "platform/infra")
+	assert.Nil(t, err)
+	assert.Equal(t, platform.ID, infra.ParentID)
+	template, err := mgr.TemplateMgr.GetByName(ctx, "javaapp")
+	assert.Nil(t, err)
+	assert.Equal(t, platform.ID, template.GroupID)
+	apps, err := oauthMgr.ListOauthApp(ctx, oauthmodels.GroupOwnerType, infra.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(apps))
+
+	// applying the same spec again changes nothing
+	out = apply(newSpec(), false)
+	assert.Equal(t, 7, strings.Count(out, string(Unchanged)))
+
+	spec := newSpec()
+	spec.Regions[0].DisplayName = "HZ"
+	spec.OauthApps[0].RedirectURL = "https://portal.com/callback"
+	out = apply(spec, false)
+	assert.Equal(t, 2, strings.Count(out, string(Updated)))
+	region, err = mgr.RegionMgr.GetRegionByName(ctx, "hz")
+	assert.Nil(t, err)
+	assert.Equal(t, "HZ", region.DisplayName)
+	app, err := oauthMgr.GetOAuthApp(ctx, apps[0].ClientID)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://portal.com/callback", app.RedirectURL)
+
+	// references to resources which do not exist are rejected
+	spec = newSpec()
+	spec.Regions[0].Registry = "not-exist"
+	err = New(mgr, oauthMgr, ioutil.Discard, false).Apply(ctx, spec)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -0,0 +1,259 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/config"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+)
+
+const (
+	OauthAppTypeHorizon = "horizon"
+	OauthAppTypeDirect  = "direct"
+
+	_defaultVisibilityLevel = "private"
+)
+
+// Spec declares the platform resources to be bootstrapped, resources are identified by their names,
+// groups by their full paths and oauth apps by their names under the owner groups
+type Spec struct {
+	Regions      []Region      `yaml:"regions"`
+	Environments []Environment `yaml:"environments"`
+	Groups       []Group       `yaml:"groups"`
+	Templates    []Template    `yaml:"templates"`
+	OauthApps    []OauthApp    `yaml:"oauthApps"`
+}
+
+type Region struct {
+	Name          string `yaml:"name"`
+	DisplayName   string `yaml:"displayName"`
+	Server        string `yaml:"server"`
+	Certificate   string `yaml:"certificate"`
+	IngressDomain string `yaml:"ingressDomain"`
+	PrometheusURL string `yaml:"prometheusURL"`
+	// Registry is the name of the image registry of the region
+	Registry string `yaml:"registry"`
+	// CDType is the cd system syncing clusters of the region, argocd or flux, argocd if empty
+	CDType   string `yaml:"cdType"`
+	Disabled bool   `yaml:"disabled"`
+}
+
+type Environment struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"displayName"`
+	// Regions are bound to the environment, bindings not declared are kept
+	Regions       []string `yaml:"regions"`
+	DefaultRegion string   `yaml:"defaultRegion"`
+}
+
+type Group struct {
+	// FullPath is the paths of the group and its ancestors joined by "/", e.g. platform/infra
+	FullPath        string `yaml:"fullPath"`
+	Name            string `yaml:"name"`
+	Description     string `yaml:"description"`
+	VisibilityLevel string `yaml:"visibilityLevel"`
+}
+
+type Template struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Repository  string `yaml:"repository"`
+	// Group is the full path of the group owning the template, the template is public if it's empty
+	Group     string `yaml:"group"`
+	OnlyOwner bool   `yaml:"onlyOwner"`
+	Type      string `yaml:"type"`
+}
+
+type OauthApp struct {
+	Name string `yaml:"name"`
+	// Group is the full path of the group owning the app
+	Group       string `yaml:"group"`
+	RedirectURL string `yaml:"redirectURL"`
+	HomeURL     string `yaml:"homeURL"`
+	Description string `yaml:"description"`
+	// Type is either horizon or direct, horizon by default
+	Type string `yaml:"type"`
+}
+
+// LoadSpec loads the spec from yaml files, a directory is expanded to the yaml files in it.
+// The specs of multiple files are merged, and environment variables and secret files
+// can be referred to in the same way as the config file.
+func LoadSpec(paths ...string) (*Spec, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	spec := &Spec{}
+	for _, file := range files {
+		node, err := config.ParseYAMLFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var s Spec
+		if err := node.Decode(&s); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		spec.Regions = append(spec.Regions, s.Regions...)
+		spec.Environments = append(spec.Environments, s.Environments...)
+		spec.Groups = append(spec.Groups, s.Groups...)
+		spec.Templates = append(spec.Templates, s.Templates...)
+		spec.OauthApps = append(spec.OauthApps, s.OauthApps...)
+	}
+	return spec, nil
+}
+
+// Validate checks the spec and fills in defaults, references to resources which are neither declared
+// nor existing are reported when the spec is applied
+func (s *Spec) Validate() error {
+	var errs []string
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	checkDuplicate := func(kind string) func(key string) {
+		seen := make(map[string]bool)
+		return func(key string) {
+			if seen[key] {
+				addError("%s %s is declared more than once", kind, key)
+			}
+			seen[key] = true
+		}
+	}
+
+	checkRegion := checkDuplicate("region")
+	for i, region := range s.Regions {
+		if region.Name == "" {
+			addError("regions[%d]: name is required", i)
+		}
+		if region.Registry == "" {
+			addError("regions[%d]: registry is required", i)
+		}
+		switch region.CDType {
+		case "", regionmodels.CDTypeArgoCD, regionmodels.CDTypeFlux:
+		default:
+			addError("regions[%d]: cdType %s is not supported", i, region.CDType)
+		}
+		checkRegion(region.Name)
+	}
+
+	checkEnvironment := checkDuplicate("environment")
+	for i, environment := range s.Environments {
+		if environment.Name == "" {
+			addError("environments[%d]: name is required", i)
+		}
+		if environment.DefaultRegion != "" && !contains(environment.Regions, environment.DefaultRegion) {
+			addError("environments[%d]: default region %s is not one of its regions", i, environment.DefaultRegion)
+		}
+		checkEnvironment(environment.Name)
+	}
+
+	checkGroup := checkDuplicate("group")
+	for i := range s.Groups {
+		group := &s.Groups[i]
+		group.FullPath = strings.Trim(group.FullPath, "/")
+		if group.FullPath == "" {
+			addError("groups[%d]: fullPath is required", i)
+		}
+		if group.Name == "" {
+			group.Name = group.FullPath[strings.LastIndex(group.FullPath, "/")+1:]
+		}
+		if group.VisibilityLevel == "" {
+			group.VisibilityLevel = _defaultVisibilityLevel
+		}
+		checkGroup(group.FullPath)
+	}
+	// parents are created before their children
+	sort.SliceStable(s.Groups, func(i, j int) bool {
+		return strings.Count(s.Groups[i].FullPath, "/") < strings.Count(s.Groups[j].FullPath, "/")
+	})
+
+	checkTemplate := checkDuplicate("template")
+	for i := range s.Templates {
+		template := &s.Templates[i]
+		if template.Name == "" {
+			addError("templates[%d]: name is required", i)
+		}
+		if template.Repository == "" {
+			addError("templates[%d]: repository is required", i)
+		}
+		if template.Type == "" {
+			template.Type = templatemodels.TemplateTypeWorkload
+		}
+		template.Group = strings.Trim(template.Group, "/")
+		checkTemplate(template.Name)
+	}
+
+	checkOauthApp := checkDuplicate("oauth app")
+	for i := range s.OauthApps {
+		app := &s.OauthApps[i]
+		if app.Name == "" {
+			addError("oauthApps[%d]: name is required", i)
+		}
+		app.Group = strings.Trim(app.Group, "/")
+		if app.Group == "" {
+			addError("oauthApps[%d]: group is required", i)
+		}
+		if app.RedirectURL == "" {
+			addError("oauthApps[%d]: redirectURL is required", i)
+		}
+		if app.Type == "" {
+			app.Type = OauthAppTypeHorizon
+		} else if app.Type != OauthAppTypeHorizon && app.Type != OauthAppTypeDirect {
+			addError("oauthApps[%d]: type %s is not one of horizon and direct", i, app.Type)
+		}
+		checkOauthApp(app.Group + "/" + app.Name)
+	}
+
+	if len(errs) > 0 {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid spec:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// hpaGVRs are tried in order, autoscaling/v2 is served since kubernetes 1.23,
+// and autoscaling/v2beta2 is removed in 1.26. The specs of both versions are the same.
+var hpaGVRs = []schema.GroupVersionResource{
+	{Group: "autoscaling", Version: "v2", Resource: ResourceKindHorizontalPodAutoscaler},
+	{Group: "autoscaling", Version: "v2beta2", Resource: ResourceKindHorizontalPodAutoscaler},
+}
+
+// GetAutoscalingStatus gets the status of the HorizontalPodAutoscaler named after the cluster,
+// nil is returned if the cluster is not autoscaled
+func (e *util) GetAutoscalingStatus(ctx context.Context,
+	params *GetAutoscalingStatusParams) (_ *AutoscalingStatus, err error) {
+	const op = "cd: get autoscaling status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var status *AutoscalingStatus
+	err = e.informerFactories.GetDynamicClientSet(params.RegionEntity.ID, func(clientset dynamic.Interface) error {
+		for _, gvr := range hpaGVRs {
+			un, err := clientset.Resource(gvr).Namespace(params.Namespace).Get(ctx,
+				params.Cluster, metav1.GetOptions{})
+			if err != nil {
+				// the version may not be served, or the autoscaler does not exist
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return herrors.NewErrGetFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to get %s %s: %v", gvr.String(), params.Cluster, err))
+			}
+			status, err = autoscalingStatusOf(un)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func autoscalingStatusOf(un *unstructured.Unstructured) (*AutoscalingStatus, error) {
+	var hpa autoscalingv2beta2.HorizontalPodAutoscaler
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, &hpa); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to convert horizontal pod autoscaler %s: %v", un.GetName(), err)
+	}
+	return summarizeAutoscaler(&hpa), nil
+}
+
+// summarizeAutoscaler lists the metrics in the order of the spec, with the current values in the status
+func summarizeAutoscaler(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) *AutoscalingStatus {
+	status := &AutoscalingStatus{
+		MinReplicas:     1,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Metrics:         make([]*AutoscalingMetric, 0, len(hpa.Spec.Metrics)),
+		Conditions:      make([]*AutoscalingCondition, 0, len(hpa.Status.Conditions)),
+	}
+	if hpa.Spec.MinReplicas != nil {
+		status.MinReplicas = *hpa.Spec.MinReplicas
+	}
+	if hpa.Status.LastScaleTime != nil {
+		lastScaleTime := hpa.Status.LastScaleTime.Time
+		status.LastScaleTime = &lastScaleTime
+	}
+
+	currents := make(map[string]string, len(hpa.Status.CurrentMetrics))
+	for _, metric := range hpa.Status.CurrentMetrics {
+		name, current := metricStatusOf(metric)
+		currents[string(metric.Type)+"/"+name] = current
+	}
+	for _, metric := range hpa.Spec.Metrics {
+		name, target := metricSpecOf(metric)
+		status.Metrics = append(status.Metrics, &AutoscalingMetric{
+			Type:    string(metric.Type),
+			Name:    name,
+			Current: currents[string(metric.Type)+"/"+name],
+			Target:  target,
+		})
+	}
+	for _, condition := range hpa.Status.Conditions {
+		status.Conditions = append(status.Conditions, &AutoscalingCondition{
+			Type:    string(condition.Type),
+			Status:  string(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		})
+	}
+	return status
+}
+
+func metricSpecOf(metric autoscalingv2beta2.MetricSpec) (string, string) {
+	switch {
+	case metric.Resource != nil:
+		return string(metric.Resource.Name), metricTargetOf(metric.Resource.Target)
+	case metric.Pods != nil:
+		return metric.Pods.Metric.Name, metricTargetOf(metric.Pods.Target)
+	case metric.External != nil:
+		return metric.External.Metric.Name, metricTargetOf(metric.External.Target)
+	case metric.Object != nil:
+		return metric.Object.Metric.Name, metricTargetOf(metric.Object.Target)
+	}
+	return "", ""
+}
+
+func metricStatusOf(metric autoscalingv2beta2.MetricStatus) (string, string) {
+	switch {
+	case metric.Resource != nil:
+		return string(metric.Resource.Name), metricValueOf(metric.Resource.Current)
+	case metric.Pods != nil:
+		return metric.Pods.Metric.Name, metricValueOf(metric.Pods.Current)
+	case metric.External != nil:
+		return metric.External.Metric.Name, metricValueOf(metric.External.Current)
+	case metric.Object != nil:
+		return metric.Object.Metric.Name, metricValueOf(metric.Object.Current)
+	}
+	return "", ""
+}
+
+func metricTargetOf(target autoscalingv2beta2.MetricTarget) string {
+	return metricValueOf(autoscalingv2beta2.MetricValueStatus{
+		Value:              target.Value,
+		AverageValue:       target.AverageValue,
+		AverageUtilization: target.AverageUtilization,
+	})
+}
+
+func metricValueOf(value autoscalingv2beta2.MetricValueStatus) string {
+	switch {
+	case value.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *value.AverageUtilization)
+	case value.AverageValue != nil:
+		return value.AverageValue.String()
+	case value.Value != nil:
+		return value.Value.String()
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizeAutoscaler(t *testing.T) {
+	minReplicas, utilization, currentUtilization := int32(2), int32(80), int32(45)
+	targetQPS, currentQPS := resource.MustParse("100"), resource.MustParse("52500m")
+	scaledAt := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			MinReplicas: &minReplicas,
+			MaxReplicas: 10,
+			Metrics: []autoscalingv2beta2.MetricSpec{
+				{
+					Type: autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2beta2.MetricTarget{
+							Type: autoscalingv2beta2.UtilizationMetricType, AverageUtilization: &utilization,
+						},
+					},
+				},
+				{
+					Type: autoscalingv2beta2.PodsMetricSourceType,
+					Pods: &autoscalingv2beta2.PodsMetricSource{
+						Metric: autoscalingv2beta2.MetricIdentifier{Name: "qps"},
+						Target: autoscalingv2beta2.MetricTarget{
+							Type: autoscalingv2beta2.AverageValueMetricType, AverageValue: &targetQPS,
+						},
+					},
+				},
+			},
+		},
+		Status: autoscalingv2beta2.HorizontalPodAutoscalerStatus{
+			LastScaleTime:   &metav1.Time{Time: scaledAt},
+			CurrentReplicas: 3,
+			DesiredReplicas: 4,
+			CurrentMetrics: []autoscalingv2beta2.MetricStatus{
+				{
+					Type: autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricStatus{
+						Name:    corev1.ResourceCPU,
+						Current: autoscalingv2beta2.MetricValueStatus{AverageUtilization: &currentUtilization},
+					},
+				},
+			},
+			Conditions: []autoscalingv2beta2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2beta2.ScalingActive, Status: corev1.ConditionTrue, Reason: "ValidMetricFound"},
+			},
+		},
+	}
+
+	status := summarizeAutoscaler(hpa)
+	assert.Equal(t, int32(2), status.MinReplicas)
+	assert.Equal(t, int32(10), status.MaxReplicas)
+	assert.Equal(t, int32(3), status.CurrentReplicas)
+	assert.Equal(t, int32(4), status.DesiredReplicas)
+	assert.Equal(t, scaledAt, *status.LastScaleTime)
+	assert.Equal(t, []*AutoscalingMetric{
+		{Type: "Resource", Name: "cpu", Current: "45%", Target: "80%"},
+		// not collected yet
+		{Type: "Pods", Name: "qps", Current: "", Target: "100"},
+	}, status.Metrics)
+	assert.Equal(t, []*AutoscalingCondition{
+		{Type: "ScalingActive", Status: "True", Reason: "ValidMetricFound"},
+	}, status.Conditions)
+
+	hpa.Status.CurrentMetrics = append(hpa.Status.CurrentMetrics, autoscalingv2beta2.MetricStatus{
+		Type: autoscalingv2beta2.PodsMetricSourceType,
+		Pods: &autoscalingv2beta2.PodsMetricStatus{
+			Metric:  autoscalingv2beta2.MetricIdentifier{Name: "qps"},
+			Current: autoscalingv2beta2.MetricValueStatus{AverageValue: &currentQPS},
+		},
+	})
+	hpa.Spec.MinReplicas = nil
+	status = summarizeAutoscaler(hpa)
+	assert.Equal(t, int32(1), status.MinReplicas)
+	assert.Equal(t, "52500m", status.Metrics[1].Current)
+}
@@ -38,14 +38,10 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/kube"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
-	"github.com/horizoncd/horizon/pkg/workload"
-	"github.com/horizoncd/horizon/pkg/workload/getter"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -92,8 +88,14 @@ type CD interface {
 	DeleteCluster(ctx context.Context, params *DeleteClusterParams) error
 	GetClusterState(ctx context.Context, params *GetClusterStateV2Params) (*ClusterStateV2, error)
 	GetResourceTree(ctx context.Context, params *GetResourceTreeParams) ([]ResourceNode, error)
+	// GetManifests gets manifests of the cluster rendered at the revision of its gitops repo
+	GetManifests(ctx context.Context, params *GetManifestsParams) ([]map[string]interface{}, error)
+	// GetClusterDrift compares the workloads declared in the gitops repo at the revision with the live ones
+	GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error)
 	GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 	GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error)
+	// ListQueuedOperations lists argocd operations of the cluster in FIFO order
+	ListQueuedOperations(ctx context.Context, cluster string) []*QueuedOperation
 }
 
 type cd struct {
@@ -102,16 +104,21 @@ type cd struct {
 	factory           argocd.Factory
 	clusterGitRepo    gitrepo.ClusterGitRepo
 	targetRevision    string
+	operationQueue    *operationQueue
+	syncThrottle      *syncThrottle
 }
 
-func NewCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
-	argoCDMapper argocdconf.Mapper, targetRevision string) CD {
+// newArgoCD returns the cd syncing clusters by argoCD
+func newArgoCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
+	argoCDMapper argocdconf.Mapper, targetRevision string, syncConcurrency argocdconf.SyncConcurrency) *cd {
 	return &cd{
 		kubeClientFactory: kubeclient.Fty,
 		informerFactories: informerFactories,
 		factory:           argocd.NewFactory(argoCDMapper),
 		clusterGitRepo:    clusterGitRepo,
 		targetRevision:    targetRevision,
+		operationQueue:    newOperationQueue(),
+		syncThrottle:      newSyncThrottle(syncConcurrency),
 	}
 }
 
@@ -156,7 +163,27 @@ func (c *cd) DeployCluster(ctx context.Context, params *DeployClusterParams) (er
 		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
 	}
 
-	return argo.DeployApplication(ctx, params.Cluster, params.Revision)
+	return c.operationQueue.Do(ctx, params.Cluster, OperationSync, func() error {
+		release, err := c.syncThrottle.Acquire(ctx, params.Region, params.Cluster)
+		if err != nil {
+			return err
+		}
+		if err := argo.DeployApplication(ctx, params.Cluster, params.Revision); err != nil {
+			release()
+			return err
+		}
+		c.syncThrottle.HoldUntilSynced(argo, params.Cluster, release)
+		return nil
+	})
+}
+
+func (c *cd) ListQueuedOperations(ctx context.Context, cluster string) []*QueuedOperation {
+	operations := c.operationQueue.List(cluster)
+	if region, position := c.syncThrottle.Position(cluster); position > 0 && len(operations) > 0 {
+		operations[0].Region = region
+		operations[0].RegionPosition = position
+	}
+	return operations
 }
 
 func (c *cd) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (err error) {
@@ -186,9 +213,9 @@ func (c *cd) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (er
 	return argo.WaitApplication(ctx, params.Cluster, string(applicationCR.UID), http.StatusNotFound)
 }
 
-func (c *cd) GetResourceTree(ctx context.Context,
-	params *GetResourceTreeParams) ([]ResourceNode, error) {
-	const op = "cd: get cluster status"
+func (c *cd) GetManifests(ctx context.Context,
+	params *GetManifestsParams) ([]map[string]interface{}, error) {
+	const op = "cd: get manifests"
 	defer wlog.Start(ctx, op).StopPrint()
 
 	argo, err := c.factory.GetArgoCD(params.Environment)
@@ -196,49 +223,119 @@ func (c *cd) GetResourceTree(ctx context.Context,
 		return nil, err
 	}
 
-	// get resourceTreeInArgo
-	resourceTreeInArgo, err := argo.GetApplicationTree(ctx, params.Cluster)
+	manifestsInArgo, err := argo.GetApplicationManifests(ctx, params.Cluster, params.Revision)
 	if err != nil {
 		return nil, err
 	}
 
-	resourceTree := make([]ResourceNode, 0, len(resourceTreeInArgo.Nodes))
-	pd, err := workload.GetAbility(GKPod)
+	manifests := make([]map[string]interface{}, 0, len(manifestsInArgo))
+	for _, m := range manifestsInArgo {
+		var manifest map[string]interface{}
+		if err := json.Unmarshal([]byte(m), &manifest); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to unmarshal manifest of cluster %s: %v", params.Cluster, err)
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+func (c *cd) GetClusterDrift(ctx context.Context,
+	params *GetClusterDriftParams) (*ClusterDrift, error) {
+	const op = "cd: get cluster drift"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	argo, err := c.factory.GetArgoCD(params.Environment)
 	if err != nil {
 		return nil, err
 	}
-	gt := getter.New(pd)
-	for _, node := range resourceTreeInArgo.Nodes {
-		n := ResourceNode{ResourceNode: node}
-		if n.Kind == "Pod" {
-			var podDetail corev1.Pod
-			err = c.informerFactories.GetDynamicFactory(params.RegionEntity.ID,
-				func(factory dynamicinformer.DynamicSharedInformerFactory) error {
-					pods, err := gt.ListPods(&node, factory)
-					if err != nil {
-						return err
-					}
-					podDetail = pods[0]
-					return nil
-				})
-			if err != nil {
-				log.Errorf(ctx, "failed to get pod detail: %v", err)
-				continue
+
+	argoApp, err := argo.GetApplication(ctx, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := c.GetManifests(ctx, &GetManifestsParams{
+		Environment: params.Environment,
+		Cluster:     params.Cluster,
+		Revision:    params.Revision,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &ClusterDrift{
+		Revision:     params.Revision,
+		LiveRevision: argoApp.Status.Sync.Revision,
+		Workloads:    make([]*WorkloadDrift, 0),
+	}
+	desired := indexDesiredWorkloads(manifests)
+	for _, key := range desired.keys {
+		manifest := desired.manifests[key]
+		group, version := splitAPIVersion(nestedString(manifest, "apiVersion"))
+		namespace := nestedString(manifest, "metadata", "namespace")
+		if namespace == "" {
+			namespace = argoApp.Spec.Destination.Namespace
+		}
+		var live map[string]interface{}
+		if err := argo.GetApplicationResource(ctx, params.Cluster, argocd.ResourceParams{
+			Group:        group,
+			Version:      version,
+			Kind:         key.kind,
+			Namespace:    namespace,
+			ResourceName: key.name,
+		}, &live); err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
 			}
-			t := Compact(podDetail)
-			n.PodDetail = &t
+			live = nil
+		}
+		drift.Workloads = append(drift.Workloads,
+			diffWorkload(key.kind, key.name, manifest, live, !desired.autoscaled[key]))
+	}
+
+	// workloads removed from the gitops repo keep running until they are pruned by the next deploy
+	for _, resource := range argoApp.Status.Resources {
+		if group, ok := driftWorkloadGroups[resource.Kind]; !ok || group != resource.Group ||
+			!resource.RequiresPruning {
+			continue
 		}
-		resourceTree = append(resourceTree, n)
+		drift.Workloads = append(drift.Workloads, diffWorkload(resource.Kind, resource.Name, nil, nil, false))
 	}
 
-	return resourceTree, nil
+	for _, w := range drift.Workloads {
+		if w.State != DriftStateUnchanged {
+			drift.Drifted = true
+			break
+		}
+	}
+	return drift, nil
+}
+
+func (c *cd) GetResourceTree(ctx context.Context,
+	params *GetResourceTreeParams) ([]ResourceNode, error) {
+	const op = "cd: get cluster status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	argo, err := c.factory.GetArgoCD(params.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	// get resourceTreeInArgo
+	resourceTreeInArgo, err := argo.GetApplicationTree(ctx, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourceNodesOfTree(ctx, c.informerFactories, params.RegionEntity.ID, resourceTreeInArgo)
 }
 
 func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
 	const op = "cd: get step"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByRegion(params.RegionEntity.Region)
 	if err != nil {
 		return nil, err
 	}
@@ -254,48 +351,7 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 		return nil, err
 	}
 
-	ifContinue := true
-	step := (*workload.Step)(nil)
-	c.traverseResourceTree(resourceTreeInArgo, func(node *ResourceTreeNode) bool {
-		if !ifContinue {
-			return ifContinue
-		}
-		workload.LoopAbilities(func(workload workload.Workload) bool {
-			if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
-				return true
-			}
-
-			gt := getter.New(workload)
-			step, err = gt.GetSteps(node.ResourceNode, kubeClient)
-			if err != nil {
-				return true
-			}
-
-			ifContinue = false
-			return false
-		})
-		return ifContinue
-	})
-
-	// step
-	if step == nil {
-		return &Step{
-			Index:        0,
-			Total:        0,
-			Replicas:     []int{},
-			ManualPaused: false,
-			AutoPromote:  false,
-		}, nil
-	}
-
-	return &Step{
-		Index:        step.Index,
-		Total:        step.Total,
-		Replicas:     step.Replicas,
-		ManualPaused: step.ManualPaused,
-		AutoPromote:  step.AutoPromote,
-		Extra:        step.Extra,
-	}, nil
+	return stepOfTree(resourceTreeInArgo, kubeClient), nil
 }
 
 // GetClusterState fetches status of cluster
@@ -346,7 +402,7 @@ func (c *cd) GetClusterState(ctx context.Context,
 		return status, nil
 	}
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByRegion(params.RegionEntity.Region)
 	if err != nil {
 		return nil, err
 	}
@@ -357,32 +413,8 @@ func (c *cd) GetClusterState(ctx context.Context,
 		return nil, err
 	}
 
-	if argoApp.Status.Health.Status == health.HealthStatusHealthy {
-		isHealthy := true
-		c.traverseResourceTree(resourceTreeInArgo, func(node *ResourceTreeNode) bool {
-			if !isHealthy {
-				return false
-			}
-			workload.LoopAbilities(func(workload workload.Workload) bool {
-				if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
-					return true
-				}
-				gt := getter.New(workload)
-				nodeHealthy, err := gt.IsHealthy(node.ResourceNode, kubeClient)
-				if err != nil {
-					return true
-				}
-				log.Debugf(ctx, "[cd get status v2] node(%v) kind(%v) isHealthy(%v)", node.Name, node.Kind, nodeHealthy)
-				isHealthy = isHealthy && nodeHealthy
-				return isHealthy
-			})
-			// break if isHealthy is false
-			return isHealthy
-		})
-
-		if !isHealthy {
-			status.Status = string(health.HealthStatusProgressing)
-		}
+	if !treeHealthy(ctx, resourceTreeInArgo, kubeClient) {
+		status.Status = string(health.HealthStatusProgressing)
 	}
 	return status, nil
 }
@@ -398,7 +430,7 @@ func (c *cd) GetClusterStateV1(ctx context.Context,
 		return nil, err
 	}
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByRegion(params.RegionEntity.Region)
 	if err != nil {
 		return nil, err
 	}
@@ -563,7 +595,7 @@ func (c *cd) GetPodEvents(ctx context.Context,
 	const op = "cd: get cluster pod events"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByRegion(params.RegionEntity.Region)
 	if err != nil {
 		return nil, err
 	}
@@ -577,32 +609,7 @@ func (c *cd) GetPodEvents(ctx context.Context,
 		return nil, err
 	}
 
-	for i := range resourceTree {
-		pod := resourceTree[i].PodDetail
-		if pod != nil && pod.Metadata.Namespace == params.Namespace && pod.Metadata.Name == params.Pod {
-			k8sEvents, err := kube.GetPodEvents(ctx, kubeClient.Basic, params.Namespace, params.Pod)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, event := range k8sEvents {
-				eventTimeStamp := metav1.Time{Time: event.EventTime.Time}
-				if eventTimeStamp.IsZero() {
-					eventTimeStamp = event.FirstTimestamp
-				}
-				events = append(events, Event{
-					Type:           event.Type,
-					Reason:         event.Reason,
-					Message:        event.Message,
-					Count:          event.Count,
-					EventTimestamp: eventTimeStamp,
-				})
-			}
-			return events, nil
-		}
-	}
-
-	return nil, herrors.NewErrNotFound(herrors.PodsInK8S, "pod does not exist")
+	return podEventsOfTree(ctx, kubeClient, resourceTree, params.Namespace, params.Pod)
 }
 
 // Deprecated
@@ -0,0 +1,235 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// driftWorkloadGroups are the api groups of the kinds of workloads compared for drift
+var driftWorkloadGroups = map[string]string{
+	"Deployment":  "apps",
+	"StatefulSet": "apps",
+	"DaemonSet":   "apps",
+	"Rollout":     "argoproj.io",
+}
+
+type workloadKey struct {
+	kind string
+	name string
+}
+
+// desiredWorkloads is the workloads declared in the manifests in order
+type desiredWorkloads struct {
+	keys      []workloadKey
+	manifests map[workloadKey]map[string]interface{}
+	// autoscaled workloads are scaled by hpa, the replicas declared are not compared
+	autoscaled map[workloadKey]bool
+}
+
+func indexDesiredWorkloads(manifests []map[string]interface{}) *desiredWorkloads {
+	desired := &desiredWorkloads{
+		manifests:  map[workloadKey]map[string]interface{}{},
+		autoscaled: map[workloadKey]bool{},
+	}
+	for _, manifest := range manifests {
+		kind := nestedString(manifest, "kind")
+		if kind == "HorizontalPodAutoscaler" {
+			desired.autoscaled[workloadKey{
+				kind: nestedString(manifest, "spec", "scaleTargetRef", "kind"),
+				name: nestedString(manifest, "spec", "scaleTargetRef", "name"),
+			}] = true
+			continue
+		}
+		group, ok := driftWorkloadGroups[kind]
+		if g, _ := splitAPIVersion(nestedString(manifest, "apiVersion")); !ok || g != group {
+			continue
+		}
+		key := workloadKey{kind: kind, name: nestedString(manifest, "metadata", "name")}
+		if _, ok := desired.manifests[key]; !ok {
+			desired.keys = append(desired.keys, key)
+		}
+		desired.manifests[key] = manifest
+	}
+	return desired
+}
+
+// diffWorkload compares the desired manifest of a workload with the live one, either of them may be nil
+func diffWorkload(kind, name string, desired, live map[string]interface{}, compareReplicas bool) *WorkloadDrift {
+	drift := &WorkloadDrift{Kind: kind, Name: name, State: DriftStateUnchanged}
+	switch {
+	case desired == nil:
+		drift.State = DriftStateExtraneous
+		return drift
+	case live == nil:
+		drift.State = DriftStateMissing
+		return drift
+	}
+
+	if desiredReplicas, ok := nestedField(desired, "spec", "replicas"); ok && compareReplicas {
+		liveReplicas, _ := nestedField(live, "spec", "replicas")
+		if formatValue(desiredReplicas) != formatValue(liveReplicas) {
+			drift.Replicas = &ValueDrift{Desired: formatValue(desiredReplicas), Live: formatValue(liveReplicas)}
+		}
+	}
+
+	desiredContainers, liveContainers := podContainers(desired), podContainers(live)
+	liveByName := make(map[string]map[string]interface{}, len(liveContainers))
+	for _, container := range liveContainers {
+		liveByName[nestedString(container, "name")] = container
+	}
+	declared := make(map[string]bool, len(desiredContainers))
+	for _, container := range desiredContainers {
+		containerName := nestedString(container, "name")
+		declared[containerName] = true
+		if c := diffContainer(containerName, container, liveByName[containerName]); c != nil {
+			drift.Containers = append(drift.Containers, c)
+		}
+	}
+	for _, container := range liveContainers {
+		if containerName := nestedString(container, "name"); !declared[containerName] {
+			drift.Containers = append(drift.Containers, diffContainer(containerName, nil, container))
+		}
+	}
+
+	if drift.Replicas != nil || len(drift.Containers) > 0 {
+		drift.State = DriftStateModified
+	}
+	return drift
+}
+
+// diffContainer returns nil if the container is unchanged
+func diffContainer(name string, desired, live map[string]interface{}) *ContainerDrift {
+	drift := &ContainerDrift{Name: name, State: DriftStateModified}
+	switch {
+	case desired == nil:
+		drift.State = DriftStateExtraneous
+		drift.Image = &ValueDrift{Live: nestedString(live, "image")}
+		return drift
+	case live == nil:
+		drift.State = DriftStateMissing
+		drift.Image = &ValueDrift{Desired: nestedString(desired, "image")}
+		return drift
+	}
+
+	if desiredImage, liveImage := nestedString(desired, "image"), nestedString(live, "image"); desiredImage != liveImage {
+		drift.Image = &ValueDrift{Desired: desiredImage, Live: liveImage}
+	}
+
+	desiredEnv, desiredNames := containerEnv(desired)
+	liveEnv, liveNames := containerEnv(live)
+	for _, envName := range desiredNames {
+		if liveValue, ok := liveEnv[envName]; !ok || liveValue != desiredEnv[envName] {
+			drift.Env = append(drift.Env, &EnvDrift{
+				Name:       envName,
+				ValueDrift: ValueDrift{Desired: desiredEnv[envName], Live: liveValue},
+			})
+		}
+	}
+	for _, envName := range liveNames {
+		if _, ok := desiredEnv[envName]; !ok {
+			drift.Env = append(drift.Env, &EnvDrift{Name: envName, ValueDrift: ValueDrift{Live: liveEnv[envName]}})
+		}
+	}
+
+	if drift.Image == nil && len(drift.Env) == 0 {
+		return nil
+	}
+	return drift
+}
+
+// podContainers returns the init containers and containers in the pod template of a workload
+func podContainers(workload map[string]interface{}) []map[string]interface{} {
+	containers := make([]map[string]interface{}, 0)
+	for _, field := range []string{"initContainers", "containers"} {
+		list, _ := nestedField(workload, "spec", "template", "spec", field)
+		items, _ := list.([]interface{})
+		for _, item := range items {
+			if container, ok := item.(map[string]interface{}); ok {
+				containers = append(containers, container)
+			}
+		}
+	}
+	return containers
+}
+
+// containerEnv returns the env vars of a container and their names in order,
+// the ones referring to other resources are formatted as their references
+func containerEnv(container map[string]interface{}) (map[string]string, []string) {
+	env := map[string]string{}
+	names := make([]string, 0)
+	list, _ := nestedField(container, "env")
+	items, _ := list.([]interface{})
+	for _, item := range items {
+		envVar, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := nestedString(envVar, "name")
+		if _, ok := env[name]; !ok {
+			names = append(names, name)
+		}
+		if valueFrom, ok := envVar["valueFrom"]; ok {
+			content, _ := json.Marshal(valueFrom)
+			env[name] = "valueFrom: " + string(content)
+			continue
+		}
+		env[name] = nestedString(envVar, "value")
+	}
+	return env, names
+}
+
+func nestedField(obj map[string]interface{}, fields ...string) (interface{}, bool) {
+	var value interface{} = obj
+	for _, field := range fields {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[field]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	value, _ := nestedField(obj, fields...)
+	s, _ := value.(string)
+	return s
+}
+
+// formatValue formats a value decoded from json, numbers are formatted as integers if they are
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprint(int64(v))
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// splitAPIVersion splits an apiVersion into its group and version, such as apps and v1 of apps/v1
+func splitAPIVersion(apiVersion string) (string, string) {
+	if i := strings.Index(apiVersion, "/"); i >= 0 {
+		return apiVersion[:i], apiVersion[i+1:]
+	}
+	return "", apiVersion
+}
@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeManifest(t *testing.T, manifest string) map[string]interface{} {
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(manifest), &m))
+	return m
+}
+
+func TestIndexDesiredWorkloads(t *testing.T) {
+	desired := indexDesiredWorkloads([]map[string]interface{}{
+		decodeManifest(t, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"}}`),
+		decodeManifest(t, `{"apiVersion":"v1","kind":"Service","metadata":{"name":"web"}}`),
+		decodeManifest(t, `{"apiVersion":"argoproj.io/v1alpha1","kind":"Rollout","metadata":{"name":"canary"}}`),
+		decodeManifest(t, `{"apiVersion":"autoscaling/v1","kind":"HorizontalPodAutoscaler",
+			"spec":{"scaleTargetRef":{"kind":"Rollout","name":"canary"}}}`),
+	})
+	assert.Equal(t, []workloadKey{{kind: "Deployment", name: "web"}, {kind: "Rollout", name: "canary"}}, desired.keys)
+	assert.False(t, desired.autoscaled[workloadKey{kind: "Deployment", name: "web"}])
+	assert.True(t, desired.autoscaled[workloadKey{kind: "Rollout", name: "canary"}])
+}
+
+func TestDiffWorkload(t *testing.T) {
+	desired := decodeManifest(t, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},
+		"spec":{"replicas":3,"template":{"spec":{"containers":[
+			{"name":"app","image":"app:v2","env":[
+				{"name":"A","value":"1"},
+				{"name":"B","valueFrom":{"secretKeyRef":{"name":"s","key":"k"}}},
+				{"name":"C","value":"3"}]},
+			{"name":"sidecar","image":"sidecar:v1"}]}}}}`)
+
+	drift := diffWorkload("Deployment", "web", desired, desired, true)
+	assert.Equal(t, DriftStateUnchanged, drift.State)
+	assert.Nil(t, drift.Replicas)
+	assert.Empty(t, drift.Containers)
+
+	live := decodeManifest(t, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},
+		"spec":{"replicas":2,"template":{"spec":{"containers":[
+			{"name":"app","image":"app:v1","env":[
+				{"name":"A","value":"1"},
+				{"name":"B","valueFrom":{"secretKeyRef":{"name":"s","key":"k"}}},
+				{"name":"C","value":"2"},
+				{"name":"D","value":"4"}]},
+			{"name":"debug","image":"debug:v1"}]}}}}`)
+	drift = diffWorkload("Deployment", "web", desired, live, true)
+	assert.Equal(t, DriftStateModified, drift.State)
+	assert.Equal(t, &ValueDrift{Desired: "3", Live: "2"}, drift.Replicas)
+	assert.Equal(t, []*ContainerDrift{
+		{
+			Name:  "app",
+			State: DriftStateModified,
+			Image: &ValueDrift{Desired: "app:v2", Live: "app:v1"},
+			Env: []*EnvDrift{
+				{Name: "C", ValueDrift: ValueDrift{Desired: "3", Live: "2"}},
+				{Name: "D", ValueDrift: ValueDrift{Live: "4"}},
+			},
+		},
+		{Name: "sidecar", State: DriftStateMissing, Image: &ValueDrift{Desired: "sidecar:v1"}},
+		{Name: "debug", State: DriftStateExtraneous, Image: &ValueDrift{Live: "debug:v1"}},
+	}, drift.Containers)
+
+	// replicas of workloads scaled by hpa are not compared
+	drift = diffWorkload("Deployment", "web", desired, live, false)
+	assert.Nil(t, drift.Replicas)
+
+	assert.Equal(t, DriftStateMissing, diffWorkload("Deployment", "web", desired, nil, true).State)
+	assert.Equal(t, DriftStateExtraneous, diffWorkload("Deployment", "web", nil, nil, false).State)
+}
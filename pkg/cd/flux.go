@@ -0,0 +1,553 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
+	"github.com/horizoncd/horizon/pkg/config/fluxcd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	_fluxDefaultNamespace = "flux-system"
+	_fluxDefaultInterval  = 5 * time.Minute
+	_fluxDeleteTimeout    = 5 * time.Minute
+	_fluxDeleteInterval   = 2 * time.Second
+
+	// _fluxReconcileAnnotation asks flux to reconcile the object right away
+	_fluxReconcileAnnotation = "reconcile.fluxcd.io/requestedAt"
+)
+
+var (
+	gvrFluxGitRepository = schema.GroupVersionResource{
+		Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Resource: "gitrepositories",
+	}
+	gvrFluxHelmRelease = schema.GroupVersionResource{
+		Group: "helm.toolkit.fluxcd.io", Version: "v2beta1", Resource: "helmreleases",
+	}
+
+	// fluxTreeGVRs are the kinds of resources making up the resource trees of clusters synced by flux
+	fluxTreeGVRs = []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"},
+		{Group: "apps", Version: "v1", Resource: "replicasets"},
+		{Group: "", Version: "v1", Resource: "pods"},
+		{Group: "", Version: "v1", Resource: "services"},
+	}
+)
+
+// fluxCD syncs each cluster by a GitRepository source and a HelmRelease named after the cluster,
+// which are created in the flux namespace of its region
+type fluxCD struct {
+	regionMgr         regionmanager.Manager
+	kubeClientFactory kubeclient.Factory
+	informerFactories *regioninformers.RegionInformers
+	clusterGitRepo    gitrepo.ClusterGitRepo
+	config            fluxcd.Config
+	targetRevision    string
+	operationQueue    *operationQueue
+}
+
+var _ CD = (*fluxCD)(nil)
+
+func newFluxCD(regionMgr regionmanager.Manager, informerFactories *regioninformers.RegionInformers,
+	clusterGitRepo gitrepo.ClusterGitRepo, config fluxcd.Config, targetRevision string) *fluxCD {
+	if config.Namespace == "" {
+		config.Namespace = _fluxDefaultNamespace
+	}
+	if config.Interval <= 0 {
+		config.Interval = _fluxDefaultInterval
+	}
+	return &fluxCD{
+		regionMgr:         regionMgr,
+		kubeClientFactory: kubeclient.Fty,
+		informerFactories: informerFactories,
+		clusterGitRepo:    clusterGitRepo,
+		config:            config,
+		targetRevision:    targetRevision,
+		operationQueue:    newOperationQueue(),
+	}
+}
+
+func (c *fluxCD) CreateCluster(ctx context.Context, params *CreateClusterParams) error {
+	const op = "flux cd: create cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.informerFactories.GetDynamicClientSet(params.RegionEntity.ID, func(client dynamic.Interface) error {
+		// if helm release exists, return, else create it
+		_, err := client.Resource(gvrFluxHelmRelease).Namespace(c.config.Namespace).
+			Get(ctx, params.Cluster, metav1.GetOptions{})
+		if err == nil {
+			return nil
+		}
+		if !k8serrors.IsNotFound(err) {
+			return herrors.NewErrGetFailed(herrors.ReleaseInFlux,
+				fmt.Sprintf("failed to get helm release %s: %v", params.Cluster, err))
+		}
+
+		for _, obj := range []struct {
+			gvr    schema.GroupVersionResource
+			object *unstructured.Unstructured
+		}{
+			{gvr: gvrFluxGitRepository, object: c.assembleGitRepository(params)},
+			{gvr: gvrFluxHelmRelease, object: c.assembleHelmRelease(params)},
+		} {
+			_, err := client.Resource(obj.gvr).Namespace(c.config.Namespace).
+				Create(ctx, obj.object, metav1.CreateOptions{})
+			if err != nil && !k8serrors.IsAlreadyExists(err) {
+				return herrors.NewErrCreateFailed(herrors.ReleaseInFlux,
+					fmt.Sprintf("failed to create %s %s: %v", obj.object.GetKind(), params.Cluster, err))
+			}
+		}
+		return nil
+	})
+}
+
+func (c *fluxCD) assembleGitRepository(params *CreateClusterParams) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"url":      params.GitRepoURL,
+		"interval": c.config.Interval.String(),
+		"ref": map[string]interface{}{
+			"branch": c.targetRevision,
+		},
+	}
+	if c.config.SecretRef != "" {
+		spec["secretRef"] = map[string]interface{}{"name": c.config.SecretRef}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvrFluxGitRepository.GroupVersion().String(),
+		"kind":       "GitRepository",
+		"metadata":   c.assembleMetadata(params.Cluster),
+		"spec":       spec,
+	}}
+}
+
+func (c *fluxCD) assembleHelmRelease(params *CreateClusterParams) *unstructured.Unstructured {
+	valueFiles := make([]interface{}, 0, len(params.ValueFiles))
+	for _, file := range params.ValueFiles {
+		valueFiles = append(valueFiles, file)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvrFluxHelmRelease.GroupVersion().String(),
+		"kind":       "HelmRelease",
+		"metadata":   c.assembleMetadata(params.Cluster),
+		"spec": map[string]interface{}{
+			"interval":        c.config.Interval.String(),
+			"releaseName":     params.Cluster,
+			"targetNamespace": params.Namespace,
+			"install": map[string]interface{}{
+				"createNamespace": true,
+			},
+			"chart": map[string]interface{}{
+				"spec": map[string]interface{}{
+					// the chart is at the root of the gitops repo
+					"chart":             ".",
+					"reconcileStrategy": "Revision",
+					"valuesFiles":       valueFiles,
+					"sourceRef": map[string]interface{}{
+						"kind":      "GitRepository",
+						"name":      params.Cluster,
+						"namespace": c.config.Namespace,
+					},
+				},
+			},
+		},
+	}}
+}
+
+func (c *fluxCD) assembleMetadata(cluster string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      cluster,
+		"namespace": c.config.Namespace,
+		"labels": map[string]interface{}{
+			common.ClusterClusterLabelKey: cluster,
+		},
+	}
+}
+
+func (c *fluxCD) DeployCluster(ctx context.Context, params *DeployClusterParams) error {
+	const op = "flux cd: deploy cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	region, err := c.regionMgr.GetRegionByName(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+
+	return c.operationQueue.Do(ctx, params.Cluster, OperationSync, func() error {
+		return c.informerFactories.GetDynamicClientSet(region.ID, func(client dynamic.Interface) error {
+			requestedAt := time.Now().Format(time.RFC3339Nano)
+			// pin the source to the revision, so that the release is upgraded to exactly what is deployed
+			var commit interface{}
+			if params.Revision != "" {
+				commit = params.Revision
+			}
+			if err := c.patch(ctx, client, gvrFluxGitRepository, params.Cluster, map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{_fluxReconcileAnnotation: requestedAt},
+				},
+				"spec": map[string]interface{}{
+					"ref": map[string]interface{}{"branch": c.targetRevision, "commit": commit},
+				},
+			}); err != nil {
+				return err
+			}
+			return c.patch(ctx, client, gvrFluxHelmRelease, params.Cluster, map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{_fluxReconcileAnnotation: requestedAt},
+				},
+			})
+		})
+	})
+}
+
+func (c *fluxCD) patch(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource,
+	name string, patch map[string]interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "failed to marshal patch: %v", err)
+	}
+	_, err = client.Resource(gvr).Namespace(c.config.Namespace).
+		Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return herrors.NewErrNotFound(herrors.ReleaseInFlux,
+				fmt.Sprintf("%s %s not found", gvr.Resource, name))
+		}
+		return herrors.NewErrUpdateFailed(herrors.ReleaseInFlux,
+			fmt.Sprintf("failed to patch %s %s: %v", gvr.Resource, name, err))
+	}
+	return nil
+}
+
+func (c *fluxCD) ListQueuedOperations(ctx context.Context, cluster string) []*QueuedOperation {
+	return c.operationQueue.List(cluster)
+}
+
+func (c *fluxCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) error {
+	const op = "flux cd: delete cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	region, err := c.regionMgr.GetRegionByName(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+
+	return c.informerFactories.GetDynamicClientSet(region.ID, func(client dynamic.Interface) error {
+		// 1. delete helm release, flux uninstalls it before it's gone
+		releases := client.Resource(gvrFluxHelmRelease).Namespace(c.config.Namespace)
+		if err := releases.Delete(ctx, params.Cluster, metav1.DeleteOptions{}); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return herrors.NewErrDeleteFailed(herrors.ReleaseInFlux,
+					fmt.Sprintf("failed to delete helm release %s: %v", params.Cluster, err))
+			}
+		}
+
+		// 2. wait for helm release to delete completely,
+		// otherwise it can never be uninstalled after the source is deleted
+		err := wait.PollImmediate(_fluxDeleteInterval, _fluxDeleteTimeout, func() (bool, error) {
+			_, err := releases.Get(ctx, params.Cluster, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			return herrors.NewErrDeleteFailed(herrors.ReleaseInFlux,
+				fmt.Sprintf("failed to wait for helm release %s to delete: %v", params.Cluster, err))
+		}
+
+		// 3. delete git repository
+		err = client.Resource(gvrFluxGitRepository).Namespace(c.config.Namespace).
+			Delete(ctx, params.Cluster, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return herrors.NewErrDeleteFailed(herrors.ReleaseInFlux,
+				fmt.Sprintf("failed to delete git repository %s: %v", params.Cluster, err))
+		}
+		return nil
+	})
+}
+
+// GetManifests is not supported, as flux does not render manifests on demand
+func (c *fluxCD) GetManifests(ctx context.Context,
+	params *GetManifestsParams) ([]map[string]interface{}, error) {
+	return nil, perror.Wrapf(herrors.ErrNotSupport,
+		"manifests of cluster %s can not be rendered by flux", params.Cluster)
+}
+
+// GetClusterDrift is not supported, as flux does not render manifests on demand
+func (c *fluxCD) GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error) {
+	return nil, perror.Wrapf(herrors.ErrNotSupport,
+		"drift of cluster %s can not be compared with flux", params.Cluster)
+}
+
+func (c *fluxCD) GetClusterState(ctx context.Context,
+	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	const op = "flux cd: get cluster status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var release, source *unstructured.Unstructured
+	err := c.informerFactories.GetDynamicClientSet(params.RegionEntity.ID, func(client dynamic.Interface) error {
+		var err error
+		if release, err = c.get(ctx, client, gvrFluxHelmRelease, params.Cluster); err != nil {
+			return err
+		}
+		source, err = c.get(ctx, client, gvrFluxGitRepository, params.Cluster)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ClusterStateV2{
+		Status: string(fluxReleaseHealth(release)),
+	}
+	if status.Status != string(health.HealthStatusHealthy) {
+		return status, nil
+	}
+
+	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, params.Application, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	revision, _, _ := unstructured.NestedString(source.Object, "status", "artifact", "revision")
+	if !fluxRevisionIs(revision, lastConfigCommit.Master) {
+		status.Status = string(health.HealthStatusProgressing)
+		log.Warningf(ctx,
+			"current revision(%s) is not consistent with gitops repo commit(%s)",
+			revision, lastConfigCommit.Master)
+		return status, nil
+	}
+
+	_, kubeClient, err := c.kubeClientFactory.GetByRegion(params.RegionEntity.Region)
+	if err != nil {
+		return nil, err
+	}
+	resourceTree, err := c.getResourceTree(ctx, params.RegionEntity.ID, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if !treeHealthy(ctx, resourceTree, kubeClient) {
+		status.Status = string(health.HealthStatusProgressing)
+	}
+	return status, nil
+}
+
+func (c *fluxCD) get(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource,
+	name string) (*unstructured.Unstructured, error) {
+	obj, err := client.Resource(gvr).Namespace(c.config.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, herrors.NewErrNotFound(herrors.ReleaseInFlux,
+				fmt.Sprintf("%s %s not found in flux", gvr.Resource, name))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ReleaseInFlux,
+			fmt.Sprintf("failed to get %s %s: %v", gvr.Resource, name, err))
+	}
+	return obj, nil
+}
+
+func (c *fluxCD) GetResourceTree(ctx context.Context,
+	params *GetResourceTreeParams) ([]ResourceNode, error) {
+	const op = "flux cd: get resource tree"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	resourceTree, err := c.getResourceTree(ctx, params.RegionEntity.ID, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	return resourceNodesOfTree(ctx, c.informerFactories, params.RegionEntity.ID, resourceTree)
+}
+
+// getResourceTree assembles the resource tree of the cluster from the resources labeled with it
+// in the target namespace of its helm release, as flux does not keep track of them
+func (c *fluxCD) getResourceTree(ctx context.Context, regionID uint,
+	cluster string) (*applicationV1alpha1.ApplicationTree, error) {
+	resourceTree := &applicationV1alpha1.ApplicationTree{}
+	err := c.informerFactories.GetDynamicClientSet(regionID, func(client dynamic.Interface) error {
+		release, err := c.get(ctx, client, gvrFluxHelmRelease, cluster)
+		if err != nil {
+			return err
+		}
+		namespace, _, _ := unstructured.NestedString(release.Object, "spec", "targetNamespace")
+
+		objects := make([]unstructured.Unstructured, 0)
+		for _, gvr := range fluxTreeGVRs {
+			list, err := client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("%s=%s", common.ClusterClusterLabelKey, cluster),
+			})
+			if err != nil {
+				// rollouts are not served if argo rollouts is not installed
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return herrors.NewErrListFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to list %s: %v", gvr.String(), err))
+			}
+			objects = append(objects, list.Items...)
+		}
+		resourceTree.Nodes = fluxResourceNodes(objects)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resourceTree, nil
+}
+
+func (c *fluxCD) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
+	const op = "flux cd: get step"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, kubeClient, err := c.kubeClientFactory.GetByRegion(params.RegionEntity.Region)
+	if err != nil {
+		return nil, err
+	}
+	resourceTree, err := c.getResourceTree(ctx, params.RegionEntity.ID, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	return stepOfTree(resourceTree, kubeClient), nil
+}
+
+func (c *fluxCD) GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error) {
+	const op = "flux cd: get cluster pod events"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, kubeClient, err := c.kubeClientFactory.GetByRegion(params.RegionEntity.Region)
+	if err != nil {
+		return nil, err
+	}
+	resourceTree, err := c.GetResourceTree(ctx, &GetResourceTreeParams{
+		Environment:  params.Environment,
+		Cluster:      params.Cluster,
+		RegionEntity: params.RegionEntity,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return podEventsOfTree(ctx, kubeClient, resourceTree, params.Namespace, params.Pod)
+}
+
+// fluxReleaseHealth maps the Ready condition of a helm release to health status
+func fluxReleaseHealth(release *unstructured.Unstructured) health.HealthStatusCode {
+	observedGeneration, _, _ := unstructured.NestedInt64(release.Object, "status", "observedGeneration")
+	if observedGeneration < release.GetGeneration() {
+		return health.HealthStatusProgressing
+	}
+	conditions, _, _ := unstructured.NestedSlice(release.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		switch condition["status"] {
+		case "True":
+			return health.HealthStatusHealthy
+		case "False":
+			if reason := condition["reason"]; reason == "Progressing" || reason == "DependencyNotReady" {
+				return health.HealthStatusProgressing
+			}
+			return health.HealthStatusDegraded
+		}
+	}
+	return health.HealthStatusProgressing
+}
+
+// fluxRevisionIs checks whether the revision of a flux artifact is the commit,
+// the revision is like master/<commit>, or master@sha1:<commit> since flux v2.0
+func fluxRevisionIs(revision, commit string) bool {
+	return commit != "" && (strings.HasSuffix(revision, "/"+commit) || strings.HasSuffix(revision, ":"+commit))
+}
+
+// fluxResourceNodes converts resources into nodes of resource tree, owners not in the resources are dropped
+func fluxResourceNodes(objects []unstructured.Unstructured) []applicationV1alpha1.ResourceNode {
+	uids := make(map[types.UID]bool, len(objects))
+	for i := range objects {
+		uids[objects[i].GetUID()] = true
+	}
+
+	nodes := make([]applicationV1alpha1.ResourceNode, 0, len(objects))
+	for i := range objects {
+		obj := &objects[i]
+		group, version := splitAPIVersion(obj.GetAPIVersion())
+		createdAt := obj.GetCreationTimestamp()
+		node := applicationV1alpha1.ResourceNode{
+			ResourceRef: applicationV1alpha1.ResourceRef{
+				Group:     group,
+				Version:   version,
+				Kind:      obj.GetKind(),
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				UID:       string(obj.GetUID()),
+			},
+			ResourceVersion: obj.GetResourceVersion(),
+			CreatedAt:       &createdAt,
+		}
+		for _, owner := range obj.GetOwnerReferences() {
+			if !uids[owner.UID] {
+				continue
+			}
+			ownerGroup, ownerVersion := splitAPIVersion(owner.APIVersion)
+			node.ParentRefs = append(node.ParentRefs, applicationV1alpha1.ResourceRef{
+				Group:     ownerGroup,
+				Version:   ownerVersion,
+				Kind:      owner.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      owner.Name,
+				UID:       string(owner.UID),
+			})
+		}
+		if healthStatus, err := health.GetResourceHealth(obj, nil); err == nil && healthStatus != nil {
+			node.Health = &applicationV1alpha1.HealthStatus{
+				Status:  healthStatus.Status,
+				Message: healthStatus.Message,
+			}
+		}
+		if obj.GetKind() == "Pod" {
+			containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "containers")
+			for _, item := range containers {
+				if container, ok := item.(map[string]interface{}); ok {
+					node.Images = append(node.Images, nestedString(container, "image"))
+				}
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,123 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/config/fluxcd"
+)
+
+func TestFluxReleaseHealth(t *testing.T) {
+	release := func(generation, observedGeneration int64, conditions ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"generation": generation},
+			"status": map[string]interface{}{
+				"observedGeneration": observedGeneration,
+				"conditions":         conditions,
+			},
+		}}
+	}
+	ready := func(status, reason string) interface{} {
+		return map[string]interface{}{"type": "Ready", "status": status, "reason": reason}
+	}
+
+	assert.Equal(t, health.HealthStatusHealthy, fluxReleaseHealth(release(2, 2, ready("True", "Succeeded"))))
+	assert.Equal(t, health.HealthStatusProgressing, fluxReleaseHealth(release(3, 2, ready("True", "Succeeded"))))
+	assert.Equal(t, health.HealthStatusDegraded, fluxReleaseHealth(release(2, 2, ready("False", "UpgradeFailed"))))
+	assert.Equal(t, health.HealthStatusProgressing, fluxReleaseHealth(release(2, 2, ready("False", "Progressing"))))
+	assert.Equal(t, health.HealthStatusProgressing, fluxReleaseHealth(release(2, 2, ready("Unknown", ""))))
+	assert.Equal(t, health.HealthStatusProgressing, fluxReleaseHealth(release(1, 1)))
+}
+
+func TestFluxRevisionIs(t *testing.T) {
+	assert.True(t, fluxRevisionIs("master/abc123", "abc123"))
+	assert.True(t, fluxRevisionIs("master@sha1:abc123", "abc123"))
+	assert.False(t, fluxRevisionIs("master/abc123", "def456"))
+	assert.False(t, fluxRevisionIs("", ""))
+}
+
+func TestFluxAssemble(t *testing.T) {
+	c := newFluxCD(nil, nil, nil, fluxcd.Config{SecretRef: "gitops"}, "master")
+	params := &CreateClusterParams{
+		Cluster:    "cluster",
+		GitRepoURL: "ssh://git@gitlab.com/horizon/cluster.git",
+		ValueFiles: []string{"application.yaml", "pipeline-output.yaml"},
+		Namespace:  "test-1",
+	}
+
+	source := c.assembleGitRepository(params)
+	assert.Equal(t, _fluxDefaultNamespace, source.GetNamespace())
+	assert.Equal(t, "cluster", source.GetLabels()[common.ClusterClusterLabelKey])
+	secret, _, _ := unstructured.NestedString(source.Object, "spec", "secretRef", "name")
+	assert.Equal(t, "gitops", secret)
+	branch, _, _ := unstructured.NestedString(source.Object, "spec", "ref", "branch")
+	assert.Equal(t, "master", branch)
+
+	release := c.assembleHelmRelease(params)
+	targetNamespace, _, _ := unstructured.NestedString(release.Object, "spec", "targetNamespace")
+	assert.Equal(t, "test-1", targetNamespace)
+	valuesFiles, _, _ := unstructured.NestedStringSlice(release.Object, "spec", "chart", "spec", "valuesFiles")
+	assert.Equal(t, params.ValueFiles, valuesFiles)
+	sourceName, _, _ := unstructured.NestedString(release.Object, "spec", "chart", "spec", "sourceRef", "name")
+	assert.Equal(t, "cluster", sourceName)
+	// objects assembled must be able to be deep copied by the dynamic client
+	assert.NotNil(t, release.DeepCopy())
+}
+
+func TestFluxResourceNodes(t *testing.T) {
+	object := func(apiVersion, kind, name, uid string, owners ...interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":            name,
+				"namespace":       "test-1",
+				"uid":             uid,
+				"ownerReferences": owners,
+			},
+		}}
+	}
+	owner := func(apiVersion, kind, name, uid string) interface{} {
+		return map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "name": name, "uid": uid}
+	}
+
+	pod := object("v1", "Pod", "cluster-abc-1", "3", owner("apps/v1", "ReplicaSet", "cluster-abc", "2"))
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{"name": "app", "image": "app:v1"},
+	}, "spec", "containers")
+	nodes := fluxResourceNodes([]unstructured.Unstructured{
+		object("apps/v1", "Deployment", "cluster", "1"),
+		object("apps/v1", "ReplicaSet", "cluster-abc", "2", owner("apps/v1", "Deployment", "cluster", "1")),
+		pod,
+		// the owner is not labeled with the cluster
+		object("v1", "Pod", "cluster-job", "4", owner("batch/v1", "Job", "cluster-job", "5")),
+	})
+
+	assert.Equal(t, 4, len(nodes))
+	assert.Equal(t, "apps", nodes[0].Group)
+	assert.Equal(t, "v1", nodes[0].Version)
+	assert.Nil(t, nodes[0].ParentRefs)
+	assert.Equal(t, "1", nodes[1].ParentRefs[0].UID)
+	assert.Equal(t, "Deployment", nodes[1].ParentRefs[0].Kind)
+	assert.Equal(t, "2", nodes[2].ParentRefs[0].UID)
+	assert.Equal(t, []string{"app:v1"}, nodes[2].Images)
+	assert.Nil(t, nodes[3].ParentRefs)
+}
@@ -51,6 +51,20 @@ type K8sUtil interface {
 	GetPodContainers(ctx context.Context, params *GetPodParams) ([]ContainerDetail, error)
 	GetPod(ctx context.Context, params *GetPodParams) (*corev1.Pod, error)
 	GetContainerLog(ctx context.Context, params *GetContainerLogParams) (<-chan string, error)
+	// StreamContainerLog opens the log stream of a container of the cluster's pod, which must be closed by the caller
+	StreamContainerLog(ctx context.Context, params *StreamContainerLogParams) (io.ReadCloser, error)
+	// KubeProxy forwards read-only requests to the resources belonging to the cluster
+	KubeProxy(ctx context.Context, params *KubeProxyParams) ([]byte, error)
+	// Shell attaches an interactive shell to a container of the cluster's pod,
+	// it returns once the shell exits or the stdin is closed
+	Shell(ctx context.Context, params *ShellParams) error
+	// ListClusterResources lists the kubernetes resources managed for the cluster with their live status
+	ListClusterResources(ctx context.Context, params *ListClusterResourcesParams) ([]*ClusterResource, error)
+	// GetClusterResource gets the yaml view of a resource managed for the cluster, secrets are redacted
+	GetClusterResource(ctx context.Context, params *GetClusterResourceParams) (*ClusterResourceDetail, error)
+	// GetAutoscalingStatus gets the status of the HorizontalPodAutoscaler of the cluster,
+	// nil is returned if the cluster is not autoscaled
+	GetAutoscalingStatus(ctx context.Context, params *GetAutoscalingStatusParams) (*AutoscalingStatus, error)
 }
 
 type util struct {
@@ -116,6 +130,33 @@ func (e *util) GetContainerLog(ctx context.Context, params *GetContainerLogParam
 	return logC, nil
 }
 
+func (e *util) StreamContainerLog(ctx context.Context, params *StreamContainerLogParams) (_ io.ReadCloser,
+	err error) {
+	const op = "cd: stream container log"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var stream io.ReadCloser
+	err = e.informerFactories.GetClientSet(params.RegionEntity.ID, func(clientset kubernetes.Interface) error {
+		pod, err := kube.GetPod(ctx, clientset, params.Namespace, params.Pod)
+		if err != nil {
+			return err
+		}
+		container, err := podContainer(pod, params.Cluster, params.LogOptions.Container)
+		if err != nil {
+			return err
+		}
+		options := *params.LogOptions
+		options.Container = container
+		// the stream is read after the clientset is released
+		stream, err = kube.StreamPodLogs(ctx, clientset, params.Namespace, pod.Name, &options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
 func parseLogsStream(stream io.ReadCloser, ch chan string) {
 	bufReader := bufio.NewReader(stream)
 	eof := false
@@ -265,7 +306,7 @@ func (e *util) Exec(ctx context.Context, params *ExecParams) (resp map[string]Ex
 type TraverseOperator func(node *ResourceTreeNode) bool
 
 // traverseResourceTree traverses tree by dfs
-func (c *cd) traverseResourceTree(resourceTree *applicationV1alpha1.ApplicationTree,
+func traverseResourceTree(resourceTree *applicationV1alpha1.ApplicationTree,
 	operators ...TraverseOperator) {
 	m := make(map[string]*applicationV1alpha1.ResourceNode)
 	for i, node := range resourceTree.Nodes {
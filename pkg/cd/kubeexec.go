@@ -0,0 +1,111 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// shellCommand prefers bash, and falls back to sh for the images without bash
+var shellCommand = []string{"/bin/sh", "-c",
+	"TERM=xterm; export TERM; command -v bash >/dev/null 2>&1 && exec bash || exec sh"}
+
+// podContainer checks the pod belongs to the cluster and returns the container,
+// which defaults to the first container of the pod
+func podContainer(pod *corev1.Pod, cluster, container string) (string, error) {
+	if !belongsToCluster(pod.Labels, cluster) {
+		return "", herrors.NewErrNotFound(herrors.PodsInK8S,
+			fmt.Sprintf("pod %s does not belong to cluster %s", pod.Name, cluster))
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "pod %s has no container", pod.Name)
+	}
+	if container == "" {
+		return pod.Spec.Containers[0].Name, nil
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return container, nil
+		}
+	}
+	return "", perror.Wrapf(herrors.ErrParamInvalid, "container %s not found in pod %s", container, pod.Name)
+}
+
+func (e *util) Shell(ctx context.Context, params *ShellParams) (err error) {
+	const op = "cd: shell"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var execURL *url.URL
+	err = e.informerFactories.GetClientSet(params.RegionEntity.ID, func(clientset kubernetes.Interface) error {
+		pod, err := kube.GetPod(ctx, clientset, params.Namespace, params.Pod)
+		if err != nil {
+			return err
+		}
+		container, err := podContainer(pod, params.Cluster, params.Container)
+		if err != nil {
+			return err
+		}
+		execURL = clientset.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Name(pod.Name).
+			Namespace(params.Namespace).
+			SubResource("exec").
+			VersionedParams(&corev1.PodExecOptions{
+				Container: container,
+				Command:   shellCommand,
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			}, scheme.ParameterCodec).URL()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the clientset is not held during the session, which may last for hours
+	config, err := e.informerFactories.GetRestConfig(params.RegionEntity.ID)
+	if err != nil {
+		return err
+	}
+	executor, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, execURL)
+	if err != nil {
+		return perror.Wrap(herrors.ErrKubeExecFailed, err.Error())
+	}
+	// the output is merged into stdout with tty
+	if err := executor.Stream(remotecommand.StreamOptions{
+		Stdin:             params.Stdin,
+		Stdout:            params.Stdout,
+		Tty:               true,
+		TerminalSizeQueue: params.SizeQueue,
+	}); err != nil {
+		return perror.Wrap(herrors.ErrKubeExecFailed, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestPodContainer(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pod-1",
+			Labels: map[string]string{common.ClusterClusterLabelKey: "cluster-1"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+		},
+	}
+
+	container, err := podContainer(pod, "cluster-1", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "app", container)
+	container, err = podContainer(pod, "cluster-1", "sidecar")
+	assert.Nil(t, err)
+	assert.Equal(t, "sidecar", container)
+
+	_, err = podContainer(pod, "cluster-1", "not-exist")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = podContainer(pod, "cluster-2", "app")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,174 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	KubeProxyResourcePods   = "pods"
+	KubeProxyResourceEvents = "events"
+	KubeProxySubresourceLog = "log"
+)
+
+// kubeProxyLogQueries are the only query parameters forwarded to the pod log api
+var kubeProxyLogQueries = []string{"container", "tailLines", "sinceSeconds", "previous", "timestamps"}
+
+// kubeProxyRequest is a read-only request which is allowed to be forwarded to kubernetes
+type kubeProxyRequest struct {
+	Resource    string
+	Name        string
+	Subresource string
+}
+
+// parseKubeProxyPath parses the path relative to the cluster's namespace,
+// only the following paths are allowed:
+//
+//	pods
+//	pods/{name}
+//	pods/{name}/log
+//	events
+func parseKubeProxyPath(path string) (*kubeProxyRequest, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	req := &kubeProxyRequest{}
+	switch {
+	case len(parts) == 1 && (parts[0] == KubeProxyResourcePods || parts[0] == KubeProxyResourceEvents):
+		req.Resource = parts[0]
+	case len(parts) == 2 && parts[0] == KubeProxyResourcePods && parts[1] != "":
+		req.Resource, req.Name = parts[0], parts[1]
+	case len(parts) == 3 && parts[0] == KubeProxyResourcePods && parts[1] != "" &&
+		parts[2] == KubeProxySubresourceLog:
+		req.Resource, req.Name, req.Subresource = parts[0], parts[1], parts[2]
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "path %s is not allowed to be proxied", path)
+	}
+	return req, nil
+}
+
+// belongsToCluster checks whether the object is created for the cluster
+func belongsToCluster(labels map[string]string, cluster string) bool {
+	return labels[common.ClusterClusterLabelKey] == cluster
+}
+
+func podLogOptionsFromQuery(query url.Values) (*corev1.PodLogOptions, error) {
+	options := &corev1.PodLogOptions{}
+	for _, key := range kubeProxyLogQueries {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		var err error
+		switch key {
+		case "container":
+			options.Container = value
+		case "tailLines":
+			var lines int64
+			lines, err = strconv.ParseInt(value, 10, 64)
+			options.TailLines = &lines
+		case "sinceSeconds":
+			var seconds int64
+			seconds, err = strconv.ParseInt(value, 10, 64)
+			options.SinceSeconds = &seconds
+		case "previous":
+			options.Previous, err = strconv.ParseBool(value)
+		case "timestamps":
+			options.Timestamps, err = strconv.ParseBool(value)
+		}
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid query %s=%s", key, value)
+		}
+	}
+	return options, nil
+}
+
+func (e *util) KubeProxy(ctx context.Context, params *KubeProxyParams) (_ []byte, err error) {
+	const op = "cd: kube proxy"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	req, err := parseKubeProxyPath(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+	err = e.informerFactories.GetClientSet(params.RegionEntity.ID, func(clientset kubernetes.Interface) error {
+		switch {
+		case req.Resource == KubeProxyResourcePods && req.Name == "":
+			pods, err := kube.GetPods(ctx, clientset, params.Namespace,
+				fmt.Sprintf("%s=%s", common.ClusterClusterLabelKey, params.Cluster))
+			if err != nil {
+				return err
+			}
+			result, err = json.Marshal(corev1.PodList{Items: pods})
+			return err
+		case req.Resource == KubeProxyResourcePods:
+			pod, err := kube.GetPod(ctx, clientset, params.Namespace, req.Name)
+			if err != nil {
+				return err
+			}
+			if !belongsToCluster(pod.Labels, params.Cluster) {
+				return herrors.NewErrNotFound(herrors.PodsInK8S,
+					fmt.Sprintf("pod %s does not belong to cluster %s", req.Name, params.Cluster))
+			}
+			if req.Subresource == "" {
+				result, err = json.Marshal(pod)
+				return err
+			}
+			options, err := podLogOptionsFromQuery(params.Query)
+			if err != nil {
+				return err
+			}
+			result, err = clientset.CoreV1().Pods(params.Namespace).GetLogs(req.Name, options).DoRaw(ctx)
+			if err != nil {
+				return herrors.NewErrGetFailed(herrors.PodLogsInK8S, err.Error())
+			}
+			return nil
+		default:
+			pods, err := kube.GetPods(ctx, clientset, params.Namespace,
+				fmt.Sprintf("%s=%s", common.ClusterClusterLabelKey, params.Cluster))
+			if err != nil {
+				return err
+			}
+			events := make([]corev1.Event, 0)
+			for _, pod := range pods {
+				podEvents, err := kube.GetPodEvents(ctx, clientset, params.Namespace, pod.Name)
+				if err != nil {
+					return err
+				}
+				events = append(events, podEvents...)
+			}
+			result, err = json.Marshal(corev1.EventList{Items: events})
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestParseKubeProxyPath(t *testing.T) {
+	cases := map[string]*kubeProxyRequest{
+		"/pods":            {Resource: "pods"},
+		"/events":          {Resource: "events"},
+		"/pods/pod-1":      {Resource: "pods", Name: "pod-1"},
+		"/pods/pod-1/log":  {Resource: "pods", Name: "pod-1", Subresource: "log"},
+		"pods/pod-1/log/":  {Resource: "pods", Name: "pod-1", Subresource: "log"},
+		"/pods/pod-1/exec": nil,
+		"/secrets":         nil,
+		"/events/e-1":      nil,
+		"/":                nil,
+	}
+	for path, expected := range cases {
+		req, err := parseKubeProxyPath(path)
+		if expected == nil {
+			assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), path)
+			continue
+		}
+		assert.Nil(t, err, path)
+		assert.Equal(t, expected, req, path)
+	}
+}
+
+func TestPodLogOptionsFromQuery(t *testing.T) {
+	options, err := podLogOptionsFromQuery(url.Values{
+		"container": {"app"},
+		"tailLines": {"100"},
+		"previous":  {"true"},
+		"follow":    {"true"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "app", options.Container)
+	assert.Equal(t, int64(100), *options.TailLines)
+	assert.True(t, options.Previous)
+	assert.False(t, options.Follow)
+
+	_, err = podLogOptionsFromQuery(url.Values{"tailLines": {"abc"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -0,0 +1,163 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/argocd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	OperationSync = "sync"
+
+	_operationRetries = 5
+	_operationBackoff = 2 * time.Second
+)
+
+// QueuedOperation is an argocd operation waiting or running in the queue of a cluster
+type QueuedOperation struct {
+	Operation string    `json:"operation"`
+	Position  int       `json:"position"`
+	Running   bool      `json:"running"`
+	Attempts  int       `json:"attempts"`
+	QueuedAt  time.Time `json:"queuedAt"`
+	// Region and RegionPosition are set when the running operation waits for a free sync slot of the region,
+	// the position starts from 1
+	Region         string `json:"region,omitempty"`
+	RegionPosition int    `json:"regionPosition,omitempty"`
+}
+
+type operation struct {
+	name     string
+	queuedAt time.Time
+	attempts int
+	// ready is closed when the operation becomes the head of the queue
+	ready chan struct{}
+}
+
+// operationQueue serializes argocd operations of the same cluster in FIFO order,
+// so that syncs triggered concurrently do not conflict with each other
+type operationQueue struct {
+	// mu protects queues and attempts of operations
+	mu      sync.Mutex
+	queues  map[string][]*operation
+	retries int
+	backoff time.Duration
+}
+
+func newOperationQueue() *operationQueue {
+	return &operationQueue{
+		queues:  make(map[string][]*operation),
+		retries: _operationRetries,
+		backoff: _operationBackoff,
+	}
+}
+
+// Do waits for the operations of cluster queued before, then runs f.
+// f is retried with backoff when argocd reports another operation is in progress.
+func (q *operationQueue) Do(ctx context.Context, cluster, name string, f func() error) error {
+	op := q.enqueue(cluster, name)
+	defer q.dequeue(cluster, op)
+
+	select {
+	case <-op.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	backoff := q.backoff
+	for {
+		q.mu.Lock()
+		op.attempts++
+		attempts := op.attempts
+		q.mu.Unlock()
+
+		err := f()
+		if err == nil || perror.Cause(err) != argocd.ErrOperationInProgress || attempts > q.retries {
+			return err
+		}
+		log.Warningf(ctx, "%s of cluster %s conflicts with an operation in progress, "+
+			"retry after %v, attempts: %d", name, cluster, backoff, attempts)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// List returns the operations of cluster, the first one is running
+func (q *operationQueue) List(cluster string) []*QueuedOperation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue := q.queues[cluster]
+	operations := make([]*QueuedOperation, 0, len(queue))
+	for i, op := range queue {
+		operations = append(operations, &QueuedOperation{
+			Operation: op.name,
+			Position:  i,
+			Running:   i == 0,
+			Attempts:  op.attempts,
+			QueuedAt:  op.queuedAt,
+		})
+	}
+	return operations
+}
+
+func (q *operationQueue) enqueue(cluster, name string) *operation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	op := &operation{
+		name:     name,
+		queuedAt: time.Now(),
+		ready:    make(chan struct{}),
+	}
+	if len(q.queues[cluster]) == 0 {
+		close(op.ready)
+	}
+	q.queues[cluster] = append(q.queues[cluster], op)
+	return op
+}
+
+func (q *operationQueue) dequeue(cluster string, op *operation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue := q.queues[cluster]
+	for i := range queue {
+		if queue[i] != op {
+			continue
+		}
+		queue = append(queue[:i], queue[i+1:]...)
+		// the next operation becomes the head
+		if i == 0 && len(queue) > 0 {
+			close(queue[0].ready)
+		}
+		break
+	}
+	if len(queue) == 0 {
+		delete(q.queues, cluster)
+		return
+	}
+	q.queues[cluster] = queue
+}
@@ -0,0 +1,108 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/argocd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestOperationQueue(t *testing.T) {
+	q := newOperationQueue()
+	q.backoff = time.Millisecond
+	ctx := context.Background()
+
+	// operations of a cluster run in FIFO order
+	running := make(chan struct{})
+	release := make(chan struct{})
+	firstDone := make(chan error)
+	go func() {
+		firstDone <- q.Do(ctx, "cluster", OperationSync, func() error {
+			close(running)
+			<-release
+			return nil
+		})
+	}()
+	<-running
+
+	secondDone := make(chan error)
+	var secondRan int32
+	go func() {
+		secondDone <- q.Do(ctx, "cluster", OperationSync, func() error {
+			atomic.StoreInt32(&secondRan, 1)
+			return nil
+		})
+	}()
+	assert.Eventually(t, func() bool {
+		return len(q.List("cluster")) == 2
+	}, time.Second, time.Millisecond)
+	operations := q.List("cluster")
+	assert.True(t, operations[0].Running)
+	assert.False(t, operations[1].Running)
+	assert.Equal(t, 1, operations[1].Position)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&secondRan))
+
+	close(release)
+	assert.Nil(t, <-firstDone)
+	assert.Nil(t, <-secondDone)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&secondRan))
+	assert.Empty(t, q.List("cluster"))
+
+	// operation in progress is retried
+	attempts := 0
+	err := q.Do(ctx, "cluster", OperationSync, func() error {
+		attempts++
+		if attempts < 3 {
+			return perror.Wrap(argocd.ErrOperationInProgress, "")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// gives up after retries
+	attempts = 0
+	err = q.Do(ctx, "cluster", OperationSync, func() error {
+		attempts++
+		return perror.Wrap(argocd.ErrOperationInProgress, "")
+	})
+	assert.Equal(t, argocd.ErrOperationInProgress, perror.Cause(err))
+	assert.Equal(t, _operationRetries+1, attempts)
+
+	// waiting is canceled with context
+	release = make(chan struct{})
+	running = make(chan struct{})
+	go func() {
+		_ = q.Do(ctx, "cluster", OperationSync, func() error {
+			close(running)
+			<-release
+			return nil
+		})
+	}()
+	<-running
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = q.Do(cancelCtx, "cluster", OperationSync, func() error { return nil })
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, len(q.List("cluster")))
+	close(release)
+}
@@ -0,0 +1,139 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+	"github.com/horizoncd/horizon/pkg/config/fluxcd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+)
+
+// regionalCD dispatches operations of clusters to the cd system of their regions
+type regionalCD struct {
+	regionMgr regionmanager.Manager
+	argoCD    CD
+	fluxCD    CD
+}
+
+var _ LegacyCD = (*regionalCD)(nil)
+
+func NewCD(regionMgr regionmanager.Manager, informerFactories *regioninformers.RegionInformers,
+	clusterGitRepo gitrepo.ClusterGitRepo, argoCDMapper argocdconf.Mapper, fluxConfig fluxcd.Config,
+	targetRevision string, syncConcurrency argocdconf.SyncConcurrency) CD {
+	return &regionalCD{
+		regionMgr: regionMgr,
+		argoCD:    newArgoCD(informerFactories, clusterGitRepo, argoCDMapper, targetRevision, syncConcurrency),
+		fluxCD:    newFluxCD(regionMgr, informerFactories, clusterGitRepo, fluxConfig, targetRevision),
+	}
+}
+
+func (c *regionalCD) of(region *regionmodels.Region) CD {
+	if region != nil && region.CDType == regionmodels.CDTypeFlux {
+		return c.fluxCD
+	}
+	return c.argoCD
+}
+
+func (c *regionalCD) ofEntity(regionEntity *regionmodels.RegionEntity) CD {
+	if regionEntity == nil {
+		return c.argoCD
+	}
+	return c.of(regionEntity.Region)
+}
+
+func (c *regionalCD) ofName(ctx context.Context, region string) (CD, error) {
+	r, err := c.regionMgr.GetRegionByName(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	return c.of(r), nil
+}
+
+func (c *regionalCD) CreateCluster(ctx context.Context, params *CreateClusterParams) error {
+	return c.ofEntity(params.RegionEntity).CreateCluster(ctx, params)
+}
+
+func (c *regionalCD) DeployCluster(ctx context.Context, params *DeployClusterParams) error {
+	backend, err := c.ofName(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+	return backend.DeployCluster(ctx, params)
+}
+
+func (c *regionalCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) error {
+	backend, err := c.ofName(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+	return backend.DeleteCluster(ctx, params)
+}
+
+func (c *regionalCD) GetClusterState(ctx context.Context, params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	return c.ofEntity(params.RegionEntity).GetClusterState(ctx, params)
+}
+
+func (c *regionalCD) GetResourceTree(ctx context.Context, params *GetResourceTreeParams) ([]ResourceNode, error) {
+	return c.ofEntity(params.RegionEntity).GetResourceTree(ctx, params)
+}
+
+func (c *regionalCD) GetManifests(ctx context.Context,
+	params *GetManifestsParams) ([]map[string]interface{}, error) {
+	backend, err := c.ofName(ctx, params.Region)
+	if err != nil {
+		return nil, err
+	}
+	return backend.GetManifests(ctx, params)
+}
+
+func (c *regionalCD) GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error) {
+	backend, err := c.ofName(ctx, params.Region)
+	if err != nil {
+		return nil, err
+	}
+	return backend.GetClusterDrift(ctx, params)
+}
+
+func (c *regionalCD) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
+	return c.ofEntity(params.RegionEntity).GetStep(ctx, params)
+}
+
+func (c *regionalCD) GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error) {
+	return c.ofEntity(params.RegionEntity).GetPodEvents(ctx, params)
+}
+
+// ListQueuedOperations lists operations of the cluster in both cd systems, as only one of them syncs it
+func (c *regionalCD) ListQueuedOperations(ctx context.Context, cluster string) []*QueuedOperation {
+	return append(c.argoCD.ListQueuedOperations(ctx, cluster), c.fluxCD.ListQueuedOperations(ctx, cluster)...)
+}
+
+// Deprecated: using GetClusterState instead
+func (c *regionalCD) GetClusterStateV1(ctx context.Context,
+	params *GetClusterStateParams) (*ClusterState, error) {
+	legacyCD, ok := c.ofEntity(params.RegionEntity).(LegacyCD)
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrNotSupport,
+			"cd of region %s does not support legacy cluster state", params.RegionEntity.Name)
+	}
+	// nolint
+	return legacyCD.GetClusterStateV1(ctx, params)
+}
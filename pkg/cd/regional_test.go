@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+)
+
+type fakeRegionManager struct {
+	regionmanager.Manager
+	regions map[string]*regionmodels.Region
+}
+
+func (m *fakeRegionManager) GetRegionByName(_ context.Context, name string) (*regionmodels.Region, error) {
+	if r, ok := m.regions[name]; ok {
+		return r, nil
+	}
+	return nil, herrors.NewErrNotFound(herrors.RegionInDB, name)
+}
+
+type fakeCD struct {
+	CD
+	deployed []string
+}
+
+func (c *fakeCD) DeployCluster(_ context.Context, params *DeployClusterParams) error {
+	c.deployed = append(c.deployed, params.Cluster)
+	return nil
+}
+
+func (c *fakeCD) ListQueuedOperations(_ context.Context, cluster string) []*QueuedOperation {
+	return []*QueuedOperation{{Operation: cluster}}
+}
+
+func TestRegionalCD(t *testing.T) {
+	ctx := context.Background()
+	argoCD, fluxCD := &fakeCD{}, &fakeCD{}
+	c := &regionalCD{
+		regionMgr: &fakeRegionManager{regions: map[string]*regionmodels.Region{
+			"hz":     {Name: "hz", CDType: regionmodels.CDTypeArgoCD},
+			"js":     {Name: "js", CDType: regionmodels.CDTypeFlux},
+			"legacy": {Name: "legacy"},
+		}},
+		argoCD: argoCD,
+		fluxCD: fluxCD,
+	}
+
+	for _, region := range []string{"hz", "js", "legacy"} {
+		err := c.DeployCluster(ctx, &DeployClusterParams{Region: region, Cluster: "cluster-" + region})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []string{"cluster-hz", "cluster-legacy"}, argoCD.deployed)
+	assert.Equal(t, []string{"cluster-js"}, fluxCD.deployed)
+
+	err := c.DeployCluster(ctx, &DeployClusterParams{Region: "unknown", Cluster: "cluster"})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	assert.Equal(t, 2, len(c.ListQueuedOperations(ctx, "cluster")))
+
+	assert.Equal(t, argoCD, c.ofEntity(nil))
+	assert.Equal(t, fluxCD, c.ofEntity(&regionmodels.RegionEntity{
+		Region: &regionmodels.Region{CDType: regionmodels.CDTypeFlux},
+	}))
+}
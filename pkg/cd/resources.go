@@ -0,0 +1,292 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	ResourceKindDeployment              = "deployments"
+	ResourceKindService                 = "services"
+	ResourceKindIngress                 = "ingresses"
+	ResourceKindHorizontalPodAutoscaler = "horizontalpodautoscalers"
+	ResourceKindConfigMap               = "configmaps"
+	ResourceKindSecret                  = "secrets"
+
+	ResourceStatusReady       = "Ready"
+	ResourceStatusProgressing = "Progressing"
+	ResourceStatusPending     = "Pending"
+
+	redactedValue = "******"
+)
+
+// lastAppliedAnnotation may contain the whole secret in plain text
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// managedResourceKinds are the kinds of resources which can be viewed, in display order
+var managedResourceKinds = []string{
+	ResourceKindDeployment,
+	ResourceKindService,
+	ResourceKindIngress,
+	ResourceKindHorizontalPodAutoscaler,
+	ResourceKindConfigMap,
+	ResourceKindSecret,
+}
+
+var managedResourceGVRs = map[string]schema.GroupVersionResource{
+	ResourceKindDeployment: {Group: "apps", Version: "v1", Resource: ResourceKindDeployment},
+	ResourceKindService:    {Group: "", Version: "v1", Resource: ResourceKindService},
+	ResourceKindIngress:    {Group: "networking.k8s.io", Version: "v1", Resource: ResourceKindIngress},
+	ResourceKindHorizontalPodAutoscaler: {
+		Group: "autoscaling", Version: "v1", Resource: ResourceKindHorizontalPodAutoscaler,
+	},
+	ResourceKindConfigMap: {Group: "", Version: "v1", Resource: ResourceKindConfigMap},
+	ResourceKindSecret:    {Group: "", Version: "v1", Resource: ResourceKindSecret},
+}
+
+func gvrOfKind(kind string) (schema.GroupVersionResource, error) {
+	gvr, ok := managedResourceGVRs[kind]
+	if !ok {
+		return schema.GroupVersionResource{}, perror.Wrapf(herrors.ErrParamInvalid,
+			"kind %s is not supported, supported kinds: %s", kind, strings.Join(managedResourceKinds, ","))
+	}
+	return gvr, nil
+}
+
+func (e *util) ListClusterResources(ctx context.Context,
+	params *ListClusterResourcesParams) (_ []*ClusterResource, err error) {
+	const op = "cd: list cluster resources"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kinds := managedResourceKinds
+	if params.Kind != "" {
+		if _, err := gvrOfKind(params.Kind); err != nil {
+			return nil, err
+		}
+		kinds = []string{params.Kind}
+	}
+
+	resources := make([]*ClusterResource, 0)
+	err = e.informerFactories.GetDynamicClientSet(params.RegionEntity.ID, func(clientset dynamic.Interface) error {
+		for _, kind := range kinds {
+			gvr := managedResourceGVRs[kind]
+			list, err := clientset.Resource(gvr).Namespace(params.Namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("%s=%s", common.ClusterClusterLabelKey, params.Cluster),
+			})
+			if err != nil {
+				// the kind may not be served by old kubernetes versions, such as networking.k8s.io/v1
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return herrors.NewErrGetFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to list %s: %v", gvr.String(), err))
+			}
+			for i := range list.Items {
+				resources = append(resources, summarizeResource(kind, &list.Items[i]))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func (e *util) GetClusterResource(ctx context.Context,
+	params *GetClusterResourceParams) (_ *ClusterResourceDetail, err error) {
+	const op = "cd: get cluster resource"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	gvr, err := gvrOfKind(params.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var detail *ClusterResourceDetail
+	err = e.informerFactories.GetDynamicClientSet(params.RegionEntity.ID, func(clientset dynamic.Interface) error {
+		un, err := clientset.Resource(gvr).Namespace(params.Namespace).Get(ctx, params.Name, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return herrors.NewErrNotFound(herrors.ResourceInK8S,
+					fmt.Sprintf("%s %s not found", params.Kind, params.Name))
+			}
+			return herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to get %s %s: %v", gvr.String(), params.Name, err))
+		}
+		if !belongsToCluster(un.GetLabels(), params.Cluster) {
+			return herrors.NewErrNotFound(herrors.ResourceInK8S,
+				fmt.Sprintf("%s %s does not belong to cluster %s", params.Kind, params.Name, params.Cluster))
+		}
+
+		sanitizeResource(params.Kind, un)
+		content, err := kyaml.Marshal(un.Object)
+		if err != nil {
+			return perror.Wrapf(err, "failed to marshal %s %s", params.Kind, params.Name)
+		}
+		detail = &ClusterResourceDetail{
+			ClusterResource: *summarizeResource(params.Kind, un),
+			YAML:            string(content),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// sanitizeResource drops noisy fields and redacts the values of secrets
+func sanitizeResource(kind string, un *unstructured.Unstructured) {
+	un.SetManagedFields(nil)
+	if kind != ResourceKindSecret {
+		return
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok, _ := unstructured.NestedMap(un.Object, field)
+		if !ok {
+			continue
+		}
+		for key := range values {
+			values[key] = redactedValue
+		}
+		_ = unstructured.SetNestedMap(un.Object, values, field)
+	}
+	if annotations := un.GetAnnotations(); annotations != nil {
+		if _, ok := annotations[lastAppliedAnnotation]; ok {
+			annotations[lastAppliedAnnotation] = redactedValue
+			un.SetAnnotations(annotations)
+		}
+	}
+}
+
+// summarizeResource extracts the live status of the resource
+func summarizeResource(kind string, un *unstructured.Unstructured) *ClusterResource {
+	resource := &ClusterResource{
+		Kind:      kind,
+		Name:      un.GetName(),
+		CreatedAt: un.GetCreationTimestamp().Time,
+		Summary:   map[string]string{},
+	}
+
+	nestedInt := func(fields ...string) int64 {
+		value, _, _ := unstructured.NestedInt64(un.Object, fields...)
+		return value
+	}
+
+	switch kind {
+	case ResourceKindDeployment:
+		desired := int64(1)
+		if replicas, ok, _ := unstructured.NestedInt64(un.Object, "spec", "replicas"); ok {
+			desired = replicas
+		}
+		ready, updated := nestedInt("status", "readyReplicas"), nestedInt("status", "updatedReplicas")
+		resource.Summary["ready"] = fmt.Sprintf("%d/%d", ready, desired)
+		resource.Summary["updated"] = fmt.Sprintf("%d", updated)
+		resource.Summary["available"] = fmt.Sprintf("%d", nestedInt("status", "availableReplicas"))
+		resource.Status = ResourceStatusProgressing
+		if ready == desired && updated == desired &&
+			nestedInt("status", "observedGeneration") >= un.GetGeneration() {
+			resource.Status = ResourceStatusReady
+		}
+	case ResourceKindService:
+		serviceType, _, _ := unstructured.NestedString(un.Object, "spec", "type")
+		clusterIP, _, _ := unstructured.NestedString(un.Object, "spec", "clusterIP")
+		ports, _, _ := unstructured.NestedSlice(un.Object, "spec", "ports")
+		portStrs := make([]string, 0, len(ports))
+		for _, port := range ports {
+			if p, ok := port.(map[string]interface{}); ok {
+				protocol, _, _ := unstructured.NestedString(p, "protocol")
+				number, _, _ := unstructured.NestedInt64(p, "port")
+				portStrs = append(portStrs, fmt.Sprintf("%d/%s", number, protocol))
+			}
+		}
+		resource.Summary["type"] = serviceType
+		resource.Summary["clusterIP"] = clusterIP
+		resource.Summary["ports"] = strings.Join(portStrs, ",")
+		resource.Status = ResourceStatusReady
+		if serviceType == "LoadBalancer" {
+			resource.Status = loadBalancerStatus(un, resource)
+		}
+	case ResourceKindIngress:
+		rules, _, _ := unstructured.NestedSlice(un.Object, "spec", "rules")
+		hosts := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			if r, ok := rule.(map[string]interface{}); ok {
+				if host, _, _ := unstructured.NestedString(r, "host"); host != "" {
+					hosts = append(hosts, host)
+				}
+			}
+		}
+		resource.Summary["hosts"] = strings.Join(hosts, ",")
+		resource.Status = loadBalancerStatus(un, resource)
+	case ResourceKindHorizontalPodAutoscaler:
+		current, desired := nestedInt("status", "currentReplicas"), nestedInt("status", "desiredReplicas")
+		resource.Summary["minReplicas"] = fmt.Sprintf("%d", nestedInt("spec", "minReplicas"))
+		resource.Summary["maxReplicas"] = fmt.Sprintf("%d", nestedInt("spec", "maxReplicas"))
+		resource.Summary["replicas"] = fmt.Sprintf("%d/%d", current, desired)
+		if utilization, ok, _ := unstructured.NestedInt64(un.Object,
+			"status", "currentCPUUtilizationPercentage"); ok {
+			resource.Summary["cpuUtilization"] = fmt.Sprintf("%d%%", utilization)
+		}
+		resource.Status = ResourceStatusProgressing
+		if current == desired {
+			resource.Status = ResourceStatusReady
+		}
+	case ResourceKindConfigMap, ResourceKindSecret:
+		data, _, _ := unstructured.NestedMap(un.Object, "data")
+		resource.Summary["keys"] = fmt.Sprintf("%d", len(data))
+		if kind == ResourceKindSecret {
+			secretType, _, _ := unstructured.NestedString(un.Object, "type")
+			resource.Summary["type"] = secretType
+		}
+		resource.Status = ResourceStatusReady
+	}
+	return resource
+}
+
+// loadBalancerStatus reports whether the load balancer has been assigned an address
+func loadBalancerStatus(un *unstructured.Unstructured, resource *ClusterResource) string {
+	ingresses, _, _ := unstructured.NestedSlice(un.Object, "status", "loadBalancer", "ingress")
+	addresses := make([]string, 0, len(ingresses))
+	for _, ingress := range ingresses {
+		if i, ok := ingress.(map[string]interface{}); ok {
+			if ip, _, _ := unstructured.NestedString(i, "ip"); ip != "" {
+				addresses = append(addresses, ip)
+			} else if hostname, _, _ := unstructured.NestedString(i, "hostname"); hostname != "" {
+				addresses = append(addresses, hostname)
+			}
+		}
+	}
+	resource.Summary["addresses"] = strings.Join(addresses, ",")
+	if len(addresses) == 0 {
+		return ResourceStatusPending
+	}
+	return ResourceStatusReady
+}
@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestGvrOfKind(t *testing.T) {
+	gvr, err := gvrOfKind(ResourceKindIngress)
+	assert.Nil(t, err)
+	assert.Equal(t, "networking.k8s.io", gvr.Group)
+
+	_, err = gvrOfKind("pods")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func TestSummarizeResource(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "app", "generation": int64(2)},
+		"spec":     map[string]interface{}{"replicas": int64(3)},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"readyReplicas":      int64(2),
+			"updatedReplicas":    int64(3),
+		},
+	}}
+	resource := summarizeResource(ResourceKindDeployment, deployment)
+	assert.Equal(t, "app", resource.Name)
+	assert.Equal(t, ResourceStatusProgressing, resource.Status)
+	assert.Equal(t, "2/3", resource.Summary["ready"])
+
+	_ = unstructured.SetNestedField(deployment.Object, int64(3), "status", "readyReplicas")
+	assert.Equal(t, ResourceStatusReady, summarizeResource(ResourceKindDeployment, deployment).Status)
+
+	service := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "app"},
+		"spec": map[string]interface{}{
+			"type":      "LoadBalancer",
+			"clusterIP": "10.0.0.1",
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(80), "protocol": "TCP"},
+			},
+		},
+	}}
+	resource = summarizeResource(ResourceKindService, service)
+	assert.Equal(t, ResourceStatusPending, resource.Status)
+	assert.Equal(t, "80/TCP", resource.Summary["ports"])
+
+	_ = unstructured.SetNestedSlice(service.Object, []interface{}{
+		map[string]interface{}{"ip": "1.2.3.4"},
+	}, "status", "loadBalancer", "ingress")
+	resource = summarizeResource(ResourceKindService, service)
+	assert.Equal(t, ResourceStatusReady, resource.Status)
+	assert.Equal(t, "1.2.3.4", resource.Summary["addresses"])
+}
+
+func TestSanitizeResource(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "app",
+			"annotations": map[string]interface{}{
+				lastAppliedAnnotation: `{"data":{"password":"cGFzc3dvcmQ="}}`,
+				"owner":               "horizon",
+			},
+			"managedFields": []interface{}{map[string]interface{}{"manager": "horizon"}},
+		},
+		"data":       map[string]interface{}{"password": "cGFzc3dvcmQ="},
+		"stringData": map[string]interface{}{"token": "plain"},
+	}}
+	sanitizeResource(ResourceKindSecret, secret)
+	assert.Equal(t, map[string]interface{}{"password": redactedValue}, secret.Object["data"])
+	assert.Equal(t, map[string]interface{}{"token": redactedValue}, secret.Object["stringData"])
+	assert.Equal(t, map[string]string{
+		lastAppliedAnnotation: redactedValue,
+		"owner":               "horizon",
+	}, secret.GetAnnotations())
+	assert.Nil(t, secret.GetManagedFields())
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "app"},
+		"data":     map[string]interface{}{"key": "value"},
+	}}
+	sanitizeResource(ResourceKindConfigMap, configMap)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, configMap.Object["data"])
+}
@@ -0,0 +1,197 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/argocd"
+	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_defaultSyncTimeout = 10 * time.Minute
+	_syncPollInterval   = 5 * time.Second
+)
+
+type slot struct {
+	cluster string
+	// ready is closed when the slot is granted
+	ready chan struct{}
+}
+
+type regionSlots struct {
+	running []*slot
+	waiting []*slot
+}
+
+// syncThrottle limits argocd syncs in progress of each region to protect control planes of small regions,
+// syncs beyond the limit wait for free slots in FIFO order
+type syncThrottle struct {
+	// mu protects regions
+	mu      sync.Mutex
+	regions map[string]*regionSlots
+	config  argocdconf.SyncConcurrency
+}
+
+func newSyncThrottle(config argocdconf.SyncConcurrency) *syncThrottle {
+	return &syncThrottle{
+		regions: make(map[string]*regionSlots),
+		config:  config,
+	}
+}
+
+// Acquire waits for a free slot of the region, the returned release must be called once the sync finishes.
+// It returns immediately if the region is not limited.
+func (t *syncThrottle) Acquire(ctx context.Context, region, cluster string) (release func(), err error) {
+	limit := t.config.LimitOf(region)
+	if limit <= 0 {
+		return func() {}, nil
+	}
+
+	s := t.enqueue(region, cluster, limit)
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		t.cancel(region, s, limit)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { t.release(region, s, limit) })
+	}, nil
+}
+
+// Position returns the region and the position starting from 1 of the cluster's sync waiting for a free slot,
+// the position is 0 if the cluster is not waiting
+func (t *syncThrottle) Position(cluster string) (string, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for region, slots := range t.regions {
+		for i, s := range slots.waiting {
+			if s.cluster == cluster {
+				return region, i + 1
+			}
+		}
+	}
+	return "", 0
+}
+
+// HoldUntilSynced releases the slot after argocd finishes the sync of the cluster or the sync times out
+func (t *syncThrottle) HoldUntilSynced(argo argocd.ArgoCD, cluster string, release func()) {
+	timeout := t.config.Timeout
+	if timeout <= 0 {
+		timeout = _defaultSyncTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	go func() {
+		defer cancel()
+		defer release()
+
+		ticker := time.NewTicker(_syncPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				log.Warningf(ctx, "sync of cluster %s is still in progress after %v, release its slot", cluster, timeout)
+				return
+			}
+			app, err := argo.GetApplication(ctx, cluster)
+			if err != nil {
+				log.Warningf(ctx, "failed to get the sync status of cluster %s: %v", cluster, err)
+				continue
+			}
+			// the operation is set when the sync is requested, and moved to the operation state once it starts
+			if app.Operation == nil && (app.Status.OperationState == nil ||
+				app.Status.OperationState.Phase.Completed()) {
+				return
+			}
+		}
+	}()
+}
+
+func (t *syncThrottle) enqueue(region, cluster string, limit int) *slot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	slots, ok := t.regions[region]
+	if !ok {
+		slots = &regionSlots{}
+		t.regions[region] = slots
+	}
+	s := &slot{
+		cluster: cluster,
+		ready:   make(chan struct{}),
+	}
+	slots.waiting = append(slots.waiting, s)
+	t.grant(region, limit)
+	return s
+}
+
+// cancel removes the slot waiting, or releases it if it has been granted in the meantime
+func (t *syncThrottle) cancel(region string, s *slot, limit int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	slots, ok := t.regions[region]
+	if !ok {
+		return
+	}
+	slots.waiting = removeSlot(slots.waiting, s)
+	slots.running = removeSlot(slots.running, s)
+	t.grant(region, limit)
+}
+
+func (t *syncThrottle) release(region string, s *slot, limit int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	slots, ok := t.regions[region]
+	if !ok {
+		return
+	}
+	slots.running = removeSlot(slots.running, s)
+	t.grant(region, limit)
+}
+
+// grant moves waiting slots to running while the limit allows, t.mu must be held
+func (t *syncThrottle) grant(region string, limit int) {
+	slots := t.regions[region]
+	for len(slots.waiting) > 0 && len(slots.running) < limit {
+		s := slots.waiting[0]
+		slots.waiting = slots.waiting[1:]
+		slots.running = append(slots.running, s)
+		close(s.ready)
+	}
+	if len(slots.waiting) == 0 && len(slots.running) == 0 {
+		delete(t.regions, region)
+	}
+}
+
+func removeSlot(slots []*slot, s *slot) []*slot {
+	for i := range slots {
+		if slots[i] == s {
+			return append(slots[:i], slots[i+1:]...)
+		}
+	}
+	return slots
+}
@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+)
+
+func TestSyncThrottle(t *testing.T) {
+	throttle := newSyncThrottle(argocdconf.SyncConcurrency{
+		Regions: map[string]int{"small": 2},
+	})
+	ctx := context.Background()
+
+	// regions not limited
+	release, err := throttle.Acquire(ctx, "large", "cluster0")
+	assert.Nil(t, err)
+	release()
+
+	release1, err := throttle.Acquire(ctx, "small", "cluster1")
+	assert.Nil(t, err)
+	release2, err := throttle.Acquire(ctx, "small", "cluster2")
+	assert.Nil(t, err)
+
+	// syncs beyond the limit wait in FIFO order
+	acquired := make(chan string, 2)
+	releases := make(chan func(), 2)
+	for _, cluster := range []string{"cluster3", "cluster4"} {
+		cluster := cluster
+		go func() {
+			release, err := throttle.Acquire(ctx, "small", cluster)
+			assert.Nil(t, err)
+			releases <- release
+			acquired <- cluster
+		}()
+		assert.Eventually(t, func() bool {
+			_, position := throttle.Position(cluster)
+			return position > 0
+		}, time.Second, time.Millisecond)
+	}
+	region, position := throttle.Position("cluster4")
+	assert.Equal(t, "small", region)
+	assert.Equal(t, 2, position)
+	_, position = throttle.Position("cluster1")
+	assert.Equal(t, 0, position)
+
+	release1()
+	// release is idempotent
+	release1()
+	assert.Equal(t, "cluster3", <-acquired)
+	_, position = throttle.Position("cluster4")
+	assert.Equal(t, 1, position)
+
+	release2()
+	assert.Equal(t, "cluster4", <-acquired)
+	(<-releases)()
+	(<-releases)()
+
+	// waiting is canceled with context
+	release1, err = throttle.Acquire(ctx, "small", "cluster1")
+	assert.Nil(t, err)
+	release2, err = throttle.Acquire(ctx, "small", "cluster2")
+	assert.Nil(t, err)
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = throttle.Acquire(cancelCtx, "small", "cluster3")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	_, position = throttle.Position("cluster3")
+	assert.Equal(t, 0, position)
+
+	release1()
+	release2()
+	assert.Empty(t, throttle.regions)
+}
@@ -0,0 +1,173 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/getter"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+)
+
+// resourceNodesOfTree returns nodes of the resource tree, with details of pods
+func resourceNodesOfTree(ctx context.Context, informerFactories *regioninformers.RegionInformers,
+	regionID uint, resourceTree *applicationV1alpha1.ApplicationTree) ([]ResourceNode, error) {
+	nodes := make([]ResourceNode, 0, len(resourceTree.Nodes))
+	pd, err := workload.GetAbility(GKPod)
+	if err != nil {
+		return nil, err
+	}
+	gt := getter.New(pd)
+	for _, node := range resourceTree.Nodes {
+		n := ResourceNode{ResourceNode: node}
+		if n.Kind == "Pod" {
+			var podDetail corev1.Pod
+			err = informerFactories.GetDynamicFactory(regionID,
+				func(factory dynamicinformer.DynamicSharedInformerFactory) error {
+					pods, err := gt.ListPods(&node, factory)
+					if err != nil {
+						return err
+					}
+					podDetail = pods[0]
+					return nil
+				})
+			if err != nil {
+				log.Errorf(ctx, "failed to get pod detail: %v", err)
+				continue
+			}
+			t := Compact(podDetail)
+			n.PodDetail = &t
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// stepOfTree returns the step of the first workload in the resource tree which has steps
+func stepOfTree(resourceTree *applicationV1alpha1.ApplicationTree, kubeClient *kube.Client) *Step {
+	ifContinue := true
+	step := (*workload.Step)(nil)
+	traverseResourceTree(resourceTree, func(node *ResourceTreeNode) bool {
+		if !ifContinue {
+			return ifContinue
+		}
+		workload.LoopAbilities(func(workload workload.Workload) bool {
+			if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
+				return true
+			}
+
+			gt := getter.New(workload)
+			s, err := gt.GetSteps(node.ResourceNode, kubeClient)
+			if err != nil {
+				return true
+			}
+
+			step = s
+			ifContinue = false
+			return false
+		})
+		return ifContinue
+	})
+
+	if step == nil {
+		return &Step{
+			Index:        0,
+			Total:        0,
+			Replicas:     []int{},
+			ManualPaused: false,
+			AutoPromote:  false,
+		}
+	}
+
+	return &Step{
+		Index:        step.Index,
+		Total:        step.Total,
+		Replicas:     step.Replicas,
+		ManualPaused: step.ManualPaused,
+		AutoPromote:  step.AutoPromote,
+		Strategy:     step.Strategy,
+		Phase:        step.Phase,
+		Aborted:      step.Aborted,
+		Message:      step.Message,
+		Extra:        step.Extra,
+	}
+}
+
+// treeHealthy checks whether all workloads in the resource tree are healthy
+func treeHealthy(ctx context.Context, resourceTree *applicationV1alpha1.ApplicationTree,
+	kubeClient *kube.Client) bool {
+	isHealthy := true
+	traverseResourceTree(resourceTree, func(node *ResourceTreeNode) bool {
+		if !isHealthy {
+			return false
+		}
+		workload.LoopAbilities(func(workload workload.Workload) bool {
+			if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
+				return true
+			}
+			gt := getter.New(workload)
+			nodeHealthy, err := gt.IsHealthy(node.ResourceNode, kubeClient)
+			if err != nil {
+				return true
+			}
+			log.Debugf(ctx, "[cd get status v2] node(%v) kind(%v) isHealthy(%v)", node.Name, node.Kind, nodeHealthy)
+			isHealthy = isHealthy && nodeHealthy
+			return isHealthy
+		})
+		// break if isHealthy is false
+		return isHealthy
+	})
+	return isHealthy
+}
+
+// podEventsOfTree returns events of the pod if it's in the resource nodes
+func podEventsOfTree(ctx context.Context, kubeClient *kube.Client, resourceTree []ResourceNode,
+	namespace, podName string) (events []Event, err error) {
+	for i := range resourceTree {
+		pod := resourceTree[i].PodDetail
+		if pod != nil && pod.Metadata.Namespace == namespace && pod.Metadata.Name == podName {
+			k8sEvents, err := kube.GetPodEvents(ctx, kubeClient.Basic, namespace, podName)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, event := range k8sEvents {
+				eventTimeStamp := metav1.Time{Time: event.EventTime.Time}
+				if eventTimeStamp.IsZero() {
+					eventTimeStamp = event.FirstTimestamp
+				}
+				events = append(events, Event{
+					Type:           event.Type,
+					Reason:         event.Reason,
+					Message:        event.Message,
+					Count:          event.Count,
+					EventTimestamp: eventTimeStamp,
+				})
+			}
+			return events, nil
+		}
+	}
+
+	return nil, herrors.NewErrNotFound(herrors.PodsInK8S, "pod does not exist")
+}
@@ -15,11 +15,16 @@
 package cd
 
 import (
+	"io"
+	"net/url"
+	"time"
+
 	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/remotecommand"
 
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 )
@@ -42,6 +47,13 @@ type GetResourceTreeParams struct {
 	RegionEntity *regionmodels.RegionEntity
 }
 
+type GetManifestsParams struct {
+	Environment string
+	Region      string
+	Cluster     string
+	Revision    string
+}
+
 type GetClusterStateV2Params struct {
 	Application  string
 	Environment  string
@@ -60,8 +72,10 @@ type CreateClusterParams struct {
 
 type DeployClusterParams struct {
 	Environment string
-	Cluster     string
-	Revision    string
+	// Region limits the syncs in progress at the same time
+	Region   string
+	Cluster  string
+	Revision string
 }
 
 type GetPodEventsParams struct {
@@ -79,6 +93,145 @@ type GetPodParams struct {
 	Pod          string
 }
 
+type KubeProxyParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Cluster      string
+	Namespace    string
+	// Path is relative to the cluster's namespace, such as pods/{name}/log
+	Path  string
+	Query url.Values
+}
+
+type ShellParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Cluster      string
+	Namespace    string
+	Pod          string
+	// Container defaults to the first container of the pod
+	Container string
+	Stdin     io.Reader
+	Stdout    io.Writer
+	// SizeQueue passes the size of the terminal once it's resized
+	SizeQueue remotecommand.TerminalSizeQueue
+}
+
+type ListClusterResourcesParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Cluster      string
+	Namespace    string
+	// Kind filters the resources, all managed kinds are listed if it's empty
+	Kind string
+}
+
+type GetClusterResourceParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Cluster      string
+	Namespace    string
+	Kind         string
+	Name         string
+}
+
+// ClusterResource is a kubernetes resource managed for the cluster with its live status
+type ClusterResource struct {
+	Kind      string            `json:"kind"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Summary   map[string]string `json:"summary"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+type ClusterResourceDetail struct {
+	ClusterResource
+	// YAML is the manifest of the resource, values of secrets are redacted
+	YAML string `json:"yaml"`
+}
+
+type GetAutoscalingStatusParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Cluster      string
+	Namespace    string
+}
+
+// AutoscalingStatus is the live status of the HorizontalPodAutoscaler of the cluster
+type AutoscalingStatus struct {
+	MinReplicas     int32                   `json:"minReplicas"`
+	MaxReplicas     int32                   `json:"maxReplicas"`
+	CurrentReplicas int32                   `json:"currentReplicas"`
+	DesiredReplicas int32                   `json:"desiredReplicas"`
+	LastScaleTime   *time.Time              `json:"lastScaleTime,omitempty"`
+	Metrics         []*AutoscalingMetric    `json:"metrics"`
+	Conditions      []*AutoscalingCondition `json:"conditions"`
+}
+
+// AutoscalingMetric is a metric the autoscaler scales by, utilizations are percentages such as "80%".
+// Current is empty if the metric has not been collected yet.
+type AutoscalingMetric struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	Target  string `json:"target"`
+}
+
+type AutoscalingCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type GetClusterDriftParams struct {
+	Environment string
+	Region      string
+	Cluster     string
+	// Revision is the commit of the gitops repo the desired manifests are rendered at
+	Revision string
+}
+
+const (
+	DriftStateUnchanged = "Unchanged"
+	DriftStateModified  = "Modified"
+	// DriftStateMissing means it is declared in the gitops repo but not running
+	DriftStateMissing = "Missing"
+	// DriftStateExtraneous means it is running but not declared in the gitops repo any more
+	DriftStateExtraneous = "Extraneous"
+)
+
+// ClusterDrift is the difference between the manifests declared in the gitops repo and the live state
+type ClusterDrift struct {
+	// Revision is the commit the desired manifests are rendered at
+	Revision string `json:"revision"`
+	// LiveRevision is the commit synced last
+	LiveRevision string           `json:"liveRevision"`
+	Drifted      bool             `json:"drifted"`
+	Workloads    []*WorkloadDrift `json:"workloads"`
+}
+
+type WorkloadDrift struct {
+	Kind       string            `json:"kind"`
+	Name       string            `json:"name"`
+	State      string            `json:"state"`
+	Replicas   *ValueDrift       `json:"replicas,omitempty"`
+	Containers []*ContainerDrift `json:"containers,omitempty"`
+}
+
+type ContainerDrift struct {
+	Name  string      `json:"name"`
+	State string      `json:"state"`
+	Image *ValueDrift `json:"image,omitempty"`
+	Env   []*EnvDrift `json:"env,omitempty"`
+}
+
+type EnvDrift struct {
+	Name string `json:"name"`
+	ValueDrift
+}
+
+// ValueDrift is a value differing, the side absent is empty
+type ValueDrift struct {
+	Desired string `json:"desired"`
+	Live    string `json:"live"`
+}
+
 type DeletePodsParams struct {
 	RegionEntity *regionmodels.RegionEntity
 	Namespace    string
@@ -87,6 +240,7 @@ type DeletePodsParams struct {
 
 type DeleteClusterParams struct {
 	Environment string
+	Region      string
 	Cluster     string
 }
 
@@ -109,6 +263,15 @@ type GetContainerLogParams struct {
 	TailLines    int64
 }
 
+type StreamContainerLogParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Cluster      string
+	Namespace    string
+	Pod          string
+	// LogOptions.Container defaults to the first container of the pod
+	LogOptions *corev1.PodLogOptions
+}
+
 type ExecParams struct {
 	Commands     []string
 	Environment  string
@@ -197,12 +360,18 @@ type ClusterState struct {
 }
 
 type Step struct {
-	Index        int     `json:"index"`
-	Total        int     `json:"total"`
-	Replicas     []int   `json:"replicas"`
-	ManualPaused bool    `json:"manualPaused"`
-	AutoPromote  bool    `json:"autoPromote"`
-	Extra        *string `json:"extra"`
+	Index        int   `json:"index"`
+	Total        int   `json:"total"`
+	Replicas     []int `json:"replicas"`
+	ManualPaused bool  `json:"manualPaused"`
+	AutoPromote  bool  `json:"autoPromote"`
+	// Strategy is canary or blueGreen, and empty if the workloads are released all at once
+	Strategy string `json:"strategy,omitempty"`
+	// Phase is the phase of the rollout, such as Progressing, Paused, Healthy and Degraded
+	Phase   string  `json:"phase,omitempty"`
+	Aborted bool    `json:"aborted"`
+	Message string  `json:"message,omitempty"`
+	Extra   *string `json:"extra"`
 }
 
 // ClusterVersion version information
@@ -0,0 +1,91 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/changerequest/models"
+	"github.com/horizoncd/horizon/pkg/common"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, changeRequest *models.ChangeRequest) (*models.ChangeRequest, error)
+	GetByID(ctx context.Context, id uint) (*models.ChangeRequest, error)
+	ListByCluster(ctx context.Context, clusterID uint, status string) ([]*models.ChangeRequest, error)
+	UpdateReview(ctx context.Context, changeRequest *models.ChangeRequest) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, changeRequest *models.ChangeRequest) (*models.ChangeRequest, error) {
+	result := d.db.WithContext(ctx).Create(changeRequest)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ChangeRequestInDB, result.Error.Error())
+	}
+	return changeRequest, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.ChangeRequest, error) {
+	var changeRequest models.ChangeRequest
+	result := d.db.WithContext(ctx).Raw(common.ChangeRequestGetByID, id).Scan(&changeRequest)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ChangeRequestInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ChangeRequestInDB,
+			fmt.Sprintf("no change request found for id %d", id))
+	}
+	return &changeRequest, nil
+}
+
+func (d *dao) ListByCluster(ctx context.Context, clusterID uint,
+	status string) ([]*models.ChangeRequest, error) {
+	var changeRequests []*models.ChangeRequest
+	query := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("id desc").Find(&changeRequests)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ChangeRequestInDB, result.Error.Error())
+	}
+	return changeRequests, nil
+}
+
+func (d *dao) UpdateReview(ctx context.Context, changeRequest *models.ChangeRequest) error {
+	result := d.db.WithContext(ctx).Model(changeRequest).
+		Where("status = ?", models.StatusPending).
+		Select("status", "merged_commit", "reviewed_by", "review_comment", "reviewed_at").
+		Updates(changeRequest)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ChangeRequestInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ChangeRequestInDB,
+			fmt.Sprintf("no pending change request found for id %d", changeRequest.ID))
+	}
+	return nil
+}
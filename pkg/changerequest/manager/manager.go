@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/changerequest/dao"
+	"github.com/horizoncd/horizon/pkg/changerequest/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, changeRequest *models.ChangeRequest) (*models.ChangeRequest, error)
+	GetByID(ctx context.Context, id uint) (*models.ChangeRequest, error)
+	// ListByCluster lists change requests of the cluster in the status, latest first,
+	// all change requests are listed if status is empty
+	ListByCluster(ctx context.Context, clusterID uint, status string) ([]*models.ChangeRequest, error)
+	// UpdateReview saves the review result of a pending change request,
+	// it returns not found if the change request has been reviewed
+	UpdateReview(ctx context.Context, changeRequest *models.ChangeRequest) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context,
+	changeRequest *models.ChangeRequest) (*models.ChangeRequest, error) {
+	return m.dao.Create(ctx, changeRequest)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.ChangeRequest, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByCluster(ctx context.Context, clusterID uint,
+	status string) ([]*models.ChangeRequest, error) {
+	return m.dao.ListByCluster(ctx, clusterID, status)
+}
+
+func (m *manager) UpdateReview(ctx context.Context, changeRequest *models.ChangeRequest) error {
+	return m.dao.UpdateReview(ctx, changeRequest)
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/changerequest/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ChangeRequest{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	for i := 0; i < 2; i++ {
+		_, err := mgr.Create(ctx, &models.ChangeRequest{
+			ClusterID: 1,
+			Title:     "scale out",
+			Branch:    models.BranchPrefix + "1",
+			Status:    models.StatusPending,
+			CreatedBy: 1,
+		})
+		assert.Nil(t, err)
+	}
+	_, err = mgr.Create(ctx, &models.ChangeRequest{
+		ClusterID: 2,
+		Branch:    models.BranchPrefix + "2",
+		Status:    models.StatusPending,
+		CreatedBy: 1,
+	})
+	assert.Nil(t, err)
+
+	changeRequests, err := mgr.ListByCluster(ctx, 1, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(changeRequests))
+	assert.Equal(t, uint(2), changeRequests[0].ID)
+
+	// review the change request
+	now := time.Now()
+	changeRequest := changeRequests[0]
+	changeRequest.Status = models.StatusMerged
+	changeRequest.MergedCommit = "abc"
+	changeRequest.ReviewedBy = 2
+	changeRequest.ReviewComment = "lgtm"
+	changeRequest.ReviewedAt = &now
+	assert.Nil(t, mgr.UpdateReview(ctx, changeRequest))
+
+	changeRequest, err = mgr.GetByID(ctx, changeRequest.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusMerged, changeRequest.Status)
+	assert.Equal(t, "abc", changeRequest.MergedCommit)
+	assert.Equal(t, uint(2), changeRequest.ReviewedBy)
+	assert.NotNil(t, changeRequest.ReviewedAt)
+
+	// a change request can only be reviewed once
+	changeRequest.Status = models.StatusRejected
+	err = mgr.UpdateReview(ctx, changeRequest)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	changeRequests, err = mgr.ListByCluster(ctx, 1, models.StatusPending)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(changeRequests))
+	assert.Equal(t, uint(1), changeRequests[0].ID)
+}
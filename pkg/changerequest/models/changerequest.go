@@ -0,0 +1,47 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	StatusPending  = "pending"
+	StatusMerged   = "merged"
+	StatusRejected = "rejected"
+
+	// BranchPrefix is the prefix of git branches which hold the changes of change requests
+	BranchPrefix = "change-request-"
+)
+
+// ChangeRequest is a proposal of config changes of a cluster, the changes are committed to
+// a branch of the gitops repo and are merged into the gitops branch after another person approves it
+type ChangeRequest struct {
+	ID          uint
+	ClusterID   uint `gorm:"index:idx_cluster_id_status"`
+	Title       string
+	Description string
+	// Branch holds the changes, it is deleted after the change request is reviewed
+	Branch          string
+	MergeRequestURL string
+	Status          string `gorm:"index:idx_cluster_id_status"`
+	// MergedCommit is the commit of gitops branch after the changes are merged
+	MergedCommit  string
+	ReviewedBy    uint
+	ReviewComment string
+	ReviewedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     uint
+}
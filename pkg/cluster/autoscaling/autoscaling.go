@@ -0,0 +1,129 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaling
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	// MetricTypePods is a metric describing each pod of the cluster, averaged across the pods
+	MetricTypePods = "Pods"
+	// MetricTypeExternal is a metric not related to any kubernetes object, such as a queue length
+	MetricTypeExternal = "External"
+
+	defaultMinReplicas = 1
+)
+
+// Config describes the HorizontalPodAutoscaler of a cluster.
+// It's kept in the horizon value file as .Values.horizon.autoscaling,
+// and rendered in .Values.horizon.autoscalingManifest for templates to apply.
+// Templates should leave the replicas of the workload unset when the manifest exists,
+// otherwise every deploy resets the replicas scaled by the autoscaler.
+type Config struct { //nolint:lll
+	MinReplicas int32 `json:"minReplicas" yaml:"minReplicas"`
+	// MaxReplicas is the upper limit of replicas, the autoscaling is disabled when it's 0
+	MaxReplicas int32 `json:"maxReplicas" yaml:"maxReplicas"`
+	// CPUUtilization is the target average cpu utilization of pods, in percentage of the requests
+	CPUUtilization *int32 `json:"cpuUtilization,omitempty" yaml:"cpuUtilization,omitempty"`
+	// MemoryUtilization is the target average memory utilization of pods, in percentage of the requests
+	MemoryUtilization *int32          `json:"memoryUtilization,omitempty" yaml:"memoryUtilization,omitempty"`
+	CustomMetrics     []*CustomMetric `json:"customMetrics,omitempty" yaml:"customMetrics,omitempty"`
+}
+
+// CustomMetric is a metric served by the custom or external metrics API of kubernetes
+type CustomMetric struct {
+	// Type is Pods or External
+	Type string `json:"type" yaml:"type"`
+	Name string `json:"name" yaml:"name"`
+	// Selector selects the series of the metric by labels
+	Selector map[string]string `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// TargetAverageValue is the target value of the metric averaged across pods, such as "100" or "500m"
+	TargetAverageValue string `json:"targetAverageValue" yaml:"targetAverageValue"`
+}
+
+// Enabled returns whether the cluster is autoscaled, an empty config turns the autoscaling off
+func (c *Config) Enabled() bool {
+	return c != nil && c.MaxReplicas > 0
+}
+
+// SetDefaults fills the fields which are not specified
+func (c *Config) SetDefaults() {
+	if c.Enabled() && c.MinReplicas == 0 {
+		c.MinReplicas = defaultMinReplicas
+	}
+}
+
+// Validate checks the config after defaults are set, nothing is checked if the autoscaling is disabled
+func (c *Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.MinReplicas < 1 {
+		return invalid("minReplicas must be at least 1")
+	}
+	if c.MaxReplicas < c.MinReplicas {
+		return invalid(fmt.Sprintf("maxReplicas %d must not be less than minReplicas %d",
+			c.MaxReplicas, c.MinReplicas))
+	}
+	if c.CPUUtilization == nil && c.MemoryUtilization == nil && len(c.CustomMetrics) == 0 {
+		return invalid("at least one of cpuUtilization, memoryUtilization and customMetrics must be specified")
+	}
+	if c.CPUUtilization != nil && *c.CPUUtilization < 1 {
+		return invalid("cpuUtilization must be greater than 0")
+	}
+	if c.MemoryUtilization != nil && *c.MemoryUtilization < 1 {
+		return invalid("memoryUtilization must be greater than 0")
+	}
+
+	metrics := make(map[string]bool)
+	for i, metric := range c.CustomMetrics {
+		field := fmt.Sprintf("customMetrics[%d]", i)
+		if metric == nil {
+			return invalid(fmt.Sprintf("%s must not be empty", field))
+		}
+		switch metric.Type {
+		case MetricTypePods, MetricTypeExternal:
+		default:
+			return invalid(fmt.Sprintf("%s.type must be %s or %s", field, MetricTypePods, MetricTypeExternal))
+		}
+		if metric.Name == "" {
+			return invalid(fmt.Sprintf("%s.name is required", field))
+		}
+		key := metric.Type + "/" + metric.Name
+		if metrics[key] {
+			return invalid(fmt.Sprintf("%s %s metric %s is duplicated", field, metric.Type, metric.Name))
+		}
+		metrics[key] = true
+		value, err := resource.ParseQuantity(metric.TargetAverageValue)
+		if err != nil {
+			return invalid(fmt.Sprintf("%s.targetAverageValue must be a quantity, but got %q",
+				field, metric.TargetAverageValue))
+		}
+		if value.Sign() <= 0 {
+			return invalid(fmt.Sprintf("%s.targetAverageValue must be greater than 0", field))
+		}
+	}
+	return nil
+}
+
+func invalid(msg string) error {
+	return perror.Wrap(herrors.ErrParamInvalid, msg)
+}
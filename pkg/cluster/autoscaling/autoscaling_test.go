@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestSetDefaults(t *testing.T) {
+	c := &Config{MaxReplicas: 3}
+	c.SetDefaults()
+	assert.Equal(t, int32(1), c.MinReplicas)
+
+	c = &Config{}
+	c.SetDefaults()
+	assert.False(t, c.Enabled())
+	assert.Equal(t, int32(0), c.MinReplicas)
+}
+
+func TestValidate(t *testing.T) {
+	var disabled *Config
+	assert.Nil(t, disabled.Validate())
+	assert.Nil(t, (&Config{}).Validate())
+
+	valid := []*Config{
+		{MinReplicas: 1, MaxReplicas: 1, CPUUtilization: int32Ptr(80)},
+		{MinReplicas: 2, MaxReplicas: 10, CPUUtilization: int32Ptr(80), MemoryUtilization: int32Ptr(120)},
+		{MinReplicas: 1, MaxReplicas: 3, CustomMetrics: []*CustomMetric{
+			{Type: MetricTypePods, Name: "qps", TargetAverageValue: "100"},
+			{Type: MetricTypeExternal, Name: "queue_length", Selector: map[string]string{"queue": "demo"},
+				TargetAverageValue: "500m"},
+		}},
+	}
+	for _, c := range valid {
+		assert.Nil(t, c.Validate())
+	}
+
+	invalidConfigs := []*Config{
+		{MinReplicas: 0, MaxReplicas: 3, CPUUtilization: int32Ptr(80)},
+		{MinReplicas: 4, MaxReplicas: 3, CPUUtilization: int32Ptr(80)},
+		{MinReplicas: 1, MaxReplicas: 3},
+		{MinReplicas: 1, MaxReplicas: 3, CPUUtilization: int32Ptr(0)},
+		{MinReplicas: 1, MaxReplicas: 3, MemoryUtilization: int32Ptr(-1)},
+		{MinReplicas: 1, MaxReplicas: 3, CustomMetrics: []*CustomMetric{nil}},
+		{MinReplicas: 1, MaxReplicas: 3, CustomMetrics: []*CustomMetric{
+			{Type: "Object", Name: "qps", TargetAverageValue: "100"}}},
+		{MinReplicas: 1, MaxReplicas: 3, CustomMetrics: []*CustomMetric{
+			{Type: MetricTypePods, TargetAverageValue: "100"}}},
+		{MinReplicas: 1, MaxReplicas: 3, CustomMetrics: []*CustomMetric{
+			{Type: MetricTypePods, Name: "qps", TargetAverageValue: "a lot"}}},
+		{MinReplicas: 1, MaxReplicas: 3, CustomMetrics: []*CustomMetric{
+			{Type: MetricTypePods, Name: "qps", TargetAverageValue: "0"}}},
+		{MinReplicas: 1, MaxReplicas: 3, CustomMetrics: []*CustomMetric{
+			{Type: MetricTypePods, Name: "qps", TargetAverageValue: "100"},
+			{Type: MetricTypePods, Name: "qps", TargetAverageValue: "200"}}},
+	}
+	for _, c := range invalidConfigs {
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaling
+
+import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/horizoncd/horizon/core/common"
+)
+
+// hpaAPIVersion is autoscaling/v2 because autoscaling/v2beta2 is removed in kubernetes 1.26,
+// the spec of both versions are the same so the types of autoscaling/v2beta2 are used
+const hpaAPIVersion = "autoscaling/v2"
+
+// RenderHorizontalPodAutoscaler returns the HorizontalPodAutoscaler scaling the workload of the cluster,
+// which is the argo Rollout if the cluster is deployed step by step, or the Deployment otherwise.
+// Nil is returned if the autoscaling is disabled.
+func RenderHorizontalPodAutoscaler(c *Config, cluster string,
+	stepped bool) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	if !c.Enabled() {
+		return nil
+	}
+	target := autoscalingv2beta2.CrossVersionObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       cluster,
+	}
+	if stepped {
+		target.APIVersion = "argoproj.io/v1alpha1"
+		target.Kind = "Rollout"
+	}
+	minReplicas := c.MinReplicas
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: hpaAPIVersion,
+			Kind:       "HorizontalPodAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   cluster,
+			Labels: map[string]string{common.ClusterClusterLabelKey: cluster},
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: target,
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    c.MaxReplicas,
+		},
+	}
+	if c.CPUUtilization != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, resourceMetric(corev1.ResourceCPU, *c.CPUUtilization))
+	}
+	if c.MemoryUtilization != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, resourceMetric(corev1.ResourceMemory, *c.MemoryUtilization))
+	}
+	for _, metric := range c.CustomMetrics {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, customMetric(metric))
+	}
+	return hpa
+}
+
+func resourceMetric(name corev1.ResourceName, utilization int32) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
+
+// customMetric renders the metric validated before, so the target value is always a quantity
+func customMetric(metric *CustomMetric) autoscalingv2beta2.MetricSpec {
+	identifier := autoscalingv2beta2.MetricIdentifier{Name: metric.Name}
+	if len(metric.Selector) > 0 {
+		identifier.Selector = &metav1.LabelSelector{MatchLabels: metric.Selector}
+	}
+	value := resource.MustParse(metric.TargetAverageValue)
+	target := autoscalingv2beta2.MetricTarget{
+		Type:         autoscalingv2beta2.AverageValueMetricType,
+		AverageValue: &value,
+	}
+	if metric.Type == MetricTypeExternal {
+		return autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ExternalMetricSourceType,
+			External: &autoscalingv2beta2.ExternalMetricSource{
+				Metric: identifier,
+				Target: target,
+			},
+		}
+	}
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.PodsMetricSourceType,
+		Pods: &autoscalingv2beta2.PodsMetricSource{
+			Metric: identifier,
+			Target: target,
+		},
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/horizoncd/horizon/core/common"
+)
+
+func TestRender(t *testing.T) {
+	assert.Nil(t, RenderHorizontalPodAutoscaler(nil, "demo", false))
+	assert.Nil(t, RenderHorizontalPodAutoscaler(&Config{}, "demo", false))
+
+	c := &Config{
+		MinReplicas:    2,
+		MaxReplicas:    10,
+		CPUUtilization: int32Ptr(80),
+		CustomMetrics: []*CustomMetric{
+			{Type: MetricTypePods, Name: "qps", TargetAverageValue: "100"},
+			{Type: MetricTypeExternal, Name: "queue_length", Selector: map[string]string{"queue": "demo"},
+				TargetAverageValue: "30"},
+		},
+	}
+	hpa := RenderHorizontalPodAutoscaler(c, "demo", false)
+	assert.Equal(t, "autoscaling/v2", hpa.APIVersion)
+	assert.Equal(t, "demo", hpa.Name)
+	assert.Equal(t, "demo", hpa.Labels[common.ClusterClusterLabelKey])
+	assert.Equal(t, "Deployment", hpa.Spec.ScaleTargetRef.Kind)
+	assert.Equal(t, "demo", hpa.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+	assert.Equal(t, 3, len(hpa.Spec.Metrics))
+
+	cpu := hpa.Spec.Metrics[0]
+	assert.Equal(t, autoscalingv2beta2.ResourceMetricSourceType, cpu.Type)
+	assert.Equal(t, corev1.ResourceCPU, cpu.Resource.Name)
+	assert.Equal(t, int32(80), *cpu.Resource.Target.AverageUtilization)
+
+	pods := hpa.Spec.Metrics[1]
+	assert.Equal(t, autoscalingv2beta2.PodsMetricSourceType, pods.Type)
+	assert.Equal(t, "qps", pods.Pods.Metric.Name)
+	assert.Nil(t, pods.Pods.Metric.Selector)
+	assert.Equal(t, "100", pods.Pods.Target.AverageValue.String())
+
+	external := hpa.Spec.Metrics[2]
+	assert.Equal(t, autoscalingv2beta2.ExternalMetricSourceType, external.Type)
+	assert.Equal(t, "demo", external.External.Metric.Selector.MatchLabels["queue"])
+	assert.Equal(t, "30", external.External.Target.AverageValue.String())
+
+	hpa = RenderHorizontalPodAutoscaler(c, "demo", true)
+	assert.Equal(t, "Rollout", hpa.Spec.ScaleTargetRef.Kind)
+	assert.Equal(t, "argoproj.io/v1alpha1", hpa.Spec.ScaleTargetRef.APIVersion)
+}
@@ -0,0 +1,200 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	WhenUnsatisfiableDoNotSchedule  = "DoNotSchedule"
+	WhenUnsatisfiableScheduleAnyway = "ScheduleAnyway"
+
+	TopologyKeyZone     = "topology.kubernetes.io/zone"
+	TopologyKeyHostname = "kubernetes.io/hostname"
+
+	defaultMaxSkew = 1
+)
+
+var intOrPercentPattern = regexp.MustCompile(`^(\d+)(%?)$`)
+
+// Config describes the availability requirements of the pods of a cluster.
+// It's kept in the horizon value file as .Values.horizon.availability,
+// and rendered in .Values.horizon.availabilityManifest for templates to apply.
+type Config struct { //nolint:lll
+	PodDisruptionBudget       *PodDisruptionBudget        `json:"podDisruptionBudget,omitempty" yaml:"podDisruptionBudget,omitempty"`
+	TopologySpreadConstraints []*TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty" yaml:"topologySpreadConstraints,omitempty"`
+}
+
+// PodDisruptionBudget limits the pods evicted at the same time, such as by node drains.
+// Values are absolute numbers or percentages, such as "1" or "50%", only one of them can be specified.
+type PodDisruptionBudget struct {
+	MinAvailable   string `json:"minAvailable,omitempty" yaml:"minAvailable,omitempty"`
+	MaxUnavailable string `json:"maxUnavailable,omitempty" yaml:"maxUnavailable,omitempty"`
+}
+
+// TopologySpreadConstraint spreads the pods of the cluster across the domains of the topology key
+type TopologySpreadConstraint struct {
+	// TopologyKey is a node label, such as topology.kubernetes.io/zone
+	TopologyKey string `json:"topologyKey" yaml:"topologyKey"`
+	// MaxSkew is the max difference of pod counts between domains, defaults to 1
+	MaxSkew int32 `json:"maxSkew" yaml:"maxSkew"`
+	// WhenUnsatisfiable is DoNotSchedule or ScheduleAnyway, defaults to DoNotSchedule
+	WhenUnsatisfiable string `json:"whenUnsatisfiable" yaml:"whenUnsatisfiable"`
+}
+
+// SetDefaults fills the fields of spread constraints which are not specified
+func (c *Config) SetDefaults() {
+	for _, constraint := range c.TopologySpreadConstraints {
+		if constraint == nil {
+			continue
+		}
+		if constraint.MaxSkew == 0 {
+			constraint.MaxSkew = defaultMaxSkew
+		}
+		if constraint.WhenUnsatisfiable == "" {
+			constraint.WhenUnsatisfiable = WhenUnsatisfiableDoNotSchedule
+		}
+	}
+}
+
+// Validate checks the config after defaults are set, the rules follow what kubernetes accepts
+func (c *Config) Validate() error {
+	if pdb := c.PodDisruptionBudget; pdb != nil {
+		if (pdb.MinAvailable == "") == (pdb.MaxUnavailable == "") {
+			return invalid("exactly one of podDisruptionBudget.minAvailable and " +
+				"podDisruptionBudget.maxUnavailable must be specified")
+		}
+		if pdb.MinAvailable != "" {
+			if _, _, err := parseIntOrPercent("podDisruptionBudget.minAvailable", pdb.MinAvailable); err != nil {
+				return err
+			}
+		}
+		if pdb.MaxUnavailable != "" {
+			n, _, err := parseIntOrPercent("podDisruptionBudget.maxUnavailable", pdb.MaxUnavailable)
+			if err != nil {
+				return err
+			}
+			// no pod could ever be evicted, which blocks node drains forever
+			if n == 0 {
+				return invalid("podDisruptionBudget.maxUnavailable must be greater than 0")
+			}
+		}
+	}
+
+	topologyKeys := make(map[string]bool)
+	for i, constraint := range c.TopologySpreadConstraints {
+		field := fmt.Sprintf("topologySpreadConstraints[%d]", i)
+		if constraint == nil {
+			return invalid(fmt.Sprintf("%s must not be empty", field))
+		}
+		if constraint.TopologyKey == "" {
+			return invalid(fmt.Sprintf("%s.topologyKey is required", field))
+		}
+		if topologyKeys[constraint.TopologyKey] {
+			return invalid(fmt.Sprintf("%s.topologyKey %s is duplicated", field, constraint.TopologyKey))
+		}
+		topologyKeys[constraint.TopologyKey] = true
+		if constraint.MaxSkew < 1 {
+			return invalid(fmt.Sprintf("%s.maxSkew must be at least 1", field))
+		}
+		switch constraint.WhenUnsatisfiable {
+		case WhenUnsatisfiableDoNotSchedule, WhenUnsatisfiableScheduleAnyway:
+		default:
+			return invalid(fmt.Sprintf("%s.whenUnsatisfiable must be %s or %s",
+				field, WhenUnsatisfiableDoNotSchedule, WhenUnsatisfiableScheduleAnyway))
+		}
+	}
+	return nil
+}
+
+// CheckReplicas makes sure the pod disruption budget still allows evictions with the replicas,
+// otherwise nodes running the pods can never be drained
+func (c *Config) CheckReplicas(replicas int) error {
+	pdb := c.PodDisruptionBudget
+	if pdb == nil || pdb.MinAvailable == "" {
+		return nil
+	}
+	n, percent, err := parseIntOrPercent("podDisruptionBudget.minAvailable", pdb.MinAvailable)
+	if err != nil {
+		return err
+	}
+	minAvailable := n
+	if percent {
+		// kubernetes rounds percentages of minAvailable up
+		minAvailable = int(math.Ceil(float64(replicas) * float64(n) / 100))
+	}
+	if minAvailable >= replicas {
+		return invalid(fmt.Sprintf("podDisruptionBudget.minAvailable %s must allow at least one of %d replicas "+
+			"to be evicted", pdb.MinAvailable, replicas))
+	}
+	return nil
+}
+
+// ReplicasOf finds the replicas in the template config, which is spec.replicas under the
+// template's root key by convention, such as app.spec.replicas. False is returned if not found.
+func ReplicasOf(templateConfig map[string]interface{}) (int, bool) {
+	for _, value := range templateConfig {
+		root, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		spec, ok := root["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch replicas := spec["replicas"].(type) {
+		case float64:
+			return int(replicas), true
+		case int:
+			return replicas, true
+		case int64:
+			return int(replicas), true
+		case json.Number:
+			if n, err := replicas.Int64(); err == nil {
+				return int(n), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// parseIntOrPercent returns the number of an absolute value or a percentage, and whether it's a percentage
+func parseIntOrPercent(field, value string) (int, bool, error) {
+	matches := intOrPercentPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, false, invalid(fmt.Sprintf("%s must be a number or a percentage, but got %q", field, value))
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, false, invalid(fmt.Sprintf("%s is invalid: %v", field, err))
+	}
+	percent := matches[2] == "%"
+	if percent && n > 100 {
+		return 0, false, invalid(fmt.Sprintf("%s must not be greater than 100%%", field))
+	}
+	return n, percent, nil
+}
+
+func invalid(msg string) error {
+	return perror.Wrap(herrors.ErrParamInvalid, msg)
+}
@@ -0,0 +1,114 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestSetDefaults(t *testing.T) {
+	c := &Config{
+		TopologySpreadConstraints: []*TopologySpreadConstraint{{TopologyKey: TopologyKeyZone}},
+	}
+	c.SetDefaults()
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, int32(1), c.TopologySpreadConstraints[0].MaxSkew)
+	assert.Equal(t, WhenUnsatisfiableDoNotSchedule, c.TopologySpreadConstraints[0].WhenUnsatisfiable)
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]*Config{
+		"both minAvailable and maxUnavailable": {
+			PodDisruptionBudget: &PodDisruptionBudget{MinAvailable: "1", MaxUnavailable: "1"},
+		},
+		"neither minAvailable nor maxUnavailable": {
+			PodDisruptionBudget: &PodDisruptionBudget{},
+		},
+		"invalid minAvailable": {
+			PodDisruptionBudget: &PodDisruptionBudget{MinAvailable: "half"},
+		},
+		"percentage over 100": {
+			PodDisruptionBudget: &PodDisruptionBudget{MinAvailable: "120%"},
+		},
+		"zero maxUnavailable": {
+			PodDisruptionBudget: &PodDisruptionBudget{MaxUnavailable: "0%"},
+		},
+		"no topology key": {
+			TopologySpreadConstraints: []*TopologySpreadConstraint{{}},
+		},
+		"duplicated topology key": {
+			TopologySpreadConstraints: []*TopologySpreadConstraint{
+				{TopologyKey: TopologyKeyHostname}, {TopologyKey: TopologyKeyHostname},
+			},
+		},
+		"negative max skew": {
+			TopologySpreadConstraints: []*TopologySpreadConstraint{{TopologyKey: TopologyKeyZone, MaxSkew: -1}},
+		},
+		"unknown whenUnsatisfiable": {
+			TopologySpreadConstraints: []*TopologySpreadConstraint{
+				{TopologyKey: TopologyKeyZone, WhenUnsatisfiable: "Never"},
+			},
+		},
+	}
+	for name, c := range cases {
+		c.SetDefaults()
+		err := c.Validate()
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), name)
+	}
+}
+
+func TestCheckReplicas(t *testing.T) {
+	cases := []struct {
+		pdb      *PodDisruptionBudget
+		replicas int
+		valid    bool
+	}{
+		{nil, 1, true},
+		{&PodDisruptionBudget{MaxUnavailable: "1"}, 1, true},
+		{&PodDisruptionBudget{MinAvailable: "1"}, 2, true},
+		{&PodDisruptionBudget{MinAvailable: "2"}, 2, false},
+		{&PodDisruptionBudget{MinAvailable: "50%"}, 3, true},
+		// 75% of 3 replicas is rounded up to 3
+		{&PodDisruptionBudget{MinAvailable: "75%"}, 3, false},
+		{&PodDisruptionBudget{MinAvailable: "50%"}, 1, false},
+	}
+	for i, tc := range cases {
+		err := (&Config{PodDisruptionBudget: tc.pdb}).CheckReplicas(tc.replicas)
+		if tc.valid {
+			assert.Nil(t, err, i)
+		} else {
+			assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), i)
+		}
+	}
+}
+
+func TestReplicasOf(t *testing.T) {
+	var templateConfig map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"app":{"spec":{"replicas":3,"resource":"small"}}}`), &templateConfig))
+	replicas, ok := ReplicasOf(templateConfig)
+	assert.True(t, ok)
+	assert.Equal(t, 3, replicas)
+
+	_, ok = ReplicasOf(map[string]interface{}{"app": map[string]interface{}{"params": "x"}})
+	assert.False(t, ok)
+	_, ok = ReplicasOf(nil)
+	assert.False(t, ok)
+}
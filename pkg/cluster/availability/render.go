@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	"github.com/horizoncd/horizon/core/common"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// pdbAPIVersion is policy/v1 because policy/v1beta1 is removed in kubernetes 1.25,
+// the spec of both versions are the same so the types of policy/v1beta1 are used
+const pdbAPIVersion = "policy/v1"
+
+// RenderPodDisruptionBudget returns the PodDisruptionBudget selecting pods of the cluster,
+// nil is returned if the config does not specify one
+func RenderPodDisruptionBudget(c *Config, cluster string) *policyv1beta1.PodDisruptionBudget {
+	if c == nil || c.PodDisruptionBudget == nil {
+		return nil
+	}
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: pdbAPIVersion,
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cluster,
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: clusterSelector(cluster),
+		},
+	}
+	if c.PodDisruptionBudget.MinAvailable != "" {
+		minAvailable := intstr.Parse(c.PodDisruptionBudget.MinAvailable)
+		pdb.Spec.MinAvailable = &minAvailable
+	}
+	if c.PodDisruptionBudget.MaxUnavailable != "" {
+		maxUnavailable := intstr.Parse(c.PodDisruptionBudget.MaxUnavailable)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	return pdb
+}
+
+// RenderTopologySpreadConstraints returns the constraints for pod specs of the cluster
+func RenderTopologySpreadConstraints(c *Config, cluster string) []corev1.TopologySpreadConstraint {
+	if c == nil || len(c.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+	ret := make([]corev1.TopologySpreadConstraint, 0, len(c.TopologySpreadConstraints))
+	for _, constraint := range c.TopologySpreadConstraints {
+		ret = append(ret, corev1.TopologySpreadConstraint{
+			MaxSkew:           constraint.MaxSkew,
+			TopologyKey:       constraint.TopologyKey,
+			WhenUnsatisfiable: corev1.UnsatisfiableConstraintAction(constraint.WhenUnsatisfiable),
+			LabelSelector:     clusterSelector(cluster),
+		})
+	}
+	return ret
+}
+
+func clusterSelector(cluster string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{common.ClusterClusterLabelKey: cluster},
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/horizoncd/horizon/core/common"
+)
+
+func TestRender(t *testing.T) {
+	assert.Nil(t, RenderPodDisruptionBudget(nil, "demo"))
+	assert.Nil(t, RenderTopologySpreadConstraints(&Config{}, "demo"))
+
+	c := &Config{
+		PodDisruptionBudget: &PodDisruptionBudget{MinAvailable: "50%"},
+		TopologySpreadConstraints: []*TopologySpreadConstraint{
+			{TopologyKey: TopologyKeyZone, MaxSkew: 1, WhenUnsatisfiable: WhenUnsatisfiableScheduleAnyway},
+		},
+	}
+	pdb := RenderPodDisruptionBudget(c, "demo")
+	assert.Equal(t, "policy/v1", pdb.APIVersion)
+	assert.Equal(t, "demo", pdb.Name)
+	assert.Equal(t, "demo", pdb.Spec.Selector.MatchLabels[common.ClusterClusterLabelKey])
+	assert.Equal(t, "50%", pdb.Spec.MinAvailable.String())
+	assert.Nil(t, pdb.Spec.MaxUnavailable)
+
+	c.PodDisruptionBudget = &PodDisruptionBudget{MaxUnavailable: "1"}
+	pdb = RenderPodDisruptionBudget(c, "demo")
+	assert.Nil(t, pdb.Spec.MinAvailable)
+	assert.Equal(t, 1, pdb.Spec.MaxUnavailable.IntValue())
+
+	constraints := RenderTopologySpreadConstraints(c, "demo")
+	assert.Equal(t, 1, len(constraints))
+	assert.Equal(t, TopologyKeyZone, constraints[0].TopologyKey)
+	assert.Equal(t, corev1.ScheduleAnyway, constraints[0].WhenUnsatisfiable)
+	assert.Equal(t, "demo", constraints[0].LabelSelector.MatchLabels[common.ClusterClusterLabelKey])
+}
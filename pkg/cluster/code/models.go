@@ -22,7 +22,7 @@ const (
 
 // Git struct about git
 type Git struct {
-	URL       string `json:"url"`
+	URL       string `json:"url" binding:"omitempty,giturl"`
 	Subfolder string `json:"subfolder"`
 	Branch    string `json:"branch,omitempty"`
 	Tag       string `json:"tag,omitempty"`
@@ -29,6 +29,7 @@ import (
 	sqlcommon "github.com/horizoncd/horizon/pkg/common"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	tagdao "github.com/horizoncd/horizon/pkg/tag/dao"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 
@@ -42,11 +43,25 @@ type DAO interface {
 	GetByName(ctx context.Context, clusterName string) (*models.Cluster, error)
 	UpdateByID(ctx context.Context, id uint, cluster *models.Cluster) (*models.Cluster, error)
 	DeleteByID(ctx context.Context, id uint) error
+	// HardDeleteByID deletes a cluster and marks it as not restorable
+	HardDeleteByID(ctx context.Context, id uint) error
 	CheckClusterExists(ctx context.Context, cluster string) (bool, error)
 	List(ctx context.Context, query *q.Query, userID uint,
 		withRegion bool, appIDs ...uint) (int, []*models.ClusterWithRegion, error)
 	ListClusterWithExpiry(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
 	GetByNameFuzzily(ctx context.Context, name string, includeSoftDelete bool) ([]*models.Cluster, error)
+	// GetDeletedByName get soft deleted clusters with the given name
+	GetDeletedByName(ctx context.Context, name string) ([]*models.Cluster, error)
+	// ListDeletedByApplicationID list soft deleted clusters of the given application, latest deleted first
+	ListDeletedByApplicationID(ctx context.Context, applicationID uint) ([]*models.Cluster, error)
+	// ListDeletedBefore list at most limit clusters soft deleted before the given time,
+	// whose id is greater than afterID
+	ListDeletedBefore(ctx context.Context, before time.Time, afterID uint,
+		limit int) ([]*models.Cluster, error)
+	// RestoreByID restore a soft deleted cluster with the given status
+	RestoreByID(ctx context.Context, id uint, status string) error
+	// PurgeByID remove a soft deleted cluster from db permanently
+	PurgeByID(ctx context.Context, id uint) error
 }
 
 type dao struct {
@@ -204,12 +219,20 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, cluster *models.Cluster)
 }
 
 func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	return d.deleteByID(ctx, sqlcommon.ClusterDeleteByID, id)
+}
+
+func (d *dao) HardDeleteByID(ctx context.Context, id uint) error {
+	return d.deleteByID(ctx, sqlcommon.ClusterHardDeleteByID, id)
+}
+
+func (d *dao) deleteByID(ctx context.Context, sql string, id uint) error {
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	result := d.db.WithContext(ctx).Exec(sqlcommon.ClusterDeleteByID, time.Now().Unix(), currentUser.GetID(), id)
+	result := d.db.WithContext(ctx).Exec(sql, time.Now().Unix(), currentUser.GetID(), id)
 
 	if result.Error != nil {
 		return herrors.NewErrDeleteFailed(herrors.ClusterInDB, result.Error.Error())
@@ -218,6 +241,61 @@ func (d *dao) DeleteByID(ctx context.Context, id uint) error {
 	return nil
 }
 
+func (d *dao) GetDeletedByName(ctx context.Context, name string) ([]*models.Cluster, error) {
+	var clusters []*models.Cluster
+	result := d.db.WithContext(ctx).Raw(sqlcommon.ClusterQueryDeletedByName, name).Scan(&clusters)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
+
+func (d *dao) ListDeletedByApplicationID(ctx context.Context, applicationID uint) ([]*models.Cluster, error) {
+	var clusters []*models.Cluster
+	result := d.db.WithContext(ctx).Raw(sqlcommon.ClusterQueryDeletedByApplicationID, applicationID).Scan(&clusters)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
+
+func (d *dao) ListDeletedBefore(ctx context.Context, before time.Time, afterID uint,
+	limit int) ([]*models.Cluster, error) {
+	var clusters []*models.Cluster
+	result := d.db.WithContext(ctx).Raw(sqlcommon.ClusterQueryDeletedBefore, before.Unix(), afterID, limit).Scan(&clusters)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
+
+func (d *dao) RestoreByID(ctx context.Context, id uint, status string) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := d.db.WithContext(ctx).Exec(sqlcommon.ClusterRestoreByID, status, currentUser.GetID(), id)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ClusterInDB, "deleted cluster not found")
+	}
+	return nil
+}
+
+func (d *dao) PurgeByID(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Exec(sqlcommon.ClusterPurgeByID, id)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.ClusterInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ClusterInDB, "deleted cluster not found")
+	}
+	return nil
+}
+
 func (d *dao) CheckClusterExists(ctx context.Context, cluster string) (bool, error) {
 	var c models.Cluster
 	result := d.db.WithContext(ctx).Raw(sqlcommon.ClusterQueryByClusterName, cluster).Scan(&c)
@@ -258,7 +336,7 @@ func (d *dao) List(ctx context.Context, query *q.Query, userID uint,
 			case common.ClusterQueryTagSelector:
 				if tagSelectors, ok := v.([]tagmodels.TagSelector); ok {
 					statementSubQuery := d.db.WithContext(ctx)
-					statementSubQuery = GenSQLForTagSelector(tagSelectors, statementSubQuery)
+					statementSubQuery = tagdao.GenSQLForTagSelector(common.ResourceCluster, tagSelectors, statementSubQuery)
 					statement.Where("c.id in (?)", statementSubQuery)
 				}
 			case common.ClusterQueryEnvironment:
@@ -348,37 +426,6 @@ func (d *dao) List(ctx context.Context, query *q.Query, userID uint,
 	return int(total), clusters, nil
 }
 
-func GenSQLForTagSelector(tagSelectors []tagmodels.TagSelector, statement *gorm.DB) *gorm.DB {
-	condition := statement.WithContext(context.Background())
-	statement = statement.Table("tb_tag as tg").
-		Select("tg.resource_id").
-		Where("tg.resource_type = ?", common.ResourceCluster).
-		Group("tg.resource_id").
-		Having("count(tg.id) > ?", len(tagSelectors)-1)
-
-	for i, tag := range tagSelectors {
-		switch tag.Operator {
-		case tagmodels.Equals:
-			value, exist := tag.Values.PopAny()
-			if exist {
-				if i == 0 {
-					statement.Where(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value = ?", value))
-				} else {
-					statement.Or(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value = ?", value))
-				}
-			}
-		case tagmodels.In:
-			if i == 0 {
-				statement.Where(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value in ?", tag.Values.List()))
-			} else {
-				statement.Or(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value in ?", tag.Values.List()))
-			}
-		}
-	}
-
-	return statement
-}
-
 func (d *dao) ListClusterWithExpiry(ctx context.Context,
 	query *q.Query) ([]*models.Cluster, error) {
 	var clusters []*models.Cluster
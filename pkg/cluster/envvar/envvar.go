@@ -0,0 +1,185 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envvar
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+	TypeJSON   = "json"
+
+	// MaskedValue replaces values of secret variables in responses
+	MaskedValue = "******"
+
+	_appKey  = "app"
+	_envsKey = "envs"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvVar is an environment variable of a cluster. It's kept in the template config as an item of
+// app.envs by convention, the type and the secret mark are kept along with the name and the value.
+// Values are always strings in the container, Type only restricts what the value can be.
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Type is string, number, bool or json, defaults to string
+	Type string `json:"type,omitempty"`
+	// Secret marks the value as sensitive, it's masked in responses and diffs
+	Secret bool `json:"secret,omitempty"`
+}
+
+// SetDefaults fills the fields which are not specified
+func (e *EnvVar) SetDefaults() {
+	if e.Type == "" {
+		e.Type = TypeString
+	}
+}
+
+// Validate checks the variable after defaults are set
+func (e *EnvVar) Validate() error {
+	if !namePattern.MatchString(e.Name) {
+		return invalid(fmt.Sprintf("env name %q must consist of letters, digits and '_', "+
+			"and must not start with a digit", e.Name))
+	}
+	switch e.Type {
+	case TypeString:
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(e.Value, 64); err != nil {
+			return invalid(fmt.Sprintf("value of env %s is not a number", e.Name))
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(e.Value); err != nil {
+			return invalid(fmt.Sprintf("value of env %s is not a bool", e.Name))
+		}
+	case TypeJSON:
+		if !json.Valid([]byte(e.Value)) {
+			return invalid(fmt.Sprintf("value of env %s is not a valid json", e.Name))
+		}
+	default:
+		return invalid(fmt.Sprintf("type of env %s must be one of %s, %s, %s and %s",
+			e.Name, TypeString, TypeNumber, TypeBool, TypeJSON))
+	}
+	return nil
+}
+
+// Masked returns a copy of the variable whose value is masked if it's a secret
+func (e *EnvVar) Masked() *EnvVar {
+	masked := *e
+	if masked.Secret {
+		masked.Value = MaskedValue
+	}
+	return &masked
+}
+
+// List reads the variables in app.envs of the template config
+func List(templateConfig map[string]interface{}) ([]*EnvVar, error) {
+	app, ok := templateConfig[_appKey].(map[string]interface{})
+	if !ok {
+		return []*EnvVar{}, nil
+	}
+	var items []interface{}
+	switch envs := app[_envsKey].(type) {
+	case nil:
+	case []interface{}:
+		items = envs
+	case []map[string]interface{}:
+		for _, env := range envs {
+			items = append(items, env)
+		}
+	default:
+		return nil, invalid("app.envs of template config is not a list")
+	}
+
+	envs := make([]*EnvVar, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, invalid(fmt.Sprintf("app.envs[%d] of template config is not an object", i))
+		}
+		env := &EnvVar{}
+		env.Name, _ = m["name"].(string)
+		env.Value = toString(m["value"])
+		env.Type, _ = m["type"].(string)
+		env.Secret, _ = m["secret"].(bool)
+		env.SetDefaults()
+		envs = append(envs, env)
+	}
+	return envs, nil
+}
+
+// Set writes the variables to app.envs of the template config, fields with default values are omitted
+func Set(templateConfig map[string]interface{}, envs []*EnvVar) {
+	app, ok := templateConfig[_appKey].(map[string]interface{})
+	if !ok {
+		app = make(map[string]interface{})
+		templateConfig[_appKey] = app
+	}
+	items := make([]interface{}, 0, len(envs))
+	for _, env := range envs {
+		item := map[string]interface{}{
+			"name":  env.Name,
+			"value": env.Value,
+		}
+		if env.Type != "" && env.Type != TypeString {
+			item["type"] = env.Type
+		}
+		if env.Secret {
+			item["secret"] = true
+		}
+		items = append(items, item)
+	}
+	app[_envsKey] = items
+}
+
+// IndexOf returns the index of the variable with the name, or -1 if not found
+func IndexOf(envs []*EnvVar, name string) int {
+	for i, env := range envs {
+		if env.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// toString converts values of old configs, such as numbers converted from params, to strings
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(b)
+	}
+}
+
+func invalid(msg string) error {
+	return perror.Wrap(herrors.ErrParamInvalid, msg)
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envvar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []*EnvVar{
+		{Name: "JAVA_OPTS", Value: "-Xmx1g"},
+		{Name: "_PORT", Value: "8080", Type: TypeNumber},
+		{Name: "RATIO", Value: "0.5", Type: TypeNumber},
+		{Name: "DEBUG", Value: "true", Type: TypeBool},
+		{Name: "FEATURES", Value: `{"a":1}`, Type: TypeJSON, Secret: true},
+	}
+	for _, env := range valid {
+		env.SetDefaults()
+		assert.Nil(t, env.Validate(), env.Name)
+	}
+
+	invalid := []*EnvVar{
+		{Name: "", Value: "a"},
+		{Name: "1PORT", Value: "a"},
+		{Name: "MY-VAR", Value: "a"},
+		{Name: "PORT", Value: "abc", Type: TypeNumber},
+		{Name: "DEBUG", Value: "yes please", Type: TypeBool},
+		{Name: "FEATURES", Value: "{", Type: TypeJSON},
+		{Name: "VAR", Value: "a", Type: "list"},
+	}
+	for _, env := range invalid {
+		env.SetDefaults()
+		err := env.Validate()
+		assert.NotNil(t, err, env.Name)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+}
+
+func TestListAndSet(t *testing.T) {
+	templateConfig := map[string]interface{}{
+		"app": map[string]interface{}{
+			"envs": []interface{}{
+				map[string]interface{}{"name": "JAVA_OPTS", "value": "-Xmx1g"},
+				map[string]interface{}{"name": "PORT", "value": float64(8080)},
+				map[string]interface{}{"name": "DSN", "value": "mysql://", "secret": true},
+			},
+			"spec": map[string]interface{}{"replicas": float64(1)},
+		},
+	}
+	envs, err := List(templateConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, []*EnvVar{
+		{Name: "JAVA_OPTS", Value: "-Xmx1g", Type: TypeString},
+		{Name: "PORT", Value: "8080", Type: TypeString},
+		{Name: "DSN", Value: "mysql://", Type: TypeString, Secret: true},
+	}, envs)
+	assert.Equal(t, 2, IndexOf(envs, "DSN"))
+	assert.Equal(t, -1, IndexOf(envs, "NOT_EXISTS"))
+	assert.Equal(t, MaskedValue, envs[2].Masked().Value)
+	assert.Equal(t, "-Xmx1g", envs[0].Masked().Value)
+
+	envs[1].Type = TypeNumber
+	Set(templateConfig, envs)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "JAVA_OPTS", "value": "-Xmx1g"},
+		map[string]interface{}{"name": "PORT", "value": "8080", "type": TypeNumber},
+		map[string]interface{}{"name": "DSN", "value": "mysql://", "secret": true},
+	}, templateConfig["app"].(map[string]interface{})["envs"])
+	assert.NotNil(t, templateConfig["app"].(map[string]interface{})["spec"])
+
+	// a template config without envs
+	empty := map[string]interface{}{}
+	envs, err = List(empty)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(envs))
+	Set(empty, []*EnvVar{{Name: "A", Value: "1"}})
+	envs, err = List(empty)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(envs))
+
+	_, err = List(map[string]interface{}{"app": map[string]interface{}{"envs": "a=1"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -22,12 +22,18 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
 	"github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/autoscaling"
+	"github.com/horizoncd/horizon/pkg/cluster/availability"
+	"github.com/horizoncd/horizon/pkg/cluster/networkpolicy"
+	"github.com/horizoncd/horizon/pkg/cluster/rollout"
 	pkgcommon "github.com/horizoncd/horizon/pkg/common"
+	networkpolicyconfig "github.com/horizoncd/horizon/pkg/config/networkpolicy"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
@@ -41,6 +47,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/xanzy/go-gitlab"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
 	kyaml "sigs.k8s.io/yaml"
 )
 
@@ -49,6 +56,10 @@ const (
 	PipelineValueParent = "pipeline"
 )
 
+// _valueFiles are the value files of the helm release of clusters, the latter ones override the former ones
+var _valueFiles = []string{common.GitopsFileApplication, common.GitopsFilePipelineOutput,
+	common.GitopsFileEnv, common.GitopsFileBase, common.GitopsFileTags, common.GitopsFileRestart, common.GitopsFileSRE}
+
 type BaseParams struct {
 	ClusterID           uint
 	Cluster             string
@@ -59,6 +70,16 @@ type BaseParams struct {
 	Environment         string
 	RegionEntity        *regionmodels.RegionEntity
 	Namespace           string
+	// Rollout is kept as it is in the repo when it's nil on update
+	Rollout *rollout.Config
+	// NetworkPolicy is kept as it is in the repo when it's nil on update,
+	// it's rendered together with the baseline of the region
+	NetworkPolicy         *networkpolicy.Config
+	NetworkPolicyBaseline *networkpolicyconfig.Baseline
+	// Availability is kept as it is in the repo when it's nil on update
+	Availability *availability.Config
+	// Autoscaling is kept as it is in the repo when it's nil on update, an empty one removes the autoscaler
+	Autoscaling *autoscaling.Config
 
 	Version string
 }
@@ -71,6 +92,12 @@ type CreateClusterParams struct {
 
 type UpdateClusterParams struct {
 	*BaseParams
+	// ExpectedCommit is the head of gitops branch which the update is based on, the update fails
+	// with ErrGitlabCommitConflict if the branch has advanced since it. Nothing is checked if it's empty.
+	ExpectedCommit string
+	// Branch is the branch to write files to, defaults to the gitops branch.
+	// ExpectedCommit is checked against the head of the branch as well.
+	Branch string
 }
 
 type RepoInfo struct {
@@ -82,6 +109,12 @@ type ClusterFiles struct {
 	PipelineJSONBlob    map[string]interface{}
 	ApplicationJSONBlob map[string]interface{}
 	Manifest            map[string]interface{}
+	Rollout             *rollout.Config
+	NetworkPolicy       *networkpolicy.Config
+	Availability        *availability.Config
+	Autoscaling         *autoscaling.Config
+	// Commit is the head of gitops branch which the files are read from
+	Commit string
 }
 
 type ClusterValueFile struct {
@@ -119,17 +152,34 @@ type ReadFileParam struct {
 //go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/cluster/gitrepo/gitrepo_cluster_mock.go -package=mock_gitrepo
 type ClusterGitRepo interface {
 	GetCluster(ctx context.Context, application, cluster, templateName string) (*ClusterFiles, error)
+	// GetClusterByCommit is like GetCluster, but the files are read from the commit
+	GetClusterByCommit(ctx context.Context, application, cluster, templateName,
+		commit string) (*ClusterFiles, error)
 	GetClusterValueFiles(ctx context.Context,
 		application, cluster string) ([]ClusterValueFile, error)
 	// GetClusterTemplate parses cluster's template name and release from GitopsFileChart
 	GetClusterTemplate(ctx context.Context, application, cluster string) (*ClusterTemplate, error)
 	CreateCluster(ctx context.Context, params *CreateClusterParams) error
 	UpdateCluster(ctx context.Context, params *UpdateClusterParams) error
+	// RenderCluster renders the value files of the cluster updated with the params without writing them,
+	// the values of the files in the branch and the rendered ones are returned, keyed by file name
+	RenderCluster(ctx context.Context, params *UpdateClusterParams) (current, rendered map[string]interface{}, _ error)
 	DeleteCluster(ctx context.Context, application, cluster string, clusterID uint) error
 	HardDeleteCluster(ctx context.Context, application, cluster string) error
+	// RestoreCluster moves a cluster deleted by DeleteCluster back from the recycling group
+	RestoreCluster(ctx context.Context, application, cluster string, clusterID uint) error
+	// PurgeCluster deletes a cluster moved to the recycling group by DeleteCluster
+	PurgeCluster(ctx context.Context, application, cluster string, clusterID uint) error
 	// CompareConfig compare config of `from` commit with `to` commit.
 	// if `from` or `to` is nil, compare the master branch with gitops branch
 	CompareConfig(ctx context.Context, application, cluster string, from, to *string) (string, error)
+	// CreateBranch creates the branch from the gitops branch
+	CreateBranch(ctx context.Context, application, cluster, branch string) error
+	// CreateMergeRequest creates a merge request from source branch to target branch and returns its web url
+	CreateMergeRequest(ctx context.Context, application, cluster, sourceBranch,
+		targetBranch, title string) (string, error)
+	// CloseBranch closes the open merge requests of the branch and deletes it
+	CloseBranch(ctx context.Context, application, cluster, branch string) error
 	// MergeBranch merge branch and return target branch's newest commit
 	MergeBranch(ctx context.Context, application, cluster, sourceBranch,
 		targetBranch string, pipelineRunID *uint) (_ string, err error)
@@ -154,6 +204,12 @@ type ClusterGitRepo interface {
 	// GetManifest returns manifest with specific revision, defaults to gitops branch
 	GetManifest(ctx context.Context, application,
 		cluster string, commit *string) (*pkgcommon.Manifest, error)
+	// GetConfigValues returns values of config files with specific revision, keyed by file name.
+	// Files which do not exist are omitted.
+	GetConfigValues(ctx context.Context, application, cluster, commit string) (map[string]interface{}, error)
+	// ListConfigCommits lists the commits of gitOps branch changing the application file, the newest first
+	ListConfigCommits(ctx context.Context, application, cluster string,
+		page, perPage int) ([]*pkgcommon.ConfigCommit, error)
 }
 type clusterGitopsRepo struct {
 	gitlabLib              gitlablib.Interface
@@ -192,16 +248,43 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	const op = "cluster git repo: get cluster"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	// 1. get template and pipeline from gitlab
+	return g.getCluster(ctx, application, cluster, templateName, GitOpsBranch)
+}
+
+func (g *clusterGitopsRepo) GetClusterByCommit(ctx context.Context,
+	application, cluster, templateName, commit string) (_ *ClusterFiles, err error) {
+	const op = "cluster git repo: get cluster by commit"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return g.getCluster(ctx, application, cluster, templateName, commit)
+}
+
+func (g *clusterGitopsRepo) getCluster(ctx context.Context,
+	application, cluster, templateName, revision string) (*ClusterFiles, error) {
+	// 1. get application file and the commit which the revision points to,
+	// the other files are read from the commit to make sure they match it
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	var applicationBytes, pipelineBytes, manifestBytes []byte
-	var err1, err2, err3 error
+	ref, commitID := revision, ""
+	applicationBytes, commit, err2 := g.gitlabLib.GetFileAndCommit(ctx, pid, revision,
+		common.GitopsFileApplication)
+	if err2 == nil {
+		ref, commitID = commit, commit
+		applicationBytes, err2 = kyaml.YAMLToJSON(applicationBytes)
+		if err2 != nil {
+			err2 = perror.Wrap(herrors.ErrParamInvalid, err2.Error())
+		}
+	}
+
+	// 2. get pipeline, manifest and base value from gitlab
+	var pipelineBytes, manifestBytes []byte
+	var baseValue *BaseValue
+	var err1, err3, err4 error
 
 	var wg sync.WaitGroup
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		pipelineBytes, err1 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFilePipeline)
+		pipelineBytes, err1 = g.gitlabLib.GetFile(ctx, pid, ref, common.GitopsFilePipeline)
 		if err1 != nil {
 			return
 		}
@@ -212,18 +295,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	}()
 	go func() {
 		defer wg.Done()
-		applicationBytes, err2 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileApplication)
-		if err2 != nil {
-			return
-		}
-		applicationBytes, err2 = kyaml.YAMLToJSON(applicationBytes)
-		if err2 != nil {
-			err2 = perror.Wrap(herrors.ErrParamInvalid, err2.Error())
-		}
-	}()
-	go func() {
-		defer wg.Done()
-		manifestBytes, err3 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileManifest)
+		manifestBytes, err3 = g.gitlabLib.GetFile(ctx, pid, ref, common.GitopsFileManifest)
 		if err3 != nil {
 			return
 		}
@@ -232,9 +304,13 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 			return
 		}
 	}()
+	go func() {
+		defer wg.Done()
+		baseValue, err4 = g.getBaseValue(ctx, pid, ref)
+	}()
 	wg.Wait()
 
-	for _, err := range []error{err1, err2, err3} {
+	for _, err := range []error{err1, err2, err3, err4} {
 		if err != nil {
 			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
 				return nil, err
@@ -317,6 +393,11 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 		PipelineJSONBlob:    pipelineJSONBlob,
 		ApplicationJSONBlob: applicationJSONBlob,
 		Manifest:            manifestJSONBlob,
+		Rollout:             baseValue.GetRollout(),
+		NetworkPolicy:       baseValue.GetNetworkPolicy(),
+		Availability:        baseValue.GetAvailability(),
+		Autoscaling:         baseValue.GetAutoscaling(),
+		Commit:              commitID,
 	}, nil
 }
 
@@ -459,7 +540,11 @@ func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClu
 	if params.BaseParams.PipelineJSONBlob != nil {
 		marshal(&pipelineYAML, &err2, g.assemblePipelineValue(params.BaseParams))
 	}
-	marshal(&baseValueYAML, &err3, g.assembleBaseValue(params.BaseParams))
+	baseValue, err := g.assembleBaseValue(params.BaseParams)
+	if err != nil {
+		return err
+	}
+	marshal(&baseValueYAML, &err3, baseValue)
 	marshal(&envValueYAML, &err4, g.assembleEnvValue(params.BaseParams))
 	marshal(&sreValueYAML, &err5, g.assembleSREValue(params))
 	marshal(&restartYAML, &err7, assembleRestart(params.TemplateRelease.ChartName))
@@ -570,6 +655,18 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 
 	// 1. write files to repo
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, params.Application.Name, params.Cluster)
+	branch := GitOpsBranch
+	if params.Branch != "" {
+		branch = params.Branch
+	}
+	if params.ExpectedCommit != "" {
+		if err := g.checkHead(ctx, pid, branch, params.ExpectedCommit); err != nil {
+			return err
+		}
+	}
+	if err := g.keepBaseValue(ctx, pid, branch, params.BaseParams); err != nil {
+		return err
+	}
 	var applicationYAML, pipelineYAML, baseValueYAML, envValueYAML, chartYAML []byte
 	var err1, err2, err3, err4, err5 error
 	if params.Application != nil {
@@ -578,7 +675,11 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 	if params.PipelineJSONBlob != nil {
 		marshal(&pipelineYAML, &err2, g.assemblePipelineValue(params.BaseParams))
 	}
-	marshal(&baseValueYAML, &err3, g.assembleBaseValue(params.BaseParams))
+	baseValue, err := g.assembleBaseValue(params.BaseParams)
+	if err != nil {
+		return err
+	}
+	marshal(&baseValueYAML, &err3, baseValue)
 	chart, err := g.assembleChart(params.BaseParams)
 	if err != nil {
 		return err
@@ -667,13 +768,97 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 		Application: params.ApplicationJSONBlob,
 		Pipeline:    params.PipelineJSONBlob,
 	})
-	if _, err := g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, actions); err != nil {
+	if _, err := g.gitlabLib.WriteFiles(ctx, pid, branch, commitMsg, nil, actions); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+func (g *clusterGitopsRepo) RenderCluster(ctx context.Context,
+	params *UpdateClusterParams) (_, _ map[string]interface{}, err error) {
+	const op = "cluster git repo: render cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, params.Application.Name, params.Cluster)
+	branch := GitOpsBranch
+	if params.Branch != "" {
+		branch = params.Branch
+	}
+	current := make(map[string]interface{})
+	for _, file := range _valueFiles {
+		content, err := g.gitlabLib.GetFile(ctx, pid, branch, file)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, nil, err
+		}
+		if current[file], err = parseValue(content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := g.keepBaseValue(ctx, pid, branch, params.BaseParams); err != nil {
+		return nil, nil, err
+	}
+
+	// the files are assembled as UpdateCluster does, the ones not updated are kept
+	files := make(map[string]interface{})
+	if params.ApplicationJSONBlob != nil {
+		files[common.GitopsFileApplication] = g.assembleApplicationValue(params.BaseParams)
+	}
+	baseValue, err := g.assembleBaseValue(params.BaseParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	files[common.GitopsFileBase] = baseValue
+	if params.RegionEntity != nil {
+		files[common.GitopsFileEnv] = g.assembleEnvValue(params.BaseParams)
+	}
+
+	rendered := make(map[string]interface{}, len(current))
+	for file, value := range current {
+		rendered[file] = value
+	}
+	for file, data := range files {
+		var content []byte
+		marshal(&content, &err, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rendered[file], err = parseValue(content); err != nil {
+			return nil, nil, err
+		}
+	}
+	return current, rendered, nil
+}
+
+// keepBaseValue fills the configs of the base value which are not updated with the ones in the branch,
+// as the base value file is rewritten on update
+func (g *clusterGitopsRepo) keepBaseValue(ctx context.Context, pid, branch string, params *BaseParams) error {
+	if params.Rollout != nil && params.NetworkPolicy != nil && params.Availability != nil &&
+		params.Autoscaling != nil {
+		return nil
+	}
+	current, err := g.getBaseValue(ctx, pid, branch)
+	if err != nil {
+		return err
+	}
+	if params.Rollout == nil {
+		params.Rollout = current.GetRollout()
+	}
+	if params.NetworkPolicy == nil {
+		params.NetworkPolicy = current.GetNetworkPolicy()
+	}
+	if params.Availability == nil {
+		params.Availability = current.GetAvailability()
+	}
+	if params.Autoscaling == nil {
+		params.Autoscaling = current.GetAutoscaling()
+	}
+	return nil
+}
+
 func (g *clusterGitopsRepo) DeleteCluster(ctx context.Context,
 	application, cluster string, clusterID uint) (err error) {
 	const op = "cluster git repo: delete cluster"
@@ -716,6 +901,48 @@ func (g *clusterGitopsRepo) HardDeleteCluster(ctx context.Context, application,
 	return g.gitlabLib.DeleteProject(ctx, pid)
 }
 
+func (g *clusterGitopsRepo) RestoreCluster(ctx context.Context,
+	application, cluster string, clusterID uint) (err error) {
+	const op = "cluster git repo: restore cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. create application group if necessary
+	_, err = g.gitlabLib.GetGroup(ctx, fmt.Sprintf("%v/%v", g.clustersGroup.FullPath, application))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		_, err = g.gitlabLib.CreateGroup(ctx, application, application,
+			&g.clustersGroup.ID, g.defaultVisibility)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 2. transfer project back from RecyclingParent
+	recycledPath := fmt.Sprintf("%v-%d", cluster, clusterID)
+	pid := fmt.Sprintf("%v/%v/%v", g.recyclingClustersGroup.FullPath, application, recycledPath)
+	if err := g.gitlabLib.TransferProject(ctx, pid,
+		fmt.Sprintf("%v/%v", g.clustersGroup.FullPath, application)); err != nil {
+		return err
+	}
+
+	// 3. edit project's name and path back to {cluster}
+	newPid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, recycledPath)
+	newName := cluster
+	newPath := cluster
+	return g.gitlabLib.EditNameAndPathForProject(ctx, newPid, &newName, &newPath)
+}
+
+func (g *clusterGitopsRepo) PurgeCluster(ctx context.Context,
+	application, cluster string, clusterID uint) (err error) {
+	const op = "cluster git repo: purge cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pid := fmt.Sprintf("%v/%v/%v-%d", g.recyclingClustersGroup.FullPath, application, cluster, clusterID)
+	return g.gitlabLib.DeleteProject(ctx, pid)
+}
+
 func (g *clusterGitopsRepo) CompareConfig(ctx context.Context, application,
 	cluster string, from, to *string) (_ string, err error) {
 	const op = "cluster git repo: compare config"
@@ -744,6 +971,42 @@ func (g *clusterGitopsRepo) CompareConfig(ctx context.Context, application,
 	return diffStr, nil
 }
 
+func (g *clusterGitopsRepo) CreateBranch(ctx context.Context, application, cluster, branch string) error {
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	_, err := g.gitlabLib.CreateBranch(ctx, pid, branch, GitOpsBranch)
+	return err
+}
+
+func (g *clusterGitopsRepo) CreateMergeRequest(ctx context.Context, application, cluster,
+	sourceBranch, targetBranch, title string) (string, error) {
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	mr, err := g.gitlabLib.CreateMR(ctx, pid, sourceBranch, targetBranch, title)
+	if err != nil {
+		return "", perror.WithMessage(err, "failed to create new merge request")
+	}
+	return mr.WebURL, nil
+}
+
+func (g *clusterGitopsRepo) CloseBranch(ctx context.Context, application, cluster, branch string) error {
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	mrs, err := g.gitlabLib.ListMRs(ctx, pid, branch, GitOpsBranch, common.GitopsMergeRequestStateOpen)
+	if err != nil {
+		return perror.WithMessage(err, "failed to list merge requests")
+	}
+	for _, mr := range mrs {
+		if _, err := g.gitlabLib.CloseMR(ctx, pid, mr.IID); err != nil {
+			return err
+		}
+	}
+	if err := g.gitlabLib.DeleteBranch(ctx, pid, branch); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
 func (g *clusterGitopsRepo) MergeBranch(ctx context.Context, application, cluster,
 	sourceBranch, targetBranch string, pipelineRunID *uint) (_ string, err error) {
 	removeSourceBranch := false
@@ -828,6 +1091,43 @@ func (g *clusterGitopsRepo) GetManifest(ctx context.Context, application,
 	return manifest, nil
 }
 
+func (g *clusterGitopsRepo) GetConfigValues(ctx context.Context,
+	application, cluster, commit string) (map[string]interface{}, error) {
+	const op = "cluster git repo: get config values"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	values := make(map[string]interface{})
+	for _, file := range []string{common.GitopsFileApplication, common.GitopsFileSRE, common.GitopsFileEnv} {
+		content, err := g.gitlabLib.GetFile(ctx, pid, commit, file)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, err
+		}
+		value, err := parseValue(content)
+		if err != nil {
+			return nil, err
+		}
+		values[file] = value
+	}
+	return values, nil
+}
+
+// parseValue parses the content of a value file into json values
+func parseValue(content []byte) (interface{}, error) {
+	jsonBytes, err := kyaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	var value interface{}
+	if err := json.Unmarshal(jsonBytes, &value); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return value, nil
+}
+
 func (g *clusterGitopsRepo) GetPipelineOutput(ctx context.Context, application, cluster string,
 	template string) (interface{}, error) {
 	ret := make(map[string]interface{})
@@ -1053,8 +1353,7 @@ func (g *clusterGitopsRepo) GetRepoInfo(ctx context.Context, application, cluste
 	repoURL := g.gitlabLib.GetHTTPURL(ctx)
 	return &RepoInfo{
 		GitRepoURL: fmt.Sprintf("%v/%v/%v/%v.git", repoURL, g.clustersGroup.FullPath, application, cluster),
-		ValueFiles: []string{common.GitopsFileApplication, common.GitopsFilePipelineOutput,
-			common.GitopsFileEnv, common.GitopsFileBase, common.GitopsFileTags, common.GitopsFileRestart, common.GitopsFileSRE},
+		ValueFiles: _valueFiles,
 	}
 }
 
@@ -1147,6 +1446,36 @@ func (g *clusterGitopsRepo) Rollback(ctx context.Context, application, cluster,
 	return newCommit.ID, nil
 }
 
+func (g *clusterGitopsRepo) ListConfigCommits(ctx context.Context, application, cluster string,
+	page, perPage int) (_ []*pkgcommon.ConfigCommit, err error) {
+	const op = "cluster git repo: list config commits"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	// commits are listed by one path only, the ones changing nothing but the pipeline file are left out
+	commits, err := g.gitlabLib.ListCommits(ctx, pid, GitOpsBranch, common.GitopsFileApplication, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	configCommits := make([]*pkgcommon.ConfigCommit, 0, len(commits))
+	for _, commit := range commits {
+		var createdAt time.Time
+		if commit.CreatedAt != nil {
+			createdAt = *commit.CreatedAt
+		}
+		var stats *pkgcommon.CommitStats
+		if commit.Stats != nil {
+			stats = &pkgcommon.CommitStats{
+				Additions: commit.Stats.Additions,
+				Deletions: commit.Stats.Deletions,
+			}
+		}
+		configCommits = append(configCommits,
+			pkgcommon.NewConfigCommit(commit.ID, commit.Message, commit.AuthorName, createdAt, stats))
+	}
+	return configCommits, nil
+}
+
 func (g *clusterGitopsRepo) UpdateTags(ctx context.Context, application, cluster, templateName string,
 	tags []*tagmodels.Tag) (err error) {
 	const op = "cluster git repo: update tags"
@@ -1605,11 +1934,32 @@ type BaseValue struct {
 	Cluster     string             `yaml:"cluster"`
 	Template    *BaseValueTemplate `yaml:"template"`
 	Priority    string             `yaml:"priority"`
+	Rollout     *rollout.Config    `yaml:"rollout,omitempty"`
+	// NetworkPolicy is what the cluster declares, NetworkPolicyManifest is the NetworkPolicy
+	// rendered from it and the baseline of the region, which templates apply as it is
+	NetworkPolicy         *networkpolicy.Config  `yaml:"networkPolicy,omitempty"`
+	NetworkPolicyManifest map[string]interface{} `yaml:"networkPolicyManifest,omitempty"`
+	// Availability is what the cluster declares, AvailabilityManifest is rendered from it
+	Availability         *availability.Config  `yaml:"availability,omitempty"`
+	AvailabilityManifest *AvailabilityManifest `yaml:"availabilityManifest,omitempty"`
+	// Autoscaling is what the cluster declares, AutoscalingManifest is the HorizontalPodAutoscaler
+	// rendered from it, templates apply it as it is and leave the replicas of the workload unset
+	Autoscaling         *autoscaling.Config    `yaml:"autoscaling,omitempty"`
+	AutoscalingManifest map[string]interface{} `yaml:"autoscalingManifest,omitempty"`
+}
+
+// AvailabilityManifest is applied by templates as it is, the PodDisruptionBudget as a resource,
+// and the topologySpreadConstraints in the pod spec
+type AvailabilityManifest struct {
+	PodDisruptionBudget       map[string]interface{}   `yaml:"podDisruptionBudget,omitempty"`
+	TopologySpreadConstraints []map[string]interface{} `yaml:"topologySpreadConstraints,omitempty"`
 }
 
 type PipelineOutput struct {
 	Image *string `yaml:"image,omitempty" json:"image,omitempty"`
 	Git   *Git    `yaml:"git,omitempty" json:"git,omitempty"`
+	// Annotations records the build provenance, which templates render on workloads and pods
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 }
 
 type Git struct {
@@ -1626,7 +1976,24 @@ type BaseValueTemplate struct {
 
 // assembleBaseValue assemble base value. return a map, key is template name,
 // and value is a map which key is "horizon", and value is *BaseValue
-func (g *clusterGitopsRepo) assembleBaseValue(params *BaseParams) map[string]map[string]*BaseValue {
+func (g *clusterGitopsRepo) assembleBaseValue(params *BaseParams) (map[string]map[string]*BaseValue, error) {
+	manifest, err := renderNetworkPolicy(params)
+	if err != nil {
+		return nil, err
+	}
+	availabilityManifest, err := renderAvailability(params)
+	if err != nil {
+		return nil, err
+	}
+	autoscalingManifest, err := renderAutoscaling(params)
+	if err != nil {
+		return nil, err
+	}
+	// an empty config only turns the autoscaling off, there is nothing to keep
+	autoscalingConfig := params.Autoscaling
+	if !autoscalingConfig.Enabled() {
+		autoscalingConfig = nil
+	}
 	baseMap := make(map[string]*BaseValue)
 	baseMap[common.GitopsBaseValueNamespace] = &BaseValue{
 		Application: params.Application.Name,
@@ -1637,11 +2004,76 @@ func (g *clusterGitopsRepo) assembleBaseValue(params *BaseParams) map[string]map
 			Release: params.TemplateRelease.ChartVersion,
 		},
 		Priority: string(params.Application.Priority),
+		Rollout:  params.Rollout,
+
+		NetworkPolicy:         params.NetworkPolicy,
+		NetworkPolicyManifest: manifest,
+		Availability:          params.Availability,
+		AvailabilityManifest:  availabilityManifest,
+		Autoscaling:           autoscalingConfig,
+		AutoscalingManifest:   autoscalingManifest,
 	}
 
 	ret := make(map[string]map[string]*BaseValue)
 	ret[params.TemplateRelease.ChartName] = baseMap
-	return ret
+	return ret, nil
+}
+
+// renderNetworkPolicy renders the NetworkPolicy of the cluster into a map,
+// which keeps the field names of kubernetes when it's written as yaml
+func renderNetworkPolicy(params *BaseParams) (map[string]interface{}, error) {
+	policy := networkpolicy.Render(params.NetworkPolicy, params.NetworkPolicyBaseline, params.Cluster)
+	if policy == nil {
+		return nil, nil
+	}
+	manifest, err := runtime.DefaultUnstructuredConverter.ToUnstructured(policy)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return manifest, nil
+}
+
+// renderAvailability renders the PodDisruptionBudget and topologySpreadConstraints of the cluster into maps,
+// nil is returned if there are none
+func renderAvailability(params *BaseParams) (*AvailabilityManifest, error) {
+	manifest := &AvailabilityManifest{}
+	if pdb := availability.RenderPodDisruptionBudget(params.Availability, params.Cluster); pdb != nil {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pdb)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		manifest.PodDisruptionBudget = m
+	}
+	for _, constraint := range availability.RenderTopologySpreadConstraints(params.Availability, params.Cluster) {
+		constraint := constraint
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&constraint)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		manifest.TopologySpreadConstraints = append(manifest.TopologySpreadConstraints, m)
+	}
+	if manifest.PodDisruptionBudget == nil && manifest.TopologySpreadConstraints == nil {
+		return nil, nil
+	}
+	return manifest, nil
+}
+
+// renderAutoscaling renders the HorizontalPodAutoscaler of the cluster into a map, nil is returned if
+// the autoscaling is disabled. The argo Rollout is scaled instead of the Deployment if it's deployed step by step.
+func renderAutoscaling(params *BaseParams) (map[string]interface{}, error) {
+	var stepped bool
+	if params.Rollout != nil {
+		stepped = params.Rollout.Strategy.Stepped()
+	}
+	hpa := autoscaling.RenderHorizontalPodAutoscaler(params.Autoscaling, params.Cluster, stepped)
+	if hpa == nil {
+		return nil, nil
+	}
+	manifest, err := runtime.DefaultUnstructuredConverter.ToUnstructured(hpa)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return manifest, nil
 }
 
 type Chart struct {
@@ -1749,6 +2181,72 @@ func (g *clusterGitopsRepo) revertAction(ctx context.Context, application, clust
 	}, nil
 }
 
+// checkHead makes sure the head of gitops branch is still the expected commit
+func (g *clusterGitopsRepo) checkHead(ctx context.Context, pid, branchName, expectedCommit string) error {
+	branch, err := g.gitlabLib.GetBranch(ctx, pid, branchName)
+	if err != nil {
+		return err
+	}
+	// short commit id is accepted
+	if !strings.HasPrefix(branch.Commit.ID, expectedCommit) {
+		return perror.Wrapf(herrors.ErrGitlabCommitConflict,
+			"config has been changed from %s to %s, please reload it and try again",
+			expectedCommit, branch.Commit.ID)
+	}
+	return nil
+}
+
+// getBaseValue reads the horizon values from base value file with the ref,
+// nil is returned if the file or the values do not exist
+func (g *clusterGitopsRepo) getBaseValue(ctx context.Context, pid, ref string) (*BaseValue, error) {
+	content, err := g.gitlabLib.GetFile(ctx, pid, ref, common.GitopsFileBase)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var baseMap map[string]map[string]*BaseValue
+	if err := yaml.Unmarshal(content, &baseMap); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	// the file has only one parent, which is the chart name
+	for _, values := range baseMap {
+		if baseValue, ok := values[common.GitopsBaseValueNamespace]; ok && baseValue != nil {
+			return baseValue, nil
+		}
+	}
+	return nil, nil
+}
+
+func (v *BaseValue) GetRollout() *rollout.Config {
+	if v == nil {
+		return nil
+	}
+	return v.Rollout
+}
+
+func (v *BaseValue) GetNetworkPolicy() *networkpolicy.Config {
+	if v == nil {
+		return nil
+	}
+	return v.NetworkPolicy
+}
+
+func (v *BaseValue) GetAvailability() *availability.Config {
+	if v == nil {
+		return nil
+	}
+	return v.Availability
+}
+
+func (v *BaseValue) GetAutoscaling() *autoscaling.Config {
+	if v == nil {
+		return nil
+	}
+	return v.Autoscaling
+}
+
 // readFile gets file for specific revision, defaults to gitOps branch
 func (g *clusterGitopsRepo) readFile(ctx context.Context, application, cluster,
 	fileName string, commit *string) ([]byte, error) {
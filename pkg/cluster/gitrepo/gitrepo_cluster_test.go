@@ -638,3 +638,30 @@ java:
 	fmt.Println(output)
 	assert.Equal(t, expectedOutput, output)
 }
+
+func TestClusterGitRepo_UpdateClusterConflict(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gitlabmockLib := gitlablibmock.NewMockInterface(mockCtrl)
+	gitlabmockLib.EXPECT().GetCreatedGroup(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&gitlab.Group{}, nil).AnyTimes()
+	gitlabmockLib.EXPECT().GetBranch(gomock.Any(), gomock.Any(), GitOpsBranch).Return(&gitlab.Branch{
+		Commit: &gitlab.Commit{ID: "b2c3d4e5f6"},
+	}, nil).Times(1)
+	gitlabmockLib.EXPECT().WriteFiles(gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	r, err := NewClusterGitlabRepo(ctx, rootGroup, &chartmuseumbase.Repo{},
+		gitlabmockLib, defaultBranch, defaultVisibility)
+	assert.Nil(t, err)
+
+	err = r.UpdateCluster(ctx, &UpdateClusterParams{
+		BaseParams: &BaseParams{
+			Cluster:     "cluster",
+			Application: &appmodels.Application{Name: "app"},
+		},
+		ExpectedCommit: "a1b2c3d4",
+	})
+	assert.Equal(t, herrors.ErrGitlabCommitConflict, perror.Cause(err))
+}
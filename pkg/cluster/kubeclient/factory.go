@@ -17,9 +17,14 @@ package kubeclient
 import (
 	"sync"
 
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/horizoncd/horizon/pkg/agent"
+	kubeclientconfig "github.com/horizoncd/horizon/pkg/config/kubeclient"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	"github.com/horizoncd/horizon/pkg/util/kube"
-	"k8s.io/client-go/rest"
 )
 
 var (
@@ -27,44 +32,115 @@ var (
 )
 
 var (
-	Fty = NewFactory()
+	// Fty is replaced by a factory with the rate limits in config when horizon starts
+	Fty = NewFactory(kubeclientconfig.Config{}, nil)
 )
 
 type Factory interface {
-	GetByK8SServer(server, certificate string) (*rest.Config, *kube.Client, error)
+	// GetByRegion returns the cached clients of the region,
+	// they are rebuilt when the server or certificate of the region changes
+	GetByRegion(region *regionmodels.Region) (*rest.Config, *kube.Client, error)
+	// RestConfig builds a rest config of the region, clients built by it share
+	// the rate limiter of the region. Requests to regions served by agents are forwarded to their agents
+	RestConfig(region *regionmodels.Region) (*rest.Config, error)
 }
 
 type factory struct {
+	config   kubeclientconfig.Config
+	agentHub agent.Hub
+
 	cache *sync.Map
+	// mu protects rateLimiters
+	mu           sync.Mutex
+	rateLimiters map[string]flowcontrol.RateLimiter
 }
 
-func NewFactory() Factory {
+// NewFactory creates a factory, agentHub can be nil when no region is served by agents
+func NewFactory(config kubeclientconfig.Config, agentHub agent.Hub) Factory {
 	return &factory{
-		cache: &sync.Map{},
+		config:       config,
+		agentHub:     agentHub,
+		cache:        &sync.Map{},
+		rateLimiters: make(map[string]flowcontrol.RateLimiter),
 	}
 }
 
 type k8sClientCache struct {
-	config *rest.Config
-	client *kube.Client
+	server      string
+	certificate string
+	config      *rest.Config
+	client      *kube.Client
 }
 
-func (f *factory) GetByK8SServer(server, certificate string) (*rest.Config, *kube.Client, error) {
-	ret, ok := f.cache.Load(server)
+func (f *factory) GetByRegion(region *regionmodels.Region) (*rest.Config, *kube.Client, error) {
+	ret, ok := f.cache.Load(region.Name)
 	if ok {
 		clientCache := ret.(*k8sClientCache)
-		return clientCache.config, clientCache.client, nil
+		if clientCache.server == region.Server && clientCache.certificate == region.Certificate {
+			observeCache(region.Name, true)
+			return clientCache.config, clientCache.client, nil
+		}
 	}
+	observeCache(region.Name, false)
 
-	config, client, err := kube.BuildClientFromContent(certificate)
+	config, err := f.RestConfig(region)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := kube.NewClient(config)
 	if err != nil {
 		return nil, nil, perror.Wrap(ErrBuildKubeClientFailed, err.Error())
 	}
 
-	f.cache.Store(server, &k8sClientCache{
-		config: config,
-		client: client,
+	f.cache.Store(region.Name, &k8sClientCache{
+		server:      region.Server,
+		certificate: region.Certificate,
+		config:      config,
+		client:      client,
 	})
 
 	return config, client, nil
 }
+
+func (f *factory) RestConfig(region *regionmodels.Region) (*rest.Config, error) {
+	var config *rest.Config
+	if f.agentHub != nil && f.agentHub.Serves(region.Name) {
+		config = &rest.Config{
+			Host:      agent.Host,
+			Transport: f.agentHub.RoundTripper(region.Name),
+		}
+	} else {
+		var err error
+		config, err = kube.BuildRestConfigFromContent(region.Certificate)
+		if err != nil {
+			return nil, perror.Wrap(ErrBuildKubeClientFailed, err.Error())
+		}
+	}
+	rateLimiter := f.rateLimiter(region.Name)
+	config.QPS = rateLimiter.QPS()
+	config.RateLimiter = rateLimiter
+	config.Wrap(newMetricsRoundTripper(region.Name))
+	return config, nil
+}
+
+func (f *factory) rateLimiter(region string) flowcontrol.RateLimiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if rateLimiter, ok := f.rateLimiters[region]; ok {
+		return rateLimiter
+	}
+
+	limit := f.config.RateLimitOf(region)
+	if limit.QPS <= 0 {
+		limit.QPS = kube.K8sClientConfigQPS
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = kube.K8sClientConfigBurst
+	}
+	rateLimiter := &metricsRateLimiter{
+		RateLimiter: flowcontrol.NewTokenBucketRateLimiter(limit.QPS, limit.Burst),
+		region:      region,
+	}
+	f.rateLimiters[region] = rateLimiter
+	return rateLimiter
+}
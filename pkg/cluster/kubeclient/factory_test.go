@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/agent"
+	agentconfig "github.com/horizoncd/horizon/pkg/config/agent"
+	kubeclientconfig "github.com/horizoncd/horizon/pkg/config/kubeclient"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+)
+
+const _kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`
+
+func TestFactory(t *testing.T) {
+	f := NewFactory(kubeclientconfig.Config{
+		RateLimit: kubeclientconfig.RateLimit{QPS: 20, Burst: 40},
+		Regions: map[string]kubeclientconfig.RateLimit{
+			"hz": {QPS: 100},
+		},
+	}, nil)
+	hz := &regionmodels.Region{Name: "hz", Server: "https://127.0.0.1:6443", Certificate: _kubeconfig}
+	sh := &regionmodels.Region{Name: "sh", Server: "https://127.0.0.1:6443", Certificate: _kubeconfig}
+
+	hzConfig, hzClient, err := f.GetByRegion(hz)
+	assert.Nil(t, err)
+	assert.Equal(t, float32(100), hzConfig.QPS)
+	shConfig, _, err := f.GetByRegion(sh)
+	assert.Nil(t, err)
+	assert.Equal(t, float32(20), shConfig.QPS)
+	assert.NotEqual(t, hzConfig.RateLimiter, shConfig.RateLimiter)
+
+	// clients and rate limiter are shared in a region
+	_, client, err := f.GetByRegion(hz)
+	assert.Nil(t, err)
+	assert.Same(t, hzClient, client)
+	restConfig, err := f.RestConfig(hz)
+	assert.Nil(t, err)
+	assert.Same(t, hzConfig.RateLimiter, restConfig.RateLimiter)
+
+	// clients are rebuilt after the region is updated
+	hz.Server = "https://127.0.0.2:6443"
+	_, client, err = f.GetByRegion(hz)
+	assert.Nil(t, err)
+	assert.NotSame(t, hzClient, client)
+}
+
+func TestFactoryWithAgent(t *testing.T) {
+	hub := agent.NewHub(agentconfig.Config{
+		Regions: map[string]agentconfig.Region{"restricted": {Token: "token"}},
+	})
+	f := NewFactory(kubeclientconfig.Config{}, hub)
+
+	// requests to regions served by agents are forwarded to the agents without kubeconfig
+	restricted := &regionmodels.Region{Name: "restricted"}
+	config, _, err := f.GetByRegion(restricted)
+	assert.Nil(t, err)
+	assert.Equal(t, agent.Host, config.Host)
+	assert.NotNil(t, config.Transport)
+
+	hz := &regionmodels.Region{Name: "hz", Server: "https://127.0.0.1:6443", Certificate: _kubeconfig}
+	config, _, err = f.GetByRegion(hz)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", config.Host)
+}
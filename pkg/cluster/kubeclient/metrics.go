@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/client-go/transport"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	_namespace = "horizon"
+	_subsystem = "kube_client"
+
+	_region = "region"
+	_code   = "code"
+	_result = "result"
+)
+
+var (
+	_requestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: _subsystem,
+		Name:      "requests_total",
+		Help:      "Requests sent to kubernetes api servers",
+	}, []string{_region, _code})
+
+	_requestHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: _namespace,
+		Subsystem: _subsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Duration of requests sent to kubernetes api servers",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{_region})
+
+	_rateLimiterHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: _namespace,
+		Subsystem: _subsystem,
+		Name:      "rate_limiter_wait_seconds",
+		Help:      "Time requests waited for the client-side rate limiter",
+		Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
+	}, []string{_region})
+
+	_cacheCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: _subsystem,
+		Name:      "cache_total",
+		Help:      "Lookups of cached kubernetes clients",
+	}, []string{_region, _result})
+)
+
+func observeCache(region string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	_cacheCounter.With(prometheus.Labels{_region: region, _result: result}).Inc()
+}
+
+type metricsRoundTripper struct {
+	region string
+	next   http.RoundTripper
+}
+
+func newMetricsRoundTripper(region string) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &metricsRoundTripper{region: region, next: rt}
+	}
+}
+
+func (rt *metricsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+	_requestHistogram.With(prometheus.Labels{_region: rt.region}).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	_requestCounter.With(prometheus.Labels{_region: rt.region, _code: code}).Inc()
+	return resp, err
+}
+
+// metricsRateLimiter observes the time spent waiting for the rate limiter
+type metricsRateLimiter struct {
+	flowcontrol.RateLimiter
+	region string
+}
+
+func (l *metricsRateLimiter) Accept() {
+	start := time.Now()
+	l.RateLimiter.Accept()
+	_rateLimiterHistogram.With(prometheus.Labels{_region: l.region}).Observe(time.Since(start).Seconds())
+}
+
+func (l *metricsRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := l.RateLimiter.Wait(ctx)
+	_rateLimiterHistogram.With(prometheus.Labels{_region: l.region}).Observe(time.Since(start).Seconds())
+	return err
+}
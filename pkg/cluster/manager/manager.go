@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/lib/q"
@@ -38,11 +39,25 @@ type Manager interface {
 	GetByName(ctx context.Context, clusterName string) (*models.Cluster, error)
 	UpdateByID(ctx context.Context, id uint, cluster *models.Cluster) (*models.Cluster, error)
 	DeleteByID(ctx context.Context, id uint) error
+	// HardDeleteByID deletes a cluster which can not be restored
+	HardDeleteByID(ctx context.Context, id uint) error
 	CheckClusterExists(ctx context.Context, cluster string) (bool, error)
 	List(ctx context.Context, query *q.Query, appIDs ...uint) (int, []*models.ClusterWithRegion, error)
 	ListByApplicationID(ctx context.Context, applicationID uint) (int, []*models.ClusterWithRegion, error)
 	ListClusterWithExpiry(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
 	GetByNameFuzzilyIncludeSoftDelete(ctx context.Context, name string) ([]*models.Cluster, error)
+	// GetDeletedByName get soft deleted clusters with the given name
+	GetDeletedByName(ctx context.Context, name string) ([]*models.Cluster, error)
+	// ListDeleted list soft deleted clusters of the given application
+	ListDeleted(ctx context.Context, applicationID uint) ([]*models.Cluster, error)
+	// ListDeletedBefore list at most limit clusters soft deleted before the given time,
+	// whose id is greater than afterID
+	ListDeletedBefore(ctx context.Context, before time.Time, afterID uint,
+		limit int) ([]*models.Cluster, error)
+	// Restore restore a soft deleted cluster with the given status
+	Restore(ctx context.Context, id uint, status string) error
+	// Purge remove a soft deleted cluster from db permanently
+	Purge(ctx context.Context, id uint) error
 }
 
 func New(db *gorm.DB) Manager {
@@ -103,6 +118,10 @@ func (m *manager) DeleteByID(ctx context.Context, id uint) error {
 	return m.dao.DeleteByID(ctx, id)
 }
 
+func (m *manager) HardDeleteByID(ctx context.Context, id uint) error {
+	return m.dao.HardDeleteByID(ctx, id)
+}
+
 func (m *manager) CheckClusterExists(ctx context.Context, cluster string) (bool, error) {
 	return m.dao.CheckClusterExists(ctx, cluster)
 }
@@ -146,3 +165,24 @@ func (m *manager) ListClusterWithExpiry(ctx context.Context,
 	}
 	return m.dao.ListClusterWithExpiry(ctx, query)
 }
+
+func (m *manager) GetDeletedByName(ctx context.Context, name string) ([]*models.Cluster, error) {
+	return m.dao.GetDeletedByName(ctx, name)
+}
+
+func (m *manager) ListDeleted(ctx context.Context, applicationID uint) ([]*models.Cluster, error) {
+	return m.dao.ListDeletedByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) ListDeletedBefore(ctx context.Context, before time.Time, afterID uint,
+	limit int) ([]*models.Cluster, error) {
+	return m.dao.ListDeletedBefore(ctx, before, afterID, limit)
+}
+
+func (m *manager) Restore(ctx context.Context, id uint, status string) error {
+	return m.dao.RestoreByID(ctx, id, status)
+}
+
+func (m *manager) Purge(ctx context.Context, id uint) error {
+	return m.dao.PurgeByID(ctx, id)
+}
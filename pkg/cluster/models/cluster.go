@@ -39,6 +39,8 @@ type Cluster struct {
 	CreatedBy       uint
 	UpdatedBy       uint
 	ExpireSeconds   uint
+	// HardDeleted is true if the cluster is deleted permanently and can not be restored
+	HardDeleted bool
 }
 
 type ClusterWithTags struct {
@@ -0,0 +1,248 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	networkpolicyconfig "github.com/horizoncd/horizon/pkg/config/networkpolicy"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	ProtocolTCP  = "TCP"
+	ProtocolUDP  = "UDP"
+	ProtocolSCTP = "SCTP"
+)
+
+// Config declares the traffic allowed into and out of the pods of a cluster.
+// It's kept in the horizon value file as .Values.horizon.networkPolicy,
+// and rendered as a NetworkPolicy in .Values.horizon.networkPolicyManifest for templates to apply.
+type Config struct {
+	// Ingress restricts the incoming traffic to its rules if not nil, a policy without rules denies all
+	Ingress *Policy `json:"ingress,omitempty" yaml:"ingress,omitempty"`
+	// Egress restricts the outgoing traffic to its rules if not nil, DNS queries are always allowed
+	Egress *Policy `json:"egress,omitempty" yaml:"egress,omitempty"`
+}
+
+type Policy struct {
+	Rules []*Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule allows the traffic from or to any of the peers on any of the ports.
+// No peers means all peers, and no ports means all ports.
+type Rule struct {
+	Peers []*Peer `json:"peers,omitempty" yaml:"peers,omitempty"`
+	Ports []*Port `json:"ports,omitempty" yaml:"ports,omitempty"`
+}
+
+// Peer is either pods selected by labels, optionally in another namespace, or a CIDR
+type Peer struct {
+	// PodSelector selects pods by labels, pods in the same namespace are selected if Namespace is empty
+	PodSelector map[string]string `json:"podSelector,omitempty" yaml:"podSelector,omitempty"`
+	// Namespace selects all pods in the namespace, or the pods selected by PodSelector in it
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	CIDR      string `json:"cidr,omitempty" yaml:"cidr,omitempty"`
+	// Except excludes sub ranges of CIDR
+	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+}
+
+type Port struct {
+	// Protocol is one of TCP, UDP and SCTP, defaults to TCP
+	Protocol string `json:"protocol" yaml:"protocol"`
+	Port     int32  `json:"port" yaml:"port"`
+}
+
+// SetDefaults fills the protocols which are not specified
+func (c *Config) SetDefaults() {
+	for _, rule := range c.rules() {
+		for _, port := range rule.Ports {
+			if port == nil {
+				continue
+			}
+			if port.Protocol == "" {
+				port.Protocol = ProtocolTCP
+			}
+			port.Protocol = strings.ToUpper(port.Protocol)
+		}
+	}
+}
+
+// Validate checks the config after defaults are set
+func (c *Config) Validate() error {
+	for _, direction := range []struct {
+		field  string
+		policy *Policy
+	}{{"ingress", c.Ingress}, {"egress", c.Egress}} {
+		if direction.policy == nil {
+			continue
+		}
+		for i, rule := range direction.policy.Rules {
+			field := fmt.Sprintf("%s.rules[%d]", direction.field, i)
+			if rule == nil {
+				return invalid(fmt.Sprintf("%s must not be empty", field))
+			}
+			for j, peer := range rule.Peers {
+				if err := peer.validate(fmt.Sprintf("%s.peers[%d]", field, j)); err != nil {
+					return err
+				}
+			}
+			for j, port := range rule.Ports {
+				if err := port.validate(fmt.Sprintf("%s.ports[%d]", field, j)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Peer) validate(field string) error {
+	if p == nil {
+		return invalid(fmt.Sprintf("%s must not be empty", field))
+	}
+	if p.CIDR == "" {
+		if len(p.PodSelector) == 0 && p.Namespace == "" {
+			return invalid(fmt.Sprintf("%s must specify podSelector, namespace or cidr", field))
+		}
+		if len(p.Except) > 0 {
+			return invalid(fmt.Sprintf("%s.except is only allowed with cidr", field))
+		}
+		return nil
+	}
+
+	if len(p.PodSelector) > 0 || p.Namespace != "" {
+		return invalid(fmt.Sprintf("%s.cidr cannot be specified with podSelector or namespace", field))
+	}
+	_, ipNet, err := net.ParseCIDR(p.CIDR)
+	if err != nil {
+		return invalid(fmt.Sprintf("%s.cidr %q is invalid", field, p.CIDR))
+	}
+	for _, except := range p.Except {
+		_, exceptNet, err := net.ParseCIDR(except)
+		if err != nil {
+			return invalid(fmt.Sprintf("%s.except %q is invalid", field, except))
+		}
+		if !contains(ipNet, exceptNet) {
+			return invalid(fmt.Sprintf("%s.except %q is not inside cidr %q", field, except, p.CIDR))
+		}
+	}
+	return nil
+}
+
+func (p *Port) validate(field string) error {
+	if p == nil {
+		return invalid(fmt.Sprintf("%s must not be empty", field))
+	}
+	switch p.Protocol {
+	case ProtocolTCP, ProtocolUDP, ProtocolSCTP:
+	default:
+		return invalid(fmt.Sprintf("%s.protocol must be one of %s, %s and %s",
+			field, ProtocolTCP, ProtocolUDP, ProtocolSCTP))
+	}
+	if p.Port < 1 || p.Port > 65535 {
+		return invalid(fmt.Sprintf("%s.port must be between 1 and 65535", field))
+	}
+	return nil
+}
+
+// Check makes sure the config does not loosen the baseline of the region, nil baseline allows anything
+func (c *Config) Check(baseline *networkpolicyconfig.Baseline) error {
+	if baseline == nil {
+		return nil
+	}
+	allowedNets := make([]*net.IPNet, 0, len(baseline.AllowedCIDRs))
+	for _, cidr := range baseline.AllowedCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return invalid(fmt.Sprintf("allowed cidr %q of the baseline is invalid", cidr))
+		}
+		allowedNets = append(allowedNets, ipNet)
+	}
+
+	for _, direction := range []struct {
+		field       string
+		policy      *Policy
+		defaultDeny bool
+	}{{"ingress", c.Ingress, baseline.DefaultDenyIngress}, {"egress", c.Egress, baseline.DefaultDenyEgress}} {
+		if direction.policy == nil {
+			continue
+		}
+		for i, rule := range direction.policy.Rules {
+			field := fmt.Sprintf("%s.rules[%d]", direction.field, i)
+			// a rule without peers allows everyone, which breaks both default deny and allowed cidrs
+			if len(rule.Peers) == 0 && (direction.defaultDeny || len(allowedNets) > 0) {
+				return invalid(fmt.Sprintf("%s must specify peers as required by the baseline of the region", field))
+			}
+			for j, peer := range rule.Peers {
+				peerField := fmt.Sprintf("%s.peers[%d]", field, j)
+				for _, forbidden := range baseline.ForbiddenNamespaces {
+					if peer.Namespace == forbidden {
+						return invalid(fmt.Sprintf("%s.namespace %q is forbidden by the baseline of the region",
+							peerField, peer.Namespace))
+					}
+				}
+				if peer.CIDR == "" || len(allowedNets) == 0 {
+					continue
+				}
+				_, ipNet, err := net.ParseCIDR(peer.CIDR)
+				if err != nil {
+					return invalid(fmt.Sprintf("%s.cidr %q is invalid", peerField, peer.CIDR))
+				}
+				allowed := false
+				for _, allowedNet := range allowedNets {
+					if contains(allowedNet, ipNet) {
+						allowed = true
+						break
+					}
+				}
+				if !allowed {
+					return invalid(fmt.Sprintf("%s.cidr %q is not allowed by the baseline of the region, "+
+						"allowed cidrs are %v", peerField, peer.CIDR, baseline.AllowedCIDRs))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) rules() []*Rule {
+	var rules []*Rule
+	for _, policy := range []*Policy{c.Ingress, c.Egress} {
+		if policy == nil {
+			continue
+		}
+		for _, rule := range policy.Rules {
+			if rule != nil {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+// contains returns true if inner is a sub range of outer
+func contains(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outer.Contains(inner.IP)
+}
+
+func invalid(msg string) error {
+	return perror.Wrap(herrors.ErrParamInvalid, msg)
+}
@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	networkpolicyconfig "github.com/horizoncd/horizon/pkg/config/networkpolicy"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestSetDefaults(t *testing.T) {
+	c := &Config{
+		Ingress: &Policy{Rules: []*Rule{{
+			Peers: []*Peer{{PodSelector: map[string]string{"app": "gateway"}}},
+			Ports: []*Port{{Port: 8080}, {Protocol: "udp", Port: 9090}},
+		}}},
+	}
+	c.SetDefaults()
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, ProtocolTCP, c.Ingress.Rules[0].Ports[0].Protocol)
+	assert.Equal(t, ProtocolUDP, c.Ingress.Rules[0].Ports[1].Protocol)
+}
+
+func TestValidate(t *testing.T) {
+	valid := &Config{
+		Ingress: &Policy{Rules: []*Rule{}},
+		Egress: &Policy{Rules: []*Rule{
+			{Peers: []*Peer{{Namespace: "middleware", PodSelector: map[string]string{"app": "redis"}}}},
+			{Peers: []*Peer{{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}}},
+			{Ports: []*Port{{Port: 443}}},
+		}},
+	}
+	valid.SetDefaults()
+	assert.Nil(t, valid.Validate())
+
+	cases := map[string]*Config{
+		"empty peer": {
+			Ingress: &Policy{Rules: []*Rule{{Peers: []*Peer{{}}}}},
+		},
+		"cidr with namespace": {
+			Ingress: &Policy{Rules: []*Rule{{Peers: []*Peer{{CIDR: "10.0.0.0/8", Namespace: "default"}}}}},
+		},
+		"invalid cidr": {
+			Egress: &Policy{Rules: []*Rule{{Peers: []*Peer{{CIDR: "10.0.0.0/33"}}}}},
+		},
+		"except outside cidr": {
+			Egress: &Policy{Rules: []*Rule{{Peers: []*Peer{{CIDR: "10.0.0.0/16", Except: []string{"10.2.0.0/24"}}}}}},
+		},
+		"except without cidr": {
+			Egress: &Policy{Rules: []*Rule{{Peers: []*Peer{{Namespace: "default", Except: []string{"10.2.0.0/24"}}}}}},
+		},
+		"invalid protocol": {
+			Ingress: &Policy{Rules: []*Rule{{Ports: []*Port{{Protocol: "ICMP", Port: 80}}}}},
+		},
+		"invalid port": {
+			Ingress: &Policy{Rules: []*Rule{{Ports: []*Port{{Port: 0}}}}},
+		},
+	}
+	for name, c := range cases {
+		c.SetDefaults()
+		err := c.Validate()
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), name)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	baseline := &networkpolicyconfig.Baseline{
+		DefaultDenyIngress:  true,
+		AllowedCIDRs:        []string{"10.0.0.0/8"},
+		ForbiddenNamespaces: []string{"kube-system"},
+	}
+	allowed := &Config{
+		Ingress: &Policy{Rules: []*Rule{{Peers: []*Peer{{PodSelector: map[string]string{"app": "gateway"}}}}}},
+		Egress:  &Policy{Rules: []*Rule{{Peers: []*Peer{{CIDR: "10.10.0.0/16"}}}}},
+	}
+	assert.Nil(t, allowed.Check(baseline))
+	assert.Nil(t, allowed.Check(nil))
+
+	cases := map[string]*Config{
+		"ingress from everywhere": {
+			Ingress: &Policy{Rules: []*Rule{{Ports: []*Port{{Protocol: ProtocolTCP, Port: 80}}}}},
+		},
+		"egress to everywhere with allowed cidrs": {
+			Egress: &Policy{Rules: []*Rule{{}}},
+		},
+		"cidr outside allowed": {
+			Egress: &Policy{Rules: []*Rule{{Peers: []*Peer{{CIDR: "0.0.0.0/0"}}}}},
+		},
+		"forbidden namespace": {
+			Ingress: &Policy{Rules: []*Rule{{Peers: []*Peer{{Namespace: "kube-system"}}}}},
+		},
+	}
+	for name, c := range cases {
+		err := c.Check(baseline)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), name)
+	}
+}
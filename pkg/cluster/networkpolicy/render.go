@@ -0,0 +1,132 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"github.com/horizoncd/horizon/core/common"
+	networkpolicyconfig "github.com/horizoncd/horizon/pkg/config/networkpolicy"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// namespaceNameLabel is set on every namespace by kubernetes, which selects a namespace by name
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+const dnsPort = 53
+
+// Render returns the NetworkPolicy selecting pods of the cluster, directions which are restricted
+// neither by the config nor by the baseline are left open. Nil is returned if nothing is restricted.
+func Render(c *Config, baseline *networkpolicyconfig.Baseline, cluster string) *networkingv1.NetworkPolicy {
+	if c == nil {
+		c = &Config{}
+	}
+	restrictIngress := c.Ingress != nil || (baseline != nil && baseline.DefaultDenyIngress)
+	restrictEgress := c.Egress != nil || (baseline != nil && baseline.DefaultDenyEgress)
+	if !restrictIngress && !restrictEgress {
+		return nil
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cluster,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{common.ClusterClusterLabelKey: cluster},
+			},
+		},
+	}
+	if restrictIngress {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+		policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{}
+		if c.Ingress != nil {
+			for _, rule := range c.Ingress.Rules {
+				policy.Spec.Ingress = append(policy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+					From:  renderPeers(rule.Peers),
+					Ports: renderPorts(rule.Ports),
+				})
+			}
+		}
+	}
+	if restrictEgress {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		// workloads hardly work without resolving names, so DNS in the cluster is always allowed
+		policy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{
+			To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}},
+			Ports: renderPorts([]*Port{
+				{Protocol: ProtocolUDP, Port: dnsPort},
+				{Protocol: ProtocolTCP, Port: dnsPort},
+			}),
+		}}
+		if c.Egress != nil {
+			for _, rule := range c.Egress.Rules {
+				policy.Spec.Egress = append(policy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+					To:    renderPeers(rule.Peers),
+					Ports: renderPorts(rule.Ports),
+				})
+			}
+		}
+	}
+	return policy
+}
+
+func renderPeers(peers []*Peer) []networkingv1.NetworkPolicyPeer {
+	if len(peers) == 0 {
+		return nil
+	}
+	ret := make([]networkingv1.NetworkPolicyPeer, 0, len(peers))
+	for _, peer := range peers {
+		var p networkingv1.NetworkPolicyPeer
+		if peer.CIDR != "" {
+			p.IPBlock = &networkingv1.IPBlock{
+				CIDR:   peer.CIDR,
+				Except: peer.Except,
+			}
+		} else {
+			if len(peer.PodSelector) > 0 || peer.Namespace == "" {
+				p.PodSelector = &metav1.LabelSelector{MatchLabels: peer.PodSelector}
+			}
+			if peer.Namespace != "" {
+				p.NamespaceSelector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{namespaceNameLabel: peer.Namespace},
+				}
+			}
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+func renderPorts(ports []*Port) []networkingv1.NetworkPolicyPort {
+	if len(ports) == 0 {
+		return nil
+	}
+	ret := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		protocol := corev1.Protocol(port.Protocol)
+		portValue := intstr.FromInt(int(port.Port))
+		ret = append(ret, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &portValue,
+		})
+	}
+	return ret
+}
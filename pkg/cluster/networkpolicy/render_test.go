@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/horizoncd/horizon/core/common"
+	networkpolicyconfig "github.com/horizoncd/horizon/pkg/config/networkpolicy"
+)
+
+func TestRender(t *testing.T) {
+	// nothing is restricted
+	assert.Nil(t, Render(nil, nil, "demo"))
+	assert.Nil(t, Render(&Config{}, &networkpolicyconfig.Baseline{}, "demo"))
+
+	// baseline isolates clusters without config
+	policy := Render(nil, &networkpolicyconfig.Baseline{DefaultDenyIngress: true}, "demo")
+	assert.Equal(t, "demo", policy.Name)
+	assert.Equal(t, "demo", policy.Spec.PodSelector.MatchLabels[common.ClusterClusterLabelKey])
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, policy.Spec.PolicyTypes)
+	assert.NotNil(t, policy.Spec.Ingress)
+	assert.Equal(t, 0, len(policy.Spec.Ingress))
+
+	c := &Config{
+		Ingress: &Policy{Rules: []*Rule{{
+			Peers: []*Peer{
+				{PodSelector: map[string]string{"app": "gateway"}},
+				{Namespace: "monitoring"},
+			},
+			Ports: []*Port{{Protocol: ProtocolTCP, Port: 8080}},
+		}}},
+		Egress: &Policy{Rules: []*Rule{{
+			Peers: []*Peer{{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+		}}},
+	}
+	policy = Render(c, nil, "demo")
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		policy.Spec.PolicyTypes)
+
+	assert.Equal(t, 1, len(policy.Spec.Ingress))
+	from := policy.Spec.Ingress[0].From
+	assert.Equal(t, 2, len(from))
+	assert.Equal(t, "gateway", from[0].PodSelector.MatchLabels["app"])
+	assert.Nil(t, from[0].NamespaceSelector)
+	assert.Nil(t, from[1].PodSelector)
+	assert.Equal(t, "monitoring", from[1].NamespaceSelector.MatchLabels[namespaceNameLabel])
+	assert.Equal(t, int32(8080), policy.Spec.Ingress[0].Ports[0].Port.IntVal)
+
+	// DNS is allowed before the rules of the config
+	assert.Equal(t, 2, len(policy.Spec.Egress))
+	assert.Equal(t, int32(dnsPort), policy.Spec.Egress[0].Ports[0].Port.IntVal)
+	assert.Equal(t, "10.0.0.0/8", policy.Spec.Egress[1].To[0].IPBlock.CIDR)
+	assert.Equal(t, []string{"10.1.0.0/16"}, policy.Spec.Egress[1].To[0].IPBlock.Except)
+}
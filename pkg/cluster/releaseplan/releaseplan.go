@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"fmt"
+	"sort"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// Item is a cluster to release along with the clusters which must be released and healthy before it
+type Item struct {
+	ClusterID uint   `json:"clusterID"`
+	DependsOn []uint `json:"dependsOn,omitempty"`
+}
+
+// Stages groups the clusters into stages in dependency order: every cluster is placed in the stage
+// right after the last of its dependencies, so clusters in the same stage never depend on each other
+// and can be released together. Clusters in a stage are sorted by id to keep the plan stable.
+func Stages(items []*Item) ([][]uint, error) {
+	if len(items) == 0 {
+		return nil, invalid("no cluster to release")
+	}
+
+	dependents := make(map[uint][]uint, len(items))
+	inDegrees := make(map[uint]int, len(items))
+	for _, item := range items {
+		if _, ok := inDegrees[item.ClusterID]; ok {
+			return nil, invalid(fmt.Sprintf("cluster %d is duplicated", item.ClusterID))
+		}
+		inDegrees[item.ClusterID] = 0
+	}
+	for _, item := range items {
+		seen := make(map[uint]bool, len(item.DependsOn))
+		for _, dep := range item.DependsOn {
+			if dep == item.ClusterID {
+				return nil, invalid(fmt.Sprintf("cluster %d depends on itself", dep))
+			}
+			if _, ok := inDegrees[dep]; !ok {
+				return nil, invalid(fmt.Sprintf("cluster %d depends on cluster %d which is not in the release",
+					item.ClusterID, dep))
+			}
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			dependents[dep] = append(dependents[dep], item.ClusterID)
+			inDegrees[item.ClusterID]++
+		}
+	}
+
+	var stage []uint
+	for id, degree := range inDegrees {
+		if degree == 0 {
+			stage = append(stage, id)
+		}
+	}
+
+	var stages [][]uint
+	placed := 0
+	for len(stage) > 0 {
+		sort.Slice(stage, func(i, j int) bool { return stage[i] < stage[j] })
+		stages = append(stages, stage)
+		placed += len(stage)
+
+		var next []uint
+		for _, id := range stage {
+			for _, dependent := range dependents[id] {
+				inDegrees[dependent]--
+				if inDegrees[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		stage = next
+	}
+	if placed != len(items) {
+		return nil, invalid("dependencies of the clusters form a cycle")
+	}
+	return stages, nil
+}
+
+func invalid(msg string) error {
+	return perror.Wrap(herrors.ErrParamInvalid, msg)
+}
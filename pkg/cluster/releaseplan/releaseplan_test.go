@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestStages(t *testing.T) {
+	// db and cache first, then the services, then the gateway
+	stages, err := Stages([]*Item{
+		{ClusterID: 5, DependsOn: []uint{3, 4}},
+		{ClusterID: 4, DependsOn: []uint{1}},
+		{ClusterID: 3, DependsOn: []uint{1, 2, 2}},
+		{ClusterID: 2},
+		{ClusterID: 1},
+		{ClusterID: 6, DependsOn: []uint{1}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]uint{{1, 2}, {3, 4, 6}, {5}}, stages)
+
+	stages, err = Stages([]*Item{{ClusterID: 2}, {ClusterID: 1}})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]uint{{1, 2}}, stages)
+
+	invalid := [][]*Item{
+		nil,
+		{{ClusterID: 1}, {ClusterID: 1}},
+		{{ClusterID: 1, DependsOn: []uint{1}}},
+		{{ClusterID: 1, DependsOn: []uint{2}}},
+		{{ClusterID: 1, DependsOn: []uint{3}}, {ClusterID: 2, DependsOn: []uint{1}}, {ClusterID: 3, DependsOn: []uint{2}}},
+	}
+	for _, items := range invalid {
+		_, err := Stages(items)
+		assert.NotNil(t, err)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	ProbeTypeHTTP = "http"
+	ProbeTypeTCP  = "tcp"
+	ProbeTypeExec = "exec"
+
+	DefaultMaxSurge                      = "25%"
+	DefaultMaxUnavailable                = "0"
+	DefaultPreStopSleepSeconds           = 10
+	DefaultTerminationGracePeriodSeconds = 30
+
+	defaultProbePeriodSeconds    = 10
+	defaultProbeTimeoutSeconds   = 1
+	defaultProbeSuccessThreshold = 1
+	defaultProbeFailureThreshold = 3
+)
+
+var intOrPercentPattern = regexp.MustCompile(`^(\d+)(%?)$`)
+
+// Config describes how the workloads of a cluster are rolled out without dropping traffic.
+// It's rendered into the horizon value file, so that templates can consume it as .Values.horizon.rollout
+type Config struct {
+	ReadinessProbe                *Probe         `json:"readinessProbe,omitempty" yaml:"readinessProbe,omitempty"`
+	LivenessProbe                 *Probe         `json:"livenessProbe,omitempty" yaml:"livenessProbe,omitempty"`
+	StartupProbe                  *Probe         `json:"startupProbe,omitempty" yaml:"startupProbe,omitempty"`
+	PreStop                       *PreStop       `json:"preStop,omitempty" yaml:"preStop,omitempty"`
+	RollingUpdate                 *RollingUpdate `json:"rollingUpdate,omitempty" yaml:"rollingUpdate,omitempty"`
+	TerminationGracePeriodSeconds int64          `json:"terminationGracePeriodSeconds" yaml:"terminationGracePeriodSeconds"`
+	// Strategy is the deploy strategy, nil means rolling
+	Strategy *Strategy `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+}
+
+type Probe struct {
+	// Type is one of http, tcp and exec
+	Type    string   `json:"type" yaml:"type"`
+	Path    string   `json:"path,omitempty" yaml:"path,omitempty"`
+	Port    int32    `json:"port,omitempty" yaml:"port,omitempty"`
+	Command []string `json:"command,omitempty" yaml:"command,omitempty"`
+
+	InitialDelaySeconds int32 `json:"initialDelaySeconds" yaml:"initialDelaySeconds"`
+	PeriodSeconds       int32 `json:"periodSeconds" yaml:"periodSeconds"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds" yaml:"timeoutSeconds"`
+	SuccessThreshold    int32 `json:"successThreshold" yaml:"successThreshold"`
+	FailureThreshold    int32 `json:"failureThreshold" yaml:"failureThreshold"`
+}
+
+// PreStop is executed before the container is stopped, either sleeps or runs the command,
+// which gives load balancers time to remove the pod before it stops serving
+type PreStop struct {
+	SleepSeconds int64    `json:"sleepSeconds,omitempty" yaml:"sleepSeconds,omitempty"`
+	Command      []string `json:"command,omitempty" yaml:"command,omitempty"`
+}
+
+// RollingUpdate values are absolute numbers or percentages, such as "1" or "25%"
+type RollingUpdate struct {
+	MaxSurge       string `json:"maxSurge" yaml:"maxSurge"`
+	MaxUnavailable string `json:"maxUnavailable" yaml:"maxUnavailable"`
+}
+
+// Default returns the config used when a cluster does not specify one
+func Default() *Config {
+	c := &Config{}
+	c.SetDefaults()
+	return c
+}
+
+// SetDefaults fills the fields which are not specified.
+// Probes are not added since the port or path to check depends on the workload.
+func (c *Config) SetDefaults() {
+	for _, p := range []*Probe{c.ReadinessProbe, c.LivenessProbe, c.StartupProbe} {
+		if p != nil {
+			p.setDefaults()
+		}
+	}
+	if c.TerminationGracePeriodSeconds == 0 {
+		c.TerminationGracePeriodSeconds = DefaultTerminationGracePeriodSeconds
+	}
+	if c.PreStop == nil {
+		c.PreStop = &PreStop{SleepSeconds: DefaultPreStopSleepSeconds}
+	}
+	if c.RollingUpdate == nil {
+		c.RollingUpdate = &RollingUpdate{}
+	}
+	if c.RollingUpdate.MaxSurge == "" {
+		c.RollingUpdate.MaxSurge = DefaultMaxSurge
+	}
+	if c.RollingUpdate.MaxUnavailable == "" {
+		c.RollingUpdate.MaxUnavailable = DefaultMaxUnavailable
+	}
+	if c.Strategy != nil {
+		c.Strategy.setDefaults()
+	}
+}
+
+func (p *Probe) setDefaults() {
+	if p.PeriodSeconds == 0 {
+		p.PeriodSeconds = defaultProbePeriodSeconds
+	}
+	if p.TimeoutSeconds == 0 {
+		p.TimeoutSeconds = defaultProbeTimeoutSeconds
+	}
+	if p.SuccessThreshold == 0 {
+		p.SuccessThreshold = defaultProbeSuccessThreshold
+	}
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = defaultProbeFailureThreshold
+	}
+}
+
+// Validate checks the config after defaults are set, the rules follow what kubernetes accepts
+func (c *Config) Validate() error {
+	if err := c.ReadinessProbe.validate("readinessProbe", false); err != nil {
+		return err
+	}
+	// liveness and startup probes must have successThreshold 1
+	if err := c.LivenessProbe.validate("livenessProbe", true); err != nil {
+		return err
+	}
+	if err := c.StartupProbe.validate("startupProbe", true); err != nil {
+		return err
+	}
+
+	if c.TerminationGracePeriodSeconds < 0 {
+		return invalid("terminationGracePeriodSeconds must not be negative")
+	}
+	if c.PreStop != nil {
+		if c.PreStop.SleepSeconds < 0 {
+			return invalid("preStop.sleepSeconds must not be negative")
+		}
+		if c.PreStop.SleepSeconds > 0 && len(c.PreStop.Command) > 0 {
+			return invalid("preStop.sleepSeconds and preStop.command cannot be both specified")
+		}
+		if c.PreStop.SleepSeconds >= c.TerminationGracePeriodSeconds {
+			return invalid(fmt.Sprintf("preStop.sleepSeconds %d must be less than terminationGracePeriodSeconds %d",
+				c.PreStop.SleepSeconds, c.TerminationGracePeriodSeconds))
+		}
+	}
+
+	if c.RollingUpdate != nil {
+		surge, err := parseIntOrPercent("rollingUpdate.maxSurge", c.RollingUpdate.MaxSurge)
+		if err != nil {
+			return err
+		}
+		unavailable, err := parseIntOrPercent("rollingUpdate.maxUnavailable", c.RollingUpdate.MaxUnavailable)
+		if err != nil {
+			return err
+		}
+		if surge == 0 && unavailable == 0 {
+			return invalid("rollingUpdate.maxSurge and rollingUpdate.maxUnavailable cannot be both 0")
+		}
+	}
+
+	if c.Strategy != nil {
+		return c.Strategy.validate()
+	}
+	return nil
+}
+
+func (p *Probe) validate(field string, requireSingleSuccess bool) error {
+	if p == nil {
+		return nil
+	}
+	switch p.Type {
+	case ProbeTypeHTTP:
+		if !strings.HasPrefix(p.Path, "/") {
+			return invalid(fmt.Sprintf("%s.path must start with /", field))
+		}
+		if err := validatePort(field, p.Port); err != nil {
+			return err
+		}
+	case ProbeTypeTCP:
+		if err := validatePort(field, p.Port); err != nil {
+			return err
+		}
+	case ProbeTypeExec:
+		if len(p.Command) == 0 {
+			return invalid(fmt.Sprintf("%s.command is required for exec probe", field))
+		}
+	default:
+		return invalid(fmt.Sprintf("%s.type must be one of %s, %s and %s",
+			field, ProbeTypeHTTP, ProbeTypeTCP, ProbeTypeExec))
+	}
+
+	if p.InitialDelaySeconds < 0 {
+		return invalid(fmt.Sprintf("%s.initialDelaySeconds must not be negative", field))
+	}
+	for name, value := range map[string]int32{
+		"periodSeconds":    p.PeriodSeconds,
+		"timeoutSeconds":   p.TimeoutSeconds,
+		"successThreshold": p.SuccessThreshold,
+		"failureThreshold": p.FailureThreshold,
+	} {
+		if value < 1 {
+			return invalid(fmt.Sprintf("%s.%s must be at least 1", field, name))
+		}
+	}
+	if requireSingleSuccess && p.SuccessThreshold != 1 {
+		return invalid(fmt.Sprintf("%s.successThreshold must be 1", field))
+	}
+	return nil
+}
+
+func validatePort(field string, port int32) error {
+	if port < 1 || port > 65535 {
+		return invalid(fmt.Sprintf("%s.port must be between 1 and 65535", field))
+	}
+	return nil
+}
+
+// parseIntOrPercent returns the number of an absolute value or a percentage
+func parseIntOrPercent(field, value string) (int, error) {
+	matches := intOrPercentPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, invalid(fmt.Sprintf("%s must be a number or a percentage, but got %q", field, value))
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, invalid(fmt.Sprintf("%s is invalid: %v", field, err))
+	}
+	if matches[2] == "%" && n > 100 {
+		return 0, invalid(fmt.Sprintf("%s must not be greater than 100%%", field))
+	}
+	return n, nil
+}
+
+func invalid(msg string) error {
+	return perror.Wrap(herrors.ErrParamInvalid, msg)
+}
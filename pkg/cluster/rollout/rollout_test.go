@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestDefault(t *testing.T) {
+	c := Default()
+	assert.Nil(t, c.Validate())
+	assert.Nil(t, c.ReadinessProbe)
+	assert.Equal(t, int64(DefaultTerminationGracePeriodSeconds), c.TerminationGracePeriodSeconds)
+	assert.Equal(t, int64(DefaultPreStopSleepSeconds), c.PreStop.SleepSeconds)
+	assert.Equal(t, DefaultMaxSurge, c.RollingUpdate.MaxSurge)
+	assert.Equal(t, DefaultMaxUnavailable, c.RollingUpdate.MaxUnavailable)
+}
+
+func TestSetDefaults(t *testing.T) {
+	c := &Config{
+		ReadinessProbe: &Probe{
+			Type: ProbeTypeHTTP,
+			Path: "/health",
+			Port: 8080,
+		},
+		RollingUpdate: &RollingUpdate{
+			MaxSurge: "1",
+		},
+	}
+	c.SetDefaults()
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, int32(10), c.ReadinessProbe.PeriodSeconds)
+	assert.Equal(t, int32(1), c.ReadinessProbe.TimeoutSeconds)
+	assert.Equal(t, int32(1), c.ReadinessProbe.SuccessThreshold)
+	assert.Equal(t, int32(3), c.ReadinessProbe.FailureThreshold)
+	assert.Equal(t, "1", c.RollingUpdate.MaxSurge)
+	assert.Equal(t, DefaultMaxUnavailable, c.RollingUpdate.MaxUnavailable)
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]*Config{
+		"unknown probe type": {
+			ReadinessProbe: &Probe{Type: "grpc", Port: 8080},
+		},
+		"http probe without path": {
+			ReadinessProbe: &Probe{Type: ProbeTypeHTTP, Port: 8080},
+		},
+		"tcp probe with invalid port": {
+			LivenessProbe: &Probe{Type: ProbeTypeTCP, Port: 70000},
+		},
+		"exec probe without command": {
+			StartupProbe: &Probe{Type: ProbeTypeExec},
+		},
+		"liveness probe success threshold": {
+			LivenessProbe: &Probe{Type: ProbeTypeTCP, Port: 8080, SuccessThreshold: 2},
+		},
+		"preStop longer than grace period": {
+			PreStop:                       &PreStop{SleepSeconds: 30},
+			TerminationGracePeriodSeconds: 30,
+		},
+		"preStop sleep and command": {
+			PreStop: &PreStop{SleepSeconds: 5, Command: []string{"/bin/offline.sh"}},
+		},
+		"invalid surge": {
+			RollingUpdate: &RollingUpdate{MaxSurge: "two"},
+		},
+		"percentage over 100": {
+			RollingUpdate: &RollingUpdate{MaxSurge: "120%"},
+		},
+		"zero surge and unavailable": {
+			RollingUpdate: &RollingUpdate{MaxSurge: "0%", MaxUnavailable: "0"},
+		},
+	}
+	for name, c := range cases {
+		c.SetDefaults()
+		err := c.Validate()
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), name)
+	}
+}
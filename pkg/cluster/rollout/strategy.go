@@ -0,0 +1,154 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// StrategyRolling replaces all pods by the rolling update of the workload
+	StrategyRolling = "rolling"
+	// StrategyBatch releases pods in batches of the same size
+	StrategyBatch = "batch"
+	// StrategyCanary releases pods by percentage steps
+	StrategyCanary = "canary"
+	// StrategyBlueGreen brings up the new revision beside the old one, and switches traffic on promotion
+	StrategyBlueGreen = "blueGreen"
+
+	DefaultProgressDeadlineSeconds = 600
+
+	maxBatches = 100
+)
+
+// Strategy describes how the new revision of a cluster replaces the old one on deploy.
+// Except rolling, strategies are carried out step by step by argo rollouts, which can be paused,
+// resumed, promoted and aborted. The templates consume it as .Values.horizon.rollout.strategy,
+// in which steps are always filled for batch and canary strategies.
+type Strategy struct {
+	Type string `json:"type" yaml:"type"`
+	// Batches is the number of batches for batch strategy
+	Batches int32 `json:"batches,omitempty" yaml:"batches,omitempty"`
+	// BatchPauseSeconds is how long to wait between batches, nil waits for manual promotion
+	BatchPauseSeconds *int32 `json:"batchPauseSeconds,omitempty" yaml:"batchPauseSeconds,omitempty"`
+	// Steps are the percentage steps of canary strategy, and are generated from batches for batch strategy
+	Steps []Step `json:"steps,omitempty" yaml:"steps,omitempty"`
+	// AutoPromotionSeconds promotes the new revision of blueGreen strategy after the seconds,
+	// 0 waits for manual promotion
+	AutoPromotionSeconds int32 `json:"autoPromotionSeconds,omitempty" yaml:"autoPromotionSeconds,omitempty"`
+	// ScaleDownDelaySeconds is how long the old revision of blueGreen strategy is kept after promotion
+	ScaleDownDelaySeconds int32 `json:"scaleDownDelaySeconds,omitempty" yaml:"scaleDownDelaySeconds,omitempty"`
+	// AutoRollback aborts the rollout if the new revision is not healthy within ProgressDeadlineSeconds,
+	// the old revision is scaled back and takes all traffic then
+	AutoRollback            bool  `json:"autoRollback" yaml:"autoRollback"`
+	ProgressDeadlineSeconds int32 `json:"progressDeadlineSeconds,omitempty" yaml:"progressDeadlineSeconds,omitempty"`
+}
+
+type Step struct {
+	// Weight is the percentage of pods running the new revision
+	Weight int32 `json:"weight" yaml:"weight"`
+	// PauseSeconds is how long to wait before the next step, nil waits for manual promotion
+	PauseSeconds *int32 `json:"pauseSeconds,omitempty" yaml:"pauseSeconds,omitempty"`
+}
+
+// Stepped returns whether the strategy is carried out step by step
+func (s *Strategy) Stepped() bool {
+	return s != nil && s.Type != "" && s.Type != StrategyRolling
+}
+
+func (s *Strategy) setDefaults() {
+	if s.Type == "" {
+		s.Type = StrategyRolling
+	}
+	if s.AutoRollback && s.ProgressDeadlineSeconds == 0 {
+		s.ProgressDeadlineSeconds = DefaultProgressDeadlineSeconds
+	}
+	if s.Type == StrategyBatch && s.Batches > 1 && s.Batches <= maxBatches {
+		s.Steps = batchSteps(s.Batches, s.BatchPauseSeconds)
+	}
+}
+
+// batchSteps splits pods into batches, the last batch is not paused since the rollout completes with it
+func batchSteps(batches int32, pauseSeconds *int32) []Step {
+	steps := make([]Step, 0, batches)
+	for i := int32(1); i <= batches; i++ {
+		step := Step{Weight: int32(math.Ceil(float64(i) * 100 / float64(batches)))}
+		if i < batches {
+			step.PauseSeconds = pauseSeconds
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func (s *Strategy) validate() error {
+	switch s.Type {
+	case StrategyRolling:
+		if s.Batches != 0 || len(s.Steps) > 0 {
+			return invalid("strategy.batches and strategy.steps are not supported by rolling strategy")
+		}
+	case StrategyBatch:
+		if s.Batches < 2 || s.Batches > maxBatches {
+			return invalid(fmt.Sprintf("strategy.batches must be between 2 and %d", maxBatches))
+		}
+		if s.BatchPauseSeconds != nil && *s.BatchPauseSeconds < 0 {
+			return invalid("strategy.batchPauseSeconds must not be negative")
+		}
+	case StrategyCanary:
+		if len(s.Steps) == 0 {
+			return invalid("strategy.steps is required by canary strategy")
+		}
+		if err := validateSteps(s.Steps); err != nil {
+			return err
+		}
+	case StrategyBlueGreen:
+		if s.Batches != 0 || len(s.Steps) > 0 {
+			return invalid("strategy.batches and strategy.steps are not supported by blueGreen strategy")
+		}
+		if s.AutoPromotionSeconds < 0 {
+			return invalid("strategy.autoPromotionSeconds must not be negative")
+		}
+		if s.ScaleDownDelaySeconds < 0 {
+			return invalid("strategy.scaleDownDelaySeconds must not be negative")
+		}
+	default:
+		return invalid(fmt.Sprintf("strategy.type must be one of %s, %s, %s and %s",
+			StrategyRolling, StrategyBatch, StrategyCanary, StrategyBlueGreen))
+	}
+
+	if s.ProgressDeadlineSeconds < 0 {
+		return invalid("strategy.progressDeadlineSeconds must not be negative")
+	}
+	return nil
+}
+
+// validateSteps makes sure the weights keep increasing within 1 to 100
+func validateSteps(steps []Step) error {
+	last := int32(0)
+	for i, step := range steps {
+		if step.Weight < 1 || step.Weight > 100 {
+			return invalid(fmt.Sprintf("strategy.steps[%d].weight must be between 1 and 100", i))
+		}
+		if step.Weight <= last {
+			return invalid(fmt.Sprintf("strategy.steps[%d].weight must be greater than the previous step", i))
+		}
+		if step.PauseSeconds != nil && *step.PauseSeconds < 0 {
+			return invalid(fmt.Sprintf("strategy.steps[%d].pauseSeconds must not be negative", i))
+		}
+		last = step.Weight
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestStrategySetDefaults(t *testing.T) {
+	c := &Config{Strategy: &Strategy{}}
+	c.SetDefaults()
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, StrategyRolling, c.Strategy.Type)
+	assert.False(t, c.Strategy.Stepped())
+	assert.False(t, Default().Strategy.Stepped())
+
+	pauseSeconds := int32(60)
+	c = &Config{Strategy: &Strategy{
+		Type:              StrategyBatch,
+		Batches:           3,
+		BatchPauseSeconds: &pauseSeconds,
+		AutoRollback:      true,
+	}}
+	c.SetDefaults()
+	assert.Nil(t, c.Validate())
+	assert.True(t, c.Strategy.Stepped())
+	assert.Equal(t, int32(DefaultProgressDeadlineSeconds), c.Strategy.ProgressDeadlineSeconds)
+	assert.Equal(t, []Step{
+		{Weight: 34, PauseSeconds: &pauseSeconds},
+		{Weight: 67, PauseSeconds: &pauseSeconds},
+		{Weight: 100},
+	}, c.Strategy.Steps)
+
+	c = &Config{Strategy: &Strategy{
+		Type:  StrategyCanary,
+		Steps: []Step{{Weight: 10}, {Weight: 50, PauseSeconds: &pauseSeconds}, {Weight: 100}},
+	}}
+	c.SetDefaults()
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, int32(0), c.Strategy.ProgressDeadlineSeconds)
+	assert.Equal(t, 3, len(c.Strategy.Steps))
+}
+
+func TestStrategyValidate(t *testing.T) {
+	negative := int32(-1)
+	cases := map[string]*Strategy{
+		"unknown type":                  {Type: "recreate"},
+		"rolling with steps":            {Type: StrategyRolling, Steps: []Step{{Weight: 50}}},
+		"batch with one batch":          {Type: StrategyBatch, Batches: 1},
+		"batch with too many batches":   {Type: StrategyBatch, Batches: 101},
+		"batch with negative pause":     {Type: StrategyBatch, Batches: 2, BatchPauseSeconds: &negative},
+		"canary without steps":          {Type: StrategyCanary},
+		"canary with zero weight":       {Type: StrategyCanary, Steps: []Step{{Weight: 0}}},
+		"canary with weight over 100":   {Type: StrategyCanary, Steps: []Step{{Weight: 120}}},
+		"canary with decreasing weight": {Type: StrategyCanary, Steps: []Step{{Weight: 50}, {Weight: 20}}},
+		"canary with negative pause":    {Type: StrategyCanary, Steps: []Step{{Weight: 50, PauseSeconds: &negative}}},
+		"blueGreen with steps":          {Type: StrategyBlueGreen, Steps: []Step{{Weight: 50}}},
+		"blueGreen with negative delay": {Type: StrategyBlueGreen, ScaleDownDelaySeconds: -1},
+		"negative progress deadline":    {Type: StrategyBlueGreen, ProgressDeadlineSeconds: -1},
+	}
+	for name, s := range cases {
+		c := &Config{Strategy: s}
+		c.SetDefaults()
+		err := c.Validate()
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), name)
+	}
+}
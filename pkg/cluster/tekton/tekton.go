@@ -68,8 +68,33 @@ type (
 		PipelineJSONBlob map[string]interface{} `json:"pipelineJSONBlob"`
 		Region           string                 `json:"region"`
 		RegionID         uint                   `json:"regionID"`
-		Template         string                 `json:"template"`
-		Token            string                 `json:"token"`
+		// RerunFrom is set when the pipelinerun reruns another one from a step,
+		// the pipeline skips the steps before it and reuses the artifacts of the pipelinerun rerun
+		RerunFrom *PipelineRunRerunFrom `json:"rerunFrom,omitempty"`
+		Template  string                `json:"template"`
+		Token     string                `json:"token"`
+		// CustomSteps are the steps declared by the template and enabled for the application,
+		// the pipeline runs them in their stages besides the built-in steps
+		CustomSteps []*PipelineRunStep `json:"customSteps,omitempty"`
+	}
+	PipelineRunStep struct {
+		Name  string `json:"name"`
+		Stage string `json:"stage"`
+		Image string `json:"image"`
+		// Script is run as the script of the tekton step
+		Script  string           `json:"script"`
+		Env     []PipelineRunEnv `json:"env,omitempty"`
+		Timeout string           `json:"timeout,omitempty"`
+	}
+	PipelineRunEnv struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	PipelineRunRerunFrom struct {
+		PipelinerunID uint   `json:"pipelinerunID"`
+		CIEventID     string `json:"ciEventID"`
+		Task          string `json:"task"`
+		Step          string `json:"step"`
 	}
 	PipelineRunGit struct {
 		URL       string `json:"url"`
@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/clusterenv/models"
+	"github.com/horizoncd/horizon/pkg/common"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, change *models.ClusterEnvChange) (*models.ClusterEnvChange, error)
+	ListByClusterID(ctx context.Context, clusterID uint, name string,
+		query *q.Query) (int, []*models.ClusterEnvChange, error)
+	ListSince(ctx context.Context, clusterID uint, since time.Time) ([]*models.ClusterEnvChange, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, change *models.ClusterEnvChange) (*models.ClusterEnvChange, error) {
+	result := d.db.WithContext(ctx).Create(change)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ClusterEnvChangeInDB, result.Error.Error())
+	}
+	return change, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint, name string,
+	query *q.Query) (int, []*models.ClusterEnvChange, error) {
+	sql := d.db.WithContext(ctx).Table("tb_cluster_env_change").Where("cluster_id = ?", clusterID)
+	if name != "" {
+		sql = sql.Where("name = ?", name)
+	}
+	sql = sql.Order("id desc")
+
+	var total int64
+	result := sql.Count(&total)
+	if result.Error != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.ClusterEnvChangeInDB, result.Error.Error())
+	}
+
+	var changes []*models.ClusterEnvChange
+	result = sql.Limit(query.Limit()).Offset(query.Offset()).Find(&changes)
+	if result.Error != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.ClusterEnvChangeInDB, result.Error.Error())
+	}
+	return int(total), changes, nil
+}
+
+func (d *dao) ListSince(ctx context.Context, clusterID uint,
+	since time.Time) ([]*models.ClusterEnvChange, error) {
+	var changes []*models.ClusterEnvChange
+	result := d.db.WithContext(ctx).Raw(common.ClusterEnvChangeListSince, clusterID, since).Scan(&changes)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterEnvChangeInDB, result.Error.Error())
+	}
+	return changes, nil
+}
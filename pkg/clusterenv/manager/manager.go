@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/clusterenv/dao"
+	"github.com/horizoncd/horizon/pkg/clusterenv/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, change *models.ClusterEnvChange) (*models.ClusterEnvChange, error)
+	// ListByClusterID lists the env changes of the cluster, the latest first.
+	// Only changes of the variable are listed if name is not empty.
+	ListByClusterID(ctx context.Context, clusterID uint, name string,
+		query *q.Query) (int, []*models.ClusterEnvChange, error)
+	// ListSince lists the env changes of the cluster made after the time, the earliest first
+	ListSince(ctx context.Context, clusterID uint, since time.Time) ([]*models.ClusterEnvChange, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, change *models.ClusterEnvChange) (*models.ClusterEnvChange, error) {
+	return m.dao.Create(ctx, change)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint, name string,
+	query *q.Query) (int, []*models.ClusterEnvChange, error) {
+	if query == nil {
+		query = &q.Query{}
+	}
+	return m.dao.ListByClusterID(ctx, clusterID, name, query)
+}
+
+func (m *manager) ListSince(ctx context.Context, clusterID uint,
+	since time.Time) ([]*models.ClusterEnvChange, error) {
+	return m.dao.ListSince(ctx, clusterID, since)
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/clusterenv/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ClusterEnvChange{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	now := time.Now()
+	changes := []*models.ClusterEnvChange{
+		{ClusterID: 1, Name: "A", Action: models.ActionAdded, NewValue: "1", CreatedAt: now.Add(-3 * time.Hour)},
+		{ClusterID: 1, Name: "A", Action: models.ActionUpdated, OldValue: "1", NewValue: "2",
+			CreatedAt: now.Add(-2 * time.Hour)},
+		{ClusterID: 1, Name: "B", Action: models.ActionAdded, NewValue: "x", CreatedAt: now.Add(-time.Hour)},
+		{ClusterID: 2, Name: "A", Action: models.ActionDeleted, OldValue: "1", CreatedAt: now},
+	}
+	for _, change := range changes {
+		_, err := mgr.Create(ctx, change)
+		assert.Nil(t, err)
+	}
+
+	total, list, err := mgr.ListByClusterID(ctx, 1, "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, "B", list[0].Name)
+
+	total, list, err = mgr.ListByClusterID(ctx, 1, "A", &q.Query{PageNumber: 1, PageSize: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, models.ActionUpdated, list[0].Action)
+
+	list, err = mgr.ListSince(ctx, 1, now.Add(-150*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "A", list[0].Name)
+	assert.Equal(t, "B", list[1].Name)
+}
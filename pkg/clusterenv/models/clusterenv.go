@@ -0,0 +1,40 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	ActionAdded   = "added"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// ClusterEnvChange records a change of an environment variable of a cluster,
+// values of secret variables are masked before saved
+type ClusterEnvChange struct {
+	ID        uint
+	ClusterID uint   `gorm:"index:idx_cluster_id_name"`
+	Name      string `gorm:"index:idx_cluster_id_name"`
+	Action    string
+	Type      string
+	Secret    bool
+	OldValue  string
+	NewValue  string
+	// ConfigCommit is the head commit of the gitops branch which the change is made on
+	ConfigCommit string
+	CreatedAt    time.Time
+	CreatedBy    uint
+}
@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	"github.com/horizoncd/horizon/pkg/common"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, snapshot *models.ClusterSnapshot) (*models.ClusterSnapshot, error)
+	GetByID(ctx context.Context, id uint) (*models.ClusterSnapshot, error)
+	GetLatestByClusterID(ctx context.Context, clusterID uint) (*models.ClusterSnapshot, error)
+	ListByClusterID(ctx context.Context, clusterID uint, query *q.Query) (int, []*models.ClusterSnapshot, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, snapshot *models.ClusterSnapshot) (*models.ClusterSnapshot, error) {
+	result := d.db.WithContext(ctx).Create(snapshot)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ClusterSnapshotInDB, result.Error.Error())
+	}
+	return snapshot, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.ClusterSnapshot, error) {
+	var snapshot models.ClusterSnapshot
+	result := d.db.WithContext(ctx).Raw(common.ClusterSnapshotGetByID, id).Scan(&snapshot)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterSnapshotInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ClusterSnapshotInDB,
+			fmt.Sprintf("cluster snapshot with id %d not found", id))
+	}
+	return &snapshot, nil
+}
+
+func (d *dao) GetLatestByClusterID(ctx context.Context, clusterID uint) (*models.ClusterSnapshot, error) {
+	var snapshot models.ClusterSnapshot
+	result := d.db.WithContext(ctx).Raw(common.ClusterSnapshotGetLatestByCluster, clusterID).Scan(&snapshot)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterSnapshotInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ClusterSnapshotInDB,
+			fmt.Sprintf("no snapshot found for cluster %d", clusterID))
+	}
+	return &snapshot, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint,
+	query *q.Query) (int, []*models.ClusterSnapshot, error) {
+	sql := d.db.WithContext(ctx).Table("tb_cluster_snapshot").Where("cluster_id = ?", clusterID).
+		Order("id desc")
+
+	var total int64
+	result := sql.Count(&total)
+	if result.Error != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.ClusterSnapshotInDB, result.Error.Error())
+	}
+
+	var snapshots []*models.ClusterSnapshot
+	result = sql.Limit(query.Limit()).Offset(query.Offset()).Find(&snapshots)
+	if result.Error != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.ClusterSnapshotInDB, result.Error.Error())
+	}
+	return int(total), snapshots, nil
+}
+
+func (d *dao) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := d.db.WithContext(ctx).Exec(common.ClusterSnapshotDeleteBefore, before)
+	if result.Error != nil {
+		return 0, herrors.NewErrDeleteFailed(herrors.ClusterSnapshotInDB, result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
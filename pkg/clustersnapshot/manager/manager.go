@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/clustersnapshot/dao"
+	"github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, snapshot *models.ClusterSnapshot) (*models.ClusterSnapshot, error)
+	GetByID(ctx context.Context, id uint) (*models.ClusterSnapshot, error)
+	// GetLatestByClusterID returns the latest snapshot of the cluster, or a not found error
+	GetLatestByClusterID(ctx context.Context, clusterID uint) (*models.ClusterSnapshot, error)
+	// ListByClusterID lists the snapshots of the cluster, the latest first
+	ListByClusterID(ctx context.Context, clusterID uint, query *q.Query) (int, []*models.ClusterSnapshot, error)
+	// DeleteBefore deletes the snapshots created before the time, and returns the count of deleted snapshots
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, snapshot *models.ClusterSnapshot) (*models.ClusterSnapshot, error) {
+	return m.dao.Create(ctx, snapshot)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.ClusterSnapshot, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) GetLatestByClusterID(ctx context.Context, clusterID uint) (*models.ClusterSnapshot, error) {
+	return m.dao.GetLatestByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint,
+	query *q.Query) (int, []*models.ClusterSnapshot, error) {
+	if query == nil {
+		query = &q.Query{}
+	}
+	return m.dao.ListByClusterID(ctx, clusterID, query)
+}
+
+func (m *manager) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.dao.DeleteBefore(ctx, before)
+}
@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ClusterSnapshot{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetLatestByClusterID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	for i, commit := range []string{"c1", "c2", "c3"} {
+		_, err := mgr.Create(ctx, &models.ClusterSnapshot{
+			ClusterID:    1,
+			TriggerType:  models.TriggerDeploy,
+			ConfigCommit: commit,
+			Image:        "image:v1",
+			CreatedAt:    time.Now().Add(time.Duration(i-3) * time.Hour),
+		})
+		assert.Nil(t, err)
+	}
+	other, err := mgr.Create(ctx, &models.ClusterSnapshot{
+		ClusterID:    2,
+		TriggerType:  models.TriggerSchedule,
+		ConfigCommit: "c4",
+	})
+	assert.Nil(t, err)
+
+	latest, err := mgr.GetLatestByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "c3", latest.ConfigCommit)
+
+	snapshot, err := mgr.GetByID(ctx, other.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), snapshot.ClusterID)
+	assert.Equal(t, models.TriggerSchedule, snapshot.TriggerType)
+
+	total, snapshots, err := mgr.ListByClusterID(ctx, 1, &q.Query{PageNumber: 1, PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 2, len(snapshots))
+	assert.Equal(t, "c3", snapshots[0].ConfigCommit)
+	assert.Equal(t, "c2", snapshots[1].ConfigCommit)
+
+	deleted, err := mgr.DeleteBefore(ctx, time.Now().Add(-90*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), deleted)
+	total, _, err = mgr.ListByClusterID(ctx, 1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+
+	_, err = mgr.GetByID(ctx, 100)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	// TriggerDeploy means the snapshot is taken before a deploy
+	TriggerDeploy = "deploy"
+	// TriggerSchedule means the snapshot is taken by the daily job
+	TriggerSchedule = "schedule"
+	// TriggerRestore means the snapshot is taken before restoring another snapshot
+	TriggerRestore = "restore"
+)
+
+// ClusterSnapshot records the gitops commit and the metadata of a cluster at a moment,
+// the cluster can be restored to a snapshot later
+type ClusterSnapshot struct {
+	ID            uint
+	ClusterID     uint `gorm:"index:idx_cluster_id"`
+	TriggerType   string
+	PipelinerunID uint
+	// ConfigCommit is the head commit of the gitops branch of the cluster
+	ConfigCommit    string
+	Template        string
+	TemplateRelease string
+	GitURL          string
+	GitSubfolder    string
+	GitRefType      string
+	GitRef          string
+	Image           string
+	// Tags is the json of the tags of the cluster
+	Tags      string
+	CreatedAt time.Time
+	CreatedBy uint
+}
@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	snapshotmanager "github.com/horizoncd/horizon/pkg/clustersnapshot/manager"
+	"github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type Service interface {
+	// Take records the config commit and the metadata of the cluster as a snapshot.
+	// Snapshots triggered by schedule are skipped when nothing changed since the latest snapshot,
+	// in which case nil is returned.
+	Take(ctx context.Context, cluster *clustermodels.Cluster, configCommit, trigger string,
+		pipelinerunID uint) (*models.ClusterSnapshot, error)
+	// TakeBeforeDeploy takes a snapshot before a deploy, errors are logged and ignored
+	// so that deploys are never blocked by snapshots
+	TakeBeforeDeploy(ctx context.Context, cluster *clustermodels.Cluster, configCommit string, pipelinerunID uint)
+}
+
+type service struct {
+	snapshotMgr snapshotmanager.Manager
+	tagMgr      tagmanager.Manager
+}
+
+func NewService(manager *managerparam.Manager) Service {
+	return &service{
+		snapshotMgr: manager.ClusterSnapshotMgr,
+		tagMgr:      manager.TagMgr,
+	}
+}
+
+func (s *service) Take(ctx context.Context, cluster *clustermodels.Cluster, configCommit, trigger string,
+	pipelinerunID uint) (*models.ClusterSnapshot, error) {
+	if configCommit == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"config commit of cluster %s is empty", cluster.Name)
+	}
+	tags, err := s.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	tagsJSON, err := json.Marshal(tagmodels.Tags(tags).IntoTagsBasic())
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	snapshot := &models.ClusterSnapshot{
+		ClusterID:       cluster.ID,
+		TriggerType:     trigger,
+		PipelinerunID:   pipelinerunID,
+		ConfigCommit:    configCommit,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		GitURL:          cluster.GitURL,
+		GitSubfolder:    cluster.GitSubfolder,
+		GitRefType:      cluster.GitRefType,
+		GitRef:          cluster.GitRef,
+		Image:           cluster.Image,
+		Tags:            string(tagsJSON),
+	}
+	if currentUser, err := common.UserFromContext(ctx); err == nil {
+		snapshot.CreatedBy = currentUser.GetID()
+	}
+
+	if trigger == models.TriggerSchedule {
+		latest, err := s.snapshotMgr.GetLatestByClusterID(ctx, cluster.ID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+		} else if sameState(latest, snapshot) {
+			return nil, nil
+		}
+	}
+	return s.snapshotMgr.Create(ctx, snapshot)
+}
+
+func (s *service) TakeBeforeDeploy(ctx context.Context, cluster *clustermodels.Cluster,
+	configCommit string, pipelinerunID uint) {
+	// pipelineruns without config commit, such as restarts, do not change the config
+	if configCommit == "" {
+		return
+	}
+	if _, err := s.Take(ctx, cluster, configCommit, models.TriggerDeploy, pipelinerunID); err != nil {
+		log.Warningf(ctx, "failed to take snapshot of cluster %s before deploy: %v", cluster.Name, err)
+	}
+}
+
+// sameState returns true if the two snapshots record the same config and metadata
+func sameState(a, b *models.ClusterSnapshot) bool {
+	return a.ConfigCommit == b.ConfigCommit &&
+		a.Template == b.Template &&
+		a.TemplateRelease == b.TemplateRelease &&
+		a.GitURL == b.GitURL &&
+		a.GitSubfolder == b.GitSubfolder &&
+		a.GitRefType == b.GitRefType &&
+		a.GitRef == b.GitRef &&
+		a.Image == b.Image &&
+		a.Tags == b.Tags
+}
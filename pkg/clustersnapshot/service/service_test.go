@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&tagmodels.Tag{}, &models.ClusterSnapshot{}))
+	callbacks.RegisterCustomCallbacks(db)
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	manager := managerparam.InitManager(db)
+	svc := NewService(manager)
+
+	cluster := &clustermodels.Cluster{
+		Name:            "cluster",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+		GitURL:          "ssh://git@github.com/demo/demo.git",
+		GitRefType:      "branch",
+		GitRef:          "master",
+		Image:           "demo:v1",
+	}
+	cluster.ID = 1
+	assert.Nil(t, manager.TagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, cluster.ID,
+		[]*tagmodels.TagBasic{{Key: "team", Value: "payment"}}))
+
+	_, err := svc.Take(ctx, cluster, "", models.TriggerDeploy, 1)
+	assert.NotNil(t, err)
+
+	svc.TakeBeforeDeploy(ctx, cluster, "c1", 1)
+	latest, err := manager.ClusterSnapshotMgr.GetLatestByClusterID(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.TriggerDeploy, latest.TriggerType)
+	assert.Equal(t, uint(1), latest.PipelinerunID)
+	assert.Equal(t, "c1", latest.ConfigCommit)
+	assert.Equal(t, "demo:v1", latest.Image)
+	assert.Equal(t, `[{"key":"team","value":"payment"}]`, latest.Tags)
+	assert.Equal(t, uint(1), latest.CreatedBy)
+
+	// scheduled snapshot is skipped when nothing changed
+	snapshot, err := svc.Take(ctx, cluster, "c1", models.TriggerSchedule, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, snapshot)
+
+	cluster.Image = "demo:v2"
+	snapshot, err = svc.Take(ctx, cluster, "c1", models.TriggerSchedule, 0)
+	assert.Nil(t, err)
+	assert.NotNil(t, snapshot)
+	assert.Equal(t, "demo:v2", snapshot.Image)
+
+	total, _, err := manager.ClusterSnapshotMgr.ListByClusterID(ctx, cluster.ID, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+}
@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/clustersummary/models"
+	"github.com/horizoncd/horizon/pkg/common"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	Upsert(ctx context.Context, summary *models.ClusterSummary) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+	ListByClusterIDs(ctx context.Context, clusterIDs []uint) ([]*models.ClusterSummary, error)
+	ListByApplicationIDs(ctx context.Context, applicationIDs []uint) ([]*models.ClusterSummary, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Upsert(ctx context.Context, summary *models.ClusterSummary) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "cluster_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"application_id", "environment_name", "region_name",
+			"template", "template_release", "pipelinerun_id", "pipelinerun_action", "pipelinerun_status",
+			"deployed_at", "updated_at"}),
+	}).Create(summary)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.ClusterSummaryInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	result := d.db.WithContext(ctx).Exec(common.ClusterSummaryDeleteByClusterID, clusterID)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.ClusterSummaryInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListByClusterIDs(ctx context.Context, clusterIDs []uint) ([]*models.ClusterSummary, error) {
+	var summaries []*models.ClusterSummary
+	if len(clusterIDs) == 0 {
+		return summaries, nil
+	}
+	result := d.db.WithContext(ctx).Raw(common.ClusterSummaryListByClusterIDs, clusterIDs).Scan(&summaries)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterSummaryInDB, result.Error.Error())
+	}
+	return summaries, nil
+}
+
+func (d *dao) ListByApplicationIDs(ctx context.Context,
+	applicationIDs []uint) ([]*models.ClusterSummary, error) {
+	var summaries []*models.ClusterSummary
+	if len(applicationIDs) == 0 {
+		return summaries, nil
+	}
+	result := d.db.WithContext(ctx).Raw(common.ClusterSummaryListByApplicationIDs,
+		applicationIDs).Scan(&summaries)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterSummaryInDB, result.Error.Error())
+	}
+	return summaries, nil
+}
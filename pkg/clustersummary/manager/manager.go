@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/clustersummary/dao"
+	"github.com/horizoncd/horizon/pkg/clustersummary/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// Upsert creates or updates the summary of the cluster
+	Upsert(ctx context.Context, summary *models.ClusterSummary) error
+	// DeleteByClusterID deletes the summary of the cluster
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+	// ListByClusterIDs lists summaries of clusters, and returns them keyed by cluster id
+	ListByClusterIDs(ctx context.Context, clusterIDs []uint) (map[uint]*models.ClusterSummary, error)
+	// ListByApplicationIDs lists summaries of clusters belonging to the applications,
+	// and returns them grouped by application id
+	ListByApplicationIDs(ctx context.Context, applicationIDs []uint) (map[uint][]*models.ClusterSummary, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Upsert(ctx context.Context, summary *models.ClusterSummary) error {
+	return m.dao.Upsert(ctx, summary)
+}
+
+func (m *manager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	return m.dao.DeleteByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListByClusterIDs(ctx context.Context,
+	clusterIDs []uint) (map[uint]*models.ClusterSummary, error) {
+	summaries, err := m.dao.ListByClusterIDs(ctx, clusterIDs)
+	if err != nil {
+		return nil, err
+	}
+	summaryMap := make(map[uint]*models.ClusterSummary, len(summaries))
+	for _, summary := range summaries {
+		summaryMap[summary.ClusterID] = summary
+	}
+	return summaryMap, nil
+}
+
+func (m *manager) ListByApplicationIDs(ctx context.Context,
+	applicationIDs []uint) (map[uint][]*models.ClusterSummary, error) {
+	summaries, err := m.dao.ListByApplicationIDs(ctx, applicationIDs)
+	if err != nil {
+		return nil, err
+	}
+	summaryMap := make(map[uint][]*models.ClusterSummary)
+	for _, summary := range summaries {
+		summaryMap[summary.ApplicationID] = append(summaryMap[summary.ApplicationID], summary)
+	}
+	return summaryMap, nil
+}
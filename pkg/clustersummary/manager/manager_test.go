@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/clustersummary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ClusterSummary{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	err := mgr.Upsert(ctx, &models.ClusterSummary{
+		ClusterID:       1,
+		ApplicationID:   1,
+		EnvironmentName: "test",
+		RegionName:      "hz",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	})
+	assert.Nil(t, err)
+	err = mgr.Upsert(ctx, &models.ClusterSummary{
+		ClusterID:       2,
+		ApplicationID:   1,
+		EnvironmentName: "online",
+		RegionName:      "hz",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	})
+	assert.Nil(t, err)
+
+	deployedAt := time.Now()
+	err = mgr.Upsert(ctx, &models.ClusterSummary{
+		ClusterID:         1,
+		ApplicationID:     1,
+		EnvironmentName:   "test",
+		RegionName:        "singapore",
+		Template:          "javaapp",
+		TemplateRelease:   "v1.0.1",
+		PipelinerunID:     10,
+		PipelinerunAction: "builddeploy",
+		PipelinerunStatus: "ok",
+		DeployedAt:        &deployedAt,
+	})
+	assert.Nil(t, err)
+
+	summaries, err := mgr.ListByClusterIDs(ctx, []uint{1, 3})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, "singapore", summaries[1].RegionName)
+	assert.Equal(t, "v1.0.1", summaries[1].TemplateRelease)
+	assert.Equal(t, uint(10), summaries[1].PipelinerunID)
+	assert.Equal(t, "ok", summaries[1].PipelinerunStatus)
+	assert.NotNil(t, summaries[1].DeployedAt)
+
+	appSummaries, err := mgr.ListByApplicationIDs(ctx, []uint{1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(appSummaries[1]))
+
+	err = mgr.DeleteByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	appSummaries, err = mgr.ListByApplicationIDs(ctx, []uint{1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(appSummaries[1]))
+	assert.Equal(t, uint(2), appSummaries[1][0].ClusterID)
+
+	summaries, err = mgr.ListByClusterIDs(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(summaries))
+}
@@ -0,0 +1,37 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// ClusterSummary is a denormalized view of a cluster for list pages,
+// it is maintained by events so that listing does not need to touch gitrepo or argocd
+type ClusterSummary struct {
+	ID              uint
+	ClusterID       uint `gorm:"uniqueIndex:idx_cluster_id"`
+	ApplicationID   uint `gorm:"index:idx_application_id"`
+	EnvironmentName string
+	RegionName      string
+	Template        string
+	TemplateRelease string
+	// PipelinerunID id of the latest deploy pipelinerun, 0 means never deployed
+	PipelinerunID     uint
+	PipelinerunAction string
+	PipelinerunStatus string
+	// DeployedAt finish time of the latest deploy pipelinerun, or create time if it is not finished
+	DeployedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
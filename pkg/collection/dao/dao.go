@@ -31,6 +31,8 @@ type DAO interface {
 	GetByResource(ctx context.Context, userID uint, resourceID uint,
 		resourceType string) (*models.Collection, error)
 	List(ctx context.Context, userID uint, resourceType string, ids []uint) ([]models.Collection, error)
+	// ListByUser lists the collections of the user, latest first. resourceType is ignored if empty
+	ListByUser(ctx context.Context, userID uint, resourceType string) ([]models.Collection, error)
 }
 
 type dao struct {
@@ -95,3 +97,17 @@ func (d dao) List(ctx context.Context, userID uint, resourceType string,
 	}
 	return collections, nil
 }
+
+func (d dao) ListByUser(ctx context.Context, userID uint, resourceType string) ([]models.Collection, error) {
+	var collections []models.Collection
+	statement := d.db.WithContext(ctx).Where("user_id = ?", userID)
+	if resourceType != "" {
+		statement = statement.Where("resource_type = ?", resourceType)
+	}
+	result := statement.Order("id desc").Find(&collections)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.CollectionInDB,
+			fmt.Sprintf("failed to list collections: %v", result.Error.Error()))
+	}
+	return collections, nil
+}
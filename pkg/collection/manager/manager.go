@@ -30,6 +30,8 @@ type Manager interface {
 		resourceType string) (*models.Collection, error)
 	List(ctx context.Context, userID uint, resourceType string,
 		ids []uint) ([]models.Collection, error)
+	// ListByUser lists the collections of the user, latest first. resourceType is ignored if empty
+	ListByUser(ctx context.Context, userID uint, resourceType string) ([]models.Collection, error)
 }
 
 type manager struct {
@@ -66,3 +68,8 @@ func (m *manager) List(ctx context.Context, userID uint, resourceType string,
 	ids []uint) ([]models.Collection, error) {
 	return m.dao.List(ctx, userID, resourceType, ids)
 }
+
+func (m *manager) ListByUser(ctx context.Context, userID uint,
+	resourceType string) ([]models.Collection, error) {
+	return m.dao.ListByUser(ctx, userID, resourceType)
+}
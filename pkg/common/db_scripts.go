@@ -65,6 +65,15 @@ const (
 		"on tb_template_release.name = tb_cluster.template_release " +
 		"and tb_template_release.template_name = tb_cluster.template " +
 		"where tb_template_release.id = ? and tb_cluster.deleted_ts = 0"
+
+	TemplateReleaseCountDeploysByStatus = "select tb_pipelinerun.status as status, count(*) as count " +
+		"from tb_template_release join tb_cluster " +
+		"on tb_template_release.name = tb_cluster.template_release " +
+		"and tb_template_release.template_name = tb_cluster.template " +
+		"join tb_pipelinerun on tb_pipelinerun.cluster_id = tb_cluster.id " +
+		"where tb_template_release.id = ? and tb_cluster.deleted_ts = 0 " +
+		"and tb_pipelinerun.action in ? and tb_pipelinerun.created_at >= ? " +
+		"group by tb_pipelinerun.status"
 )
 
 /* sql about user */
@@ -114,11 +123,11 @@ const (
 	GroupQueryByPaths         = "select * from tb_group where path in ? and deleted_ts = 0"
 	GroupQueryByIDNameFuzzily = "select * from tb_group " +
 		"where traversal_ids like ? and name like ? and deleted_ts = 0"
-	GroupAll                      = "select * from tb_group where deleted_ts = 0"
-	GroupUpdateTraversalIDs       = "update tb_group set traversal_ids = ?, updated_by = ? where id = ? and deleted_ts = 0"
-	GroupCountByParentID          = "select count(1) from tb_group where parent_id = ? and deleted_ts = 0"
-	GroupUpdateTraversalIDsPrefix = "update tb_group set traversal_ids = replace(traversal_ids, ?, ?), updated_by = ? " +
-		"where traversal_ids like ? and deleted_ts = 0"
+	GroupAll                       = "select * from tb_group where deleted_ts = 0"
+	GroupUpdateTraversalIDs        = "update tb_group set traversal_ids = ?, updated_by = ? where id = ? and deleted_ts = 0"
+	GroupCountByParentID           = "select count(1) from tb_group where parent_id = ? and deleted_ts = 0"
+	GroupQueryByTraversalIDsPrefix = "select * from tb_group where (traversal_ids = ? or traversal_ids like ?) " +
+		"and deleted_ts = 0"
 	GroupQueryByNameOrPathUnderParent = "select * from tb_group where parent_id = ? " +
 		"and (name = ? or path = ?) and deleted_ts = 0"
 	GroupQueryGroupChildren = "" +
@@ -148,6 +157,18 @@ const (
 	ApplicationDeleteByID     = "update tb_application set deleted_ts = ?, updated_by = ? where id = ?"
 	ApplicationTransferByID   = "update tb_application set group_id = ?, updated_by = ? where id = ?"
 	ApplicationCountByGroupID = "select count(1) from tb_application where group_id = ? and deleted_ts = 0"
+
+	ApplicationHardDeleteByID = "update tb_application set deleted_ts = ?, hard_deleted = 1, updated_by = ? " +
+		"where id = ?"
+	ApplicationQueryDeletedByName = "select * from tb_application where name = ? and deleted_ts != 0 " +
+		"and hard_deleted = 0"
+	ApplicationQueryDeletedByGroupID = "select * from tb_application where group_id = ? and deleted_ts != 0 " +
+		"and hard_deleted = 0 order by deleted_ts desc"
+	ApplicationQueryDeletedBefore = "select * from tb_application where deleted_ts != 0 and deleted_ts < ? " +
+		"and id > ? order by id limit ?"
+	ApplicationRestoreByID = "update tb_application set deleted_ts = 0, updated_by = ? where id = ? " +
+		"and deleted_ts != 0 and hard_deleted = 0"
+	ApplicationPurgeByID = "delete from tb_application where id = ? and deleted_ts != 0"
 )
 
 /* sql about environment */
@@ -199,6 +220,17 @@ const (
 	ClusterDeleteByID         = "update tb_cluster set deleted_ts = ?, updated_by = ? where id = ?"
 	ClusterQueryByName        = "select * from tb_cluster where name = ? and deleted_ts = 0"
 	ClusterQueryByClusterName = "select * from tb_cluster where name = ? and deleted_ts = 0"
+
+	ClusterHardDeleteByID     = "update tb_cluster set deleted_ts = ?, hard_deleted = 1, updated_by = ? where id = ?"
+	ClusterQueryDeletedByName = "select * from tb_cluster where name = ? and deleted_ts != 0 " +
+		"and hard_deleted = 0"
+	ClusterQueryDeletedByApplicationID = "select * from tb_cluster where application_id = ? and deleted_ts != 0 " +
+		"and hard_deleted = 0 order by deleted_ts desc"
+	ClusterQueryDeletedBefore = "select * from tb_cluster where deleted_ts != 0 and deleted_ts < ? " +
+		"and id > ? order by id limit ?"
+	ClusterRestoreByID = "update tb_cluster set deleted_ts = 0, status = ?, updated_by = ? " +
+		"where id = ? and deleted_ts != 0 and hard_deleted = 0"
+	ClusterPurgeByID = "delete from tb_cluster where id = ? and deleted_ts != 0"
 )
 
 /* sql about pipelinerun */
@@ -233,6 +265,107 @@ const (
 		" and action != 'restart' and status = 'ok' order by created_at desc limit 1 offset 0"
 )
 
+/* sql about pipelinerun sbom */
+const (
+	// SBOMComponentListClustersByName lists components of the images which clusters are running,
+	// the image of the latest successful pipelinerun except restart is regarded as the running one
+	SBOMComponentListClustersByName = "select distinct c.id as cluster_id, c.name as cluster_name, " +
+		"pr.id as pipeline_run_id, pr.image_url, s.name, s.version, s.purl from tb_cluster c " +
+		"join tb_pipelinerun pr on pr.id = (select max(p.id) from tb_pipelinerun p where p.cluster_id = c.id " +
+		"and p.action != 'restart' and p.status = 'ok') " +
+		"join tb_pr_sbom_component s on s.image_url = pr.image_url " +
+		"where c.deleted_ts = 0 and pr.image_url != '' and s.name = ? and (? = '' or s.version = ?) " +
+		"order by c.id"
+)
+
+/* sql about cluster summary */
+const (
+	ClusterSummaryListByClusterIDs     = "select * from tb_cluster_summary where cluster_id in ?"
+	ClusterSummaryListByApplicationIDs = "select * from tb_cluster_summary where application_id in ? " +
+		"order by application_id, cluster_id"
+	ClusterSummaryDeleteByClusterID = "delete from tb_cluster_summary where cluster_id = ?"
+)
+
+/* sql about deploy lock */
+const (
+	DeployLockGetByResource = "select * from tb_deploy_lock where resource_type = ? and resource_id = ? " +
+		"and (expire_at is null or expire_at > ?)"
+	DeployLockDeleteByResource = "delete from tb_deploy_lock where resource_type = ? and resource_id = ?"
+)
+
+/* sql about custom role */
+const (
+	CustomRoleGetByName = "select * from tb_custom_role where name = ?"
+	CustomRoleList      = "select * from tb_custom_role order by name"
+	CustomRoleUpdate    = "update tb_custom_role set `desc` = ?, rules = ?, updated_by = ?, updated_at = ? " +
+		"where name = ?"
+	CustomRoleDelete = "delete from tb_custom_role where name = ?"
+	// CustomRoleCountMembers counts the members of all resources granted the role
+	CustomRoleCountMembers = "select count(1) from tb_member where role = ? and deleted_ts = 0"
+)
+
+/* sql about change request */
+const (
+	ChangeRequestGetByID = "select * from tb_change_request where id = ?"
+)
+
+/* sql about deploy approval */
+const (
+	DeployApprovalGetByPipelinerunID = "select * from tb_deploy_approval where pipelinerun_id = ?"
+)
+
+/* sql about scheduled deploy */
+const (
+	ScheduledDeployGetByID = "select * from tb_scheduled_deploy where id = ?"
+)
+
+/* sql about cluster snapshot */
+const (
+	ClusterSnapshotGetByID            = "select * from tb_cluster_snapshot where id = ?"
+	ClusterSnapshotGetLatestByCluster = "select * from tb_cluster_snapshot where cluster_id = ? " +
+		"order by id desc limit 1"
+	ClusterSnapshotDeleteBefore = "delete from tb_cluster_snapshot where created_at < ?"
+)
+
+/* sql about cluster env change */
+const (
+	ClusterEnvChangeListSince = "select * from tb_cluster_env_change where cluster_id = ? and created_at > ? " +
+		"order by id"
+)
+
+/* sql about async task */
+const (
+	AsyncTaskGetByID        = "select * from tb_async_task where id = ?"
+	AsyncTaskUpdateProgress = "update tb_async_task set done = ?, total = ?, message = ?, updated_at = ? where id = ?"
+	AsyncTaskUpdateResult   = "update tb_async_task set result = ?, updated_at = ? where id = ?"
+	// AsyncTaskFinish keeps the partial result when the final result is empty
+	AsyncTaskFinish = "update tb_async_task set status = ?, result = coalesce(nullif(?, ''), result), " +
+		"error_message = ?, updated_at = ?, finished_at = ? where id = ?"
+	AsyncTaskHeartbeat = "update tb_async_task set updated_at = ? where id in ? and status = ?"
+	// AsyncTaskFailStale fails the running tasks whose heartbeats stopped
+	AsyncTaskFailStale = "update tb_async_task set status = ?, error_message = ?, updated_at = ?, finished_at = ? " +
+		"where status = ? and updated_at < ?"
+)
+
+/* sql about group quota */
+const (
+	GroupQuotaListByGroupIDs        = "select * from tb_group_quota where group_id in ? order by group_id, resource"
+	GroupQuotaDelete                = "delete from tb_group_quota where group_id = ? and resource = ?"
+	GroupQuotaUpdateWarnedThreshold = "update tb_group_quota set warned_threshold = ? where id = ?"
+	// counts of the resources under groups, deleted_ts of the deleted ones is in seconds
+	GroupQuotaCountApplications = "select count(1) from tb_application where group_id in ? and deleted_ts = 0"
+	GroupQuotaCountClusters     = "select count(1) from tb_cluster c join tb_application a " +
+		"on c.application_id = a.id where a.group_id in ? and c.deleted_ts = 0"
+	GroupQuotaCountApplicationsCreatedSince = "select count(1) from tb_application " +
+		"where group_id in ? and created_at >= ?"
+	GroupQuotaCountApplicationsDeletedSince = "select count(1) from tb_application " +
+		"where group_id in ? and deleted_ts >= ?"
+	GroupQuotaCountClustersCreatedSince = "select count(1) from tb_cluster c join tb_application a " +
+		"on c.application_id = a.id where a.group_id in ? and c.created_at >= ?"
+	GroupQuotaCountClustersDeletedSince = "select count(1) from tb_cluster c join tb_application a " +
+		"on c.application_id = a.id where a.group_id in ? and c.deleted_ts >= ?"
+)
+
 /* sql about cluster tag */
 const (
 	// TagListByResourceTypeID ...
@@ -248,6 +381,18 @@ const (
 		" and resource_id = ? and `tag_key` not in ?"
 )
 
+/* sql about resource metadata */
+const (
+	MetadataListByResource = "select * from tb_resource_metadata where resource_type = ?" +
+		" and resource_id = ? order by id"
+	MetadataListByResources = "select * from tb_resource_metadata where resource_type = ?" +
+		" and resource_id in ? order by id"
+	MetadataDeleteAllByResource = "delete from tb_resource_metadata where resource_type = ?" +
+		" and resource_id = ?"
+	MetadataDeleteByResourceAndKeys = "delete from tb_resource_metadata where resource_type = ?" +
+		" and resource_id = ? and `meta_key` not in ?"
+)
+
 /* sql about cluster template tag */
 const (
 	ClusterTemplateSchemaTagListByClusterID = "select * from tb_cluster_template_schema_tag where cluster_id = ? " +
@@ -271,6 +416,23 @@ const (
 	DeleteTokenByID  = "delete from tb_token where id = ?"
 	TokenGetByCode   = "select * from tb_token where code = ?"
 	DeleteByClientID = "delete from tb_token where client_id = ?"
+	DeleteByUserID   = "delete from tb_token where user_id = ?"
+	// access tokens are prefixed with "ha_", the tokens authorized to oauth clients are excluded
+	TokenListAccessTokensByUserID = "select * from tb_token where user_id = ? and code like 'ha_%' order by id"
+	// access and refresh tokens are all prefixed with "h*_", authorization codes are not,
+	// the codes exchanged are kept to detect replays
+	DeleteAuthorizationCodesByClientID = "delete from tb_token where client_id = ? and code not like 'h%' " +
+		"and (ref_id is null or ref_id = 0)"
+	// tokens issued to oauth clients which expire, personal and resource access tokens are excluded
+	TokenListExpirableAfterID = "select * from tb_token where id > ? and client_id != '' and expires_in > 0 " +
+		"order by id limit ?"
+	DeleteTokensByIDs = "delete from tb_token where id in ?"
+	// an authorization code refers to the refresh token issued by it once exchanged,
+	// and expires with the refresh token so that it's not purged while replays can still revoke the token
+	ConsumeAuthorizationCode = "update tb_token set ref_id = ?, expires_in = ? " +
+		"where id = ? and (ref_id is null or ref_id = 0)"
+	// a device code is approved by setting its user, only once
+	ApproveDeviceCode = "update tb_token set user_id = ? where id = ? and user_code != '' and user_id = 0"
 )
 
 /* sql about oauth app*/
@@ -281,6 +443,11 @@ const (
 	DeleteClientSecret           = "delete from tb_oauth_client_secret where  client_id = ? and id = ?"
 	DeleteClientSecretByClientID = "delete from tb_oauth_client_secret where client_id = ?"
 	ClientSecretSelectAll        = "select * from tb_oauth_client_secret where client_id = ?"
+	ClientSecretSelectBySuffix   = "select * from tb_oauth_client_secret where client_id = ? and client_secret_suffix = ?"
+	GetOauthAppsByClientIDs      = "select * from tb_oauth_app where client_id in ?"
+	GetOauthGrant                = "select * from tb_oauth_grant where user_id = ? and client_id = ?"
+	DeleteOauthGrant             = "delete from tb_oauth_grant where user_id = ? and client_id = ?"
+	DeleteOauthGrantByClientID   = "delete from tb_oauth_grant where client_id = ?"
 )
 
 /* sql about pipeline*/
@@ -289,3 +456,45 @@ const (
 	TaskDeleteByCluster     = "delete from tb_task where cluster= ?"
 	StepDeleteByCluster     = "delete from tb_step where cluster= ?"
 )
+
+/* sql about search */
+const (
+	SearchGroupsByName = "select 'groups' as resource_type, id as resource_id, name, path, description, " +
+		"traversal_ids from tb_group where deleted_ts = 0 and (name like ? or path like ? or description like ?) " +
+		"order by id limit ?"
+	SearchApplicationsByName = "select 'applications' as resource_type, a.id as resource_id, a.name, " +
+		"a.description, g.traversal_ids from tb_application a join tb_group g on g.id = a.group_id " +
+		"where a.deleted_ts = 0 and (a.name like ? or a.description like ?) order by a.id limit ?"
+	SearchClustersByName = "select 'clusters' as resource_type, c.id as resource_id, c.name, c.description, " +
+		"a.id as application_id, a.name as application_name, g.traversal_ids from tb_cluster c " +
+		"join tb_application a on a.id = c.application_id join tb_group g on g.id = a.group_id " +
+		"where c.deleted_ts = 0 and (c.name like ? or c.description like ?) order by c.id limit ?"
+	SearchApplicationsByTag = "select 'applications' as resource_type, a.id as resource_id, a.name, " +
+		"a.description, g.traversal_ids, t.tag_key, t.tag_value from tb_tag t " +
+		"join tb_application a on a.id = t.resource_id join tb_group g on g.id = a.group_id " +
+		"where t.resource_type = 'applications' and a.deleted_ts = 0 and (t.tag_key like ? or t.tag_value like ?) " +
+		"order by a.id limit ?"
+	SearchClustersByTag = "select 'clusters' as resource_type, c.id as resource_id, c.name, c.description, " +
+		"a.id as application_id, a.name as application_name, g.traversal_ids, t.tag_key, t.tag_value from tb_tag t " +
+		"join tb_cluster c on c.id = t.resource_id join tb_application a on a.id = c.application_id " +
+		"join tb_group g on g.id = a.group_id " +
+		"where t.resource_type = 'clusters' and c.deleted_ts = 0 and (t.tag_key like ? or t.tag_value like ?) " +
+		"order by c.id limit ?"
+	// owners are the users granted the owner role on the resources directly
+	SearchGroupsByOwner = "select 'groups' as resource_type, g.id as resource_id, g.name, g.path, g.description, " +
+		"g.traversal_ids, u.name as owner from tb_member m join tb_user u on u.id = m.membername_id " +
+		"join tb_group g on g.id = m.resource_id " +
+		"where m.resource_type = 'groups' and m.member_type = 0 and m.role = 'owner' and m.deleted_ts = 0 " +
+		"and g.deleted_ts = 0 and (u.name like ? or u.full_name like ? or u.email like ?) order by g.id limit ?"
+	SearchApplicationsByOwner = "select 'applications' as resource_type, a.id as resource_id, a.name, " +
+		"a.description, g.traversal_ids, u.name as owner from tb_member m join tb_user u on u.id = m.membername_id " +
+		"join tb_application a on a.id = m.resource_id join tb_group g on g.id = a.group_id " +
+		"where m.resource_type = 'applications' and m.member_type = 0 and m.role = 'owner' and m.deleted_ts = 0 " +
+		"and a.deleted_ts = 0 and (u.name like ? or u.full_name like ? or u.email like ?) order by a.id limit ?"
+	SearchClustersByOwner = "select 'clusters' as resource_type, c.id as resource_id, c.name, c.description, " +
+		"a.id as application_id, a.name as application_name, g.traversal_ids, u.name as owner from tb_member m " +
+		"join tb_user u on u.id = m.membername_id join tb_cluster c on c.id = m.resource_id " +
+		"join tb_application a on a.id = c.application_id join tb_group g on g.id = a.group_id " +
+		"where m.resource_type = 'clusters' and m.member_type = 0 and m.role = 'owner' and m.deleted_ts = 0 " +
+		"and c.deleted_ts = 0 and (u.name like ? or u.full_name like ? or u.email like ?) order by c.id limit ?"
+)
@@ -14,7 +14,47 @@
 
 package common
 
+import (
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/util/angular"
+)
+
 type Manifest struct {
 	// TODO(encode the template info into manifest),currently only the Version
 	Version string `yaml:"version" json:"version"`
 }
+
+// ConfigCommit is a commit changing the config in gitops repos
+type ConfigCommit struct {
+	ID string `json:"id"`
+	// Title is the first line of the commit message
+	Title string `json:"title"`
+	// Operator is who made the change, it's the author of the commit if the commit is not made by horizon
+	Operator  string    `json:"operator"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Stats is nil if the git provider does not tell the lines changed by the commit
+	Stats *CommitStats `json:"stats,omitempty"`
+}
+
+type CommitStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// NewConfigCommit creates the config commit, the operator is parsed from the message if it's made by horizon,
+// as commits are all authored by the account of horizon
+func NewConfigCommit(id, message, author string, createdAt time.Time, stats *CommitStats) *ConfigCommit {
+	operator := author
+	if msg, ok := angular.ParseCommitMessage(message); ok && msg.Header.Subject.Operator != "" {
+		operator = msg.Header.Subject.Operator
+	}
+	return &ConfigCommit{
+		ID:        id,
+		Title:     strings.SplitN(message, "\n", 2)[0],
+		Operator:  operator,
+		CreatedAt: createdAt,
+		Stats:     stats,
+	}
+}
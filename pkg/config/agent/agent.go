@@ -0,0 +1,37 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import "time"
+
+// Config configures the regions served by agents. The agent runs inside the cluster of the region,
+// polls kubernetes requests from horizon over an outbound connection and performs them locally,
+// so horizon can manage clusters it cannot reach directly.
+type Config struct {
+	// Regions are served by agents, keyed by region name
+	Regions map[string]Region `yaml:"regions"`
+	// PollTimeout is the longest time a poll of agents is held when no request is queued
+	PollTimeout time.Duration `yaml:"pollTimeout"`
+	// OfflineTimeout is the time after the last poll that an agent is considered offline,
+	// requests to regions whose agents are offline fail immediately
+	OfflineTimeout time.Duration `yaml:"offlineTimeout"`
+	// RequestTimeout is the longest time to wait for the agent to start responding a request
+	RequestTimeout time.Duration `yaml:"requestTimeout"`
+}
+
+type Region struct {
+	// Token authenticates the agent of the region
+	Token string `yaml:"token"`
+}
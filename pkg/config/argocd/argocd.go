@@ -14,6 +14,8 @@
 
 package argocd
 
+import "time"
+
 type Mapper map[string]*ArgoCD
 
 type ArgoCD struct {
@@ -22,3 +24,22 @@ type ArgoCD struct {
 	HelmRepo  string `yaml:"helmRepo"`
 	Namespace string `yaml:"namespace"`
 }
+
+// SyncConcurrency limits the argoCD syncs in progress of each region, syncs beyond the limit wait in a queue
+type SyncConcurrency struct {
+	// Limit is the default limit of each region, 0 means unlimited
+	Limit int `yaml:"limit"`
+	// Regions overrides the limit by region name
+	Regions map[string]int `yaml:"regions"`
+	// Timeout is the longest time a sync counts against the limit,
+	// a sync still in progress after timeout no longer blocks others
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// LimitOf returns the limit of the region, 0 means unlimited
+func (c SyncConcurrency) LimitOf(region string) int {
+	if limit, ok := c.Regions[region]; ok {
+		return limit
+	}
+	return c.Limit
+}
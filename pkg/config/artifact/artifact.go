@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import "time"
+
+// Config of the artifacts and test reports uploaded by the steps of pipelines
+type Config struct {
+	// Storage is the s3 compatible storage of artifacts, uploading is disabled if its bucket is empty
+	Storage Storage `yaml:"storage"`
+	// MaxSize is the max size in bytes of an artifact uploaded, default is 100MiB
+	MaxSize int64 `yaml:"maxSize"`
+	// DownloadURLExpireIn is how long the url of downloading an artifact is valid, default is 10m
+	DownloadURLExpireIn time.Duration `yaml:"downloadURLExpireIn"`
+	Retention           Retention     `yaml:"retention"`
+}
+
+type Storage struct {
+	AccessKey        string `yaml:"accessKey"`
+	SecretKey        string `yaml:"secretKey"`
+	Region           string `yaml:"region"`
+	Endpoint         string `yaml:"endpoint"`
+	Bucket           string `yaml:"bucket"`
+	DisableSSL       bool   `yaml:"disableSSL"`
+	SkipVerify       bool   `yaml:"skipVerify"`
+	S3ForcePathStyle bool   `yaml:"s3ForcePathStyle"`
+}
+
+type Retention struct {
+	// Artifacts is how long artifacts are kept after uploaded, default is 30 days
+	Artifacts time.Duration `yaml:"artifacts"`
+	// Reports is how long test and coverage reports are kept after uploaded, default is 90 days
+	Reports time.Duration `yaml:"reports"`
+	// KeepLatest is the number of latest pipelineruns of each cluster whose artifacts and reports
+	// are kept regardless of the retention
+	KeepLatest int `yaml:"keepLatest"`
+	// JobInterval is the interval of deleting artifacts and reports beyond the retention, default is 1h
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// BatchSize is the number of artifacts and reports deleted in a batch, default is 100
+	BatchSize int `yaml:"batchSize"`
+}
+
+// Enabled tells whether the storage of artifacts is configured
+func (c Config) Enabled() bool {
+	return c.Storage.Bucket != ""
+}
@@ -22,4 +22,6 @@ type Config struct {
 	JobInterval   time.Duration `yaml:"jobInterval"`
 	BatchInterval time.Duration `yaml:"batchInterval"`
 	BatchSize     int           `yaml:"batchSize"`
+	// WarningBefore is how long before a cluster is freed to warn of it, no warning is sent if it's 0
+	WarningBefore time.Duration `yaml:"warningBefore"`
 }
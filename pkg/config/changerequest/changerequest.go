@@ -0,0 +1,31 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changerequest
+
+type Config struct {
+	// Environments lists the environments whose clusters are in change request mode,
+	// config changes of these clusters must be proposed by change requests and approved by another person
+	Environments []string `yaml:"environments"`
+}
+
+// Required tells whether config changes of clusters in the environment require change requests
+func (c *Config) Required(environment string) bool {
+	for _, env := range c.Environments {
+		if env == environment {
+			return true
+		}
+	}
+	return false
+}
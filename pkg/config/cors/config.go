@@ -0,0 +1,37 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import "time"
+
+// Config allows the console hosted on other domains to call the apis, it is disabled if no origin is allowed
+type Config struct {
+	// AllowedOrigins are the origins allowed, such as https://console.example.com.
+	// A subdomain wildcard like https://*.example.com matches the subdomains, and * matches any origin
+	AllowedOrigins []string `yaml:"allowedOrigins"`
+	AllowedMethods []string `yaml:"allowedMethods"`
+	// AllowedHeaders are the request headers allowed besides the CORS-safelisted ones
+	AllowedHeaders []string `yaml:"allowedHeaders"`
+	// ExposedHeaders are the response headers readable by the scripts besides the CORS-safelisted ones
+	ExposedHeaders []string `yaml:"exposedHeaders"`
+	// AllowCredentials allows the requests with cookies, it can not be used with the * origin
+	AllowCredentials bool `yaml:"allowCredentials"`
+	// MaxAge is how long the browsers cache the results of preflight requests
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+func (c Config) Enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
@@ -21,4 +21,17 @@ type Config struct {
 	Password          string `yaml:"password,omitempty"`
 	Database          string `yaml:"database"`
 	PrometheusEnabled bool   `yaml:"prometheusEnabled"`
+	// RequestTransaction wraps the mutating requests of the routes marked as route.Route.Transactional
+	// in a db transaction
+	RequestTransaction bool `yaml:"requestTransaction"`
+	// Replicas serve the queries of read-only requests, the primary serves everything if there is none
+	Replicas []Replica `yaml:"replicas"`
+}
+
+// Replica is a read-only replica of the database, the username and password default to the primary's
+type Replica struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password,omitempty"`
 }
@@ -0,0 +1,57 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployapproval
+
+type Config struct {
+	// Environments lists the protected environments, deploys to their clusters
+	// must be approved by one of the approvers before the pipelineruns are executed
+	Environments []Environment `yaml:"environments"`
+}
+
+type Environment struct {
+	Name string `yaml:"name"`
+	// Approvers are emails of the users who can approve deploys, admins can always approve
+	Approvers []string `yaml:"approvers"`
+}
+
+// Of returns the protected environment of the name, or nil if the environment is not protected
+func (c *Config) Of(environment string) *Environment {
+	for i := range c.Environments {
+		if c.Environments[i].Name == environment {
+			return &c.Environments[i]
+		}
+	}
+	return nil
+}
+
+// ApprovableEnvironments returns names of the protected environments whose deploys the user can approve
+func (c *Config) ApprovableEnvironments(email string, isAdmin bool) []string {
+	environments := make([]string, 0)
+	for i := range c.Environments {
+		if isAdmin || c.Environments[i].IsApprover(email) {
+			environments = append(environments, c.Environments[i].Name)
+		}
+	}
+	return environments
+}
+
+func (e *Environment) IsApprover(email string) bool {
+	for _, approver := range e.Approvers {
+		if approver == email {
+			return true
+		}
+	}
+	return false
+}
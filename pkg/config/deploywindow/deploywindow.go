@@ -0,0 +1,196 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// ConflictPolicyWarn lets a conflicting deploy go on and leaves a warning
+	ConflictPolicyWarn = "warn"
+	// ConflictPolicyQueue refuses to start a conflicting deploy, the pipelinerun keeps
+	// waiting and can be executed again after the deploys in progress finish
+	ConflictPolicyQueue = "queue"
+)
+
+type Config struct {
+	// ConflictPolicy is one of warn and queue, default is warn
+	ConflictPolicy string `yaml:"conflictPolicy"`
+	// CoupledTagKey is the key of cluster tag, clusters with the same value of it are
+	// tightly coupled and should not be deployed at the same time
+	CoupledTagKey string `yaml:"coupledTagKey"`
+	// FreezeWindows are periods in which deploys to some environments are refused,
+	// users granted clusters/freezeoverride can still deploy for emergencies
+	FreezeWindows []*FreezeWindow `yaml:"freezeWindows"`
+}
+
+const (
+	// weeklyLayout is the layout of the clock in a weekly time, such as Fri 18:00
+	weeklyLayout = "15:04"
+	// dateLayout is the layout of a one-off time, such as 2026-12-24 00:00
+	dateLayout = "2006-01-02 15:04"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// FreezeWindow is a weekly or one-off period in which deploys are frozen
+type FreezeWindow struct {
+	Name string `yaml:"name"`
+	// Environments are the environments frozen by the window, all environments are frozen if it is empty
+	Environments []string `yaml:"environments"`
+	// Start and End are either weekly times like "Fri 18:00" or one-off times like "2026-12-24 00:00".
+	// A weekly window whose end is before its start spans the end of the week, such as Fri 18:00 to Mon 08:00
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Timezone is an IANA timezone like Asia/Shanghai, default is the local timezone of the server
+	Timezone string `yaml:"timezone"`
+}
+
+type weeklyTime struct {
+	weekday time.Weekday
+	hour    int
+	minute  int
+}
+
+func parseWeekly(value string) (*weeklyTime, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("%q is neither a weekly time like Fri 18:00 nor a time like 2026-12-24 00:00", value)
+	}
+	name := strings.ToLower(fields[0])
+	if len(name) > 3 {
+		name = name[:3]
+	}
+	weekday, ok := weekdays[name]
+	if !ok {
+		return nil, fmt.Errorf("%q is not a day of the week", fields[0])
+	}
+	clock, err := time.Parse(weeklyLayout, fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a time like 18:00", fields[1])
+	}
+	return &weeklyTime{weekday: weekday, hour: clock.Hour(), minute: clock.Minute()}, nil
+}
+
+// at returns the time of the weekly time in the week starting at the sunday
+func (w *weeklyTime) at(sunday time.Time) time.Time {
+	return time.Date(sunday.Year(), sunday.Month(), sunday.Day()+int(w.weekday), w.hour, w.minute, 0, 0,
+		sunday.Location())
+}
+
+func (w *weeklyTime) before(o *weeklyTime) bool {
+	if w.weekday != o.weekday {
+		return w.weekday < o.weekday
+	}
+	if w.hour != o.hour {
+		return w.hour < o.hour
+	}
+	return w.minute < o.minute
+}
+
+// Validate checks the start, end and timezone of the window
+func (w *FreezeWindow) Validate() error {
+	if _, err := w.location(); err != nil {
+		return err
+	}
+	_, _, err := w.bounds(time.Now())
+	return err
+}
+
+// AppliesTo tells whether the window freezes the environment
+func (w *FreezeWindow) AppliesTo(environment string) bool {
+	if len(w.Environments) == 0 {
+		return true
+	}
+	for _, env := range w.Environments {
+		if env == environment {
+			return true
+		}
+	}
+	return false
+}
+
+// Active tells whether the time falls into the window, and returns the end of the window if it does
+func (w *FreezeWindow) Active(t time.Time) (time.Time, bool) {
+	start, end, err := w.bounds(t)
+	if err != nil || t.Before(start) || !t.Before(end) {
+		return time.Time{}, false
+	}
+	return end, true
+}
+
+// bounds returns the occurrence of the window which is in progress at the time or starts after it
+func (w *FreezeWindow) bounds(t time.Time) (time.Time, time.Time, error) {
+	loc, err := w.location()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	start, startErr := time.ParseInLocation(dateLayout, w.Start, loc)
+	end, endErr := time.ParseInLocation(dateLayout, w.End, loc)
+	if startErr == nil && endErr == nil {
+		if !end.After(start) {
+			return time.Time{}, time.Time{}, fmt.Errorf("end %s must be after start %s", w.End, w.Start)
+		}
+		return start, end, nil
+	}
+
+	weeklyStart, err := parseWeekly(w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start: %v", err)
+	}
+	weeklyEnd, err := parseWeekly(w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("end: %v", err)
+	}
+	if *weeklyStart == *weeklyEnd {
+		return time.Time{}, time.Time{}, fmt.Errorf("start and end must differ")
+	}
+
+	t = t.In(loc)
+	sunday := time.Date(t.Year(), t.Month(), t.Day()-int(t.Weekday()), 0, 0, 0, 0, loc)
+	// the window started last week may still be in progress if it spans the end of the week
+	for _, week := range []time.Time{sunday.AddDate(0, 0, -7), sunday} {
+		start, end := weeklyStart.at(week), weeklyEnd.at(week)
+		if weeklyEnd.before(weeklyStart) {
+			end = weeklyEnd.at(week.AddDate(0, 0, 7))
+		}
+		if t.Before(end) {
+			return start, end, nil
+		}
+	}
+	next := sunday.AddDate(0, 0, 7)
+	if weeklyEnd.before(weeklyStart) {
+		return weeklyStart.at(next), weeklyEnd.at(next.AddDate(0, 0, 7)), nil
+	}
+	return weeklyStart.at(next), weeklyEnd.at(next), nil
+}
+
+func (w *FreezeWindow) location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone %s is invalid: %v", w.Timezone, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,27 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluxcd
+
+import "time"
+
+// Config is for the regions whose clusters are synced by flux
+type Config struct {
+	// Namespace where flux sources and helm releases of clusters are created, flux-system if empty
+	Namespace string `yaml:"namespace"`
+	// SecretRef is the secret in the namespace holding the credentials of gitops repos
+	SecretRef string `yaml:"secretRef"`
+	// Interval is how often flux reconciles the clusters, 5m if empty
+	Interval time.Duration `yaml:"interval"`
+}
@@ -14,6 +14,12 @@
 
 package gitlab
 
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/resilience"
+)
+
 // GitopsRepoConfig gitops repo config
 type GitopsRepoConfig struct {
 	URL               string `yaml:"url"`
@@ -21,4 +27,50 @@ type GitopsRepoConfig struct {
 	RootGroupPath     string `yaml:"rootGroupPath"`
 	DefaultBranch     string `yaml:"defaultBranch"`
 	DefaultVisibility string `yaml:"defaultVisibility"`
+	// ApplicationRepo is where gitops repos of applications are hosted, they are in the gitlab above if it's not set
+	ApplicationRepo ApplicationRepoConfig `yaml:"applicationRepo"`
+	// Resilience is the timeout, retries and circuit breaker of requests to the gitlab above
+	Resilience resilience.Config `yaml:"resilience"`
+	// Cache caches the files read from the gitops repos in the gitlab above
+	Cache CacheConfig `yaml:"cache"`
+}
+
+// kinds of git providers hosting gitops repos
+const (
+	KindGitlab = "gitlab"
+	KindGithub = "github"
+	KindGitea  = "gitea"
+)
+
+// ApplicationRepoConfig is the git provider hosting gitops repos of applications,
+// the repos are named like <application>.<environment> under the owner
+type ApplicationRepoConfig struct {
+	// Kind is one of gitlab, github and gitea
+	Kind string `yaml:"kind"`
+	// URL is the url of gitea, or the base url of the api of github which is https://api.github.com/ if empty,
+	// such as https://github.example.com/api/v3/ for github enterprise
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+	// Owner is the organization owning the repos
+	Owner string `yaml:"owner"`
+}
+
+const (
+	// CacheBackendMemory caches files in each server, which is only invalidated by the webhooks it receives
+	CacheBackendMemory = "memory"
+	// CacheBackendRedis caches files in redisConfig, which is shared by all servers
+	CacheBackendRedis = "redis"
+)
+
+// CacheConfig caches the files read from gitops repos by repo and ref, the files of a repo are invalidated
+// once horizon writes it, or gitlab sends its events to the webhook /apis/internal/v2/gitops/webhook
+type CacheConfig struct {
+	// Backend is memory or redis, files are not cached if it's empty
+	Backend string `yaml:"backend"`
+	// TTL bounds how long the files changed without horizon or webhooks are stale, 10m by default
+	TTL time.Duration `yaml:"ttl"`
+	// Size is the max number of repos cached in memory, 1000 by default
+	Size int `yaml:"size"`
+	// WebhookToken is the secret token of the webhook, which is sent in the X-Gitlab-Token header by gitlab
+	WebhookToken string `yaml:"webhookToken"`
 }
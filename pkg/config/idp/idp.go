@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idp
+
+type Config struct {
+	// ClaimsMappings are keyed by name of identity provider, providers without mapping
+	// take the standard claims sub, email, name and groups
+	ClaimsMappings map[string]*ClaimsMapping `yaml:"claimsMappings"`
+}
+
+// ClaimsMapping describes how claims of an identity provider are mapped to a user
+type ClaimsMapping struct {
+	ID     *ClaimRule `yaml:"id"`
+	Email  *ClaimRule `yaml:"email"`
+	Name   *ClaimRule `yaml:"name"`
+	Groups *ClaimRule `yaml:"groups"`
+	// DisableAutoProvision forbids users who never logged in before to login
+	DisableAutoProvision bool `yaml:"disableAutoProvision"`
+	// ProvisionGroups restricts auto-provisioning to users in any of the groups if not empty
+	ProvisionGroups []string `yaml:"provisionGroups"`
+}
+
+// ClaimRule takes the value of a claim and transforms it optionally
+type ClaimRule struct {
+	// Claim is the name of the claim, nested claims are separated by dots, such as profile.email
+	Claim string `yaml:"claim"`
+	// Pattern is a regular expression the value must match, values which do not match are dropped.
+	// The first submatch is taken if there is one, otherwise the whole match.
+	Pattern string `yaml:"pattern"`
+	// Replacement expands the match of Pattern if not empty, such as ${1}@example.com
+	Replacement string `yaml:"replacement"`
+}
+
+// CanProvision returns true if a new user in the groups can be created on first login
+func (m *ClaimsMapping) CanProvision(groups []string) bool {
+	if m == nil {
+		return true
+	}
+	if m.DisableAutoProvision {
+		return false
+	}
+	if len(m.ProvisionGroups) == 0 {
+		return true
+	}
+	for _, allowed := range m.ProvisionGroups {
+		for _, group := range groups {
+			if group == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeclient
+
+type RateLimit struct {
+	QPS   float32 `yaml:"qps"`
+	Burst int     `yaml:"burst"`
+}
+
+type Config struct {
+	// RateLimit is the default client-side rate limit of each region
+	RateLimit `yaml:",inline"`
+	// Regions overrides the rate limit by region name
+	Regions map[string]RateLimit `yaml:"regions"`
+}
+
+// RateLimitOf returns the rate limit of the region, zero values mean not configured
+func (c Config) RateLimitOf(region string) RateLimit {
+	limit := c.RateLimit
+	if override, ok := c.Regions[region]; ok {
+		if override.QPS > 0 {
+			limit.QPS = override.QPS
+		}
+		if override.Burst > 0 {
+			limit.Burst = override.Burst
+		}
+	}
+	return limit
+}
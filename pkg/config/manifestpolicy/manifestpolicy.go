@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestpolicy
+
+const (
+	// ActionBlock refuses to deploy manifests which violate the policy
+	ActionBlock = "block"
+	// ActionWarn lets the deploy go on and leaves a warning
+	ActionWarn = "warn"
+	// ActionOff disables the policy
+	ActionOff = "off"
+)
+
+type Config struct {
+	// Policies are evaluated against the rendered manifests of clusters before their configs are merged
+	Policies []*Policy `yaml:"policies"`
+}
+
+type Policy struct {
+	Name string `yaml:"name"`
+	// Rule is the name of a registered rule, such as disallowLatestTag,
+	// requireResourceLimits and disallowPrivileged
+	Rule string `yaml:"rule"`
+	// Action is one of block, warn and off, default is warn
+	Action string `yaml:"action"`
+	// Environments overrides the action in environments, keyed by environment name
+	Environments map[string]string `yaml:"environments"`
+}
+
+// ActionOf returns the action of the policy in the environment
+func (p *Policy) ActionOf(environment string) string {
+	if action, ok := p.Environments[environment]; ok {
+		return action
+	}
+	if p.Action == "" {
+		return ActionWarn
+	}
+	return p.Action
+}
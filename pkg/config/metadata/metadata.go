@@ -0,0 +1,47 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+const (
+	// FieldTypeString accepts any text, optionally restricted by the pattern
+	FieldTypeString = "string"
+	// FieldTypeURL accepts absolute http or https urls, such as runbook links
+	FieldTypeURL = "url"
+	// FieldTypeEnum accepts one of the options, such as tiers
+	FieldTypeEnum = "enum"
+)
+
+// Field defines a structured metadata field of applications or clusters
+type Field struct {
+	// Key is the unique key of the field, the value is stored and returned under it
+	Key         string `yaml:"key" json:"key"`
+	DisplayName string `yaml:"displayName" json:"displayName"`
+	Description string `yaml:"description" json:"description"`
+	// Type is one of string, url and enum, default is string
+	Type string `yaml:"type" json:"type"`
+	// Options are the values allowed by enum fields
+	Options []string `yaml:"options" json:"options,omitempty"`
+	// Pattern is the regular expression which values of string fields must match
+	Pattern string `yaml:"pattern" json:"pattern,omitempty"`
+	// MaxLength is the max length of values, zero means no extra limit
+	MaxLength int  `yaml:"maxLength" json:"maxLength,omitempty"`
+	Required  bool `yaml:"required" json:"required"`
+}
+
+// Config defines the metadata fields per installation, values of undefined keys are rejected
+type Config struct {
+	Application []Field `yaml:"application" json:"application"`
+	Cluster     []Field `yaml:"cluster" json:"cluster"`
+}
@@ -0,0 +1,39 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package naming
+
+const (
+	// UniqueScopeKind means the name only needs to be unique among resources of the same kind
+	UniqueScopeKind = "kind"
+	// UniqueScopeGlobal means the name must be unique among both applications and clusters
+	UniqueScopeGlobal = "global"
+)
+
+type Policy struct {
+	// Prefix is the prefix which names must start with
+	Prefix string `yaml:"prefix"`
+	// Pattern is the regular expression which names must match, used to restrict allowed characters
+	Pattern string `yaml:"pattern"`
+	// MaxLength is the max length of names, zero means no extra limit
+	MaxLength int `yaml:"maxLength"`
+	// UniqueScope is one of kind and global, default is kind. It does not take effect for namespaces
+	UniqueScope string `yaml:"uniqueScope"`
+}
+
+type Config struct {
+	Application Policy `yaml:"application"`
+	Cluster     Policy `yaml:"cluster"`
+	Namespace   Policy `yaml:"namespace"`
+}
@@ -0,0 +1,46 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+type Config struct {
+	// Default is the baseline of regions which are not listed in Regions
+	Default *Baseline `yaml:"default"`
+	// Regions are keyed by region name
+	Regions map[string]*Baseline `yaml:"regions"`
+}
+
+// Baseline is the network policy mandated by security teams for all clusters in a region
+type Baseline struct {
+	// DefaultDenyIngress denies the ingress traffic of clusters which is not allowed by their own rules,
+	// clusters without network policy config are isolated as well
+	DefaultDenyIngress bool `yaml:"defaultDenyIngress"`
+	DefaultDenyEgress  bool `yaml:"defaultDenyEgress"`
+	// AllowedCIDRs restricts the CIDRs which clusters can allow if not empty,
+	// the CIDRs of rules must be inside one of them
+	AllowedCIDRs []string `yaml:"allowedCIDRs"`
+	// ForbiddenNamespaces cannot be allowed by clusters, such as namespaces of system components
+	ForbiddenNamespaces []string `yaml:"forbiddenNamespaces"`
+}
+
+// BaselineOf returns the baseline of the region, nil is returned if there is none
+func (c *Config) BaselineOf(region string) *Baseline {
+	if c == nil {
+		return nil
+	}
+	if baseline, ok := c.Regions[region]; ok {
+		return baseline
+	}
+	return c.Default
+}
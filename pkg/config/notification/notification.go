@@ -0,0 +1,40 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+type Config struct {
+	// WebURL is the address of horizon web, the links to clusters in notifications are based on it
+	WebURL string `yaml:"webURL"`
+	// seconds for http client timeout of slack and dingtalk
+	ClientTimeout uint `yaml:"clientTimeout"`
+	// SMTP sends the notifications of email channels and subscriptions, emails are not sent if its host is empty
+	SMTP SMTP `yaml:"smtp"`
+	// Templates override the default templates by the kinds of notifications, such as deploy_failed
+	Templates map[string]Template `yaml:"templates"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// Template is rendered by text/template, see pkg/notification/service for the fields available
+type Template struct {
+	Title   string `yaml:"title"`
+	Content string `yaml:"content"`
+}
@@ -29,4 +29,53 @@ type Server struct {
 	AuthorizeCodeExpireIn time.Duration `yaml:"authorizeCodeExpireIn"`
 	AccessTokenExpireIn   time.Duration `yaml:"accessTokenExpireIn"`
 	RefreshTokenExpireIn  time.Duration `yaml:"refreshTokenExpireIn"`
+	// MaxAccessTokenExpireIn and MaxRefreshTokenExpireIn bound the token lifetimes oauth apps can override,
+	// the default lifetimes are used as the maximums when they are not set
+	MaxAccessTokenExpireIn  time.Duration `yaml:"maxAccessTokenExpireIn"`
+	MaxRefreshTokenExpireIn time.Duration `yaml:"maxRefreshTokenExpireIn"`
+	// RateLimit limits the requests to the authorize and token endpoints
+	RateLimit RateLimit `yaml:"rateLimit"`
+	// OIDC makes horizon an openid connect provider
+	OIDC OIDC `yaml:"oidc"`
+	// Device is the device authorization grant for clients without browsers, such as CLIs, ref: rfc8628
+	Device Device `yaml:"device"`
+}
+
+// Device is disabled if the verification uri is not set
+type Device struct {
+	// VerificationURI is the page where users enter the user codes, such as https://horizon.example.com/oauth/device
+	VerificationURI string        `yaml:"verificationURI"`
+	CodeExpireIn    time.Duration `yaml:"codeExpireIn"`
+	// Interval is the time clients wait between polling the token endpoint
+	Interval time.Duration `yaml:"interval"`
+}
+
+func (d Device) Enabled() bool {
+	return d.VerificationURI != ""
+}
+
+// OIDC issues id tokens to clients requesting the openid scope, it is disabled if the issuer is not set
+type OIDC struct {
+	// Issuer is the external url of horizon, such as https://horizon.example.com
+	Issuer string `yaml:"issuer"`
+	// SigningKeyFile is the PEM encoded RSA private key to sign id tokens
+	SigningKeyFile  string        `yaml:"signingKeyFile"`
+	IDTokenExpireIn time.Duration `yaml:"idTokenExpireIn"`
+}
+
+func (o OIDC) Enabled() bool {
+	return o.Issuer != ""
+}
+
+// RateLimit is a token bucket for each pair of client and source ip, and another one for each client
+// which bounds the requests of a client from all the source ips
+type RateLimit struct {
+	// Rate is the requests allowed per second, zero disables the limit
+	Rate float64 `yaml:"rate"`
+	// Burst is the size of the bucket, defaults to the rate
+	Burst int `yaml:"burst"`
+	// ClientRate is the requests of a client allowed per second, zero disables the limit
+	ClientRate float64 `yaml:"clientRate"`
+	// ClientBurst is the size of the bucket of a client, defaults to the client rate
+	ClientBurst int `yaml:"clientBurst"`
 }
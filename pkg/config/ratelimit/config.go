@@ -0,0 +1,53 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+const (
+	// BackendMemory keeps the buckets in each server, so the limits apply to each server separately
+	BackendMemory = "memory"
+	// BackendRedis keeps the buckets in redisConfig, so the limits are shared by all servers
+	BackendRedis = "redis"
+
+	// DefaultRoute is the name of the global limits, which can not be used by routes
+	DefaultRoute = "default"
+)
+
+// Limit is a token bucket for each user or each source ip
+type Limit struct {
+	// Rate is the requests allowed per second, zero disables the limit
+	Rate float64 `yaml:"rate"`
+	// Burst is the size of the bucket, defaults to the rate
+	Burst int `yaml:"burst"`
+}
+
+// Route is a class of routes limited separately from the others, such as deploys or searches
+type Route struct {
+	Name string `yaml:"name"`
+	// Methods are the http methods matched, all methods are matched if empty
+	Methods []string `yaml:"methods"`
+	// Path is the regular expression of the paths matched
+	Path string `yaml:"path"`
+	User Limit  `yaml:"user"`
+	IP   Limit  `yaml:"ip"`
+}
+
+// Config limits the api requests of each user and each source ip, the requests are limited
+// by the first route matched, or by the global limits if no route matches
+type Config struct {
+	Backend string  `yaml:"backend"`
+	User    Limit   `yaml:"user"`
+	IP      Limit   `yaml:"ip"`
+	Routes  []Route `yaml:"routes"`
+}
@@ -0,0 +1,37 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilience
+
+import "time"
+
+// Config is how requests to a service are protected from its transient failures,
+// the zero values are replaced by the defaults
+type Config struct {
+	// Timeout is the timeout of each attempt of a request, 30s by default
+	Timeout time.Duration `yaml:"timeout"`
+	// RetryMax is the max retries of a request failed or responded with 5xx or 429, 3 by default,
+	// requests are never retried if it's negative
+	RetryMax int `yaml:"retryMax"`
+	// RetryWaitMin is the wait before the first retry, which doubles with each retry, 500ms by default
+	RetryWaitMin time.Duration `yaml:"retryWaitMin"`
+	// RetryWaitMax bounds the wait before retries, including the one in Retry-After, 5s by default
+	RetryWaitMax time.Duration `yaml:"retryWaitMax"`
+	// FailureThreshold is the number of consecutive failed attempts opening the circuit breaker, 10 by default,
+	// the circuit breaker is disabled if it's negative
+	FailureThreshold int `yaml:"failureThreshold"`
+	// OpenDuration is how long the circuit breaker rejects requests before letting one through to probe,
+	// 30s by default
+	OpenDuration time.Duration `yaml:"openDuration"`
+}
@@ -0,0 +1,53 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import "time"
+
+type Config struct {
+	// ApplicationID is the application which sandbox clusters are created in, sandbox is disabled if it's zero
+	ApplicationID uint `yaml:"applicationID"`
+	// Environment must support auto-free, so that sandbox clusters are freed once their TTL expires
+	Environment string `yaml:"environment"`
+	Region      string `yaml:"region"`
+	// TTL is how long a sandbox cluster lives after its last update, default is 24h
+	TTL time.Duration `yaml:"ttl"`
+	// Templates lists the templates which sandbox clusters can be created from, all templates are allowed if empty
+	Templates []string `yaml:"templates"`
+	// Replicas and Resource override spec.replicas and spec.resource of the template config,
+	// Resource is kept as it's in the template config if empty
+	Replicas int    `yaml:"replicas"`
+	Resource string `yaml:"resource"`
+	// MaxPerUser is the number of sandbox clusters a user can hold at the same time, default is 1
+	MaxPerUser int `yaml:"maxPerUser"`
+}
+
+// Enabled tells whether users can create sandbox clusters
+func (c *Config) Enabled() bool {
+	return c.ApplicationID != 0
+}
+
+// TemplateAllowed tells whether sandbox clusters can be created from the template
+func (c *Config) TemplateAllowed(template string) bool {
+	if len(c.Templates) == 0 {
+		return true
+	}
+	for _, t := range c.Templates {
+		if t == template {
+			return true
+		}
+	}
+	return false
+}
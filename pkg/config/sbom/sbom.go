@@ -0,0 +1,29 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import "time"
+
+// Config of the sbom generated for the images built by pipelines
+type Config struct {
+	// Enabled adds a step generating the sbom of the image by syft to the pipelines of builddeploy
+	Enabled bool `yaml:"enabled"`
+	// Image is the syft image with a shell, default is anchore/syft:v0.98.0-debug
+	Image string `yaml:"image"`
+	// ServerURL is the address of horizon which the sbom is uploaded to from the pipelines
+	ServerURL string `yaml:"serverURL"`
+	// Timeout of the step, default is 10m
+	Timeout time.Duration `yaml:"timeout"`
+}
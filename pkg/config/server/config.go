@@ -14,6 +14,43 @@
 
 package server
 
+import "time"
+
 type Config struct {
 	Port int `yaml:"port"`
+	// ShutdownTimeout is the time to drain in-flight requests on shutdown before connections are closed forcibly
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// TLS serves HTTPS instead of HTTP if set
+	TLS TLS `yaml:"tls"`
+	// ReadinessTimeout bounds the check of each dependency on /ready
+	ReadinessTimeout time.Duration `yaml:"readinessTimeout"`
+	// Debug exposes pprof and runtime stats to profile the live server
+	Debug Debug `yaml:"debug"`
+	// TrustedProxies are the ips or cidrs of the proxies in front of the server, the client ip is taken
+	// from X-Forwarded-For only if the request comes from one of them, none is trusted if not set
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+// TLS is disabled if the cert file is not set
+type TLS struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// ClientCAFile enables mutual TLS, clients are required to present certificates signed by the CAs
+	ClientCAFile string `yaml:"clientCAFile"`
+	// ReloadInterval is the interval to check the files for changes, so that rotated certificates
+	// take effect without a restart
+	ReloadInterval time.Duration `yaml:"reloadInterval"`
+}
+
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Debug serves /debug/pprof/*, /debug/goroutines and /debug/gcstats
+type Debug struct {
+	// Enabled serves the endpoints on the server port, only admins are allowed to access them
+	Enabled bool `yaml:"enabled"`
+	// Port serves the endpoints on a separate port without authentication if set,
+	// it should not be exposed outside the cluster
+	Port int `yaml:"port"`
 }
@@ -0,0 +1,26 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenclean
+
+import "time"
+
+type Config struct {
+	// JobInterval is the interval of purging expired tokens, default is 1h
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// BatchInterval is the interval between batches of tokens
+	BatchInterval time.Duration `yaml:"batchInterval"`
+	// BatchSize is the number of tokens checked in a batch, default is 500
+	BatchSize int `yaml:"batchSize"`
+}
@@ -0,0 +1,36 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import "time"
+
+// Config exports the spans of requests to an OpenTelemetry collector, it is disabled if the endpoint is not set
+type Config struct {
+	// Endpoint is the OTLP/HTTP endpoint of the collector, such as http://otel-collector:4318
+	Endpoint string `yaml:"endpoint"`
+	// Headers are sent along with the spans exported, such as the authorization of the collector
+	Headers     map[string]string `yaml:"headers"`
+	ServiceName string            `yaml:"serviceName"`
+	// SampleRatio in (0, 1] is the ratio of requests traced, 1 by default.
+	// Requests with the traceparent header follow the sampling decision of the callers
+	SampleRatio float64 `yaml:"sampleRatio"`
+	// BatchSize and FlushInterval bound the spans buffered before they are exported
+	BatchSize     int           `yaml:"batchSize"`
+	FlushInterval time.Duration `yaml:"flushInterval"`
+}
+
+func (c Config) Enabled() bool {
+	return c.Endpoint != ""
+}
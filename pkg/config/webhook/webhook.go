@@ -23,4 +23,10 @@ type Config struct {
 	WorkerReconcileInterval uint `yaml:"workerReconcileInterval"`
 	// bytes limit to truncate for response body
 	ResponseBodyTruncateSize uint `yaml:"responseBodyTruncateSize"`
+	// times to retry a delivery failed temporarily before it's recorded as a dead letter
+	MaxRetries uint `yaml:"maxRetries"`
+	// seconds to wait before the first retry, it's doubled for each of the following retries
+	RetryBackoff uint `yaml:"retryBackoff"`
+	// seconds to wait at most between retries
+	MaxRetryBackoff uint `yaml:"maxRetryBackoff"`
 }
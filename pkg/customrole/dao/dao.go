@@ -0,0 +1,105 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/customrole/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, role *models.CustomRole) error
+	Update(ctx context.Context, role *models.CustomRole) error
+	Delete(ctx context.Context, name string) error
+	GetByName(ctx context.Context, name string) (*models.CustomRole, error)
+	List(ctx context.Context) ([]*models.CustomRole, error)
+	CountMembers(ctx context.Context, name string) (int64, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, role *models.CustomRole) error {
+	result := d.db.WithContext(ctx).Create(role)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.CustomRoleInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) Update(ctx context.Context, role *models.CustomRole) error {
+	result := d.db.WithContext(ctx).Exec(common.CustomRoleUpdate, role.Desc, role.Rules,
+		role.UpdatedBy, time.Now(), role.Name)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.CustomRoleInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.CustomRoleInDB, fmt.Sprintf("role %s not found", role.Name))
+	}
+	return nil
+}
+
+func (d *dao) Delete(ctx context.Context, name string) error {
+	result := d.db.WithContext(ctx).Exec(common.CustomRoleDelete, name)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.CustomRoleInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.CustomRoleInDB, fmt.Sprintf("role %s not found", name))
+	}
+	return nil
+}
+
+func (d *dao) GetByName(ctx context.Context, name string) (*models.CustomRole, error) {
+	var role models.CustomRole
+	result := d.db.WithContext(ctx).Raw(common.CustomRoleGetByName, name).Scan(&role)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.CustomRoleInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.CustomRoleInDB, fmt.Sprintf("role %s not found", name))
+	}
+	return &role, nil
+}
+
+func (d *dao) List(ctx context.Context) ([]*models.CustomRole, error) {
+	var roles []*models.CustomRole
+	result := d.db.WithContext(ctx).Raw(common.CustomRoleList).Scan(&roles)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.CustomRoleInDB, result.Error.Error())
+	}
+	return roles, nil
+}
+
+func (d *dao) CountMembers(ctx context.Context, name string) (int64, error) {
+	var count int64
+	result := d.db.WithContext(ctx).Raw(common.CustomRoleCountMembers, name).Scan(&count)
+	if result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.CustomRoleInDB, result.Error.Error())
+	}
+	return count, nil
+}
@@ -0,0 +1,110 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/customrole/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.CustomRole{}, &membermodels.Member{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByName(ctx, "deployer")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	err = mgr.Create(ctx, &models.CustomRole{
+		Name:      "deployer",
+		Desc:      "deploy only",
+		Rules:     "[]",
+		CreatedBy: 1,
+		UpdatedBy: 1,
+	})
+	assert.Nil(t, err)
+	err = mgr.Create(ctx, &models.CustomRole{
+		Name:  "auditor2",
+		Rules: "[]",
+	})
+	assert.Nil(t, err)
+
+	role, err := mgr.GetByName(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, "deploy only", role.Desc)
+	assert.Equal(t, uint(1), role.CreatedBy)
+
+	err = mgr.Update(ctx, &models.CustomRole{
+		Name:      "deployer",
+		Desc:      "deploy and restart",
+		Rules:     `[{"verbs":["create"]}]`,
+		UpdatedBy: 2,
+	})
+	assert.Nil(t, err)
+	role, err = mgr.GetByName(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, "deploy and restart", role.Desc)
+	assert.Equal(t, `[{"verbs":["create"]}]`, role.Rules)
+	assert.Equal(t, uint(2), role.UpdatedBy)
+
+	err = mgr.Update(ctx, &models.CustomRole{Name: "unknown"})
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	roles, err := mgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(roles))
+	assert.Equal(t, "auditor2", roles[0].Name)
+
+	count, err := mgr.CountMembers(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+	assert.Nil(t, db.Create(&membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   1,
+		Role:         "deployer",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: 1,
+	}).Error)
+	count, err = mgr.CountMembers(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	err = mgr.Delete(ctx, "auditor2")
+	assert.Nil(t, err)
+	err = mgr.Delete(ctx, "auditor2")
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
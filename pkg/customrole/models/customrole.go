@@ -0,0 +1,31 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// CustomRole is a role defined by admins besides the built-in roles of the roles file,
+// it's granted to members the same way as the built-in ones
+type CustomRole struct {
+	ID   uint
+	Name string `gorm:"uniqueIndex:idx_name"`
+	Desc string
+	// Rules are the policy rules of the role in json
+	Rules     string
+	CreatedAt time.Time
+	CreatedBy uint
+	UpdatedAt time.Time
+	UpdatedBy uint
+}
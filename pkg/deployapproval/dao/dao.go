@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/deployapproval/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, approval *models.DeployApproval) (*models.DeployApproval, error)
+	GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.DeployApproval, error)
+	ListByEnvironments(ctx context.Context, environments []string, status string) ([]*models.DeployApproval, error)
+	UpdateReview(ctx context.Context, approval *models.DeployApproval) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, approval *models.DeployApproval) (*models.DeployApproval, error) {
+	result := d.db.WithContext(ctx).Create(approval)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.DeployApprovalInDB, result.Error.Error())
+	}
+	return approval, nil
+}
+
+func (d *dao) GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.DeployApproval, error) {
+	var approval models.DeployApproval
+	result := d.db.WithContext(ctx).Raw(common.DeployApprovalGetByPipelinerunID, pipelinerunID).Scan(&approval)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.DeployApprovalInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.DeployApprovalInDB,
+			fmt.Sprintf("no deploy approval found for pipelinerun %d", pipelinerunID))
+	}
+	return &approval, nil
+}
+
+func (d *dao) ListByEnvironments(ctx context.Context, environments []string,
+	status string) ([]*models.DeployApproval, error) {
+	approvals := make([]*models.DeployApproval, 0)
+	if len(environments) == 0 {
+		return approvals, nil
+	}
+	query := d.db.WithContext(ctx).Where("environment in ?", environments)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("id desc").Find(&approvals)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.DeployApprovalInDB, result.Error.Error())
+	}
+	return approvals, nil
+}
+
+func (d *dao) UpdateReview(ctx context.Context, approval *models.DeployApproval) error {
+	result := d.db.WithContext(ctx).Model(approval).
+		Where("status = ?", models.StatusPending).
+		Select("status", "reviewed_by", "review_comment", "reviewed_at").
+		Updates(approval)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.DeployApprovalInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.DeployApprovalInDB,
+			fmt.Sprintf("no pending deploy approval found for id %d", approval.ID))
+	}
+	return nil
+}
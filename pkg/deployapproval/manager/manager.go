@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/deployapproval/dao"
+	"github.com/horizoncd/horizon/pkg/deployapproval/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, approval *models.DeployApproval) (*models.DeployApproval, error)
+	GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.DeployApproval, error)
+	// ListByEnvironments lists approvals of the environments in the status, latest first,
+	// all approvals are listed if status is empty
+	ListByEnvironments(ctx context.Context, environments []string, status string) ([]*models.DeployApproval, error)
+	// UpdateReview saves the review result of a pending approval,
+	// it returns not found if the approval has been reviewed
+	UpdateReview(ctx context.Context, approval *models.DeployApproval) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, approval *models.DeployApproval) (*models.DeployApproval, error) {
+	return m.dao.Create(ctx, approval)
+}
+
+func (m *manager) GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.DeployApproval, error) {
+	return m.dao.GetByPipelinerunID(ctx, pipelinerunID)
+}
+
+func (m *manager) ListByEnvironments(ctx context.Context, environments []string,
+	status string) ([]*models.DeployApproval, error) {
+	return m.dao.ListByEnvironments(ctx, environments, status)
+}
+
+func (m *manager) UpdateReview(ctx context.Context, approval *models.DeployApproval) error {
+	return m.dao.UpdateReview(ctx, approval)
+}
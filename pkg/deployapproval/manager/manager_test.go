@@ -0,0 +1,98 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/deployapproval/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.DeployApproval{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByPipelinerunID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	for i, env := range []string{"online", "online", "pre"} {
+		_, err := mgr.Create(ctx, &models.DeployApproval{
+			PipelinerunID: uint(i + 1),
+			ClusterID:     1,
+			Environment:   env,
+			Status:        models.StatusPending,
+			CreatedBy:     1,
+		})
+		assert.Nil(t, err)
+	}
+
+	approvals, err := mgr.ListByEnvironments(ctx, []string{"online"}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(approvals))
+	assert.Equal(t, uint(2), approvals[0].PipelinerunID)
+
+	approvals, err = mgr.ListByEnvironments(ctx, nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(approvals))
+
+	// approve the deploy
+	now := time.Now()
+	approval, err := mgr.GetByPipelinerunID(ctx, 2)
+	assert.Nil(t, err)
+	approval.Status = models.StatusApproved
+	approval.ReviewedBy = 2
+	approval.ReviewComment = "lgtm"
+	approval.ReviewedAt = &now
+	assert.Nil(t, mgr.UpdateReview(ctx, approval))
+
+	approval, err = mgr.GetByPipelinerunID(ctx, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusApproved, approval.Status)
+	assert.Equal(t, uint(2), approval.ReviewedBy)
+	assert.Equal(t, "lgtm", approval.ReviewComment)
+	assert.NotNil(t, approval.ReviewedAt)
+
+	// an approval can only be reviewed once
+	approval.Status = models.StatusRejected
+	err = mgr.UpdateReview(ctx, approval)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	approvals, err = mgr.ListByEnvironments(ctx, []string{"online", "pre"}, models.StatusPending)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(approvals))
+	assert.Equal(t, uint(3), approvals[0].PipelinerunID)
+	assert.Equal(t, uint(1), approvals[1].PipelinerunID)
+}
@@ -0,0 +1,38 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// DeployApproval records the approval of a pipelinerun deploying to a protected environment
+type DeployApproval struct {
+	ID            uint
+	PipelinerunID uint `gorm:"uniqueIndex:idx_pipelinerun_id"`
+	ClusterID     uint
+	Environment   string `gorm:"index:idx_environment_status"`
+	Status        string `gorm:"index:idx_environment_status"`
+	ReviewedBy    uint
+	ReviewComment string
+	ReviewedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     uint
+}
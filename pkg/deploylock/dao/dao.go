@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/deploylock/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	Upsert(ctx context.Context, lock *models.DeployLock) error
+	GetByResource(ctx context.Context, resourceType string, resourceID uint) (*models.DeployLock, error)
+	DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Upsert(ctx context.Context, lock *models.DeployLock) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "resource_type"}, {Name: "resource_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "expire_at", "created_by",
+			"created_at", "updated_at"}),
+	}).Create(lock)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.DeployLockInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) GetByResource(ctx context.Context, resourceType string,
+	resourceID uint) (*models.DeployLock, error) {
+	var lock models.DeployLock
+	result := d.db.WithContext(ctx).Raw(common.DeployLockGetByResource, resourceType,
+		resourceID, time.Now()).Scan(&lock)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.DeployLockInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.DeployLockInDB,
+			fmt.Sprintf("no deploy lock found for %s %d", resourceType, resourceID))
+	}
+	return &lock, nil
+}
+
+func (d *dao) DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error {
+	result := d.db.WithContext(ctx).Exec(common.DeployLockDeleteByResource, resourceType, resourceID)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.DeployLockInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/deploylock/dao"
+	"github.com/horizoncd/horizon/pkg/deploylock/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// Lock creates the lock of the resource, or replaces the existing one
+	Lock(ctx context.Context, lock *models.DeployLock) error
+	// Unlock removes the lock of the resource, it is fine if the resource is not locked
+	Unlock(ctx context.Context, resourceType string, resourceID uint) error
+	// GetByResource returns the lock of the resource, expired locks are regarded as not found
+	GetByResource(ctx context.Context, resourceType string, resourceID uint) (*models.DeployLock, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Lock(ctx context.Context, lock *models.DeployLock) error {
+	return m.dao.Upsert(ctx, lock)
+}
+
+func (m *manager) Unlock(ctx context.Context, resourceType string, resourceID uint) error {
+	return m.dao.DeleteByResource(ctx, resourceType, resourceID)
+}
+
+func (m *manager) GetByResource(ctx context.Context, resourceType string,
+	resourceID uint) (*models.DeployLock, error) {
+	return m.dao.GetByResource(ctx, resourceType, resourceID)
+}
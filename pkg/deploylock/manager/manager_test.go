@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/deploylock/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.DeployLock{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByResource(ctx, common.ResourceCluster, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	err = mgr.Lock(ctx, &models.DeployLock{
+		ResourceType: common.ResourceCluster,
+		ResourceID:   1,
+		Reason:       "incident",
+		CreatedBy:    1,
+	})
+	assert.Nil(t, err)
+	lock, err := mgr.GetByResource(ctx, common.ResourceCluster, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "incident", lock.Reason)
+	assert.Nil(t, lock.ExpireAt)
+
+	// lock again replaces the existing one
+	expireAt := time.Now().Add(time.Hour)
+	err = mgr.Lock(ctx, &models.DeployLock{
+		ResourceType: common.ResourceCluster,
+		ResourceID:   1,
+		Reason:       "incident again",
+		ExpireAt:     &expireAt,
+		CreatedBy:    2,
+	})
+	assert.Nil(t, err)
+	lock, err = mgr.GetByResource(ctx, common.ResourceCluster, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "incident again", lock.Reason)
+	assert.Equal(t, uint(2), lock.CreatedBy)
+	assert.NotNil(t, lock.ExpireAt)
+
+	// other resources are not affected
+	_, err = mgr.GetByResource(ctx, common.ResourceApplication, 1)
+	assert.NotNil(t, err)
+
+	// expired lock is ignored
+	expireAt = time.Now().Add(-time.Minute)
+	err = mgr.Lock(ctx, &models.DeployLock{
+		ResourceType: common.ResourceApplication,
+		ResourceID:   1,
+		Reason:       "expired",
+		ExpireAt:     &expireAt,
+	})
+	assert.Nil(t, err)
+	_, err = mgr.GetByResource(ctx, common.ResourceApplication, 1)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	err = mgr.Unlock(ctx, common.ResourceCluster, 1)
+	assert.Nil(t, err)
+	_, err = mgr.GetByResource(ctx, common.ResourceCluster, 1)
+	assert.NotNil(t, err)
+	err = mgr.Unlock(ctx, common.ResourceCluster, 1)
+	assert.Nil(t, err)
+}
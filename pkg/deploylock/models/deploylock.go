@@ -0,0 +1,31 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// DeployLock forbids deploys of a cluster or of all clusters of an application,
+// it is used by operators during incident response
+type DeployLock struct {
+	ID           uint
+	ResourceType string `gorm:"uniqueIndex:idx_resource"`
+	ResourceID   uint   `gorm:"uniqueIndex:idx_resource"`
+	Reason       string
+	// ExpireAt nil means the lock is held until unlocked
+	ExpireAt  *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+}
@@ -0,0 +1,368 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auth"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	deploywindowconfig "github.com/horizoncd/horizon/pkg/config/deploywindow"
+	deploylockmanager "github.com/horizoncd/horizon/pkg/deploylock/manager"
+	deploylockmodels "github.com/horizoncd/horizon/pkg/deploylock/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/sets"
+)
+
+// SubresourceFreezeOverride is the subresource of clusters granted to users who can deploy
+// during freeze windows for emergencies
+const SubresourceFreezeOverride = "freezeoverride"
+
+var (
+	// inProgressStatuses are statuses of pipelineruns which are deploying
+	inProgressStatuses = []prmodels.PipelineStatus{prmodels.StatusCreated, prmodels.StatusRunning}
+	// upcomingStatuses are statuses of pipelineruns which are waiting to be executed
+	upcomingStatuses = []prmodels.PipelineStatus{prmodels.StatusPending, prmodels.StatusReady}
+)
+
+// Conflict is a deploy in progress which collides with another deploy
+type Conflict struct {
+	ClusterID     uint       `json:"clusterID"`
+	ClusterName   string     `json:"clusterName"`
+	PipelinerunID uint       `json:"pipelinerunID"`
+	Action        string     `json:"action"`
+	Status        string     `json:"status"`
+	StartedAt     *time.Time `json:"startedAt"`
+	// Coupled is true if the conflict comes from a cluster coupled with the deploying one
+	Coupled bool `json:"coupled"`
+}
+
+type Deploy struct {
+	ClusterID     uint        `json:"clusterID"`
+	ClusterName   string      `json:"clusterName"`
+	PipelinerunID uint        `json:"pipelinerunID"`
+	Title         string      `json:"title"`
+	Action        string      `json:"action"`
+	Status        string      `json:"status"`
+	CreatedAt     time.Time   `json:"createdAt"`
+	StartedAt     *time.Time  `json:"startedAt"`
+	Conflicts     []*Conflict `json:"conflicts"`
+}
+
+// Window is the deploy window of an application
+type Window struct {
+	// InProgress are deploys which are running now
+	InProgress []*Deploy `json:"inProgress"`
+	// Upcoming are deploys which are waiting for checks or to be executed
+	Upcoming []*Deploy `json:"upcoming"`
+}
+
+type Service interface {
+	// Check detects deploys in progress which collide with a deploy of the cluster.
+	// It returns ErrDeployLocked when the cluster or its application is locked, ErrDeployFrozen
+	// when the environment is frozen now, and ErrDeployConflict when conflicts are found and the policy is queue.
+	Check(ctx context.Context, clusterID uint, pipelinerunID uint) ([]*Conflict, error)
+	// CheckFreeze returns ErrDeployFrozen if the environment of the cluster is frozen at the time,
+	// unless the current user is allowed to override freeze windows
+	CheckFreeze(ctx context.Context, cluster *clustermodels.Cluster, at time.Time) error
+	// GetWindow returns deploys in progress and upcoming deploys of the application
+	GetWindow(ctx context.Context, applicationID uint) (*Window, error)
+}
+
+type service struct {
+	config        deploywindowconfig.Config
+	clusterMgr    clustermanager.Manager
+	tagMgr        tagmanager.Manager
+	prMgr         *prmanager.PRManager
+	deployLockMgr deploylockmanager.Manager
+	userMgr       usermanager.Manager
+	authorizer    auth.Authorizer
+}
+
+func NewService(manager *managerparam.Manager, config deploywindowconfig.Config,
+	authorizer auth.Authorizer) Service {
+	return &service{
+		config:        config,
+		authorizer:    authorizer,
+		clusterMgr:    manager.ClusterMgr,
+		tagMgr:        manager.TagMgr,
+		prMgr:         manager.PRMgr,
+		deployLockMgr: manager.DeployLockMgr,
+		userMgr:       manager.UserMgr,
+	}
+}
+
+func (s *service) Check(ctx context.Context, clusterID uint, pipelinerunID uint) ([]*Conflict, error) {
+	cluster, err := s.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLock(ctx, cluster); err != nil {
+		return nil, err
+	}
+	if err := s.CheckFreeze(ctx, cluster, time.Now()); err != nil {
+		return nil, err
+	}
+	conflicts, err := s.conflicts(ctx, clusterID, cluster.Name, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) == 0 {
+		return conflicts, nil
+	}
+
+	if s.config.ConflictPolicy == deploywindowconfig.ConflictPolicyQueue {
+		return conflicts, perror.Wrapf(herrors.ErrDeployConflict,
+			"cluster %s is blocked by pipelinerun %d of cluster %s, please retry after it finishes",
+			cluster.Name, conflicts[0].PipelinerunID, conflicts[0].ClusterName)
+	}
+	log.Warningf(ctx, "deploy of cluster %s conflicts with %d deploys in progress, the first is pipelinerun %d",
+		cluster.Name, len(conflicts), conflicts[0].PipelinerunID)
+	return conflicts, nil
+}
+
+func (s *service) GetWindow(ctx context.Context, applicationID uint) (*Window, error) {
+	_, clusters, err := s.clusterMgr.List(ctx, &q.Query{
+		Keywords:          q.KeyWords{common.ParamApplicationID: applicationID},
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clusterNames := make(map[uint]string, len(clusters))
+	clusterIDs := make([]uint, 0, len(clusters))
+	for _, cluster := range clusters {
+		clusterNames[cluster.ID] = cluster.Name
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+
+	pipelineruns, err := s.prMgr.PipelineRun.ListByClusterIDsAndStatuses(ctx, clusterIDs,
+		append(inProgressStatuses, upcomingStatuses...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &Window{
+		InProgress: make([]*Deploy, 0),
+		Upcoming:   make([]*Deploy, 0),
+	}
+	for _, pr := range pipelineruns {
+		conflicts, err := s.conflicts(ctx, pr.ClusterID, clusterNames[pr.ClusterID], pr.ID)
+		if err != nil {
+			return nil, err
+		}
+		deploy := &Deploy{
+			ClusterID:     pr.ClusterID,
+			ClusterName:   clusterNames[pr.ClusterID],
+			PipelinerunID: pr.ID,
+			Title:         pr.Title,
+			Action:        pr.Action,
+			Status:        pr.Status,
+			CreatedAt:     pr.CreatedAt,
+			StartedAt:     pr.StartedAt,
+			Conflicts:     conflicts,
+		}
+		if isInProgress(pr.Status) {
+			window.InProgress = append(window.InProgress, deploy)
+		} else {
+			window.Upcoming = append(window.Upcoming, deploy)
+		}
+	}
+	return window, nil
+}
+
+// conflicts lists deploys in progress of the cluster and its coupled clusters,
+// the pipelinerun itself is excluded
+func (s *service) conflicts(ctx context.Context, clusterID uint, clusterName string,
+	pipelinerunID uint) ([]*Conflict, error) {
+	clusterNames, err := s.coupledClusters(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	clusterNames[clusterID] = clusterName
+
+	clusterIDs := make([]uint, 0, len(clusterNames))
+	for id := range clusterNames {
+		clusterIDs = append(clusterIDs, id)
+	}
+	pipelineruns, err := s.prMgr.PipelineRun.ListByClusterIDsAndStatuses(ctx, clusterIDs, inProgressStatuses...)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]*Conflict, 0)
+	for _, pr := range pipelineruns {
+		if pr.ID == pipelinerunID {
+			continue
+		}
+		conflicts = append(conflicts, &Conflict{
+			ClusterID:     pr.ClusterID,
+			ClusterName:   clusterNames[pr.ClusterID],
+			PipelinerunID: pr.ID,
+			Action:        pr.Action,
+			Status:        pr.Status,
+			StartedAt:     pr.StartedAt,
+			Coupled:       pr.ClusterID != clusterID,
+		})
+	}
+	return conflicts, nil
+}
+
+// checkLock returns ErrDeployLocked with the owner and reason of the lock
+// if the cluster or its application is locked
+func (s *service) checkLock(ctx context.Context, cluster *clustermodels.Cluster) error {
+	locked := func(resourceType string, resourceID uint) (*deploylockmodels.DeployLock, error) {
+		lock, err := s.deployLockMgr.GetByResource(ctx, resourceType, resourceID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return lock, nil
+	}
+
+	target := fmt.Sprintf("cluster %s", cluster.Name)
+	lock, err := locked(common.ResourceCluster, cluster.ID)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		target = fmt.Sprintf("application of cluster %s", cluster.Name)
+		if lock, err = locked(common.ResourceApplication, cluster.ApplicationID); err != nil {
+			return err
+		}
+	}
+	if lock == nil {
+		return nil
+	}
+
+	owner := fmt.Sprintf("user %d", lock.CreatedBy)
+	if user, err := s.userMgr.GetUserByID(ctx, lock.CreatedBy); err == nil {
+		owner = fmt.Sprintf("%s(%s)", user.FullName, user.Email)
+	}
+	until := "it is unlocked"
+	if lock.ExpireAt != nil {
+		until = lock.ExpireAt.Format(time.RFC3339)
+	}
+	return perror.Wrapf(herrors.ErrDeployLocked, "%s is locked by %s until %s, reason: %s",
+		target, owner, until, lock.Reason)
+}
+
+func (s *service) CheckFreeze(ctx context.Context, cluster *clustermodels.Cluster, at time.Time) error {
+	for _, window := range s.config.FreezeWindows {
+		if window == nil || !window.AppliesTo(cluster.EnvironmentName) {
+			continue
+		}
+		end, ok := window.Active(at)
+		if !ok {
+			continue
+		}
+		overridden, err := s.canOverrideFreeze(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		if overridden {
+			log.Warningf(ctx, "deploy of cluster %s overrides freeze window %s until %s",
+				cluster.Name, window.Name, end.Format(time.RFC3339))
+			return nil
+		}
+		return perror.Wrapf(herrors.ErrDeployFrozen, "deploys to environment %s are frozen by %s until %s",
+			cluster.EnvironmentName, window.Name, end.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// canOverrideFreeze tells whether the current user is granted to deploy the cluster during freeze windows
+func (s *service) canOverrideFreeze(ctx context.Context, cluster *clustermodels.Cluster) (bool, error) {
+	if s.authorizer == nil {
+		return false, nil
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return false, nil
+	}
+	decision, _, err := s.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            "create",
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceCluster,
+		SubResource:     SubresourceFreezeOverride,
+		Name:            strconv.FormatUint(uint64(cluster.ID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	return decision == auth.DecisionAllow, nil
+}
+
+// coupledClusters returns clusters having the same value of the coupled tag as the cluster
+func (s *service) coupledClusters(ctx context.Context, clusterID uint) (map[uint]string, error) {
+	clusterNames := make(map[uint]string)
+	if s.config.CoupledTagKey == "" {
+		return clusterNames, nil
+	}
+
+	tags, err := s.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if tag.Key != s.config.CoupledTagKey {
+			continue
+		}
+		_, clusters, err := s.clusterMgr.List(ctx, &q.Query{
+			Keywords: q.KeyWords{
+				common.ClusterQueryTagSelector: []tagmodels.TagSelector{{
+					Key:      tag.Key,
+					Values:   sets.NewString(tag.Value),
+					Operator: tagmodels.In,
+				}},
+			},
+			WithoutPagination: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters {
+			clusterNames[cluster.ID] = cluster.Name
+		}
+	}
+	return clusterNames, nil
+}
+
+func isInProgress(status string) bool {
+	for _, s := range inProgressStatuses {
+		if string(s) == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	deploywindowconfig "github.com/horizoncd/horizon/pkg/config/deploywindow"
+	deploylockmodels "github.com/horizoncd/horizon/pkg/deploylock/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&clustermodels.Cluster{}, &regionmodels.Region{},
+		&templatemodels.Template{}, &tagmodels.Tag{}, &prmodels.Pipelinerun{},
+		&deploylockmodels.DeployLock{}, &usermodels.User{}))
+	callbacks.RegisterCustomCallbacks(db)
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	manager := managerparam.InitManager(db)
+
+	assert.Nil(t, db.Create(&regionmodels.Region{Name: "hz"}).Error)
+	clusterA := &clustermodels.Cluster{Name: "cluster-a", ApplicationID: 1, RegionName: "hz"}
+	clusterB := &clustermodels.Cluster{Name: "cluster-b", ApplicationID: 1, RegionName: "hz"}
+	clusterC := &clustermodels.Cluster{Name: "cluster-c", ApplicationID: 2, RegionName: "hz"}
+	for _, cluster := range []*clustermodels.Cluster{clusterA, clusterB, clusterC} {
+		assert.Nil(t, db.Create(cluster).Error)
+	}
+	// cluster-a and cluster-c are coupled
+	for _, cluster := range []*clustermodels.Cluster{clusterA, clusterC} {
+		assert.Nil(t, manager.TagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, cluster.ID,
+			[]*tagmodels.TagBasic{{Key: "deployGroup", Value: "payment"}}))
+	}
+
+	running, err := manager.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: clusterC.ID,
+		Action:    prmodels.ActionDeploy,
+		Status:    string(prmodels.StatusRunning),
+	})
+	assert.Nil(t, err)
+	ready, err := manager.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: clusterA.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusReady),
+	})
+	assert.Nil(t, err)
+
+	// without coupled tag, clusters are deployed independently
+	svc := NewService(manager, deploywindowconfig.Config{}, nil)
+	conflicts, err := svc.Check(ctx, clusterA.ID, ready.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(conflicts))
+
+	svc = NewService(manager, deploywindowconfig.Config{
+		ConflictPolicy: deploywindowconfig.ConflictPolicyWarn,
+		CoupledTagKey:  "deployGroup",
+	}, nil)
+	conflicts, err = svc.Check(ctx, clusterA.ID, ready.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(conflicts))
+	assert.Equal(t, running.ID, conflicts[0].PipelinerunID)
+	assert.Equal(t, "cluster-c", conflicts[0].ClusterName)
+	assert.True(t, conflicts[0].Coupled)
+
+	conflicts, err = svc.Check(ctx, clusterB.ID, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(conflicts))
+
+	window, err := svc.GetWindow(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(window.InProgress))
+	assert.Equal(t, 1, len(window.Upcoming))
+	assert.Equal(t, ready.ID, window.Upcoming[0].PipelinerunID)
+	assert.Equal(t, "cluster-a", window.Upcoming[0].ClusterName)
+	assert.Equal(t, 1, len(window.Upcoming[0].Conflicts))
+
+	svc = NewService(manager, deploywindowconfig.Config{
+		ConflictPolicy: deploywindowconfig.ConflictPolicyQueue,
+		CoupledTagKey:  "deployGroup",
+	}, nil)
+	_, err = svc.Check(ctx, clusterA.ID, ready.ID)
+	assert.Equal(t, herrors.ErrDeployConflict, perror.Cause(err))
+
+	// locks of the cluster or its application block deploys
+	assert.Nil(t, db.Create(&usermodels.User{Name: "tony", FullName: "Tony", Email: "tony@horizon.com"}).Error)
+	assert.Nil(t, manager.DeployLockMgr.Lock(ctx, &deploylockmodels.DeployLock{
+		ResourceType: common.ResourceApplication,
+		ResourceID:   1,
+		Reason:       "incident",
+		CreatedBy:    1,
+	}))
+	_, err = svc.Check(ctx, clusterB.ID, 0)
+	assert.Equal(t, herrors.ErrDeployLocked, perror.Cause(err))
+	assert.Contains(t, err.Error(), "application of cluster cluster-b is locked by Tony(tony@horizon.com)")
+	assert.Contains(t, err.Error(), "reason: incident")
+
+	assert.Nil(t, manager.DeployLockMgr.Unlock(ctx, common.ResourceApplication, 1))
+	_, err = svc.Check(ctx, clusterB.ID, 0)
+	assert.Nil(t, err)
+}
+
+// clusterAuthorizer allows to override freeze windows in the clusters listed only
+type clusterAuthorizer map[string]bool
+
+func (a clusterAuthorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetResource() == common.ResourceCluster && attr.GetSubResource() == SubresourceFreezeOverride &&
+		attr.GetVerb() == "create" && a[attr.GetName()] {
+		return auth.DecisionAllow, "", nil
+	}
+	return auth.DecisionDeny, "not a pe", nil
+}
+
+func TestFreezeWindow(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	assert.Nil(t, err)
+	weekend := &deploywindowconfig.FreezeWindow{
+		Name:         "weekend",
+		Environments: []string{"online"},
+		Start:        "Fri 18:00",
+		End:          "Monday 08:00",
+		Timezone:     "Asia/Shanghai",
+	}
+	assert.Nil(t, weekend.Validate())
+	assert.True(t, weekend.AppliesTo("online"))
+	assert.False(t, weekend.AppliesTo("test"))
+
+	monday := time.Date(2026, 10, 19, 8, 0, 0, 0, loc)
+	for at, frozen := range map[time.Time]bool{
+		time.Date(2026, 10, 16, 17, 59, 0, 0, loc): false,
+		time.Date(2026, 10, 16, 18, 0, 0, 0, loc):  true,
+		time.Date(2026, 10, 18, 12, 0, 0, 0, loc):  true,
+		time.Date(2026, 10, 19, 7, 59, 0, 0, loc):  true,
+		monday: false,
+		// the same instant in another timezone
+		time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC): true,
+	} {
+		end, ok := weekend.Active(at)
+		assert.Equal(t, frozen, ok, at.String())
+		if ok {
+			assert.True(t, end.Equal(monday), at.String())
+		}
+	}
+
+	holiday := &deploywindowconfig.FreezeWindow{
+		Name:     "holiday",
+		Start:    "2026-12-24 00:00",
+		End:      "2026-12-26 00:00",
+		Timezone: "Asia/Shanghai",
+	}
+	assert.Nil(t, holiday.Validate())
+	assert.True(t, holiday.AppliesTo("test"))
+	_, ok := holiday.Active(time.Date(2026, 12, 25, 12, 0, 0, 0, loc))
+	assert.True(t, ok)
+	_, ok = holiday.Active(time.Date(2026, 12, 26, 0, 0, 0, 0, loc))
+	assert.False(t, ok)
+
+	for _, window := range []*deploywindowconfig.FreezeWindow{
+		{Start: "Fri 18:00", End: "Fri 18:00"},
+		{Start: "Fri 25:00", End: "Mon 08:00"},
+		{Start: "Someday 18:00", End: "Mon 08:00"},
+		{Start: "2026-12-26 00:00", End: "2026-12-24 00:00"},
+		{Start: "Fri 18:00", End: "Mon 08:00", Timezone: "Mars/Olympus"},
+	} {
+		assert.NotNil(t, window.Validate(), window.Start)
+	}
+}
+
+func TestCheckFreeze(t *testing.T) {
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+	svc := NewService(&managerparam.Manager{}, deploywindowconfig.Config{
+		FreezeWindows: []*deploywindowconfig.FreezeWindow{{
+			Name:         "weekend",
+			Environments: []string{"online"},
+			Start:        "Fri 18:00",
+			End:          "Mon 08:00",
+			Timezone:     "UTC",
+		}},
+	}, clusterAuthorizer{"1": true})
+
+	saturday := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	online := &clustermodels.Cluster{Name: "online-cluster", EnvironmentName: "online"}
+	online.ID = 2
+	err := svc.CheckFreeze(ctx, online, saturday)
+	assert.Equal(t, herrors.ErrDeployFrozen, perror.Cause(err))
+	assert.Contains(t, err.Error(), "frozen by weekend until 2026-10-19T08:00:00Z")
+	assert.Nil(t, svc.CheckFreeze(ctx, online, saturday.AddDate(0, 0, 3)))
+
+	// users granted to override freeze windows can deploy for emergencies
+	online.ID = 1
+	assert.Nil(t, svc.CheckFreeze(ctx, online, saturday))
+
+	test := &clustermodels.Cluster{Name: "test-cluster", EnvironmentName: "test"}
+	test.ID = 3
+	assert.Nil(t, svc.CheckFreeze(ctx, test, saturday))
+}
@@ -33,6 +33,8 @@ import (
 type DAO interface {
 	CreateEvent(ctx context.Context, event ...*models.Event) ([]*models.Event, error)
 	List(ctx context.Context, query *q.Query) ([]*models.Event, error)
+	ListByResource(ctx context.Context, resourceType string, resourceID uint,
+		before uint, limit int) ([]*models.Event, error)
 	CreateOrUpdateCursor(ctx context.Context,
 		eventIndex *models.EventCursor) (*models.EventCursor, error)
 	GetCursor(ctx context.Context) (*models.EventCursor, error)
@@ -77,6 +79,10 @@ func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Event, error)
 			statement = statement.Where("id <= ?", v)
 		case common.ReqID:
 			statement = statement.Where("req_id = ?", v)
+		case common.StartTime:
+			statement = statement.Where("created_at >= ?", v)
+		case common.EndTime:
+			statement = statement.Where("created_at < ?", v)
 		}
 	}
 
@@ -86,6 +92,21 @@ func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Event, error)
 	return events, nil
 }
 
+// ListByResource lists the latest events of the resource with id less than before, unless before is 0
+func (d *dao) ListByResource(ctx context.Context, resourceType string, resourceID uint,
+	before uint, limit int) ([]*models.Event, error) {
+	var events []*models.Event
+	statement := d.db.WithContext(ctx).
+		Where("resource_type = ? and resource_id = ?", resourceType, resourceID)
+	if before > 0 {
+		statement = statement.Where("id < ?", before)
+	}
+	if result := statement.Order("id desc").Limit(limit).Find(&events); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.EventInDB, result.Error.Error())
+	}
+	return events, nil
+}
+
 func (d *dao) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
 	var event *models.Event
 	if result := d.db.WithContext(ctx).Where("id = ?", id).First(&event); result.Error != nil {
@@ -16,23 +16,35 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/event/dao"
 	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/event/publisher"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
 type Manager interface {
+	// EventPublisher publishes the events once they're created
+	publisher.EventPublisher
+
 	CreateEvent(ctx context.Context, event ...*models.Event) ([]*models.Event, error)
 	ListEvents(ctx context.Context, query *q.Query) ([]*models.Event, error)
+	// ListEventsByResource lists the latest events of the resource before the cursor, the newest first.
+	// The cursor is the id of the last event listed, 0 to list from the newest, and next is 0 if no more events.
+	ListEventsByResource(ctx context.Context, resourceType string, resourceID uint,
+		cursor uint, limit int) (events []*models.Event, next uint, err error)
 	ListEventsByRange(ctx context.Context, start, end uint) ([]*models.Event, error)
+	// ListEventsByTimeRange lists events created in [start, end)
+	ListEventsByTimeRange(ctx context.Context, start, end time.Time) ([]*models.Event, error)
 	CreateOrUpdateCursor(ctx context.Context,
 		eventIndex *models.EventCursor) (*models.EventCursor, error)
 	GetCursor(ctx context.Context) (*models.EventCursor, error)
@@ -42,12 +54,14 @@ type Manager interface {
 }
 
 type manager struct {
+	publisher.EventPublisher
 	dao dao.DAO
 }
 
 func New(db *gorm.DB) Manager {
 	return &manager{
-		dao: dao.NewDAO(db),
+		EventPublisher: publisher.New(),
+		dao:            dao.NewDAO(db),
 	}
 }
 
@@ -69,6 +83,10 @@ func (m *manager) CreateEvent(ctx context.Context,
 	if err != nil {
 		return nil, herrors.NewErrCreateFailed(herrors.EventInDB, err.Error())
 	}
+	// the events rolled back with the request are never published
+	orm.AfterCommit(ctx, func() {
+		m.Publish(ctx, e...)
+	})
 
 	return e, nil
 }
@@ -98,6 +116,22 @@ func (m *manager) ListEvents(ctx context.Context, query *q.Query) ([]*models.Eve
 	return m.dao.List(ctx, query)
 }
 
+func (m *manager) ListEventsByResource(ctx context.Context, resourceType string, resourceID uint,
+	cursor uint, limit int) ([]*models.Event, uint, error) {
+	const op = "event manager: list events by resource"
+	defer wlog.Start(ctx, op).StopPrint()
+	// one more event is listed to tell whether there are more
+	events, err := m.dao.ListByResource(ctx, resourceType, resourceID, cursor, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(events) <= limit {
+		return events, 0, nil
+	}
+	events = events[:limit]
+	return events, events[limit-1].ID, nil
+}
+
 func (m *manager) ListEventsByRange(ctx context.Context, start, end uint) ([]*models.Event, error) {
 	const op = "event manager: list events by range"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -109,6 +143,17 @@ func (m *manager) ListEventsByRange(ctx context.Context, start, end uint) ([]*mo
 	})
 }
 
+func (m *manager) ListEventsByTimeRange(ctx context.Context, start, end time.Time) ([]*models.Event, error) {
+	const op = "event manager: list events by time range"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx, &q.Query{
+		Keywords: q.KeyWords{
+			common.StartTime: start,
+			common.EndTime:   end,
+		},
+	})
+}
+
 func (m *manager) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
 	const op = "event manager: get event"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -126,13 +171,17 @@ var supportedEvents = map[string]string{
 	models.ApplicationDeleted:     "Application has been deleted",
 	models.ApplicationTransfered:  "Application has been transferred to another group",
 	models.ApplicationUpdated:     "Application has been updated",
+	models.ApplicationRestored:    "Deleted application has been restored",
 	models.ClusterCreated:         "New cluster has been created",
 	models.ClusterDeleted:         "Cluster has been deleted",
+	models.ClusterRestored:        "Deleted cluster has been restored",
 	models.ClusterUpdated:         "Cluster has been updated",
 	models.ClusterBuildDeployed:   "Cluster has completed a build task and triggered a deploy task",
 	models.ClusterDeployed:        "Cluster has triggered a deploying task",
 	models.ClusterRollbacked:      "Cluster has triggered a rollback task",
 	models.ClusterFreed:           "Cluster has been freed",
+	models.ClusterAutoFreeWarned:  "Cluster is going to be freed automatically",
+	models.GroupTransferred:       "Group has been transferred to another parent group",
 	models.ClusterRestarted:       "Cluster has been restarted",
 	models.ClusterAction:          "Cluster has triggered an action",
 	models.ClusterPodsRescheduled: "Pods has been deleted to reschedule",
@@ -142,6 +191,15 @@ var supportedEvents = map[string]string{
 	models.MemberDeleted:          "Member has been deleted",
 	models.PipelinerunCreated:     "New pipelinerun has been created",
 	models.PipelinerunCancelled:   "Pipelinerun has been cancelled",
+	models.PipelinerunFinished:    "Pipelinerun has finished running",
+	models.PipelinerunAwaiting:    "Pipelinerun is awaiting deploy approval",
+	models.PipelinerunApproved:    "Deploy of pipelinerun has been approved",
+	models.PipelinerunRejected:    "Deploy of pipelinerun has been rejected",
+	models.PipelinerunRetried:     "Pipelinerun has been retried",
+	models.TokenRevoked:           "Access token has been revoked",
+	models.RobotCreated:           "New robot has been created",
+	models.RobotDeleted:           "Robot has been deleted",
+	models.GroupQuotaWarned:       "Group has used most of its quota",
 }
 
 func (m *manager) ListSupportEvents() map[string]string {
@@ -17,12 +17,14 @@ package manager
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/event/publisher"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
 	"github.com/stretchr/testify/assert"
 )
@@ -91,6 +93,14 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(events))
 
+	now := time.Now()
+	events, err = m.ListEventsByTimeRange(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(events))
+	events, err = m.ListEventsByTimeRange(ctx, now.Add(time.Hour), now.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+
 	ec, err := m.CreateOrUpdateCursor(ctx, &eventmodels.EventCursor{
 		Position: 1,
 	})
@@ -111,3 +121,57 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(events))
 }
+
+func TestListEventsByResource(t *testing.T) {
+	createCtx()
+	published := make(chan *eventmodels.Event, 10)
+	assert.Nil(t, m.Subscribe("test", publisher.SubscriberFunc(func(ctx context.Context,
+		events []*eventmodels.Event) error {
+		for _, e := range events {
+			published <- e
+		}
+		return nil
+	})))
+
+	var ids []uint
+	for i := 0; i < 5; i++ {
+		events, err := m.CreateEvent(ctx, &eventmodels.Event{
+			EventSummary: eventmodels.EventSummary{
+				ResourceType: common.ResourceApplication,
+				ResourceID:   100,
+				EventType:    eventmodels.ApplicationUpdated,
+			},
+		}, &eventmodels.Event{
+			EventSummary: eventmodels.EventSummary{
+				ResourceType: common.ResourceCluster,
+				ResourceID:   100,
+				EventType:    eventmodels.ClusterUpdated,
+			},
+		})
+		assert.Nil(t, err)
+		ids = append(ids, events[0].ID)
+	}
+	for i := 0; i < 10; i++ {
+		select {
+		case e := <-published:
+			assert.NotZero(t, e.ID)
+		case <-time.After(time.Second):
+			t.Fatal("events are not published")
+		}
+	}
+
+	events, next, err := m.ListEventsByResource(ctx, common.ResourceApplication, 100, 0, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{ids[4], ids[3]}, []uint{events[0].ID, events[1].ID})
+	assert.Equal(t, ids[3], next)
+
+	events, next, err = m.ListEventsByResource(ctx, common.ResourceApplication, 100, next, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{ids[2], ids[1]}, []uint{events[0].ID, events[1].ID})
+
+	events, next, err = m.ListEventsByResource(ctx, common.ResourceApplication, 100, next, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, ids[0], events[0].ID)
+	assert.Equal(t, uint(0), next)
+}
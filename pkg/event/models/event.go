@@ -27,8 +27,10 @@ const (
 	ApplicationDeleted     string = "applications_deleted"
 	ApplicationUpdated     string = "applications_updated"
 	ApplicationTransfered  string = "applications_transferred"
+	ApplicationRestored    string = "applications_restored"
 	ClusterCreated         string = "clusters_created"
 	ClusterDeleted         string = "clusters_deleted"
+	ClusterRestored        string = "clusters_restored"
 	ClusterBuildDeployed   string = "clusters_builddeployed"
 	ClusterDeployed        string = "clusters_deployed"
 	ClusterRollbacked      string = "clusters_rollbacked"
@@ -36,6 +38,8 @@ const (
 	ClusterPodsRescheduled string = "clusters_rescheduled"
 	ClusterUpdated         string = "clusters_updated"
 	ClusterFreed           string = "clusters_freed"
+	ClusterAutoFreeWarned  string = "clusters_autofreewarned"
+	GroupTransferred       string = "groups_transferred"
 	ClusterKubernetesEvent string = "clusters_kubernetes_event"
 	ClusterAction                 = "clusters_action"
 	MemberCreated          string = "members_created"
@@ -43,9 +47,46 @@ const (
 	MemberDeleted          string = "members_deleted"
 	PipelinerunCreated     string = "pipelineruns_created"
 	PipelinerunCancelled   string = "pipelineruns_cancelled"
+	PipelinerunFinished    string = "pipelineruns_finished"
+	PipelinerunAwaiting    string = "pipelineruns_awaitingapproval"
+	PipelinerunApproved    string = "pipelineruns_approved"
+	PipelinerunRejected    string = "pipelineruns_rejected"
+	PipelinerunRetried     string = "pipelineruns_retried"
+	TokenRevoked           string = "accesstokens_revoked"
+	RobotCreated           string = "robots_created"
+	RobotDeleted           string = "robots_deleted"
+	GroupQuotaWarned       string = "groups_quotawarned"
 	// TODO: add group events
 )
 
+// AutoFreeWarning is the extra of ClusterAutoFreeWarned events
+type AutoFreeWarning struct {
+	FreeAt time.Time `json:"freeAt"`
+}
+
+// PipelinerunRetry is the extra of PipelinerunRetried events
+type PipelinerunRetry struct {
+	RetryOf uint `json:"retryOf"`
+	// Task and Step are the step which the pipelinerun reruns from, empty if it reruns all the steps
+	Task string `json:"task,omitempty"`
+	Step string `json:"step,omitempty"`
+}
+
+// QuotaWarning is the extra of GroupQuotaWarned events
+type QuotaWarning struct {
+	Resource  string `json:"resource"`
+	Used      int64  `json:"used"`
+	HardLimit uint   `json:"hardLimit"`
+	// Threshold is the soft threshold in percentage reached
+	Threshold int `json:"threshold"`
+}
+
+// GroupTransfer is the extra of GroupTransferred events
+type GroupTransfer struct {
+	FromParentID uint `json:"fromParentID"`
+	ToParentID   uint `json:"toParentID"`
+}
+
 type EventSummary struct {
 	ResourceType string
 	ResourceID   uint
@@ -0,0 +1,143 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"context"
+	"sync"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	utilcommon "github.com/horizoncd/horizon/pkg/util/common"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// _queueSize is the number of batches buffered for each subscriber,
+// batches are dropped once it's full, and left to the subscribers polling the event table
+const _queueSize = 1024
+
+// Subscriber handles the events published
+type Subscriber interface {
+	Handle(ctx context.Context, events []*models.Event) error
+}
+
+// SubscriberFunc adapts a func to Subscriber
+type SubscriberFunc func(ctx context.Context, events []*models.Event) error
+
+func (f SubscriberFunc) Handle(ctx context.Context, events []*models.Event) error {
+	return f(ctx, events)
+}
+
+// EventPublisher delivers the events recorded to the subscribers,
+// every subscriber receives the events in the order published, asynchronously
+type EventPublisher interface {
+	Subscribe(name string, subscriber Subscriber) error
+	Publish(ctx context.Context, events ...*models.Event)
+}
+
+type subscription struct {
+	name       string
+	subscriber Subscriber
+	queue      chan *batch
+}
+
+type batch struct {
+	ctx    context.Context
+	events []*models.Event
+}
+
+type publisher struct {
+	sync.RWMutex
+	subscriptions map[string]*subscription
+}
+
+func New() EventPublisher {
+	return &publisher{
+		subscriptions: map[string]*subscription{},
+	}
+}
+
+func (p *publisher) Subscribe(name string, subscriber Subscriber) error {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.subscriptions[name]; ok {
+		return perror.Wrapf(herrors.ErrEventHandlerAlreadyExist, "subscriber %s already exist", name)
+	}
+	s := &subscription{
+		name:       name,
+		subscriber: subscriber,
+		queue:      make(chan *batch, _queueSize),
+	}
+	p.subscriptions[name] = s
+	go s.run()
+	return nil
+}
+
+func (p *publisher) Publish(ctx context.Context, events ...*models.Event) {
+	if len(events) == 0 {
+		return
+	}
+	p.RLock()
+	defer p.RUnlock()
+	if len(p.subscriptions) == 0 {
+		return
+	}
+	// the request may be done before the events are handled
+	b := &batch{ctx: detach(ctx), events: events}
+	for _, s := range p.subscriptions {
+		select {
+		case s.queue <- b:
+		default:
+			log.Warningf(ctx, "queue of subscriber %s is full, %d events are dropped", s.name, len(events))
+		}
+	}
+}
+
+func (s *subscription) run() {
+	for b := range s.queue {
+		s.handle(b)
+	}
+}
+
+func (s *subscription) handle(b *batch) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf(b.ctx, "subscriber %s panic: %v", s.name, err)
+			utilcommon.PrintStack()
+		}
+	}()
+	if err := s.subscriber.Handle(b.ctx, b.events); err != nil {
+		log.Warningf(b.ctx, "subscriber %s failed to handle events: %v", s.name, err)
+	}
+}
+
+// detach copies the values the subscribers need into a fresh context. The context of a request is done
+// once the request finishes, and gin reuses it for other requests, so it must not be kept by subscribers.
+func detach(ctx context.Context) context.Context {
+	detached := context.Background()
+	if user, err := common.UserFromContext(ctx); err == nil {
+		detached = common.WithContext(detached, user)
+	}
+	if rid, err := requestid.FromContext(ctx); err == nil {
+		detached = context.WithValue(detached, requestid.HeaderXRequestID, rid) // nolint
+	}
+	if traceID, ok := ctx.Value(log.Key()).(string); ok {
+		detached = log.WithContext(detached, traceID)
+	}
+	return detached
+}
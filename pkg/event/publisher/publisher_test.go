@@ -0,0 +1,89 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/event/models"
+)
+
+func TestPublisher(t *testing.T) {
+	p := New()
+	// nothing happens without subscribers
+	p.Publish(context.Background(), &models.Event{ID: 1})
+
+	received := make(chan uint, 10)
+	assert.Nil(t, p.Subscribe("webhook", SubscriberFunc(func(ctx context.Context, events []*models.Event) error {
+		for _, e := range events {
+			received <- e.ID
+		}
+		return nil
+	})))
+	assert.NotNil(t, p.Subscribe("webhook", SubscriberFunc(func(ctx context.Context,
+		events []*models.Event) error {
+		return nil
+	})))
+	// failures and panics of a subscriber never affect others
+	assert.Nil(t, p.Subscribe("failed", SubscriberFunc(func(ctx context.Context, events []*models.Event) error {
+		return errors.New("failed")
+	})))
+	assert.Nil(t, p.Subscribe("panic", SubscriberFunc(func(ctx context.Context, events []*models.Event) error {
+		panic("panic")
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Publish(ctx, &models.Event{ID: 2}, &models.Event{ID: 3})
+	cancel()
+	p.Publish(ctx, &models.Event{ID: 4})
+
+	for _, id := range []uint{2, 3, 4} {
+		select {
+		case got := <-received:
+			assert.Equal(t, id, got)
+		case <-time.After(time.Second):
+			t.Fatalf("event %d is not received", id)
+		}
+	}
+}
+
+func TestDetach(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{ID: 1, Name: "tony"})
+	ctx = context.WithValue(ctx, requestid.HeaderXRequestID, "rid") // nolint
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	detached := detach(ctx)
+	assert.Nil(t, detached.Err())
+	assert.Nil(t, detached.Done())
+	user, err := common.UserFromContext(detached)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), user.GetID())
+	rid, err := requestid.FromContext(detached)
+	assert.Nil(t, err)
+	assert.Equal(t, "rid", rid)
+	// other values of the request, such as its transaction, are never forwarded
+	assert.Nil(t, detached.Value(key{}))
+}
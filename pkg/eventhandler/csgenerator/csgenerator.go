@@ -0,0 +1,125 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csgenerator
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	csmanager "github.com/horizoncd/horizon/pkg/clustersummary/manager"
+	csmodels "github.com/horizoncd/horizon/pkg/clustersummary/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// deployActions are the actions whose pipelinerun is regarded as a deployment of the cluster
+var deployActions = []string{prmodels.ActionBuildDeploy, prmodels.ActionDeploy, prmodels.ActionRollback}
+
+// ClusterSummaryGenerator keeps cluster summaries up to date by events
+type ClusterSummaryGenerator struct {
+	clusterMgr        clustermanager.Manager
+	clusterSummaryMgr csmanager.Manager
+	prMgr             *prmanager.PRManager
+}
+
+func NewClusterSummaryGenerator(manager *managerparam.Manager) *ClusterSummaryGenerator {
+	return &ClusterSummaryGenerator{
+		clusterMgr:        manager.ClusterMgr,
+		clusterSummaryMgr: manager.ClusterSummaryMgr,
+		prMgr:             manager.PRMgr,
+	}
+}
+
+// Process refreshes summaries of the clusters which the events are associated with.
+// Summaries are always rebuilt from db, so processing the same events again on resume is harmless.
+func (g *ClusterSummaryGenerator) Process(ctx context.Context, events []*models.Event, resume bool) error {
+	clusterIDs := make([]uint, 0, len(events))
+	seen := make(map[uint]bool, len(events))
+	for _, event := range events {
+		clusterID, ok := g.clusterIDOfEvent(ctx, event)
+		if !ok || seen[clusterID] {
+			continue
+		}
+		seen[clusterID] = true
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+
+	for _, clusterID := range clusterIDs {
+		if err := g.refresh(ctx, clusterID); err != nil {
+			log.Errorf(ctx, "failed to refresh summary of cluster %d, error: %+v", clusterID, err)
+		}
+	}
+	return nil
+}
+
+func (g *ClusterSummaryGenerator) clusterIDOfEvent(ctx context.Context, event *models.Event) (uint, bool) {
+	switch event.ResourceType {
+	case common.ResourceCluster:
+		if event.EventType == models.ClusterKubernetesEvent {
+			return 0, false
+		}
+		return event.ResourceID, true
+	case common.ResourcePipelinerun:
+		pr, err := g.prMgr.PipelineRun.GetByID(ctx, event.ResourceID)
+		if err != nil {
+			log.Warningf(ctx, "pipelinerun %d is not exist", event.ResourceID)
+			return 0, false
+		}
+		return pr.ClusterID, true
+	default:
+		return 0, false
+	}
+}
+
+func (g *ClusterSummaryGenerator) refresh(ctx context.Context, clusterID uint) error {
+	cluster, err := g.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return g.clusterSummaryMgr.DeleteByClusterID(ctx, clusterID)
+		}
+		return err
+	}
+
+	summary := &csmodels.ClusterSummary{
+		ClusterID:       cluster.ID,
+		ApplicationID:   cluster.ApplicationID,
+		EnvironmentName: cluster.EnvironmentName,
+		RegionName:      cluster.RegionName,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+	}
+
+	pr, err := g.prMgr.PipelineRun.GetLatestByClusterIDAndActions(ctx, clusterID, deployActions...)
+	if err != nil {
+		return err
+	}
+	if pr != nil {
+		summary.PipelinerunID = pr.ID
+		summary.PipelinerunAction = pr.Action
+		summary.PipelinerunStatus = pr.Status
+		summary.DeployedAt = pr.FinishedAt
+		if summary.DeployedAt == nil {
+			createdAt := pr.CreatedAt
+			summary.DeployedAt = &createdAt
+		}
+	}
+	return g.clusterSummaryMgr.Upsert(ctx, summary)
+}
@@ -35,6 +35,8 @@ import (
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
 	"github.com/horizoncd/horizon/pkg/event/models"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	metadatamanager "github.com/horizoncd/horizon/pkg/metadata/manager"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -59,6 +61,7 @@ type MessageContent struct {
 	Cluster     *ClusterInfo          `json:"cluster,omitempty"`
 	Pipelinerun *PipelinerunInfo      `json:"pipelinerun,omitempty"`
 	Member      *MemberInfo           `json:"member,omitempty"`
+	Group       *GroupInfo            `json:"group,omitempty"`
 	EventType   string                `json:"eventType,omitempty"`
 	User        *usermodels.UserBasic `json:"user,omitempty"`
 	Extra       *string               `json:"extra,omitempty"`
@@ -72,14 +75,16 @@ type ResourceCommonInfo struct {
 // ApplicationInfo contains basic info of application
 type ApplicationInfo struct {
 	ResourceCommonInfo
-	Priority string `json:"priority,omitempty"`
+	Priority string            `json:"priority,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // ClusterInfo contains basic info of cluster
 type ClusterInfo struct {
 	ResourceCommonInfo
-	ApplicationName string `json:"applicationName,omitempty"`
-	Env             string `json:"env,omitempty"`
+	ApplicationName string            `json:"applicationName,omitempty"`
+	Env             string            `json:"env,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
 }
 
 // PipelinerunInfo contains basic info of pipelinerun
@@ -104,6 +109,12 @@ type MemberInfo struct {
 	MemberName   string                    `json:"memberName"`
 }
 
+// GroupInfo contains basic info of group
+type GroupInfo struct {
+	ResourceCommonInfo
+	Path string `json:"path,omitempty"`
+}
+
 // WebhookLogGenerator generates webhook logs by events
 type WebhookLogGenerator struct {
 	webhookMgr     webhookmanager.Manager
@@ -114,6 +125,7 @@ type WebhookLogGenerator struct {
 	prMgr          *prmanager.PRManager
 	memberMgr      membermanager.Manager
 	userMgr        usermanager.Manager
+	metadataMgr    metadatamanager.Manager
 }
 
 func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator {
@@ -126,6 +138,7 @@ func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator
 		prMgr:          manager.PRMgr,
 		memberMgr:      manager.MemberMgr,
 		userMgr:        manager.UserMgr,
+		metadataMgr:    manager.MetadataMgr,
 	}
 }
 
@@ -137,6 +150,7 @@ type messageDependency struct {
 	pipelinerun *prmodels.Pipelinerun
 	member      *membermodels.Member
 	userBasic   *usermodels.UserBasic
+	group       *groupmodels.Group
 }
 
 // listSystemResources lists root group(0) as system resource
@@ -146,6 +160,20 @@ func (w *WebhookLogGenerator) listSystemResources() map[string][]uint {
 	}
 }
 
+// listAssociatedResourcesOfGroup gets group by id and list the group and its parents
+func (w *WebhookLogGenerator) listAssociatedResourcesOfGroup(ctx context.Context,
+	id uint) (*groupmodels.Group, map[string][]uint) {
+	resources := w.listSystemResources()
+	group, err := w.groupMgr.GetByID(ctx, id)
+	if err != nil {
+		log.Warningf(ctx, "group %d is not exist", id)
+		return nil, resources
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	resources[common.ResourceGroup] = append(resources[common.ResourceGroup], groupIDs...)
+	return group, resources
+}
+
 // listAssociatedResourcesOfApp get application by id and list all the parent resources
 func (w *WebhookLogGenerator) listAssociatedResourcesOfApp(ctx context.Context,
 	id uint) (*applicationmodels.Application, map[string][]uint) {
@@ -237,6 +265,7 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 		pr          *prmodels.Pipelinerun
 		member      *membermodels.Member
 		userBasic   *usermodels.UserBasic
+		group       *groupmodels.Group
 		dep         = &messageDependency{}
 	)
 
@@ -257,6 +286,9 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 		member, userBasic, resources = w.listAssociatedResourcesOfMember(ctx, e.ResourceID)
 		dep.member = member
 		dep.userBasic = userBasic
+	case common.ResourceGroup:
+		group, resources = w.listAssociatedResourcesOfGroup(ctx, e.ResourceID)
+		dep.group = group
 	default:
 		log.Infof(ctx, "resource type %s is unsupported",
 			e.ResourceType)
@@ -301,6 +333,7 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 				Name: dep.application.Name,
 			},
 			Priority: string(dep.application.Priority),
+			Metadata: w.listMetadata(ctx, common.ResourceApplication, dep.application.ID),
 		}
 	}
 
@@ -313,6 +346,7 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 			},
 			ApplicationName: dep.application.Name,
 			Env:             dep.cluster.EnvironmentName,
+			Metadata:        w.listMetadata(ctx, common.ResourceCluster, dep.cluster.ID),
 		}
 	}
 
@@ -351,6 +385,17 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 		}
 	}
 
+	if dep.event.ResourceType == common.ResourceGroup &&
+		dep.group != nil {
+		message.Group = &GroupInfo{
+			ResourceCommonInfo: ResourceCommonInfo{
+				ID:   dep.group.ID,
+				Name: dep.group.Name,
+			},
+			Path: dep.group.Path,
+		}
+	}
+
 	reqBody, err := json.Marshal(message)
 	if err != nil {
 		log.Errorf(ctx, fmt.Sprintf("failed to marshal message, error: %+v", err))
@@ -409,6 +454,7 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 				pipelinerun: dependency.pipelinerun,
 				member:      dependency.member,
 				userBasic:   dependency.userBasic,
+				group:       dependency.group,
 			}
 			conditionsToQuery[event.ID] = append(conditionsToQuery[event.ID], webhook.ID)
 		}
@@ -467,3 +513,18 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 	}
 	return nil
 }
+
+// listMetadata returns the metadata of a resource, metadata is optional for
+// webhook messages, so failures are only logged
+func (w *WebhookLogGenerator) listMetadata(ctx context.Context, resourceType string,
+	resourceID uint) map[string]string {
+	if w.metadataMgr == nil {
+		return nil
+	}
+	metadata, err := w.metadataMgr.ListByResource(ctx, resourceType, resourceID)
+	if err != nil {
+		log.Warningf(ctx, "failed to list metadata of %s %d: %v", resourceType, resourceID, err)
+		return nil
+	}
+	return metadata
+}
@@ -0,0 +1,227 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitopscache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/xanzy/go-gitlab"
+
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_keyFile          = "file"
+	_keyFileAndCommit = "filecommit"
+)
+
+var _cacheCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "horizon",
+	Subsystem: "gitops_cache",
+	Name:      "lookups_total",
+	Help:      "Lookups of files cached from gitops repos",
+}, []string{"result"})
+
+func observe(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	_cacheCounter.WithLabelValues(result).Inc()
+}
+
+type fileAndCommit struct {
+	Content []byte `json:"content"`
+	Commit  string `json:"commit"`
+}
+
+// cachedGitlab caches the files read from projects by their paths, the ones read by ids are not cached.
+// The projects written by it are invalidated, even if the writes fail as they may be done before timeout,
+// and the others are invalidated by webhooks.
+type cachedGitlab struct {
+	gitlablib.Interface
+	store Store
+}
+
+// NewGitlab wraps the gitlab of gitops repos with the cache in store
+func NewGitlab(lib gitlablib.Interface, store Store) gitlablib.Interface {
+	return &cachedGitlab{
+		Interface: lib,
+		store:     store,
+	}
+}
+
+func fileKey(kind, ref, filepath string) string {
+	return fmt.Sprintf("%s:%s:%s", kind, ref, filepath)
+}
+
+func (g *cachedGitlab) GetFile(ctx context.Context, pid interface{}, ref, filepath string) ([]byte, error) {
+	repo, ok := pid.(string)
+	if !ok {
+		return g.Interface.GetFile(ctx, pid, ref, filepath)
+	}
+	key := fileKey(_keyFile, ref, filepath)
+	content, generation, ok := g.store.Get(ctx, repo, key)
+	observe(ok)
+	if ok {
+		return content, nil
+	}
+	content, err := g.Interface.GetFile(ctx, pid, ref, filepath)
+	if err != nil {
+		return nil, err
+	}
+	g.store.Set(ctx, repo, key, content, generation)
+	return content, nil
+}
+
+func (g *cachedGitlab) GetFileAndCommit(ctx context.Context, pid interface{},
+	ref, filepath string) ([]byte, string, error) {
+	repo, ok := pid.(string)
+	if !ok {
+		return g.Interface.GetFileAndCommit(ctx, pid, ref, filepath)
+	}
+	key := fileKey(_keyFileAndCommit, ref, filepath)
+	value, generation, ok := g.store.Get(ctx, repo, key)
+	var file fileAndCommit
+	if ok && json.Unmarshal(value, &file) == nil {
+		observe(true)
+		return file.Content, file.Commit, nil
+	}
+	observe(false)
+	content, commit, err := g.Interface.GetFileAndCommit(ctx, pid, ref, filepath)
+	if err != nil {
+		return nil, "", err
+	}
+	if value, err := json.Marshal(&fileAndCommit{Content: content, Commit: commit}); err == nil {
+		g.store.Set(ctx, repo, key, value, generation)
+	}
+	return content, commit, nil
+}
+
+func (g *cachedGitlab) CreateProject(ctx context.Context, name string,
+	groupID int, visibility string) (*gitlab.Project, error) {
+	project, err := g.Interface.CreateProject(ctx, name, groupID, visibility)
+	if err != nil {
+		return nil, err
+	}
+	// the files of the project deleted before at the same path are dropped
+	g.store.Invalidate(ctx, project.PathWithNamespace)
+	return project, nil
+}
+
+func (g *cachedGitlab) DeleteProject(ctx context.Context, pid interface{}) error {
+	repo := g.projectPath(ctx, pid)
+	err := g.Interface.DeleteProject(ctx, pid)
+	g.invalidate(ctx, repo)
+	return err
+}
+
+func (g *cachedGitlab) DeleteGroup(ctx context.Context, gid interface{}) error {
+	group := g.groupPath(ctx, gid)
+	err := g.Interface.DeleteGroup(ctx, gid)
+	if group != "" {
+		g.store.InvalidatePrefix(ctx, group+"/")
+	}
+	return err
+}
+
+func (g *cachedGitlab) CreateBranch(ctx context.Context, pid interface{},
+	branch, fromRef string) (*gitlab.Branch, error) {
+	b, err := g.Interface.CreateBranch(ctx, pid, branch, fromRef)
+	g.invalidate(ctx, g.projectPath(ctx, pid))
+	return b, err
+}
+
+func (g *cachedGitlab) DeleteBranch(ctx context.Context, pid interface{}, branch string) error {
+	err := g.Interface.DeleteBranch(ctx, pid, branch)
+	g.invalidate(ctx, g.projectPath(ctx, pid))
+	return err
+}
+
+func (g *cachedGitlab) AcceptMR(ctx context.Context, pid interface{}, mrID int,
+	mergeCommitMsg *string, shouldRemoveSourceBranch *bool) (*gitlab.MergeRequest, error) {
+	mr, err := g.Interface.AcceptMR(ctx, pid, mrID, mergeCommitMsg, shouldRemoveSourceBranch)
+	g.invalidate(ctx, g.projectPath(ctx, pid))
+	return mr, err
+}
+
+func (g *cachedGitlab) WriteFiles(ctx context.Context, pid interface{}, branch, commitMsg string,
+	startBranch *string, actions []gitlablib.CommitAction) (*gitlab.Commit, error) {
+	commit, err := g.Interface.WriteFiles(ctx, pid, branch, commitMsg, startBranch, actions)
+	g.invalidate(ctx, g.projectPath(ctx, pid))
+	return commit, err
+}
+
+func (g *cachedGitlab) TransferProject(ctx context.Context, pid interface{}, gid interface{}) error {
+	repo := g.projectPath(ctx, pid)
+	err := g.Interface.TransferProject(ctx, pid, gid)
+	g.invalidate(ctx, repo)
+	// the files of the project deleted before at the new path are dropped
+	if group := g.groupPath(ctx, gid); group != "" && repo != "" {
+		g.store.Invalidate(ctx, group+"/"+path.Base(repo))
+	}
+	return err
+}
+
+func (g *cachedGitlab) EditNameAndPathForProject(ctx context.Context, pid interface{},
+	newName, newPath *string) error {
+	repo := g.projectPath(ctx, pid)
+	err := g.Interface.EditNameAndPathForProject(ctx, pid, newName, newPath)
+	g.invalidate(ctx, repo)
+	if newPath != nil && repo != "" {
+		g.store.Invalidate(ctx, path.Dir(repo)+"/"+*newPath)
+	}
+	return err
+}
+
+// projectPath gets the path of the project, which is looked up if it's an id,
+// it's empty if the project is not found
+func (g *cachedGitlab) projectPath(ctx context.Context, pid interface{}) string {
+	if repo, ok := pid.(string); ok {
+		return repo
+	}
+	project, err := g.Interface.GetProject(ctx, pid)
+	if err != nil {
+		log.Warningf(ctx, "failed to get project %v to invalidate its files cached: %v", pid, err)
+		return ""
+	}
+	return project.PathWithNamespace
+}
+
+// groupPath gets the full path of the group, which is looked up if it's an id,
+// it's empty if the group is not found
+func (g *cachedGitlab) groupPath(ctx context.Context, gid interface{}) string {
+	if group, ok := gid.(string); ok {
+		return group
+	}
+	group, err := g.Interface.GetGroup(ctx, gid)
+	if err != nil {
+		log.Warningf(ctx, "failed to get group %v to invalidate its files cached: %v", gid, err)
+		return ""
+	}
+	return group.FullPath
+}
+
+func (g *cachedGitlab) invalidate(ctx context.Context, repo string) {
+	if repo != "" {
+		g.store.Invalidate(ctx, repo)
+	}
+}
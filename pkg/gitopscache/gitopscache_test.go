@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitopscache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/xanzy/go-gitlab"
+
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	gitlablibmock "github.com/horizoncd/horizon/mock/lib/gitlab"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(50*time.Millisecond, 2)
+
+	_, generation, ok := s.Get(ctx, "a/b", "k")
+	assert.False(t, ok)
+	s.Set(ctx, "a/b", "k", []byte("v"), generation)
+	value, _, ok := s.Get(ctx, "a/b", "k")
+	assert.True(t, ok)
+	assert.Equal(t, "v", string(value))
+
+	// the file read before the invalidation is not cached
+	_, generation, _ = s.Get(ctx, "a/b", "k2")
+	s.Invalidate(ctx, "a/b")
+	s.Set(ctx, "a/b", "k2", []byte("v2"), generation)
+	_, _, ok = s.Get(ctx, "a/b", "k2")
+	assert.False(t, ok)
+	_, _, ok = s.Get(ctx, "a/b", "k")
+	assert.False(t, ok)
+
+	// the files expire
+	_, generation, _ = s.Get(ctx, "a/b", "k")
+	s.Set(ctx, "a/b", "k", []byte("v"), generation)
+	time.Sleep(60 * time.Millisecond)
+	_, _, ok = s.Get(ctx, "a/b", "k")
+	assert.False(t, ok)
+
+	// the repo used least recently is evicted
+	for _, repo := range []string{"a/b", "a/c", "d/e"} {
+		_, generation, _ = s.Get(ctx, repo, "k")
+		s.Set(ctx, repo, "k", []byte(repo), generation)
+	}
+	_, _, ok = s.Get(ctx, "a/c", "k")
+	assert.True(t, ok)
+	_, generation, ok = s.Get(ctx, "a/b", "k")
+	assert.False(t, ok)
+	s.Set(ctx, "a/b", "k", []byte("a/b"), generation)
+
+	// the repos under the group are invalidated
+	s.InvalidatePrefix(ctx, "a/")
+	_, _, ok = s.Get(ctx, "a/b", "k")
+	assert.False(t, ok)
+	_, _, ok = s.Get(ctx, "a/c", "k")
+	assert.False(t, ok)
+}
+
+func TestGitlab(t *testing.T) {
+	ctx := context.Background()
+	mockCtl := gomock.NewController(t)
+	lib := gitlablibmock.NewMockInterface(mockCtl)
+	g := NewGitlab(lib, NewMemoryStore(time.Minute, 10))
+
+	repo := "horizon/clusters/app/cluster"
+	lib.EXPECT().GetFile(ctx, repo, "gitops", "application.yaml").Return([]byte("v1"), nil).Times(1)
+	lib.EXPECT().GetFileAndCommit(ctx, repo, "gitops", "application.yaml").
+		Return([]byte("v1"), "c1", nil).Times(1)
+	for i := 0; i < 2; i++ {
+		content, err := g.GetFile(ctx, repo, "gitops", "application.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, "v1", string(content))
+		content, commit, err := g.GetFileAndCommit(ctx, repo, "gitops", "application.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, "v1", string(content))
+		assert.Equal(t, "c1", commit)
+	}
+
+	// files of projects read by ids are not cached
+	lib.EXPECT().GetFile(ctx, 1, "gitops", "application.yaml").Return([]byte("v1"), nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := g.GetFile(ctx, 1, "gitops", "application.yaml")
+		assert.Nil(t, err)
+	}
+
+	// the project written is invalidated
+	lib.EXPECT().WriteFiles(ctx, repo, "gitops", "update", nil, nil).Return(&gitlab.Commit{ID: "c2"}, nil)
+	_, err := g.WriteFiles(ctx, repo, "gitops", "update", nil, []gitlablib.CommitAction(nil))
+	assert.Nil(t, err)
+	lib.EXPECT().GetFile(ctx, repo, "gitops", "application.yaml").Return([]byte("v2"), nil).Times(1)
+	for i := 0; i < 2; i++ {
+		content, err := g.GetFile(ctx, repo, "gitops", "application.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, "v2", string(content))
+	}
+
+	// the project at the new path is invalidated
+	newRepo := "horizon/recycling-clusters/app/cluster"
+	lib.EXPECT().GetFile(ctx, newRepo, "gitops", "application.yaml").Return([]byte("old"), nil).Times(1)
+	_, err = g.GetFile(ctx, newRepo, "gitops", "application.yaml")
+	assert.Nil(t, err)
+	lib.EXPECT().TransferProject(ctx, repo, "horizon/recycling-clusters/app").Return(nil)
+	assert.Nil(t, g.TransferProject(ctx, repo, "horizon/recycling-clusters/app"))
+	lib.EXPECT().GetFile(ctx, newRepo, "gitops", "application.yaml").Return([]byte("v2"), nil).Times(1)
+	content, err := g.GetFile(ctx, newRepo, "gitops", "application.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", string(content))
+
+	// the projects under the group deleted are invalidated
+	lib.EXPECT().GetGroup(ctx, 10).Return(&gitlab.Group{FullPath: "horizon/recycling-clusters/app"}, nil)
+	lib.EXPECT().DeleteGroup(ctx, 10).Return(nil)
+	assert.Nil(t, g.DeleteGroup(ctx, 10))
+	lib.EXPECT().GetFile(ctx, newRepo, "gitops", "application.yaml").Return(nil, assert.AnError)
+	_, err = g.GetFile(ctx, newRepo, "gitops", "application.yaml")
+	assert.Equal(t, assert.AnError, err)
+}
@@ -0,0 +1,162 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitopscache
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	// _redisTimeout bounds the time a request waits on redis, files are read from gitlab if redis is slow or down
+	_redisTimeout = 100 * time.Millisecond
+	// _generationField is the field of the generation in the hash of each repo, the files are in the other fields
+	_generationField = "_generation"
+	_filePrefix      = "f:"
+	_scanCount       = 100
+)
+
+// _invalidateScript drops the files in the hash of KEYS[1], and advances its generation
+var _invalidateScript = redis.NewScript(`
+local generation = redis.call('HINCRBY', KEYS[1], '_generation', 1)
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], '_generation', generation)
+redis.call('EXPIRE', KEYS[1], ARGV[1])
+return generation
+`)
+
+// _setScript sets the file ARGV[2] in the hash of KEYS[1] if its generation is still ARGV[1]
+var _setScript = redis.NewScript(`
+local generation = tonumber(redis.call('HGET', KEYS[1], '_generation') or '0')
+if generation ~= tonumber(ARGV[1]) then
+  return 0
+end
+redis.call('HSET', KEYS[1], ARGV[2], ARGV[3])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+// RedisStore keeps the files of each repo in a hash of redis, so that the servers share them.
+// The expiration of each file is stored before its content, as the hash expires after the last file set.
+type RedisStore struct {
+	client *redis.Client
+	// prefix of the keys in redis
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedisStore(client *redis.Client, prefix string, ttl time.Duration) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+func (s *RedisStore) Get(ctx context.Context, repo, key string) ([]byte, int64, bool) {
+	ctx, cancel := context.WithTimeout(ctx, _redisTimeout)
+	defer cancel()
+	values, err := s.client.HMGet(ctx, s.prefix+repo, _filePrefix+key, _generationField).Result()
+	if err != nil || len(values) != 2 {
+		log.Warningf(ctx, "failed to get file %s of %s from redis: %v", key, repo, err)
+		// the file read is not cached, as no generation matches it
+		return nil, -1, false
+	}
+	generation := int64(0)
+	if value, ok := values[1].(string); ok {
+		generation, _ = strconv.ParseInt(value, 10, 64)
+	}
+	value, ok := values[0].(string)
+	if !ok {
+		return nil, generation, false
+	}
+	index := strings.IndexByte(value, '\n')
+	if index < 0 {
+		return nil, generation, false
+	}
+	expireAt, err := strconv.ParseInt(value[:index], 10, 64)
+	if err != nil || time.Now().Unix() >= expireAt {
+		return nil, generation, false
+	}
+	return []byte(value[index+1:]), generation, true
+}
+
+func (s *RedisStore) Set(ctx context.Context, repo, key string, value []byte, generation int64) {
+	if generation < 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, _redisTimeout)
+	defer cancel()
+	var buf bytes.Buffer
+	buf.WriteString(strconv.FormatInt(time.Now().Add(s.ttl).Unix(), 10))
+	buf.WriteByte('\n')
+	buf.Write(value)
+	if err := _setScript.Run(ctx, s.client, []string{s.prefix + repo}, generation, _filePrefix+key,
+		buf.String(), int64(s.ttl.Seconds())+1).Err(); err != nil {
+		log.Warningf(ctx, "failed to set file %s of %s to redis: %v", key, repo, err)
+	}
+}
+
+func (s *RedisStore) Invalidate(ctx context.Context, repo string) {
+	s.invalidate(ctx, s.prefix+repo)
+}
+
+func (s *RedisStore) InvalidatePrefix(ctx context.Context, prefix string) {
+	var cursor uint64
+	for {
+		scanCtx, cancel := context.WithTimeout(ctx, _redisTimeout)
+		keys, next, err := s.client.Scan(scanCtx, cursor, escapePattern(s.prefix+prefix)+"*", _scanCount).Result()
+		cancel()
+		if err != nil {
+			log.Errorf(ctx, "failed to scan repos with prefix %s in redis: %v", prefix, err)
+			return
+		}
+		for _, key := range keys {
+			s.invalidate(ctx, key)
+		}
+		if next == 0 {
+			return
+		}
+		cursor = next
+	}
+}
+
+func (s *RedisStore) invalidate(ctx context.Context, key string) {
+	ctx, cancel := context.WithTimeout(ctx, _redisTimeout)
+	defer cancel()
+	if err := _invalidateScript.Run(ctx, s.client, []string{key}, int64(s.ttl.Seconds())+1).Err(); err != nil {
+		// the files are stale until they expire
+		log.Errorf(ctx, "failed to invalidate %s in redis: %v", key, err)
+	}
+}
+
+// escapePattern escapes the special characters of the glob-style pattern of redis
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune(`*?[]\^`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
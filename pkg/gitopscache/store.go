@@ -0,0 +1,140 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitopscache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store keeps the files read from gitops repos, which are grouped by repos to be invalidated together.
+// Each invalidation advances the generation of the repo, and files read before it are not cached any more,
+// so that a read racing with a write never caches the content before the write.
+type Store interface {
+	// Get gets the file cached in the repo, and the current generation of the repo which is passed to Set
+	Get(ctx context.Context, repo, key string) (value []byte, generation int64, ok bool)
+	// Set caches the file unless the repo has been invalidated since the generation
+	Set(ctx context.Context, repo, key string, value []byte, generation int64)
+	// Invalidate drops the files of the repo
+	Invalidate(ctx context.Context, repo string)
+	// InvalidatePrefix drops the files of the repos with the prefix, such as the ones under a group
+	InvalidatePrefix(ctx context.Context, prefix string)
+}
+
+type memoryFile struct {
+	value    []byte
+	expireAt time.Time
+}
+
+type memoryRepo struct {
+	repo       string
+	generation int64
+	files      map[string]*memoryFile
+}
+
+// MemoryStore keeps the files of the repos used recently in memory, which are not shared by the servers
+type MemoryStore struct {
+	ttl  time.Duration
+	size int
+
+	mu sync.Mutex
+	// lru is the list of *memoryRepo, the front is the one used most recently
+	lru   *list.List
+	repos map[string]*list.Element
+	// generation is the last generation of all repos, so that a repo evicted and used again
+	// never reuses the generations before
+	generation int64
+}
+
+func NewMemoryStore(ttl time.Duration, size int) *MemoryStore {
+	return &MemoryStore{
+		ttl:   ttl,
+		size:  size,
+		lru:   list.New(),
+		repos: make(map[string]*list.Element),
+	}
+}
+
+// repo gets the repo and marks it used, the one used least recently is evicted if there are too many
+func (s *MemoryStore) repo(repo string) *memoryRepo {
+	if e, ok := s.repos[repo]; ok {
+		s.lru.MoveToFront(e)
+		return e.Value.(*memoryRepo)
+	}
+	s.generation++
+	r := &memoryRepo{repo: repo, generation: s.generation, files: make(map[string]*memoryFile)}
+	s.repos[repo] = s.lru.PushFront(r)
+	for s.lru.Len() > s.size {
+		e := s.lru.Back()
+		s.lru.Remove(e)
+		delete(s.repos, e.Value.(*memoryRepo).repo)
+	}
+	return r
+}
+
+func (s *MemoryStore) Get(_ context.Context, repo, key string) ([]byte, int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(repo)
+	f, ok := r.files[key]
+	if !ok {
+		return nil, r.generation, false
+	}
+	if time.Now().After(f.expireAt) {
+		delete(r.files, key)
+		return nil, r.generation, false
+	}
+	return f.value, r.generation, true
+}
+
+func (s *MemoryStore) Set(_ context.Context, repo, key string, value []byte, generation int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the repo evicted since the generation may have been invalidated, which is unknown any more
+	e, ok := s.repos[repo]
+	if !ok {
+		return
+	}
+	r := e.Value.(*memoryRepo)
+	if r.generation != generation {
+		return
+	}
+	r.files[key] = &memoryFile{value: value, expireAt: time.Now().Add(s.ttl)}
+}
+
+func (s *MemoryStore) Invalidate(_ context.Context, repo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalidate(s.repo(repo))
+}
+
+func (s *MemoryStore) InvalidatePrefix(_ context.Context, prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for repo, e := range s.repos {
+		if strings.HasPrefix(repo, prefix) {
+			s.invalidate(e.Value.(*memoryRepo))
+		}
+	}
+}
+
+func (s *MemoryStore) invalidate(r *memoryRepo) {
+	s.generation++
+	r.generation = s.generation
+	r.files = make(map[string]*memoryFile)
+}
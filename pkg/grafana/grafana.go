@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -47,20 +48,49 @@ const (
 type Service interface {
 	SyncDatasource(ctx context.Context)
 	ListDashboards(ctx context.Context) ([]*Dashboard, error)
+	// UpdateConfig applies the config reloaded, the datasource is synced at the new period from then on
+	UpdateConfig(config grafana.Config)
 }
 
 type service struct {
-	config     grafana.Config
 	kubeClient kubernetes.Interface
 	regionMgr  regionmanager.Manager
+
+	lock   sync.RWMutex
+	config grafana.Config
+	// periodChanged receives the new sync period
+	periodChanged chan time.Duration
 }
 
 func NewService(config grafana.Config, manager *managerparam.Manager, client kubernetes.Interface) Service {
 	return &service{
-		config:     config,
-		kubeClient: client,
-		regionMgr:  manager.RegionMgr,
+		config:        config,
+		kubeClient:    client,
+		regionMgr:     manager.RegionMgr,
+		periodChanged: make(chan time.Duration, 1),
+	}
+}
+
+func (s *service) getConfig() grafana.Config {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.config
+}
+
+func (s *service) UpdateConfig(config grafana.Config) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	periodChanged := config.SyncDatasourceConfig.Period != s.config.SyncDatasourceConfig.Period
+	s.config = config
+	if !periodChanged || config.SyncDatasourceConfig.Period <= 0 {
+		return
+	}
+	// only the latest period matters
+	select {
+	case <-s.periodChanged:
+	default:
 	}
+	s.periodChanged <- config.SyncDatasourceConfig.Period
 }
 
 type Content struct {
@@ -82,10 +112,11 @@ type Dashboard struct {
 }
 
 func (s *service) SyncDatasource(ctx context.Context) {
-	log.Infof(ctx, "Starting syncing grafana datasource every %v", s.config.SyncDatasourceConfig.Period)
+	period := s.getConfig().SyncDatasourceConfig.Period
+	log.Infof(ctx, "Starting syncing grafana datasource every %v", period)
 	defer log.Infof(ctx, "Stopping syncing grafana datasource")
 
-	ticker := time.NewTicker(s.config.SyncDatasourceConfig.Period)
+	ticker := time.NewTicker(period)
 	defer ticker.Stop()
 
 	for {
@@ -93,6 +124,9 @@ func (s *service) SyncDatasource(ctx context.Context) {
 		case <-ctx.Done():
 			log.Debug(ctx, "Get done signal from context")
 			return
+		case period := <-s.periodChanged:
+			log.Infof(ctx, "Syncing grafana datasource every %v from now on", period)
+			ticker.Reset(period)
 		case <-ticker.C:
 			s.sync(ctx)
 		}
@@ -112,7 +146,8 @@ func (s *service) sync(ctx context.Context) {
 		return
 	}
 
-	configMapOps := s.kubeClient.CoreV1().ConfigMaps(s.config.Namespace)
+	config := s.getConfig()
+	configMapOps := s.kubeClient.CoreV1().ConfigMaps(config.Namespace)
 	datasourceConfigMap, err := configMapOps.Get(ctx, _datasourceConfigMapName, metav1.GetOptions{})
 	if err != nil {
 		if statusError, ok := err.(*k8serrors.StatusError); !ok || statusError.ErrStatus.Code != http.StatusNotFound {
@@ -147,7 +182,7 @@ func (s *service) sync(ctx context.Context) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: _datasourceConfigMapName,
 			Labels: map[string]string{
-				s.config.SyncDatasourceConfig.LabelKey: s.config.SyncDatasourceConfig.LabelValue,
+				config.SyncDatasourceConfig.LabelKey: config.SyncDatasourceConfig.LabelValue,
 			},
 			Annotations: map[string]string{
 				_contentMD5AnnotationKey: curMD5Val,
@@ -186,12 +221,13 @@ func (s *service) sync(ctx context.Context) {
 }
 
 func (s *service) ListDashboards(ctx context.Context) ([]*Dashboard, error) {
-	configMapOps := s.kubeClient.CoreV1().ConfigMaps(s.config.Namespace)
+	config := s.getConfig()
+	configMapOps := s.kubeClient.CoreV1().ConfigMaps(config.Namespace)
 
 	dashboardConfigMapList, err := configMapOps.List(ctx,
 		metav1.ListOptions{
-			LabelSelector: fmt.Sprintf("%v=%v", s.config.Dashboards.LabelKey,
-				s.config.Dashboards.LabelValue),
+			LabelSelector: fmt.Sprintf("%v=%v", config.Dashboards.LabelKey,
+				config.Dashboards.LabelValue),
 		})
 	if err != nil {
 		if statusError, ok := err.(*k8serrors.StatusError); !ok || statusError.ErrStatus.Code != http.StatusNotFound {
@@ -16,9 +16,9 @@ package dao
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -129,7 +129,7 @@ func (d *dao) ListChildren(ctx context.Context, parentID uint, pageNumber, pageS
 func (d *dao) Transfer(ctx context.Context, id, newParentID uint) error {
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	// check records exist
@@ -137,41 +137,62 @@ func (d *dao) Transfer(ctx context.Context, id, newParentID uint) error {
 	if err != nil {
 		return err
 	}
-	pGroup, err := d.GetByID(ctx, newParentID)
-	if err != nil {
-		return err
-	}
-
-	// check name whether conflict
-	queryResult := models.Group{}
-	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryByParentIDAndName, newParentID, group.Name).First(&queryResult)
-	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
+	if group.ParentID == newParentID {
+		return nil
 	}
-	if result.RowsAffected > 0 {
-		return perror.Wrap(herrors.ErrNameConflict,
-			"group name conflict when trying to transfer to a new group")
+	oldTIDs := group.TraversalIDs
+	newTIDs := strconv.Itoa(int(group.ID))
+	if newParentID > 0 {
+		pGroup, err := d.GetByID(ctx, newParentID)
+		if err != nil {
+			return err
+		}
+		// a group can not be put under itself or its sub groups
+		if pGroup.TraversalIDs == oldTIDs || strings.HasPrefix(pGroup.TraversalIDs, oldTIDs+",") {
+			return perror.Wrap(herrors.ErrParamInvalid,
+				"a group can not be transferred under itself or its sub groups")
+		}
+		newTIDs = fmt.Sprintf("%s,%d", pGroup.TraversalIDs, group.ID)
 	}
 
-	err = d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// check name and path whether conflict
+		var conflicts []*models.Group
+		if err := tx.Raw(dbcommon.GroupQueryByNameOrPathUnderParent, newParentID,
+			group.Name, group.Path).Scan(&conflicts).Error; err != nil {
+			return herrors.NewErrGetFailed(herrors.GroupInDB, err.Error())
+		}
+		for _, conflict := range conflicts {
+			if conflict.Name == group.Name {
+				return perror.Wrap(herrors.ErrNameConflict,
+					"group name conflict when trying to transfer to a new group")
+			}
+			return perror.Wrap(herrors.ErrPathConflict,
+				"group path conflict when trying to transfer to a new group")
+		}
+
 		// change parentID
 		if err := tx.Exec(dbcommon.GroupUpdateParentID, newParentID, currentUser.GetID(), id).Error; err != nil {
 			return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
 		}
 
-		// update traversalIDs
-		oldTIDs := group.TraversalIDs
-		newTIDs := fmt.Sprintf("%s,%d", pGroup.TraversalIDs, group.ID)
-		if err := tx.Exec(dbcommon.GroupUpdateTraversalIDsPrefix, oldTIDs, newTIDs,
-			currentUser.GetID(), oldTIDs+"%").Error; err != nil {
-			return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
+		// update traversalIDs of the group and its sub groups
+		var groups []*models.Group
+		if err := tx.Raw(dbcommon.GroupQueryByTraversalIDsPrefix, oldTIDs,
+			oldTIDs+",%").Scan(&groups).Error; err != nil {
+			return herrors.NewErrGetFailed(herrors.GroupInDB, err.Error())
+		}
+		for _, g := range groups {
+			traversalIDs := newTIDs + strings.TrimPrefix(g.TraversalIDs, oldTIDs)
+			if err := tx.Exec(dbcommon.GroupUpdateTraversalIDs, traversalIDs,
+				currentUser.GetID(), g.ID).Error; err != nil {
+				return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
+			}
 		}
 
 		// commit when return nil
 		return nil
 	})
-
-	return err
 }
 
 func (d *dao) CountByParentID(ctx context.Context, parentID uint) (int64, error) {
@@ -93,6 +93,9 @@ type Manager interface {
 	IsRootGroup(ctx context.Context, groupID uint) bool
 	// GroupExist returns whether the group exists in db
 	GroupExist(ctx context.Context, groupID uint) bool
+	// GetTree returns the tree of all the groups, it's cached in memory and reloaded after groups are changed.
+	// Changes made by other instances are visible in the tree after the cache expires.
+	GetTree(ctx context.Context) (*models.GroupTree, error)
 }
 
 type manager struct {
@@ -100,6 +103,7 @@ type manager struct {
 	applicationDAO applicationdao.DAO
 	envregionDAO   envregiondao.DAO
 	regionDAO      regiondao.DAO
+	treeCache      *treeCache
 }
 
 func (m manager) GetByIDNameFuzzily(ctx context.Context, id uint, name string) ([]*models.Group, error) {
@@ -112,6 +116,7 @@ func New(db *gorm.DB) Manager {
 		applicationDAO: applicationdao.NewDAO(db),
 		envregionDAO:   envregiondao.NewDAO(db),
 		regionDAO:      regiondao.NewDAO(db),
+		treeCache:      newTreeCache(_treeCacheTTL),
 	}
 }
 
@@ -126,6 +131,7 @@ func (m manager) GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize
 }
 
 func (m manager) Transfer(ctx context.Context, id, newParentID uint) error {
+	defer m.treeCache.invalidate()
 	return m.groupDAO.Transfer(ctx, id, newParentID)
 }
 
@@ -150,6 +156,7 @@ func (m manager) GetAll(ctx context.Context) ([]*models.Group, error) {
 }
 
 func (m manager) Create(ctx context.Context, group *models.Group) (*models.Group, error) {
+	defer m.treeCache.invalidate()
 	if err := m.checkApplicationExists(ctx, group); err != nil {
 		return nil, err
 	}
@@ -163,6 +170,7 @@ func (m manager) Create(ctx context.Context, group *models.Group) (*models.Group
 }
 
 func (m manager) Delete(ctx context.Context, id uint) (int64, error) {
+	defer m.treeCache.invalidate()
 	count, err := m.groupDAO.CountByParentID(ctx, id)
 	if err != nil {
 		return 0, err
@@ -187,6 +195,7 @@ func (m manager) GetByID(ctx context.Context, id uint) (*models.Group, error) {
 }
 
 func (m manager) UpdateBasic(ctx context.Context, group *models.Group) error {
+	defer m.treeCache.invalidate()
 	if err := m.checkApplicationExists(ctx, group); err != nil {
 		return err
 	}
@@ -211,6 +220,10 @@ func (m manager) UpdateBasic(ctx context.Context, group *models.Group) error {
 	return m.groupDAO.UpdateBasic(ctx, group)
 }
 
+func (m manager) GetTree(ctx context.Context) (*models.GroupTree, error) {
+	return m.treeCache.get(ctx, m.groupDAO.GetAll)
+}
+
 func (m manager) GetSubGroupsUnderParentIDs(ctx context.Context, parentIDs []uint) ([]*models.Group, error) {
 	query := q.New(q.KeyWords{
 		_parentID: parentIDs,
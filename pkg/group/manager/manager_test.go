@@ -161,6 +161,39 @@ func TestCreate(t *testing.T) {
 	assert.Nil(t, res.Error)
 }
 
+func TestGetTree(t *testing.T) {
+	mgr := New(db)
+	g1, err := mgr.Create(ctx, getGroup(0, "1", "a"))
+	assert.Nil(t, err)
+	g2, err := mgr.Create(ctx, getGroup(g1.ID, "2", "b"))
+	assert.Nil(t, err)
+
+	tree, err := mgr.GetTree(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tree.Children[0]))
+	assert.Equal(t, g1.ID, tree.Children[0][0].ID)
+	assert.Equal(t, g2.ID, tree.Children[g1.ID][0].ID)
+	assert.Equal(t, "2", tree.Groups[g2.ID].Name)
+
+	// the tree is cached until groups are changed by the manager
+	res := db.WithContext(ctx).Create(getGroup(0, "3", "c"))
+	assert.Nil(t, res.Error)
+	tree, err = mgr.GetTree(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tree.Children[0]))
+
+	_, err = mgr.Delete(ctx, g2.ID)
+	assert.Nil(t, err)
+	tree, err = mgr.GetTree(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tree.Children[0]))
+	assert.Empty(t, tree.Children[g1.ID])
+
+	// drop table
+	res = db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
+	assert.Nil(t, res.Error)
+}
+
 func TestDelete(t *testing.T) {
 	g1, err := Mgr.Create(ctx, getGroup(0, "1", "a"))
 	assert.Nil(t, err)
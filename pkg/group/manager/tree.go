@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/group/models"
+)
+
+// _treeCacheTTL bounds how long changes of groups made by other instances are invisible in the tree
+const _treeCacheTTL = time.Minute
+
+// treeCache caches the group tree in memory, it's invalidated when groups are changed by this instance
+type treeCache struct {
+	mu       sync.Mutex
+	tree     *models.GroupTree
+	loadedAt time.Time
+	// generation is increased on invalidation, so that a tree loaded before the change is not cached
+	generation uint64
+	ttl        time.Duration
+}
+
+func newTreeCache(ttl time.Duration) *treeCache {
+	return &treeCache{ttl: ttl}
+}
+
+func (c *treeCache) get(ctx context.Context,
+	load func(ctx context.Context) ([]*models.Group, error)) (*models.GroupTree, error) {
+	c.mu.Lock()
+	if c.tree != nil && time.Since(c.loadedAt) < c.ttl {
+		tree := c.tree
+		c.mu.Unlock()
+		return tree, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	groups, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := models.NewGroupTree(groups)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		c.tree = tree
+		c.loadedAt = time.Now()
+	}
+	return tree, nil
+}
+
+func (c *treeCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tree = nil
+	c.generation++
+}
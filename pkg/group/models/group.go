@@ -15,6 +15,7 @@
 package models
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/horizoncd/horizon/pkg/server/global"
@@ -70,3 +71,28 @@ type RegionSelector struct {
 }
 
 type RegionSelectors []*RegionSelector
+
+// GroupTree indexes groups by their ids and parents, it's shared and must not be modified
+type GroupTree struct {
+	Groups map[uint]*Group
+	// Children are subgroups indexed by the parent id sorted by name, root groups are indexed by 0
+	Children map[uint][]*Group
+}
+
+// NewGroupTree builds a tree of the groups
+func NewGroupTree(groups []*Group) *GroupTree {
+	tree := &GroupTree{
+		Groups:   make(map[uint]*Group, len(groups)),
+		Children: make(map[uint][]*Group),
+	}
+	for _, group := range groups {
+		tree.Groups[group.ID] = group
+		tree.Children[group.ParentID] = append(tree.Children[group.ParentID], group)
+	}
+	for _, children := range tree.Children {
+		sort.Slice(children, func(i, j int) bool {
+			return children[i].Name < children[j].Name
+		})
+	}
+	return tree
+}
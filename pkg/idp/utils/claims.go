@@ -0,0 +1,159 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	idpconfig "github.com/horizoncd/horizon/pkg/config/idp"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	_claimSub    = "sub"
+	_claimEmail  = "email"
+	_claimName   = "name"
+	_claimGroups = "groups"
+)
+
+// MapClaims takes user's claims from raw claims of identity provider by the mapping,
+// standard claims are taken if mapping is nil
+func MapClaims(raw map[string]interface{}, mapping *idpconfig.ClaimsMapping) (*Claims, error) {
+	if mapping == nil {
+		mapping = &idpconfig.ClaimsMapping{}
+	}
+
+	var (
+		claims Claims
+		err    error
+	)
+	if claims.Sub, err = mapClaim(raw, mapping.ID, _claimSub); err != nil {
+		return nil, err
+	}
+	if claims.Email, err = mapClaim(raw, mapping.Email, _claimEmail); err != nil {
+		return nil, err
+	}
+	if claims.Name, err = mapClaim(raw, mapping.Name, _claimName); err != nil {
+		return nil, err
+	}
+	if claims.Groups, err = mapClaims(raw, mapping.Groups, _claimGroups); err != nil {
+		return nil, err
+	}
+
+	if claims.Sub == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "claim of user id is empty")
+	}
+	if claims.Email == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "claim of user email is empty")
+	}
+	return &claims, nil
+}
+
+func mapClaim(raw map[string]interface{}, rule *idpconfig.ClaimRule, defaultClaim string) (string, error) {
+	values, err := mapClaims(raw, rule, defaultClaim)
+	if err != nil || len(values) == 0 {
+		return "", err
+	}
+	return values[0], nil
+}
+
+// mapClaims takes values of the claim and transforms them by the rule
+func mapClaims(raw map[string]interface{}, rule *idpconfig.ClaimRule, defaultClaim string) ([]string, error) {
+	if rule == nil {
+		rule = &idpconfig.ClaimRule{}
+	}
+	claim := rule.Claim
+	if claim == "" {
+		claim = defaultClaim
+	}
+
+	var re *regexp.Regexp
+	if rule.Pattern != "" {
+		var err error
+		re, err = regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"invalid pattern of claim %s: %v", claim, err)
+		}
+	}
+
+	values := make([]string, 0)
+	for _, value := range claimValues(lookupClaim(raw, claim)) {
+		if re != nil {
+			value = transform(re, rule.Replacement, value)
+		}
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// lookupClaim finds the claim in raw claims, nested claims are separated by dots
+func lookupClaim(raw map[string]interface{}, claim string) interface{} {
+	if value, ok := raw[claim]; ok {
+		return value
+	}
+	var current interface{} = raw
+	for _, key := range strings.Split(claim, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if current, ok = m[key]; !ok {
+			return nil
+		}
+	}
+	return current
+}
+
+func claimValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, claimValues(item)...)
+		}
+		return values
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+// transform returns the first submatch or the whole match of the value,
+// or the expanded replacement if it's not empty
+func transform(re *regexp.Regexp, replacement, value string) string {
+	match := re.FindStringSubmatchIndex(value)
+	if match == nil {
+		return ""
+	}
+	if replacement != "" {
+		return string(re.ExpandString(nil, replacement, value, match))
+	}
+	if len(match) > 2 && match[2] >= 0 {
+		return value[match[2]:match[3]]
+	}
+	return value[match[0]:match[1]]
+}
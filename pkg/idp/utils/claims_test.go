@@ -0,0 +1,91 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	idpconfig "github.com/horizoncd/horizon/pkg/config/idp"
+)
+
+func TestMapClaims(t *testing.T) {
+	raw := map[string]interface{}{
+		"sub":   "a1b2",
+		"email": "tony@example.com",
+		"name":  "Tony",
+		"groups": []interface{}{
+			"cn=dev,ou=groups,dc=example", "cn=ops,ou=groups,dc=example", "everyone",
+		},
+		"employee": map[string]interface{}{
+			"number": float64(10086),
+			"login":  "tony",
+		},
+	}
+
+	// standard claims
+	claims, err := MapClaims(raw, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &Claims{
+		Sub:    "a1b2",
+		Name:   "Tony",
+		Email:  "tony@example.com",
+		Groups: []string{"cn=dev,ou=groups,dc=example", "cn=ops,ou=groups,dc=example", "everyone"},
+	}, claims)
+
+	// nested claims and transforms
+	claims, err = MapClaims(raw, &idpconfig.ClaimsMapping{
+		ID:     &idpconfig.ClaimRule{Claim: "employee.number"},
+		Email:  &idpconfig.ClaimRule{Claim: "employee.login", Replacement: "${0}@corp.example.com", Pattern: "^.+$"},
+		Name:   &idpconfig.ClaimRule{Claim: "email", Pattern: "^([^@]+)@"},
+		Groups: &idpconfig.ClaimRule{Pattern: "^cn=([^,]+),"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &Claims{
+		Sub:    "10086",
+		Name:   "tony",
+		Email:  "tony@corp.example.com",
+		Groups: []string{"dev", "ops"},
+	}, claims)
+
+	// missing required claims
+	_, err = MapClaims(raw, &idpconfig.ClaimsMapping{
+		Email: &idpconfig.ClaimRule{Claim: "mail"},
+	})
+	assert.NotNil(t, err)
+	_, err = MapClaims(raw, &idpconfig.ClaimsMapping{
+		ID: &idpconfig.ClaimRule{Pattern: "^[0-9]+$"},
+	})
+	assert.NotNil(t, err)
+
+	// invalid pattern
+	_, err = MapClaims(raw, &idpconfig.ClaimsMapping{
+		Name: &idpconfig.ClaimRule{Pattern: "("},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestCanProvision(t *testing.T) {
+	var mapping *idpconfig.ClaimsMapping
+	assert.True(t, mapping.CanProvision(nil))
+
+	mapping = &idpconfig.ClaimsMapping{DisableAutoProvision: true}
+	assert.False(t, mapping.CanProvision([]string{"dev"}))
+
+	mapping = &idpconfig.ClaimsMapping{ProvisionGroups: []string{"dev"}}
+	assert.True(t, mapping.CanProvision([]string{"ops", "dev"}))
+	assert.False(t, mapping.CanProvision([]string{"ops"}))
+}
@@ -21,15 +21,17 @@ import (
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	idpconfig "github.com/horizoncd/horizon/pkg/config/idp"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/idp/models"
 	"golang.org/x/oauth2"
 )
 
 type Claims struct {
-	Sub   string `json:"sub"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Sub    string   `json:"sub"`
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
 }
 
 func MakeOuath2Config(ctx context.Context, idp *models.IdentityProvider,
@@ -113,8 +115,8 @@ func MakeOidcProvider(ctx context.Context, idp *models.IdentityProvider) (*oidc.
 	return provider, nil
 }
 
-// HandleOIDC gets user's email and name by code & token
-func HandleOIDC(ctx context.Context, idp *models.IdentityProvider,
+// HandleOIDC gets user's email and name by code & token, claims are mapped by the mapping if it's not nil
+func HandleOIDC(ctx context.Context, idp *models.IdentityProvider, mapping *idpconfig.ClaimsMapping,
 	code string, redirect ...string) (*Claims, error) {
 	conf, err := MakeOuath2Config(ctx, idp)
 	if err != nil {
@@ -146,12 +148,12 @@ func HandleOIDC(ctx context.Context, idp *models.IdentityProvider,
 				"token = %v\n err = %v", token, err)
 	}
 
-	var claims Claims
-	if err := userinfo.Claims(&claims); err != nil {
+	raw := make(map[string]interface{})
+	if err := userinfo.Claims(&raw); err != nil {
 		return nil, perror.Wrapf(herrors.ErrParamInvalid,
 			"failed to parse claims:\n"+
 				"err = %v", err)
 	}
 
-	return &claims, nil
+	return MapClaims(raw, mapping)
 }
@@ -0,0 +1,134 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactclean
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/s3"
+	artifactmanager "github.com/horizoncd/horizon/pkg/artifact/manager"
+	artifactmodels "github.com/horizoncd/horizon/pkg/artifact/models"
+	"github.com/horizoncd/horizon/pkg/config/artifact"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const op = "job: artifact clean"
+
+var _deletedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "horizon",
+	Subsystem: "artifact",
+	Name:      "deleted_total",
+	Help:      "Artifacts and reports of pipelineruns deleted beyond the retention",
+}, []string{"type"})
+
+// Run deletes artifacts and reports uploaded beyond the retention periodically,
+// the ones of the latest pipelineruns of each cluster are kept.
+func Run(ctx context.Context, retention *artifact.Retention, artifactMgr artifactmanager.Manager,
+	storage s3.Interface) {
+	log.Infof(ctx, "Starting deleting artifacts beyond the retention every %v", retention.JobInterval)
+	defer log.Infof(ctx, "Stopping deleting artifacts beyond the retention")
+	ticker := time.NewTicker(retention.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "artifact clean job starts to execute, rid: %v", rid)
+			clean(ctx, retention, artifactMgr, storage, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func clean(ctx context.Context, retention *artifact.Retention, artifactMgr artifactmanager.Manager,
+	storage s3.Interface, now time.Time) {
+	// ids of the latest pipelineruns of clusters, whose artifacts are kept
+	latest := make(map[uint]map[uint]bool)
+	isLatest := func(a *artifactmodels.Artifact) (bool, error) {
+		if retention.KeepLatest <= 0 {
+			return false, nil
+		}
+		ids, ok := latest[a.ClusterID]
+		if !ok {
+			pipelinerunIDs, err := artifactMgr.ListLatestPipelinerunIDs(ctx, a.ClusterID, retention.KeepLatest)
+			if err != nil {
+				return false, err
+			}
+			ids = make(map[uint]bool, len(pipelinerunIDs))
+			for _, id := range pipelinerunIDs {
+				ids[id] = true
+			}
+			latest[a.ClusterID] = ids
+		}
+		return ids[a.PipelinerunID], nil
+	}
+
+	for _, group := range []struct {
+		types     []string
+		retention time.Duration
+	}{
+		{[]string{artifactmodels.TypeArtifact}, retention.Artifacts},
+		{[]string{artifactmodels.TypeJUnit, artifactmodels.TypeCoverage}, retention.Reports},
+	} {
+		before := now.Add(-group.retention)
+		deleted, afterID := 0, uint(0)
+		for {
+			artifacts, err := artifactMgr.ListUploadedBefore(ctx, group.types, before, afterID, retention.BatchSize)
+			if err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to list %v beyond the retention, err: %v",
+					group.types, err.Error())
+				break
+			}
+			for _, a := range artifacts {
+				afterID = a.ID
+				keep, err := isLatest(a)
+				if err != nil {
+					log.WithFiled(ctx, "op", op).Errorf("failed to list latest pipelineruns of cluster %d, err: %v",
+						a.ClusterID, err.Error())
+					continue
+				}
+				if keep {
+					continue
+				}
+				if err := storage.DeleteObjects(ctx, artifactmodels.ObjectPrefix(a.PipelinerunID, a.ID)); err != nil {
+					log.WithFiled(ctx, "op", op).Errorf("failed to delete objects of artifact %v(%d), err: %v",
+						a.Name, a.ID, err.Error())
+					continue
+				}
+				if err := artifactMgr.DeleteByID(ctx, a.ID); err != nil {
+					log.WithFiled(ctx, "op", op).Errorf("failed to delete artifact %v(%d), err: %v",
+						a.Name, a.ID, err.Error())
+					continue
+				}
+				_deletedCounter.WithLabelValues(a.Type).Inc()
+				deleted++
+			}
+			// the ones failed to delete are skipped by the id cursor and retried next time
+			if len(artifacts) < retention.BatchSize {
+				break
+			}
+		}
+		log.WithFiled(ctx, "op", op).Infof("%d artifacts of types %v are deleted", deleted, group.types)
+	}
+}
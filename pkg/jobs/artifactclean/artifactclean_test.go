@@ -0,0 +1,109 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactclean
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/s3"
+	artifactmanager "github.com/horizoncd/horizon/pkg/artifact/manager"
+	artifactmodels "github.com/horizoncd/horizon/pkg/artifact/models"
+	"github.com/horizoncd/horizon/pkg/config/artifact"
+)
+
+type fakeStorage struct {
+	s3.Interface
+	deleted []string
+	failed  map[string]bool
+}
+
+func (s *fakeStorage) DeleteObjects(_ context.Context, prefix string) error {
+	if s.failed[prefix] {
+		return errors.New("failed")
+	}
+	s.deleted = append(s.deleted, prefix)
+	return nil
+}
+
+func TestClean(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&artifactmodels.Artifact{}))
+	ctx := context.Background()
+	mgr := artifactmanager.New(db)
+	storage := &fakeStorage{failed: make(map[string]bool)}
+
+	newArtifact := func(clusterID, pipelinerunID uint, name, artifactType string) *artifactmodels.Artifact {
+		a, err := mgr.Create(ctx, &artifactmodels.Artifact{
+			ClusterID:     clusterID,
+			PipelinerunID: pipelinerunID,
+			Name:          name,
+			Type:          artifactType,
+		})
+		assert.Nil(t, err)
+		return a
+	}
+	// pipelinerun 3 is the latest one of cluster 1
+	oldArtifact := newArtifact(1, 1, "app.jar", artifactmodels.TypeArtifact)
+	oldReport := newArtifact(1, 1, "junit.xml", artifactmodels.TypeJUnit)
+	newReport := newArtifact(1, 2, "junit.xml", artifactmodels.TypeJUnit)
+	latestArtifact := newArtifact(1, 3, "app.jar", artifactmodels.TypeArtifact)
+	otherArtifact := newArtifact(2, 4, "app.jar", artifactmodels.TypeArtifact)
+
+	retention := &artifact.Retention{
+		Artifacts:  time.Hour,
+		Reports:    2 * time.Hour,
+		KeepLatest: 1,
+		BatchSize:  1,
+	}
+	// the reports are in the retention an hour and a half later
+	clean(ctx, retention, mgr, storage, time.Now().Add(90*time.Minute))
+	assert.Equal(t, []string{
+		artifactmodels.ObjectPrefix(1, oldArtifact.ID),
+	}, storage.deleted)
+	for _, a := range []*artifactmodels.Artifact{oldReport, newReport, latestArtifact, otherArtifact} {
+		_, err := mgr.GetByID(ctx, a.ID)
+		assert.Nil(t, err)
+	}
+
+	storage.deleted = nil
+	clean(ctx, retention, mgr, storage, time.Now().Add(3*time.Hour))
+	assert.Equal(t, []string{
+		artifactmodels.ObjectPrefix(1, oldReport.ID),
+		artifactmodels.ObjectPrefix(2, newReport.ID),
+	}, storage.deleted)
+	remaining, err := mgr.ListUploadedBefore(ctx, []string{artifactmodels.TypeArtifact,
+		artifactmodels.TypeJUnit}, time.Now().Add(time.Hour), 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(remaining))
+	assert.Equal(t, latestArtifact.ID, remaining[0].ID)
+	assert.Equal(t, otherArtifact.ID, remaining[1].ID)
+
+	// artifacts whose objects failed to delete are kept
+	failed := newArtifact(3, 5, "app.jar", artifactmodels.TypeArtifact)
+	storage.failed[artifactmodels.ObjectPrefix(5, failed.ID)] = true
+	newArtifact(3, 6, "app.jar", artifactmodels.TypeArtifact)
+	retention.KeepLatest = 0
+	storage.deleted = nil
+	clean(ctx, retention, mgr, storage, time.Now().Add(3*time.Hour))
+	_, err = mgr.GetByID(ctx, failed.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(storage.deleted))
+}
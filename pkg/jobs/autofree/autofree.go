@@ -16,6 +16,7 @@ package autofree
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
@@ -27,12 +28,14 @@ import (
 	"github.com/horizoncd/horizon/lib/q"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/config/autofree"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 func Run(ctx context.Context, jobConfig *autofree.Config, userMgr usermanager.Manager,
-	clusterCtr clusterctl.Controller, prCtr prctl.Controller) {
+	eventSvc eventservice.Service, clusterCtr clusterctl.Controller, prCtr prctl.Controller) {
 	// verify account
 	user, err := userMgr.GetUserByID(ctx, jobConfig.AccountID)
 	if err != nil {
@@ -59,15 +62,15 @@ func Run(ctx context.Context, jobConfig *autofree.Config, userMgr usermanager.Ma
 			// nolint
 			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
 			log.Infof(ctx, "auto-free job starts to execute, rid: %v", rid)
-			process(ctx, jobConfig, clusterCtr, prCtr)
+			process(ctx, jobConfig, eventSvc, clusterCtr, prCtr)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func process(ctx context.Context, jobConfig *autofree.Config, clusterCtr clusterctl.Controller,
-	prCtr prctl.Controller) {
+func process(ctx context.Context, jobConfig *autofree.Config, eventSvc eventservice.Service,
+	clusterCtr clusterctl.Controller, prCtr prctl.Controller) {
 	op := "job: cluster auto-free"
 	query := &q.Query{
 		PageNumber: common.DefaultPageNumber,
@@ -97,13 +100,15 @@ func process(ctx context.Context, jobConfig *autofree.Config, clusterCtr cluster
 
 				prUpdatedAt := pipelineruns[0].UpdatedAt
 				if !expired(clr, prUpdatedAt) {
+					freeAt := freeTime(clr, prUpdatedAt)
+					if shouldWarn(jobConfig, freeAt, time.Now()) && supportedEnv(jobConfig, clr.EnvironmentName) {
+						warn(ctx, eventSvc, clr, freeAt)
+					}
 					return false, nil
 				}
 				supported := func() bool {
-					for _, env := range jobConfig.SupportedEnvs {
-						if clr.EnvironmentName == env {
-							return true
-						}
+					if supportedEnv(jobConfig, clr.EnvironmentName) {
+						return true
 					}
 					log.WithFiled(ctx, "op", op).
 						Warningf("%v environment does not allow auto-free. cluster: %v, expire seconds: %v",
@@ -138,7 +143,17 @@ func process(ctx context.Context, jobConfig *autofree.Config, clusterCtr cluster
 	}
 }
 
-func expired(cluster *clusterctl.ListClusterWithExpiryResponse, prUpdateAt time.Time) bool {
+func supportedEnv(jobConfig *autofree.Config, environment string) bool {
+	for _, env := range jobConfig.SupportedEnvs {
+		if environment == env {
+			return true
+		}
+	}
+	return false
+}
+
+// freeTime is when the cluster expires, it's counted from the last update of the cluster or its pipelineruns
+func freeTime(cluster *clusterctl.ListClusterWithExpiryResponse, prUpdateAt time.Time) time.Time {
 	updatedAt := cluster.UpdatedAt
 	var lastUpdateAt time.Time
 	if updatedAt.After(prUpdateAt) {
@@ -146,5 +161,33 @@ func expired(cluster *clusterctl.ListClusterWithExpiryResponse, prUpdateAt time.
 	} else {
 		lastUpdateAt = prUpdateAt
 	}
-	return lastUpdateAt.Add(time.Duration(cluster.ExpireSeconds * 1e9)).Before(time.Now())
+	return lastUpdateAt.Add(time.Duration(cluster.ExpireSeconds * 1e9))
+}
+
+func expired(cluster *clusterctl.ListClusterWithExpiryResponse, prUpdateAt time.Time) bool {
+	return freeTime(cluster, prUpdateAt).Before(time.Now())
+}
+
+// shouldWarn tells whether to warn of the cluster freed at freeAt, only the first run
+// within WarningBefore of freeAt warns, so that a cluster is warned once before it's freed
+func shouldWarn(jobConfig *autofree.Config, freeAt, now time.Time) bool {
+	if jobConfig.WarningBefore <= 0 {
+		return false
+	}
+	remaining := freeAt.Sub(now)
+	return remaining > 0 && remaining <= jobConfig.WarningBefore &&
+		remaining > jobConfig.WarningBefore-jobConfig.JobInterval
+}
+
+func warn(ctx context.Context, eventSvc eventservice.Service,
+	cluster *clusterctl.ListClusterWithExpiryResponse, freeAt time.Time) {
+	extra, err := json.Marshal(eventmodels.AutoFreeWarning{FreeAt: freeAt})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal auto-free warning of cluster %v, err: %v", cluster.Name, err)
+		return
+	}
+	extraStr := string(extra)
+	eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
+		eventmodels.ClusterAutoFreeWarned, &extraStr)
+	log.Infof(ctx, "cluster %v is warned to be freed at %v", cluster.Name, freeAt)
 }
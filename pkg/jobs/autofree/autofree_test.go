@@ -42,6 +42,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
 	"github.com/horizoncd/horizon/pkg/errors"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	idpmodels "github.com/horizoncd/horizon/pkg/idp/models"
 	"github.com/horizoncd/horizon/pkg/idp/utils"
@@ -134,7 +135,7 @@ func TestAutoFreeExpiredCluster(t *testing.T) {
 	parameter.PRMgr = &prmanager.PRManager{
 		PipelineRun: mockPipelineManager,
 	}
-	clrCtl := clusterctl.NewController(conf, parameter)
+	clrCtl := clusterctl.NewController(conf, parameter, nil)
 	prCtl := prctl.NewController(conf, parameter)
 
 	// init data
@@ -213,5 +214,26 @@ func TestAutoFreeExpiredCluster(t *testing.T) {
 		BatchInterval: 0 * time.Second,
 		BatchSize:     20,
 		SupportedEnvs: []string{"dev"},
-	}, manager.UserMgr, clrCtl, prCtl)
+	}, manager.UserMgr, eventservice.New(manager), clrCtl, prCtl)
+}
+
+func TestShouldWarn(t *testing.T) {
+	now := time.Now()
+	config := &autofree.Config{
+		JobInterval:   time.Hour,
+		WarningBefore: 24 * time.Hour,
+	}
+	// warned by the first run within a day before it's freed
+	assert.True(t, shouldWarn(config, now.Add(24*time.Hour), now))
+	assert.True(t, shouldWarn(config, now.Add(23*time.Hour+time.Minute), now))
+	// warned by the previous run
+	assert.False(t, shouldWarn(config, now.Add(23*time.Hour), now))
+	assert.False(t, shouldWarn(config, now.Add(time.Hour), now))
+	// not warned yet
+	assert.False(t, shouldWarn(config, now.Add(25*time.Hour), now))
+	// freed already
+	assert.False(t, shouldWarn(config, now.Add(-time.Hour), now))
+
+	config.WarningBefore = 0
+	assert.False(t, shouldWarn(config, now.Add(24*time.Hour), now))
 }
@@ -0,0 +1,129 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersnapshot
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	snapshotmodels "github.com/horizoncd/horizon/pkg/clustersnapshot/models"
+	snapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	"github.com/horizoncd/horizon/pkg/config/clustersnapshot"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const op = "job: cluster snapshot"
+
+// Run takes snapshots of all clusters periodically, and removes snapshots beyond the retention
+func Run(ctx context.Context, jobConfig *clustersnapshot.Config, manager *managerparam.Manager,
+	clusterGitRepo gitrepo.ClusterGitRepo, snapshotSvc snapshotservice.Service) {
+	// listing clusters requires a user in context
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:  "cluster-snapshot-job",
+		Admin: true,
+	})
+
+	log.Infof(ctx, "Starting taking cluster snapshots every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping taking cluster snapshots")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "cluster snapshot job starts to execute, rid: %v", rid)
+			process(ctx, jobConfig, manager, clusterGitRepo, snapshotSvc)
+			prune(ctx, jobConfig, manager)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func process(ctx context.Context, jobConfig *clustersnapshot.Config, manager *managerparam.Manager,
+	clusterGitRepo gitrepo.ClusterGitRepo, snapshotSvc snapshotservice.Service) {
+	query := &q.Query{
+		PageNumber: common.DefaultPageNumber,
+		PageSize:   jobConfig.BatchSize,
+		Keywords:   make(map[string]interface{}),
+	}
+	for {
+		_, clusters, err := manager.ClusterMgr.List(ctx, query)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list clusters, err: %v", err.Error())
+			return
+		}
+
+		appIDs := make([]uint, 0, len(clusters))
+		for _, cluster := range clusters {
+			appIDs = append(appIDs, cluster.ApplicationID)
+		}
+		apps, err := manager.ApplicationMgr.GetByIDs(ctx, appIDs)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list applications, err: %v", err.Error())
+			return
+		}
+		appNames := make(map[uint]string, len(apps))
+		for _, app := range apps {
+			appNames[app.ID] = app.Name
+		}
+
+		for _, cluster := range clusters {
+			// the gitops repo of a creating cluster may not be ready
+			if cluster.Status == common.ClusterStatusCreating {
+				continue
+			}
+			appName, ok := appNames[cluster.ApplicationID]
+			if !ok {
+				continue
+			}
+			commit, err := clusterGitRepo.GetConfigCommit(ctx, appName, cluster.Name)
+			if err != nil {
+				log.WithFiled(ctx, "op", op).
+					Errorf("failed to get config commit of cluster %v, err: %v", cluster.Name, err.Error())
+				continue
+			}
+			if _, err := snapshotSvc.Take(ctx, cluster.Cluster, commit.Gitops,
+				snapshotmodels.TriggerSchedule, 0); err != nil {
+				log.WithFiled(ctx, "op", op).
+					Errorf("failed to take snapshot of cluster %v, err: %v", cluster.Name, err.Error())
+			}
+		}
+		if len(clusters) < query.PageSize {
+			break
+		}
+		query.PageNumber++
+		time.Sleep(jobConfig.BatchInterval)
+	}
+}
+
+func prune(ctx context.Context, jobConfig *clustersnapshot.Config, manager *managerparam.Manager) {
+	deleted, err := manager.ClusterSnapshotMgr.DeleteBefore(ctx, time.Now().Add(-jobConfig.Retention))
+	if err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to remove expired snapshots, err: %v", err.Error())
+		return
+	}
+	log.WithFiled(ctx, "op", op).Infof("%d expired snapshots are removed", deleted)
+}
@@ -0,0 +1,185 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recyclebin
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/recyclebin"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const op = "job: recycle bin"
+
+var _purgedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "horizon",
+	Subsystem: "recycle_bin",
+	Name:      "purged_total",
+	Help:      "Applications and clusters deleted purged beyond the retention",
+}, []string{"resource"})
+
+// Run purges applications and clusters deleted beyond the retention periodically,
+// with their git repos, members and tags, they can not be restored any more.
+// Pipelineruns of clusters are kept as the deploy history.
+func Run(ctx context.Context, jobConfig *recyclebin.Config, manager *managerparam.Manager,
+	applicationGitRepo appgitrepo.ApplicationGitRepo, clusterGitRepo clustergitrepo.ClusterGitRepo) {
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:  "recycle-bin-job",
+		Admin: true,
+	})
+
+	log.Infof(ctx, "Starting purging applications and clusters deleted every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping purging applications and clusters deleted")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "recycle bin job starts to execute, rid: %v", rid)
+			purge(ctx, jobConfig, manager, applicationGitRepo, clusterGitRepo, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func purge(ctx context.Context, jobConfig *recyclebin.Config, manager *managerparam.Manager,
+	applicationGitRepo appgitrepo.ApplicationGitRepo, clusterGitRepo clustergitrepo.ClusterGitRepo, now time.Time) {
+	before := now.Add(-jobConfig.Retention)
+
+	// clusters are purged first, as applications are deleted after their clusters
+	purged, afterID := 0, uint(0)
+	for {
+		clusters, err := manager.ClusterMgr.ListDeletedBefore(ctx, before, afterID, jobConfig.BatchSize)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list clusters deleted, err: %v", err.Error())
+			return
+		}
+		n := 0
+		for _, cluster := range clusters {
+			afterID = cluster.ID
+			if err := purgeCluster(ctx, manager, clusterGitRepo, cluster); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to purge cluster %v(%d), err: %v",
+					cluster.Name, cluster.ID, err.Error())
+				continue
+			}
+			n++
+		}
+		_purgedCounter.WithLabelValues(common.ResourceCluster).Add(float64(n))
+		purged += n
+		// the ones failed to purge are skipped by the id cursor and retried next time
+		if len(clusters) < jobConfig.BatchSize {
+			break
+		}
+	}
+	log.WithFiled(ctx, "op", op).Infof("%d clusters deleted are purged", purged)
+
+	purged, afterID = 0, 0
+	for {
+		applications, err := manager.ApplicationMgr.ListDeletedBefore(ctx, before, afterID, jobConfig.BatchSize)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list applications deleted, err: %v", err.Error())
+			return
+		}
+		n := 0
+		for _, application := range applications {
+			afterID = application.ID
+			if err := purgeApplication(ctx, manager, applicationGitRepo, application); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to purge application %v(%d), err: %v",
+					application.Name, application.ID, err.Error())
+				continue
+			}
+			n++
+		}
+		_purgedCounter.WithLabelValues(common.ResourceApplication).Add(float64(n))
+		purged += n
+		if len(applications) < jobConfig.BatchSize {
+			break
+		}
+	}
+	log.WithFiled(ctx, "op", op).Infof("%d applications deleted are purged", purged)
+}
+
+func purgeCluster(ctx context.Context, manager *managerparam.Manager,
+	clusterGitRepo clustergitrepo.ClusterGitRepo, cluster *clustermodels.Cluster) error {
+	application, err := manager.ApplicationMgr.GetByIDIncludeSoftDelete(ctx, cluster.ApplicationID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+	} else {
+		// the git repo was moved to the recycling group named after the cluster id when it was deleted,
+		// so it never conflicts with the ones of clusters created later.
+		// It's purged even if the cluster is hard deleted, since the clusters deleted before the recycle bin
+		// are all marked as hard deleted, whether their git repos were deleted or moved
+		if err := clusterGitRepo.PurgeCluster(ctx, application.Name, cluster.Name, cluster.ID); err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return err
+			}
+		}
+	}
+	if err := manager.MemberMgr.HardDeleteMemberByResourceTypeID(ctx,
+		string(membermodels.TypeApplicationCluster), cluster.ID); err != nil {
+		return err
+	}
+	if err := manager.TagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, cluster.ID, nil); err != nil {
+		return err
+	}
+	return manager.ClusterMgr.Purge(ctx, cluster.ID)
+}
+
+func purgeApplication(ctx context.Context, manager *managerparam.Manager,
+	applicationGitRepo appgitrepo.ApplicationGitRepo, application *appmodels.Application) error {
+	// the git repo of an application hard deleted was deleted with it,
+	// and the repo of the same name may belong to an application created later
+	if !application.HardDeleted {
+		if err := applicationGitRepo.HardDeleteApplication(ctx, application.Name); err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return err
+			}
+		}
+	}
+	if err := manager.MemberMgr.HardDeleteMemberByResourceTypeID(ctx,
+		string(membermodels.TypeApplication), application.ID); err != nil {
+		return err
+	}
+	if err := manager.ApplicationRegionMgr.UpsertByApplicationID(ctx, application.ID, nil); err != nil {
+		return err
+	}
+	if err := manager.TagMgr.UpsertByResourceTypeID(ctx, common.ResourceApplication,
+		application.ID, nil); err != nil {
+		return err
+	}
+	return manager.ApplicationMgr.Purge(ctx, application.ID)
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recyclebin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	appgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	appregionmodels "github.com/horizoncd/horizon/pkg/applicationregion/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/recyclebin"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+)
+
+func TestPurge(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&membermodels.Member{}, &tagmodels.Tag{}, &appregionmodels.ApplicationRegion{},
+		&prmodels.Pipelinerun{}))
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   uint(1),
+	})
+	manager := managerparam.InitManager(db)
+
+	mockCtl := gomock.NewController(t)
+	appGitRepo := appgitrepomock.NewMockApplicationGitRepo2(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+
+	newApplication := func(name string) *appmodels.Application {
+		application, err := manager.ApplicationMgr.Create(ctx, &appmodels.Application{
+			Name:     name,
+			Priority: "P3",
+		}, nil)
+		assert.Nil(t, err)
+		return application
+	}
+	newCluster := func(application *appmodels.Application, name string) *clustermodels.Cluster {
+		cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+			ApplicationID: application.ID,
+			Name:          name,
+		}, nil, nil)
+		assert.Nil(t, err)
+		return cluster
+	}
+
+	deletedApp := newApplication("deleted")
+	failedCluster := newCluster(deletedApp, "deleted-failed")
+	deletedCluster := newCluster(deletedApp, "deleted")
+	pipelinerun, err := manager.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: deletedCluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusOK),
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, manager.ClusterMgr.DeleteByID(ctx, failedCluster.ID))
+	assert.Nil(t, manager.ClusterMgr.DeleteByID(ctx, deletedCluster.ID))
+	assert.Nil(t, manager.ApplicationMgr.DeleteByID(ctx, deletedApp.ID))
+	hardDeletedApp := newApplication("hard-deleted")
+	assert.Nil(t, manager.ApplicationMgr.HardDeleteByID(ctx, hardDeletedApp.ID))
+	liveApp := newApplication("live")
+	liveCluster := newCluster(liveApp, "live")
+
+	// nothing is purged within the retention
+	cfg := &recyclebin.Config{BatchSize: 1, Retention: time.Hour}
+	purge(ctx, cfg, manager, appGitRepo, clusterGitRepo, time.Now())
+
+	// the git repo of the application hard deleted is not touched, as it was deleted already,
+	// and the cluster failed to purge does not block the ones after it
+	clusterGitRepo.EXPECT().PurgeCluster(ctx, deletedApp.Name, failedCluster.Name, failedCluster.ID).
+		Return(errors.New("gitlab is down")).Times(1)
+	clusterGitRepo.EXPECT().PurgeCluster(ctx, deletedApp.Name, deletedCluster.Name, deletedCluster.ID).
+		Return(nil).Times(1)
+	appGitRepo.EXPECT().HardDeleteApplication(ctx, deletedApp.Name).Return(nil).Times(1)
+	purge(ctx, cfg, manager, appGitRepo, clusterGitRepo, time.Now().Add(2*time.Hour))
+
+	_, err = manager.ClusterMgr.GetByIDIncludeSoftDelete(ctx, failedCluster.ID)
+	assert.Nil(t, err)
+	_, err = manager.ClusterMgr.GetByIDIncludeSoftDelete(ctx, deletedCluster.ID)
+	assert.NotNil(t, err)
+	// the deploy history of the cluster purged is kept
+	_, err = manager.PRMgr.PipelineRun.GetByID(ctx, pipelinerun.ID)
+	assert.Nil(t, err)
+	_, err = manager.ClusterMgr.GetByID(ctx, liveCluster.ID)
+	assert.Nil(t, err)
+	_, err = manager.ApplicationMgr.GetByIDIncludeSoftDelete(ctx, deletedApp.ID)
+	assert.NotNil(t, err)
+	_, err = manager.ApplicationMgr.GetByIDIncludeSoftDelete(ctx, hardDeletedApp.ID)
+	assert.NotNil(t, err)
+	_, err = manager.ApplicationMgr.GetByID(ctx, liveApp.ID)
+	assert.Nil(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(_purgedCounter.WithLabelValues(common.ResourceCluster)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(_purgedCounter.WithLabelValues(common.ResourceApplication)))
+}
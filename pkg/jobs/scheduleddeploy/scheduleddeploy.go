@@ -0,0 +1,188 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduleddeploy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/scheduleddeploy"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	op = "job: scheduled deploy"
+
+	_resultTriggered = "triggered"
+	_resultFailed    = "failed"
+
+	// _maxMessageLength is the length of the message column
+	_maxMessageLength = 2048
+)
+
+var _triggeredCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "horizon",
+	Subsystem: "scheduled_deploy",
+	Name:      "triggered_total",
+	Help:      "Scheduled deploys triggered by their results",
+}, []string{"result"})
+
+// Run triggers builddeploys and restarts which are due periodically, as the users who scheduled them.
+// A deploy is claimed by moving it to running before triggered, so that it is triggered only once.
+func Run(ctx context.Context, jobConfig *scheduleddeploy.Config, manager *managerparam.Manager,
+	authorizer auth.Authorizer, clusterCtl clusterctl.Controller) {
+	log.Infof(ctx, "Starting triggering scheduled deploys every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping triggering scheduled deploys")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "scheduled deploy job starts to execute, rid: %v", rid)
+			trigger(ctx, jobConfig, manager, authorizer, clusterCtl, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func trigger(ctx context.Context, jobConfig *scheduleddeploy.Config, manager *managerparam.Manager,
+	authorizer auth.Authorizer, clusterCtl clusterctl.Controller, now time.Time) {
+	for {
+		deploys, err := manager.ScheduledDeployMgr.ListDue(ctx, now, jobConfig.BatchSize)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list scheduled deploys, err: %v", err.Error())
+			return
+		}
+
+		for _, deploy := range deploys {
+			if err := manager.ScheduledDeployMgr.UpdateStatus(ctx, deploy.ID, models.StatusScheduled,
+				models.StatusRunning, 0, ""); err != nil {
+				// it's cancelled or claimed by another instance
+				if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+					continue
+				}
+				log.WithFiled(ctx, "op", op).Errorf("failed to claim scheduled deploy %d, err: %v",
+					deploy.ID, err.Error())
+				return
+			}
+
+			status, message := models.StatusTriggered, ""
+			pipelinerunID, err := triggerOne(ctx, manager, authorizer, clusterCtl, deploy)
+			if err != nil {
+				status, message = models.StatusFailed, err.Error()
+				if len(message) > _maxMessageLength {
+					message = message[:_maxMessageLength]
+				}
+				_triggeredCounter.WithLabelValues(_resultFailed).Inc()
+				log.WithFiled(ctx, "op", op).Warningf("failed to trigger scheduled deploy %d of cluster %d, err: %v",
+					deploy.ID, deploy.ClusterID, message)
+			} else {
+				_triggeredCounter.WithLabelValues(_resultTriggered).Inc()
+				log.WithFiled(ctx, "op", op).Infof("scheduled deploy %d of cluster %d triggered pipelinerun %d",
+					deploy.ID, deploy.ClusterID, pipelinerunID)
+			}
+			if err := manager.ScheduledDeployMgr.UpdateStatus(ctx, deploy.ID, models.StatusRunning,
+				status, pipelinerunID, message); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to update scheduled deploy %d to %s, err: %v",
+					deploy.ID, status, err.Error())
+			}
+		}
+
+		if len(deploys) < jobConfig.BatchSize {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// triggerOne deploys the cluster as the user who scheduled the deploy, the permission of the user,
+// locks and freeze windows are checked again as if the user deployed it now
+func triggerOne(ctx context.Context, manager *managerparam.Manager, authorizer auth.Authorizer,
+	clusterCtl clusterctl.Controller, deploy *models.ScheduledDeploy) (uint, error) {
+	user, err := manager.UserMgr.GetUserByID(ctx, deploy.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+	if user.Banned {
+		return 0, perror.Wrapf(herrors.ErrForbidden, "user %s who scheduled the deploy is banned", user.Name)
+	}
+	currentUser := &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	}
+	ctx = common.WithContext(ctx, currentUser)
+	if authorizer != nil {
+		decision, reason, err := authorizer.Authorize(ctx, auth.AttributesRecord{
+			User:            currentUser,
+			Verb:            "create",
+			APIGroup:        common.GroupCore,
+			Resource:        common.ResourceCluster,
+			SubResource:     deploy.Action,
+			Name:            strconv.FormatUint(uint64(deploy.ClusterID), 10),
+			ResourceRequest: true,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if decision != auth.DecisionAllow {
+			return 0, perror.Wrapf(herrors.ErrForbidden, "user %s can not %s the cluster any more: %s",
+				user.Name, deploy.Action, reason)
+		}
+	}
+
+	switch deploy.Action {
+	case prmodels.ActionBuildDeploy:
+		resp, err := clusterCtl.BuildDeploy(ctx, deploy.ClusterID, clusterctl.BuildDeployRequestOf(deploy))
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	case prmodels.ActionRestart:
+		resp, err := clusterCtl.Restart(ctx, deploy.ClusterID)
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	default:
+		return 0, fmt.Errorf("unsupported action %s", deploy.Action)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduleddeploy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/config/scheduleddeploy"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// fakeClusterController records the deploys triggered, restarts of cluster 2 fail
+type fakeClusterController struct {
+	clusterctl.Controller
+	users        []string
+	buildDeploys []*clusterctl.BuildDeployRequest
+}
+
+func (c *fakeClusterController) BuildDeploy(ctx context.Context, clusterID uint,
+	r *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error) {
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.users = append(c.users, user.GetName())
+	c.buildDeploys = append(c.buildDeploys, r)
+	return &clusterctl.BuildDeployResponse{PipelinerunID: 100}, nil
+}
+
+func (c *fakeClusterController) Restart(ctx context.Context,
+	clusterID uint) (*clusterctl.PipelinerunIDResponse, error) {
+	if clusterID == 2 {
+		return nil, perror.Wrap(herrors.ErrDeployFrozen, "deploys to environment online are frozen")
+	}
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: 101}, nil
+}
+
+func TestTrigger(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.ScheduledDeploy{}, &usermodels.User{}))
+	ctx := context.Background()
+	manager := managerparam.InitManager(db)
+
+	user := &usermodels.User{Name: "tony", Email: "tony@horizon.com"}
+	assert.Nil(t, db.Create(user).Error)
+
+	now := time.Now()
+	deploys := []*models.ScheduledDeploy{
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Title: "nightly", GitRefType: "tag", GitRef: "v1.0.0"},
+		{ClusterID: 2, Action: prmodels.ActionRestart},
+		{ClusterID: 1, Action: prmodels.ActionRestart, ScheduledAt: now.Add(time.Hour)},
+		{ClusterID: 1, Action: prmodels.ActionRestart, Status: models.StatusCancelled},
+	}
+	for _, deploy := range deploys {
+		deploy.CreatedBy = user.ID
+		if deploy.ScheduledAt.IsZero() {
+			deploy.ScheduledAt = now.Add(-time.Minute)
+		}
+		if deploy.Status == "" {
+			deploy.Status = models.StatusScheduled
+		}
+		_, err := manager.ScheduledDeployMgr.Create(ctx, deploy)
+		assert.Nil(t, err)
+	}
+
+	ctl := &fakeClusterController{}
+	// the deploys due are triggered in multiple batches
+	trigger(ctx, &scheduleddeploy.Config{BatchSize: 1}, manager, nil, ctl, now)
+
+	assert.Equal(t, []string{"tony"}, ctl.users)
+	assert.Equal(t, 1, len(ctl.buildDeploys))
+	assert.Equal(t, "nightly", ctl.buildDeploys[0].Title)
+	assert.Equal(t, "v1.0.0", ctl.buildDeploys[0].Git.Tag)
+
+	expected := []struct {
+		status        string
+		pipelinerunID uint
+	}{
+		{models.StatusTriggered, 100},
+		{models.StatusFailed, 0},
+		{models.StatusScheduled, 0},
+		{models.StatusCancelled, 0},
+	}
+	for i, deploy := range deploys {
+		deploy, err := manager.ScheduledDeployMgr.GetByID(ctx, deploy.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, expected[i].status, deploy.Status)
+		assert.Equal(t, expected[i].pipelinerunID, deploy.PipelinerunID)
+	}
+	failed, err := manager.ScheduledDeployMgr.GetByID(ctx, deploys[1].ID)
+	assert.Nil(t, err)
+	assert.Contains(t, failed.Message, "frozen")
+
+	// triggered deploys are not triggered again
+	trigger(ctx, &scheduleddeploy.Config{BatchSize: 10}, manager, nil, ctl, now)
+	assert.Equal(t, 1, len(ctl.users))
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,7 +33,7 @@ import (
 
 type Job = func(ctx context.Context)
 
-// Run runs the job in a single instance
+// Run runs the job in a single instance, it returns after all the jobs return once ctx is done
 func Run(ctx context.Context, jobconfig *jobconfig.Config, jobs ...Job) {
 	hostname := os.Getenv("HOSTNAME")
 	// get candidate name
@@ -53,6 +54,7 @@ func Run(ctx context.Context, jobconfig *jobconfig.Config, jobs ...Job) {
 
 	// create the leader elector
 	var elector *leaderelection.LeaderElector
+	var wg sync.WaitGroup
 	electionConfig := leaderelection.LeaderElectionConfig{
 		Lock: &resourcelock.LeaseLock{
 			LeaseMeta: metav1.ObjectMeta{
@@ -67,11 +69,17 @@ func Run(ctx context.Context, jobconfig *jobconfig.Config, jobs ...Job) {
 		LeaseDuration: time.Duration(jobconfig.LeaseDuration) * time.Second,
 		RenewDeadline: time.Duration(jobconfig.RenewDeadline) * time.Second,
 		RetryPeriod:   time.Duration(jobconfig.RetryPeriod) * time.Second,
+		// the lease is released on shutdown, so that another instance takes over the jobs at once
+		ReleaseOnCancel: true,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
 				for _, job := range jobs {
 					log.Debugf(ctx, "job %p is running", job)
-					go job(ctx)
+					wg.Add(1)
+					go func(job Job) {
+						defer wg.Done()
+						job(ctx)
+					}(job)
 				}
 			},
 			OnStoppedLeading: func() {
@@ -89,6 +97,7 @@ func Run(ctx context.Context, jobconfig *jobconfig.Config, jobs ...Job) {
 	for {
 		select {
 		case <-ctx.Done():
+			wg.Wait()
 			return
 		default:
 			elector.Run(ctx)
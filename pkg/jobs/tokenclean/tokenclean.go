@@ -0,0 +1,123 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenclean
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/config/tokenclean"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	op = "job: token clean"
+
+	_kindAuthorizationCode = "authorization_code"
+	_kindAccessToken       = "access_token"
+	_kindRefreshToken      = "refresh_token"
+)
+
+var _purgedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "horizon",
+	Subsystem: "token_clean",
+	Name:      "purged_total",
+	Help:      "Expired tokens purged from the token store",
+}, []string{"kind"})
+
+// Run purges expired authorization codes, access tokens and refresh tokens issued to oauth clients periodically.
+// Personal and resource access tokens are kept, as they are managed by users.
+func Run(ctx context.Context, jobConfig *tokenclean.Config, tokenMgr tokenmanager.Manager) {
+	log.Infof(ctx, "Starting purging expired tokens every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping purging expired tokens")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "token clean job starts to execute, rid: %v", rid)
+			purge(ctx, jobConfig, tokenMgr, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func purge(ctx context.Context, jobConfig *tokenclean.Config, tokenMgr tokenmanager.Manager, now time.Time) {
+	cursor := uint(0)
+	purged := 0
+	for {
+		tokens, err := tokenMgr.ListExpirableTokensAfterID(ctx, cursor, jobConfig.BatchSize)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list tokens, err: %v", err.Error())
+			return
+		}
+
+		expired := make([]uint, 0, len(tokens))
+		kinds := make(map[string]float64)
+		for _, token := range tokens {
+			cursor = token.ID
+			if token.CreatedAt.Add(token.ExpiresIn).After(now) {
+				continue
+			}
+			expired = append(expired, token.ID)
+			kinds[kindOf(token)]++
+		}
+		if len(expired) > 0 {
+			if _, err := tokenMgr.DeleteTokensByIDs(ctx, expired); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to delete expired tokens, err: %v", err.Error())
+				return
+			}
+			for kind, count := range kinds {
+				_purgedCounter.WithLabelValues(kind).Add(count)
+			}
+			purged += len(expired)
+		}
+
+		if len(tokens) < jobConfig.BatchSize {
+			break
+		}
+		select {
+		case <-time.After(jobConfig.BatchInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+	log.WithFiled(ctx, "op", op).Infof("%d expired tokens are purged", purged)
+}
+
+// kindOf tells the kind of the token by its prefix, authorization codes are not prefixed
+func kindOf(token *tokenmodels.Token) string {
+	switch {
+	case strings.HasPrefix(token.Code, generator.RefreshTokenPrefix):
+		return _kindRefreshToken
+	case len(token.Code) > 2 && token.Code[0] == 'h' && token.Code[2] == '_':
+		return _kindAccessToken
+	default:
+		return _kindAuthorizationCode
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenclean
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/config/tokenclean"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+)
+
+func TestPurge(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&tokenmodels.Token{}))
+	ctx := context.Background()
+	mgr := tokenmanager.New(db)
+
+	now := time.Now()
+	expired, valid := now.Add(-2*time.Hour), now
+	tokens := map[string]*tokenmodels.Token{
+		"code":          {Code: "MTIzNDU2", ClientID: "client", CreatedAt: expired, ExpiresIn: time.Minute},
+		"access":        {Code: "ho_expired", ClientID: "client", CreatedAt: expired, ExpiresIn: time.Hour},
+		"refresh":       {Code: "hr_expired", ClientID: "client", CreatedAt: expired, ExpiresIn: time.Hour},
+		"validAccess":   {Code: "ho_valid", ClientID: "client", CreatedAt: valid, ExpiresIn: time.Hour},
+		"validRefresh":  {Code: "hr_valid", ClientID: "client", CreatedAt: expired, ExpiresIn: 720 * time.Hour},
+		"personal":      {Code: "ha_expired", CreatedAt: expired, ExpiresIn: time.Hour},
+		"neverExpiring": {Code: "ha_never", CreatedAt: expired},
+	}
+	for _, token := range tokens {
+		_, err := mgr.CreateToken(ctx, token)
+		assert.Nil(t, err)
+	}
+
+	// the tokens are checked in multiple batches
+	purge(ctx, &tokenclean.Config{BatchSize: 2}, mgr, now)
+
+	for name, token := range tokens {
+		_, err := mgr.LoadTokenByID(ctx, token.ID)
+		switch name {
+		case "code", "access", "refresh":
+			assert.NotNil(t, err, name)
+		default:
+			assert.Nil(t, err, name)
+		}
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(_purgedCounter.WithLabelValues(_kindAuthorizationCode)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(_purgedCounter.WithLabelValues(_kindAccessToken)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(_purgedCounter.WithLabelValues(_kindRefreshToken)))
+}
@@ -0,0 +1,121 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestpolicy
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	RuleDisallowLatestTag     = "disallowLatestTag"
+	RuleRequireResourceLimits = "requireResourceLimits"
+	RuleDisallowPrivileged    = "disallowPrivileged"
+)
+
+// Rule evaluates a rendered manifest and returns messages of the violations
+type Rule func(manifest map[string]interface{}) []string
+
+var rules = map[string]Rule{
+	RuleDisallowLatestTag:     disallowLatestTag,
+	RuleRequireResourceLimits: requireResourceLimits,
+	RuleDisallowPrivileged:    disallowPrivileged,
+}
+
+// Register registers a rule which can be referred to by policies,
+// installations can register rules evaluated by their own engines, such as rego or cel
+func Register(name string, rule Rule) {
+	rules[name] = rule
+}
+
+func disallowLatestTag(manifest map[string]interface{}) []string {
+	var msgs []string
+	for _, c := range containersOf(manifest) {
+		image, _ := c["image"].(string)
+		if usesLatestTag(image) {
+			msgs = append(msgs, fmt.Sprintf("container %v uses image %s with the latest tag", c["name"], image))
+		}
+	}
+	return msgs
+}
+
+// usesLatestTag tells whether the image is tagged latest or not tagged at all, images pinned by digest are fine
+func usesLatestTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i < 0 || name[i+1:] == "latest"
+}
+
+func requireResourceLimits(manifest map[string]interface{}) []string {
+	var msgs []string
+	for _, c := range containersOf(manifest) {
+		limits := nestedMap(c, "resources", "limits")
+		for _, resource := range []string{"cpu", "memory"} {
+			if _, ok := limits[resource]; !ok {
+				msgs = append(msgs, fmt.Sprintf("container %v has no %s limit", c["name"], resource))
+			}
+		}
+	}
+	return msgs
+}
+
+func disallowPrivileged(manifest map[string]interface{}) []string {
+	var msgs []string
+	for _, c := range containersOf(manifest) {
+		if privileged, _ := nestedMap(c, "securityContext")["privileged"].(bool); privileged {
+			msgs = append(msgs, fmt.Sprintf("container %v is privileged", c["name"]))
+		}
+	}
+	return msgs
+}
+
+// containersOf returns containers and init containers of pods, and of workloads by their pod templates
+func containersOf(manifest map[string]interface{}) []map[string]interface{} {
+	var podSpec map[string]interface{}
+	switch manifest["kind"] {
+	case "Pod":
+		podSpec = nestedMap(manifest, "spec")
+	case "CronJob":
+		podSpec = nestedMap(manifest, "spec", "jobTemplate", "spec", "template", "spec")
+	default:
+		// Deployment, StatefulSet, DaemonSet, Job, Rollout and so on
+		podSpec = nestedMap(manifest, "spec", "template", "spec")
+	}
+
+	var containers []map[string]interface{}
+	for _, field := range []string{"initContainers", "containers"} {
+		list, _ := podSpec[field].([]interface{})
+		for _, item := range list {
+			if c, ok := item.(map[string]interface{}); ok {
+				containers = append(containers, c)
+			}
+		}
+	}
+	return containers
+}
+
+// nestedMap returns the map of fields, nil is returned if any of them does not exist
+func nestedMap(obj map[string]interface{}, fields ...string) map[string]interface{} {
+	for _, field := range fields {
+		obj, _ = obj[field].(map[string]interface{})
+		if obj == nil {
+			return nil
+		}
+	}
+	return obj
+}
@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	manifestpolicyconfig "github.com/horizoncd/horizon/pkg/config/manifestpolicy"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Violation is a resource of rendered manifests which violates a policy
+type Violation struct {
+	Policy  string `json:"policy"`
+	Action  string `json:"action"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+func (v *Violation) String() string {
+	return fmt.Sprintf("policy %s: %s %s: %s", v.Policy, v.Kind, v.Name, v.Message)
+}
+
+type Service interface {
+	// Enabled tells whether any policy is enabled in the environment,
+	// there is no need to render manifests if not
+	Enabled(environment string) bool
+	// Check evaluates rendered manifests deployed to the environment against the policies.
+	// It returns ErrManifestPolicyViolated when policies blocking in the environment are violated,
+	// violations of policies warning in the environment are returned and logged.
+	Check(ctx context.Context, environment string, manifests []map[string]interface{}) ([]*Violation, error)
+}
+
+type service struct {
+	policies []*manifestpolicyconfig.Policy
+}
+
+func NewService(config manifestpolicyconfig.Config) (Service, error) {
+	policies := make([]*manifestpolicyconfig.Policy, 0, len(config.Policies))
+	for _, policy := range config.Policies {
+		if policy == nil {
+			continue
+		}
+		if _, ok := rules[policy.Rule]; !ok {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"rule %s of manifest policy %s is not registered", policy.Rule, policy.Name)
+		}
+		policies = append(policies, policy)
+	}
+	return &service{policies: policies}, nil
+}
+
+func (s *service) Enabled(environment string) bool {
+	for _, policy := range s.policies {
+		if policy.ActionOf(environment) != manifestpolicyconfig.ActionOff {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) Check(ctx context.Context, environment string,
+	manifests []map[string]interface{}) ([]*Violation, error) {
+	violations := make([]*Violation, 0)
+	var blocked []string
+	for _, policy := range s.policies {
+		action := policy.ActionOf(environment)
+		if action == manifestpolicyconfig.ActionOff {
+			continue
+		}
+		for _, manifest := range manifests {
+			for _, msg := range rules[policy.Rule](manifest) {
+				kind, _ := manifest["kind"].(string)
+				name, _ := nestedMap(manifest, "metadata")["name"].(string)
+				violation := &Violation{
+					Policy:  policy.Name,
+					Action:  action,
+					Kind:    kind,
+					Name:    name,
+					Message: msg,
+				}
+				violations = append(violations, violation)
+				if action == manifestpolicyconfig.ActionBlock {
+					blocked = append(blocked, violation.String())
+				} else {
+					log.Warningf(ctx, "manifests violate %s", violation)
+				}
+			}
+		}
+	}
+	if len(blocked) > 0 {
+		return violations, perror.Wrapf(herrors.ErrManifestPolicyViolated,
+			"manifests violate policies in environment %s:\n%s", environment, strings.Join(blocked, "\n"))
+	}
+	return violations, nil
+}
@@ -0,0 +1,148 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestpolicy
+
+import (
+	"context"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	manifestpolicyconfig "github.com/horizoncd/horizon/pkg/config/manifestpolicy"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func deployment(name string, containers ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		},
+	}
+}
+
+func container(name, image string, limited, privileged bool) map[string]interface{} {
+	c := map[string]interface{}{"name": name, "image": image}
+	if limited {
+		c["resources"] = map[string]interface{}{
+			"limits": map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+		}
+	}
+	if privileged {
+		c["securityContext"] = map[string]interface{}{"privileged": true}
+	}
+	return c
+}
+
+func TestRules(t *testing.T) {
+	assert.False(t, usesLatestTag("nginx:1.21"))
+	assert.False(t, usesLatestTag("registry:5000/library/nginx:1.21"))
+	assert.False(t, usesLatestTag("nginx@sha256:0123"))
+	assert.True(t, usesLatestTag("nginx"))
+	assert.True(t, usesLatestTag("nginx:latest"))
+	assert.True(t, usesLatestTag("registry:5000/library/nginx"))
+
+	app := deployment("app", container("app", "nginx:latest", false, true),
+		container("sidecar", "envoy:1.20", true, false))
+	assert.Equal(t, []string{"container app uses image nginx:latest with the latest tag"},
+		disallowLatestTag(app))
+	assert.Equal(t, []string{"container app has no cpu limit", "container app has no memory limit"},
+		requireResourceLimits(app))
+	assert.Equal(t, []string{"container app is privileged"}, disallowPrivileged(app))
+
+	// containers of pods, init containers and cron jobs are checked as well
+	pod := map[string]interface{}{
+		"kind": "Pod",
+		"spec": map[string]interface{}{
+			"initContainers": []interface{}{container("init", "busybox", true, false)},
+		},
+	}
+	assert.Equal(t, []string{"container init uses image busybox with the latest tag"}, disallowLatestTag(pod))
+	cronJob := map[string]interface{}{
+		"kind": "CronJob",
+		"spec": map[string]interface{}{
+			"jobTemplate": map[string]interface{}{
+				"spec": deployment("job", container("job", "busybox:1.35", true, true))["spec"],
+			},
+		},
+	}
+	assert.Equal(t, []string{"container job is privileged"}, disallowPrivileged(cronJob))
+
+	// resources without pods are skipped
+	assert.Nil(t, requireResourceLimits(map[string]interface{}{"kind": "Service"}))
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewService(manifestpolicyconfig.Config{
+		Policies: []*manifestpolicyconfig.Policy{{Name: "unknown", Rule: "unknown"}},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	s, err := NewService(manifestpolicyconfig.Config{})
+	assert.Nil(t, err)
+	assert.False(t, s.Enabled("online"))
+
+	s, err = NewService(manifestpolicyconfig.Config{
+		Policies: []*manifestpolicyconfig.Policy{
+			{
+				Name:         "no-latest-tag",
+				Rule:         RuleDisallowLatestTag,
+				Environments: map[string]string{"online": manifestpolicyconfig.ActionBlock},
+			},
+			{
+				Name:         "no-privileged",
+				Rule:         RuleDisallowPrivileged,
+				Action:       manifestpolicyconfig.ActionBlock,
+				Environments: map[string]string{"test": manifestpolicyconfig.ActionOff},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, s.Enabled("test"))
+
+	manifests := []map[string]interface{}{
+		deployment("app", container("app", "nginx:latest", true, false)),
+		{"kind": "Service", "metadata": map[string]interface{}{"name": "app"}},
+	}
+	// warn by default
+	violations, err := s.Check(ctx, "test", manifests)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Violation{{
+		Policy:  "no-latest-tag",
+		Action:  manifestpolicyconfig.ActionWarn,
+		Kind:    "Deployment",
+		Name:    "app",
+		Message: "container app uses image nginx:latest with the latest tag",
+	}}, violations)
+
+	// blocked in online
+	violations, err = s.Check(ctx, "online", manifests)
+	assert.Equal(t, herrors.ErrManifestPolicyViolated, perror.Cause(err))
+	assert.Equal(t, 1, len(violations))
+	assert.Equal(t, manifestpolicyconfig.ActionBlock, violations[0].Action)
+
+	// privileged pods are blocked except in test
+	manifests = []map[string]interface{}{deployment("app", container("app", "nginx:1.21", true, true))}
+	violations, err = s.Check(ctx, "test", manifests)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(violations))
+	_, err = s.Check(ctx, "dev", manifests)
+	assert.Equal(t, herrors.ErrManifestPolicyViolated, perror.Cause(err))
+}
@@ -47,6 +47,7 @@ type DAO interface {
 	ListResourceOfMemberInfoByRole(ctx context.Context,
 		resourceType models.ResourceType, info uint, role string) ([]uint, error)
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+	ListAll(ctx context.Context) ([]models.Member, error)
 }
 
 var (
@@ -215,3 +216,15 @@ func (d *dao) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Me
 	}
 	return members, nil
 }
+
+func (d *dao) ListAll(ctx context.Context) ([]models.Member, error) {
+	var members []models.Member
+	result := d.db.Model(model).WithContext(ctx).
+		Where("deleted_ts = 0").
+		Order("resource_type, resource_id").
+		Scan(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
@@ -65,6 +65,9 @@ type Manager interface {
 		resourceType models.ResourceType, memberInfo uint, role string) ([]uint, error)
 
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+
+	// ListAll lists the direct members of all the resources
+	ListAll(ctx context.Context) ([]models.Member, error)
 }
 
 type manager struct {
@@ -131,3 +134,7 @@ func (m *manager) ListResourceOfMemberInfoByRole(ctx context.Context,
 func (m *manager) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error) {
 	return m.dao.ListMembersByUserID(ctx, userID)
 }
+
+func (m *manager) ListAll(ctx context.Context) ([]models.Member, error) {
+	return m.dao.ListAll(ctx)
+}
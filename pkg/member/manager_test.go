@@ -140,6 +140,10 @@ func TestList(t *testing.T) {
 	assert.Equal(t, len(members), 2)
 	assert.True(t, MemberValueEqual(&members[0], retMember1))
 	assert.True(t, MemberValueEqual(&members[1], retMember2))
+
+	members, err = mgr.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.True(t, len(members) >= 2)
 }
 
 func TestListResourceOfMemberInfo(t *testing.T) {
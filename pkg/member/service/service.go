@@ -30,10 +30,12 @@ import (
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/member"
 	"github.com/horizoncd/horizon/pkg/member/models"
+	notificationmanager "github.com/horizoncd/horizon/pkg/notification/manager"
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	robotmanager "github.com/horizoncd/horizon/pkg/robot/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	templatereleasemanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -74,6 +76,8 @@ type service struct {
 	oauthManager              oauthmanager.Manager
 	userManager               usermanager.Manager
 	webhookManager            webhookmanager.Manager
+	notificationManager       notificationmanager.Manager
+	robotManager              robotmanager.Manager
 }
 
 func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manager,
@@ -90,6 +94,8 @@ func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manag
 		oauthManager:              oauthManager,
 		userManager:               manager.UserMgr,
 		webhookManager:            manager.WebhookMgr,
+		notificationManager:       manager.NotificationMgr,
+		robotManager:              manager.RobotMgr,
 	}
 }
 
@@ -232,6 +238,38 @@ func (s *service) listWebhookMember(ctx context.Context, id uint) ([]models.Memb
 	}
 }
 
+func (s *service) listNotificationChannelMember(ctx context.Context, id uint) ([]models.Member, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	channel, err := s.notificationManager.GetChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch channel.ResourceType {
+	case common.ResourceGroup, common.ResourceApplication:
+		return s.ListMember(ctx, channel.ResourceType, channel.ResourceID)
+	default:
+		return nil, nil
+	}
+}
+
+func (s *service) listRobotMember(ctx context.Context, id uint) ([]models.Member, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	robot, err := s.robotManager.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch robot.ResourceType {
+	case common.ResourceGroup, common.ResourceApplication:
+		return s.ListMember(ctx, robot.ResourceType, robot.ResourceID)
+	default:
+		return nil, nil
+	}
+}
+
 func (s *service) listWebhookLogMember(ctx context.Context, id uint) ([]models.Member, error) {
 	if id == 0 {
 		return nil, nil
@@ -370,6 +408,10 @@ func (s *service) ListMember(ctx context.Context, resourceType string, resourceI
 		allMembers, err = s.listWebhookMember(ctx, resourceID)
 	case common.ResourceWebhookLog:
 		allMembers, err = s.listWebhookLogMember(ctx, resourceID)
+	case common.ResourceNotificationChannel:
+		allMembers, err = s.listNotificationChannelMember(ctx, resourceID)
+	case common.ResourceRobot:
+		allMembers, err = s.listRobotMember(ctx, resourceID)
 	default:
 		err = errors.New("unsupported resourceType")
 	}
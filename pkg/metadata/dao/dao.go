@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/metadata/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	ListByResource(ctx context.Context, resourceType string, resourceID uint) ([]*models.Metadata, error)
+	ListByResources(ctx context.Context, resourceType string, resourceIDs []uint) ([]*models.Metadata, error)
+	// UpsertByResource replaces the metadata of the resource, keys absent from metadatas are deleted
+	UpsertByResource(ctx context.Context, resourceType string, resourceID uint, metadatas []*models.Metadata) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) ListByResource(ctx context.Context, resourceType string,
+	resourceID uint) ([]*models.Metadata, error) {
+	var metadatas []*models.Metadata
+	result := d.db.WithContext(ctx).Raw(common.MetadataListByResource, resourceType, resourceID).Scan(&metadatas)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.MetadataInDB, result.Error.Error())
+	}
+	return metadatas, nil
+}
+
+func (d *dao) ListByResources(ctx context.Context, resourceType string,
+	resourceIDs []uint) ([]*models.Metadata, error) {
+	var metadatas []*models.Metadata
+	if len(resourceIDs) == 0 {
+		return metadatas, nil
+	}
+	result := d.db.WithContext(ctx).Raw(common.MetadataListByResources, resourceType, resourceIDs).Scan(&metadatas)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.MetadataInDB, result.Error.Error())
+	}
+	return metadatas, nil
+}
+
+func (d *dao) UpsertByResource(ctx context.Context, resourceType string,
+	resourceID uint, metadatas []*models.Metadata) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(metadatas) == 0 {
+			if err := tx.Exec(common.MetadataDeleteAllByResource, resourceType, resourceID).Error; err != nil {
+				return herrors.NewErrDeleteFailed(herrors.MetadataInDB, err.Error())
+			}
+			return nil
+		}
+
+		keys := make([]string, 0, len(metadatas))
+		for _, metadata := range metadatas {
+			keys = append(keys, metadata.Key)
+		}
+		if err := tx.Exec(common.MetadataDeleteByResourceAndKeys, resourceType,
+			resourceID, keys).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.MetadataInDB, err.Error())
+		}
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "resource_type"},
+				{Name: "resource_id"},
+				{Name: "meta_key"},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{"meta_value", "updated_at", "updated_by"}),
+		}).Create(metadatas)
+		if result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.MetadataInDB, result.Error.Error())
+		}
+		return nil
+	})
+}
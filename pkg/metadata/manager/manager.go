@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"sort"
+
+	"github.com/horizoncd/horizon/pkg/metadata/dao"
+	"github.com/horizoncd/horizon/pkg/metadata/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// ListByResource returns the metadata of the resource keyed by field key
+	ListByResource(ctx context.Context, resourceType string, resourceID uint) (map[string]string, error)
+	// ListByResources returns the metadata of the resources keyed by resource id,
+	// resources without metadata are absent
+	ListByResources(ctx context.Context, resourceType string,
+		resourceIDs []uint) (map[uint]map[string]string, error)
+	// UpsertByResource replaces the metadata of the resource, keys absent from values are deleted
+	UpsertByResource(ctx context.Context, resourceType string, resourceID uint, values map[string]string) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) ListByResource(ctx context.Context, resourceType string,
+	resourceID uint) (map[string]string, error) {
+	metadatas, err := m.dao.ListByResource(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(metadatas))
+	for _, metadata := range metadatas {
+		values[metadata.Key] = metadata.Value
+	}
+	return values, nil
+}
+
+func (m *manager) ListByResources(ctx context.Context, resourceType string,
+	resourceIDs []uint) (map[uint]map[string]string, error) {
+	metadatas, err := m.dao.ListByResources(ctx, resourceType, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint]map[string]string)
+	for _, metadata := range metadatas {
+		if _, ok := values[metadata.ResourceID]; !ok {
+			values[metadata.ResourceID] = make(map[string]string)
+		}
+		values[metadata.ResourceID][metadata.Key] = metadata.Value
+	}
+	return values, nil
+}
+
+func (m *manager) UpsertByResource(ctx context.Context, resourceType string,
+	resourceID uint, values map[string]string) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	metadatas := make([]*models.Metadata, 0, len(values))
+	for _, key := range keys {
+		metadatas = append(metadatas, &models.Metadata{
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Key:          key,
+			Value:        values[key],
+		})
+	}
+	return m.dao.UpsertByResource(ctx, resourceType, resourceID, metadatas)
+}
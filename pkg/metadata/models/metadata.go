@@ -0,0 +1,34 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// Metadata is the value of a metadata field of an application or a cluster
+type Metadata struct {
+	ID           uint
+	ResourceType string `gorm:"uniqueIndex:idx_resource_key"`
+	ResourceID   uint   `gorm:"uniqueIndex:idx_resource_key"`
+	Key          string `gorm:"uniqueIndex:idx_resource_key;column:meta_key"`
+	Value        string `gorm:"column:meta_value"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CreatedBy    uint
+	UpdatedBy    uint
+}
+
+func (Metadata) TableName() string {
+	return "tb_resource_metadata"
+}
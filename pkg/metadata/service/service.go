@@ -0,0 +1,222 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	metadataconfig "github.com/horizoncd/horizon/pkg/config/metadata"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	metadatamanager "github.com/horizoncd/horizon/pkg/metadata/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/sets"
+)
+
+// maxValueLength is the length of the value column
+const maxValueLength = 1024
+
+type Service interface {
+	// Fields returns the metadata fields defined for applications and clusters
+	Fields() metadataconfig.Config
+	// Get returns the metadata of an application or a cluster, values of fields no longer defined are dropped
+	Get(ctx context.Context, resourceType string, resourceID uint) (map[string]string, error)
+	// List returns the metadata of applications or clusters keyed by their ids
+	List(ctx context.Context, resourceType string, resourceIDs []uint) (map[uint]map[string]string, error)
+	// Update validates the metadata against the fields defined, and replaces the metadata of the resource.
+	// Empty values are treated as unset
+	Update(ctx context.Context, resourceType string, resourceID uint, values map[string]string) error
+}
+
+type service struct {
+	config      metadataconfig.Config
+	patterns    map[string]*regexp.Regexp
+	metadataMgr metadatamanager.Manager
+}
+
+var _ Service = (*service)(nil)
+
+func NewService(manager *managerparam.Manager, config metadataconfig.Config) (Service, error) {
+	s := &service{
+		config:      config,
+		patterns:    make(map[string]*regexp.Regexp),
+		metadataMgr: manager.MetadataMgr,
+	}
+	for _, resourceType := range []string{common.ResourceApplication, common.ResourceCluster} {
+		fields, _ := s.fields(resourceType)
+		keys := sets.NewString()
+		for _, field := range fields {
+			if field.Key == "" {
+				return nil, fmt.Errorf("metadata field of %s without key", resourceType)
+			}
+			if keys.Has(field.Key) {
+				return nil, fmt.Errorf("duplicate metadata field %s of %s", field.Key, resourceType)
+			}
+			keys.Insert(field.Key)
+
+			switch field.Type {
+			case "", metadataconfig.FieldTypeString, metadataconfig.FieldTypeURL:
+			case metadataconfig.FieldTypeEnum:
+				if len(field.Options) == 0 {
+					return nil, fmt.Errorf("enum metadata field %s of %s without options", field.Key, resourceType)
+				}
+			default:
+				return nil, fmt.Errorf("unsupported type %s of metadata field %s", field.Type, field.Key)
+			}
+			if field.Pattern == "" {
+				continue
+			}
+			pattern, err := regexp.Compile(field.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of metadata field %s of %s: %v",
+					field.Key, resourceType, err)
+			}
+			s.patterns[patternKey(resourceType, field.Key)] = pattern
+		}
+	}
+	return s, nil
+}
+
+func patternKey(resourceType, key string) string {
+	return resourceType + "/" + key
+}
+
+func (s *service) Fields() metadataconfig.Config {
+	return s.config
+}
+
+func (s *service) fields(resourceType string) ([]metadataconfig.Field, error) {
+	switch resourceType {
+	case common.ResourceApplication:
+		return s.config.Application, nil
+	case common.ResourceCluster:
+		return s.config.Cluster, nil
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported resource type %s", resourceType)
+	}
+}
+
+func (s *service) Get(ctx context.Context, resourceType string, resourceID uint) (map[string]string, error) {
+	metadatas, err := s.List(ctx, resourceType, []uint{resourceID})
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := metadatas[resourceID]; ok {
+		return values, nil
+	}
+	return map[string]string{}, nil
+}
+
+func (s *service) List(ctx context.Context, resourceType string,
+	resourceIDs []uint) (map[uint]map[string]string, error) {
+	fields, err := s.fields(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	metadatas, err := s.metadataMgr.ListByResources(ctx, resourceType, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := sets.NewString()
+	for _, field := range fields {
+		defined.Insert(field.Key)
+	}
+	for _, values := range metadatas {
+		for key := range values {
+			if !defined.Has(key) {
+				delete(values, key)
+			}
+		}
+	}
+	return metadatas, nil
+}
+
+func (s *service) Update(ctx context.Context, resourceType string,
+	resourceID uint, values map[string]string) error {
+	fields, err := s.fields(resourceType)
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]string, len(values))
+	for key, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			set[key] = value
+		}
+	}
+	messages := make([]string, 0)
+	defined := sets.NewString()
+	for _, field := range fields {
+		defined.Insert(field.Key)
+		value, ok := set[field.Key]
+		if !ok {
+			if field.Required {
+				messages = append(messages, fmt.Sprintf("%s is required", field.Key))
+			}
+			continue
+		}
+		if message := s.validate(resourceType, field, value); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	undefined := make([]string, 0)
+	for key := range set {
+		if !defined.Has(key) {
+			undefined = append(undefined, key)
+		}
+	}
+	sort.Strings(undefined)
+	for _, key := range undefined {
+		messages = append(messages, fmt.Sprintf("%s is not a defined metadata field", key))
+	}
+	if len(messages) > 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, strings.Join(messages, "; "))
+	}
+
+	return s.metadataMgr.UpsertByResource(ctx, resourceType, resourceID, set)
+}
+
+// validate returns the violation of the value against the field, or empty if it's valid
+func (s *service) validate(resourceType string, field metadataconfig.Field, value string) string {
+	maxLength := maxValueLength
+	if field.MaxLength > 0 && field.MaxLength < maxLength {
+		maxLength = field.MaxLength
+	}
+	if len(value) > maxLength {
+		return fmt.Sprintf("%s must not exceed %d characters", field.Key, maxLength)
+	}
+	switch field.Type {
+	case metadataconfig.FieldTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Sprintf("%s must be an http or https url", field.Key)
+		}
+	case metadataconfig.FieldTypeEnum:
+		if !sets.NewString(field.Options...).Has(value) {
+			return fmt.Sprintf("%s must be one of %s", field.Key, strings.Join(field.Options, ", "))
+		}
+	}
+	if pattern, ok := s.patterns[patternKey(resourceType, field.Key)]; ok && !pattern.MatchString(value) {
+		return fmt.Sprintf("%s must match pattern %s", field.Key, field.Pattern)
+	}
+	return ""
+}
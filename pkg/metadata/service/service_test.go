@@ -0,0 +1,109 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	metadataconfig "github.com/horizoncd/horizon/pkg/config/metadata"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/metadata/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Metadata{}))
+	callbacks.RegisterCustomCallbacks(db)
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	manager := managerparam.InitManager(db)
+
+	_, err := NewService(manager, metadataconfig.Config{
+		Cluster: []metadataconfig.Field{{Key: "tier", Type: metadataconfig.FieldTypeEnum}},
+	})
+	assert.NotNil(t, err)
+	_, err = NewService(manager, metadataconfig.Config{
+		Cluster: []metadataconfig.Field{{Key: "runbookURL"}, {Key: "runbookURL"}},
+	})
+	assert.NotNil(t, err)
+
+	svc, err := NewService(manager, metadataconfig.Config{
+		Application: []metadataconfig.Field{
+			{Key: "tier", Type: metadataconfig.FieldTypeEnum, Options: []string{"p0", "p1"}, Required: true},
+		},
+		Cluster: []metadataconfig.Field{
+			{Key: "runbookURL", Type: metadataconfig.FieldTypeURL},
+			{Key: "oncallChannel", Pattern: "^#[a-z-]+$", MaxLength: 16},
+		},
+	})
+	assert.Nil(t, err)
+
+	// required fields, enum options and undefined keys are validated
+	err = svc.Update(ctx, common.ResourceApplication, 1, map[string]string{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = svc.Update(ctx, common.ResourceApplication, 1, map[string]string{"tier": "p2"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = svc.Update(ctx, common.ResourceApplication, 1, map[string]string{"tier": "p0", "owner": "tony"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	assert.Nil(t, svc.Update(ctx, common.ResourceApplication, 1, map[string]string{"tier": " p0 "}))
+
+	values, err := svc.Get(ctx, common.ResourceApplication, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"tier": "p0"}, values)
+
+	// urls, patterns and max length are validated
+	err = svc.Update(ctx, common.ResourceCluster, 1, map[string]string{"runbookURL": "ftp://wiki.example.com"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = svc.Update(ctx, common.ResourceCluster, 1, map[string]string{"oncallChannel": "horizon"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = svc.Update(ctx, common.ResourceCluster, 1, map[string]string{"oncallChannel": "#horizon-oncall-team"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	assert.Nil(t, svc.Update(ctx, common.ResourceCluster, 1, map[string]string{
+		"runbookURL":    "https://wiki.example.com/runbook",
+		"oncallChannel": "#horizon",
+	}))
+
+	// empty values unset the fields
+	assert.Nil(t, svc.Update(ctx, common.ResourceCluster, 1, map[string]string{
+		"runbookURL":    "https://wiki.example.com/runbook",
+		"oncallChannel": "",
+	}))
+	metadatas, err := svc.List(ctx, common.ResourceCluster, []uint{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[uint]map[string]string{1: {"runbookURL": "https://wiki.example.com/runbook"}}, metadatas)
+
+	// values of fields no longer defined are dropped
+	svc, err = NewService(manager, metadataconfig.Config{
+		Cluster: []metadataconfig.Field{{Key: "oncallChannel"}},
+	})
+	assert.Nil(t, err)
+	values, err = svc.Get(ctx, common.ResourceCluster, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{}, values)
+
+	_, err = svc.Get(ctx, "groups", 1)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
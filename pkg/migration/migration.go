@@ -0,0 +1,288 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migration applies the versioned sql files in db/migrations to the database.
+// The version of a migration is its file name without the extension, such as 20261016_add_token_user_code,
+// migrations are applied in the order of their versions, and each of them is recorded once applied.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	// _lockName is the mysql named lock held while applying, so that servers starting together migrate once
+	_lockName           = "horizon_schema_migration"
+	_lockTimeoutSeconds = 300
+	// _existingTable tells whether the database has been prepared out of band
+	_existingTable = "tb_user"
+)
+
+// Migration is a sql file to apply
+type Migration struct {
+	Version string
+	File    string
+}
+
+// Record is a migration applied
+type Record struct {
+	Version   string    `gorm:"column:version;primaryKey"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (Record) TableName() string {
+	return "tb_schema_migration"
+}
+
+type Status struct {
+	// Current is the version of the latest migration applied, empty if none
+	Current string   `json:"current"`
+	Applied int      `json:"applied"`
+	Pending []string `json:"pending"`
+}
+
+// Load lists the sql files in dir, sorted by version
+func Load(dir string) ([]Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".sql") {
+			continue
+		}
+		version := strings.TrimSuffix(strings.TrimSuffix(file.Name(), ".sql"), ".up")
+		migrations = append(migrations, Migration{
+			Version: version,
+			File:    filepath.Join(dir, file.Name()),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// splitStatements splits the content of a sql file by the semicolons ending lines, comments are dropped
+func splitStatements(content string) []string {
+	var (
+		statements []string
+		current    []string
+	)
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, strings.Join(current, "\n"))
+	}
+	return statements
+}
+
+type Runner struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewRunner(db *gorm.DB, dir string) (*Runner, error) {
+	migrations, err := Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations from %s: %v", dir, err)
+	}
+	return &Runner{db: db, migrations: migrations}, nil
+}
+
+func (r *Runner) applied(ctx context.Context) (map[string]bool, error) {
+	// replicas may not have caught up with the migrations just applied
+	db := r.db.WithContext(orm.WithPrimary(ctx))
+	if !db.Migrator().HasTable(&Record{}) {
+		return map[string]bool{}, nil
+	}
+	var records []Record
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+	return applied, nil
+}
+
+func (r *Runner) Status(ctx context.Context) (*Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Applied: len(applied), Pending: []string{}}
+	for version := range applied {
+		if version > status.Current {
+			status.Current = version
+		}
+	}
+	for _, m := range r.migrations {
+		if !applied[m.Version] {
+			status.Pending = append(status.Pending, m.Version)
+		}
+	}
+	return status, nil
+}
+
+// lock holds the named lock of mysql until the returned func is called, other dialects are not locked
+func (r *Runner) lock(ctx context.Context) (func(), error) {
+	if r.db.Dialector.Name() != "mysql" {
+		return func() {}, nil
+	}
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// the lock belongs to the session, so the same connection is kept until released
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", _lockName, _lockTimeoutSeconds).
+		Scan(&locked); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if locked.Int64 != 1 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to acquire lock %s in %ds, another migration may be running",
+			_lockName, _lockTimeoutSeconds)
+	}
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", _lockName); err != nil {
+			log.Warningf(ctx, "failed to release lock %s: %v", _lockName, err)
+		}
+		_ = conn.Close()
+	}, nil
+}
+
+// Up applies the pending migrations in order, and returns the versions applied.
+// A database prepared out of band must be baselined first, so that the migrations applied are not run again.
+func (r *Runner) Up(ctx context.Context) ([]string, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	db := r.db.WithContext(orm.WithPrimary(ctx))
+	if !db.Migrator().HasTable(&Record{}) {
+		if db.Migrator().HasTable(_existingTable) {
+			return nil, fmt.Errorf("the database has been prepared without migrations, " +
+				"baseline it with the version of the latest migration applied")
+		}
+		if err := db.Migrator().CreateTable(&Record{}); err != nil {
+			return nil, err
+		}
+	}
+	// the migrations may be applied by another server while waiting for the lock
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, m := range r.migrations {
+		if applied[m.Version] {
+			continue
+		}
+		content, err := ioutil.ReadFile(m.File)
+		if err != nil {
+			return versions, err
+		}
+		log.Infof(ctx, "applying migration %s", m.Version)
+		// ddl of mysql is committed implicitly, a failed migration must be fixed by hand before retrying
+		for i, statement := range splitStatements(string(content)) {
+			if err := db.Exec(statement).Error; err != nil {
+				return versions, fmt.Errorf("failed to apply statement %d of migration %s: %v", i+1, m.Version, err)
+			}
+		}
+		if err := db.Create(&Record{Version: m.Version, AppliedAt: time.Now()}).Error; err != nil {
+			return versions, err
+		}
+		versions = append(versions, m.Version)
+	}
+	return versions, nil
+}
+
+// Baseline records the migrations up to the version as applied without running them,
+// it's used to adopt the databases prepared out of band
+func (r *Runner) Baseline(ctx context.Context, version string) ([]string, error) {
+	found := false
+	for _, m := range r.migrations {
+		if m.Version == version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("migration %s not found", version)
+	}
+
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	db := r.db.WithContext(orm.WithPrimary(ctx))
+	if !db.Migrator().HasTable(&Record{}) {
+		if err := db.Migrator().CreateTable(&Record{}); err != nil {
+			return nil, err
+		}
+	}
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, m := range r.migrations {
+		if m.Version > version {
+			break
+		}
+		if applied[m.Version] {
+			continue
+		}
+		if err := db.Create(&Record{Version: m.Version, AppliedAt: time.Now()}).Error; err != nil {
+			return versions, err
+		}
+		versions = append(versions, m.Version)
+	}
+	return versions, nil
+}
@@ -0,0 +1,130 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+)
+
+func writeMigrations(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`-- comment
+CREATE TABLE t1
+(
+    id integer
+);
+
+ALTER TABLE t1 ADD COLUMN name varchar(10);
+UPDATE t1 SET name = 'a;b'`)
+	assert.Equal(t, []string{
+		"CREATE TABLE t1\n(\n    id integer\n);",
+		"ALTER TABLE t1 ADD COLUMN name varchar(10);",
+		"UPDATE t1 SET name = 'a;b'",
+	}, statements)
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "migrations")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	writeMigrations(t, dir, map[string]string{
+		"20210908_initial_schema.up.sql": "CREATE TABLE t1 (id integer);",
+		"20220124_add_name.sql": "ALTER TABLE t1 ADD COLUMN name varchar(10);\n" +
+			"INSERT INTO t1 (id, name) VALUES (1, 'a');",
+		"README.md": "not a migration",
+	})
+
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	runner, err := NewRunner(db, dir)
+	assert.Nil(t, err)
+
+	status, err := runner.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "", status.Current)
+	assert.Equal(t, []string{"20210908_initial_schema", "20220124_add_name"}, status.Pending)
+
+	versions, err := runner.Up(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"20210908_initial_schema", "20220124_add_name"}, versions)
+	var name string
+	assert.Nil(t, db.Raw("SELECT name FROM t1 WHERE id = 1").Scan(&name).Error)
+	assert.Equal(t, "a", name)
+
+	// nothing is applied twice
+	versions, err = runner.Up(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, versions)
+
+	writeMigrations(t, dir, map[string]string{"20230302_add_t2.sql": "CREATE TABLE t2 (id integer);"})
+	runner, err = NewRunner(db, dir)
+	assert.Nil(t, err)
+	status, err = runner.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "20220124_add_name", status.Current)
+	assert.Equal(t, 2, status.Applied)
+	assert.Equal(t, []string{"20230302_add_t2"}, status.Pending)
+	versions, err = runner.Up(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"20230302_add_t2"}, versions)
+}
+
+func TestBaseline(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "migrations")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	writeMigrations(t, dir, map[string]string{
+		"20210908_initial_schema.up.sql": "CREATE TABLE tb_user (id integer);",
+		"20220124_add_name.sql":          "ALTER TABLE tb_user ADD COLUMN name varchar(10);",
+	})
+
+	// the database prepared out of band
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.Exec("CREATE TABLE tb_user (id integer)").Error)
+	runner, err := NewRunner(db, dir)
+	assert.Nil(t, err)
+	_, err = runner.Up(ctx)
+	assert.NotNil(t, err)
+
+	_, err = runner.Baseline(ctx, "20200101_not_exist")
+	assert.NotNil(t, err)
+	versions, err := runner.Baseline(ctx, "20210908_initial_schema")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"20210908_initial_schema"}, versions)
+
+	versions, err = runner.Up(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"20220124_add_name"}, versions)
+	status, err := runner.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "20220124_add_name", status.Current)
+	assert.Empty(t, status.Pending)
+}
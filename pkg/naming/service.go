@@ -0,0 +1,188 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package naming
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	namingconfig "github.com/horizoncd/horizon/pkg/config/naming"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+type Kind string
+
+const (
+	KindApplication Kind = "application"
+	KindCluster     Kind = "cluster"
+	KindNamespace   Kind = "namespace"
+)
+
+const (
+	RulePrefix    = "prefix"
+	RulePattern   = "pattern"
+	RuleMaxLength = "maxLength"
+	RuleUnique    = "unique"
+)
+
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type Service interface {
+	// Policies returns the naming policies of all kinds
+	Policies() namingconfig.Config
+	// Preview reports all violations of the name against the policy of the kind
+	Preview(ctx context.Context, kind Kind, name string) ([]Violation, error)
+	// Validate returns an error if the name violates the policy of the kind
+	Validate(ctx context.Context, kind Kind, name string) error
+}
+
+type service struct {
+	config         namingconfig.Config
+	patterns       map[Kind]*regexp.Regexp
+	applicationMgr appmanager.Manager
+	clusterMgr     clustermanager.Manager
+}
+
+func NewService(manager *managerparam.Manager, config namingconfig.Config) (Service, error) {
+	s := &service{
+		config:         config,
+		patterns:       make(map[Kind]*regexp.Regexp),
+		applicationMgr: manager.ApplicationMgr,
+		clusterMgr:     manager.ClusterMgr,
+	}
+	for _, kind := range []Kind{KindApplication, KindCluster, KindNamespace} {
+		policy, _ := s.policy(kind)
+		if policy.Pattern == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(policy.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid naming pattern for %s: %v", kind, err)
+		}
+		s.patterns[kind] = pattern
+	}
+	return s, nil
+}
+
+func (s *service) Policies() namingconfig.Config {
+	return s.config
+}
+
+func (s *service) policy(kind Kind) (namingconfig.Policy, error) {
+	switch kind {
+	case KindApplication:
+		return s.config.Application, nil
+	case KindCluster:
+		return s.config.Cluster, nil
+	case KindNamespace:
+		return s.config.Namespace, nil
+	default:
+		return namingconfig.Policy{}, perror.Wrapf(herrors.ErrParamInvalid, "unsupported kind %s", kind)
+	}
+}
+
+func (s *service) Preview(ctx context.Context, kind Kind, name string) ([]Violation, error) {
+	policy, err := s.policy(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0)
+	if policy.Prefix != "" && !strings.HasPrefix(name, policy.Prefix) {
+		violations = append(violations, Violation{
+			Rule:    RulePrefix,
+			Message: fmt.Sprintf("%s name must start with %s", kind, policy.Prefix),
+		})
+	}
+	if pattern, ok := s.patterns[kind]; ok && !pattern.MatchString(name) {
+		violations = append(violations, Violation{
+			Rule:    RulePattern,
+			Message: fmt.Sprintf("%s name must match pattern %s", kind, policy.Pattern),
+		})
+	}
+	if policy.MaxLength > 0 && len(name) > policy.MaxLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("%s name must not exceed %d characters", kind, policy.MaxLength),
+		})
+	}
+
+	if kind == KindNamespace {
+		return violations, nil
+	}
+	kinds := []Kind{kind}
+	if policy.UniqueScope == namingconfig.UniqueScopeGlobal {
+		kinds = []Kind{KindApplication, KindCluster}
+	}
+	for _, k := range kinds {
+		exists, err := s.exists(ctx, k, name)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			violations = append(violations, Violation{
+				Rule:    RuleUnique,
+				Message: fmt.Sprintf("a %s with the same name already exists", k),
+			})
+		}
+	}
+	return violations, nil
+}
+
+func (s *service) Validate(ctx context.Context, kind Kind, name string) error {
+	violations, err := s.Preview(ctx, kind, name)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(violations))
+	conflict := false
+	for _, violation := range violations {
+		messages = append(messages, violation.Message)
+		if violation.Rule == RuleUnique {
+			conflict = true
+		}
+	}
+	if conflict {
+		return perror.Wrap(herrors.ErrNameConflict, strings.Join(messages, "; "))
+	}
+	return perror.Wrap(herrors.ErrParamInvalid, strings.Join(messages, "; "))
+}
+
+func (s *service) exists(ctx context.Context, kind Kind, name string) (bool, error) {
+	if kind == KindCluster {
+		return s.clusterMgr.CheckClusterExists(ctx, name)
+	}
+	_, err := s.applicationMgr.GetByName(ctx, name)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
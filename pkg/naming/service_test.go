@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package naming
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	namingconfig "github.com/horizoncd/horizon/pkg/config/naming"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}))
+	callbacks.RegisterCustomCallbacks(db)
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	assert.Nil(t, db.Create(&appmodels.Application{Name: "hz-app"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Name: "hz-cluster"}).Error)
+
+	_, err := NewService(managerparam.InitManager(db), namingconfig.Config{
+		Application: namingconfig.Policy{Pattern: "("},
+	})
+	assert.NotNil(t, err)
+
+	svc, err := NewService(managerparam.InitManager(db), namingconfig.Config{
+		Application: namingconfig.Policy{
+			Prefix:    "hz-",
+			Pattern:   "^[a-z-]+$",
+			MaxLength: 10,
+		},
+		Cluster: namingconfig.Policy{
+			UniqueScope: namingconfig.UniqueScopeGlobal,
+		},
+		Namespace: namingconfig.Policy{
+			MaxLength: 5,
+		},
+	})
+	assert.Nil(t, err)
+
+	violations, err := svc.Preview(ctx, KindApplication, "app-1-too-long")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{RulePrefix, RulePattern, RuleMaxLength}, rules(violations))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(svc.Validate(ctx, KindApplication, "app-1-too-long")))
+
+	violations, err = svc.Preview(ctx, KindApplication, "hz-app")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{RuleUnique}, rules(violations))
+	assert.Nil(t, svc.Validate(ctx, KindApplication, "hz-app-b"))
+
+	// cluster names are unique among applications and clusters
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(svc.Validate(ctx, KindCluster, "hz-app")))
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(svc.Validate(ctx, KindCluster, "hz-cluster")))
+	assert.Nil(t, svc.Validate(ctx, KindCluster, "hz-cluster-b"))
+
+	assert.Nil(t, svc.Validate(ctx, KindNamespace, "test"))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(svc.Validate(ctx, KindNamespace, "test-1")))
+
+	_, err = svc.Preview(ctx, Kind("group"), "hz")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func rules(violations []Violation) []string {
+	res := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		res = append(res, violation.Rule)
+	}
+	return res
+}
@@ -0,0 +1,185 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+)
+
+type DAO interface {
+	CreateChannel(ctx context.Context, channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	GetChannel(ctx context.Context, id uint) (*models.NotificationChannel, error)
+	ListChannelsOfResources(ctx context.Context,
+		resources map[string][]uint) ([]*models.NotificationChannel, error)
+	UpdateChannel(ctx context.Context, id uint,
+		channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	DeleteChannel(ctx context.Context, id uint) error
+	GetSubscription(ctx context.Context, id uint) (*models.NotificationSubscription, error)
+	ListSubscriptionsByUser(ctx context.Context, userID uint) ([]*models.NotificationSubscription, error)
+	ListSubscriptionsOfResources(ctx context.Context,
+		resources map[string][]uint) ([]*models.NotificationSubscription, error)
+	UpsertSubscription(ctx context.Context,
+		subscription *models.NotificationSubscription) (*models.NotificationSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+}
+
+type dao struct{ db *gorm.DB }
+
+// NewDAO returns an instance of the default DAO
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+// whereResources matches the records belonging to any of the resources
+func (d *dao) whereResources(resources map[string][]uint) *gorm.DB {
+	var condition *gorm.DB
+	for resourceType, resourceIDs := range resources {
+		subCondition := d.db.Where("resource_type = ?", resourceType).
+			Where("resource_id in ?", resourceIDs)
+		if condition != nil {
+			condition = condition.Or(subCondition)
+		} else {
+			condition = subCondition
+		}
+	}
+	return condition
+}
+
+func (d *dao) CreateChannel(ctx context.Context,
+	channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	if result := d.db.WithContext(ctx).Create(channel); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.NotificationChannelInDB, result.Error.Error())
+	}
+	return channel, nil
+}
+
+func (d *dao) GetChannel(ctx context.Context, id uint) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	if result := d.db.WithContext(ctx).First(&channel, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.NotificationChannelInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.NotificationChannelInDB, result.Error.Error())
+	}
+	return &channel, nil
+}
+
+func (d *dao) ListChannelsOfResources(ctx context.Context,
+	resources map[string][]uint) ([]*models.NotificationChannel, error) {
+	var channels []*models.NotificationChannel
+	if len(resources) == 0 {
+		return channels, nil
+	}
+	if result := d.db.WithContext(ctx).Where(d.whereResources(resources)).
+		Order("id asc").Find(&channels); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.NotificationChannelInDB, result.Error.Error())
+	}
+	return channels, nil
+}
+
+func (d *dao) UpdateChannel(ctx context.Context, id uint,
+	channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	if result := d.db.WithContext(ctx).Where("id = ?", id).
+		Select("name", "url", "secret", "triggers", "enabled", "updated_by").
+		Updates(channel); result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.NotificationChannelInDB, result.Error.Error())
+	}
+	return channel, nil
+}
+
+func (d *dao) DeleteChannel(ctx context.Context, id uint) error {
+	if result := d.db.WithContext(ctx).Delete(&models.NotificationChannel{}, id); result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.NotificationChannelInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) GetSubscription(ctx context.Context, id uint) (*models.NotificationSubscription, error) {
+	var subscription models.NotificationSubscription
+	if result := d.db.WithContext(ctx).First(&subscription, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.NotificationSubscriptionInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.NotificationSubscriptionInDB, result.Error.Error())
+	}
+	return &subscription, nil
+}
+
+func (d *dao) ListSubscriptionsByUser(ctx context.Context,
+	userID uint) ([]*models.NotificationSubscription, error) {
+	var subscriptions []*models.NotificationSubscription
+	if result := d.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("id asc").Find(&subscriptions); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.NotificationSubscriptionInDB, result.Error.Error())
+	}
+	return subscriptions, nil
+}
+
+func (d *dao) ListSubscriptionsOfResources(ctx context.Context,
+	resources map[string][]uint) ([]*models.NotificationSubscription, error) {
+	var subscriptions []*models.NotificationSubscription
+	if len(resources) == 0 {
+		return subscriptions, nil
+	}
+	if result := d.db.WithContext(ctx).Where(d.whereResources(resources)).
+		Order("id asc").Find(&subscriptions); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.NotificationSubscriptionInDB, result.Error.Error())
+	}
+	return subscriptions, nil
+}
+
+// UpsertSubscription updates the triggers if the user has subscribed the resource, or creates the subscription
+func (d *dao) UpsertSubscription(ctx context.Context,
+	subscription *models.NotificationSubscription) (*models.NotificationSubscription, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.NotificationSubscription
+		result := tx.Where("user_id = ?", subscription.UserID).
+			Where("resource_type = ?", subscription.ResourceType).
+			Where("resource_id = ?", subscription.ResourceID).
+			Limit(1).Find(&existing)
+		if result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.NotificationSubscriptionInDB, result.Error.Error())
+		}
+		if result.RowsAffected == 0 {
+			if err := tx.Create(subscription).Error; err != nil {
+				return herrors.NewErrInsertFailed(herrors.NotificationSubscriptionInDB, err.Error())
+			}
+			return nil
+		}
+		existing.Triggers = subscription.Triggers
+		if err := tx.Model(&existing).Select("triggers").Updates(&existing).Error; err != nil {
+			return herrors.NewErrUpdateFailed(herrors.NotificationSubscriptionInDB, err.Error())
+		}
+		*subscription = existing
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (d *dao) DeleteSubscription(ctx context.Context, id uint) error {
+	if result := d.db.WithContext(ctx).
+		Delete(&models.NotificationSubscription{}, id); result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.NotificationSubscriptionInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/notification/dao"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	CreateChannel(ctx context.Context, channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	GetChannel(ctx context.Context, id uint) (*models.NotificationChannel, error)
+	// ListChannelsOfResources lists the channels belonging to any of the resources
+	ListChannelsOfResources(ctx context.Context,
+		resources map[string][]uint) ([]*models.NotificationChannel, error)
+	UpdateChannel(ctx context.Context, id uint,
+		channel *models.NotificationChannel) (*models.NotificationChannel, error)
+	DeleteChannel(ctx context.Context, id uint) error
+	GetSubscription(ctx context.Context, id uint) (*models.NotificationSubscription, error)
+	ListSubscriptionsByUser(ctx context.Context, userID uint) ([]*models.NotificationSubscription, error)
+	// ListSubscriptionsOfResources lists the subscriptions of any of the resources
+	ListSubscriptionsOfResources(ctx context.Context,
+		resources map[string][]uint) ([]*models.NotificationSubscription, error)
+	// UpsertSubscription creates the subscription, or updates its triggers if the user has subscribed the resource
+	UpsertSubscription(ctx context.Context,
+		subscription *models.NotificationSubscription) (*models.NotificationSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) CreateChannel(ctx context.Context,
+	channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	const op = "notification manager: create channel"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.CreateChannel(ctx, channel)
+}
+
+func (m *manager) GetChannel(ctx context.Context, id uint) (*models.NotificationChannel, error) {
+	const op = "notification manager: get channel"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetChannel(ctx, id)
+}
+
+func (m *manager) ListChannelsOfResources(ctx context.Context,
+	resources map[string][]uint) ([]*models.NotificationChannel, error) {
+	const op = "notification manager: list channels of resources"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListChannelsOfResources(ctx, resources)
+}
+
+func (m *manager) UpdateChannel(ctx context.Context, id uint,
+	channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	const op = "notification manager: update channel"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateChannel(ctx, id, channel)
+}
+
+func (m *manager) DeleteChannel(ctx context.Context, id uint) error {
+	const op = "notification manager: delete channel"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteChannel(ctx, id)
+}
+
+func (m *manager) GetSubscription(ctx context.Context, id uint) (*models.NotificationSubscription, error) {
+	const op = "notification manager: get subscription"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetSubscription(ctx, id)
+}
+
+func (m *manager) ListSubscriptionsByUser(ctx context.Context,
+	userID uint) ([]*models.NotificationSubscription, error) {
+	const op = "notification manager: list subscriptions by user"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListSubscriptionsByUser(ctx, userID)
+}
+
+func (m *manager) ListSubscriptionsOfResources(ctx context.Context,
+	resources map[string][]uint) ([]*models.NotificationSubscription, error) {
+	const op = "notification manager: list subscriptions of resources"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListSubscriptionsOfResources(ctx, resources)
+}
+
+func (m *manager) UpsertSubscription(ctx context.Context,
+	subscription *models.NotificationSubscription) (*models.NotificationSubscription, error) {
+	const op = "notification manager: upsert subscription"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpsertSubscription(ctx, subscription)
+}
+
+func (m *manager) DeleteSubscription(ctx context.Context, id uint) error {
+	const op = "notification manager: delete subscription"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteSubscription(ctx, id)
+}
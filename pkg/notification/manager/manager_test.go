@@ -0,0 +1,112 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.NotificationChannel{}, &models.NotificationSubscription{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestChannel(t *testing.T) {
+	for _, channel := range []*models.NotificationChannel{
+		{ResourceType: common.ResourceGroup, ResourceID: 1, Name: "group", Type: models.ChannelSlack,
+			URL: "https://hooks.slack.com/services/1", Triggers: models.DeployFailed, Enabled: true},
+		{ResourceType: common.ResourceApplication, ResourceID: 2, Name: "app", Type: models.ChannelDingTalk,
+			URL: "https://oapi.dingtalk.com/robot/send?access_token=1", Triggers: models.DeployStarted},
+		{ResourceType: common.ResourceApplication, ResourceID: 3, Name: "other", Type: models.ChannelEmail,
+			URL: "a@noreply.com", Triggers: models.DeployStarted},
+	} {
+		_, err := mgr.CreateChannel(ctx, channel)
+		assert.Nil(t, err)
+	}
+
+	channels, err := mgr.ListChannelsOfResources(ctx, map[string][]uint{
+		common.ResourceGroup:       {1},
+		common.ResourceApplication: {2},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(channels))
+	assert.Equal(t, "group", channels[0].Name)
+	assert.Equal(t, "app", channels[1].Name)
+
+	channel := channels[1]
+	channel.Enabled = true
+	channel.Triggers = models.DeploySucceeded
+	_, err = mgr.UpdateChannel(ctx, channel.ID, channel)
+	assert.Nil(t, err)
+	channel, err = mgr.GetChannel(ctx, channel.ID)
+	assert.Nil(t, err)
+	assert.True(t, channel.Enabled)
+	assert.Equal(t, models.DeploySucceeded, channel.Triggers)
+
+	assert.Nil(t, mgr.DeleteChannel(ctx, channel.ID))
+	_, err = mgr.GetChannel(ctx, channel.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestSubscription(t *testing.T) {
+	subscription, err := mgr.UpsertSubscription(ctx, &models.NotificationSubscription{
+		UserID: 1, ResourceType: common.ResourceApplication, ResourceID: 1, Triggers: models.DeployFailed,
+	})
+	assert.Nil(t, err)
+	// the same resource is subscribed once
+	updated, err := mgr.UpsertSubscription(ctx, &models.NotificationSubscription{
+		UserID: 1, ResourceType: common.ResourceApplication, ResourceID: 1, Triggers: models.AutoFreeWarning,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, subscription.ID, updated.ID)
+	_, err = mgr.UpsertSubscription(ctx, &models.NotificationSubscription{
+		UserID: 2, ResourceType: common.ResourceApplication, ResourceID: 1, Triggers: models.DeployFailed,
+	})
+	assert.Nil(t, err)
+
+	subscriptions, err := mgr.ListSubscriptionsByUser(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(subscriptions))
+	assert.Equal(t, models.AutoFreeWarning, subscriptions[0].Triggers)
+
+	subscriptions, err = mgr.ListSubscriptionsOfResources(ctx, map[string][]uint{
+		common.ResourceApplication: {1},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(subscriptions))
+
+	assert.Nil(t, mgr.DeleteSubscription(ctx, subscription.ID))
+	_, err = mgr.GetSubscription(ctx, subscription.ID)
+	assert.NotNil(t, err)
+}
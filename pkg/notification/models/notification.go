@@ -0,0 +1,98 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// types of the channels notifications are sent through
+const (
+	ChannelEmail    = "email"
+	ChannelSlack    = "slack"
+	ChannelDingTalk = "dingtalk"
+)
+
+// ChannelTypes are the types of channels that can be created
+var ChannelTypes = []string{ChannelEmail, ChannelSlack, ChannelDingTalk}
+
+// kinds of the notifications
+const (
+	DeployStarted   = "deploy_started"
+	DeploySucceeded = "deploy_succeeded"
+	DeployFailed    = "deploy_failed"
+	AutoFreeWarning = "autofree_warning"
+	QuotaWarning    = "quota_warning"
+)
+
+// Kinds are all the kinds of the notifications
+var Kinds = []string{DeployStarted, DeploySucceeded, DeployFailed, AutoFreeWarning, QuotaWarning}
+
+// NotificationChannel sends the notifications of the clusters under a group or an application
+type NotificationChannel struct {
+	ID           uint
+	ResourceType string
+	ResourceID   uint
+	Name         string
+	Type         string
+	// URL is the incoming webhook of slack or the robot of dingtalk, or the comma separated addresses of email
+	URL string
+	// Secret signs the requests to the dingtalk robot
+	Secret string
+	// Triggers are the comma separated kinds of the notifications to send
+	Triggers  string
+	Enabled   bool
+	CreatedAt time.Time
+	CreatedBy uint
+	UpdatedAt time.Time
+	UpdatedBy uint
+}
+
+// NotificationSubscription is the preference of a user to receive the notifications of a resource by email
+type NotificationSubscription struct {
+	ID           uint
+	UserID       uint
+	ResourceType string
+	ResourceID   uint
+	// Triggers are the comma separated kinds of the notifications to receive
+	Triggers  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TriggerSeparator separates the kinds of notifications in triggers
+const TriggerSeparator = ","
+
+func SplitTriggers(triggers string) []string {
+	if triggers == "" {
+		return []string{}
+	}
+	return strings.Split(triggers, TriggerSeparator)
+}
+
+func JoinTriggers(kinds []string) string {
+	return strings.Join(kinds, TriggerSeparator)
+}
+
+// HasTrigger tells whether the kind of notifications is one of the triggers
+func HasTrigger(triggers, kind string) bool {
+	for _, trigger := range SplitTriggers(triggers) {
+		if trigger == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sender
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+)
+
+type dingTalkSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewDingTalkSender sends messages in markdown to the robots of dingtalk,
+// the requests are signed if the channel has a secret
+func NewDingTalkSender(client *http.Client) Sender {
+	return &dingTalkSender{client: client, now: time.Now}
+}
+
+type dingTalkResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// sign signs the timestamp in milliseconds by the secret of the robot
+func sign(timestamp int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d\n%s", timestamp, secret)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *dingTalkSender) Send(ctx context.Context, channel *models.NotificationChannel, message *Message) error {
+	robotURL := channel.URL
+	if channel.Secret != "" {
+		u, err := url.Parse(channel.URL)
+		if err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid url of dingtalk robot: %v", err)
+		}
+		timestamp := s.now().UnixNano() / int64(time.Millisecond)
+		query := u.Query()
+		query.Set("timestamp", strconv.FormatInt(timestamp, 10))
+		query.Set("sign", sign(timestamp, channel.Secret))
+		u.RawQuery = query.Encode()
+		robotURL = u.String()
+	}
+
+	body, err := postJSON(ctx, s.client, robotURL, map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": message.Title,
+			// lines are not broken by single line breaks in markdown
+			"text": fmt.Sprintf("### %s\n\n%s", message.Title, strings.ReplaceAll(message.Content, "\n", "\n\n")),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// dingtalk responds 200 with an error code
+	var resp dingTalkResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "invalid response: %s", string(body))
+	}
+	if resp.ErrCode != 0 {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"dingtalk responded %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
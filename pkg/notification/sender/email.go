@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/config/notification"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+)
+
+// AddressSeparator separates the addresses of email channels
+const AddressSeparator = ","
+
+type emailSender struct {
+	config   notification.SMTP
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailSender sends messages in plain text by smtp to the addresses of the channel
+func NewEmailSender(config notification.SMTP) Sender {
+	return &emailSender{config: config, sendMail: smtp.SendMail}
+}
+
+// SplitAddresses splits the addresses of an email channel
+func SplitAddresses(addresses string) []string {
+	var result []string
+	for _, address := range strings.Split(addresses, AddressSeparator) {
+		if address = strings.TrimSpace(address); address != "" {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+func buildEmail(from string, to []string, message *Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Title))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(message.Content, "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+func (s *emailSender) Send(ctx context.Context, channel *models.NotificationChannel, message *Message) error {
+	to := SplitAddresses(channel.URL)
+	if len(to) == 0 {
+		return perror.Wrapf(herrors.ErrParamInvalid, "no address of channel %s", channel.Name)
+	}
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	if err := s.sendMail(addr, auth, s.config.From, to, buildEmail(s.config.From, to, message)); err != nil {
+		return perror.Wrap(err, "failed to send email")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sender sends the notifications through the channels, such as email, slack and dingtalk.
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+)
+
+// Message is a notification rendered
+type Message struct {
+	Title   string
+	Content string
+}
+
+// Sender sends messages through the channels of a type
+type Sender interface {
+	Send(ctx context.Context, channel *models.NotificationChannel, message *Message) error
+}
+
+// postJSON posts the body to the url and returns the response body if it responds 200
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json;charset=utf-8")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"responded %s: %s", resp.Status, string(respBody))
+	}
+	return respBody, nil
+}
@@ -0,0 +1,128 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/config/notification"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+)
+
+var message = &Message{Title: "demo-dev deployed", Content: "Cluster: demo-dev\nStatus: ok"}
+
+func TestSlack(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		assert.Nil(t, json.Unmarshal(data, &body))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	s := NewSlackSender(server.Client())
+	assert.Nil(t, s.Send(context.Background(), &models.NotificationChannel{URL: server.URL}, message))
+	assert.Equal(t, "*demo-dev deployed*\nCluster: demo-dev\nStatus: ok", body["text"])
+
+	// not found
+	assert.NotNil(t, s.Send(context.Background(), &models.NotificationChannel{URL: server.URL + "/404"}, message))
+}
+
+type robotQuery struct {
+	accessToken string
+	timestamp   string
+	sign        string
+}
+
+func TestDingTalk(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var (
+		query robotQuery
+		body  struct {
+			MsgType  string            `json:"msgtype"`
+			Markdown map[string]string `json:"markdown"`
+		}
+		errCode int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = robotQuery{r.URL.Query().Get("access_token"), r.URL.Query().Get("timestamp"), r.URL.Query().Get("sign")}
+		data, _ := ioutil.ReadAll(r.Body)
+		assert.Nil(t, json.Unmarshal(data, &body))
+		_ = json.NewEncoder(w).Encode(dingTalkResponse{ErrCode: errCode, ErrMsg: "sign not match"})
+	}))
+	defer server.Close()
+
+	s := &dingTalkSender{client: server.Client(), now: func() time.Time { return now }}
+	channel := &models.NotificationChannel{URL: server.URL + "/robot/send?access_token=token", Secret: "secret"}
+	assert.Nil(t, s.Send(context.Background(), channel, message))
+	assert.Equal(t, "token", query.accessToken)
+	assert.Equal(t, "1700000000000", query.timestamp)
+	assert.Equal(t, sign(1700000000000, "secret"), query.sign)
+	assert.Equal(t, "markdown", body.MsgType)
+	assert.Equal(t, "demo-dev deployed", body.Markdown["title"])
+	assert.True(t, strings.HasPrefix(body.Markdown["text"], "### demo-dev deployed\n\n"))
+
+	// not signed without secret
+	channel.Secret = ""
+	assert.Nil(t, s.Send(context.Background(), channel, message))
+	assert.Equal(t, "", query.sign)
+
+	// errors are responded with 200
+	errCode = 310000
+	err := s.Send(context.Background(), channel, message)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "sign not match")
+}
+
+func TestSign(t *testing.T) {
+	// HmacSHA256 of "1700000000000\nsecret" with key "secret"
+	assert.Equal(t, "OuzzJR5+xZ4/EYwqtNt6sMYZQMTa/HEGvc9miJe7XzY=", sign(1700000000000, "secret"))
+}
+
+func TestEmail(t *testing.T) {
+	var (
+		sentTo  []string
+		sentMsg string
+	)
+	s := &emailSender{
+		config: notification.SMTP{Host: "smtp.example.com", Port: 25, From: "horizon@example.com"},
+		sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			assert.Equal(t, "smtp.example.com:25", addr)
+			assert.Nil(t, a)
+			assert.Equal(t, "horizon@example.com", from)
+			sentTo, sentMsg = to, string(msg)
+			return nil
+		},
+	}
+	assert.Nil(t, s.Send(context.Background(), &models.NotificationChannel{
+		URL: "a@example.com, b@example.com,",
+	}, message))
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, sentTo)
+	assert.Contains(t, sentMsg, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, sentMsg, "Subject: demo-dev deployed\r\n")
+	assert.True(t, strings.HasSuffix(sentMsg, "\r\n\r\nCluster: demo-dev\r\nStatus: ok"))
+
+	assert.NotNil(t, s.Send(context.Background(), &models.NotificationChannel{URL: " "}, message))
+}
@@ -0,0 +1,39 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sender
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/horizoncd/horizon/pkg/notification/models"
+)
+
+type slackSender struct {
+	client *http.Client
+}
+
+// NewSlackSender sends messages to the incoming webhooks of slack
+func NewSlackSender(client *http.Client) Sender {
+	return &slackSender{client: client}
+}
+
+func (s *slackSender) Send(ctx context.Context, channel *models.NotificationChannel, message *Message) error {
+	_, err := postJSON(ctx, s.client, channel.URL, map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", message.Title, message.Content),
+	})
+	return err
+}
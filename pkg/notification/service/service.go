@@ -0,0 +1,369 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package service sends the notifications of deploys, autofree warnings and quota warnings through the channels
+// configured on applications and groups, and by email to the users subscribing them.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/notification"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	notificationmanager "github.com/horizoncd/horizon/pkg/notification/manager"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+	"github.com/horizoncd/horizon/pkg/notification/sender"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type Service interface {
+	// RegisterSender plugs in the sender of a type of channels, the sender registered before is replaced
+	RegisterSender(channelType string, s sender.Sender)
+	// Handle sends the notifications of the events, it's subscribed to the events recorded
+	Handle(ctx context.Context, events []*eventmodels.Event) error
+}
+
+type service struct {
+	config    notification.Config
+	templates map[string]*messageTemplate
+
+	sendersLock sync.RWMutex
+	senders     map[string]sender.Sender
+
+	notificationMgr notificationmanager.Manager
+	clusterMgr      clustermanager.Manager
+	applicationMgr  applicationmanager.Manager
+	groupMgr        groupmanager.Manager
+	prMgr           *prmanager.PRManager
+	userMgr         usermanager.Manager
+}
+
+// NewService registers the senders of slack and dingtalk, and the sender of email if smtp is configured
+func NewService(manager *managerparam.Manager, config notification.Config) (Service, error) {
+	templates, err := parseTemplates(config.Templates)
+	if err != nil {
+		return nil, err
+	}
+	s := &service{
+		config:          config,
+		templates:       templates,
+		senders:         make(map[string]sender.Sender),
+		notificationMgr: manager.NotificationMgr,
+		clusterMgr:      manager.ClusterMgr,
+		applicationMgr:  manager.ApplicationMgr,
+		groupMgr:        manager.GroupMgr,
+		prMgr:           manager.PRMgr,
+		userMgr:         manager.UserMgr,
+	}
+	client := &http.Client{Timeout: time.Duration(config.ClientTimeout) * time.Second}
+	s.RegisterSender(models.ChannelSlack, sender.NewSlackSender(client))
+	s.RegisterSender(models.ChannelDingTalk, sender.NewDingTalkSender(client))
+	if config.SMTP.Host != "" {
+		s.RegisterSender(models.ChannelEmail, sender.NewEmailSender(config.SMTP))
+	}
+	return s, nil
+}
+
+func (s *service) RegisterSender(channelType string, sd sender.Sender) {
+	s.sendersLock.Lock()
+	defer s.sendersLock.Unlock()
+	s.senders[channelType] = sd
+}
+
+func (s *service) sender(channelType string) sender.Sender {
+	s.sendersLock.RLock()
+	defer s.sendersLock.RUnlock()
+	return s.senders[channelType]
+}
+
+// notice is a notification to send about a cluster, or about a group if cluster and application are nil
+type notice struct {
+	kind        string
+	subject     string
+	cluster     *clustermodels.Cluster
+	application *applicationmodels.Application
+	groupIDs    []uint
+	data        *Data
+}
+
+func (s *service) Handle(ctx context.Context, events []*eventmodels.Event) error {
+	for _, event := range events {
+		n, err := s.noticeOf(ctx, event)
+		if err != nil {
+			log.Warningf(ctx, "failed to make notification of event %d, err: %+v", event.ID, err)
+			continue
+		}
+		if n != nil {
+			s.notify(ctx, n)
+		}
+	}
+	return nil
+}
+
+// noticeOf makes the notification of the event, it returns nil if nothing is to be notified
+func (s *service) noticeOf(ctx context.Context, event *eventmodels.Event) (*notice, error) {
+	switch event.EventType {
+	case eventmodels.PipelinerunCreated, eventmodels.PipelinerunFinished:
+		pr, err := s.prMgr.PipelineRun.GetByID(ctx, event.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		if pr.Action != prmodels.ActionBuildDeploy && pr.Action != prmodels.ActionDeploy {
+			return nil, nil
+		}
+		kind := models.DeployStarted
+		if event.EventType == eventmodels.PipelinerunFinished {
+			// the deploys succeeded are notified by the events of clusters
+			if pr.Status != string(prmodels.StatusFailed) {
+				return nil, nil
+			}
+			kind = models.DeployFailed
+		}
+		n, err := s.newNotice(ctx, kind, pr.ClusterID, pr.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		n.data.Action = pr.Action
+		n.data.PipelinerunID = pr.ID
+		return n, nil
+	case eventmodels.ClusterDeployed, eventmodels.ClusterBuildDeployed, eventmodels.ClusterRollbacked:
+		n, err := s.newNotice(ctx, models.DeploySucceeded, event.ResourceID, event.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		switch event.EventType {
+		case eventmodels.ClusterBuildDeployed:
+			n.data.Action = prmodels.ActionBuildDeploy
+		case eventmodels.ClusterRollbacked:
+			n.data.Action = prmodels.ActionRollback
+		default:
+			n.data.Action = prmodels.ActionDeploy
+		}
+		return n, nil
+	case eventmodels.ClusterAutoFreeWarned:
+		var warning eventmodels.AutoFreeWarning
+		if event.Extra == nil {
+			return nil, fmt.Errorf("extra of event is empty")
+		}
+		if err := json.Unmarshal([]byte(*event.Extra), &warning); err != nil {
+			return nil, err
+		}
+		n, err := s.newNotice(ctx, models.AutoFreeWarning, event.ResourceID, 0)
+		if err != nil {
+			return nil, err
+		}
+		n.data.FreeAt = warning.FreeAt
+		return n, nil
+	case eventmodels.GroupQuotaWarned:
+		var warning eventmodels.QuotaWarning
+		if event.Extra == nil {
+			return nil, fmt.Errorf("extra of event is empty")
+		}
+		if err := json.Unmarshal([]byte(*event.Extra), &warning); err != nil {
+			return nil, err
+		}
+		return s.newQuotaNotice(ctx, event.ResourceID, &warning)
+	}
+	return nil, nil
+}
+
+func (s *service) newQuotaNotice(ctx context.Context, groupID uint,
+	warning *eventmodels.QuotaWarning) (*notice, error) {
+	group, err := s.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	data := &Data{
+		Kind:      models.QuotaWarning,
+		Group:     group.Name,
+		Resource:  warning.Resource,
+		Used:      warning.Used,
+		HardLimit: warning.HardLimit,
+		Threshold: warning.Threshold,
+	}
+	fullPath, err := s.fullPath(ctx, groupIDs)
+	if err != nil {
+		log.Warningf(ctx, "failed to get full path of group %d, err: %v", group.ID, err)
+	} else {
+		data.Group = fullPath
+		if s.config.WebURL != "" {
+			data.URL = fmt.Sprintf("%s/%s", strings.TrimSuffix(s.config.WebURL, "/"), fullPath)
+		}
+	}
+	return &notice{
+		kind:     models.QuotaWarning,
+		subject:  data.Group,
+		groupIDs: groupIDs,
+		data:     data,
+	}, nil
+}
+
+func (s *service) newNotice(ctx context.Context, kind string, clusterID, operatorID uint) (*notice, error) {
+	cluster, err := s.clusterMgr.GetByIDIncludeSoftDelete(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := s.applicationMgr.GetByIDIncludeSoftDelete(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.groupMgr.GetByID(ctx, application.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+
+	data := &Data{
+		Kind:        kind,
+		Application: application.Name,
+		Cluster:     cluster.Name,
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+	}
+	if operatorID != 0 {
+		operator, err := s.userMgr.GetUserByID(ctx, operatorID)
+		if err != nil {
+			log.Warningf(ctx, "failed to get operator %d, err: %v", operatorID, err)
+		} else {
+			data.Operator = operator.FullName
+			if data.Operator == "" {
+				data.Operator = operator.Name
+			}
+		}
+	}
+	if s.config.WebURL != "" {
+		fullPath, err := s.fullPath(ctx, groupIDs, application.Name, cluster.Name)
+		if err != nil {
+			log.Warningf(ctx, "failed to get full path of cluster %d, err: %v", cluster.ID, err)
+		} else {
+			data.URL = fmt.Sprintf("%s/%s", strings.TrimSuffix(s.config.WebURL, "/"), fullPath)
+		}
+	}
+	return &notice{
+		kind:        kind,
+		subject:     cluster.Name,
+		cluster:     cluster,
+		application: application,
+		groupIDs:    groupIDs,
+		data:        data,
+	}, nil
+}
+
+// fullPath joins the paths of the groups and the names of the resources under them
+func (s *service) fullPath(ctx context.Context, groupIDs []uint, names ...string) (string, error) {
+	groups, err := s.groupMgr.GetByIDs(ctx, groupIDs)
+	if err != nil {
+		return "", err
+	}
+	paths := make(map[uint]string, len(groups))
+	for _, group := range groups {
+		paths[group.ID] = group.Path
+	}
+	parts := make([]string, 0, len(groupIDs)+len(names))
+	for _, id := range groupIDs {
+		parts = append(parts, paths[id])
+	}
+	return strings.Join(append(parts, names...), "/"), nil
+}
+
+// notify sends the notification through the channels of the application and its groups,
+// and to the users subscribing the cluster, the application or its groups.
+// Notifications about groups are sent through the channels of the groups and to their subscribers.
+func (s *service) notify(ctx context.Context, n *notice) {
+	message, err := s.templates[n.kind].render(n.data)
+	if err != nil {
+		log.Warningf(ctx, "failed to render notification %s of %s, err: %v", n.kind, n.subject, err)
+		return
+	}
+
+	resources := map[string][]uint{
+		common.ResourceGroup: n.groupIDs,
+	}
+	if n.application != nil {
+		resources[common.ResourceApplication] = []uint{n.application.ID}
+	}
+	channels, err := s.notificationMgr.ListChannelsOfResources(ctx, resources)
+	if err != nil {
+		log.Warningf(ctx, "failed to list notification channels, err: %v", err)
+	}
+	for _, channel := range channels {
+		if channel.Enabled && models.HasTrigger(channel.Triggers, n.kind) {
+			s.send(ctx, channel, message)
+		}
+	}
+
+	if n.cluster != nil {
+		resources[common.ResourceCluster] = []uint{n.cluster.ID}
+	}
+	subscriptions, err := s.notificationMgr.ListSubscriptionsOfResources(ctx, resources)
+	if err != nil {
+		log.Warningf(ctx, "failed to list notification subscriptions, err: %v", err)
+		return
+	}
+	// users subscribing the cluster and its parents are notified once
+	userIDs := make([]uint, 0, len(subscriptions))
+	subscribed := make(map[uint]bool, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if models.HasTrigger(subscription.Triggers, n.kind) && !subscribed[subscription.UserID] {
+			subscribed[subscription.UserID] = true
+			userIDs = append(userIDs, subscription.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+	users, err := s.userMgr.GetUserByIDs(ctx, userIDs)
+	if err != nil {
+		log.Warningf(ctx, "failed to list subscribers, err: %v", err)
+		return
+	}
+	for _, user := range users {
+		if user.Email == "" {
+			continue
+		}
+		s.send(ctx, &models.NotificationChannel{
+			Name: user.Name,
+			Type: models.ChannelEmail,
+			URL:  user.Email,
+		}, message)
+	}
+}
+
+func (s *service) send(ctx context.Context, channel *models.NotificationChannel, message *sender.Message) {
+	sd := s.sender(channel.Type)
+	if sd == nil {
+		log.Warningf(ctx, "no sender of %s channels, notification to %s is dropped", channel.Type, channel.Name)
+		return
+	}
+	if err := sd.Send(ctx, channel, message); err != nil {
+		log.Warningf(ctx, "failed to send notification to %s channel %s, err: %v", channel.Type, channel.Name, err)
+	}
+}
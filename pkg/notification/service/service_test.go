@@ -0,0 +1,202 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/notification"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+	"github.com/horizoncd/horizon/pkg/notification/sender"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.TODO()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&usermodels.User{}, &prmodels.Pipelinerun{},
+		&models.NotificationChannel{}, &models.NotificationSubscription{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+type sent struct {
+	channel string
+	message *sender.Message
+}
+
+type fakeSender struct {
+	lock sync.Mutex
+	sent []sent
+}
+
+func (f *fakeSender) Send(ctx context.Context, channel *models.NotificationChannel,
+	message *sender.Message) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sent = append(f.sent, sent{channel: channel.URL, message: message})
+	return nil
+}
+
+func (f *fakeSender) take() []sent {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	s := f.sent
+	f.sent = nil
+	return s
+}
+
+func TestParseTemplates(t *testing.T) {
+	templates, err := parseTemplates(map[string]notification.Template{
+		models.DeployFailed: {Title: "{{.Cluster}} failed"},
+	})
+	assert.Nil(t, err)
+	message, err := templates[models.DeployFailed].render(&Data{
+		Cluster: "demo-dev", Application: "demo", Environment: "dev", Operator: "Tom", PipelinerunID: 2,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "demo-dev failed", message.Title)
+	// the default content is kept
+	assert.Equal(t, "Cluster: demo-dev\nApplication: demo\nEnvironment: dev\nRegion: \n"+
+		"Operator: Tom\nPipelinerun: 2", message.Content)
+
+	_, err = parseTemplates(map[string]notification.Template{"unknown": {Title: "title"}})
+	assert.NotNil(t, err)
+	_, err = parseTemplates(map[string]notification.Template{models.DeployFailed: {Title: "{{.Cluster"}})
+	assert.NotNil(t, err)
+}
+
+func TestHandle(t *testing.T) {
+	// groups: 1 -> 2, application 1 under group 2
+	for _, group := range []*groupmodels.Group{
+		{Model: global.Model{ID: 1}, Name: "horizon", Path: "horizon", TraversalIDs: "1"},
+		{Model: global.Model{ID: 2}, Name: "music", Path: "music", ParentID: 1, TraversalIDs: "1,2"},
+	} {
+		assert.Nil(t, db.Create(group).Error)
+	}
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "demo", GroupID: 2}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 1}, Name: "demo-dev",
+		ApplicationID: 1, EnvironmentName: "dev", RegionName: "hz"}).Error)
+	for _, user := range []*usermodels.User{
+		{Model: global.Model{ID: 1}, Name: "tom", FullName: "Tom", Email: "tom@noreply.com"},
+		{Model: global.Model{ID: 2}, Name: "jerry", Email: "jerry@noreply.com"},
+	} {
+		assert.Nil(t, db.Create(user).Error)
+	}
+	assert.Nil(t, db.Create(&prmodels.Pipelinerun{ID: 1, ClusterID: 1, Action: prmodels.ActionBuildDeploy,
+		Status: string(prmodels.StatusFailed), CreatedBy: 1}).Error)
+
+	for _, channel := range []*models.NotificationChannel{
+		{ResourceType: common.ResourceGroup, ResourceID: 1, Name: "root", Type: models.ChannelSlack,
+			URL: "slack", Triggers: models.JoinTriggers([]string{models.DeployFailed, models.AutoFreeWarning}),
+			Enabled: true},
+		{ResourceType: common.ResourceApplication, ResourceID: 1, Name: "app", Type: models.ChannelDingTalk,
+			URL: "dingtalk", Triggers: models.JoinTriggers(models.Kinds), Enabled: true},
+		{ResourceType: common.ResourceApplication, ResourceID: 1, Name: "disabled", Type: models.ChannelSlack,
+			URL: "disabled", Triggers: models.JoinTriggers(models.Kinds)},
+	} {
+		_, err := manager.NotificationMgr.CreateChannel(ctx, channel)
+		assert.Nil(t, err)
+	}
+	for _, subscription := range []*models.NotificationSubscription{
+		{UserID: 1, ResourceType: common.ResourceCluster, ResourceID: 1, Triggers: models.AutoFreeWarning},
+		{UserID: 1, ResourceType: common.ResourceGroup, ResourceID: 2, Triggers: models.AutoFreeWarning},
+		{UserID: 2, ResourceType: common.ResourceApplication, ResourceID: 1, Triggers: models.DeployStarted},
+	} {
+		_, err := manager.NotificationMgr.UpsertSubscription(ctx, subscription)
+		assert.Nil(t, err)
+	}
+
+	svc, err := NewService(manager, notification.Config{WebURL: "https://horizon.example.com/"})
+	assert.Nil(t, err)
+	fake := &fakeSender{}
+	for _, channelType := range models.ChannelTypes {
+		svc.RegisterSender(channelType, fake)
+	}
+
+	// the pipelinerun failed
+	assert.Nil(t, svc.Handle(ctx, []*eventmodels.Event{{EventSummary: eventmodels.EventSummary{
+		ResourceType: common.ResourcePipelinerun, ResourceID: 1, EventType: eventmodels.PipelinerunFinished,
+	}}}))
+	s := fake.take()
+	assert.Equal(t, 2, len(s))
+	assert.Equal(t, "slack", s[0].channel)
+	assert.Equal(t, "dingtalk", s[1].channel)
+	assert.Equal(t, "[Horizon] demo-dev failed to builddeploy", s[0].message.Title)
+	assert.Contains(t, s[0].message.Content, "Operator: Tom\nPipelinerun: 1\n"+
+		"Detail: https://horizon.example.com/horizon/music/demo/demo-dev")
+
+	// the deploy succeeded
+	assert.Nil(t, svc.Handle(ctx, []*eventmodels.Event{{EventSummary: eventmodels.EventSummary{
+		ResourceType: common.ResourceCluster, ResourceID: 1, EventType: eventmodels.ClusterDeployed,
+	}, CreatedBy: 2}}))
+	s = fake.take()
+	assert.Equal(t, 1, len(s))
+	assert.Equal(t, "[Horizon] demo-dev succeeded to deploy", s[0].message.Title)
+	assert.Contains(t, s[0].message.Content, "Operator: jerry")
+
+	// the pipelinerun created, the subscriber is notified by email
+	assert.Nil(t, db.Create(&prmodels.Pipelinerun{ID: 2, ClusterID: 1, Action: prmodels.ActionDeploy,
+		Status: string(prmodels.StatusCreated), CreatedBy: 2}).Error)
+	assert.Nil(t, svc.Handle(ctx, []*eventmodels.Event{{EventSummary: eventmodels.EventSummary{
+		ResourceType: common.ResourcePipelinerun, ResourceID: 2, EventType: eventmodels.PipelinerunCreated,
+	}}}))
+	s = fake.take()
+	assert.Equal(t, 2, len(s))
+	assert.Equal(t, "dingtalk", s[0].channel)
+	assert.Equal(t, "jerry@noreply.com", s[1].channel)
+
+	// the cluster is going to be freed, the subscriber of the cluster and its group is notified once
+	freeAt := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	extra, _ := json.Marshal(eventmodels.AutoFreeWarning{FreeAt: freeAt})
+	extraStr := string(extra)
+	assert.Nil(t, svc.Handle(ctx, []*eventmodels.Event{{EventSummary: eventmodels.EventSummary{
+		ResourceType: common.ResourceCluster, ResourceID: 1, EventType: eventmodels.ClusterAutoFreeWarned,
+		Extra: &extraStr,
+	}}}))
+	s = fake.take()
+	assert.Equal(t, 3, len(s))
+	assert.Equal(t, "tom@noreply.com", s[2].channel)
+	assert.Equal(t, "[Horizon] demo-dev is going to be freed", s[2].message.Title)
+	assert.Contains(t, s[2].message.Content, "demo-dev will be freed automatically at 2026-10-17 08:00:00 UTC")
+
+	// restarts are not notified
+	assert.Nil(t, svc.Handle(ctx, []*eventmodels.Event{{EventSummary: eventmodels.EventSummary{
+		ResourceType: common.ResourceCluster, ResourceID: 1, EventType: eventmodels.ClusterRestarted,
+	}}}))
+	assert.Empty(t, fake.take())
+}
@@ -0,0 +1,139 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/notification"
+	"github.com/horizoncd/horizon/pkg/notification/models"
+	"github.com/horizoncd/horizon/pkg/notification/sender"
+)
+
+// Data is what the templates are rendered with
+type Data struct {
+	Kind        string
+	Application string
+	Cluster     string
+	Environment string
+	Region      string
+	// Action is builddeploy, deploy or rollback, it's empty for autofree warnings
+	Action string
+	// PipelinerunID is 0 if the deploy is not run by a pipelinerun, such as rollbacks
+	PipelinerunID uint
+	Operator      string
+	// FreeAt is when the cluster is freed, it's set for autofree warnings only
+	FreeAt time.Time
+	// Group is the full path of the group, Resource, Used, HardLimit and Threshold are the usage of its quota,
+	// they're set for quota warnings only
+	Group     string
+	Resource  string
+	Used      int64
+	HardLimit uint
+	Threshold int
+	// URL links to the cluster or the group on horizon web, it's empty if the web url is not configured
+	URL string
+}
+
+const _clusterDetail = `Cluster: {{.Cluster}}
+Application: {{.Application}}
+Environment: {{.Environment}}
+Region: {{.Region}}
+{{- if .Operator}}
+Operator: {{.Operator}}
+{{- end}}
+{{- if .PipelinerunID}}
+Pipelinerun: {{.PipelinerunID}}
+{{- end}}
+{{- if .URL}}
+Detail: {{.URL}}
+{{- end}}`
+
+var defaultTemplates = map[string]notification.Template{
+	models.DeployStarted: {
+		Title:   "[Horizon] {{.Cluster}} started to {{.Action}}",
+		Content: _clusterDetail,
+	},
+	models.DeploySucceeded: {
+		Title:   "[Horizon] {{.Cluster}} succeeded to {{.Action}}",
+		Content: _clusterDetail,
+	},
+	models.DeployFailed: {
+		Title:   "[Horizon] {{.Cluster}} failed to {{.Action}}",
+		Content: _clusterDetail,
+	},
+	models.AutoFreeWarning: {
+		Title: "[Horizon] {{.Cluster}} is going to be freed",
+		Content: `{{.Cluster}} will be freed automatically at {{.FreeAt.Format "2006-01-02 15:04:05 MST"}}, ` +
+			"deploy or update it to keep it.\n" + _clusterDetail,
+	},
+	models.QuotaWarning: {
+		Title: "[Horizon] {{.Group}} has used {{.Threshold}}% of its {{.Resource}} quota",
+		Content: `{{.Used}} of {{.HardLimit}} {{.Resource}} are used under {{.Group}}, ` +
+			"request more quota before creations fail." + `
+{{- if .URL}}
+Detail: {{.URL}}
+{{- end}}`,
+	},
+}
+
+type messageTemplate struct {
+	title   *template.Template
+	content *template.Template
+}
+
+// parseTemplates parses the templates of all the kinds, the default ones are used if not configured
+func parseTemplates(configured map[string]notification.Template) (map[string]*messageTemplate, error) {
+	for kind := range configured {
+		if _, ok := defaultTemplates[kind]; !ok {
+			return nil, fmt.Errorf("unknown kind of notification template: %s", kind)
+		}
+	}
+	templates := make(map[string]*messageTemplate, len(defaultTemplates))
+	for kind, t := range defaultTemplates {
+		if c, ok := configured[kind]; ok {
+			if c.Title != "" {
+				t.Title = c.Title
+			}
+			if c.Content != "" {
+				t.Content = c.Content
+			}
+		}
+		title, err := template.New(kind + " title").Parse(t.Title)
+		if err != nil {
+			return nil, fmt.Errorf("invalid title template of %s: %v", kind, err)
+		}
+		content, err := template.New(kind + " content").Parse(t.Content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content template of %s: %v", kind, err)
+		}
+		templates[kind] = &messageTemplate{title: title, content: content}
+	}
+	return templates, nil
+}
+
+func (t *messageTemplate) render(data *Data) (*sender.Message, error) {
+	var title, content bytes.Buffer
+	if err := t.title.Execute(&title, data); err != nil {
+		return nil, err
+	}
+	if err := t.content.Execute(&content, data); err != nil {
+		return nil, err
+	}
+	return &sender.Message{Title: title.String(), Content: content.String()}, nil
+}
@@ -17,7 +17,9 @@ package store
 import (
 	goerrors "errors"
 
+	corecommon "github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/common"
 	"github.com/horizoncd/horizon/pkg/oauth/models"
 	"golang.org/x/net/context"
@@ -27,13 +29,30 @@ import (
 type DAO interface {
 	CreateApp(ctx context.Context, client models.OauthApp) error
 	GetApp(ctx context.Context, clientID string) (*models.OauthApp, error)
+	// ListAppByClientIDs gets apps of clientIDs in one query
+	ListAppByClientIDs(ctx context.Context, clientIDs []string) ([]models.OauthApp, error)
 	DeleteApp(ctx context.Context, clientID string) error
 	ListApp(ctx context.Context, ownerType models.OwnerType, ownerID uint) ([]models.OauthApp, error)
 	UpdateApp(ctx context.Context, clientID string, app models.OauthApp) (*models.OauthApp, error)
+	// TransferApp moves the app to the new owner
+	TransferApp(ctx context.Context, clientID string, ownerType models.OwnerType,
+		ownerID uint, updatedBy uint) (*models.OauthApp, error)
 	CreateSecret(ctx context.Context, secret *models.OauthClientSecret) (*models.OauthClientSecret, error)
 	DeleteSecret(ctx context.Context, clientID string, clientSecretID uint) error
 	DeleteSecretByClientID(ctx context.Context, clientID string) error
 	ListSecret(ctx context.Context, clientID string) ([]models.OauthClientSecret, error)
+	// ListSecretBySuffix lists the secrets of client ending with suffix, which narrows the secrets to verify
+	ListSecretBySuffix(ctx context.Context, clientID, suffix string) ([]models.OauthClientSecret, error)
+
+	GetGrant(ctx context.Context, userID uint, clientID string) (*models.OauthGrant, error)
+	// SaveGrant creates the grant of user to client, or replaces the scope of the existing one
+	SaveGrant(ctx context.Context, userID uint, clientID, scope string) (*models.OauthGrant, error)
+	DeleteGrant(ctx context.Context, userID uint, clientID string) error
+	DeleteGrantByClientID(ctx context.Context, clientID string) error
+
+	CreateTokenAudit(ctx context.Context, audit *models.OauthTokenAudit) error
+	// ListTokenAudits lists the token audits matching the keywords of query, latest first
+	ListTokenAudits(ctx context.Context, query *q.Query) ([]*models.OauthTokenAudit, int, error)
 }
 
 func NewDAO(db *gorm.DB) DAO {
@@ -60,6 +79,18 @@ func (d *dao) GetApp(ctx context.Context, clientID string) (*models.OauthApp, er
 	return &client, nil
 }
 
+func (d *dao) ListAppByClientIDs(ctx context.Context, clientIDs []string) ([]models.OauthApp, error) {
+	var apps []models.OauthApp
+	if len(clientIDs) == 0 {
+		return apps, nil
+	}
+	result := d.db.WithContext(ctx).Raw(common.GetOauthAppsByClientIDs, clientIDs).Scan(&apps)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.OAuthInDB, result.Error.Error())
+	}
+	return apps, nil
+}
+
 func (d *dao) UpdateApp(ctx context.Context,
 	clientID string, app models.OauthApp) (*models.OauthApp, error) {
 	var appInDb models.OauthApp
@@ -76,6 +107,9 @@ func (d *dao) UpdateApp(ctx context.Context,
 		appInDb.HomeURL = app.HomeURL
 		appInDb.RedirectURL = app.RedirectURL
 		appInDb.Desc = app.Desc
+		appInDb.ClientType = app.ClientType
+		appInDb.AccessTokenExpireIn = app.AccessTokenExpireIn
+		appInDb.RefreshTokenExpireIn = app.RefreshTokenExpireIn
 		appInDb.UpdatedBy = app.UpdatedBy
 		if err := tx.Save(&appInDb).Error; err != nil {
 			return herrors.NewErrUpdateFailed(herrors.OAuthInDB, err.Error())
@@ -87,6 +121,30 @@ func (d *dao) UpdateApp(ctx context.Context,
 	return &appInDb, err
 }
 
+func (d *dao) TransferApp(ctx context.Context, clientID string, ownerType models.OwnerType,
+	ownerID uint, updatedBy uint) (*models.OauthApp, error) {
+	var appInDb models.OauthApp
+	if err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(common.GetOauthAppByClientID, clientID).Scan(&appInDb)
+		if result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.OAuthInDB, result.Error.Error())
+		}
+		if result.RowsAffected == 0 {
+			return herrors.NewErrNotFound(herrors.OAuthInDB, "row affected = 0s")
+		}
+		appInDb.OwnerType = ownerType
+		appInDb.OwnerID = ownerID
+		appInDb.UpdatedBy = updatedBy
+		if err := tx.Save(&appInDb).Error; err != nil {
+			return herrors.NewErrUpdateFailed(herrors.OAuthInDB, err.Error())
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &appInDb, nil
+}
+
 func (d *dao) DeleteApp(ctx context.Context, clientID string) error {
 	result := d.db.WithContext(ctx).Exec(common.DeleteOauthAppByClientID, clientID)
 	return result.Error
@@ -125,3 +183,109 @@ func (d *dao) ListSecret(ctx context.Context, clientID string) ([]models.OauthCl
 	}
 	return secrets, nil
 }
+
+func (d *dao) ListSecretBySuffix(ctx context.Context,
+	clientID, suffix string) ([]models.OauthClientSecret, error) {
+	var secrets []models.OauthClientSecret
+	result := d.db.WithContext(ctx).Raw(common.ClientSecretSelectBySuffix, clientID, suffix).Scan(&secrets)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.OAuthInDB, result.Error.Error())
+	}
+	return secrets, nil
+}
+
+func (d *dao) GetGrant(ctx context.Context, userID uint, clientID string) (*models.OauthGrant, error) {
+	var grant models.OauthGrant
+	result := d.db.WithContext(ctx).Raw(common.GetOauthGrant, userID, clientID).First(&grant)
+	if result.Error != nil {
+		if goerrors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.OAuthGrantInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.OAuthGrantInDB, result.Error.Error())
+	}
+	return &grant, nil
+}
+
+func (d *dao) SaveGrant(ctx context.Context, userID uint,
+	clientID, scope string) (*models.OauthGrant, error) {
+	var grant models.OauthGrant
+	if err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(common.GetOauthGrant, userID, clientID).Scan(&grant)
+		if result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.OAuthGrantInDB, result.Error.Error())
+		}
+		if result.RowsAffected == 0 {
+			grant = models.OauthGrant{
+				UserID:   userID,
+				ClientID: clientID,
+			}
+		}
+		grant.Scope = scope
+		if err := tx.Save(&grant).Error; err != nil {
+			return herrors.NewErrUpdateFailed(herrors.OAuthGrantInDB, err.Error())
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (d *dao) DeleteGrant(ctx context.Context, userID uint, clientID string) error {
+	result := d.db.WithContext(ctx).Exec(common.DeleteOauthGrant, userID, clientID)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.OAuthGrantInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteGrantByClientID(ctx context.Context, clientID string) error {
+	result := d.db.WithContext(ctx).Exec(common.DeleteOauthGrantByClientID, clientID)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.OAuthGrantInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) CreateTokenAudit(ctx context.Context, audit *models.OauthTokenAudit) error {
+	if result := d.db.WithContext(ctx).Create(audit); result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.OAuthTokenAuditInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListTokenAudits(ctx context.Context, query *q.Query) ([]*models.OauthTokenAudit, int, error) {
+	var (
+		audits []*models.OauthTokenAudit
+		total  int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.OauthTokenAudit{})
+	if query != nil {
+		for k, v := range query.Keywords {
+			switch k {
+			case corecommon.OauthTokenAuditQueryClientID:
+				statement = statement.Where("client_id = ?", v)
+			case corecommon.OauthTokenAuditQueryUserID:
+				statement = statement.Where("user_id = ?", v)
+			case corecommon.OauthTokenAuditQueryAction:
+				statement = statement.Where("action = ?", v)
+			case corecommon.OauthTokenAuditQuerySourceIP:
+				statement = statement.Where("source_ip = ?", v)
+			case corecommon.StartTime:
+				statement = statement.Where("created_at >= ?", v)
+			case corecommon.EndTime:
+				statement = statement.Where("created_at < ?", v)
+			}
+		}
+	}
+	if result := statement.Count(&total); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.OAuthTokenAuditInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Offset(query.Offset()).Limit(query.Limit())
+	}
+	if result := statement.Order("id desc").Find(&audits); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.OAuthTokenAuditInDB, result.Error.Error())
+	}
+	return audits, int(total), nil
+}
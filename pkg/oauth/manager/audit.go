@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/oauth/models"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"golang.org/x/net/context"
+)
+
+func (m *OauthManager) ListTokenAudits(ctx context.Context,
+	query *q.Query) ([]*models.OauthTokenAudit, int, error) {
+	return m.oauthAppDAO.ListTokenAudits(ctx, query)
+}
+
+// auditToken records the action on the token, a failure is only logged
+// so that the token requests do not fail for the audit
+func (m *OauthManager) auditToken(ctx context.Context, action models.TokenAuditAction,
+	token *tokenmodels.Token, r *http.Request) {
+	audit := &models.OauthTokenAudit{
+		Action:   action,
+		ClientID: token.ClientID,
+		UserID:   token.UserID,
+		Scope:    token.Scope,
+		TokenID:  token.ID,
+		SourceIP: sourceIP(r),
+	}
+	if err := m.oauthAppDAO.CreateTokenAudit(ctx, audit); err != nil {
+		log.Warningf(ctx, "record token audit error, action = %s, client = %s, token = %d, err = %v",
+			action, token.ClientID, token.ID, err)
+	}
+}
+
+// sourceIP returns the ip the request comes from, the one forwarded by proxies is preferred like gin does
+func sourceIP(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		if ip := strings.TrimSpace(strings.Split(forwarded, ",")[0]); ip != "" {
+			return ip
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-Ip")); ip != "" {
+		return ip
+	}
+	if ip, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr)); err == nil {
+		return ip
+	}
+	return r.RemoteAddr
+}
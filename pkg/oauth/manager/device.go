@@ -0,0 +1,184 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"crypto/rand"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"golang.org/x/net/context"
+)
+
+// user codes are made of consonants only to avoid ambiguous characters and words, ref: rfc8628 section 6.1
+const (
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength  = 8
+)
+
+func genUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeCharset))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeCharset[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// NormalizeUserCode drops the separators and spaces users type, and makes the code upper case
+func NormalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(userCode))
+}
+
+// FormatUserCode splits the user code into halves to be read easily, such as WDJB-MJHT
+func FormatUserCode(userCode string) string {
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+func isDeviceCodeExpired(token *tokenmodels.Token) bool {
+	return token.CreatedAt.Add(token.ExpiresIn).Before(time.Now())
+}
+
+func (m *OauthManager) GenDeviceCode(ctx context.Context, clientID, scope string,
+	r *http.Request) (*tokenmodels.Token, error) {
+	if _, err := m.oauthAppDAO.GetApp(ctx, clientID); err != nil {
+		return nil, err
+	}
+	userCode, err := genUserCode()
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrOAuthInternal, "failed to generate user code: %v", err)
+	}
+
+	// the user is unknown until the device code is approved
+	token := &tokenmodels.Token{
+		ClientID:  clientID,
+		CreatedAt: time.Now(),
+		ExpiresIn: m.deviceCodeExpireTime,
+		Scope:     scope,
+		UserCode:  userCode,
+	}
+	token.Code = m.authorizationCodeGenerator.Generate(&generator.CodeGenerateInfo{
+		Token:   *token,
+		Request: r,
+	})
+	return m.tokenStore.Create(ctx, token)
+}
+
+func (m *OauthManager) GetDeviceCode(ctx context.Context, userCode string) (*tokenmodels.Token, error) {
+	userCode = NormalizeUserCode(userCode)
+	if userCode == "" {
+		return nil, perror.Wrap(herrors.ErrOAuthReqNotValid, "user code is required")
+	}
+	token, err := m.tokenStore.GetByUserCode(ctx, userCode)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, perror.Wrap(err, "user code not exist")
+		}
+		return nil, err
+	}
+	if isDeviceCodeExpired(token) {
+		return nil, perror.Wrap(herrors.ErrOAuthCodeExpired, "user code expired")
+	}
+	if token.UserID != 0 {
+		return nil, perror.Wrap(herrors.ErrOAuthReqNotValid, "user code has already been approved")
+	}
+	return token, nil
+}
+
+func (m *OauthManager) ApproveDeviceCode(ctx context.Context, userCode string,
+	userIdentity uint) (*tokenmodels.Token, error) {
+	if userIdentity == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "user identity is required")
+	}
+	token, err := m.GetDeviceCode(ctx, userCode)
+	if err != nil {
+		return nil, err
+	}
+	// only one of the concurrent approvals of the same code succeeds
+	approved, err := m.tokenStore.ApproveDeviceCode(ctx, token.ID, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return nil, perror.Wrap(herrors.ErrOAuthReqNotValid, "user code has already been approved")
+	}
+	token.UserID = userIdentity
+	return token, nil
+}
+
+func (m *OauthManager) DenyDeviceCode(ctx context.Context, userCode string) error {
+	token, err := m.GetDeviceCode(ctx, userCode)
+	if err != nil {
+		return err
+	}
+	return m.tokenStore.DeleteByID(ctx, token.ID)
+}
+
+func (m *OauthManager) GenDeviceTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error) {
+	deviceCodeToken, err := m.tokenStore.GetByCode(ctx, req.Code)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, perror.Wrap(err, "device code not exist, it may be denied or expired")
+		}
+		return nil, err
+	}
+	if deviceCodeToken.UserCode == "" || deviceCodeToken.ClientID != req.ClientID {
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"device code is not issued to client %s", req.ClientID)
+	}
+
+	// devices are public clients mostly, confidential ones authenticate themselves as well, ref: rfc8628 section 3.4
+	if _, err := m.authenticateClient(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// a device code can only be exchanged once as an authorization code
+	if deviceCodeToken.RefID != 0 {
+		m.revokeTokensIssuedByCode(ctx, deviceCodeToken)
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"device code has already been used, id = %d", deviceCodeToken.ID)
+	}
+	if isDeviceCodeExpired(deviceCodeToken) {
+		if err := m.tokenStore.DeleteByID(ctx, deviceCodeToken.ID); err != nil {
+			log.Warningf(ctx, "delete expired device code error, err = %v", err)
+		}
+		return nil, perror.Wrap(herrors.ErrOAuthCodeExpired, "device code expired")
+	}
+	if deviceCodeToken.UserID == 0 {
+		return nil, perror.Wrap(herrors.ErrOAuthAuthorizationPending, "")
+	}
+
+	// tokens issued by device codes are not bound to any redirect url
+	req.RedirectURL = ""
+	return m.issueTokensByCode(ctx, req, deviceCodeToken)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"sort"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func (m *OauthManager) IsGranted(ctx context.Context, userIdentity uint, clientID, scope string) (bool, error) {
+	grant, err := m.oauthAppDAO.GetGrant(ctx, userIdentity, clientID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	granted := make(map[string]struct{})
+	for _, s := range splitScope(grant.Scope) {
+		granted[s] = struct{}{}
+	}
+	for _, s := range splitScope(scope) {
+		if _, ok := granted[s]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m *OauthManager) Grant(ctx context.Context, userIdentity uint, clientID, scope string) error {
+	scopes := splitScope(scope)
+	grant, err := m.oauthAppDAO.GetGrant(ctx, userIdentity, clientID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+	} else {
+		scopes = append(scopes, splitScope(grant.Scope)...)
+	}
+	_, err = m.oauthAppDAO.SaveGrant(ctx, userIdentity, clientID, joinScope(scopes))
+	return err
+}
+
+func (m *OauthManager) RevokeGrant(ctx context.Context, userIdentity uint, clientID string) error {
+	return m.oauthAppDAO.DeleteGrant(ctx, userIdentity, clientID)
+}
+
+// splitScope splits the space separated scopes, empty ones are dropped
+func splitScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// joinScope joins the scopes sorted and deduplicated, so that equal sets of scopes are stored the same
+func joinScope(scopes []string) string {
+	set := make(map[string]struct{}, len(scopes))
+	unique := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if _, ok := set[s]; ok {
+			continue
+		}
+		set[s] = struct{}{}
+		unique = append(unique, s)
+	}
+	sort.Strings(unique)
+	return strings.Join(unique, " ")
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
 	"github.com/horizoncd/horizon/pkg/oauth/models"
@@ -36,6 +37,11 @@ type AuthorizeGenerateRequest struct {
 	ClientID    string
 	RedirectURL string
 	State       string
+	// CodeChallenge and CodeChallengeMethod are the PKCE challenge of public clients, ref: rfc7636
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// Nonce is the openid connect nonce, which is carried by the id token
+	Nonce string
 
 	Scope        string
 	UserIdentify uint
@@ -48,6 +54,9 @@ type OauthTokensRequest struct {
 	Code         string // authorization code
 	RefreshToken string // refresh token
 	RedirectURL  string
+	// CodeVerifier proves the possession of the authorization code requested with a PKCE challenge,
+	// the client secret can be omitted then
+	CodeVerifier string
 
 	Request *http.Request
 
@@ -58,6 +67,8 @@ type OauthTokensRequest struct {
 type OauthTokensResponse struct {
 	AccessToken  *tokenmodels.Token
 	RefreshToken *tokenmodels.Token
+	// Nonce is the openid connect nonce of the authorization code exchanged
+	Nonce string
 }
 
 type CreateOAuthAppReq struct {
@@ -68,6 +79,12 @@ type CreateOAuthAppReq struct {
 	OwnerType   models.OwnerType
 	OwnerID     uint
 	APPType     models.AppType
+	// ClientType is confidential by default
+	ClientType models.ClientType
+	// AccessTokenExpireIn and RefreshTokenExpireIn override the default token lifetimes,
+	// zero means the defaults
+	AccessTokenExpireIn  time.Duration
+	RefreshTokenExpireIn time.Duration
 }
 
 type UpdateOauthAppReq struct {
@@ -75,14 +92,27 @@ type UpdateOauthAppReq struct {
 	HomeURL     string
 	RedirectURI string
 	Desc        string
+	// ClientType is kept when empty
+	ClientType models.ClientType
+	// AccessTokenExpireIn and RefreshTokenExpireIn are kept when nil, zero means the default lifetimes
+	AccessTokenExpireIn  *time.Duration
+	RefreshTokenExpireIn *time.Duration
 }
 
 type Manager interface {
 	CreateOauthApp(ctx context.Context, info *CreateOAuthAppReq) (*models.OauthApp, error)
 	GetOAuthApp(ctx context.Context, clientID string) (*models.OauthApp, error)
+	// GetOAuthApps gets apps of clientIDs in batch, the result is keyed by clientID
+	GetOAuthApps(ctx context.Context, clientIDs []string) (map[string]*models.OauthApp, error)
 	DeleteOAuthApp(ctx context.Context, clientID string) error
+	// ListOauthApp lists all apps registered under the owner
 	ListOauthApp(ctx context.Context, ownerType models.OwnerType, ownerID uint) ([]models.OauthApp, error)
+	// UpdateOauthApp updates the basic info of the app, authorization codes issued to the app
+	// are invalidated once its redirect uri changes
 	UpdateOauthApp(ctx context.Context, clientID string, req UpdateOauthAppReq) (*models.OauthApp, error)
+	// TransferOauthApp transfers the app to a new owner, its secrets and tokens are kept
+	TransferOauthApp(ctx context.Context, clientID string, newOwnerType models.OwnerType,
+		newOwnerID uint) (*models.OauthApp, error)
 
 	CreateSecret(ctx context.Context, clientID string) (*models.OauthClientSecret, error)
 	DeleteSecret(ctx context.Context, ClientID string, clientSecretID uint) error
@@ -91,6 +121,43 @@ type Manager interface {
 	GenAuthorizeCode(ctx context.Context, req *AuthorizeGenerateRequest) (*tokenmodels.Token, error)
 	GenOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
 	RefreshOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
+	// GenClientCredentialsToken issues an access token to a direct oauth app by its own credentials
+	// without the authorization of a user, ref: rfc6749 section 4.4.
+	// The token acts as the identity of the app resolved by identity, never as a human such as its creator,
+	// and is revoked as other tokens of the app. It's not issued as the owner of the app either,
+	// since owners are groups, the owners grant permissions to the identity by memberships instead.
+	GenClientCredentialsToken(ctx context.Context, clientID, clientSecret, scope string, identity AppIdentity,
+		r *http.Request) (*tokenmodels.Token, error)
+	// RevokeAccessToken revokes a single token issued to the client, ref: rfc7009.
+	// Revoking a refresh token revokes its associated access token as well,
+	// and a token which does not exist is treated as revoked already.
+	RevokeAccessToken(ctx context.Context, clientID, token string, r *http.Request) error
+	// RevokeTokensByUser revokes all tokens the user or robot authorized across all clients at once,
+	// which is used to offboard users or to respond to incidents.
+	RevokeTokensByUser(ctx context.Context, userIdentity uint) error
+
+	// IsGranted tells whether the user has consented to grant all the scopes to the client before
+	IsGranted(ctx context.Context, userIdentity uint, clientID, scope string) (bool, error)
+	// Grant remembers the user consents to grant the scopes to the client, in addition to those granted before
+	Grant(ctx context.Context, userIdentity uint, clientID, scope string) error
+	// RevokeGrant forgets the consents of the user to the client, so the user is asked on next authorization
+	RevokeGrant(ctx context.Context, userIdentity uint, clientID string) error
+
+	// GenDeviceCode issues a device code and a user code to the client, the client polls for tokens by the device code
+	// while the user approves it by entering the user code on the verification page, ref: rfc8628
+	GenDeviceCode(ctx context.Context, clientID, scope string, r *http.Request) (*tokenmodels.Token, error)
+	// GetDeviceCode gets the device code pending for approval by the user code
+	GetDeviceCode(ctx context.Context, userCode string) (*tokenmodels.Token, error)
+	// ApproveDeviceCode approves the device code of the user code on behalf of the user
+	ApproveDeviceCode(ctx context.Context, userCode string, userIdentity uint) (*tokenmodels.Token, error)
+	// DenyDeviceCode denies the device code of the user code, the client polling for it gets an error then
+	DenyDeviceCode(ctx context.Context, userCode string) error
+	// GenDeviceTokens exchanges the approved device code for tokens,
+	// herrors.ErrOAuthAuthorizationPending is returned if the user has not yet approved it
+	GenDeviceTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
+
+	// ListTokenAudits lists the issuances, refreshes and revocations of tokens recorded, latest first
+	ListTokenAudits(ctx context.Context, query *q.Query) ([]*models.OauthTokenAudit, int, error)
 }
 
 var _ Manager = &OauthManager{}
@@ -107,6 +174,9 @@ func NewManager(oauthAppDAO oauthdao.DAO, tokenStore tokenstore.Store,
 		authorizeCodeExpireTime:    authorizeCodeExpireTime,
 		accessTokenExpireTime:      accessTokenExpireTime,
 		refreshTokenExpireTime:     refreshTokenExpireTime,
+		maxAccessTokenExpireTime:   accessTokenExpireTime,
+		maxRefreshTokenExpireTime:  refreshTokenExpireTime,
+		deviceCodeExpireTime:       authorizeCodeExpireTime,
 		clientIDGenerate:           GenClientID,
 	}
 }
@@ -118,7 +188,11 @@ type OauthManager struct {
 	authorizeCodeExpireTime    time.Duration
 	accessTokenExpireTime      time.Duration
 	refreshTokenExpireTime     time.Duration
-	clientIDGenerate           ClientIDGenerate
+	// maxAccessTokenExpireTime and maxRefreshTokenExpireTime bound the lifetimes apps override
+	maxAccessTokenExpireTime  time.Duration
+	maxRefreshTokenExpireTime time.Duration
+	deviceCodeExpireTime      time.Duration
+	clientIDGenerate          ClientIDGenerate
 }
 
 const HorizonAPPClientIDPrefix = "ho_"
@@ -140,11 +214,74 @@ type ClientIDGenerate func(appType models.AppType) string
 func (m *OauthManager) SetClientIDGenerate(gen ClientIDGenerate) {
 	m.clientIDGenerate = gen
 }
+
+// SetMaxTokenExpireTime sets the longest token lifetimes apps can override, zero keeps the default lifetimes
+// as the maximums
+func (m *OauthManager) SetMaxTokenExpireTime(maxAccessTokenExpireTime, maxRefreshTokenExpireTime time.Duration) {
+	if maxAccessTokenExpireTime > 0 {
+		m.maxAccessTokenExpireTime = maxAccessTokenExpireTime
+	}
+	if maxRefreshTokenExpireTime > 0 {
+		m.maxRefreshTokenExpireTime = maxRefreshTokenExpireTime
+	}
+}
+
+// SetDeviceCodeExpireTime sets the lifetime of device codes, which defaults to the one of authorization codes
+func (m *OauthManager) SetDeviceCodeExpireTime(deviceCodeExpireTime time.Duration) {
+	if deviceCodeExpireTime > 0 {
+		m.deviceCodeExpireTime = deviceCodeExpireTime
+	}
+}
+
+func (m *OauthManager) checkTokenExpireTime(accessTokenExpireTime, refreshTokenExpireTime time.Duration) error {
+	if accessTokenExpireTime < 0 || accessTokenExpireTime > m.maxAccessTokenExpireTime {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"access token lifetime must be between 0 and %v", m.maxAccessTokenExpireTime)
+	}
+	if refreshTokenExpireTime < 0 || refreshTokenExpireTime > m.maxRefreshTokenExpireTime {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"refresh token lifetime must be between 0 and %v", m.maxRefreshTokenExpireTime)
+	}
+	return nil
+}
+
+// accessTokenExpireTimeOf returns the lifetime of access tokens issued to the app,
+// the override is bounded by the maximum in case the maximum is lowered after it was set
+func (m *OauthManager) accessTokenExpireTimeOf(app *models.OauthApp) time.Duration {
+	if app == nil || app.AccessTokenExpireIn <= 0 {
+		return m.accessTokenExpireTime
+	}
+	if app.AccessTokenExpireIn > m.maxAccessTokenExpireTime {
+		return m.maxAccessTokenExpireTime
+	}
+	return app.AccessTokenExpireIn
+}
+
+// refreshTokenExpireTimeOf returns the lifetime of refresh tokens issued to the app
+func (m *OauthManager) refreshTokenExpireTimeOf(app *models.OauthApp) time.Duration {
+	if app == nil || app.RefreshTokenExpireIn <= 0 {
+		return m.refreshTokenExpireTime
+	}
+	if app.RefreshTokenExpireIn > m.maxRefreshTokenExpireTime {
+		return m.maxRefreshTokenExpireTime
+	}
+	return app.RefreshTokenExpireIn
+}
 func (m *OauthManager) CreateOauthApp(ctx context.Context, info *CreateOAuthAppReq) (*models.OauthApp, error) {
 	user, err := common.UserFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if err := m.checkTokenExpireTime(info.AccessTokenExpireIn, info.RefreshTokenExpireIn); err != nil {
+		return nil, err
+	}
+	clientType := info.ClientType
+	if clientType == "" {
+		clientType = models.ConfidentialClient
+	}
+	if err := checkClientType(clientType); err != nil {
+		return nil, err
+	}
 	clientID := m.clientIDGenerate(info.APPType)
 	oauthApp := models.OauthApp{
 		Name:        info.Name,
@@ -155,8 +292,13 @@ func (m *OauthManager) CreateOauthApp(ctx context.Context, info *CreateOAuthAppR
 		OwnerType:   info.OwnerType,
 		OwnerID:     info.OwnerID,
 		AppType:     info.APPType,
-		CreatedBy:   user.GetID(),
-		UpdatedBy:   user.GetID(),
+		ClientType:  clientType,
+
+		AccessTokenExpireIn:  info.AccessTokenExpireIn,
+		RefreshTokenExpireIn: info.RefreshTokenExpireIn,
+
+		CreatedBy: user.GetID(),
+		UpdatedBy: user.GetID(),
 	}
 	if err := m.oauthAppDAO.CreateApp(ctx, oauthApp); err != nil {
 		return nil, err
@@ -168,6 +310,18 @@ func (m *OauthManager) GetOAuthApp(ctx context.Context, clientID string) (*model
 	return m.oauthAppDAO.GetApp(ctx, clientID)
 }
 
+func (m *OauthManager) GetOAuthApps(ctx context.Context, clientIDs []string) (map[string]*models.OauthApp, error) {
+	apps, err := m.oauthAppDAO.ListAppByClientIDs(ctx, clientIDs)
+	if err != nil {
+		return nil, err
+	}
+	appMap := make(map[string]*models.OauthApp, len(apps))
+	for i := range apps {
+		appMap[apps[i].ClientID] = &apps[i]
+	}
+	return appMap, nil
+}
+
 func (m *OauthManager) DeleteOAuthApp(ctx context.Context, clientID string) error {
 	// revoke all the token
 	if err := m.tokenStore.DeleteByClientID(ctx, clientID); err != nil {
@@ -178,6 +332,10 @@ func (m *OauthManager) DeleteOAuthApp(ctx context.Context, clientID string) erro
 	if err := m.oauthAppDAO.DeleteSecretByClientID(ctx, clientID); err != nil {
 		return err
 	}
+	// forget the consents of users
+	if err := m.oauthAppDAO.DeleteGrantByClientID(ctx, clientID); err != nil {
+		return err
+	}
 	// delete the app
 	return m.oauthAppDAO.DeleteApp(ctx, clientID)
 }
@@ -193,13 +351,61 @@ func (m *OauthManager) UpdateOauthApp(ctx context.Context, clientID string,
 	if err != nil {
 		return nil, err
 	}
-	return m.oauthAppDAO.UpdateApp(ctx, clientID, models.OauthApp{
-		Name:        req.Name,
-		RedirectURL: req.RedirectURI,
-		HomeURL:     req.HomeURL,
-		Desc:        req.Desc,
-		UpdatedBy:   user.GetID(),
+	oldApp, err := m.oauthAppDAO.GetApp(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	clientType := oldApp.ClientType
+	if req.ClientType != "" {
+		if err := checkClientType(req.ClientType); err != nil {
+			return nil, err
+		}
+		clientType = req.ClientType
+	}
+	accessTokenExpireIn, refreshTokenExpireIn := oldApp.AccessTokenExpireIn, oldApp.RefreshTokenExpireIn
+	if req.AccessTokenExpireIn != nil {
+		accessTokenExpireIn = *req.AccessTokenExpireIn
+	}
+	if req.RefreshTokenExpireIn != nil {
+		refreshTokenExpireIn = *req.RefreshTokenExpireIn
+	}
+	if req.AccessTokenExpireIn != nil || req.RefreshTokenExpireIn != nil {
+		if err := m.checkTokenExpireTime(accessTokenExpireIn, refreshTokenExpireIn); err != nil {
+			return nil, err
+		}
+	}
+	app, err := m.oauthAppDAO.UpdateApp(ctx, clientID, models.OauthApp{
+		Name:                 req.Name,
+		RedirectURL:          req.RedirectURI,
+		HomeURL:              req.HomeURL,
+		Desc:                 req.Desc,
+		ClientType:           clientType,
+		AccessTokenExpireIn:  accessTokenExpireIn,
+		RefreshTokenExpireIn: refreshTokenExpireIn,
+		UpdatedBy:            user.GetID(),
 	})
+	if err != nil {
+		return nil, err
+	}
+	if oldApp.RedirectURL != app.RedirectURL {
+		// codes granted to the old redirect uri should not be exchanged any more
+		if err := m.tokenStore.DeleteAuthorizationCodesByClientID(ctx, clientID); err != nil {
+			return nil, err
+		}
+	}
+	return app, nil
+}
+
+func (m *OauthManager) TransferOauthApp(ctx context.Context, clientID string,
+	newOwnerType models.OwnerType, newOwnerID uint) (*models.OauthApp, error) {
+	if newOwnerType != models.GroupOwnerType {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "owner type %d is not supported", newOwnerType)
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.oauthAppDAO.TransferApp(ctx, clientID, newOwnerType, newOwnerID, user.GetID())
 }
 
 func (m *OauthManager) CreateSecret(ctx context.Context, clientID string) (*models.OauthClientSecret, error) {
@@ -207,14 +413,26 @@ func (m *OauthManager) CreateSecret(ctx context.Context, clientID string) (*mode
 	if err != nil {
 		return nil, err
 	}
+	secret := rand.String(OauthClientSecretLength)
+	hashedSecret, err := hashClientSecret(secret)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrOAuthInternal, "failed to hash client secret: %v", err)
+	}
 	newSecret := &models.OauthClientSecret{
 		// ID:           0, // filled by return
-		ClientID:     clientID,
-		ClientSecret: rand.String(OauthClientSecretLength),
-		CreatedAt:    time.Now(),
-		CreatedBy:    user.GetID(),
+		ClientID:           clientID,
+		ClientSecret:       hashedSecret,
+		ClientSecretSuffix: clientSecretSuffix(secret),
+		CreatedAt:          time.Now(),
+		CreatedBy:          user.GetID(),
 	}
-	return m.oauthAppDAO.CreateSecret(ctx, newSecret)
+	newSecret, err = m.oauthAppDAO.CreateSecret(ctx, newSecret)
+	if err != nil {
+		return nil, err
+	}
+	// only the hash is stored, this is the only chance to get the plaintext
+	newSecret.ClientSecret = secret
+	return newSecret, nil
 }
 
 func (m *OauthManager) DeleteSecret(ctx context.Context, ClientID string, clientSecretID uint) error {
@@ -227,11 +445,10 @@ const (
 	MustPrefix = "*****"
 )
 
+// MuskClientSecrets replaces the hashed secrets with the musked plaintext secrets
 func MuskClientSecrets(clientSecrets []models.OauthClientSecret) {
 	for i := 0; i < len(clientSecrets); i++ {
-		originSecret := clientSecrets[i].ClientSecret
-		muskedSecret := MustPrefix + originSecret[len(originSecret)-CutPostNum:]
-		clientSecrets[i].ClientSecret = muskedSecret
+		clientSecrets[i].ClientSecret = MustPrefix + clientSecrets[i].ClientSecretSuffix
 	}
 }
 
@@ -264,6 +481,10 @@ func (m *OauthManager) NewAuthorizationToken(req *AuthorizeGenerateRequest) *tok
 		ExpiresIn:   m.authorizeCodeExpireTime,
 		Scope:       req.Scope,
 		UserID:      req.UserIdentify,
+
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
 	}
 	token.Code = m.authorizationCodeGenerator.Generate(&generator.CodeGenerateInfo{
 		Token:   *token,
@@ -271,13 +492,15 @@ func (m *OauthManager) NewAuthorizationToken(req *AuthorizeGenerateRequest) *tok
 	})
 	return token
 }
-func (m *OauthManager) NewAccessToken(authorizationCodeToken *tokenmodels.Token,
+
+// NewAccessToken generates an access token, its lifetime is overridden by the app
+func (m *OauthManager) NewAccessToken(app *models.OauthApp, authorizationCodeToken *tokenmodels.Token,
 	req *OauthTokensRequest) *tokenmodels.Token {
 	token := &tokenmodels.Token{
 		ClientID:    req.ClientID,
 		RedirectURI: req.RedirectURL,
 		CreatedAt:   time.Now(),
-		ExpiresIn:   m.accessTokenExpireTime,
+		ExpiresIn:   m.accessTokenExpireTimeOf(app),
 		Scope:       authorizationCodeToken.Scope,
 		UserID:      authorizationCodeToken.UserID,
 	}
@@ -288,13 +511,14 @@ func (m *OauthManager) NewAccessToken(authorizationCodeToken *tokenmodels.Token,
 	return token
 }
 
-func (m *OauthManager) NewRefreshToken(accessToken *tokenmodels.Token,
+// NewRefreshToken generates a refresh token, its lifetime is overridden by the app
+func (m *OauthManager) NewRefreshToken(app *models.OauthApp, accessToken *tokenmodels.Token,
 	req *OauthTokensRequest) *tokenmodels.Token {
 	token := &tokenmodels.Token{
 		ClientID:    req.ClientID,
 		RedirectURI: req.RedirectURL,
 		CreatedAt:   time.Now(),
-		ExpiresIn:   m.refreshTokenExpireTime,
+		ExpiresIn:   m.refreshTokenExpireTimeOf(app),
 		Scope:       accessToken.Scope,
 		UserID:      accessToken.UserID,
 	}
@@ -314,6 +538,13 @@ func (m *OauthManager) GenAuthorizeCode(ctx context.Context,
 		log.Warningf(ctx, "redirect URL not match")
 		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid, "redirect URL not match")
 	}
+	req.CodeChallengeMethod, err = checkCodeChallenge(req.CodeChallenge, req.CodeChallengeMethod)
+	if err != nil {
+		return nil, err
+	}
+	if oauthApp.IsPublicClient() && req.CodeChallenge == "" {
+		return nil, perror.Wrap(herrors.ErrOAuthReqNotValid, "code_challenge is required for public clients")
+	}
 
 	authorizationToken := m.NewAuthorizationToken(req)
 	_, err = m.tokenStore.Create(ctx, authorizationToken)
@@ -321,6 +552,10 @@ func (m *OauthManager) GenAuthorizeCode(ctx context.Context,
 }
 
 func (m *OauthManager) checkByAuthorizationCode(req *OauthTokensRequest, codeToken *tokenmodels.Token) error {
+	if req.ClientID != codeToken.ClientID {
+		return perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"req client id = %s, code client id = %s", req.ClientID, codeToken.ClientID)
+	}
 	if req.RedirectURL != codeToken.RedirectURI {
 		return perror.Wrapf(herrors.ErrOAuthReqNotValid,
 			"req redirect url = %s, code redirect url = %s", req.RedirectURL, codeToken.RedirectURI)
@@ -328,16 +563,10 @@ func (m *OauthManager) checkByAuthorizationCode(req *OauthTokensRequest, codeTok
 	if codeToken.CreatedAt.Add(m.authorizeCodeExpireTime).Before(time.Now()) {
 		return perror.Wrap(herrors.ErrOAuthCodeExpired, "")
 	}
-	return nil
+	return checkCodeVerifier(req.CodeVerifier, codeToken)
 }
 
 func (m *OauthManager) GenOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error) {
-	// check client secret
-	err := m.checkClientSecret(ctx, req)
-	if err != nil {
-		return nil, err
-	}
-
 	// get authorize token, and check by it
 	authorizationCodeToken, err := m.tokenStore.GetByCode(ctx, req.Code)
 	if err != nil {
@@ -347,6 +576,22 @@ func (m *OauthManager) GenOauthTokens(ctx context.Context, req *OauthTokensReque
 		return nil, err
 	}
 
+	// check client secret, public clients prove the possession of the code by PKCE instead
+	oauthApp, err := m.authenticateClient(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if oauthApp.IsPublicClient() && authorizationCodeToken.CodeChallenge == "" {
+		return nil, perror.Wrap(herrors.ErrOAuthReqNotValid, "code_challenge is required for public clients")
+	}
+
+	// a code can only be exchanged once, the tokens issued by a replayed code are revoked, ref: rfc6749 section 4.1.2
+	if authorizationCodeToken.RefID != 0 {
+		m.revokeTokensIssuedByCode(ctx, authorizationCodeToken)
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"authorization code has already been used, id = %d", authorizationCodeToken.ID)
+	}
+
 	if err := m.checkByAuthorizationCode(req, authorizationCodeToken); err != nil {
 		if perror.Cause(err) == herrors.ErrOAuthCodeExpired {
 			if delErr := m.tokenStore.DeleteByCode(ctx, req.Code); delErr != nil {
@@ -356,33 +601,93 @@ func (m *OauthManager) GenOauthTokens(ctx context.Context, req *OauthTokensReque
 		return nil, err
 	}
 
+	tokens, err := m.issueTokensByCode(ctx, req, authorizationCodeToken)
+	if err != nil {
+		return nil, err
+	}
+	tokens.Nonce = authorizationCodeToken.Nonce
+	return tokens, nil
+}
+
+// issueTokensByCode issues an access token and a refresh token by the authorization code or the device code,
+// and consumes the code
+func (m *OauthManager) issueTokensByCode(ctx context.Context, req *OauthTokensRequest,
+	authorizationCodeToken *tokenmodels.Token) (*OauthTokensResponse, error) {
+	oauthApp, err := m.oauthAppDAO.GetApp(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
 	// generate access token and store
-	accessToken := m.NewAccessToken(authorizationCodeToken, req)
+	accessToken := m.NewAccessToken(oauthApp, authorizationCodeToken, req)
 	accessTokenInDB, err := m.tokenStore.Create(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
 
 	// generate refresh token, store and associate with the access token
-	refreshToken := m.NewRefreshToken(accessToken, req)
+	refreshToken := m.NewRefreshToken(oauthApp, accessToken, req)
 	refreshToken.RefID = accessTokenInDB.ID
 	refreshTokenInDB, err := m.tokenStore.Create(ctx, refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
-	// delete authorize code
-	err = m.tokenStore.DeleteByCode(ctx, req.Code)
-	if err != nil {
-		log.Warningf(ctx, "Delete Authorization token error, code = %s, error = %v", req.Code, err)
+	// consume authorize code, only one of the concurrent exchanges of the same code succeeds.
+	// The code is kept until the refresh token expires, so that the token is revoked if the code is replayed.
+	consumed, err := m.tokenStore.ConsumeCode(ctx, authorizationCodeToken.ID, refreshTokenInDB.ID,
+		consumedCodeExpiresIn(authorizationCodeToken, refreshTokenInDB))
+	if err != nil || !consumed {
+		m.deleteTokens(ctx, accessTokenInDB.ID, refreshTokenInDB.ID)
+		if err != nil {
+			return nil, err
+		}
+		if codeInDB, err := m.tokenStore.GetByID(ctx, authorizationCodeToken.ID); err == nil {
+			m.revokeTokensIssuedByCode(ctx, codeInDB)
+		}
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"authorization code has already been used, id = %d", authorizationCodeToken.ID)
 	}
 
+	m.auditToken(ctx, models.TokenAuditActionIssue, accessTokenInDB, req.Request)
 	return &OauthTokensResponse{
 		AccessToken:  accessTokenInDB,
 		RefreshToken: refreshTokenInDB,
 	}, nil
 }
 
+// consumedCodeExpiresIn returns the lifetime of the consumed code which ends with the refresh token issued by it
+func consumedCodeExpiresIn(codeToken, refreshToken *tokenmodels.Token) time.Duration {
+	if refreshToken.ExpiresIn <= 0 {
+		return 0
+	}
+	return refreshToken.CreatedAt.Add(refreshToken.ExpiresIn).Sub(codeToken.CreatedAt)
+}
+
+// revokeTokensIssuedByCode revokes the refresh token issued by the authorization code and its access token
+func (m *OauthManager) revokeTokensIssuedByCode(ctx context.Context, codeToken *tokenmodels.Token) {
+	refreshToken, err := m.tokenStore.GetByID(ctx, codeToken.RefID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			log.Warningf(ctx, "get tokens issued by authorization code error, id = %d, err = %v", codeToken.ID, err)
+		}
+		return
+	}
+	log.Warningf(ctx, "authorization code %d is replayed, revoke tokens issued by it", codeToken.ID)
+	m.deleteTokens(ctx, refreshToken.RefID, refreshToken.ID)
+}
+
+func (m *OauthManager) deleteTokens(ctx context.Context, ids ...uint) {
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		if err := m.tokenStore.DeleteByID(ctx, id); err != nil {
+			log.Warningf(ctx, "delete token error, id = %d, err = %v", id, err)
+		}
+	}
+}
+
 func (m *OauthManager) RefreshOauthTokens(ctx context.Context,
 	req *OauthTokensRequest) (*OauthTokensResponse, error) {
 	// check client secret
@@ -397,8 +702,13 @@ func (m *OauthManager) RefreshOauthTokens(ctx context.Context,
 		return nil, err
 	}
 
+	oauthApp, err := m.oauthAppDAO.GetApp(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
 	// refresh associated access token
-	accessToken, err := m.refreshAccessToken(ctx, refreshToken, req)
+	accessToken, err := m.refreshAccessToken(ctx, oauthApp, refreshToken, req)
 	if err != nil {
 		return nil, err
 	}
@@ -413,24 +723,129 @@ func (m *OauthManager) RefreshOauthTokens(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	m.auditToken(ctx, models.TokenAuditActionRefresh, accessToken, req.Request)
 	return &OauthTokensResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
+// AppIdentity returns the id of the user a direct oauth app acts as by its own credentials
+type AppIdentity func(ctx context.Context, app *models.OauthApp) (uint, error)
+
+func (m *OauthManager) GenClientCredentialsToken(ctx context.Context, clientID, clientSecret, scope string,
+	identity AppIdentity, r *http.Request) (*tokenmodels.Token, error) {
+	// check client secret
+	err := m.checkClientSecret(ctx, &OauthTokensRequest{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// horizon apps act on behalf of users, only direct apps can act as themselves
+	oauthApp, err := m.oauthAppDAO.GetApp(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if oauthApp.AppType != models.DirectOAuthAPP {
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"client credentials grant is not supported by app %s, only direct oauth apps support it", clientID)
+	}
+	userID, err := identity(ctx, oauthApp)
+	if err != nil {
+		return nil, err
+	}
+
+	// no refresh token is issued, the app can request a new token by its credentials at any time
+	token := &tokenmodels.Token{
+		ClientID:  clientID,
+		CreatedAt: time.Now(),
+		ExpiresIn: m.accessTokenExpireTimeOf(oauthApp),
+		Scope:     scope,
+		UserID:    userID,
+	}
+	token.Code = generator.NewOauthAccessGenerator().Generate(&generator.CodeGenerateInfo{
+		Token: *token,
+	})
+	tokenInDB, err := m.tokenStore.Create(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	m.auditToken(ctx, models.TokenAuditActionIssue, tokenInDB, r)
+	return tokenInDB, nil
+}
+
+func (m *OauthManager) RevokeAccessToken(ctx context.Context, clientID, token string, r *http.Request) error {
+	tokenInDB, err := m.tokenStore.GetByCode(ctx, token)
+	if err != nil {
+		// invalid tokens do not cause an error, ref: rfc7009 section 2.2
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	if tokenInDB.ClientID != clientID {
+		return perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"token is not issued to client %s", clientID)
+	}
+
+	// a refresh token refers to its access token, which can not outlive it
+	if tokenInDB.RefID != 0 {
+		if err := m.tokenStore.DeleteByID(ctx, tokenInDB.RefID); err != nil {
+			return err
+		}
+	}
+	if err := m.tokenStore.DeleteByID(ctx, tokenInDB.ID); err != nil {
+		return err
+	}
+	m.auditToken(ctx, models.TokenAuditActionRevoke, tokenInDB, r)
+	return nil
+}
+
+func (m *OauthManager) RevokeTokensByUser(ctx context.Context, userIdentity uint) error {
+	if userIdentity == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "user identity is required")
+	}
+	return m.tokenStore.DeleteByUser(ctx, userIdentity)
+}
+
+// authenticateClient authenticates the client of the token request by its type,
+// confidential clients always authenticate by the client secret, public clients only if a secret is given
+func (m *OauthManager) authenticateClient(ctx context.Context, req *OauthTokensRequest) (*models.OauthApp, error) {
+	oauthApp, err := m.oauthAppDAO.GetApp(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if oauthApp.IsPublicClient() && req.ClientSecret == "" {
+		return oauthApp, nil
+	}
+	if err := m.checkClientSecret(ctx, req); err != nil {
+		return nil, err
+	}
+	return oauthApp, nil
+}
+
+func checkClientType(clientType models.ClientType) error {
+	if clientType != models.ConfidentialClient && clientType != models.PublicClient {
+		return perror.Wrapf(herrors.ErrParamInvalid, "client type %s is not supported", clientType)
+	}
+	return nil
+}
+
 func (m *OauthManager) checkClientSecret(ctx context.Context, req *OauthTokensRequest) error {
-	secrets, err := m.oauthAppDAO.ListSecret(ctx, req.ClientID)
+	// the suffix narrows the candidates, usually to one, before verifying the hashes
+	secrets, err := m.oauthAppDAO.ListSecretBySuffix(ctx, req.ClientID, clientSecretSuffix(req.ClientSecret))
 	if err != nil {
 		return err
 	}
 	for _, secret := range secrets {
-		if secret.ClientSecret == req.ClientSecret {
+		if verifyClientSecret(req.ClientSecret, secret.ClientSecret) {
 			return nil
 		}
 	}
-	return perror.Wrapf(herrors.ErrOAuthSecretNotValid,
-		"clientId = %s, secret = %s", req.ClientID, req.ClientSecret)
+	return perror.Wrapf(herrors.ErrOAuthSecretNotValid, "clientId = %s", req.ClientID)
 }
 
 func (m *OauthManager) checkRefreshToken(ctx context.Context,
@@ -446,20 +861,21 @@ func (m *OauthManager) checkRefreshToken(ctx context.Context,
 		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
 			"req redirect url = %s, token redirect url = %s", redirectURL, token.RedirectURI)
 	}
-	if token.CreatedAt.Add(m.refreshTokenExpireTime).Before(time.Now()) {
+	// refresh tokens carry the lifetimes of their apps at the time they are issued
+	if token.CreatedAt.Add(token.ExpiresIn).Before(time.Now()) {
 		return nil, perror.Wrap(herrors.ErrOAuthRefreshTokenExpired, "")
 	}
 	return token, nil
 }
 
-func (m *OauthManager) refreshAccessToken(ctx context.Context, refreshToken *tokenmodels.Token,
-	req *OauthTokensRequest) (*tokenmodels.Token, error) {
+func (m *OauthManager) refreshAccessToken(ctx context.Context, oauthApp *models.OauthApp,
+	refreshToken *tokenmodels.Token, req *OauthTokensRequest) (*tokenmodels.Token, error) {
 	accessToken, err := m.tokenStore.GetByID(ctx, refreshToken.RefID)
 	accessTokenNotFound := false
 	if err != nil {
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
-			// TODO(zhuxu): remove this log after adding token cleanup policy
-			log.Warningf(ctx, "associated access token does not exist, id: %d", refreshToken.RefID)
+			// expired access tokens are purged by the token clean job
+			log.Infof(ctx, "associated access token does not exist, id: %d", refreshToken.RefID)
 			accessTokenNotFound = true
 		} else {
 			return nil, err
@@ -467,7 +883,7 @@ func (m *OauthManager) refreshAccessToken(ctx context.Context, refreshToken *tok
 	}
 	if accessTokenNotFound {
 		// generate new access token and insert to db
-		token := m.NewAccessToken(&tokenmodels.Token{
+		token := m.NewAccessToken(oauthApp, &tokenmodels.Token{
 			Scope:  refreshToken.Scope,
 			UserID: refreshToken.UserID,
 		}, req)
@@ -476,12 +892,13 @@ func (m *OauthManager) refreshAccessToken(ctx context.Context, refreshToken *tok
 			return nil, err
 		}
 	} else {
-		// update token code and creation time
+		// update token code, creation time and lifetime
 		accessToken.Code = req.AccessTokenGenerator.Generate(&generator.CodeGenerateInfo{
 			Token:   *accessToken,
 			Request: req.Request,
 		})
 		accessToken.CreatedAt = time.Now()
+		accessToken.ExpiresIn = m.accessTokenExpireTimeOf(oauthApp)
 		err = m.tokenStore.UpdateByID(ctx, accessToken.ID, accessToken)
 		if err != nil {
 			return nil, err
@@ -15,8 +15,10 @@
 package manager
 
 import (
+	"net/http"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
@@ -105,6 +108,22 @@ func TestOauthAppBasic(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(apps))
 
+	_, err = oauthManager.TransferOauthApp(ctx, oauthApp.ClientID, models.OwnerType(2), 2)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = oauthManager.TransferOauthApp(ctx, "not-exist", models.GroupOwnerType, 2)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	transferRet, err := oauthManager.TransferOauthApp(ctx, oauthApp.ClientID, models.GroupOwnerType, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), transferRet.OwnerID)
+	assert.Equal(t, updateReq.Name, transferRet.Name)
+	apps, err = oauthManager.ListOauthApp(ctx, models.GroupOwnerType, createReq.OwnerID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(apps))
+	apps, err = oauthManager.ListOauthApp(ctx, models.GroupOwnerType, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(apps))
+
 	err = oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID)
 	assert.Nil(t, err)
 
@@ -160,6 +179,40 @@ func TestClientSecretBasic(t *testing.T) {
 			assert.Fail(t, "secret error")
 		}
 	}
+
+	// secrets are hashed in db, and can be verified by the plaintext
+	secretsInDB, err := oauthAppDAO.ListSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+	for _, secret := range secretsInDB {
+		assert.NotEqual(t, secret1.ClientSecret, secret.ClientSecret)
+		assert.NotEqual(t, secret2.ClientSecret, secret.ClientSecret)
+		assert.True(t, strings.HasPrefix(secret.ClientSecret, secretHashAlgorithm+secretHashSeparator))
+	}
+	m := oauthManager.(*OauthManager)
+	for _, secret := range []string{secret1.ClientSecret, secret2.ClientSecret} {
+		assert.Nil(t, m.checkClientSecret(ctx, &OauthTokensRequest{ClientID: oauthApp.ClientID, ClientSecret: secret}))
+	}
+	err = m.checkClientSecret(ctx, &OauthTokensRequest{
+		ClientID:     oauthApp.ClientID,
+		ClientSecret: "wrong" + secret1.ClientSecret[len("wrong"):],
+	})
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+}
+
+func TestVerifyClientSecret(t *testing.T) {
+	hashed, err := hashClientSecret("secret")
+	assert.Nil(t, err)
+	assert.True(t, verifyClientSecret("secret", hashed))
+	assert.False(t, verifyClientSecret("Secret", hashed))
+	another, err := hashClientSecret("secret")
+	assert.Nil(t, err)
+	assert.NotEqual(t, hashed, another)
+
+	// plaintext secrets are migrated by sql in the same format
+	assert.True(t, verifyClientSecret("secret",
+		"sha256$0123456789abcdef0123456789abcdef$"+
+			"0bcca54fe4bc681d31dd1922d1cf4e8e3d4548a4bcca8fd33d47cbec06fd41e0"))
+	assert.False(t, verifyClientSecret("secret", "secret"))
 }
 
 func checkAuthorizeToken(req *AuthorizeGenerateRequest, token *tokenmodels.Token) bool {
@@ -513,9 +566,737 @@ func TestUpdateToken(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestGetOAuthApps(t *testing.T) {
+	createReq := &CreateOAuthAppReq{
+		Name:        "OauthTest",
+		RedirectURI: "https://example.com/oauth/redirect",
+		HomeURL:     "https://example.com",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+	}
+	app1, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	app2, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, app1.ClientID))
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, app2.ClientID))
+	}()
+
+	apps, err := oauthManager.GetOAuthApps(ctx, []string{app1.ClientID, app2.ClientID, "not-exist"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(apps))
+	assert.Equal(t, app1.ID, apps[app1.ClientID].ID)
+	assert.Equal(t, app2.ID, apps[app2.ClientID].ID)
+
+	apps, err = oauthManager.GetOAuthApps(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(apps))
+}
+
+func BenchmarkCheckClientSecret(b *testing.B) {
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "OauthBenchmark",
+		RedirectURI: "https://example.com/oauth/redirect",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID) }()
+
+	var secret *models.OauthClientSecret
+	for i := 0; i < 100; i++ {
+		if secret, err = oauthManager.CreateSecret(ctx, oauthApp.ClientID); err != nil {
+			b.Fatal(err)
+		}
+	}
+	m := oauthManager.(*OauthManager)
+	req := &OauthTokensRequest{ClientID: oauthApp.ClientID, ClientSecret: secret.ClientSecret}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := m.checkClientSecret(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	createReq := &CreateOAuthAppReq{
+		Name:        "pkce-test",
+		RedirectURI: "http://127.0.0.1:8765/callback",
+		HomeURL:     "https://pkce.com",
+		Desc:        "This is a public oauth app for testing pkce",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+		ClientType:  models.PublicClient,
+	}
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	defer func() {
+		err = oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID)
+		assert.Nil(t, err)
+	}()
+
+	// the example of rfc7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	authorizeReq := func(challenge, method string) *AuthorizeGenerateRequest {
+		return &AuthorizeGenerateRequest{
+			ClientID:            oauthApp.ClientID,
+			RedirectURL:         oauthApp.RedirectURL,
+			State:               "pkce-state",
+			UserIdentify:        43,
+			CodeChallenge:       challenge,
+			CodeChallengeMethod: method,
+		}
+	}
+	tokensReq := func(code, verifier string) *OauthTokensRequest {
+		return &OauthTokensRequest{
+			ClientID:              oauthApp.ClientID,
+			Code:                  code,
+			CodeVerifier:          verifier,
+			RedirectURL:           oauthApp.RedirectURL,
+			AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+			RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+		}
+	}
+
+	// invalid challenges
+	_, err = oauthManager.GenAuthorizeCode(ctx, authorizeReq(challenge, "S512"))
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	_, err = oauthManager.GenAuthorizeCode(ctx, authorizeReq("short", CodeChallengeMethodS256))
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	_, err = oauthManager.GenAuthorizeCode(ctx, authorizeReq("", CodeChallengeMethodS256))
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	// S256 without client secret
+	codeToken, err := oauthManager.GenAuthorizeCode(ctx, authorizeReq(challenge, CodeChallengeMethodS256))
+	assert.Nil(t, err)
+	assert.Equal(t, challenge, codeToken.CodeChallenge)
+	_, err = oauthManager.GenOauthTokens(ctx, tokensReq(codeToken.Code, ""))
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	_, err = oauthManager.GenOauthTokens(ctx, tokensReq(codeToken.Code, challenge))
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	otherClientReq := tokensReq(codeToken.Code, verifier)
+	otherClientReq.ClientID = "other-client"
+	_, err = oauthManager.GenOauthTokens(ctx, otherClientReq)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	oauthTokens, err := oauthManager.GenOauthTokens(ctx, tokensReq(codeToken.Code, verifier))
+	assert.Nil(t, err)
+	assert.NotNil(t, oauthTokens.AccessToken)
+
+	// plain is the default method
+	codeToken, err = oauthManager.GenAuthorizeCode(ctx, authorizeReq(verifier, ""))
+	assert.Nil(t, err)
+	assert.Equal(t, CodeChallengeMethodPlain, codeToken.CodeChallengeMethod)
+	_, err = oauthManager.GenOauthTokens(ctx, tokensReq(codeToken.Code, verifier))
+	assert.Nil(t, err)
+
+	// public clients must use PKCE
+	_, err = oauthManager.GenAuthorizeCode(ctx, authorizeReq("", ""))
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	// confidential clients always authenticate by the client secret, even with PKCE
+	_, err = oauthManager.UpdateOauthApp(ctx, oauthApp.ClientID, UpdateOauthAppReq{
+		Name:        oauthApp.Name,
+		HomeURL:     oauthApp.HomeURL,
+		RedirectURI: oauthApp.RedirectURL,
+		Desc:        oauthApp.Desc,
+		ClientType:  models.ConfidentialClient,
+	})
+	assert.Nil(t, err)
+	codeToken, err = oauthManager.GenAuthorizeCode(ctx, authorizeReq(challenge, CodeChallengeMethodS256))
+	assert.Nil(t, err)
+	_, err = oauthManager.GenOauthTokens(ctx, tokensReq(codeToken.Code, verifier))
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+	confidentialReq := tokensReq(codeToken.Code, verifier)
+	confidentialReq.ClientSecret = secret.ClientSecret
+	_, err = oauthManager.GenOauthTokens(ctx, confidentialReq)
+	assert.Nil(t, err)
+
+	// the client type is validated
+	_, err = oauthManager.UpdateOauthApp(ctx, oauthApp.ClientID, UpdateOauthAppReq{
+		Name:       oauthApp.Name,
+		ClientType: "unknown",
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+// appUserID is the user the direct apps act as by their own credentials in tests
+const appUserID uint = 1000
+
+func appIdentity(ctx context.Context, app *models.OauthApp) (uint, error) {
+	return appUserID, nil
+}
+
+func TestClientCredentials(t *testing.T) {
+	createReq := &CreateOAuthAppReq{
+		Name:        "client-credentials-test",
+		RedirectURI: "https://machine.com/oauth/redirect",
+		HomeURL:     "https://machine.com",
+		Desc:        "This is an oauth app for testing client credentials grant",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+	}
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+
+	// case 1: client secret is wrong
+	_, err = oauthManager.GenClientCredentialsToken(ctx, oauthApp.ClientID, "wrong-secret", "", appIdentity, nil)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+
+	// case 2: ok, the token acts as the identity of the app rather than its creator
+	token, err := oauthManager.GenClientCredentialsToken(ctx, oauthApp.ClientID, secret.ClientSecret,
+		"clusters:read-only", appIdentity, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, appUserID, token.UserID)
+	assert.NotEqual(t, aUser.GetID(), token.UserID)
+	assert.Equal(t, oauthApp.ClientID, token.ClientID)
+	assert.Equal(t, "clusters:read-only", token.Scope)
+	assert.Equal(t, accessTokenExpireIn, token.ExpiresIn)
+	loaded, err := tokenManager.LoadTokenByCode(ctx, token.Code)
+	assert.Nil(t, err)
+	assert.Equal(t, token.ID, loaded.ID)
+
+	// case 3: the token is revoked with the app
+	assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	_, err = tokenManager.LoadTokenByCode(ctx, token.Code)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// case 4: horizon apps are not supported
+	createReq.APPType = models.HorizonOAuthAPP
+	horizonApp, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, horizonApp.ClientID))
+	}()
+	secret, err = oauthManager.CreateSecret(ctx, horizonApp.ClientID)
+	assert.Nil(t, err)
+	_, err = oauthManager.GenClientCredentialsToken(ctx, horizonApp.ClientID, secret.ClientSecret, "",
+		appIdentity, nil)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	createReq := &CreateOAuthAppReq{
+		Name:        "revoke-token-test",
+		RedirectURI: "https://revoke.com/oauth/redirect",
+		HomeURL:     "https://revoke.com",
+		Desc:        "This is an oauth app for testing token revocation",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+	}
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	}()
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+
+	genTokens := func() *OauthTokensResponse {
+		authorizeCode, err := oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+			ClientID:     oauthApp.ClientID,
+			RedirectURL:  oauthApp.RedirectURL,
+			State:        "test-state",
+			UserIdentify: 43,
+		})
+		assert.Nil(t, err)
+		tokens, err := oauthManager.GenOauthTokens(ctx, &OauthTokensRequest{
+			ClientID:              oauthApp.ClientID,
+			ClientSecret:          secret.ClientSecret,
+			Code:                  authorizeCode.Code,
+			RedirectURL:           oauthApp.RedirectURL,
+			AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+			RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+		})
+		assert.Nil(t, err)
+		return tokens
+	}
+	isRevoked := func(code string) bool {
+		_, err := tokenManager.LoadTokenByCode(ctx, code)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		return ok
+	}
+	tokens1 := genTokens()
+	tokens2 := genTokens()
+
+	// case 1: the token is issued to another client
+	err = oauthManager.RevokeAccessToken(ctx, "another-client", tokens1.AccessToken.Code, nil)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	assert.False(t, isRevoked(tokens1.AccessToken.Code))
+
+	// case 2: revoke an access token, other tokens of the app still work
+	assert.Nil(t, oauthManager.RevokeAccessToken(ctx, oauthApp.ClientID, tokens1.AccessToken.Code, nil))
+	assert.True(t, isRevoked(tokens1.AccessToken.Code))
+	assert.False(t, isRevoked(tokens1.RefreshToken.Code))
+	assert.False(t, isRevoked(tokens2.AccessToken.Code))
+
+	// case 3: revoke a refresh token, its access token is revoked as well
+	assert.Nil(t, oauthManager.RevokeAccessToken(ctx, oauthApp.ClientID, tokens2.RefreshToken.Code, nil))
+	assert.True(t, isRevoked(tokens2.RefreshToken.Code))
+	assert.True(t, isRevoked(tokens2.AccessToken.Code))
+
+	// case 4: revoking a token which does not exist is ok
+	assert.Nil(t, oauthManager.RevokeAccessToken(ctx, oauthApp.ClientID, tokens1.AccessToken.Code, nil))
+	assert.Nil(t, oauthManager.RevokeAccessToken(ctx, oauthApp.ClientID, "not-exist", nil))
+}
+
+func TestRevokeTokensByUser(t *testing.T) {
+	genTokens := func(name string, userID uint) []string {
+		oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+			Name:        name,
+			RedirectURI: "https://revoke.com/oauth/redirect",
+			HomeURL:     "https://revoke.com",
+			Desc:        "This is an oauth app for testing token revocation of users",
+			OwnerType:   models.GroupOwnerType,
+			OwnerID:     1,
+			APPType:     models.HorizonOAuthAPP,
+		})
+		assert.Nil(t, err)
+		secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+		assert.Nil(t, err)
+		authorizeCode, err := oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+			ClientID:     oauthApp.ClientID,
+			RedirectURL:  oauthApp.RedirectURL,
+			State:        "test-state",
+			UserIdentify: userID,
+		})
+		assert.Nil(t, err)
+		tokens, err := oauthManager.GenOauthTokens(ctx, &OauthTokensRequest{
+			ClientID:              oauthApp.ClientID,
+			ClientSecret:          secret.ClientSecret,
+			Code:                  authorizeCode.Code,
+			RedirectURL:           oauthApp.RedirectURL,
+			AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+			RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+		})
+		assert.Nil(t, err)
+		return []string{tokens.AccessToken.Code, tokens.RefreshToken.Code}
+	}
+	isRevoked := func(code string) bool {
+		_, err := tokenManager.LoadTokenByCode(ctx, code)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		return ok
+	}
+	userTokens := append(genTokens("revoke-user-test-1", 51), genTokens("revoke-user-test-2", 51)...)
+	otherTokens := genTokens("revoke-user-test-3", 52)
+
+	err := oauthManager.RevokeTokensByUser(ctx, 0)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	assert.Nil(t, oauthManager.RevokeTokensByUser(ctx, 51))
+	for _, code := range userTokens {
+		assert.True(t, isRevoked(code))
+	}
+	for _, code := range otherTokens {
+		assert.False(t, isRevoked(code))
+	}
+}
+
+func TestUpdateRedirectURIInvalidatesCodes(t *testing.T) {
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "update-redirect-test",
+		RedirectURI: "https://update.com/oauth/redirect",
+		HomeURL:     "https://update.com",
+		Desc:        "This is an oauth app for testing update of redirect uri",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+	})
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID)) }()
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+	genCode := func(redirectURI string) string {
+		authorizeCode, err := oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+			ClientID:     oauthApp.ClientID,
+			RedirectURL:  redirectURI,
+			State:        "test-state",
+			UserIdentify: aUser.GetID(),
+		})
+		assert.Nil(t, err)
+		return authorizeCode.Code
+	}
+	isRevoked := func(code string) bool {
+		_, err := tokenStore.GetByCode(ctx, code)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		return ok
+	}
+	tokens, err := oauthManager.GenOauthTokens(ctx, &OauthTokensRequest{
+		ClientID:              oauthApp.ClientID,
+		ClientSecret:          secret.ClientSecret,
+		Code:                  genCode(oauthApp.RedirectURL),
+		RedirectURL:           oauthApp.RedirectURL,
+		AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	})
+	assert.Nil(t, err)
+
+	// codes are kept if the redirect uri is not changed
+	code := genCode(oauthApp.RedirectURL)
+	_, err = oauthManager.UpdateOauthApp(ctx, oauthApp.ClientID, UpdateOauthAppReq{
+		Name:        oauthApp.Name,
+		HomeURL:     oauthApp.HomeURL,
+		RedirectURI: oauthApp.RedirectURL,
+		Desc:        "desc updated",
+	})
+	assert.Nil(t, err)
+	assert.False(t, isRevoked(code))
+
+	updated, err := oauthManager.UpdateOauthApp(ctx, oauthApp.ClientID, UpdateOauthAppReq{
+		Name:        oauthApp.Name,
+		HomeURL:     oauthApp.HomeURL,
+		RedirectURI: "https://update.com/oauth/redirect2",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://update.com/oauth/redirect2", updated.RedirectURL)
+	assert.True(t, isRevoked(code))
+	assert.False(t, isRevoked(tokens.AccessToken.Code))
+	assert.False(t, isRevoked(tokens.RefreshToken.Code))
+
+	_, err = oauthManager.UpdateOauthApp(ctx, "not-exist", UpdateOauthAppReq{Name: "not-exist"})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestAuthorizationCodeReplay(t *testing.T) {
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "code-replay-test",
+		RedirectURI: "https://replay.com/oauth/redirect",
+		HomeURL:     "https://replay.com",
+		Desc:        "This is an oauth app for testing replay of authorization codes",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+	})
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID)) }()
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+	authorizeCode, err := oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+		ClientID:     oauthApp.ClientID,
+		RedirectURL:  oauthApp.RedirectURL,
+		State:        "test-state",
+		UserIdentify: aUser.GetID(),
+	})
+	assert.Nil(t, err)
+	tokensReq := &OauthTokensRequest{
+		ClientID:              oauthApp.ClientID,
+		ClientSecret:          secret.ClientSecret,
+		Code:                  authorizeCode.Code,
+		RedirectURL:           oauthApp.RedirectURL,
+		AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	}
+	isRevoked := func(code string) bool {
+		_, err := tokenStore.GetByCode(ctx, code)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		return ok
+	}
+
+	tokens, err := oauthManager.GenOauthTokens(ctx, tokensReq)
+	assert.Nil(t, err)
+	assert.False(t, isRevoked(tokens.AccessToken.Code))
+	assert.False(t, isRevoked(tokens.RefreshToken.Code))
+
+	// the code can not be consumed again
+	consumed, err := tokenStore.ConsumeCode(ctx, authorizeCode.ID, tokens.RefreshToken.ID, 0)
+	assert.Nil(t, err)
+	assert.False(t, consumed)
+
+	// the consumed code is kept as long as the refresh token
+	codeInDB, err := tokenStore.GetByID(ctx, authorizeCode.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, tokens.RefreshToken.ID, codeInDB.RefID)
+	assert.WithinDuration(t, tokens.RefreshToken.CreatedAt.Add(tokens.RefreshToken.ExpiresIn),
+		codeInDB.CreatedAt.Add(codeInDB.ExpiresIn), time.Second)
+
+	// the replayed code is rejected, and the tokens issued by it are revoked
+	_, err = oauthManager.GenOauthTokens(ctx, tokensReq)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	assert.True(t, isRevoked(tokens.AccessToken.Code))
+	assert.True(t, isRevoked(tokens.RefreshToken.Code))
+}
+
+func TestGrant(t *testing.T) {
+	createReq := &CreateOAuthAppReq{
+		Name:        "GrantApp",
+		RedirectURI: "https://example.com/oauth/redirect",
+		HomeURL:     "https://example.com",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+	}
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	userID := aUser.GetID()
+
+	// nothing is granted before the user consents
+	granted, err := oauthManager.IsGranted(ctx, userID, oauthApp.ClientID, "clusters:read-only")
+	assert.Nil(t, err)
+	assert.False(t, granted)
+
+	assert.Nil(t, oauthManager.Grant(ctx, userID, oauthApp.ClientID, "clusters:read-only"))
+	granted, err = oauthManager.IsGranted(ctx, userID, oauthApp.ClientID, "clusters:read-only")
+	assert.Nil(t, err)
+	assert.True(t, granted)
+	// the grant belongs to the user only
+	granted, err = oauthManager.IsGranted(ctx, userID+1, oauthApp.ClientID, "clusters:read-only")
+	assert.Nil(t, err)
+	assert.False(t, granted)
+
+	// more scopes need the consent again, and are granted along with the former ones
+	granted, err = oauthManager.IsGranted(ctx, userID, oauthApp.ClientID, "applications:read-write clusters:read-only")
+	assert.Nil(t, err)
+	assert.False(t, granted)
+	assert.Nil(t, oauthManager.Grant(ctx, userID, oauthApp.ClientID, "applications:read-write"))
+	granted, err = oauthManager.IsGranted(ctx, userID, oauthApp.ClientID, "clusters:read-only  applications:read-write")
+	assert.Nil(t, err)
+	assert.True(t, granted)
+	grant, err := oauthAppDAO.GetGrant(ctx, userID, oauthApp.ClientID)
+	assert.Nil(t, err)
+	assert.Equal(t, "applications:read-write clusters:read-only", grant.Scope)
+
+	assert.Nil(t, oauthManager.RevokeGrant(ctx, userID, oauthApp.ClientID))
+	granted, err = oauthManager.IsGranted(ctx, userID, oauthApp.ClientID, "clusters:read-only")
+	assert.Nil(t, err)
+	assert.False(t, granted)
+
+	// grants are forgotten along with the app
+	assert.Nil(t, oauthManager.Grant(ctx, userID, oauthApp.ClientID, "clusters:read-only"))
+	assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	_, err = oauthAppDAO.GetGrant(ctx, userID, oauthApp.ClientID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestAppTokenExpireTime(t *testing.T) {
+	mgr := NewManager(oauthAppDAO, tokenStore, generator.NewOauthAccessGenerator(),
+		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn)
+	mgr.SetMaxTokenExpireTime(time.Hour, time.Hour*2)
+
+	createReq := &CreateOAuthAppReq{
+		Name:        "token-expire-test",
+		RedirectURI: "https://machine.com/oauth/redirect",
+		HomeURL:     "https://machine.com",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+	}
+
+	// case 1: lifetimes beyond the maximums are rejected
+	createReq.AccessTokenExpireIn = time.Hour * 2
+	_, err := mgr.CreateOauthApp(ctx, createReq)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	createReq.AccessTokenExpireIn = -time.Second
+	_, err = mgr.CreateOauthApp(ctx, createReq)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// case 2: the app without overrides gets the defaults
+	createReq.AccessTokenExpireIn = 0
+	oauthApp, err := mgr.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, mgr.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	}()
+	secret, err := mgr.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+	token, err := mgr.GenClientCredentialsToken(ctx, oauthApp.ClientID, secret.ClientSecret, "",
+		appIdentity, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, accessTokenExpireIn, token.ExpiresIn)
+
+	// case 3: the overrides are used, and kept when updating other fields
+	accessExpire, refreshExpire := time.Minute*30, time.Hour
+	_, err = mgr.UpdateOauthApp(ctx, oauthApp.ClientID, UpdateOauthAppReq{
+		Name:                 createReq.Name,
+		RedirectURI:          createReq.RedirectURI,
+		AccessTokenExpireIn:  &accessExpire,
+		RefreshTokenExpireIn: &refreshExpire,
+	})
+	assert.Nil(t, err)
+	updated, err := mgr.UpdateOauthApp(ctx, oauthApp.ClientID, UpdateOauthAppReq{
+		Name:        createReq.Name,
+		RedirectURI: createReq.RedirectURI,
+		Desc:        "updated",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, accessExpire, updated.AccessTokenExpireIn)
+	assert.Equal(t, refreshExpire, updated.RefreshTokenExpireIn)
+	token, err = mgr.GenClientCredentialsToken(ctx, oauthApp.ClientID, secret.ClientSecret, "",
+		appIdentity, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, accessExpire, token.ExpiresIn)
+
+	// case 4: overrides beyond a lowered maximum are bounded
+	mgr.SetMaxTokenExpireTime(time.Minute*10, 0)
+	token, err = mgr.GenClientCredentialsToken(ctx, oauthApp.ClientID, secret.ClientSecret, "",
+		appIdentity, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute*10, token.ExpiresIn)
+}
+
+func TestTokenAudit(t *testing.T) {
+	createReq := &CreateOAuthAppReq{
+		Name:        "token-audit-test",
+		RedirectURI: "https://machine.com/oauth/redirect",
+		HomeURL:     "https://machine.com",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+	}
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, createReq)
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	}()
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+
+	// case 1: the issuance is recorded with the source ip forwarded by proxies
+	r := &http.Request{Header: http.Header{}, RemoteAddr: "10.0.0.1:34567"}
+	r.Header.Set("X-Forwarded-For", "192.168.1.1, 10.0.0.2")
+	token, err := oauthManager.GenClientCredentialsToken(ctx, oauthApp.ClientID, secret.ClientSecret,
+		"clusters:read-only", appIdentity, r)
+	assert.Nil(t, err)
+
+	// case 2: failures are not recorded
+	_, err = oauthManager.GenClientCredentialsToken(ctx, oauthApp.ClientID, "wrong-secret", "", appIdentity, r)
+	assert.NotNil(t, err)
+
+	// case 3: the revocation is recorded with the remote address
+	r = &http.Request{Header: http.Header{}, RemoteAddr: "10.0.0.1:34567"}
+	assert.Nil(t, oauthManager.RevokeAccessToken(ctx, oauthApp.ClientID, token.Code, r))
+
+	audits, total, err := oauthManager.ListTokenAudits(ctx, q.New(q.KeyWords{
+		common.OauthTokenAuditQueryClientID: oauthApp.ClientID,
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, models.TokenAuditActionRevoke, audits[0].Action)
+	assert.Equal(t, "10.0.0.1", audits[0].SourceIP)
+	assert.Equal(t, models.TokenAuditActionIssue, audits[1].Action)
+	assert.Equal(t, "192.168.1.1", audits[1].SourceIP)
+	assert.Equal(t, aUser.GetID(), audits[1].UserID)
+	assert.Equal(t, "clusters:read-only", audits[1].Scope)
+	assert.Equal(t, token.ID, audits[1].TokenID)
+
+	// case 4: filter by action and source ip
+	audits, total, err = oauthManager.ListTokenAudits(ctx, q.New(q.KeyWords{
+		common.OauthTokenAuditQueryClientID: oauthApp.ClientID,
+		common.OauthTokenAuditQueryAction:   models.TokenAuditActionIssue,
+		common.OauthTokenAuditQuerySourceIP: "192.168.1.1",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, token.ID, audits[0].TokenID)
+}
+
+func TestDeviceCode(t *testing.T) {
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "device-code-test",
+		RedirectURI: "https://device.com/oauth/redirect",
+		HomeURL:     "https://device.com",
+		Desc:        "This is an oauth app for testing device authorization grant",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+		ClientType:  models.PublicClient,
+	})
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID)) }()
+
+	deviceCode, err := oauthManager.GenDeviceCode(ctx, oauthApp.ClientID, "applications:read-only", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(deviceCode.UserCode))
+	assert.Equal(t, uint(0), deviceCode.UserID)
+
+	tokensReq := &OauthTokensRequest{
+		ClientID:              oauthApp.ClientID,
+		Code:                  deviceCode.Code,
+		AccessTokenGenerator:  generator.NewHorizonAppUserToServerAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	}
+
+	// the client keeps polling until the user approves
+	_, err = oauthManager.GenDeviceTokens(ctx, tokensReq)
+	assert.Equal(t, herrors.ErrOAuthAuthorizationPending, perror.Cause(err))
+
+	// the device code is only exchanged by the client it is issued to
+	_, err = oauthManager.GenDeviceTokens(ctx, &OauthTokensRequest{
+		ClientID: "another-client",
+		Code:     deviceCode.Code,
+	})
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	// the user code is typed in lower case with the separator
+	userCode := strings.ToLower(FormatUserCode(deviceCode.UserCode))
+	pending, err := oauthManager.GetDeviceCode(ctx, userCode)
+	assert.Nil(t, err)
+	assert.Equal(t, deviceCode.ID, pending.ID)
+	approved, err := oauthManager.ApproveDeviceCode(ctx, userCode, aUser.GetID())
+	assert.Nil(t, err)
+	assert.Equal(t, aUser.GetID(), approved.UserID)
+	_, err = oauthManager.ApproveDeviceCode(ctx, userCode, aUser.GetID())
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	tokens, err := oauthManager.GenDeviceTokens(ctx, tokensReq)
+	assert.Nil(t, err)
+	assert.Equal(t, aUser.GetID(), tokens.AccessToken.UserID)
+	assert.Equal(t, "applications:read-only", tokens.AccessToken.Scope)
+	assert.True(t, strings.HasPrefix(tokens.AccessToken.Code, generator.HorizonAppUserToServerAccessTokenPrefix))
+
+	// the replayed device code is rejected, and the tokens issued by it are revoked
+	_, err = oauthManager.GenDeviceTokens(ctx, tokensReq)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	_, err = tokenStore.GetByCode(ctx, tokens.AccessToken.Code)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// a denied device code is deleted
+	deniedCode, err := oauthManager.GenDeviceCode(ctx, oauthApp.ClientID, "", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, oauthManager.DenyDeviceCode(ctx, deniedCode.UserCode))
+	_, err = oauthManager.GenDeviceTokens(ctx, &OauthTokensRequest{
+		ClientID: oauthApp.ClientID,
+		Code:     deniedCode.Code,
+	})
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// device codes expire as authorization codes by default
+	expiredCode, err := oauthManager.GenDeviceCode(ctx, oauthApp.ClientID, "", nil)
+	assert.Nil(t, err)
+	time.Sleep(authorizeCodeExpireIn)
+	_, err = oauthManager.GetDeviceCode(ctx, expiredCode.UserCode)
+	assert.Equal(t, herrors.ErrOAuthCodeExpired, perror.Cause(err))
+	_, err = oauthManager.GenDeviceTokens(ctx, &OauthTokensRequest{
+		ClientID: oauthApp.ClientID,
+		Code:     expiredCode.Code,
+	})
+	assert.Equal(t, herrors.ErrOAuthCodeExpired, perror.Cause(err))
+}
+
 func TestMain(m *testing.M) {
 	db, _ = orm.NewSqliteDB("")
-	if err := db.AutoMigrate(&tokenmodels.Token{}, &models.OauthApp{}, &models.OauthClientSecret{}); err != nil {
+	if err := db.AutoMigrate(&tokenmodels.Token{}, &models.OauthApp{}, &models.OauthClientSecret{},
+		&models.OauthGrant{}, &models.OauthTokenAudit{}); err != nil {
 		panic(err)
 	}
 	db = db.WithContext(context.WithValue(context.Background(), common.UserContextKey(), aUser))
@@ -0,0 +1,78 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"regexp"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+)
+
+// PKCE (Proof Key for Code Exchange), ref: rfc7636
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
+)
+
+// pkceValuePattern matches code verifiers and code challenges, which are 43 to 128 unreserved characters
+var pkceValuePattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// checkCodeChallenge checks the challenge of an authorization request and returns the method,
+// which defaults to plain as rfc7636 specifies
+func checkCodeChallenge(challenge, method string) (string, error) {
+	if challenge == "" {
+		if method != "" {
+			return "", perror.Wrap(herrors.ErrOAuthReqNotValid, "code_challenge is required with code_challenge_method")
+		}
+		return "", nil
+	}
+	if method == "" {
+		method = CodeChallengeMethodPlain
+	}
+	if method != CodeChallengeMethodPlain && method != CodeChallengeMethodS256 {
+		return "", perror.Wrapf(herrors.ErrOAuthReqNotValid, "code_challenge_method %s is not supported", method)
+	}
+	if !pkceValuePattern.MatchString(challenge) {
+		return "", perror.Wrap(herrors.ErrOAuthReqNotValid, "code_challenge is invalid")
+	}
+	return method, nil
+}
+
+// checkCodeVerifier verifies the verifier of a token request by the challenge of the authorization code
+func checkCodeVerifier(verifier string, codeToken *tokenmodels.Token) error {
+	if codeToken.CodeChallenge == "" {
+		if verifier != "" {
+			return perror.Wrap(herrors.ErrOAuthReqNotValid, "code_verifier is given without code_challenge")
+		}
+		return nil
+	}
+	if !pkceValuePattern.MatchString(verifier) {
+		return perror.Wrap(herrors.ErrOAuthReqNotValid, "code_verifier is missing or invalid")
+	}
+	challenge := verifier
+	if codeToken.CodeChallengeMethod == CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(codeToken.CodeChallenge)) != 1 {
+		return perror.Wrap(herrors.ErrOAuthReqNotValid, "code_verifier does not match code_challenge")
+	}
+	return nil
+}
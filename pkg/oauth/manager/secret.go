@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// client secrets are stored as "sha256$<salt>$<hex of sha256(salt + secret)>",
+// tb_oauth_client_secret migrates plaintext secrets in the same format
+const (
+	secretHashAlgorithm = "sha256"
+	secretHashSeparator = "$"
+	secretSaltLength    = 16
+)
+
+// hashClientSecret hashes the secret with a random salt
+func hashClientSecret(secret string) (string, error) {
+	salt := make([]byte, secretSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hashClientSecretWithSalt(secret, hex.EncodeToString(salt)), nil
+}
+
+func hashClientSecretWithSalt(secret, salt string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return strings.Join([]string{secretHashAlgorithm, salt, hex.EncodeToString(sum[:])}, secretHashSeparator)
+}
+
+// verifyClientSecret checks the secret against the hashed one stored
+func verifyClientSecret(secret, hashed string) bool {
+	parts := strings.Split(hashed, secretHashSeparator)
+	if len(parts) != 3 || parts[0] != secretHashAlgorithm {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashClientSecretWithSalt(secret, parts[1])), []byte(hashed)) == 1
+}
+
+// clientSecretSuffix is the part of secret kept in plaintext, to show the secret musked and to look it up
+func clientSecretSuffix(secret string) string {
+	if len(secret) <= CutPostNum {
+		return secret
+	}
+	return secret[len(secret)-CutPostNum:]
+}
@@ -38,16 +38,32 @@ const (
 	DirectOAuthAPP  AppType = 2
 )
 
+// ClientType is the client type of rfc6749 section 2.1
+type ClientType string
+
+const (
+	// ConfidentialClient keeps its secrets, and always authenticates itself by the client secret
+	ConfidentialClient ClientType = "confidential"
+	// PublicClient can't keep a secret, such as a native app or a cli,
+	// it proves the possession of authorization codes by PKCE instead
+	PublicClient ClientType = "public"
+)
+
 type OauthApp struct {
-	ID          uint      `gorm:"primarykey"`
-	Name        string    `gorm:"column:name"`
-	ClientID    string    `gorm:"column:client_id"`
-	RedirectURL string    `gorm:"column:redirect_url"`
-	HomeURL     string    `gorm:"column:home_url"`
-	Desc        string    `gorm:"column:description"`
-	OwnerType   OwnerType `gorm:"column:owner_type"`
-	OwnerID     uint      `gorm:"column:owner_id"`
-	AppType     AppType   `gorm:"column:app_type"`
+	ID          uint       `gorm:"primarykey"`
+	Name        string     `gorm:"column:name"`
+	ClientID    string     `gorm:"column:client_id"`
+	RedirectURL string     `gorm:"column:redirect_url"`
+	HomeURL     string     `gorm:"column:home_url"`
+	Desc        string     `gorm:"column:description"`
+	OwnerType   OwnerType  `gorm:"column:owner_type"`
+	OwnerID     uint       `gorm:"column:owner_id"`
+	AppType     AppType    `gorm:"column:app_type"`
+	ClientType  ClientType `gorm:"column:client_type"`
+	// AccessTokenExpireIn and RefreshTokenExpireIn override the lifetimes of tokens issued to the app,
+	// zero means the default lifetimes
+	AccessTokenExpireIn  time.Duration `gorm:"column:access_token_expire_in"`
+	RefreshTokenExpireIn time.Duration `gorm:"column:refresh_token_expire_in"`
 
 	CreatedAt time.Time `gorm:"column:created_at"`
 	CreatedBy uint      `gorm:"column:created_by"`
@@ -60,10 +76,55 @@ func (a *OauthApp) IsGroupOwnerType() bool {
 	return a.OwnerType == GroupOwnerType
 }
 
+// IsPublicClient returns whether the app is a public client, apps are confidential clients by default
+func (a *OauthApp) IsPublicClient() bool {
+	return a.ClientType == PublicClient
+}
+
 type OauthClientSecret struct {
-	ID           uint      `gorm:"column:id" json:"id"`
-	ClientID     string    `gorm:"column:client_id" json:"clientID"`
-	ClientSecret string    `gorm:"column:client_secret" json:"clientSecret"`
-	CreatedAt    time.Time `gorm:"column:created_at" json:"createdAt"`
-	CreatedBy    uint      `gorm:"column:created_by" json:"createdBy"`
+	ID       uint   `gorm:"column:id" json:"id"`
+	ClientID string `gorm:"column:client_id" json:"clientID"`
+	// ClientSecret is hashed in db, the plaintext is only returned once at creation
+	ClientSecret string `gorm:"column:client_secret" json:"clientSecret"`
+	// ClientSecretSuffix is the last characters of the plaintext secret
+	ClientSecretSuffix string    `gorm:"column:client_secret_suffix" json:"-"`
+	CreatedAt          time.Time `gorm:"column:created_at" json:"createdAt"`
+	CreatedBy          uint      `gorm:"column:created_by" json:"createdBy"`
+}
+
+// OauthGrant remembers the scopes a user has consented to grant to a client,
+// the consent step is skipped for later authorizations within these scopes
+type OauthGrant struct {
+	ID       uint   `gorm:"primarykey"`
+	UserID   uint   `gorm:"column:user_id"`
+	ClientID string `gorm:"column:client_id"`
+	// Scope is the space separated scopes granted, sorted and deduplicated
+	Scope string `gorm:"column:scope"`
+
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+type TokenAuditAction string
+
+const (
+	TokenAuditActionIssue   TokenAuditAction = "issue"
+	TokenAuditActionRefresh TokenAuditAction = "refresh"
+	TokenAuditActionRevoke  TokenAuditAction = "revoke"
+)
+
+// OauthTokenAudit records an issuance, refresh or revocation of oauth tokens,
+// so that the usages of leaked credentials can be investigated
+type OauthTokenAudit struct {
+	ID       uint             `gorm:"primarykey" json:"id"`
+	Action   TokenAuditAction `gorm:"column:action" json:"action"`
+	ClientID string           `gorm:"column:client_id" json:"clientID"`
+	// UserID is the subject the token acts as
+	UserID uint   `gorm:"column:user_id" json:"userID"`
+	Scope  string `gorm:"column:scope" json:"scope"`
+	// TokenID is the id of the access token issued or refreshed, or of the token revoked
+	TokenID  uint   `gorm:"column:token_id" json:"tokenID"`
+	SourceIP string `gorm:"column:source_ip" json:"sourceIP"`
+
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
 }
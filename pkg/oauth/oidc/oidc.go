@@ -0,0 +1,228 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/horizoncd/horizon/pkg/config/oauth"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// scopes of openid connect, ref: OpenID Connect Core 1.0 section 5.4
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+const signingAlgorithm = "RS256"
+
+// Profile is the claims about the user released by the profile and email scopes
+type Profile struct {
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce string `json:"nonce,omitempty"`
+	Profile
+}
+
+// UserInfo is the response of the userinfo endpoint, ref: OpenID Connect Core 1.0 section 5.3
+type UserInfo struct {
+	Subject string `json:"sub"`
+	Profile
+}
+
+// Endpoints are the paths of the endpoints relative to the issuer
+type Endpoints struct {
+	Authorization string
+	Token         string
+	UserInfo      string
+	JWKS          string
+	Revocation    string
+}
+
+// Configuration is the discovery document, ref: OpenID Connect Discovery 1.0 section 3
+type Configuration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// JSONWebKey is the public key to verify id tokens, ref: rfc7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Provider signs id tokens and describes horizon as an openid connect provider
+type Provider struct {
+	issuer          string
+	idTokenExpireIn time.Duration
+	key             *rsa.PrivateKey
+	keyID           string
+}
+
+// NewProvider loads the signing key of config, nil is returned if openid connect is disabled
+func NewProvider(config oauth.OIDC) (*Provider, error) {
+	if !config.Enabled() {
+		return nil, nil
+	}
+	content, err := ioutil.ReadFile(config.SigningKeyFile)
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to read oidc signing key file %s", config.SigningKeyFile)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(content)
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to parse oidc signing key file %s", config.SigningKeyFile)
+	}
+	return newProvider(config.Issuer, config.IDTokenExpireIn, key), nil
+}
+
+func newProvider(issuer string, idTokenExpireIn time.Duration, key *rsa.PrivateKey) *Provider {
+	// the key id is derived from the public key, so that it changes along with the key
+	sum := sha256.Sum256(key.PublicKey.N.Bytes())
+	return &Provider{
+		issuer:          strings.TrimSuffix(issuer, "/"),
+		idTokenExpireIn: idTokenExpireIn,
+		key:             key,
+		keyID:           base64.RawURLEncoding.EncodeToString(sum[:16]),
+	}
+}
+
+// HasOpenIDScope tells whether the space separated scopes request openid connect
+func HasOpenIDScope(scope string) bool {
+	return hasScope(scope, ScopeOpenID)
+}
+
+func hasScope(scope, target string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
+
+func subject(user *usermodels.User) string {
+	return strconv.FormatUint(uint64(user.ID), 10)
+}
+
+func profile(user *usermodels.User, scope string) Profile {
+	var p Profile
+	if hasScope(scope, ScopeProfile) {
+		p.Name = user.FullName
+		p.PreferredUsername = user.Name
+	}
+	if hasScope(scope, ScopeEmail) {
+		p.Email = user.Email
+	}
+	return p
+}
+
+// IssueIDToken signs an id token of the user for the client, nonce is the one of the authorization request
+func (p *Provider) IssueIDToken(clientID string, user *usermodels.User, scope, nonce string) (string, error) {
+	now := time.Now()
+	claims := IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.issuer,
+			Subject:   subject(user),
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(p.idTokenExpireIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Nonce:   nonce,
+		Profile: profile(user, scope),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.keyID
+	return token.SignedString(p.key)
+}
+
+// UserInfo returns the claims about the user released by the scope
+func (p *Provider) UserInfo(user *usermodels.User, scope string) *UserInfo {
+	return &UserInfo{
+		Subject: subject(user),
+		Profile: profile(user, scope),
+	}
+}
+
+// Configuration returns the discovery document, scopes are all the scopes defined including those of openid
+func (p *Provider) Configuration(endpoints Endpoints, scopes []string) *Configuration {
+	return &Configuration{
+		Issuer:                            p.issuer,
+		AuthorizationEndpoint:             p.issuer + endpoints.Authorization,
+		TokenEndpoint:                     p.issuer + endpoints.Token,
+		UserInfoEndpoint:                  p.issuer + endpoints.UserInfo,
+		JWKSURI:                           p.issuer + endpoints.JWKS,
+		RevocationEndpoint:                p.issuer + endpoints.Revocation,
+		ScopesSupported:                   scopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{signingAlgorithm},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_post"},
+		CodeChallengeMethodsSupported:     []string{"plain", "S256"},
+		ClaimsSupported: []string{"iss", "sub", "aud", "exp", "iat", "nonce",
+			"name", "preferred_username", "email"},
+	}
+}
+
+// JWKS returns the public key to verify id tokens
+func (p *Provider) JWKS() *JSONWebKeySet {
+	return &JSONWebKeySet{
+		Keys: []JSONWebKey{{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: signingAlgorithm,
+			KeyID:     p.keyID,
+			N:         base64.RawURLEncoding.EncodeToString(p.key.PublicKey.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.PublicKey.E)).Bytes()),
+		}},
+	}
+}
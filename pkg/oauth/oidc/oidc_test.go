@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/config/oauth"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestIssueIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	provider := newProvider("https://horizon.example.com/", time.Hour, key)
+
+	user := &usermodels.User{
+		Model:    global.Model{ID: 12},
+		Name:     "tony",
+		FullName: "Tony",
+		Email:    "tony@example.com",
+	}
+	signed, err := provider.IssueIDToken("client", user, "openid email", "n-0S6_WzA2Mj")
+	assert.Nil(t, err)
+
+	claims := &IDTokenClaims{}
+	token, err := jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, provider.keyID, token.Header["kid"])
+		return &key.PublicKey, nil
+	})
+	assert.Nil(t, err)
+	assert.True(t, token.Valid)
+	assert.Equal(t, "https://horizon.example.com", claims.Issuer)
+	assert.Equal(t, "12", claims.Subject)
+	assert.True(t, claims.VerifyAudience("client", true))
+	assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+	// the profile scope is not granted
+	assert.Equal(t, "", claims.Name)
+	assert.Equal(t, "tony@example.com", claims.Email)
+
+	userInfo := provider.UserInfo(user, "openid profile")
+	assert.Equal(t, "12", userInfo.Subject)
+	assert.Equal(t, "Tony", userInfo.Name)
+	assert.Equal(t, "tony", userInfo.PreferredUsername)
+	assert.Equal(t, "", userInfo.Email)
+}
+
+func TestDisabled(t *testing.T) {
+	provider, err := NewProvider(oauth.OIDC{})
+	assert.Nil(t, err)
+	assert.Nil(t, provider)
+
+	assert.True(t, HasOpenIDScope("profile openid"))
+	assert.False(t, HasOpenIDScope("openid-like"))
+}
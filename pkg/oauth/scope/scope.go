@@ -15,7 +15,11 @@
 package scope
 
 import (
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 )
 
@@ -23,6 +27,9 @@ type Service interface {
 	GetRulesByScope([]string) []types.Role
 	GetAllScopeNames() []string
 	GetAllScopes() []types.Role
+	// ValidateScopes checks that the scopes are all defined,
+	// empty scopes are valid and the default scopes are granted for them
+	ValidateScopes(scopes []string) error
 }
 
 type fileScopeService struct {
@@ -83,3 +90,24 @@ func (f *fileScopeService) GetAllScopeNames() []string {
 func (f *fileScopeService) GetAllScopes() []types.Role {
 	return f.Roles
 }
+
+func (f *fileScopeService) ValidateScopes(scopes []string) error {
+	defined := make(map[string]struct{}, len(f.Roles))
+	for _, role := range f.Roles {
+		defined[role.Name] = struct{}{}
+	}
+	undefined := make([]string, 0)
+	for _, scope := range scopes {
+		if scope == "" {
+			continue
+		}
+		if _, ok := defined[scope]; !ok {
+			undefined = append(undefined, scope)
+		}
+	}
+	if len(undefined) > 0 {
+		return perror.Wrapf(herrors.ErrOAuthScopeNotValid,
+			"scopes are not defined: %s", strings.Join(undefined, ", "))
+	}
+	return nil
+}
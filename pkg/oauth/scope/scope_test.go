@@ -0,0 +1,44 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope
+
+import (
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/config/oauth"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateScopes(t *testing.T) {
+	svc, err := NewFileScopeService(oauth.Scopes{
+		DefaultScopes: []string{"applications:read-only"},
+		Roles: []types.Role{
+			{Name: "applications:read-only"},
+			{Name: "clusters:read-write"},
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, svc.ValidateScopes(nil))
+	assert.Nil(t, svc.ValidateScopes([]string{""}))
+	assert.Nil(t, svc.ValidateScopes([]string{"applications:read-only", "clusters:read-write"}))
+
+	err = svc.ValidateScopes([]string{"applications:read-only", "clusters:admin"})
+	assert.Equal(t, herrors.ErrOAuthScopeNotValid, perror.Cause(err))
+	assert.Contains(t, err.Error(), "clusters:admin")
+}
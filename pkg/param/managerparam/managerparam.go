@@ -22,17 +22,35 @@ import (
 	accesstokenmanager "github.com/horizoncd/horizon/pkg/accesstoken/manager"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
+	artifactmanager "github.com/horizoncd/horizon/pkg/artifact/manager"
+	asynctaskmanager "github.com/horizoncd/horizon/pkg/asynctask/manager"
+	auditlogmanager "github.com/horizoncd/horizon/pkg/auditlog/manager"
+	changerequestmanager "github.com/horizoncd/horizon/pkg/changerequest/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clusterenvmanager "github.com/horizoncd/horizon/pkg/clusterenv/manager"
+	clustersnapshotmanager "github.com/horizoncd/horizon/pkg/clustersnapshot/manager"
+	clustersummarymanager "github.com/horizoncd/horizon/pkg/clustersummary/manager"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	deployapprovalmanager "github.com/horizoncd/horizon/pkg/deployapproval/manager"
+	deploylockmanager "github.com/horizoncd/horizon/pkg/deploylock/manager"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	environmentregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventManager "github.com/horizoncd/horizon/pkg/event/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	idpmanager "github.com/horizoncd/horizon/pkg/idp/manager"
 	membermanager "github.com/horizoncd/horizon/pkg/member"
+	metadatamanager "github.com/horizoncd/horizon/pkg/metadata/manager"
+	notificationmanager "github.com/horizoncd/horizon/pkg/notification/manager"
+	pipelinestepmanager "github.com/horizoncd/horizon/pkg/pipelinestep/manager"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	recentvisitmanager "github.com/horizoncd/horizon/pkg/recentvisit/manager"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	registrymanager "github.com/horizoncd/horizon/pkg/registry/manager"
+	robotmanager "github.com/horizoncd/horizon/pkg/robot/manager"
+	scheduleddeploymanager "github.com/horizoncd/horizon/pkg/scheduleddeploy/manager"
+	searchmanager "github.com/horizoncd/horizon/pkg/search/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
@@ -52,6 +70,7 @@ type Manager struct {
 	TemplateSchemaTagMgr trtmanager.Manager
 	CollectionMgr        collectionmanager.Manager
 	ClusterMgr           clustermanager.Manager
+	ClusterSummaryMgr    clustersummarymanager.Manager
 	MemberMgr            membermanager.Manager
 	ClusterSchemaTagMgr  templateschematagmanager.Manager
 	ApplicationRegionMgr applicationregionmanager.Manager
@@ -70,6 +89,23 @@ type Manager struct {
 	WebhookMgr           webhookManager.Manager
 	EventMgr             eventManager.Manager
 	TokenMgr             tokenmanager.Manager
+	DeployLockMgr        deploylockmanager.Manager
+	ClusterSnapshotMgr   clustersnapshotmanager.Manager
+	ClusterEnvChangeMgr  clusterenvmanager.Manager
+	ChangeRequestMgr     changerequestmanager.Manager
+	DeployApprovalMgr    deployapprovalmanager.Manager
+	ScheduledDeployMgr   scheduleddeploymanager.Manager
+	ArtifactMgr          artifactmanager.Manager
+	PipelineStepMgr      pipelinestepmanager.Manager
+	AsyncTaskMgr         asynctaskmanager.Manager
+	MetadataMgr          metadatamanager.Manager
+	AuditLogMgr          auditlogmanager.Manager
+	NotificationMgr      notificationmanager.Manager
+	RobotMgr             robotmanager.Manager
+	QuotaMgr             quotamanager.Manager
+	CustomRoleMgr        customrolemanager.Manager
+	SearchMgr            searchmanager.Manager
+	RecentVisitMgr       recentvisitmanager.Manager
 }
 
 func InitManager(db *gorm.DB) *Manager {
@@ -80,6 +116,7 @@ func InitManager(db *gorm.DB) *Manager {
 		TemplateReleaseMgr:   trmanager.New(db),
 		TemplateSchemaTagMgr: trtmanager.New(db),
 		ClusterMgr:           clustermanager.New(db),
+		ClusterSummaryMgr:    clustersummarymanager.New(db),
 		CollectionMgr:        collectionmanager.New(db),
 		MemberMgr:            membermanager.New(db),
 		ClusterSchemaTagMgr:  templateschematagmanager.New(db),
@@ -99,5 +136,22 @@ func InitManager(db *gorm.DB) *Manager {
 		WebhookMgr:           webhookManager.New(db),
 		EventMgr:             eventManager.New(db),
 		TokenMgr:             tokenmanager.New(db),
+		DeployLockMgr:        deploylockmanager.New(db),
+		ClusterSnapshotMgr:   clustersnapshotmanager.New(db),
+		ClusterEnvChangeMgr:  clusterenvmanager.New(db),
+		ChangeRequestMgr:     changerequestmanager.New(db),
+		DeployApprovalMgr:    deployapprovalmanager.New(db),
+		ScheduledDeployMgr:   scheduleddeploymanager.New(db),
+		ArtifactMgr:          artifactmanager.New(db),
+		PipelineStepMgr:      pipelinestepmanager.New(db),
+		AsyncTaskMgr:         asynctaskmanager.New(db),
+		MetadataMgr:          metadatamanager.New(db),
+		AuditLogMgr:          auditlogmanager.New(db),
+		NotificationMgr:      notificationmanager.New(db),
+		RobotMgr:             robotmanager.New(db),
+		QuotaMgr:             quotamanager.New(db),
+		CustomRoleMgr:        customrolemanager.New(db),
+		SearchMgr:            searchmanager.New(db),
+		RecentVisitMgr:       recentvisitmanager.New(db),
 	}
 }
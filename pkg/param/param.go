@@ -15,29 +15,44 @@
 package param
 
 import (
+	"github.com/horizoncd/horizon/lib/s3"
+	"github.com/horizoncd/horizon/pkg/agent"
 	applicationgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	asynctaskservice "github.com/horizoncd/horizon/pkg/asynctask/service"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	clustersnapshotservice "github.com/horizoncd/horizon/pkg/clustersnapshot/service"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/gitopscache"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
 	"github.com/horizoncd/horizon/pkg/hook/hook"
+	"github.com/horizoncd/horizon/pkg/manifestpolicy"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	metadataservice "github.com/horizoncd/horizon/pkg/metadata/service"
+	"github.com/horizoncd/horizon/pkg/naming"
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	"github.com/horizoncd/horizon/pkg/oauth/oidc"
 	"github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	pipelinestepservice "github.com/horizoncd/horizon/pkg/pipelinestep/service"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 
 	"github.com/horizoncd/horizon/core/controller/build"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
+	templaterender "github.com/horizoncd/horizon/pkg/templaterelease/render"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidation "github.com/horizoncd/horizon/pkg/templaterelease/validation"
+	"github.com/horizoncd/horizon/pkg/templatesource"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 )
 
@@ -46,19 +61,41 @@ type Param struct {
 	*managerparam.Manager
 
 	OauthManager oauthmanager.Manager
+	// OIDCProvider is nil if openid connect is disabled
+	OIDCProvider *oidc.Provider
 	// service
-	AutoFreeSvc    *service.AutoFreeSVC
-	MemberService  memberservice.Service
-	ApplicationSvc applicationservice.Service
-	ClusterSvc     clusterservice.Service
-	GroupSvc       groupsvc.Service
-	EventSvc       eventservice.Service
-	UserSvc        userservice.Service
-	TokenSvc       tokenservice.Service
-	RoleService    role.Service
-	PRService      *prservice.Service
-	ScopeService   scope.Service
-	GrafanaService grafana.Service
+	AutoFreeSvc       *service.AutoFreeSVC
+	MemberService     memberservice.Service
+	ApplicationSvc    applicationservice.Service
+	ClusterSvc        clusterservice.Service
+	GroupSvc          groupsvc.Service
+	EventSvc          eventservice.Service
+	UserSvc           userservice.Service
+	TokenSvc          tokenservice.Service
+	RoleService       role.Service
+	PRService         *prservice.Service
+	ScopeService      scope.Service
+	GrafanaService    grafana.Service
+	NamingSvc         naming.Service
+	DeployWindowSvc   deploywindow.Service
+	SnapshotSvc       clustersnapshotservice.Service
+	AsyncTaskSvc      asynctaskservice.Service
+	ManifestPolicySvc manifestpolicy.Service
+	MetadataSvc       metadataservice.Service
+	AgentHub          agent.Hub
+	// TemplateValidationSvc validates the template values of applications and clusters
+	TemplateValidationSvc templatevalidation.Service
+	// TemplateRenderer renders the kubernetes manifests of applications and clusters without deploying them
+	TemplateRenderer templaterender.Renderer
+	// TemplateSources are where charts of templates are fetched from
+	TemplateSources templatesource.Sources
+	// GitopsCache caches the files of gitops repos, it's nil if the cache is disabled
+	GitopsCache gitopscache.Store
+	// ArtifactStorage keeps the artifacts and reports uploaded by pipelineruns, it's nil if not configured
+	ArtifactStorage s3.Interface
+	// PipelineStepSvc resolves the custom pipeline steps declared by templates
+	PipelineStepSvc pipelinestepservice.Service
+	QuotaSvc        quotaservice.Service
 
 	// others
 	Hook                 hook.Hook
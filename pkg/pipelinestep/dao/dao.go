@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/pipelinestep/models"
+)
+
+type DAO interface {
+	ListByApplicationID(ctx context.Context, applicationID uint) ([]*models.Setting, error)
+	Upsert(ctx context.Context, settings []*models.Setting) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) ListByApplicationID(ctx context.Context, applicationID uint) ([]*models.Setting, error) {
+	settings := make([]*models.Setting, 0)
+	result := d.db.WithContext(ctx).Where("application_id = ?", applicationID).
+		Order("id").Find(&settings)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.PipelineStepSettingInDB, result.Error.Error())
+	}
+	return settings, nil
+}
+
+func (d *dao) Upsert(ctx context.Context, settings []*models.Setting) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "application_id"}, {Name: "step_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_by", "updated_at"}),
+	}).Create(settings)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.PipelineStepSettingInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/pipelinestep/dao"
+	"github.com/horizoncd/horizon/pkg/pipelinestep/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// ListByApplicationID lists the steps enabled or disabled by the application
+	ListByApplicationID(ctx context.Context, applicationID uint) ([]*models.Setting, error)
+	// Upsert enables or disables the steps for applications, steps not in the settings are unchanged
+	Upsert(ctx context.Context, settings []*models.Setting) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) ListByApplicationID(ctx context.Context, applicationID uint) ([]*models.Setting, error) {
+	return m.dao.ListByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) Upsert(ctx context.Context, settings []*models.Setting) error {
+	return m.dao.Upsert(ctx, settings)
+}
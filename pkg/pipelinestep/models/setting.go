@@ -0,0 +1,33 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// Setting enables or disables a pipeline step declared by the template for an application
+type Setting struct {
+	ID            uint
+	ApplicationID uint   `gorm:"uniqueIndex:idx_application_step"`
+	StepName      string `gorm:"uniqueIndex:idx_application_step"`
+	Enabled       bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     uint
+	UpdatedBy     uint
+}
+
+func (Setting) TableName() string {
+	return "tb_pipeline_step_setting"
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinestep
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	sbomconfig "github.com/horizoncd/horizon/pkg/config/sbom"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+const (
+	// SBOMStepName is the name of the built-in step generating the sbom of the image
+	SBOMStepName = "horizon-sbom"
+
+	// the sbom is wrapped in the body of creating sbom, since there is no jq in the syft image
+	_sbomScript = `set -e
+syft "$IMAGE_URL" -o syft-json > /tmp/sbom.json
+{ printf '{"format":"syft-json","content":'; cat /tmp/sbom.json; printf '}'; } > /tmp/sbom-request.json
+wget -q -O /dev/null --header "Content-Type: application/json" \
+  --header "X-Horizon-JWT-Token: $HORIZON_JWT_TOKEN" \
+  --post-file /tmp/sbom-request.json "$HORIZON_SBOM_URL"
+`
+)
+
+// WithSBOMStep adds the built-in step generating the sbom of the image by syft to the pipelinerun of builddeploy,
+// the step uploads the sbom to horizon with the jwt token of the pipelinerun
+func WithSBOMStep(config sbomconfig.Config, pr *tekton.PipelineRun) *tekton.PipelineRun {
+	if !config.Enabled || pr.Action != prmodels.ActionBuildDeploy || pr.ImageURL == "" {
+		return pr
+	}
+	timeout := ""
+	if config.Timeout > 0 {
+		timeout = config.Timeout.String()
+	}
+	pr.CustomSteps = append(pr.CustomSteps, &tekton.PipelineRunStep{
+		Name:   SBOMStepName,
+		Stage:  StageAfterBuild,
+		Image:  config.Image,
+		Script: _sbomScript,
+		Env: []tekton.PipelineRunEnv{
+			{
+				Name:  "HORIZON_JWT_TOKEN",
+				Value: pr.Token,
+			},
+			{
+				Name: "HORIZON_SBOM_URL",
+				Value: fmt.Sprintf("%s/apis/internal/v2/pipelineruns/%d/sbom",
+					strings.TrimSuffix(config.ServerURL, "/"), pr.PipelinerunID),
+			},
+			{
+				Name:  "IMAGE_URL",
+				Value: pr.ImageURL,
+			},
+		},
+		Timeout: timeout,
+	})
+	return pr
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinestep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	sbomconfig "github.com/horizoncd/horizon/pkg/config/sbom"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestWithSBOMStep(t *testing.T) {
+	config := sbomconfig.Config{
+		Enabled:   true,
+		Image:     "anchore/syft:v0.98.0-debug",
+		ServerURL: "https://horizon.example.com/",
+		Timeout:   10 * time.Minute,
+	}
+	pr := WithSBOMStep(config, &tekton.PipelineRun{
+		Action:        prmodels.ActionBuildDeploy,
+		ImageURL:      "harbor.example.com/app/cluster:v1",
+		PipelinerunID: 1,
+		Token:         "token",
+		CustomSteps:   []*tekton.PipelineRunStep{{Name: "security-scan", Stage: StageAfterBuild}},
+	})
+	assert.Equal(t, 2, len(pr.CustomSteps))
+	step := pr.CustomSteps[1]
+	assert.Equal(t, SBOMStepName, step.Name)
+	assert.Equal(t, StageAfterBuild, step.Stage)
+	assert.Equal(t, config.Image, step.Image)
+	assert.Equal(t, "10m0s", step.Timeout)
+	assert.Equal(t, []tekton.PipelineRunEnv{
+		{Name: "HORIZON_JWT_TOKEN", Value: "token"},
+		{Name: "HORIZON_SBOM_URL", Value: "https://horizon.example.com/apis/internal/v2/pipelineruns/1/sbom"},
+		{Name: "IMAGE_URL", Value: "harbor.example.com/app/cluster:v1"},
+	}, step.Env)
+
+	// no sbom for deploy, which builds no image
+	pr = WithSBOMStep(config, &tekton.PipelineRun{Action: prmodels.ActionDeploy, ImageURL: "image"})
+	assert.Empty(t, pr.CustomSteps)
+
+	config.Enabled = false
+	pr = WithSBOMStep(config, &tekton.PipelineRun{Action: prmodels.ActionBuildDeploy, ImageURL: "image"})
+	assert.Empty(t, pr.CustomSteps)
+}
@@ -0,0 +1,160 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/pipelinestep"
+	"github.com/horizoncd/horizon/pkg/pipelinestep/manager"
+	"github.com/horizoncd/horizon/pkg/pipelinestep/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+// ApplicationStep is a step declared by the template, Enabled tells whether it's enabled for the application
+type ApplicationStep struct {
+	pipelinestep.Step
+	// EnabledByDefault tells whether the step is enabled for the applications which haven't enabled or disabled it
+	EnabledByDefault bool `json:"enabledByDefault"`
+}
+
+type Service interface {
+	// ListSteps lists the steps declared by the template release and whether they are enabled for the application
+	ListSteps(ctx context.Context, applicationID uint, templateName, releaseName string) ([]*ApplicationStep, error)
+	// UpdateSettings enables or disables the steps declared by the template release for the application
+	UpdateSettings(ctx context.Context, applicationID uint, templateName, releaseName string,
+		settings map[string]bool) error
+	// ListPipelineRunSteps lists the steps enabled for the application which run in the pipeline of the action
+	ListPipelineRunSteps(ctx context.Context, applicationID uint, templateName, releaseName,
+		action string) ([]*tekton.PipelineRunStep, error)
+}
+
+type service struct {
+	getter  pipelinestep.Getter
+	manager manager.Manager
+}
+
+func NewService(getter pipelinestep.Getter, m *managerparam.Manager) Service {
+	return &service{
+		getter:  getter,
+		manager: m.PipelineStepMgr,
+	}
+}
+
+func (s *service) ListSteps(ctx context.Context, applicationID uint,
+	templateName, releaseName string) ([]*ApplicationStep, error) {
+	steps, err := s.getter.GetSteps(ctx, templateName, releaseName)
+	if err != nil {
+		return nil, err
+	}
+	// most templates don't declare any step
+	if len(steps) == 0 {
+		return []*ApplicationStep{}, nil
+	}
+	settings, err := s.manager.ListByApplicationID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[string]bool, len(settings))
+	for _, setting := range settings {
+		enabled[setting.StepName] = setting.Enabled
+	}
+	applicationSteps := make([]*ApplicationStep, 0, len(steps))
+	for _, step := range steps {
+		applicationStep := &ApplicationStep{
+			Step:             *step,
+			EnabledByDefault: step.Enabled,
+		}
+		if e, ok := enabled[step.Name]; ok {
+			applicationStep.Enabled = e
+		}
+		applicationSteps = append(applicationSteps, applicationStep)
+	}
+	return applicationSteps, nil
+}
+
+func (s *service) UpdateSettings(ctx context.Context, applicationID uint, templateName, releaseName string,
+	settings map[string]bool) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	steps, err := s.getter.GetSteps(ctx, templateName, releaseName)
+	if err != nil {
+		return err
+	}
+	declared := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		declared[step.Name] = true
+	}
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		if !declared[name] {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"step %s is not declared by template %s release %s", name, templateName, releaseName)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stepSettings := make([]*models.Setting, 0, len(names))
+	for _, name := range names {
+		stepSettings = append(stepSettings, &models.Setting{
+			ApplicationID: applicationID,
+			StepName:      name,
+			Enabled:       settings[name],
+			CreatedBy:     currentUser.GetID(),
+			UpdatedBy:     currentUser.GetID(),
+		})
+	}
+	return s.manager.Upsert(ctx, stepSettings)
+}
+
+func (s *service) ListPipelineRunSteps(ctx context.Context, applicationID uint, templateName, releaseName,
+	action string) ([]*tekton.PipelineRunStep, error) {
+	steps, err := s.ListSteps(ctx, applicationID, templateName, releaseName)
+	if err != nil {
+		return nil, err
+	}
+	pipelineRunSteps := make([]*tekton.PipelineRunStep, 0, len(steps))
+	for _, step := range steps {
+		if !step.Enabled {
+			continue
+		}
+		// the build stages only run in the pipeline of builddeploy
+		if step.Stage != pipelinestep.StageAfterDeploy && action != prmodels.ActionBuildDeploy {
+			continue
+		}
+		env := make([]tekton.PipelineRunEnv, 0, len(step.Env))
+		for name, value := range step.Env {
+			env = append(env, tekton.PipelineRunEnv{Name: name, Value: value})
+		}
+		sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+		pipelineRunSteps = append(pipelineRunSteps, &tekton.PipelineRunStep{
+			Name:    step.Name,
+			Stage:   step.Stage,
+			Image:   step.Image,
+			Script:  step.Script,
+			Env:     env,
+			Timeout: step.Timeout,
+		})
+	}
+	return pipelineRunSteps, nil
+}
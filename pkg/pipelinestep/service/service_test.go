@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	pipelinestepmock "github.com/horizoncd/horizon/mock/pkg/pipelinestep"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/pipelinestep"
+	"github.com/horizoncd/horizon/pkg/pipelinestep/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Setting{}))
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	manager := managerparam.InitManager(db)
+
+	mockCtl := gomock.NewController(t)
+	getter := pipelinestepmock.NewMockGetter(mockCtl)
+	getter.EXPECT().GetSteps(gomock.Any(), "javaapp", "v1").Return([]*pipelinestep.Step{
+		{
+			Name:    "scan",
+			Stage:   pipelinestep.StageAfterBuild,
+			Image:   "scanner",
+			Script:  "scan",
+			Env:     map[string]string{"SEVERITY": "high", "FORMAT": "json"},
+			Timeout: "10m",
+			Enabled: true,
+		},
+		{Name: "e2e", Stage: pipelinestep.StageAfterDeploy, Image: "tester", Script: "test"},
+	}, nil).AnyTimes()
+	getter.EXPECT().GetSteps(gomock.Any(), "javaapp", "v0").Return(nil, nil).AnyTimes()
+	svc := NewService(getter, manager)
+
+	// templates without steps
+	steps, err := svc.ListSteps(ctx, 1, "javaapp", "v0")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(steps))
+
+	// steps are enabled by default as the template declares
+	steps, err = svc.ListSteps(ctx, 1, "javaapp", "v1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(steps))
+	assert.True(t, steps[0].Enabled)
+	assert.False(t, steps[1].Enabled)
+
+	prSteps, err := svc.ListPipelineRunSteps(ctx, 1, "javaapp", "v1", prmodels.ActionBuildDeploy)
+	assert.Nil(t, err)
+	assert.Equal(t, []*tekton.PipelineRunStep{{
+		Name:    "scan",
+		Stage:   pipelinestep.StageAfterBuild,
+		Image:   "scanner",
+		Script:  "scan",
+		Env:     []tekton.PipelineRunEnv{{Name: "FORMAT", Value: "json"}, {Name: "SEVERITY", Value: "high"}},
+		Timeout: "10m",
+	}}, prSteps)
+
+	// only steps declared by the template can be set
+	err = svc.UpdateSettings(ctx, 1, "javaapp", "v1", map[string]bool{"lint": true})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	assert.Nil(t, svc.UpdateSettings(ctx, 1, "javaapp", "v1", map[string]bool{"scan": false, "e2e": true}))
+	steps, err = svc.ListSteps(ctx, 1, "javaapp", "v1")
+	assert.Nil(t, err)
+	assert.False(t, steps[0].Enabled)
+	assert.True(t, steps[0].EnabledByDefault)
+	assert.True(t, steps[1].Enabled)
+
+	// other applications keep the defaults
+	steps, err = svc.ListSteps(ctx, 2, "javaapp", "v1")
+	assert.Nil(t, err)
+	assert.True(t, steps[0].Enabled)
+
+	// the build stages don't run in the pipeline of deploy
+	prSteps, err = svc.ListPipelineRunSteps(ctx, 1, "javaapp", "v1", prmodels.ActionBuildDeploy)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(prSteps))
+	assert.Equal(t, "e2e", prSteps[0].Name)
+	assert.Nil(t, svc.UpdateSettings(ctx, 1, "javaapp", "v1", map[string]bool{"scan": true}))
+	prSteps, err = svc.ListPipelineRunSteps(ctx, 1, "javaapp", "v1", prmodels.ActionDeploy)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(prSteps))
+	assert.Equal(t, "e2e", prSteps[0].Name)
+
+	settings, err := manager.PipelineStepMgr.ListByApplicationID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(settings))
+}
@@ -0,0 +1,152 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinestep
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	// StageBeforeBuild steps run after the code is checked out and before it's built
+	StageBeforeBuild = "beforeBuild"
+	// StageAfterBuild steps run after the image is built and pushed, such as security scans
+	StageAfterBuild = "afterBuild"
+	// StageAfterDeploy steps run after the cluster is deployed, such as integration tests
+	StageAfterDeploy = "afterDeploy"
+
+	// steps file path in the template
+	_stepsPath = "pipeline/steps.yaml"
+)
+
+// names of steps are used as names of tekton steps
+var _stepNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Step is a pipeline step declared by a template in pipeline/steps.yaml, for example:
+//
+//	steps:
+//	  - name: security-scan
+//	    description: scan the image for vulnerabilities
+//	    stage: afterBuild
+//	    image: aquasec/trivy:0.45.0
+//	    script: trivy image --exit-code 1 "$IMAGE_URL"
+//	    env:
+//	      TRIVY_SEVERITY: HIGH,CRITICAL
+//	    timeout: 10m
+//	    enabled: true
+type Step struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Stage       string            `json:"stage"`
+	Image       string            `json:"image"`
+	Script      string            `json:"script"`
+	Env         map[string]string `json:"env"`
+	// Timeout is a duration like 10m, the step is not limited if empty
+	Timeout string `json:"timeout"`
+	// Enabled tells whether the step runs for the applications which haven't enabled or disabled it
+	Enabled bool `json:"enabled"`
+}
+
+// Parse parses and validates the steps file of a template
+func Parse(content []byte) ([]*Step, error) {
+	var file struct {
+		Steps []*Step `json:"steps"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to parse %s: %v", _stepsPath, err)
+	}
+	names := make(map[string]bool, len(file.Steps))
+	for _, step := range file.Steps {
+		if !_stepNamePattern.MatchString(step.Name) || len(step.Name) > 63 {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"invalid step name %q in %s, it should be a DNS-1123 label", step.Name, _stepsPath)
+		}
+		if names[step.Name] {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "duplicate step %s in %s", step.Name, _stepsPath)
+		}
+		names[step.Name] = true
+		switch step.Stage {
+		case StageBeforeBuild, StageAfterBuild, StageAfterDeploy:
+		default:
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"invalid stage %q of step %s, only %s, %s and %s are supported",
+				step.Stage, step.Name, StageBeforeBuild, StageAfterBuild, StageAfterDeploy)
+		}
+		if step.Image == "" || step.Script == "" {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "image and script of step %s are required", step.Name)
+		}
+		if step.Timeout != "" {
+			if _, err := time.ParseDuration(step.Timeout); err != nil {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"invalid timeout %q of step %s: %v", step.Timeout, step.Name, err)
+			}
+		}
+	}
+	return file.Steps, nil
+}
+
+// Getter gets the pipeline steps declared by templates
+//
+//go:generate mockgen -source=$GOFILE -destination=../../mock/pkg/pipelinestep/mock_step.go -package=mock_pipelinestep
+type Getter interface {
+	// GetSteps gets steps of the template release, it returns empty if the template declares no steps
+	GetSteps(ctx context.Context, templateName, releaseName string) ([]*Step, error)
+}
+
+type getter struct {
+	templateRepo       templaterepo.TemplateRepo
+	templateReleaseMgr trmanager.Manager
+}
+
+func NewGetter(repo templaterepo.TemplateRepo, manager *managerparam.Manager) Getter {
+	return &getter{
+		templateRepo:       repo,
+		templateReleaseMgr: manager.TemplateReleaseMgr,
+	}
+}
+
+func (g *getter) GetSteps(ctx context.Context, templateName, releaseName string) ([]*Step, error) {
+	const op = "pipeline step getter: get steps"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tr, err := g.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateName, releaseName)
+	if err != nil {
+		return nil, err
+	}
+	chart, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range chart.Files {
+		if file.Name == _stepsPath {
+			steps, err := Parse(file.Data)
+			if err != nil {
+				return nil, perror.WithMessagef(err, "template %s release %s", templateName, releaseName)
+			}
+			return steps, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinestep
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestParse(t *testing.T) {
+	steps, err := Parse([]byte(`
+steps:
+  - name: security-scan
+    description: scan the image for vulnerabilities
+    stage: afterBuild
+    image: aquasec/trivy:0.45.0
+    script: trivy image --exit-code 1 "$IMAGE_URL"
+    env:
+      TRIVY_SEVERITY: HIGH,CRITICAL
+    timeout: 10m
+    enabled: true
+  - name: integration-test
+    stage: afterDeploy
+    image: golang:1.19
+    script: |
+      go test ./e2e/...
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []*Step{
+		{
+			Name:        "security-scan",
+			Description: "scan the image for vulnerabilities",
+			Stage:       StageAfterBuild,
+			Image:       "aquasec/trivy:0.45.0",
+			Script:      `trivy image --exit-code 1 "$IMAGE_URL"`,
+			Env:         map[string]string{"TRIVY_SEVERITY": "HIGH,CRITICAL"},
+			Timeout:     "10m",
+			Enabled:     true,
+		},
+		{
+			Name:   "integration-test",
+			Stage:  StageAfterDeploy,
+			Image:  "golang:1.19",
+			Script: "go test ./e2e/...\n",
+		},
+	}, steps)
+
+	steps, err = Parse([]byte(""))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(steps))
+
+	for _, content := range []string{
+		"steps: [",
+		"steps: [{name: Scan, stage: afterBuild, image: trivy, script: scan}]",
+		"steps: [{name: scan, stage: afterBuild, image: trivy, script: scan}," +
+			" {name: scan, stage: afterDeploy, image: trivy, script: scan}]",
+		"steps: [{name: scan, stage: build, image: trivy, script: scan}]",
+		"steps: [{name: scan, stage: afterBuild, script: scan}]",
+		"steps: [{name: scan, stage: afterBuild, image: trivy, script: scan, timeout: 10}]",
+	} {
+		_, err := Parse([]byte(content))
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), content)
+	}
+}
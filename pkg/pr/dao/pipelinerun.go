@@ -16,6 +16,7 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -46,6 +47,9 @@ type PipelineRunDAO interface {
 	GetLatestSuccessByClusterID(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
 	GetFirstCanRollbackPipelinerun(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
 	UpdateColumns(ctx context.Context, id uint, columns map[string]interface{}) error
+	ListByClusterIDsAndStatuses(ctx context.Context, clusterIDs []uint,
+		statuses []string) ([]*models.Pipelinerun, error)
+	ListByTimeRange(ctx context.Context, start, end time.Time, actions []string) ([]*models.Pipelinerun, error)
 }
 
 type pipelinerunDAO struct{ db *gorm.DB }
@@ -236,3 +240,31 @@ func (d *pipelinerunDAO) UpdateColumns(ctx context.Context, id uint, columns map
 	}
 	return res.Error
 }
+
+func (d *pipelinerunDAO) ListByClusterIDsAndStatuses(ctx context.Context, clusterIDs []uint,
+	statuses []string) ([]*models.Pipelinerun, error) {
+	var pipelineruns []*models.Pipelinerun
+	if len(clusterIDs) == 0 {
+		return pipelineruns, nil
+	}
+	result := d.db.WithContext(ctx).Where("cluster_id in ?", clusterIDs).
+		Where("status in ?", statuses).Order("created_at asc").Find(&pipelineruns)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return pipelineruns, nil
+}
+
+func (d *pipelinerunDAO) ListByTimeRange(ctx context.Context, start, end time.Time,
+	actions []string) ([]*models.Pipelinerun, error) {
+	var pipelineruns []*models.Pipelinerun
+	statement := d.db.WithContext(ctx).Where("created_at >= ? and created_at < ?", start, end)
+	if len(actions) > 0 {
+		statement = statement.Where("action in ?", actions)
+	}
+	result := statement.Order("created_at asc").Find(&pipelineruns)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return pipelineruns, nil
+}
@@ -0,0 +1,79 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+// _componentBatchSize is the size of batch to insert sbom components
+const _componentBatchSize = 500
+
+type SBOMDAO interface {
+	// Create creates a sbom with its components
+	Create(ctx context.Context, sbom *models.SBOM, components []*models.SBOMComponent) (*models.SBOM, error)
+	// GetLatestByPipelineRunID gets the latest sbom uploaded for the pipelinerun
+	GetLatestByPipelineRunID(ctx context.Context, pipelineRunID uint) (*models.SBOM, error)
+	// ListClustersByComponent lists clusters which are running images containing the component,
+	// version is optional
+	ListClustersByComponent(ctx context.Context, name, version string) ([]*models.ClusterComponent, error)
+}
+
+type sbomDAO struct{ db *gorm.DB }
+
+func NewSBOMDAO(db *gorm.DB) SBOMDAO {
+	return &sbomDAO{db: db}
+}
+
+func (d *sbomDAO) Create(ctx context.Context, sbom *models.SBOM,
+	components []*models.SBOMComponent) (*models.SBOM, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(sbom).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.PRSBOMInDB, err.Error())
+		}
+		if len(components) == 0 {
+			return nil
+		}
+		for _, component := range components {
+			component.SBOMID = sbom.ID
+			component.PipelineRunID = sbom.PipelineRunID
+			component.ImageURL = sbom.ImageURL
+		}
+		if err := tx.CreateInBatches(components, _componentBatchSize).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.PRSBOMInDB, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sbom, nil
+}
+
+func (d *sbomDAO) GetLatestByPipelineRunID(ctx context.Context, pipelineRunID uint) (*models.SBOM, error) {
+	var sbom models.SBOM
+	result := d.db.WithContext(ctx).Where("pipeline_run_id = ?", pipelineRunID).
+		Order("id desc").First(&sbom)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.PRSBOMInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PRSBOMInDB, result.Error.Error())
+	}
+	return &sbom, nil
+}
+
+func (d *sbomDAO) ListClustersByComponent(ctx context.Context,
+	name, version string) ([]*models.ClusterComponent, error) {
+	var components []*models.ClusterComponent
+	result := d.db.WithContext(ctx).Raw(common.SBOMComponentListClustersByName,
+		name, version, version).Scan(&components)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PRSBOMInDB, result.Error.Error())
+	}
+	return components, nil
+}
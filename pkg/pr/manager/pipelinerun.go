@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -48,6 +49,11 @@ type PipelineRunManager interface {
 	// UpdateResultByID  update the pipelinerun restore result
 	UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error
 	UpdateColumns(ctx context.Context, pipelinerunID uint, columns map[string]interface{}) error
+	// ListByClusterIDsAndStatuses lists pipelineruns of the clusters which are in the statuses
+	ListByClusterIDsAndStatuses(ctx context.Context, clusterIDs []uint,
+		statuses ...models.PipelineStatus) ([]*models.Pipelinerun, error)
+	// ListByTimeRange lists pipelineruns created in [start, end) of the actions, all actions if none is specified
+	ListByTimeRange(ctx context.Context, start, end time.Time, actions ...string) ([]*models.Pipelinerun, error)
 }
 
 type pipelinerunManager struct {
@@ -131,3 +137,17 @@ func (m *pipelinerunManager) UpdateColumns(ctx context.Context,
 	pipelinerunID uint, columns map[string]interface{}) error {
 	return m.dao.UpdateColumns(ctx, pipelinerunID, columns)
 }
+
+func (m *pipelinerunManager) ListByClusterIDsAndStatuses(ctx context.Context, clusterIDs []uint,
+	statuses ...models.PipelineStatus) ([]*models.Pipelinerun, error) {
+	statusStrings := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		statusStrings = append(statusStrings, string(status))
+	}
+	return m.dao.ListByClusterIDsAndStatuses(ctx, clusterIDs, statusStrings)
+}
+
+func (m *pipelinerunManager) ListByTimeRange(ctx context.Context, start, end time.Time,
+	actions ...string) ([]*models.Pipelinerun, error) {
+	return m.dao.ListByTimeRange(ctx, start, end, actions)
+}
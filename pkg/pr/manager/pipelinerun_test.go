@@ -26,6 +26,7 @@ import (
 	"github.com/horizoncd/horizon/lib/q"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/pr/models"
 
 	"github.com/stretchr/testify/assert"
@@ -159,6 +160,31 @@ func TestGetByClusterID(t *testing.T) {
 	assert.Equal(t, 0, len(pipelineruns))
 }
 
+func TestListByTimeRange(t *testing.T) {
+	for _, action := range []string{models.ActionRestart, models.ActionRollback} {
+		_, err := mgr.Create(ctx, &models.Pipelinerun{
+			ClusterID: 100,
+			Action:    action,
+			Status:    string(models.StatusOK),
+		})
+		assert.Nil(t, err)
+	}
+
+	now := time.Now()
+	pipelineruns, err := mgr.ListByTimeRange(ctx, now.Add(-time.Hour), now.Add(time.Hour), models.ActionRestart)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pipelineruns))
+	assert.Equal(t, models.ActionRestart, pipelineruns[0].Action)
+
+	pipelineruns, err = mgr.ListByTimeRange(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.True(t, len(pipelineruns) >= 2)
+
+	pipelineruns, err = mgr.ListByTimeRange(ctx, now.Add(time.Hour), now.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pipelineruns))
+}
+
 // nolint
 func TestGetLatestSuccessByClusterID(t *testing.T) {
 	var clusterID uint = 1
@@ -243,7 +269,8 @@ func TestGetFirstCanRollbackPipelinerun(t *testing.T) {
 
 func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&models.Pipelinerun{}, &models.Check{},
-		&models.CheckRun{}, &models.PRMessage{}); err != nil {
+		&models.CheckRun{}, &models.PRMessage{}, &models.SBOM{}, &models.SBOMComponent{},
+		&clustermodels.Cluster{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
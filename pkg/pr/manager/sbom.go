@@ -0,0 +1,44 @@
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/pr/dao"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+type SBOMManager interface {
+	// Create creates a sbom with its components
+	Create(ctx context.Context, sbom *models.SBOM, components []*models.SBOMComponent) (*models.SBOM, error)
+	// GetLatestByPipelineRunID gets the latest sbom uploaded for the pipelinerun
+	GetLatestByPipelineRunID(ctx context.Context, pipelineRunID uint) (*models.SBOM, error)
+	// ListClustersByComponent lists clusters which are running images containing the component,
+	// version is optional
+	ListClustersByComponent(ctx context.Context, name, version string) ([]*models.ClusterComponent, error)
+}
+
+type sbomManager struct {
+	dao dao.SBOMDAO
+}
+
+func NewSBOMManager(db *gorm.DB) SBOMManager {
+	return &sbomManager{
+		dao: dao.NewSBOMDAO(db),
+	}
+}
+
+func (m *sbomManager) Create(ctx context.Context, sbom *models.SBOM,
+	components []*models.SBOMComponent) (*models.SBOM, error) {
+	return m.dao.Create(ctx, sbom, components)
+}
+
+func (m *sbomManager) GetLatestByPipelineRunID(ctx context.Context, pipelineRunID uint) (*models.SBOM, error) {
+	return m.dao.GetLatestByPipelineRunID(ctx, pipelineRunID)
+}
+
+func (m *sbomManager) ListClustersByComponent(ctx context.Context,
+	name, version string) ([]*models.ClusterComponent, error) {
+	return m.dao.ListClustersByComponent(ctx, name, version)
+}
@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestSBOM(t *testing.T) {
+	sbomManager := NewSBOMManager(db)
+	prManager := NewPipelineRunManager(db)
+	ctx := context.Background()
+
+	cluster := &clustermodels.Cluster{Name: "sbom-cluster"}
+	assert.Nil(t, db.Create(cluster).Error)
+
+	image := "harbor.com/app/sbom-cluster:v1"
+	build, err := prManager.Create(ctx, &models.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    models.ActionBuildDeploy,
+		Status:    string(models.StatusOK),
+		ImageURL:  image,
+	})
+	assert.Nil(t, err)
+
+	_, err = sbomManager.GetLatestByPipelineRunID(ctx, build.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	sbom, err := sbomManager.Create(ctx, &models.SBOM{
+		PipelineRunID: build.ID,
+		ImageURL:      image,
+		Format:        "syft-json",
+		Content:       "{}",
+	}, []*models.SBOMComponent{
+		{Name: "log4j-core", Version: "2.14.1", Type: "java-archive"},
+		{Name: "spring-core", Version: "5.3.9", Type: "java-archive"},
+	})
+	assert.Nil(t, err)
+	sbomInDB, err := sbomManager.GetLatestByPipelineRunID(ctx, build.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, sbom.ID, sbomInDB.ID)
+
+	components, err := sbomManager.ListClustersByComponent(ctx, "log4j-core", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(components))
+	assert.Equal(t, cluster.ID, components[0].ClusterID)
+	assert.Equal(t, "sbom-cluster", components[0].ClusterName)
+	assert.Equal(t, build.ID, components[0].PipelineRunID)
+
+	components, err = sbomManager.ListClustersByComponent(ctx, "log4j-core", "2.17.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(components))
+
+	// cluster is deployed with another image which has no sbom
+	_, err = prManager.Create(ctx, &models.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    models.ActionDeploy,
+		Status:    string(models.StatusOK),
+		ImageURL:  "harbor.com/app/sbom-cluster:v2",
+	})
+	assert.Nil(t, err)
+	components, err = sbomManager.ListClustersByComponent(ctx, "log4j-core", "2.14.1")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(components))
+}
@@ -82,6 +82,12 @@ type Pipelinerun struct {
 	FinishedAt *time.Time
 	// RollbackFrom which pipelinerun this pipelinerun rollback from
 	RollbackFrom *uint
+	// RetryOf which pipelinerun this pipelinerun retries or reruns from one of its steps
+	RetryOf *uint
+	// RerunFromTask and RerunFromStep the step which this pipelinerun reruns the pipelinerun RetryOf from,
+	// empty if all the steps are rerun
+	RerunFromTask string
+	RerunFromStep string
 	// CIEventID event id returned from tekton-trigger EventListener
 	CIEventID string
 	CreatedAt time.Time
@@ -127,6 +133,11 @@ type PipelineBasic struct {
 	FinishedAt *time.Time `json:"finishedAt"`
 	// CanRollback can this pipelinerun be rollback, default is false
 	CanRollback bool `json:"canRollback"`
+	// RetryOf which pipelinerun this pipelinerun retries or reruns from one of its steps
+	RetryOf *uint `json:"retryOf,omitempty"`
+	// RerunFromTask and RerunFromStep the step which this pipelinerun reruns from
+	RerunFromTask string `json:"rerunFromTask,omitempty"`
+	RerunFromStep string `json:"rerunFromStep,omitempty"`
 	// createInfo
 	CreatedBy UserInfo `json:"createdBy"`
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// SBOM is the software bill of materials of the image built by a pipelinerun
+type SBOM struct {
+	global.Model  `json:",inline"`
+	PipelineRunID uint   `gorm:"column:pipeline_run_id" json:"pipelineRunId"`
+	ImageURL      string `json:"imageURL"`
+	Format        string `json:"format"`
+	Content       string `json:"-"`
+	CreatedBy     uint   `json:"-"`
+	UpdatedBy     uint   `json:"-"`
+}
+
+func (SBOM) TableName() string {
+	return "tb_pr_sbom"
+}
+
+type SBOMComponent struct {
+	ID            uint   `gorm:"primarykey" json:"id"`
+	SBOMID        uint   `gorm:"column:sbom_id" json:"sbomId"`
+	PipelineRunID uint   `gorm:"column:pipeline_run_id" json:"pipelineRunId"`
+	ImageURL      string `json:"imageURL"`
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	Type          string `json:"type"`
+	PURL          string `gorm:"column:purl" json:"purl"`
+}
+
+func (SBOMComponent) TableName() string {
+	return "tb_pr_sbom_component"
+}
+
+// ClusterComponent is a component found in the image which a cluster is running
+type ClusterComponent struct {
+	ClusterID     uint   `json:"clusterId"`
+	ClusterName   string `json:"clusterName"`
+	PipelineRunID uint   `gorm:"column:pipeline_run_id" json:"pipelineRunId"`
+	ImageURL      string `json:"imageURL"`
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	PURL          string `gorm:"column:purl" json:"purl"`
+}
@@ -37,6 +37,8 @@ type DAO interface {
 	Create(ctx context.Context, results *tekton.PipelineResults, data *global.HorizonMetaData) error
 	// ListPipelineStats list pipeline stats by query struct
 	ListPipelineStats(ctx context.Context, query *q.Query) ([]*models.PipelineStats, int64, error)
+	// ListSteps lists the steps of a pipelinerun in the order they ran
+	ListSteps(ctx context.Context, pipelinerunID uint) ([]*models.Step, error)
 }
 
 type dao struct{ db *gorm.DB }
@@ -150,6 +152,16 @@ func (d *dao) ListPipelineStats(ctx context.Context, query *q.Query) ([]*models.
 	return formatPipelineStats(pipelines, tasks, steps), count, nil
 }
 
+func (d *dao) ListSteps(ctx context.Context, pipelinerunID uint) ([]*models.Step, error) {
+	var steps []*models.Step
+	result := d.db.WithContext(ctx).Where("pipelinerun_id = ?", pipelinerunID).
+		Order("started_at").Order("id").Find(&steps)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.StepInDB, result.Error.Error())
+	}
+	return steps, nil
+}
+
 func formatPipelineStats(pipelines []*models.Pipeline, tasks []*models.Task,
 	steps []*models.Step) []*models.PipelineStats {
 	stepMap := make(map[uint]map[string][]*models.StepStats)
@@ -36,6 +36,8 @@ type Manager interface {
 	Create(ctx context.Context, results *tekton.PipelineResults, data *global.HorizonMetaData) error
 	ListPipelineStats(ctx context.Context, application, cluster string, pageNumber, pageSize int) (
 		[]*models.PipelineStats, int64, error)
+	// ListSteps lists the steps of a pipelinerun in the order they ran
+	ListSteps(ctx context.Context, pipelinerunID uint) ([]*models.Step, error)
 }
 
 type manager struct {
@@ -63,6 +65,10 @@ func (m manager) Create(ctx context.Context, results *tekton.PipelineResults, da
 	return m.dao.Create(ctx, results, data)
 }
 
+func (m manager) ListSteps(ctx context.Context, pipelinerunID uint) ([]*models.Step, error) {
+	return m.dao.ListSteps(ctx, pipelinerunID)
+}
+
 func New(db *gorm.DB) Manager {
 	return &manager{
 		dao: dao.NewDAO(db),
@@ -190,3 +190,17 @@ func Test_manager_ListPipelineStats(t *testing.T) {
 		})
 	}
 }
+
+func Test_manager_ListSteps(t *testing.T) {
+	m := New(db)
+	steps, err := m.ListSteps(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(steps))
+	assert.Equal(t, "build", steps[0].Task)
+	assert.Equal(t, "git", steps[0].Step)
+	assert.Equal(t, "failed", steps[0].Result)
+
+	steps, err = m.ListSteps(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(steps))
+}
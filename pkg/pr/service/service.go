@@ -53,6 +53,9 @@ func (s *Service) OfPipelineBasic(ctx context.Context,
 		StartedAt:        pr.StartedAt,
 		FinishedAt:       pr.FinishedAt,
 		CanRollback:      canRollback,
+		RetryOf:          pr.RetryOf,
+		RerunFromTask:    pr.RerunFromTask,
+		RerunFromStep:    pr.RerunFromStep,
 		CreatedBy: models.UserInfo{
 			UserID:   pr.CreatedBy,
 			UserName: user.Name,
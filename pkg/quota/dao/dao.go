@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	Upsert(ctx context.Context, quota *models.GroupQuota) error
+	Delete(ctx context.Context, groupID uint, resource string) error
+	ListByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.GroupQuota, error)
+	UpdateWarnedThreshold(ctx context.Context, id uint, threshold int) error
+	CountUsage(ctx context.Context, resource string, groupIDs []uint) (int64, error)
+	CountChanges(ctx context.Context, resource string, groupIDs []uint,
+		since time.Time) (created int64, deleted int64, err error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Upsert(ctx context.Context, quota *models.GroupQuota) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "group_id"}, {Name: "resource"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hard_limit", "warned_threshold",
+			"updated_at", "updated_by"}),
+	}).Create(quota)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.GroupQuotaInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) Delete(ctx context.Context, groupID uint, resource string) error {
+	result := d.db.WithContext(ctx).Exec(common.GroupQuotaDelete, groupID, resource)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.GroupQuotaInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.GroupQuotaInDB,
+			fmt.Sprintf("no quota of %s found for group %d", resource, groupID))
+	}
+	return nil
+}
+
+func (d *dao) ListByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.GroupQuota, error) {
+	var quotas []*models.GroupQuota
+	if len(groupIDs) == 0 {
+		return quotas, nil
+	}
+	result := d.db.WithContext(ctx).Raw(common.GroupQuotaListByGroupIDs, groupIDs).Scan(&quotas)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.GroupQuotaInDB, result.Error.Error())
+	}
+	return quotas, nil
+}
+
+func (d *dao) UpdateWarnedThreshold(ctx context.Context, id uint, threshold int) error {
+	result := d.db.WithContext(ctx).Exec(common.GroupQuotaUpdateWarnedThreshold, threshold, id)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.GroupQuotaInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) CountUsage(ctx context.Context, resource string, groupIDs []uint) (int64, error) {
+	var sql string
+	switch resource {
+	case models.ResourceApplications:
+		sql = common.GroupQuotaCountApplications
+	case models.ResourceClusters:
+		sql = common.GroupQuotaCountClusters
+	default:
+		return 0, herrors.NewErrGetFailed(herrors.GroupQuotaInDB,
+			fmt.Sprintf("quota of %s is not supported", resource))
+	}
+	var count int64
+	if len(groupIDs) == 0 {
+		return count, nil
+	}
+	if result := d.db.WithContext(ctx).Raw(sql, groupIDs).Scan(&count); result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.GroupQuotaInDB, result.Error.Error())
+	}
+	return count, nil
+}
+
+func (d *dao) CountChanges(ctx context.Context, resource string, groupIDs []uint,
+	since time.Time) (created int64, deleted int64, err error) {
+	var createdSQL, deletedSQL string
+	switch resource {
+	case models.ResourceApplications:
+		createdSQL, deletedSQL = common.GroupQuotaCountApplicationsCreatedSince,
+			common.GroupQuotaCountApplicationsDeletedSince
+	case models.ResourceClusters:
+		createdSQL, deletedSQL = common.GroupQuotaCountClustersCreatedSince,
+			common.GroupQuotaCountClustersDeletedSince
+	default:
+		return 0, 0, herrors.NewErrGetFailed(herrors.GroupQuotaInDB,
+			fmt.Sprintf("quota of %s is not supported", resource))
+	}
+	if len(groupIDs) == 0 {
+		return 0, 0, nil
+	}
+	db := d.db.WithContext(ctx)
+	if result := db.Raw(createdSQL, groupIDs, since).Scan(&created); result.Error != nil {
+		return 0, 0, herrors.NewErrGetFailed(herrors.GroupQuotaInDB, result.Error.Error())
+	}
+	if result := db.Raw(deletedSQL, groupIDs, since.Unix()).Scan(&deleted); result.Error != nil {
+		return 0, 0, herrors.NewErrGetFailed(herrors.GroupQuotaInDB, result.Error.Error())
+	}
+	return created, deleted, nil
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/quota/dao"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// Set creates the quota of the resource under the group, or replaces the existing one
+	Set(ctx context.Context, quota *models.GroupQuota) error
+	Delete(ctx context.Context, groupID uint, resource string) error
+	// ListByGroupIDs lists the quotas of the groups, ordered by group and resource
+	ListByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.GroupQuota, error)
+	UpdateWarnedThreshold(ctx context.Context, id uint, threshold int) error
+	// CountUsage counts the resources which are not deleted under the groups
+	CountUsage(ctx context.Context, resource string, groupIDs []uint) (int64, error)
+	// CountChanges counts the resources created and deleted under the groups since the time
+	CountChanges(ctx context.Context, resource string, groupIDs []uint,
+		since time.Time) (created int64, deleted int64, err error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Set(ctx context.Context, quota *models.GroupQuota) error {
+	return m.dao.Upsert(ctx, quota)
+}
+
+func (m *manager) Delete(ctx context.Context, groupID uint, resource string) error {
+	return m.dao.Delete(ctx, groupID, resource)
+}
+
+func (m *manager) ListByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.GroupQuota, error) {
+	return m.dao.ListByGroupIDs(ctx, groupIDs)
+}
+
+func (m *manager) UpdateWarnedThreshold(ctx context.Context, id uint, threshold int) error {
+	return m.dao.UpdateWarnedThreshold(ctx, id, threshold)
+}
+
+func (m *manager) CountUsage(ctx context.Context, resource string, groupIDs []uint) (int64, error) {
+	return m.dao.CountUsage(ctx, resource, groupIDs)
+}
+
+func (m *manager) CountChanges(ctx context.Context, resource string, groupIDs []uint,
+	since time.Time) (int64, int64, error) {
+	return m.dao.CountChanges(ctx, resource, groupIDs, since)
+}
@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.GroupQuota{}, &appmodels.Application{},
+		&clustermodels.Cluster{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	err := mgr.Set(ctx, &models.GroupQuota{
+		GroupID:   1,
+		Resource:  models.ResourceApplications,
+		HardLimit: 10,
+		CreatedBy: 1,
+		UpdatedBy: 1,
+	})
+	assert.Nil(t, err)
+	err = mgr.Set(ctx, &models.GroupQuota{
+		GroupID:   2,
+		Resource:  models.ResourceClusters,
+		HardLimit: 20,
+	})
+	assert.Nil(t, err)
+
+	// set again replaces the existing one
+	err = mgr.Set(ctx, &models.GroupQuota{
+		GroupID:   1,
+		Resource:  models.ResourceApplications,
+		HardLimit: 5,
+		UpdatedBy: 2,
+	})
+	assert.Nil(t, err)
+	quotas, err := mgr.ListByGroupIDs(ctx, []uint{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(quotas))
+	assert.Equal(t, uint(5), quotas[0].HardLimit)
+	assert.Equal(t, uint(2), quotas[0].UpdatedBy)
+	assert.Equal(t, models.ResourceClusters, quotas[1].Resource)
+
+	err = mgr.UpdateWarnedThreshold(ctx, quotas[0].ID, 80)
+	assert.Nil(t, err)
+	quotas, err = mgr.ListByGroupIDs(ctx, []uint{1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(quotas))
+	assert.Equal(t, 80, quotas[0].WarnedThreshold)
+
+	err = mgr.Delete(ctx, 2, models.ResourceClusters)
+	assert.Nil(t, err)
+	err = mgr.Delete(ctx, 2, models.ResourceClusters)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestCount(t *testing.T) {
+	since := time.Now().Add(-time.Hour)
+	apps := []*appmodels.Application{
+		{Name: "app1", GroupID: 11},
+		{Name: "app2", GroupID: 11},
+		{Name: "app3", GroupID: 12},
+	}
+	for _, app := range apps {
+		assert.Nil(t, db.Create(app).Error)
+	}
+	for i, app := range apps {
+		assert.Nil(t, db.Create(&clustermodels.Cluster{
+			Name:          app.Name + "-cluster",
+			ApplicationID: app.ID,
+		}).Error)
+		if i == 0 {
+			assert.Nil(t, db.Create(&clustermodels.Cluster{
+				Name:          app.Name + "-cluster2",
+				ApplicationID: app.ID,
+			}).Error)
+		}
+	}
+	assert.Nil(t, db.Delete(&appmodels.Application{}, apps[1].ID).Error)
+
+	count, err := mgr.CountUsage(ctx, models.ResourceApplications, []uint{11, 12})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+	count, err = mgr.CountUsage(ctx, models.ResourceClusters, []uint{11})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+	count, err = mgr.CountUsage(ctx, models.ResourceClusters, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+	_, err = mgr.CountUsage(ctx, "unknown", []uint{11})
+	assert.NotNil(t, err)
+
+	created, deleted, err := mgr.CountChanges(ctx, models.ResourceApplications, []uint{11}, since)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), created)
+	assert.Equal(t, int64(1), deleted)
+	created, deleted, err = mgr.CountChanges(ctx, models.ResourceApplications, []uint{11},
+		time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), created)
+	assert.Equal(t, int64(0), deleted)
+}
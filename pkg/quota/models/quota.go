@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// resources limited by quotas of groups
+const (
+	ResourceApplications = "applications"
+	ResourceClusters     = "clusters"
+)
+
+// Resources are all the resources limited by quotas
+var Resources = []string{ResourceApplications, ResourceClusters}
+
+// SoftThresholds are the usages in percentage of a quota that the group is warned at, in ascending order
+var SoftThresholds = []int{80, 90}
+
+// GroupQuota limits the number of a resource under a group and all its subgroups
+type GroupQuota struct {
+	ID       uint
+	GroupID  uint   `gorm:"uniqueIndex:idx_group_resource"`
+	Resource string `gorm:"uniqueIndex:idx_group_resource"`
+	// HardLimit is the max number of the resource, creations fail once it's reached
+	HardLimit uint
+	// WarnedThreshold is the highest soft threshold warned, it's lowered as the usage drops below it,
+	// so that a threshold is warned once each time it's reached
+	WarnedThreshold int
+	CreatedAt       time.Time
+	CreatedBy       uint
+	UpdatedAt       time.Time
+	UpdatedBy       uint
+}
@@ -0,0 +1,315 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package service enforces the quotas of groups on creations, warns the groups whose usages reach
+// the soft thresholds, and forecasts when the quotas are exhausted.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	// _forecastWindow is the period the growth of a usage is averaged over
+	_forecastWindow = 30 * 24 * time.Hour
+	// _forecastHorizon is how far the exhaustion is forecasted, quotas exhausted later are regarded as never
+	_forecastHorizon = 10 * 365 * 24 * time.Hour
+)
+
+// Usage is the usage of a quota effective on a group, which is set on the group or one of its parents
+type Usage struct {
+	GroupID   uint
+	Resource  string
+	HardLimit uint
+	Used      int64
+	// Threshold is the highest soft threshold reached, 0 if none is reached
+	Threshold int
+	// GrowthPerDay is the net number of the resource created per day in the forecast window
+	GrowthPerDay float64
+	// ExhaustAt is when the quota is forecasted to be exhausted at the growth. It's nil if the usage is not
+	// growing or it's beyond the forecast horizon, and it's now if the quota is exhausted already.
+	ExhaustAt *time.Time
+}
+
+type Service interface {
+	// Check returns ErrQuotaExceeded if no more of the resource can be created under the group,
+	// the quotas of the group and its parents are all checked
+	Check(ctx context.Context, groupID uint, resource string) error
+	// ListUsages reports the usages and forecasts of the quotas effective on the group
+	ListUsages(ctx context.Context, groupID uint) ([]*Usage, error)
+	// Process warns the groups whose usages reach the soft thresholds, it's registered as an event handler.
+	// The usages are evaluated against the thresholds warned, so processing the same events again on resume
+	// warns nothing new.
+	Process(ctx context.Context, events []*eventmodels.Event, resume bool) error
+}
+
+type service struct {
+	quotaMgr       quotamanager.Manager
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+	eventMgr       eventmanager.Manager
+}
+
+func NewService(manager *managerparam.Manager) Service {
+	return &service{
+		quotaMgr:       manager.QuotaMgr,
+		groupMgr:       manager.GroupMgr,
+		applicationMgr: manager.ApplicationMgr,
+		clusterMgr:     manager.ClusterMgr,
+		eventMgr:       manager.EventMgr,
+	}
+}
+
+func (s *service) Check(ctx context.Context, groupID uint, resource string) error {
+	quotas, err := s.quotasOf(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	for _, quota := range quotas {
+		if quota.Resource != resource {
+			continue
+		}
+		used, err := s.usageOf(ctx, quota)
+		if err != nil {
+			return err
+		}
+		if used >= int64(quota.HardLimit) {
+			return perror.Wrapf(herrors.ErrQuotaExceeded,
+				"%d %s are under group %d, which reach its quota %d, please request more quota",
+				used, resource, quota.GroupID, quota.HardLimit)
+		}
+	}
+	return nil
+}
+
+func (s *service) ListUsages(ctx context.Context, groupID uint) ([]*Usage, error) {
+	quotas, err := s.quotasOf(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	usages := make([]*Usage, 0, len(quotas))
+	for _, quota := range quotas {
+		usage, err := s.forecast(ctx, quota, now)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+func (s *service) forecast(ctx context.Context, quota *models.GroupQuota, now time.Time) (*Usage, error) {
+	groupIDs, err := s.subGroupIDsOf(ctx, quota.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.quotaMgr.CountUsage(ctx, quota.Resource, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	created, deleted, err := s.quotaMgr.CountChanges(ctx, quota.Resource, groupIDs, now.Add(-_forecastWindow))
+	if err != nil {
+		return nil, err
+	}
+	usage := &Usage{
+		GroupID:      quota.GroupID,
+		Resource:     quota.Resource,
+		HardLimit:    quota.HardLimit,
+		Used:         used,
+		Threshold:    reachedThreshold(used, quota.HardLimit),
+		GrowthPerDay: float64(created-deleted) / _forecastWindow.Hours() * 24,
+	}
+	usage.ExhaustAt = exhaustAt(used, quota.HardLimit, usage.GrowthPerDay, now)
+	return usage, nil
+}
+
+// exhaustAt forecasts linearly when the usage reaches the limit at the growth per day
+func exhaustAt(used int64, limit uint, growthPerDay float64, now time.Time) *time.Time {
+	if used >= int64(limit) {
+		return &now
+	}
+	if growthPerDay <= 0 {
+		return nil
+	}
+	remaining := time.Duration(float64(int64(limit)-used) / growthPerDay * 24 * float64(time.Hour))
+	if remaining > _forecastHorizon {
+		return nil
+	}
+	at := now.Add(remaining)
+	return &at
+}
+
+// reachedThreshold returns the highest soft threshold the usage reaches, 0 if none is reached
+func reachedThreshold(used int64, limit uint) int {
+	for i := len(models.SoftThresholds) - 1; i >= 0; i-- {
+		if used*100 >= int64(models.SoftThresholds[i])*int64(limit) {
+			return models.SoftThresholds[i]
+		}
+	}
+	return 0
+}
+
+func (s *service) Process(ctx context.Context, events []*eventmodels.Event, _ bool) error {
+	type target struct {
+		groupID  uint
+		resource string
+	}
+	// usages are evaluated once for the events of a batch
+	evaluated := make(map[target]bool)
+	for _, event := range events {
+		groupID, resources, err := s.resourcesChangedBy(ctx, event)
+		if err != nil {
+			log.Warningf(ctx, "failed to get the group of event %d, err: %v", event.ID, err)
+			continue
+		}
+		for _, resource := range resources {
+			t := target{groupID: groupID, resource: resource}
+			if evaluated[t] {
+				continue
+			}
+			evaluated[t] = true
+			if err := s.warn(ctx, groupID, resource); err != nil {
+				log.Warningf(ctx, "failed to check the quota of %s under group %d, err: %v",
+					resource, groupID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// resourcesChangedBy returns the group whose usages are changed by the event and the resources changed
+func (s *service) resourcesChangedBy(ctx context.Context, event *eventmodels.Event) (uint, []string, error) {
+	switch event.EventType {
+	case eventmodels.ApplicationCreated, eventmodels.ApplicationDeleted, eventmodels.ApplicationTransfered,
+		eventmodels.ApplicationRestored:
+		application, err := s.applicationMgr.GetByIDIncludeSoftDelete(ctx, event.ResourceID)
+		if err != nil {
+			return 0, nil, err
+		}
+		// the clusters of the application are deleted or transferred with it
+		return application.GroupID, models.Resources, nil
+	case eventmodels.ClusterCreated, eventmodels.ClusterDeleted, eventmodels.ClusterRestored:
+		cluster, err := s.clusterMgr.GetByIDIncludeSoftDelete(ctx, event.ResourceID)
+		if err != nil {
+			return 0, nil, err
+		}
+		application, err := s.applicationMgr.GetByIDIncludeSoftDelete(ctx, cluster.ApplicationID)
+		if err != nil {
+			return 0, nil, err
+		}
+		return application.GroupID, []string{models.ResourceClusters}, nil
+	}
+	return 0, nil, nil
+}
+
+// warn records a GroupQuotaWarned event for each quota of the resource whose usage reaches a soft threshold
+// higher than the one warned, the threshold warned is lowered as the usage drops
+func (s *service) warn(ctx context.Context, groupID uint, resource string) error {
+	quotas, err := s.quotasOf(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	for _, quota := range quotas {
+		if quota.Resource != resource {
+			continue
+		}
+		used, err := s.usageOf(ctx, quota)
+		if err != nil {
+			return err
+		}
+		threshold := reachedThreshold(used, quota.HardLimit)
+		if threshold == quota.WarnedThreshold {
+			continue
+		}
+		if threshold > quota.WarnedThreshold {
+			extra, err := json.Marshal(eventmodels.QuotaWarning{
+				Resource:  resource,
+				Used:      used,
+				HardLimit: quota.HardLimit,
+				Threshold: threshold,
+			})
+			if err != nil {
+				return err
+			}
+			extraStr := string(extra)
+			if _, err := s.eventMgr.CreateEvent(ctx, &eventmodels.Event{
+				EventSummary: eventmodels.EventSummary{
+					ResourceType: common.ResourceGroup,
+					ResourceID:   quota.GroupID,
+					EventType:    eventmodels.GroupQuotaWarned,
+					Extra:        &extraStr,
+				},
+			}); err != nil {
+				return err
+			}
+			log.Infof(ctx, "group %d is warned that %d%% of its %s quota is used", quota.GroupID,
+				threshold, resource)
+		}
+		if err := s.quotaMgr.UpdateWarnedThreshold(ctx, quota.ID, threshold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quotasOf lists the quotas effective on the group, which are set on the group and its parents
+func (s *service) quotasOf(ctx context.Context, groupID uint) ([]*models.GroupQuota, error) {
+	group, err := s.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.quotaMgr.ListByGroupIDs(ctx, groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs))
+}
+
+func (s *service) usageOf(ctx context.Context, quota *models.GroupQuota) (int64, error) {
+	groupIDs, err := s.subGroupIDsOf(ctx, quota.GroupID)
+	if err != nil {
+		return 0, err
+	}
+	return s.quotaMgr.CountUsage(ctx, quota.Resource, groupIDs)
+}
+
+// subGroupIDsOf returns the ids of the group and all its subgroups
+func (s *service) subGroupIDsOf(ctx context.Context, groupID uint) ([]uint, error) {
+	groups, err := s.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{groupID})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
+	}
+	if len(ids) == 0 {
+		return nil, herrors.NewErrNotFound(herrors.GroupInDB, fmt.Sprintf("group %d not found", groupID))
+	}
+	return ids, nil
+}
@@ -0,0 +1,144 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.GroupQuota{}, &groupmodels.Group{}, &appmodels.Application{},
+		&clustermodels.Cluster{}, &membermodels.Member{}, &eventmodels.Event{}))
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	manager := managerparam.InitManager(db)
+	svc := NewService(manager)
+	warningsOf := func(groupID uint) []*eventmodels.Event {
+		var events []*eventmodels.Event
+		assert.Nil(t, db.Where("resource_type = ? and resource_id = ? and event_type = ?",
+			common.ResourceGroup, groupID, eventmodels.GroupQuotaWarned).Find(&events).Error)
+		return events
+	}
+
+	parent, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{Name: "parent", Path: "parent"})
+	assert.Nil(t, err)
+	child, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{Name: "child", Path: "child",
+		ParentID: parent.ID})
+	assert.Nil(t, err)
+
+	// no quota is set
+	assert.Nil(t, svc.Check(ctx, child.ID, models.ResourceApplications))
+
+	assert.Nil(t, manager.QuotaMgr.Set(ctx, &models.GroupQuota{
+		GroupID:   parent.ID,
+		Resource:  models.ResourceApplications,
+		HardLimit: 10,
+	}))
+	var apps []*appmodels.Application
+	for i := 0; i < 8; i++ {
+		app := &appmodels.Application{Name: fmt.Sprintf("app%d", i), GroupID: child.ID}
+		assert.Nil(t, db.Create(app).Error)
+		apps = append(apps, app)
+	}
+	assert.Nil(t, svc.Check(ctx, child.ID, models.ResourceApplications))
+	// clusters are not limited
+	assert.Nil(t, svc.Check(ctx, child.ID, models.ResourceClusters))
+
+	// the usage reaches 80% of the quota of the parent
+	events := []*eventmodels.Event{{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceApplication,
+			ResourceID:   apps[7].ID,
+			EventType:    eventmodels.ApplicationCreated,
+		},
+	}}
+	assert.Nil(t, svc.Process(ctx, events, false))
+	warned := warningsOf(parent.ID)
+	assert.Equal(t, 1, len(warned))
+	assert.Equal(t, eventmodels.GroupQuotaWarned, warned[0].EventType)
+	var warning eventmodels.QuotaWarning
+	assert.Nil(t, json.Unmarshal([]byte(*warned[0].Extra), &warning))
+	assert.Equal(t, eventmodels.QuotaWarning{
+		Resource:  models.ResourceApplications,
+		Used:      8,
+		HardLimit: 10,
+		Threshold: 80,
+	}, warning)
+
+	// the threshold is warned only once
+	assert.Nil(t, svc.Process(ctx, events, false))
+	assert.Equal(t, 1, len(warningsOf(parent.ID)))
+
+	for i := 8; i < 10; i++ {
+		assert.Nil(t, db.Create(&appmodels.Application{Name: fmt.Sprintf("app%d", i),
+			GroupID: child.ID}).Error)
+	}
+	err = svc.Check(ctx, child.ID, models.ResourceApplications)
+	assert.Equal(t, herrors.ErrQuotaExceeded, perror.Cause(err))
+
+	usages, err := svc.ListUsages(ctx, child.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(usages))
+	assert.Equal(t, parent.ID, usages[0].GroupID)
+	assert.Equal(t, int64(10), usages[0].Used)
+	assert.Equal(t, 90, usages[0].Threshold)
+	assert.InDelta(t, 10.0/30, usages[0].GrowthPerDay, 0.001)
+	assert.NotNil(t, usages[0].ExhaustAt)
+
+	// the threshold warned is lowered as the usage drops
+	assert.Nil(t, db.Delete(&appmodels.Application{}, apps[0].ID, apps[1].ID, apps[2].ID).Error)
+	assert.Nil(t, svc.Process(ctx, events, false))
+	quotas, err := manager.QuotaMgr.ListByGroupIDs(ctx, []uint{parent.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, quotas[0].WarnedThreshold)
+	assert.Nil(t, svc.Check(ctx, child.ID, models.ResourceApplications))
+}
+
+func TestForecast(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, 0, reachedThreshold(7, 10))
+	assert.Equal(t, 80, reachedThreshold(8, 10))
+	assert.Equal(t, 90, reachedThreshold(95, 100))
+	assert.Equal(t, 90, reachedThreshold(12, 10))
+
+	assert.Equal(t, now, *exhaustAt(10, 10, 0, now))
+	assert.Nil(t, exhaustAt(5, 10, 0, now))
+	assert.Nil(t, exhaustAt(5, 10, -1, now))
+	assert.Equal(t, now.Add(5*24*time.Hour), *exhaustAt(5, 10, 1, now))
+	// exhaustion beyond the horizon is never
+	assert.Nil(t, exhaustAt(0, 10000, 1, now))
+}
@@ -55,6 +55,7 @@ const (
 	MemberNotExist    = "member not exist"
 	RoleNotExist      = "role not exist"
 	AdminAllow        = "admin allows everything"
+	AuditorAllow      = "auditor allows reading"
 )
 
 func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision,
@@ -67,14 +68,25 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 	if currentUser.IsAdmin() {
 		return auth.DecisionAllow, AdminAllow, nil
 	}
+	// auditors read the resources listed in the auditor role regardless of their memberships,
+	// other requests of auditors, including interactive ones like shell, are checked as usual
+	if currentUser.IsAuditor() {
+		auditorRole, err := a.roleService.GetRole(ctx, role.Auditor)
+		if err != nil {
+			log.Warningf(ctx, "get role for role(%s), err = %+v", role.Auditor, err)
+		} else if decision, _, _ := VisitRoles(nil, auditorRole, attr); decision == auth.DecisionAllow {
+			return auth.DecisionAllow, AuditorAllow, nil
+		}
+	}
 
 	// TODO(tom): members, users, accesstokens and environments need to add to auth check
 	if attr.IsResourceRequest() && (attr.GetResource() == "members" ||
 		attr.GetResource() == "environments" || attr.GetResource() == "users" ||
-		attr.GetResource() == "personalaccesstokens" ||
+		attr.GetResource() == "personalaccesstokens" || attr.GetResource() == "sandboxes" ||
+		attr.GetResource() == "notificationsubscriptions" ||
 		(attr.GetResource() == "accesstokens" && attr.GetVerb() == "delete")) {
 		log.Warning(ctx,
-			"members|environments|access tokens are not authed yet")
+			"members|environments|access tokens|sandboxes are not authed yet")
 		return auth.DecisionAllow, NotChecked, nil
 	}
 
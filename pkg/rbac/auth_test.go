@@ -28,6 +28,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/auth"
 	"github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 )
 
@@ -146,3 +147,65 @@ func TestAuthRole(t *testing.T) {
 	assert.Equal(t, auth.DecisionDeny, decision)
 	assert.Nil(t, err)
 }
+
+func TestAuthAuditor(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	memberServiceMock := servicemock.NewMockService(mockCtl)
+	roleServiceMock := rolemock.NewMockService(mockCtl)
+	testAuthorizer := Authorizer(&authorizer{
+		roleService:   roleServiceMock,
+		memberService: memberServiceMock,
+	})
+	auditor := &user.DefaultInfo{
+		Name:    "jerry",
+		ID:      2,
+		Auditor: true,
+	}
+	auditorCtx := common.WithContext(context.Background(), auditor)
+	roleServiceMock.EXPECT().GetRole(auditorCtx, role.Auditor).Return(&types.Role{
+		Name: role.Auditor,
+		PolicyRules: []types.PolicyRule{{
+			Verbs:     []string{"get", "list"},
+			APIGroups: []string{"core"},
+			Resources: []string{"clusters", "clusters/envs"},
+			Scopes:    []string{"*"},
+		}},
+	}, nil).Times(3)
+
+	// reading is allowed without membership
+	authRecord := auth.AttributesRecord{
+		User:            auditor,
+		Verb:            "get",
+		APIGroup:        "core",
+		APIVersion:      "v2",
+		Resource:        "clusters",
+		SubResource:     "envs",
+		Name:            "123",
+		ResourceRequest: true,
+	}
+	decision, reason, err := testAuthorizer.Authorize(auditorCtx, authRecord)
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+	assert.Equal(t, AuditorAllow, reason)
+
+	// writing is checked against the membership
+	authRecord.Verb = "create"
+	memberServiceMock.EXPECT().GetMemberOfResource(auditorCtx, gomock.Any(),
+		gomock.Any()).Return(nil, nil).Times(1)
+	roleServiceMock.EXPECT().GetDefaultRole(auditorCtx).Return(nil).Times(1)
+	decision, reason, err = testAuthorizer.Authorize(auditorCtx, authRecord)
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, MemberNotExist, reason)
+
+	// interactive access is not granted by the auditor role though it's requested by GET
+	authRecord.Verb = "get"
+	authRecord.SubResource = "shell"
+	memberServiceMock.EXPECT().GetMemberOfResource(auditorCtx, gomock.Any(),
+		gomock.Any()).Return(nil, nil).Times(1)
+	roleServiceMock.EXPECT().GetDefaultRole(auditorCtx).Return(nil).Times(1)
+	decision, reason, err = testAuthorizer.Authorize(auditorCtx, authRecord)
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, MemberNotExist, reason)
+}
@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"encoding/json"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	customrolemodels "github.com/horizoncd/horizon/pkg/customrole/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+type customRoleService struct {
+	*fileRoleService
+	customRoleMgr customrolemanager.Manager
+}
+
+// NewCustomRoleService returns a Service serving the built-in roles of the config along with the custom roles
+// defined by admins. Custom roles rank lower than all the built-in roles except the default one, and rank equal
+// to each other, so members of the built-in roles above can grant them but not the other way around.
+func NewCustomRoleService(ctx context.Context, config roleconfig.Config,
+	customRoleMgr customrolemanager.Manager) (Service, error) {
+	service, err := NewFileRoleFrom2(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &customRoleService{
+		fileRoleService: service.(*fileRoleService),
+		customRoleMgr:   customRoleMgr,
+	}, nil
+}
+
+func (s *customRoleService) ListRole(ctx context.Context) ([]types.Role, error) {
+	roles, err := s.fileRoleService.ListRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	customRoles, err := s.customRoleMgr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, customRole := range customRoles {
+		role, err := OfCustomRole(customRole)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
+
+func (s *customRoleService) GetRole(ctx context.Context, roleName string) (*types.Role, error) {
+	if role, err := s.fileRoleService.GetRole(ctx, roleName); err == nil {
+		return role, nil
+	}
+	customRole, err := s.getCustomRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	return OfCustomRole(customRole)
+}
+
+func (s *customRoleService) RoleCompare(ctx context.Context, role1, role2 string) (CompResult, error) {
+	rank1, err := s.rank(ctx, role1)
+	if err != nil {
+		return RoleCanNotCompare, err
+	}
+	rank2, err := s.rank(ctx, role2)
+	if err != nil {
+		return RoleCanNotCompare, err
+	}
+	if rank1 < rank2 {
+		return RoleBigger, nil
+	} else if rank1 > rank2 {
+		return RoleSmaller, nil
+	}
+	return RoleEqual, nil
+}
+
+// rank returns the rank of the role, the smaller the higher. Built-in roles rank at the even numbers
+// in their order, and custom roles rank at the odd number right above the default role.
+func (s *customRoleService) rank(ctx context.Context, roleName string) (int, error) {
+	if item, ok := s.roleRankMap[roleName]; ok {
+		return item.rank * 2, nil
+	}
+	if _, err := s.getCustomRole(ctx, roleName); err != nil {
+		return 0, err
+	}
+	if s.DefaultRole != nil {
+		return s.roleRankMap[s.DefaultRoleName].rank*2 - 1, nil
+	}
+	return len(s.RolePriorityRankDesc) * 2, nil
+}
+
+func (s *customRoleService) getCustomRole(ctx context.Context,
+	roleName string) (*customrolemodels.CustomRole, error) {
+	customRole, err := s.customRoleMgr.GetByName(ctx, roleName)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, ErrorRoleNotFound
+		}
+		return nil, err
+	}
+	return customRole, nil
+}
+
+// OfCustomRole converts the custom role stored to a role
+func OfCustomRole(customRole *customrolemodels.CustomRole) (*types.Role, error) {
+	role := &types.Role{
+		Name: customRole.Name,
+		Desc: customRole.Desc,
+	}
+	if err := json.Unmarshal([]byte(customRole.Rules), &role.PolicyRules); err != nil {
+		return nil, perror.Wrapf(err, "failed to unmarshal the rules of role %s", customRole.Name)
+	}
+	return role, nil
+}
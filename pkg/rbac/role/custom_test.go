@@ -0,0 +1,82 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"testing"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	customrolemodels "github.com/horizoncd/horizon/pkg/customrole/models"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCustomRoleService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&customrolemodels.CustomRole{}))
+	mgr := customrolemanager.New(db)
+
+	var config roleconfig.Config
+	assert.Nil(t, yaml.Unmarshal([]byte(roleForTestOk), &config))
+	service, err := NewCustomRoleService(ctx, config, mgr)
+	assert.Nil(t, err)
+
+	_, err = service.GetRole(ctx, "deployer")
+	assert.Equal(t, ErrorRoleNotFound, err)
+
+	assert.Nil(t, mgr.Create(ctx, &customrolemodels.CustomRole{
+		Name:  "deployer",
+		Desc:  "deploy clusters only",
+		Rules: `[{"verbs":["create"],"apiGroups":["core"],"resources":["clusters/deploy"],"scopes":["*"]}]`,
+	}))
+	assert.Nil(t, mgr.Create(ctx, &customrolemodels.CustomRole{
+		Name:  "reader",
+		Rules: `[{"verbs":["get"],"apiGroups":["core"],"resources":["clusters"],"scopes":["*"]}]`,
+	}))
+
+	role, err := service.GetRole(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, "deploy clusters only", role.Desc)
+	assert.Equal(t, 1, len(role.PolicyRules))
+	assert.Equal(t, []string{"clusters/deploy"}, role.PolicyRules[0].Resources)
+
+	// built-in roles are served as well
+	role, err = service.GetRole(ctx, "owner")
+	assert.Nil(t, err)
+	assert.Equal(t, "owner", role.Name)
+
+	roles, err := service.ListRole(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(roles))
+	assert.Equal(t, "owner", roles[0].Name)
+	assert.Equal(t, "deployer", roles[2].Name)
+	assert.Equal(t, "reader", roles[3].Name)
+
+	// custom roles rank right above the default role
+	result, err := service.RoleCompare(ctx, "owner", "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleBigger, result)
+	result, err = service.RoleCompare(ctx, "maintainer", "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleSmaller, result)
+	result, err = service.RoleCompare(ctx, "reader", "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleEqual, result)
+	result, err = service.RoleCompare(ctx, "unknown", "deployer")
+	assert.Equal(t, ErrorRoleNotFound, err)
+	assert.Equal(t, RoleCanNotCompare, result)
+}
@@ -40,6 +40,8 @@ const (
 	Owner      string = "owner"
 	Maintainer string = "maintainer"
 	Guest      string = "guest"
+	// Auditor is granted to users marked as auditor rather than members of resources
+	Auditor string = "auditor"
 )
 
 var (
@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/recentvisit/models"
+)
+
+type DAO interface {
+	// Upsert creates the visit of the resource by the user, or refreshes its visitedAt if visited before
+	Upsert(ctx context.Context, visit *models.RecentVisit) error
+	// List lists the visits of the user, latest first. resourceType is ignored if empty
+	List(ctx context.Context, userID uint, resourceType string, offset, limit int) ([]*models.RecentVisit, error)
+	// DeleteByIDs deletes the visits by ids
+	DeleteByIDs(ctx context.Context, ids []uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Upsert(ctx context.Context, visit *models.RecentVisit) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "user_id"}, {Name: "resource_type"}, {Name: "resource_id"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"visited_at"}),
+	}).Create(visit)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.RecentVisitInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) List(ctx context.Context, userID uint, resourceType string,
+	offset, limit int) ([]*models.RecentVisit, error) {
+	var visits []*models.RecentVisit
+	statement := d.db.WithContext(ctx).Where("user_id = ?", userID)
+	if resourceType != "" {
+		statement = statement.Where("resource_type = ?", resourceType)
+	}
+	result := statement.Order("visited_at desc").Order("id desc").
+		Offset(offset).Limit(limit).Find(&visits)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.RecentVisitInDB, result.Error.Error())
+	}
+	return visits, nil
+}
+
+func (d *dao) DeleteByIDs(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := d.db.WithContext(ctx).Where("id in ?", ids).Delete(&models.RecentVisit{})
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.RecentVisitInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/recentvisit/dao"
+	"github.com/horizoncd/horizon/pkg/recentvisit/models"
+)
+
+// _maxVisitsPerUser is the number of visits kept for each user, the older ones are pruned on recording
+const _maxVisitsPerUser = 50
+
+type Manager interface {
+	// Record records that the user visited the resource just now
+	Record(ctx context.Context, userID uint, resourceType string, resourceID uint) error
+	// List lists at most limit visits of the user, latest first. resourceType is ignored if empty
+	List(ctx context.Context, userID uint, resourceType string, limit int) ([]*models.RecentVisit, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Record(ctx context.Context, userID uint, resourceType string, resourceID uint) error {
+	if err := m.dao.Upsert(ctx, &models.RecentVisit{
+		UserID:       userID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		VisitedAt:    time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	exceeded, err := m.dao.List(ctx, userID, "", _maxVisitsPerUser, _maxVisitsPerUser)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(exceeded))
+	for _, visit := range exceeded {
+		ids = append(ids, visit.ID)
+	}
+	return m.dao.DeleteByIDs(ctx, ids)
+}
+
+func (m *manager) List(ctx context.Context, userID uint, resourceType string,
+	limit int) ([]*models.RecentVisit, error) {
+	if limit <= 0 || limit > _maxVisitsPerUser {
+		limit = _maxVisitsPerUser
+	}
+	return m.dao.List(ctx, userID, resourceType, 0, limit)
+}
@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/recentvisit/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.RecentVisit{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	userID := uint(1)
+	assert.Nil(t, mgr.Record(ctx, userID, common.ResourceApplication, 1))
+	assert.Nil(t, mgr.Record(ctx, userID, common.ResourceCluster, 1))
+	assert.Nil(t, mgr.Record(ctx, userID, common.ResourceCluster, 2))
+	// visiting again refreshes the visit instead of adding one
+	assert.Nil(t, mgr.Record(ctx, userID, common.ResourceApplication, 1))
+	assert.Nil(t, mgr.Record(ctx, 2, common.ResourceApplication, 1))
+
+	visits, err := mgr.List(ctx, userID, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(visits))
+	assert.Equal(t, common.ResourceApplication, visits[0].ResourceType)
+	assert.Equal(t, uint(1), visits[0].ResourceID)
+
+	visits, err = mgr.List(ctx, userID, common.ResourceCluster, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(visits))
+	assert.Equal(t, uint(2), visits[0].ResourceID)
+
+	// only the latest visits are kept
+	for i := 0; i < _maxVisitsPerUser+5; i++ {
+		assert.Nil(t, mgr.Record(ctx, 3, common.ResourceCluster, uint(i+1)))
+	}
+	visits, err = mgr.List(ctx, 3, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, _maxVisitsPerUser, len(visits))
+	assert.Equal(t, uint(_maxVisitsPerUser+5), visits[0].ResourceID)
+}
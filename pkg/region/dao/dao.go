@@ -103,13 +103,16 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, region *models.Region) er
 		return err
 	}
 
-	// can only update displayName, server, Certificate, ingressDomain、prometheusURL, registryID
+	// can only update displayName, server, Certificate, ingressDomain、prometheusURL, registryID, cdType
 	regionInDB.DisplayName = region.DisplayName
 	regionInDB.Server = region.Server
 	regionInDB.Certificate = region.Certificate
 	regionInDB.IngressDomain = region.IngressDomain
 	regionInDB.PrometheusURL = region.PrometheusURL
 	regionInDB.RegistryID = region.RegistryID
+	if region.CDType != "" {
+		regionInDB.CDType = region.CDType
+	}
 	regionInDB.Disabled = region.Disabled
 	result := d.db.WithContext(ctx).Save(regionInDB)
 	if result.Error != nil {
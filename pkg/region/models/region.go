@@ -20,6 +20,12 @@ import (
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 )
 
+const (
+	// CDTypeArgoCD is the default cd system of regions
+	CDTypeArgoCD = "argocd"
+	CDTypeFlux   = "flux"
+)
+
 type Region struct {
 	global.Model
 
@@ -30,9 +36,11 @@ type Region struct {
 	IngressDomain string
 	PrometheusURL string
 	RegistryID    uint `gorm:"column:registry_id"`
-	Disabled      bool
-	CreatedBy     uint
-	UpdatedBy     uint
+	// CDType is the cd system syncing clusters of the region, argocd if empty
+	CDType    string `gorm:"column:cd_type"`
+	Disabled  bool
+	CreatedBy uint
+	UpdatedBy uint
 }
 
 // RegionEntity region entity, region with registry
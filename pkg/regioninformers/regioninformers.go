@@ -33,9 +33,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/clientcmd"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	"github.com/horizoncd/horizon/pkg/region/manager"
 	"github.com/horizoncd/horizon/pkg/region/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -112,11 +112,7 @@ func (f *RegionInformers) NewRegionInformers(region *models.Region) error {
 		return nil
 	}
 
-	config, err := clientcmd.NewClientConfigFromBytes([]byte(region.Certificate))
-	if err != nil {
-		return err
-	}
-	restConfig, err := config.ClientConfig()
+	restConfig, err := kubeclient.Fty.RestConfig(region)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/robot/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, robot *models.Robot) (*models.Robot, error)
+	GetByID(ctx context.Context, id uint) (*models.Robot, error)
+	GetByUserID(ctx context.Context, userID uint) (*models.Robot, error)
+	ListByResource(ctx context.Context, resourceType string, resourceID uint) ([]*models.Robot, error)
+	UpdateByID(ctx context.Context, id uint, robot *models.Robot) (*models.Robot, error)
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type dao struct{ db *gorm.DB }
+
+// NewDAO returns an instance of the default DAO
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, robot *models.Robot) (*models.Robot, error) {
+	if result := d.db.WithContext(ctx).Create(robot); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.RobotInDB, result.Error.Error())
+	}
+	return robot, nil
+}
+
+func (d *dao) get(ctx context.Context, query string, args ...interface{}) (*models.Robot, error) {
+	var robot models.Robot
+	if result := d.db.WithContext(ctx).Where(query, args...).First(&robot); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.RobotInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.RobotInDB, result.Error.Error())
+	}
+	return &robot, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Robot, error) {
+	return d.get(ctx, "id = ?", id)
+}
+
+func (d *dao) GetByUserID(ctx context.Context, userID uint) (*models.Robot, error) {
+	return d.get(ctx, "user_id = ?", userID)
+}
+
+func (d *dao) ListByResource(ctx context.Context, resourceType string,
+	resourceID uint) ([]*models.Robot, error) {
+	var robots []*models.Robot
+	if result := d.db.WithContext(ctx).Where("resource_type = ?", resourceType).
+		Where("resource_id = ?", resourceID).Order("id asc").Find(&robots); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.RobotInDB, result.Error.Error())
+	}
+	return robots, nil
+}
+
+func (d *dao) UpdateByID(ctx context.Context, id uint, robot *models.Robot) (*models.Robot, error) {
+	if result := d.db.WithContext(ctx).Where("id = ?", id).
+		Select("description", "disabled", "updated_by").
+		Updates(robot); result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.RobotInDB, result.Error.Error())
+	}
+	return d.GetByID(ctx, id)
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	if result := d.db.WithContext(ctx).Delete(&models.Robot{}, id); result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.RobotInDB, result.Error.Error())
+	}
+	return nil
+}
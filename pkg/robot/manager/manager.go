@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/robot/dao"
+	"github.com/horizoncd/horizon/pkg/robot/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	Create(ctx context.Context, robot *models.Robot) (*models.Robot, error)
+	GetByID(ctx context.Context, id uint) (*models.Robot, error)
+	// GetByUserID gets the robot owning the user, a not found error is returned if the user is not a robot's
+	GetByUserID(ctx context.Context, userID uint) (*models.Robot, error)
+	ListByResource(ctx context.Context, resourceType string, resourceID uint) ([]*models.Robot, error)
+	// UpdateByID updates the description and the disabled of the robot
+	UpdateByID(ctx context.Context, id uint, robot *models.Robot) (*models.Robot, error)
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, robot *models.Robot) (*models.Robot, error) {
+	const op = "robot manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, robot)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.Robot, error) {
+	const op = "robot manager: get by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) GetByUserID(ctx context.Context, userID uint) (*models.Robot, error) {
+	const op = "robot manager: get by user id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByUserID(ctx, userID)
+}
+
+func (m *manager) ListByResource(ctx context.Context, resourceType string,
+	resourceID uint) ([]*models.Robot, error) {
+	const op = "robot manager: list by resource"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByResource(ctx, resourceType, resourceID)
+}
+
+func (m *manager) UpdateByID(ctx context.Context, id uint, robot *models.Robot) (*models.Robot, error) {
+	const op = "robot manager: update by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateByID(ctx, id, robot)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	const op = "robot manager: delete by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteByID(ctx, id)
+}
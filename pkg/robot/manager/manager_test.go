@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/robot/models"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Robot{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRobot(t *testing.T) {
+	for _, robot := range []*models.Robot{
+		{Name: "ci", ResourceType: common.ResourceGroup, ResourceID: 1, UserID: 11},
+		{Name: "deployer", ResourceType: common.ResourceApplication, ResourceID: 2, UserID: 12},
+		{Name: "release", ResourceType: common.ResourceApplication, ResourceID: 2, UserID: 13},
+	} {
+		_, err := mgr.Create(ctx, robot)
+		assert.Nil(t, err)
+	}
+
+	robots, err := mgr.ListByResource(ctx, common.ResourceApplication, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(robots))
+	assert.Equal(t, "deployer", robots[0].Name)
+	assert.Equal(t, "release", robots[1].Name)
+
+	robot, err := mgr.GetByUserID(ctx, 11)
+	assert.Nil(t, err)
+	assert.Equal(t, "ci", robot.Name)
+	_, err = mgr.GetByUserID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	robot, err = mgr.UpdateByID(ctx, robot.ID, &models.Robot{Description: "builds images", Disabled: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "ci", robot.Name)
+	assert.Equal(t, "builds images", robot.Description)
+	assert.True(t, robot.Disabled)
+
+	assert.Nil(t, mgr.DeleteByID(ctx, robot.ID))
+	_, err = mgr.GetByID(ctx, robot.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,36 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// Robot is a service account of a group or an application, such as the one used by ci pipelines.
+// It acts as the robot user it owns, which is a member of the resource and holds the access tokens.
+type Robot struct {
+	ID           uint
+	Name         string
+	Description  string
+	ResourceType string
+	ResourceID   uint
+	UserID       uint
+	// Disabled robots can not be authenticated by their tokens
+	Disabled  bool
+	CreatedAt time.Time
+	CreatedBy uint
+	UpdatedAt time.Time
+	UpdatedBy uint
+}
@@ -0,0 +1,165 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// formats of sbom document which can be parsed
+const (
+	FormatSyftJSON      = "syft-json"
+	FormatCycloneDXJSON = "cyclonedx-json"
+	FormatSPDXJSON      = "spdx-json"
+)
+
+// Component is a package found in an image
+type Component struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type"`
+	PURL    string `json:"purl"`
+}
+
+type syftDocument struct {
+	Artifacts []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Type    string `json:"type"`
+		PURL    string `json:"purl"`
+	} `json:"artifacts"`
+}
+
+type cycloneDXDocument struct {
+	Components []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Type    string `json:"type"`
+		PURL    string `json:"purl"`
+	} `json:"components"`
+}
+
+type spdxDocument struct {
+	Packages []struct {
+		Name         string `json:"name"`
+		VersionInfo  string `json:"versionInfo"`
+		ExternalRefs []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+// DetectFormat detects the format of sbom document, syft json is the default format
+func DetectFormat(content []byte) string {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return FormatSyftJSON
+	}
+	switch {
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		return FormatCycloneDXJSON
+	case probe.SPDXVersion != "":
+		return FormatSPDXJSON
+	default:
+		return FormatSyftJSON
+	}
+}
+
+// Parse parses components from sbom document, components with the same name and version are merged
+func Parse(format string, content []byte) ([]*Component, error) {
+	components := make([]*Component, 0)
+	switch format {
+	case FormatSyftJSON:
+		var doc syftDocument
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse syft document: %v", err)
+		}
+		for _, artifact := range doc.Artifacts {
+			components = append(components, &Component{
+				Name:    artifact.Name,
+				Version: artifact.Version,
+				Type:    artifact.Type,
+				PURL:    artifact.PURL,
+			})
+		}
+	case FormatCycloneDXJSON:
+		var doc cycloneDXDocument
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse cyclonedx document: %v", err)
+		}
+		for _, c := range doc.Components {
+			components = append(components, &Component{
+				Name:    c.Name,
+				Version: c.Version,
+				Type:    c.Type,
+				PURL:    c.PURL,
+			})
+		}
+	case FormatSPDXJSON:
+		var doc spdxDocument
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse spdx document: %v", err)
+		}
+		for _, p := range doc.Packages {
+			component := &Component{
+				Name:    p.Name,
+				Version: p.VersionInfo,
+			}
+			for _, ref := range p.ExternalRefs {
+				if ref.ReferenceType == "purl" {
+					component.PURL = ref.ReferenceLocator
+					component.Type = purlType(ref.ReferenceLocator)
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sbom format %s", format)
+	}
+	return dedup(components), nil
+}
+
+// purlType returns the type of package url, such as maven for pkg:maven/org.apache/log4j@2.14.1
+func purlType(purl string) string {
+	purl = strings.TrimPrefix(purl, "pkg:")
+	if i := strings.Index(purl, "/"); i > 0 {
+		return purl[:i]
+	}
+	return ""
+}
+
+func dedup(components []*Component) []*Component {
+	seen := make(map[string]struct{}, len(components))
+	res := make([]*Component, 0, len(components))
+	for _, c := range components {
+		if c.Name == "" {
+			continue
+		}
+		key := c.Name + "@" + c.Version + "@" + c.PURL
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		res = append(res, c)
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	syft := []byte(`{"artifacts":[
+		{"name":"log4j-core","version":"2.14.1","type":"java-archive","purl":"pkg:maven/org.apache/log4j-core@2.14.1"},
+		{"name":"log4j-core","version":"2.14.1","type":"java-archive","purl":"pkg:maven/org.apache/log4j-core@2.14.1"},
+		{"name":"","version":"1.0.0"}]}`)
+	assert.Equal(t, FormatSyftJSON, DetectFormat(syft))
+	components, err := Parse(FormatSyftJSON, syft)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(components))
+	assert.Equal(t, "log4j-core", components[0].Name)
+	assert.Equal(t, "java-archive", components[0].Type)
+
+	cyclonedx := []byte(`{"bomFormat":"CycloneDX","components":[
+		{"name":"openssl","version":"1.1.1k","type":"library","purl":"pkg:apk/alpine/openssl@1.1.1k"}]}`)
+	assert.Equal(t, FormatCycloneDXJSON, DetectFormat(cyclonedx))
+	components, err = Parse(FormatCycloneDXJSON, cyclonedx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(components))
+	assert.Equal(t, "1.1.1k", components[0].Version)
+
+	spdx := []byte(`{"spdxVersion":"SPDX-2.3","packages":[{"name":"lodash","versionInfo":"4.17.20",
+		"externalRefs":[{"referenceType":"purl","referenceLocator":"pkg:npm/lodash@4.17.20"}]}]}`)
+	assert.Equal(t, FormatSPDXJSON, DetectFormat(spdx))
+	components, err = Parse(FormatSPDXJSON, spdx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(components))
+	assert.Equal(t, "npm", components[0].Type)
+	assert.Equal(t, "pkg:npm/lodash@4.17.20", components[0].PURL)
+
+	_, err = Parse("unknown", syft)
+	assert.NotNil(t, err)
+	_, err = Parse(FormatSyftJSON, []byte("not json"))
+	assert.NotNil(t, err)
+}
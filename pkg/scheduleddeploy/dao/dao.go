@@ -0,0 +1,108 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, deploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error)
+	GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error)
+	ListByCluster(ctx context.Context, clusterID uint, status string) ([]*models.ScheduledDeploy, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error)
+	UpdateStatus(ctx context.Context, id uint, from, to string, pipelinerunID uint, message string) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, deploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error) {
+	result := d.db.WithContext(ctx).Create(deploy)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return deploy, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error) {
+	var deploy models.ScheduledDeploy
+	result := d.db.WithContext(ctx).Raw(common.ScheduledDeployGetByID, id).Scan(&deploy)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ScheduledDeployInDB,
+			fmt.Sprintf("no scheduled deploy found for id %d", id))
+	}
+	return &deploy, nil
+}
+
+func (d *dao) ListByCluster(ctx context.Context, clusterID uint,
+	status string) ([]*models.ScheduledDeploy, error) {
+	deploys := make([]*models.ScheduledDeploy, 0)
+	query := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("scheduled_at desc").Order("id desc").Find(&deploys)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return deploys, nil
+}
+
+func (d *dao) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error) {
+	deploys := make([]*models.ScheduledDeploy, 0)
+	result := d.db.WithContext(ctx).
+		Where("status = ? and scheduled_at <= ?", models.StatusScheduled, now).
+		Order("scheduled_at").Order("id").Limit(limit).Find(&deploys)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return deploys, nil
+}
+
+func (d *dao) UpdateStatus(ctx context.Context, id uint, from, to string,
+	pipelinerunID uint, message string) error {
+	result := d.db.WithContext(ctx).Model(&models.ScheduledDeploy{ID: id}).
+		Where("status = ?", from).
+		Updates(map[string]interface{}{
+			"status":         to,
+			"pipelinerun_id": pipelinerunID,
+			"message":        message,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ScheduledDeployInDB,
+			fmt.Sprintf("no %s scheduled deploy found for id %d", from, id))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/scheduleddeploy/dao"
+	"github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, deploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error)
+	GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error)
+	// ListByCluster lists deploys of the cluster in the status, latest scheduled first,
+	// all deploys are listed if status is empty
+	ListByCluster(ctx context.Context, clusterID uint, status string) ([]*models.ScheduledDeploy, error)
+	// ListDue lists at most limit deploys which are scheduled at or before now, earliest first
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error)
+	// UpdateStatus moves the deploy from a status to another,
+	// it returns not found if the deploy is no longer in the from status
+	UpdateStatus(ctx context.Context, id uint, from, to string, pipelinerunID uint, message string) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, deploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error) {
+	return m.dao.Create(ctx, deploy)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByCluster(ctx context.Context, clusterID uint,
+	status string) ([]*models.ScheduledDeploy, error) {
+	return m.dao.ListByCluster(ctx, clusterID, status)
+}
+
+func (m *manager) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error) {
+	return m.dao.ListDue(ctx, now, limit)
+}
+
+func (m *manager) UpdateStatus(ctx context.Context, id uint, from, to string,
+	pipelinerunID uint, message string) error {
+	return m.dao.UpdateStatus(ctx, id, from, to, pipelinerunID, message)
+}
@@ -0,0 +1,91 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/scheduleddeploy/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ScheduledDeploy{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	now := time.Now()
+	for _, at := range []time.Time{now.Add(-time.Minute), now.Add(-2 * time.Minute), now.Add(time.Hour)} {
+		_, err := mgr.Create(ctx, &models.ScheduledDeploy{
+			ClusterID:   1,
+			Action:      "restart",
+			Status:      models.StatusScheduled,
+			ScheduledAt: at,
+		})
+		assert.Nil(t, err)
+	}
+
+	due, err := mgr.ListDue(ctx, now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(due))
+	assert.Equal(t, uint(2), due[0].ID)
+	due, err = mgr.ListDue(ctx, now, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(due))
+
+	// a deploy is claimed only once
+	assert.Nil(t, mgr.UpdateStatus(ctx, 2, models.StatusScheduled, models.StatusRunning, 0, ""))
+	err = mgr.UpdateStatus(ctx, 2, models.StatusScheduled, models.StatusRunning, 0, "")
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	assert.Nil(t, mgr.UpdateStatus(ctx, 2, models.StatusRunning, models.StatusTriggered, 10, ""))
+
+	deploy, err := mgr.GetByID(ctx, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusTriggered, deploy.Status)
+	assert.Equal(t, uint(10), deploy.PipelinerunID)
+
+	deploys, err := mgr.ListByCluster(ctx, 1, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(deploys))
+	assert.Equal(t, uint(3), deploys[0].ID)
+	deploys, err = mgr.ListByCluster(ctx, 1, models.StatusScheduled)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deploys))
+	deploys, err = mgr.ListByCluster(ctx, 2, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(deploys))
+}
@@ -0,0 +1,49 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	// StatusScheduled means the deploy waits for its time
+	StatusScheduled = "scheduled"
+	// StatusRunning means the deploy is being triggered by the job
+	StatusRunning = "running"
+	// StatusTriggered means the pipelinerun of the deploy has been created
+	StatusTriggered = "triggered"
+	// StatusFailed means the deploy failed to be triggered, the reason is in the message
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// ScheduledDeploy is a builddeploy or restart of a cluster triggered at a future time
+type ScheduledDeploy struct {
+	ID          uint
+	ClusterID   uint `gorm:"index:idx_cluster_id"`
+	Action      string
+	Title       string
+	Description string
+	GitRefType  string
+	GitRef      string
+	Status      string    `gorm:"index:idx_status_scheduled_at"`
+	ScheduledAt time.Time `gorm:"index:idx_status_scheduled_at"`
+	// PipelinerunID is the pipelinerun created when the deploy is triggered
+	PipelinerunID uint
+	Message       string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     uint
+	UpdatedBy     uint
+}
@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	dbcommon "github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/search/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// SearchByName lists the resources whose names, paths or descriptions match the pattern
+	SearchByName(ctx context.Context, resourceType, pattern string, limit int) ([]*models.Hit, error)
+	// SearchByTag lists the resources having tags whose keys or values match the pattern
+	SearchByTag(ctx context.Context, resourceType, pattern string, limit int) ([]*models.Hit, error)
+	// SearchByOwner lists the resources whose owners' names, full names or emails match the pattern
+	SearchByOwner(ctx context.Context, resourceType, pattern string, limit int) ([]*models.Hit, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) SearchByName(ctx context.Context, resourceType, pattern string, limit int) ([]*models.Hit, error) {
+	switch resourceType {
+	case common.ResourceGroup:
+		return d.search(ctx, dbcommon.SearchGroupsByName, pattern, pattern, pattern, limit)
+	case common.ResourceApplication:
+		return d.search(ctx, dbcommon.SearchApplicationsByName, pattern, pattern, limit)
+	case common.ResourceCluster:
+		return d.search(ctx, dbcommon.SearchClustersByName, pattern, pattern, limit)
+	}
+	return nil, nil
+}
+
+func (d *dao) SearchByTag(ctx context.Context, resourceType, pattern string, limit int) ([]*models.Hit, error) {
+	// groups are not tagged
+	switch resourceType {
+	case common.ResourceApplication:
+		return d.search(ctx, dbcommon.SearchApplicationsByTag, pattern, pattern, limit)
+	case common.ResourceCluster:
+		return d.search(ctx, dbcommon.SearchClustersByTag, pattern, pattern, limit)
+	}
+	return nil, nil
+}
+
+func (d *dao) SearchByOwner(ctx context.Context, resourceType, pattern string, limit int) ([]*models.Hit, error) {
+	switch resourceType {
+	case common.ResourceGroup:
+		return d.search(ctx, dbcommon.SearchGroupsByOwner, pattern, pattern, pattern, limit)
+	case common.ResourceApplication:
+		return d.search(ctx, dbcommon.SearchApplicationsByOwner, pattern, pattern, pattern, limit)
+	case common.ResourceCluster:
+		return d.search(ctx, dbcommon.SearchClustersByOwner, pattern, pattern, pattern, limit)
+	}
+	return nil, nil
+}
+
+func (d *dao) search(ctx context.Context, sql string, values ...interface{}) ([]*models.Hit, error) {
+	var hits []*models.Hit
+	if result := d.db.WithContext(ctx).Raw(sql, values...).Scan(&hits); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.SearchInDB, result.Error.Error())
+	}
+	return hits, nil
+}
@@ -0,0 +1,173 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/search/dao"
+	"github.com/horizoncd/horizon/pkg/search/models"
+	"gorm.io/gorm"
+)
+
+// _limit limits the resources listed by each query before ranking
+const _limit = 500
+
+// weights of the fields matching the keyword, names equal to or prefixed with the keyword weigh more
+const (
+	_weightNameEqual   = 100
+	_weightNamePrefix  = 60
+	_weightName        = 40
+	_weightPath        = 30
+	_weightTag         = 20
+	_weightOwner       = 20
+	_weightDescription = 10
+)
+
+type Manager interface {
+	// Search lists the resources of the types whose names, paths, descriptions, tags or owners
+	// contain the keyword, the most relevant first
+	Search(ctx context.Context, keyword string, resourceTypes []string) ([]*models.Hit, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Search(ctx context.Context, keyword string, resourceTypes []string) ([]*models.Hit, error) {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	pattern := fmt.Sprintf("%%%s%%", keyword)
+
+	hits := make([]*models.Hit, 0)
+	merged := make(map[string]*models.Hit)
+	add := func(hit *models.Hit, fields ...string) {
+		key := fmt.Sprintf("%s/%d", hit.ResourceType, hit.ResourceID)
+		if h, ok := merged[key]; ok {
+			if h.TagKey == "" {
+				h.TagKey, h.TagValue = hit.TagKey, hit.TagValue
+			}
+			if h.Owner == "" {
+				h.Owner = hit.Owner
+			}
+			hit = h
+		} else {
+			merged[key] = hit
+			hits = append(hits, hit)
+		}
+		for _, field := range fields {
+			if !contains(hit.MatchedFields, field) {
+				hit.MatchedFields = append(hit.MatchedFields, field)
+			}
+		}
+	}
+	for _, resourceType := range resourceTypes {
+		byName, err := m.dao.SearchByName(ctx, resourceType, pattern, _limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range byName {
+			add(hit, matchedFields(hit, keyword)...)
+		}
+		byTag, err := m.dao.SearchByTag(ctx, resourceType, pattern, _limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range byTag {
+			add(hit, models.FieldTag)
+		}
+		byOwner, err := m.dao.SearchByOwner(ctx, resourceType, pattern, _limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range byOwner {
+			add(hit, models.FieldOwner)
+		}
+	}
+
+	for _, hit := range hits {
+		hit.Score = score(hit, keyword)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		// the shorter name is closer to the keyword
+		if len(hits[i].Name) != len(hits[j].Name) {
+			return len(hits[i].Name) < len(hits[j].Name)
+		}
+		return hits[i].Name < hits[j].Name
+	})
+	return hits, nil
+}
+
+// matchedFields returns the fields of the resource listed by name containing the keyword
+func matchedFields(hit *models.Hit, keyword string) []string {
+	fields := make([]string, 0)
+	if strings.Contains(strings.ToLower(hit.Name), keyword) {
+		fields = append(fields, models.FieldName)
+	}
+	if hit.Path != "" && strings.Contains(strings.ToLower(hit.Path), keyword) {
+		fields = append(fields, models.FieldPath)
+	}
+	if strings.Contains(strings.ToLower(hit.Description), keyword) {
+		fields = append(fields, models.FieldDescription)
+	}
+	return fields
+}
+
+func score(hit *models.Hit, keyword string) int {
+	score := 0
+	for _, field := range hit.MatchedFields {
+		switch field {
+		case models.FieldName:
+			name := strings.ToLower(hit.Name)
+			switch {
+			case name == keyword:
+				score += _weightNameEqual
+			case strings.HasPrefix(name, keyword):
+				score += _weightNamePrefix
+			default:
+				score += _weightName
+			}
+		case models.FieldPath:
+			score += _weightPath
+		case models.FieldTag:
+			score += _weightTag
+		case models.FieldOwner:
+			score += _weightOwner
+		case models.FieldDescription:
+			score += _weightDescription
+		}
+	}
+	return score
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/search/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&tagmodels.Tag{}, &membermodels.Member{}, &usermodels.User{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	web := &groupmodels.Group{Name: "web", Path: "web", Description: "web team", TraversalIDs: "1"}
+	infra := &groupmodels.Group{Name: "infra", Path: "infra", VisibilityLevel: "private", TraversalIDs: "2"}
+	assert.Nil(t, db.Create(web).Error)
+	assert.Nil(t, db.Create(infra).Error)
+	webapp := &appmodels.Application{Name: "webapp", GroupID: web.ID}
+	api := &appmodels.Application{Name: "api", GroupID: infra.ID, Description: "serves the web"}
+	assert.Nil(t, db.Create(webapp).Error)
+	assert.Nil(t, db.Create(api).Error)
+	webappTest := &clustermodels.Cluster{Name: "webapp-test", ApplicationID: webapp.ID}
+	apiProd := &clustermodels.Cluster{Name: "api-prod", ApplicationID: api.ID}
+	assert.Nil(t, db.Create(webappTest).Error)
+	assert.Nil(t, db.Create(apiProd).Error)
+	assert.Nil(t, db.Create(&tagmodels.Tag{ResourceType: common.ResourceCluster, ResourceID: apiProd.ID,
+		Key: "team", Value: "web"}).Error)
+	user := &usermodels.User{Name: "webber", Email: "webber@horizon.com"}
+	assert.Nil(t, db.Create(user).Error)
+	assert.Nil(t, db.Create(&membermodels.Member{ResourceType: membermodels.TypeGroup, ResourceID: infra.ID,
+		MemberType: membermodels.MemberUser, MemberNameID: user.ID, Role: "owner"}).Error)
+
+	hits, err := mgr.Search(ctx, " WEB ", []string{common.ResourceGroup,
+		common.ResourceApplication, common.ResourceCluster})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(hits))
+
+	// the name equal to the keyword weighs the most
+	assert.Equal(t, web.ID, hits[0].ResourceID)
+	assert.Equal(t, []string{models.FieldName, models.FieldPath, models.FieldDescription}, hits[0].MatchedFields)
+	// names prefixed with the keyword, the shorter first
+	assert.Equal(t, webapp.Name, hits[1].Name)
+	assert.Equal(t, webappTest.Name, hits[2].Name)
+	assert.Equal(t, webapp.ID, hits[2].ApplicationID)
+	assert.Equal(t, webapp.Name, hits[2].ApplicationName)
+	assert.Equal(t, "1", hits[2].TraversalIDs)
+	// owners and tags
+	assert.Equal(t, infra.Name, hits[3].Name)
+	assert.Equal(t, []string{models.FieldOwner}, hits[3].MatchedFields)
+	assert.Equal(t, user.Name, hits[3].Owner)
+	assert.Equal(t, apiProd.Name, hits[4].Name)
+	assert.Equal(t, []string{models.FieldTag}, hits[4].MatchedFields)
+	assert.Equal(t, "team", hits[4].TagKey)
+	// descriptions weigh the least
+	assert.Equal(t, api.Name, hits[5].Name)
+	assert.Equal(t, "2", hits[5].TraversalIDs)
+
+	hits, err = mgr.Search(ctx, "api", []string{common.ResourceCluster})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, apiProd.ID, hits[0].ResourceID)
+	assert.Equal(t, common.ResourceCluster, hits[0].ResourceType)
+}
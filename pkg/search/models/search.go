@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// fields a resource matches the keyword on
+const (
+	FieldName        = "name"
+	FieldPath        = "path"
+	FieldDescription = "description"
+	FieldTag         = "tag"
+	FieldOwner       = "owner"
+)
+
+// Hit is a group, application or cluster matching the keyword
+type Hit struct {
+	ResourceType string
+	ResourceID   uint
+	Name         string
+	// Path is the path of a group
+	Path        string
+	Description string
+	// ApplicationID and ApplicationName are of the application a cluster belongs to
+	ApplicationID   uint
+	ApplicationName string
+	// TraversalIDs are of the group, or the group the application or cluster belongs to
+	TraversalIDs string
+	// TagKey and TagValue are of the tag matching the keyword
+	TagKey   string
+	TagValue string
+	// Owner is the name of the owner matching the keyword
+	Owner string
+
+	// MatchedFields are the fields matching the keyword, and Score is the relevance according to them
+	MatchedFields []string `gorm:"-"`
+	Score         int      `gorm:"-"`
+}
@@ -0,0 +1,164 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certreload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/server"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Reloader serves the certificate and the client CAs of the latest files,
+// the files are checked for changes periodically, so that rotated certificates take effect without a restart
+type Reloader struct {
+	config server.TLS
+
+	lock      sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// modTimes are the modification times of the files loaded
+	modTimes map[string]time.Time
+}
+
+// New loads the files of config, an error is returned if any of them is invalid
+func New(config server.TLS) (*Reloader, error) {
+	r := &Reloader{config: config}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	return files
+}
+
+func modTimes(files []string) (map[string]time.Time, error) {
+	times := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		times[file] = info.ModTime()
+	}
+	return times, nil
+}
+
+// changed tells whether any file is modified since it was loaded
+func (r *Reloader) changed() (bool, error) {
+	times, err := modTimes(r.files())
+	if err != nil {
+		return false, err
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for file, t := range times {
+		if !t.Equal(r.modTimes[file]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Reloader) reload() error {
+	// the times are taken before reading, so that a change during reading is picked up next time
+	times, err := modTimes(r.files())
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate %s and key %s: %v", r.config.CertFile, r.config.KeyFile, err)
+	}
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		content, err := ioutil.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file %s: %v", r.config.ClientCAFile, err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(content) {
+			return fmt.Errorf("no certificate found in client CA file %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = times
+	return nil
+}
+
+// Run checks the files for changes until ctx is done,
+// the files being rotated are kept serving until they are all valid again
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := r.changed()
+		if err != nil {
+			log.Warningf(ctx, "failed to check tls files for changes: %v", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err := r.reload(); err != nil {
+			log.Warningf(ctx, "failed to reload tls files, keep serving the previous ones: %v", err)
+			continue
+		}
+		log.Infof(ctx, "tls files are reloaded, certificate = %s", r.config.CertFile)
+	}
+}
+
+// TLSConfig returns the config for servers, the certificate and the client CAs are looked up on each handshake
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.lock.RLock()
+			defer r.lock.RUnlock()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.clientCAs != nil {
+				config.ClientCAs = r.clientCAs
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certreload
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/config/server"
+)
+
+// writeCert writes a self-signed certificate of the common name and its key
+func writeCert(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600))
+	assert.Nil(t, os.Chtimes(certFile, modTime, modTime))
+	assert.Nil(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func servedCommonName(t *testing.T, r *Reloader) string {
+	config, err := r.TLSConfig().GetConfigForClient(nil)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	assert.Nil(t, err)
+	return cert.Subject.CommonName
+}
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certreload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	now := time.Now()
+	writeCert(t, certFile, keyFile, "old", now.Add(-time.Minute))
+
+	r, err := New(server.TLS{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Second})
+	assert.Nil(t, err)
+	assert.Equal(t, "old", servedCommonName(t, r))
+	changed, err := r.changed()
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	// the rotated certificate is served once reloaded
+	writeCert(t, certFile, keyFile, "new", now)
+	changed, err = r.changed()
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Nil(t, r.reload())
+	assert.Equal(t, "new", servedCommonName(t, r))
+
+	// the previous certificate is kept serving if the files are invalid
+	assert.Nil(t, ioutil.WriteFile(keyFile, []byte("invalid"), 0600))
+	assert.NotNil(t, r.reload())
+	assert.Equal(t, "new", servedCommonName(t, r))
+
+	_, err = New(server.TLS{CertFile: certFile, KeyFile: filepath.Join(dir, "not-exist")})
+	assert.NotNil(t, err)
+}
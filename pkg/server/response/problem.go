@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	ContentTypeProblemJSON = "application/problem+json"
+	// ProblemTypePrefix prefixes the error code to make up the type of problem details
+	ProblemTypePrefix = "urn:horizon:error:"
+
+	_problemDetailsKey = "problemDetails"
+)
+
+// Problem is the problem details of RFC 7807,
+// errorCode, errorMessage and requestID are kept as extension members,
+// so that clients reading the errors of Response work with problem details as well
+type Problem struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	Status       int    `json:"status"`
+	Detail       string `json:"detail,omitempty"`
+	Instance     string `json:"instance,omitempty"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	RequestID    string `json:"requestID,omitempty"`
+	// Details is the same as the one of Response
+	Details interface{} `json:"details,omitempty"`
+}
+
+func NewProblem(c *gin.Context, httpCode int, errorCode, errorMessage string) *Problem {
+	problem := &Problem{
+		Type:         "about:blank",
+		Title:        http.StatusText(httpCode),
+		Status:       httpCode,
+		Detail:       errorMessage,
+		ErrorCode:    errorCode,
+		ErrorMessage: errorMessage,
+		RequestID:    requestID(c),
+	}
+	if errorCode != "" {
+		problem.Type = ProblemTypePrefix + errorCode
+	}
+	if c.Request != nil {
+		problem.Instance = c.Request.URL.Path
+	}
+	return problem
+}
+
+// EnableProblemDetails makes the errors of the request rendered as problem details instead of Response
+func EnableProblemDetails(c *gin.Context) {
+	c.Set(_problemDetailsKey, true)
+}
+
+func problemDetailsEnabled(c *gin.Context) bool {
+	return c.GetBool(_problemDetailsKey)
+}
+
+// AbortWithHorizonError maps the errors returned by controllers to the rpc errors,
+// the errors not known are logged and responded as internal errors
+func AbortWithHorizonError(c *gin.Context, op string, err error) {
+	cause := perror.Cause(err)
+	if _, ok := cause.(*herrors.HorizonErrNotFound); ok {
+		AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch cause {
+	case herrors.ErrParamInvalid:
+		AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()), ErrorDetails(err))
+	case herrors.ErrNoPrivilege, herrors.ErrForbidden:
+		AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+	case herrors.ErrNameConflict:
+		AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+	default:
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
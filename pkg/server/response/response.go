@@ -15,6 +15,7 @@
 package response
 
 import (
+	goerrors "errors"
 	"net/http"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -36,6 +37,8 @@ type Response struct {
 	ErrorMessage string      `json:"errorMessage,omitempty"`
 	Data         interface{} `json:"data,omitempty"`
 	RequestID    string      `json:"requestID,omitempty"`
+	// Details tells more about the error, such as the invalid fields of the request
+	Details interface{} `json:"details,omitempty"`
 }
 
 func NewResponse() *Response {
@@ -48,24 +51,46 @@ func NewResponseWithData(data interface{}) *Response {
 	}
 }
 
+// requestID returns the requestID attached by the requestID middleware, empty if not attached
+func requestID(c *gin.Context) string {
+	rid, _ := requestid.FromContext(c)
+	return rid
+}
+
 func Success(c *gin.Context) {
-	c.JSON(http.StatusOK, NewResponse())
+	c.JSON(http.StatusOK, &Response{RequestID: requestID(c)})
 }
 
 func SuccessWithData(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, NewResponseWithData(data))
+	c.JSON(http.StatusOK, &Response{Data: data, RequestID: requestID(c)})
 }
 
+// Abort responds the error with Response, or with Problem if problem details are enabled for the request
 func Abort(c *gin.Context, httpCode int, errorCode, errorMessage string) {
+	abort(c, httpCode, errorCode, errorMessage, nil)
+}
+
+func abort(c *gin.Context, httpCode int, errorCode, errorMessage string, details interface{}) {
 	rid, err := requestid.FromContext(c)
 	if err != nil {
 		log.Errorf(c, "error to get requestID from context, err: %v", err)
 	}
 
+	if problemDetailsEnabled(c) {
+		// the content type set ahead is kept by the json render
+		c.Header("Content-Type", ContentTypeProblemJSON)
+		problem := NewProblem(c, httpCode, errorCode, errorMessage)
+		problem.Details = details
+		c.JSON(httpCode, problem)
+		c.Abort()
+		return
+	}
+
 	c.JSON(httpCode, &Response{
 		ErrorCode:    errorCode,
 		ErrorMessage: errorMessage,
 		RequestID:    rid,
+		Details:      details,
 	})
 	c.Abort()
 }
@@ -94,6 +119,25 @@ func AbortWithRPCError(c *gin.Context, rpcError rpcerror.RPCError) {
 	Abort(c, rpcError.HTTPCode, string(rpcError.ErrorCode), rpcError.ErrorMessage)
 }
 
+// AbortWithRPCErrorDetails is the same as AbortWithRPCError, and tells the details of the error
+func AbortWithRPCErrorDetails(c *gin.Context, rpcError rpcerror.RPCError, details interface{}) {
+	abort(c, rpcError.HTTPCode, string(rpcError.ErrorCode), rpcError.ErrorMessage, details)
+}
+
+// detailer is implemented by the errors telling more about themselves, such as the invalid fields
+type detailer interface {
+	Details() interface{}
+}
+
+// ErrorDetails returns the details of the error, or nil if the error tells nothing more
+func ErrorDetails(err error) interface{} {
+	var d detailer
+	if goerrors.As(err, &d) {
+		return d.Details()
+	}
+	return nil
+}
+
 // AbortWithError TODO: remove this function after all error changed to rpcerror.RPCError
 func AbortWithError(c *gin.Context, err error) {
 	Abort(c, errors.Status(err), errors.Code(err), err.Error())
@@ -28,25 +28,47 @@ type Route struct {
 	Pattern string
 	// HandlerFunc is the handler function of this route.
 	HandlerFunc gin.HandlerFunc
+	// Transactional runs the handler in a request transaction if the request transaction is enabled,
+	// so that its writes are committed or rolled back together.
+	// Handlers calling gitlab, argocd or kubernetes, or waiting on other systems must not opt in,
+	// so that no transaction is held open across the calls.
+	Transactional bool
 }
 
 // Routes is the list of the generated Route.
 type Routes []Route
 
+// transactionMiddleware runs the handlers of the transactional routes in a request transaction,
+// it's nil if the request transaction is disabled
+var transactionMiddleware gin.HandlerFunc
+
+// UseTransactionMiddleware sets the middleware of the transactional routes,
+// it must be called before the routes are registered
+func UseTransactionMiddleware(middleware gin.HandlerFunc) {
+	transactionMiddleware = middleware
+}
+
+func (r Route) handlers() []gin.HandlerFunc {
+	if r.Transactional && transactionMiddleware != nil {
+		return []gin.HandlerFunc{transactionMiddleware, r.HandlerFunc}
+	}
+	return []gin.HandlerFunc{r.HandlerFunc}
+}
+
 // RegisterRoutes register every route to routerGroup
 func RegisterRoutes(api *gin.RouterGroup, routes Routes) {
 	for _, route := range routes {
 		switch route.Method {
 		case http.MethodGet:
-			api.GET(route.Pattern, route.HandlerFunc)
+			api.GET(route.Pattern, route.handlers()...)
 		case http.MethodPost:
-			api.POST(route.Pattern, route.HandlerFunc)
+			api.POST(route.Pattern, route.handlers()...)
 		case http.MethodPut:
-			api.PUT(route.Pattern, route.HandlerFunc)
+			api.PUT(route.Pattern, route.handlers()...)
 		case http.MethodPatch:
-			api.PATCH(route.Pattern, route.HandlerFunc)
+			api.PATCH(route.Pattern, route.handlers()...)
 		case http.MethodDelete:
-			api.DELETE(route.Pattern, route.HandlerFunc)
+			api.DELETE(route.Pattern, route.handlers()...)
 		}
 	}
 }
@@ -72,4 +72,8 @@ var (
 		HTTPCode:  http.StatusConflict,
 		ErrorCode: "Conflict",
 	}
+	LockedError = RPCError{
+		HTTPCode:  http.StatusLocked,
+		ErrorCode: "Locked",
+	}
 )
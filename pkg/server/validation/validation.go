@@ -0,0 +1,286 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validation validates the requests bound by gin with the binding tags,
+// and translates the errors into the language the client accepts.
+// Besides the validators of go-playground/validator, the ones of horizon are registered:
+//   - resourcename: names of applications and clusters, which are used as the names of kubernetes resources
+//   - grouppath: paths of groups, which are parts of the urls
+//   - giturl: urls of git repositories
+//   - templatevalues: values of templates, which are rendered into yaml
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/validate"
+)
+
+const (
+	LocaleEN = "en"
+	LocaleZH = "zh"
+
+	TagResourceName   = "resourcename"
+	TagGroupPath      = "grouppath"
+	TagGitURL         = "giturl"
+	TagTemplateValues = "templatevalues"
+
+	_headerAcceptLanguage = "Accept-Language"
+	// _maxTemplateValuesSize keeps the values of templates committed to gitops repos small
+	_maxTemplateValuesSize = 1 << 20
+)
+
+var (
+	_resourceName = regexp.MustCompile(`^(([a-z][-a-z0-9]*)?[a-z0-9])?$`)
+	_groupPath    = regexp.MustCompile(`^[a-zA-Z0-9][-a-zA-Z0-9_.]*$`)
+
+	// _messages are the messages of the validators of horizon in each locale
+	_messages = map[string]map[string]string{
+		LocaleEN: {
+			TagResourceName: "{0} must consist of lower case letters, digits and '-', " +
+				"start with a letter and end with a letter or digit",
+			TagGroupPath:      "{0} must consist of letters, digits, '_', '-' and '.', and start with a letter or digit",
+			TagGitURL:         "{0} must be a git url, such as https://github.com/horizoncd/horizon.git",
+			TagTemplateValues: "{0} must not contain empty keys, and must not exceed 1MiB",
+		},
+		LocaleZH: {
+			TagResourceName:   "{0}只能包含小写字母、数字和'-'，且必须以字母开头、以字母或数字结尾",
+			TagGroupPath:      "{0}只能包含字母、数字、'_'、'-'和'.'，且必须以字母或数字开头",
+			TagGitURL:         "{0}必须是git地址，例如https://github.com/horizoncd/horizon.git",
+			TagTemplateValues: "{0}不能包含空的键，且不能超过1MiB",
+		},
+	}
+
+	_uni = ut.New(en.New(), en.New(), zh.New())
+)
+
+// FieldError is an invalid field of the request
+type FieldError struct {
+	// Field is the path of the field in json, such as git.url
+	Field   string `json:"field"`
+	Tag     string `json:"tag,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error is the error of binding a request
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func init() {
+	if err := Register(binding.Validator.Engine().(*validator.Validate)); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the validators of horizon and the translations of them
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	validators := map[string]validator.Func{
+		TagResourceName: func(fl validator.FieldLevel) bool {
+			return _resourceName.MatchString(fl.Field().String())
+		},
+		TagGroupPath: func(fl validator.FieldLevel) bool {
+			return _groupPath.MatchString(fl.Field().String())
+		},
+		TagGitURL: func(fl validator.FieldLevel) bool {
+			return validate.CheckGitURL(fl.Field().String()) == nil
+		},
+		TagTemplateValues: func(fl validator.FieldLevel) bool {
+			if !fl.Field().CanInterface() {
+				return false
+			}
+			values := fl.Field().Interface()
+			if hasEmptyKey(values) {
+				return false
+			}
+			data, err := json.Marshal(values)
+			return err == nil && len(data) <= _maxTemplateValuesSize
+		},
+	}
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+
+	for locale, messages := range _messages {
+		trans, _ := _uni.GetTranslator(locale)
+		var err error
+		switch locale {
+		case LocaleZH:
+			err = zhtranslations.RegisterDefaultTranslations(v, trans)
+		default:
+			err = entranslations.RegisterDefaultTranslations(v, trans)
+		}
+		if err != nil {
+			return err
+		}
+		for tag, message := range messages {
+			tag, message := tag, message
+			if err := v.RegisterTranslation(tag, trans,
+				func(trans ut.Translator) error {
+					return trans.Add(tag, message, true)
+				},
+				func(trans ut.Translator, fe validator.FieldError) string {
+					t, err := trans.T(tag, fieldPath(fe))
+					if err != nil {
+						return fe.Error()
+					}
+					return t
+				}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasEmptyKey tells whether the values decoded from json have empty keys
+func hasEmptyKey(values interface{}) bool {
+	switch values := values.(type) {
+	case map[string]interface{}:
+		for key, value := range values {
+			if key == "" || hasEmptyKey(value) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, value := range values {
+			if hasEmptyKey(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldPath returns the path of the field in json, the name of the struct bound is trimmed
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// Translator returns the translator of the language accepted by the request, English by default
+func Translator(c *gin.Context) ut.Translator {
+	var locales []string
+	for _, language := range strings.Split(c.GetHeader(_headerAcceptLanguage), ",") {
+		// such as zh-CN;q=0.9
+		language = strings.TrimSpace(strings.SplitN(language, ";", 2)[0])
+		language = strings.ToLower(strings.SplitN(language, "-", 2)[0])
+		if language != "" {
+			locales = append(locales, language)
+		}
+	}
+	trans, _ := _uni.FindTranslator(locales...)
+	return trans
+}
+
+// Bind decodes the json body of the request into obj and validates it,
+// obj may be a pointer to a pointer of the struct, which is allocated if the body is not empty
+func Bind(c *gin.Context, obj interface{}) error {
+	trans := Translator(c)
+	if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return &Error{Fields: []FieldError{{Message: "request body is empty"}}}
+		case errors.As(err, &typeErr):
+			return &Error{Fields: []FieldError{{
+				Field:   typeErr.Field,
+				Message: fmt.Sprintf("%s should be %s, but got %s", typeErr.Field, typeErr.Type, typeErr.Value),
+			}}}
+		default:
+			return &Error{Fields: []FieldError{{Message: fmt.Sprintf("request body is invalid json: %v", err)}}}
+		}
+	}
+
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.IsNil() {
+		return &Error{Fields: []FieldError{{Message: "request body is empty"}}}
+	}
+	err := binding.Validator.ValidateStruct(value.Interface())
+	if err == nil {
+		return nil
+	}
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return &Error{Fields: []FieldError{{Message: err.Error()}}}
+	}
+	bindErr := &Error{}
+	for _, fe := range validationErrs {
+		bindErr.Fields = append(bindErr.Fields, FieldError{
+			Field:   fieldPath(fe),
+			Tag:     fe.Tag(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return bindErr
+}
+
+// BindJSON binds the request by Bind, and aborts with the invalid fields if failed
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	err := Bind(c, obj)
+	if err == nil {
+		return true
+	}
+	var details interface{}
+	var bindErr *Error
+	if errors.As(err, &bindErr) {
+		details = bindErr.Fields
+	}
+	response.AbortWithRPCErrorDetails(c, rpcerror.ParamError.WithErrMsg(err.Error()), details)
+	return false
+}
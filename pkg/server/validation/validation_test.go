@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+)
+
+type git struct {
+	URL string `json:"url" binding:"omitempty,giturl"`
+}
+
+type request struct {
+	Name           string                 `json:"name" binding:"required,max=40,resourcename"`
+	Path           string                 `json:"path" binding:"omitempty,grouppath"`
+	Replicas       int                    `json:"replicas"`
+	Git            *git                   `json:"git"`
+	TemplateConfig map[string]interface{} `json:"templateConfig" binding:"omitempty,templatevalues"`
+}
+
+func newContext(body, language string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/apis/core/v2/groups", strings.NewReader(body))
+	if language != "" {
+		c.Request.Header.Set(_headerAcceptLanguage, language)
+	}
+	return c, w
+}
+
+func bind(body, language string) (*request, error) {
+	var req *request
+	c, _ := newContext(body, language)
+	err := Bind(c, &req)
+	return req, err
+}
+
+func TestBind(t *testing.T) {
+	req, err := bind(`{"name": "app", "path": "horizon.io", "git": {"url": "https://github.com/horizoncd/horizon.git"},
+		"templateConfig": {"app": {"envs": [{"name": "a"}]}}}`, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "app", req.Name)
+
+	fields := func(err error) []FieldError {
+		bindErr, ok := err.(*Error)
+		assert.True(t, ok)
+		return bindErr.Fields
+	}
+
+	_, err = bind(`{"name": "App", "path": "-horizon", "git": {"url": "github.com/horizoncd/horizon"},
+		"templateConfig": {"app": [{"": "a"}]}}`, "")
+	assert.Equal(t, []FieldError{
+		{Field: "name", Tag: TagResourceName, Message: "name must consist of lower case letters, digits and '-', " +
+			"start with a letter and end with a letter or digit"},
+		{Field: "path", Tag: TagGroupPath, Message: "path must consist of letters, digits, '_', '-' and '.', " +
+			"and start with a letter or digit"},
+		{Field: "git.url", Tag: TagGitURL, Message: "git.url must be a git url, " +
+			"such as https://github.com/horizoncd/horizon.git"},
+		{Field: "templateConfig", Tag: TagTemplateValues,
+			Message: "templateConfig must not contain empty keys, and must not exceed 1MiB"},
+	}, fields(err))
+
+	// messages are translated into the language accepted
+	_, err = bind(`{"path": "horizon"}`, "zh-CN,zh;q=0.9,en;q=0.8")
+	assert.Equal(t, []FieldError{{Field: "name", Tag: "required", Message: "name为必填字段"}}, fields(err))
+	_, err = bind(`{"path": "horizon"}`, "fr")
+	assert.Equal(t, []FieldError{{Field: "name", Tag: "required", Message: "name is a required field"}}, fields(err))
+
+	_, err = bind(`{"name": "app", "replicas": "1"}`, "")
+	assert.Equal(t, []FieldError{{Field: "replicas", Message: "replicas should be int, but got string"}}, fields(err))
+	_, err = bind(`null`, "")
+	assert.Equal(t, []FieldError{{Message: "request body is empty"}}, fields(err))
+	_, err = bind(``, "")
+	assert.Equal(t, []FieldError{{Message: "request body is empty"}}, fields(err))
+}
+
+func TestBindJSON(t *testing.T) {
+	c, w := newContext(`{"name": "App", "path": "horizon"}`, "")
+	var req *request
+	assert.False(t, BindJSON(c, &req))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	resp := struct {
+		ErrorCode    string       `json:"errorCode"`
+		ErrorMessage string       `json:"errorMessage"`
+		Details      []FieldError `json:"details"`
+	}{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, string(rpcerror.ParamError.ErrorCode), resp.ErrorCode)
+	assert.Equal(t, "name must consist of lower case letters, digits and '-', "+
+		"start with a letter and end with a letter or digit", resp.ErrorMessage)
+	assert.Equal(t, []FieldError{{Field: "name", Tag: TagResourceName, Message: resp.ErrorMessage}}, resp.Details)
+
+	c, _ = newContext(`{"name": "app"}`, "")
+	assert.True(t, BindJSON(c, &req))
+	assert.Equal(t, "app", req.Name)
+}
@@ -34,6 +34,9 @@ type DAO interface {
 		deduplicate bool) ([]*models.Tag, error)
 	// UpsertByResourceTypeID upsert tags
 	UpsertByResourceTypeID(ctx context.Context, resourceType string, resourceID uint, tags []*models.Tag) error
+	// ListResourceIDsByTagSelectors lists ids of the resources of the type matching all the tag selectors
+	ListResourceIDsByTagSelectors(ctx context.Context, resourceType string,
+		tagSelectors []models.TagSelector) ([]uint, error)
 	CreateMetatags(ctx context.Context, metatags []*models.Metatag) error
 	GetMetatagKeys(ctx context.Context) ([]string, error)
 	GetMetatagsByKey(ctx context.Context, key string) ([]*models.Metatag, error)
@@ -122,6 +125,49 @@ func (d dao) UpsertByResourceTypeID(ctx context.Context, resourceType string,
 	return nil
 }
 
+func (d dao) ListResourceIDsByTagSelectors(ctx context.Context, resourceType string,
+	tagSelectors []models.TagSelector) ([]uint, error) {
+	var resourceIDs []uint
+	result := GenSQLForTagSelector(resourceType, tagSelectors, d.db.WithContext(ctx)).Scan(&resourceIDs)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.TagInDB, result.Error.Error())
+	}
+	return resourceIDs, nil
+}
+
+// GenSQLForTagSelector generates the sub query of ids of the resources of the type matching all the tag selectors
+func GenSQLForTagSelector(resourceType string, tagSelectors []models.TagSelector, statement *gorm.DB) *gorm.DB {
+	condition := statement.WithContext(context.Background())
+	statement = statement.Table("tb_tag as tg").
+		Select("tg.resource_id").
+		Where("tg.resource_type = ?", resourceType).
+		Group("tg.resource_id").
+		Having("count(tg.id) > ?", len(tagSelectors)-1)
+
+	for i, tag := range tagSelectors {
+		switch tag.Operator {
+		case models.Equals:
+			// avoid PopAny here, the selectors may be used to build more than one statement
+			if values := tag.Values.List(); len(values) > 0 {
+				value := values[0]
+				if i == 0 {
+					statement.Where(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value = ?", value))
+				} else {
+					statement.Or(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value = ?", value))
+				}
+			}
+		case models.In:
+			if i == 0 {
+				statement.Where(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value in ?", tag.Values.List()))
+			} else {
+				statement.Or(condition.Where("tg.tag_key = ?", tag.Key).Where("tg.tag_value in ?", tag.Values.List()))
+			}
+		}
+	}
+
+	return statement
+}
+
 func (d dao) CreateMetatags(ctx context.Context, metatags []*models.Metatag) error {
 	result := d.db.WithContext(ctx).Create(&metatags)
 	if result.Error != nil {
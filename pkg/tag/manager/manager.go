@@ -35,6 +35,9 @@ type Manager interface {
 		deduplicate bool) ([]*models.Tag, error)
 	// UpsertByResourceTypeID upsert tags
 	UpsertByResourceTypeID(ctx context.Context, resourceType string, resourceID uint, tags []*models.TagBasic) error
+	// ListResourceIDsByTagSelectors lists ids of the resources of the type matching all the tag selectors
+	ListResourceIDsByTagSelectors(ctx context.Context, resourceType string,
+		tagSelectors []models.TagSelector) ([]uint, error)
 	CreateMetatags(ctx context.Context, metatags []*models.Metatag) error
 	GetMetatagKeys(ctx context.Context) ([]string, error)
 	GetMetatagsByKey(ctx context.Context, key string) ([]*models.Metatag, error)
@@ -74,6 +77,11 @@ func (m *manager) UpsertByResourceTypeID(ctx context.Context,
 	return m.dao.UpsertByResourceTypeID(ctx, resourceType, resourceID, tags)
 }
 
+func (m *manager) ListResourceIDsByTagSelectors(ctx context.Context, resourceType string,
+	tagSelectors []models.TagSelector) ([]uint, error) {
+	return m.dao.ListResourceIDsByTagSelectors(ctx, resourceType, tagSelectors)
+}
+
 // ValidateUpsert tags upsert
 func ValidateUpsert(tags []*models.Tag) error {
 	if len(tags) > 20 {
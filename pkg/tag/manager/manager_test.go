@@ -23,6 +23,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/util/sets"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -129,6 +130,40 @@ func Test(t *testing.T) {
 	assert.NotNil(t, 0, len(tags))
 }
 
+func TestListResourceIDsByTagSelectors(t *testing.T) {
+	tagsOfGroups := map[uint][]*models.TagBasic{
+		101: {{Key: "tier", Value: "core"}, {Key: "costCenter", Value: "infra"}},
+		102: {{Key: "tier", Value: "edge"}, {Key: "costCenter", Value: "infra"}},
+		103: {{Key: "tier", Value: "core"}},
+	}
+	for groupID, tags := range tagsOfGroups {
+		err := mgr.UpsertByResourceTypeID(ctx, common.ResourceGroup, groupID, tags)
+		assert.Nil(t, err)
+	}
+	err := mgr.UpsertByResourceTypeID(ctx, common.ResourceApplication, 101,
+		[]*models.TagBasic{{Key: "tier", Value: "edge"}})
+	assert.Nil(t, err)
+
+	ids, err := mgr.ListResourceIDsByTagSelectors(ctx, common.ResourceGroup, []models.TagSelector{
+		{Key: "tier", Operator: models.Equals, Values: sets.NewString("core")},
+	})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []uint{101, 103}, ids)
+
+	ids, err = mgr.ListResourceIDsByTagSelectors(ctx, common.ResourceGroup, []models.TagSelector{
+		{Key: "tier", Operator: models.In, Values: sets.NewString("core", "edge")},
+		{Key: "costCenter", Operator: models.Equals, Values: sets.NewString("infra")},
+	})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []uint{101, 102}, ids)
+
+	ids, err = mgr.ListResourceIDsByTagSelectors(ctx, common.ResourceApplication, []models.TagSelector{
+		{Key: "tier", Operator: models.Equals, Values: sets.NewString("core")},
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, ids)
+}
+
 func Test_ValidateUpsert(t *testing.T) {
 	tags := make([]*models.Tag, 0)
 	tags = append(tags, &models.Tag{
@@ -176,6 +176,9 @@ func (d dao) UpdateByID(ctx context.Context, templateID uint, template *models.T
 		if template.Repository != "" {
 			oldTemplate.Repository = template.Repository
 		}
+		if template.SourceType != "" {
+			oldTemplate.SourceType = template.SourceType
+		}
 		if template.Description != "" {
 			oldTemplate.Description = template.Description
 		}
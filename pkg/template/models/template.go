@@ -28,6 +28,7 @@ type Template struct {
 	ChartName   string
 	Description string
 	Repository  string
+	SourceType  string
 	GroupID     uint
 	OnlyOwner   *bool
 	WithoutCI   bool
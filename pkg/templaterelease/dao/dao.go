@@ -17,6 +17,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -38,6 +39,10 @@ type DAO interface {
 	GetRefOfApplication(ctx context.Context, id uint) ([]*amodels.Application, uint, error)
 	GetRefOfCluster(ctx context.Context, id uint) ([]*cmodel.Cluster, uint, error)
 	UpdateByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	UpdateCanaryByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	UpdateChannelByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	CountDeploysByStatus(ctx context.Context, id uint, actions []string,
+		since time.Time) ([]*models.DeployStatusCount, error)
 	DeleteByID(ctx context.Context, id uint) error
 }
 
@@ -156,6 +161,44 @@ func (d dao) UpdateByID(ctx context.Context, releaseID uint, release *models.Tem
 	})
 }
 
+// UpdateCanaryByID updates canary fields of the release, including zero values
+func (d dao) UpdateCanaryByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error {
+	result := d.db.WithContext(ctx).Model(&models.TemplateRelease{}).Where("id = ?", releaseID).
+		Select("canary", "canary_percentage", "canary_clusters", "canary_at").Updates(release)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TemplateReleaseInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.TemplateReleaseInDB,
+			fmt.Sprintf("template release %d not found", releaseID))
+	}
+	return nil
+}
+
+// UpdateChannelByID updates the channel and the deprecation message of the release, including zero values
+func (d dao) UpdateChannelByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error {
+	result := d.db.WithContext(ctx).Model(&models.TemplateRelease{}).Where("id = ?", releaseID).
+		Select("channel", "deprecation_message").Updates(release)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TemplateReleaseInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.TemplateReleaseInDB,
+			fmt.Sprintf("template release %d not found", releaseID))
+	}
+	return nil
+}
+
+func (d dao) CountDeploysByStatus(ctx context.Context, id uint, actions []string,
+	since time.Time) ([]*models.DeployStatusCount, error) {
+	var counts []*models.DeployStatusCount
+	result := d.db.WithContext(ctx).Raw(common.TemplateReleaseCountDeploysByStatus, id, actions, since).Scan(&counts)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TemplateReleaseInDB, result.Error.Error())
+	}
+	return counts, nil
+}
+
 func (d dao) DeleteByID(ctx context.Context, id uint) error {
 	if res := d.db.Exec(common.TemplateReleaseDelete, id); res.Error != nil {
 		return perror.Wrap(herrors.NewErrDeleteFailed(herrors.TemplateInDB, res.Error.Error()),
@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	amodels "github.com/horizoncd/horizon/pkg/application/models"
 	cmodel "github.com/horizoncd/horizon/pkg/cluster/models"
@@ -42,6 +43,13 @@ type Manager interface {
 	GetRefOfApplication(ctx context.Context, id uint) ([]*amodels.Application, uint, error)
 	GetRefOfCluster(ctx context.Context, id uint) ([]*cmodel.Cluster, uint, error)
 	UpdateByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	// UpdateCanaryByID updates canary fields of the release, zero values are updated as well
+	UpdateCanaryByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	// UpdateChannelByID updates the channel and the deprecation message of the release
+	UpdateChannelByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	// CountDeploysByStatus counts deploys with the actions since the time of clusters using the release
+	CountDeploysByStatus(ctx context.Context, id uint, actions []string,
+		since time.Time) ([]*models.DeployStatusCount, error)
 	DeleteByID(ctx context.Context, id uint) error
 }
 
@@ -93,6 +101,19 @@ func (m *manager) UpdateByID(ctx context.Context, releaseID uint, release *model
 	return m.dao.UpdateByID(ctx, releaseID, release)
 }
 
+func (m *manager) UpdateCanaryByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error {
+	return m.dao.UpdateCanaryByID(ctx, releaseID, release)
+}
+
+func (m *manager) UpdateChannelByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error {
+	return m.dao.UpdateChannelByID(ctx, releaseID, release)
+}
+
+func (m *manager) CountDeploysByStatus(ctx context.Context, id uint, actions []string,
+	since time.Time) ([]*models.DeployStatusCount, error) {
+	return m.dao.CountDeploysByStatus(ctx, id, actions, since)
+}
+
 func (m *manager) DeleteByID(ctx context.Context, id uint) error {
 	return m.dao.DeleteByID(ctx, id)
 }
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -26,8 +27,10 @@ import (
 	amanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
@@ -139,11 +142,68 @@ func Test(t *testing.T) {
 	assert.Nil(t, templateRelease)
 }
 
+func TestCanary(t *testing.T) {
+	canary := true
+	templateRelease, err := templateReleaseMgr.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName:     "canaryapp",
+		Name:             "v2.0.0",
+		Canary:           &canary,
+		CanaryPercentage: 10,
+		CanaryClusters:   "cluster-1",
+	})
+	assert.Nil(t, err)
+
+	cluster := &clustermodels.Cluster{
+		Name:            "cluster-1",
+		Template:        "canaryapp",
+		TemplateRelease: "v2.0.0",
+	}
+	assert.Nil(t, db.Create(cluster).Error)
+	since := time.Now().Add(-time.Hour)
+	for _, pr := range []*prmodels.Pipelinerun{
+		{ClusterID: cluster.ID, Action: prmodels.ActionDeploy, Status: string(prmodels.StatusOK)},
+		{ClusterID: cluster.ID, Action: prmodels.ActionBuildDeploy, Status: string(prmodels.StatusFailed)},
+		{ClusterID: cluster.ID, Action: prmodels.ActionDeploy, Status: string(prmodels.StatusFailed)},
+		{ClusterID: cluster.ID, Action: prmodels.ActionRestart, Status: string(prmodels.StatusOK)},
+	} {
+		pr.CreatedAt = time.Now()
+		assert.Nil(t, db.Create(pr).Error)
+	}
+
+	counts, err := templateReleaseMgr.CountDeploysByStatus(ctx, templateRelease.ID,
+		[]string{prmodels.ActionDeploy, prmodels.ActionBuildDeploy}, since)
+	assert.Nil(t, err)
+	result := map[string]uint{}
+	for _, count := range counts {
+		result[count.Status] = count.Count
+	}
+	assert.Equal(t, map[string]uint{
+		string(prmodels.StatusOK):     1,
+		string(prmodels.StatusFailed): 2,
+	}, result)
+
+	// promote to stable, zero values should be updated
+	stable := false
+	err = templateReleaseMgr.UpdateCanaryByID(ctx, templateRelease.ID, &trmodels.TemplateRelease{
+		Canary: &stable,
+	})
+	assert.Nil(t, err)
+	templateRelease, err = templateReleaseMgr.GetByID(ctx, templateRelease.ID)
+	assert.Nil(t, err)
+	assert.False(t, templateRelease.IsCanary())
+	assert.Equal(t, uint(0), templateRelease.CanaryPercentage)
+	assert.Equal(t, "", templateRelease.CanaryClusters)
+
+	err = templateReleaseMgr.UpdateCanaryByID(ctx, 1000, &trmodels.TemplateRelease{Canary: &stable})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
 func TestMain(m *testing.M) {
 	db, _ = orm.NewSqliteDB("")
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{},
 		&applicationmodel.Application{}, &tmodels.Template{},
-		&membermodels.Member{}); err != nil {
+		&membermodels.Member{}, &clustermodels.Cluster{}, &prmodels.Pipelinerun{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -16,6 +16,9 @@ package models
 
 import (
 	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"strings"
 	"time"
 
 	"github.com/horizoncd/horizon/pkg/server/global"
@@ -41,6 +44,87 @@ type TemplateRelease struct {
 	CommitID     string
 	CreatedBy    uint
 	UpdatedBy    uint
+
+	// Canary release is only available to the opted-in clusters
+	// and a percentage of clusters until it's promoted to stable
+	Canary           *bool
+	CanaryPercentage uint
+	// CanaryClusters are names of the opted-in clusters joined by comma
+	CanaryClusters string
+	// CanaryAt is the time when the release became canary
+	CanaryAt *time.Time
+
+	// Channel is one of stable, beta and deprecated, new clusters cannot use
+	// deprecated releases unless they are permitted to
+	Channel string
+	// DeprecationMessage tells users why the release is deprecated and which release to use instead
+	DeprecationMessage string
+}
+
+const (
+	ChannelStable     = "stable"
+	ChannelBeta       = "beta"
+	ChannelDeprecated = "deprecated"
+)
+
+// ChannelOrDefault returns the channel of the release, releases without a channel are stable
+func (t *TemplateRelease) ChannelOrDefault() string {
+	if t.Channel == "" {
+		return ChannelStable
+	}
+	return t.Channel
+}
+
+// IsDeprecated returns true if the release is in the deprecated channel
+func (t *TemplateRelease) IsDeprecated() bool {
+	return t.Channel == ChannelDeprecated
+}
+
+// IsCanary returns true if the release has not been promoted to stable
+func (t *TemplateRelease) IsCanary() bool {
+	return t.Canary != nil && *t.Canary
+}
+
+// AvailableTo checks whether the cluster is allowed to use the release,
+// a stable release is available to all clusters
+func (t *TemplateRelease) AvailableTo(cluster string) bool {
+	if !t.IsCanary() {
+		return true
+	}
+	for _, c := range ParseCanaryClusters(t.CanaryClusters) {
+		if c == cluster {
+			return true
+		}
+	}
+	return canaryBucket(t.ID, cluster) < t.CanaryPercentage
+}
+
+// canaryBucket maps the cluster to a stable bucket in [0, 100),
+// the release id is hashed as well, so that different releases pick different clusters
+func canaryBucket(releaseID uint, cluster string) uint {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(fmt.Sprintf("%d/%s", releaseID, cluster)))
+	return uint(h.Sum32() % 100)
+}
+
+func ParseCanaryClusters(clusters string) []string {
+	result := make([]string, 0)
+	for _, c := range strings.Split(clusters, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func JoinCanaryClusters(clusters []string) string {
+	return strings.Join(clusters, ",")
+}
+
+// DeployStatusCount is the number of deploys in the status of clusters using a release
+type DeployStatusCount struct {
+	Status string
+	Count  uint
 }
 
 type SyncStatus uint8
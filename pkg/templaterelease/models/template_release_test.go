@@ -0,0 +1,59 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailableTo(t *testing.T) {
+	canary, stable := true, false
+
+	release := &TemplateRelease{}
+	assert.True(t, release.AvailableTo("cluster-1"))
+	release.Canary = &stable
+	assert.True(t, release.AvailableTo("cluster-1"))
+
+	release.Canary = &canary
+	release.CanaryClusters = "cluster-1, cluster-2"
+	assert.True(t, release.AvailableTo("cluster-1"))
+	assert.True(t, release.AvailableTo("cluster-2"))
+	assert.False(t, release.AvailableTo("cluster-3"))
+
+	// percentage of clusters should be roughly honored
+	release.CanaryClusters = ""
+	release.CanaryPercentage = 30
+	available := 0
+	for i := 0; i < 1000; i++ {
+		if release.AvailableTo(fmt.Sprintf("cluster-%d", i)) {
+			available++
+		}
+	}
+	assert.InDelta(t, 300, available, 60)
+
+	release.CanaryPercentage = 100
+	assert.True(t, release.AvailableTo("cluster-3"))
+	release.CanaryPercentage = 0
+	assert.False(t, release.AvailableTo("cluster-3"))
+}
+
+func TestCanaryClusters(t *testing.T) {
+	assert.Equal(t, []string{}, ParseCanaryClusters(""))
+	assert.Equal(t, []string{"a", "b"}, ParseCanaryClusters("a, ,b,"))
+	assert.Equal(t, "a,b", JoinCanaryClusters([]string{"a", "b"}))
+}
@@ -0,0 +1,277 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"helm.sh/helm/v3/pkg/chart"
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+)
+
+const (
+	_notesFile   = "NOTES.txt"
+	_partialMark = "_"
+	_noValue     = "<no value>"
+
+	// _kubeVersion is the version of kubernetes told to templates, as there is no cluster to ask when rendering
+	_kubeVersion = "v1.20.0"
+)
+
+// _apiVersions are the api versions templates are told to be available
+var _apiVersions = []string{
+	"v1", "apps/v1", "batch/v1", "batch/v1beta1", "autoscaling/v1", "autoscaling/v2beta2",
+	"networking.k8s.io/v1", "policy/v1", "policy/v1beta1", "rbac.authorization.k8s.io/v1",
+}
+
+// Release is the release told to templates
+type Release struct {
+	Name      string
+	Namespace string
+	Revision  int
+	IsInstall bool
+	IsUpgrade bool
+	Service   string
+}
+
+// Capabilities is the capabilities of the kubernetes cluster told to templates
+type Capabilities struct {
+	KubeVersion KubeVersion
+	APIVersions APIVersions
+}
+
+type KubeVersion struct {
+	Version string
+	Major   string
+	Minor   string
+}
+
+type APIVersions []string
+
+// Has returns true if the api version, such as apps/v1 or apps/v1/Deployment, is available
+func (a APIVersions) Has(apiVersion string) bool {
+	groupVersion := apiVersion
+	if i := strings.LastIndex(apiVersion, "/"); i > 0 && !a.has(apiVersion) {
+		groupVersion = apiVersion[:i]
+	}
+	return a.has(groupVersion)
+}
+
+func (a APIVersions) has(groupVersion string) bool {
+	for _, v := range a {
+		if v == groupVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// Files are the files of the chart except templates
+type Files map[string][]byte
+
+func (f Files) Get(name string) string {
+	return string(f[name])
+}
+
+func (f Files) GetBytes(name string) []byte {
+	return f[name]
+}
+
+// renderable is a template to render with the values of the chart it belongs to
+type renderable struct {
+	name   string
+	data   []byte
+	values map[string]interface{}
+	chart  *chart.Chart
+	base   string
+}
+
+// renderChart renders the templates of the chart and its dependencies like `helm template`,
+// the values are merged into the default values of the chart, and the values keyed by the name
+// of a dependency are merged into the ones of the dependency. The manifests are keyed by template path,
+// such as javaapp/templates/deployment.yaml, partials, notes and the empty ones are omitted.
+func renderChart(chrt *chart.Chart, values map[string]interface{}, release *Release) (map[string]string, error) {
+	renderables, err := collect(chrt, chrt.Name(), values)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(renderables, func(i, j int) bool {
+		return renderables[i].name < renderables[j].name
+	})
+
+	t := template.New("gotpl")
+	// missing values are rendered as empty as helm does
+	t.Option("missingkey=zero")
+	t.Funcs(funcMap(t))
+	for _, r := range renderables {
+		if _, err := t.New(r.name).Parse(string(r.data)); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to parse template %s: %v", r.name, err)
+		}
+	}
+
+	capabilities := &Capabilities{
+		KubeVersion: KubeVersion{Version: _kubeVersion, Major: "1", Minor: "20"},
+		APIVersions: _apiVersions,
+	}
+	manifests := make(map[string]string)
+	for _, r := range renderables {
+		base := path.Base(r.name)
+		if strings.HasPrefix(base, _partialMark) || base == _notesFile {
+			continue
+		}
+		files := Files{}
+		for _, f := range r.chart.Files {
+			files[f.Name] = f.Data
+		}
+		var buf strings.Builder
+		if err := t.ExecuteTemplate(&buf, r.name, map[string]interface{}{
+			"Values":       r.values,
+			"Release":      release,
+			"Chart":        r.chart.Metadata,
+			"Capabilities": capabilities,
+			"Files":        files,
+			"Template": map[string]interface{}{
+				"Name":     r.name,
+				"BasePath": r.base,
+			},
+		}); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to render template %s: %v", r.name, err)
+		}
+		manifest := strings.ReplaceAll(buf.String(), _noValue, "")
+		if strings.TrimSpace(manifest) == "" {
+			continue
+		}
+		manifests[r.name] = manifest
+	}
+	return manifests, nil
+}
+
+// collect collects the templates of the chart and its dependencies with the values to render them
+func collect(chrt *chart.Chart, prefix string, values map[string]interface{}) ([]*renderable, error) {
+	chartValues := mergemap.Copy(chrt.Values)
+	if chartValues == nil {
+		chartValues = map[string]interface{}{}
+	}
+	chartValues, err := mergemap.Merge(chartValues, mergemap.Copy(values))
+	if err != nil {
+		return nil, err
+	}
+
+	renderables := make([]*renderable, 0, len(chrt.Templates))
+	for _, tpl := range chrt.Templates {
+		renderables = append(renderables, &renderable{
+			name:   path.Join(prefix, tpl.Name),
+			data:   tpl.Data,
+			values: chartValues,
+			chart:  chrt,
+			base:   path.Join(prefix, "templates"),
+		})
+	}
+	for _, dep := range chrt.Dependencies() {
+		depValues, _ := chartValues[dep.Name()].(map[string]interface{})
+		depRenderables, err := collect(dep, path.Join(prefix, "charts", dep.Name()), depValues)
+		if err != nil {
+			return nil, err
+		}
+		renderables = append(renderables, depRenderables...)
+	}
+	return renderables, nil
+}
+
+// funcMap returns the functions of helm templates,
+// lookup always finds nothing as there is no cluster to ask when rendering
+func funcMap(t *template.Template) template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	// the environment of horizon should not be leaked into the manifests
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+
+	extra := template.FuncMap{
+		"toYaml": func(v interface{}) string {
+			data, err := yaml.Marshal(v)
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSuffix(string(data), "\n")
+		},
+		"fromYaml": func(str string) map[string]interface{} {
+			m := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(str), &m); err != nil {
+				m["Error"] = err.Error()
+			}
+			return m
+		},
+		"toJson": func(v interface{}) string {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return ""
+			}
+			return string(data)
+		},
+		"fromJson": func(str string) map[string]interface{} {
+			m := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(str), &m); err != nil {
+				m["Error"] = err.Error()
+			}
+			return m
+		},
+		"include": func(name string, data interface{}) (string, error) {
+			var buf strings.Builder
+			if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		},
+		"tpl": func(text string, data interface{}) (string, error) {
+			clone, err := t.Clone()
+			if err != nil {
+				return "", err
+			}
+			if _, err := clone.New("tpl").Parse(text); err != nil {
+				return "", err
+			}
+			var buf strings.Builder
+			if err := clone.ExecuteTemplate(&buf, "tpl", data); err != nil {
+				return "", err
+			}
+			return strings.ReplaceAll(buf.String(), _noValue, ""), nil
+		},
+		"required": func(message string, v interface{}) (interface{}, error) {
+			if v == nil {
+				return nil, perror.New(message)
+			}
+			if s, ok := v.(string); ok && s == "" {
+				return nil, perror.New(message)
+			}
+			return v, nil
+		},
+		"lookup": func(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		},
+	}
+	for name, f := range extra {
+		funcs[name] = f
+	}
+	return funcs
+}
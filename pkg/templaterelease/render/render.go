@@ -0,0 +1,192 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+	"github.com/horizoncd/horizon/pkg/util/valuediff"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+var _documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// Input is the config of an application or a cluster to render
+type Input struct {
+	TemplateRelease *trmodels.TemplateRelease
+	// ReleaseName and Namespace are the ones of the helm release, which are the cluster and its namespace
+	ReleaseName string
+	Namespace   string
+	// ValueFiles are the value files in the order they are applied, the latter ones override the former ones
+	ValueFiles []string
+	// Values are the values of the value files keyed by file name, the values of the template
+	// are keyed by the chart name in each file, as the files in the gitops repo are
+	Values map[string]interface{}
+}
+
+// Preview is what a config produces without committing it
+type Preview struct {
+	// Values are the values of the value files, keyed by file name
+	Values map[string]interface{} `json:"values"`
+	// Manifests are the kubernetes manifests rendered, keyed by template path
+	Manifests map[string]string `json:"manifests"`
+	// Changes are the changes compared with the current config
+	Changes *Changes `json:"changes"`
+}
+
+// Changes are the changes of a config, values of sensitive keys are masked
+type Changes struct {
+	// Values are changes of values keyed by value file
+	Values map[string][]*valuediff.Change `json:"values,omitempty"`
+	// Objects are changes of kubernetes objects keyed by kind/name
+	Objects map[string][]*valuediff.Change `json:"objects,omitempty"`
+}
+
+// Renderer renders the kubernetes manifests of template releases like `helm template`
+type Renderer interface {
+	// Render renders the kubernetes manifests with the input, keyed by template path
+	Render(ctx context.Context, input *Input) (map[string]string, error)
+	// Preview renders the proposed config, and compares its values and kubernetes objects with the ones
+	// of the current config. Everything is added if current is nil.
+	Preview(ctx context.Context, current, proposed *Input) (*Preview, error)
+}
+
+type renderer struct {
+	templateRepo templaterepo.TemplateRepo
+}
+
+func NewRenderer(repo templaterepo.TemplateRepo) Renderer {
+	return &renderer{templateRepo: repo}
+}
+
+func (r *renderer) Render(ctx context.Context, input *Input) (map[string]string, error) {
+	const op = "template renderer: render"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tr := input.TemplateRelease
+	chrt, err := r.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	for _, file := range input.ValueFiles {
+		fileValues, _ := input.Values[file].(map[string]interface{})
+		chartValues, _ := fileValues[tr.ChartName].(map[string]interface{})
+		if values, err = mergemap.Merge(values, mergemap.Copy(chartValues)); err != nil {
+			return nil, err
+		}
+	}
+	return renderChart(chrt, values, &Release{
+		Name:      input.ReleaseName,
+		Namespace: input.Namespace,
+		Revision:  1,
+		IsInstall: true,
+		Service:   "Helm",
+	})
+}
+
+func (r *renderer) Preview(ctx context.Context, current, proposed *Input) (*Preview, error) {
+	const op = "template renderer: preview"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	manifests, err := r.Render(ctx, proposed)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := parseObjects(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	currentValues, currentObjects := map[string]interface{}{}, map[string]interface{}{}
+	if current != nil {
+		currentManifests, err := r.Render(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		if currentObjects, err = parseObjects(currentManifests); err != nil {
+			return nil, err
+		}
+		currentValues = current.Values
+	}
+
+	return &Preview{
+		Values:    proposed.Values,
+		Manifests: manifests,
+		Changes: &Changes{
+			Values:  compare(currentValues, proposed.Values),
+			Objects: compare(currentObjects, objects),
+		},
+	}, nil
+}
+
+// parseObjects parses the kubernetes objects in the manifests, keyed by kind/name
+func parseObjects(manifests map[string]string) (map[string]interface{}, error) {
+	objects := make(map[string]interface{})
+	for name, manifest := range manifests {
+		for i, document := range _documentSeparator.Split(manifest, -1) {
+			if strings.TrimSpace(document) == "" {
+				continue
+			}
+			var object map[string]interface{}
+			if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"failed to parse the manifest rendered by %s: %v", name, err)
+			}
+			if object == nil {
+				continue
+			}
+			kind, _ := object["kind"].(string)
+			metadata, _ := object["metadata"].(map[string]interface{})
+			objectName, _ := metadata["name"].(string)
+			key := fmt.Sprintf("%s/%s", kind, objectName)
+			if _, ok := objects[key]; ok || kind == "" || objectName == "" {
+				key = fmt.Sprintf("%s#%d", name, i)
+			}
+			objects[key] = object
+		}
+	}
+	return objects, nil
+}
+
+// compare compares the values with the same keys, the ones only in one side are compared with nil
+func compare(from, to map[string]interface{}) map[string][]*valuediff.Change {
+	changes := make(map[string][]*valuediff.Change)
+	for key, fromValue := range from {
+		if c := valuediff.Compare(fromValue, to[key]); len(c) > 0 {
+			changes[key] = c
+		}
+	}
+	for key, toValue := range to {
+		if _, ok := from[key]; ok {
+			continue
+		}
+		if c := valuediff.Compare(nil, toValue); len(c) > 0 {
+			changes[key] = c
+		}
+	}
+	return changes
+}
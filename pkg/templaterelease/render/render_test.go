@@ -0,0 +1,185 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/util/valuediff"
+)
+
+const (
+	_helpers = `{{- define "javaapp.labels" -}}
+app: {{ .Release.Name }}
+chart: {{ .Chart.Name }}-{{ .Chart.Version }}
+{{- end -}}`
+	_deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+  labels:
+{{ include "javaapp.labels" . | indent 4 }}
+spec:
+  replicas: {{ .Values.app.replicas }}
+  template:
+    spec:
+      containers:
+        - name: main
+          image: {{ required "image is required" .Values.image }}
+          env:
+{{ toYaml .Values.app.envs | indent 12 }}
+`
+	_service = `{{- if .Values.app.port }}
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+  annotations:
+    description: {{ tpl .Values.app.description . }}
+spec:
+  ports:
+    - port: {{ .Values.app.port }}
+{{- end }}
+`
+	_notes = `{{ .Release.Name }} is deployed`
+)
+
+func TestRender(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	templateRepo := repomock.NewMockTemplateRepo(mockCtl)
+	lastSyncAt := time.Now()
+	templateRepo.EXPECT().GetChart("javaapp", "v1.0.0", lastSyncAt).Return(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "javaapp", Version: "v1.0.0"},
+		Values: map[string]interface{}{
+			"app": map[string]interface{}{"replicas": 1, "description": "{{ .Release.Name }} service"},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(_helpers)},
+			{Name: "templates/deployment.yaml", Data: []byte(_deployment)},
+			{Name: "templates/service.yaml", Data: []byte(_service)},
+			{Name: "templates/NOTES.txt", Data: []byte(_notes)},
+		},
+	}, nil).AnyTimes()
+	tr := &trmodels.TemplateRelease{
+		TemplateName: "javaapp",
+		ChartName:    "javaapp",
+		ChartVersion: "v1.0.0",
+		LastSyncAt:   lastSyncAt,
+	}
+	input := func(app map[string]interface{}, image string) *Input {
+		return &Input{
+			TemplateRelease: tr,
+			ReleaseName:     "app-cluster",
+			Namespace:       "test-1",
+			ValueFiles:      []string{"application.yaml", "pipeline/pipeline-output.yaml"},
+			Values: map[string]interface{}{
+				"application.yaml": map[string]interface{}{
+					"javaapp": map[string]interface{}{"app": app},
+				},
+				"pipeline/pipeline-output.yaml": map[string]interface{}{
+					"javaapp": map[string]interface{}{"image": image},
+				},
+			},
+		}
+	}
+	r := NewRenderer(templateRepo)
+	ctx := context.TODO()
+
+	// the values in files are merged into the default values, partials and notes are not rendered
+	manifests, err := r.Render(ctx, input(map[string]interface{}{
+		"replicas": 2,
+		"envs":     []interface{}{map[string]interface{}{"name": "JAVA_OPTS", "value": "-Xmx1g"}},
+	}, "nginx:1.0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"javaapp/templates/deployment.yaml"}, keys(manifests))
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-cluster
+  namespace: test-1
+  labels:
+    app: app-cluster
+    chart: javaapp-v1.0.0
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: main
+          image: nginx:1.0
+          env:
+            - name: JAVA_OPTS
+              value: -Xmx1g
+`, manifests["javaapp/templates/deployment.yaml"])
+
+	// required values are checked
+	_, err = r.Render(ctx, input(map[string]interface{}{"replicas": 2}, ""))
+	assert.NotNil(t, err)
+
+	// the changes of values and objects are compared with the current ones
+	current := input(map[string]interface{}{"replicas": 2}, "nginx:1.0")
+	proposed := input(map[string]interface{}{"replicas": 3, "port": 8080}, "nginx:1.0")
+	preview, err := r.Preview(ctx, current, proposed)
+	assert.Nil(t, err)
+	assert.Equal(t, proposed.Values, preview.Values)
+	assert.Equal(t, []string{"javaapp/templates/deployment.yaml", "javaapp/templates/service.yaml"},
+		keys(preview.Manifests))
+	assert.Contains(t, preview.Manifests["javaapp/templates/service.yaml"], "description: app-cluster service")
+	assert.Equal(t, map[string][]*valuediff.Change{
+		"application.yaml": {
+			{Path: "javaapp.app.port", Type: valuediff.ChangeAdded, To: "8080"},
+			{Path: "javaapp.app.replicas", Type: valuediff.ChangeModified, From: "2", To: "3"},
+		},
+	}, preview.Changes.Values)
+	assert.Equal(t, []*valuediff.Change{
+		{Path: "spec.replicas", Type: valuediff.ChangeModified, From: "2", To: "3"},
+	}, preview.Changes.Objects["Deployment/app-cluster"])
+	assert.NotEmpty(t, preview.Changes.Objects["Service/app-cluster"])
+
+	// everything is added without the current config
+	preview, err = r.Preview(ctx, nil, current)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, preview.Changes.Values["application.yaml"])
+	assert.NotEmpty(t, preview.Changes.Objects["Deployment/app-cluster"])
+}
+
+func TestAPIVersionsHas(t *testing.T) {
+	versions := APIVersions{"v1", "apps/v1", "networking.k8s.io/v1"}
+	assert.True(t, versions.Has("v1"))
+	assert.True(t, versions.Has("apps/v1"))
+	assert.True(t, versions.Has("apps/v1/Deployment"))
+	assert.True(t, versions.Has("networking.k8s.io/v1/Ingress"))
+	assert.False(t, versions.Has("apps/v2"))
+	assert.False(t, versions.Has("policy/v1"))
+}
+
+func keys(m map[string]string) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
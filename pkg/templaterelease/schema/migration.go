@@ -0,0 +1,246 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// RenamedFromKey can be set on a property of a template's json schema to declare
+// which property of the previous release it replaces
+const RenamedFromKey = "x-renamed-from"
+
+type FieldRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Migration describes how values of one template release map to another
+type Migration struct {
+	Renamed []*FieldRename `json:"renamed"`
+	Removed []string       `json:"removed"`
+	Added   []string       `json:"added"`
+
+	defaults map[string]interface{}
+}
+
+type field struct {
+	typ         string
+	renamedFrom string
+	def         interface{}
+	hasDefault  bool
+}
+
+// DiffSchema compares two json schemas and computes the migration of their leaf properties.
+// A field is treated as renamed when the new one declares RenamedFromKey, or when it is the
+// only field removed and the only field added under the same parent with the same type.
+func DiffSchema(from, to map[string]interface{}) *Migration {
+	fromFields := make(map[string]*field)
+	toFields := make(map[string]*field)
+	flattenSchema("", from, fromFields)
+	flattenSchema("", to, toFields)
+
+	m := &Migration{defaults: make(map[string]interface{})}
+	removed := make(map[string]bool)
+	for path := range fromFields {
+		if _, ok := toFields[path]; !ok {
+			removed[path] = true
+		}
+	}
+	added := make(map[string]bool)
+	for path, f := range toFields {
+		if _, ok := fromFields[path]; ok {
+			continue
+		}
+		if f.renamedFrom != "" {
+			oldPath := joinPath(parentPath(path), f.renamedFrom)
+			if removed[oldPath] {
+				m.Renamed = append(m.Renamed, &FieldRename{From: oldPath, To: path})
+				delete(removed, oldPath)
+				continue
+			}
+		}
+		added[path] = true
+	}
+
+	// pair the remaining fields which are the only change under their parent
+	removedByParent := groupByParent(removed)
+	addedByParent := groupByParent(added)
+	for parent, oldPaths := range removedByParent {
+		newPaths := addedByParent[parent]
+		if len(oldPaths) != 1 || len(newPaths) != 1 {
+			continue
+		}
+		oldPath, newPath := oldPaths[0], newPaths[0]
+		if fromFields[oldPath].typ != toFields[newPath].typ {
+			continue
+		}
+		m.Renamed = append(m.Renamed, &FieldRename{From: oldPath, To: newPath})
+		delete(removed, oldPath)
+		delete(added, newPath)
+	}
+
+	for path := range removed {
+		m.Removed = append(m.Removed, path)
+	}
+	for path := range added {
+		m.Added = append(m.Added, path)
+		if toFields[path].hasDefault {
+			m.defaults[path] = toFields[path].def
+		}
+	}
+	sort.Strings(m.Removed)
+	sort.Strings(m.Added)
+	sort.Slice(m.Renamed, func(i, j int) bool {
+		return m.Renamed[i].From < m.Renamed[j].From
+	})
+	return m
+}
+
+// Apply returns a copy of values migrated to the new schema: renamed fields are moved,
+// removed fields are dropped and added fields are filled with their defaults
+func (m *Migration) Apply(values map[string]interface{}) map[string]interface{} {
+	migrated := deepCopy(values)
+	for _, rename := range m.Renamed {
+		if v, ok := popPath(migrated, rename.From); ok {
+			setPath(migrated, rename.To, v)
+		}
+	}
+	for _, path := range m.Removed {
+		popPath(migrated, path)
+	}
+	for _, path := range m.Added {
+		def, ok := m.defaults[path]
+		if !ok {
+			continue
+		}
+		if _, exists := getPath(migrated, path); !exists {
+			setPath(migrated, path, deepCopyValue(def))
+		}
+	}
+	return migrated
+}
+
+func flattenSchema(prefix string, schema map[string]interface{}, fields map[string]*field) {
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for name, p := range properties {
+		property, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := joinPath(prefix, name)
+		if _, ok := property["properties"].(map[string]interface{}); ok {
+			flattenSchema(path, property, fields)
+			continue
+		}
+		f := &field{}
+		f.typ, _ = property["type"].(string)
+		f.renamedFrom, _ = property[RenamedFromKey].(string)
+		f.def, f.hasDefault = property["default"]
+		fields[path] = f
+	}
+}
+
+func groupByParent(paths map[string]bool) map[string][]string {
+	grouped := make(map[string][]string)
+	for path := range paths {
+		parent := parentPath(path)
+		grouped[parent] = append(grouped[parent], path)
+	}
+	return grouped
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func getPath(values map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	current := values
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	v, ok := current[keys[len(keys)-1]]
+	return v, ok
+}
+
+func popPath(values map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	current := values
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	last := keys[len(keys)-1]
+	v, ok := current[last]
+	delete(current, last)
+	return v, ok
+}
+
+func setPath(values map[string]interface{}, path string, v interface{}) {
+	keys := strings.Split(path, ".")
+	current := values
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = v
+}
+
+func deepCopy(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return make(map[string]interface{})
+	}
+	copied, _ := deepCopyValue(values).(map[string]interface{})
+	return copied
+}
+
+func deepCopyValue(v interface{}) interface{} {
+	bts, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var copied interface{}
+	if err := json.Unmarshal(bts, &copied); err != nil {
+		return v
+	}
+	return copied
+}
@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigration(t *testing.T) {
+	from := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"app": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"replicas": map[string]interface{}{"type": "integer"},
+							"resource": map[string]interface{}{"type": "string"},
+						},
+					},
+					"port":    map[string]interface{}{"type": "integer"},
+					"logPath": map[string]interface{}{"type": "string"},
+					"legacy":  map[string]interface{}{"type": "boolean"},
+				},
+			},
+		},
+	}
+	to := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"app": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"replicas": map[string]interface{}{"type": "integer"},
+							"flavor":   map[string]interface{}{"type": "string"},
+						},
+					},
+					"servicePort": map[string]interface{}{"type": "integer", RenamedFromKey: "port"},
+					"logDir":      map[string]interface{}{"type": "string"},
+					"probe": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"enabled": map[string]interface{}{"type": "boolean", "default": true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	m := DiffSchema(from, to)
+	assert.Equal(t, []*FieldRename{
+		{From: "app.port", To: "app.servicePort"},
+		{From: "app.spec.resource", To: "app.spec.flavor"},
+	}, m.Renamed)
+	assert.Equal(t, []string{"app.legacy", "app.logPath"}, m.Removed)
+	assert.Equal(t, []string{"app.logDir", "app.probe.enabled"}, m.Added)
+
+	values := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 2,
+				"resource": "small",
+			},
+			"port":    8080,
+			"logPath": "/var/log",
+			"legacy":  true,
+		},
+	}
+	migrated := m.Apply(values)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": float64(2),
+				"flavor":   "small",
+			},
+			"servicePort": float64(8080),
+			"probe": map[string]interface{}{
+				"enabled": true,
+			},
+		},
+	}, migrated)
+	// the original values are untouched
+	assert.Equal(t, "small", values["app"].(map[string]interface{})["spec"].(map[string]interface{})["resource"])
+}
@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/util/jsonschema"
+)
+
+// Input is the template values submitted to create or update an application or a cluster
+type Input struct {
+	Template string
+	Release  string
+	// RenderValues are used to render the schemas of the release, such as the resource type
+	RenderValues map[string]string
+
+	Application map[string]interface{}
+	Pipeline    map[string]interface{}
+	// PreviousApplication and PreviousPipeline are the values replaced by the ones submitted,
+	// the read-only and hidden fields must keep their previous values, or the defaults if they're nil
+	PreviousApplication map[string]interface{}
+	PreviousPipeline    map[string]interface{}
+}
+
+type Service interface {
+	// Validate validates the values against the json schemas of the template release,
+	// and checks the read-only and hidden fields in the ui schemas are not changed except by admins.
+	// The values of application are validated before the ones of pipeline,
+	// a *jsonschema.ValidationError is returned with the invalid fields of the first invalid values.
+	Validate(ctx context.Context, input *Input) error
+}
+
+type service struct {
+	schemaGetter templateschema.Getter
+}
+
+func NewService(schemaGetter templateschema.Getter) Service {
+	return &service{schemaGetter: schemaGetter}
+}
+
+func (s *service) Validate(ctx context.Context, input *Input) error {
+	schema, err := s.schemaGetter.GetTemplateSchema(ctx, input.Template, input.Release, input.RenderValues)
+	if err != nil {
+		return err
+	}
+	// admins are allowed to change the fields managed by the platform
+	checkUISchema := true
+	if currentUser, err := common.UserFromContext(ctx); err == nil && currentUser.IsAdmin() {
+		checkUISchema = false
+	}
+
+	if schema.Application != nil && input.Application != nil {
+		if err := validate(schema.Application, input.Application, input.PreviousApplication,
+			false, checkUISchema); err != nil {
+			return err
+		}
+	}
+	if schema.Pipeline != nil && input.Pipeline != nil {
+		if err := validate(schema.Pipeline, input.Pipeline, input.PreviousPipeline,
+			true, checkUISchema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(schema *templateschema.Schema, values, previous map[string]interface{},
+	setUnevaluatedPropertiesToFalse, checkUISchema bool) error {
+	if schema.JSONSchema != nil {
+		if err := jsonschema.Validate(schema.JSONSchema, values, setUnevaluatedPropertiesToFalse); err != nil {
+			return err
+		}
+	}
+	if checkUISchema {
+		return jsonschema.ValidateUISchema(schema.JSONSchema, schema.UISchema, values, previous)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/util/jsonschema"
+)
+
+func TestValidate(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	schemaGetter := trschemamock.NewMockGetter(mockCtl)
+	schemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), "javaapp", "v1.0.0", gomock.Any()).Return(
+		&templateschema.Schemas{
+			Application: &templateschema.Schema{
+				JSONSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"app": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"replicas": map[string]interface{}{"type": "integer", "default": 1},
+								"image":    map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+				UISchema: map[string]interface{}{
+					"app": map[string]interface{}{
+						"replicas": map[string]interface{}{"ui:readonly": true},
+					},
+				},
+			},
+			Pipeline: &templateschema.Schema{
+				JSONSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"buildxml": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	user := context.WithValue(context.TODO(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	admin := context.WithValue(context.TODO(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "Admin",
+		ID:    2,
+		Admin: true,
+	})
+	s := NewService(schemaGetter)
+
+	input := func(replicas interface{}, buildxml interface{}) *Input {
+		return &Input{
+			Template: "javaapp",
+			Release:  "v1.0.0",
+			Application: map[string]interface{}{
+				"app": map[string]interface{}{"replicas": replicas, "image": "nginx"},
+			},
+			Pipeline: map[string]interface{}{"buildxml": buildxml},
+		}
+	}
+
+	assert.Nil(t, s.Validate(user, input(1.0, "<xml/>")))
+
+	// the invalid fields are returned
+	err := s.Validate(user, input("two", "<xml/>"))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	fields := jsonschema.FieldErrors(err)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "app.replicas", fields[0].Field)
+
+	err = s.Validate(user, input(1.0, 1.0))
+	fields = jsonschema.FieldErrors(err)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "buildxml", fields[0].Field)
+
+	// read-only fields can only be changed by admins
+	err = s.Validate(user, input(2.0, "<xml/>"))
+	assert.Equal(t, []jsonschema.FieldError{
+		{Field: "app.replicas", Message: "is read-only and cannot be changed"},
+	}, jsonschema.FieldErrors(err))
+	assert.Nil(t, s.Validate(admin, input(2.0, "<xml/>")))
+
+	// the previous values are kept when updating
+	in := input(2.0, "<xml/>")
+	in.PreviousApplication = map[string]interface{}{
+		"app": map[string]interface{}{"replicas": 2},
+	}
+	assert.Nil(t, s.Validate(user, in))
+}
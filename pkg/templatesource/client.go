@@ -0,0 +1,186 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatesource
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+var _challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// registryClient gets resources from helm chart repositories and oci registries
+type registryClient struct {
+	registries     map[string]*config.Repo
+	client         *http.Client
+	insecureClient *http.Client
+}
+
+func newRegistryClient(registries []*config.Repo) *registryClient {
+	registryMap := make(map[string]*config.Repo)
+	for _, registry := range registries {
+		registryMap[hostOf(registry.Host)] = registry
+	}
+	return &registryClient{
+		registries: registryMap,
+		client:     &http.Client{},
+		insecureClient: &http.Client{
+			Transport: &http.Transport{
+				// nolint
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}
+}
+
+// hostOf returns the host of a url, or the string itself if it's not a url with scheme
+func hostOf(s string) string {
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return strings.TrimSuffix(s, "/")
+}
+
+// basicAuth returns the basic authorization of the registry, it's empty if no username is configured
+func basicAuth(registry *config.Repo) string {
+	if registry == nil || registry.Username == "" {
+		return ""
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(registry.Username+":"+registry.Password))
+}
+
+// get gets the resource of the url, and answers the bearer token challenge of oci registries
+func (c *registryClient) get(ctx context.Context, rawURL string, header http.Header) ([]byte, http.Header, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "url is incorrect: %v", err)
+	}
+	registry := c.registries[u.Host]
+
+	authorization := basicAuth(registry)
+	if registry != nil && registry.Token != "" {
+		authorization = "Bearer " + registry.Token
+	}
+
+	resp, body, err := c.do(ctx, registry, rawURL, header, authorization)
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge := resp.Header.Get("WWW-Authenticate"); resp.StatusCode == http.StatusUnauthorized &&
+		strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		token, err := c.token(ctx, registry, challenge)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, body, err = c.do(ctx, registry, rawURL, header, "Bearer "+token)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, resp.Header, nil
+	case http.StatusNotFound:
+		return nil, nil, perror.Wrap(herrors.NewErrNotFound(herrors.ChartInTemplateSource,
+			fmt.Sprintf("%s: %s", resp.Status, string(body))), "not found")
+	default:
+		return nil, nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to get %s, %s: %s", rawURL, resp.Status, string(body))
+	}
+}
+
+func (c *registryClient) do(ctx context.Context, registry *config.Repo, rawURL string,
+	header http.Header, authorization string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to create request: %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	client := c.client
+	if registry != nil && registry.Insecure {
+		client = c.insecureClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to get %s: %v", rawURL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrReadFailed, "failed to read response: %v", err)
+	}
+	return resp, body, nil
+}
+
+// token gets a bearer token from the realm of the challenge, with the basic auth of the registry if configured
+func (c *registryClient) token(ctx context.Context, registry *config.Repo, challenge string) (string, error) {
+	params := make(map[string]string)
+	for _, match := range _challengeParamPattern.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "invalid challenge: %s", challenge)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, body, err := c.do(ctx, registry, realm.String(), nil, basicAuth(registry))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to get token, %s: %s", resp.Status, string(body))
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to unmarshal token: %v", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatesource
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+const _tagPageSize = 100
+
+type gitSource struct {
+	helper git.Helper
+}
+
+func (s *gitSource) ListVersions(ctx context.Context, repository string) ([]string, error) {
+	versions := make([]string, 0)
+	for page := 1; ; page++ {
+		tags, err := s.helper.ListTag(ctx, repository, &git.SearchParams{
+			PageNumber: page,
+			PageSize:   _tagPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, tags...)
+		if len(tags) < _tagPageSize {
+			return versions, nil
+		}
+	}
+}
+
+func (s *gitSource) GetChart(ctx context.Context, repository, version string) (*Chart, error) {
+	tag, err := s.helper.GetTagArchive(ctx, repository, version)
+	if err != nil {
+		return nil, err
+	}
+	return &Chart{
+		Revision:    tag.ShortID,
+		ArchiveData: tag.ArchiveData,
+	}, nil
+}
@@ -0,0 +1,128 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatesource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+type helmIndex struct {
+	Entries map[string][]*helmChartVersion `json:"entries"`
+}
+
+type helmChartVersion struct {
+	Version string   `json:"version"`
+	URLs    []string `json:"urls"`
+}
+
+// helmSource fetches charts from helm chart repositories by their index.yaml
+type helmSource struct {
+	client *registryClient
+}
+
+func (s *helmSource) ListVersions(ctx context.Context, repository string) ([]string, error) {
+	_, versions, err := s.getVersions(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(versions))
+	for _, version := range versions {
+		result = append(result, version.Version)
+	}
+	return result, nil
+}
+
+func (s *helmSource) GetChart(ctx context.Context, repository, version string) (*Chart, error) {
+	repoURL, versions, err := s.getVersions(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.Version != version {
+			continue
+		}
+		if len(v.URLs) == 0 {
+			return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+				"no url of version %s in repository %s", version, repository)
+		}
+		// urls in the index can be relative to the repository
+		chartURL, err := repoURL.Parse(v.URLs[0])
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "url of chart is incorrect: %v", err)
+		}
+		data, _, err := s.client.get(ctx, chartURL.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256(data)
+		return &Chart{
+			Revision:    shortDigest(hex.EncodeToString(digest[:])),
+			ArchiveData: data,
+		}, nil
+	}
+	reason := fmt.Sprintf("version %s not found in repository %s", version, repository)
+	return nil, perror.Wrap(herrors.NewErrNotFound(herrors.ChartInTemplateSource, reason), reason)
+}
+
+// getVersions gets the index of the repository, and returns versions of the chart in it
+func (s *helmSource) getVersions(ctx context.Context, repository string) (*url.URL, []*helmChartVersion, error) {
+	repoURL, chartName, err := parseHelmRepository(repository)
+	if err != nil {
+		return nil, nil, err
+	}
+	indexURL, _ := repoURL.Parse("index.yaml")
+	data, _, err := s.client.get(ctx, indexURL.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var index helmIndex
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to unmarshal index: %v", err)
+	}
+	versions, ok := index.Entries[chartName]
+	if !ok {
+		reason := fmt.Sprintf("chart %s not found in repository %s", chartName, repoURL)
+		return nil, nil, perror.Wrap(herrors.NewErrNotFound(herrors.ChartInTemplateSource, reason), reason)
+	}
+	return repoURL, versions, nil
+}
+
+// parseHelmRepository splits the repository like https://charts.example.com/stable/nginx
+// into the url of the helm repository ending with slash and the chart name
+func parseHelmRepository(repository string) (*url.URL, string, error) {
+	repository = strings.TrimSuffix(repository, "/")
+	i := strings.LastIndex(repository, "/")
+	if i < 0 {
+		return nil, "", perror.Wrapf(herrors.ErrParamInvalid,
+			"repository %s is not a chart url like https://charts.example.com/stable/nginx", repository)
+	}
+	repoURL, err := url.Parse(repository[:i+1])
+	if err != nil || (repoURL.Scheme != "http" && repoURL.Scheme != "https") || repoURL.Host == "" {
+		return nil, "", perror.Wrapf(herrors.ErrParamInvalid,
+			"repository %s is not a chart url like https://charts.example.com/stable/nginx", repository)
+	}
+	return repoURL, repository[i+1:], nil
+}
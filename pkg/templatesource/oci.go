@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatesource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	_ociScheme            = "oci://"
+	_ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	_helmChartMediaType   = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+)
+
+var _nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="?next"?`)
+
+type ociTags struct {
+	Tags []string `json:"tags"`
+}
+
+type ociManifest struct {
+	Layers []*ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+// ociSource fetches charts from oci registries by the distribution api
+type ociSource struct {
+	client *registryClient
+}
+
+func (s *ociSource) ListVersions(ctx context.Context, repository string) ([]string, error) {
+	host, name, err := parseOCIRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0)
+	next := fmt.Sprintf("https://%s/v2/%s/tags/list", host, name)
+	for next != "" {
+		data, header, err := s.client.get(ctx, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		var tags ociTags
+		if err := json.Unmarshal(data, &tags); err != nil {
+			return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to unmarshal tags: %v", err)
+		}
+		for _, tag := range tags.Tags {
+			// plus signs of chart versions are replaced by underscores in tags, as they are not allowed
+			versions = append(versions, strings.ReplaceAll(tag, "_", "+"))
+		}
+		next, err = nextLink(next, header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return versions, nil
+}
+
+func (s *ociSource) GetChart(ctx context.Context, repository, version string) (*Chart, error) {
+	host, name, err := parseOCIRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := strings.ReplaceAll(version, "+", "_")
+	data, header, err := s.client.get(ctx, fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, name, tag),
+		http.Header{"Accept": []string{_ociManifestMediaType}})
+	if err != nil {
+		return nil, err
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to unmarshal manifest: %v", err)
+	}
+	digest := header.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(data)
+		digest = hex.EncodeToString(sum[:])
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != _helmChartMediaType {
+			continue
+		}
+		archive, _, err := s.client.get(ctx, fmt.Sprintf("https://%s/v2/%s/blobs/%s", host, name, layer.Digest), nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Chart{
+			Revision:    shortDigest(digest),
+			ArchiveData: archive,
+		}, nil
+	}
+	return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+		"%s:%s is not a helm chart, no layer of %s", repository, tag, _helmChartMediaType)
+}
+
+// parseOCIRepository splits the repository like oci://registry.example.com/charts/nginx
+// into the host of the registry and the name of the chart
+func parseOCIRepository(repository string) (string, string, error) {
+	ref := strings.TrimSuffix(strings.TrimPrefix(repository, _ociScheme), "/")
+	i := strings.Index(ref, "/")
+	if !strings.HasPrefix(repository, _ociScheme) || i <= 0 || i == len(ref)-1 {
+		return "", "", perror.Wrapf(herrors.ErrParamInvalid,
+			"repository %s is not a chart reference like oci://registry.example.com/charts/nginx", repository)
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+// nextLink resolves the next page in the link header against the current url, it's empty on the last page
+func nextLink(current, link string) (string, error) {
+	matches := _nextLinkPattern.FindStringSubmatch(link)
+	if len(matches) != 2 {
+		return "", nil
+	}
+	u, err := url.Parse(current)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "url is incorrect: %v", err)
+	}
+	next, err := u.Parse(matches[1])
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "link is incorrect: %v", err)
+	}
+	return next.String(), nil
+}
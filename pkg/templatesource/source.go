@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatesource
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
+)
+
+const (
+	// KindGit fetches charts from archives of tags in git repositories,
+	// the repository of a template is the url of the git repository.
+	KindGit = "git"
+	// KindHelm fetches charts from helm chart repositories, the repository of a template is the url
+	// of the repository followed by the chart name, such as https://charts.example.com/stable/nginx.
+	KindHelm = "helm"
+	// KindOCI fetches charts from oci registries, the repository of a template is the reference
+	// of the chart without tag, such as oci://registry.example.com/charts/nginx.
+	KindOCI = "oci"
+)
+
+const (
+	// json schema file path
+	_pipelineSchemaPath    = "schema/pipeline.schema.json"
+	_applicationSchemaPath = "schema/application.schema.json"
+	// ui schema file path
+	_pipelineUISchemaPath    = "schema/pipeline.ui.schema.json"
+	_applicationUISchemaPath = "schema/application.ui.schema.json"
+)
+
+// Chart is a version of a chart fetched from a source
+type Chart struct {
+	// Revision identifies the content of the version, it's the short commit id of a git tag,
+	// or the short digest of a chart in a helm chart repository or an oci registry
+	Revision    string
+	ArchiveData []byte
+}
+
+// Source is where charts of templates are fetched from, releases of a template are versions of its chart
+//
+//go:generate mockgen -source=$GOFILE -destination=../../mock/pkg/templatesource/mock_source.go -package=mock_templatesource
+type Source interface {
+	// ListVersions lists versions of the chart in the repository, in the order of the source
+	ListVersions(ctx context.Context, repository string) ([]string, error)
+	// GetChart fetches the chart archive of the version in the repository
+	GetChart(ctx context.Context, repository, version string) (*Chart, error)
+}
+
+// Sources gets sources of templates by their kinds
+type Sources interface {
+	// Get gets the source of the kind, it's the git source if kind is empty
+	Get(kind string) (Source, error)
+}
+
+type sources map[string]Source
+
+// NewSources creates sources of templates, helm chart repositories and oci registries
+// are accessed with the credentials of registries matched by host, or anonymously
+func NewSources(gitHelper git.Helper, registries []*config.Repo) Sources {
+	client := newRegistryClient(registries)
+	return sources{
+		KindGit:  &gitSource{helper: gitHelper},
+		KindHelm: &helmSource{client: client},
+		KindOCI:  &ociSource{client: client},
+	}
+}
+
+func (s sources) Get(kind string) (Source, error) {
+	if kind == "" {
+		kind = KindGit
+	}
+	if source, ok := s[kind]; ok {
+		return source, nil
+	}
+	return nil, perror.Wrapf(herrors.ErrParamInvalid,
+		"template source %s is not one of %s, %s and %s", kind, KindGit, KindHelm, KindOCI)
+}
+
+// GetSchema fetches the chart of the version and parses the schemas in it
+func GetSchema(ctx context.Context, source Source, repository, version string,
+	params map[string]string) (*schema.Schemas, error) {
+	chart, err := source.GetChart(ctx, repository, version)
+	if err != nil {
+		return nil, err
+	}
+	chartPkg, err := loader.LoadArchive(bytes.NewReader(chart.ArchiveData))
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrLoadChartArchive, "failed to load archive: %v", err)
+	}
+
+	files := map[string][]byte{
+		_pipelineSchemaPath:      nil,
+		_applicationSchemaPath:   nil,
+		_pipelineUISchemaPath:    nil,
+		_applicationUISchemaPath: nil,
+	}
+	for _, file := range chartPkg.Files {
+		if t, ok := files[file.Name]; ok && t == nil {
+			files[file.Name] = file.Data
+		}
+	}
+
+	return schema.ParseFiles(params,
+		files[_pipelineSchemaPath], files[_applicationSchemaPath],
+		files[_pipelineUISchemaPath], files[_applicationUISchemaPath])
+}
+
+// shortDigest shortens a digest like sha256:<hex> to the first 8 characters of the hex
+func shortDigest(digest string) string {
+	if i := strings.Index(digest, ":"); i >= 0 {
+		digest = digest[i+1:]
+	}
+	if len(digest) > 8 {
+		return digest[:8]
+	}
+	return digest
+}
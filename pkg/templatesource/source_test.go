@@ -0,0 +1,178 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatesource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+)
+
+func chartArchive(t *testing.T) []byte {
+	var buf bytes.Buffer
+	err := templaterepo.ChartSerialize(&chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "nginx",
+			Version:    "1.0.0",
+		},
+		Files: []*chart.File{
+			{Name: _applicationSchemaPath, Data: []byte(`{"type": "object"}`)},
+		},
+	}, &buf)
+	assert.Nil(t, err)
+	return buf.Bytes()
+}
+
+func TestSourcesGet(t *testing.T) {
+	sources := NewSources(nil, nil)
+	for _, kind := range []string{"", KindGit, KindHelm, KindOCI} {
+		source, err := sources.Get(kind)
+		assert.Nil(t, err)
+		assert.NotNil(t, source)
+	}
+	_, err := sources.Get("svn")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func TestHelmSource(t *testing.T) {
+	archive := chartArchive(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/stable/index.yaml":
+			_, _ = w.Write([]byte(`
+apiVersion: v1
+entries:
+  nginx:
+    - version: 1.0.1
+      urls: [https://127.0.0.1:1/nginx-1.0.1.tgz]
+    - version: 1.0.0
+      urls: [charts/nginx-1.0.0.tgz]
+`))
+		case "/stable/charts/nginx-1.0.0.tgz":
+			_, _ = w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := &helmSource{client: newRegistryClient([]*config.Repo{
+		{Host: server.URL, Username: "user", Password: "password"},
+	})}
+	repository := server.URL + "/stable/nginx"
+
+	versions, err := source.ListVersions(context.Background(), repository)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0.1", "1.0.0"}, versions)
+
+	c, err := source.GetChart(context.Background(), repository, "1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, archive, c.ArchiveData)
+	assert.Equal(t, 8, len(c.Revision))
+
+	_, err = source.GetChart(context.Background(), repository, "0.0.1")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	_, err = source.ListVersions(context.Background(), server.URL+"/stable/redis")
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	schemas, err := GetSchema(context.Background(), source, repository, "1.0.0", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "object", schemas.Application.JSONSchema["type"])
+
+	_, err = source.ListVersions(context.Background(), "nginx")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func TestOCISource(t *testing.T) {
+	archive := chartArchive(t)
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "repository:charts/nginx:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token": "token"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+				`Bearer realm="%s/token",service="registry",scope="repository:charts/nginx:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/charts/nginx/tags/list" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/charts/nginx/tags/list?last=1.0.0&n=2>; rel="next"`)
+			_, _ = w.Write([]byte(`{"name": "charts/nginx", "tags": ["0.9.0", "1.0.0"]}`))
+		case r.URL.Path == "/v2/charts/nginx/tags/list":
+			_, _ = w.Write([]byte(`{"name": "charts/nginx", "tags": ["1.0.1_build.1"]}`))
+		case r.URL.Path == "/v2/charts/nginx/manifests/1.0.1_build.1":
+			assert.Equal(t, _ociManifestMediaType, r.Header.Get("Accept"))
+			w.Header().Set("Docker-Content-Digest", "sha256:0123456789abcdef")
+			_, _ = w.Write([]byte(`{"layers": [
+				{"mediaType": "application/vnd.cncf.helm.config.v1+json", "digest": "sha256:config"},
+				{"mediaType": "` + _helmChartMediaType + `", "digest": "sha256:chart"}]}`))
+		case r.URL.Path == "/v2/charts/nginx/blobs/sha256:chart":
+			_, _ = w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	source := &ociSource{client: newRegistryClient([]*config.Repo{
+		{Host: host, Username: "user", Password: "password", Insecure: true},
+	})}
+	repository := "oci://" + host + "/charts/nginx"
+
+	versions, err := source.ListVersions(context.Background(), repository)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"0.9.0", "1.0.0", "1.0.1+build.1"}, versions)
+
+	c, err := source.GetChart(context.Background(), repository, "1.0.1+build.1")
+	assert.Nil(t, err)
+	assert.Equal(t, archive, c.ArchiveData)
+	assert.Equal(t, "01234567", c.Revision)
+
+	_, err = source.GetChart(context.Background(), repository, "2.0.0")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	_, err = source.ListVersions(context.Background(), "https://"+host+"/charts/nginx")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
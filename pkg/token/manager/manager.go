@@ -28,6 +28,13 @@ type Manager interface {
 	LoadTokenByCode(ctx context.Context, code string) (*models.Token, error)
 	RevokeTokenByID(context.Context, uint) error
 	RevokeTokenByClientID(ctx context.Context, clientID string) error
+	// RevokeTokensByUserID revokes all the tokens of the user, including the ones authorized to oauth clients
+	RevokeTokensByUserID(ctx context.Context, userID uint) error
+	// ListAccessTokensByUserID lists the access tokens issued to the user directly
+	ListAccessTokensByUserID(ctx context.Context, userID uint) ([]*models.Token, error)
+	// ListExpirableTokensAfterID lists tokens issued to oauth clients which can expire, ordered by id
+	ListExpirableTokensAfterID(ctx context.Context, id uint, limit int) ([]*models.Token, error)
+	DeleteTokensByIDs(ctx context.Context, ids []uint) (int64, error)
 }
 
 func New(db *gorm.DB) Manager {
@@ -57,3 +64,19 @@ func (m *manager) RevokeTokenByID(ctx context.Context, id uint) error {
 func (m *manager) RevokeTokenByClientID(ctx context.Context, clientID string) error {
 	return m.store.DeleteByClientID(ctx, clientID)
 }
+
+func (m *manager) RevokeTokensByUserID(ctx context.Context, userID uint) error {
+	return m.store.DeleteByUser(ctx, userID)
+}
+
+func (m *manager) ListAccessTokensByUserID(ctx context.Context, userID uint) ([]*models.Token, error) {
+	return m.store.ListAccessTokensByUser(ctx, userID)
+}
+
+func (m *manager) ListExpirableTokensAfterID(ctx context.Context, id uint, limit int) ([]*models.Token, error) {
+	return m.store.ListExpirableAfterID(ctx, id, limit)
+}
+
+func (m *manager) DeleteTokensByIDs(ctx context.Context, ids []uint) (int64, error) {
+	return m.store.DeleteByIDs(ctx, ids)
+}
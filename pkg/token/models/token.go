@@ -35,8 +35,19 @@ type Token struct {
 	ExpiresIn time.Duration `gorm:"column:expires_in"`
 	Scope     string        `gorm:"column:scope"`
 
-	// access token id when code type is refresh_token
+	// access token id when code type is refresh_token,
+	// refresh token id issued by it when code type is authorize_code, which means the code is consumed
 	RefID uint `gorm:"column:ref_id"`
 
 	UserID uint `gorm:"column:user_id"`
+
+	// PKCE challenge of the authorization code, ref: rfc7636
+	CodeChallenge       string `gorm:"column:code_challenge"`
+	CodeChallengeMethod string `gorm:"column:code_challenge_method"`
+
+	// Nonce of the authorization request, which is carried by the id token exchanged by the code
+	Nonce string `gorm:"column:nonce"`
+
+	// UserCode is the code the user enters on the verification page to approve a device code, ref: rfc8628
+	UserCode string `gorm:"column:user_code"`
 }
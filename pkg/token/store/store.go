@@ -17,6 +17,7 @@ package store
 import (
 	"context"
 	goerrors "errors"
+	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/common"
@@ -91,3 +92,70 @@ func (s *store) DeleteByClientID(ctx context.Context, clientID string) error {
 	result := s.db.WithContext(ctx).Exec(common.DeleteByClientID, clientID)
 	return result.Error
 }
+
+func (s *store) DeleteAuthorizationCodesByClientID(ctx context.Context, clientID string) error {
+	result := s.db.WithContext(ctx).Exec(common.DeleteAuthorizationCodesByClientID, clientID)
+	return result.Error
+}
+
+func (s *store) ConsumeCode(ctx context.Context, id, refreshTokenID uint, expiresIn time.Duration) (bool, error) {
+	result := s.db.WithContext(ctx).Exec(common.ConsumeAuthorizationCode, refreshTokenID, expiresIn, id)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *store) GetByUserCode(ctx context.Context, userCode string) (*models.Token, error) {
+	var token models.Token
+	result := s.db.WithContext(ctx).Model(token).Where("user_code = ?", userCode).First(&token)
+	if result.Error != nil {
+		if goerrors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TokenInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return &token, nil
+}
+
+func (s *store) ApproveDeviceCode(ctx context.Context, id, userID uint) (bool, error) {
+	result := s.db.WithContext(ctx).Exec(common.ApproveDeviceCode, userID, id)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *store) DeleteByUser(ctx context.Context, userID uint) error {
+	result := s.db.WithContext(ctx).Exec(common.DeleteByUserID, userID)
+	return result.Error
+}
+
+func (s *store) ListAccessTokensByUser(ctx context.Context, userID uint) ([]*models.Token, error) {
+	var tokens []*models.Token
+	result := s.db.WithContext(ctx).Raw(common.TokenListAccessTokensByUserID, userID).Scan(&tokens)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return tokens, nil
+}
+
+func (s *store) ListExpirableAfterID(ctx context.Context, id uint, limit int) ([]*models.Token, error) {
+	var tokens []*models.Token
+	result := s.db.WithContext(ctx).Raw(common.TokenListExpirableAfterID, id, limit).Scan(&tokens)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return tokens, nil
+}
+
+func (s *store) DeleteByIDs(ctx context.Context, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := s.db.WithContext(ctx).Exec(common.DeleteTokensByIDs, ids)
+	if result.Error != nil {
+		return 0, herrors.NewErrDeleteFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
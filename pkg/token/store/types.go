@@ -16,6 +16,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/token/models"
 )
@@ -28,4 +29,21 @@ type Store interface {
 	DeleteByID(ctx context.Context, id uint) error
 	DeleteByCode(ctx context.Context, code string) error
 	DeleteByClientID(ctx context.Context, clientID string) error
+	// DeleteAuthorizationCodesByClientID deletes the authorization codes not yet exchanged by the client
+	DeleteAuthorizationCodesByClientID(ctx context.Context, clientID string) error
+	// ConsumeCode marks the authorization code as exchanged for the refresh token atomically,
+	// false is returned if the code has already been consumed by another exchange.
+	// The code expires in expiresIn after it's consumed, 0 means it never expires.
+	ConsumeCode(ctx context.Context, id, refreshTokenID uint, expiresIn time.Duration) (bool, error)
+	// GetByUserCode gets the device code by the user code shown to the user
+	GetByUserCode(ctx context.Context, userCode string) (*models.Token, error)
+	// ApproveDeviceCode sets the user who approves the device code atomically,
+	// false is returned if the code has already been approved
+	ApproveDeviceCode(ctx context.Context, id, userID uint) (bool, error)
+	DeleteByUser(ctx context.Context, userID uint) error
+	// ListAccessTokensByUser lists the personal access tokens of the user or the tokens of the robot
+	ListAccessTokensByUser(ctx context.Context, userID uint) ([]*models.Token, error)
+	// ListExpirableAfterID lists tokens issued to oauth clients which can expire, ordered by id
+	ListExpirableAfterID(ctx context.Context, id uint, limit int) ([]*models.Token, error)
+	DeleteByIDs(ctx context.Context, ids []uint) (int64, error)
 }
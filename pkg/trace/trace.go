@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trace sets up the OpenTelemetry SDK exporting the spans of requests to a collector by OTLP/HTTP.
+// Spans are propagated by the w3c traceparent header, ref: https://www.w3.org/TR/trace-context/
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	traceconfig "github.com/horizoncd/horizon/pkg/config/trace"
+)
+
+const (
+	_instrumentationName = "github.com/horizoncd/horizon"
+	_exportTimeout       = 10 * time.Second
+)
+
+// Tracer exports the spans ended, it's nil if tracing is disabled
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+}
+
+// Init enables tracing if the endpoint is configured by setting the global tracer provider and propagator.
+// The returned Tracer should be shut down before exiting to export the spans left,
+// a nil Tracer is returned if tracing is disabled.
+func Init(config traceconfig.Config) (*Tracer, error) {
+	if !config.Enabled() {
+		return nil, nil
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(endpoint.Path, "/") + "/v1/traces"),
+		otlptracehttp.WithHeaders(config.Headers),
+		otlptracehttp.WithTimeout(_exportTimeout),
+	}
+	if endpoint.Scheme != "https" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithMaxExportBatchSize(config.BatchSize),
+			sdktrace.WithBatchTimeout(config.FlushInterval)),
+		// requests with the traceparent header follow the sampling decision of the callers
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return &Tracer{provider: provider}, nil
+}
+
+// Shutdown exports the spans left until ctx is done
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// Start starts a span as the child of the span in ctx, and returns the context derived with the new span.
+// The spans started with the returned context are children of the new span, ctx itself is not changed.
+// The span is a no-op one if tracing is disabled or the request is not sampled.
+func Start(ctx context.Context, name string,
+	opts ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span) {
+	return otel.Tracer(_instrumentationName).Start(withRequestSpan(ctx), name, opts...)
+}
+
+// withRequestSpan attaches the span of the request if ctx is a gin.Context, as gin.Context (v1.7) only looks up
+// its own keys instead of the values of the request context, and returns the request by the key 0
+func withRequestSpan(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	if oteltrace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	if r, ok := ctx.Value(0).(*http.Request); ok && r != nil {
+		if span := oteltrace.SpanFromContext(r.Context()); span.SpanContext().IsValid() {
+			return oteltrace.ContextWithSpan(ctx, span)
+		}
+	}
+	return ctx
+}
+
+// TraceID returns the hex trace id of the span in ctx, or empty if there is none
+func TraceID(ctx context.Context) string {
+	spanContext := oteltrace.SpanContextFromContext(withRequestSpan(ctx))
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
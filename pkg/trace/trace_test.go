@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	traceconfig "github.com/horizoncd/horizon/pkg/config/trace"
+)
+
+func TestTrace(t *testing.T) {
+	// tracing is disabled without the endpoint
+	tracer, err := Init(traceconfig.Config{})
+	assert.Nil(t, err)
+	assert.Nil(t, tracer)
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	// a backend records the traceparent propagated
+	var propagated string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		propagated = r.Header.Get("traceparent")
+	}))
+	defer backend.Close()
+
+	// the span of the request is looked up from gin.Context
+	requestCtx, request := Start(context.Background(), "GET /apis/core/v2/groups")
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/apis/core/v2/groups", nil).WithContext(requestCtx)
+	assert.Equal(t, request.SpanContext().TraceID().String(), TraceID(c))
+
+	opCtx, op := Start(c, "group: get group")
+	// the spans are derived, the context of the request is not changed
+	_, sibling := Start(c, "group: list groups")
+	sibling.End()
+
+	client := &http.Client{Transport: Transport("gitlab", nil)}
+	req, err := http.NewRequestWithContext(opCtx, http.MethodGet, backend.URL, nil)
+	assert.Nil(t, err)
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	_ = resp.Body.Close()
+	op.End()
+	request.End()
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, request.SpanContext().TraceID(), span.SpanContext().TraceID())
+		byName[span.Name()] = span
+	}
+	assert.Equal(t, 4, len(byName))
+	assert.Equal(t, request.SpanContext().SpanID(), byName["group: get group"].Parent().SpanID())
+	assert.Equal(t, request.SpanContext().SpanID(), byName["group: list groups"].Parent().SpanID())
+	assert.Equal(t, op.SpanContext().SpanID(), byName["gitlab GET"].Parent().SpanID())
+	assert.Equal(t, "00-"+request.SpanContext().TraceID().String()+"-"+
+		byName["gitlab GET"].SpanContext().SpanID().String()+"-01", propagated)
+}
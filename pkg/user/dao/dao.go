@@ -170,7 +170,7 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, newUser *models.User) (*m
 	err := d.db.
 		Transaction(
 			func(tx *gorm.DB) error {
-				res := tx.Where("id = ?", id).Select("admin", "banned").Updates(newUser)
+				res := tx.Where("id = ?", id).Select("admin", "auditor", "banned").Updates(newUser)
 				if res.Error != nil {
 					return perror.Wrapf(herrors.NewErrUpdateFailed(herrors.UserInDB, "failed to update user"),
 						"failed to update user\n"+
@@ -35,7 +35,9 @@ type User struct {
 	OidcID   string `gorm:"column:oidc_id"`
 	OidcType string `gorm:"column:oidc_type"`
 	Admin    bool
-	Banned   bool
+	// Auditor has read-only access to all resources
+	Auditor bool
+	Banned  bool
 }
 
 type UserBasic struct {
@@ -34,6 +34,7 @@ func SetSession(ss *sessions.Session,
 		ID:       user.ID,
 		Email:    user.Email,
 		Admin:    user.Admin,
+		Auditor:  user.Auditor,
 	}
 
 	if err := ss.Save(request, response); err != nil {
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -40,6 +41,20 @@ func CommitMessage(scope string, subject Subject, body interface{}) string {
 	return msg.String()
 }
 
+// ParseCommitMessage parses the message of commits created by CommitMessage,
+// false is returned if the commit is not created by it
+func ParseCommitMessage(commitMessage string) (*Message, bool) {
+	parts := strings.SplitN(commitMessage, "\n\n", 2)
+	if len(parts) != 2 {
+		return nil, false
+	}
+	var msg Message
+	if err := json.Unmarshal([]byte(strings.TrimSpace(parts[1])), &msg); err != nil || msg.Header.Kind == "" {
+		return nil, false
+	}
+	return &msg, true
+}
+
 func (m Message) String() string {
 	data, _ := json.Marshal(m)
 
@@ -16,6 +16,8 @@ package angular
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 type Body struct {
@@ -62,3 +64,21 @@ func TestCommitMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCommitMessage(t *testing.T) {
+	commitMessage := CommitMessage("cluster", Subject{
+		Operator: "alice",
+		Action:   "update cluster",
+		Cluster:  StringPtr("cluster-test-1"),
+	}, Body{Replica: func() *int { i := 1; return &i }()})
+	msg, ok := ParseCommitMessage(commitMessage)
+	assert.True(t, ok)
+	assert.Equal(t, "cluster", msg.Header.Scope)
+	assert.Equal(t, "alice", msg.Header.Subject.Operator)
+	assert.Equal(t, "cluster-test-1", *msg.Header.Subject.Cluster)
+
+	for _, commitMessage := range []string{"", "Initial commit", "fix values\n\nreplicas are changed"} {
+		_, ok := ParseCommitMessage(commitMessage)
+		assert.False(t, ok)
+	}
+}
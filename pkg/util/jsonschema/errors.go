@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v5jsonschema "github.com/santhosh-tekuri/jsonschema/v5"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+)
+
+// FieldError is an invalid field of the document
+type FieldError struct {
+	// Field is the path of the field in json, such as app.params.xmx, it's empty for the document
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError tells the invalid fields of the document,
+// its cause is herrors.ErrParamInvalid, so it's handled the same as the other invalid params
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if f.Field == "" {
+			messages = append(messages, f.Message)
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	return fmt.Sprintf("invalid values: %s", strings.Join(messages, "; "))
+}
+
+// Cause makes perror.Cause returns herrors.ErrParamInvalid
+func (e *ValidationError) Cause() error {
+	return herrors.ErrParamInvalid
+}
+
+// Details returns the invalid fields, which are responded with the error
+func (e *ValidationError) Details() interface{} {
+	return e.Fields
+}
+
+// FieldErrors returns the invalid fields if err is a *ValidationError
+func FieldErrors(err error) []FieldError {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	return nil
+}
+
+// newValidationError flattens the error tree of jsonschema into the fields, only the leaves are kept,
+// as the others just tell the fields containing them are invalid
+func newValidationError(err *v5jsonschema.ValidationError) *ValidationError {
+	validationErr := &ValidationError{}
+	var visit func(e *v5jsonschema.ValidationError)
+	visit = func(e *v5jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			validationErr.Fields = append(validationErr.Fields, FieldError{
+				Field:   fieldPath(e.InstanceLocation),
+				Message: e.Message,
+			})
+			return
+		}
+		for _, cause := range e.Causes {
+			visit(cause)
+		}
+	}
+	visit(err)
+	return validationErr
+}
+
+// fieldPath converts the json pointer, such as /app/params/xmx, into the path of field
+func fieldPath(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return strings.Join(tokens, ".")
+}
@@ -16,6 +16,7 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -30,6 +31,7 @@ var (
 
 // Validate json by jsonschema.
 // schema and document support 2 types: string, map[string]interface{}
+// If the document is invalid, a *ValidationError telling the invalid fields is returned.
 func Validate(schema, document interface{}, setUnevaluatedPropertiesToFalse bool) error {
 	// change schema type to Golang map
 	var schemaMap map[string]interface{}
@@ -77,6 +79,10 @@ func Validate(schema, document interface{}, setUnevaluatedPropertiesToFalse bool
 			fmt.Sprintf("jsonschema compilestring error, schema: %s, error: %s", schemaStr, err.Error()))
 	}
 	if err = sch.Validate(v); err != nil {
+		var validationErr *v5jsonschema.ValidationError
+		if errors.As(err, &validationErr) {
+			return newValidationError(validationErr)
+		}
 		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
 	}
 
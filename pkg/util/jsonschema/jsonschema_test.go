@@ -19,6 +19,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 )
 
 func TestValidate(t *testing.T) {
@@ -849,3 +852,35 @@ func TestDependency(t *testing.T) {
 	err = Validate(schema, document, true)
 	assert.NotNil(t, err)
 }
+
+func TestValidateFieldErrors(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"app": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"replicas": map[string]interface{}{"type": "integer", "minimum": 1},
+					"a/b":      map[string]interface{}{"type": "string"},
+				},
+				"required": []interface{}{"replicas"},
+			},
+		},
+	}
+
+	err := Validate(schema, map[string]interface{}{
+		"app": map[string]interface{}{"replicas": 0.0, "a/b": 1.0},
+	}, false)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	fields := FieldErrors(err)
+	assert.Len(t, fields, 2)
+	paths := []string{fields[0].Field, fields[1].Field}
+	assert.ElementsMatch(t, []string{"app.replicas", "app.a/b"}, paths)
+
+	err = Validate(schema, map[string]interface{}{"app": map[string]interface{}{}}, false)
+	fields = FieldErrors(err)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "app", fields[0].Field)
+
+	assert.Nil(t, FieldErrors(perror.New("not a validation error")))
+}
@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	_uiReadonly = "ui:readonly"
+	_uiWidget   = "ui:widget"
+	_uiPrefix   = "ui:"
+
+	_widgetHidden = "hidden"
+	_default      = "default"
+	_items        = "items"
+)
+
+// ValidateUISchema checks the read-only and hidden fields of the ui schema are not changed in the document.
+// Their values must be the same as the ones in previous, which is the document replaced,
+// or the defaults in schema if there is no previous document. Fields omitted are allowed,
+// as they are filled by the defaults of the template. A *ValidationError is returned if any field is changed.
+func ValidateUISchema(schema, uiSchema, document, previous map[string]interface{}) error {
+	if uiSchema == nil || document == nil {
+		return nil
+	}
+	validationErr := &ValidationError{}
+	var prev interface{}
+	if previous != nil {
+		prev = previous
+	}
+	validateUINode(nil, schema, uiSchema, document, prev, previous != nil, validationErr)
+	if len(validationErr.Fields) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+func validateUINode(path []string, schema, uiSchema map[string]interface{},
+	value, previous interface{}, hasPrevious bool, validationErr *ValidationError) {
+	if locked(uiSchema) {
+		expected, ok := previous, hasPrevious
+		if !hasPrevious {
+			expected, ok = schema[_default]
+		}
+		if ok && equal(expected, value) {
+			return
+		}
+		message := "is read-only and cannot be changed"
+		if !ok {
+			message = "is read-only and cannot be set"
+		}
+		validationErr.Fields = append(validationErr.Fields, FieldError{
+			Field:   strings.Join(path, "."),
+			Message: message,
+		})
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		prevMap, _ := previous.(map[string]interface{})
+		props, _ := schema[properties].(map[string]interface{})
+		for key, child := range uiSchema {
+			if strings.HasPrefix(key, _uiPrefix) || key == _items {
+				continue
+			}
+			childUISchema, ok := child.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			childValue, ok := value[key]
+			if !ok {
+				continue
+			}
+			childSchema, _ := props[key].(map[string]interface{})
+			childPrev, childHasPrev := prevMap[key]
+			validateUINode(append(path[:len(path):len(path)], key), childSchema, childUISchema,
+				childValue, childPrev, hasPrevious && childHasPrev, validationErr)
+		}
+	case []interface{}:
+		itemsUISchema, ok := uiSchema[_items].(map[string]interface{})
+		if !ok {
+			return
+		}
+		itemsSchema, _ := schema[_items].(map[string]interface{})
+		prevSlice, _ := previous.([]interface{})
+		for i, item := range value {
+			var itemPrev interface{}
+			itemHasPrev := hasPrevious && i < len(prevSlice)
+			if itemHasPrev {
+				itemPrev = prevSlice[i]
+			}
+			validateUINode(append(path[:len(path):len(path)], strconv.Itoa(i)), itemsSchema, itemsUISchema,
+				item, itemPrev, itemHasPrev, validationErr)
+		}
+	}
+}
+
+// locked returns true if the field is read-only or hidden in the ui schema
+func locked(uiSchema map[string]interface{}) bool {
+	if readonly, ok := uiSchema[_uiReadonly].(bool); ok && readonly {
+		return true
+	}
+	widget, _ := uiSchema[_uiWidget].(string)
+	return widget == _widgetHidden
+}
+
+// equal compares the values in json, as the numbers decoded from json and yaml are of different types
+func equal(a, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
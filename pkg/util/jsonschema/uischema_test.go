@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUISchema(t *testing.T) {
+	var schema, uiSchema map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{
+  "type": "object",
+  "properties": {
+    "app": {
+      "type": "object",
+      "properties": {
+        "image": {"type": "string"},
+        "replicas": {"type": "integer", "default": 1},
+        "ports": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "name": {"type": "string"},
+              "protocol": {"type": "string", "default": "TCP"}
+            }
+          }
+        }
+      }
+    }
+  }
+}`), &schema))
+	assert.Nil(t, json.Unmarshal([]byte(`{
+  "app": {
+    "image": {"ui:widget": "hidden"},
+    "replicas": {"ui:readonly": true},
+    "ports": {"items": {"protocol": {"ui:readonly": true}}}
+  }
+}`), &uiSchema))
+
+	parse := func(document string) map[string]interface{} {
+		var m map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(document), &m))
+		return m
+	}
+
+	// the defaults are kept or omitted when creating
+	assert.Nil(t, ValidateUISchema(schema, uiSchema,
+		parse(`{"app": {"replicas": 1, "ports": [{"name": "http", "protocol": "TCP"}, {"name": "grpc"}]}}`), nil))
+	err := ValidateUISchema(schema, uiSchema,
+		parse(`{"app": {"image": "nginx", "replicas": 2, "ports": [{"name": "http", "protocol": "UDP"}]}}`), nil)
+	assert.Equal(t, []FieldError{
+		{Field: "app.image", Message: "is read-only and cannot be set"},
+		{Field: "app.ports.0.protocol", Message: "is read-only and cannot be changed"},
+		{Field: "app.replicas", Message: "is read-only and cannot be changed"},
+	}, sortedFieldErrors(FieldErrors(err)))
+
+	// the previous values are kept when updating, and the numbers decoded from yaml equal the ones from json
+	previous := map[string]interface{}{
+		"app": map[string]interface{}{"image": "nginx", "replicas": 2},
+	}
+	assert.Nil(t, ValidateUISchema(schema, uiSchema, parse(`{"app": {"image": "nginx", "replicas": 2}}`), previous))
+	err = ValidateUISchema(schema, uiSchema, parse(`{"app": {"image": "redis", "replicas": 2}}`), previous)
+	assert.Equal(t, []FieldError{{Field: "app.image", Message: "is read-only and cannot be changed"}},
+		FieldErrors(err))
+
+	// nothing is checked without ui schema
+	assert.Nil(t, ValidateUISchema(schema, nil, parse(`{"app": {"image": "redis"}}`), nil))
+}
+
+func sortedFieldErrors(fields []FieldError) []FieldError {
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+	return fields
+}
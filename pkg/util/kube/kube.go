@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 
@@ -143,6 +144,19 @@ func GetPod(ctx context.Context, kubeClientset kubernetes.Interface, namespace,
 	return pod, nil
 }
 
+// StreamPodLogs opens the log stream of the pod, which must be closed by the caller
+func StreamPodLogs(ctx context.Context, kubeClientset kubernetes.Interface, namespace, podName string,
+	options *v1.PodLogOptions) (_ io.ReadCloser, err error) {
+	stream, err := kubeClientset.CoreV1().Pods(namespace).GetLogs(podName, options).Stream(ctx)
+	if err != nil {
+		if kubeerror.IsNotFound(err) {
+			return nil, herrors.NewErrNotFound(herrors.PodsInK8S, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PodLogsInK8S, err.Error())
+	}
+	return stream, nil
+}
+
 func DeletePods(ctx context.Context, kubeClientset kubernetes.Interface, namespace string, pod string) (err error) {
 	err = kubeClientset.CoreV1().Pods(namespace).Delete(ctx, pod, metav1.DeleteOptions{})
 	if err != nil {
@@ -193,50 +207,67 @@ type Client struct {
 
 // BuildClientFromContent build client from k8s kubeconfig content, not file path
 func BuildClientFromContent(kubeconfigContent string) (*rest.Config, *Client, error) {
+	restConfig, err := BuildRestConfigFromContent(kubeconfigContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	restConfig.QPS = K8sClientConfigQPS
+	restConfig.Burst = K8sClientConfigBurst
+	log.Infof(context.Background(), "BuildClientFromContent set kube qps: %v, burst: %v", K8sClientConfigQPS,
+		K8sClientConfigBurst)
+
+	kubeClient, err := NewClient(restConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return restConfig, kubeClient, nil
+}
+
+// BuildRestConfigFromContent build rest config from k8s kubeconfig content,
+// in cluster config is used when the content is empty
+func BuildRestConfigFromContent(kubeconfigContent string) (*rest.Config, error) {
 	var restConfig *rest.Config
 	var err error
 	if len(kubeconfigContent) > 0 {
 		clientConfig, err := clientcmd.NewClientConfigFromBytes([]byte(kubeconfigContent))
 		if err != nil {
-			return nil, nil, herrors.NewErrGetFailed(herrors.KubeConfigInK8S, err.Error())
+			return nil, herrors.NewErrGetFailed(herrors.KubeConfigInK8S, err.Error())
 		}
 		restConfig, err = clientConfig.ClientConfig()
 		if err != nil {
-			return nil, nil, herrors.NewErrGetFailed(herrors.KubeConfigInK8S, err.Error())
+			return nil, herrors.NewErrGetFailed(herrors.KubeConfigInK8S, err.Error())
 		}
 	} else {
 		restConfig, err = rest.InClusterConfig()
 		if err != nil {
-			return nil, nil, herrors.NewErrGetFailed(herrors.KubeConfigInK8S, err.Error())
+			return nil, herrors.NewErrGetFailed(herrors.KubeConfigInK8S, err.Error())
 		}
 	}
+	return restConfig, nil
+}
 
+// NewClient build basic and dynamic clients for the rest config
+func NewClient(restConfig *rest.Config) (*Client, error) {
 	groupVersion := &schema.GroupVersion{Group: "", Version: "v1"}
 	restConfig.GroupVersion = groupVersion
 	restConfig.APIPath = "/api"
 	restConfig.ContentType = runtime.ContentTypeJSON
 	restConfig.NegotiatedSerializer = scheme.Codecs
-	restConfig.QPS = K8sClientConfigQPS
-	restConfig.Burst = K8sClientConfigBurst
-	log.Infof(context.Background(), "BuildClientFromContent set kube qps: %v, burst: %v", K8sClientConfigQPS,
-		K8sClientConfigBurst)
 
 	basicClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
-		return nil, nil, herrors.NewErrCreateFailed(herrors.KubeConfigInK8S, err.Error())
+		return nil, herrors.NewErrCreateFailed(herrors.KubeConfigInK8S, err.Error())
 	}
 
 	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
-		return nil, nil, herrors.NewErrCreateFailed(herrors.KubeConfigInK8S, err.Error())
+		return nil, herrors.NewErrCreateFailed(herrors.KubeConfigInK8S, err.Error())
 	}
 
-	kubeClient := &Client{
+	return &Client{
 		Basic:   basicClient,
 		Dynamic: dynamicClient,
-	}
-
-	return restConfig, kubeClient, nil
+	}, nil
 }
 
 type ContainerRef struct {
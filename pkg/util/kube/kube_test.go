@@ -92,6 +92,18 @@ func TestGetPods(t *testing.T) {
 	assert.Equal(t, 2, len(pods))
 }
 
+func TestStreamPodLogs(t *testing.T) {
+	ctx := log.WithContext(context.Background(), "TestStreamPodLogs")
+	clientset := fakek8s.NewSimpleClientset()
+	stream, err := StreamPodLogs(ctx, clientset, "ns", "alice", &v1core.PodLogOptions{Container: "app"})
+	assert.Nil(t, err)
+	defer stream.Close()
+	logs, err := ioutil.ReadAll(stream)
+	assert.Nil(t, err)
+	// the fake clientset responds the same logs for any pod
+	assert.Equal(t, "fake logs", string(logs))
+}
+
 func TestBuildClient(t *testing.T) {
 	data := `
 apiVersion: v1
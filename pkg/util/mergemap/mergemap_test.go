@@ -104,3 +104,29 @@ func assertMapEqual(t *testing.T, expected, got map[string]interface{}) {
 		return
 	}
 }
+
+func TestCopy(t *testing.T) {
+	assert.Nil(t, Copy(nil))
+
+	m := map[string]interface{}{
+		"app": map[string]interface{}{
+			"replicas": 1,
+			"ports":    []interface{}{map[string]interface{}{"name": "http"}},
+		},
+	}
+	copied := Copy(m)
+	assert.Equal(t, m, copied)
+
+	// merging into the copy does not change the original one
+	_, err := Merge(copied, map[string]interface{}{
+		"app": map[string]interface{}{"replicas": 2},
+	})
+	assert.Nil(t, err)
+	copied["app"].(map[string]interface{})["ports"].([]interface{})[0].(map[string]interface{})["name"] = "grpc"
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{
+			"replicas": 1,
+			"ports":    []interface{}{map[string]interface{}{"name": "http"}},
+		},
+	}, m)
+}
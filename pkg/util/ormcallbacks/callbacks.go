@@ -18,6 +18,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
 )
 
 const (
@@ -56,7 +57,23 @@ func addUpdatedByForUpdateDeleteCallback(db *gorm.DB) {
 	}
 }
 
+// useRequestTxCallback runs the statement in the request transaction if there is one in the context
+func useRequestTxCallback(db *gorm.DB) {
+	tx, ok := orm.TxFromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+	db.Statement.ConnPool = tx.Statement.ConnPool
+}
+
 func RegisterCustomCallbacks(db *gorm.DB) {
+	_ = db.Callback().Create().Before("gorm:begin_transaction").Register("use_request_tx", useRequestTxCallback)
+	_ = db.Callback().Update().Before("gorm:begin_transaction").Register("use_request_tx", useRequestTxCallback)
+	_ = db.Callback().Delete().Before("gorm:begin_transaction").Register("use_request_tx", useRequestTxCallback)
+	_ = db.Callback().Query().Before("gorm:query").Register("use_request_tx", useRequestTxCallback)
+	_ = db.Callback().Row().Before("gorm:row").Register("use_request_tx", useRequestTxCallback)
+	_ = db.Callback().Raw().Before("gorm:raw").Register("use_request_tx", useRequestTxCallback)
+
 	_ = db.Callback().Create().After("gorm:before_create").Before("gorm:create").
 		Register("add_created_by", addCreatedByUpdatedByForCreateCallback)
 
@@ -65,4 +82,6 @@ func RegisterCustomCallbacks(db *gorm.DB) {
 
 	_ = db.Callback().Delete().After("gorm:before_delete").Before("gorm:delete").
 		Register("add_updated_by", addUpdatedByForUpdateDeleteCallback)
+
+	_ = db.Use(tracingPlugin{})
 }
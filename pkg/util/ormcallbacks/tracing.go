@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callbacks
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/trace"
+)
+
+const (
+	_spanKey          = "horizon:trace_span"
+	_parentContextKey = "horizon:trace_parent_context"
+)
+
+// tracingPlugin records a client span for each statement whose context is traced,
+// the statement runs with the context derived with its span, and gets back its context after the span ends
+type tracingPlugin struct{}
+
+var _ gorm.Plugin = tracingPlugin{}
+
+func (tracingPlugin) Name() string {
+	return "horizon:tracing"
+}
+
+func (tracingPlugin) Initialize(db *gorm.DB) error {
+	_ = db.Callback().Create().Before("*").Register("trace_start", startSpanCallback("create"))
+	_ = db.Callback().Create().After("*").Register("trace_end", endSpanCallback)
+	_ = db.Callback().Update().Before("*").Register("trace_start", startSpanCallback("update"))
+	_ = db.Callback().Update().After("*").Register("trace_end", endSpanCallback)
+	_ = db.Callback().Delete().Before("*").Register("trace_start", startSpanCallback("delete"))
+	_ = db.Callback().Delete().After("*").Register("trace_end", endSpanCallback)
+	_ = db.Callback().Query().Before("*").Register("trace_start", startSpanCallback("query"))
+	_ = db.Callback().Query().After("*").Register("trace_end", endSpanCallback)
+	_ = db.Callback().Row().Before("*").Register("trace_start", startSpanCallback("row"))
+	_ = db.Callback().Row().After("*").Register("trace_end", endSpanCallback)
+	_ = db.Callback().Raw().Before("*").Register("trace_start", startSpanCallback("raw"))
+	_ = db.Callback().Raw().After("*").Register("trace_end", endSpanCallback)
+	return nil
+}
+
+// startSpanCallback starts a span of the statement if the context is traced
+func startSpanCallback(op string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := trace.Start(db.Statement.Context, "gorm "+op,
+			oteltrace.WithSpanKind(oteltrace.SpanKindClient))
+		if !span.IsRecording() {
+			return
+		}
+		db.InstanceSet(_spanKey, span)
+		db.InstanceSet(_parentContextKey, db.Statement.Context)
+		db.Statement.Context = ctx
+	}
+}
+
+// endSpanCallback ends the span of the statement with the sql executed
+func endSpanCallback(db *gorm.DB) {
+	v, ok := db.InstanceGet(_spanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(oteltrace.Span)
+	if !ok {
+		return
+	}
+	if parent, ok := db.InstanceGet(_parentContextKey); ok {
+		if ctx, ok := parent.(context.Context); ok {
+			db.Statement.Context = ctx
+		}
+	}
+	span.SetAttributes(
+		attribute.String("db.system", db.Dialector.Name()),
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
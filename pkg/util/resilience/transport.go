@@ -0,0 +1,298 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	resilienceconfig "github.com/horizoncd/horizon/pkg/config/resilience"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	_namespace = "horizon"
+	_subsystem = "http_client"
+
+	_service = "service"
+	_host    = "host"
+
+	_defaultTimeout          = 30 * time.Second
+	_defaultRetryMax         = 3
+	_defaultRetryWaitMin     = 500 * time.Millisecond
+	_defaultRetryWaitMax     = 5 * time.Second
+	_defaultFailureThreshold = 10
+	_defaultOpenDuration     = 30 * time.Second
+)
+
+// states of the circuit breaker, which are the values of the gauge
+const (
+	stateClosed = iota
+	stateOpen
+	stateHalfOpen
+)
+
+var (
+	_retryCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: _subsystem,
+		Name:      "retries_total",
+		Help:      "Requests retried after failures or responses of 5xx and 429",
+	}, []string{_service, _host})
+
+	_rejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: _subsystem,
+		Name:      "circuit_breaker_rejected_total",
+		Help:      "Requests rejected by the open circuit breaker",
+	}, []string{_service, _host})
+
+	_stateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: _namespace,
+		Subsystem: _subsystem,
+		Name:      "circuit_breaker_state",
+		Help:      "State of the circuit breaker, 0 is closed, 1 is open and 2 is half open",
+	}, []string{_service, _host})
+)
+
+type transport struct {
+	config  resilienceconfig.Config
+	base    http.RoundTripper
+	breaker *breaker
+	labels  prometheus.Labels
+}
+
+// Transport sends requests to the host of the service by base, each attempt is bounded by the timeout,
+// and requests failed or responded with 5xx or 429 are retried with exponential backoff.
+// Consecutive failures open the circuit breaker, which fails requests fast until the service recovers.
+func Transport(service, host string, config resilienceconfig.Config, base http.RoundTripper) http.RoundTripper {
+	config = withDefaults(config)
+	labels := prometheus.Labels{_service: service, _host: host}
+	_stateGauge.With(labels).Set(stateClosed)
+	return &transport{
+		config: config,
+		base:   base,
+		breaker: &breaker{
+			threshold:    config.FailureThreshold,
+			openDuration: config.OpenDuration,
+			gauge:        _stateGauge.With(labels),
+		},
+		labels: labels,
+	}
+}
+
+func withDefaults(config resilienceconfig.Config) resilienceconfig.Config {
+	if config.Timeout <= 0 {
+		config.Timeout = _defaultTimeout
+	}
+	if config.RetryMax == 0 {
+		config.RetryMax = _defaultRetryMax
+	}
+	if config.RetryWaitMin <= 0 {
+		config.RetryWaitMin = _defaultRetryWaitMin
+	}
+	if config.RetryWaitMax <= 0 {
+		config.RetryWaitMax = _defaultRetryWaitMax
+	}
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = _defaultFailureThreshold
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = _defaultOpenDuration
+	}
+	return config
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the body is read in advance to be sent again by retries
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !t.breaker.allow() {
+			_rejectedCounter.With(t.labels).Inc()
+			return nil, ErrCircuitOpen
+		}
+		resp, err := t.send(req, body)
+		// failures caused by the caller, like the cancellation of the request, are not the service's
+		if err != nil && req.Context().Err() != nil {
+			t.breaker.release()
+			return nil, err
+		}
+		failed := err != nil || resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= http.StatusInternalServerError
+		t.breaker.done(!failed)
+		if !failed || attempt >= t.config.RetryMax {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		_retryCounter.With(t.labels).Inc()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// send sends an attempt of the request, the timeout covers reading the body of the response
+func (t *transport) send(req *http.Request, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.config.Timeout)
+	r := req.Clone(ctx)
+	if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff doubles the wait with each retry, or follows Retry-After in seconds, bounded by RetryWaitMax
+func (t *transport) backoff(attempt int, resp *http.Response) time.Duration {
+	wait := t.config.RetryWaitMin << uint(attempt)
+	// the shift overflows if there are too many retries
+	if wait <= 0 || wait > t.config.RetryWaitMax {
+		wait = t.config.RetryWaitMax
+	}
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+			if wait > t.config.RetryWaitMax {
+				wait = t.config.RetryWaitMax
+			}
+		}
+	}
+	return wait
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// breaker opens after consecutive failures, and lets a request through to probe after the open duration,
+// it closes if the probe succeeds, or opens again otherwise
+type breaker struct {
+	threshold    int
+	openDuration time.Duration
+	gauge        prometheus.Gauge
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *breaker) allow() bool {
+	if b.threshold < 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.openDuration {
+			return false
+		}
+		b.setState(stateHalfOpen)
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) done(success bool) {
+	if b.threshold < 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.setState(stateClosed)
+		return
+	}
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(stateOpen)
+	}
+}
+
+// release ends the attempt without its result, so that another request can probe
+func (b *breaker) release() {
+	if b.threshold < 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) setState(state int) {
+	b.state = state
+	b.gauge.Set(float64(state))
+}
@@ -0,0 +1,243 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package valuediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+
+	// MaskedValue replaces values of sensitive keys
+	MaskedValue = "******"
+)
+
+// sensitiveWords are words which mark a key as sensitive, its value is masked in diff
+var sensitiveWords = []string{"password", "passwd", "secret", "token", "credential", "privatekey", "accesskey"}
+
+// Change is the change of a leaf value, Path is like app.envs[JAVA_OPTS].value
+type Change struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Masked bool   `json:"masked,omitempty"`
+}
+
+// Compare compares leaf values of two json blobs and returns changes sorted by path.
+// Items of a list which all have a name, such as environment variables, are identified by name instead of index.
+// Values of sensitive keys, or of named items marked with "secret": true on either side, are masked.
+func Compare(from, to interface{}) []*Change {
+	fromValues, toValues := map[string]string{}, map[string]string{}
+	secrets := map[string]bool{}
+	flatten("", from, fromValues, secrets, false)
+	flatten("", to, toValues, secrets, false)
+
+	changes := make([]*Change, 0)
+	for path, fromValue := range fromValues {
+		toValue, ok := toValues[path]
+		if !ok {
+			changes = append(changes, &Change{Path: path, Type: ChangeRemoved, From: fromValue})
+		} else if toValue != fromValue {
+			changes = append(changes, &Change{Path: path, Type: ChangeModified, From: fromValue, To: toValue})
+		}
+	}
+	for path, toValue := range toValues {
+		if _, ok := fromValues[path]; !ok {
+			changes = append(changes, &Change{Path: path, Type: ChangeAdded, To: toValue})
+		}
+	}
+
+	for _, change := range changes {
+		if secrets[change.Path] || isSensitive(change.Path) {
+			change.Masked = true
+			if change.From != "" {
+				change.From = MaskedValue
+			}
+			if change.To != "" {
+				change.To = MaskedValue
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// flatten collects leaf values by path, paths of leaves under a secret item are collected in secrets
+func flatten(prefix string, value interface{}, values map[string]string, secrets map[string]bool, secret bool) {
+	if secret && prefix != "" {
+		secrets[prefix] = true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flatten(path, item, values, secrets, secret)
+		}
+	case []interface{}:
+		names, ok := itemNames(v)
+		for i, item := range v {
+			if !ok {
+				flatten(fmt.Sprintf("%s[%d]", prefix, i), item, values, secrets, secret)
+				continue
+			}
+			// the name identifies the item, so only the other fields are compared
+			path := fmt.Sprintf("%s[%s]", prefix, names[i])
+			fields := make(map[string]interface{})
+			itemSecret := secret || isSecretItem(item)
+			for key, field := range item.(map[string]interface{}) {
+				if key != "name" {
+					fields[key] = field
+				}
+			}
+			if len(fields) == 0 {
+				values[path] = names[i]
+				continue
+			}
+			for key, field := range fields {
+				flatten(path+"."+key, field, values, secrets, itemSecret)
+			}
+		}
+	case nil:
+		if prefix != "" {
+			values[prefix] = "null"
+		}
+	case string:
+		values[prefix] = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			values[prefix] = fmt.Sprintf("%v", v)
+			return
+		}
+		values[prefix] = string(b)
+	}
+}
+
+// itemNames returns names of items if every item is an object with a unique name
+func itemNames(items []interface{}) ([]string, bool) {
+	names := make([]string, 0, len(items))
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok || name == "" {
+			return nil, false
+		}
+		if _, ok := seen[name]; ok {
+			return nil, false
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names, len(names) > 0
+}
+
+// isSecretItem tells whether a named item is marked as secret, such as an environment variable
+func isSecretItem(item interface{}) bool {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	secret, _ := m["secret"].(bool)
+	return secret
+}
+
+func isSensitive(path string) bool {
+	path = strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(path))
+	for _, word := range sensitiveWords {
+		if strings.Contains(path, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretValues returns the leaf values of sensitive keys and of named items marked as secret in the json blobs
+func SecretValues(blobs ...interface{}) map[string]bool {
+	secretValues := make(map[string]bool)
+	for _, blob := range blobs {
+		values, secrets := map[string]string{}, map[string]bool{}
+		flatten("", blob, values, secrets, false)
+		for path, value := range values {
+			// booleans such as the secret mark of items are not secrets
+			switch value {
+			case "", "null", "true", "false":
+				continue
+			}
+			if secrets[path] || isSensitive(path) {
+				secretValues[value] = true
+			}
+		}
+	}
+	return secretValues
+}
+
+// MaskDiff masks values in a unified diff of yaml or json files,
+// the value of a line is masked if its key is sensitive or it's one of secretValues
+func MaskDiff(diff string, secretValues map[string]bool) string {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		lines[i] = maskDiffLine(line, secretValues)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maskDiffLine masks the value of a diff line like `+  key: value`, `-  - value` or ` "key": "value",`
+func maskDiffLine(line string, secretValues map[string]bool) string {
+	if line == "" || strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ") {
+		return line
+	}
+	switch line[0] {
+	case '+', '-', ' ':
+	default:
+		return line
+	}
+	content := strings.TrimLeft(line[1:], " ")
+	content = strings.TrimPrefix(content, "- ")
+	start := len(line) - len(content)
+
+	if idx := strings.Index(content, ":"); idx > 0 && !strings.Contains(content[:idx], " ") {
+		key := strings.Trim(content[:idx], `"'`)
+		value := unquote(content[idx+1:])
+		if value != "" && (isSensitive(key) || secretValues[value]) {
+			return line[:start+idx+1] + " " + MaskedValue
+		}
+		return line
+	}
+	if secretValues[unquote(content)] {
+		return line[:start] + MaskedValue
+	}
+	return line
+}
+
+func unquote(value string) string {
+	value = strings.TrimSuffix(strings.TrimSpace(value), ",")
+	return strings.Trim(value, `"'`)
+}
@@ -0,0 +1,143 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package valuediff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	from := map[string]interface{}{
+		"app": map[string]interface{}{
+			"envs": []interface{}{
+				map[string]interface{}{"name": "JAVA_OPTS", "value": "-Xmx1g"},
+				map[string]interface{}{"name": "DB_PASSWORD", "value": "old"},
+				map[string]interface{}{"name": "REMOVED", "value": "1"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": float64(1),
+				"ports":    []interface{}{float64(8080)},
+			},
+		},
+	}
+	to := map[string]interface{}{
+		"app": map[string]interface{}{
+			"envs": []interface{}{
+				map[string]interface{}{"name": "DB_PASSWORD", "value": "new"},
+				map[string]interface{}{"name": "JAVA_OPTS", "value": "-Xmx2g"},
+				map[string]interface{}{"name": "ADDED", "value": "2"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": float64(2),
+				"ports":    []interface{}{float64(8080)},
+			},
+		},
+	}
+
+	changes := Compare(from, to)
+	assert.Equal(t, []*Change{
+		{Path: "app.envs[ADDED].value", Type: ChangeAdded, To: "2"},
+		{Path: "app.envs[DB_PASSWORD].value", Type: ChangeModified, From: MaskedValue, To: MaskedValue, Masked: true},
+		{Path: "app.envs[JAVA_OPTS].value", Type: ChangeModified, From: "-Xmx1g", To: "-Xmx2g"},
+		{Path: "app.envs[REMOVED].value", Type: ChangeRemoved, From: "1"},
+		{Path: "app.spec.replicas", Type: ChangeModified, From: "1", To: "2"},
+	}, changes)
+
+	assert.Equal(t, 0, len(Compare(from, from)))
+	assert.Equal(t, 1, len(Compare(nil, map[string]interface{}{"token": "abc"})))
+	assert.True(t, Compare(nil, map[string]interface{}{"token": "abc"})[0].Masked)
+
+	// variables marked as secret are masked even if the name is not sensitive
+	secretFrom := map[string]interface{}{
+		"envs": []interface{}{
+			map[string]interface{}{"name": "DSN", "value": "a", "secret": true},
+		},
+	}
+	secretTo := map[string]interface{}{
+		"envs": []interface{}{
+			map[string]interface{}{"name": "DSN", "value": "b"},
+		},
+	}
+	assert.Equal(t, []*Change{
+		{Path: "envs[DSN].secret", Type: ChangeRemoved, From: MaskedValue, Masked: true},
+		{Path: "envs[DSN].value", Type: ChangeModified, From: MaskedValue, To: MaskedValue, Masked: true},
+	}, Compare(secretFrom, secretTo))
+}
+
+func TestMaskDiff(t *testing.T) {
+	from := map[string]interface{}{
+		"application.yaml": map[string]interface{}{
+			"app": map[string]interface{}{
+				"envs": []interface{}{
+					map[string]interface{}{"name": "API_KEY", "value": "old-key", "secret": true},
+					map[string]interface{}{"name": "JAVA_OPTS", "value": "-Xmx1g"},
+				},
+				"secrets": map[string]interface{}{"db": "old-db"},
+			},
+		},
+	}
+	to := map[string]interface{}{
+		"application.yaml": map[string]interface{}{
+			"app": map[string]interface{}{
+				"envs": []interface{}{
+					map[string]interface{}{"name": "API_KEY", "value": "new-key", "secret": true},
+					map[string]interface{}{"name": "JAVA_OPTS", "value": "-Xmx2g"},
+				},
+				"secrets": map[string]interface{}{"db": "new-db"},
+			},
+		},
+	}
+	diff := `--- a/application.yaml
++++ b/application.yaml
+@@ -1,10 +1,10 @@
+ app:
+   envs:
+   - name: API_KEY
+-    value: old-key
++    value: "new-key"
+     secret: true
+   - name: JAVA_OPTS
+-    value: -Xmx1g
++    value: -Xmx2g
+   secrets:
+-    db: old-db
++    db: new-db
+   password: unchanged
+ ---
+   "token": "abc",
+   - new-db`
+	expected := `--- a/application.yaml
++++ b/application.yaml
+@@ -1,10 +1,10 @@
+ app:
+   envs:
+   - name: API_KEY
+-    value: ******
++    value: ******
+     secret: ******
+   - name: JAVA_OPTS
+-    value: -Xmx1g
++    value: -Xmx2g
+   secrets:
+-    db: ******
++    db: ******
+   password: ******
+ ---
+   "token": ******
+   - ******`
+	assert.Equal(t, expected, MaskDiff(diff, SecretValues(from, to)))
+}
@@ -16,9 +16,14 @@ package wlog
 
 import (
 	"context"
+	"fmt"
 	"runtime/debug"
 	"time"
 
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/horizoncd/horizon/pkg/trace"
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
@@ -26,14 +31,26 @@ type Log struct {
 	ctx   context.Context
 	start time.Time
 	op    string
+	span  oteltrace.Span
 }
 
+// Start starts a span of op as well if the request of ctx is traced,
+// the spans started with the context returned by Context are the children of it
 func Start(ctx context.Context, op string) Log {
-	return Log{op: op, ctx: ctx, start: time.Now()}
+	spanCtx, span := trace.Start(ctx, op)
+	return Log{op: op, ctx: spanCtx, start: time.Now(), span: span}
+}
+
+// Context returns the context derived with the span of op, it carries the values of the context started with
+func (l Log) Context() context.Context {
+	return l.ctx
 }
 
 func (l Log) StopPrint() {
+	defer l.span.End()
 	if err := recover(); err != nil {
+		l.span.RecordError(fmt.Errorf("panic: %v", err))
+		l.span.SetStatus(codes.Error, "panic")
 		log.Error(l.ctx, string(debug.Stack()))
 	}
 	duration := time.Since(l.start)
@@ -17,6 +17,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -41,6 +42,7 @@ type DAO interface {
 		resources map[string][]uint) ([]*models.WebhookLogWithEventInfo, int64, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	ListWebhookLogsByMap(ctx context.Context,
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
@@ -191,6 +193,8 @@ func (d *dao) ListWebhookLogs(ctx context.Context, query *q.Query,
 				stm = stm.Where("l.webhook_id = ?", v)
 			case common.EventType:
 				stm = stm.Where("e.event_type = ?", v)
+			case common.Status:
+				stm = stm.Where("l.status = ?", v)
 			case common.Offset:
 				if offset, ok := v.(int); ok {
 					stm = stm.Offset(offset)
@@ -268,10 +272,23 @@ func (d *dao) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return ws, nil
 }
 
+// ListWebhookLogsToSend lists the logs waiting to be sent and the logs to retry by now
+func (d *dao) ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error) {
+	var ws []*models.WebhookLog
+	if result := d.db.WithContext(ctx).Where("webhook_id = ?", wID).
+		Where("status = ? or (status = ? and next_retry_at <= ?)",
+			models.StatusWaiting, models.StatusRetrying, now).
+		Order("id asc").
+		Find(&ws); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return ws, nil
+}
+
 func (d *dao) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", wl.ID).
 		Select("status", "response_headers", "response_body",
-			"status", "error_message").
+			"status", "error_message", "attempts", "next_retry_at").
 		Updates(wl); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
 	}
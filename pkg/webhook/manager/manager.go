@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -41,6 +42,8 @@ type Manager interface {
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	// ListWebhookLogsToSend lists the logs waiting to be sent and the logs to retry by now
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
 	GetWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
@@ -130,6 +133,11 @@ func (m *manager) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return m.dao.ListWebhookLogsByStatus(ctx, wID, status)
 }
 
+func (m *manager) ListWebhookLogsToSend(ctx context.Context, wID uint,
+	now time.Time) ([]*models.WebhookLog, error) {
+	return m.dao.ListWebhookLogsToSend(ctx, wID, now)
+}
+
 func (m *manager) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	const op = "webhook manager: update  webhook log"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -18,8 +18,12 @@ import "time"
 
 const (
 	StatusWaiting = "waiting"
-	StatusSuccess = "success"
-	StatusFailed  = "failed"
+	// StatusRetrying means the last attempt failed temporarily, the delivery is sent again at NextRetryAt
+	StatusRetrying = "retrying"
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	// StatusDeadLetter means the delivery still failed after all the retries, it's only sent again by resending
+	StatusDeadLetter = "deadletter"
 )
 
 type Webhook struct {
@@ -49,6 +53,8 @@ type WebhookLog struct {
 	ResponseBody    string
 	Status          string
 	ErrorMessage    string
+	Attempts        uint
+	NextRetryAt     *time.Time
 	CreatedAt       time.Time
 	CreatedBy       uint
 	UpdatedAt       time.Time
@@ -39,11 +39,15 @@ import (
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 	"github.com/horizoncd/horizon/pkg/webhook/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
 )
 
 type worker struct {
 	idleWaitInterval         uint
 	responseBodyTruncateSize uint
+	maxRetries               uint
+	retryBackoff             time.Duration
+	maxRetryBackoff          time.Duration
 
 	ctx            context.Context
 	insecureClient http.Client
@@ -131,8 +135,7 @@ func (s *service) reconcileWorkers() {
 		} else {
 			// 2.2 create workers
 			s.workers[id] = newWebhookWorker(s.webhookManager, s.eventManager,
-				s.userManager, webhook, s.config.IdleWaitInterval,
-				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize)
+				s.userManager, webhook, s.config)
 		}
 		reconciled[id] = true
 	}
@@ -182,15 +185,17 @@ func (s *service) StopAndWait() {
 
 func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	eventMgr eventmanager.Manager, userMgr usermanager.Manager,
-	webhook *models.Webhook, idleWaitInterval uint,
-	clientTimeout, responseBodyTruncateSize uint) *worker {
+	webhook *models.Webhook, config webhookconfig.Config) *worker {
 	ww := &worker{
-		idleWaitInterval:         idleWaitInterval,
-		responseBodyTruncateSize: responseBodyTruncateSize,
+		idleWaitInterval:         config.IdleWaitInterval,
+		responseBodyTruncateSize: config.ResponseBodyTruncateSize,
+		maxRetries:               config.MaxRetries,
+		retryBackoff:             time.Second * time.Duration(config.RetryBackoff),
+		maxRetryBackoff:          time.Second * time.Duration(config.MaxRetryBackoff),
 		ctx:                      context.Background(),
 		quit:                     make(chan bool, 1),
 		insecureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
+			Timeout: time.Second * time.Duration(config.ClientTimeout),
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: true,
@@ -198,7 +203,7 @@ func newWebhookWorker(webhookMgr webhookmanager.Manager,
 			},
 		},
 		secureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
+			Timeout: time.Second * time.Duration(config.ClientTimeout),
 		},
 		webhookManager: webhookMgr,
 		eventManager:   eventMgr,
@@ -209,20 +214,21 @@ func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	return ww
 }
 
-func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models.WebhookLog {
+// sendWebhook sends the webhook log, and tells whether the failure is temporary and worth retrying
+func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) (_ *models.WebhookLog, retryable bool) {
 	// 1. make request and set body
 	reqBody, err := addWebhookLogID([]byte(wl.RequestData), wl.ID)
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to add id, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, false
 	}
 	req, err := http.NewRequest(http.MethodPost, wl.URL,
 		bytes.NewBuffer(reqBody))
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to new request, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, false
 	}
 
 	// 2. set headers
@@ -230,19 +236,22 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 	if err := yaml.Unmarshal([]byte(wl.RequestHeaders), &headers); err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to unmarshal header, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, false
 	}
 	req.Header = headers
-
-	// 3. send request
-	cli := w.secureClient
 	webhook, err := w.getWebhook()
 	if err != nil {
 		log.Error(ctx, err)
 		wl.ErrorMessage = err.Error()
-		return wl
+		return wl, false
+	}
+	// sign the final body at sending, so that receivers can verify it and reject replays
+	if webhook.Secret != "" {
+		signature.SetHeaders(req.Header, webhook.Secret, reqBody, time.Now())
 	}
 
+	// 3. send request
+	cli := w.secureClient
 	if !webhook.SSLVerifyEnabled {
 		cli = w.insecureClient
 	}
@@ -250,7 +259,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to send req, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, true
 	}
 
 	// 4. update response body
@@ -261,7 +270,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		wl.ErrorMessage = fmt.Sprintf("failed to read response body, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
 		resp.Body.Close()
-		return wl
+		return wl, true
 	}
 	wl.ResponseBody = string(respBody)
 
@@ -271,14 +280,15 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		wl.ErrorMessage = fmt.Sprintf("failed to marshal, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
 		resp.Body.Close()
-		return wl
+		return wl, false
 	}
 	if resp.StatusCode >= http.StatusBadRequest || resp.StatusCode < http.StatusOK {
 		wl.ErrorMessage = fmt.Sprintf("unexpected response code: %d", resp.StatusCode)
+		retryable = retryableStatusCode(resp.StatusCode)
 	}
 	wl.ResponseHeaders = string(respHeader)
 	resp.Body.Close()
-	return wl
+	return wl, retryable
 }
 
 // start webhook worker and begin to send process webhook logs
@@ -303,8 +313,7 @@ L:
 				log.Error(ctx, err)
 				continue
 			}
-			wls, err := w.webhookManager.ListWebhookLogsByStatus(ctx, webhook.ID,
-				webhookmodels.StatusWaiting)
+			wls, err := w.webhookManager.ListWebhookLogsToSend(ctx, webhook.ID, time.Now())
 			if err != nil {
 				log.Errorf(ctx, "failed to list webhook logs of %d, error: %s", webhook.ID, err.Error())
 				continue
@@ -314,11 +323,22 @@ L:
 				continue
 			}
 			for _, wl := range wls {
-				saveResult := func() {
-					if wl.ErrorMessage != "" {
-						wl.Status = webhookmodels.StatusFailed
-					} else {
+				saveResult := func(retryable bool) {
+					wl.Attempts++
+					wl.NextRetryAt = nil
+					switch {
+					case wl.ErrorMessage == "":
 						wl.Status = webhookmodels.StatusSuccess
+					case !retryable:
+						wl.Status = webhookmodels.StatusFailed
+					case wl.Attempts <= w.maxRetries:
+						wl.Status = webhookmodels.StatusRetrying
+						nextRetryAt := time.Now().Add(backoff(w.retryBackoff, w.maxRetryBackoff, wl.Attempts))
+						wl.NextRetryAt = &nextRetryAt
+					default:
+						wl.Status = webhookmodels.StatusDeadLetter
+						log.Warningf(ctx, "webhook log %d is dead after %d attempts, error: %s",
+							wl.ID, wl.Attempts, wl.ErrorMessage)
 					}
 					_, err := w.webhookManager.UpdateWebhookLog(ctx, wl)
 					if err != nil {
@@ -326,8 +346,9 @@ L:
 					}
 				}
 
-				wl = w.sendWebhook(ctx, wl)
-				saveResult()
+				var retryable bool
+				wl, retryable = w.sendWebhook(ctx, wl)
+				saveResult(retryable)
 			}
 		}
 	}
@@ -347,6 +368,27 @@ func (w *worker) Wait() {
 	log.Infof(w.ctx, "webhook worker %d stopped", webhook.ID)
 }
 
+// retryableStatusCode tells whether the receiver may accept the delivery later
+func retryableStatusCode(code int) bool {
+	return code >= http.StatusInternalServerError ||
+		code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// backoff is the interval before the retry after the attempts, it's doubled for each attempt up to max
+func backoff(base, max time.Duration, attempts uint) time.Duration {
+	interval := base
+	for i := uint(1); i < attempts; i++ {
+		interval *= 2
+		if interval >= max {
+			return max
+		}
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
+
 func addWebhookLogID(reqData []byte, id uint) ([]byte, error) {
 	var content wlgenerator.MessageContent
 	err := json.Unmarshal([]byte(reqData), &content)
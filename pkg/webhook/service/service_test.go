@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	webhookconfig "github.com/horizoncd/horizon/pkg/config/webhook"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+func TestBackoff(t *testing.T) {
+	base, max := 10*time.Second, time.Minute
+	assert.Equal(t, 10*time.Second, backoff(base, max, 1))
+	assert.Equal(t, 20*time.Second, backoff(base, max, 2))
+	assert.Equal(t, 40*time.Second, backoff(base, max, 3))
+	assert.Equal(t, time.Minute, backoff(base, max, 4))
+	assert.Equal(t, time.Minute, backoff(base, max, 100))
+}
+
+func TestRetryableStatusCode(t *testing.T) {
+	assert.True(t, retryableStatusCode(http.StatusBadGateway))
+	assert.True(t, retryableStatusCode(http.StatusTooManyRequests))
+	assert.True(t, retryableStatusCode(http.StatusRequestTimeout))
+	assert.False(t, retryableStatusCode(http.StatusBadRequest))
+	assert.False(t, retryableStatusCode(http.StatusNotFound))
+}
+
+func TestWorkerRetry(t *testing.T) {
+	ctx := context.Background()
+	// the worker shares the database in another goroutine
+	db, err := orm.NewSqliteDB(filepath.Join(t.TempDir(), "webhook.db"))
+	assert.Nil(t, err)
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	assert.Nil(t, db.AutoMigrate(&models.Webhook{}, &models.WebhookLog{}))
+	manager := managerparam.InitManager(db)
+
+	var requests int32
+	// responds the status code in query
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		code, _ := strconv.Atoi(r.URL.Query().Get("code"))
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	webhook, err := manager.WebhookMgr.CreateWebhook(ctx, &models.Webhook{
+		Enabled: true,
+		URL:     server.URL,
+	})
+	assert.Nil(t, err)
+	newLog := func(url string) *models.WebhookLog {
+		wl, err := manager.WebhookMgr.CreateWebhookLog(ctx, &models.WebhookLog{
+			WebhookID:   webhook.ID,
+			URL:         url,
+			RequestData: "{}",
+			Status:      models.StatusWaiting,
+		})
+		assert.Nil(t, err)
+		return wl
+	}
+	rejected := newLog(server.URL + "?code=400")
+	unavailable := newLog(server.URL + "?code=502")
+
+	w := newWebhookWorker(manager.WebhookMgr, manager.EventMgr, manager.UserMgr, webhook, webhookconfig.Config{
+		ClientTimeout:            1,
+		IdleWaitInterval:         1,
+		ResponseBodyTruncateSize: 1024,
+		MaxRetries:               1,
+		RetryBackoff:             1,
+		MaxRetryBackoff:          1,
+	})
+	defer w.Stop().Wait()
+
+	waitStatus := func(id uint, status string) *models.WebhookLog {
+		var wl *models.WebhookLog
+		assert.Eventually(t, func() bool {
+			wl, err = manager.WebhookMgr.GetWebhookLog(ctx, id)
+			return err == nil && wl.Status == status
+		}, 5*time.Second, 100*time.Millisecond)
+		return wl
+	}
+
+	// rejected deliveries are not retried
+	wl := waitStatus(rejected.ID, models.StatusFailed)
+	assert.Equal(t, uint(1), wl.Attempts)
+	assert.Nil(t, wl.NextRetryAt)
+
+	// the delivery unavailable is retried once, and recorded as a dead letter
+	wl = waitStatus(unavailable.ID, models.StatusDeadLetter)
+	assert.Equal(t, uint(2), wl.Attempts)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,143 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs webhook deliveries and verifies them on the receiver side.
+//
+// A delivery carries the unix timestamp of sending and the hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>" keyed by the secret of the webhook. Receivers can import this package
+// and call Verifier.Verify, which rejects forged or tampered deliveries, deliveries sent out of
+// the replay window and deliveries which have been verified before.
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Horizon-Webhook-Signature"
+	HeaderTimestamp = "X-Horizon-Webhook-Timestamp"
+
+	// SignaturePrefix tells the algorithm of the signature
+	SignaturePrefix = "sha256="
+
+	// DefaultTolerance is the max difference between the sending time and the receiving time
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrMissingHeader     = errors.New("webhook signature or timestamp header is missing")
+	ErrInvalidTimestamp  = errors.New("webhook timestamp is invalid")
+	ErrTimestampExpired  = errors.New("webhook timestamp is out of the replay window")
+	ErrSignatureMismatch = errors.New("webhook signature does not match")
+	ErrReplayed          = errors.New("webhook delivery has been received already")
+)
+
+// Sign computes the signature of body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders signs body sent at now and sets the signature and timestamp headers
+func SetHeaders(header http.Header, secret string, body []byte, now time.Time) {
+	timestamp := now.Unix()
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+}
+
+// Verifier verifies deliveries of a webhook, it is safe for concurrent use
+type Verifier struct {
+	secret    string
+	tolerance time.Duration
+	now       func() time.Time
+
+	lock sync.Mutex
+	// seen records signatures verified in the replay window, keyed by signature with their timestamps
+	seen map[string]time.Time
+}
+
+// NewVerifier creates a verifier of the webhook secret,
+// tolerance is the replay window, DefaultTolerance is used if it's not positive
+func NewVerifier(secret string, tolerance time.Duration) *Verifier {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	return &Verifier{
+		secret:    secret,
+		tolerance: tolerance,
+		now:       time.Now,
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// Verify checks the signature and timestamp in header against body
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	signature, timestampStr := strings.ToLower(header.Get(HeaderSignature)), header.Get(HeaderTimestamp)
+	if signature == "" || timestampStr == "" {
+		return ErrMissingHeader
+	}
+	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTimestamp, timestampStr)
+	}
+	sentAt, now := time.Unix(timestamp, 0), v.now()
+	if sentAt.Before(now.Add(-v.tolerance)) || sentAt.After(now.Add(v.tolerance)) {
+		return ErrTimestampExpired
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(v.secret, timestamp, body))) {
+		return ErrSignatureMismatch
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	// deliveries older than the window are rejected by the timestamp check, forget them
+	for s, t := range v.seen {
+		if t.Before(now.Add(-v.tolerance)) {
+			delete(v.seen, s)
+		}
+	}
+	if _, ok := v.seen[signature]; ok {
+		return ErrReplayed
+	}
+	v.seen[signature] = sentAt
+	return nil
+}
+
+// VerifyRequest verifies a delivery request, the body is read and can be read again afterwards
+func (v *Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := v.Verify(r.Header, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	s := Sign("secret", 1700000000, body)
+	assert.Equal(t, s, Sign("secret", 1700000000, body))
+	assert.Equal(t, SignaturePrefix, s[:len(SignaturePrefix)])
+	assert.NotEqual(t, s, Sign("another-secret", 1700000000, body))
+	assert.NotEqual(t, s, Sign("secret", 1700000001, body))
+	assert.NotEqual(t, s, Sign("secret", 1700000000, []byte(`{"id":2}`)))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	v := NewVerifier("secret", time.Minute)
+	v.now = func() time.Time { return now }
+
+	signed := func(secret string, sentAt time.Time) http.Header {
+		header := http.Header{}
+		SetHeaders(header, secret, body, sentAt)
+		return header
+	}
+
+	// case 1: headers are missing
+	assert.Equal(t, ErrMissingHeader, v.Verify(http.Header{}, body))
+	header := signed("secret", now)
+	header.Set(HeaderTimestamp, "yesterday")
+	assert.True(t, errors.Is(v.Verify(header, body), ErrInvalidTimestamp))
+
+	// case 2: out of the replay window
+	assert.Equal(t, ErrTimestampExpired, v.Verify(signed("secret", now.Add(-2*time.Minute)), body))
+	assert.Equal(t, ErrTimestampExpired, v.Verify(signed("secret", now.Add(2*time.Minute)), body))
+
+	// case 3: forged or tampered
+	assert.Equal(t, ErrSignatureMismatch, v.Verify(signed("wrong-secret", now), body))
+	assert.Equal(t, ErrSignatureMismatch, v.Verify(signed("secret", now), []byte(`{"id":2}`)))
+	header = signed("secret", now)
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix()-1, 10))
+	assert.Equal(t, ErrSignatureMismatch, v.Verify(header, body))
+
+	// case 4: ok, and replaying it is rejected
+	header = signed("secret", now.Add(-30*time.Second))
+	assert.Nil(t, v.Verify(header, body))
+	assert.Equal(t, ErrReplayed, v.Verify(header, body))
+	assert.Nil(t, v.Verify(signed("secret", now), body))
+
+	// case 5: verified deliveries are forgotten once they are out of the window
+	now = now.Add(time.Minute)
+	assert.Equal(t, ErrTimestampExpired, v.Verify(header, body))
+	assert.Nil(t, v.Verify(signed("secret", now), body))
+	assert.Equal(t, 2, len(v.seen))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	v := NewVerifier("secret", 0)
+	assert.Equal(t, DefaultTolerance, v.tolerance)
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/webhook", bytes.NewReader(body))
+	assert.Nil(t, err)
+	SetHeaders(req.Header, "secret", body, time.Now())
+	got, err := v.VerifyRequest(req)
+	assert.Nil(t, err)
+	assert.Equal(t, body, got)
+	// the body can be read again
+	got, err = ioutil.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, body, got)
+}
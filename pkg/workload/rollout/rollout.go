@@ -56,6 +56,11 @@ var (
 	}
 )
 
+const (
+	_strategyCanary    = "canary"
+	_strategyBlueGreen = "blueGreen"
+)
+
 func init() {
 	workload.Register(ability, GVRRollout, GVRReplicaSet, GVRPod)
 }
@@ -215,12 +220,26 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 		replicasTotal = int(*instance.Spec.Replicas)
 	}
 
+	strategy := _strategyCanary
+	if instance.Spec.Strategy.BlueGreen != nil {
+		strategy = _strategyBlueGreen
+	}
+	// the fields are read from unstructured, since they are not all in the rollout type of this version
+	phase, _, _ := unstructured.NestedString(un.Object, "status", "phase")
+	aborted, _, _ := unstructured.NestedBool(un.Object, "status", "abort")
+	message, _, _ := unstructured.NestedString(un.Object, "status", "message")
+
 	if instance.Spec.Strategy.Canary == nil ||
 		len(instance.Spec.Strategy.Canary.Steps) == 0 {
 		return &workload.Step{
-			Index:    0,
-			Total:    1,
-			Replicas: []int{replicasTotal},
+			Index:        0,
+			Total:        1,
+			Replicas:     []int{replicasTotal},
+			ManualPaused: instance.Spec.Paused,
+			Strategy:     strategy,
+			Phase:        phase,
+			Aborted:      aborted,
+			Message:      message,
 		}, nil
 	}
 
@@ -258,7 +277,11 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 		return nil, err
 	}
 
-	bts, err := json.Marshal(map[string]interface{}{"currentIndex": *instance.Status.CurrentStepIndex})
+	currentIndex := int32(0)
+	if instance.Status.CurrentStepIndex != nil {
+		currentIndex = *instance.Status.CurrentStepIndex
+	}
+	bts, err := json.Marshal(map[string]interface{}{"currentIndex": currentIndex})
 	if err != nil {
 		log.Errorf(context.TODO(), "marshal current step index failed: %v", err)
 		bts = append(bts, []byte("{}")...)
@@ -273,6 +296,10 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 		Replicas:     incrementReplicasList,
 		ManualPaused: instance.Spec.Paused,
 		AutoPromote:  autoPromote,
+		Strategy:     strategy,
+		Phase:        phase,
+		Aborted:      aborted,
+		Message:      message,
 		Extra:        &extra,
 	}, nil
 }
@@ -298,10 +325,12 @@ func (r *rollout) Action(actionName string, un *unstructured.Unstructured) (*uns
 	case "pause":
 		spec["paused"] = true
 	case "promote-full":
-		steps := int32(len(instance.Spec.Strategy.Canary.Steps))
 		spec["paused"] = false
 		delete(status, "pauseConditions")
-		status["currentStepIndex"] = steps
+		// blueGreen has no steps, promoting it is to switch traffic to the new revision
+		if instance.Spec.Strategy.Canary != nil {
+			status["currentStepIndex"] = int64(len(instance.Spec.Strategy.Canary.Steps))
+		}
 	case "promote":
 		spec["paused"] = false
 		delete(status, "pauseConditions")
@@ -311,6 +340,14 @@ func (r *rollout) Action(actionName string, un *unstructured.Unstructured) (*uns
 		spec["paused"] = false
 	case "cancel-auto-promote":
 		delete(status, "autoPromote")
+	case "abort":
+		// the stable revision is scaled back and takes all traffic
+		status["abort"] = true
+	case "retry":
+		// retry an aborted rollout from the first step
+		delete(status, "abort")
+		delete(status, "pauseConditions")
+		spec["paused"] = false
 	default:
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
 	}
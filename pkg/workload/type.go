@@ -24,7 +24,13 @@ type Step struct {
 	Replicas     []int
 	ManualPaused bool
 	AutoPromote  bool
-	Extra        *string
+	// Strategy is canary or blueGreen, and empty for workloads released all at once
+	Strategy string
+	// Phase is the phase reported by the workload, such as Progressing, Paused, Healthy and Degraded
+	Phase   string
+	Aborted bool
+	Message string
+	Extra   *string
 }
 
 type Revision struct {